@@ -18,7 +18,19 @@ import (
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/invocation/cli"
 	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	"github.com/genmcp/gen-mcp/pkg/invocation/file"
+	"github.com/genmcp/gen-mcp/pkg/invocation/grpc"
 	"github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	"github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	"github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	"github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	"github.com/genmcp/gen-mcp/pkg/invocation/script"
+	"github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	"github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	"github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	"github.com/genmcp/gen-mcp/pkg/invocation/wasm"
 	googlejsonschema "github.com/google/jsonschema-go/jsonschema"
 )
 
@@ -343,6 +355,66 @@ func generateSchema(rootType interface{}, rootBase, rootPath string, schemaID, s
 			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
 			Path: "../../pkg/invocation",
 		},
+		{
+			Type: &sql.SqlInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &file.FileInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &grpc.GrpcInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &proxy.ProxyInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &inline.InlineInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &plugin.PluginInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &queue.QueueInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &k8s.K8sInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &ssh.SshInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &script.ScriptInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &wasm.WasmInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
+		{
+			Type: &smtp.SmtpInvocationConfig{},
+			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
+			Path: "../../pkg/invocation",
+		},
 		{
 			Type: &extends.ExtendsConfig{},
 			Base: "github.com/genmcp/gen-mcp/pkg/invocation",
@@ -382,10 +454,10 @@ func generateSchema(rootType interface{}, rootBase, rootPath string, schemaID, s
 				}
 			}
 			if t == reflect.TypeOf(&invocation.InvocationConfigWrapper{}) || t == reflect.TypeOf(invocation.InvocationConfigWrapper{}) {
-				// Create a schema that allows an object with one property: http, cli, or extends
+				// Create a schema that allows an object with one property: http, cli, sql, file, grpc, proxy, inline, plugin, queue, k8s, ssh, script, wasm, smtp, or extends
 				schema := &jsonschema.Schema{
 					Type:        "object",
-					Description: "Invocation configuration with exactly one type key (http, cli, or extends)",
+					Description: "Invocation configuration with exactly one type key (http, cli, sql, file, grpc, proxy, inline, plugin, queue, k8s, ssh, script, wasm, smtp, or extends)",
 					OneOf: []*jsonschema.Schema{
 						{
 							Type:                 "object",
@@ -399,6 +471,78 @@ func generateSchema(rootType interface{}, rootBase, rootPath string, schemaID, s
 							Required:             []string{"cli"},
 							AdditionalProperties: jsonschema.FalseSchema,
 						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"sql"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"file"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"grpc"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"proxy"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"inline"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"plugin"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"queue"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"k8s"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"ssh"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"script"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"wasm"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
+						{
+							Type:                 "object",
+							Properties:           jsonschema.NewProperties(),
+							Required:             []string{"smtp"},
+							AdditionalProperties: jsonschema.FalseSchema,
+						},
 						{
 							Type:                 "object",
 							Properties:           jsonschema.NewProperties(),
@@ -415,8 +559,56 @@ func generateSchema(rootType interface{}, rootBase, rootPath string, schemaID, s
 				schema.OneOf[1].Properties.Set("cli", &jsonschema.Schema{
 					Ref: "#/$defs/CliInvocationConfig",
 				})
+				// Add the sql property with reference to SqlInvocationConfig
+				schema.OneOf[2].Properties.Set("sql", &jsonschema.Schema{
+					Ref: "#/$defs/SqlInvocationConfig",
+				})
+				// Add the file property with reference to FileInvocationConfig
+				schema.OneOf[3].Properties.Set("file", &jsonschema.Schema{
+					Ref: "#/$defs/FileInvocationConfig",
+				})
+				// Add the grpc property with reference to GrpcInvocationConfig
+				schema.OneOf[4].Properties.Set("grpc", &jsonschema.Schema{
+					Ref: "#/$defs/GrpcInvocationConfig",
+				})
+				// Add the proxy property with reference to ProxyInvocationConfig
+				schema.OneOf[5].Properties.Set("proxy", &jsonschema.Schema{
+					Ref: "#/$defs/ProxyInvocationConfig",
+				})
+				// Add the inline property with reference to InlineInvocationConfig
+				schema.OneOf[6].Properties.Set("inline", &jsonschema.Schema{
+					Ref: "#/$defs/InlineInvocationConfig",
+				})
+				// Add the plugin property with reference to PluginInvocationConfig
+				schema.OneOf[7].Properties.Set("plugin", &jsonschema.Schema{
+					Ref: "#/$defs/PluginInvocationConfig",
+				})
+				// Add the queue property with reference to QueueInvocationConfig
+				schema.OneOf[8].Properties.Set("queue", &jsonschema.Schema{
+					Ref: "#/$defs/QueueInvocationConfig",
+				})
+				// Add the k8s property with reference to K8sInvocationConfig
+				schema.OneOf[9].Properties.Set("k8s", &jsonschema.Schema{
+					Ref: "#/$defs/K8sInvocationConfig",
+				})
+				// Add the ssh property with reference to SshInvocationConfig
+				schema.OneOf[10].Properties.Set("ssh", &jsonschema.Schema{
+					Ref: "#/$defs/SshInvocationConfig",
+				})
+				// Add the script property with reference to ScriptInvocationConfig
+				schema.OneOf[11].Properties.Set("script", &jsonschema.Schema{
+					Ref: "#/$defs/ScriptInvocationConfig",
+				})
+				// Add the wasm property with reference to WasmInvocationConfig
+				schema.OneOf[12].Properties.Set("wasm", &jsonschema.Schema{
+					Ref: "#/$defs/WasmInvocationConfig",
+				})
+				// Add the smtp property with reference to SmtpInvocationConfig
+				schema.OneOf[13].Properties.Set("smtp", &jsonschema.Schema{
+					Ref: "#/$defs/SmtpInvocationConfig",
+				})
 				// Add the extends property with reference to ExtendsConfig
-				schema.OneOf[2].Properties.Set("extends", &jsonschema.Schema{
+				schema.OneOf[14].Properties.Set("extends", &jsonschema.Schema{
 					Ref: "#/$defs/ExtendsConfig",
 				})
 				return schema
@@ -0,0 +1,258 @@
+// Package artifact packages MCP files as OCI artifacts, with the local files read by their invocations, so
+// that tool definitions can be versioned and distributed through container registries independently of
+// server images.
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"sigs.k8s.io/yaml"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
+
+	// register the invocation types, so that their configs can be parsed
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/file"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/script"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/wasm"
+)
+
+const (
+	// ConfigMediaType is the media type of the config of the artifacts, identifying MCP file artifacts.
+	ConfigMediaType types.MediaType = "application/vnd.genmcp.mcpfile.config.v1+json"
+
+	// MCPFileMediaType is the media type of the layer holding the MCP file, so that the artifacts can be
+	// extended by MCP files with oci:// references.
+	MCPFileMediaType types.MediaType = inherit.OCILayerMediaType
+
+	// FileMediaType is the media type of the layers holding the files referenced by the MCP file.
+	FileMediaType types.MediaType = "application/vnd.genmcp.file.v1"
+
+	// TitleAnnotation holds the path of the file of a layer, relative to the MCP file.
+	TitleAnnotation = "org.opencontainers.image.title"
+
+	// VersionAnnotation holds the version of the MCP server of an artifact.
+	VersionAnnotation = "org.opencontainers.image.version"
+
+	// MCPFileName is the name of the MCP file of pulled artifacts.
+	MCPFileName = "mcpfile.yaml"
+)
+
+// Package is an MCP file and the local files it references, packaged as an OCI artifact.
+type Package struct {
+	Image v1.Image
+
+	// Name and version of the MCP server
+	Name    string
+	Version string
+
+	// Files are the packaged files referenced by the MCP file, relative to it.
+	Files []string
+
+	// Skipped are the files referenced by the MCP file that are not packaged, and why.
+	Skipped []string
+}
+
+// NewPackage packages the MCP file at path, merged onto the files it extends, with the local files read by
+// its invocations. Referenced files are packaged if their path is relative to the directory of the MCP file
+// and inside of it, since the server reads them relative to its working directory.
+func NewPackage(path string) (*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP file: %w", err)
+	}
+
+	data, err = flatten(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	mcpFile, err := definitions.ParseMCPFileData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{Name: mcpFile.Name, Version: mcpFile.Version}
+	addenda := []mutate.Addendum{{
+		Layer:       static.NewLayer(data, MCPFileMediaType),
+		Annotations: map[string]string{TitleAnnotation: MCPFileName},
+		MediaType:   MCPFileMediaType,
+	}}
+
+	refs, err := referencedFiles(&mcpFile.MCPToolDefinitions)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	for _, ref := range refs {
+		rel, reason := packagedPath(ref)
+		if reason != "" {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s: %s", ref, reason))
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read referenced file: %w", err)
+		}
+
+		title := filepath.ToSlash(rel)
+		p.Files = append(p.Files, title)
+		addenda = append(addenda, mutate.Addendum{
+			Layer:       static.NewLayer(content, FileMediaType),
+			Annotations: map[string]string{TitleAnnotation: title},
+			MediaType:   FileMediaType,
+		})
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ConfigMediaType)
+	img, err = mutate.Append(img, addenda...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to package MCP file: %w", err)
+	}
+
+	annotations := map[string]string{TitleAnnotation: p.Name}
+	if p.Version != "" {
+		annotations[VersionAnnotation] = p.Version
+	}
+	p.Image = mutate.Annotations(img, annotations).(v1.Image)
+
+	return p, nil
+}
+
+// flatten returns the contents of the MCP file merged onto the files it extends, without its extends, so
+// that the artifact doesn't depend on them.
+func flatten(data []byte, path string) ([]byte, error) {
+	resolved, err := inherit.Resolve(data, path, inherit.Options{Cache: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the extends of MCP file: %w", err)
+	}
+	if bytes.Equal(resolved, data) {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(resolved))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "extends")
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(jsonData)
+}
+
+// referencedFiles returns the sorted paths of the local files read by the invocations of defs.
+func referencedFiles(defs *definitions.MCPToolDefinitions) ([]string, error) {
+	var primitives []invocation.Primitive
+	for _, t := range defs.Tools {
+		primitives = append(primitives, t)
+	}
+	for _, p := range defs.Prompts {
+		primitives = append(primitives, p)
+		for argument := range p.Completions {
+			primitives = append(primitives, p.CompletionTool(argument))
+		}
+	}
+	for _, r := range defs.Resources {
+		primitives = append(primitives, r)
+	}
+	for _, rt := range defs.ResourceTemplates {
+		primitives = append(primitives, rt)
+		if lr := rt.ListResource(); lr != nil {
+			primitives = append(primitives, lr)
+		}
+		for argument := range rt.Completions {
+			primitives = append(primitives, rt.CompletionTool(argument))
+		}
+	}
+
+	var files []string
+	for _, p := range primitives {
+		config := p.GetInvocationConfig()
+		if ec, ok := config.(*extends.ExtendsConfig); ok {
+			resolved, err := ec.Resolve()
+			if err != nil {
+				return nil, fmt.Errorf("invalid invocation of %s %s: %w", p.PrimitiveType(), p.GetName(), err)
+			}
+			config = resolved.Config
+		}
+
+		if fr, ok := config.(invocation.FileReferencer); ok {
+			files = append(files, fr.ReferencedFiles()...)
+		}
+	}
+
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// packagedPath returns the path of the referenced file ref in the artifact, or why it is not packaged.
+func packagedPath(ref string) (string, string) {
+	switch {
+	case strings.Contains(ref, "${"):
+		return "", "references environment variables"
+	case filepath.IsAbs(ref):
+		return "", "absolute path"
+	case !filepath.IsLocal(ref):
+		return "", "outside of the directory of the MCP file"
+	}
+	return filepath.Clean(ref), ""
+}
+
+// Push pushes the artifact of p to the registry, tagged ref, authenticating with the Docker credentials.
+// It returns the digest of the artifact.
+func Push(ctx context.Context, p *Package, ref string) (string, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if err := remote.Write(parsed, p.Image, remoteOptions(ctx)...); err != nil {
+		return "", fmt.Errorf("failed to push %s: %w", ref, err)
+	}
+
+	digest, err := p.Image.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+func remoteOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+}
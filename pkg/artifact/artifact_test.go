@@ -0,0 +1,243 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+)
+
+const mcpFileHeader = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+`
+
+const usersMCPFile = mcpFileHeader + `name: users
+version: 1.2.0
+invocationBases:
+  users:
+    http:
+      url: http://localhost/users
+      method: POST
+      protobuf:
+        descriptorSet: protos/users.pb
+        requestMessage: users.User
+tools:
+- name: create_user
+  description: Create a user
+  inputSchema:
+    type: object
+  invocation:
+    extends:
+      from: users
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      url: https://localhost/users/1
+      method: GET
+      client:
+        caCertFiles:
+        - certs/ca.pem
+        - /etc/ssl/certs/internal.pem
+        - ${CERTS_DIR}/ca.pem
+        - ../shared/ca.pem
+resources:
+- name: user_schema
+  description: The schema of users
+  uri: schemas://user
+  invocation:
+    inline:
+      file: schemas/user.json
+`
+
+// writeFiles writes files, by path relative to dir, and returns dir.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func newRegistry(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestNewPackage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"mcpfile.yaml":      usersMCPFile,
+		"protos/users.pb":   "descriptor set",
+		"certs/ca.pem":      "certificate",
+		"schemas/user.json": "{}",
+	})
+
+	p, err := NewPackage(filepath.Join(dir, "mcpfile.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "users", p.Name)
+	assert.Equal(t, "1.2.0", p.Version)
+	assert.Equal(t, []string{"certs/ca.pem", "protos/users.pb", "schemas/user.json"}, p.Files)
+	assert.Equal(t, []string{
+		"${CERTS_DIR}/ca.pem: references environment variables",
+		"../shared/ca.pem: outside of the directory of the MCP file",
+		"/etc/ssl/certs/internal.pem: absolute path",
+	}, p.Skipped)
+
+	manifest, err := p.Image.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, ConfigMediaType, manifest.Config.MediaType)
+	assert.Equal(t, map[string]string{TitleAnnotation: "users", VersionAnnotation: "1.2.0"}, manifest.Annotations)
+	require.Len(t, manifest.Layers, 4)
+	assert.Equal(t, MCPFileMediaType, manifest.Layers[0].MediaType)
+	assert.Equal(t, "certs/ca.pem", manifest.Layers[1].Annotations[TitleAnnotation])
+	assert.Equal(t, "protos/users.pb", manifest.Layers[2].Annotations[TitleAnnotation])
+	assert.Equal(t, "schemas/user.json", manifest.Layers[3].Annotations[TitleAnnotation])
+}
+
+func TestNewPackageErrors(t *testing.T) {
+	tt := map[string]struct {
+		files         map[string]string
+		errorContains string
+	}{
+		"missing MCP file": {
+			files:         map[string]string{},
+			errorContains: "failed to read MCP file",
+		},
+		"missing referenced file": {
+			files: map[string]string{
+				"mcpfile.yaml":    usersMCPFile,
+				"protos/users.pb": "descriptor set",
+			},
+			errorContains: "failed to read referenced file",
+		},
+		"invalid extends": {
+			files: map[string]string{
+				"mcpfile.yaml": mcpFileHeader + "extends: [missing.yaml]\n",
+			},
+			errorContains: "failed to resolve the extends of MCP file",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+
+			_, err := NewPackage(filepath.Join(dir, "mcpfile.yaml"))
+			assert.ErrorContains(t, err, tc.errorContains)
+		})
+	}
+}
+
+func TestNewPackageFlattensExtends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": mcpFileHeader + `name: base
+version: 1.0.0
+tools:
+- name: echo
+  description: Echo
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: echo hello
+`,
+		"mcpfile.yaml": mcpFileHeader + `extends: [base.yaml]
+version: 2.0.0
+`,
+	})
+
+	p, err := NewPackage(filepath.Join(dir, "mcpfile.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "base", p.Name)
+	assert.Equal(t, "2.0.0", p.Version)
+
+	layers, err := p.Image.Layers()
+	require.NoError(t, err)
+	rc, err := layers[0].Compressed()
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data), "extends")
+	assert.Contains(t, string(data), "command: echo hello")
+}
+
+func TestPushAndPull(t *testing.T) {
+	host := newRegistry(t)
+	ref := host + "/tools/users:1.2.0"
+
+	src := writeFiles(t, map[string]string{
+		"mcpfile.yaml":      usersMCPFile,
+		"protos/users.pb":   "descriptor set",
+		"certs/ca.pem":      "certificate",
+		"schemas/user.json": "{}",
+	})
+	p, err := NewPackage(filepath.Join(src, "mcpfile.yaml"))
+	require.NoError(t, err)
+
+	digest, err := Push(context.Background(), p, ref)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(digest, "sha256:"))
+
+	dest := t.TempDir()
+	pulled, err := Pull(context.Background(), ref, dest, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, digest, pulled.Digest)
+	assert.Equal(t, filepath.Join(dest, MCPFileName), pulled.MCPFile)
+	assert.Equal(t, []string{filepath.Join(dest, "certs", "ca.pem"), filepath.Join(dest, "protos", "users.pb"), filepath.Join(dest, "schemas", "user.json")}, pulled.Files)
+	for _, path := range []string{"mcpfile.yaml", "certs/ca.pem", "protos/users.pb", "schemas/user.json"} {
+		expected, err := os.ReadFile(filepath.Join(src, path))
+		require.NoError(t, err)
+		actual, err := os.ReadFile(filepath.Join(dest, path))
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), path)
+	}
+
+	t.Run("existing files are not overwritten", func(t *testing.T) {
+		_, err := Pull(context.Background(), ref, dest, false)
+		assert.ErrorContains(t, err, "already exists")
+
+		_, err = Pull(context.Background(), ref, dest, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pushed MCP files can be extended", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+		data := []byte(mcpFileHeader + "extends: [oci://" + ref + "]\nversion: 1.3.0\n")
+
+		resolved, err := inherit.Resolve(data, path, inherit.Options{})
+		require.NoError(t, err)
+
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(resolved, &doc))
+		assert.Equal(t, "users", doc["name"])
+		assert.Equal(t, "1.3.0", doc["version"])
+	})
+
+	t.Run("missing artifact", func(t *testing.T) {
+		_, err := Pull(context.Background(), host+"/tools/missing:1.0", t.TempDir(), false)
+		assert.ErrorContains(t, err, "failed to pull")
+	})
+}
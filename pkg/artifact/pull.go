@@ -0,0 +1,137 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Pulled is an artifact written to a directory by Pull.
+type Pulled struct {
+	Digest string
+
+	// MCPFile is the path of the MCP file.
+	MCPFile string
+
+	// Files are the paths of the files referenced by the MCP file.
+	Files []string
+}
+
+// pulledFile is a layer of an artifact and the path it is written to.
+type pulledFile struct {
+	layer v1.Layer
+	path  string
+}
+
+// Pull pulls the artifact tagged ref from the registry, authenticating with the Docker credentials, and
+// writes its MCP file to dir as mcpfile.yaml, and the files it references relative to it. Existing files
+// are only overwritten if overwrite is set.
+func Pull(ctx context.Context, ref, dir string, overwrite bool) (*Pulled, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := remote.Image(parsed, remoteOptions(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+
+	files, err := artifactFiles(img, dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact %s: %w", ref, err)
+	}
+
+	if !overwrite {
+		for _, f := range files {
+			if _, err := os.Stat(f.path); err == nil {
+				return nil, fmt.Errorf("%s already exists", f.path)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	pulled := &Pulled{Digest: digest.String(), MCPFile: files[0].path}
+	for i, f := range files {
+		if err := writeLayer(f.layer, f.path); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			pulled.Files = append(pulled.Files, f.path)
+		}
+	}
+
+	return pulled, nil
+}
+
+// artifactFiles returns the files of the layers of img written to dir, starting with the MCP file.
+func artifactFiles(img v1.Image, dir string) ([]pulledFile, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var mcpFile *pulledFile
+	var files []pulledFile
+	for _, desc := range manifest.Layers {
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		switch desc.MediaType {
+		case MCPFileMediaType:
+			if mcpFile != nil {
+				return nil, fmt.Errorf("more than one layer has media type %s", MCPFileMediaType)
+			}
+			mcpFile = &pulledFile{layer: layer, path: filepath.Join(dir, MCPFileName)}
+		case FileMediaType:
+			title := desc.Annotations[TitleAnnotation]
+			if title == "" || !filepath.IsLocal(filepath.FromSlash(title)) || filepath.FromSlash(title) == MCPFileName {
+				return nil, fmt.Errorf("invalid path '%s' of referenced file", title)
+			}
+			files = append(files, pulledFile{layer: layer, path: filepath.Join(dir, filepath.FromSlash(title))})
+		}
+	}
+
+	if mcpFile == nil {
+		return nil, fmt.Errorf("no layer has media type %s", MCPFileMediaType)
+	}
+	return append([]pulledFile{*mcpFile}, files...), nil
+}
+
+func writeLayer(layer v1.Layer, path string) error {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,296 @@
+// Package audit writes an audit log of the tool calls of a server: a JSON record per call, stating who
+// called which tool with which arguments, how the call ended and how long it took. The audit log is
+// separate from the debug logs of the server, and is written to a file, a syslog daemon, or an HTTP
+// endpoint.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outcomes of a tool call.
+const (
+	OutcomeSuccess   = "success"   // the tool returned a result
+	OutcomeError     = "error"     // the tool returned an error result, or the call failed
+	OutcomeDenied    = "denied"    // the caller is not authorized to call the tool
+	OutcomeCancelled = "cancelled" // the call was cancelled by the client
+)
+
+// How the arguments of a call are recorded.
+const (
+	ArgumentsHash     = "hash"     // a SHA-256 hash of the arguments
+	ArgumentsRedacted = "redacted" // the arguments, with the values of sensitive properties redacted
+)
+
+// What Log does with a record when the queue of the records waiting to be written is full.
+const (
+	QueueFullFail = "fail" // wait for room in the queue, and return ErrQueueFull if there is none in time
+	QueueFullDrop = "drop" // drop the record
+)
+
+// RedactedValue replaces the values of the redacted properties of arguments.
+const RedactedValue = "[REDACTED]"
+
+// DefaultQueueTimeout is how long Log waits for room in a full queue with QueueFullFail, if not set.
+const DefaultQueueTimeout = 5 * time.Second
+
+// queueSize is the number of records waiting to be written to the sink, past which the queue is full.
+const queueSize = 1024
+
+// ErrQueueFull is returned by Log when a record is dropped because the queue stayed full.
+var ErrQueueFull = errors.New("audit log queue is full")
+
+// Record is the audit record of a tool call.
+type Record struct {
+	Timestamp     time.Time       `json:"timestamp"`
+	Subject       string          `json:"subject,omitempty"` // subject of the credentials of the caller, if authenticated
+	Tool          string          `json:"tool"`
+	ArgumentsHash string          `json:"argumentsHash,omitempty"`
+	Arguments     json.RawMessage `json:"arguments,omitempty"`
+	Outcome       string          `json:"outcome"`
+	LatencyMs     float64         `json:"latencyMs"`
+}
+
+// Options defines how a Logger records the arguments of calls.
+type Options struct {
+	// Arguments is ArgumentsHash (the default) or ArgumentsRedacted.
+	Arguments string
+
+	// RedactFields are the names of the properties whose values are redacted, at any depth of the
+	// arguments, with ArgumentsRedacted. Names are matched case-insensitively.
+	RedactFields []string
+
+	// OnQueueFull is QueueFullFail (the default) or QueueFullDrop.
+	OnQueueFull string
+
+	// QueueTimeout is how long Log waits for room in a full queue with QueueFullFail, DefaultQueueTimeout
+	// if 0.
+	QueueTimeout time.Duration
+}
+
+// Logger writes audit records to a sink. Records are written in the background, so that a slow sink
+// doesn't delay tool calls. If the sink can't keep up, records are dropped, either right away or once Log
+// waited for room in the queue for too long, and counted. A nil Logger drops every record.
+type Logger struct {
+	sink         Sink
+	arguments    string
+	redactFields map[string]bool
+	onQueueFull  string
+	queueTimeout time.Duration
+	logger       *zap.Logger
+	dropped      atomic.Uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	records chan []byte
+	done    chan struct{}
+}
+
+// NewLogger creates a Logger writing records to sink. Failures to write records are logged to logger.
+func NewLogger(sink Sink, opts Options, logger *zap.Logger) *Logger {
+	redactFields := make(map[string]bool, len(opts.RedactFields))
+	for _, field := range opts.RedactFields {
+		redactFields[strings.ToLower(field)] = true
+	}
+
+	arguments := opts.Arguments
+	if arguments == "" {
+		arguments = ArgumentsHash
+	}
+
+	onQueueFull := opts.OnQueueFull
+	if onQueueFull == "" {
+		onQueueFull = QueueFullFail
+	}
+
+	queueTimeout := opts.QueueTimeout
+	if queueTimeout <= 0 {
+		queueTimeout = DefaultQueueTimeout
+	}
+
+	l := &Logger{
+		sink:         sink,
+		arguments:    arguments,
+		redactFields: redactFields,
+		onQueueFull:  onQueueFull,
+		queueTimeout: queueTimeout,
+		logger:       logger,
+		records:      make(chan []byte, queueSize),
+		done:         make(chan struct{}),
+	}
+	go l.run()
+
+	return l
+}
+
+// Log writes r to the audit log. r.Arguments holds the arguments sent by the client, which are replaced
+// according to the options of the Logger before the record is written. It returns an error wrapping
+// ErrQueueFull if the record was dropped with QueueFullFail, so that the call can fail instead of going
+// unaudited.
+func (l *Logger) Log(r Record) error {
+	if l == nil {
+		return nil
+	}
+
+	switch l.arguments {
+	case ArgumentsRedacted:
+		r.Arguments = l.redact(r.Arguments)
+	default:
+		r.ArgumentsHash = hashArguments(r.Arguments)
+		r.Arguments = nil
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		l.logger.Error("Failed to encode audit record", zap.String("tool_name", r.Tool), zap.Error(err))
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return nil
+	}
+
+	select {
+	case l.records <- line:
+		return nil
+	default:
+	}
+
+	if l.onQueueFull == QueueFullFail {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+
+		select {
+		case l.records <- line:
+			return nil
+		case <-timer.C:
+		}
+	}
+
+	dropped := l.dropped.Add(1)
+	l.logger.Error("Audit log queue is full, dropping record",
+		zap.String("tool_name", r.Tool),
+		zap.Uint64("dropped_records", dropped))
+	if l.onQueueFull == QueueFullFail {
+		return fmt.Errorf("%w, the record of the call of %s was dropped", ErrQueueFull, r.Tool)
+	}
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (l *Logger) Dropped() uint64 {
+	if l == nil {
+		return 0
+	}
+	return l.dropped.Load()
+}
+
+// Close writes the pending records and closes the sink. Records logged after Close are dropped.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.records)
+	l.mu.Unlock()
+
+	<-l.done
+	return l.sink.Close()
+}
+
+func (l *Logger) run() {
+	defer close(l.done)
+
+	for line := range l.records {
+		if err := l.sink.Write(line); err != nil {
+			l.logger.Error("Failed to write audit record", zap.Error(err))
+		}
+	}
+}
+
+// hashArguments returns the SHA-256 hash of arguments, encoded with sorted keys and without white space
+// so that the same arguments always have the same hash, or "" if there are no arguments.
+func hashArguments(arguments json.RawMessage) string {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		return ""
+	}
+
+	data := []byte(arguments)
+	if value, err := decode(arguments); err == nil {
+		if canonical, err := json.Marshal(value); err == nil {
+			data = canonical
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// redact returns arguments with the values of the redacted properties replaced with RedactedValue. It
+// returns nil if arguments are not valid JSON, as they can't be redacted.
+func (l *Logger) redact(arguments json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		return nil
+	}
+
+	value, err := decode(arguments)
+	if err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(l.redactValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func (l *Logger) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if l.redactFields[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = l.redactValue(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = l.redactValue(child)
+		}
+	}
+	return value
+}
+
+// decode decodes JSON data, keeping numbers as they are written.
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	records []string
+	closed  bool
+}
+
+func (s *memorySink) Write(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = append(s.records, string(record))
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestLoggerLog(t *testing.T) {
+	timestamp := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	tt := []struct {
+		name      string
+		opts      Options
+		arguments string
+		expected  string
+	}{
+		{
+			name:      "arguments hashed by default",
+			arguments: `{"repo": "genmcp/gen-mcp", "limit": 10}`,
+			expected:  `{"timestamp":"2026-10-16T09:30:00Z","subject":"alice","tool":"list_issues","argumentsHash":"sha256:d167adcbfbf9e2106fb217f36237ad24391aac72cb1f77c3f22ff19773c409fb","outcome":"success","latencyMs":12.5}`,
+		},
+		{
+			name:      "hash does not depend on the order of properties",
+			arguments: `{"limit":10,"repo":"genmcp/gen-mcp"}`,
+			expected:  `{"timestamp":"2026-10-16T09:30:00Z","subject":"alice","tool":"list_issues","argumentsHash":"sha256:d167adcbfbf9e2106fb217f36237ad24391aac72cb1f77c3f22ff19773c409fb","outcome":"success","latencyMs":12.5}`,
+		},
+		{
+			name:     "no arguments",
+			expected: `{"timestamp":"2026-10-16T09:30:00Z","subject":"alice","tool":"list_issues","outcome":"success","latencyMs":12.5}`,
+		},
+		{
+			name:      "redacted arguments",
+			opts:      Options{Arguments: ArgumentsRedacted, RedactFields: []string{"token", "Password"}},
+			arguments: `{"repo":"genmcp/gen-mcp","token":"ghp_secret","auth":[{"user":"bob","password":"hunter2"}],"limit":10}`,
+			expected:  `{"timestamp":"2026-10-16T09:30:00Z","subject":"alice","tool":"list_issues","arguments":{"auth":[{"password":"[REDACTED]","user":"bob"}],"limit":10,"repo":"genmcp/gen-mcp","token":"[REDACTED]"},"outcome":"success","latencyMs":12.5}`,
+		},
+		{
+			name:      "invalid arguments are not redacted",
+			opts:      Options{Arguments: ArgumentsRedacted},
+			arguments: `{"repo":`,
+			expected:  `{"timestamp":"2026-10-16T09:30:00Z","subject":"alice","tool":"list_issues","outcome":"success","latencyMs":12.5}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &memorySink{}
+			logger := NewLogger(sink, tc.opts, zap.NewNop())
+
+			logger.Log(Record{
+				Timestamp: timestamp,
+				Subject:   "alice",
+				Tool:      "list_issues",
+				Arguments: json.RawMessage(tc.arguments),
+				Outcome:   OutcomeSuccess,
+				LatencyMs: 12.5,
+			})
+			require.NoError(t, logger.Close())
+
+			assert.True(t, sink.closed)
+			require.Len(t, sink.records, 1)
+			assert.JSONEq(t, tc.expected, sink.records[0])
+		})
+	}
+}
+
+// blockingSink blocks writes until unblock is closed.
+type blockingSink struct {
+	memorySink
+	unblock chan struct{}
+}
+
+func (s *blockingSink) Write(record []byte) error {
+	<-s.unblock
+	return s.memorySink.Write(record)
+}
+
+func TestLoggerQueueFull(t *testing.T) {
+	tt := []struct {
+		name        string
+		opts        Options
+		expectedErr error
+	}{
+		{
+			name:        "fail by default",
+			opts:        Options{QueueTimeout: 10 * time.Millisecond},
+			expectedErr: ErrQueueFull,
+		},
+		{
+			name: "drop",
+			opts: Options{OnQueueFull: QueueFullDrop},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &blockingSink{unblock: make(chan struct{})}
+			logger := NewLogger(sink, tc.opts, zap.NewNop())
+
+			// the first record is taken from the queue by the writer, which is blocked writing it
+			require.NoError(t, logger.Log(Record{Tool: "list_issues", Outcome: OutcomeSuccess}))
+			require.Eventually(t, func() bool { return len(logger.records) == 0 }, time.Second, time.Millisecond)
+			for range queueSize {
+				require.NoError(t, logger.Log(Record{Tool: "list_issues", Outcome: OutcomeSuccess}))
+			}
+
+			err := logger.Log(Record{Tool: "list_issues", Outcome: OutcomeSuccess})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, uint64(1), logger.Dropped())
+
+			close(sink.unblock)
+			require.NoError(t, logger.Close())
+			assert.Len(t, sink.records, queueSize+1)
+		})
+	}
+}
+
+func TestLoggerClose(t *testing.T) {
+	sink := &memorySink{}
+	logger := NewLogger(sink, Options{}, zap.NewNop())
+
+	for range 100 {
+		logger.Log(Record{Tool: "list_issues", Outcome: OutcomeSuccess})
+	}
+	require.NoError(t, logger.Close())
+	assert.Len(t, sink.records, 100, "pending records should be written before the sink is closed")
+
+	logger.Log(Record{Tool: "list_issues", Outcome: OutcomeSuccess})
+	assert.NoError(t, logger.Close())
+	assert.Len(t, sink.records, 100)
+
+	var nilLogger *Logger
+	nilLogger.Log(Record{Tool: "list_issues"})
+	assert.NoError(t, nilLogger.Close())
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// httpSinkTimeout bounds the time an HTTPSink waits for the endpoint to accept a record.
+const httpSinkTimeout = 10 * time.Second
+
+// Sink is where audit records are written to.
+type Sink interface {
+	// Write writes a record, encoded as a single line of JSON without the trailing newline.
+	Write(record []byte) error
+
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// FileSink appends records to a file, one per line (JSON Lines).
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens the file at path for appending, creating it if it doesn't exist. The file is only
+// readable by the user of the server, as records can contain arguments of calls.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.file.Write(append(record, '\n'))
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// HTTPSink sends each record as the JSON body of a POST request to an endpoint, e.g. the HTTP collector
+// of a log management system.
+type HTTPSink struct {
+	URL     string
+	Headers map[string]string // headers of the requests, e.g. Authorization
+	Client  *http.Client      // http.DefaultClient if nil
+}
+
+func (s *HTTPSink) Write(record []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), httpSinkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(record))
+	if err != nil {
+		return fmt.Errorf("failed to create audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send audit record: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send audit record: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *HTTPSink) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"tool":"existing"}`+"\n"), 0o600))
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]byte(`{"tool":"first"}`)))
+	require.NoError(t, sink.Write([]byte(`{"tool":"second"}`)))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"tool":"existing"}`+"\n"+`{"tool":"first"}`+"\n"+`{"tool":"second"}`+"\n", string(data))
+
+	_, err = NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	assert.ErrorContains(t, err, "failed to open audit log file")
+}
+
+func TestHTTPSink(t *testing.T) {
+	tt := []struct {
+		name        string
+		status      int
+		errContains string
+	}{
+		{
+			name:   "record accepted",
+			status: http.StatusAccepted,
+		},
+		{
+			name:        "record rejected",
+			status:      http.StatusUnauthorized,
+			errContains: "failed to send audit record: unexpected status 401",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var received *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			sink := &HTTPSink{URL: server.URL + "/audit", Headers: map[string]string{"Authorization": "Bearer token"}}
+			err := sink.Write([]byte(`{"tool":"list_issues"}`))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.NotNil(t, received)
+			assert.Equal(t, http.MethodPost, received.Method)
+			assert.Equal(t, "/audit", received.URL.Path)
+			assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+			assert.Equal(t, `{"tool":"list_issues"}`, string(body))
+		})
+	}
+}
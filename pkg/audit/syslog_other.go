@@ -0,0 +1,21 @@
+//go:build !unix
+
+package audit
+
+import "fmt"
+
+// SyslogSink sends records to a syslog daemon. It is not supported on this platform.
+type SyslogSink struct{}
+
+// NewSyslogSink returns an error, as syslog is not supported on this platform.
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
+
+func (s *SyslogSink) Write(record []byte) error {
+	return fmt.Errorf("syslog is not supported on this platform")
+}
+
+func (s *SyslogSink) Close() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogSink sends records to a syslog daemon, with the info severity of the auth facility.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the syslog daemon at address over network (e.g. udp, tcp), or to the local
+// daemon if network is empty. Messages are tagged with tag.
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) Write(record []byte) error {
+	return s.writer.Info(string(record))
+}
+
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
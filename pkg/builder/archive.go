@@ -0,0 +1,157 @@
+package builder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// archive formats
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
+// launchers set the paths of the config files next to them and start the server
+const (
+	unixLauncher = `#!/bin/sh
+dir=$(cd "$(dirname "$0")" && pwd)
+export MCP_FILE_PATH="$dir/mcpfile.yaml"
+export MCP_SERVER_CONFIG_PATH="$dir/mcpserver.yaml"
+exec "$dir/genmcp-server" "$@"
+`
+	windowsLauncher = "@echo off\r\n" +
+		"set \"MCP_FILE_PATH=%~dp0mcpfile.yaml\"\r\n" +
+		"set \"MCP_SERVER_CONFIG_PATH=%~dp0mcpserver.yaml\"\r\n" +
+		"\"%~dp0genmcp-server.exe\" %*\r\n"
+)
+
+// ArchiveOptions defines an archive holding the server binary of a platform and its config files, for
+// platforms that don't run containers, such as darwin.
+type ArchiveOptions struct {
+	Platform               *v1.Platform // Target platform (darwin/arm64, etc.)
+	MCPToolDefinitionsPath string       // path to the MCP file
+	MCPServerConfigPath    string       // path to the MCP server configuration file
+	Format                 string       // zip or tar.gz, zip for windows and tar.gz otherwise if empty
+}
+
+func (o *ArchiveOptions) SetDefaults() {
+	if o.Platform == nil {
+		o.Platform = &v1.Platform{OS: "linux", Architecture: "amd64"}
+	}
+	if o.Format == "" {
+		o.Format = ArchiveFormatTarGz
+		if o.Platform.OS == "windows" {
+			o.Format = ArchiveFormatZip
+		}
+	}
+}
+
+type archiveEntry struct {
+	name string
+	mode int64
+	data []byte
+}
+
+// BuildArchive writes an archive with the server binary, the config files, and a launcher script setting the
+// paths of the config files (run.sh, or run.cmd on windows) to w.
+func (b *ImageBuilder) BuildArchive(ctx context.Context, opts ArchiveOptions, w io.Writer) error {
+	opts.SetDefaults()
+	if opts.Format != ArchiveFormatZip && opts.Format != ArchiveFormatTarGz {
+		return fmt.Errorf("invalid archive format '%s' expected one of '%s' or '%s'", opts.Format, ArchiveFormatZip, ArchiveFormatTarGz)
+	}
+
+	serverBinary, _, err := b.binaryProvider.ExtractServerBinary(opts.Platform)
+	if err != nil {
+		return fmt.Errorf("failed to extract server binary: %w", err)
+	}
+
+	mcpToolDefsData, err := b.fs.ReadFile(opts.MCPToolDefinitionsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read MCP file: %w", err)
+	}
+
+	mcpServerConfigData, err := b.fs.ReadFile(opts.MCPServerConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read MCP server config file: %w", err)
+	}
+
+	entries := []archiveEntry{
+		{name: "genmcp-server", mode: 0755, data: serverBinary},
+		{name: "mcpfile.yaml", mode: 0644, data: mcpToolDefsData},
+		{name: "mcpserver.yaml", mode: 0644, data: mcpServerConfigData},
+		{name: "run.sh", mode: 0755, data: []byte(unixLauncher)},
+	}
+	if opts.Platform.OS == "windows" {
+		entries[0].name = "genmcp-server.exe"
+		entries[3] = archiveEntry{name: "run.cmd", mode: 0644, data: []byte(windowsLauncher)}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if opts.Format == ArchiveFormatZip {
+		return writeZip(w, entries)
+	}
+	return writeTarGz(w, entries)
+}
+
+func writeZip(w io.Writer, entries []archiveEntry) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		}
+		header.SetMode(fs.FileMode(entry.mode))
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write header for file %s to zip: %w", entry.name, err)
+		}
+		if _, err := fw.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write data for file %s to zip: %w", entry.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write zip: %w", err)
+	}
+	return nil
+}
+
+func writeTarGz(w io.Writer, entries []archiveEntry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Size:     int64(len(entry.data)),
+			Typeflag: tar.TypeReg,
+			Mode:     entry.mode,
+			ModTime:  time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to write header for file %s to tar: %w", entry.name, err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write data for file %s to tar: %w", entry.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write tar: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to write gzip: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package builder
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// readArchive returns the modes and contents of the files of an archive by name.
+func readArchive(t *testing.T, format string, data []byte) (map[string]int64, map[string]string) {
+	t.Helper()
+
+	modes, contents := map[string]int64{}, map[string]string{}
+	if format == ArchiveFormatZip {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			_ = rc.Close()
+			modes[f.Name] = int64(f.Mode().Perm())
+			contents[f.Name] = string(content)
+		}
+		return modes, contents
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		modes[header.Name] = header.Mode
+		contents[header.Name] = string(content)
+	}
+	return modes, contents
+}
+
+func TestImageBuilder_BuildArchive(t *testing.T) {
+	tt := []struct {
+		name           string
+		opts           ArchiveOptions
+		expectedFormat string
+		expectedModes  map[string]int64
+		expectedError  string
+	}{
+		{
+			name:           "darwin tar.gz",
+			opts:           ArchiveOptions{Platform: &v1.Platform{OS: "darwin", Architecture: "arm64"}},
+			expectedFormat: ArchiveFormatTarGz,
+			expectedModes: map[string]int64{
+				"genmcp-server":  0755,
+				"mcpfile.yaml":   0644,
+				"mcpserver.yaml": 0644,
+				"run.sh":         0755,
+			},
+		},
+		{
+			name:           "windows zip",
+			opts:           ArchiveOptions{Platform: &v1.Platform{OS: "windows", Architecture: "amd64"}},
+			expectedFormat: ArchiveFormatZip,
+			expectedModes: map[string]int64{
+				"genmcp-server.exe": 0755,
+				"mcpfile.yaml":      0644,
+				"mcpserver.yaml":    0644,
+				"run.cmd":           0644,
+			},
+		},
+		{
+			name:           "linux zip",
+			opts:           ArchiveOptions{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}, Format: ArchiveFormatZip},
+			expectedFormat: ArchiveFormatZip,
+			expectedModes: map[string]int64{
+				"genmcp-server":  0755,
+				"mcpfile.yaml":   0644,
+				"mcpserver.yaml": 0644,
+				"run.sh":         0755,
+			},
+		},
+		{
+			name:          "invalid format",
+			opts:          ArchiveOptions{Format: "rar"},
+			expectedError: "invalid archive format 'rar'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFS := &mockFileSystem{}
+			mockBP := &mockBinaryProvider{}
+			mockFS.On("ReadFile", "/test/mcpfile.yaml").Return([]byte("name: test-server\n"), nil)
+			mockFS.On("ReadFile", "/test/mcpserver.yaml").Return([]byte("runtime: {}\n"), nil)
+			mockBP.On("ExtractServerBinary", mock.Anything).Return([]byte("fake-binary-data"), &mockFileInfo{name: "genmcp-server"}, nil)
+
+			b := &ImageBuilder{fs: mockFS, binaryProvider: mockBP}
+			opts := tc.opts
+			opts.MCPToolDefinitionsPath = "/test/mcpfile.yaml"
+			opts.MCPServerConfigPath = "/test/mcpserver.yaml"
+
+			var buf bytes.Buffer
+			err := b.BuildArchive(context.Background(), opts, &buf)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			modes, contents := readArchive(t, tc.expectedFormat, buf.Bytes())
+			assert.Equal(t, tc.expectedModes, modes)
+			assert.Equal(t, "name: test-server\n", contents["mcpfile.yaml"])
+			assert.Equal(t, "runtime: {}\n", contents["mcpserver.yaml"])
+			for name, content := range contents {
+				if name == "run.sh" || name == "run.cmd" {
+					assert.Contains(t, content, "MCP_SERVER_CONFIG_PATH=")
+				}
+			}
+		})
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
 
 	mcpfile "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -22,13 +26,13 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
-	"sigs.k8s.io/yaml"
 )
 
 // FileSystem interface for file operations
 type FileSystem interface {
 	Stat(name string) (fs.FileInfo, error)
 	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
 }
 
 // BinaryProvider interface for accessing server binaries
@@ -58,6 +62,10 @@ func (fs *OSFileSystem) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
 
+func (fs *OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
 // DefaultImageDownloader implements ImageDownloader using go-containerregistry
 type DefaultImageDownloader struct{}
 
@@ -65,7 +73,11 @@ type DefaultImageDownloader struct{}
 type RegistryImageSaver struct{}
 
 // DaemonImageSaver implements ImageSaver for saving to local container engine
-type DaemonImageSaver struct{}
+type DaemonImageSaver struct {
+	// Engine is the CLI of the container engine the images are loaded into with its load command, e.g.
+	// podman. Images are written with the Docker Engine API, at DOCKER_HOST if set, when empty.
+	Engine string
+}
 
 func (d *DefaultImageDownloader) DownloadImage(ctx context.Context, baseImage string, platform *v1.Platform) (v1.Image, error) {
 	ref, err := name.ParseReference(baseImage)
@@ -124,14 +136,40 @@ func (d *DaemonImageSaver) SaveImage(ctx context.Context, img v1.Image, ref stri
 		return fmt.Errorf("failed to parse tag %s: %w", ref, err)
 	}
 
-	_, err = daemon.Write(tag, img, daemon.WithContext(ctx))
-	if err != nil {
+	if err := d.write(ctx, tag, img); err != nil {
 		return fmt.Errorf("failed to save image to local container engine: %w", err)
 	}
 
 	return nil
 }
 
+func (d *DaemonImageSaver) write(ctx context.Context, tag name.Tag, img v1.Image) error {
+	if d.Engine == "" {
+		_, err := daemon.Write(tag, img, daemon.WithContext(ctx))
+		return err
+	}
+
+	// stream the image as a tarball to the load command of the engine
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarball.Write(tag, img, pw))
+	}()
+	defer func() { _ = pr.Close() }()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.Engine, "load")
+	cmd.Stdin = pr
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("%s load failed: %w: %s", d.Engine, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return fmt.Errorf("%s load failed: %w", d.Engine, err)
+	}
+
+	return nil
+}
+
 func (d *DaemonImageSaver) SaveImageIndex(ctx context.Context, idx v1.ImageIndex, ref string) error {
 	// Docker daemon doesn't support writing image indexes directly
 	// Instead, we save each platform-specific image with a platform suffix
@@ -175,16 +213,14 @@ func (d *DaemonImageSaver) SaveImageIndex(ctx context.Context, idx v1.ImageIndex
 			return fmt.Errorf("failed to create platform tag: %w", err)
 		}
 
-		_, err = daemon.Write(platformTag, img, daemon.WithContext(ctx))
-		if err != nil {
+		if err := d.write(ctx, platformTag, img); err != nil {
 			return fmt.Errorf("failed to save image for platform %s to local container engine: %w", platformSuffix, err)
 		}
 	}
 
 	// Also write the selected image with the original tag
 	if imageForBaseTag != nil {
-		_, err = daemon.Write(baseTag, imageForBaseTag, daemon.WithContext(ctx))
-		if err != nil {
+		if err := d.write(ctx, baseTag, imageForBaseTag); err != nil {
 			return fmt.Errorf("failed to save image with original tag to local container engine: %w", err)
 		}
 	}
@@ -206,6 +242,12 @@ const (
 	McpServerNameLabel    = "io.modelcontextprotocol.server.name"
 )
 
+// paths of the config files in linux images
+const (
+	ImageMCPFilePath         = "/app/mcpfile.yaml"
+	ImageMCPServerConfigPath = "/app/mcpserver.yaml"
+)
+
 type ImageBuilder struct {
 	fs              FileSystem
 	binaryProvider  BinaryProvider
@@ -235,8 +277,20 @@ func New(saveToRegistry bool, version string, verbose bool) (*ImageBuilder, erro
 	}, nil
 }
 
+// SaveToDaemon makes the builder load the images it saves into the local container engine instead of
+// pushing them to a registry. See DaemonImageSaver for engine.
+func (b *ImageBuilder) SaveToDaemon(engine string) {
+	b.imageSaver = &DaemonImageSaver{Engine: engine}
+}
+
 func (b *ImageBuilder) Build(ctx context.Context, opts BuildOptions) (v1.Image, error) {
 	opts.SetDefaults()
+	if opts.Platform.OS == "darwin" {
+		return nil, fmt.Errorf("container images can't be built for %s/%s, build an archive instead", opts.Platform.OS, opts.Platform.Architecture)
+	}
+	if err := opts.ImageConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid image config: %w", err)
+	}
 
 	baseImg, err := b.imageDownloader.DownloadImage(ctx, opts.BaseImage, opts.Platform)
 	if err != nil {
@@ -261,7 +315,7 @@ func (b *ImageBuilder) Build(ctx context.Context, opts BuildOptions) (v1.Image,
 
 	defs := &mcpfile.MCPToolDefinitionsFile{}
 
-	err = yaml.Unmarshal(mcpToolDefsData, defs)
+	err = parsecache.Unmarshal(mcpToolDefsData, defs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse MCP file: %w", err)
 	}
@@ -297,7 +351,16 @@ func (b *ImageBuilder) Build(ctx context.Context, opts BuildOptions) (v1.Image,
 		return nil, fmt.Errorf("failed to create layer for mcpserver.yaml: %w", err)
 	}
 
-	img, err := b.assembleImage(baseImg, opts, defs, binaryLayer, mcpToolDefsLayer, mcpServerConfigLayer)
+	layers := []v1.Layer{binaryLayer, mcpToolDefsLayer, mcpServerConfigLayer}
+	if len(opts.Files) > 0 {
+		filesLayer, err := b.createFilesLayer(opts.Files, opts.Platform, mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create layer for additional files: %w", err)
+		}
+		layers = append(layers, filesLayer)
+	}
+
+	img, err := b.assembleImage(baseImg, opts, defs, layers...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to assemble final image: %w", err)
 	}
@@ -325,6 +388,7 @@ func (b *ImageBuilder) BuildMultiArch(ctx context.Context, opts MultiArchBuildOp
 			MCPToolDefinitionsPath: opts.MCPToolDefinitionsPath,
 			MCPServerConfigPath:    opts.MCPServerConfigPath,
 			ImageTag:               opts.ImageTag,
+			ImageConfig:            opts.ImageConfig,
 		}
 
 		img, err := b.Build(ctx, buildOpts)
@@ -384,8 +448,8 @@ func (b *ImageBuilder) assembleImage(
 
 	binaryPath := "/usr/local/bin/genmcp-server"
 	workingDir := "/app"
-	mcpToolDefsPath := "/app/mcpfile.yaml"
-	mcpServerConfigPath := "/app/mcpserver.yaml"
+	mcpToolDefsPath := ImageMCPFilePath
+	mcpServerConfigPath := ImageMCPServerConfigPath
 	if opts.Platform.OS == "windows" {
 		binaryPath = `C:\usr\local\bin\genmcp-server.exe`
 		workingDir = `C:\app`
@@ -397,6 +461,10 @@ func (b *ImageBuilder) assembleImage(
 	cfg.Config.WorkingDir = workingDir
 	cfg.Config.Env = append(cfg.Config.Env, "MCP_FILE_PATH="+mcpToolDefsPath)
 	cfg.Config.Env = append(cfg.Config.Env, "MCP_SERVER_CONFIG_PATH="+mcpServerConfigPath)
+	for _, env := range opts.Env {
+		cfg.Config.Env = setEnv(cfg.Config.Env, env)
+	}
+	cfg.Config.Cmd = opts.Args
 	cfg.Config.User = "1001:1001"
 	cfg.Created = v1.Time{Time: createTime}
 
@@ -420,6 +488,19 @@ func (b *ImageBuilder) assembleImage(
 		}
 	}
 
+	for key, value := range opts.Labels {
+		cfg.Config.Labels[key] = value
+	}
+
+	if len(opts.ExposedPorts) > 0 && cfg.Config.ExposedPorts == nil {
+		cfg.Config.ExposedPorts = make(map[string]struct{})
+	}
+	for _, port := range opts.ExposedPorts {
+		// validated by Build
+		p, _ := exposedPort(port)
+		cfg.Config.ExposedPorts[p] = struct{}{}
+	}
+
 	img, err = mutate.ConfigFile(img, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set image config: %w", err)
@@ -439,10 +520,25 @@ func (b *ImageBuilder) assembleImage(
 			annotations[ImageVersionLabel] = tag
 		}
 	}
+	for key, value := range opts.Annotations {
+		annotations[key] = value
+	}
 
 	return mutate.Annotations(img, annotations).(v1.Image), nil
 }
 
+// setEnv sets the variable of env, given as NAME=value, in the environment variables of an image config.
+func setEnv(vars []string, env string) []string {
+	name, _, _ := strings.Cut(env, "=")
+	for i, v := range vars {
+		if strings.HasPrefix(v, name+"=") {
+			vars[i] = env
+			return vars
+		}
+	}
+	return append(vars, env)
+}
+
 // createBinaryLayer creates a tarball layer with the genmcp-server binary at /usr/local/bin/genmcp-server
 func (b *ImageBuilder) createBinaryLayer(
 	binaryData []byte,
@@ -483,6 +579,83 @@ func (b *ImageBuilder) createMCPFileLayer(
 	}, tarball.WithCompressedCaching, tarball.WithMediaType(layerMediaType))
 }
 
+// createFilesLayer creates a tarball layer with the additional files of the image, copying the contents of
+// directories recursively
+func (b *ImageBuilder) createFilesLayer(files []ImageFile, platform *v1.Platform, layerMediaType types.MediaType) (v1.Layer, error) {
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+
+	for _, file := range files {
+		if err := b.addToTar(tw, file.Source, path.Clean(file.Destination), file.Mode, platform.OS); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write tar: %w", err)
+	}
+
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewBuffer(buf.Bytes())), nil
+	}, tarball.WithCompressedCaching, tarball.WithMediaType(layerMediaType))
+}
+
+func (b *ImageBuilder) addToTar(tw *tar.Writer, source, destination string, mode fs.FileMode, os string) error {
+	info, err := b.fs.Stat(source)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", source, err)
+	}
+
+	if info.IsDir() {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     destination,
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+		}); err != nil {
+			return fmt.Errorf("failed to write dir %s to tar: %w", destination, err)
+		}
+
+		entries, err := b.fs.ReadDir(source)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", source, err)
+		}
+		for _, entry := range entries {
+			if err := b.addToTar(tw, filepath.Join(source, entry.Name()), path.Join(destination, entry.Name()), mode, os); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	data, err := b.fs.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	if mode == 0 {
+		mode = info.Mode().Perm()
+	}
+	header := &tar.Header{
+		Name:       destination,
+		Size:       int64(len(data)),
+		Typeflag:   tar.TypeReg,
+		Mode:       int64(mode.Perm()),
+		PAXRecords: map[string]string{},
+	}
+	if os == "windows" {
+		// need to set magic value for binaries to be executable
+		header.PAXRecords["MSWINDOWS.rawsd"] = userOwnerAndGroupSID
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header for file %s to tar: %w", destination, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write data for file %s to tar: %w", destination, err)
+	}
+
+	return nil
+}
+
 func createTarWithFile(filepath, filename, os string, data []byte, fileInfo fs.FileInfo, mode int64) (*bytes.Buffer, error) {
 	buf := bytes.NewBuffer(nil)
 	tw := tar.NewWriter(buf)
@@ -1,9 +1,14 @@
 package builder
 
 import (
+	"archive/tar"
 	"context"
 	"errors"
+	"io"
 	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -12,6 +17,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/fake"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +41,14 @@ func (m *mockFileSystem) ReadFile(name string) ([]byte, error) {
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *mockFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]fs.DirEntry), args.Error(1)
+}
+
 type mockBinaryProvider struct {
 	mock.Mock
 }
@@ -340,6 +354,80 @@ func TestImageBuilder_Build(t *testing.T) {
 			},
 			expectedError: "failed to get media type for layers: invalid base image media type",
 		},
+		{
+			name: "applies image config",
+			buildOptions: BuildOptions{
+				MCPToolDefinitionsPath: "/test/mcpfile.yaml",
+				MCPServerConfigPath:    "/test/mcpserver.yaml",
+				ImageTag:               "test:latest",
+				ImageConfig: ImageConfig{
+					Labels:       map[string]string{"team": "payments", ImageDescriptionLabel: "Payments MCP server"},
+					Annotations:  map[string]string{"org.opencontainers.image.source": "https://example.com/payments"},
+					Env:          []string{"LOG_LEVEL=debug", "MCP_SERVER_CONFIG_PATH=/config/mcpserver.yaml"},
+					ExposedPorts: []string{"8080", "9090/udp"},
+					Args:         []string{"--verbose"},
+				},
+			},
+			setupMocks: func(mfs *mockFileSystem, mbp *mockBinaryProvider, mid *mockImageDownloader) {
+				baseImg := newTestImage(types.DockerManifestSchema2)
+				mid.On("DownloadImage", mock.Anything, DefaultBaseImage, &v1.Platform{OS: "linux", Architecture: "amd64"}).Return(baseImg, nil)
+
+				binaryData := []byte("fake-binary-data")
+				binaryInfo := &mockFileInfo{name: "genmcp-server", size: int64(len(binaryData))}
+				mbp.On("ExtractServerBinary", &v1.Platform{OS: "linux", Architecture: "amd64"}).Return(binaryData, binaryInfo, nil)
+
+				mcpToolDefsData := []byte("kind: MCPToolDefinitions\nschemaVersion: 0.2.0\nname: test-server\nversion: 1.0.0\n")
+				mcpToolDefsInfo := &mockFileInfo{name: "mcpfile.yaml", size: int64(len(mcpToolDefsData))}
+				mfs.On("Stat", "/test/mcpfile.yaml").Return(mcpToolDefsInfo, nil)
+				mfs.On("ReadFile", "/test/mcpfile.yaml").Return(mcpToolDefsData, nil)
+
+				mcpServerConfigData := []byte("fake-mcp-mcpserver-data")
+				mcpServerConfigInfo := &mockFileInfo{name: "mcpserver.yaml", size: int64(len(mcpServerConfigData))}
+				mfs.On("Stat", "/test/mcpserver.yaml").Return(mcpServerConfigInfo, nil)
+				mfs.On("ReadFile", "/test/mcpserver.yaml").Return(mcpServerConfigData, nil)
+			},
+			validateResult: func(t *testing.T, img v1.Image) {
+				configFile, err := img.ConfigFile()
+				assert.NoError(t, err)
+
+				assert.Equal(t, "payments", configFile.Config.Labels["team"])
+				assert.Equal(t, "Payments MCP server", configFile.Config.Labels[ImageDescriptionLabel])
+				assert.Equal(t, "test-server", configFile.Config.Labels[McpServerNameLabel])
+				assert.Equal(t, []string{
+					"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+					"MCP_FILE_PATH=/app/mcpfile.yaml",
+					"MCP_SERVER_CONFIG_PATH=/config/mcpserver.yaml",
+					"LOG_LEVEL=debug",
+				}, configFile.Config.Env)
+				assert.Equal(t, map[string]struct{}{"8080/tcp": {}, "9090/udp": {}}, configFile.Config.ExposedPorts)
+				assert.Equal(t, []string{"--verbose"}, configFile.Config.Cmd)
+
+				manifest, err := img.Manifest()
+				assert.NoError(t, err)
+				assert.Equal(t, "https://example.com/payments", manifest.Annotations["org.opencontainers.image.source"])
+				assert.Equal(t, "GenMCP Server Image", manifest.Annotations[ImageDescriptionLabel])
+			},
+		},
+		{
+			name: "darwin platform",
+			buildOptions: BuildOptions{
+				Platform:               &v1.Platform{OS: "darwin", Architecture: "arm64"},
+				MCPToolDefinitionsPath: "/test/mcpfile.yaml",
+				MCPServerConfigPath:    "/test/mcpserver.yaml",
+			},
+			setupMocks:    func(mfs *mockFileSystem, mbp *mockBinaryProvider, mid *mockImageDownloader) {},
+			expectedError: "container images can't be built for darwin/arm64, build an archive instead",
+		},
+		{
+			name: "invalid image config",
+			buildOptions: BuildOptions{
+				MCPToolDefinitionsPath: "/test/mcpfile.yaml",
+				MCPServerConfigPath:    "/test/mcpserver.yaml",
+				ImageConfig:            ImageConfig{Env: []string{"LOG_LEVEL"}},
+			},
+			setupMocks:    func(mfs *mockFileSystem, mbp *mockBinaryProvider, mid *mockImageDownloader) {},
+			expectedError: `invalid image config: invalid environment variable "LOG_LEVEL": expected NAME=value`,
+		},
 	}
 
 	for _, tc := range tt {
@@ -444,6 +532,53 @@ func TestImageBuilder_Save(t *testing.T) {
 	}
 }
 
+func TestImageConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        ImageConfig
+		expectedError []string
+	}{
+		{
+			name: "valid config",
+			config: ImageConfig{
+				Files:        []ImageFile{{Source: "bin/jq", Destination: "/usr/local/bin/jq", Mode: 0o755}},
+				Env:          []string{"LOG_LEVEL=debug", "EMPTY="},
+				ExposedPorts: []string{"8080", "8443/tcp", "53/udp"},
+			},
+		},
+		{
+			name: "invalid files, environment variables and ports",
+			config: ImageConfig{
+				Files:        []ImageFile{{Source: "bin/jq", Destination: "usr/local/bin/jq"}, {Destination: "/etc/ca.pem"}},
+				Env:          []string{"LOG_LEVEL", "=debug"},
+				ExposedPorts: []string{"http", "0", "8080/sctp"},
+			},
+			expectedError: []string{
+				`invalid file "bin/jq": the destination must be an absolute path, got "usr/local/bin/jq"`,
+				`invalid file "/etc/ca.pem": the source is required`,
+				`invalid environment variable "LOG_LEVEL"`,
+				`invalid environment variable "=debug"`,
+				`invalid exposed port "http": the port must be a number between 1 and 65535`,
+				`invalid exposed port "0": the port must be a number between 1 and 65535`,
+				`invalid exposed port "8080/sctp": the protocol must be tcp or udp`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if len(tc.expectedError) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, expected := range tc.expectedError {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
 func TestBuildOptions_SetDefaults(t *testing.T) {
 	tt := []struct {
 		name           string
@@ -906,3 +1041,161 @@ func TestMultiArchBuildOptions_SetDefaults(t *testing.T) {
 		})
 	}
 }
+
+// fakeEngine writes a container engine CLI to a temporary directory running script, and returns its path.
+func fakeEngine(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "engine")
+	assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestDaemonImageSaver_Engine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake engine is a shell script")
+	}
+
+	tt := []struct {
+		name          string
+		script        string // run by the fake engine, whose directory is $dir
+		index         bool
+		expectedLoads []string // tags of the images loaded, in order
+		expectedError string
+	}{
+		{
+			name:          "image",
+			script:        `[ "$1" = load ] || exit 2; cat > "$dir/$(date +%s%N).tar"`,
+			expectedLoads: []string{"example.com/test/image:latest"},
+		},
+		{
+			name:   "image index",
+			script: `[ "$1" = load ] || exit 2; cat > "$dir/$(date +%s%N).tar"`,
+			index:  true,
+			expectedLoads: []string{
+				"example.com/test/image:latest-linux-amd64",
+				"example.com/test/image:latest-linux-arm64",
+				"example.com/test/image:latest",
+			},
+		},
+		{
+			name:          "load failure",
+			script:        `cat > /dev/null; echo "cannot connect to the engine" >&2; exit 1`,
+			expectedError: "load failed: exit status 1: cannot connect to the engine",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := fakeEngine(t, `dir=$(dirname "$0")/loaded; mkdir -p "$dir"; `+tc.script)
+			saver := &DaemonImageSaver{Engine: engine}
+			ctx := context.Background()
+
+			var err error
+			if tc.index {
+				idx := mutate.AppendManifests(empty.Index,
+					mutate.IndexAddendum{Add: empty.Image, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+					mutate.IndexAddendum{Add: empty.Image, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+				)
+				err = saver.SaveImageIndex(ctx, idx, "example.com/test/image:latest")
+			} else {
+				err = saver.SaveImage(ctx, empty.Image, "example.com/test/image:latest")
+			}
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			entries, err := os.ReadDir(filepath.Join(filepath.Dir(engine), "loaded"))
+			assert.NoError(t, err)
+			var loaded []string
+			for _, entry := range entries {
+				manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) {
+					return os.Open(filepath.Join(filepath.Dir(engine), "loaded", entry.Name()))
+				})
+				assert.NoError(t, err)
+				loaded = append(loaded, manifest[0].RepoTags...)
+			}
+			assert.Equal(t, tc.expectedLoads, loaded)
+		})
+	}
+}
+
+func TestImageBuilder_createFilesLayer(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("ca"), 0o600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "bin", "lib"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "jq"), []byte("jq"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "lib", "jq.so"), []byte("so"), 0o644))
+
+	type entry struct {
+		name string
+		mode int64
+		data string
+	}
+
+	tt := []struct {
+		name          string
+		files         []ImageFile
+		expected      []entry
+		expectedError string
+	}{
+		{
+			name:     "file with its permissions",
+			files:    []ImageFile{{Source: filepath.Join(dir, "ca.pem"), Destination: "/etc/pki/ca.pem"}},
+			expected: []entry{{name: "/etc/pki/ca.pem", mode: 0o600, data: "ca"}},
+		},
+		{
+			name:     "file with custom permissions",
+			files:    []ImageFile{{Source: filepath.Join(dir, "ca.pem"), Destination: "/etc/pki/ca.pem/", Mode: 0o644}},
+			expected: []entry{{name: "/etc/pki/ca.pem", mode: 0o644, data: "ca"}},
+		},
+		{
+			name:  "directory",
+			files: []ImageFile{{Source: filepath.Join(dir, "bin"), Destination: "/opt/tools"}},
+			expected: []entry{
+				{name: "/opt/tools", mode: 0o755},
+				{name: "/opt/tools/jq", mode: 0o755, data: "jq"},
+				{name: "/opt/tools/lib", mode: 0o755},
+				{name: "/opt/tools/lib/jq.so", mode: 0o644, data: "so"},
+			},
+		},
+		{
+			name:          "missing source",
+			files:         []ImageFile{{Source: filepath.Join(dir, "missing"), Destination: "/missing"}},
+			expectedError: "failed to stat",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &ImageBuilder{fs: &OSFileSystem{}}
+			layer, err := b.createFilesLayer(tc.files, &v1.Platform{OS: "linux", Architecture: "amd64"}, types.OCILayer)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			rc, err := layer.Uncompressed()
+			assert.NoError(t, err)
+			defer func() { _ = rc.Close() }()
+
+			var entries []entry
+			tr := tar.NewReader(rc)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				data, err := io.ReadAll(tr)
+				assert.NoError(t, err)
+				entries = append(entries, entry{name: header.Name, mode: header.Mode, data: string(data)})
+			}
+			assert.Equal(t, tc.expected, entries)
+		})
+	}
+}
@@ -4,11 +4,22 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"slices"
 
 	"github.com/genmcp/gen-mcp/pkg/utils/binarycache"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
+// SupportedPlatforms are the platforms server binaries are released for.
+var SupportedPlatforms = []string{
+	"linux/amd64",
+	"linux/arm64",
+	"darwin/amd64",
+	"darwin/arm64",
+	"windows/amd64",
+	"windows/arm64",
+}
+
 // DownloadBinaryProvider implements BinaryProvider by downloading from GitHub releases
 type DownloadBinaryProvider struct {
 	downloader *binarycache.BinaryDownloader
@@ -32,6 +43,10 @@ func NewDownloadBinaryProvider(version string, verbose bool) (*DownloadBinaryPro
 
 // ExtractServerBinary downloads and returns the server binary for the specified platform
 func (dp *DownloadBinaryProvider) ExtractServerBinary(platform *v1.Platform) ([]byte, fs.FileInfo, error) {
+	if p := platform.OS + "/" + platform.Architecture; !slices.Contains(SupportedPlatforms, p) {
+		return nil, nil, fmt.Errorf("no server binary is released for platform %s, supported platforms are %v", p, SupportedPlatforms)
+	}
+
 	binaryPath, err := dp.downloader.GetBinary(dp.version, platform.OS, platform.Architecture)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get binary for platform %s/%s: %w", platform.OS, platform.Architecture, err)
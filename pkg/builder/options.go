@@ -1,17 +1,81 @@
 package builder
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 const DefaultBaseImage = "registry.access.redhat.com/ubi9/ubi-minimal:latest"
 
+// ImageFile is a local file or directory added to the built image.
+type ImageFile struct {
+	Source      string      // path of the local file or directory
+	Destination string      // absolute path in the image, under which the contents of a directory are copied
+	Mode        fs.FileMode // permissions of the files in the image, the permissions of the local files if 0
+}
+
+// ImageConfig holds additions to the built image: files, and additions to its config and manifest.
+type ImageConfig struct {
+	Files        []ImageFile       // files and directories added to the image in a separate layer
+	Labels       map[string]string // labels of the image config, overriding the default OCI and MCP labels
+	Annotations  map[string]string // annotations of the image manifest, overriding the default ones
+	Env          []string          // environment variables as NAME=value, overriding the ones of the base image
+	ExposedPorts []string          // ports exposed by the image, as port or port/protocol (tcp or udp)
+	Args         []string          // arguments passed to the server entrypoint, replacing the command of the base image
+}
+
+// Validate checks that the files, the environment variables and the exposed ports are well formed.
+func (c *ImageConfig) Validate() error {
+	var errs []error
+	for _, file := range c.Files {
+		if file.Source == "" {
+			errs = append(errs, fmt.Errorf("invalid file %q: the source is required", file.Destination))
+		}
+		if !path.IsAbs(file.Destination) {
+			errs = append(errs, fmt.Errorf("invalid file %q: the destination must be an absolute path, got %q", file.Source, file.Destination))
+		}
+	}
+	for _, env := range c.Env {
+		if name, _, ok := strings.Cut(env, "="); !ok || name == "" {
+			errs = append(errs, fmt.Errorf("invalid environment variable %q: expected NAME=value", env))
+		}
+	}
+	for _, port := range c.ExposedPorts {
+		if _, err := exposedPort(port); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// exposedPort returns port in the port/protocol form of the image config.
+func exposedPort(port string) (string, error) {
+	number, protocol, ok := strings.Cut(port, "/")
+	if !ok {
+		protocol = "tcp"
+	}
+	if n, err := strconv.Atoi(number); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid exposed port %q: the port must be a number between 1 and 65535", port)
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return "", fmt.Errorf("invalid exposed port %q: the protocol must be tcp or udp", port)
+	}
+	return number + "/" + protocol, nil
+}
+
 type BuildOptions struct {
 	Platform               *v1.Platform // Target platform (linux/amd64, etc.)
 	BaseImage              string       // Base image reference
 	MCPToolDefinitionsPath string       // path to the MCP file
 	MCPServerConfigPath    string       // path to the MCP server configuration file
 	ImageTag               string       // output image tag
+	ImageConfig
 }
 
 func (o *BuildOptions) SetDefaults() {
@@ -29,6 +93,7 @@ type MultiArchBuildOptions struct {
 	MCPToolDefinitionsPath string         // path to the MCP file
 	MCPServerConfigPath    string         // path to the MCP server configuration file
 	ImageTag               string         // output image tag
+	ImageConfig
 }
 
 func (o *MultiArchBuildOptions) SetDefaults() {
@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
 	"strings"
 
 	"github.com/genmcp/gen-mcp/pkg/builder"
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
 	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/deploy"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
@@ -22,6 +28,18 @@ func init() {
 	buildCmd.Flags().StringVar(&platform, "platform", "", "platform to build for (e.g., linux/amd64). If not specified, builds multi-arch image for linux/amd64 and linux/arm64")
 	buildCmd.Flags().StringVar(&imageTag, "tag", "", "image tag for the registry")
 	buildCmd.Flags().BoolVar(&push, "push", false, "push the image to the registry (if false, store locally)")
+	buildCmd.Flags().BoolVar(&load, "load", false, "load the image into the local container engine instead of pushing it (the default unless --push is set)")
+	buildCmd.Flags().StringVar(&loadEngine, "engine", "", "container engine the image is loaded into with its load command, e.g. podman (default: the Docker Engine API at DOCKER_HOST)")
+	buildCmd.MarkFlagsMutuallyExclusive("push", "load")
+	buildCmd.MarkFlagsMutuallyExclusive("push", "engine")
+	buildCmd.Flags().StringArrayVar(&imageFiles, "add", nil, "local file or directory added to the image, as source:destination or source:destination:mode, e.g. bin/jq:/usr/local/bin/jq:0755 (repeatable)")
+	buildCmd.Flags().StringArrayVar(&imageLabels, "label", nil, "additional label of the image, as key=value (repeatable)")
+	buildCmd.Flags().StringArrayVar(&imageAnnotations, "annotation", nil, "additional annotation of the image manifest, as key=value (repeatable)")
+	buildCmd.Flags().StringArrayVarP(&imageEnv, "env", "e", nil, "environment variable of the image, as NAME=value (repeatable)")
+	buildCmd.Flags().StringArrayVar(&imageExposedPorts, "expose", nil, "port exposed by the image, as port or port/protocol (repeatable)")
+	buildCmd.Flags().StringArrayVar(&imageArgs, "arg", nil, "argument passed to the server entrypoint (repeatable)")
+	buildCmd.Flags().StringVarP(&archiveOutputDir, "output", "o", "", "write an archive with the server binary, the config files and a launcher to this directory instead of building an image, for the platform set with --platform or the local one")
+	buildCmd.Flags().StringVar(&archiveFormat, "archive-format", "", "format of the archive written with --output: zip or tar.gz (default: zip for windows, tar.gz otherwise)")
 	buildCmd.Flags().StringVar(&serverVersion, "server-version", "", "server binary version to download (default: latest release, or match CLI version if set)")
 	buildCmd.Flags().BoolVarP(&verbose, "verbose", "v", true, "show download progress")
 }
@@ -39,14 +57,24 @@ var (
 	platform               string
 	imageTag               string
 	push                   bool
+	load                   bool
+	loadEngine             string
 	serverVersion          string
 	verbose                bool
+	imageFiles             []string
+	archiveOutputDir       string
+	archiveFormat          string
+	imageLabels            []string
+	imageAnnotations       []string
+	imageEnv               []string
+	imageExposedPorts      []string
+	imageArgs              []string
 )
 
 func executeBuildCmd(cobraCmd *cobra.Command, args []string) {
 	ctx := cobraCmd.Context()
 
-	if imageTag == "" {
+	if imageTag == "" && archiveOutputDir == "" {
 		fmt.Printf("--tag is required to build an image\n")
 		os.Exit(1)
 	}
@@ -61,6 +89,51 @@ func executeBuildCmd(cobraCmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if archiveOutputDir != "" {
+		b, err := newImageBuilder(false)
+		if err != nil {
+			fmt.Printf("Failed to setup binary downloader: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if err := buildArchive(ctx, b); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	imageConfig, err := buildImageConfig()
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	b, err := newImageBuilder(push)
+	if err != nil {
+		fmt.Printf("Failed to setup binary downloader: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if !push {
+		b.SaveToDaemon(loadEngine)
+	}
+
+	if err := buildAndSaveImage(ctx, b, imageBuildOptions{
+		platform:               platform,
+		baseImage:              baseImage,
+		mcpToolDefinitionsPath: mcpToolDefinitionsPath,
+		mcpServerConfigPath:    mcpServerConfigPath,
+		imageTag:               imageTag,
+		push:                   push,
+		imageConfig:            imageConfig,
+	}); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// newImageBuilder creates a builder using the server binaries of the version set with --server-version,
+// or of the version of the CLI.
+func newImageBuilder(push bool) (*builder.ImageBuilder, error) {
 	// Determine which server version to use
 	version := serverVersion
 	if version == "" {
@@ -77,55 +150,180 @@ func executeBuildCmd(cobraCmd *cobra.Command, args []string) {
 		fmt.Printf("Using specified server version: %s\n", version)
 	}
 
-	// Create builder
-	b, err := builder.New(push, version, verbose)
+	return builder.New(push, version, verbose)
+}
+
+type imageBuildOptions struct {
+	platform               string // builds a multi-arch image for linux/amd64 and linux/arm64 if empty
+	baseImage              string
+	mcpToolDefinitionsPath string
+	mcpServerConfigPath    string
+	imageTag               string
+	push                   bool // pushes the image to the registry instead of saving it to the local container engine
+	imageConfig            builder.ImageConfig
+}
+
+// buildArchive writes the archive of the server for the platform set with --platform, or the local one, to
+// the --output directory.
+func buildArchive(ctx context.Context, b *builder.ImageBuilder) error {
+	p := platform
+	if p == "" {
+		p = goruntime.GOOS + "/" + goruntime.GOARCH
+	}
+	parsedPlatform, err := v1.ParsePlatform(p)
 	if err != nil {
-		fmt.Printf("Failed to setup binary downloader: %s\n", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to parse platform '%s': %w", p, err)
+	}
+
+	opts := builder.ArchiveOptions{
+		Platform:               parsedPlatform,
+		MCPToolDefinitionsPath: mcpToolDefinitionsPath,
+		MCPServerConfigPath:    mcpServerConfigPath,
+		Format:                 archiveFormat,
+	}
+	opts.SetDefaults()
+
+	defs, err := definitions.ParseMCPFile(mcpToolDefinitionsPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(archiveOutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	path := filepath.Join(archiveOutputDir, fmt.Sprintf("%s-%s-%s.%s", deploy.ResourceName(defs.Name), parsedPlatform.OS, parsedPlatform.Architecture, opts.Format))
+
+	fmt.Printf("building archive for %s...\n", p)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := b.BuildArchive(ctx, opts, f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to build archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	fmt.Printf("successfully wrote %s\n", path)
+	return nil
+}
+
+// buildImageConfig returns the additions to the image config set with the flags of the build command.
+func buildImageConfig() (builder.ImageConfig, error) {
+	labels, err := parseKeyValues("label", imageLabels)
+	if err != nil {
+		return builder.ImageConfig{}, err
+	}
+	annotations, err := parseKeyValues("annotation", imageAnnotations)
+	if err != nil {
+		return builder.ImageConfig{}, err
+	}
+
+	files, err := parseImageFiles(imageFiles)
+	if err != nil {
+		return builder.ImageConfig{}, err
 	}
 
+	config := builder.ImageConfig{
+		Files:        files,
+		Labels:       labels,
+		Annotations:  annotations,
+		Env:          imageEnv,
+		ExposedPorts: imageExposedPorts,
+		Args:         imageArgs,
+	}
+	if err := config.Validate(); err != nil {
+		return builder.ImageConfig{}, fmt.Errorf("invalid image config: %w", err)
+	}
+	return config, nil
+}
+
+// parseImageFiles parses the source:destination[:mode] values of the --add flag. The source may contain colons,
+// e.g. a Windows path, as the destination and the mode are split from the end.
+func parseImageFiles(values []string) ([]builder.ImageFile, error) {
+	var files []builder.ImageFile
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		var mode uint64
+		if len(parts) >= 3 {
+			if m, err := strconv.ParseUint(parts[len(parts)-1], 8, 32); err == nil {
+				mode = m
+				parts = parts[:len(parts)-1]
+			}
+		}
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid --add %q: expected source:destination or source:destination:mode", v)
+		}
+
+		files = append(files, builder.ImageFile{
+			Source:      strings.Join(parts[:len(parts)-1], ":"),
+			Destination: parts[len(parts)-1],
+			Mode:        fs.FileMode(mode),
+		})
+	}
+	return files, nil
+}
+
+// parseKeyValues parses the key=value values of a flag.
+func parseKeyValues(flag string, values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s %q: expected key=value", flag, v)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// buildAndSaveImage builds the image of the server and pushes it or saves it locally, reporting progress.
+func buildAndSaveImage(ctx context.Context, b *builder.ImageBuilder, o imageBuildOptions) error {
 	// Single platform build if --platform is specified
-	if platform != "" {
-		parsedPlatform, err := v1.ParsePlatform(platform)
+	if o.platform != "" {
+		parsedPlatform, err := v1.ParsePlatform(o.platform)
 		if err != nil {
-			fmt.Printf("failed to parse platform '%s': %s\n", platform, err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to parse platform '%s': %w", o.platform, err)
 		}
 
-		fmt.Printf("building image for %s...\n", platform)
+		fmt.Printf("building image for %s...\n", o.platform)
 		opts := builder.BuildOptions{
 			Platform:               parsedPlatform,
-			BaseImage:              baseImage,
-			MCPToolDefinitionsPath: mcpToolDefinitionsPath,
-			MCPServerConfigPath:    mcpServerConfigPath,
-			ImageTag:               imageTag,
+			BaseImage:              o.baseImage,
+			MCPToolDefinitionsPath: o.mcpToolDefinitionsPath,
+			MCPServerConfigPath:    o.mcpServerConfigPath,
+			ImageTag:               o.imageTag,
+			ImageConfig:            o.imageConfig,
 		}
 
 		img, err := b.Build(ctx, opts)
 		if err != nil {
-			fmt.Printf("failed to build image: %s\n", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to build image: %w", err)
 		}
 
-		if push {
-			fmt.Printf("successfully built image!\npushing image to %s...\n", imageTag)
+		if o.push {
+			fmt.Printf("successfully built image!\npushing image to %s...\n", o.imageTag)
 		} else {
-			fmt.Printf("successfully built image!\nsaving image to local container engine as %s...\n", imageTag)
+			fmt.Printf("successfully built image!\nsaving image to local container engine as %s...\n", o.imageTag)
 		}
 
-		if err := b.Save(ctx, img, imageTag); err != nil {
-			if push {
-				fmt.Printf("failed to push image - ensure you are logged in: %s\n", err.Error())
-			} else {
-				fmt.Printf("failed to save image to local container engine: %s\n", err.Error())
+		if err := b.Save(ctx, img, o.imageTag); err != nil {
+			if o.push {
+				return fmt.Errorf("failed to push image - ensure you are logged in: %w", err)
 			}
-			os.Exit(1)
+			return fmt.Errorf("failed to save image to local container engine: %w", err)
 		}
 
-		if push {
-			fmt.Printf("successfully pushed %s\n", imageTag)
+		if o.push {
+			fmt.Printf("successfully pushed %s\n", o.imageTag)
 		} else {
-			fmt.Printf("successfully saved %s to local container engine\n", imageTag)
+			fmt.Printf("successfully saved %s to local container engine\n", o.imageTag)
 		}
 	} else {
 		// Multi-arch build (default when --platform not specified)
@@ -135,8 +333,7 @@ func executeBuildCmd(cobraCmd *cobra.Command, args []string) {
 		for _, p := range platforms {
 			parsed, err := v1.ParsePlatform(p)
 			if err != nil {
-				fmt.Printf("failed to parse platform '%s': %s\n", p, err.Error())
-				os.Exit(1)
+				return fmt.Errorf("failed to parse platform '%s': %w", p, err)
 			}
 			parsedPlatforms = append(parsedPlatforms, parsed)
 		}
@@ -144,46 +341,46 @@ func executeBuildCmd(cobraCmd *cobra.Command, args []string) {
 		fmt.Printf("building multi-arch image for platforms: %v...\n", platforms)
 		opts := builder.MultiArchBuildOptions{
 			Platforms:              parsedPlatforms,
-			BaseImage:              baseImage,
-			MCPToolDefinitionsPath: mcpToolDefinitionsPath,
-			MCPServerConfigPath:    mcpServerConfigPath,
-			ImageTag:               imageTag,
+			BaseImage:              o.baseImage,
+			MCPToolDefinitionsPath: o.mcpToolDefinitionsPath,
+			MCPServerConfigPath:    o.mcpServerConfigPath,
+			ImageTag:               o.imageTag,
+			ImageConfig:            o.imageConfig,
 		}
 
 		idx, err := b.BuildMultiArch(ctx, opts)
 		if err != nil {
-			fmt.Printf("failed to build multi-arch image: %s\n", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to build multi-arch image: %w", err)
 		}
 
-		if push {
-			fmt.Printf("successfully built multi-arch image!\npushing image index to %s...\n", imageTag)
+		if o.push {
+			fmt.Printf("successfully built multi-arch image!\npushing image index to %s...\n", o.imageTag)
 		} else {
 			fmt.Printf("successfully built multi-arch image!\nsaving images to local container engine...\n")
 			fmt.Printf("note: local daemon doesn't support manifest lists, saving each platform separately\n")
 		}
 
-		if err := b.SaveIndex(ctx, idx, imageTag); err != nil {
-			if push {
-				fmt.Printf("failed to push image index - ensure you are logged in: %s\n", err.Error())
-			} else {
-				fmt.Printf("failed to save images to local container engine: %s\n", err.Error())
+		if err := b.SaveIndex(ctx, idx, o.imageTag); err != nil {
+			if o.push {
+				return fmt.Errorf("failed to push image index - ensure you are logged in: %w", err)
 			}
-			os.Exit(1)
+			return fmt.Errorf("failed to save images to local container engine: %w", err)
 		}
 
-		if push {
-			fmt.Printf("successfully pushed multi-arch image %s\n", imageTag)
+		if o.push {
+			fmt.Printf("successfully pushed multi-arch image %s\n", o.imageTag)
 		} else {
 			fmt.Printf("successfully saved multi-arch images to local container engine\n")
-			fmt.Printf("available tags: %s", imageTag)
+			fmt.Printf("available tags: %s", o.imageTag)
 			for _, p := range platforms {
 				tagSuffix := strings.ReplaceAll(p, "/", "-")
-				fmt.Printf(", %s-%s", imageTag, tagSuffix)
+				fmt.Printf(", %s-%s", o.imageTag, tagSuffix)
 			}
 			fmt.Printf("\n")
 		}
 	}
+
+	return nil
 }
 
 // validateMCPToolDefinitionsFile validates an MCP file
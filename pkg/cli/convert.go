@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,9 @@ import (
 	"os"
 
 	"github.com/genmcp/gen-mcp/pkg/cli/utils"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/converter/grpc"
 	"github.com/genmcp/gen-mcp/pkg/converter/openapi"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
@@ -18,20 +22,40 @@ func init() {
 	convertCmd.Flags().StringVarP(&toolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to write the MCP file to")
 	convertCmd.Flags().StringVarP(&serverConfigPath, "server-config", "s", "mcpserver.yaml", "the path to write the server config file to")
 	convertCmd.Flags().StringVarP(&host, "host", "H", "", "the base host for the API, if different than in the OpenAPI spec")
+	convertCmd.Flags().StringVarP(&convertRulesPath, "rules", "r", "", "the path to a YAML file of rules selecting, renaming and simplifying the converted tools")
+	convertCmd.Flags().StringVar(&fromGrpc, "from-grpc", "", "the host:port of a gRPC server to convert with server reflection, instead of an OpenAPI spec")
+	convertCmd.Flags().BoolVar(&grpcTLS, "grpc-tls", false, "connect to the gRPC server with TLS")
 }
 
 var toolDefinitionsPath string
 var serverConfigPath string
 var host string
+var convertRulesPath string
+var fromGrpc string
+var grpcTLS bool
 
 var convertCmd = &cobra.Command{
 	Use:   "convert",
-	Short: "Convert an OpenAPI v2/v3 spec into MCP tool definitions and server config files",
-	Args:  cobra.ExactArgs(1),
+	Short: "Convert an OpenAPI v2/v3 spec or a gRPC server into MCP tool definitions and server config files",
+	Args:  cobra.MaximumNArgs(1),
 	Run:   executeConvertCmd,
 }
 
 func executeConvertCmd(_ *cobra.Command, args []string) {
+	if fromGrpc != "" {
+		if len(args) > 0 {
+			fmt.Printf("an OpenAPI spec can't be converted along with --from-grpc\n")
+			return
+		}
+		convertGrpcServer()
+		return
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("an OpenAPI spec or --from-grpc is required\n")
+		return
+	}
+
 	openApiLocation := args[0]
 
 	var openApiBytes []byte
@@ -51,7 +75,16 @@ func executeConvertCmd(_ *cobra.Command, args []string) {
 		}
 	}
 
-	convertedFiles, err := openapi.DocumentToMcpFile(openApiBytes, host)
+	var rules *openapi.Rules
+	if convertRulesPath != "" {
+		rules, err = openapi.ParseRulesFile(convertRulesPath)
+		if err != nil {
+			fmt.Printf("could not load conversion rules at path %s: %s\n", convertRulesPath, err.Error())
+			return
+		}
+	}
+
+	convertedFiles, err := openapi.DocumentToMcpFileWithRules(openApiBytes, host, rules)
 	if err != nil {
 		fmt.Printf("encountered errors while converting openapi document to GenMCP config files: %s\n", err.Error())
 	}
@@ -68,8 +101,32 @@ func executeConvertCmd(_ *cobra.Command, args []string) {
 	}
 	fmt.Printf("INFO    Converted %d endpoints to MCP tools\n", numTools)
 
+	writeConvertedFiles(convertedFiles.ToolDefinitions, convertedFiles.ServerConfig)
+}
+
+// convertGrpcServer converts the services of the gRPC server at the --from-grpc address.
+func convertGrpcServer() {
+	fmt.Printf("INFO    Discovering services of %s with server reflection\n", fromGrpc)
+
+	convertedFiles, err := grpc.ServerToMcpFile(context.Background(), fromGrpc, grpc.Options{TLS: grpcTLS})
+	if err != nil {
+		fmt.Printf("encountered errors while converting gRPC services to GenMCP config files: %s\n", err.Error())
+	}
+
+	if convertedFiles == nil {
+		fmt.Printf("conversion failed, no files generated\n")
+		return
+	}
+
+	fmt.Printf("INFO    Converted %d methods to MCP tools\n", len(convertedFiles.ToolDefinitions.Tools))
+
+	writeConvertedFiles(convertedFiles.ToolDefinitions, convertedFiles.ServerConfig)
+}
+
+// writeConvertedFiles writes the converted files to the paths of the --file and --server-config flags.
+func writeConvertedFiles(toolDefinitions *definitions.MCPToolDefinitionsFile, serverConfig *serverconfig.MCPServerConfigFile) {
 	// Write MCP file
-	toolDefBytes, err := yaml.Marshal(convertedFiles.ToolDefinitions)
+	toolDefBytes, err := yaml.Marshal(toolDefinitions)
 	if err != nil {
 		fmt.Printf("could not marshal MCP file: %s\n", err.Error())
 		return
@@ -86,7 +143,7 @@ func executeConvertCmd(_ *cobra.Command, args []string) {
 	fmt.Printf("INFO    Created %s\n", toolDefinitionsPath)
 
 	// Write server config file
-	serverConfigBytes, err := yaml.Marshal(convertedFiles.ServerConfig)
+	serverConfigBytes, err := yaml.Marshal(serverConfig)
 	if err != nil {
 		fmt.Printf("could not marshal server config file: %s\n", err.Error())
 		return
@@ -101,7 +158,6 @@ func executeConvertCmd(_ *cobra.Command, args []string) {
 	}
 
 	fmt.Printf("INFO    Created %s\n", serverConfigPath)
-
 }
 
 func getOpenApiSpec(url string) ([]byte, error) {
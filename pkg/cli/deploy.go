@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/deploy"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.Flags().StringVarP(&deployToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	deployCmd.Flags().StringVarP(&deployServerConfigPath, "server-config", "s", "mcpserver.yaml", "the path to the server config file")
+	deployCmd.Flags().StringVar(&deployImage, "image", "", "image of the server, pushed to its registry before deploying unless --skip-build is set")
+	deployCmd.Flags().StringVarP(&deployNamespace, "namespace", "n", "", "namespace to deploy to (default: the namespace of the current kubectl context)")
+	deployCmd.Flags().StringVar(&deployName, "name", "", "name of the Kubernetes resources (default: the name of the MCP server)")
+	deployCmd.Flags().IntVar(&deployReplicas, "replicas", 1, "number of replicas of the server")
+	deployCmd.Flags().StringVar(&deployIngressHost, "ingress-host", "", "create an Ingress routing this host to the server")
+	deployCmd.Flags().StringVar(&deployIngressClass, "ingress-class", "", "class of the Ingress (default: the default class of the cluster)")
+	deployCmd.Flags().BoolVar(&deployRoute, "route", false, "create an OpenShift Route to the server")
+	deployCmd.Flags().BoolVar(&deploySkipBuild, "skip-build", false, "deploy the image as is, without building and pushing it")
+	deployCmd.Flags().BoolVar(&deployDryRun, "dry-run", false, "print the manifests without building the image or applying them")
+	deployCmd.Flags().StringVar(&deployKubectl, "kubectl", "kubectl", "kubectl binary used to apply the manifests, e.g. oc")
+	deployCmd.Flags().StringVar(&platform, "platform", "", "platform to build for (e.g., linux/amd64). If not specified, builds multi-arch image for linux/amd64 and linux/arm64")
+	deployCmd.Flags().StringVar(&baseImage, "base-image", "", "base image to build the genmcp image on top of")
+	deployCmd.Flags().StringVar(&serverVersion, "server-version", "", "server binary version to download (default: latest release, or match CLI version if set)")
+	deployCmd.Flags().BoolVarP(&verbose, "verbose", "v", true, "show download progress")
+}
+
+var (
+	deployToolDefinitionsPath string
+	deployServerConfigPath    string
+	deployImage               string
+	deployNamespace           string
+	deployName                string
+	deployReplicas            int
+	deployIngressHost         string
+	deployIngressClass        string
+	deployRoute               bool
+	deploySkipBuild           bool
+	deployDryRun              bool
+	deployKubectl             string
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Build the image of a MCP server and deploy it to Kubernetes",
+	Long: `Build the image of a MCP server, push it, and deploy it to the cluster of the current kubectl context.
+
+The MCP file and the server config file are stored in a ConfigMap mounted by a Deployment running the
+image, which is exposed by a Service, and optionally by an Ingress (--ingress-host) or an OpenShift
+Route (--route). The manifests are applied with kubectl apply, so running the command again updates
+the deployment, and changing the config files rolls out new pods.`,
+	Args: cobra.NoArgs,
+	Run:  executeDeployCmd,
+}
+
+func executeDeployCmd(cobraCmd *cobra.Command, _ []string) {
+	ctx := cobraCmd.Context()
+
+	if deployImage == "" {
+		fmt.Printf("--image is required to deploy a server\n")
+		os.Exit(1)
+	}
+
+	// Validate GenMCP config files before building
+	if err := validateMCPToolDefinitionsFile(deployToolDefinitionsPath); err != nil {
+		fmt.Printf("invalid MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if err := validateMCPServerConfigFile(deployServerConfigPath); err != nil {
+		fmt.Printf("invalid server config file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	manifests, err := renderDeployManifests()
+	if err != nil {
+		fmt.Printf("failed to render manifests: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if deployDryRun {
+		fmt.Print(string(manifests))
+		return
+	}
+
+	if !deploySkipBuild {
+		b, err := newImageBuilder(true)
+		if err != nil {
+			fmt.Printf("Failed to setup binary downloader: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if err := buildAndSaveImage(ctx, b, imageBuildOptions{
+			platform:               platform,
+			baseImage:              baseImage,
+			mcpToolDefinitionsPath: deployToolDefinitionsPath,
+			mcpServerConfigPath:    deployServerConfigPath,
+			imageTag:               deployImage,
+			push:                   true,
+		}); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("applying manifests with %s...\n", deployKubectl)
+	if err := deploy.Apply(ctx, deployKubectl, manifests, os.Stdout); err != nil {
+		fmt.Printf("failed to deploy: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("successfully deployed %s\n", deployImage)
+}
+
+func renderDeployManifests() ([]byte, error) {
+	mcpFile, err := os.ReadFile(deployToolDefinitionsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP file: %w", err)
+	}
+	serverConfig, err := os.ReadFile(deployServerConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server config file: %w", err)
+	}
+
+	defs, err := definitions.ParseMCPFile(deployToolDefinitionsPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := serverconfig.ParseMCPFile(deployServerConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	config.ApplyDefaults()
+
+	opts := deploy.Options{
+		Name:         deployName,
+		Namespace:    deployNamespace,
+		Image:        deployImage,
+		Replicas:     deployReplicas,
+		MCPFile:      mcpFile,
+		ServerConfig: serverConfig,
+		IngressHost:  deployIngressHost,
+		IngressClass: deployIngressClass,
+		Route:        deployRoute,
+	}
+	if opts.Name == "" {
+		opts.Name = deploy.ResourceName(defs.Name)
+	}
+	if err := opts.ApplyServerConfig(config.Runtime); err != nil {
+		return nil, err
+	}
+
+	return deploy.Render(opts)
+}
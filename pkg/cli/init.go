@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/cli/utils"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/scaffold"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	defaults := scaffold.DefaultOptions()
+	initCmd.Flags().StringVarP(&initToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to write the MCP file to")
+	initCmd.Flags().StringVarP(&initServerConfigPath, "server-config", "s", "mcpserver.yaml", "the path to write the server config file to")
+	initCmd.Flags().StringVar(&initOptions.Name, "name", defaults.Name, "the name of the MCP server")
+	initCmd.Flags().StringVar(&initOptions.Version, "server-version", defaults.Version, "the version of the MCP server")
+	initCmd.Flags().StringVar(&initOptions.TransportProtocol, "transport", defaults.TransportProtocol, "the transport protocol of the server, one of streamablehttp or stdio")
+	initCmd.Flags().IntVar(&initOptions.Port, "port", defaults.Port, "the port of the streamable HTTP server")
+	initCmd.Flags().StringSliceVar(&initOptions.InvocationTypes, "invocation-types", defaults.InvocationTypes, "the invocation types to generate an example tool for")
+	initCmd.Flags().BoolVarP(&initNonInteractive, "yes", "y", false, "do not prompt, use the flag values or their defaults")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite existing files")
+}
+
+var initToolDefinitionsPath string
+var initServerConfigPath string
+var initOptions scaffold.Options
+var initNonInteractive bool
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the MCP file and server config file of a new MCP server",
+	Long: `Create the MCP file and server config file of a new MCP server, with an example tool for each
+selected invocation type.
+
+Values that are not set with flags are asked for interactively, unless --yes is set.
+The generated files pass 'genmcp validate' and can be run with 'genmcp run' right away.`,
+	Args: cobra.NoArgs,
+	Run:  executeInitCmd,
+}
+
+func executeInitCmd(cmd *cobra.Command, _ []string) {
+	if !initForce {
+		for _, path := range []string{initToolDefinitionsPath, initServerConfigPath} {
+			if _, err := os.Stat(path); err == nil {
+				fmt.Printf("file already exists at path %s, use --force to overwrite it\n", path)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if !initNonInteractive {
+		promptInitOptions(cmd, bufio.NewReader(os.Stdin), os.Stdout)
+	}
+
+	files, err := scaffold.Generate(initOptions)
+	if err != nil {
+		fmt.Printf("invalid options: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	toolDefBytes, err := yaml.Marshal(files.ToolDefinitions)
+	if err != nil {
+		fmt.Printf("could not marshal MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	toolDefBytes = utils.AppendToolDefinitionsSchemaHeader(toolDefBytes)
+
+	if err := os.WriteFile(initToolDefinitionsPath, toolDefBytes, 0644); err != nil {
+		fmt.Printf("could not write MCP file to path %s: %s\n", initToolDefinitionsPath, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("INFO    Created %s\n", initToolDefinitionsPath)
+
+	serverConfigBytes, err := yaml.Marshal(files.ServerConfig)
+	if err != nil {
+		fmt.Printf("could not marshal server config file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	serverConfigBytes = utils.AppendServerConfigSchemaHeader(serverConfigBytes)
+
+	if err := os.WriteFile(initServerConfigPath, serverConfigBytes, 0644); err != nil {
+		fmt.Printf("could not write server config file to path %s: %s\n", initServerConfigPath, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("INFO    Created %s\n", initServerConfigPath)
+	fmt.Printf("\nStart the server with: genmcp run -f %s -s %s\n", initToolDefinitionsPath, initServerConfigPath)
+}
+
+// promptInitOptions asks for the options that were not set with flags, keeping their current value
+// when the answer is empty.
+func promptInitOptions(cmd *cobra.Command, in *bufio.Reader, out io.Writer) {
+	if !cmd.Flags().Changed("name") {
+		initOptions.Name = prompt(in, out, "Server name", initOptions.Name)
+	}
+
+	if !cmd.Flags().Changed("server-version") {
+		initOptions.Version = prompt(in, out, "Server version", initOptions.Version)
+	}
+
+	if !cmd.Flags().Changed("transport") {
+		initOptions.TransportProtocol = prompt(in, out, "Transport protocol (streamablehttp, stdio)", initOptions.TransportProtocol)
+	}
+
+	if !cmd.Flags().Changed("port") && initOptions.TransportProtocol != serverconfig.TransportProtocolStdio {
+		for {
+			answer := prompt(in, out, "Port", strconv.Itoa(initOptions.Port))
+			port, err := strconv.Atoi(answer)
+			if err == nil {
+				initOptions.Port = port
+				break
+			}
+			fmt.Fprintf(out, "invalid port '%s', must be a number\n", answer)
+		}
+	}
+
+	if !cmd.Flags().Changed("invocation-types") {
+		question := fmt.Sprintf("Example tools to generate (%s)", strings.Join(scaffold.InvocationTypes, ", "))
+		answer := prompt(in, out, question, strings.Join(initOptions.InvocationTypes, ","))
+		initOptions.InvocationTypes = nil
+		for _, t := range strings.Split(answer, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				initOptions.InvocationTypes = append(initOptions.InvocationTypes, t)
+			}
+		}
+	}
+}
+
+// prompt asks question, returning the trimmed answer, or defaultValue if the answer is empty or the input
+// is closed.
+func prompt(in *bufio.Reader, out io.Writer, question, defaultValue string) string {
+	fmt.Fprintf(out, "%s [%s]: ", question, defaultValue)
+
+	answer, err := in.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(out)
+		return defaultValue
+	}
+
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue
+	}
+	return answer
+}
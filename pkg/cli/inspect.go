@@ -79,7 +79,8 @@ type SecurityInfo struct {
 
 // TLSInfo contains TLS status
 type TLSInfo struct {
-	Enabled bool `json:"enabled"`
+	Enabled    bool `json:"enabled"`
+	ClientAuth bool `json:"clientAuth,omitempty"`
 }
 
 // AuthInfo contains auth status
@@ -87,6 +88,9 @@ type AuthInfo struct {
 	Enabled              bool     `json:"enabled"`
 	JWKSURI              string   `json:"jwksUri,omitempty"`
 	AuthorizationServers []string `json:"authorizationServers,omitempty"`
+	Issuers              []string `json:"issuers,omitempty"`
+	BearerTokens         bool     `json:"bearerTokens,omitempty"`
+	BasicAuth            bool     `json:"basicAuth,omitempty"`
 }
 
 // ClientTLSInfo contains client TLS status
@@ -335,7 +339,7 @@ func buildSecurityInfo(serverConfig *serverconfig.MCPServerConfigFile) SecurityI
 		serverConfig.Runtime.StreamableHTTPConfig.TLS != nil {
 		tls := serverConfig.Runtime.StreamableHTTPConfig.TLS
 		if tls.CertFile != "" || tls.KeyFile != "" {
-			security.TLS = &TLSInfo{Enabled: true}
+			security.TLS = &TLSInfo{Enabled: true, ClientAuth: tls.ClientAuth != nil}
 		}
 	}
 
@@ -343,11 +347,15 @@ func buildSecurityInfo(serverConfig *serverconfig.MCPServerConfigFile) SecurityI
 	if serverConfig.Runtime.StreamableHTTPConfig != nil &&
 		serverConfig.Runtime.StreamableHTTPConfig.Auth != nil {
 		auth := serverConfig.Runtime.StreamableHTTPConfig.Auth
-		if auth.JWKSURI != "" || len(auth.AuthorizationServers) > 0 {
+		if auth.JWKSURI != "" || len(auth.AuthorizationServers) > 0 || len(auth.Issuers) > 0 || auth.HasStaticCredentials() {
+			// only report which static credentials are configured, never their values
 			security.Auth = &AuthInfo{
 				Enabled:              true,
 				JWKSURI:              auth.JWKSURI,
 				AuthorizationServers: auth.AuthorizationServers,
+				Issuers:              issuerNames(auth.Issuers),
+				BearerTokens:         len(auth.BearerTokens) > 0,
+				BasicAuth:            auth.BasicAuth != nil && len(auth.BasicAuth.Users) > 0,
 			}
 		}
 	}
@@ -439,14 +447,16 @@ func printHumanReadableOutput(output InspectOutput) {
 
 	// Security section
 	fmt.Println("\nSecurity:")
-	if output.Security.TLS != nil && output.Security.TLS.Enabled {
+	if output.Security.TLS != nil && output.Security.TLS.Enabled && output.Security.TLS.ClientAuth {
+		fmt.Println("  TLS: enabled (cert configured, client certificates required)")
+	} else if output.Security.TLS != nil && output.Security.TLS.Enabled {
 		fmt.Println("  TLS: enabled (cert configured)")
 	} else {
 		fmt.Println("  TLS: disabled")
 	}
 
 	if output.Security.Auth != nil && output.Security.Auth.Enabled {
-		fmt.Println("  Auth: enabled (OAuth 2.0)")
+		fmt.Printf("  Auth: enabled (%s)\n", strings.Join(authMethods(output.Security.Auth), ", "))
 	} else {
 		fmt.Println("  Auth: disabled")
 	}
@@ -516,3 +526,29 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// authMethods returns the names of the authentication methods enabled in auth.
+func authMethods(auth *AuthInfo) []string {
+	var methods []string
+	if auth.JWKSURI != "" || len(auth.AuthorizationServers) > 0 || len(auth.Issuers) > 0 {
+		methods = append(methods, "OAuth 2.0")
+	}
+	if auth.BearerTokens {
+		methods = append(methods, "static bearer tokens")
+	}
+	if auth.BasicAuth {
+		methods = append(methods, "basic auth")
+	}
+	return methods
+}
+
+// issuerNames returns the URLs of issuers.
+func issuerNames(issuers []*serverconfig.IssuerConfig) []string {
+	var names []string
+	for _, issuer := range issuers {
+		if issuer != nil {
+			names = append(names, issuer.Issuer)
+		}
+	}
+	return names
+}
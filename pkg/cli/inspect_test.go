@@ -101,6 +101,27 @@ func TestBuildSecurityInfo(t *testing.T) {
 				TLS: &TLSInfo{Enabled: true},
 			},
 		},
+		"tls enabled with client auth": {
+			serverConfig: &serverconfig.MCPServerConfigFile{
+				MCPServerConfig: serverconfig.MCPServerConfig{
+					Runtime: &serverconfig.ServerRuntime{
+						TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+						StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+							TLS: &serverconfig.TLSConfig{
+								CertFile: "/path/to/cert.pem",
+								KeyFile:  "/path/to/key.pem",
+								ClientAuth: &serverconfig.ClientAuthConfig{
+									CACertFiles: []string{"/path/to/client-ca.pem"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: SecurityInfo{
+				TLS: &TLSInfo{Enabled: true, ClientAuth: true},
+			},
+		},
 		"auth enabled with jwks": {
 			serverConfig: &serverconfig.MCPServerConfigFile{
 				MCPServerConfig: serverconfig.MCPServerConfig{
@@ -123,6 +144,30 @@ func TestBuildSecurityInfo(t *testing.T) {
 				},
 			},
 		},
+		"auth enabled with static credentials": {
+			serverConfig: &serverconfig.MCPServerConfigFile{
+				MCPServerConfig: serverconfig.MCPServerConfig{
+					Runtime: &serverconfig.ServerRuntime{
+						TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+						StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+							Auth: &serverconfig.AuthConfig{
+								BearerTokens: []string{"secret"},
+								BasicAuth: &serverconfig.BasicAuthConfig{
+									Users: map[string]string{"admin": "password"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: SecurityInfo{
+				Auth: &AuthInfo{
+					Enabled:      true,
+					BearerTokens: true,
+					BasicAuth:    true,
+				},
+			},
+		},
 		"client tls with custom ca": {
 			serverConfig: &serverconfig.MCPServerConfigFile{
 				MCPServerConfig: serverconfig.MCPServerConfig{
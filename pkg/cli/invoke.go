@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invoke"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(invokeCmd)
+	invokeCmd.Flags().StringVarP(&invokeToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	invokeCmd.Flags().StringVar(&invokeToolName, "tool", "", "the name of the tool to invoke")
+	invokeCmd.Flags().StringVar(&invokePromptName, "prompt", "", "the name of the prompt to get")
+	invokeCmd.Flags().StringVar(&invokeResourceURI, "resource", "", "the URI of the resource to read, from a resource or a resource template")
+	invokeCmd.Flags().StringVar(&invokeArgs, "args", "{}", "the arguments of the tool or prompt, as a JSON object")
+	invokeCmd.Flags().BoolVar(&invokeDryRun, "dry-run", false, "print the request the tool would make instead of executing it")
+	invokeCmd.Flags().BoolVar(&invokeJSONOutput, "json", false, "output the full MCP result in JSON format")
+	invokeCmd.MarkFlagsMutuallyExclusive("tool", "prompt", "resource")
+	invokeCmd.MarkFlagsOneRequired("tool", "prompt", "resource")
+}
+
+var invokeToolDefinitionsPath string
+var invokeToolName string
+var invokePromptName string
+var invokeResourceURI string
+var invokeArgs string
+var invokeDryRun bool
+var invokeJSONOutput bool
+
+var invokeCmd = &cobra.Command{
+	Use:   "invoke",
+	Short: "Invoke a tool, prompt or resource of an MCP file locally",
+	Long: `Invoke a tool, prompt or resource of an MCP file locally, without starting a server or wiring up
+an MCP client, and print the MCP result.
+
+Exactly one of --tool, --prompt or --resource must be set. Tools and prompts take their arguments
+from --args, resources and resource templates take them from the URI.
+
+With --dry-run, the arguments are parsed and validated against the input schema of the tool, and
+the request the tool would make is printed with all of its templates rendered, without executing it:
+the HTTP method, URL, headers and body, the CLI command, or the SQL query and its parameters.`,
+	Args: cobra.NoArgs,
+	Run:  executeInvokeCmd,
+}
+
+func executeInvokeCmd(_ *cobra.Command, _ []string) {
+	if invokeDryRun && invokeToolName == "" {
+		fmt.Println("--dry-run is only supported for tools")
+		os.Exit(1)
+	}
+
+	if !json.Valid([]byte(invokeArgs)) {
+		fmt.Printf("invalid --args, must be a JSON object: %s\n", invokeArgs)
+		os.Exit(1)
+	}
+
+	mcpFile, err := definitions.ParseMCPFile(invokeToolDefinitionsPath)
+	if err != nil {
+		fmt.Printf("invalid MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+	defs := &mcpFile.MCPToolDefinitions
+
+	ctx := context.Background()
+
+	switch {
+	case invokeToolName != "" && invokeDryRun:
+		dryRunTool(ctx, defs)
+	case invokeToolName != "":
+		invokeTool(ctx, defs)
+	case invokePromptName != "":
+		getPrompt(ctx, defs)
+	default:
+		readResource(ctx, defs)
+	}
+}
+
+func dryRunTool(ctx context.Context, defs *definitions.MCPToolDefinitions) {
+	tool, err := invoke.FindTool(defs, invokeToolName)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	result, err := invoke.DryRunTool(ctx, tool, json.RawMessage(invokeArgs))
+	if err != nil {
+		fmt.Printf("dry run of tool %s failed: %s\n", tool.Name, err.Error())
+		os.Exit(1)
+	}
+
+	if invokeJSONOutput {
+		printInvokeJSON(result)
+		return
+	}
+
+	fmt.Print(result.String())
+}
+
+func invokeTool(ctx context.Context, defs *definitions.MCPToolDefinitions) {
+	tool, err := invoke.FindTool(defs, invokeToolName)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	result, err := invoke.InvokeTool(ctx, tool, json.RawMessage(invokeArgs))
+	if err != nil {
+		fmt.Printf("invocation of tool %s failed: %s\n", tool.Name, err.Error())
+		os.Exit(1)
+	}
+
+	if invokeJSONOutput {
+		printInvokeJSON(result)
+	} else {
+		for _, content := range result.Content {
+			printInvokeContent(content)
+		}
+	}
+
+	if result.IsError {
+		os.Exit(1)
+	}
+}
+
+func getPrompt(ctx context.Context, defs *definitions.MCPToolDefinitions) {
+	var args map[string]string
+	if err := json.Unmarshal([]byte(invokeArgs), &args); err != nil {
+		fmt.Printf("invalid --args, prompt arguments must be a JSON object of strings: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	prompt, err := invoke.FindPrompt(defs, invokePromptName)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	result, err := invoke.GetPrompt(ctx, prompt, args)
+	if err != nil {
+		fmt.Printf("getting prompt %s failed: %s\n", prompt.Name, err.Error())
+		os.Exit(1)
+	}
+
+	if invokeJSONOutput {
+		printInvokeJSON(result)
+		return
+	}
+
+	for _, message := range result.Messages {
+		fmt.Printf("[%s]\n", message.Role)
+		printInvokeContent(message.Content)
+	}
+}
+
+func readResource(ctx context.Context, defs *definitions.MCPToolDefinitions) {
+	result, err := invoke.ReadResource(ctx, defs, invokeResourceURI)
+	if err != nil {
+		fmt.Printf("reading resource %s failed: %s\n", invokeResourceURI, err.Error())
+		os.Exit(1)
+	}
+
+	if invokeJSONOutput {
+		printInvokeJSON(result)
+		return
+	}
+
+	for _, contents := range result.Contents {
+		if contents.Text != "" {
+			fmt.Println(contents.Text)
+			continue
+		}
+		fmt.Printf("<%d bytes of %s>\n", len(contents.Blob), contents.MIMEType)
+	}
+}
+
+func printInvokeContent(content mcp.Content) {
+	if text, ok := content.(*mcp.TextContent); ok {
+		fmt.Println(text.Text)
+		return
+	}
+	printInvokeJSON(content)
+}
+
+func printInvokeJSON(v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Printf("failed to marshal JSON: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
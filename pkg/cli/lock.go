@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.Flags().StringVarP(&lockToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	lockCmd.Flags().StringVarP(&lockServerConfigPath, "server-config", "s", "mcpserver.yaml", "the path to the server config file, skipped if it doesn't exist and the flag is not set")
+}
+
+var lockToolDefinitionsPath string
+var lockServerConfigPath string
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pin the remote files extended by the MCP file and the server config file",
+	Long: `Pin the remote files extended by the MCP file and the server config file, directly or through other
+files, at their current digest.
+
+The digests are written to a lockfile next to each file that extends remote files, e.g. mcpfile.lock.yaml
+for mcpfile.yaml: the sha256 of the contents of URLs, and the manifest digest of OCI references. While a
+lockfile exists, remote files are fetched at their pinned digest, and files that changed or that are not
+in the lockfile are rejected. Run the command again to update the lockfiles after changing the extends.`,
+	Args: cobra.NoArgs,
+	Run:  executeLockCmd,
+}
+
+func executeLockCmd(cmd *cobra.Command, _ []string) {
+	if err := lockFile(lockToolDefinitionsPath); err != nil {
+		fmt.Printf("failed to lock %s: %s\n", lockToolDefinitionsPath, err.Error())
+		os.Exit(1)
+	}
+
+	_, err := os.Stat(lockServerConfigPath)
+	if errors.Is(err, fs.ErrNotExist) && !cmd.Flags().Changed("server-config") {
+		return
+	}
+	if err := lockFile(lockServerConfigPath); err != nil {
+		fmt.Printf("failed to lock %s: %s\n", lockServerConfigPath, err.Error())
+		os.Exit(1)
+	}
+}
+
+// lockFile writes the lockfile of the config file at path, if it extends remote files.
+func lockFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lock, err := inherit.Lock(data, path)
+	if err != nil {
+		return err
+	}
+
+	lockPath := inherit.LockFilePath(path)
+	if len(lock.Digests) == 0 {
+		fmt.Printf("%s extends no remote file\n", path)
+		// the lockfile of a file that no longer extends remote files is stale
+		if err := os.Remove(lockPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	if err := lock.Write(lockPath); err != nil {
+		return err
+	}
+	fmt.Printf("Pinned %d remote files of %s in %s\n", len(lock.Digests), path, lockPath)
+	return nil
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/mock"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+	mockCmd.Flags().StringVarP(&mockToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	mockCmd.Flags().IntVarP(&mockPort, "port", "p", 9090, "the port the mock backend listens on")
+	mockCmd.Flags().StringVar(&mockFixturesDir, "fixtures", "", "directory of the tool calls recorded with genmcp run --record, returned when they match a request")
+}
+
+var mockToolDefinitionsPath string
+var mockPort int
+var mockFixturesDir string
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Serve a fake HTTP backend for the tools of an MCP file",
+	Long: `Serve a fake HTTP backend implementing the URLs called by the tools of an MCP file, so that the
+MCP file can be demoed and developed without the real APIs.
+
+Every tool with an HTTP invocation is served at the method and path of its URL, regardless of its host.
+Requests are answered with a result recorded by genmcp run --record in the --fixtures directory if one
+matches the values of the path and query of the request, and with data generated from the output schema
+of the tool otherwise.
+
+Point the tools at the mock backend, e.g. by setting the environment variable of a URL like
+${API_URL}/users/{id} to http://localhost:9090, or with HTTP_PROXY=http://localhost:9090 for http URLs.`,
+	Args: cobra.NoArgs,
+	Run:  executeMockCmd,
+}
+
+func executeMockCmd(_ *cobra.Command, _ []string) {
+	mcpFile, err := definitions.ParseMCPFile(mockToolDefinitionsPath)
+	if err != nil {
+		fmt.Printf("invalid MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	var fixtures *recording.Store
+	if mockFixturesDir != "" {
+		fixtures, err = recording.NewStore(mockFixturesDir, recording.ModeReplay, nil)
+		if err != nil {
+			fmt.Printf("invalid --fixtures: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	server, err := mock.NewServer(&mcpFile.MCPToolDefinitions, fixtures)
+	if err != nil {
+		fmt.Printf("failed to create mock backend: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	routes := server.Routes()
+	if len(routes) == 0 {
+		fmt.Printf("no tool of %s has an HTTP invocation\n", mockToolDefinitionsPath)
+		os.Exit(1)
+	}
+
+	addr := fmt.Sprintf(":%d", mockPort)
+	fmt.Printf("Mock backend listening on http://localhost%s\n\n", addr)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "METHOD\tPATH\tTOOL")
+	for _, route := range routes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Tool)
+	}
+	_ = w.Flush()
+	fmt.Println()
+
+	if err := http.ListenAndServe(addr, logMockRequests(server)); err != nil {
+		fmt.Printf("mock backend failed: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// logMockRequests prints the requests answered by next, with their status and where the response comes from.
+func logMockRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		source := w.Header().Get(mock.SourceHeader)
+		if source == "" {
+			source = "-"
+		}
+		fmt.Printf("%s %s -> %d (%s)\n", r.Method, r.URL.RequestURI(), sw.status, source)
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
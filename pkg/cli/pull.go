@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/artifact"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringVarP(&pullOutputDir, "output", "o", ".", "the directory the MCP file and its referenced files are written to")
+	pullCmd.Flags().BoolVar(&pullForce, "force", false, "overwrite existing files")
+}
+
+var pullOutputDir string
+var pullForce bool
+
+var pullCmd = &cobra.Command{
+	Use:   "pull REFERENCE",
+	Short: "Fetch an MCP file published to an OCI registry",
+	Long: `Pull an MCP file pushed with genmcp push from a registry, e.g. ghcr.io/acme/mcp-tools:1.2.0, and write
+it to the output directory as mcpfile.yaml, with the files it references relative to it.
+
+Existing files are not overwritten unless --force is set. Registries are authenticated with the Docker
+credentials, e.g. from docker login.`,
+	Args: cobra.ExactArgs(1),
+	Run:  executePullCmd,
+}
+
+func executePullCmd(_ *cobra.Command, args []string) {
+	ref := args[0]
+
+	pulled, err := artifact.Pull(context.Background(), ref, pullOutputDir, pullForce)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Pulled %s@%s\n", ref, pulled.Digest)
+	fmt.Printf("INFO    Created %s\n", pulled.MCPFile)
+	for _, file := range pulled.Files {
+		fmt.Printf("INFO    Created %s\n", file)
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/artifact"
+	"github.com/genmcp/gen-mcp/pkg/config/diagnostics"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+	pushCmd.Flags().StringVarP(&pushToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+}
+
+var pushToolDefinitionsPath string
+
+var pushCmd = &cobra.Command{
+	Use:   "push REFERENCE",
+	Short: "Publish an MCP file to an OCI registry",
+	Long: `Package an MCP file as an OCI artifact and push it to a registry, e.g. ghcr.io/acme/mcp-tools:1.2.0,
+so that tool definitions can be versioned and distributed independently of server images.
+
+The MCP file is validated, merged onto the files it extends, and packaged with the local files read by its
+invocations, such as protobuf descriptor sets and CA certificates, whose paths are relative to it. Pushed
+MCP files can be fetched with genmcp pull, or extended by other MCP files with an oci:// reference.
+
+Registries are authenticated with the Docker credentials, e.g. from docker login.`,
+	Args: cobra.ExactArgs(1),
+	Run:  executePushCmd,
+}
+
+func executePushCmd(_ *cobra.Command, args []string) {
+	ref := args[0]
+
+	report := diagnostics.ValidateMCPFile(pushToolDefinitionsPath)
+	if report.HasErrors() {
+		for _, d := range report.Diagnostics {
+			fmt.Println(d.Format(report.File))
+		}
+		fmt.Println("MCP file is invalid, not pushing it")
+		os.Exit(1)
+	}
+
+	p, err := artifact.NewPackage(pushToolDefinitionsPath)
+	if err != nil {
+		fmt.Printf("failed to package MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	for _, skipped := range p.Skipped {
+		fmt.Printf("WARNING referenced file not packaged: %s\n", skipped)
+	}
+	for _, file := range p.Files {
+		fmt.Printf("INFO    Packaged %s\n", file)
+	}
+
+	digest, err := artifact.Push(context.Background(), p, ref)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Pushed %s %s to %s@%s\n", p.Name, p.Version, ref, digest)
+}
@@ -6,11 +6,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
+	"slices"
 	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/cli/utils"
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
 	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/container"
+	"github.com/genmcp/gen-mcp/pkg/deploy"
 	"github.com/genmcp/gen-mcp/pkg/runtime"
 	"github.com/spf13/cobra"
 )
@@ -20,11 +24,28 @@ func init() {
 	runCmd.Flags().StringVarP(&runToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
 	runCmd.Flags().StringVarP(&runServerConfigPath, "server-config", "s", "mcpserver.yaml", "the path to the server config file")
 	runCmd.Flags().BoolVarP(&detach, "detach", "d", false, "whether to detach when running")
+	runCmd.Flags().BoolVarP(&watch, "watch", "w", false, "whether to reload the MCP file when it changes")
+	runCmd.Flags().StringVar(&runRecordDir, "record", "", "directory the tool calls are recorded to as fixtures, with their secrets redacted")
+	runCmd.Flags().StringVar(&runReplayDir, "replay", "", "directory of the fixtures whose results are returned instead of invoking tools")
+	runCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	runCmd.Flags().StringVar(&runProfile, "profile", "", "the profile of the server config file merged onto it, e.g. dev or prod (default: $GENMCP_PROFILE)")
+	runCmd.Flags().BoolVar(&runContainer, "container", false, "build the image of the server and run it with a container engine, mounting the local config files")
+	runCmd.Flags().StringVar(&runContainerEngine, "engine", "", "container engine used with --container (default: docker, or podman if docker is not installed)")
+	runCmd.Flags().StringVar(&runContainerImage, "image", "", "tag of the image built and run with --container (default: genmcp-<server name>:local)")
+	runCmd.Flags().StringArrayVarP(&runContainerEnv, "env", "e", nil, "environment variable passed to the container with --container, as NAME=value or NAME to pass the local value")
 }
 
 var runToolDefinitionsPath string
 var runServerConfigPath string
 var detach bool
+var watch bool
+var runRecordDir string
+var runReplayDir string
+var runProfile string
+var runContainer bool
+var runContainerEngine string
+var runContainerImage string
+var runContainerEnv []string
 
 var runCmd = &cobra.Command{
 	Use:   "run",
@@ -63,7 +84,7 @@ func executeRunCmd(_ *cobra.Command, _ []string) {
 	}
 
 	// Parse and validate server config file
-	serverConfigFile, err := serverconfig.ParseMCPFile(serverConfigPath)
+	serverConfigFile, err := serverconfig.ParseMCPFileWithProfile(serverConfigPath, runProfile)
 	if err != nil {
 		fmt.Printf("invalid server config file: %s\n", err)
 		return
@@ -76,12 +97,38 @@ func executeRunCmd(_ *cobra.Command, _ []string) {
 		detach = false
 	}
 
+	recordDir, replayDir, err := resolveRecordingDirs(runRecordDir, runReplayDir)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return
+	}
+
+	if runContainer {
+		if watch {
+			fmt.Printf("cannot watch the MCP file when running in a container\n")
+			return
+		}
+		if recordDir != "" || replayDir != "" {
+			fmt.Printf("cannot record or replay tool calls when running in a container\n")
+			return
+		}
+		if err := runServerContainer(context.Background(), mcpFile, serverConfigFile, toolDefinitionsPath, serverConfigPath); err != nil {
+			fmt.Printf("%s\n", err.Error())
+		}
+		return
+	}
+
 	// Use tool definitions path as the identifier for process management (for backward compatibility)
 	processIdentifier := toolDefinitionsPath
 
 	if !detach {
 		// Run servers directly in the current process
-		err := runtime.RunServer(context.Background(), toolDefinitionsPath, serverConfigPath)
+		err := runtime.RunServerWithOptions(context.Background(), toolDefinitionsPath, serverConfigPath, runtime.RunOptions{
+			WatchToolDefinitions: watch,
+			RecordDir:            recordDir,
+			ReplayDir:            replayDir,
+			Profile:              runProfile,
+		})
 		if err != nil {
 			fmt.Printf("genmcp-server failed with %s\n", err.Error())
 		}
@@ -89,7 +136,20 @@ func executeRunCmd(_ *cobra.Command, _ []string) {
 	}
 
 	// Detached mode: spawn the same command without --detach flag
-	cmd := exec.Command(os.Args[0], "run", "-f", toolDefinitionsPath, "-s", serverConfigPath)
+	args := []string{"run", "-f", toolDefinitionsPath, "-s", serverConfigPath}
+	if watch {
+		args = append(args, "--watch")
+	}
+	if recordDir != "" {
+		args = append(args, "--record", recordDir)
+	}
+	if replayDir != "" {
+		args = append(args, "--replay", replayDir)
+	}
+	if runProfile != "" {
+		args = append(args, "--profile", runProfile)
+	}
+	cmd := exec.Command(os.Args[0], args...)
 	err = cmd.Start()
 	if err != nil {
 		fmt.Printf("failed to start genmcp-server: %s\n", err.Error())
@@ -131,3 +191,83 @@ func executeRunCmd(_ *cobra.Command, _ []string) {
 
 	fmt.Printf("successfully started gen-mcp server...\n")
 }
+
+// runServerContainer builds the image of the server for the platform of the host, saves it to the local
+// container engine, and runs it with the local config files mounted over the ones of the image.
+func runServerContainer(ctx context.Context, mcpFile *definitions.MCPToolDefinitionsFile, serverConfigFile *serverconfig.MCPServerConfigFile, toolDefinitionsPath, serverConfigPath string) error {
+	engine := runContainerEngine
+	if engine == "" {
+		var err error
+		if engine, err = container.DetectEngine(); err != nil {
+			return err
+		}
+	}
+
+	image := runContainerImage
+	if image == "" {
+		image = "genmcp-" + deploy.ResourceName(mcpFile.Name) + ":local"
+	}
+
+	serverConfigFile.ApplyDefaults()
+	opts := container.RunOptions{
+		Name:             "genmcp-" + deploy.ResourceName(mcpFile.Name),
+		Image:            image,
+		MCPFilePath:      toolDefinitionsPath,
+		ServerConfigPath: serverConfigPath,
+		Env:              runContainerEnv,
+		Detach:           detach,
+	}
+	if profile := serverconfig.SelectedProfile(runProfile); profile != "" {
+		// the server of the image selects the profile from the environment
+		opts.Env = append(slices.Clone(opts.Env), serverconfig.ProfileEnvVar+"="+profile)
+	}
+	opts.ApplyServerConfig(serverConfigFile.Runtime)
+
+	// the stdout of a server using the stdio transport is reserved to the protocol, so report the progress
+	// of the build on stderr
+	stdout := os.Stdout
+	if opts.Interactive {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
+
+	b, err := newImageBuilder(false)
+	if err != nil {
+		return fmt.Errorf("failed to setup binary downloader: %w", err)
+	}
+	// load the image with the engine running it, which may not serve the Docker Engine API
+	b.SaveToDaemon(engine)
+	if err := buildAndSaveImage(ctx, b, imageBuildOptions{
+		platform:               "linux/" + goruntime.GOARCH,
+		mcpToolDefinitionsPath: toolDefinitionsPath,
+		mcpServerConfigPath:    serverConfigPath,
+		imageTag:               image,
+	}); err != nil {
+		return err
+	}
+
+	if err := container.Run(ctx, engine, opts, os.Stdin, stdout, os.Stderr); err != nil {
+		return err
+	}
+	if detach {
+		fmt.Printf("successfully started gen-mcp server in container %s, stop it with %s stop %s\n", opts.Name, engine, opts.Name)
+	}
+	return nil
+}
+
+// resolveRecordingDirs returns the absolute paths of the directories of --record and --replay, so that they
+// don't depend on the working directory of detached servers.
+func resolveRecordingDirs(recordDir, replayDir string) (string, string, error) {
+	var err error
+	if recordDir != "" {
+		if recordDir, err = filepath.Abs(recordDir); err != nil {
+			return "", "", fmt.Errorf("failed to resolve record directory: %w", err)
+		}
+	}
+	if replayDir != "" {
+		if replayDir, err = filepath.Abs(replayDir); err != nil {
+			return "", "", fmt.Errorf("failed to resolve replay directory: %w", err)
+		}
+	}
+	return recordDir, replayDir, nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// adminTokenEnv is the environment variable the bearer token of the admin API is read from.
+const adminTokenEnv = "GENMCP_ADMIN_TOKEN"
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVarP(&statusServerConfigPath, "server-config", "s", "mcpserver.yaml", "the path to the server config file, whose admin API is queried")
+	statusCmd.Flags().StringVar(&statusURL, "url", "", "URL of the admin API, including its base path (default: http://localhost:<admin port><admin base path> of the server config)")
+	statusCmd.Flags().StringVar(&statusToken, "token", "", "bearer token of the admin API (default: $"+adminTokenEnv+", or the first bearer token of the server config)")
+	statusCmd.Flags().BoolVar(&statusJSONOutput, "json", false, "output the status in JSON format")
+}
+
+var statusServerConfigPath string
+var statusURL string
+var statusToken string
+var statusJSONOutput bool
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running MCP server",
+	Long: `Show the status of a running MCP server, from its admin API: its uptime, and for each listener the
+version and hash of the served tool definitions, the active sessions with the tools they are served,
+the tools served to each set of scopes, and the recent errors.
+
+The address and bearer token of the admin API are read from the server config, unless --url and
+--token are set.`,
+	Args: cobra.NoArgs,
+	Run:  executeStatusCmd,
+}
+
+func executeStatusCmd(_ *cobra.Command, _ []string) {
+	url, token, err := adminEndpoint()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	body, err := getAdminStatus(url, token)
+	if err != nil {
+		fmt.Printf("failed to get server status: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if statusJSONOutput {
+		fmt.Println(strings.TrimSpace(string(body)))
+		return
+	}
+
+	var status runtime.Status
+	if err := json.Unmarshal(body, &status); err != nil {
+		fmt.Printf("failed to parse server status: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	printStatus(status)
+}
+
+// adminEndpoint returns the URL and bearer token of the admin API, from the flags, the environment
+// and the server config.
+func adminEndpoint() (string, string, error) {
+	url := statusURL
+	token := statusToken
+	if token == "" {
+		token = os.Getenv(adminTokenEnv)
+	}
+	if url != "" && token != "" {
+		return strings.TrimSuffix(url, "/"), token, nil
+	}
+
+	serverConfigFile, err := serverconfig.ParseMCPFile(statusServerConfigPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse server config file: %w", err)
+	}
+	if serverConfigFile.Runtime == nil || serverConfigFile.Runtime.Admin == nil {
+		return "", "", fmt.Errorf("the admin API is not enabled in %s", statusServerConfigPath)
+	}
+	admin := serverConfigFile.Runtime.Admin
+	admin.ApplyDefaults()
+
+	if url == "" {
+		url = fmt.Sprintf("http://localhost:%d%s", admin.Port, admin.BasePath)
+	}
+	if token == "" && len(admin.BearerTokens) > 0 {
+		token = admin.BearerTokens[0]
+	}
+
+	return strings.TrimSuffix(url, "/"), token, nil
+}
+
+func getAdminStatus(url, token string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var adminErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &adminErr) == nil && adminErr.Error != "" {
+			return nil, fmt.Errorf("admin API returned %s: %s", resp.Status, adminErr.Error)
+		}
+		return nil, fmt.Errorf("admin API returned %s", resp.Status)
+	}
+
+	return body, nil
+}
+
+func printStatus(status runtime.Status) {
+	fmt.Printf("Server: %s\n", status.Server)
+	fmt.Printf("Started: %s (up %s)\n", status.StartedAt.Format(time.RFC3339), status.Uptime)
+	if status.DroppedAuditRecords > 0 {
+		fmt.Printf("Dropped audit records: %d\n", status.DroppedAuditRecords)
+	}
+
+	if len(status.Listeners) == 0 {
+		fmt.Println("\nNo listeners served over streamable HTTP")
+	}
+
+	for _, l := range status.Listeners {
+		fmt.Printf("\nListener: %s\n", l.Name)
+		fmt.Printf("  Version: %s\n", l.Version)
+		fmt.Printf("  Config hash: %s\n", l.ConfigHash)
+		if l.Reloads > 0 {
+			fmt.Printf("  Reloads: %d (last at %s)\n", l.Reloads, l.ReloadedAt.Format(time.RFC3339))
+		} else {
+			fmt.Println("  Reloads: 0")
+		}
+
+		fmt.Printf("  Sessions: %d\n", len(l.Sessions))
+		for _, s := range l.Sessions {
+			fmt.Printf("    - %s: %s\n", s.ID, formatToolList(s.Tools))
+		}
+
+		fmt.Printf("  Tool filters: %d\n", len(l.ToolFilters))
+		for _, f := range l.ToolFilters {
+			scopes := f.Scopes
+			if scopes == "" {
+				scopes = "(no scopes)"
+			}
+			fmt.Printf("    - %s: %s\n", scopes, formatToolList(f.Tools))
+		}
+
+		fmt.Printf("  Recent errors: %d\n", len(l.RecentErrors))
+		for _, e := range l.RecentErrors {
+			fmt.Printf("    - %s: %s\n", e.Time.Format(time.RFC3339), e.Message)
+		}
+	}
+}
+
+func formatToolList(tools []string) string {
+	if len(tools) == 0 {
+		return "(no tools)"
+	}
+	return strings.Join(tools, ", ")
+}
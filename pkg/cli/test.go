@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+	"github.com/genmcp/gen-mcp/pkg/tooltest"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	testCmd.Flags().StringVarP(&testToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	testCmd.Flags().StringArrayVar(&testTools, "tool", nil, "name of a tool whose tests are run (repeatable, default: every tool)")
+	testCmd.Flags().StringVar(&testReplayDir, "replay", "", "directory of the tool calls recorded with genmcp run --record, returned instead of invoking the tools")
+	testCmd.Flags().StringVar(&testJUnitPath, "junit", "", "path of the JUnit XML report written after the tests")
+}
+
+var testToolDefinitionsPath string
+var testTools []string
+var testReplayDir string
+var testJUnitPath string
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run the tests of the tools of an MCP file",
+	Long: `Run the tests declared in the tests section of the tools of an MCP file: call each tool with the
+arguments of its tests and check the results against their expectations.
+
+Tools are invoked against their real backends, or against a mock backend started with genmcp mock.
+With --replay, the results recorded by genmcp run --record are returned instead of invoking the tools.
+
+The command exits with status 1 if a test fails. With --junit, a JUnit XML report is written for CI systems.`,
+	Args: cobra.NoArgs,
+	Run:  executeTestCmd,
+}
+
+func executeTestCmd(_ *cobra.Command, _ []string) {
+	mcpFile, err := definitions.ParseMCPFile(testToolDefinitionsPath)
+	if err != nil {
+		fmt.Printf("invalid MCP file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	opts := tooltest.Options{Tools: testTools}
+	if testReplayDir != "" {
+		opts.Replay, err = recording.NewStore(testReplayDir, recording.ModeReplay, nil)
+		if err != nil {
+			fmt.Printf("invalid --replay: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	report, err := tooltest.Run(context.Background(), &mcpFile.MCPToolDefinitions, opts)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if len(report.Cases) == 0 {
+		fmt.Printf("no tests found in %s\n", testToolDefinitionsPath)
+		return
+	}
+
+	for _, c := range report.Cases {
+		status := "PASS"
+		if !c.Passed() {
+			status = "FAIL"
+		}
+		fmt.Printf("%s  %s/%s (%s)\n", status, c.Tool, c.Name, c.Duration.Round(time.Millisecond))
+		for _, failure := range c.Failures {
+			fmt.Printf("      %s\n", failure)
+		}
+	}
+	fmt.Printf("\n%d tests, %d failed\n", len(report.Cases), report.Failed())
+
+	if testJUnitPath != "" {
+		if err := writeJUnitReport(report, testJUnitPath); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if report.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeJUnitReport(report *tooltest.Report, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := report.WriteJUnit(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
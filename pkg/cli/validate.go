@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/config/diagnostics"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&validateToolDefinitionsPath, "file", "f", "mcpfile.yaml", "the path to the MCP file")
+	validateCmd.Flags().StringVarP(&validateServerConfigPath, "server-config", "s", "", "the path to the server config file, validated only if set")
+	validateCmd.Flags().StringVar(&validateProfile, "profile", "", "the profile of the server config file to validate (default: $GENMCP_PROFILE)")
+	validateCmd.Flags().BoolVar(&validateJSONOutput, "json", false, "output in JSON format")
+}
+
+var validateToolDefinitionsPath string
+var validateServerConfigPath string
+var validateProfile string
+var validateJSONOutput bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate an MCP file without starting a server",
+	Long: `Validate an MCP file, and optionally a server config file, without starting a server.
+
+The files are checked against their schemas, and every tool, prompt, resource and resource template
+is checked the same way the server checks them on startup, including their invocation configs and
+templates. Every problem is reported with the line and column it refers to.
+
+The command exits with a non-zero status if any error is found. Warnings do not change the exit status.`,
+	Args: cobra.NoArgs,
+	Run:  executeValidateCmd,
+}
+
+func executeValidateCmd(_ *cobra.Command, _ []string) {
+	reports := []*diagnostics.Report{diagnostics.ValidateMCPFile(validateToolDefinitionsPath)}
+	if validateServerConfigPath != "" {
+		reports = append(reports, diagnostics.ValidateServerConfigFile(validateServerConfigPath, validateProfile))
+	}
+
+	errors, warnings := 0, 0
+	for _, r := range reports {
+		errors += r.Count(diagnostics.SeverityError)
+		warnings += r.Count(diagnostics.SeverityWarning)
+	}
+
+	if validateJSONOutput {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			fmt.Printf("failed to marshal JSON: %s\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, r := range reports {
+			for _, d := range r.Diagnostics {
+				fmt.Println(d.Format(r.File))
+			}
+		}
+		fmt.Printf("%s, %s\n", pluralize(errors, "error"), pluralize(warnings, "warning"))
+	}
+
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
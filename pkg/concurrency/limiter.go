@@ -0,0 +1,81 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrLimitExceeded is returned when an operation could not acquire a slot of a limiter, because its queue
+// is full or the operation waited for longer than the queue timeout.
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// Limiter bounds the number of operations running concurrently. Operations over the limit wait in a queue
+// until a slot is released.
+type Limiter struct {
+	slots     chan struct{}
+	maxQueued int
+
+	queued atomic.Int64
+}
+
+// NewLimiter creates a limiter running up to limit operations concurrently, with up to maxQueued operations
+// waiting for a slot. The queue is unbounded if maxQueued is 0. It returns nil if limit is not positive,
+// which runs every operation immediately.
+func NewLimiter(limit, maxQueued int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{
+		slots:     make(chan struct{}, limit),
+		maxQueued: maxQueued,
+	}
+}
+
+// Limit returns the number of operations the limiter runs concurrently, or 0 if l is nil.
+func (l *Limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
+
+// InFlight returns the number of operations holding a slot.
+func (l *Limiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.slots)
+}
+
+// Acquire waits for a slot until ctx is done, and returns the function releasing it. The error wraps
+// ErrLimitExceeded if the queue is full, or is the cause of ctx if it is done while waiting.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	queued := l.queued.Add(1)
+	defer l.queued.Add(-1)
+	if l.maxQueued > 0 && queued > int64(l.maxQueued) {
+		return nil, fmt.Errorf("%w: %d operations running and %d queued", ErrLimitExceeded, l.Limit(), l.maxQueued)
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (l *Limiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,118 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterAcquire(t *testing.T) {
+	errTimeout := errors.New("timed out")
+
+	tt := []struct {
+		name        string
+		limit       int
+		maxQueued   int
+		running     int
+		queued      int
+		expectedErr error
+	}{
+		{
+			name:  "nil limiter runs immediately",
+			limit: 0,
+		},
+		{
+			name:    "free slot runs immediately",
+			limit:   2,
+			running: 1,
+		},
+		{
+			name:        "waits until the context is done",
+			limit:       1,
+			running:     1,
+			expectedErr: errTimeout,
+		},
+		{
+			name:        "full queue rejects without waiting",
+			limit:       1,
+			maxQueued:   1,
+			running:     1,
+			queued:      1,
+			expectedErr: ErrLimitExceeded,
+		},
+		{
+			name:        "queue with room waits",
+			limit:       1,
+			maxQueued:   2,
+			running:     1,
+			queued:      1,
+			expectedErr: errTimeout,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewLimiter(tc.limit, tc.maxQueued)
+
+			for range tc.running {
+				_, err := l.Acquire(context.Background())
+				require.NoError(t, err)
+			}
+
+			waitCtx, cancelWaiting := context.WithCancel(context.Background())
+			defer cancelWaiting()
+			for range tc.queued {
+				go func() {
+					_, _ = l.Acquire(waitCtx)
+				}()
+			}
+			require.Eventually(t, func() bool { return l == nil || l.queued.Load() == int64(tc.queued) },
+				time.Second, time.Millisecond)
+
+			ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, errTimeout)
+			defer cancel()
+
+			release, err := l.Acquire(ctx)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, min(tc.running+1, tc.limit), l.InFlight())
+			release()
+			assert.Equal(t, min(tc.running, tc.limit), l.InFlight())
+		})
+	}
+}
+
+func TestLimiterReleaseWakesQueued(t *testing.T) {
+	l := NewLimiter(1, 0)
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(context.Background())
+		acquired <- err
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("queued operation should wait for the running operation")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("queued operation should run once a slot is released")
+	}
+}
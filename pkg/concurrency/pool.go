@@ -0,0 +1,82 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Pool bounds the invocations of a server: all invocations share a global limit, and the invocations of
+// each tool can have a limit of their own. Invocations over a limit wait for up to the queue timeout.
+type Pool struct {
+	global       *Limiter
+	maxQueued    int
+	queueTimeout time.Duration
+
+	mu    sync.Mutex
+	tools map[string]*Limiter
+}
+
+// NewPool creates a pool running up to maxInFlight invocations concurrently, unlimited if 0, with up to
+// maxQueued invocations waiting for each limit, unlimited if 0. Invocations wait for up to queueTimeout,
+// or until their context is done if it is 0.
+func NewPool(maxInFlight, maxQueued int, queueTimeout time.Duration) *Pool {
+	return &Pool{
+		global:       NewLimiter(maxInFlight, maxQueued),
+		maxQueued:    maxQueued,
+		queueTimeout: queueTimeout,
+		tools:        make(map[string]*Limiter),
+	}
+}
+
+// Acquire waits for a slot of the limit of the tool name, if limit is positive, then for a global slot, and
+// returns the function releasing them. The error wraps ErrLimitExceeded if a queue is full or the queue
+// timeout elapsed. A nil pool runs every invocation immediately.
+func (p *Pool) Acquire(ctx context.Context, name string, limit int) (release func(), err error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	if p.queueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.queueTimeout,
+			fmt.Errorf("%w: timed out after %s waiting for a running invocation to complete", ErrLimitExceeded, p.queueTimeout))
+		defer cancel()
+	}
+
+	releaseTool, err := p.tool(name, limit).Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", name, err)
+	}
+
+	releaseGlobal, err := p.global.Acquire(ctx)
+	if err != nil {
+		releaseTool()
+		return nil, err
+	}
+
+	return func() {
+		releaseGlobal()
+		releaseTool()
+	}, nil
+}
+
+// tool returns the limiter of the tool name. The limiter is kept across calls, so that invocations of
+// handlers created again with the same limit, e.g. when the tools are reloaded, share it.
+func (p *Pool) tool(name string, limit int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l, ok := p.tools[name]
+	if !ok || l.Limit() != limit {
+		l = NewLimiter(limit, p.maxQueued)
+		p.tools[name] = l
+	}
+
+	return l
+}
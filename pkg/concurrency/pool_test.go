@@ -0,0 +1,96 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolAcquire(t *testing.T) {
+	tt := []struct {
+		name        string
+		maxInFlight int
+		running     []string
+		tool        string
+		limit       int
+		expectedErr string
+	}{
+		{
+			name:    "unlimited pool runs immediately",
+			running: []string{"a", "a"},
+			tool:    "a",
+		},
+		{
+			name:        "tool limit",
+			running:     []string{"a"},
+			tool:        "a",
+			limit:       1,
+			expectedErr: "tool a: concurrency limit exceeded: timed out after 50ms",
+		},
+		{
+			name:    "tool limit of another tool",
+			running: []string{"a"},
+			tool:    "b",
+			limit:   1,
+		},
+		{
+			name:        "global limit",
+			maxInFlight: 1,
+			running:     []string{"a"},
+			tool:        "b",
+			expectedErr: "concurrency limit exceeded: timed out after 50ms",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPool(tc.maxInFlight, 0, 50*time.Millisecond)
+
+			for _, name := range tc.running {
+				_, err := p.Acquire(context.Background(), name, tc.limit)
+				require.NoError(t, err)
+			}
+
+			release, err := p.Acquire(context.Background(), tc.tool, tc.limit)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrLimitExceeded)
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			release()
+		})
+	}
+}
+
+func TestPoolKeepsToolLimiters(t *testing.T) {
+	p := NewPool(0, 0, 0)
+
+	release, err := p.Acquire(context.Background(), "a", 1)
+	require.NoError(t, err)
+	defer release()
+
+	assert.Same(t, p.tool("a", 1), p.tool("a", 1), "limiter should be kept for the same limit")
+	assert.Equal(t, 1, p.tool("a", 1).InFlight(), "limiter should count the running invocation")
+	assert.Equal(t, 2, p.tool("a", 2).Limit(), "limiter should be replaced when the limit changes")
+	assert.Nil(t, p.tool("a", 0), "no limiter should be used without a limit")
+}
+
+func TestPoolReleasesToolSlotWhenGlobalLimitIsReached(t *testing.T) {
+	p := NewPool(1, 0, 50*time.Millisecond)
+
+	releaseA, err := p.Acquire(context.Background(), "a", 0)
+	require.NoError(t, err)
+
+	_, err = p.Acquire(context.Background(), "b", 1)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, 0, p.tool("b", 1).InFlight(), "tool slot should be released")
+
+	releaseA()
+	releaseB, err := p.Acquire(context.Background(), "b", 1)
+	require.NoError(t, err)
+	releaseB()
+}
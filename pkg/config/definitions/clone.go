@@ -0,0 +1,108 @@
+package mcpfile
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// clone returns a copy of m whose primitives, schemas and invocation configs can be changed, e.g. when they
+// are validated for a server, without changing m. The values of defaults and the tests of the tools are
+// shared, as they are only read.
+func (m *MCPToolDefinitionsFile) clone() *MCPToolDefinitionsFile {
+	c := *m
+	c.Extends = slices.Clone(m.Extends)
+	c.InvocationBases = cloneWrappers(m.InvocationBases)
+	c.Tools = clonePrimitives(m.Tools, (*Tool).clone)
+	c.Prompts = clonePrimitives(m.Prompts, (*Prompt).clone)
+	c.Resources = clonePrimitives(m.Resources, (*Resource).clone)
+	c.ResourceTemplates = clonePrimitives(m.ResourceTemplates, (*ResourceTemplate).clone)
+	return &c
+}
+
+func (t *Tool) clone() *Tool {
+	c := *t
+	c.InputSchema = t.InputSchema.CloneSchemas()
+	c.OutputSchema = t.OutputSchema.CloneSchemas()
+	c.Defaults = maps.Clone(t.Defaults)
+	c.Transforms = maps.Clone(t.Transforms)
+	c.InvocationConfigWrapper = cloneWrapper(t.InvocationConfigWrapper)
+	c.RequiredScopes = slices.Clone(t.RequiredScopes)
+	c.Toolsets = slices.Clone(t.Toolsets)
+	if t.ResultContent != nil {
+		resultContent := *t.ResultContent
+		c.ResultContent = &resultContent
+	}
+	return &c
+}
+
+func (p *Prompt) clone() *Prompt {
+	c := *p
+	c.Arguments = clonePrimitives(p.Arguments, func(a *PromptArgument) *PromptArgument { argument := *a; return &argument })
+	c.InputSchema = p.InputSchema.CloneSchemas()
+	c.OutputSchema = p.OutputSchema.CloneSchemas()
+	c.InvocationConfigWrapper = cloneWrapper(p.InvocationConfigWrapper)
+	c.Completions = cloneWrappers(p.Completions)
+	c.RequiredScopes = slices.Clone(p.RequiredScopes)
+	return &c
+}
+
+func (r *Resource) clone() *Resource {
+	c := *r
+	c.InputSchema = r.InputSchema.CloneSchemas()
+	c.OutputSchema = r.OutputSchema.CloneSchemas()
+	c.InvocationConfigWrapper = cloneWrapper(r.InvocationConfigWrapper)
+	c.RequiredScopes = slices.Clone(r.RequiredScopes)
+	return &c
+}
+
+func (r *ResourceTemplate) clone() *ResourceTemplate {
+	c := *r
+	c.InputSchema = r.InputSchema.CloneSchemas()
+	c.OutputSchema = r.OutputSchema.CloneSchemas()
+	c.InvocationConfigWrapper = cloneWrapper(r.InvocationConfigWrapper)
+	c.List = cloneWrapper(r.List)
+	c.Completions = cloneWrappers(r.Completions)
+	c.RequiredScopes = slices.Clone(r.RequiredScopes)
+	return &c
+}
+
+// clonePrimitives returns the clones of items, keeping nil items.
+func clonePrimitives[T any](items []*T, clone func(*T) *T) []*T {
+	if items == nil {
+		return nil
+	}
+
+	clones := make([]*T, len(items))
+	for i, item := range items {
+		if item != nil {
+			clones[i] = clone(item)
+		}
+	}
+	return clones
+}
+
+func cloneWrapper(w *invocation.InvocationConfigWrapper) *invocation.InvocationConfigWrapper {
+	if w == nil {
+		return nil
+	}
+
+	c := *w
+	if w.Config != nil {
+		c.Config = w.Config.DeepCopy()
+	}
+	return &c
+}
+
+func cloneWrappers(wrappers map[string]*invocation.InvocationConfigWrapper) map[string]*invocation.InvocationConfigWrapper {
+	if wrappers == nil {
+		return nil
+	}
+
+	clones := make(map[string]*invocation.InvocationConfigWrapper, len(wrappers))
+	for name, w := range wrappers {
+		clones[name] = cloneWrapper(w)
+	}
+	return clones
+}
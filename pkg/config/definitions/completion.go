@@ -0,0 +1,94 @@
+package mcpfile
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// CompletionTool returns the tool invoked to complete the values of an argument of the prompt, or nil if the
+// argument has no completion invocation.
+func (p Prompt) CompletionTool(argument string) *Tool {
+	return completionTool(p.Name, p.Description, p.InputSchema, p.RequiredScopes, p.TemplateAllowlist, p.Completions[argument])
+}
+
+// CompletionTool returns the tool invoked to complete the values of an argument of the resource template, or
+// nil if the argument has no completion invocation.
+func (r ResourceTemplate) CompletionTool(argument string) *Tool {
+	return completionTool(r.Name, r.Description, r.InputSchema, r.RequiredScopes, r.TemplateAllowlist, r.Completions[argument])
+}
+
+// completionTool returns a tool calling the completion invocation w. Completion requests only hold strings:
+// the partial value of the completed argument, and the values of the arguments already set by the client, so
+// the properties of the input schema of the tool are the ones of inputSchema, typed as optional strings.
+func completionTool(name, description string, inputSchema *jsonschema.Schema, requiredScopes []string, allowlist *template.Allowlist, w *invocation.InvocationConfigWrapper) *Tool {
+	if w == nil {
+		return nil
+	}
+
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema),
+	}
+	if inputSchema != nil {
+		for property, propertySchema := range inputSchema.Properties {
+			schema.Properties[property] = &jsonschema.Schema{Type: "string", Description: propertySchema.Description}
+		}
+	}
+
+	tool := &Tool{
+		Name:                    name,
+		Description:             description,
+		InputSchema:             schema,
+		InvocationConfigWrapper: w,
+		RequiredScopes:          requiredScopes,
+		TemplateAllowlist:       allowlist,
+	}
+	if resolved, err := parsecache.ResolveSchema(schema); err == nil {
+		tool.ResolvedInputSchema = resolved
+	}
+
+	return tool
+}
+
+// EnumCompletions returns the values of the enum of a property of inputSchema, which complete the argument
+// when it has no completion invocation.
+func EnumCompletions(inputSchema *jsonschema.Schema, argument string) []string {
+	if inputSchema == nil || inputSchema.Properties[argument] == nil {
+		return nil
+	}
+
+	var values []string
+	for _, v := range inputSchema.Properties[argument].Enum {
+		values = append(values, fmt.Sprint(v))
+	}
+	return values
+}
+
+// validateCompletions checks that the completion invocations are for properties of inputSchema, and valid
+// for the tools calling them.
+func validateCompletions(inputSchema *jsonschema.Schema, completions map[string]*invocation.InvocationConfigWrapper, completionTool func(string) *Tool, invocationValidator InvocationValidator) error {
+	var err error = nil
+
+	for _, argument := range slices.Sorted(maps.Keys(completions)) {
+		if inputSchema != nil && inputSchema.Properties[argument] == nil {
+			err = errors.Join(err, fmt.Errorf("completions[%s] does not match a property of the inputSchema", argument))
+			continue
+		}
+
+		w := completions[argument]
+		if w == nil || w.Config == nil {
+			err = errors.Join(err, fmt.Errorf("completions[%s] invocation is empty", argument))
+		} else if invocationErr := invocationValidator(completionTool(argument)); invocationErr != nil {
+			err = errors.Join(err, fmt.Errorf("completions[%s] invocation is not valid: %w", argument, invocationErr))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,158 @@
+package mcpfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestCompletionTool(t *testing.T) {
+	w := &invocation.InvocationConfigWrapper{Type: "http", Config: &mockInvocationConfig{}}
+	p := Prompt{
+		Name:        "review",
+		Description: "Review a pull request",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"repo":   {Type: "string", Description: "The repository"},
+				"number": {Type: "integer"},
+			},
+			Required: []string{"repo", "number"},
+		},
+		RequiredScopes: []string{"repo:read"},
+		Completions:    map[string]*invocation.InvocationConfigWrapper{"repo": w},
+	}
+
+	assert.Nil(t, p.CompletionTool("number"))
+
+	tool := p.CompletionTool("repo")
+	require.NotNil(t, tool)
+	assert.Equal(t, "review", tool.Name)
+	assert.Equal(t, w, tool.InvocationConfigWrapper)
+	assert.Equal(t, []string{"repo:read"}, tool.RequiredScopes)
+	assert.Empty(t, tool.InputSchema.Required)
+	assert.Equal(t, &jsonschema.Schema{Type: "string", Description: "The repository"}, tool.InputSchema.Properties["repo"])
+	assert.Equal(t, &jsonschema.Schema{Type: "string"}, tool.InputSchema.Properties["number"])
+	require.NotNil(t, tool.ResolvedInputSchema)
+	assert.NoError(t, tool.ResolvedInputSchema.Validate(map[string]any{"repo": "gen", "number": "4"}))
+}
+
+func TestEnumCompletions(t *testing.T) {
+	inputSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"language": {Type: "string", Enum: []any{"go", "python"}},
+			"level":    {Type: "integer", Enum: []any{1, 2}},
+			"name":     {Type: "string"},
+		},
+	}
+
+	tt := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		argument string
+		expected []string
+	}{
+		{
+			name:     "string enum",
+			schema:   inputSchema,
+			argument: "language",
+			expected: []string{"go", "python"},
+		},
+		{
+			name:     "integer enum",
+			schema:   inputSchema,
+			argument: "level",
+			expected: []string{"1", "2"},
+		},
+		{
+			name:     "no enum",
+			schema:   inputSchema,
+			argument: "name",
+		},
+		{
+			name:     "unknown argument",
+			schema:   inputSchema,
+			argument: "other",
+		},
+		{
+			name:     "no input schema",
+			argument: "language",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, EnumCompletions(tc.schema, tc.argument))
+		})
+	}
+}
+
+func TestValidateCompletions(t *testing.T) {
+	completionErr := errors.New("invalid completion invocation")
+	validator := func(primitive invocation.Primitive) error {
+		if primitive.PrimitiveType() == PrimitiveTypeTool {
+			return completionErr
+		}
+		return nil
+	}
+
+	tt := []struct {
+		name          string
+		completions   map[string]*invocation.InvocationConfigWrapper
+		expectedError string
+	}{
+		{
+			name: "no completions",
+		},
+		{
+			name: "invalid completion invocation",
+			completions: map[string]*invocation.InvocationConfigWrapper{
+				"id": {Type: "http", Config: &mockInvocationConfig{}},
+			},
+			expectedError: "invalid resource template: completions[id] invocation is not valid: invalid completion invocation",
+		},
+		{
+			name: "empty completion invocation",
+			completions: map[string]*invocation.InvocationConfigWrapper{
+				"id": {},
+			},
+			expectedError: "invalid resource template: completions[id] invocation is empty",
+		},
+		{
+			name: "unknown argument",
+			completions: map[string]*invocation.InvocationConfigWrapper{
+				"name": {Type: "http", Config: &mockInvocationConfig{}},
+			},
+			expectedError: "invalid resource template: completions[name] does not match a property of the inputSchema",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := &ResourceTemplate{
+				Name:        "user",
+				Description: "A user",
+				URITemplate: "users://{id}",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{"id": {Type: "string"}},
+				},
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "http", Config: &mockInvocationConfig{}},
+				Completions:             tc.completions,
+			}
+
+			err := rt.Validate(validator)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// envReference matches the ${VAR} references to environment variables in the string values of defaults.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ApplyDefaults returns the arguments of a call of the tool with its defaults: the defaults of properties of the
+// input schema are set when the arguments don't set them, and the defaults of other properties are always set.
+// References to environment variables in the string values of defaults are replaced by their values.
+func (t *Tool) ApplyDefaults(arguments json.RawMessage) (json.RawMessage, error) {
+	if len(t.Defaults) == 0 {
+		return arguments, nil
+	}
+
+	args := make(map[string]any)
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			// the arguments are rejected when they are validated
+			return arguments, nil
+		}
+		if args == nil {
+			args = make(map[string]any)
+		}
+	}
+
+	for name, value := range t.Defaults {
+		if _, set := args[name]; set && t.declaresProperty(name) {
+			continue
+		}
+
+		resolved, err := expandEnv(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the default of property %s: %w", name, err)
+		}
+		args[name] = resolved
+	}
+
+	return json.Marshal(args)
+}
+
+// declaresProperty reports whether the input schema of the tool declares the property name.
+func (t *Tool) declaresProperty(name string) bool {
+	if t.InputSchema == nil {
+		return false
+	}
+	_, ok := t.InputSchema.Properties[name]
+	return ok
+}
+
+// invocationInputSchema returns the input schema of the tool extended with the properties that are only set by
+// its defaults, or nil if all its defaults are for properties of the input schema.
+func (t *Tool) invocationInputSchema() *jsonschema.Schema {
+	var schema *jsonschema.Schema
+	for name, value := range t.Defaults {
+		if t.declaresProperty(name) {
+			continue
+		}
+		if schema == nil {
+			schema = t.InputSchema.CloneSchemas()
+			if schema.Properties == nil {
+				schema.Properties = make(map[string]*jsonschema.Schema)
+			}
+		}
+		schema.Properties[name] = schemaForValue(value)
+	}
+
+	return schema
+}
+
+// schemaForValue returns a schema accepting the type of value.
+func schemaForValue(value any) *jsonschema.Schema {
+	switch v := value.(type) {
+	case string:
+		return &jsonschema.Schema{Type: "string"}
+	case bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case float64:
+		if v == math.Trunc(v) {
+			return &jsonschema.Schema{Type: "integer"}
+		}
+		return &jsonschema.Schema{Type: "number"}
+	case []any:
+		return &jsonschema.Schema{Type: "array"}
+	case map[string]any:
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: &jsonschema.Schema{}}
+	default:
+		return &jsonschema.Schema{}
+	}
+}
+
+// expandEnv replaces the references to environment variables in the strings of value.
+func expandEnv(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		var err error
+		expanded := envReference.ReplaceAllStringFunc(v, func(ref string) string {
+			name := strings.TrimSuffix(strings.TrimPrefix(ref, "${"), "}")
+			envValue, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable '%s' not set", name)
+			}
+			return envValue
+		})
+		return expanded, err
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			expanded, err := expandEnv(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = expanded
+		}
+		return items, nil
+	case map[string]any:
+		fields := make(map[string]any, len(v))
+		for name, field := range v {
+			expanded, err := expandEnv(field)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = expanded
+		}
+		return fields, nil
+	default:
+		return value, nil
+	}
+}
+
+// envReferences returns the names of the environment variables referenced in the strings of value.
+func envReferences(value any) []string {
+	var names []string
+	switch v := value.(type) {
+	case string:
+		for _, match := range envReference.FindAllStringSubmatch(v, -1) {
+			names = append(names, match[1])
+		}
+	case []any:
+		for _, item := range v {
+			names = append(names, envReferences(item)...)
+		}
+	case map[string]any:
+		for _, field := range v {
+			names = append(names, envReferences(field)...)
+		}
+	}
+	return names
+}
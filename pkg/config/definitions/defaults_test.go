@@ -0,0 +1,160 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+func TestToolApplyDefaults(t *testing.T) {
+	tt := []struct {
+		name        string
+		defaults    map[string]any
+		arguments   string
+		env         map[string]string
+		expected    string
+		errContains string
+	}{
+		{
+			name:      "no defaults",
+			arguments: `{"title": "a"}`,
+			expected:  `{"title": "a"}`,
+		},
+		{
+			name:      "default of a missing property",
+			defaults:  map[string]any{"priority": "normal"},
+			arguments: `{"title": "a"}`,
+			expected:  `{"priority": "normal", "title": "a"}`,
+		},
+		{
+			name:      "arguments override defaults of declared properties",
+			defaults:  map[string]any{"priority": "normal"},
+			arguments: `{"title": "a", "priority": "high"}`,
+			expected:  `{"priority": "high", "title": "a"}`,
+		},
+		{
+			name:      "defaults of undeclared properties are constants",
+			defaults:  map[string]any{"source": "mcp", "version": float64(2)},
+			arguments: `{"title": "a", "source": "model"}`,
+			expected:  `{"source": "mcp", "title": "a", "version": 2}`,
+		},
+		{
+			name:      "empty arguments",
+			defaults:  map[string]any{"priority": "normal"},
+			arguments: ``,
+			expected:  `{"priority": "normal"}`,
+		},
+		{
+			name:      "null arguments",
+			defaults:  map[string]any{"priority": "normal"},
+			arguments: `null`,
+			expected:  `{"priority": "normal"}`,
+		},
+		{
+			name:      "environment variables",
+			defaults:  map[string]any{"tenant": "${GENMCP_DEFAULTS_TEST_TENANT}", "labels": []any{"env:${GENMCP_DEFAULTS_TEST_ENV}"}},
+			arguments: `{}`,
+			env:       map[string]string{"GENMCP_DEFAULTS_TEST_TENANT": "acme", "GENMCP_DEFAULTS_TEST_ENV": "prod"},
+			expected:  `{"labels": ["env:prod"], "tenant": "acme"}`,
+		},
+		{
+			name:        "unset environment variable",
+			defaults:    map[string]any{"tenant": "${GENMCP_DEFAULTS_TEST_UNSET}"},
+			arguments:   `{}`,
+			errContains: "failed to resolve the default of property tenant: environment variable 'GENMCP_DEFAULTS_TEST_UNSET' not set",
+		},
+		{
+			name:      "invalid arguments are left to validation",
+			defaults:  map[string]any{"priority": "normal"},
+			arguments: `["a"]`,
+			expected:  `["a"]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			tool := &Tool{
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"title":    {Type: "string"},
+						"priority": {Type: "string"},
+					},
+				},
+				Defaults: tc.defaults,
+			}
+
+			result, err := tool.ApplyDefaults(json.RawMessage(tc.arguments))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+type testInvocationConfig struct{}
+
+func (testInvocationConfig) Validate() error                       { return nil }
+func (testInvocationConfig) DeepCopy() invocation.InvocationConfig { return testInvocationConfig{} }
+
+func TestToolValidateDefaults(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tool := &Tool{
+		Name:        "create_ticket",
+		Description: "Create a ticket",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"title":    {Type: "string"},
+				"priority": {Type: "string"},
+			},
+			AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+		},
+		Defaults: map[string]any{
+			"priority": "normal",
+			"source":   "mcp",
+			"version":  float64(2),
+			"meta":     map[string]any{"team": "support"},
+		},
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+	}
+	require.NoError(t, tool.Validate(noopValidator))
+
+	// the input schema advertised to clients does not list the properties only set by defaults
+	assert.NotContains(t, tool.InputSchema.Properties, "source")
+
+	// invocations validate the arguments with them
+	schema := tool.GetInputSchema()
+	assert.Equal(t, "string", schema.Properties["source"].Type)
+	assert.Equal(t, "integer", schema.Properties["version"].Type)
+	assert.Equal(t, "object", schema.Properties["meta"].Type)
+	assert.Equal(t, "string", schema.Properties["priority"].Type)
+
+	arguments, err := tool.ApplyDefaults(json.RawMessage(`{"title": "Broken"}`))
+	require.NoError(t, err)
+	var args map[string]any
+	require.NoError(t, json.Unmarshal(arguments, &args))
+	assert.NoError(t, tool.ResolvedInputSchema.Validate(args))
+
+	tool.Defaults[""] = "x"
+	assert.ErrorContains(t, tool.Validate(noopValidator), "defaults must not have empty property names")
+	delete(tool.Defaults, "")
+
+	tool.TemplateAllowlist = template.NewAllowlist(nil, []string{"TEAM"}, nil)
+	tool.Defaults["meta"] = map[string]any{"team": "${TEAM}", "token": "Bearer ${SUPPORT_TOKEN}"}
+	assert.ErrorContains(t, tool.Validate(noopValidator), "defaults[meta] references environment variable 'SUPPORT_TOKEN', which is not in the allowedEnv")
+}
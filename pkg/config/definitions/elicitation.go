@@ -0,0 +1,121 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ToolElicitation configures how the user is asked for the missing required arguments of a call of a tool.
+type ToolElicitation struct {
+	// Message shown to the user. Defaults to a message naming the tool.
+	Message string `json:"message,omitempty" jsonschema:"optional"`
+
+	// Human-friendly prompts shown for the properties, keyed by property name.
+	// Defaults to the description of the property in the inputSchema.
+	Prompts map[string]string `json:"prompts,omitempty" jsonschema:"optional"`
+}
+
+func (te *ToolElicitation) Validate(inputSchema *jsonschema.Schema) error {
+	var err error = nil
+
+	for name := range te.Prompts {
+		property := inputSchema.Properties[name]
+		if property == nil {
+			err = errors.Join(err, fmt.Errorf("prompts[%s] does not match a property of the inputSchema", name))
+		} else if !elicitable(property) {
+			err = errors.Join(err, fmt.Errorf("prompts[%s] is for a property that can't be elicited, only string, number, integer and boolean properties can", name))
+		}
+	}
+
+	return err
+}
+
+// MissingArguments returns the required properties of the input schema of the tool that are not set by the
+// arguments of a call of the tool, nor by its defaults, in the order they are required.
+func (t *Tool) MissingArguments(arguments json.RawMessage) []string {
+	if t.InputSchema == nil || len(t.InputSchema.Required) == 0 {
+		return nil
+	}
+
+	args := make(map[string]any)
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			// the arguments are rejected when they are validated
+			return nil
+		}
+	}
+
+	var missing []string
+	for _, name := range t.InputSchema.Required {
+		if _, hasDefault := t.Defaults[name]; hasDefault {
+			continue
+		}
+		if value, set := args[name]; !set || value == nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// ElicitationSchema returns the schema of the form asking the user for the values of the properties, or false
+// if any of the properties can't be elicited.
+func (t *Tool) ElicitationSchema(properties []string) (*jsonschema.Schema, bool) {
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema, len(properties)),
+		Required:   slices.Clone(properties),
+	}
+
+	for _, name := range properties {
+		property := t.InputSchema.Properties[name]
+		if property == nil || !elicitable(property) {
+			return nil, false
+		}
+
+		// elicitation forms only support a subset of JSON schema
+		field := &jsonschema.Schema{
+			Type:        property.Type,
+			Title:       property.Title,
+			Description: property.Description,
+			Enum:        property.Enum,
+			Format:      property.Format,
+			MinLength:   property.MinLength,
+			MaxLength:   property.MaxLength,
+			Minimum:     property.Minimum,
+			Maximum:     property.Maximum,
+		}
+		if t.Elicitation != nil && t.Elicitation.Prompts[name] != "" {
+			field.Description = t.Elicitation.Prompts[name]
+		}
+		schema.Properties[name] = field
+	}
+
+	return schema, true
+}
+
+// ElicitationMessage returns the message shown to the user when asking for the missing arguments of a call.
+func (t *Tool) ElicitationMessage() string {
+	if t.Elicitation != nil && t.Elicitation.Message != "" {
+		return t.Elicitation.Message
+	}
+
+	name := t.Title
+	if name == "" {
+		name = t.Name
+	}
+	return fmt.Sprintf("Please provide the missing information to run %s.", name)
+}
+
+// elicitable reports whether the values of property can be asked to the user with an elicitation form.
+func elicitable(property *jsonschema.Schema) bool {
+	switch property.Type {
+	case "string", "number", "integer", "boolean":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,136 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func testElicitationInputSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"title":    {Type: "string", Description: "Title of the ticket"},
+			"priority": {Type: "string", Enum: []any{"low", "high"}},
+			"labels":   {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+		},
+		Required: []string{"title", "priority", "labels"},
+	}
+}
+
+func TestToolMissingArguments(t *testing.T) {
+	tt := []struct {
+		name      string
+		defaults  map[string]any
+		arguments string
+		expected  []string
+	}{
+		{
+			name:      "all required arguments set",
+			arguments: `{"title": "a", "priority": "low", "labels": []}`,
+		},
+		{
+			name:      "missing arguments in the order they are required",
+			arguments: `{"priority": "low"}`,
+			expected:  []string{"title", "labels"},
+		},
+		{
+			name:      "null arguments are missing",
+			arguments: `{"title": null, "priority": "low", "labels": []}`,
+			expected:  []string{"title"},
+		},
+		{
+			name:      "empty arguments",
+			arguments: ``,
+			expected:  []string{"title", "priority", "labels"},
+		},
+		{
+			name:      "arguments with defaults are not missing",
+			defaults:  map[string]any{"priority": "low"},
+			arguments: `{"labels": []}`,
+			expected:  []string{"title"},
+		},
+		{
+			name:      "invalid arguments are left to validation",
+			arguments: `["a"]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{InputSchema: testElicitationInputSchema(), Defaults: tc.defaults}
+			assert.Equal(t, tc.expected, tool.MissingArguments(json.RawMessage(tc.arguments)))
+		})
+	}
+}
+
+func TestToolElicitationSchema(t *testing.T) {
+	tool := &Tool{
+		Name:        "create_ticket",
+		InputSchema: testElicitationInputSchema(),
+		Elicitation: &ToolElicitation{Prompts: map[string]string{"priority": "How urgent is it?"}},
+	}
+
+	schema, ok := tool.ElicitationSchema([]string{"title", "priority"})
+	assert.True(t, ok)
+	assert.Equal(t, &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"title":    {Type: "string", Description: "Title of the ticket"},
+			"priority": {Type: "string", Enum: []any{"low", "high"}, Description: "How urgent is it?"},
+		},
+		Required: []string{"title", "priority"},
+	}, schema)
+	assert.Equal(t, "Please provide the missing information to run create_ticket.", tool.ElicitationMessage())
+
+	_, ok = tool.ElicitationSchema([]string{"title", "labels"})
+	assert.False(t, ok, "array properties can't be elicited")
+}
+
+func TestToolValidateElicitation(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name        string
+		elicitation *ToolElicitation
+		errContains string
+	}{
+		{
+			name:        "valid prompts",
+			elicitation: &ToolElicitation{Message: "Missing details", Prompts: map[string]string{"priority": "How urgent is it?"}},
+		},
+		{
+			name:        "prompt of an unknown property",
+			elicitation: &ToolElicitation{Prompts: map[string]string{"owner": "Who owns it?"}},
+			errContains: "invalid tool: elicitation is not valid: prompts[owner] does not match a property of the inputSchema",
+		},
+		{
+			name:        "prompt of a property that can't be elicited",
+			elicitation: &ToolElicitation{Prompts: map[string]string{"labels": "Which labels?"}},
+			errContains: "prompts[labels] is for a property that can't be elicited",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "create_ticket",
+				Description:             "Create a ticket",
+				InputSchema:             testElicitationInputSchema(),
+				Elicitation:             tc.elicitation,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -7,15 +7,14 @@ import (
 	"path/filepath"
 
 	"github.com/genmcp/gen-mcp/pkg/config"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
 	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
 	"github.com/google/jsonschema-go/jsonschema"
-	"sigs.k8s.io/yaml"
 )
 
 // ParseMCPFile parses an MCP file (mcpfile.yaml)
 func ParseMCPFile(path string) (*MCPToolDefinitionsFile, error) {
-	mcpFile := &MCPToolDefinitionsFile{}
-
 	path, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path to MCP file: %v", err)
@@ -26,14 +25,44 @@ func ParseMCPFile(path string) (*MCPToolDefinitionsFile, error) {
 		return nil, fmt.Errorf("failed to read MCP file: %v", err)
 	}
 
-	err = yaml.Unmarshal(data, mcpFile)
+	return ParseMCPFileDataAt(data, path)
+}
+
+// ParseMCPFileData parses the contents of an MCP file. The files it extends are not resolved, use
+// ParseMCPFileDataAt to merge them. The definitions parsed from large files are cached in memory.
+func ParseMCPFileData(data []byte) (*MCPToolDefinitionsFile, error) {
+	mcpFile, err := parsecache.Parse(data, parseMCPFileData, (*MCPToolDefinitionsFile).clone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal MCP file: %v", err)
 	}
 
+	// the bases of cached definitions are set again, as they were not unmarshaled
+	if len(mcpFile.InvocationBases) > 0 {
+		extends.SetBases(mcpFile.InvocationBases)
+	}
+
+	return mcpFile, nil
+}
+
+func parseMCPFileData(data []byte) (*MCPToolDefinitionsFile, error) {
+	mcpFile := &MCPToolDefinitionsFile{}
+	if err := parsecache.Unmarshal(data, mcpFile); err != nil {
+		return nil, err
+	}
 	return mcpFile, nil
 }
 
+// ParseMCPFileDataAt parses the contents of the MCP file at path, merged onto the files it extends. Paths
+// of extended files are relative to path, and remote files must match the digests of its lockfile.
+func ParseMCPFileDataAt(data []byte, path string) (*MCPToolDefinitionsFile, error) {
+	data, err := inherit.Resolve(data, path, inherit.Options{Cache: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the extends of MCP file: %w", err)
+	}
+
+	return ParseMCPFileData(data)
+}
+
 func (m *MCPToolDefinitionsFile) UnmarshalJSON(data []byte) error {
 	// First unmarshal into a temporary struct to get all fields
 	var raw map[string]json.RawMessage
@@ -67,6 +96,12 @@ func (m *MCPToolDefinitionsFile) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid schema version %s, expected %s - please migrate your file and handle any breaking changes", m.SchemaVersion, config.SchemaVersion)
 	}
 
+	if e, ok := raw["extends"]; ok {
+		if err := json.Unmarshal(e, &m.Extends); err != nil {
+			return err
+		}
+	}
+
 	// Unmarshal the rest into MCPToolDefinitions
 	if err := json.Unmarshal(data, &m.MCPToolDefinitions); err != nil {
 		return err
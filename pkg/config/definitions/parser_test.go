@@ -2,14 +2,18 @@ package mcpfile
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/genmcp/gen-mcp/pkg/config"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	cliInv "github.com/genmcp/gen-mcp/pkg/invocation/cli"
 	httpInv "github.com/genmcp/gen-mcp/pkg/invocation/http"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
 )
 
@@ -178,6 +182,59 @@ func TestParseMcpFile(t *testing.T) {
 				},
 			},
 		},
+		"extends": {
+			testFileName: "extends.yaml",
+			expected: &MCPToolDefinitionsFile{
+				Kind:          KindMCPToolDefinitions,
+				SchemaVersion: config.SchemaVersion,
+				Extends:       []inherit.Reference{{From: "one-server-cli-tools.yaml"}},
+				MCPToolDefinitions: MCPToolDefinitions{
+					Name:    "test-server",
+					Version: "1.1.0",
+					Tools: []*Tool{
+						{
+							Name:        "clone_repo",
+							Title:       "Clone repository",
+							Description: "Clone a git repository from a url to the local machine",
+							InputSchema: &jsonschema.Schema{
+								Type: "object",
+								Properties: map[string]*jsonschema.Schema{
+									"repoUrl": {
+										Type:        "string",
+										Description: "The git url of the repo to clone",
+									},
+									"depth": {
+										Type:        "integer",
+										Description: "The number of commits to clone",
+									},
+									"verbose": {
+										Type:        "boolean",
+										Description: "Whether to return verbose logs",
+									},
+								},
+								Required: []string{"repoUrl"},
+							},
+							InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+								Type: "cli",
+								Config: &cliInv.CliInvocationConfig{
+									Command: "git clone {repoUrl} {depth} {verbose}",
+									TemplateVariables: map[string]*cliInv.TemplateVariable{
+										"depth": {
+											Template:    "--depth {depth}",
+											OmitIfFalse: false,
+										},
+										"verbose": {
+											Template:    "--verbose",
+											OmitIfFalse: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"one server, prompts": {
 			testFileName: "one-server-prompts.yaml",
 			expected: &MCPToolDefinitionsFile{
@@ -419,3 +476,26 @@ func TestParseMcpFile(t *testing.T) {
 
 	}
 }
+
+func TestParseMCPFileDataCachesDefinitions(t *testing.T) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kind: MCPToolDefinitions\nschemaVersion: %q\nname: test\nversion: 1.0.0\ntools:\n", config.SchemaVersion)
+	for i := 0; b.Len() < parsecache.MinBytes; i++ {
+		fmt.Fprintf(&b, "- name: get_user_%d\n  description: Get a user by ID\n  inputSchema:\n    type: object\n    properties:\n      id:\n        type: string\n  invocation:\n    http:\n      method: GET\n      url: http://localhost:8080/users/{id}\n", i)
+	}
+	data := []byte(b.String())
+
+	first, err := ParseMCPFileData(data)
+	require.NoError(t, err)
+	require.NoError(t, first.Validate(func(invocation.Primitive) error { return nil }))
+	first.Tools[0].InputSchema.Properties["id"].Type = "integer"
+
+	second, err := ParseMCPFileData(data)
+	require.NoError(t, err)
+	require.Len(t, second.Tools, len(first.Tools))
+	assert.NotSame(t, first.Tools[0], second.Tools[0])
+	assert.Equal(t, "string", second.Tools[0].InputSchema.Properties["id"].Type,
+		"the changes of the parsed definitions should not change the cached ones")
+	assert.Nil(t, second.Tools[0].ResolvedInputSchema, "the cached definitions should not be validated")
+	assert.NotSame(t, first.Tools[0].InvocationConfigWrapper.Config, second.Tools[0].InvocationConfigWrapper.Config)
+}
@@ -0,0 +1,49 @@
+package mcpfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultPostProcessMaxTokens is the maximum number of tokens generated when post-processing a tool result,
+// if the postProcess of the tool doesn't set one.
+const DefaultPostProcessMaxTokens = 1000
+
+// ToolPostProcess configures a model of the client rewriting the result of a tool, with MCP sampling, before
+// the result is returned.
+type ToolPostProcess struct {
+	// Instruction sent to the model along with the result of the tool, e.g. "Summarize the open issues".
+	Instruction string `json:"instruction" jsonschema:"required"`
+
+	// Optional system prompt of the sampling request.
+	SystemPrompt string `json:"systemPrompt,omitempty" jsonschema:"optional"`
+
+	// Maximum number of tokens the model generates. Defaults to 1000.
+	MaxTokens int `json:"maxTokens,omitempty" jsonschema:"optional"`
+
+	// If true, calls fail when the client does not support sampling or the model can't rewrite the result.
+	// Otherwise, the result of the tool is returned unchanged.
+	Required bool `json:"required,omitempty" jsonschema:"optional"`
+}
+
+func (pp *ToolPostProcess) Validate() error {
+	var err error = nil
+
+	if pp.Instruction == "" {
+		err = errors.Join(err, fmt.Errorf("instruction is required"))
+	}
+
+	if pp.MaxTokens < 0 {
+		err = errors.Join(err, fmt.Errorf("maxTokens must not be negative"))
+	}
+
+	return err
+}
+
+// GetMaxTokens returns the maximum number of tokens the model generates.
+func (pp *ToolPostProcess) GetMaxTokens() int {
+	if pp.MaxTokens == 0 {
+		return DefaultPostProcessMaxTokens
+	}
+	return pp.MaxTokens
+}
@@ -0,0 +1,65 @@
+package mcpfile
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestToolValidatePostProcess(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name         string
+		postProcess  *ToolPostProcess
+		outputSchema *jsonschema.Schema
+		errContains  string
+	}{
+		{
+			name:        "valid postProcess",
+			postProcess: &ToolPostProcess{Instruction: "Summarize the result", MaxTokens: 200},
+		},
+		{
+			name:        "missing instruction",
+			postProcess: &ToolPostProcess{},
+			errContains: "invalid tool: postProcess is not valid: instruction is required",
+		},
+		{
+			name:        "negative max tokens",
+			postProcess: &ToolPostProcess{Instruction: "Summarize the result", MaxTokens: -1},
+			errContains: "maxTokens must not be negative",
+		},
+		{
+			name:         "with outputSchema",
+			postProcess:  &ToolPostProcess{Instruction: "Summarize the result"},
+			outputSchema: &jsonschema.Schema{Type: "object"},
+			errContains:  "invalid tool: postProcess cannot be used with outputSchema",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "list_issues",
+				Description:             "List the open issues",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				OutputSchema:            tc.outputSchema,
+				PostProcess:             tc.postProcess,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 200, tool.PostProcess.GetMaxTokens())
+		})
+	}
+
+	assert.Equal(t, DefaultPostProcessMaxTokens, (&ToolPostProcess{}).GetMaxTokens())
+}
@@ -0,0 +1,114 @@
+package mcpfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	ResultContentText         = "text"
+	ResultContentStructured   = "structured"
+	ResultContentResource     = "resource"
+	ResultContentResourceLink = "resourceLink"
+)
+
+const (
+	StructuredContentAuto     = "auto"
+	StructuredContentDisabled = "disabled"
+	StructuredContentRequired = "required"
+)
+
+// ToolResultContent configures how the result of a tool is represented in the content of the tool result.
+type ToolResultContent struct {
+	// How the result is returned:
+	// text returns the result as text only, with the structured content serialized as JSON if there is no text;
+	// structured returns the structured content only, without the text duplicating it;
+	// resource embeds the text of the result as the contents of a resource of the MCP file;
+	// resourceLink returns a link to a resource of the MCP file, which the client reads to get the result.
+	Type string `json:"type" jsonschema:"required,enum=text,enum=structured,enum=resource,enum=resourceLink"`
+
+	// Name of a resource or resource template of the MCP file, for the resource and resourceLink types. The
+	// variables of the uriTemplate of resource templates are set from the arguments of the call, and from the
+	// top-level fields of the structured content of the result.
+	Resource string `json:"resource,omitempty" jsonschema:"optional"`
+
+	// The resource the result is returned as, set when the MCP file is validated (internal use only).
+	ResolvedResource *ResultResource `json:"-"`
+}
+
+// ResultResource is the resource or resource template the result of a tool is returned as.
+type ResultResource struct {
+	Name        string
+	Title       string
+	Description string
+	MIMEType    string
+
+	// URI of the resource, or URI template of the resource template.
+	URI string
+
+	// Whether URI is the URI template of a resource template.
+	Template bool
+}
+
+func (rc *ToolResultContent) Validate(t *Tool) error {
+	var err error = nil
+
+	switch rc.Type {
+	case ResultContentText, ResultContentStructured:
+		if rc.Resource != "" {
+			err = errors.Join(err, fmt.Errorf("resource can only be set for the %s and %s types", ResultContentResource, ResultContentResourceLink))
+		}
+	case ResultContentResource, ResultContentResourceLink:
+		if rc.Resource == "" {
+			err = errors.Join(err, fmt.Errorf("resource is required for the %s type", rc.Type))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf("type must be one of (%s, %s, %s, %s), received %s",
+			ResultContentText, ResultContentStructured, ResultContentResource, ResultContentResourceLink, rc.Type))
+	}
+
+	// the structured content is required by the outputSchema, and replaced with text by postProcess
+	if t.OutputSchema != nil && rc.Type != ResultContentStructured {
+		err = errors.Join(err, fmt.Errorf("type %s cannot be used with outputSchema, which requires structured content", rc.Type))
+	}
+	if t.PostProcess != nil && rc.Type != ResultContentText {
+		err = errors.Join(err, fmt.Errorf("type %s cannot be used with postProcess, which returns text", rc.Type))
+	}
+
+	return err
+}
+
+// resolveResultResources sets the resources the results of the tools of s are returned as, and returns an error
+// for each tool whose resultContent references a resource that does not exist.
+func (s *MCPToolDefinitions) resolveResultResources() error {
+	var err error = nil
+
+	for i, t := range s.Tools {
+		if t == nil || t.ResultContent == nil || t.ResultContent.Resource == "" {
+			continue
+		}
+
+		resource := s.findResultResource(t.ResultContent.Resource)
+		if resource == nil {
+			err = errors.Join(err, fmt.Errorf("invalid server: tools[%d] is invalid: invalid tool: resultContent resource %s does not match a resource or resource template", i, t.ResultContent.Resource))
+			continue
+		}
+		t.ResultContent.ResolvedResource = resource
+	}
+
+	return err
+}
+
+func (s *MCPToolDefinitions) findResultResource(name string) *ResultResource {
+	for _, r := range s.Resources {
+		if r != nil && r.Name == name {
+			return &ResultResource{Name: r.Name, Title: r.Title, Description: r.Description, MIMEType: r.MIMEType, URI: r.URI}
+		}
+	}
+	for _, rt := range s.ResourceTemplates {
+		if rt != nil && rt.Name == name {
+			return &ResultResource{Name: rt.Name, Title: rt.Title, Description: rt.Description, MIMEType: rt.MIMEType, URI: rt.URITemplate, Template: true}
+		}
+	}
+	return nil
+}
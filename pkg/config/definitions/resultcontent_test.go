@@ -0,0 +1,196 @@
+package mcpfile
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestToolValidateResultContent(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name          string
+		resultContent *ToolResultContent
+		outputSchema  *jsonschema.Schema
+		postProcess   *ToolPostProcess
+		errContains   string
+	}{
+		{
+			name:          "text",
+			resultContent: &ToolResultContent{Type: ResultContentText},
+		},
+		{
+			name:          "structured with outputSchema",
+			resultContent: &ToolResultContent{Type: ResultContentStructured},
+			outputSchema:  &jsonschema.Schema{Type: "object"},
+		},
+		{
+			name:          "resource link",
+			resultContent: &ToolResultContent{Type: ResultContentResourceLink, Resource: "report"},
+		},
+		{
+			name:          "invalid type",
+			resultContent: &ToolResultContent{Type: "json"},
+			errContains:   "invalid tool: resultContent is not valid: type must be one of (text, structured, resource, resourceLink), received json",
+		},
+		{
+			name:          "missing resource",
+			resultContent: &ToolResultContent{Type: ResultContentResource},
+			errContains:   "resource is required for the resource type",
+		},
+		{
+			name:          "resource of text",
+			resultContent: &ToolResultContent{Type: ResultContentText, Resource: "report"},
+			errContains:   "resource can only be set for the resource and resourceLink types",
+		},
+		{
+			name:          "text with outputSchema",
+			resultContent: &ToolResultContent{Type: ResultContentText},
+			outputSchema:  &jsonschema.Schema{Type: "object"},
+			errContains:   "type text cannot be used with outputSchema",
+		},
+		{
+			name:          "structured with postProcess",
+			resultContent: &ToolResultContent{Type: ResultContentStructured},
+			postProcess:   &ToolPostProcess{Instruction: "Summarize the result"},
+			errContains:   "type structured cannot be used with postProcess",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "create_report",
+				Description:             "Create a report",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				OutputSchema:            tc.outputSchema,
+				PostProcess:             tc.postProcess,
+				ResultContent:           tc.resultContent,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestToolValidateStructuredContent(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name              string
+		structuredContent string
+		outputSchema      *jsonschema.Schema
+		resultContent     *ToolResultContent
+		errContains       string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:              "required with outputSchema",
+			structuredContent: StructuredContentRequired,
+			outputSchema:      &jsonschema.Schema{Type: "object"},
+		},
+		{
+			name:              "disabled",
+			structuredContent: StructuredContentDisabled,
+			resultContent:     &ToolResultContent{Type: ResultContentText},
+		},
+		{
+			name:              "invalid value",
+			structuredContent: "always",
+			errContains:       "invalid tool: structuredContent must be one of (auto, disabled, required), received always",
+		},
+		{
+			name:              "disabled with outputSchema",
+			structuredContent: StructuredContentDisabled,
+			outputSchema:      &jsonschema.Schema{Type: "object"},
+			errContains:       "invalid tool: structuredContent cannot be disabled with outputSchema",
+		},
+		{
+			name:              "disabled with structured resultContent",
+			structuredContent: StructuredContentDisabled,
+			resultContent:     &ToolResultContent{Type: ResultContentStructured},
+			errContains:       "invalid tool: structuredContent cannot be disabled with resultContent of type structured",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "get_user",
+				Description:             "Get a user",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				OutputSchema:            tc.outputSchema,
+				StructuredContent:       tc.structuredContent,
+				ResultContent:           tc.resultContent,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResolveResultResources(t *testing.T) {
+	tt := []struct {
+		name        string
+		resource    string
+		expected    *ResultResource
+		errContains string
+	}{
+		{
+			name:     "resource",
+			resource: "latest_report",
+			expected: &ResultResource{Name: "latest_report", Description: "The latest report", MIMEType: "text/csv", URI: "reports://latest"},
+		},
+		{
+			name:     "resource template",
+			resource: "report",
+			expected: &ResultResource{Name: "report", Title: "Report", Description: "A report", URI: "reports://{id}", Template: true},
+		},
+		{
+			name:        "unknown resource",
+			resource:    "other",
+			errContains: "invalid server: tools[0] is invalid: invalid tool: resultContent resource other does not match a resource or resource template",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defs := &MCPToolDefinitions{
+				Tools: []*Tool{{Name: "create_report", ResultContent: &ToolResultContent{Type: ResultContentResourceLink, Resource: tc.resource}}},
+				Resources: []*Resource{
+					{Name: "latest_report", Description: "The latest report", MIMEType: "text/csv", URI: "reports://latest"},
+				},
+				ResourceTemplates: []*ResourceTemplate{
+					{Name: "report", Title: "Report", Description: "A report", URITemplate: "reports://{id}"},
+				},
+			}
+
+			err := defs.resolveResultResources()
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, defs.Tools[0].ResultContent.ResolvedResource)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package mcpfile
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// validTestErrorCodes are the error codes a test can expect.
+var validTestErrorCodes = map[invocation.ErrorCode]struct{}{
+	invocation.ErrorCodeValidation:         {},
+	invocation.ErrorCodeAuth:               {},
+	invocation.ErrorCodeBackendUnavailable: {},
+	invocation.ErrorCodeBackendStatus:      {},
+	invocation.ErrorCodeTimeout:            {},
+	invocation.ErrorCodeInternal:           {},
+}
+
+// ToolTest is a call of a tool and the result it is expected to return, run by genmcp test.
+type ToolTest struct {
+	// Name of the test, unique among the tests of the tool.
+	Name string `json:"name" jsonschema:"required"`
+
+	// Arguments of the call, as sent by a client. The call has no arguments if unset.
+	Arguments map[string]any `json:"arguments,omitempty" jsonschema:"optional"`
+
+	// Expected result of the call. The call is only expected to succeed if unset.
+	Expect *ToolTestExpectation `json:"expect,omitempty" jsonschema:"optional"`
+}
+
+// ToolTestExpectation defines the result a tool test expects. Every condition that is set must hold.
+type ToolTestExpectation struct {
+	// Whether the result is an error. Defaults to true if errorCode or status is set, and to false otherwise.
+	IsError *bool `json:"isError,omitempty" jsonschema:"optional"`
+
+	// Error code of the failed result, e.g. validation_error or backend_error_status.
+	ErrorCode invocation.ErrorCode `json:"errorCode,omitempty" jsonschema:"optional,enum=validation_error,enum=auth_error,enum=backend_unavailable,enum=backend_error_status,enum=timeout,enum=internal_error"`
+
+	// Status returned by the backend for a failed result: the HTTP status of the response, the exit code of
+	// the command, or the gRPC status code.
+	Status int `json:"status,omitempty" jsonschema:"optional"`
+
+	// Strings the text content of the result must contain.
+	Contains []string `json:"contains,omitempty" jsonschema:"optional"`
+
+	// Regular expression the text content of the result must match.
+	Matches string `json:"matches,omitempty" jsonschema:"optional"`
+
+	// Matchers of the values of the structured content of the result, or of its text content parsed as JSON
+	// if it has no structured content.
+	JSON []ToolTestMatcher `json:"json,omitempty" jsonschema:"optional"`
+}
+
+// ToolTestMatcher checks a value of the JSON content of a result.
+type ToolTestMatcher struct {
+	// JMESPath expression selecting the value, e.g. "items[0].name" or "length(items)".
+	Path string `json:"path" jsonschema:"required"`
+
+	// Value the selected value must be equal to.
+	Equals any `json:"equals,omitempty" jsonschema:"optional"`
+
+	// Regular expression the selected value must match, formatted as a string if it isn't one.
+	Matches string `json:"matches,omitempty" jsonschema:"optional"`
+}
+
+func (tt *ToolTest) Validate() error {
+	var err error = nil
+
+	if tt.Name == "" {
+		err = errors.Join(err, fmt.Errorf("name is required"))
+	}
+
+	if tt.Expect != nil {
+		if expectErr := tt.Expect.Validate(); expectErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid expect: %w", expectErr))
+		}
+	}
+
+	return err
+}
+
+func (te *ToolTestExpectation) Validate() error {
+	var err error = nil
+
+	if te.ErrorCode != "" {
+		if _, ok := validTestErrorCodes[te.ErrorCode]; !ok {
+			err = errors.Join(err, fmt.Errorf("unknown errorCode %s", te.ErrorCode))
+		}
+	}
+
+	if te.IsError != nil && !*te.IsError && (te.ErrorCode != "" || te.Status != 0) {
+		err = errors.Join(err, fmt.Errorf("errorCode and status can only be set for errors"))
+	}
+
+	if te.Matches != "" {
+		if _, reErr := regexp.Compile(te.Matches); reErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid matches: %w", reErr))
+		}
+	}
+
+	for i, m := range te.JSON {
+		if matcherErr := m.Validate(); matcherErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid json[%d]: %w", i, matcherErr))
+		}
+	}
+
+	return err
+}
+
+func (tm *ToolTestMatcher) Validate() error {
+	var err error = nil
+
+	if tm.Path == "" {
+		err = errors.Join(err, fmt.Errorf("path is required"))
+	} else if _, pathErr := jmespath.Compile(tm.Path); pathErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid path '%s': %w", tm.Path, pathErr))
+	}
+
+	if tm.Equals == nil && tm.Matches == "" {
+		err = errors.Join(err, fmt.Errorf("one of equals or matches is required"))
+	}
+
+	if tm.Matches != "" {
+		if _, reErr := regexp.Compile(tm.Matches); reErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid matches: %w", reErr))
+		}
+	}
+
+	return err
+}
+
+// ExpectsError returns whether the result is expected to be an error.
+func (te *ToolTestExpectation) ExpectsError() bool {
+	if te == nil {
+		return false
+	}
+	if te.IsError != nil {
+		return *te.IsError
+	}
+	return te.ErrorCode != "" || te.Status != 0
+}
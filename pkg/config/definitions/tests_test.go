@@ -0,0 +1,86 @@
+package mcpfile
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestToolValidateTests(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+	isError := false
+
+	tt := []struct {
+		name        string
+		tests       []*ToolTest
+		errContains string
+	}{
+		{
+			name: "valid tests",
+			tests: []*ToolTest{
+				{Name: "lists the issues", Arguments: map[string]any{"repo": "genmcp"}, Expect: &ToolTestExpectation{
+					Contains: []string{"open"},
+					JSON:     []ToolTestMatcher{{Path: "length(issues)", Equals: 2}, {Path: "issues[0].title", Matches: "^Fix"}},
+				}},
+				{Name: "unknown repo", Expect: &ToolTestExpectation{ErrorCode: invocation.ErrorCodeBackendStatus, Status: 404}},
+			},
+		},
+		{
+			name:        "missing name",
+			tests:       []*ToolTest{{}},
+			errContains: "invalid tool: tests[0] is not valid: name is required",
+		},
+		{
+			name:        "duplicate names",
+			tests:       []*ToolTest{{Name: "lists the issues"}, {Name: "lists the issues"}},
+			errContains: "invalid tool: tests[1] has the same name as another test: lists the issues",
+		},
+		{
+			name:        "unknown error code",
+			tests:       []*ToolTest{{Name: "fails", Expect: &ToolTestExpectation{ErrorCode: "not_found"}}},
+			errContains: "invalid expect: unknown errorCode not_found",
+		},
+		{
+			name:        "error code of a successful result",
+			tests:       []*ToolTest{{Name: "fails", Expect: &ToolTestExpectation{IsError: &isError, Status: 404}}},
+			errContains: "errorCode and status can only be set for errors",
+		},
+		{
+			name:        "invalid regular expression",
+			tests:       []*ToolTest{{Name: "matches", Expect: &ToolTestExpectation{Matches: "(open"}}},
+			errContains: "invalid matches",
+		},
+		{
+			name:        "invalid path",
+			tests:       []*ToolTest{{Name: "matches", Expect: &ToolTestExpectation{JSON: []ToolTestMatcher{{Path: "issues[", Equals: 1}}}}},
+			errContains: "invalid json[0]: invalid path 'issues['",
+		},
+		{
+			name:        "matcher without condition",
+			tests:       []*ToolTest{{Name: "matches", Expect: &ToolTestExpectation{JSON: []ToolTestMatcher{{Path: "issues"}}}}},
+			errContains: "invalid json[0]: one of equals or matches is required",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "list_issues",
+				Description:             "List the open issues",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				Tests:                   tc.tests,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
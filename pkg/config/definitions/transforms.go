@@ -0,0 +1,264 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const (
+	CastString  = "string"
+	CastInteger = "integer"
+	CastNumber  = "number"
+	CastBoolean = "boolean"
+)
+
+// PropertyTransform normalizes the value of an input property before the arguments of a call are validated.
+// The rules are applied in the order of the fields, to each element of array values. String rules only apply
+// to string values, and null values are left unchanged.
+type PropertyTransform struct {
+	// If true, leading and trailing white space is removed.
+	Trim bool `json:"trim,omitempty" jsonschema:"optional"`
+
+	// Regular expressions replaced in the value, in order.
+	Replace []ReplaceRule `json:"replace,omitempty" jsonschema:"optional"`
+
+	// If true, values matching a value of the enum of the property regardless of case and surrounding white
+	// space are replaced with that value, e.g. " High" with "high".
+	NormalizeEnum bool `json:"normalizeEnum,omitempty" jsonschema:"optional"`
+
+	// Maximum number of characters of the value, longer values are truncated. Unlimited if 0.
+	MaxLength int `json:"maxLength,omitempty" jsonschema:"optional"`
+
+	// Type the value is converted to, e.g. "42" to 42 with integer. Values that can't be converted are rejected.
+	Cast string `json:"cast,omitempty" jsonschema:"optional,enum=string,enum=integer,enum=number,enum=boolean"`
+}
+
+// ReplaceRule replaces the matches of a regular expression.
+type ReplaceRule struct {
+	// Go regular expression to replace.
+	Pattern string `json:"pattern" jsonschema:"required"`
+
+	// Replacement of the matches, which can reference the groups of the pattern as $1 or ${name}.
+	Replacement string `json:"replacement" jsonschema:"optional"`
+}
+
+func (pt *PropertyTransform) Validate(property *jsonschema.Schema) error {
+	var err error = nil
+
+	for i, rule := range pt.Replace {
+		if _, reErr := regexp.Compile(rule.Pattern); reErr != nil {
+			err = errors.Join(err, fmt.Errorf("replace[%d] has an invalid pattern: %w", i, reErr))
+		}
+	}
+
+	if pt.MaxLength < 0 {
+		err = errors.Join(err, fmt.Errorf("maxLength must not be negative"))
+	}
+
+	if pt.NormalizeEnum && len(enumOf(property)) == 0 {
+		err = errors.Join(err, fmt.Errorf("normalizeEnum requires the property to have an enum of strings"))
+	}
+
+	switch pt.Cast {
+	case "", CastString, CastInteger, CastNumber, CastBoolean:
+	default:
+		err = errors.Join(err, fmt.Errorf("cast must be one of %s, %s, %s or %s", CastString, CastInteger, CastNumber, CastBoolean))
+	}
+
+	return err
+}
+
+// ApplyTransforms returns the arguments of a call of the tool with the transforms of the tool applied to the
+// values of their properties. The error describes the arguments that could not be transformed.
+func (t *Tool) ApplyTransforms(arguments json.RawMessage) (json.RawMessage, error) {
+	if len(t.Transforms) == 0 || len(arguments) == 0 {
+		return arguments, nil
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(arguments, &args); err != nil || args == nil {
+		// the arguments are rejected when they are validated
+		return arguments, nil
+	}
+
+	var err error = nil
+	for _, path := range slices.Sorted(maps.Keys(t.Transforms)) {
+		parent, name, ok := lookupParent(args, path)
+		if !ok {
+			continue
+		}
+
+		transform := t.Transforms[path]
+		value, transformErr := transform.apply(parent[name], lookupSchema(t.InputSchema, path))
+		if transformErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid value of property %s: %w", path, transformErr))
+			continue
+		}
+		parent[name] = value
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(args)
+}
+
+// apply applies the transform to value, or to each of its elements if it is an array.
+func (pt *PropertyTransform) apply(value any, property *jsonschema.Schema) (any, error) {
+	if items, ok := value.([]any); ok {
+		if property != nil {
+			property = property.Items
+		}
+		transformed := make([]any, len(items))
+		for i, item := range items {
+			v, err := pt.applyValue(item, property)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			transformed[i] = v
+		}
+		return transformed, nil
+	}
+
+	return pt.applyValue(value, property)
+}
+
+func (pt *PropertyTransform) applyValue(value any, property *jsonschema.Schema) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if s, ok := value.(string); ok {
+		if pt.Trim {
+			s = strings.TrimSpace(s)
+		}
+		for _, rule := range pt.Replace {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			s = re.ReplaceAllString(s, rule.Replacement)
+		}
+		if pt.NormalizeEnum {
+			for _, option := range enumOf(property) {
+				if strings.EqualFold(strings.TrimSpace(s), option) {
+					s = option
+					break
+				}
+			}
+		}
+		if runes := []rune(s); pt.MaxLength > 0 && len(runes) > pt.MaxLength {
+			s = string(runes[:pt.MaxLength])
+		}
+		value = s
+	}
+
+	return cast(value, pt.Cast)
+}
+
+// cast converts value to the JSON type to, or returns it unchanged if to is empty.
+func cast(value any, to string) (any, error) {
+	switch to {
+	case "":
+		return value, nil
+	case CastString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case CastInteger:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return v, nil
+			}
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case CastNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+				return f, nil
+			}
+		}
+	case CastBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	}
+
+	encoded, _ := json.Marshal(value)
+	return nil, fmt.Errorf("cannot convert %s to %s", encoded, to)
+}
+
+// lookupParent returns the object holding the property at the dot-separated path in args, and the name of the
+// property in it, if the property is set.
+func lookupParent(args map[string]any, path string) (map[string]any, string, bool) {
+	segments := strings.Split(path, ".")
+	parent := args
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := parent[segment].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+		parent = child
+	}
+
+	name := segments[len(segments)-1]
+	if _, ok := parent[name]; !ok {
+		return nil, "", false
+	}
+	return parent, name, true
+}
+
+// lookupSchema returns the schema of the property at the dot-separated path of schema, or nil if it has none.
+func lookupSchema(schema *jsonschema.Schema, path string) *jsonschema.Schema {
+	for _, segment := range strings.Split(path, ".") {
+		if schema == nil {
+			return nil
+		}
+		schema = schema.Properties[segment]
+	}
+	return schema
+}
+
+// enumOf returns the string values of the enum of property, or of its items if it is an array.
+func enumOf(property *jsonschema.Schema) []string {
+	if property == nil {
+		return nil
+	}
+	if property.Items != nil && len(property.Enum) == 0 {
+		property = property.Items
+	}
+
+	var values []string
+	for _, v := range property.Enum {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,237 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestToolApplyTransforms(t *testing.T) {
+	tt := []struct {
+		name        string
+		transforms  map[string]PropertyTransform
+		arguments   string
+		expected    string
+		errContains string
+	}{
+		{
+			name:      "no transforms",
+			arguments: `{"title": " a "}`,
+			expected:  `{"title": " a "}`,
+		},
+		{
+			name:       "trim",
+			transforms: map[string]PropertyTransform{"title": {Trim: true}},
+			arguments:  `{"title": "  Broken printer\n"}`,
+			expected:   `{"title": "Broken printer"}`,
+		},
+		{
+			name: "regex replace in order",
+			transforms: map[string]PropertyTransform{"title": {Replace: []ReplaceRule{
+				{Pattern: `[^A-Za-z0-9 ]+`, Replacement: ""},
+				{Pattern: `\s+`, Replacement: "-"},
+			}}},
+			arguments: `{"title": "Broken; printer && rm -rf"}`,
+			expected:  `{"title": "Broken-printer-rm-rf"}`,
+		},
+		{
+			name: "regex replace with groups",
+			transforms: map[string]PropertyTransform{"title": {Replace: []ReplaceRule{
+				{Pattern: `^(\w+)@example\.com$`, Replacement: "$1"},
+			}}},
+			arguments: `{"title": "jane@example.com"}`,
+			expected:  `{"title": "jane"}`,
+		},
+		{
+			name:       "max length counts characters",
+			transforms: map[string]PropertyTransform{"title": {MaxLength: 4}},
+			arguments:  `{"title": "héllo wörld"}`,
+			expected:   `{"title": "héll"}`,
+		},
+		{
+			name:       "enum normalization",
+			transforms: map[string]PropertyTransform{"priority": {NormalizeEnum: true}},
+			arguments:  `{"priority": " HIGH "}`,
+			expected:   `{"priority": "high"}`,
+		},
+		{
+			name:       "enum normalization leaves unknown values to validation",
+			transforms: map[string]PropertyTransform{"priority": {NormalizeEnum: true}},
+			arguments:  `{"priority": "urgent"}`,
+			expected:   `{"priority": "urgent"}`,
+		},
+		{
+			name:       "enum normalization of array elements",
+			transforms: map[string]PropertyTransform{"labels": {NormalizeEnum: true}},
+			arguments:  `{"labels": ["BUG", "Docs"]}`,
+			expected:   `{"labels": ["bug", "docs"]}`,
+		},
+		{
+			name:       "cast string to integer",
+			transforms: map[string]PropertyTransform{"count": {Trim: true, Cast: CastInteger}},
+			arguments:  `{"count": " 42 "}`,
+			expected:   `{"count": 42}`,
+		},
+		{
+			name:        "cast fractional number to integer",
+			transforms:  map[string]PropertyTransform{"count": {Cast: CastInteger}},
+			arguments:   `{"count": 4.5}`,
+			errContains: "invalid value of property count: cannot convert 4.5 to integer",
+		},
+		{
+			name:       "cast string to number",
+			transforms: map[string]PropertyTransform{"count": {Cast: CastNumber}},
+			arguments:  `{"count": "4.5"}`,
+			expected:   `{"count": 4.5}`,
+		},
+		{
+			name:       "cast number to string",
+			transforms: map[string]PropertyTransform{"title": {Cast: CastString}},
+			arguments:  `{"title": 1234}`,
+			expected:   `{"title": "1234"}`,
+		},
+		{
+			name:       "cast string to boolean",
+			transforms: map[string]PropertyTransform{"urgent": {Cast: CastBoolean}},
+			arguments:  `{"urgent": "TRUE"}`,
+			expected:   `{"urgent": true}`,
+		},
+		{
+			name:        "cast invalid string to boolean",
+			transforms:  map[string]PropertyTransform{"urgent": {Cast: CastBoolean}},
+			arguments:   `{"urgent": "maybe"}`,
+			errContains: `invalid value of property urgent: cannot convert "maybe" to boolean`,
+		},
+		{
+			name:        "cast array elements",
+			transforms:  map[string]PropertyTransform{"ids": {Cast: CastInteger}},
+			arguments:   `{"ids": ["1", "two"]}`,
+			errContains: `invalid value of property ids: element 1: cannot convert "two" to integer`,
+		},
+		{
+			name:       "nested property",
+			transforms: map[string]PropertyTransform{"filter.status": {Trim: true, NormalizeEnum: true}},
+			arguments:  `{"filter": {"status": " Open"}}`,
+			expected:   `{"filter": {"status": "open"}}`,
+		},
+		{
+			name:       "missing and null properties are left unchanged",
+			transforms: map[string]PropertyTransform{"title": {Cast: CastInteger}, "filter.status": {Trim: true}},
+			arguments:  `{"title": null}`,
+			expected:   `{"title": null}`,
+		},
+		{
+			name:       "invalid arguments are left to validation",
+			transforms: map[string]PropertyTransform{"title": {Trim: true}},
+			arguments:  `["a"]`,
+			expected:   `["a"]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				InputSchema: testTransformsInputSchema(),
+				Transforms:  tc.transforms,
+			}
+
+			result, err := tool.ApplyTransforms(json.RawMessage(tc.arguments))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestToolValidateTransforms(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name        string
+		transforms  map[string]PropertyTransform
+		errContains string
+	}{
+		{
+			name: "valid transforms",
+			transforms: map[string]PropertyTransform{
+				"title":         {Trim: true, Replace: []ReplaceRule{{Pattern: `\s+`, Replacement: " "}}, MaxLength: 80},
+				"priority":      {NormalizeEnum: true},
+				"labels":        {NormalizeEnum: true},
+				"count":         {Cast: CastInteger},
+				"filter.status": {NormalizeEnum: true},
+			},
+		},
+		{
+			name:        "unknown property",
+			transforms:  map[string]PropertyTransform{"owner": {Trim: true}},
+			errContains: "invalid tool: transforms[owner] does not match a property of the inputSchema",
+		},
+		{
+			name:        "invalid pattern",
+			transforms:  map[string]PropertyTransform{"title": {Replace: []ReplaceRule{{Pattern: `(`}}}},
+			errContains: "invalid tool: transforms[title] is not valid: replace[0] has an invalid pattern",
+		},
+		{
+			name:        "negative max length",
+			transforms:  map[string]PropertyTransform{"title": {MaxLength: -1}},
+			errContains: "maxLength must not be negative",
+		},
+		{
+			name:        "enum normalization without enum",
+			transforms:  map[string]PropertyTransform{"title": {NormalizeEnum: true}},
+			errContains: "normalizeEnum requires the property to have an enum of strings",
+		},
+		{
+			name:        "unknown cast",
+			transforms:  map[string]PropertyTransform{"count": {Cast: "date"}},
+			errContains: "cast must be one of string, integer, number or boolean",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "create_ticket",
+				Description:             "Create a ticket",
+				InputSchema:             testTransformsInputSchema(),
+				Transforms:              tc.transforms,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func testTransformsInputSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"title":    {Type: "string"},
+			"priority": {Type: "string", Enum: []any{"low", "normal", "high"}},
+			"labels":   {Type: "array", Items: &jsonschema.Schema{Type: "string", Enum: []any{"bug", "docs"}}},
+			"count":    {Type: "integer"},
+			"urgent":   {Type: "boolean"},
+			"ids":      {Type: "array", Items: &jsonschema.Schema{Type: "integer"}},
+			"filter": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"status": {Type: "string", Enum: []any{"open", "closed"}},
+				},
+			},
+		},
+	}
+}
@@ -1,7 +1,11 @@
 package mcpfile
 
 import (
+	"slices"
+
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
 	"github.com/google/jsonschema-go/jsonschema"
 )
 
@@ -27,20 +31,79 @@ type Tool struct {
 	// JSON Schema describing input parameters.
 	InputSchema *jsonschema.Schema `json:"inputSchema" jsonschema:"required"`
 
-	// Optional JSON Schema describing output.
+	// Values of input properties set by the server before the arguments are validated. Defaults of properties
+	// of the inputSchema are used when the model does not provide them. Other properties are hidden from the
+	// model and always set to their default. String values can reference environment variables as ${VAR}.
+	Defaults map[string]any `json:"defaults,omitempty" jsonschema:"optional"`
+
+	// Transforms normalizing the values of input properties provided by the model before the arguments are
+	// validated, keyed by the dot-separated path of the property (e.g. "filter.status").
+	Transforms map[string]PropertyTransform `json:"transforms,omitempty" jsonschema:"optional"`
+
+	// If set, the user is asked for the missing required arguments of calls with MCP elicitation when the
+	// client supports it, instead of rejecting the calls.
+	Elicitation *ToolElicitation `json:"elicitation,omitempty" jsonschema:"optional"`
+
+	// Optional JSON Schema describing output. Successful results are validated against it.
 	OutputSchema *jsonschema.Schema `json:"outputSchema,omitempty" jsonschema:"optional"`
 
+	// If true, values in the output are converted to the types declared in the outputSchema
+	// where possible (e.g. "42" to 42) before the output is validated.
+	CoerceOutputTypes bool `json:"coerceOutputTypes,omitempty" jsonschema:"optional"`
+
 	// Object describing how to execute the tool.
-	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/ExtendsConfig"`
+	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/SqlInvocationConfig;#/$defs/FileInvocationConfig;#/$defs/GrpcInvocationConfig;#/$defs/ProxyInvocationConfig;#/$defs/PluginInvocationConfig;#/$defs/QueueInvocationConfig;#/$defs/K8sInvocationConfig;#/$defs/SshInvocationConfig;#/$defs/ScriptInvocationConfig;#/$defs/WasmInvocationConfig;#/$defs/SmtpInvocationConfig;#/$defs/ExtendsConfig"`
 
 	// OAuth scopes required to invoke this tool.
 	RequiredScopes []string `json:"requiredScopes,omitempty" jsonschema:"optional"`
 
+	// If true, the tool is not served. Disabled tools are still validated.
+	Disabled bool `json:"disabled,omitempty" jsonschema:"optional"`
+
+	// Names of the toolsets of the tool, groups of tools clients can select so that they are only served the
+	// tools of those groups, e.g. github or jira. Tools without toolsets are served to every client.
+	Toolsets []string `json:"toolsets,omitempty" jsonschema:"optional"`
+
+	// Maximum number of calls of the tool running concurrently. Calls over the limit wait for a running
+	// call to complete, within the queue limits of the concurrency config of the server. Unlimited if 0.
+	MaxConcurrency int `json:"maxConcurrency,omitempty" jsonschema:"optional"`
+
 	// Annotations to indicate tool behaviour to the client.
 	Annotations *ToolAnnotations `json:"annotations" jsonschema:"optional"`
 
+	// Optional transform extracting or reshaping the JSON response before it is returned.
+	// Only supported for HTTP invocations.
+	ResponseTransform *invocation.ResponseTransform `json:"responseTransform,omitempty" jsonschema:"optional"`
+
+	// How the structured content of results is set (default: auto): auto keeps the structured content set by
+	// the invocation, e.g. from JSON object responses, disabled drops it, and required parses the text of
+	// results without structured content as a JSON object, failing the call if it is not one.
+	StructuredContent string `json:"structuredContent,omitempty" jsonschema:"optional,enum=auto,enum=disabled,enum=required"`
+
+	// Optional representation of the result of the tool: text, structured content only, an embedded resource,
+	// or a link to a resource of the MCP file. By default, the result is returned as the invocation returns it.
+	ResultContent *ToolResultContent `json:"resultContent,omitempty" jsonschema:"optional"`
+
+	// Optional instruction for a model of the client to rewrite the result of the tool, e.g. to summarize it,
+	// with MCP sampling. The text generated by the model is returned instead of the result.
+	PostProcess *ToolPostProcess `json:"postProcess,omitempty" jsonschema:"optional"`
+
+	// Tests of the tool, calls and the results they are expected to return, run by genmcp test.
+	// They are not used by the server.
+	Tests []*ToolTest `json:"tests,omitempty" jsonschema:"optional"`
+
 	// Resolved input schema for validation (internal use only).
 	ResolvedInputSchema *jsonschema.Resolved `json:"-"`
+
+	// Input schema extended with the properties only set by defaults, used by invocations (internal use only).
+	InvocationInputSchema *jsonschema.Schema `json:"-"`
+
+	// Resolved output schema for validation (internal use only).
+	ResolvedOutputSchema *jsonschema.Resolved `json:"-"`
+
+	// Headers of the incoming request and environment variables the templates of the invocation can reference,
+	// set when the server serving the tool is validated (internal use only).
+	TemplateAllowlist *template.Allowlist `json:"-"`
 }
 
 type ToolAnnotations struct {
@@ -64,7 +127,6 @@ type ToolAnnotations struct {
 func (t Tool) GetName() string                     { return t.Name }
 func (t Tool) GetDescription() string              { return t.Description }
 func (t Tool) PrimitiveType() string               { return PrimitiveTypeTool }
-func (t Tool) GetInputSchema() *jsonschema.Schema  { return t.InputSchema }
 func (t Tool) GetOutputSchema() *jsonschema.Schema { return t.OutputSchema }
 func (t Tool) GetInvocationConfig() invocation.InvocationConfig {
 	if t.InvocationConfigWrapper == nil {
@@ -78,9 +140,20 @@ func (t Tool) GetInvocationType() string {
 	}
 	return t.InvocationConfigWrapper.Type
 }
-func (t Tool) GetRequiredScopes() []string                  { return t.RequiredScopes }
-func (t Tool) GetResolvedInputSchema() *jsonschema.Resolved { return t.ResolvedInputSchema }
-func (t Tool) GetURITemplate() string                       { return "" }
+func (t Tool) GetRequiredScopes() []string                         { return t.RequiredScopes }
+func (t Tool) GetResolvedInputSchema() *jsonschema.Resolved        { return t.ResolvedInputSchema }
+func (t Tool) GetURITemplate() string                              { return "" }
+func (t Tool) GetResponseTransform() *invocation.ResponseTransform { return t.ResponseTransform }
+func (t Tool) GetTemplateAllowlist() *template.Allowlist           { return t.TemplateAllowlist }
+
+// GetInputSchema returns the input schema the invocation of the tool validates and uses the arguments with,
+// including the properties only set by the defaults of the tool.
+func (t Tool) GetInputSchema() *jsonschema.Schema {
+	if t.InvocationInputSchema != nil {
+		return t.InvocationInputSchema
+	}
+	return t.InputSchema
+}
 
 // Prompt represents a natural-language or LLM-style function invocation.
 type Prompt struct {
@@ -103,13 +176,23 @@ type Prompt struct {
 	OutputSchema *jsonschema.Schema `json:"outputSchema,omitempty" jsonschema:"optional"`
 
 	// Object describing how to invoke the prompt.
-	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/ExtendsConfig"`
+	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/SqlInvocationConfig;#/$defs/FileInvocationConfig;#/$defs/GrpcInvocationConfig;#/$defs/ProxyInvocationConfig;#/$defs/InlineInvocationConfig;#/$defs/PluginInvocationConfig;#/$defs/ExtendsConfig"`
+
+	// Invocations completing the values of the arguments of the prompt, keyed by argument name. They are called
+	// with the partial value of the argument and the arguments already set, as strings, and return a JSON
+	// array of values or a value per line. Arguments without completion invocation are completed from the enum
+	// of their property in the inputSchema.
+	Completions map[string]*invocation.InvocationConfigWrapper `json:"completions,omitempty" jsonschema:"optional"`
 
 	// OAuth scopes required to invoke this prompt.
 	RequiredScopes []string `json:"requiredScopes,omitempty" jsonschema:"optional"`
 
 	// Resolved input schema for validation (internal use only).
 	ResolvedInputSchema *jsonschema.Resolved `json:"-"`
+
+	// Headers of the incoming request and environment variables the templates of the invocation can reference,
+	// set when the server serving the prompt is validated (internal use only).
+	TemplateAllowlist *template.Allowlist `json:"-"`
 }
 
 func (p Prompt) GetName() string                     { return p.Name }
@@ -129,9 +212,11 @@ func (p Prompt) GetInvocationType() string {
 	}
 	return p.InvocationConfigWrapper.Type
 }
-func (p Prompt) GetRequiredScopes() []string                  { return p.RequiredScopes }
-func (p Prompt) GetResolvedInputSchema() *jsonschema.Resolved { return p.ResolvedInputSchema }
-func (p Prompt) GetURITemplate() string                       { return "" }
+func (p Prompt) GetRequiredScopes() []string                         { return p.RequiredScopes }
+func (p Prompt) GetResolvedInputSchema() *jsonschema.Resolved        { return p.ResolvedInputSchema }
+func (p Prompt) GetURITemplate() string                              { return "" }
+func (p Prompt) GetResponseTransform() *invocation.ResponseTransform { return nil }
+func (p Prompt) GetTemplateAllowlist() *template.Allowlist           { return p.TemplateAllowlist }
 
 // PromptArgument defines a variable that can be substituted into a prompt template.
 type PromptArgument struct {
@@ -175,13 +260,17 @@ type Resource struct {
 	OutputSchema *jsonschema.Schema `json:"outputSchema,omitempty" jsonschema:"optional"`
 
 	// Object describing how to invoke the resource.
-	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/ExtendsConfig"`
+	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/SqlInvocationConfig;#/$defs/FileInvocationConfig;#/$defs/GrpcInvocationConfig;#/$defs/ProxyInvocationConfig;#/$defs/InlineInvocationConfig;#/$defs/PluginInvocationConfig;#/$defs/ExtendsConfig"`
 
 	// OAuth scopes required to access this resource.
 	RequiredScopes []string `json:"requiredScopes,omitempty" jsonschema:"optional"`
 
 	// Resolved input schema for validation (internal use only).
 	ResolvedInputSchema *jsonschema.Resolved `json:"-"`
+
+	// Headers of the incoming request and environment variables the templates of the invocation can reference,
+	// set when the server serving the resource is validated (internal use only).
+	TemplateAllowlist *template.Allowlist `json:"-"`
 }
 
 func (r Resource) GetName() string                     { return r.Name }
@@ -201,9 +290,11 @@ func (r Resource) GetInvocationType() string {
 	}
 	return r.InvocationConfigWrapper.Type
 }
-func (r Resource) GetRequiredScopes() []string                  { return r.RequiredScopes }
-func (r Resource) GetResolvedInputSchema() *jsonschema.Resolved { return r.ResolvedInputSchema }
-func (r Resource) GetURITemplate() string                       { return "" }
+func (r Resource) GetRequiredScopes() []string                         { return r.RequiredScopes }
+func (r Resource) GetResolvedInputSchema() *jsonschema.Resolved        { return r.ResolvedInputSchema }
+func (r Resource) GetURITemplate() string                              { return "" }
+func (r Resource) GetResponseTransform() *invocation.ResponseTransform { return nil }
+func (r Resource) GetTemplateAllowlist() *template.Allowlist           { return r.TemplateAllowlist }
 
 // ResourceTemplate represents a reusable URI-based template for resources.
 type ResourceTemplate struct {
@@ -229,13 +320,28 @@ type ResourceTemplate struct {
 	OutputSchema *jsonschema.Schema `json:"outputSchema,omitempty" jsonschema:"optional"`
 
 	// Object describing how to invoke the resource template.
-	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/ExtendsConfig"`
+	InvocationConfigWrapper *invocation.InvocationConfigWrapper `json:"invocation" jsonschema:"required,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/SqlInvocationConfig;#/$defs/FileInvocationConfig;#/$defs/GrpcInvocationConfig;#/$defs/ProxyInvocationConfig;#/$defs/PluginInvocationConfig;#/$defs/ExtendsConfig"`
+
+	// Optional invocation enumerating the resources matching the template, which are listed by resources/list
+	// next to the static resources. It is invoked without arguments, and returns a JSON array of URIs, or of
+	// objects with a uri and optionally a name, title, description and mimeType, or a URI per line.
+	List *invocation.InvocationConfigWrapper `json:"list,omitempty" jsonschema:"optional,oneof_ref=#/$defs/HttpInvocationConfig;#/$defs/CliInvocationConfig;#/$defs/SqlInvocationConfig;#/$defs/FileInvocationConfig;#/$defs/GrpcInvocationConfig;#/$defs/ProxyInvocationConfig;#/$defs/PluginInvocationConfig;#/$defs/ExtendsConfig"`
+
+	// Invocations completing the values of the variables of the uriTemplate, keyed by variable name. They are
+	// called with the partial value of the variable and the variables already set, as strings, and return a
+	// JSON array of values or a value per line. Variables without completion invocation are completed from the
+	// enum of their property in the inputSchema.
+	Completions map[string]*invocation.InvocationConfigWrapper `json:"completions,omitempty" jsonschema:"optional"`
 
 	// OAuth scopes required to access this resource template.
 	RequiredScopes []string `json:"requiredScopes,omitempty" jsonschema:"optional"`
 
 	// Resolved input schema for validation (internal use only).
 	ResolvedInputSchema *jsonschema.Resolved `json:"-"`
+
+	// Headers of the incoming request and environment variables the templates of the invocation can reference,
+	// set when the server serving the resource template is validated (internal use only).
+	TemplateAllowlist *template.Allowlist `json:"-"`
 }
 
 func (r ResourceTemplate) GetName() string                     { return r.Name }
@@ -255,9 +361,28 @@ func (r ResourceTemplate) GetInvocationType() string {
 	}
 	return r.InvocationConfigWrapper.Type
 }
-func (r ResourceTemplate) GetRequiredScopes() []string                  { return r.RequiredScopes }
-func (r ResourceTemplate) GetResolvedInputSchema() *jsonschema.Resolved { return r.ResolvedInputSchema }
-func (r ResourceTemplate) GetURITemplate() string                       { return r.URITemplate }
+func (r ResourceTemplate) GetRequiredScopes() []string                         { return r.RequiredScopes }
+func (r ResourceTemplate) GetResolvedInputSchema() *jsonschema.Resolved        { return r.ResolvedInputSchema }
+func (r ResourceTemplate) GetURITemplate() string                              { return r.URITemplate }
+func (r ResourceTemplate) GetResponseTransform() *invocation.ResponseTransform { return nil }
+func (r ResourceTemplate) GetTemplateAllowlist() *template.Allowlist           { return r.TemplateAllowlist }
+
+// ListResource returns the resource invoked to enumerate the resources of the template, or nil if it has
+// no list invocation.
+func (r ResourceTemplate) ListResource() *Resource {
+	if r.List == nil {
+		return nil
+	}
+
+	return &Resource{
+		Name:                    r.Name,
+		Description:             r.Description,
+		URI:                     r.URITemplate,
+		InvocationConfigWrapper: r.List,
+		RequiredScopes:          r.RequiredScopes,
+		TemplateAllowlist:       r.TemplateAllowlist,
+	}
+}
 
 // StreamableHTTPConfig defines configuration for the HTTP-based runtime.
 type StreamableHTTPConfig struct {
@@ -322,6 +447,37 @@ type MCPToolDefinitions struct {
 	ResourceTemplates []*ResourceTemplate `json:"resourceTemplates,omitempty" jsonschema:"optional"`
 }
 
+// ToolsetNames returns the sorted names of the toolsets of the tools of m, each once.
+func (m MCPToolDefinitions) ToolsetNames() []string {
+	var names []string
+	for _, t := range m.Tools {
+		for _, name := range t.Toolsets {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SetTemplateAllowlist sets the headers of the incoming request and the environment variables the templates of
+// the invocations of the tools, prompts, resources and resource templates of m can reference.
+func (m MCPToolDefinitions) SetTemplateAllowlist(allowlist *template.Allowlist) {
+	for _, t := range m.Tools {
+		t.TemplateAllowlist = allowlist
+	}
+	for _, p := range m.Prompts {
+		p.TemplateAllowlist = allowlist
+	}
+	for _, r := range m.Resources {
+		r.TemplateAllowlist = allowlist
+	}
+	for _, rt := range m.ResourceTemplates {
+		rt.TemplateAllowlist = allowlist
+	}
+}
+
 // MCPToolDefinitionsFile is the root structure of an MCP file (mcpfile.yaml).
 type MCPToolDefinitionsFile struct {
 	// Kind identifies the type of GenMCP config file.
@@ -330,6 +486,11 @@ type MCPToolDefinitionsFile struct {
 	// Version of the GenMCP config file format.
 	SchemaVersion string `json:"schemaVersion" jsonschema:"required"`
 
+	// Config files of the same kind this file is merged onto, in order: paths relative to this file, http(s)
+	// URLs, or OCI artifacts (oci://registry/repository:tag). Remote files are pinned by genmcp lock. Extended
+	// files can be written as objects with from and a prefix added to the names of their tools, e.g. crm_.
+	Extends []inherit.Reference `json:"extends,omitempty" jsonschema:"optional"`
+
 	// MCP server definition.
 	MCPToolDefinitions `json:",inline"`
 }
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"unicode"
 
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 )
 
@@ -42,7 +44,9 @@ func (t *Tool) Validate(invocationValidator InvocationValidator) error {
 	if t.InputSchema == nil {
 		err = errors.Join(err, fmt.Errorf("invalid tool: inputSchema is required"))
 	} else {
-		resolved, schemaErr := t.InputSchema.Resolve(nil)
+		// invocations validate the arguments with the properties only set by defaults
+		t.InvocationInputSchema = t.invocationInputSchema()
+		resolved, schemaErr := parsecache.ResolveSchema(t.GetInputSchema())
 		if schemaErr != nil {
 			err = errors.Join(err, fmt.Errorf("invalid tool: inputSchema is not valid: %w", schemaErr))
 		} else {
@@ -50,16 +54,120 @@ func (t *Tool) Validate(invocationValidator InvocationValidator) error {
 		}
 	}
 
+	for name, value := range t.Defaults {
+		if name == "" {
+			err = errors.Join(err, fmt.Errorf("invalid tool: defaults must not have empty property names"))
+		}
+		for _, env := range envReferences(value) {
+			if !t.TemplateAllowlist.EnvAllowed(t.Name, env) {
+				err = errors.Join(err, fmt.Errorf("invalid tool: defaults[%s] references environment variable '%s', which is not in the allowedEnv of the security config of the server", name, env))
+			}
+		}
+	}
+
+	for path, transform := range t.Transforms {
+		property := lookupSchema(t.InputSchema, path)
+		if property == nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: transforms[%s] does not match a property of the inputSchema", path))
+			continue
+		}
+		if transformErr := transform.Validate(property); transformErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: transforms[%s] is not valid: %w", path, transformErr))
+		}
+	}
+
 	if t.InputSchema != nil && strings.ToLower(t.InputSchema.Type) != "object" {
 		err = errors.Join(err, fmt.Errorf("invalid tool: inputScheme must be type object at the root"))
 	}
 
+	if t.OutputSchema != nil {
+		if strings.ToLower(t.OutputSchema.Type) != "object" {
+			err = errors.Join(err, fmt.Errorf("invalid tool: outputSchema must be type object at the root"))
+		} else if resolved, schemaErr := parsecache.ResolveSchema(t.OutputSchema); schemaErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: outputSchema is not valid: %w", schemaErr))
+		} else {
+			t.ResolvedOutputSchema = resolved
+		}
+	} else if t.CoerceOutputTypes {
+		err = errors.Join(err, fmt.Errorf("invalid tool: coerceOutputTypes requires an outputSchema"))
+	}
+
+	if t.MaxConcurrency < 0 {
+		err = errors.Join(err, fmt.Errorf("invalid tool: maxConcurrency must not be negative"))
+	}
+
+	// clients select toolsets by comma-separated lists of names
+	for i, toolset := range t.Toolsets {
+		if toolset == "" {
+			err = errors.Join(err, fmt.Errorf("invalid tool: toolsets[%d] must not be empty", i))
+		} else if strings.IndexFunc(toolset, func(ch rune) bool {
+			return !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && !strings.ContainsRune("-_.", ch)
+		}) >= 0 {
+			err = errors.Join(err, fmt.Errorf("invalid tool: toolset '%s' must only contain letters, digits, '-', '_' and '.'", toolset))
+		}
+	}
+
 	if t.InvocationConfigWrapper == nil || t.InvocationConfigWrapper.Config == nil {
 		err = errors.Join(err, fmt.Errorf("invalid tool: invocation is not set for the tool"))
 	} else if invocationErr := invocationValidator(t); invocationErr != nil {
 		err = errors.Join(err, fmt.Errorf("invalid tool: invocation is not valid: %w", invocationErr))
 	}
 
+	if t.ResponseTransform != nil {
+		if transformErr := t.ResponseTransform.Validate(); transformErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: responseTransform is not valid: %w", transformErr))
+		}
+	}
+
+	if t.PostProcess != nil {
+		if postProcessErr := t.PostProcess.Validate(); postProcessErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: postProcess is not valid: %w", postProcessErr))
+		}
+		if t.OutputSchema != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: postProcess cannot be used with outputSchema, as the result is text generated by the model"))
+		}
+	}
+
+	switch t.StructuredContent {
+	case "", StructuredContentAuto, StructuredContentRequired:
+	case StructuredContentDisabled:
+		if t.OutputSchema != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: structuredContent cannot be disabled with outputSchema, which requires structured content"))
+		}
+		if t.ResultContent != nil && t.ResultContent.Type == ResultContentStructured {
+			err = errors.Join(err, fmt.Errorf("invalid tool: structuredContent cannot be disabled with resultContent of type %s", ResultContentStructured))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf("invalid tool: structuredContent must be one of (%s, %s, %s), received %s",
+			StructuredContentAuto, StructuredContentDisabled, StructuredContentRequired, t.StructuredContent))
+	}
+
+	if t.ResultContent != nil {
+		if resultContentErr := t.ResultContent.Validate(t); resultContentErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: resultContent is not valid: %w", resultContentErr))
+		}
+	}
+
+	if t.Elicitation != nil && t.InputSchema != nil {
+		if elicitationErr := t.Elicitation.Validate(t.InputSchema); elicitationErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: elicitation is not valid: %w", elicitationErr))
+		}
+	}
+
+	testNames := make(map[string]struct{}, len(t.Tests))
+	for i, test := range t.Tests {
+		if test == nil {
+			continue
+		}
+		if testErr := test.Validate(); testErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tool: tests[%d] is not valid: %w", i, testErr))
+		}
+		if _, ok := testNames[test.Name]; ok && test.Name != "" {
+			err = errors.Join(err, fmt.Errorf("invalid tool: tests[%d] has the same name as another test: %s", i, test.Name))
+		}
+		testNames[test.Name] = struct{}{}
+	}
+
 	return err
 }
 
@@ -75,7 +183,7 @@ func (p *Prompt) Validate(invocationValidator InvocationValidator) error {
 	if p.InputSchema == nil {
 		err = errors.Join(err, fmt.Errorf("invalid prompt: inputSchema is required"))
 	} else {
-		resolved, schemaErr := p.InputSchema.Resolve(nil)
+		resolved, schemaErr := parsecache.ResolveSchema(p.InputSchema)
 		if schemaErr != nil {
 			err = errors.Join(err, fmt.Errorf("invalid prompt: inputSchema is not valid: %w", schemaErr))
 		} else {
@@ -91,6 +199,9 @@ func (p *Prompt) Validate(invocationValidator InvocationValidator) error {
 	} else if invocationErr := invocationValidator(p); invocationErr != nil {
 		err = errors.Join(err, fmt.Errorf("invalid prompt: invocation is not valid: %w", invocationErr))
 	}
+	if completionsErr := validateCompletions(p.InputSchema, p.Completions, p.CompletionTool, invocationValidator); completionsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid prompt: %w", completionsErr))
+	}
 	return err
 }
 
@@ -130,7 +241,7 @@ func (rt *ResourceTemplate) Validate(invocationValidator InvocationValidator) er
 	if rt.InputSchema == nil {
 		err = errors.Join(err, fmt.Errorf("invalid resource template: inputSchema is required"))
 	} else {
-		resolved, schemaErr := rt.InputSchema.Resolve(nil)
+		resolved, schemaErr := parsecache.ResolveSchema(rt.InputSchema)
 		if schemaErr != nil {
 			err = errors.Join(err, fmt.Errorf("invalid resource template: inputSchema is not valid: %w", schemaErr))
 		} else {
@@ -145,6 +256,16 @@ func (rt *ResourceTemplate) Validate(invocationValidator InvocationValidator) er
 	} else if invocationErr := invocationValidator(rt); invocationErr != nil {
 		err = errors.Join(err, fmt.Errorf("invalid resource template: invocation is not valid: %w", invocationErr))
 	}
+	if lr := rt.ListResource(); lr != nil {
+		if lr.InvocationConfigWrapper.Config == nil {
+			err = errors.Join(err, fmt.Errorf("invalid resource template: list invocation is empty"))
+		} else if listErr := invocationValidator(lr); listErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid resource template: list invocation is not valid: %w", listErr))
+		}
+	}
+	if completionsErr := validateCompletions(rt.InputSchema, rt.Completions, rt.CompletionTool, invocationValidator); completionsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid resource template: %w", completionsErr))
+	}
 	return err
 }
 
@@ -170,5 +291,21 @@ func (s *MCPToolDefinitions) Validate(invocationValidator InvocationValidator) e
 		}
 	}
 
+	for i, r := range s.Resources {
+		if resourceErr := r.Validate(invocationValidator); resourceErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid server: resources[%d] is invalid: %w", i, resourceErr))
+		}
+	}
+
+	for i, rt := range s.ResourceTemplates {
+		if templateErr := rt.Validate(invocationValidator); templateErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid server: resourceTemplates[%d] is invalid: %w", i, templateErr))
+		}
+	}
+
+	if resourcesErr := s.resolveResultResources(); resourcesErr != nil {
+		err = errors.Join(err, resourcesErr)
+	}
+
 	return err
 }
@@ -1,10 +1,12 @@
 package mcpfile
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/genmcp/gen-mcp/pkg/config"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +51,115 @@ func TestMCPFileValidate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestToolValidateToolsets(t *testing.T) {
+	noopValidator := func(primitive invocation.Primitive) error { return nil }
+
+	tt := []struct {
+		name        string
+		toolsets    []string
+		errContains string
+	}{
+		{
+			name:     "valid toolsets",
+			toolsets: []string{"github", "issue_tracking", "v2.beta"},
+		},
+		{
+			name:        "empty toolset",
+			toolsets:    []string{"github", ""},
+			errContains: "invalid tool: toolsets[1] must not be empty",
+		},
+		{
+			name:        "comma in a toolset",
+			toolsets:    []string{"github,jira"},
+			errContains: "invalid tool: toolset 'github,jira' must only contain letters, digits, '-', '_' and '.'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &Tool{
+				Name:                    "list_issues",
+				Description:             "List the open issues",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				Toolsets:                tc.toolsets,
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: testInvocationConfig{}},
+			}
+
+			err := tool.Validate(noopValidator)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestToolsetNames(t *testing.T) {
+	defs := MCPToolDefinitions{
+		Tools: []*Tool{
+			{Name: "list_issues", Toolsets: []string{"jira", "issues"}},
+			{Name: "list_pull_requests", Toolsets: []string{"github", "issues"}},
+			{Name: "get_time"},
+		},
+	}
+
+	assert.Equal(t, []string{"github", "issues", "jira"}, defs.ToolsetNames())
+	assert.Empty(t, MCPToolDefinitions{}.ToolsetNames())
+}
+
+func TestResourceTemplateValidateList(t *testing.T) {
+	listErr := errors.New("invalid list invocation")
+	validator := func(primitive invocation.Primitive) error {
+		if primitive.PrimitiveType() == PrimitiveTypeResource {
+			return listErr
+		}
+		return nil
+	}
+
+	tt := []struct {
+		name          string
+		list          *invocation.InvocationConfigWrapper
+		expectedError string
+	}{
+		{
+			name: "no list invocation",
+		},
+		{
+			name:          "invalid list invocation",
+			list:          &invocation.InvocationConfigWrapper{Type: "http", Config: &mockInvocationConfig{}},
+			expectedError: "invalid resource template: list invocation is not valid: invalid list invocation",
+		},
+		{
+			name:          "empty list invocation",
+			list:          &invocation.InvocationConfigWrapper{},
+			expectedError: "invalid resource template: list invocation is empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := &ResourceTemplate{
+				Name:                    "user",
+				Description:             "A user",
+				URITemplate:             "users://{id}",
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "http", Config: &mockInvocationConfig{}},
+				List:                    tc.list,
+			}
+
+			err := rt.Validate(validator)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+type mockInvocationConfig struct{}
+
+func (*mockInvocationConfig) Validate() error                       { return nil }
+func (*mockInvocationConfig) DeepCopy() invocation.InvocationConfig { return &mockInvocationConfig{} }
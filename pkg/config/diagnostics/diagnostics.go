@@ -0,0 +1,333 @@
+// Package diagnostics validates gen-mcp configuration files without starting a server. Every problem found
+// is reported as a Diagnostic anchored to the line and column of the file it refers to.
+package diagnostics
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a configuration file.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line,omitempty"`   // 1-based line, 0 if the problem is not tied to a location
+	Column   int      `json:"column,omitempty"` // 1-based column, 0 if unknown
+	Path     string   `json:"path,omitempty"`   // Path of the offending value, e.g. tools[0].invocation.http.url
+	Message  string   `json:"message"`
+}
+
+// Format formats the diagnostic as "file:line:column: severity: path: message".
+func (d Diagnostic) Format(file string) string {
+	location := file
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, d.Line)
+		if d.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, d.Column)
+		}
+	}
+
+	if d.Path == "" {
+		return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", location, d.Severity, d.Path, d.Message)
+}
+
+// Report holds the diagnostics of a configuration file, in the order they were found.
+type Report struct {
+	File        string       `json:"file"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Count returns the number of diagnostics with the given severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors reports whether the file has any errors.
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+// sort orders the diagnostics by their location in the file, keeping the order of the diagnostics of
+// a same line.
+func (r *Report) sort() {
+	slices.SortStableFunc(r.Diagnostics, func(a, b Diagnostic) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+}
+
+func (r *Report) addf(severity Severity, node *yaml.Node, p path, format string, args ...any) {
+	d := Diagnostic{
+		Severity: severity,
+		Path:     p.String(),
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+
+	r.Diagnostics = append(r.Diagnostics, d)
+}
+
+// addErr adds an error diagnostic for every error joined in err.
+func (r *Report) addErr(node *yaml.Node, p path, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			r.addErr(node, p, e)
+		}
+		return
+	}
+
+	r.addf(SeverityError, node, p, "%s", err)
+}
+
+// clearLocations removes the location of the diagnostics from index from, reported for a file merged onto
+// the files it extends, whose values can come from any of the files.
+func (r *Report) clearLocations(from int) {
+	for i := from; i < len(r.Diagnostics); i++ {
+		r.Diagnostics[i].Line, r.Diagnostics[i].Column = 0, 0
+	}
+}
+
+// resolveExtends returns data, the contents of the file at filePath, merged onto the files it extends, and
+// whether it extends any file. It returns nil if the files could not be merged.
+func resolveExtends(r *Report, data []byte, filePath string, opts inherit.Options) ([]byte, bool) {
+	resolved, err := inherit.Resolve(data, filePath, opts)
+	if err != nil {
+		r.addf(SeverityError, nil, path{"extends"}, "%s", err)
+		return nil, false
+	}
+	return resolved, !bytes.Equal(resolved, data)
+}
+
+// safely runs check, turning a panic into an error diagnostic, so that a broken file can never crash
+// the validation.
+func (r *Report) safely(node *yaml.Node, p path, check func() error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.addf(SeverityError, node, p, "validation failed unexpectedly: %v", rec)
+		}
+	}()
+
+	if err := check(); err != nil {
+		r.addErr(node, p, err)
+	}
+}
+
+// path is the location of a value in a document, made of mapping keys (string) and sequence indices (int).
+type path []any
+
+func (p path) String() string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch e := elem.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", e)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			fmt.Fprintf(&sb, "%v", e)
+		}
+	}
+	return sb.String()
+}
+
+// child returns the path of the child elem, without modifying p.
+func (p path) child(elem ...any) path {
+	return append(append(path{}, p...), elem...)
+}
+
+// document is a parsed YAML (or JSON) configuration file.
+type document struct {
+	root *yaml.Node
+}
+
+var errorLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// parseDocument parses data, reporting syntax errors. It returns nil if data could not be parsed.
+func parseDocument(r *Report, data []byte) *document {
+	var file yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		d := Diagnostic{Severity: SeverityError, Message: err.Error()}
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+			d.Message = typeErr.Errors[0]
+		}
+		if m := errorLinePattern.FindStringSubmatch(d.Message); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Message = d.Message[len(m[0]):]
+		}
+		r.Diagnostics = append(r.Diagnostics, d)
+		return nil
+	}
+
+	if len(file.Content) == 0 {
+		r.addf(SeverityError, nil, nil, "file is empty")
+		return nil
+	}
+
+	root := resolveAlias(file.Content[0])
+	if root.Kind != yaml.MappingNode {
+		r.addf(SeverityError, root, nil, "expected a mapping at the top level of the file")
+		return nil
+	}
+
+	return &document{root: root}
+}
+
+// validateDocument parses data and validates it against the JSON schema in schemaData. It returns nil if
+// data could not be parsed.
+func validateDocument(r *Report, data []byte, schemaData []byte) *document {
+	doc := parseDocument(r, data)
+	if doc == nil {
+		return nil
+	}
+
+	validateSchema(r, doc, schemaData)
+	return doc
+}
+
+// validateSchema validates doc against the JSON schema in schemaData.
+func validateSchema(r *Report, doc *document, schemaData []byte) {
+	r.safely(doc.root, nil, func() error {
+		v, err := newSchemaValidator(schemaData)
+		if err != nil {
+			return err
+		}
+		r.Diagnostics = append(r.Diagnostics, v.validate(doc.root)...)
+		return nil
+	})
+}
+
+// decode unmarshals data into v the same way the server does. It reports whether decoding succeeded.
+// Decoding errors are only reported if the schema validation found no errors, as they are almost always
+// the same errors without a location.
+func decode(r *Report, doc *document, data []byte, v any) bool {
+	decoded := false
+	reported := r.HasErrors()
+	r.safely(doc.root, nil, func() error {
+		if err := k8syaml.Unmarshal(data, v); err != nil {
+			if reported {
+				return nil
+			}
+			return fmt.Errorf("failed to parse file: %w", err)
+		}
+		decoded = true
+		return nil
+	})
+
+	return decoded
+}
+
+// lookup returns the node at p. If p does not exist, it returns the node of the deepest existing parent,
+// so that diagnostics about missing values point at the value that should contain them.
+func (d *document) lookup(p path) *yaml.Node {
+	node := d.root
+	for _, elem := range p {
+		child := childNode(node, elem)
+		if child == nil {
+			return node
+		}
+		node = child
+	}
+	return node
+}
+
+// pathOf returns the path of node in the document, or nil if it is not part of it.
+func (d *document) pathOf(node *yaml.Node) path {
+	var find func(n *yaml.Node, p path) path
+	find = func(n *yaml.Node, p path) path {
+		if n == node {
+			return p
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if found := find(n.Content[i+1], p.child(n.Content[i].Value)); found != nil {
+					return found
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range n.Content {
+				if found := find(item, p.child(i)); found != nil {
+					return found
+				}
+			}
+		}
+		return nil
+	}
+
+	return find(d.root, path{})
+}
+
+// keyNode returns the node of the key of the mapping entry at p, or the node of its value if p
+// does not point to a mapping entry.
+func (d *document) keyNode(p path) *yaml.Node {
+	if len(p) > 0 {
+		if key, ok := p[len(p)-1].(string); ok {
+			if k, _ := mappingEntry(d.lookup(p[:len(p)-1]), key); k != nil {
+				return k
+			}
+		}
+	}
+	return d.lookup(p)
+}
+
+func childNode(node *yaml.Node, elem any) *yaml.Node {
+	node = resolveAlias(node)
+	switch e := elem.(type) {
+	case string:
+		_, v := mappingEntry(node, e)
+		return v
+	case int:
+		if node.Kind == yaml.SequenceNode && e >= 0 && e < len(node.Content) {
+			return resolveAlias(node.Content[e])
+		}
+	}
+	return nil
+}
+
+// mappingEntry returns the key and value nodes of key in the mapping node, or nil if it has no such key.
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil, nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,492 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mcpFileHeader = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test
+version: "1.0"
+`
+
+func TestValidateMCPFile(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected []Diagnostic
+	}{
+		{
+			name: "valid file",
+			data: mcpFileHeader + `tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+    properties:
+      userId:
+        type: string
+  invocation:
+    http:
+      url: http://localhost/users/{userId}
+      method: GET
+`,
+		},
+		{
+			name:     "syntax error",
+			data:     "name: [a\n  b: c",
+			expected: []Diagnostic{{Severity: SeverityError, Line: 1, Message: "did not find expected ',' or ']'"}},
+		},
+		{
+			name:     "empty file",
+			data:     "",
+			expected: []Diagnostic{{Severity: SeverityError, Message: "file is empty"}},
+		},
+		{
+			name:     "not a mapping",
+			data:     "- a\n- b\n",
+			expected: []Diagnostic{{Severity: SeverityError, Line: 1, Column: 1, Message: "expected a mapping at the top level of the file"}},
+		},
+		{
+			name: "type mismatch",
+			data: `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test
+version: 1.0
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 4, Column: 10, Path: "version", Message: "expected string, got number"}},
+		},
+		{
+			name: "missing name",
+			data: `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+version: "1.0"
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 1, Column: 1, Path: "name", Message: "name is required"}},
+		},
+		{
+			name: "unknown field",
+			data: mcpFileHeader + `tools:
+- name: get_user
+  description: Get a user
+  bogus: true
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: echo
+`,
+			expected: []Diagnostic{{Severity: SeverityWarning, Line: 8, Column: 3, Path: "tools[0].bogus", Message: "unknown field 'bogus' is ignored"}},
+		},
+		{
+			name: "template variable not in input schema",
+			data: mcpFileHeader + `tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      url: http://localhost/users/{userId}
+      method: GET
+`,
+			expected: []Diagnostic{{
+				Severity: SeverityError,
+				Line:     12,
+				Column:   7,
+				Path:     "tools[0].invocation.http",
+				Message:  "failed to parse URL template: failed to create variable for parameter 'userId': path parameter userId has no corresponding property in the input schema",
+			}},
+		},
+		{
+			name: "required property not defined",
+			data: mcpFileHeader + `tools:
+- name: echo
+  description: Echo
+  inputSchema:
+    type: object
+    required: [missing]
+  invocation:
+    cli:
+      command: echo
+`,
+			expected: []Diagnostic{{Severity: SeverityWarning, Line: 10, Column: 16, Path: "tools[0].inputSchema.required[0]", Message: "required property 'missing' is not defined in properties"}},
+		},
+		{
+			name: "property not used in command",
+			data: mcpFileHeader + `tools:
+- name: echo
+  description: Echo
+  inputSchema:
+    type: object
+    properties:
+      a:
+        type: string
+      b:
+        type: string
+  invocation:
+    cli:
+      command: echo {a}
+`,
+			expected: []Diagnostic{{Severity: SeverityWarning, Line: 13, Column: 7, Path: "tools[0].inputSchema.properties.b", Message: "property 'b' is not used in the command, its value is ignored"}},
+		},
+		{
+			name: "uri template variable not in input schema",
+			data: mcpFileHeader + `resourceTemplates:
+- name: file
+  description: A file
+  uriTemplate: file:///{dir}/{name}
+  mimeType: text/plain
+  inputSchema:
+    type: object
+    properties:
+      dir:
+        type: string
+      name:
+        type: string
+  invocation:
+    cli:
+      command: cat {dir}/{name}
+- name: other
+  description: Another file
+  uriTemplate: other:///{id}
+  mimeType: text/plain
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: cat
+`,
+			expected: []Diagnostic{{Severity: SeverityWarning, Line: 22, Column: 16, Path: "resourceTemplates[1].uriTemplate", Message: "template variable 'id' is not defined in inputSchema"}},
+		},
+		{
+			name: "invalid list invocation of resource template",
+			data: mcpFileHeader + `resourceTemplates:
+- name: user
+  description: A user
+  uriTemplate: users://{id}
+  inputSchema:
+    type: object
+    properties:
+      id:
+        type: string
+  invocation:
+    http:
+      url: http://localhost/users/{id}
+      method: GET
+  list:
+    http:
+      url: http://localhost/users/{id}
+      method: GET
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 20, Column: 7, Path: "resourceTemplates[0].list.http", Message: "failed to parse URL template: failed to create variable for parameter 'id': input schema is nil"}},
+		},
+		{
+			name: "invalid completion invocation of prompt",
+			data: mcpFileHeader + `prompts:
+- name: review
+  description: Review code
+  inputSchema:
+    type: object
+    properties:
+      language:
+        type: string
+  invocation:
+    http:
+      url: http://localhost/review
+      method: GET
+  completions:
+    language:
+      http:
+        url: http://localhost/languages/{prefix}
+        method: GET
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 20, Column: 9, Path: "prompts[0].completions.language.http", Message: "failed to parse URL template: failed to create variable for parameter 'prefix': path parameter prefix has no corresponding property in the input schema"}},
+		},
+		{
+			name: "unknown resource of tool result",
+			data: mcpFileHeader + `tools:
+- name: create_report
+  description: Create a report
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: create-report
+  resultContent:
+    type: resourceLink
+    resource: report
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 15, Column: 15, Path: "tools[0].resultContent.resource", Message: "resource 'report' does not match a resource or resource template"}},
+		},
+		{
+			name: "duplicate names and empty entry",
+			data: mcpFileHeader + `tools:
+- name: echo
+  description: Echo
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: echo
+- name: echo
+  description: Echo again
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: echo again
+-
+`,
+			expected: []Diagnostic{
+				{Severity: SeverityWarning, Line: 13, Column: 9, Path: "tools[1].name", Message: "duplicate name 'echo', this definition replaces tools[0]"},
+				{Severity: SeverityError, Line: 20, Column: 2, Path: "tools[2]", Message: "tool definition is empty"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Report{}
+			validateMCPFile(r, []byte(tc.data), "mcpfile.yaml")
+			r.sort()
+			assert.Equal(t, tc.expected, r.Diagnostics)
+		})
+	}
+}
+
+func TestValidateServerConfigFile(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		profile  string
+		env      map[string]string
+		expected []Diagnostic
+	}{
+		{
+			name: "valid file",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: 8009
+`,
+		},
+		{
+			name: "runtime defaults",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+`,
+		},
+		{
+			name: "invalid runtime",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: carrier-pigeon
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 4, Column: 3, Path: "runtime", Message: "invalid runtime: transport protocol must be one of (stdio, streamablehttp), received carrier-pigeon"}},
+		},
+		{
+			name: "type mismatch",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  streamableHttpConfig:
+    port: eighty
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 5, Column: 11, Path: "runtime.streamableHttpConfig.port", Message: "expected integer, got string"}},
+		},
+		{
+			name: "environment variables",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: ${GENMCP_TEST_TRANSPORT:-streamablehttp}
+  streamableHttpConfig:
+    port: ${GENMCP_TEST_PORT}
+`,
+			env: map[string]string{"GENMCP_TEST_PORT": "8009"},
+		},
+		{
+			name: "unset environment variable",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  streamableHttpConfig:
+    port: ${GENMCP_TEST_UNSET_PORT}
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Line: 5, Column: 11, Path: "runtime.streamableHttpConfig.port", Message: "environment variable 'GENMCP_TEST_UNSET_PORT' is not set"}},
+		},
+		{
+			name: "environment variable of the wrong type",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  streamableHttpConfig:
+    port: ${GENMCP_TEST_PORT}
+`,
+			env:      map[string]string{"GENMCP_TEST_PORT": "eighty"},
+			expected: []Diagnostic{{Severity: SeverityError, Line: 5, Column: 11, Path: "runtime.streamableHttpConfig.port", Message: "expected integer, got string"}},
+		},
+		{
+			name: "profile",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: 8009
+profiles:
+  prod:
+    runtime:
+      streamableHttpConfig:
+        port: eighty
+  dev:
+    runtime:
+      streamableHttpConfig:
+        port: ${GENMCP_TEST_UNSET_PORT}
+`,
+			profile:  "prod",
+			expected: []Diagnostic{{Severity: SeverityError, Line: 11, Column: 15, Path: "runtime.streamableHttpConfig.port", Message: "expected integer, got string"}},
+		},
+		{
+			name: "unknown profile",
+			data: `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+profiles:
+  prod: {}
+`,
+			profile:  "staging",
+			expected: []Diagnostic{{Severity: SeverityError, Path: "profiles", Message: "unknown profile 'staging', expected one of: prod"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			r := &Report{}
+			validateServerConfigFile(r, []byte(tc.data), "mcpserver.yaml", tc.profile)
+			r.sort()
+			assert.Equal(t, tc.expected, r.Diagnostics)
+		})
+	}
+}
+
+func TestValidateMCPFileFromPath(t *testing.T) {
+	r := ValidateMCPFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Len(t, r.Diagnostics, 1)
+	assert.True(t, r.HasErrors())
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(mcpFileHeader+"bogus: true\n"), 0o600))
+	r = ValidateMCPFile(path)
+	assert.Equal(t, path, r.File)
+	assert.False(t, r.HasErrors())
+	assert.Equal(t, 1, r.Count(SeverityWarning))
+}
+
+func TestValidateMCPFileExtends(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte(mcpFileHeader+`tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+    properties:
+      userId:
+        type: string
+  invocation:
+    http:
+      url: http://localhost/users/{userId}
+      method: GET
+`), 0o600))
+
+	tt := []struct {
+		name     string
+		data     string
+		expected []Diagnostic
+	}{
+		{
+			name: "tools are merged with the tools of the extended file",
+			data: `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+extends: [base.yaml]
+tools:
+- name: get_user
+  invocation:
+    http:
+      method: POST
+`,
+		},
+		{
+			name: "problems of the merged file have no location",
+			data: `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+extends: [base.yaml]
+tools:
+- name: get_user
+  invocation:
+    http:
+      url: http://localhost/users/{id}
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Path: "tools[0].invocation.http", Message: "failed to parse URL template: failed to create variable for parameter 'id': path parameter id has no corresponding property in the input schema"}},
+		},
+		{
+			name: "missing extended file",
+			data: `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+extends: [missing.yaml]
+`,
+			expected: []Diagnostic{{Severity: SeverityError, Path: "extends", Message: "failed to read " + filepath.Join(dir, "missing.yaml") + ": open " + filepath.Join(dir, "missing.yaml") + ": no such file or directory"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Report{}
+			validateMCPFile(r, []byte(tc.data), filepath.Join(dir, "mcpfile.yaml"))
+			r.sort()
+			assert.Equal(t, tc.expected, r.Diagnostics)
+		})
+	}
+}
+
+func TestDiagnosticFormat(t *testing.T) {
+	tt := []struct {
+		name       string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			name:       "full location",
+			diagnostic: Diagnostic{Severity: SeverityError, Line: 3, Column: 5, Path: "tools[0].name", Message: "name is required"},
+			expected:   "mcpfile.yaml:3:5: error: tools[0].name: name is required",
+		},
+		{
+			name:       "line only",
+			diagnostic: Diagnostic{Severity: SeverityError, Line: 3, Message: "bad syntax"},
+			expected:   "mcpfile.yaml:3: error: bad syntax",
+		},
+		{
+			name:       "no location",
+			diagnostic: Diagnostic{Severity: SeverityWarning, Message: "file is empty"},
+			expected:   "mcpfile.yaml: warning: file is empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.diagnostic.Format("mcpfile.yaml"))
+		})
+	}
+}
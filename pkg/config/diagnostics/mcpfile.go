@@ -0,0 +1,289 @@
+package diagnostics
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/yosida95/uritemplate/v3"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	"github.com/genmcp/gen-mcp/specs"
+
+	// register the invocation types, so that their configs can be parsed
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/file"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/script"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/wasm"
+)
+
+// ValidateMCPFile validates the MCP file at path: its syntax, its structure against the MCP file schema, and
+// the definition of every tool, prompt, resource and resource template, including their invocation configs
+// and templates.
+func ValidateMCPFile(path string) *Report {
+	r := &Report{File: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.addf(SeverityError, nil, nil, "failed to read MCP file: %v", err)
+		return r
+	}
+
+	validateMCPFile(r, data, path)
+	r.sort()
+	return r
+}
+
+func validateMCPFile(r *Report, data []byte, filePath string) {
+	data, merged := resolveExtends(r, data, filePath, inherit.Options{Cache: true})
+	if data == nil {
+		return
+	}
+	if merged {
+		defer r.clearLocations(len(r.Diagnostics))
+	}
+
+	doc := validateDocument(r, data, specs.MCPFileSchema)
+	if doc == nil {
+		return
+	}
+
+	mcpFile := &definitions.MCPToolDefinitionsFile{}
+	if !decode(r, doc, data, mcpFile) {
+		return
+	}
+
+	c := &primitiveChecker{report: r, doc: doc}
+	c.check(&mcpFile.MCPToolDefinitions)
+}
+
+// primitiveChecker runs the semantic checks of the primitives of an MCP file.
+type primitiveChecker struct {
+	report *Report
+	doc    *document
+}
+
+// primitive is implemented by tools, prompts, resources and resource templates.
+type primitive interface {
+	invocation.Primitive
+	Validate(invocationValidator definitions.InvocationValidator) error
+}
+
+func (c *primitiveChecker) check(defs *definitions.MCPToolDefinitions) {
+	if defs.Name == "" {
+		c.report.addf(SeverityError, c.doc.root, path{"name"}, "name is required")
+	}
+	if defs.Version == "" {
+		c.report.addf(SeverityError, c.doc.root, path{"version"}, "version is required")
+	}
+
+	for i, t := range defs.Tools {
+		c.checkPrimitive(path{"tools", i}, t)
+		c.checkResultResource(path{"tools", i}, defs, t)
+	}
+	for i, p := range defs.Prompts {
+		c.checkPrimitive(path{"prompts", i}, p)
+		if p != nil {
+			c.checkCompletionInvocations(path{"prompts", i}, p.Completions, p.CompletionTool)
+		}
+	}
+	for i, res := range defs.Resources {
+		c.checkPrimitive(path{"resources", i}, res)
+	}
+	for i, rt := range defs.ResourceTemplates {
+		p := path{"resourceTemplates", i}
+		c.checkPrimitive(p, rt)
+		c.checkURITemplateVariables(p, rt)
+		c.checkListInvocation(p, rt)
+		if rt != nil {
+			c.checkCompletionInvocations(p, rt.Completions, rt.CompletionTool)
+		}
+	}
+
+	checkDuplicateNames(c, "tools", defs.Tools)
+	checkDuplicateNames(c, "prompts", defs.Prompts)
+	checkDuplicateNames(c, "resources", defs.Resources)
+	checkDuplicateNames(c, "resourceTemplates", defs.ResourceTemplates)
+}
+
+func (c *primitiveChecker) checkPrimitive(p path, prim primitive) {
+	if isNil(prim) {
+		c.report.addf(SeverityError, c.doc.lookup(p), p, "%s definition is empty", strings.TrimSuffix(fmt.Sprint(p[0]), "s"))
+		return
+	}
+
+	// the invocation is validated separately, so that its errors point to the invocation
+	c.report.safely(c.doc.lookup(p), p, func() error {
+		return prim.Validate(func(invocation.Primitive) error { return nil })
+	})
+
+	if prim.GetInputSchema() != nil {
+		c.checkRequiredProperties(p.child("inputSchema"), prim.GetInputSchema())
+	}
+
+	if prim.GetInvocationConfig() == nil {
+		return
+	}
+
+	invocationPath := p.child("invocation", prim.GetInvocationType())
+	var invoker invocation.Invoker
+	c.report.safely(c.doc.lookup(invocationPath), invocationPath, func() error {
+		var err error
+		invoker, err = invocation.CreateInvoker(prim)
+		return err
+	})
+
+	if cliInvoker, ok := invoker.(*cli.CliInvoker); ok && prim.GetInputSchema() != nil {
+		c.checkUnusedCommandProperties(p, prim.GetInputSchema(), cliInvoker)
+	}
+}
+
+// checkRequiredProperties warns about required properties that are not defined in the schema.
+func (c *primitiveChecker) checkRequiredProperties(p path, s *jsonschema.Schema) {
+	for i, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			required := p.child("required", i)
+			c.report.addf(SeverityWarning, c.doc.lookup(required), required, "required property '%s' is not defined in properties", name)
+		}
+	}
+}
+
+// checkURITemplateVariables warns about variables of the URI template of a resource template that are not
+// defined in its input schema, and so are not validated.
+func (c *primitiveChecker) checkURITemplateVariables(p path, rt *definitions.ResourceTemplate) {
+	if rt == nil || rt.URITemplate == "" {
+		return
+	}
+
+	tmpl, err := uritemplate.New(rt.URITemplate)
+	if err != nil {
+		// reported by the invocation checks
+		return
+	}
+
+	for _, name := range tmpl.Varnames() {
+		if rt.InputSchema != nil {
+			if _, ok := rt.InputSchema.Properties[name]; ok {
+				continue
+			}
+		}
+		uriTemplate := p.child("uriTemplate")
+		c.report.addf(SeverityWarning, c.doc.lookup(uriTemplate), uriTemplate, "template variable '%s' is not defined in inputSchema", name)
+	}
+}
+
+// checkListInvocation checks the invocation enumerating the resources of a resource template, if any.
+func (c *primitiveChecker) checkListInvocation(p path, rt *definitions.ResourceTemplate) {
+	if rt == nil || rt.List == nil || rt.List.Config == nil {
+		return
+	}
+
+	listPath := p.child("list", rt.List.Type)
+	c.report.safely(c.doc.lookup(listPath), listPath, func() error {
+		_, err := invocation.CreateResourceInvoker(rt.ListResource())
+		return err
+	})
+}
+
+// checkResultResource checks that the resource the result of a tool is returned as is defined in the MCP file.
+func (c *primitiveChecker) checkResultResource(p path, defs *definitions.MCPToolDefinitions, t *definitions.Tool) {
+	if t == nil || t.ResultContent == nil || t.ResultContent.Resource == "" {
+		return
+	}
+
+	name := t.ResultContent.Resource
+	for _, r := range defs.Resources {
+		if r != nil && r.Name == name {
+			return
+		}
+	}
+	for _, rt := range defs.ResourceTemplates {
+		if rt != nil && rt.Name == name {
+			return
+		}
+	}
+
+	resource := p.child("resultContent", "resource")
+	c.report.addf(SeverityError, c.doc.lookup(resource), resource, "resource '%s' does not match a resource or resource template", name)
+}
+
+// checkCompletionInvocations checks the invocations completing the arguments of a prompt or resource template.
+func (c *primitiveChecker) checkCompletionInvocations(p path, completions map[string]*invocation.InvocationConfigWrapper, completionTool func(string) *definitions.Tool) {
+	for _, argument := range slices.Sorted(maps.Keys(completions)) {
+		w := completions[argument]
+		if w == nil || w.Config == nil {
+			continue
+		}
+
+		completionPath := p.child("completions", argument, w.Type)
+		c.report.safely(c.doc.lookup(completionPath), completionPath, func() error {
+			_, err := invocation.CreateInvoker(completionTool(argument))
+			return err
+		})
+	}
+}
+
+// checkUnusedCommandProperties warns about input properties that are not used in the command of a CLI
+// invocation, as their values are silently dropped.
+func (c *primitiveChecker) checkUnusedCommandProperties(p path, s *jsonschema.Schema, invoker *cli.CliInvoker) {
+	used := make(map[string]bool)
+	for _, v := range invoker.ParsedTemplate.Variables {
+		used[rootProperty(v.Name)] = true
+		for _, name := range v.VariableNames() {
+			used[rootProperty(name)] = true
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		if !used[name] {
+			prop := p.child("inputSchema", "properties", name)
+			c.report.addf(SeverityWarning, c.doc.keyNode(prop), prop, "property '%s' is not used in the command, its value is ignored", name)
+		}
+	}
+}
+
+// checkDuplicateNames warns about primitives with the same name as an earlier one, which they replace on the server.
+func checkDuplicateNames[T interface{ GetName() string }](c *primitiveChecker, key string, prims []T) {
+	seen := make(map[string]int)
+	for i, prim := range prims {
+		if isNil(prim) || prim.GetName() == "" {
+			continue
+		}
+		if first, ok := seen[prim.GetName()]; ok {
+			name := path{key, i, "name"}
+			c.report.addf(SeverityWarning, c.doc.lookup(name), name, "duplicate name '%s', this definition replaces %s", prim.GetName(), path{key, first})
+			continue
+		}
+		seen[prim.GetName()] = i
+	}
+}
+
+// isNil reports whether v is nil, e.g. for a null entry in a list of primitives.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func rootProperty(name string) string {
+	root, _, _ := strings.Cut(name, ".")
+	return root
+}
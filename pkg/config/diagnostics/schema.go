@@ -0,0 +1,186 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// schema is the subset of JSON schema used by the gen-mcp file schemas in specs/.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*schema `json:"$defs"`
+	Type                 any                `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	OneOf                []*schema          `json:"oneOf"`
+
+	// forbidden is set for the false schema, which no value matches
+	forbidden bool
+}
+
+func (s *schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		// the true schema matches every value
+		s.forbidden = !b
+		return nil
+	}
+
+	type plain schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// schemaValidator validates documents against a schema. Missing required fields are not reported here,
+// as the semantic checks of the configuration types report them with more context.
+type schemaValidator struct {
+	root *schema
+}
+
+func newSchemaValidator(data []byte) (*schemaValidator, error) {
+	root := &schema{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+
+	return &schemaValidator{root: root}, nil
+}
+
+// validate returns the diagnostics of node against the root schema.
+func (v *schemaValidator) validate(node *yaml.Node) []Diagnostic {
+	r := &Report{}
+	v.validateNode(r, node, v.root, nil)
+	return r.Diagnostics
+}
+
+func (v *schemaValidator) validateNode(r *Report, node *yaml.Node, s *schema, p path) {
+	node = resolveAlias(node)
+	s = v.resolveRef(s)
+	if s == nil {
+		return
+	}
+
+	// null values are decoded as zero values, so they are valid wherever they appear
+	if node.ShortTag() == "!!null" {
+		return
+	}
+
+	if len(s.OneOf) > 0 {
+		v.validateOneOf(r, node, s.OneOf, p)
+	}
+
+	if types := schemaTypes(s.Type); len(types) > 0 {
+		actual := nodeType(node)
+		if !slices.Contains(types, actual) && !(actual == "integer" && slices.Contains(types, "number")) {
+			r.addf(SeverityError, node, p, "expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && node.Kind == yaml.ScalarNode {
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		if !slices.Contains(allowed, node.Value) {
+			r.addf(SeverityError, node, p, "invalid value '%s', must be one of: %s", node.Value, strings.Join(allowed, ", "))
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				// merge keys are resolved by the YAML parser
+				continue
+			}
+
+			if prop, ok := s.Properties[key.Value]; ok {
+				v.validateNode(r, value, prop, p.child(key.Value))
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.forbidden {
+				r.addf(SeverityWarning, key, p.child(key.Value), "unknown field '%s' is ignored", key.Value)
+			} else if s.AdditionalProperties != nil {
+				v.validateNode(r, value, s.AdditionalProperties, p.child(key.Value))
+			}
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				v.validateNode(r, item, s.Items, p.child(i))
+			}
+		}
+	}
+}
+
+// validateOneOf reports the diagnostics of the alternative that matches node best: the first one
+// without diagnostics, or else the one with the fewest.
+func (v *schemaValidator) validateOneOf(r *Report, node *yaml.Node, alternatives []*schema, p path) {
+	var best []Diagnostic
+	for i, alternative := range alternatives {
+		candidate := &Report{}
+		v.validateNode(candidate, node, alternative, p)
+		if len(candidate.Diagnostics) == 0 {
+			return
+		}
+		if i == 0 || len(candidate.Diagnostics) < len(best) {
+			best = candidate.Diagnostics
+		}
+	}
+
+	r.Diagnostics = append(r.Diagnostics, best...)
+}
+
+func (v *schemaValidator) resolveRef(s *schema) *schema {
+	for s != nil && s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		if !ok {
+			return nil
+		}
+		s = v.root.Defs[name]
+	}
+	return s
+}
+
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, e := range t {
+			types = append(types, fmt.Sprint(e))
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+// nodeType returns the JSON type of the value of node.
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	default:
+		return "string"
+	}
+}
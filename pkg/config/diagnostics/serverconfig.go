@@ -0,0 +1,102 @@
+package diagnostics
+
+import (
+	"errors"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/specs"
+	"go.yaml.in/yaml/v3"
+)
+
+// ValidateServerConfigFile validates the server config file at path: its syntax, its structure against the
+// server config file schema, and the runtime configuration, with the named profile merged onto it, or the
+// profile selected by GENMCP_PROFILE if profile is empty.
+func ValidateServerConfigFile(path string, profile string) *Report {
+	r := &Report{File: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.addf(SeverityError, nil, nil, "failed to read server config file: %v", err)
+		return r
+	}
+
+	validateServerConfigFile(r, data, path, profile)
+	r.sort()
+	return r
+}
+
+func validateServerConfigFile(r *Report, data []byte, filePath string, profile string) {
+	doc := parseDocument(r, data)
+	if doc == nil {
+		return
+	}
+
+	// the server merges the profile before expanding environment variables, so do the same here
+	if err := serverconfig.ApplyProfile(doc.root, profile); err != nil {
+		r.addf(SeverityError, nil, path{"profiles"}, "%v", err)
+		return
+	}
+
+	// the server expands environment variables before decoding the file, so do the same here
+	data = expandEnv(r, doc)
+	if data == nil {
+		return
+	}
+
+	// extended files are expanded before they are merged, like the file
+	data, merged := resolveExtends(r, data, filePath, inherit.Options{Preprocess: serverconfig.ExpandExtendedFile})
+	if data == nil {
+		return
+	}
+	if merged {
+		defer r.clearLocations(len(r.Diagnostics))
+		if doc = parseDocument(r, data); doc == nil {
+			return
+		}
+	}
+
+	validateSchema(r, doc, specs.MCPServerConfigSchema)
+
+	serverConfigFile := &serverconfig.MCPServerConfigFile{}
+	if !decode(r, doc, data, serverConfigFile) {
+		return
+	}
+
+	// the server applies the defaults before validating its runtime, so do the same here
+	runtime := path{"runtime"}
+	r.safely(doc.lookup(runtime), runtime, func() error {
+		serverConfigFile.ApplyDefaults()
+		return serverConfigFile.Runtime.Validate()
+	})
+}
+
+// expandEnv expands the environment variable references of doc in place, reporting references that cannot
+// be expanded. It returns the expanded file, or nil if any reference could not be expanded.
+func expandEnv(r *Report, doc *document) []byte {
+	err := serverconfig.ExpandEnv(doc.root)
+	if err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs {
+			var envErr *serverconfig.EnvError
+			if errors.As(err, &envErr) {
+				r.addf(SeverityError, envErr.Node, doc.pathOf(envErr.Node), "%s", envErr.Err)
+			} else {
+				r.addErr(doc.root, nil, err)
+			}
+		}
+		return nil
+	}
+
+	var data []byte
+	r.safely(doc.root, nil, func() error {
+		data, err = yaml.Marshal(doc.root)
+		return err
+	})
+
+	return data
+}
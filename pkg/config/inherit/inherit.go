@@ -0,0 +1,281 @@
+// Package inherit resolves the extends of GenMCP config files: the config files, local or remote, that a
+// config file is merged onto. Remote files can be pinned by digest in a lockfile next to the config file.
+package inherit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
+)
+
+// namedLists are the lists of the fields of each kind of config file whose items are merged by name: items
+// of the extending file replace the fields they set of the item of the extended file with the same name,
+// and other items are appended. Other lists are replaced.
+var namedLists = map[string]map[string]bool{
+	"MCPToolDefinitions": {"tools": true, "prompts": true, "resources": true, "resourceTemplates": true},
+}
+
+// Options holds optional settings for Resolve.
+type Options struct {
+	// Preprocess is applied to the contents of every extended file before they are merged, to process them
+	// like the config file, e.g. to expand environment variables.
+	Preprocess func(data []byte) ([]byte, error)
+
+	// Cache caches the conversions of the files to JSON with parsecache.ToJSON, which persists them on disk
+	// if enabled. They are only cached in memory otherwise. It must not be set if Preprocess inserts secrets
+	// in the files, e.g. by expanding environment variables, as they would be written to disk.
+	Cache bool
+}
+
+// Resolve returns data, the contents of the config file at path, merged onto the files it extends, as JSON.
+// data is returned unchanged if the file extends no file or is not valid YAML, so that parsing it reports
+// its errors.
+//
+// Files are deep merged in order: every extended file is merged onto the previous ones, and the config file
+// is merged onto the result. The tools of an extended MCP file are renamed first if its reference sets a
+// prefix, so the config file overrides them by their prefixed name. Objects are merged recursively, and a null value removes the field it is set
+// for. Tools, prompts, resources and resource templates are merged by name. Other values, including lists,
+// replace the values they are merged onto, and so does an invocation of a different type.
+//
+// Remote files must match the digests of the lockfile of path if it exists.
+func Resolve(data []byte, path string, opts Options) ([]byte, error) {
+	lock, err := ReadLockFile(LockFilePath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	r := newResolver(opts, lock.Digests, nil)
+	return r.resolveRoot(data, path)
+}
+
+// resolver resolves the extends of a config file. Every file is fetched once.
+type resolver struct {
+	opts Options
+
+	// locked are the digests remote files must match, by reference. Remote files can't be fetched if it is
+	// not nil and they are not in it.
+	locked map[string]string
+
+	// pinned records the digests of the fetched remote files, by reference, if not nil.
+	pinned map[string]string
+
+	// stack holds the references of the files being resolved, to detect cycles.
+	stack []string
+
+	docs map[string]map[string]any
+}
+
+func newResolver(opts Options, locked, pinned map[string]string) *resolver {
+	return &resolver{opts: opts, locked: locked, pinned: pinned, docs: make(map[string]map[string]any)}
+}
+
+func (r *resolver) resolveRoot(data []byte, path string) ([]byte, error) {
+	doc, err := decode(data, r.opts.Cache)
+	if err != nil || doc["extends"] == nil {
+		return data, nil
+	}
+
+	src, err := localSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := r.resolveDoc(src, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merged)
+}
+
+// resolveDoc returns doc, the contents of the file of src, merged onto the files it extends.
+func (r *resolver) resolveDoc(src source, doc map[string]any) (map[string]any, error) {
+	refs, err := extendsOf(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extends in %s: %w", src, err)
+	}
+	if len(refs) == 0 {
+		return doc, nil
+	}
+
+	r.stack = append(r.stack, src.id())
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	kind, _ := doc["kind"].(string)
+	var merged map[string]any
+	for _, ref := range refs {
+		baseSrc, err := src.resolve(ref.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extends '%s' in %s: %w", ref.From, src, err)
+		}
+
+		base, err := r.load(baseSrc)
+		if err != nil {
+			return nil, err
+		}
+
+		if baseKind, _ := base["kind"].(string); baseKind != kind {
+			return nil, fmt.Errorf("%s extended by %s has kind %s, expected %s", baseSrc, src, baseKind, kind)
+		}
+		if base["schemaVersion"] != doc["schemaVersion"] {
+			return nil, fmt.Errorf("%s extended by %s has schemaVersion %v, expected %v", baseSrc, src, base["schemaVersion"], doc["schemaVersion"])
+		}
+
+		if ref.Prefix != "" {
+			if kind != "MCPToolDefinitions" {
+				return nil, fmt.Errorf("invalid extends '%s' in %s: prefix is only supported by MCP files", ref.From, src)
+			}
+			prefixTools(base, ref.Prefix)
+		}
+
+		// the extends of a file are resolved, and not inherited by the files extending it
+		delete(base, "extends")
+		if merged == nil {
+			merged = base
+		} else {
+			merged = mergeDocs(merged, base, kind)
+		}
+	}
+
+	return mergeDocs(merged, doc, kind), nil
+}
+
+// load returns the contents of the file of src, merged onto the files it extends.
+func (r *resolver) load(src source) (map[string]any, error) {
+	id := src.id()
+	for i, ancestor := range r.stack {
+		if ancestor == id {
+			cycle := append(append([]string{}, r.stack[i:]...), id)
+			return nil, fmt.Errorf("extends cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if doc, ok := r.docs[id]; ok {
+		return copyValue(doc).(map[string]any), nil
+	}
+
+	data, err := r.fetch(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.opts.Preprocess != nil {
+		data, err = r.opts.Preprocess(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process %s: %w", src, err)
+		}
+	}
+
+	doc, err := decode(data, r.opts.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	doc, err = r.resolveDoc(src, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	r.docs[id] = doc
+	return copyValue(doc).(map[string]any), nil
+}
+
+// fetch returns the contents of the file of src, checking remote files against their locked digest.
+func (r *resolver) fetch(src source) ([]byte, error) {
+	id := src.id()
+	locked, ok := r.locked[id]
+	if src.remote() && r.locked != nil && !ok {
+		return nil, fmt.Errorf("%s is not in the lockfile, run genmcp lock to add it", src)
+	}
+
+	data, digest, err := src.fetch(locked)
+	if err != nil || !src.remote() {
+		return data, err
+	}
+
+	if locked != "" && digest != locked {
+		return nil, fmt.Errorf("%s has digest %s, expected %s from the lockfile, run genmcp lock to update it", src, digest, locked)
+	}
+
+	if r.pinned != nil {
+		r.pinned[id] = digest
+	}
+	return data, nil
+}
+
+func extendsOf(doc map[string]any) ([]Reference, error) {
+	raw, ok := doc["extends"].([]any)
+	if !ok {
+		if doc["extends"] == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("expected a list of references")
+	}
+
+	refs := make([]Reference, 0, len(raw))
+	for i, v := range raw {
+		var ref Reference
+		switch v := v.(type) {
+		case string:
+			ref.From = v
+		case map[string]any:
+			for key := range v {
+				if key != "from" && key != "prefix" {
+					return nil, fmt.Errorf("extends[%d] has unknown field '%s'", i, key)
+				}
+			}
+			prefix, ok := v["prefix"].(string)
+			if !ok && v["prefix"] != nil {
+				return nil, fmt.Errorf("extends[%d] has an invalid prefix, expected a string", i)
+			}
+			ref.From, _ = v["from"].(string)
+			ref.Prefix = prefix
+		}
+		if ref.From == "" {
+			return nil, fmt.Errorf("extends[%d] is not a reference", i)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// prefixTools adds prefix to the names of the tools of doc, an MCP file.
+func prefixTools(doc map[string]any, prefix string) {
+	tools, _ := doc["tools"].([]any)
+	for _, t := range tools {
+		if tool, ok := t.(map[string]any); ok {
+			if name, ok := tool["name"].(string); ok {
+				tool["name"] = prefix + name
+			}
+		}
+	}
+}
+
+// decode parses a YAML or JSON document, keeping the exact value of numbers. Its conversion to JSON is cached
+// with parsecache.ToJSON if cached is set, and only in memory otherwise.
+func decode(data []byte, cached bool) (map[string]any, error) {
+	toJSON := parsecache.ToJSONInMemory
+	if cached {
+		toJSON = parsecache.ToJSON
+	}
+	jsonData, err := toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("the file is empty")
+	}
+	return doc, nil
+}
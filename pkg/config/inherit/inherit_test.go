@@ -0,0 +1,313 @@
+package inherit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const header = "kind: MCPToolDefinitions\nschemaVersion: \"0.2.0\"\n"
+
+// writeFiles writes files, by path relative to dir, and returns dir.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func resolveFile(t *testing.T, path string, opts Options) (map[string]any, error) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	resolved, err := Resolve(data, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(resolved, &doc))
+	return doc, nil
+}
+
+func TestResolve(t *testing.T) {
+	tt := map[string]struct {
+		files         map[string]string
+		opts          Options
+		expected      string
+		errorContains string
+	}{
+		"extended files are merged in order": {
+			files: map[string]string{
+				"base.yaml": header + `name: base
+version: 1.0.0
+instructions: base instructions
+tools:
+- name: a
+  description: A`,
+				"team.yaml": header + `version: 2.0.0
+tools:
+- name: a
+  description: team A
+- name: b
+  description: B`,
+				"mcpfile.yaml": header + `extends: [base.yaml, team.yaml]
+name: service
+tools:
+- name: c
+  description: C`,
+			},
+			expected: `{
+				"kind": "MCPToolDefinitions", "schemaVersion": "0.2.0", "extends": ["base.yaml", "team.yaml"],
+				"name": "service", "version": "2.0.0", "instructions": "base instructions",
+				"tools": [{"name": "a", "description": "team A"}, {"name": "b", "description": "B"}, {"name": "c", "description": "C"}]
+			}`,
+		},
+		"extends are resolved recursively, relative to the extending file": {
+			files: map[string]string{
+				"shared/root.yaml": header + `name: root
+version: 1.0.0`,
+				"shared/base.yaml": header + `extends: [root.yaml]
+instructions: base`,
+				"mcpfile.yaml": header + `extends: [shared/base.yaml]
+version: 1.1.0`,
+			},
+			expected: `{
+				"kind": "MCPToolDefinitions", "schemaVersion": "0.2.0", "extends": ["shared/base.yaml"],
+				"name": "root", "version": "1.1.0", "instructions": "base"
+			}`,
+		},
+		"a file extended twice is merged twice": {
+			files: map[string]string{
+				"root.yaml":    header + `name: root`,
+				"a.yaml":       header + `extends: [root.yaml]`,
+				"b.yaml":       header + `extends: [root.yaml]`,
+				"mcpfile.yaml": header + `extends: [a.yaml, b.yaml]`,
+			},
+			expected: `{"kind": "MCPToolDefinitions", "schemaVersion": "0.2.0", "extends": ["a.yaml", "b.yaml"], "name": "root"}`,
+		},
+		"extended files are preprocessed": {
+			files: map[string]string{
+				"base.yaml":    header + `name: NAME`,
+				"mcpfile.yaml": header + `extends: [base.yaml]`,
+			},
+			opts: Options{Preprocess: func(data []byte) ([]byte, error) {
+				return []byte(strings.ReplaceAll(string(data), "NAME", "preprocessed")), nil
+			}},
+			expected: `{"kind": "MCPToolDefinitions", "schemaVersion": "0.2.0", "extends": ["base.yaml"], "name": "preprocessed"}`,
+		},
+		"tools of extended files are prefixed": {
+			files: map[string]string{
+				"crm.yaml": header + `name: crm
+tools:
+- name: search
+  description: Search contacts
+prompts:
+- name: summary`,
+				"tickets.yaml": header + `tools:
+- name: search
+  description: Search tickets`,
+				"mcpfile.yaml": header + `extends:
+- from: crm.yaml
+  prefix: crm_
+- {from: tickets.yaml, prefix: tickets_}
+tools:
+- name: crm_search
+  description: Search CRM contacts`,
+			},
+			expected: `{
+				"kind": "MCPToolDefinitions", "schemaVersion": "0.2.0",
+				"extends": [{"from": "crm.yaml", "prefix": "crm_"}, {"from": "tickets.yaml", "prefix": "tickets_"}],
+				"name": "crm", "prompts": [{"name": "summary"}],
+				"tools": [{"name": "crm_search", "description": "Search CRM contacts"}, {"name": "tickets_search", "description": "Search tickets"}]
+			}`,
+		},
+		"prefix of a server config file": {
+			files: map[string]string{
+				"base.yaml":    "kind: MCPServerConfig\nschemaVersion: \"0.2.0\"\n",
+				"mcpfile.yaml": "kind: MCPServerConfig\nschemaVersion: \"0.2.0\"\nextends: [{from: base.yaml, prefix: crm_}]\n",
+			},
+			errorContains: "prefix is only supported by MCP files",
+		},
+		"unknown field of a reference": {
+			files: map[string]string{
+				"mcpfile.yaml": header + `extends: [{from: base.yaml, suffix: _crm}]`,
+			},
+			errorContains: "extends[0] has unknown field 'suffix'",
+		},
+		"reference without from": {
+			files: map[string]string{
+				"mcpfile.yaml": header + `extends: [{prefix: crm_}]`,
+			},
+			errorContains: "extends[0] is not a reference",
+		},
+		"cycle": {
+			files: map[string]string{
+				"a.yaml":       header + `extends: [b.yaml]`,
+				"b.yaml":       header + `extends: [a.yaml]`,
+				"mcpfile.yaml": header + `extends: [a.yaml]`,
+			},
+			errorContains: "extends cycle:",
+		},
+		"file extending itself": {
+			files: map[string]string{
+				"mcpfile.yaml": header + `extends: [mcpfile.yaml]`,
+			},
+			errorContains: "extends cycle:",
+		},
+		"different kind": {
+			files: map[string]string{
+				"base.yaml":    "kind: MCPServerConfig\nschemaVersion: \"0.2.0\"\n",
+				"mcpfile.yaml": header + `extends: [base.yaml]`,
+			},
+			errorContains: "has kind MCPServerConfig, expected MCPToolDefinitions",
+		},
+		"different schema version": {
+			files: map[string]string{
+				"base.yaml":    "kind: MCPToolDefinitions\nschemaVersion: \"0.1.0\"\n",
+				"mcpfile.yaml": header + `extends: [base.yaml]`,
+			},
+			errorContains: "has schemaVersion 0.1.0, expected 0.2.0",
+		},
+		"missing file": {
+			files: map[string]string{
+				"mcpfile.yaml": header + `extends: [missing.yaml]`,
+			},
+			errorContains: "failed to read",
+		},
+		"invalid extends": {
+			files: map[string]string{
+				"mcpfile.yaml": header + `extends: base.yaml`,
+			},
+			errorContains: "expected a list of references",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+
+			doc, err := resolveFile(t, filepath.Join(dir, "mcpfile.yaml"), tc.opts)
+			if tc.errorContains != "" {
+				assert.ErrorContains(t, err, tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+
+			var expected map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.expected), &expected))
+			assert.Equal(t, expected, doc)
+		})
+	}
+}
+
+func TestResolveWithoutExtends(t *testing.T) {
+	tt := map[string]string{
+		"no extends":   header + "name: test\n",
+		"invalid yaml": header + "name: [test\n",
+	}
+
+	for name, data := range tt {
+		t.Run(name, func(t *testing.T) {
+			resolved, err := Resolve([]byte(data), filepath.Join(t.TempDir(), "mcpfile.yaml"), Options{})
+			assert.NoError(t, err)
+			assert.Equal(t, data, string(resolved))
+		})
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	files := map[string]string{
+		"/configs/base.yaml": header + "name: remote\nversion: 1.0.0\n",
+		"/configs/team.yaml": header + "extends: [base.yaml]\nversion: 2.0.0\n",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	teamURL := srv.URL + "/configs/team.yaml"
+	dir := writeFiles(t, map[string]string{
+		"mcpfile.yaml": header + "extends: [" + teamURL + "]\n",
+	})
+	path := filepath.Join(dir, "mcpfile.yaml")
+
+	doc, err := resolveFile(t, path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "remote", doc["name"])
+	assert.Equal(t, "2.0.0", doc["version"])
+
+	t.Run("lock pins the remote files", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		lock, err := Lock(data, path)
+		require.NoError(t, err)
+		assert.Len(t, lock.Digests, 2)
+		assert.Contains(t, lock.Digests, teamURL)
+		assert.Contains(t, lock.Digests, srv.URL+"/configs/base.yaml")
+		require.NoError(t, lock.Write(LockFilePath(path)))
+
+		read, err := ReadLockFile(LockFilePath(path))
+		require.NoError(t, err)
+		assert.Equal(t, lock, read)
+
+		_, err = resolveFile(t, path, Options{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("changed remote files don't match the lockfile", func(t *testing.T) {
+		files["/configs/base.yaml"] = header + "name: changed\nversion: 1.0.0\n"
+
+		_, err := resolveFile(t, path, Options{})
+		assert.ErrorContains(t, err, "from the lockfile, run genmcp lock to update it")
+	})
+
+	t.Run("remote files not in the lockfile", func(t *testing.T) {
+		require.NoError(t, (&LockFile{Digests: map[string]string{}}).Write(LockFilePath(path)))
+
+		_, err := resolveFile(t, path, Options{})
+		assert.ErrorContains(t, err, "is not in the lockfile, run genmcp lock to add it")
+	})
+
+	t.Run("missing remote file", func(t *testing.T) {
+		require.NoError(t, os.Remove(LockFilePath(path)))
+		require.NoError(t, os.WriteFile(path, []byte(header+"extends: ["+srv.URL+"/missing.yaml]\n"), 0644))
+
+		_, err := resolveFile(t, path, Options{})
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+}
+
+func TestLockFilePath(t *testing.T) {
+	tt := map[string]string{
+		"mcpfile.yaml":          "mcpfile.lock.yaml",
+		"configs/mcpserver.yml": "configs/mcpserver.lock.yml",
+		"mcpfile":               "mcpfile.lock",
+	}
+
+	for path, expected := range tt {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, expected, LockFilePath(path))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package inherit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// LockFile pins the digests of the remote files extended by a config file, directly or through other files.
+type LockFile struct {
+	// Digests of the remote files, by reference: the sha256 of the contents of URLs, and the manifest digest
+	// of OCI references.
+	Digests map[string]string `json:"digests"`
+}
+
+// LockFilePath returns the path of the lockfile of the config file at path, e.g. mcpfile.lock.yaml for
+// mcpfile.yaml.
+func LockFilePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".lock" + ext
+}
+
+// ReadLockFile reads the lockfile at path. It returns an empty lockfile, pinning no file, if it doesn't exist.
+func ReadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &LockFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	lock := &LockFile{}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.Digests == nil {
+		lock.Digests = make(map[string]string)
+	}
+	return lock, nil
+}
+
+// Lock resolves the extends of data, the contents of the config file at path, regardless of its lockfile,
+// and returns a lockfile pinning the remote files at their current digest.
+func Lock(data []byte, path string) (*LockFile, error) {
+	lock := &LockFile{Digests: make(map[string]string)}
+
+	r := newResolver(Options{}, nil, lock.Digests)
+	if _, err := r.resolveRoot(data, path); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Write writes l to path.
+func (l *LockFile) Write(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	data = append([]byte("# Generated by genmcp lock. Do not edit.\n"), data...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
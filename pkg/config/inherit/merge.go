@@ -0,0 +1,122 @@
+package inherit
+
+// mergeDocs merges the config file override onto base, both of the given kind. base is modified.
+func mergeDocs(base, override map[string]any, kind string) map[string]any {
+	named := namedLists[kind]
+	for key, value := range override {
+		if named[key] {
+			baseItems, baseOk := base[key].([]any)
+			items, ok := value.([]any)
+			if baseOk && ok {
+				base[key] = mergeNamed(baseItems, items)
+				continue
+			}
+		}
+		setMerged(base, key, value)
+	}
+	return base
+}
+
+// mergeNamed merges the items of override onto the items of base with the same name, and appends the others.
+func mergeNamed(base, override []any) []any {
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		if name, ok := nameOf(item); ok {
+			index[name] = i
+		}
+	}
+
+	for _, item := range override {
+		name, ok := nameOf(item)
+		i, found := index[name]
+		if !ok || !found {
+			base = append(base, item)
+			continue
+		}
+		base[i] = merge(base[i], item, "")
+	}
+	return base
+}
+
+func nameOf(item any) (string, bool) {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	name, ok := obj["name"].(string)
+	return name, ok
+}
+
+// merge returns override merged onto base, the value of the field key. base is modified.
+func merge(base, override any, key string) any {
+	baseObj, baseOk := base.(map[string]any)
+	obj, ok := override.(map[string]any)
+	if !baseOk || !ok {
+		return override
+	}
+
+	// an invocation has a single field, its type, and the configs of different types don't merge
+	if key == "invocation" && !sameKeys(baseObj, obj) {
+		return override
+	}
+
+	for k, v := range obj {
+		field := k
+		if key == "invocationBases" {
+			// invocation bases are invocations, keyed by name
+			field = "invocation"
+		}
+		setMergedAs(baseObj, k, v, field)
+	}
+	return baseObj
+}
+
+// setMerged merges value onto the field key of obj, or removes the field if value is null.
+func setMerged(obj map[string]any, key string, value any) {
+	setMergedAs(obj, key, value, key)
+}
+
+// setMergedAs is setMerged for a value merged as the value of field.
+func setMergedAs(obj map[string]any, key string, value any, field string) {
+	if value == nil {
+		delete(obj, key)
+		return
+	}
+	if existing, ok := obj[key]; ok {
+		obj[key] = merge(existing, value, field)
+		return
+	}
+	obj[key] = value
+}
+
+func sameKeys(a, b map[string]any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// copyValue returns a deep copy of a decoded JSON value.
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for k, item := range v {
+			c[k] = copyValue(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = copyValue(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,96 @@
+package inherit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDocs(t *testing.T) {
+	tt := map[string]struct {
+		kind     string
+		base     string
+		override string
+		expected string
+	}{
+		"objects are merged recursively": {
+			kind:     "MCPServerConfig",
+			base:     `{"runtime": {"transportProtocol": "streamablehttp", "streamableHttpConfig": {"port": 8080, "basePath": "/mcp"}}}`,
+			override: `{"runtime": {"streamableHttpConfig": {"port": 9090}}}`,
+			expected: `{"runtime": {"transportProtocol": "streamablehttp", "streamableHttpConfig": {"port": 9090, "basePath": "/mcp"}}}`,
+		},
+		"null removes a field": {
+			kind:     "MCPServerConfig",
+			base:     `{"runtime": {"streamableHttpConfig": {"port": 8080, "basePath": "/mcp"}}}`,
+			override: `{"runtime": {"streamableHttpConfig": {"basePath": null}}}`,
+			expected: `{"runtime": {"streamableHttpConfig": {"port": 8080}}}`,
+		},
+		"lists are replaced": {
+			kind:     "MCPServerConfig",
+			base:     `{"runtime": {"auth": {"authorizationServers": ["https://a.example.com"]}}}`,
+			override: `{"runtime": {"auth": {"authorizationServers": ["https://b.example.com"]}}}`,
+			expected: `{"runtime": {"auth": {"authorizationServers": ["https://b.example.com"]}}}`,
+		},
+		"tools are merged by name": {
+			kind: "MCPToolDefinitions",
+			base: `{"tools": [
+				{"name": "a", "description": "A", "requiredScopes": ["read"]},
+				{"name": "b", "description": "B"}
+			]}`,
+			override: `{"tools": [
+				{"name": "b", "description": "B2", "disabled": true},
+				{"name": "c", "description": "C"},
+				{"name": "a", "requiredScopes": ["write"]}
+			]}`,
+			expected: `{"tools": [
+				{"name": "a", "description": "A", "requiredScopes": ["write"]},
+				{"name": "b", "description": "B2", "disabled": true},
+				{"name": "c", "description": "C"}
+			]}`,
+		},
+		"lists of tools are not merged by name": {
+			kind:     "MCPToolDefinitions",
+			base:     `{"tools": [{"name": "a", "tests": [{"name": "t1"}, {"name": "t2"}]}]}`,
+			override: `{"tools": [{"name": "a", "tests": [{"name": "t2", "arguments": {"id": 1}}]}]}`,
+			expected: `{"tools": [{"name": "a", "tests": [{"name": "t2", "arguments": {"id": 1}}]}]}`,
+		},
+		"invocations of the same type are merged": {
+			kind:     "MCPToolDefinitions",
+			base:     `{"tools": [{"name": "a", "invocation": {"http": {"url": "http://localhost/a", "method": "GET"}}}]}`,
+			override: `{"tools": [{"name": "a", "invocation": {"http": {"method": "POST"}}}]}`,
+			expected: `{"tools": [{"name": "a", "invocation": {"http": {"url": "http://localhost/a", "method": "POST"}}}]}`,
+		},
+		"invocations of another type are replaced": {
+			kind:     "MCPToolDefinitions",
+			base:     `{"tools": [{"name": "a", "invocation": {"http": {"url": "http://localhost/a", "method": "GET"}}}]}`,
+			override: `{"tools": [{"name": "a", "invocation": {"cli": {"command": "echo a"}}}]}`,
+			expected: `{"tools": [{"name": "a", "invocation": {"cli": {"command": "echo a"}}}]}`,
+		},
+		"invocation bases are merged like invocations": {
+			kind:     "MCPToolDefinitions",
+			base:     `{"invocationBases": {"api": {"http": {"url": "http://localhost", "method": "GET"}}, "cmd": {"cli": {"command": "ls"}}}}`,
+			override: `{"invocationBases": {"api": {"http": {"method": "POST"}}, "cmd": {"http": {"url": "http://localhost/ls"}}}}`,
+			expected: `{"invocationBases": {"api": {"http": {"url": "http://localhost", "method": "POST"}}, "cmd": {"http": {"url": "http://localhost/ls"}}}}`,
+		},
+		"server config lists of tools are replaced": {
+			kind:     "MCPServerConfig",
+			base:     `{"tools": [{"name": "a", "description": "A"}]}`,
+			override: `{"tools": [{"name": "a"}]}`,
+			expected: `{"tools": [{"name": "a"}]}`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			base, err := decode([]byte(tc.base), false)
+			require.NoError(t, err)
+			override, err := decode([]byte(tc.override), false)
+			require.NoError(t, err)
+			expected, err := decode([]byte(tc.expected), false)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, mergeDocs(base, override, tc.kind))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package inherit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	invopopschema "github.com/invopop/jsonschema"
+)
+
+// Reference is an entry of the extends of a config file: a reference to an extended file, written as a
+// string, or as an object to set a prefix.
+type Reference struct {
+	// Path relative to the extending file, http(s) URL, or OCI artifact (oci://registry/repository:tag) of
+	// the extended file.
+	From string `json:"from"`
+
+	// Prefix added to the names of the tools of the extended file, e.g. "crm_", so that they don't clash with
+	// the tools of other files. Only supported by MCP files.
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// UnmarshalJSON reads references written as strings or as objects.
+func (r *Reference) UnmarshalJSON(data []byte) error {
+	var from string
+	if err := json.Unmarshal(data, &from); err == nil {
+		*r = Reference{From: from}
+		return nil
+	}
+
+	type reference Reference
+	var ref reference
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return fmt.Errorf("expected a reference or an object with from and prefix: %w", err)
+	}
+	*r = Reference(ref)
+	return nil
+}
+
+// MarshalJSON writes references without prefix as strings.
+func (r Reference) MarshalJSON() ([]byte, error) {
+	if r.Prefix == "" {
+		return json.Marshal(r.From)
+	}
+
+	type reference Reference
+	return json.Marshal(reference(r))
+}
+
+// JSONSchema describes references as strings or objects.
+func (Reference) JSONSchema() *invopopschema.Schema {
+	object := &invopopschema.Schema{
+		Type:                 "object",
+		Properties:           invopopschema.NewProperties(),
+		Required:             []string{"from"},
+		AdditionalProperties: invopopschema.FalseSchema,
+	}
+	object.Properties.Set("from", &invopopschema.Schema{
+		Type:        "string",
+		Description: "Path relative to this file, http(s) URL, or OCI artifact (oci://registry/repository:tag) of the extended file.",
+	})
+	object.Properties.Set("prefix", &invopopschema.Schema{
+		Type:        "string",
+		Description: "Prefix added to the names of the tools of the extended file, e.g. crm_. Only supported by MCP files.",
+	})
+
+	return &invopopschema.Schema{
+		OneOf: []*invopopschema.Schema{
+			{Type: "string"},
+			object,
+		},
+	}
+}
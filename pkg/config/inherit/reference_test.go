@@ -0,0 +1,47 @@
+package inherit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceJSON(t *testing.T) {
+	tt := map[string]struct {
+		data          string
+		expected      Reference
+		errorContains string
+	}{
+		"string": {
+			data:     `"base.yaml"`,
+			expected: Reference{From: "base.yaml"},
+		},
+		"object with prefix": {
+			data:     `{"from":"oci://ghcr.io/acme/crm:1.0.0","prefix":"crm_"}`,
+			expected: Reference{From: "oci://ghcr.io/acme/crm:1.0.0", Prefix: "crm_"},
+		},
+		"invalid": {
+			data:          `1`,
+			errorContains: "expected a reference or an object with from and prefix",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var ref Reference
+			err := json.Unmarshal([]byte(tc.data), &ref)
+			if tc.errorContains != "" {
+				assert.ErrorContains(t, err, tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ref)
+
+			data, err := json.Marshal(ref)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.data, string(data))
+		})
+	}
+}
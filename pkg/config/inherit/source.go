@@ -0,0 +1,211 @@
+package inherit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	// OCIScheme prefixes the references of config files stored as OCI artifacts.
+	OCIScheme = "oci://"
+
+	// OCILayerMediaType is the media type of the layer holding the config file of an OCI artifact. The layer
+	// of artifacts with a single layer is used whatever its media type.
+	OCILayerMediaType = "application/vnd.genmcp.config.v1+yaml"
+
+	// maxFileSize is the maximum size of a remote config file.
+	maxFileSize = 10 << 20
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type sourceKind int
+
+const (
+	sourceLocal sourceKind = iota
+	sourceURL
+	sourceOCI
+)
+
+// source is the location of a config file: an absolute path, an http(s) URL, or an OCI reference.
+type source struct {
+	kind     sourceKind
+	location string
+}
+
+func localSource(path string) (source, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return source{}, fmt.Errorf("failed to get absolute path to %s: %w", path, err)
+	}
+	return source{kind: sourceLocal, location: abs}, nil
+}
+
+// id identifies the file of s, and is the key of its digest in lockfiles.
+func (s source) id() string {
+	if s.kind == sourceOCI {
+		return OCIScheme + s.location
+	}
+	return s.location
+}
+
+func (s source) String() string {
+	return s.id()
+}
+
+func (s source) remote() bool {
+	return s.kind != sourceLocal
+}
+
+// resolve returns the source of ref, extended by the file of s. Paths are relative to the file of s.
+func (s source) resolve(ref string) (source, error) {
+	if strings.HasPrefix(ref, OCIScheme) {
+		parsed, err := name.ParseReference(strings.TrimPrefix(ref, OCIScheme))
+		if err != nil {
+			return source{}, err
+		}
+		return source{kind: sourceOCI, location: parsed.Name()}, nil
+	}
+
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return source{kind: sourceURL, location: u.String()}, nil
+	}
+
+	switch s.kind {
+	case sourceURL:
+		base, err := url.Parse(s.location)
+		if err != nil {
+			return source{}, err
+		}
+		rel, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return source{}, err
+		}
+		return source{kind: sourceURL, location: base.ResolveReference(rel).String()}, nil
+	case sourceOCI:
+		return source{}, fmt.Errorf("paths can't be extended by OCI artifacts")
+	default:
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(filepath.Dir(s.location), ref)
+		}
+		return source{kind: sourceLocal, location: filepath.Clean(ref)}, nil
+	}
+}
+
+// fetch returns the contents of the file of s and, for remote files, their digest. The file of an OCI
+// reference is fetched at the locked digest if it is not empty.
+func (s source) fetch(locked string) ([]byte, string, error) {
+	switch s.kind {
+	case sourceURL:
+		return s.fetchURL()
+	case sourceOCI:
+		return s.fetchOCI(locked)
+	default:
+		data, err := os.ReadFile(s.location)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", s, err)
+		}
+		return data, "", nil
+	}
+}
+
+func (s source) fetchURL() ([]byte, string, error) {
+	resp, err := httpClient.Get(s.location)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: %s", s, resp.Status)
+	}
+
+	data, err := readAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return data, "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func (s source) fetchOCI(locked string) ([]byte, string, error) {
+	ref, err := name.ParseReference(s.location)
+	if err != nil {
+		return nil, "", err
+	}
+	if locked != "" {
+		ref = ref.Context().Digest(locked)
+	}
+
+	desc, err := remote.Get(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+
+	layer, err := configLayer(img)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := readAll(rc)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+
+	return data, desc.Digest.String(), nil
+}
+
+// configLayer returns the layer of img holding a config file.
+func configLayer(img v1.Image) (v1.Layer, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range layers {
+		mediaType, err := layer.MediaType()
+		if err == nil && string(mediaType) == OCILayerMediaType {
+			return layer, nil
+		}
+	}
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+
+	return nil, fmt.Errorf("no layer has media type %s", OCILayerMediaType)
+}
+
+func readAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("the file is larger than %d bytes", maxFileSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package inherit
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceResolve(t *testing.T) {
+	local := source{kind: sourceLocal, location: "/configs/service/mcpfile.yaml"}
+	remoteURL := source{kind: sourceURL, location: "https://example.com/configs/mcpfile.yaml"}
+	oci := source{kind: sourceOCI, location: "ghcr.io/org/base:1.0"}
+
+	tt := map[string]struct {
+		from          source
+		ref           string
+		expected      string
+		errorContains string
+	}{
+		"relative path": {
+			from:     local,
+			ref:      "../shared/base.yaml",
+			expected: "/configs/shared/base.yaml",
+		},
+		"absolute path": {
+			from:     local,
+			ref:      "/shared/base.yaml",
+			expected: "/shared/base.yaml",
+		},
+		"url": {
+			from:     local,
+			ref:      "https://example.com/base.yaml",
+			expected: "https://example.com/base.yaml",
+		},
+		"oci reference": {
+			from:     local,
+			ref:      "oci://ghcr.io/org/base:1.0",
+			expected: "oci://ghcr.io/org/base:1.0",
+		},
+		"oci reference without tag": {
+			from:     local,
+			ref:      "oci://ghcr.io/org/base",
+			expected: "oci://ghcr.io/org/base:latest",
+		},
+		"path relative to url": {
+			from:     remoteURL,
+			ref:      "../shared/base.yaml",
+			expected: "https://example.com/shared/base.yaml",
+		},
+		"oci reference from oci reference": {
+			from:     oci,
+			ref:      "oci://ghcr.io/org/root:1.0",
+			expected: "oci://ghcr.io/org/root:1.0",
+		},
+		"path relative to oci reference": {
+			from:          oci,
+			ref:           "base.yaml",
+			errorContains: "paths can't be extended by OCI artifacts",
+		},
+		"invalid oci reference": {
+			from:          local,
+			ref:           "oci://ghcr.io/Org/base:1.0",
+			errorContains: "could not parse reference",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if strings.HasPrefix(tc.expected, "/") && filepath.Separator != '/' {
+				t.Skip("paths are tested on unix")
+			}
+
+			src, err := tc.from.resolve(tc.ref)
+			if tc.errorContains != "" {
+				assert.ErrorContains(t, err, tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, src.id())
+		})
+	}
+}
+
+// pushConfig pushes content as an OCI artifact tagged ref, with a single layer, and returns its digest.
+func pushConfig(t *testing.T, ref, content string, mediaType types.MediaType) v1.Hash {
+	t.Helper()
+
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte(content), mediaType))
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(parsed, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return digest
+}
+
+func TestResolveOCI(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	baseRef := host + "/org/base:1.0"
+	digest := pushConfig(t, baseRef, header+"name: base\nversion: 1.0.0\n", OCILayerMediaType)
+	pushConfig(t, host+"/org/team:1.0", header+"extends: [oci://"+baseRef+"]\nversion: 2.0.0\n", "text/yaml")
+
+	dir := writeFiles(t, map[string]string{
+		"mcpfile.yaml": header + "extends: [oci://" + host + "/org/team:1.0]\n",
+	})
+	path := filepath.Join(dir, "mcpfile.yaml")
+
+	doc, err := resolveFile(t, path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "base", doc["name"])
+	assert.Equal(t, "2.0.0", doc["version"])
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lock, err := Lock(data, path)
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), lock.Digests["oci://"+baseRef])
+	require.NoError(t, lock.Write(LockFilePath(path)))
+
+	// the tag is moved, but the lockfile pins the previous manifest
+	pushConfig(t, baseRef, header+"name: moved\nversion: 1.0.0\n", OCILayerMediaType)
+
+	doc, err = resolveFile(t, path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "base", doc["name"])
+
+	t.Run("artifacts without a config layer", func(t *testing.T) {
+		parsed, err := name.ParseReference(host + "/org/layers:1.0")
+		require.NoError(t, err)
+		img, err := mutate.AppendLayers(empty.Image,
+			static.NewLayer([]byte("a"), "text/plain"),
+			static.NewLayer([]byte("b"), "text/plain"))
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(parsed, img))
+
+		require.NoError(t, os.Remove(LockFilePath(path)))
+		require.NoError(t, os.WriteFile(path, []byte(header+"extends: [oci://"+host+"/org/layers:1.0]\n"), 0644))
+
+		_, err = resolveFile(t, path, Options{})
+		assert.ErrorContains(t, err, "no layer has media type "+OCILayerMediaType)
+	})
+}
@@ -0,0 +1,274 @@
+// Package parsecache caches the parsing of large config files, keyed by the hash of their content, so that
+// reloads and repeated runs of genmcp on the same MCP file skip the YAML parsing, which dominates the time
+// taken to load files with hundreds of tools. The conversions of the files to JSON, the values parsed from
+// them and the resolved JSON schemas are cached in memory, and the conversions are also persisted on disk
+// when enabled with GENMCP_PARSE_CACHE=disk.
+package parsecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+// EnvVar is the environment variable configuring the cache: off disables it, and disk persists the
+// conversions to JSON on disk, e.g. GENMCP_PARSE_CACHE=disk. The cache is only kept in memory by default.
+const EnvVar = "GENMCP_PARSE_CACHE"
+
+// MinBytes is the size of the smallest file whose parsing is cached. Smaller files are parsed faster than
+// their parsing is read from the cache.
+const MinBytes = 64 << 10
+
+// MaxEntries is the number of conversions, parsed values and resolved schemas each kept in memory. The
+// oldest are dropped first.
+const MaxEntries = 256
+
+// MaxAge is the time after which the conversions that were not used are deleted from the disk cache.
+const MaxAge = 30 * 24 * time.Hour
+
+// version is part of the keys of the cached conversions, so that changes of the conversion invalidate them.
+const version = "v1"
+
+// cacheDir is the directory the conversions are written to, named after their key.
+var cacheDir = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "genmcp", "parse"), nil
+}
+
+var (
+	conversions = newMemory() // conversions to JSON by key
+	values      = newMemory() // parsed values by type and key
+	schemas     = newMemory() // resolved schemas by key of their JSON
+)
+
+// ToJSON converts data, a YAML or JSON document, to JSON like yaml.YAMLToJSON. The conversions of documents
+// of at least MinBytes are read from the cache, and written to it when they are not in it. The cache is
+// best-effort: the document is converted whenever the cache can't be read or written.
+func ToJSON(data []byte) ([]byte, error) {
+	return toJSON(data, true)
+}
+
+// ToJSONInMemory is like ToJSON, but never persists the conversion on disk. It converts the documents that
+// may hold secrets, e.g. server config files whose environment variables were expanded.
+func ToJSONInMemory(data []byte) ([]byte, error) {
+	return toJSON(data, false)
+}
+
+func toJSON(data []byte, persist bool) ([]byte, error) {
+	mode := os.Getenv(EnvVar)
+	if len(data) < MinBytes || mode == "off" {
+		return yaml.YAMLToJSON(data)
+	}
+
+	k := key(data)
+	if cached, ok := conversions.get(k); ok {
+		return cached.([]byte), nil
+	}
+
+	jsonData, err := readOrConvert(data, k, persist && mode == "disk")
+	if err != nil {
+		return nil, err
+	}
+	conversions.put(k, jsonData)
+	return jsonData, nil
+}
+
+// readOrConvert returns the conversion of data with key k, read from the disk cache if persist is set, and
+// written to it after converting data if it is not in it.
+func readOrConvert(data []byte, k string, persist bool) ([]byte, error) {
+	if !persist {
+		return yaml.YAMLToJSON(data)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return yaml.YAMLToJSON(data)
+	}
+	path := filepath.Join(dir, k+".json")
+
+	if cached, err := os.ReadFile(path); err == nil {
+		// the modification time records the last use of the conversion
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return cached, nil
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := write(dir, path, jsonData); err == nil {
+		prune(dir)
+	}
+	return jsonData, nil
+}
+
+// Unmarshal unmarshals data, a YAML or JSON document, into obj with its conversion by ToJSON. It is equivalent
+// to yaml.Unmarshal for objects implementing json.Unmarshaler, which don't select the types of the values of
+// the document, and returns the same errors.
+func Unmarshal(data []byte, obj json.Unmarshaler) error {
+	return unmarshal(data, obj, ToJSON)
+}
+
+// UnmarshalInMemory is like Unmarshal, but converts data with ToJSONInMemory.
+func UnmarshalInMemory(data []byte, obj json.Unmarshaler) error {
+	return unmarshal(data, obj, ToJSONInMemory)
+}
+
+func unmarshal(data []byte, obj json.Unmarshaler, toJSON func([]byte) ([]byte, error)) error {
+	jsonData, err := toJSON(data)
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %w", err)
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(jsonData)).Decode(obj); err != nil {
+		return fmt.Errorf("error unmarshaling JSON: while decoding JSON: %w", err)
+	}
+	return nil
+}
+
+// Parse returns the value parsed from data by parse. The values parsed from documents of at least MinBytes
+// are cached in memory, and a copy made by clone is returned, so that callers can change it. Errors are not
+// cached.
+func Parse[T any](data []byte, parse func([]byte) (T, error), clone func(T) T) (T, error) {
+	if len(data) < MinBytes || os.Getenv(EnvVar) == "off" {
+		return parse(data)
+	}
+
+	var zero T
+	k := fmt.Sprintf("%T/%s", zero, key(data))
+	if cached, ok := values.get(k); ok {
+		return clone(cached.(T)), nil
+	}
+
+	v, err := parse(data)
+	if err != nil {
+		return zero, err
+	}
+	// the cached value is a copy, as the caller can change the parsed one
+	values.put(k, clone(v))
+	return v, nil
+}
+
+// ResolveSchema resolves schema like schema.Resolve(nil). The resolved schemas are cached in memory by the
+// hash of the JSON of their schema, and shared by the schemas with the same JSON, as they are not changed
+// once resolved. Errors are not cached.
+func ResolveSchema(schema *jsonschema.Schema) (*jsonschema.Resolved, error) {
+	if os.Getenv(EnvVar) == "off" {
+		return schema.Resolve(nil)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return schema.Resolve(nil)
+	}
+	k := key(data)
+	if cached, ok := schemas.get(k); ok {
+		return cached.(*jsonschema.Resolved), nil
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, err
+	}
+	schemas.put(k, resolved)
+	return resolved, nil
+}
+
+// memory holds up to MaxEntries values by key, dropping the oldest first.
+type memory struct {
+	mu     sync.Mutex
+	values map[string]any
+	keys   []string // in the order they were added
+}
+
+func newMemory() *memory {
+	return &memory{values: make(map[string]any)}
+}
+
+func (m *memory) get(k string) (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.values[k]
+	return v, ok
+}
+
+func (m *memory) put(k string, v any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[k]; !ok {
+		if len(m.keys) == MaxEntries {
+			delete(m.values, m.keys[0])
+			m.keys = m.keys[1:]
+		}
+		m.keys = append(m.keys, k)
+	}
+	m.values[k] = v
+}
+
+// key returns the key of the conversion of data.
+func key(data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(version))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// write writes the conversion jsonData to path atomically, so that concurrent runs never read a partial
+// conversion. The conversions are only readable by the user, as MCP files may hold credentials.
+func write(dir, path string, jsonData []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if err := f.Chmod(0600); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(jsonData); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// prune deletes the conversions of dir that were not used for MaxAge.
+func prune(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < MaxAge {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, entry.Name()))
+	}
+}
@@ -0,0 +1,242 @@
+package parsecache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+// largeDocument returns a YAML document of at least MinBytes.
+func largeDocument() []byte {
+	var b strings.Builder
+	b.WriteString("tools:\n")
+	for b.Len() < MinBytes {
+		b.WriteString("- name: get_user\n  description: Get a user by ID\n")
+	}
+	return []byte(b.String())
+}
+
+func useCacheDir(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "parse")
+	previous := cacheDir
+	cacheDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { cacheDir = previous })
+
+	// the tests start with an empty memory
+	previousConversions, previousValues, previousSchemas := conversions, values, schemas
+	conversions, values, schemas = newMemory(), newMemory(), newMemory()
+	t.Cleanup(func() { conversions, values, schemas = previousConversions, previousValues, previousSchemas })
+	return dir
+}
+
+func TestToJSON(t *testing.T) {
+	tt := []struct {
+		name          string
+		data          []byte
+		mode          string
+		expectCached  bool
+		expectOnDisk  bool
+		expectedError string
+	}{
+		{
+			name:         "large document",
+			data:         largeDocument(),
+			expectCached: true,
+		},
+		{
+			name:         "large document persisted on disk",
+			data:         largeDocument(),
+			mode:         "disk",
+			expectCached: true,
+			expectOnDisk: true,
+		},
+		{
+			name: "small document",
+			data: []byte("name: test\n"),
+			mode: "disk",
+		},
+		{
+			name: "cache disabled",
+			data: largeDocument(),
+			mode: "off",
+		},
+		{
+			name:          "invalid document",
+			data:          append(largeDocument(), []byte("\t- name: [\n")...),
+			mode:          "disk",
+			expectedError: "yaml",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := useCacheDir(t)
+			t.Setenv(EnvVar, tc.mode)
+
+			jsonData, err := ToJSON(tc.data)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.NoDirExists(t, dir)
+				return
+			}
+			require.NoError(t, err)
+			expected, err := yaml.YAMLToJSON(tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, expected, jsonData)
+
+			_, cached := conversions.get(key(tc.data))
+			assert.Equal(t, tc.expectCached, cached)
+
+			path := filepath.Join(dir, key(tc.data)+".json")
+			if !tc.expectOnDisk {
+				assert.NoFileExists(t, path)
+				return
+			}
+			require.FileExists(t, path)
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the conversions should only be readable by the user")
+
+			// the conversion is read from the disk by the next runs
+			conversions = newMemory()
+			require.NoError(t, os.WriteFile(path, []byte(`{"cached":true}`), 0600))
+			jsonData, err = ToJSON(tc.data)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"cached":true}`, string(jsonData))
+		})
+	}
+}
+
+func TestToJSONInMemory(t *testing.T) {
+	dir := useCacheDir(t)
+	t.Setenv(EnvVar, "disk")
+
+	data := largeDocument()
+	_, err := ToJSONInMemory(data)
+	require.NoError(t, err)
+
+	_, cached := conversions.get(key(data))
+	assert.True(t, cached)
+	assert.NoDirExists(t, dir, "documents holding secrets should never be written to disk")
+}
+
+func TestToJSONPrunesUnusedConversions(t *testing.T) {
+	dir := useCacheDir(t)
+	t.Setenv(EnvVar, "disk")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+
+	unused := filepath.Join(dir, "unused.json")
+	recent := filepath.Join(dir, "recent.json")
+	require.NoError(t, os.WriteFile(unused, []byte(`{}`), 0600))
+	require.NoError(t, os.WriteFile(recent, []byte(`{}`), 0600))
+	old := time.Now().Add(-MaxAge - time.Hour)
+	require.NoError(t, os.Chtimes(unused, old, old))
+
+	_, err := ToJSON(largeDocument())
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, unused)
+	assert.FileExists(t, recent)
+}
+
+type document struct {
+	Name string `json:"name"`
+}
+
+func (d *document) UnmarshalJSON(data []byte) error {
+	type plain document
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+func TestUnmarshal(t *testing.T) {
+	tt := []struct {
+		name string
+		data string
+	}{
+		{name: "valid document", data: "name: test\n"},
+		{name: "invalid YAML", data: "name: [\n"},
+		{name: "invalid value", data: "name: [1, 2]\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			useCacheDir(t)
+
+			var expected, actual document
+			expectedErr := yaml.Unmarshal([]byte(tc.data), &expected)
+			err := Unmarshal([]byte(tc.data), &actual)
+
+			assert.Equal(t, expected, actual)
+			if expectedErr != nil {
+				assert.EqualError(t, err, expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	useCacheDir(t)
+
+	parses := 0
+	parse := func(data []byte) (*document, error) {
+		parses++
+		return &document{Name: "test"}, nil
+	}
+	clone := func(d *document) *document {
+		c := *d
+		return &c
+	}
+
+	data := largeDocument()
+	first, err := Parse(data, parse, clone)
+	require.NoError(t, err)
+	first.Name = "changed"
+
+	second, err := Parse(data, parse, clone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, parses, "the parsed value should be cached")
+	assert.Equal(t, "test", second.Name, "the changes of the parsed values should not change the cached value")
+	assert.NotSame(t, first, second)
+
+	_, err = Parse([]byte("name: test\n"), parse, clone)
+	require.NoError(t, err)
+	assert.Equal(t, 2, parses, "the values of small documents should not be cached")
+
+	t.Setenv(EnvVar, "off")
+	_, err = Parse(data, parse, clone)
+	require.NoError(t, err)
+	assert.Equal(t, 3, parses, "the values should not be cached when the cache is disabled")
+}
+
+func TestResolveSchema(t *testing.T) {
+	useCacheDir(t)
+
+	schema := func() *jsonschema.Schema {
+		return &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"id": {Type: "string"}}}
+	}
+
+	first, err := ResolveSchema(schema())
+	require.NoError(t, err)
+	second, err := ResolveSchema(schema())
+	require.NoError(t, err)
+	assert.Same(t, first, second, "schemas with the same JSON should share their resolved schema")
+
+	other, err := ResolveSchema(&jsonschema.Schema{Type: "string"})
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+
+	_, err = ResolveSchema(&jsonschema.Schema{Ref: "#/$defs/missing"})
+	assert.Error(t, err)
+}
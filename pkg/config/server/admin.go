@@ -0,0 +1,24 @@
+package server
+
+import (
+	"net"
+	"strings"
+)
+
+// GetAddress returns the address the admin API listens on, defaulting to DefaultAdminAddress.
+func (a *AdminConfig) GetAddress() string {
+	if a.Address == "" {
+		return DefaultAdminAddress
+	}
+	return a.Address
+}
+
+// isLoopbackAddress reports whether address, an IP address or a host name, only accepts connections from the
+// local machine.
+func isLoopbackAddress(address string) bool {
+	if strings.EqualFold(address, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/audit"
+)
+
+// GetAuditLogger returns the audit logger of the tool calls of the server, according to the Audit config.
+// The logger is created once and cached for subsequent calls, so that reloaded tools and additional
+// listeners write to the same sink. It returns nil if Audit is nil, which drops every record.
+func (sr *ServerRuntime) GetAuditLogger() (*audit.Logger, error) {
+	if sr == nil || sr.Audit == nil {
+		return nil, nil
+	}
+
+	sr.auditLoggerMu.Lock()
+	defer sr.auditLoggerMu.Unlock()
+
+	sr.auditLoggerOnce.Do(func() {
+		sr.auditLogger, sr.auditLoggerErr = sr.newAuditLogger()
+	})
+
+	return sr.auditLogger, sr.auditLoggerErr
+}
+
+// CloseAuditLogger closes the audit logger of the server, if it was created, and forgets it, so that the
+// next call of GetAuditLogger creates a new one, e.g. when an embedded server is started again.
+func (sr *ServerRuntime) CloseAuditLogger() error {
+	if sr == nil {
+		return nil
+	}
+
+	sr.auditLoggerMu.Lock()
+	defer sr.auditLoggerMu.Unlock()
+
+	logger := sr.auditLogger
+	sr.auditLogger, sr.auditLoggerErr, sr.auditLoggerOnce = nil, nil, sync.Once{}
+
+	return logger.Close()
+}
+
+func (sr *ServerRuntime) newAuditLogger() (*audit.Logger, error) {
+	ac := sr.Audit
+
+	var sink audit.Sink
+	switch ac.Sink {
+	case AuditSinkFile:
+		fileSink, err := audit.NewFileSink(ac.Path)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case AuditSinkSyslog:
+		tag := ac.SyslogTag
+		if tag == "" {
+			tag = DefaultAuditSyslogTag
+		}
+		// syslog connections can't be dialed with the dial function of the network policy, so the host of
+		// the daemon is checked before connecting to it
+		if host, ok := syslogHost(ac.SyslogNetwork, ac.SyslogAddress); ok {
+			policy, err := sr.GetNetworkPolicy()
+			if err != nil {
+				return nil, err
+			}
+			if err := policy.CheckHost(host); err != nil {
+				return nil, fmt.Errorf("invalid syslog address: %w", err)
+			}
+		}
+		syslogSink, err := audit.NewSyslogSink(ac.SyslogNetwork, ac.SyslogAddress, tag)
+		if err != nil {
+			return nil, err
+		}
+		sink = syslogSink
+	case AuditSinkHTTP:
+		client, err := sr.GetHTTPClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		sink = &audit.HTTPSink{URL: ac.URL, Headers: ac.Headers, Client: client}
+	default:
+		return nil, fmt.Errorf("unknown audit sink %s", ac.Sink)
+	}
+
+	return audit.NewLogger(sink, audit.Options{
+		Arguments:    ac.Arguments,
+		RedactFields: ac.RedactFields,
+		OnQueueFull:  ac.OnQueueFull,
+		QueueTimeout: ac.GetQueueTimeout(),
+	}, sr.GetBaseLogger()), nil
+}
+
+// GetQueueTimeout returns how long a call waits for its record to be queued with onQueueFull fail.
+func (ac *AuditConfig) GetQueueTimeout() time.Duration {
+	timeout := ac.QueueTimeout
+	if timeout == "" {
+		timeout = DefaultAuditQueueTimeout
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		d, _ = time.ParseDuration(DefaultAuditQueueTimeout)
+	}
+	return d
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// GetJWKSRefreshInterval returns how long fetched JWKS are used before they are fetched again.
+func (a *AuthConfig) GetJWKSRefreshInterval() time.Duration {
+	d, err := time.ParseDuration(cmp.Or(a.JWKSRefreshInterval, DefaultJWKSRefreshInterval))
+	if err != nil || d <= 0 {
+		d, _ = time.ParseDuration(DefaultJWKSRefreshInterval)
+	}
+	return d
+}
+
+// IssuerURLs returns the URLs of the authorization servers and issuers trusted by the server.
+func (a *AuthConfig) IssuerURLs() []string {
+	urls := slices.Clone(a.AuthorizationServers)
+	for _, issuer := range a.Issuers {
+		if issuer != nil && !slices.Contains(urls, issuer.Issuer) {
+			urls = append(urls, issuer.Issuer)
+		}
+	}
+	return urls
+}
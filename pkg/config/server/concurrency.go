@@ -0,0 +1,44 @@
+package server
+
+import (
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
+)
+
+// GetConcurrencyPool returns the pool limiting the invocations of the server according to the Concurrency
+// config. The pool is created once and cached for subsequent calls, so that reloaded tools and additional
+// listeners share its limits. If Concurrency is nil, only the maxConcurrency of tools is enforced.
+func (sr *ServerRuntime) GetConcurrencyPool() *concurrency.Pool {
+	if sr == nil {
+		return (*ConcurrencyConfig)(nil).newPool()
+	}
+
+	sr.concurrencyPoolOnce.Do(func() {
+		sr.concurrencyPool = sr.Concurrency.newPool()
+	})
+
+	return sr.concurrencyPool
+}
+
+func (c *ConcurrencyConfig) newPool() *concurrency.Pool {
+	if c == nil {
+		return concurrency.NewPool(0, 0, c.GetQueueTimeout())
+	}
+	return concurrency.NewPool(c.MaxInFlight, c.MaxQueued, c.GetQueueTimeout())
+}
+
+// GetQueueTimeout returns how long invocations wait for a running invocation to complete. It can be
+// called on a nil config.
+func (c *ConcurrencyConfig) GetQueueTimeout() time.Duration {
+	timeout := DefaultQueueTimeout
+	if c != nil && c.QueueTimeout != "" {
+		timeout = c.QueueTimeout
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		d, _ = time.ParseDuration(DefaultQueueTimeout)
+	}
+	return d
+}
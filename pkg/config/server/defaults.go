@@ -1,5 +1,10 @@
 package server
 
+import (
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
 // Default values for server configuration.
 const (
 	// DefaultBasePath is the default base path for the MCP server.
@@ -13,6 +18,37 @@ const (
 
 	// DefaultReadinessPath is the default path for the readiness probe endpoint.
 	DefaultReadinessPath = "/readyz"
+
+	// DefaultAdminBasePath is the default base path for the admin API.
+	DefaultAdminBasePath = "/admin"
+
+	// DefaultAdminAddress is the default address the admin API listens on.
+	DefaultAdminAddress = "127.0.0.1"
+
+	// DefaultSessionTTL is the default time sessions are kept after their last request.
+	DefaultSessionTTL = "30m"
+
+	// DefaultJWKSRefreshInterval is the default time fetched JWKS are used before they are fetched again.
+	DefaultJWKSRefreshInterval = "10m"
+
+	// DefaultQueueTimeout is the default time invocations wait for a running invocation to complete
+	// when a concurrency limit is reached.
+	DefaultQueueTimeout = "30s"
+
+	// DefaultMaxIdleConns is the default number of idle connections kept open by the HTTP client.
+	DefaultMaxIdleConns = 100
+
+	// DefaultMaxIdleConnsPerHost is the default number of idle connections kept open to each host.
+	DefaultMaxIdleConnsPerHost = 100
+
+	// DefaultIdleConnTimeout is the default time idle connections are kept open.
+	DefaultIdleConnTimeout = "90s"
+
+	// DefaultAuditSyslogTag is the default tag of the syslog messages of the audit log.
+	DefaultAuditSyslogTag = "genmcp"
+
+	// DefaultAuditQueueTimeout is the default time a call waits for its audit record to be queued.
+	DefaultAuditQueueTimeout = "5s"
 )
 
 // ApplyDefaults applies default values to the MCPServerConfig after parsing.
@@ -40,6 +76,68 @@ func (r *ServerRuntime) ApplyDefaults() {
 		}
 		r.StreamableHTTPConfig.ApplyDefaults()
 	}
+
+	for _, l := range r.Listeners {
+		l.ApplyDefaults()
+	}
+
+	if r.LoggingConfig == nil {
+		// set before the env overrides, so that e.g. GENMCP_LOGGINGCONFIG_LEVEL=debug only changes the level
+		r.LoggingConfig = logging.NewDefaultConfig()
+	}
+
+	if r.Limits != nil {
+		r.Limits.ApplyDefaults()
+	}
+
+	if r.Admin != nil {
+		r.Admin.ApplyDefaults()
+	}
+
+	if r.Audit != nil {
+		r.Audit.ApplyDefaults()
+	}
+}
+
+// ApplyDefaults applies default values to AuditConfig.
+func (a *AuditConfig) ApplyDefaults() {
+	if a.Arguments == "" {
+		a.Arguments = audit.ArgumentsHash
+	}
+	if a.Sink == AuditSinkSyslog && a.SyslogTag == "" {
+		a.SyslogTag = DefaultAuditSyslogTag
+	}
+	if a.OnQueueFull == "" {
+		a.OnQueueFull = audit.QueueFullFail
+	}
+}
+
+// ApplyDefaults applies default values to AdminConfig.
+func (a *AdminConfig) ApplyDefaults() {
+	if a.BasePath == "" {
+		a.BasePath = DefaultAdminBasePath
+	}
+}
+
+// ApplyDefaults applies default values to LimitsConfig.
+func (l *LimitsConfig) ApplyDefaults() {
+	if l.TruncationStrategy == "" {
+		l.TruncationStrategy = TruncationStrategyHead
+	}
+}
+
+// ApplyDefaults applies default values to ListenerConfig.
+func (l *ListenerConfig) ApplyDefaults() {
+	if l.TransportProtocol == "" {
+		l.TransportProtocol = TransportProtocolStreamableHttp
+	}
+
+	if l.TransportProtocol == TransportProtocolStreamableHttp {
+		if l.StreamableHTTPConfig == nil {
+			l.StreamableHTTPConfig = &StreamableHTTPConfig{}
+		}
+		l.StreamableHTTPConfig.ApplyDefaults()
+	}
 }
 
 // ApplyDefaults applies default values to StreamableHTTPConfig.
@@ -60,6 +158,20 @@ func (s *StreamableHTTPConfig) ApplyDefaults() {
 		s.Health = &HealthConfig{}
 	}
 	s.Health.ApplyDefaults()
+
+	if s.Sessions != nil {
+		s.Sessions.ApplyDefaults()
+	}
+}
+
+// ApplyDefaults applies default values to SessionsConfig.
+func (s *SessionsConfig) ApplyDefaults() {
+	if s.Store == "" {
+		s.Store = SessionStoreMemory
+	}
+	if s.TTL == "" {
+		s.TTL = DefaultSessionTTL
+	}
 }
 
 // ApplyDefaults applies default values to HealthConfig.
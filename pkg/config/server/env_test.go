@@ -97,6 +97,23 @@ func TestEnvOverrides(t *testing.T) {
 				"GENMCP_LOGGINGCONFIG_INITIALFIELDS": "{\"service\": \"genmcp\"}",
 			},
 		},
+		"overrides the level of the default logging config": {
+			initialRuntime: &ServerRuntime{
+				TransportProtocol: "stdio",
+				LoggingConfig:     logging.NewDefaultConfig(),
+			},
+			expectedRuntime: &ServerRuntime{
+				TransportProtocol: "stdio",
+				LoggingConfig: &logging.LoggingConfig{
+					Level:       "debug",
+					Development: true,
+					Encoding:    logging.EncodingConsole,
+				},
+			},
+			env: map[string]string{
+				"GENMCP_LOGGINGCONFIG_LEVEL": "debug",
+			},
+		},
 		"handles slices correctly": {
 			initialRuntime: &ServerRuntime{
 				TransportProtocol: "streamablehttp",
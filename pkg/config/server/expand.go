@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvError is an error expanding the environment variable references of a value, e.g. a reference to a
+// variable that is not set and has no default.
+type EnvError struct {
+	Node *yaml.Node // the scalar containing the references
+	Err  error
+}
+
+func (e *EnvError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
+
+// ExpandEnv replaces the environment variable references in the string values of a YAML document, in place.
+// ${VAR} is replaced with the value of VAR, and ${VAR:-default} with default if VAR is unset or empty.
+// $${ is replaced with a literal ${. A value made of a single unquoted reference takes the type of the
+// substituted value, so that e.g. 'port: ${PORT}' is an integer.
+//
+// It returns the errors of every value, joined, as an *EnvError each.
+func ExpandEnv(node *yaml.Node) error {
+	var errs []error
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			errs = append(errs, ExpandEnv(child))
+		}
+	case yaml.MappingNode:
+		// only values are expanded, keys are field names
+		for i := 1; i < len(node.Content); i += 2 {
+			errs = append(errs, ExpandEnv(node.Content[i]))
+		}
+	case yaml.ScalarNode:
+		errs = append(errs, expandScalar(node))
+	}
+
+	return errors.Join(errs...)
+}
+
+func expandScalar(node *yaml.Node) error {
+	if !strings.Contains(node.Value, "${") {
+		return nil
+	}
+
+	value, wholeReference, err := expandString(node.Value)
+	if err != nil {
+		return &EnvError{Node: node, Err: err}
+	}
+
+	node.Value = value
+	if wholeReference && node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		// resolve the tag again from the substituted value
+		node.Tag = ""
+	}
+
+	return nil
+}
+
+// expandString expands the references in s. It also reports whether s is made of a single reference.
+func expandString(s string) (string, bool, error) {
+	wholeReference := strings.HasPrefix(s, "${") && strings.IndexByte(s, '}') == len(s)-1
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			sb.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", false, fmt.Errorf("unterminated environment variable reference in '%s'", s)
+			}
+
+			name, defaultValue, hasDefault := strings.Cut(s[i+2:i+end], ":-")
+			if name == "" {
+				return "", false, fmt.Errorf("empty environment variable reference in '%s'", s)
+			}
+
+			value, ok := os.LookupEnv(name)
+			switch {
+			case hasDefault && value == "":
+				value = defaultValue
+			case !ok:
+				return "", false, fmt.Errorf("environment variable '%s' is not set", name)
+			}
+
+			sb.WriteString(value)
+			i += end + 1
+		default:
+			sb.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return sb.String(), wholeReference, nil
+}
+
+// ExpandEnvData expands the environment variable references of a YAML (or JSON) file, see ExpandEnv.
+func ExpandEnvData(data []byte) ([]byte, error) {
+	var file yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Content) == 0 {
+		return data, nil
+	}
+
+	if err := ExpandEnv(&file); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(&file)
+}
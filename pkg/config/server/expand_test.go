@@ -0,0 +1,116 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestExpandEnv(t *testing.T) {
+	tt := []struct {
+		name        string
+		data        string
+		env         map[string]string
+		expected    map[string]any
+		errContains string
+	}{
+		{
+			name:     "whole reference takes the type of the value",
+			data:     "port: ${PORT}\nstateless: ${STATELESS}",
+			env:      map[string]string{"PORT": "8080", "STATELESS": "false"},
+			expected: map[string]any{"port": 8080, "stateless": false},
+		},
+		{
+			name:     "quoted reference stays a string",
+			data:     `port: "${PORT}"`,
+			env:      map[string]string{"PORT": "8080"},
+			expected: map[string]any{"port": "8080"},
+		},
+		{
+			name:     "references within a string",
+			data:     "jwksUri: https://${ISSUER_HOST}/realms/${REALM}/certs",
+			env:      map[string]string{"ISSUER_HOST": "sso.example.com", "REALM": "mcp"},
+			expected: map[string]any{"jwksUri": "https://sso.example.com/realms/mcp/certs"},
+		},
+		{
+			name:     "default values",
+			data:     "basePath: ${BASE_PATH:-/mcp}\nport: ${PORT:-8080}",
+			env:      map[string]string{"BASE_PATH": ""},
+			expected: map[string]any{"basePath": "/mcp", "port": 8080},
+		},
+		{
+			name:     "sequences and keys",
+			data:     "${KEY}:\n  - ${FIRST}\n  - second",
+			env:      map[string]string{"KEY": "expanded", "FIRST": "first"},
+			expected: map[string]any{"${KEY}": []any{"first", "second"}},
+		},
+		{
+			name:     "escaped reference",
+			data:     "password: p$${ASS}",
+			expected: map[string]any{"password": "p${ASS}"},
+		},
+		{
+			name:        "unset variable",
+			data:        "certFile: ${GENMCP_TEST_UNSET_CERT}\nkeyFile: ${GENMCP_TEST_UNSET_KEY}",
+			errContains: "environment variable 'GENMCP_TEST_UNSET_CERT' is not set\nenvironment variable 'GENMCP_TEST_UNSET_KEY' is not set",
+		},
+		{
+			name:        "unterminated reference",
+			data:        "basePath: ${BASE_PATH",
+			errContains: "unterminated environment variable reference in '${BASE_PATH'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			var doc yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tc.data), &doc))
+
+			err := ExpandEnv(&doc)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual map[string]any
+			require.NoError(t, doc.Decode(&actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseMcpFileExpandsEnv(t *testing.T) {
+	t.Setenv("GENMCP_TEST_PORT", "9090")
+	t.Setenv("GENMCP_TEST_CERTS", "/etc/genmcp/certs")
+
+	path := filepath.Join(t.TempDir(), "mcpserver.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: ${GENMCP_TEST_PORT}
+    basePath: ${GENMCP_TEST_BASE_PATH:-/mcp}
+    tls:
+      certFile: ${GENMCP_TEST_CERTS}/tls.crt
+      keyFile: ${GENMCP_TEST_CERTS}/tls.key
+`), 0o600))
+
+	mcpFile, err := ParseMCPFile(path)
+	require.NoError(t, err)
+
+	httpConfig := mcpFile.Runtime.StreamableHTTPConfig
+	assert.Equal(t, 9090, httpConfig.Port)
+	assert.Equal(t, "/mcp", httpConfig.BasePath)
+	assert.Equal(t, "/etc/genmcp/certs/tls.crt", httpConfig.TLS.CertFile)
+	assert.Equal(t, "/etc/genmcp/certs/tls.key", httpConfig.TLS.KeyFile)
+}
@@ -1,18 +1,21 @@
 package server
 
 import (
+	"cmp"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-// GetHTTPClient returns a configured HTTP client based on the ClientTLSConfig.
-// The client is created once and cached for subsequent calls.
-// If ClientTLSConfig is nil, it returns a default HTTP client with reasonable timeouts.
+// GetHTTPClient returns the HTTP client shared by the outbound requests of the server, configured with
+// the ClientTLSConfig and the connection pool of the HTTPClient config.
+// The client is created once and cached for subsequent calls, so that its connections are reused.
 func (sr *ServerRuntime) GetHTTPClient() (*http.Client, error) {
 	if sr == nil {
 		return http.DefaultClient, nil
@@ -25,37 +28,64 @@ func (sr *ServerRuntime) GetHTTPClient() (*http.Client, error) {
 	return sr.httpClient, sr.httpClientErr
 }
 
-// buildHTTPClient creates an HTTP client with custom TLS configuration if specified.
-// If no ClientTLSConfig is provided, returns a basic client matching the original behavior.
-// When custom TLS is configured, we clone http.DefaultTransport to preserve important
-// defaults like ProxyFromEnvironment, TLSHandshakeTimeout, and HTTP/2 support.
+// buildHTTPClient creates an HTTP client with a pooled transport and custom TLS configuration if specified.
+// We clone http.DefaultTransport to preserve important defaults like ProxyFromEnvironment,
+// TLSHandshakeTimeout, and HTTP/2 support, but raise its limit of 2 idle connections per host,
+// which makes concurrent requests to the same backend open and close connections under load.
 func (sr *ServerRuntime) buildHTTPClient() (*http.Client, error) {
-	// If no custom TLS config, return a simple client matching original behavior
-	// (nil Transport means http.DefaultTransport is used implicitly)
-	if sr.ClientTLSConfig == nil {
-		return &http.Client{}, nil
+	// Guard the type assertion in case a host application replaced DefaultTransport.
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("http.DefaultTransport is not *http.Transport; cannot configure the HTTP client")
+	}
+	transport := defaultTransport.Clone()
+
+	if err := sr.HTTPClient.configure(transport); err != nil {
+		return nil, err
 	}
 
-	// Build TLS config from user settings
-	tlsConfig, err := sr.ClientTLSConfig.BuildTLSConfig()
+	// the destinations are checked by host name, including those of the requests sent through a proxy, and
+	// by the addresses the host names resolve to when connecting
+	policy, err := sr.GetNetworkPolicy()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build TLS config: %w", err)
+		return nil, fmt.Errorf("invalid network policy: %w", err)
 	}
+	transport.DialContext = policy.DialContext(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	transport.Proxy = policy.Proxy(transport.Proxy)
 
-	// Clone DefaultTransport to preserve proxy settings, timeouts, connection pooling, and HTTP/2.
-	// Guard the type assertion in case a host application replaced DefaultTransport.
-	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
-	if !ok {
-		return nil, fmt.Errorf("http.DefaultTransport is not *http.Transport; cannot apply custom TLS config")
+	if sr.ClientTLSConfig != nil {
+		tlsConfig, err := sr.ClientTLSConfig.BuildTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS config: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
 	}
-	transport := defaultTransport.Clone()
-	transport.TLSClientConfig = tlsConfig
 
 	return &http.Client{
 		Transport: transport,
 	}, nil
 }
 
+// configure applies the connection pool settings to transport, using the defaults for unset settings.
+// It can be called on a nil config.
+func (c *HTTPClientConfig) configure(transport *http.Transport) error {
+	if c == nil {
+		c = &HTTPClientConfig{}
+	}
+
+	transport.MaxIdleConns = cmp.Or(c.MaxIdleConns, DefaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = cmp.Or(c.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+	transport.MaxConnsPerHost = c.MaxConnsPerHost
+
+	idleConnTimeout, err := time.ParseDuration(cmp.Or(c.IdleConnTimeout, DefaultIdleConnTimeout))
+	if err != nil {
+		return fmt.Errorf("invalid idleConnTimeout: %w", err)
+	}
+	transport.IdleConnTimeout = idleConnTimeout
+
+	return nil
+}
+
 // BuildTLSConfig creates a tls.Config from the ClientTLSConfig settings.
 // It loads CA certificates from the specified files and/or directory.
 func (c *ClientTLSConfig) BuildTLSConfig() (*tls.Config, error) {
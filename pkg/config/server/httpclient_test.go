@@ -6,9 +6,14 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"io"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -208,6 +213,155 @@ func TestServerRuntime_GetHTTPClient_Caching(t *testing.T) {
 	assert.Same(t, client1, client2, "GetHTTPClient should return cached client")
 }
 
+func TestServerRuntime_GetHTTPClient_ConnectionPool(t *testing.T) {
+	tt := []struct {
+		name                        string
+		runtime                     *ServerRuntime
+		expectedMaxIdleConns        int
+		expectedMaxIdleConnsPerHost int
+		expectedMaxConnsPerHost     int
+		expectedIdleConnTimeout     time.Duration
+		expectInsecureSkipVerify    bool
+	}{
+		{
+			name:                        "defaults without config",
+			runtime:                     &ServerRuntime{},
+			expectedMaxIdleConns:        DefaultMaxIdleConns,
+			expectedMaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+			expectedIdleConnTimeout:     90 * time.Second,
+		},
+		{
+			name: "configured pool",
+			runtime: &ServerRuntime{
+				HTTPClient: &HTTPClientConfig{
+					MaxIdleConns:        500,
+					MaxIdleConnsPerHost: 50,
+					MaxConnsPerHost:     200,
+					IdleConnTimeout:     "5m",
+				},
+			},
+			expectedMaxIdleConns:        500,
+			expectedMaxIdleConnsPerHost: 50,
+			expectedMaxConnsPerHost:     200,
+			expectedIdleConnTimeout:     5 * time.Minute,
+		},
+		{
+			name: "partial pool config uses defaults",
+			runtime: &ServerRuntime{
+				HTTPClient: &HTTPClientConfig{
+					MaxConnsPerHost: 10,
+				},
+			},
+			expectedMaxIdleConns:        DefaultMaxIdleConns,
+			expectedMaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+			expectedMaxConnsPerHost:     10,
+			expectedIdleConnTimeout:     90 * time.Second,
+		},
+		{
+			name: "pool with client TLS config",
+			runtime: &ServerRuntime{
+				ClientTLSConfig: &ClientTLSConfig{
+					InsecureSkipVerify: true,
+				},
+			},
+			expectedMaxIdleConns:        DefaultMaxIdleConns,
+			expectedMaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+			expectedIdleConnTimeout:     90 * time.Second,
+			expectInsecureSkipVerify:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := tc.runtime.GetHTTPClient()
+			require.NoError(t, err)
+
+			transport, ok := client.Transport.(*http.Transport)
+			require.True(t, ok, "client should use an *http.Transport")
+
+			assert.Equal(t, tc.expectedMaxIdleConns, transport.MaxIdleConns)
+			assert.Equal(t, tc.expectedMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tc.expectedMaxConnsPerHost, transport.MaxConnsPerHost)
+			assert.Equal(t, tc.expectedIdleConnTimeout, transport.IdleConnTimeout)
+			if tc.expectInsecureSkipVerify {
+				require.NotNil(t, transport.TLSClientConfig)
+				assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+			}
+			assert.NotSame(t, http.DefaultTransport, transport, "DefaultTransport should not be modified")
+		})
+	}
+}
+
+func TestServerRuntime_GetHTTPClient_ReusesConnections(t *testing.T) {
+	var newConns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	runtime := &ServerRuntime{}
+	for range 5 {
+		client, err := runtime.GetHTTPClient()
+		require.NoError(t, err)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(1), newConns.Load(), "sequential requests should reuse a single connection")
+}
+
+func TestServerRuntime_GetHTTPClient_NetworkPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	tt := []struct {
+		name          string
+		security      *SecurityConfig
+		url           string
+		expectedError string
+	}{
+		{
+			name:          "redirect to a link-local address",
+			url:           server.URL,
+			expectedError: "destination denied by the network policy: 169.254.169.254 is denied",
+		},
+		{
+			name:          "denied CIDR",
+			security:      &SecurityConfig{Network: &NetworkPolicyConfig{DeniedHosts: []string{"127.0.0.0/8"}}},
+			url:           server.URL,
+			expectedError: "destination denied by the network policy: 127.0.0.1 is denied",
+		},
+		{
+			name:          "host outside of the allowed hosts",
+			security:      &SecurityConfig{Network: &NetworkPolicyConfig{AllowedHosts: []string{"api.example.com"}}},
+			url:           server.URL,
+			expectedError: "destination denied by the network policy: 127.0.0.1 is not allowed",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			runtime := &ServerRuntime{Security: tc.security}
+			client, err := runtime.GetHTTPClient()
+			require.NoError(t, err)
+
+			_, err = client.Get(tc.url)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
 // generateTestCACert generates a self-signed CA certificate for testing
 func generateTestCACert(t *testing.T) []byte {
 	t.Helper()
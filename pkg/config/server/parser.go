@@ -6,13 +6,20 @@ import (
 	"os"
 	"path/filepath"
 
-	"sigs.k8s.io/yaml"
-
 	"github.com/genmcp/gen-mcp/pkg/config"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/config/parsecache"
 )
 
-// ParseMCPFile parses a Server Config File (mcpserver.yaml)
+// ParseMCPFile parses a Server Config File (mcpserver.yaml), with the profile selected by GENMCP_PROFILE
+// merged onto it if set.
 func ParseMCPFile(path string) (*MCPServerConfigFile, error) {
+	return ParseMCPFileWithProfile(path, "")
+}
+
+// ParseMCPFileWithProfile parses a Server Config File (mcpserver.yaml) with the named profile merged onto
+// it, or the profile selected by GENMCP_PROFILE if profile is empty.
+func ParseMCPFileWithProfile(path string, profile string) (*MCPServerConfigFile, error) {
 	mcpFile := &MCPServerConfigFile{}
 
 	path, err := filepath.Abs(path)
@@ -25,7 +32,23 @@ func ParseMCPFile(path string) (*MCPServerConfigFile, error) {
 		return nil, fmt.Errorf("failed to read server config file: %v", err)
 	}
 
-	err = yaml.Unmarshal(data, mcpFile)
+	data, err = ApplyProfileData(data, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the profile of server config file: %w", err)
+	}
+
+	data, err = ExpandEnvData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand environment variables in server config file: %w", err)
+	}
+
+	data, err = inherit.Resolve(data, path, inherit.Options{Preprocess: ExpandExtendedFile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the extends of server config file: %w", err)
+	}
+
+	// the conversion is only cached in memory, as the environment variables of the file were expanded
+	err = parsecache.UnmarshalInMemory(data, mcpFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal server config file: %v", err)
 	}
@@ -66,6 +89,12 @@ func (m *MCPServerConfigFile) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid schema version %s, expected %s - please migrate your file and handle any breaking changes", m.SchemaVersion, config.SchemaVersion)
 	}
 
+	if e, ok := raw["extends"]; ok {
+		if err := json.Unmarshal(e, &m.Extends); err != nil {
+			return err
+		}
+	}
+
 	// Unmarshal the rest into MCPServerConfig
 	if err := json.Unmarshal(data, &m.MCPServerConfig); err != nil {
 		return err
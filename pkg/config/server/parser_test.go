@@ -2,10 +2,15 @@ package server
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/genmcp/gen-mcp/pkg/config"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
 )
 
@@ -24,6 +29,7 @@ func TestParseMcpFile(t *testing.T) {
 				MCPServerConfig: MCPServerConfig{
 					Runtime: &ServerRuntime{
 						TransportProtocol: TransportProtocolStreamableHttp,
+						LoggingConfig:     logging.NewDefaultConfig(),
 						StreamableHTTPConfig: &StreamableHTTPConfig{
 							Port:      DefaultPort,
 							BasePath:  DefaultBasePath,
@@ -46,6 +52,7 @@ func TestParseMcpFile(t *testing.T) {
 				MCPServerConfig: MCPServerConfig{
 					Runtime: &ServerRuntime{
 						TransportProtocol: TransportProtocolStreamableHttp,
+						LoggingConfig:     logging.NewDefaultConfig(),
 						StreamableHTTPConfig: &StreamableHTTPConfig{
 							BasePath:  DefaultBasePath,
 							Port:      3000, // explicitly set in YAML
@@ -68,6 +75,7 @@ func TestParseMcpFile(t *testing.T) {
 				MCPServerConfig: MCPServerConfig{
 					Runtime: &ServerRuntime{
 						TransportProtocol: TransportProtocolStdio,
+						LoggingConfig:     logging.NewDefaultConfig(),
 					},
 				},
 			},
@@ -80,6 +88,7 @@ func TestParseMcpFile(t *testing.T) {
 				MCPServerConfig: MCPServerConfig{
 					Runtime: &ServerRuntime{
 						TransportProtocol: TransportProtocolStreamableHttp,
+						LoggingConfig:     logging.NewDefaultConfig(),
 						StreamableHTTPConfig: &StreamableHTTPConfig{
 							Port:      8008,
 							BasePath:  DefaultBasePath,
@@ -102,6 +111,7 @@ func TestParseMcpFile(t *testing.T) {
 				MCPServerConfig: MCPServerConfig{
 					Runtime: &ServerRuntime{
 						TransportProtocol: TransportProtocolStreamableHttp,
+						LoggingConfig:     logging.NewDefaultConfig(),
 						StreamableHTTPConfig: &StreamableHTTPConfig{
 							Port:      7007,
 							BasePath:  DefaultBasePath,
@@ -145,3 +155,143 @@ func TestParseMcpFile(t *testing.T) {
 
 	}
 }
+
+func TestParseMcpFileExtends(t *testing.T) {
+	t.Setenv("GENMCP_TEST_PORT", "9090")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte(`kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: ${GENMCP_TEST_PORT}
+    basePath: /mcp
+    stateless: false
+`), 0o600))
+	path := filepath.Join(dir, "mcpserver.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`kind: MCPServerConfig
+schemaVersion: "0.2.0"
+extends:
+- base.yaml
+runtime:
+  streamableHttpConfig:
+    basePath: /service/mcp
+`), 0o600))
+
+	mcpFile, err := ParseMCPFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []inherit.Reference{{From: "base.yaml"}}, mcpFile.Extends)
+	assert.Equal(t, TransportProtocolStreamableHttp, mcpFile.Runtime.TransportProtocol)
+	httpConfig := mcpFile.Runtime.StreamableHTTPConfig
+	assert.Equal(t, 9090, httpConfig.Port)
+	assert.Equal(t, "/service/mcp", httpConfig.BasePath)
+	assert.False(t, httpConfig.IsStateless())
+}
+
+func TestParseMcpFileProfile(t *testing.T) {
+	const serverConfig = `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: 8080
+    auth:
+      authorizationServers:
+      - https://auth.example.com
+  loggingConfig:
+    level: debug
+profiles:
+  dev:
+    runtime:
+      streamableHttpConfig:
+        auth: null
+  prod:
+    runtime:
+      streamableHttpConfig:
+        port: 8443
+        tls:
+          certFile: /etc/tls/tls.crt
+          keyFile: ${GENMCP_TEST_TLS_KEY}
+      loggingConfig:
+        level: warn
+  broken:
+    kind: MCPToolDefinitions
+`
+
+	tt := []struct {
+		name          string
+		profile       string
+		env           map[string]string
+		expectedPort  int
+		expectedLevel string
+		expectAuth    bool
+		expectTLS     bool
+		expectedError string
+	}{
+		{
+			name:          "no profile",
+			expectedPort:  8080,
+			expectedLevel: "debug",
+			expectAuth:    true,
+		},
+		{
+			name:          "profile",
+			profile:       "prod",
+			env:           map[string]string{"GENMCP_TEST_TLS_KEY": "/etc/tls/tls.key"},
+			expectedPort:  8443,
+			expectedLevel: "warn",
+			expectAuth:    true,
+			expectTLS:     true,
+		},
+		{
+			name:          "profile selected by the environment",
+			env:           map[string]string{ProfileEnvVar: "dev"},
+			expectedPort:  8080,
+			expectedLevel: "debug",
+		},
+		{
+			name:          "profile overrides the environment",
+			profile:       "dev",
+			env:           map[string]string{ProfileEnvVar: "prod"},
+			expectedPort:  8080,
+			expectedLevel: "debug",
+		},
+		{
+			name:          "unknown profile",
+			profile:       "staging",
+			expectedError: "unknown profile 'staging', expected one of: dev, prod, broken",
+		},
+		{
+			name:          "reserved field",
+			profile:       "broken",
+			expectedError: "profile 'broken' can't set kind",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "mcpserver.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(serverConfig), 0o600))
+
+			mcpFile, err := ParseMCPFileWithProfile(path, tc.profile)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Nil(t, mcpFile.Profiles)
+			httpConfig := mcpFile.Runtime.StreamableHTTPConfig
+			assert.Equal(t, tc.expectedPort, httpConfig.Port)
+			assert.Equal(t, tc.expectedLevel, mcpFile.Runtime.LoggingConfig.Level)
+			assert.Equal(t, tc.expectAuth, httpConfig.Auth != nil)
+			assert.Equal(t, tc.expectTLS, httpConfig.TLS != nil)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/policy"
+)
+
+// GetPolicyEngine returns the engine evaluating the authorization policy of the tool calls of the server,
+// according to the Policy config. The engine is created once and cached for subsequent calls, so that reloaded
+// tools and additional listeners share it. It returns nil if Policy is nil, which authorizes every call.
+func (sr *ServerRuntime) GetPolicyEngine() (policy.Engine, error) {
+	if sr == nil || sr.Policy == nil {
+		return nil, nil
+	}
+
+	sr.policyEngineOnce.Do(func() {
+		sr.policyEngine, sr.policyEngineErr = sr.newPolicyEngine()
+	})
+
+	return sr.policyEngine, sr.policyEngineErr
+}
+
+func (sr *ServerRuntime) newPolicyEngine() (policy.Engine, error) {
+	pc := sr.Policy
+
+	switch pc.Engine {
+	case PolicyEngineCEL:
+		return policy.NewCELEngine(pc.Expression)
+	case PolicyEngineOPA:
+		var timeout time.Duration
+		if pc.Timeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(pc.Timeout); err != nil {
+				return nil, fmt.Errorf("invalid policy timeout %s: %w", pc.Timeout, err)
+			}
+		}
+		client, err := sr.GetHTTPClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		return &policy.OPAEngine{URL: pc.URL, Client: client, Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown policy engine %s", pc.Engine)
+	}
+}
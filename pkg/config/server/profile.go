@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ProfileEnvVar is the environment variable selecting the profile of the server config file when none is
+// given, e.g. GENMCP_PROFILE=prod.
+const ProfileEnvVar = "GENMCP_PROFILE"
+
+// profileReservedFields are the fields of a server config file that its profiles can't set.
+var profileReservedFields = []string{"kind", "schemaVersion", "extends", "profiles"}
+
+// SelectedProfile returns profile, or the profile selected by GENMCP_PROFILE if profile is empty.
+func SelectedProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv(ProfileEnvVar)
+}
+
+// ApplyProfile merges the named profile of the profiles of root, the top-level mapping of a server config
+// file, onto root in place, and removes the profiles, so that the environment variable references of the
+// profiles that are not selected are not expanded. The profile selected by GENMCP_PROFILE is merged if
+// profile is empty, and none if it is not set either.
+//
+// A profile is a partial server config file, merged like a file extending root: mappings are merged
+// recursively, a null value removes the field it is set for, and other values, including lists, replace the
+// values they are merged onto.
+func ApplyProfile(root *yaml.Node, profile string) error {
+	profile = SelectedProfile(profile)
+
+	profiles := removeProfiles(root)
+	if profile == "" {
+		return nil
+	}
+
+	i := mappingIndex(profiles, profile)
+	if i < 0 {
+		names := profileNames(profiles)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile '%s', the server config file has no profiles", profile)
+		}
+		return fmt.Errorf("unknown profile '%s', expected one of: %s", profile, strings.Join(names, ", "))
+	}
+
+	overlay := profiles.Content[i+1]
+	if overlay.Kind != yaml.MappingNode {
+		return fmt.Errorf("profile '%s' must be a mapping", profile)
+	}
+	for _, field := range profileReservedFields {
+		if mappingIndex(overlay, field) >= 0 {
+			return fmt.Errorf("profile '%s' can't set %s", profile, field)
+		}
+	}
+
+	mergeMapping(root, overlay)
+	return nil
+}
+
+// ApplyProfileData merges the named profile of a server config file onto it, see ApplyProfile.
+func ApplyProfileData(data []byte, profile string) ([]byte, error) {
+	var file yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil || len(file.Content) == 0 || file.Content[0].Kind != yaml.MappingNode {
+		// parsing the file reports its errors
+		return data, nil
+	}
+
+	if err := ApplyProfile(file.Content[0], profile); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(&file)
+}
+
+// ExpandExtendedFile expands the environment variable references of a file extended by a server config file,
+// see ExpandEnv. The profiles of extended files are removed first: only the profiles of the server config
+// file itself are applied.
+func ExpandExtendedFile(data []byte) ([]byte, error) {
+	var file yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Content) == 0 {
+		return data, nil
+	}
+
+	removeProfiles(file.Content[0])
+	if err := ExpandEnv(&file); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(&file)
+}
+
+// removeProfiles removes the profiles of root, the top-level mapping of a server config file, and returns
+// them.
+func removeProfiles(root *yaml.Node) *yaml.Node {
+	i := mappingIndex(root, "profiles")
+	if i < 0 {
+		return nil
+	}
+	profiles := root.Content[i+1]
+	root.Content = slices.Delete(root.Content, i, i+2)
+	return profiles
+}
+
+// mergeMapping merges the fields of the mapping override onto the mapping base.
+func mergeMapping(base, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		j := mappingIndex(base, key.Value)
+		switch {
+		case value.ShortTag() == "!!null":
+			if j >= 0 {
+				base.Content = slices.Delete(base.Content, j, j+2)
+			}
+		case j < 0:
+			base.Content = append(base.Content, key, value)
+		case base.Content[j+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMapping(base.Content[j+1], value)
+		default:
+			base.Content[j+1] = value
+		}
+	}
+}
+
+// mappingIndex returns the index of the key of the field of mapping, or -1 if it has no such field.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func profileNames(profiles *yaml.Node) []string {
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return nil
+	}
+	names := make([]string, 0, len(profiles.Content)/2)
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		names = append(names, profiles.Content[i].Value)
+	}
+	return names
+}
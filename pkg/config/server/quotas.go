@@ -0,0 +1,47 @@
+package server
+
+import (
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+// AnonymousClient is the client the tool calls of unauthenticated clients are counted for.
+const AnonymousClient = "anonymous"
+
+// GetQuotaTracker returns the tracker counting the tool calls of each client and enforcing the Quotas config.
+// The tracker is created once and cached for subsequent calls, so that reloaded tools and additional listeners
+// share its counts. Without quotas, calls are only counted to report their usage in the admin API. It returns
+// nil if neither Quotas nor Admin are set, which counts no calls.
+func (sr *ServerRuntime) GetQuotaTracker() *quota.Tracker {
+	if sr == nil || sr.Quotas == nil && sr.Admin == nil {
+		return nil
+	}
+
+	sr.quotaTrackerOnce.Do(func() {
+		sr.quotaTracker = quota.NewTracker(sr.Quotas.quotaConfig())
+	})
+
+	return sr.quotaTracker
+}
+
+func (q *QuotasConfig) quotaConfig() quota.Config {
+	if q == nil {
+		return quota.Config{}
+	}
+
+	config := quota.Config{
+		Default: quota.Limits{PerHour: q.PerHour, PerDay: q.PerDay},
+		Clients: make(map[string]quota.Limits, len(q.Clients)),
+		Tools:   make(map[string]quota.Limits, len(q.Tools)),
+	}
+	for client, limits := range q.Clients {
+		if limits != nil {
+			config.Clients[client] = quota.Limits{PerHour: limits.PerHour, PerDay: limits.PerDay}
+		}
+	}
+	for tool, limits := range q.Tools {
+		if limits != nil {
+			config.Tools[tool] = quota.Limits{PerHour: limits.PerHour, PerDay: limits.PerDay}
+		}
+	}
+	return config
+}
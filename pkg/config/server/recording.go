@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+// GetRecordingStore returns the store recording or replaying the tool calls of the server, according to the
+// Recording config. Secrets are redacted from the fixtures with the redaction rules of LoggingConfig. The store
+// is created once and cached for subsequent calls. It returns nil if Recording is nil, so that tools are
+// invoked normally.
+func (sr *ServerRuntime) GetRecordingStore() (*recording.Store, error) {
+	if sr == nil || sr.Recording == nil {
+		return nil, nil
+	}
+
+	sr.recordingStoreOnce.Do(func() {
+		var rc *logging.RedactionConfig
+		if sr.LoggingConfig != nil {
+			rc = sr.LoggingConfig.Redaction
+		}
+
+		redactor, err := logging.NewRedactor(rc)
+		if err != nil {
+			sr.recordingStoreErr = fmt.Errorf("failed to create redactor: %w", err)
+			return
+		}
+
+		sr.recordingStore, sr.recordingStoreErr = recording.NewStore(sr.Recording.Dir, sr.Recording.Mode, redactor)
+	})
+
+	return sr.recordingStore, sr.recordingStoreErr
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrRestartRequired is returned when a reloaded config changes settings that can only be applied by
+// restarting the server.
+var ErrRestartRequired = errors.New("restart required")
+
+// PrepareReload checks that c can replace running, the config of a running server, without restarting it,
+// and makes the runtime of c share the objects of the runtime of running that outlive a reload: the base
+// logger, the audit logger, the recording store, the scheduler, the webhook receivers, the spool and the quota
+// trackers, whose quotas are replaced by those of c so that the calls already counted still count against them.
+//
+// The transport, the port, TLS and session store of the streamable HTTP transport, the logging, tracing,
+// listeners, admin API, audit log, recording, schedules, webhooks and spool of the runtime, the OpenAPI document
+// and the upstream MCP servers can only be changed by restarting the server. An error wrapping ErrRestartRequired is
+// returned if any of them changed.
+func (c *MCPServerConfig) PrepareReload(running *MCPServerConfig) error {
+	var err error = nil
+
+	changed := func(name string, runningValue, value any) {
+		if !sameJSON(runningValue, value) {
+			err = errors.Join(err, fmt.Errorf("%w: changing %s requires a restart", ErrRestartRequired, name))
+		}
+	}
+
+	changed("openapiRef", running.OpenAPIRef, c.OpenAPIRef)
+	changed("upstreams", running.Upstreams, c.Upstreams)
+
+	r, n := running.Runtime, c.Runtime
+	if r == nil || n == nil {
+		if r != n {
+			err = errors.Join(err, fmt.Errorf("%w: changing runtime requires a restart", ErrRestartRequired))
+		}
+		return err
+	}
+
+	changed("transportProtocol", r.TransportProtocol, n.TransportProtocol)
+	changed("streamableHttpConfig.port", r.StreamableHTTPConfig.port(), n.StreamableHTTPConfig.port())
+	changed("streamableHttpConfig.tls", r.StreamableHTTPConfig.tls(), n.StreamableHTTPConfig.tls())
+	changed("streamableHttpConfig.sessions", r.StreamableHTTPConfig.sessions(), n.StreamableHTTPConfig.sessions())
+	changed("loggingConfig", r.LoggingConfig, n.LoggingConfig)
+	changed("tracingConfig", r.TracingConfig, n.TracingConfig)
+	changed("listeners", r.Listeners, n.Listeners)
+	changed("admin", r.Admin, n.Admin)
+	changed("audit", r.Audit, n.Audit)
+	changed("recording", r.Recording, n.Recording)
+	changed("schedules", r.Schedules, n.Schedules)
+	changed("webhooks", r.Webhooks, n.Webhooks)
+	changed("spool", r.Spool, n.Spool)
+	if err != nil {
+		return err
+	}
+
+	n.initLoggerOnce.Do(func() {
+		n.baseLogger = r.GetBaseLogger()
+	})
+	n.auditLoggerOnce.Do(func() {
+		n.auditLogger, n.auditLoggerErr = r.GetAuditLogger()
+	})
+	n.recordingStoreOnce.Do(func() {
+		n.recordingStore, n.recordingStoreErr = r.GetRecordingStore()
+	})
+	n.schedulerOnce.Do(func() {
+		n.scheduler = r.GetScheduler()
+	})
+	n.webhookReceiversOnce.Do(func() {
+		n.webhookReceivers, n.webhookReceiversErr = r.GetWebhookReceivers()
+	})
+	n.spoolOnce.Do(func() {
+		n.spool, n.spoolErr = r.GetSpool()
+	})
+	if tracker := r.GetQuotaTracker(); tracker != nil && (n.Quotas != nil || n.Admin != nil) {
+		tracker.SetConfig(n.Quotas.quotaConfig())
+		n.quotaTrackerOnce.Do(func() {
+			n.quotaTracker = tracker
+		})
+	}
+	if tracker := r.GetTenantQuotaTracker(); tracker != nil && n.Tenants != nil {
+		tracker.SetConfig(n.Tenants.quotaConfig())
+		n.tenantQuotaTrackerOnce.Do(func() {
+			n.tenantQuotaTracker = tracker
+		})
+	}
+
+	return nil
+}
+
+func (s *StreamableHTTPConfig) port() int {
+	if s == nil {
+		return 0
+	}
+	return s.Port
+}
+
+func (s *StreamableHTTPConfig) tls() *TLSConfig {
+	if s == nil {
+		return nil
+	}
+	return s.TLS
+}
+
+func (s *StreamableHTTPConfig) sessions() *SessionsConfig {
+	if s == nil {
+		return nil
+	}
+	return s.Sessions
+}
+
+// sameJSON returns whether a and b have the same JSON encoding.
+func sameJSON(a, b any) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareReload(t *testing.T) {
+	spoolDir := t.TempDir()
+	newConfig := func() *MCPServerConfig {
+		config := &MCPServerConfig{
+			Runtime: &ServerRuntime{
+				TransportProtocol: TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{
+					Port: 8080,
+				},
+				Admin:     &AdminConfig{Port: 9090, BearerTokens: []string{"s3cr3t"}},
+				Schedules: []*ScheduleConfig{{Name: "report", Tool: "generate_report", Schedule: "@daily"}},
+				Webhooks:  []*WebhookConfig{{Name: "github", Path: "/webhooks/github", Secret: "s3cret"}},
+				Spool:     &SpoolConfig{Dir: spoolDir},
+				Tenants: &TenantsConfig{
+					Header:  "X-Tenant-ID",
+					Tenants: map[string]*TenantConfig{"acme": {Quotas: &QuotaLimits{PerDay: 100}}},
+				},
+			},
+		}
+		config.Runtime.ApplyDefaults()
+		return config
+	}
+
+	tt := []struct {
+		name          string
+		change        func(c *MCPServerConfig)
+		expectedError string
+	}{
+		{
+			name:   "no changes",
+			change: func(c *MCPServerConfig) {},
+		},
+		{
+			name: "settings applied without a restart",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.StreamableHTTPConfig.BasePath = "/v2/mcp"
+				c.Runtime.Limits = &LimitsConfig{MaxArgumentBytes: 1024}
+				c.Runtime.Quotas = &QuotasConfig{PerHour: 10}
+				c.Runtime.Tenants.Tenants["acme"].Quotas.PerDay = 200
+			},
+		},
+		{
+			name: "port",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.StreamableHTTPConfig.Port = 8081
+			},
+			expectedError: "changing streamableHttpConfig.port requires a restart",
+		},
+		{
+			name: "admin API and upstreams",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.Admin.BearerTokens = []string{"other"}
+				c.Upstreams = []*UpstreamConfig{{Name: "github"}}
+			},
+			expectedError: "changing upstreams requires a restart\nrestart required: changing admin requires a restart",
+		},
+		{
+			name: "schedules",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.Schedules[0].Schedule = "@hourly"
+			},
+			expectedError: "changing schedules requires a restart",
+		},
+		{
+			name: "webhooks",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.Webhooks[0].Secret = "rotated"
+			},
+			expectedError: "changing webhooks requires a restart",
+		},
+		{
+			name: "spool",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.Spool.ThresholdBytes = 1 << 20
+			},
+			expectedError: "changing spool requires a restart",
+		},
+		{
+			name: "transport",
+			change: func(c *MCPServerConfig) {
+				c.Runtime.TransportProtocol = TransportProtocolStdio
+				c.Runtime.StreamableHTTPConfig = nil
+			},
+			expectedError: "changing transportProtocol requires a restart",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			running := newConfig()
+			require.NoError(t, running.Runtime.GetQuotaTracker().Record("alice", "search"))
+			require.NoError(t, running.Runtime.GetTenantQuotaTracker().Record("acme", "search"))
+
+			config := newConfig()
+			tc.change(config)
+
+			err := config.PrepareReload(running)
+			if tc.expectedError != "" {
+				assert.ErrorIs(t, err, ErrRestartRequired)
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Same(t, running.Runtime.GetBaseLogger(), config.Runtime.GetBaseLogger())
+			assert.Same(t, running.Runtime.GetQuotaTracker(), config.Runtime.GetQuotaTracker())
+			assert.Same(t, running.Runtime.GetTenantQuotaTracker(), config.Runtime.GetTenantQuotaTracker())
+			assert.Same(t, running.Runtime.GetScheduler(), config.Runtime.GetScheduler())
+			runningReceivers, err := running.Runtime.GetWebhookReceivers()
+			require.NoError(t, err)
+			receivers, err := config.Runtime.GetWebhookReceivers()
+			require.NoError(t, err)
+			assert.Same(t, runningReceivers["github"], receivers["github"])
+			runningSpool, err := running.Runtime.GetSpool()
+			require.NoError(t, err)
+			spool, err := config.Runtime.GetSpool()
+			require.NoError(t, err)
+			assert.Same(t, runningSpool, spool)
+
+			usage, ok := config.Runtime.GetQuotaTracker().ClientUsage("alice")
+			require.True(t, ok, "calls counted before the reload should be kept")
+			assert.Equal(t, config.Runtime.Quotas.quotaConfig().Default.PerHour, usage.Total.QuotaPerHour)
+
+			usage, ok = config.Runtime.GetTenantQuotaTracker().ClientUsage("acme")
+			require.True(t, ok, "calls of tenants counted before the reload should be kept")
+			assert.Equal(t, config.Runtime.Tenants.Tenants["acme"].Quotas.PerDay, usage.Total.QuotaPerDay)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/genmcp/gen-mcp/pkg/scheduler"
+)
+
+// GetScheduler returns the scheduler running the tool calls of the Schedules config. The scheduler is
+// created once and cached for subsequent calls, so that additional listeners and reloaded configs serve
+// the results of the same calls. It returns nil if Schedules is empty.
+func (sr *ServerRuntime) GetScheduler() *scheduler.Scheduler {
+	if sr == nil || len(sr.Schedules) == 0 {
+		return nil
+	}
+
+	sr.schedulerOnce.Do(func() {
+		sr.scheduler = scheduler.New()
+	})
+
+	return sr.scheduler
+}
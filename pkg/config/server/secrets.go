@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+)
+
+const defaultVaultCacheTTL = 5 * time.Minute
+
+// GetSecretStore returns the store resolving the secrets referenced by invocation templates, reading
+// from the providers of the Secrets config in order. The store is created once and cached for
+// subsequent calls. If Secrets is nil, secrets are read from environment variables.
+//
+// Vault providers fetching their secret on startup fetch it when the store is created, so that an
+// unreachable server or invalid credentials are reported here.
+func (sr *ServerRuntime) GetSecretStore() (*secrets.Store, error) {
+	if sr == nil {
+		return secrets.NewStore(&secrets.EnvProvider{}), nil
+	}
+
+	sr.secretStoreOnce.Do(func() {
+		sr.secretStore, sr.secretStoreErr = sr.buildSecretStore()
+	})
+
+	return sr.secretStore, sr.secretStoreErr
+}
+
+func (sr *ServerRuntime) buildSecretStore() (*secrets.Store, error) {
+	if sr.Secrets == nil {
+		return secrets.NewStore(&secrets.EnvProvider{}), nil
+	}
+
+	providers := make([]secrets.Provider, 0, len(sr.Secrets.Providers))
+	for i, p := range sr.Secrets.Providers {
+		switch p.Type {
+		case SecretProviderEnv:
+			providers = append(providers, &secrets.EnvProvider{Prefix: p.Prefix})
+		case SecretProviderFile:
+			providers = append(providers, &secrets.FileProvider{Path: p.Path})
+		case SecretProviderDirectory:
+			providers = append(providers, &secrets.DirectoryProvider{Path: p.Path})
+		case SecretProviderVault:
+			provider, err := sr.buildVaultProvider(p.Vault)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create secret provider %d: %w", i, err)
+			}
+			providers = append(providers, provider)
+		}
+	}
+
+	return secrets.NewStore(providers...), nil
+}
+
+func (sr *ServerRuntime) buildVaultProvider(v *VaultConfig) (*secrets.VaultProvider, error) {
+	client, err := sr.GetHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	provider := &secrets.VaultProvider{
+		Address:   v.Address,
+		Namespace: v.Namespace,
+		MountPath: v.MountPath,
+		Path:      v.Path,
+		KVVersion: v.KVVersion,
+		Auth: secrets.VaultAuth{
+			Method:       v.Auth.Method,
+			TokenFile:    v.Auth.TokenFile,
+			MountPath:    v.Auth.MountPath,
+			Role:         v.Auth.Role,
+			JWTFile:      v.Auth.JWTFile,
+			RoleID:       v.Auth.RoleID,
+			SecretIDFile: v.Auth.SecretIDFile,
+		},
+		CacheTTL: defaultVaultCacheTTL,
+		Client:   client,
+	}
+	if provider.MountPath == "" {
+		provider.MountPath = "secret"
+	}
+	if provider.KVVersion == 0 {
+		provider.KVVersion = 2
+	}
+
+	if v.Fetch == VaultFetchStartup {
+		provider.CacheTTL = -1
+		if err := provider.Load(); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+
+	if v.CacheTTL != "" {
+		if provider.CacheTTL, err = time.ParseDuration(v.CacheTTL); err != nil {
+			return nil, fmt.Errorf("invalid cacheTtl: %w", err)
+		}
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerRuntime_GetSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "API_KEY"), []byte("from-directory\n"), 0o600))
+	t.Setenv("GENMCP_TEST_SECRET_API_KEY", "from-env")
+	t.Setenv("GENMCP_TEST_SECRET_TOKEN", "token")
+
+	tt := []struct {
+		name     string
+		runtime  *ServerRuntime
+		secret   string
+		expected string
+	}{
+		{
+			name:     "nil runtime reads environment variables",
+			runtime:  nil,
+			secret:   "GENMCP_TEST_SECRET_API_KEY",
+			expected: "from-env",
+		},
+		{
+			name:     "no secrets config reads environment variables",
+			runtime:  &ServerRuntime{},
+			secret:   "GENMCP_TEST_SECRET_API_KEY",
+			expected: "from-env",
+		},
+		{
+			name: "first provider having the secret wins",
+			runtime: &ServerRuntime{Secrets: &SecretsConfig{Providers: []*SecretProviderConfig{
+				{Type: SecretProviderDirectory, Path: dir},
+				{Type: SecretProviderEnv, Prefix: "GENMCP_TEST_SECRET_"},
+			}}},
+			secret:   "API_KEY",
+			expected: "from-directory",
+		},
+		{
+			name: "falls back to later providers",
+			runtime: &ServerRuntime{Secrets: &SecretsConfig{Providers: []*SecretProviderConfig{
+				{Type: SecretProviderDirectory, Path: dir},
+				{Type: SecretProviderEnv, Prefix: "GENMCP_TEST_SECRET_"},
+			}}},
+			secret:   "TOKEN",
+			expected: "token",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := tc.runtime.GetSecretStore()
+			require.NoError(t, err)
+
+			value, err := store.Resolve(tc.secret)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestServerRuntime_GetSecretStore_Shared(t *testing.T) {
+	sr := &ServerRuntime{}
+
+	store, err := sr.GetSecretStore()
+	require.NoError(t, err)
+
+	cached, err := sr.GetSecretStore()
+	require.NoError(t, err)
+	assert.Same(t, store, cached)
+
+	shared, err := sr.ForListener(&ListenerConfig{Name: "admin", TransportProtocol: TransportProtocolStdio}).GetSecretStore()
+	require.NoError(t, err)
+	assert.Same(t, store, shared)
+}
+
+func TestServerRuntime_GetSecretStore_Vault(t *testing.T) {
+	var reads atomic.Int32
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		reads.Add(1)
+		_, _ = w.Write([]byte(`{"data":{"data":{"API_KEY":"from-vault"},"metadata":{"version":1}}}`))
+	}))
+	defer vault.Close()
+
+	vaultProvider := func(fetch, token string) *SecretsConfig {
+		t.Setenv("VAULT_TOKEN", token)
+		return &SecretsConfig{Providers: []*SecretProviderConfig{{
+			Type: SecretProviderVault,
+			Vault: &VaultConfig{
+				Address: vault.URL,
+				Path:    "genmcp",
+				Auth:    &VaultAuthConfig{Method: "token"},
+				Fetch:   fetch,
+			},
+		}}}
+	}
+
+	t.Run("fetched on startup", func(t *testing.T) {
+		reads.Store(0)
+		sr := &ServerRuntime{Secrets: vaultProvider(VaultFetchStartup, "root")}
+
+		store, err := sr.GetSecretStore()
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), reads.Load())
+
+		value, err := store.Resolve("API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "from-vault", value)
+		assert.Equal(t, int32(1), reads.Load())
+	})
+
+	t.Run("startup fetch failure", func(t *testing.T) {
+		sr := &ServerRuntime{Secrets: vaultProvider(VaultFetchStartup, "invalid")}
+
+		_, err := sr.GetSecretStore()
+		assert.ErrorContains(t, err, "failed to read vault secret 'secret/genmcp': permission denied")
+	})
+
+	t.Run("fetched on invocation", func(t *testing.T) {
+		reads.Store(0)
+		sr := &ServerRuntime{Secrets: vaultProvider("", "root")}
+
+		store, err := sr.GetSecretStore()
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), reads.Load())
+
+		value, err := store.Resolve("API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "from-vault", value)
+		assert.Equal(t, int32(1), reads.Load())
+	})
+}
@@ -0,0 +1,58 @@
+package server
+
+import "github.com/genmcp/gen-mcp/pkg/netpolicy"
+
+// GetAllowedHeaders returns the headers of the incoming requests that invocation templates can reference, or
+// nil if any header can be referenced.
+func (sr *ServerRuntime) GetAllowedHeaders() []string {
+	if sr == nil || sr.Security == nil {
+		return nil
+	}
+	return sr.Security.AllowedHeaders
+}
+
+// GetAllowedEnv returns the environment variables that invocation templates can reference, and the ones that
+// the templates of each tool can also reference, or nil for both if any environment variable can be referenced.
+func (sr *ServerRuntime) GetAllowedEnv() ([]string, map[string][]string) {
+	if sr == nil || sr.Security == nil || (sr.Security.AllowedEnv == nil && sr.Security.Tools == nil) {
+		return nil, nil
+	}
+
+	toolEnv := make(map[string][]string, len(sr.Security.Tools))
+	for tool, tsc := range sr.Security.Tools {
+		if tsc != nil {
+			toolEnv[tool] = tsc.AllowedEnv
+		}
+	}
+
+	// the allowlist applies as soon as the security config sets one
+	env := sr.Security.AllowedEnv
+	if env == nil {
+		env = []string{}
+	}
+
+	return env, toolEnv
+}
+
+// GetNetworkPolicy returns the policy restricting the destinations of the outbound requests and connections of the
+// server. It denies link-local addresses and the metadata endpoints of cloud providers unless the security config
+// allows them.
+func (sr *ServerRuntime) GetNetworkPolicy() (*netpolicy.Policy, error) {
+	var nc *NetworkPolicyConfig
+	if sr != nil && sr.Security != nil {
+		nc = sr.Security.Network
+	}
+	return netpolicy.New(nc.policyConfig())
+}
+
+// policyConfig returns the netpolicy config of the network policy. It can be called on a nil config.
+func (nc *NetworkPolicyConfig) policyConfig() netpolicy.Config {
+	if nc == nil {
+		return netpolicy.Config{}
+	}
+	return netpolicy.Config{
+		AllowedHosts:   nc.AllowedHosts,
+		DeniedHosts:    nc.DeniedHosts,
+		AllowLinkLocal: nc.AllowLinkLocal,
+	}
+}
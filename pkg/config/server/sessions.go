@@ -0,0 +1,67 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/sessions"
+)
+
+// GetTTL returns how long sessions are kept after their last request, or 0 if they never expire.
+func (s *SessionsConfig) GetTTL() time.Duration {
+	ttl := s.TTL
+	if ttl == "" {
+		ttl = DefaultSessionTTL
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// NewSessionStore creates the store of the sessions of the streamable HTTP transport of the runtime. It
+// returns nil if no sessions config is set. Redis stores connect through the network policy of the runtime,
+// and trust the CA certificates of the ClientTLSConfig if they use TLS.
+func (sr *ServerRuntime) NewSessionStore() (sessions.Store, error) {
+	if sr.StreamableHTTPConfig == nil || sr.StreamableHTTPConfig.Sessions == nil {
+		return nil, nil
+	}
+
+	sc := sr.StreamableHTTPConfig.Sessions
+	if sc.Store != SessionStoreRedis {
+		return sessions.NewMemoryStore(sc.GetTTL(), sc.MaxSessions), nil
+	}
+
+	// Redis is connected to through the network policy, like the backends of the tools
+	policy, err := sr.GetNetworkPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &sessions.RedisStore{
+		Address:   sc.Redis.Address,
+		Username:  sc.Redis.Username,
+		Password:  sc.Redis.Password,
+		DB:        sc.Redis.DB,
+		KeyPrefix: sc.Redis.KeyPrefix,
+		TTL:       sc.GetTTL(),
+		Dial:      policy.DialContext(&net.Dialer{Timeout: 5 * time.Second}),
+	}
+
+	if sc.Redis.TLS {
+		tlsConfig, err := sr.ClientTLSConfig.BuildTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build redis TLS config: %w", err)
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		store.TLSConfig = tlsConfig
+	}
+
+	return store, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/spool"
+)
+
+// GetSpool returns the spool of the large responses of tool calls, as configured by the Spool config. The spool
+// is created once and cached for subsequent calls, so that additional listeners and reloaded configs serve the
+// responses it already holds. It returns nil if Spool is not set.
+func (sr *ServerRuntime) GetSpool() (*spool.Spool, error) {
+	if sr == nil || sr.Spool == nil {
+		return nil, nil
+	}
+
+	sr.spoolOnce.Do(func() {
+		sr.spool, sr.spoolErr = spool.New(sr.Spool.spoolConfig())
+	})
+
+	return sr.spool, sr.spoolErr
+}
+
+func (sc *SpoolConfig) spoolConfig() spool.Config {
+	// the ttl is validated with the config
+	ttl, _ := time.ParseDuration(sc.TTL)
+
+	return spool.Config{
+		Dir:            sc.Dir,
+		ThresholdBytes: sc.ThresholdBytes,
+		MaxChunkBytes:  sc.MaxChunkBytes,
+		TTL:            ttl,
+		MaxTotalBytes:  sc.MaxTotalBytes,
+	}
+}
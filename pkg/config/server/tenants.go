@@ -0,0 +1,49 @@
+package server
+
+import (
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+// GetTenantQuotaTracker returns the tracker counting the tool calls of each tenant and enforcing the quotas of
+// the tenants. The tracker is created once and cached for subsequent calls, so that reloaded tools and
+// additional listeners share its counts. It returns nil if Tenants is not set.
+func (sr *ServerRuntime) GetTenantQuotaTracker() *quota.Tracker {
+	if sr == nil || sr.Tenants == nil {
+		return nil
+	}
+
+	sr.tenantQuotaTrackerOnce.Do(func() {
+		sr.tenantQuotaTracker = quota.NewTracker(sr.Tenants.quotaConfig())
+	})
+
+	return sr.tenantQuotaTracker
+}
+
+// GetTenants returns the tenants of the server, nil if Tenants is not set or the runtime is nil.
+func (sr *ServerRuntime) GetTenants() *TenantsConfig {
+	if sr == nil {
+		return nil
+	}
+	return sr.Tenants
+}
+
+// Tenant returns the config of the tenant id, and false if it isn't defined.
+func (t *TenantsConfig) Tenant(id string) (*TenantConfig, bool) {
+	if t == nil {
+		return nil, false
+	}
+	tenant, ok := t.Tenants[id]
+	return tenant, ok && tenant != nil
+}
+
+// quotaConfig returns the quotas of the tenants as the quotas of clients, the tenants being the clients of the
+// tracker.
+func (t *TenantsConfig) quotaConfig() quota.Config {
+	config := quota.Config{Clients: make(map[string]quota.Limits, len(t.Tenants))}
+	for id, tenant := range t.Tenants {
+		if tenant != nil && tenant.Quotas != nil {
+			config.Clients[id] = quota.Limits{PerHour: tenant.Quotas.PerHour, PerDay: tenant.Quotas.PerDay}
+		}
+	}
+	return config
+}
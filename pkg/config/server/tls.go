@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"slices"
+)
+
+// BuildTLSConfig creates the tls.Config of the streamable HTTP server. It loads the server certificate
+// and, if client authentication is configured, the CA certificates that sign the accepted client certificates.
+func (c *TLSConfig) BuildTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientAuth == nil {
+		return tlsConfig, nil
+	}
+
+	clientCAs := x509.NewCertPool()
+	for _, certFile := range c.ClientAuth.CACertFiles {
+		if err := appendCertFromFile(clientCAs, certFile); err != nil {
+			return nil, fmt.Errorf("failed to load client CA cert from %s: %w", certFile, err)
+		}
+	}
+
+	// Client certificates are verified if given, but not required during the handshake, so that the
+	// health endpoints stay reachable for probes. The MCP endpoint rejects requests without a certificate.
+	tlsConfig.ClientCAs = clientCAs
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return nil
+		}
+
+		if !c.ClientAuth.AllowsCertificate(cs.PeerCertificates[0]) {
+			return fmt.Errorf("client certificate '%s' has none of the allowed subject alternative names", cs.PeerCertificates[0].Subject)
+		}
+
+		return nil
+	}
+
+	return tlsConfig, nil
+}
+
+// AllowsCertificate reports whether cert has one of the allowed subject alternative names.
+// Every certificate is allowed if no subject alternative names are configured.
+func (c *ClientAuthConfig) AllowsCertificate(cert *x509.Certificate) bool {
+	if len(c.AllowedSANs) == 0 {
+		return true
+	}
+
+	for _, san := range CertificateSANs(cert) {
+		if slices.Contains(c.AllowedSANs, san) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CertificateSANs returns the subject alternative names of cert.
+func CertificateSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return sans
+}
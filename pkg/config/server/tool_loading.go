@@ -0,0 +1,15 @@
+package server
+
+// LazyToolLoading returns whether the invokers of the tools are only created when the tools are first called.
+func (sr *ServerRuntime) LazyToolLoading() bool {
+	return sr != nil && sr.ToolLoading != nil && sr.ToolLoading.Lazy
+}
+
+// GetPageSize returns the maximum number of items of a page of the list responses of the server, or 0 to use
+// the default of the MCP SDK.
+func (sr *ServerRuntime) GetPageSize() int {
+	if sr == nil || sr.ToolLoading == nil {
+		return 0
+	}
+	return sr.ToolLoading.PageSize
+}
@@ -6,7 +6,19 @@ import (
 	"os"
 	"sync"
 
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
+	"github.com/genmcp/gen-mcp/pkg/config/inherit"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
 	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/policy"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+	"github.com/genmcp/gen-mcp/pkg/scheduler"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/spool"
+	"github.com/genmcp/gen-mcp/pkg/webhook"
 	"go.uber.org/zap"
 )
 
@@ -16,6 +28,19 @@ const (
 	KindMCPServerConfig             = "MCPServerConfig"
 )
 
+const (
+	TruncationStrategyHead    = "head"
+	TruncationStrategyTail    = "tail"
+	TruncationStrategySummary = "summary"
+)
+
+const (
+	ConflictPolicySkip     = "skip"
+	ConflictPolicyError    = "error"
+	ConflictPolicyRename   = "rename"
+	ConflictPolicyOverride = "override"
+)
+
 // StreamableHTTPConfig defines configuration for the HTTP-based runtime.
 type StreamableHTTPConfig struct {
 	// Port number to listen on.
@@ -35,6 +60,10 @@ type StreamableHTTPConfig struct {
 
 	// Health check configuration for k8s probes.
 	Health *HealthConfig `json:"health,omitempty" jsonschema:"optional"`
+
+	// Storage and expiry of the sessions of a stateful server. Only allowed when stateless is false.
+	// Sessions are kept in memory and never expire if unset.
+	Sessions *SessionsConfig `json:"sessions,omitempty" jsonschema:"optional"`
 }
 
 // IsStateless returns whether the server is stateless.
@@ -53,6 +82,70 @@ type TLSConfig struct {
 
 	// Absolute path to the server's private key.
 	KeyFile string `json:"keyFile,omitempty" jsonschema:"optional"`
+
+	// Client certificate authentication (mutual TLS) for the MCP endpoint.
+	ClientAuth *ClientAuthConfig `json:"clientAuth,omitempty" jsonschema:"optional"`
+}
+
+// ClientAuthConfig defines how the client certificates of incoming connections are verified.
+// Requests to the MCP endpoint without a valid client certificate are rejected.
+type ClientAuthConfig struct {
+	// Paths to CA certificate files (PEM format) that sign the accepted client certificates.
+	// The system's certificate pool is not used.
+	CACertFiles []string `json:"caCertFiles" jsonschema:"required"`
+
+	// Subject alternative names (DNS names, email addresses, URIs or IP addresses) of the accepted
+	// client certificates. If empty, every certificate signed by one of the CAs is accepted.
+	AllowedSANs []string `json:"allowedSans,omitempty" jsonschema:"optional"`
+
+	// Scopes granted to clients authenticated with a certificate, used when no auth is configured.
+	Scopes []string `json:"scopes,omitempty" jsonschema:"optional"`
+}
+
+const (
+	SessionStoreMemory = "memory"
+	SessionStoreRedis  = "redis"
+)
+
+// SessionsConfig defines where the sessions of a stateful server are stored and when they expire. Sessions
+// are saved to the store on each request, so that a client can resume its session on another replica of the
+// server, or after the replica serving it closed it.
+type SessionsConfig struct {
+	// Store of the sessions: memory keeps them in the server process, and redis in a Redis server shared by
+	// the replicas of the server. Defaults to memory.
+	Store string `json:"store,omitempty" jsonschema:"optional,enum=memory,enum=redis"`
+
+	// How long a session is kept after its last request, e.g. 30m. Idle sessions are closed and removed
+	// from the store after it. Defaults to 30m.
+	TTL string `json:"ttl,omitempty" jsonschema:"optional"`
+
+	// Maximum number of sessions kept by a memory store. The least recently used session is evicted past
+	// it. Unlimited if 0.
+	MaxSessions int `json:"maxSessions,omitempty" jsonschema:"optional"`
+
+	// Configuration of a redis store.
+	Redis *RedisConfig `json:"redis,omitempty" jsonschema:"optional"`
+}
+
+// RedisConfig defines the Redis server sessions are stored in.
+type RedisConfig struct {
+	// Address of the Redis server, as host:port.
+	Address string `json:"address" jsonschema:"required"`
+
+	// User name to authenticate with, when Redis ACLs are used.
+	Username string `json:"username,omitempty" jsonschema:"optional"`
+
+	// Password to authenticate with, e.g. ${REDIS_PASSWORD}.
+	Password string `json:"password,omitempty" jsonschema:"optional"`
+
+	// Index of the database the sessions are stored in (default: 0).
+	DB int `json:"db,omitempty" jsonschema:"optional"`
+
+	// Prefix of the keys of the sessions (default: genmcp:session:).
+	KeyPrefix string `json:"keyPrefix,omitempty" jsonschema:"optional"`
+
+	// Connect to the Redis server with TLS, trusting the CA certificates of the clientTlsConfig of the runtime.
+	TLS bool `json:"tls,omitempty" jsonschema:"optional"`
 }
 
 type HealthConfig struct {
@@ -93,18 +186,556 @@ type ClientTLSConfig struct {
 	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" jsonschema:"optional"`
 }
 
-// AuthConfig defines OAuth 2.0 authorization settings.
+// HTTPClientConfig defines the connection pool of the HTTP client shared by outbound requests.
+// Connections are kept alive and reused across invocations, so that servers under load don't
+// open a new connection, and use a new ephemeral port, for every request.
+type HTTPClientConfig struct {
+	// Maximum number of idle connections kept open across all hosts (default: 100).
+	MaxIdleConns int `json:"maxIdleConns,omitempty" jsonschema:"optional"`
+
+	// Maximum number of idle connections kept open to each host (default: 100).
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty" jsonschema:"optional"`
+
+	// Maximum number of connections to each host, including connections in use. Requests wait
+	// for a connection once it is reached. Unlimited if 0.
+	MaxConnsPerHost int `json:"maxConnsPerHost,omitempty" jsonschema:"optional"`
+
+	// How long idle connections are kept open, e.g. 90s (default: 90s).
+	IdleConnTimeout string `json:"idleConnTimeout,omitempty" jsonschema:"optional"`
+}
+
+// AuthConfig defines the authentication of clients: OAuth 2.0 access tokens, static bearer tokens,
+// HTTP basic auth, or any combination of them.
 type AuthConfig struct {
 	// List of authorization server URLs for token validation.
 	AuthorizationServers []string `json:"authorizationServers,omitempty" jsonschema:"optional"`
 
 	// URI for the JSON Web Key Set (JWKS) used for token verification.
 	JWKSURI string `json:"jwksUri,omitempty" jsonschema:"optional"`
+
+	// Trusted token issuers, each with its own JWKS, audiences and scope mapping, e.g. to accept the
+	// tokens of several identity providers.
+	Issuers []*IssuerConfig `json:"issuers,omitempty" jsonschema:"optional"`
+
+	// How long the fetched JWKS are used before they are fetched again to pick up rotated keys,
+	// e.g. 10m (default: 10m). The JWKS are also fetched again when a token is signed with an
+	// unknown key.
+	JWKSRefreshInterval string `json:"jwksRefreshInterval,omitempty" jsonschema:"optional"`
+
+	// Static bearer tokens accepted from clients, e.g. shared secrets for deployments without an identity provider.
+	BearerTokens []string `json:"bearerTokens,omitempty" jsonschema:"optional"`
+
+	// HTTP basic auth credentials accepted from clients.
+	BasicAuth *BasicAuthConfig `json:"basicAuth,omitempty" jsonschema:"optional"`
+
+	// Scopes granted to clients authenticated with a static bearer token or basic auth,
+	// used to check the required scopes of tools, prompts and resources.
+	StaticScopes []string `json:"staticScopes,omitempty" jsonschema:"optional"`
+}
+
+// IssuerConfig defines how the OAuth 2.0 access tokens of an issuer are validated.
+type IssuerConfig struct {
+	// Issuer URL, which must match the iss claim of the tokens.
+	Issuer string `json:"issuer" jsonschema:"required"`
+
+	// URI of the JWKS of the issuer. Discovered from the issuer URL if not set.
+	JWKSURI string `json:"jwksUri,omitempty" jsonschema:"optional"`
+
+	// Audiences accepted from the issuer. If set, the aud claim of the tokens must contain one of them.
+	Audiences []string `json:"audiences,omitempty" jsonschema:"optional"`
+
+	// Claim holding the scopes of the tokens, as a space-separated string or an array (default: scope).
+	ScopeClaim string `json:"scopeClaim,omitempty" jsonschema:"optional"`
+
+	// Scopes of the server granted for each scope of the tokens of the issuer, e.g. to map the roles
+	// of an identity provider to the requiredScopes of tools. Scopes without a mapping are kept.
+	ScopeMapping map[string][]string `json:"scopeMapping,omitempty" jsonschema:"optional"`
+}
+
+// BasicAuthConfig defines the users accepted with HTTP basic auth.
+type BasicAuthConfig struct {
+	// Passwords of the accepted users, by user name.
+	Users map[string]string `json:"users,omitempty" jsonschema:"optional"`
+}
+
+// HasStaticCredentials reports whether static bearer tokens or basic auth users are configured.
+func (a *AuthConfig) HasStaticCredentials() bool {
+	return len(a.BearerTokens) > 0 || a.BasicAuth != nil && len(a.BasicAuth.Users) > 0
+}
+
+// UsesOAuth reports whether bearer tokens are validated as OAuth 2.0 access tokens. This is the case
+// unless only static credentials are configured.
+func (a *AuthConfig) UsesOAuth() bool {
+	return len(a.AuthorizationServers) > 0 || a.JWKSURI != "" || len(a.Issuers) > 0 || !a.HasStaticCredentials()
 }
 
 // StdioConfig defines configuration for stdio transport protocol.
 type StdioConfig struct{}
 
+// ListenerConfig defines an additional transport the server is served on, next to the
+// transport of the runtime.
+type ListenerConfig struct {
+	// Name of the listener, used in logs and error messages.
+	Name string `json:"name" jsonschema:"required"`
+
+	// Transport protocol to use (streamablehttp or stdio).
+	TransportProtocol string `json:"transportProtocol" jsonschema:"required"`
+
+	// Configuration for streamable HTTP transport protocol.
+	StreamableHTTPConfig *StreamableHTTPConfig `json:"streamableHttpConfig,omitempty" jsonschema:"optional"`
+
+	// Configuration for stdio transport protocol.
+	StdioConfig *StdioConfig `json:"stdioConfig,omitempty" jsonschema:"optional"`
+
+	// Names of the tools served on this listener. All tools are served if empty.
+	// Prompts, resources and resource templates are always served.
+	Tools []string `json:"tools,omitempty" jsonschema:"optional"`
+}
+
+// LimitsConfig defines size limits of the arguments of requests and of the content of responses.
+// A limit of 0 disables it.
+type LimitsConfig struct {
+	// Maximum size in bytes of each text, image or resource content returned to the client.
+	// Larger content is truncated according to the truncation strategy.
+	MaxResponseBytes int `json:"maxResponseBytes,omitempty" jsonschema:"optional"`
+
+	// Maximum size in bytes of the arguments of a tool call or prompt. Larger requests are rejected.
+	MaxArgumentBytes int `json:"maxArgumentBytes,omitempty" jsonschema:"optional"`
+
+	// How content larger than maxResponseBytes is truncated: head keeps its beginning, tail keeps its end,
+	// and summary replaces it with a marker stating its size (default: head). Binary content is always
+	// replaced with a marker.
+	TruncationStrategy string `json:"truncationStrategy,omitempty" jsonschema:"optional,enum=head,enum=tail,enum=summary"`
+}
+
+// ConcurrencyConfig defines the number of tool calls, prompts and resource reads running concurrently.
+// Invocations over the limit, or over the maxConcurrency of their tool, wait in a queue and are rejected
+// with an error once the queue is full or the queue timeout elapses.
+type ConcurrencyConfig struct {
+	// Maximum number of invocations running concurrently across all clients. Unlimited if 0.
+	MaxInFlight int `json:"maxInFlight,omitempty" jsonschema:"optional"`
+
+	// Maximum number of invocations waiting for the global limit, and for the limit of each tool.
+	// Unlimited if 0.
+	MaxQueued int `json:"maxQueued,omitempty" jsonschema:"optional"`
+
+	// How long invocations wait for a running invocation to complete, e.g. 10s (default: 30s).
+	QueueTimeout string `json:"queueTimeout,omitempty" jsonschema:"optional"`
+}
+
+// AdminConfig defines the admin API, which adds, updates, disables and removes tools at runtime. Changes
+// are written to the MCP file and applied to the running server, which notifies connected clients. The API
+// listens on the loopback interface unless another address is set, and is served over TLS with the tls of
+// streamableHttpConfig if it is configured, which is required to listen on other addresses.
+type AdminConfig struct {
+	// Address the admin API listens on, an IP address or a host name (default: 127.0.0.1). Addresses other than
+	// loopback ones require the tls of streamableHttpConfig, as the bearer tokens would be sent in cleartext.
+	Address string `json:"address,omitempty" jsonschema:"optional"`
+
+	// Port the admin API is served on. It must differ from the ports of the MCP transports.
+	Port int `json:"port" jsonschema:"required"`
+
+	// Base path of the admin API (default: /admin).
+	BasePath string `json:"basePath,omitempty" jsonschema:"optional"`
+
+	// Bearer tokens accepted by the admin API. At least one is required.
+	BearerTokens []string `json:"bearerTokens" jsonschema:"required"`
+}
+
+const (
+	AuditSinkFile   = "file"
+	AuditSinkSyslog = "syslog"
+	AuditSinkHTTP   = "http"
+)
+
+// AuditConfig defines the audit log of the tool calls of the server. A JSON record is written for every call,
+// with its timestamp, the subject of the caller, the tool, its arguments, its outcome and its latency. The audit
+// log is separate from the logs of loggingConfig.
+type AuditConfig struct {
+	// Where records are written: file appends them to a file as JSON lines, syslog sends them to a syslog
+	// daemon, and http POSTs each of them to a URL.
+	Sink string `json:"sink" jsonschema:"required,enum=file,enum=syslog,enum=http"`
+
+	// Path of the file records are appended to, for the file sink.
+	Path string `json:"path,omitempty" jsonschema:"optional"`
+
+	// URL records are sent to, for the http sink. Requests use the HTTP client of the server.
+	URL string `json:"url,omitempty" jsonschema:"optional"`
+
+	// Headers of the requests of the http sink, e.g. Authorization: Bearer ${AUDIT_TOKEN}.
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"optional"`
+
+	// Network of the syslog daemon (udp, tcp or unix), for the syslog sink. The local daemon is used if unset.
+	SyslogNetwork string `json:"syslogNetwork,omitempty" jsonschema:"optional"`
+
+	// Address of the syslog daemon, e.g. logs.example.com:514, for the syslog sink.
+	SyslogAddress string `json:"syslogAddress,omitempty" jsonschema:"optional"`
+
+	// Tag of the syslog messages (default: genmcp).
+	SyslogTag string `json:"syslogTag,omitempty" jsonschema:"optional"`
+
+	// How the arguments of calls are recorded: hash records a SHA-256 hash of them, so that identical calls can
+	// be matched without recording their values, and redacted records them with the values of redactFields
+	// replaced. Defaults to hash.
+	Arguments string `json:"arguments,omitempty" jsonschema:"optional,enum=hash,enum=redacted"`
+
+	// Names of the properties whose values are replaced with [REDACTED] at any depth of the arguments, when
+	// arguments is redacted. Names are matched case-insensitively.
+	RedactFields []string `json:"redactFields,omitempty" jsonschema:"optional"`
+
+	// What happens to the record of a call when the sink can't keep up and 1024 records are waiting to be
+	// written: fail waits up to queueTimeout for the record to be queued, and fails the call if it can't be,
+	// and drop drops the record. Dropped records are counted in the status of the admin API. Defaults to fail.
+	OnQueueFull string `json:"onQueueFull,omitempty" jsonschema:"optional,enum=fail,enum=drop"`
+
+	// How long a call waits for its record to be queued with onQueueFull fail, e.g. 1s (default: 5s).
+	QueueTimeout string `json:"queueTimeout,omitempty" jsonschema:"optional"`
+}
+
+const (
+	PolicyEngineCEL = "cel"
+	PolicyEngineOPA = "opa"
+)
+
+// PolicyConfig defines the authorization policy evaluated before every tool call, in addition to the
+// requiredScopes of the tools. The policy receives the claims of the caller, the name of the tool and the
+// arguments of the call, and denies the call or allows it, possibly with other arguments. Calls are denied if
+// the policy cannot be evaluated.
+type PolicyConfig struct {
+	// How the policy is evaluated: cel evaluates the CEL expression of expression, and opa queries the Data API
+	// of an Open Policy Agent server.
+	Engine string `json:"engine" jsonschema:"required,enum=cel,enum=opa"`
+
+	// CEL expression of the policy, for the cel engine. It can use the variables subject, tool and arguments,
+	// and returns a boolean, or a map with an allow boolean and optionally the reason of a denial and the
+	// arguments the tool is called with.
+	Expression string `json:"expression,omitempty" jsonschema:"optional"`
+
+	// URL of the decision of the policy, for the opa engine, e.g. http://localhost:8181/v1/data/genmcp/authz.
+	// Requests use the HTTP client of the server.
+	URL string `json:"url,omitempty" jsonschema:"optional"`
+
+	// How long the evaluation of the policy by OPA can take, for the opa engine (default: 5s).
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+// QuotasConfig defines the number of tool calls each client can make, counted by the subject of its
+// credentials, or by its OAuth client ID if it has no subject. Calls of unauthenticated clients share the
+// quotas of the anonymous client. Calls over a quota are rejected until the hour or day ends, and the usage
+// of each client is reported by the admin API. Counts are kept in memory by each replica of the server.
+type QuotasConfig struct {
+	// Maximum number of tool calls of each client per hour. Unlimited if 0.
+	PerHour int `json:"perHour,omitempty" jsonschema:"optional"`
+
+	// Maximum number of tool calls of each client per day, from midnight UTC. Unlimited if 0.
+	PerDay int `json:"perDay,omitempty" jsonschema:"optional"`
+
+	// Quotas of specific clients by subject, replacing perHour and perDay.
+	Clients map[string]*QuotaLimits `json:"clients,omitempty" jsonschema:"optional"`
+
+	// Quotas of the calls of each client to a tool by tool name, in addition to the quotas of the client.
+	Tools map[string]*QuotaLimits `json:"tools,omitempty" jsonschema:"optional"`
+}
+
+// QuotaLimits defines the maximum numbers of tool calls of a client per hour and per day.
+type QuotaLimits struct {
+	// Maximum number of calls per hour. Unlimited if 0.
+	PerHour int `json:"perHour,omitempty" jsonschema:"optional"`
+
+	// Maximum number of calls per day, from midnight UTC. Unlimited if 0.
+	PerDay int `json:"perDay,omitempty" jsonschema:"optional"`
+}
+
+// TenantsConfig defines the tenants of a server shared by several customers. The tenant of each call is
+// identified by a claim of the credentials of the caller or by a header of the request, and selects the
+// variables referenced by invocation templates as {tenant.NAME}, such as the base URL of its backend, the
+// secrets resolved for {secrets.NAME} and the quotas of the tool calls of all its clients.
+type TenantsConfig struct {
+	// Claim of the credentials of the caller identifying its tenant, e.g. tenant_id. Nested claims are
+	// referenced with dots, e.g. org.id. Exactly one of claim and header must be set.
+	Claim string `json:"claim,omitempty" jsonschema:"optional"`
+
+	// Header of the request identifying the tenant of the caller, e.g. X-Tenant-ID. Clients can select any
+	// tenant with it, so it must only be used behind a gateway setting it. Requests over stdio have no headers.
+	Header string `json:"header,omitempty" jsonschema:"optional"`
+
+	// Tenant of the calls whose tenant isn't identified, e.g. of unauthenticated clients, and of the tool calls
+	// of the schedules. Tool calls, prompts, resource reads and completions whose tenant isn't identified are
+	// rejected if unset. Calls identifying a tenant that isn't defined are always rejected.
+	Default string `json:"default,omitempty" jsonschema:"optional"`
+
+	// Tenants by ID.
+	Tenants map[string]*TenantConfig `json:"tenants" jsonschema:"required"`
+}
+
+// TenantConfig defines the settings of the calls of a tenant.
+type TenantConfig struct {
+	// Variables referenced by invocation templates as {tenant.NAME}, e.g. the base URL of the backend of the
+	// tenant. {tenant.id} references the ID of the tenant.
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"optional"`
+
+	// Secrets of the tenant, mapping the names referenced by invocation templates as {secrets.NAME} to the
+	// names of the secrets of the tenant in the providers of the server, e.g. API_KEY: ACME_API_KEY. Other
+	// secrets are resolved by their names.
+	Secrets map[string]string `json:"secrets,omitempty" jsonschema:"optional"`
+
+	// Quotas of the tool calls of all the clients of the tenant together, in addition to the quotas of each
+	// client. Unlimited if unset.
+	Quotas *QuotaLimits `json:"quotas,omitempty" jsonschema:"optional"`
+}
+
+// ToolLoadingConfig defines how the tools of the server are loaded and listed, to cut the startup time and the
+// memory of servers with hundreds of tools.
+type ToolLoadingConfig struct {
+	// Whether the invoker of each tool, parsing the templates of its invocation, is only created when the tool
+	// is first called, and then shared by every session. The invocations are still validated when the server
+	// starts, but the errors of their templates only fail the calls of their tools.
+	Lazy bool `json:"lazy,omitempty" jsonschema:"optional"`
+
+	// Maximum number of tools, prompts, resources or resource templates of a page of a list response. Clients
+	// request the next pages with the nextCursor of the responses (default: 1000).
+	PageSize int `json:"pageSize,omitempty" jsonschema:"optional"`
+}
+
+// ScheduleConfig defines a tool called by the server on a schedule, e.g. to refresh a report every morning.
+// The result of the last call is served as the resource schedule://<name>/last. A call is skipped while the
+// previous one is not finished. Calls are made by the server itself: the requiredScopes of the tool, the
+// policy and the quotas don't apply to them.
+type ScheduleConfig struct {
+	// Unique name of the schedule, made of letters, digits, '-', '_' and '.'.
+	Name string `json:"name" jsonschema:"required"`
+
+	// Name of the tool called.
+	Tool string `json:"tool" jsonschema:"required"`
+
+	// When the tool is called: a cron expression with five fields, the minute, hour, day of the month, month
+	// and day of the week (e.g. "0 6 * * 1-5"), a descriptor (@yearly, @monthly, @weekly, @daily or @hourly),
+	// or @every followed by a duration (e.g. "@every 15m"). Cron expressions use the local time zone of the
+	// server, unless prefixed with CRON_TZ=<zone>, e.g. "CRON_TZ=UTC 0 6 * * *".
+	Schedule string `json:"schedule" jsonschema:"required"`
+
+	// Arguments the tool is called with. The defaults of the tool apply to the arguments that are not set.
+	Arguments map[string]any `json:"arguments,omitempty" jsonschema:"optional"`
+
+	// Whether the tool is also called when the server starts, so that the resource has a result before the
+	// first scheduled call.
+	RunOnStart bool `json:"runOnStart,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of each call, e.g. 5m. Calls are only limited by the timeouts of the invocation of the
+	// tool if unset.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+// WebhookConfig defines an endpoint of the streamable HTTP transports of the server receiving the events of an
+// external service, e.g. the pushes of a git forge. The most recent events are served as the resource
+// webhook://<name>/events, whose subscribers are notified of every new event. Requests are verified with the
+// HMAC signature of their body if a secret is set, and are not authenticated by the auth of the transport.
+type WebhookConfig struct {
+	// Unique name of the webhook, made of letters, digits, '-', '_' and '.'.
+	Name string `json:"name" jsonschema:"required"`
+
+	// Path of the endpoint events are POSTed to, e.g. /webhooks/github. It must differ from the base path
+	// and the health paths of the streamable HTTP transports.
+	Path string `json:"path" jsonschema:"required"`
+
+	// Secret of the HMAC signatures of the requests, e.g. ${GITHUB_WEBHOOK_SECRET}. Requests are not verified
+	// if unset.
+	Secret string `json:"secret,omitempty" jsonschema:"optional"`
+
+	// Header of the signatures of the requests (default: X-Hub-Signature-256). Signatures may be prefixed
+	// with the algorithm, e.g. sha256=<hex>.
+	SignatureHeader string `json:"signatureHeader,omitempty" jsonschema:"optional"`
+
+	// Hash function of the signatures (default: sha256).
+	Algorithm string `json:"algorithm,omitempty" jsonschema:"optional,enum=sha1,enum=sha256,enum=sha512"`
+
+	// Encoding of the signatures (default: hex).
+	Encoding string `json:"encoding,omitempty" jsonschema:"optional,enum=hex,enum=base64"`
+
+	// Number of events kept in memory, the oldest events being dropped past it (default: 100).
+	MaxEvents int `json:"maxEvents,omitempty" jsonschema:"optional"`
+
+	// Maximum size in bytes of the body of a request. Larger requests are rejected (default: 1048576).
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" jsonschema:"optional"`
+}
+
+// SpoolConfig defines the spooling of large responses to disk. The HTTP responses of tool calls larger than
+// thresholdBytes are written to a file as they are received instead of being held in memory, and the tool call
+// returns a link to the resource spool://<id> instead of their content. Clients read the resource in chunks of
+// at most maxChunkBytes with spool://<id>?offset=<offset>&length=<length>, following the nextOffset of the
+// _meta of each chunk.
+type SpoolConfig struct {
+	// Directory of the spooled responses. A new directory of the temporary directory of the system is used
+	// if unset.
+	Dir string `json:"dir,omitempty" jsonschema:"optional"`
+
+	// Size in bytes above which responses are spooled (default: 10485760).
+	ThresholdBytes int64 `json:"thresholdBytes,omitempty" jsonschema:"optional"`
+
+	// Maximum size in bytes of a chunk read from a spooled response (default: 1048576).
+	MaxChunkBytes int `json:"maxChunkBytes,omitempty" jsonschema:"optional"`
+
+	// Time a spooled response is kept before it is deleted, e.g. 30m (default: 1h).
+	TTL string `json:"ttl,omitempty" jsonschema:"optional"`
+
+	// Maximum size in bytes of all the spooled responses kept. The tool calls whose responses would exceed
+	// it fail. Unlimited if 0.
+	MaxTotalBytes int64 `json:"maxTotalBytes,omitempty" jsonschema:"optional"`
+}
+
+// RecordingConfig defines the recording of the tool calls of the server as fixtures, and their replay. In record
+// mode, tools are invoked and the arguments and result of every call are written to a file of dir, with their
+// secrets redacted according to the redaction rules of loggingConfig. In replay mode, tools are not invoked: the
+// recorded result of a call with the same arguments is returned, and calls that were not recorded fail with a
+// backend_unavailable error.
+type RecordingConfig struct {
+	// Whether tool calls are recorded or replayed.
+	Mode string `json:"mode" jsonschema:"required,enum=record,enum=replay"`
+
+	// Directory of the fixtures, with a subdirectory per tool.
+	Dir string `json:"dir" jsonschema:"required"`
+}
+
+// SecurityConfig restricts what the invocations of the server can do with the incoming requests.
+type SecurityConfig struct {
+	// Headers of the incoming requests that invocation templates can reference as {headers.Name} and so forward
+	// to backends and commands, matched case-insensitively. MCP files referencing other headers are rejected
+	// when they are loaded. Any header can be referenced if unset.
+	AllowedHeaders []string `json:"allowedHeaders,omitempty" jsonschema:"optional"`
+
+	// Environment variables that invocation templates can reference as ${VAR} or {env.VAR}, and that the
+	// defaults of tools and proxy invocations can reference as ${VAR}. MCP files referencing other environment
+	// variables are rejected when they are loaded. Any environment variable can be referenced if neither
+	// allowedEnv nor tools is set.
+	AllowedEnv []string `json:"allowedEnv,omitempty" jsonschema:"optional"`
+
+	// Security config of individual tools of the MCP file, by tool name.
+	Tools map[string]*ToolSecurityConfig `json:"tools,omitempty" jsonschema:"optional"`
+
+	// Network restricts the hosts and addresses the outbound requests and connections of the server may target,
+	// to prevent tool definitions from reaching internal services. Link-local addresses and the metadata endpoints
+	// of cloud providers are denied even if unset.
+	Network *NetworkPolicyConfig `json:"network,omitempty" jsonschema:"optional"`
+}
+
+// NetworkPolicyConfig restricts the destinations of the outbound requests and connections of the server. Host
+// names match case-insensitively, and a leading "*." matches any subdomain, e.g. "*.example.com".
+type NetworkPolicyConfig struct {
+	// Host names, IP addresses and CIDRs requests may target. Host names that aren't allowed by name are
+	// allowed if the addresses they resolve to are in an allowed CIDR. Any destination that isn't denied may be
+	// targeted if unset.
+	AllowedHosts []string `json:"allowedHosts,omitempty" jsonschema:"optional"`
+
+	// Host names, IP addresses and CIDRs requests may never target, even if they are allowed.
+	DeniedHosts []string `json:"deniedHosts,omitempty" jsonschema:"optional"`
+
+	// Allows link-local addresses (169.254.0.0/16 and fe80::/10) and the metadata endpoints of cloud
+	// providers, which are denied by default as they expose the credentials of the machine.
+	AllowLinkLocal bool `json:"allowLinkLocal,omitempty" jsonschema:"optional"`
+}
+
+// ToolSecurityConfig restricts what the invocation of a tool can do, in addition to the security config of the server.
+type ToolSecurityConfig struct {
+	// Environment variables that the invocation and the defaults of the tool can reference, in addition to the
+	// allowedEnv of the server.
+	AllowedEnv []string `json:"allowedEnv,omitempty" jsonschema:"optional"`
+}
+
+const (
+	SecretProviderEnv       = "env"
+	SecretProviderFile      = "file"
+	SecretProviderDirectory = "directory"
+	SecretProviderVault     = "vault"
+)
+
+const (
+	VaultFetchStartup    = "startup"
+	VaultFetchInvocation = "invocation"
+)
+
+// SecretsConfig defines where the {secrets.NAME} references of invocation templates are resolved from.
+type SecretsConfig struct {
+	// Providers looked up in order, the first one having a secret providing its value.
+	Providers []*SecretProviderConfig `json:"providers" jsonschema:"required"`
+}
+
+// SecretProviderConfig defines a source of secrets.
+type SecretProviderConfig struct {
+	// Type of the provider: env reads environment variables, file reads a file of NAME=value lines
+	// (e.g. a dotenv file), directory reads a file per secret named after it, which is how
+	// Kubernetes mounts secrets, and vault reads the keys of a HashiCorp Vault secret.
+	Type string `json:"type" jsonschema:"required,enum=env,enum=file,enum=directory,enum=vault"`
+
+	// Prefix of the environment variables read by an env provider, e.g. SECRET_ to read
+	// {secrets.API_KEY} from SECRET_API_KEY.
+	Prefix string `json:"prefix,omitempty" jsonschema:"optional"`
+
+	// Path of the file or directory read by a file or directory provider.
+	Path string `json:"path,omitempty" jsonschema:"optional"`
+
+	// Configuration of a vault provider.
+	Vault *VaultConfig `json:"vault,omitempty" jsonschema:"optional"`
+}
+
+// VaultConfig defines a secret of a HashiCorp Vault KV secrets engine, whose keys are read as secrets.
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200. Requests use the clientTlsConfig of the runtime.
+	Address string `json:"address" jsonschema:"required"`
+
+	// Vault Enterprise namespace of the secret.
+	Namespace string `json:"namespace,omitempty" jsonschema:"optional"`
+
+	// Path the KV secrets engine is mounted at. Defaults to secret.
+	MountPath string `json:"mountPath,omitempty" jsonschema:"optional"`
+
+	// Path of the secret within the engine, e.g. genmcp/prod. Each of its keys is a secret.
+	Path string `json:"path" jsonschema:"required"`
+
+	// Version of the KV secrets engine. Defaults to 2.
+	KVVersion int `json:"kvVersion,omitempty" jsonschema:"optional,enum=1,enum=2"`
+
+	// How the server authenticates to Vault.
+	Auth *VaultAuthConfig `json:"auth" jsonschema:"required"`
+
+	// When the secret is fetched: startup fetches it once when the server starts, and fails the
+	// start if it cannot be read, invocation fetches it when it is first used and caches it for
+	// cacheTtl. Defaults to invocation.
+	Fetch string `json:"fetch,omitempty" jsonschema:"optional,enum=startup,enum=invocation"`
+
+	// How long a secret fetched on invocation is reused before being fetched again, e.g. 5m.
+	// 0s fetches it on every invocation. Defaults to 5m.
+	CacheTTL string `json:"cacheTtl,omitempty" jsonschema:"optional"`
+}
+
+// VaultAuthConfig defines how the server authenticates to Vault.
+type VaultAuthConfig struct {
+	// Auth method: token uses a Vault token, kubernetes logs in with the service account token of
+	// the pod, and approle logs in with a role ID and secret ID.
+	Method string `json:"method" jsonschema:"required,enum=token,enum=kubernetes,enum=approle"`
+
+	// File the Vault token is read from, for the token method. The VAULT_TOKEN environment
+	// variable is used if not set.
+	TokenFile string `json:"tokenFile,omitempty" jsonschema:"optional"`
+
+	// Path the auth method is mounted at, for the kubernetes and approle methods. Defaults to
+	// the name of the method.
+	MountPath string `json:"mountPath,omitempty" jsonschema:"optional"`
+
+	// Vault role to log in with, for the kubernetes method.
+	Role string `json:"role,omitempty" jsonschema:"optional"`
+
+	// File of the service account token, for the kubernetes method. Defaults to
+	// /var/run/secrets/kubernetes.io/serviceaccount/token.
+	JWTFile string `json:"jwtFile,omitempty" jsonschema:"optional"`
+
+	// Role ID to log in with, for the approle method.
+	RoleID string `json:"roleId,omitempty" jsonschema:"optional"`
+
+	// File the secret ID is read from, for the approle method. The VAULT_SECRET_ID environment
+	// variable is used if not set.
+	SecretIDFile string `json:"secretIdFile,omitempty" jsonschema:"optional"`
+}
+
 // ServerRuntime defines transport protocol and associated configuration.
 type ServerRuntime struct {
 	// Transport protocol to use (streamablehttp or stdio).
@@ -119,22 +750,122 @@ type ServerRuntime struct {
 	// Configuration for the server logging
 	LoggingConfig *logging.LoggingConfig `json:"loggingConfig" jsonschema:"optional"`
 
+	// Configuration for exporting OpenTelemetry traces. Tracing is disabled if unset.
+	TracingConfig *tracing.TracingConfig `json:"tracingConfig,omitempty" jsonschema:"optional"`
+
 	// TLS configuration for outbound HTTP requests (e.g., custom CA certificates).
 	// Use this when connecting to internal services that use certificates signed by a corporate CA.
 	ClientTLSConfig *ClientTLSConfig `json:"clientTlsConfig,omitempty" jsonschema:"optional"`
 
+	// Connection pool of the HTTP client shared by the outbound requests of the server.
+	// Pooled defaults are used if unset.
+	HTTPClient *HTTPClientConfig `json:"httpClient,omitempty" jsonschema:"optional"`
+
+	// Additional listeners started with the server, e.g. to serve over both stdio and streamable HTTP.
+	// The listeners share the logging, tracing, client TLS and limits configuration of the runtime.
+	Listeners []*ListenerConfig `json:"listeners,omitempty" jsonschema:"optional"`
+
+	// Size limits of the arguments sent by clients and of the content returned to them.
+	Limits *LimitsConfig `json:"limits,omitempty" jsonschema:"optional"`
+
+	// Limits of the number of invocations running concurrently. Only the maxConcurrency of tools
+	// applies if unset.
+	Concurrency *ConcurrencyConfig `json:"concurrency,omitempty" jsonschema:"optional"`
+
+	// Providers of the secrets referenced by invocation templates as {secrets.NAME}.
+	// Secrets are read from environment variables if unset.
+	Secrets *SecretsConfig `json:"secrets,omitempty" jsonschema:"optional"`
+
+	// Admin API managing the tool definitions of the server at runtime. Disabled if unset.
+	Admin *AdminConfig `json:"admin,omitempty" jsonschema:"optional"`
+
+	// Audit log of the tool calls of the server. Disabled if unset.
+	Audit *AuditConfig `json:"audit,omitempty" jsonschema:"optional"`
+
+	// Recording or replay of the tool calls of the server, for offline development and deterministic
+	// tests. Tools are invoked normally if unset.
+	Recording *RecordingConfig `json:"recording,omitempty" jsonschema:"optional"`
+
+	// Restrictions of the incoming request data available to invocations, such as the headers that can be
+	// forwarded to backends. Any header can be referenced if unset.
+	Security *SecurityConfig `json:"security,omitempty" jsonschema:"optional"`
+
+	// Authorization policy evaluated before every tool call. Calls are only authorized by the requiredScopes of
+	// the tools if unset.
+	Policy *PolicyConfig `json:"policy,omitempty" jsonschema:"optional"`
+
+	// Quotas of the tool calls of each client. Calls are only counted for the usage reported by the admin
+	// API if unset.
+	Quotas *QuotasConfig `json:"quotas,omitempty" jsonschema:"optional"`
+
+	// Tools called by the server on a schedule, whose last results are served as resources.
+	Schedules []*ScheduleConfig `json:"schedules,omitempty" jsonschema:"optional"`
+
+	// Endpoints of the streamable HTTP transports receiving the events of external services, whose recent
+	// events are served as resources.
+	Webhooks []*WebhookConfig `json:"webhooks,omitempty" jsonschema:"optional"`
+
+	// Spooling of the large responses of tool calls to disk, served as resources read in chunks. Responses
+	// are held in memory whatever their size if unset.
+	Spool *SpoolConfig `json:"spool,omitempty" jsonschema:"optional"`
+
+	// Tenants of a server shared by several customers, selecting the backends, secrets and quotas of each
+	// call. Every call uses the same settings if unset.
+	Tenants *TenantsConfig `json:"tenants,omitempty" jsonschema:"optional"`
+
+	// Loading and listing of the tools of MCP files with hundreds of tools. Tools are loaded when the server
+	// starts and listed in pages of 1000 if unset.
+	ToolLoading *ToolLoadingConfig `json:"toolLoading,omitempty" jsonschema:"optional"`
+
 	baseLogger     *zap.Logger
 	initLoggerOnce sync.Once
 
 	httpClient     *http.Client
 	httpClientErr  error
 	httpClientOnce sync.Once
+
+	secretStore     *secrets.Store
+	secretStoreErr  error
+	secretStoreOnce sync.Once
+
+	concurrencyPool     *concurrency.Pool
+	concurrencyPoolOnce sync.Once
+
+	auditLogger     *audit.Logger
+	auditLoggerErr  error
+	auditLoggerOnce sync.Once
+	auditLoggerMu   sync.Mutex
+
+	recordingStore     *recording.Store
+	recordingStoreErr  error
+	recordingStoreOnce sync.Once
+
+	policyEngine     policy.Engine
+	policyEngineErr  error
+	policyEngineOnce sync.Once
+
+	quotaTracker     *quota.Tracker
+	quotaTrackerOnce sync.Once
+
+	tenantQuotaTracker     *quota.Tracker
+	tenantQuotaTrackerOnce sync.Once
+
+	scheduler     *scheduler.Scheduler
+	schedulerOnce sync.Once
+
+	webhookReceivers     map[string]*webhook.Receiver
+	webhookReceiversErr  error
+	webhookReceiversOnce sync.Once
+
+	spool     *spool.Spool
+	spoolErr  error
+	spoolOnce sync.Once
 }
 
 // GetBaseLogger returns the base logger for the server.
-// If LoggingConfig is nil, it defaults to a console logger with info level to ensure
-// startup messages are visible as documented in tutorials.
-// If LoggingConfig is provided but fails to build, it falls back to a console logger.
+// If LoggingConfig is nil, it uses the default configuration of the logging package, a console logger
+// with info level ensuring startup messages are visible as documented in tutorials.
+// If LoggingConfig is provided but fails to build, it falls back to the default configuration.
 // If the runtime is nil, it returns a no-op logger.
 func (sr *ServerRuntime) GetBaseLogger() *zap.Logger {
 	if sr == nil {
@@ -144,46 +875,197 @@ func (sr *ServerRuntime) GetBaseLogger() *zap.Logger {
 	sr.initLoggerOnce.Do(func() {
 		if sr.LoggingConfig != nil {
 			logger, err := sr.LoggingConfig.BuildBase()
-			if err != nil || logger == nil {
-				// Surface the error to stderr before falling back
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "ERROR: Failed to build base logger, using default console logger: %v\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "ERROR: BuildBase returned nil logger, using default console logger\n")
-				}
-				// Fall back to default console logger
-				config := zap.NewDevelopmentConfig()
-				config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-				config.Encoding = "console"
-				logger, _ = config.Build()
-				if logger == nil {
-					logger = zap.NewNop()
-				}
+			if err == nil && logger != nil {
+				sr.baseLogger = logger
+				return
 			}
-			sr.baseLogger = logger
-		} else {
-			// Default to console logger with info level when no logging config is provided
-			// This ensures users can see startup messages as documented in tutorials
-			config := zap.NewDevelopmentConfig()
-			config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-			config.Encoding = "console"
-			logger, err := config.Build()
-			if err != nil || logger == nil {
-				// Last resort: use no-op logger
-				sr.baseLogger = zap.NewNop()
+
+			// Surface the error to stderr before falling back
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: Failed to build base logger, using default console logger: %v\n", err)
 			} else {
-				sr.baseLogger = logger
+				fmt.Fprintf(os.Stderr, "ERROR: BuildBase returned nil logger, using default console logger\n")
 			}
 		}
+
+		logger, err := logging.NewDefaultConfig().BuildBase()
+		if err != nil || logger == nil {
+			// Last resort: use no-op logger
+			logger = zap.NewNop()
+		}
+		sr.baseLogger = logger
 	})
 
 	return sr.baseLogger
 }
 
+// SetBaseLogger replaces the base logger of the server, e.g. with the logger of a program embedding it.
+// It must be called before the runtime is used, as its objects keep the logger they were created with.
+func (sr *ServerRuntime) SetBaseLogger(logger *zap.Logger) {
+	sr.initLoggerOnce.Do(func() {})
+	sr.baseLogger = logger
+}
+
+// ForListener returns the runtime of an additional listener. It uses the transport of the listener
+// and shares every other setting, the base logger, the HTTP client, the secret store, the concurrency
+// pool, the audit logger, the recording store, the policy engine, the quota trackers, the scheduler, the
+// webhook receivers and the spool with sr.
+func (sr *ServerRuntime) ForListener(l *ListenerConfig) *ServerRuntime {
+	lr := &ServerRuntime{
+		TransportProtocol:    l.TransportProtocol,
+		StreamableHTTPConfig: l.StreamableHTTPConfig,
+		StdioConfig:          l.StdioConfig,
+		LoggingConfig:        sr.LoggingConfig,
+		TracingConfig:        sr.TracingConfig,
+		ClientTLSConfig:      sr.ClientTLSConfig,
+		HTTPClient:           sr.HTTPClient,
+		Limits:               sr.Limits,
+		Concurrency:          sr.Concurrency,
+		Secrets:              sr.Secrets,
+		Audit:                sr.Audit,
+		Recording:            sr.Recording,
+		Security:             sr.Security,
+		Policy:               sr.Policy,
+		Quotas:               sr.Quotas,
+		Schedules:            sr.Schedules,
+		Webhooks:             sr.Webhooks,
+		Spool:                sr.Spool,
+		Tenants:              sr.Tenants,
+		ToolLoading:          sr.ToolLoading,
+	}
+
+	lr.initLoggerOnce.Do(func() {
+		lr.baseLogger = sr.GetBaseLogger().With(zap.String("listener", l.Name))
+	})
+	lr.httpClientOnce.Do(func() {
+		lr.httpClient, lr.httpClientErr = sr.GetHTTPClient()
+	})
+	lr.secretStoreOnce.Do(func() {
+		lr.secretStore, lr.secretStoreErr = sr.GetSecretStore()
+	})
+	lr.concurrencyPoolOnce.Do(func() {
+		lr.concurrencyPool = sr.GetConcurrencyPool()
+	})
+	lr.auditLoggerOnce.Do(func() {
+		lr.auditLogger, lr.auditLoggerErr = sr.GetAuditLogger()
+	})
+	lr.recordingStoreOnce.Do(func() {
+		lr.recordingStore, lr.recordingStoreErr = sr.GetRecordingStore()
+	})
+	lr.policyEngineOnce.Do(func() {
+		lr.policyEngine, lr.policyEngineErr = sr.GetPolicyEngine()
+	})
+	lr.quotaTrackerOnce.Do(func() {
+		lr.quotaTracker = sr.GetQuotaTracker()
+	})
+	lr.tenantQuotaTrackerOnce.Do(func() {
+		lr.tenantQuotaTracker = sr.GetTenantQuotaTracker()
+	})
+	lr.schedulerOnce.Do(func() {
+		lr.scheduler = sr.GetScheduler()
+	})
+	lr.webhookReceiversOnce.Do(func() {
+		lr.webhookReceivers, lr.webhookReceiversErr = sr.GetWebhookReceivers()
+	})
+	lr.spoolOnce.Do(func() {
+		lr.spool, lr.spoolErr = sr.GetSpool()
+	})
+
+	return lr
+}
+
 // MCPServerConfig defines the runtime configuration of an MCP server.
 type MCPServerConfig struct {
 	// Runtime configuration for the MCP server.
 	Runtime *ServerRuntime `json:"runtime,omitempty" jsonschema:"optional"`
+
+	// OpenAPI document whose operations are imported as tools at startup, next to the tools of the MCP file.
+	OpenAPIRef *OpenAPIRefConfig `json:"openapiRef,omitempty" jsonschema:"optional"`
+
+	// Upstream MCP servers whose tools are imported at startup, next to the tools of the MCP file, and served
+	// by forwarding their calls to them.
+	Upstreams []*UpstreamConfig `json:"upstreams,omitempty" jsonschema:"optional"`
+}
+
+// OpenAPIRefConfig defines an OpenAPI document fetched by the server, whose operations are served as tools
+// invoking the API directly, without generating an MCP file.
+type OpenAPIRefConfig struct {
+	// URL (http or https) or file path of the OpenAPI document (v2 or v3, JSON or YAML).
+	Source string `json:"source" jsonschema:"required"`
+
+	// Host of the API for a v2 document, or base URL of the API for a v3 document, if different than in the
+	// document. Required when the document doesn't set it, or sets a relative server URL.
+	Host string `json:"host,omitempty" jsonschema:"optional"`
+
+	// Only import the operations with one of these tags.
+	Tags []string `json:"tags,omitempty" jsonschema:"optional"`
+
+	// Only import the operations with one of these operationIds.
+	OperationIDs []string `json:"operationIds,omitempty" jsonschema:"optional"`
+
+	// How often the document is fetched again to keep the tools in sync with the API, as a duration
+	// (e.g. 10m). The document is only fetched at startup when unset.
+	RefreshInterval string `json:"refreshInterval,omitempty" jsonschema:"optional"`
+
+	// Prefix added to the names of the imported tools, e.g. "petstore_" to serve the operation addPet as
+	// petstore_addPet.
+	Prefix string `json:"prefix,omitempty" jsonschema:"optional"`
+
+	// What to do with imported tools named like a tool already served: skip them (default), fail with an
+	// error, rename them with the prefix "openapi_", or override the served tool.
+	OnConflict string `json:"onConflict,omitempty" jsonschema:"optional,enum=skip,enum=error,enum=rename,enum=override"`
+}
+
+// UpstreamConfig defines an upstream MCP server whose tools are served by forwarding their calls to it, so
+// that the server can aggregate several MCP servers behind a single one.
+type UpstreamConfig struct {
+	// Unique name of the upstream MCP server, used in logs.
+	Name string `json:"name" jsonschema:"required"`
+
+	// The command starting the upstream MCP server, connected to over its standard input and output
+	// (e.g. npx). Exactly one of command and url is required.
+	Command string `json:"command,omitempty" jsonschema:"optional"`
+
+	// The arguments of the command.
+	Args []string `json:"args,omitempty" jsonschema:"optional"`
+
+	// Environment variables of the command, in addition to the environment of the server.
+	Env map[string]string `json:"env,omitempty" jsonschema:"optional"`
+
+	// The URL of the upstream MCP server, using the streamable HTTP transport.
+	// Exactly one of command and url is required.
+	URL string `json:"url,omitempty" jsonschema:"optional"`
+
+	// HTTP headers sent to the upstream MCP server at the url, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"optional"`
+
+	// Only import the tools with one of these names. All the tools are imported when unset.
+	Tools []string `json:"tools,omitempty" jsonschema:"optional"`
+
+	// Prefix added to the names of the imported tools, e.g. "github_" to serve the tool create_issue as
+	// github_create_issue, so that the tools of different servers don't clash.
+	Prefix string `json:"prefix,omitempty" jsonschema:"optional"`
+
+	// What to do with imported tools named like a tool already served: skip them (default), fail with an
+	// error, rename them with the name of the upstream as prefix (e.g. github_create_issue), or override the
+	// served tool.
+	OnConflict string `json:"onConflict,omitempty" jsonschema:"optional,enum=skip,enum=error,enum=rename,enum=override"`
+
+	// Maximum duration of the calls, as a duration string (e.g. "10s"). Defaults to no limit.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+// ProxyConfig returns the invocation config of the tools imported from the upstream MCP server, without the
+// name of the upstream tool.
+func (u *UpstreamConfig) ProxyConfig() *proxy.ProxyInvocationConfig {
+	return &proxy.ProxyInvocationConfig{
+		Command: u.Command,
+		Args:    u.Args,
+		Env:     u.Env,
+		URL:     u.URL,
+		Headers: u.Headers,
+		Timeout: u.Timeout,
+	}
 }
 
 // MCPServerConfigFile is the root structure of a Server Config File (mcpserver.yaml).
@@ -194,6 +1076,15 @@ type MCPServerConfigFile struct {
 	// Version of the GenMCP config file format.
 	SchemaVersion string `json:"schemaVersion" jsonschema:"required"`
 
+	// Config files of the same kind this file is merged onto, in order: paths relative to this file, http(s)
+	// URLs, or OCI artifacts (oci://registry/repository:tag). Remote files are pinned by genmcp lock.
+	Extends []inherit.Reference `json:"extends,omitempty" jsonschema:"optional"`
+
+	// Partial server configs by name, e.g. dev, staging and prod, merged onto this file when selected with
+	// --profile or GENMCP_PROFILE. The selected profile is merged when the file is parsed, and the profiles
+	// are removed, so this is always empty once parsed.
+	Profiles map[string]any `json:"profiles,omitempty" jsonschema:"optional"`
+
 	// MCP server definition.
 	MCPServerConfig `json:",inline"`
 }
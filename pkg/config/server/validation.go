@@ -1,46 +1,1081 @@
 package server
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	"github.com/genmcp/gen-mcp/pkg/netpolicy"
+	"github.com/genmcp/gen-mcp/pkg/policy"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+	"github.com/genmcp/gen-mcp/pkg/scheduler"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/genmcp/gen-mcp/pkg/webhook"
 )
 
-func (m *MCPServerConfigFile) Validate() error {
+func (m *MCPServerConfigFile) Validate() error {
+	var err error = nil
+
+	if runtimeErr := m.Runtime.Validate(); runtimeErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config file, runtime is invalid: %w", runtimeErr))
+	}
+
+	if m.OpenAPIRef != nil {
+		if refErr := m.OpenAPIRef.Validate(); refErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid server config file, openapiRef is invalid: %w", refErr))
+		}
+	}
+
+	if upstreamsErr := validateUpstreams(m.Upstreams); upstreamsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config file, %w", upstreamsErr))
+	}
+
+	return err
+}
+
+func (s *MCPServerConfig) Validate() error {
+	var err error = nil
+
+	if runtimeErr := s.Runtime.Validate(); runtimeErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server, runtime is invalid: %w", runtimeErr))
+	}
+
+	if s.OpenAPIRef != nil {
+		if refErr := s.OpenAPIRef.Validate(); refErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid openapiRef: %w", refErr))
+		}
+	}
+
+	if upstreamsErr := validateUpstreams(s.Upstreams); upstreamsErr != nil {
+		err = errors.Join(err, upstreamsErr)
+	}
+
+	return err
+}
+
+func (o *OpenAPIRefConfig) Validate() error {
+	var err error = nil
+
+	if o.Source == "" {
+		err = errors.Join(err, fmt.Errorf("source is required"))
+	}
+
+	if o.RefreshInterval != "" {
+		if d, parseErr := time.ParseDuration(o.RefreshInterval); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("refreshInterval must be a positive duration, got '%s'", o.RefreshInterval))
+		}
+	}
+
+	if conflictErr := validateConflictPolicy(o.OnConflict); conflictErr != nil {
+		err = errors.Join(err, conflictErr)
+	}
+
+	return err
+}
+
+// validateConflictPolicy validates the onConflict of a source of imported tools.
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictPolicySkip, ConflictPolicyError, ConflictPolicyRename, ConflictPolicyOverride:
+		return nil
+	default:
+		return fmt.Errorf(
+			"onConflict must be one of (%s, %s, %s, %s), received %s",
+			ConflictPolicySkip,
+			ConflictPolicyError,
+			ConflictPolicyRename,
+			ConflictPolicyOverride,
+			policy,
+		)
+	}
+}
+
+// validateUpstreams validates the upstream MCP servers, whose names must be unique.
+func validateUpstreams(upstreams []*UpstreamConfig) error {
+	var err error = nil
+
+	names := make(map[string]bool, len(upstreams))
+	for i, u := range upstreams {
+		if upstreamErr := u.Validate(); upstreamErr != nil {
+			err = errors.Join(err, fmt.Errorf("upstreams[%d] is invalid: %w", i, upstreamErr))
+		}
+		if u.Name != "" && names[u.Name] {
+			err = errors.Join(err, fmt.Errorf("upstreams[%d] is invalid: duplicate name '%s'", i, u.Name))
+		}
+		names[u.Name] = true
+	}
+
+	return err
+}
+
+func (u *UpstreamConfig) Validate() error {
+	var err error = nil
+
+	if u.Name == "" {
+		err = errors.Join(err, fmt.Errorf("name is required"))
+	}
+
+	if proxyErr := u.ProxyConfig().Validate(); proxyErr != nil {
+		err = errors.Join(err, proxyErr)
+	}
+
+	for _, tool := range u.Tools {
+		if tool == "" {
+			err = errors.Join(err, fmt.Errorf("tools must not have empty names"))
+			break
+		}
+	}
+
+	if conflictErr := validateConflictPolicy(u.OnConflict); conflictErr != nil {
+		err = errors.Join(err, conflictErr)
+	}
+
+	return err
+}
+
+func (r *ServerRuntime) Validate() error {
+	err := validateTransport(r.TransportProtocol, r.StreamableHTTPConfig)
+
+	if r.LoggingConfig != nil {
+		if loggingErr := r.LoggingConfig.Validate(); loggingErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid loggingConfig: %w", loggingErr))
+		}
+	}
+
+	if r.TracingConfig != nil {
+		if tracingErr := r.TracingConfig.Validate(); tracingErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tracingConfig: %w", tracingErr))
+		}
+	}
+
+	if listenersErr := r.validateListeners(); listenersErr != nil {
+		err = errors.Join(err, listenersErr)
+	}
+
+	if r.HTTPClient != nil {
+		if httpClientErr := r.HTTPClient.Validate(); httpClientErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid httpClient: %w", httpClientErr))
+		}
+	}
+
+	if r.Limits != nil {
+		if limitsErr := r.Limits.Validate(); limitsErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid limits: %w", limitsErr))
+		}
+	}
+
+	if r.Concurrency != nil {
+		if concurrencyErr := r.Concurrency.Validate(); concurrencyErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid concurrency: %w", concurrencyErr))
+		}
+	}
+
+	if r.Secrets != nil {
+		if secretsErr := r.Secrets.Validate(); secretsErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid secrets: %w", secretsErr))
+		}
+	}
+
+	if r.Admin != nil {
+		if adminErr := r.Admin.Validate(); adminErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid admin: %w", adminErr))
+		}
+		// the bearer tokens of the admin API must not be sent in cleartext over the network
+		if !isLoopbackAddress(r.Admin.GetAddress()) && (r.StreamableHTTPConfig == nil || r.StreamableHTTPConfig.TLS == nil) {
+			err = errors.Join(err, fmt.Errorf("invalid admin: address %s is not a loopback address, which requires the tls of streamableHttpConfig", r.Admin.GetAddress()))
+		}
+	}
+
+	if r.Audit != nil {
+		if auditErr := r.Audit.Validate(); auditErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid audit: %w", auditErr))
+		}
+	}
+
+	if r.Recording != nil {
+		if recordingErr := r.Recording.Validate(); recordingErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid recording: %w", recordingErr))
+		}
+	}
+
+	if r.Security != nil {
+		if securityErr := r.Security.Validate(); securityErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid security: %w", securityErr))
+		}
+	}
+
+	if r.Policy != nil {
+		if policyErr := r.Policy.Validate(); policyErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid policy: %w", policyErr))
+		}
+	}
+
+	if r.Quotas != nil {
+		if quotasErr := r.Quotas.Validate(); quotasErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid quotas: %w", quotasErr))
+		}
+	}
+
+	if r.Tenants != nil {
+		if tenantsErr := r.Tenants.Validate(); tenantsErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid tenants: %w", tenantsErr))
+		}
+	}
+
+	if schedulesErr := r.validateSchedules(); schedulesErr != nil {
+		err = errors.Join(err, schedulesErr)
+	}
+
+	if webhooksErr := r.validateWebhooks(); webhooksErr != nil {
+		err = errors.Join(err, webhooksErr)
+	}
+
+	if r.Spool != nil {
+		if spoolErr := r.Spool.Validate(); spoolErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid spool: %w", spoolErr))
+		}
+	}
+
+	if r.ToolLoading != nil {
+		if toolLoadingErr := r.ToolLoading.Validate(); toolLoadingErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid toolLoading: %w", toolLoadingErr))
+		}
+	}
+
+	if destinationsErr := r.validateDestinations(); destinationsErr != nil {
+		err = errors.Join(err, destinationsErr)
+	}
+
+	return err
+}
+
+// validateDestinations checks that the services the server connects to outside of invocations, the policy
+// engine, the audit sink, the Vault servers and the authorization servers, may be targeted under the network
+// policy of the runtime.
+func (r *ServerRuntime) validateDestinations() error {
+	policy, policyErr := r.GetNetworkPolicy()
+	if policyErr != nil {
+		// reported by the validation of the security config
+		return nil
+	}
+
+	var err error = nil
+	checkURL := func(field, rawURL string) {
+		// URLs referencing environment variables are only checked when their requests are sent
+		u, parseErr := url.Parse(rawURL)
+		if parseErr != nil || u.Hostname() == "" || strings.ContainsAny(rawURL, "{$") {
+			return
+		}
+		if checkErr := policy.CheckHost(u.Hostname()); checkErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid %s '%s': %w", field, rawURL, checkErr))
+		}
+	}
+
+	if r.Policy != nil && r.Policy.Engine == PolicyEngineOPA {
+		checkURL("policy.url", r.Policy.URL)
+	}
+
+	if r.Audit != nil {
+		switch r.Audit.Sink {
+		case AuditSinkHTTP:
+			checkURL("audit.url", r.Audit.URL)
+		case AuditSinkSyslog:
+			if host, ok := syslogHost(r.Audit.SyslogNetwork, r.Audit.SyslogAddress); ok {
+				if checkErr := policy.CheckHost(host); checkErr != nil {
+					err = errors.Join(err, fmt.Errorf("invalid audit.syslogAddress '%s': %w", r.Audit.SyslogAddress, checkErr))
+				}
+			}
+		}
+	}
+
+	if r.Secrets != nil {
+		for i, p := range r.Secrets.Providers {
+			if p != nil && p.Vault != nil {
+				checkURL(fmt.Sprintf("secrets.providers[%d].vault.address", i), p.Vault.Address)
+			}
+		}
+	}
+
+	checkAuth := func(owner string, httpConfig *StreamableHTTPConfig) {
+		if httpConfig == nil || httpConfig.Auth == nil {
+			return
+		}
+		auth := httpConfig.Auth
+		checkURL(owner+"auth.jwksUri", auth.JWKSURI)
+		for i, server := range auth.AuthorizationServers {
+			checkURL(fmt.Sprintf("%sauth.authorizationServers[%d]", owner, i), server)
+		}
+		for i, issuer := range auth.Issuers {
+			if issuer == nil {
+				continue
+			}
+			checkURL(fmt.Sprintf("%sauth.issuers[%d].issuer", owner, i), issuer.Issuer)
+			checkURL(fmt.Sprintf("%sauth.issuers[%d].jwksUri", owner, i), issuer.JWKSURI)
+		}
+	}
+	checkAuth("streamableHttpConfig.", r.StreamableHTTPConfig)
+	for i, l := range r.Listeners {
+		if l != nil {
+			checkAuth(fmt.Sprintf("listeners[%d].streamableHttpConfig.", i), l.StreamableHTTPConfig)
+		}
+	}
+
+	return err
+}
+
+// syslogHost returns the host of the syslog daemon at address over network, if it is reached over the network.
+func syslogHost(network, address string) (string, bool) {
+	if network == "" || strings.HasPrefix(network, "unix") {
+		return "", false
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", false
+	}
+	return host, true
+}
+
+// validateSchedules validates the schedules of the runtime, whose names must be unique.
+func (r *ServerRuntime) validateSchedules() error {
+	var err error = nil
+
+	names := make(map[string]bool, len(r.Schedules))
+	for i, sc := range r.Schedules {
+		if sc == nil {
+			err = errors.Join(err, fmt.Errorf("schedules[%d] must not be empty", i))
+			continue
+		}
+
+		if scheduleErr := sc.Validate(); scheduleErr != nil {
+			err = errors.Join(err, fmt.Errorf("schedules[%d] is invalid: %w", i, scheduleErr))
+		}
+		if sc.Name != "" && names[sc.Name] {
+			err = errors.Join(err, fmt.Errorf("schedules[%d] is invalid: duplicate name '%s'", i, sc.Name))
+		}
+		names[sc.Name] = true
+	}
+
+	return err
+}
+
+func (sc *ScheduleConfig) Validate() error {
+	var err error = nil
+
+	if sc.Name == "" {
+		err = errors.Join(err, fmt.Errorf("name is required"))
+	} else if strings.IndexFunc(sc.Name, func(ch rune) bool {
+		return !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && !strings.ContainsRune("-_.", ch)
+	}) >= 0 {
+		err = errors.Join(err, fmt.Errorf("name '%s' must only contain letters, digits, '-', '_' and '.'", sc.Name))
+	}
+
+	if sc.Tool == "" {
+		err = errors.Join(err, fmt.Errorf("tool is required"))
+	}
+
+	if sc.Schedule == "" {
+		err = errors.Join(err, fmt.Errorf("schedule is required"))
+	} else if _, parseErr := scheduler.Parse(sc.Schedule); parseErr != nil {
+		err = errors.Join(err, parseErr)
+	}
+
+	if sc.Timeout != "" {
+		if d, parseErr := time.ParseDuration(sc.Timeout); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("timeout must be a positive duration, received %s", sc.Timeout))
+		}
+	}
+
+	return err
+}
+
+// validateWebhooks validates the webhooks of the runtime, whose names and paths must be unique. Their paths
+// must not be served by the streamable HTTP transports, which at least one transport must use.
+func (r *ServerRuntime) validateWebhooks() error {
+	if len(r.Webhooks) == 0 {
+		return nil
+	}
+
+	var err error = nil
+
+	reserved := make(map[string]bool)
+	addTransport := func(transportProtocol string, httpConfig *StreamableHTTPConfig) {
+		if transportProtocol != TransportProtocolStreamableHttp || httpConfig == nil {
+			return
+		}
+		reserved[cmp.Or(httpConfig.BasePath, DefaultBasePath)] = true
+		if httpConfig.Health.IsEnabled() {
+			liveness, readiness := DefaultLivenessPath, DefaultReadinessPath
+			if httpConfig.Health != nil {
+				liveness = cmp.Or(httpConfig.Health.LivenessPath, liveness)
+				readiness = cmp.Or(httpConfig.Health.ReadinessPath, readiness)
+			}
+			reserved[liveness] = true
+			reserved[readiness] = true
+		}
+	}
+	addTransport(r.TransportProtocol, r.StreamableHTTPConfig)
+	for _, l := range r.Listeners {
+		if l != nil {
+			addTransport(l.TransportProtocol, l.StreamableHTTPConfig)
+		}
+	}
+	if len(reserved) == 0 {
+		err = errors.Join(err, fmt.Errorf("webhooks require a transport using %s", TransportProtocolStreamableHttp))
+	}
+
+	names := make(map[string]bool, len(r.Webhooks))
+	paths := make(map[string]bool, len(r.Webhooks))
+	for i, wc := range r.Webhooks {
+		if wc == nil {
+			err = errors.Join(err, fmt.Errorf("webhooks[%d] must not be empty", i))
+			continue
+		}
+
+		if webhookErr := wc.Validate(); webhookErr != nil {
+			err = errors.Join(err, fmt.Errorf("webhooks[%d] is invalid: %w", i, webhookErr))
+		}
+		if wc.Name != "" && names[wc.Name] {
+			err = errors.Join(err, fmt.Errorf("webhooks[%d] is invalid: duplicate name '%s'", i, wc.Name))
+		}
+		names[wc.Name] = true
+		if wc.Path != "" && paths[wc.Path] {
+			err = errors.Join(err, fmt.Errorf("webhooks[%d] is invalid: duplicate path '%s'", i, wc.Path))
+		}
+		paths[wc.Path] = true
+		if reserved[wc.Path] {
+			err = errors.Join(err, fmt.Errorf("webhooks[%d] is invalid: path '%s' is already served by a streamable HTTP transport", i, wc.Path))
+		}
+	}
+
+	return err
+}
+
+func (wc *WebhookConfig) Validate() error {
+	var err error = nil
+
+	if wc.Name == "" {
+		err = errors.Join(err, fmt.Errorf("name is required"))
+	} else if strings.IndexFunc(wc.Name, func(ch rune) bool {
+		return !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && !strings.ContainsRune("-_.", ch)
+	}) >= 0 {
+		err = errors.Join(err, fmt.Errorf("name '%s' must only contain letters, digits, '-', '_' and '.'", wc.Name))
+	}
+
+	if wc.Path == "" {
+		err = errors.Join(err, fmt.Errorf("path is required"))
+	} else if !strings.HasPrefix(wc.Path, "/") || strings.ContainsAny(wc.Path, "{}? ") {
+		err = errors.Join(err, fmt.Errorf("path must be an absolute path without wildcards, received %s", wc.Path))
+	} else if strings.HasPrefix(wc.Path, "/.well-known/") {
+		err = errors.Join(err, fmt.Errorf("path must not be under /.well-known/, received %s", wc.Path))
+	}
+
+	switch wc.Algorithm {
+	case "", webhook.AlgorithmSHA1, webhook.AlgorithmSHA256, webhook.AlgorithmSHA512:
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"algorithm must be one of (%s, %s, %s), received %s",
+			webhook.AlgorithmSHA1,
+			webhook.AlgorithmSHA256,
+			webhook.AlgorithmSHA512,
+			wc.Algorithm,
+		))
+	}
+
+	switch wc.Encoding {
+	case "", webhook.EncodingHex, webhook.EncodingBase64:
+	default:
+		err = errors.Join(err, fmt.Errorf("encoding must be one of (%s, %s), received %s", webhook.EncodingHex, webhook.EncodingBase64, wc.Encoding))
+	}
+
+	if wc.Secret == "" && (wc.SignatureHeader != "" || wc.Algorithm != "" || wc.Encoding != "") {
+		err = errors.Join(err, fmt.Errorf("signatureHeader, algorithm and encoding require a secret"))
+	}
+
+	if wc.MaxEvents < 0 {
+		err = errors.Join(err, fmt.Errorf("maxEvents must not be negative"))
+	}
+	if wc.MaxBodyBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("maxBodyBytes must not be negative"))
+	}
+
+	return err
+}
+
+func (sc *SpoolConfig) Validate() error {
+	var err error = nil
+
+	if sc.ThresholdBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("thresholdBytes must not be negative"))
+	}
+	if sc.MaxChunkBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("maxChunkBytes must not be negative"))
+	}
+	if sc.MaxTotalBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("maxTotalBytes must not be negative"))
+	}
+
+	if sc.TTL != "" {
+		if d, parseErr := time.ParseDuration(sc.TTL); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("ttl must be a positive duration, received %s", sc.TTL))
+		}
+	}
+
+	return err
+}
+
+func (tl *ToolLoadingConfig) Validate() error {
+	var err error = nil
+
+	if tl.PageSize < 0 {
+		err = errors.Join(err, fmt.Errorf("pageSize must not be negative"))
+	}
+
+	return err
+}
+
+func (q *QuotasConfig) Validate() error {
+	var err error = nil
+
+	if limitsErr := (&QuotaLimits{PerHour: q.PerHour, PerDay: q.PerDay}).Validate(); limitsErr != nil {
+		err = errors.Join(err, limitsErr)
+	}
+	for _, client := range slices.Sorted(maps.Keys(q.Clients)) {
+		if client == "" {
+			err = errors.Join(err, fmt.Errorf("clients must not have empty client names"))
+		} else if q.Clients[client] == nil {
+			err = errors.Join(err, fmt.Errorf("clients[%s] must not be empty", client))
+		} else if limitsErr := q.Clients[client].Validate(); limitsErr != nil {
+			err = errors.Join(err, fmt.Errorf("clients[%s] is invalid: %w", client, limitsErr))
+		}
+	}
+	for _, tool := range slices.Sorted(maps.Keys(q.Tools)) {
+		if tool == "" {
+			err = errors.Join(err, fmt.Errorf("tools must not have empty tool names"))
+		} else if q.Tools[tool] == nil {
+			err = errors.Join(err, fmt.Errorf("tools[%s] must not be empty", tool))
+		} else if limitsErr := q.Tools[tool].Validate(); limitsErr != nil {
+			err = errors.Join(err, fmt.Errorf("tools[%s] is invalid: %w", tool, limitsErr))
+		}
+	}
+
+	return err
+}
+
+func (t *TenantsConfig) Validate() error {
+	var err error = nil
+
+	if (t.Claim == "") == (t.Header == "") {
+		err = errors.Join(err, fmt.Errorf("exactly one of claim and header must be set"))
+	}
+	if len(t.Tenants) == 0 {
+		err = errors.Join(err, fmt.Errorf("tenants must not be empty"))
+	}
+	if t.Default != "" && t.Tenants[t.Default] == nil {
+		err = errors.Join(err, fmt.Errorf("default tenant '%s' is not defined", t.Default))
+	}
+	for _, id := range slices.Sorted(maps.Keys(t.Tenants)) {
+		if id == "" {
+			err = errors.Join(err, fmt.Errorf("tenants must not have empty tenant IDs"))
+		} else if t.Tenants[id] == nil {
+			err = errors.Join(err, fmt.Errorf("tenants[%s] must not be empty", id))
+		} else if tenantErr := t.Tenants[id].Validate(); tenantErr != nil {
+			err = errors.Join(err, fmt.Errorf("tenants[%s] is invalid: %w", id, tenantErr))
+		}
+	}
+
+	return err
+}
+
+func (t *TenantConfig) Validate() error {
+	var err error = nil
+
+	for _, name := range slices.Sorted(maps.Keys(t.Variables)) {
+		if name == "" || strings.ContainsAny(name, "{}.") {
+			err = errors.Join(err, fmt.Errorf("invalid variable name '%s'", name))
+		} else if name == template.TenantIDVariable {
+			err = errors.Join(err, fmt.Errorf("variable name '%s' is reserved for the tenant ID", name))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(t.Secrets)) {
+		if name == "" || t.Secrets[name] == "" {
+			err = errors.Join(err, fmt.Errorf("secrets must map non-empty secret names, received '%s': '%s'", name, t.Secrets[name]))
+		}
+	}
+	if t.Quotas != nil {
+		if quotasErr := t.Quotas.Validate(); quotasErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid quotas: %w", quotasErr))
+		}
+	}
+
+	return err
+}
+
+func (l *QuotaLimits) Validate() error {
 	var err error = nil
 
-	if runtimeErr := m.Runtime.Validate(); runtimeErr != nil {
-		err = errors.Join(err, fmt.Errorf("invalid server config file, runtime is invalid: %w", runtimeErr))
+	if l.PerHour < 0 {
+		err = errors.Join(err, fmt.Errorf("perHour must not be negative"))
+	}
+	if l.PerDay < 0 {
+		err = errors.Join(err, fmt.Errorf("perDay must not be negative"))
 	}
 
 	return err
 }
 
-func (s *MCPServerConfig) Validate() error {
+func (a *AdminConfig) Validate() error {
 	var err error = nil
 
-	if runtimeErr := s.Runtime.Validate(); runtimeErr != nil {
-		err = errors.Join(err, fmt.Errorf("invalid server, runtime is invalid: %w", runtimeErr))
+	if _, _, splitErr := net.SplitHostPort(a.Address); splitErr == nil {
+		err = errors.Join(err, fmt.Errorf("address must not include a port, received %s", a.Address))
+	}
+
+	if a.Port <= 0 {
+		err = errors.Join(err, fmt.Errorf("port must be greater than 0"))
+	}
+
+	if a.BasePath != "" && !strings.HasPrefix(a.BasePath, "/") {
+		err = errors.Join(err, fmt.Errorf("basePath must start with '/'"))
+	}
+
+	if len(a.BearerTokens) == 0 {
+		err = errors.Join(err, fmt.Errorf("at least one bearer token is required"))
+	}
+	for i, token := range a.BearerTokens {
+		if token == "" {
+			err = errors.Join(err, fmt.Errorf("bearerTokens[%d] must not be empty", i))
+		}
 	}
 
 	return err
 }
 
-func (r *ServerRuntime) Validate() error {
+func (s *SessionsConfig) Validate() error {
+	var err error = nil
+
+	switch s.Store {
+	case "", SessionStoreMemory:
+		if s.Redis != nil {
+			err = errors.Join(err, fmt.Errorf("redis can only be set for the %s store", SessionStoreRedis))
+		}
+	case SessionStoreRedis:
+		if s.Redis == nil {
+			err = errors.Join(err, fmt.Errorf("redis is required for the %s store", SessionStoreRedis))
+		} else if redisErr := s.Redis.Validate(); redisErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid redis: %w", redisErr))
+		}
+		if s.MaxSessions != 0 {
+			err = errors.Join(err, fmt.Errorf("maxSessions can only be set for the %s store", SessionStoreMemory))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"store must be one of (%s, %s), received %s",
+			SessionStoreMemory,
+			SessionStoreRedis,
+			s.Store,
+		))
+	}
+
+	if s.TTL != "" {
+		if d, parseErr := time.ParseDuration(s.TTL); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("ttl must be a positive duration, received %s", s.TTL))
+		}
+	}
+
+	if s.MaxSessions < 0 {
+		err = errors.Join(err, fmt.Errorf("maxSessions must not be negative"))
+	}
+
+	return err
+}
+
+func (r *RedisConfig) Validate() error {
+	var err error = nil
+
+	if r.Address == "" {
+		err = errors.Join(err, fmt.Errorf("address is required"))
+	} else if _, _, splitErr := net.SplitHostPort(r.Address); splitErr != nil {
+		err = errors.Join(err, fmt.Errorf("address must be host:port, received %s", r.Address))
+	}
+
+	if r.DB < 0 {
+		err = errors.Join(err, fmt.Errorf("db must not be negative"))
+	}
+
+	if r.Username != "" && r.Password == "" {
+		err = errors.Join(err, fmt.Errorf("password is required when username is set"))
+	}
+
+	return err
+}
+
+func (s *SecretsConfig) Validate() error {
+	var err error = nil
+
+	if len(s.Providers) == 0 {
+		err = errors.Join(err, fmt.Errorf("at least one provider is required"))
+	}
+
+	for i, p := range s.Providers {
+		if p == nil {
+			err = errors.Join(err, fmt.Errorf("providers[%d] must not be empty", i))
+			continue
+		}
+
+		switch p.Type {
+		case SecretProviderEnv:
+			if p.Path != "" {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: path cannot be set for env providers", i))
+			}
+		case SecretProviderFile, SecretProviderDirectory:
+			if p.Path == "" {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: path is required for %s providers", i, p.Type))
+			}
+			if p.Prefix != "" {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: prefix can only be set for env providers", i))
+			}
+		case SecretProviderVault:
+			if p.Vault == nil {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: vault is required for vault providers", i))
+			} else if vaultErr := p.Vault.Validate(); vaultErr != nil {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: invalid vault: %w", i, vaultErr))
+			}
+			if p.Path != "" || p.Prefix != "" {
+				err = errors.Join(err, fmt.Errorf("providers[%d]: path and prefix cannot be set for vault providers", i))
+			}
+		default:
+			err = errors.Join(err, fmt.Errorf(
+				"providers[%d]: type must be one of (%s, %s, %s, %s), received %s",
+				i,
+				SecretProviderEnv,
+				SecretProviderFile,
+				SecretProviderDirectory,
+				SecretProviderVault,
+				p.Type,
+			))
+		}
+
+		if p.Type != SecretProviderVault && p.Vault != nil {
+			err = errors.Join(err, fmt.Errorf("providers[%d]: vault can only be set for vault providers", i))
+		}
+	}
+
+	return err
+}
+
+func (v *VaultConfig) Validate() error {
+	var err error = nil
+
+	if v.Address == "" {
+		err = errors.Join(err, fmt.Errorf("address is required"))
+	} else if u, parseErr := url.Parse(v.Address); parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = errors.Join(err, fmt.Errorf("address must be an http or https URL, received %s", v.Address))
+	}
+
+	if v.Path == "" {
+		err = errors.Join(err, fmt.Errorf("path is required"))
+	}
+
+	if v.KVVersion != 0 && v.KVVersion != 1 && v.KVVersion != 2 {
+		err = errors.Join(err, fmt.Errorf("kvVersion must be 1 or 2, received %d", v.KVVersion))
+	}
+
+	switch v.Fetch {
+	case "", VaultFetchInvocation:
+		if v.CacheTTL != "" {
+			if d, parseErr := time.ParseDuration(v.CacheTTL); parseErr != nil || d < 0 {
+				err = errors.Join(err, fmt.Errorf("cacheTtl must be a non-negative duration, received %s", v.CacheTTL))
+			}
+		}
+	case VaultFetchStartup:
+		if v.CacheTTL != "" {
+			err = errors.Join(err, fmt.Errorf("cacheTtl cannot be set when fetch is %s", VaultFetchStartup))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"fetch must be one of (%s, %s), received %s",
+			VaultFetchStartup,
+			VaultFetchInvocation,
+			v.Fetch,
+		))
+	}
+
+	if v.Auth == nil {
+		err = errors.Join(err, fmt.Errorf("auth is required"))
+	} else if authErr := v.Auth.Validate(); authErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid auth: %w", authErr))
+	}
+
+	return err
+}
+
+func (a *VaultAuthConfig) Validate() error {
+	var err error = nil
+
+	switch a.Method {
+	case secrets.VaultAuthToken:
+		if a.MountPath != "" || a.Role != "" || a.JWTFile != "" || a.RoleID != "" || a.SecretIDFile != "" {
+			err = errors.Join(err, fmt.Errorf("only tokenFile can be set for the token method"))
+		}
+	case secrets.VaultAuthKubernetes:
+		if a.Role == "" {
+			err = errors.Join(err, fmt.Errorf("role is required for the kubernetes method"))
+		}
+		if a.TokenFile != "" || a.RoleID != "" || a.SecretIDFile != "" {
+			err = errors.Join(err, fmt.Errorf("only mountPath, role and jwtFile can be set for the kubernetes method"))
+		}
+	case secrets.VaultAuthAppRole:
+		if a.RoleID == "" {
+			err = errors.Join(err, fmt.Errorf("roleId is required for the approle method"))
+		}
+		if a.TokenFile != "" || a.Role != "" || a.JWTFile != "" {
+			err = errors.Join(err, fmt.Errorf("only mountPath, roleId and secretIdFile can be set for the approle method"))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"method must be one of (%s, %s, %s), received %s",
+			secrets.VaultAuthToken,
+			secrets.VaultAuthKubernetes,
+			secrets.VaultAuthAppRole,
+			a.Method,
+		))
+	}
+
+	return err
+}
+
+func (c *HTTPClientConfig) Validate() error {
+	var err error = nil
+
+	if c.MaxIdleConns < 0 {
+		err = errors.Join(err, fmt.Errorf("maxIdleConns must not be negative"))
+	}
+	if c.MaxIdleConnsPerHost < 0 {
+		err = errors.Join(err, fmt.Errorf("maxIdleConnsPerHost must not be negative"))
+	}
+	if c.MaxConnsPerHost < 0 {
+		err = errors.Join(err, fmt.Errorf("maxConnsPerHost must not be negative"))
+	}
+	if c.IdleConnTimeout != "" {
+		if d, parseErr := time.ParseDuration(c.IdleConnTimeout); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("idleConnTimeout must be a positive duration, received %s", c.IdleConnTimeout))
+		}
+	}
+
+	return err
+}
+
+func (c *ConcurrencyConfig) Validate() error {
+	var err error = nil
+
+	if c.MaxInFlight < 0 {
+		err = errors.Join(err, fmt.Errorf("maxInFlight must not be negative"))
+	}
+	if c.MaxQueued < 0 {
+		err = errors.Join(err, fmt.Errorf("maxQueued must not be negative"))
+	}
+	if c.QueueTimeout != "" {
+		if d, parseErr := time.ParseDuration(c.QueueTimeout); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("queueTimeout must be a positive duration, received %s", c.QueueTimeout))
+		}
+	}
+
+	return err
+}
+
+func (a *AuditConfig) Validate() error {
+	var err error = nil
+
+	switch a.Sink {
+	case AuditSinkFile:
+		if a.Path == "" {
+			err = errors.Join(err, fmt.Errorf("path is required for the %s sink", AuditSinkFile))
+		}
+	case AuditSinkSyslog:
+		if a.SyslogNetwork != "" && a.SyslogAddress == "" {
+			err = errors.Join(err, fmt.Errorf("syslogAddress is required when syslogNetwork is set"))
+		}
+	case AuditSinkHTTP:
+		if a.URL == "" {
+			err = errors.Join(err, fmt.Errorf("url is required for the %s sink", AuditSinkHTTP))
+		} else if u, parseErr := url.Parse(a.URL); parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			err = errors.Join(err, fmt.Errorf("url must be an http or https URL, received %s", a.URL))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"sink must be one of (%s, %s, %s), received %s",
+			AuditSinkFile,
+			AuditSinkSyslog,
+			AuditSinkHTTP,
+			a.Sink,
+		))
+	}
+
+	switch a.Arguments {
+	case "", audit.ArgumentsHash:
+		if len(a.RedactFields) > 0 {
+			err = errors.Join(err, fmt.Errorf("redactFields can only be set when arguments is %s", audit.ArgumentsRedacted))
+		}
+	case audit.ArgumentsRedacted:
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"arguments must be one of (%s, %s), received %s",
+			audit.ArgumentsHash,
+			audit.ArgumentsRedacted,
+			a.Arguments,
+		))
+	}
+
+	switch a.OnQueueFull {
+	case "", audit.QueueFullFail, audit.QueueFullDrop:
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"onQueueFull must be one of (%s, %s), received %s",
+			audit.QueueFullFail,
+			audit.QueueFullDrop,
+			a.OnQueueFull,
+		))
+	}
+
+	if a.QueueTimeout != "" {
+		if d, parseErr := time.ParseDuration(a.QueueTimeout); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("queueTimeout must be a positive duration, received %s", a.QueueTimeout))
+		}
+	}
+
+	return err
+}
+
+func (rc *RecordingConfig) Validate() error {
+	var err error = nil
+
+	switch rc.Mode {
+	case recording.ModeRecord, recording.ModeReplay:
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"mode must be one of (%s, %s), received %s",
+			recording.ModeRecord,
+			recording.ModeReplay,
+			rc.Mode,
+		))
+	}
+
+	if rc.Dir == "" {
+		err = errors.Join(err, fmt.Errorf("dir is required"))
+	}
+
+	return err
+}
+
+func (l *LimitsConfig) Validate() error {
+	var err error = nil
+
+	if l.MaxResponseBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("maxResponseBytes must not be negative"))
+	}
+	if l.MaxArgumentBytes < 0 {
+		err = errors.Join(err, fmt.Errorf("maxArgumentBytes must not be negative"))
+	}
+
+	switch l.TruncationStrategy {
+	case "", TruncationStrategyHead, TruncationStrategyTail, TruncationStrategySummary:
+	default:
+		err = errors.Join(err, fmt.Errorf(
+			"truncationStrategy must be one of (%s, %s, %s), received %s",
+			TruncationStrategyHead,
+			TruncationStrategyTail,
+			TruncationStrategySummary,
+			l.TruncationStrategy,
+		))
+	}
+
+	return err
+}
+
+// validateListeners checks that the listeners are uniquely named and that no two transports of
+// the runtime compete for stdio or for the same port.
+func (r *ServerRuntime) validateListeners() error {
+	var err error = nil
+
+	stdioCount := 0
+	ports := make(map[int]string)
+	addTransport := func(owner, transportProtocol string, httpConfig *StreamableHTTPConfig) {
+		switch transportProtocol {
+		case TransportProtocolStdio:
+			stdioCount++
+		case TransportProtocolStreamableHttp:
+			if httpConfig == nil || httpConfig.Port <= 0 {
+				return
+			}
+			if other, ok := ports[httpConfig.Port]; ok {
+				err = errors.Join(err, fmt.Errorf("%s uses port %d, which is already used by %s", owner, httpConfig.Port, other))
+				return
+			}
+			ports[httpConfig.Port] = owner
+		}
+	}
+
+	addTransport("the runtime", r.TransportProtocol, r.StreamableHTTPConfig)
+
+	names := make(map[string]bool, len(r.Listeners))
+	for i, l := range r.Listeners {
+		if l == nil {
+			err = errors.Join(err, fmt.Errorf("listeners[%d] must not be empty", i))
+			continue
+		}
+
+		if l.Name == "" {
+			err = errors.Join(err, fmt.Errorf("listeners[%d].name is required", i))
+		} else if names[l.Name] {
+			err = errors.Join(err, fmt.Errorf("duplicate listener name '%s'", l.Name))
+		}
+		names[l.Name] = true
+
+		if transportErr := validateTransport(l.TransportProtocol, l.StreamableHTTPConfig); transportErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid listener '%s': %w", l.Name, transportErr))
+		}
+
+		addTransport(fmt.Sprintf("listener '%s'", l.Name), l.TransportProtocol, l.StreamableHTTPConfig)
+	}
+
+	if stdioCount > 1 {
+		err = errors.Join(err, fmt.Errorf("at most one transport may use %s, found %d", TransportProtocolStdio, stdioCount))
+	}
+
+	if r.Admin != nil {
+		if other, ok := ports[r.Admin.Port]; ok {
+			err = errors.Join(err, fmt.Errorf("the admin API uses port %d, which is already used by %s", r.Admin.Port, other))
+		}
+	}
+
+	return err
+}
+
+func validateTransport(transportProtocol string, httpConfig *StreamableHTTPConfig) error {
 	var err error = nil
-	if r.TransportProtocol != TransportProtocolStdio && r.TransportProtocol != TransportProtocolStreamableHttp {
+	if transportProtocol != TransportProtocolStdio && transportProtocol != TransportProtocolStreamableHttp {
 		err = errors.Join(
 			err,
 			fmt.Errorf(
 				"invalid runtime: transport protocol must be one of (%s, %s), received %s",
 				TransportProtocolStdio,
 				TransportProtocolStreamableHttp,
-				r.TransportProtocol,
+				transportProtocol,
 			),
 		)
 	}
 
-	if r.TransportProtocol == TransportProtocolStreamableHttp {
-		if r.StreamableHTTPConfig == nil {
+	if transportProtocol == TransportProtocolStreamableHttp {
+		if httpConfig == nil {
 			err = errors.Join(
 				err,
 				fmt.Errorf(
@@ -50,11 +1085,193 @@ func (r *ServerRuntime) Validate() error {
 			)
 		} else {
 			// Only validate fields if StreamableHTTPConfig is set
-			if r.StreamableHTTPConfig.Port <= 0 {
+			if httpConfig.Port <= 0 {
 				err = errors.Join(err, fmt.Errorf("streamableHttpConfig.port must be greater than 0"))
 			}
+			if httpConfig.Auth != nil {
+				if authErr := httpConfig.Auth.Validate(); authErr != nil {
+					err = errors.Join(err, fmt.Errorf("invalid streamableHttpConfig.auth: %w", authErr))
+				}
+			}
+			if httpConfig.TLS != nil {
+				if tlsErr := httpConfig.TLS.Validate(); tlsErr != nil {
+					err = errors.Join(err, fmt.Errorf("invalid streamableHttpConfig.tls: %w", tlsErr))
+				}
+				if httpConfig.TLS.ClientAuth != nil && len(httpConfig.TLS.ClientAuth.Scopes) > 0 && httpConfig.Auth != nil {
+					err = errors.Join(err, fmt.Errorf("streamableHttpConfig.tls.clientAuth.scopes cannot be used with streamableHttpConfig.auth, the scopes of the auth credentials are used"))
+				}
+			}
+			if httpConfig.Sessions != nil {
+				if httpConfig.IsStateless() {
+					err = errors.Join(err, fmt.Errorf("streamableHttpConfig.sessions can only be set when streamableHttpConfig.stateless is false"))
+				}
+				if sessionsErr := httpConfig.Sessions.Validate(); sessionsErr != nil {
+					err = errors.Join(err, fmt.Errorf("invalid streamableHttpConfig.sessions: %w", sessionsErr))
+				}
+			}
+		}
+	}
+
+	return err
+}
+
+func (t *TLSConfig) Validate() error {
+	var err error = nil
+	if t.ClientAuth != nil {
+		if len(t.ClientAuth.CACertFiles) == 0 {
+			err = errors.Join(err, fmt.Errorf("clientAuth.caCertFiles must contain at least one CA certificate file"))
+		}
+		for i, san := range t.ClientAuth.AllowedSANs {
+			if san == "" {
+				err = errors.Join(err, fmt.Errorf("clientAuth.allowedSans[%d] must not be empty", i))
+			}
+		}
+	}
+
+	return err
+}
+
+func (a *AuthConfig) Validate() error {
+	var err error = nil
+
+	for i, token := range a.BearerTokens {
+		if token == "" {
+			err = errors.Join(err, fmt.Errorf("bearerTokens[%d] must not be empty", i))
+		}
+	}
+
+	if a.BasicAuth != nil {
+		for user, password := range a.BasicAuth.Users {
+			if user == "" || strings.Contains(user, ":") {
+				err = errors.Join(err, fmt.Errorf("invalid basic auth user name '%s': must be non-empty and must not contain ':'", user))
+			}
+			if password == "" {
+				err = errors.Join(err, fmt.Errorf("password of basic auth user '%s' must not be empty", user))
+			}
+		}
+	}
+
+	if len(a.StaticScopes) > 0 && !a.HasStaticCredentials() {
+		err = errors.Join(err, fmt.Errorf("staticScopes requires bearerTokens or basicAuth users"))
+	}
+
+	issuers := make(map[string]struct{}, len(a.Issuers))
+	for i, issuer := range a.Issuers {
+		if issuer == nil {
+			err = errors.Join(err, fmt.Errorf("issuers[%d] must not be empty", i))
+			continue
+		}
+		if issuer.Issuer == "" {
+			err = errors.Join(err, fmt.Errorf("issuers[%d].issuer is required", i))
+		} else if _, ok := issuers[issuer.Issuer]; ok {
+			err = errors.Join(err, fmt.Errorf("issuers[%d].issuer '%s' is already configured", i, issuer.Issuer))
+		} else if slices.Contains(a.AuthorizationServers, issuer.Issuer) {
+			err = errors.Join(err, fmt.Errorf("issuers[%d].issuer '%s' is already configured in authorizationServers", i, issuer.Issuer))
+		}
+		issuers[issuer.Issuer] = struct{}{}
+
+		for j, audience := range issuer.Audiences {
+			if audience == "" {
+				err = errors.Join(err, fmt.Errorf("issuers[%d].audiences[%d] must not be empty", i, j))
+			}
+		}
+		for scope, mapped := range issuer.ScopeMapping {
+			if len(mapped) == 0 || slices.Contains(mapped, "") {
+				err = errors.Join(err, fmt.Errorf("issuers[%d].scopeMapping[%s] must contain non-empty scopes", i, scope))
+			}
+		}
+	}
+
+	if a.JWKSRefreshInterval != "" {
+		if d, parseErr := time.ParseDuration(a.JWKSRefreshInterval); parseErr != nil || d <= 0 {
+			err = errors.Join(err, fmt.Errorf("jwksRefreshInterval must be a positive duration, got '%s'", a.JWKSRefreshInterval))
+		}
+	}
+
+	return err
+}
+
+func (sc *SecurityConfig) Validate() error {
+	var err error = nil
+
+	for i, header := range sc.AllowedHeaders {
+		if header == "" || strings.ContainsAny(header, " \t\r\n:") {
+			err = errors.Join(err, fmt.Errorf("allowedHeaders[%d] is not a valid header name: %q", i, header))
+		}
+	}
+
+	for i, name := range sc.AllowedEnv {
+		if !isEnvVarName(name) {
+			err = errors.Join(err, fmt.Errorf("allowedEnv[%d] is not a valid environment variable name: %q", i, name))
+		}
+	}
+
+	for tool, tsc := range sc.Tools {
+		if tool == "" {
+			err = errors.Join(err, fmt.Errorf("tools must not have empty tool names"))
+		}
+		if tsc == nil {
+			continue
+		}
+		for i, name := range tsc.AllowedEnv {
+			if !isEnvVarName(name) {
+				err = errors.Join(err, fmt.Errorf("tools[%s].allowedEnv[%d] is not a valid environment variable name: %q", tool, i, name))
+			}
+		}
+	}
+
+	if sc.Network != nil {
+		if _, networkErr := netpolicy.New(sc.Network.policyConfig()); networkErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid network policy: %w", networkErr))
+		}
+	}
+
+	return err
+}
+
+func (p *PolicyConfig) Validate() error {
+	var err error = nil
+
+	switch p.Engine {
+	case PolicyEngineCEL:
+		if p.Expression == "" {
+			err = errors.Join(err, fmt.Errorf("expression is required for the %s engine", PolicyEngineCEL))
+		} else if _, celErr := policy.NewCELEngine(p.Expression); celErr != nil {
+			err = errors.Join(err, celErr)
+		}
+		if p.URL != "" || p.Timeout != "" {
+			err = errors.Join(err, fmt.Errorf("url and timeout can only be set for the %s engine", PolicyEngineOPA))
+		}
+	case PolicyEngineOPA:
+		if p.URL == "" {
+			err = errors.Join(err, fmt.Errorf("url is required for the %s engine", PolicyEngineOPA))
+		} else if u, parseErr := url.Parse(p.URL); parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			err = errors.Join(err, fmt.Errorf("url must be an http or https URL, received %s", p.URL))
+		}
+		if p.Timeout != "" {
+			if timeout, parseErr := time.ParseDuration(p.Timeout); parseErr != nil || timeout <= 0 {
+				err = errors.Join(err, fmt.Errorf("timeout must be a positive duration, received %s", p.Timeout))
+			}
 		}
+		if p.Expression != "" {
+			err = errors.Join(err, fmt.Errorf("expression can only be set for the %s engine", PolicyEngineCEL))
+		}
+	default:
+		err = errors.Join(err, fmt.Errorf("engine must be one of (%s, %s), received %s", PolicyEngineCEL, PolicyEngineOPA, p.Engine))
 	}
 
 	return err
 }
+
+// isEnvVarName reports whether name is a name of environment variable that templates can reference.
+func isEnvVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, ch := range name {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
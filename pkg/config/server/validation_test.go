@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/genmcp/gen-mcp/pkg/config"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,4 +25,1661 @@ func TestServerFileValidate(t *testing.T) {
 		err := serverConfig.Validate()
 		assert.NoError(t, err)
 	})
+
+	t.Run("invalid log redaction pattern should fail validation", func(t *testing.T) {
+		serverConfig := &MCPServerConfigFile{
+			SchemaVersion: config.SchemaVersion,
+			MCPServerConfig: MCPServerConfig{
+				Runtime: &ServerRuntime{
+					TransportProtocol: TransportProtocolStdio,
+					LoggingConfig: &logging.LoggingConfig{
+						Redaction: &logging.RedactionConfig{Patterns: []string{`acct-(\d+`}},
+					},
+				},
+			},
+		}
+		err := serverConfig.Validate()
+		assert.ErrorContains(t, err, "invalid loggingConfig: invalid redaction: invalid patterns[0]")
+	})
+
+	t.Run("invalid log level should fail validation", func(t *testing.T) {
+		serverConfig := &MCPServerConfigFile{
+			SchemaVersion: config.SchemaVersion,
+			MCPServerConfig: MCPServerConfig{
+				Runtime: &ServerRuntime{
+					TransportProtocol: TransportProtocolStdio,
+					LoggingConfig:     &logging.LoggingConfig{Level: "verbose"},
+				},
+			},
+		}
+		err := serverConfig.Validate()
+		assert.ErrorContains(t, err, "invalid loggingConfig: invalid level")
+	})
+}
+
+func TestServerRuntimeValidateListeners(t *testing.T) {
+	httpListener := func(name string, port int) *ListenerConfig {
+		return &ListenerConfig{
+			Name:                 name,
+			TransportProtocol:    TransportProtocolStreamableHttp,
+			StreamableHTTPConfig: &StreamableHTTPConfig{Port: port},
+		}
+	}
+
+	tt := []struct {
+		name          string
+		runtime       *ServerRuntime
+		expectedError string
+	}{
+		{
+			name: "stdio and http listeners",
+			runtime: &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{Port: 3000},
+				Listeners: []*ListenerConfig{
+					{Name: "local", TransportProtocol: TransportProtocolStdio},
+					httpListener("admin", 3001),
+				},
+			},
+		},
+		{
+			name: "missing listener name",
+			runtime: &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{Port: 3000},
+				Listeners:            []*ListenerConfig{httpListener("", 3001)},
+			},
+			expectedError: "listeners[0].name is required",
+		},
+		{
+			name: "duplicate listener name",
+			runtime: &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{Port: 3000},
+				Listeners:            []*ListenerConfig{httpListener("admin", 3001), httpListener("admin", 3002)},
+			},
+			expectedError: "duplicate listener name 'admin'",
+		},
+		{
+			name: "listener uses the port of the runtime",
+			runtime: &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{Port: 3000},
+				Listeners:            []*ListenerConfig{httpListener("admin", 3000)},
+			},
+			expectedError: "listener 'admin' uses port 3000, which is already used by the runtime",
+		},
+		{
+			name: "two stdio transports",
+			runtime: &ServerRuntime{
+				TransportProtocol: TransportProtocolStdio,
+				Listeners: []*ListenerConfig{
+					{Name: "local", TransportProtocol: TransportProtocolStdio},
+				},
+			},
+			expectedError: "at most one transport may use stdio, found 2",
+		},
+		{
+			name: "listener without http config",
+			runtime: &ServerRuntime{
+				TransportProtocol: TransportProtocolStdio,
+				Listeners: []*ListenerConfig{
+					{Name: "admin", TransportProtocol: TransportProtocolStreamableHttp},
+				},
+			},
+			expectedError: "invalid listener 'admin': transportProtocol is streamablehttp, but streamableHttpConfig is not set",
+		},
+		{
+			name: "admin API uses the port of a listener",
+			runtime: &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &StreamableHTTPConfig{Port: 3000},
+				Listeners:            []*ListenerConfig{httpListener("internal", 3001)},
+				Admin:                &AdminConfig{Port: 3001, BearerTokens: []string{"s3cr3t"}},
+			},
+			expectedError: "the admin API uses port 3001, which is already used by listener 'internal'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.runtime.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		auth          *AuthConfig
+		expectedError string
+	}{
+		{
+			name: "static credentials with scopes",
+			auth: &AuthConfig{
+				BearerTokens: []string{"secret"},
+				BasicAuth:    &BasicAuthConfig{Users: map[string]string{"admin": "password"}},
+				StaticScopes: []string{"tools:read"},
+			},
+		},
+		{
+			name:          "empty bearer token",
+			auth:          &AuthConfig{BearerTokens: []string{"secret", ""}},
+			expectedError: "bearerTokens[1] must not be empty",
+		},
+		{
+			name:          "user name with colon",
+			auth:          &AuthConfig{BasicAuth: &BasicAuthConfig{Users: map[string]string{"ad:min": "password"}}},
+			expectedError: "invalid basic auth user name 'ad:min'",
+		},
+		{
+			name:          "empty password",
+			auth:          &AuthConfig{BasicAuth: &BasicAuthConfig{Users: map[string]string{"admin": ""}}},
+			expectedError: "password of basic auth user 'admin' must not be empty",
+		},
+		{
+			name:          "static scopes without static credentials",
+			auth:          &AuthConfig{JWKSURI: "https://auth.example.com/jwks", StaticScopes: []string{"tools:read"}},
+			expectedError: "staticScopes requires bearerTokens or basicAuth users",
+		},
+		{
+			name: "issuers",
+			auth: &AuthConfig{
+				AuthorizationServers: []string{"https://auth.example.com"},
+				Issuers: []*IssuerConfig{
+					{Issuer: "https://login.example.org", Audiences: []string{"genmcp"}, ScopeMapping: map[string][]string{"admin": {"tools:write"}}},
+					{Issuer: "https://accounts.example.net", JWKSURI: "https://accounts.example.net/keys", ScopeClaim: "scp"},
+				},
+				JWKSRefreshInterval: "5m",
+			},
+		},
+		{
+			name:          "issuer without URL",
+			auth:          &AuthConfig{Issuers: []*IssuerConfig{{JWKSURI: "https://accounts.example.net/keys"}}},
+			expectedError: "issuers[0].issuer is required",
+		},
+		{
+			name:          "duplicate issuer",
+			auth:          &AuthConfig{Issuers: []*IssuerConfig{{Issuer: "https://login.example.org"}, {Issuer: "https://login.example.org"}}},
+			expectedError: "issuers[1].issuer 'https://login.example.org' is already configured",
+		},
+		{
+			name: "issuer of the authorization servers",
+			auth: &AuthConfig{
+				AuthorizationServers: []string{"https://auth.example.com"},
+				Issuers:              []*IssuerConfig{{Issuer: "https://auth.example.com"}},
+			},
+			expectedError: "issuers[0].issuer 'https://auth.example.com' is already configured in authorizationServers",
+		},
+		{
+			name:          "empty audience",
+			auth:          &AuthConfig{Issuers: []*IssuerConfig{{Issuer: "https://login.example.org", Audiences: []string{""}}}},
+			expectedError: "issuers[0].audiences[0] must not be empty",
+		},
+		{
+			name:          "scope mapped to no scopes",
+			auth:          &AuthConfig{Issuers: []*IssuerConfig{{Issuer: "https://login.example.org", ScopeMapping: map[string][]string{"admin": {}}}}},
+			expectedError: "issuers[0].scopeMapping[admin] must contain non-empty scopes",
+		},
+		{
+			name:          "invalid JWKS refresh interval",
+			auth:          &AuthConfig{JWKSURI: "https://auth.example.com/jwks", JWKSRefreshInterval: "0s"},
+			expectedError: "jwksRefreshInterval must be a positive duration, got '0s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.auth.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateTransportClientAuth(t *testing.T) {
+	tt := []struct {
+		name          string
+		httpConfig    *StreamableHTTPConfig
+		expectedError string
+	}{
+		{
+			name: "client auth with scopes",
+			httpConfig: &StreamableHTTPConfig{
+				Port: 8443,
+				TLS: &TLSConfig{
+					CertFile: "/path/to/server.crt",
+					KeyFile:  "/path/to/server.key",
+					ClientAuth: &ClientAuthConfig{
+						CACertFiles: []string{"/path/to/client-ca.crt"},
+						AllowedSANs: []string{"client.example.com"},
+						Scopes:      []string{"tools:read"},
+					},
+				},
+			},
+		},
+		{
+			name: "client auth without CA certificates",
+			httpConfig: &StreamableHTTPConfig{
+				Port: 8443,
+				TLS:  &TLSConfig{ClientAuth: &ClientAuthConfig{}},
+			},
+			expectedError: "clientAuth.caCertFiles must contain at least one CA certificate file",
+		},
+		{
+			name: "empty allowed SAN",
+			httpConfig: &StreamableHTTPConfig{
+				Port: 8443,
+				TLS: &TLSConfig{ClientAuth: &ClientAuthConfig{
+					CACertFiles: []string{"/path/to/client-ca.crt"},
+					AllowedSANs: []string{""},
+				}},
+			},
+			expectedError: "clientAuth.allowedSans[0] must not be empty",
+		},
+		{
+			name: "client auth scopes with auth",
+			httpConfig: &StreamableHTTPConfig{
+				Port: 8443,
+				Auth: &AuthConfig{BearerTokens: []string{"secret"}},
+				TLS: &TLSConfig{ClientAuth: &ClientAuthConfig{
+					CACertFiles: []string{"/path/to/client-ca.crt"},
+					Scopes:      []string{"tools:read"},
+				}},
+			},
+			expectedError: "streamableHttpConfig.tls.clientAuth.scopes cannot be used with streamableHttpConfig.auth",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateTransport(TransportProtocolStreamableHttp, tc.httpConfig)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestLimitsConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		limits        *LimitsConfig
+		expectedError string
+	}{
+		{
+			name: "valid limits",
+			limits: &LimitsConfig{
+				MaxResponseBytes:   1 << 20,
+				MaxArgumentBytes:   64 << 10,
+				TruncationStrategy: TruncationStrategyTail,
+			},
+		},
+		{
+			name:   "no limits",
+			limits: &LimitsConfig{},
+		},
+		{
+			name:          "negative max response bytes",
+			limits:        &LimitsConfig{MaxResponseBytes: -1},
+			expectedError: "maxResponseBytes must not be negative",
+		},
+		{
+			name:          "negative max argument bytes",
+			limits:        &LimitsConfig{MaxArgumentBytes: -1},
+			expectedError: "maxArgumentBytes must not be negative",
+		},
+		{
+			name:          "unknown truncation strategy",
+			limits:        &LimitsConfig{TruncationStrategy: "middle"},
+			expectedError: "truncationStrategy must be one of (head, tail, summary), received middle",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.limits.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestHTTPClientConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *HTTPClientConfig
+		expectedError string
+	}{
+		{
+			name: "valid pool",
+			config: &HTTPClientConfig{
+				MaxIdleConns:        200,
+				MaxIdleConnsPerHost: 50,
+				MaxConnsPerHost:     100,
+				IdleConnTimeout:     "2m",
+			},
+		},
+		{
+			name:   "defaults",
+			config: &HTTPClientConfig{},
+		},
+		{
+			name:          "negative max idle conns",
+			config:        &HTTPClientConfig{MaxIdleConns: -1},
+			expectedError: "maxIdleConns must not be negative",
+		},
+		{
+			name:          "negative max idle conns per host",
+			config:        &HTTPClientConfig{MaxIdleConnsPerHost: -1},
+			expectedError: "maxIdleConnsPerHost must not be negative",
+		},
+		{
+			name:          "negative max conns per host",
+			config:        &HTTPClientConfig{MaxConnsPerHost: -1},
+			expectedError: "maxConnsPerHost must not be negative",
+		},
+		{
+			name:          "invalid idle conn timeout",
+			config:        &HTTPClientConfig{IdleConnTimeout: "soon"},
+			expectedError: "idleConnTimeout must be a positive duration, received soon",
+		},
+		{
+			name:          "zero idle conn timeout",
+			config:        &HTTPClientConfig{IdleConnTimeout: "0s"},
+			expectedError: "idleConnTimeout must be a positive duration, received 0s",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestConcurrencyConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ConcurrencyConfig
+		expectedError string
+	}{
+		{
+			name: "valid concurrency",
+			config: &ConcurrencyConfig{
+				MaxInFlight:  64,
+				MaxQueued:    128,
+				QueueTimeout: "10s",
+			},
+		},
+		{
+			name:   "defaults",
+			config: &ConcurrencyConfig{},
+		},
+		{
+			name:          "negative max in flight",
+			config:        &ConcurrencyConfig{MaxInFlight: -1},
+			expectedError: "maxInFlight must not be negative",
+		},
+		{
+			name:          "negative max queued",
+			config:        &ConcurrencyConfig{MaxQueued: -1},
+			expectedError: "maxQueued must not be negative",
+		},
+		{
+			name:          "invalid queue timeout",
+			config:        &ConcurrencyConfig{QueueTimeout: "later"},
+			expectedError: "queueTimeout must be a positive duration, received later",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestAdminConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		admin         *AdminConfig
+		expectedError string
+	}{
+		{
+			name:  "valid admin",
+			admin: &AdminConfig{Port: 9090, BasePath: "/admin", BearerTokens: []string{"s3cr3t"}},
+		},
+		{
+			name:  "valid address",
+			admin: &AdminConfig{Address: "::1", Port: 9090, BearerTokens: []string{"s3cr3t"}},
+		},
+		{
+			name:          "address with a port",
+			admin:         &AdminConfig{Address: "127.0.0.1:9090", Port: 9090, BearerTokens: []string{"s3cr3t"}},
+			expectedError: "address must not include a port, received 127.0.0.1:9090",
+		},
+		{
+			name:          "missing port",
+			admin:         &AdminConfig{BearerTokens: []string{"s3cr3t"}},
+			expectedError: "port must be greater than 0",
+		},
+		{
+			name:          "relative base path",
+			admin:         &AdminConfig{Port: 9090, BasePath: "admin", BearerTokens: []string{"s3cr3t"}},
+			expectedError: "basePath must start with '/'",
+		},
+		{
+			name:          "no bearer tokens",
+			admin:         &AdminConfig{Port: 9090},
+			expectedError: "at least one bearer token is required",
+		},
+		{
+			name:          "empty bearer token",
+			admin:         &AdminConfig{Port: 9090, BearerTokens: []string{""}},
+			expectedError: "bearerTokens[0] must not be empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.admin.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestServerRuntimeValidateAdminAddress(t *testing.T) {
+	httpConfig := func(tls *TLSConfig) *StreamableHTTPConfig {
+		return &StreamableHTTPConfig{Port: 8080, BasePath: DefaultBasePath, TLS: tls}
+	}
+	serverTLS := &TLSConfig{CertFile: "/certs/server.crt", KeyFile: "/certs/server.key"}
+
+	tt := []struct {
+		name          string
+		address       string
+		httpConfig    *StreamableHTTPConfig
+		expectedError string
+	}{
+		{
+			name:       "default loopback address",
+			httpConfig: httpConfig(nil),
+		},
+		{
+			name:       "localhost",
+			address:    "localhost",
+			httpConfig: httpConfig(nil),
+		},
+		{
+			name:       "public address with tls",
+			address:    "0.0.0.0",
+			httpConfig: httpConfig(serverTLS),
+		},
+		{
+			name:          "public address without tls",
+			address:       "0.0.0.0",
+			httpConfig:    httpConfig(nil),
+			expectedError: "invalid admin: address 0.0.0.0 is not a loopback address, which requires the tls of streamableHttpConfig",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			runtime := &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: tc.httpConfig,
+				Admin:                &AdminConfig{Address: tc.address, Port: 9090, BearerTokens: []string{"s3cr3t"}},
+			}
+			err := runtime.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestAuditConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		audit         *AuditConfig
+		expectedError string
+	}{
+		{
+			name:  "valid file sink",
+			audit: &AuditConfig{Sink: AuditSinkFile, Path: "/var/log/genmcp/audit.jsonl"},
+		},
+		{
+			name:  "valid syslog sink",
+			audit: &AuditConfig{Sink: AuditSinkSyslog, SyslogNetwork: "udp", SyslogAddress: "logs.example.com:514"},
+		},
+		{
+			name:  "valid http sink with redacted arguments",
+			audit: &AuditConfig{Sink: AuditSinkHTTP, URL: "https://logs.example.com/audit", Arguments: "redacted", RedactFields: []string{"token"}},
+		},
+		{
+			name:          "missing sink",
+			audit:         &AuditConfig{},
+			expectedError: "sink must be one of (file, syslog, http), received ",
+		},
+		{
+			name:          "file sink without path",
+			audit:         &AuditConfig{Sink: AuditSinkFile},
+			expectedError: "path is required for the file sink",
+		},
+		{
+			name:          "syslog network without address",
+			audit:         &AuditConfig{Sink: AuditSinkSyslog, SyslogNetwork: "tcp"},
+			expectedError: "syslogAddress is required when syslogNetwork is set",
+		},
+		{
+			name:          "http sink without url",
+			audit:         &AuditConfig{Sink: AuditSinkHTTP},
+			expectedError: "url is required for the http sink",
+		},
+		{
+			name:          "http sink with invalid url",
+			audit:         &AuditConfig{Sink: AuditSinkHTTP, URL: "logs.example.com/audit"},
+			expectedError: "url must be an http or https URL, received logs.example.com/audit",
+		},
+		{
+			name:          "invalid arguments",
+			audit:         &AuditConfig{Sink: AuditSinkFile, Path: "audit.jsonl", Arguments: "full"},
+			expectedError: "arguments must be one of (hash, redacted), received full",
+		},
+		{
+			name:          "redact fields with hashed arguments",
+			audit:         &AuditConfig{Sink: AuditSinkFile, Path: "audit.jsonl", RedactFields: []string{"token"}},
+			expectedError: "redactFields can only be set when arguments is redacted",
+		},
+		{
+			name:  "records dropped when the queue is full",
+			audit: &AuditConfig{Sink: AuditSinkFile, Path: "audit.jsonl", OnQueueFull: "drop"},
+		},
+		{
+			name:          "invalid onQueueFull",
+			audit:         &AuditConfig{Sink: AuditSinkFile, Path: "audit.jsonl", OnQueueFull: "block"},
+			expectedError: "onQueueFull must be one of (fail, drop), received block",
+		},
+		{
+			name:          "invalid queue timeout",
+			audit:         &AuditConfig{Sink: AuditSinkFile, Path: "audit.jsonl", QueueTimeout: "-1s"},
+			expectedError: "queueTimeout must be a positive duration, received -1s",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.audit.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestOpenAPIRefConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		ref           *OpenAPIRefConfig
+		expectedError string
+	}{
+		{
+			name: "valid ref",
+			ref:  &OpenAPIRefConfig{Source: "https://petstore3.swagger.io/api/v3/openapi.json", Tags: []string{"pet"}, RefreshInterval: "10m"},
+		},
+		{
+			name:          "missing source",
+			ref:           &OpenAPIRefConfig{Host: "https://petstore3.swagger.io/api/v3"},
+			expectedError: "source is required",
+		},
+		{
+			name:          "invalid refresh interval",
+			ref:           &OpenAPIRefConfig{Source: "openapi.json", RefreshInterval: "often"},
+			expectedError: "refreshInterval must be a positive duration, got 'often'",
+		},
+		{
+			name:          "negative refresh interval",
+			ref:           &OpenAPIRefConfig{Source: "openapi.json", RefreshInterval: "-1m"},
+			expectedError: "refreshInterval must be a positive duration, got '-1m'",
+		},
+		{
+			name:          "invalid conflict policy",
+			ref:           &OpenAPIRefConfig{Source: "openapi.json", Prefix: "petstore_", OnConflict: "merge"},
+			expectedError: "onConflict must be one of (skip, error, rename, override), received merge",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.ref.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateUpstreams(t *testing.T) {
+	tt := []struct {
+		name          string
+		upstreams     []*UpstreamConfig
+		expectedError string
+	}{
+		{
+			name: "valid upstreams",
+			upstreams: []*UpstreamConfig{
+				{Name: "filesystem", Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem", "/data"}},
+				{Name: "github", URL: "https://api.githubcopilot.com/mcp/", Tools: []string{"create_issue"}, Prefix: "github_", OnConflict: ConflictPolicyRename},
+			},
+		},
+		{
+			name:          "missing name",
+			upstreams:     []*UpstreamConfig{{Command: "npx"}},
+			expectedError: "upstreams[0] is invalid: name is required",
+		},
+		{
+			name:          "missing command and url",
+			upstreams:     []*UpstreamConfig{{Name: "github"}},
+			expectedError: "upstreams[0] is invalid: one of command or url is required",
+		},
+		{
+			name: "duplicate name",
+			upstreams: []*UpstreamConfig{
+				{Name: "github", URL: "https://api.githubcopilot.com/mcp/"},
+				{Name: "github", Command: "github-mcp-server"},
+			},
+			expectedError: "upstreams[1] is invalid: duplicate name 'github'",
+		},
+		{
+			name:          "empty tool name",
+			upstreams:     []*UpstreamConfig{{Name: "github", URL: "https://api.githubcopilot.com/mcp/", Tools: []string{""}}},
+			expectedError: "tools must not have empty names",
+		},
+		{
+			name:          "invalid conflict policy",
+			upstreams:     []*UpstreamConfig{{Name: "github", URL: "https://api.githubcopilot.com/mcp/", OnConflict: "ignore"}},
+			expectedError: "upstreams[0] is invalid: onConflict must be one of (skip, error, rename, override), received ignore",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateUpstreams(tc.upstreams)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSessionsConfigValidate(t *testing.T) {
+	stateful := false
+
+	tt := []struct {
+		name          string
+		httpConfig    *StreamableHTTPConfig
+		expectedError string
+	}{
+		{
+			name: "memory store",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Store: SessionStoreMemory, TTL: "10m", MaxSessions: 1000},
+			},
+		},
+		{
+			name: "redis store",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions: &SessionsConfig{
+					Store: SessionStoreRedis,
+					Redis: &RedisConfig{Address: "redis:6379", Username: "genmcp", Password: "s3cr3t", DB: 1},
+				},
+			},
+		},
+		{
+			name: "stateless server",
+			httpConfig: &StreamableHTTPConfig{
+				Port:     3000,
+				Sessions: &SessionsConfig{},
+			},
+			expectedError: "streamableHttpConfig.sessions can only be set when streamableHttpConfig.stateless is false",
+		},
+		{
+			name: "unknown store",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Store: "etcd"},
+			},
+			expectedError: "store must be one of (memory, redis), received etcd",
+		},
+		{
+			name: "invalid ttl",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{TTL: "0s"},
+			},
+			expectedError: "ttl must be a positive duration, received 0s",
+		},
+		{
+			name: "redis store without redis",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Store: SessionStoreRedis},
+			},
+			expectedError: "redis is required for the redis store",
+		},
+		{
+			name: "max sessions of a redis store",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions: &SessionsConfig{
+					Store:       SessionStoreRedis,
+					MaxSessions: 10,
+					Redis:       &RedisConfig{Address: "redis:6379"},
+				},
+			},
+			expectedError: "maxSessions can only be set for the memory store",
+		},
+		{
+			name: "redis of a memory store",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Redis: &RedisConfig{Address: "redis:6379"}},
+			},
+			expectedError: "redis can only be set for the redis store",
+		},
+		{
+			name: "redis address without port",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Store: SessionStoreRedis, Redis: &RedisConfig{Address: "redis"}},
+			},
+			expectedError: "invalid redis: address must be host:port, received redis",
+		},
+		{
+			name: "redis username without password",
+			httpConfig: &StreamableHTTPConfig{
+				Port:      3000,
+				Stateless: &stateful,
+				Sessions:  &SessionsConfig{Store: SessionStoreRedis, Redis: &RedisConfig{Address: "redis:6379", Username: "genmcp"}},
+			},
+			expectedError: "password is required when username is set",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			runtime := &ServerRuntime{
+				TransportProtocol:    TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: tc.httpConfig,
+			}
+			err := runtime.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSecretsConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		secrets       *SecretsConfig
+		expectedError string
+	}{
+		{
+			name: "valid providers",
+			secrets: &SecretsConfig{Providers: []*SecretProviderConfig{
+				{Type: SecretProviderEnv, Prefix: "SECRET_"},
+				{Type: SecretProviderFile, Path: "/etc/genmcp/secrets.env"},
+				{Type: SecretProviderDirectory, Path: "/var/run/secrets/genmcp"},
+			}},
+		},
+		{
+			name:          "no providers",
+			secrets:       &SecretsConfig{},
+			expectedError: "at least one provider is required",
+		},
+		{
+			name:          "unknown type",
+			secrets:       &SecretsConfig{Providers: []*SecretProviderConfig{{Type: "keyring"}}},
+			expectedError: "providers[0]: type must be one of (env, file, directory, vault), received keyring",
+		},
+		{
+			name:          "missing path",
+			secrets:       &SecretsConfig{Providers: []*SecretProviderConfig{{Type: SecretProviderDirectory}}},
+			expectedError: "providers[0]: path is required for directory providers",
+		},
+		{
+			name:          "path of an env provider",
+			secrets:       &SecretsConfig{Providers: []*SecretProviderConfig{{Type: SecretProviderEnv, Path: "/etc"}}},
+			expectedError: "providers[0]: path cannot be set for env providers",
+		},
+		{
+			name: "valid vault provider",
+			secrets: &SecretsConfig{Providers: []*SecretProviderConfig{{
+				Type: SecretProviderVault,
+				Vault: &VaultConfig{
+					Address:  "https://vault.example.com:8200",
+					Path:     "genmcp/prod",
+					Auth:     &VaultAuthConfig{Method: "kubernetes", Role: "genmcp"},
+					CacheTTL: "1m",
+				},
+			}}},
+		},
+		{
+			name:          "missing vault config",
+			secrets:       &SecretsConfig{Providers: []*SecretProviderConfig{{Type: SecretProviderVault}}},
+			expectedError: "providers[0]: vault is required for vault providers",
+		},
+		{
+			name: "vault config of an env provider",
+			secrets: &SecretsConfig{Providers: []*SecretProviderConfig{{
+				Type:  SecretProviderEnv,
+				Vault: &VaultConfig{},
+			}}},
+			expectedError: "providers[0]: vault can only be set for vault providers",
+		},
+		{
+			name: "prefix of a file provider",
+			secrets: &SecretsConfig{Providers: []*SecretProviderConfig{
+				{Type: SecretProviderEnv},
+				{Type: SecretProviderFile, Path: "/etc/genmcp/secrets.env", Prefix: "SECRET_"},
+			}},
+			expectedError: "providers[1]: prefix can only be set for env providers",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.secrets.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestVaultConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		vault         *VaultConfig
+		expectedError string
+	}{
+		{
+			name: "valid token auth fetched on startup",
+			vault: &VaultConfig{
+				Address:   "http://127.0.0.1:8200",
+				MountPath: "kv",
+				Path:      "genmcp",
+				KVVersion: 1,
+				Auth:      &VaultAuthConfig{Method: "token", TokenFile: "/etc/vault/token"},
+				Fetch:     VaultFetchStartup,
+			},
+		},
+		{
+			name: "valid approle auth",
+			vault: &VaultConfig{
+				Address: "https://vault.example.com",
+				Path:    "genmcp",
+				Auth:    &VaultAuthConfig{Method: "approle", RoleID: "genmcp-role"},
+			},
+		},
+		{
+			name:          "missing address, path and auth",
+			vault:         &VaultConfig{},
+			expectedError: "address is required\npath is required\nauth is required",
+		},
+		{
+			name:          "invalid address",
+			vault:         &VaultConfig{Address: "vault:8200", Path: "genmcp", Auth: &VaultAuthConfig{Method: "token"}},
+			expectedError: "address must be an http or https URL, received vault:8200",
+		},
+		{
+			name:          "invalid kv version",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", KVVersion: 3, Auth: &VaultAuthConfig{Method: "token"}},
+			expectedError: "kvVersion must be 1 or 2, received 3",
+		},
+		{
+			name:          "invalid fetch",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Fetch: "always", Auth: &VaultAuthConfig{Method: "token"}},
+			expectedError: "fetch must be one of (startup, invocation), received always",
+		},
+		{
+			name:          "invalid cache ttl",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", CacheTTL: "-1m", Auth: &VaultAuthConfig{Method: "token"}},
+			expectedError: "cacheTtl must be a non-negative duration, received -1m",
+		},
+		{
+			name:          "cache ttl fetched on startup",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Fetch: VaultFetchStartup, CacheTTL: "1m", Auth: &VaultAuthConfig{Method: "token"}},
+			expectedError: "cacheTtl cannot be set when fetch is startup",
+		},
+		{
+			name:          "unknown auth method",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Auth: &VaultAuthConfig{Method: "ldap"}},
+			expectedError: "invalid auth: method must be one of (token, kubernetes, approle), received ldap",
+		},
+		{
+			name:          "kubernetes auth without role",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Auth: &VaultAuthConfig{Method: "kubernetes"}},
+			expectedError: "invalid auth: role is required for the kubernetes method",
+		},
+		{
+			name:          "approle auth without role id",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Auth: &VaultAuthConfig{Method: "approle", SecretIDFile: "/etc/vault/secret-id"}},
+			expectedError: "invalid auth: roleId is required for the approle method",
+		},
+		{
+			name:          "token auth with a role",
+			vault:         &VaultConfig{Address: "https://vault", Path: "genmcp", Auth: &VaultAuthConfig{Method: "token", Role: "genmcp"}},
+			expectedError: "invalid auth: only tokenFile can be set for the token method",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.vault.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRecordingConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		recording     *RecordingConfig
+		expectedError string
+	}{
+		{
+			name:      "valid record mode",
+			recording: &RecordingConfig{Mode: "record", Dir: "fixtures"},
+		},
+		{
+			name:      "valid replay mode",
+			recording: &RecordingConfig{Mode: "replay", Dir: "fixtures"},
+		},
+		{
+			name:          "invalid mode",
+			recording:     &RecordingConfig{Mode: "mock", Dir: "fixtures"},
+			expectedError: "mode must be one of (record, replay), received mock",
+		},
+		{
+			name:          "missing dir",
+			recording:     &RecordingConfig{Mode: "record"},
+			expectedError: "dir is required",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.recording.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSecurityConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		security      *SecurityConfig
+		expectedError string
+	}{
+		{
+			name:     "valid allowed headers",
+			security: &SecurityConfig{AllowedHeaders: []string{"X-Tenant-ID", "accept-language"}},
+		},
+		{
+			name:     "no allowed headers",
+			security: &SecurityConfig{AllowedHeaders: []string{}},
+		},
+		{
+			name:          "empty header name",
+			security:      &SecurityConfig{AllowedHeaders: []string{"X-Tenant-ID", ""}},
+			expectedError: `allowedHeaders[1] is not a valid header name: ""`,
+		},
+		{
+			name:          "header name with a colon",
+			security:      &SecurityConfig{AllowedHeaders: []string{"X-Tenant-ID: acme"}},
+			expectedError: `allowedHeaders[0] is not a valid header name: "X-Tenant-ID: acme"`,
+		},
+		{
+			name: "valid allowed environment variables",
+			security: &SecurityConfig{
+				AllowedEnv: []string{"API_URL"},
+				Tools:      map[string]*ToolSecurityConfig{"get_repo": {AllowedEnv: []string{"GITHUB_TOKEN"}}},
+			},
+		},
+		{
+			name:          "environment variable name with an equal sign",
+			security:      &SecurityConfig{AllowedEnv: []string{"API_URL=https://example.com"}},
+			expectedError: `allowedEnv[0] is not a valid environment variable name: "API_URL=https://example.com"`,
+		},
+		{
+			name:          "empty environment variable name of a tool",
+			security:      &SecurityConfig{Tools: map[string]*ToolSecurityConfig{"get_repo": {AllowedEnv: []string{""}}}},
+			expectedError: `tools[get_repo].allowedEnv[0] is not a valid environment variable name: ""`,
+		},
+		{
+			name:          "empty tool name",
+			security:      &SecurityConfig{Tools: map[string]*ToolSecurityConfig{"": {}}},
+			expectedError: "tools must not have empty tool names",
+		},
+		{
+			name: "valid network policy",
+			security: &SecurityConfig{Network: &NetworkPolicyConfig{
+				AllowedHosts: []string{"*.example.com", "10.0.0.0/8"},
+				DeniedHosts:  []string{"admin.example.com"},
+			}},
+		},
+		{
+			name:          "network policy with invalid CIDR",
+			security:      &SecurityConfig{Network: &NetworkPolicyConfig{DeniedHosts: []string{"10.0.0.0/40"}}},
+			expectedError: `invalid network policy: invalid denied hosts: invalid CIDR "10.0.0.0/40"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.security.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestPolicyConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		policy        *PolicyConfig
+		expectedError string
+	}{
+		{
+			name:   "valid cel policy",
+			policy: &PolicyConfig{Engine: PolicyEngineCEL, Expression: `tool != "delete_namespace"`},
+		},
+		{
+			name:   "valid opa policy",
+			policy: &PolicyConfig{Engine: PolicyEngineOPA, URL: "http://localhost:8181/v1/data/genmcp/authz", Timeout: "2s"},
+		},
+		{
+			name:          "invalid engine",
+			policy:        &PolicyConfig{Engine: "rego"},
+			expectedError: "engine must be one of (cel, opa), received rego",
+		},
+		{
+			name:          "missing expression",
+			policy:        &PolicyConfig{Engine: PolicyEngineCEL},
+			expectedError: "expression is required for the cel engine",
+		},
+		{
+			name:          "invalid expression",
+			policy:        &PolicyConfig{Engine: PolicyEngineCEL, Expression: `tool ==`},
+			expectedError: "invalid CEL expression",
+		},
+		{
+			name:          "url of cel policy",
+			policy:        &PolicyConfig{Engine: PolicyEngineCEL, Expression: "true", URL: "http://localhost:8181"},
+			expectedError: "url and timeout can only be set for the opa engine",
+		},
+		{
+			name:          "missing url",
+			policy:        &PolicyConfig{Engine: PolicyEngineOPA},
+			expectedError: "url is required for the opa engine",
+		},
+		{
+			name:          "invalid url",
+			policy:        &PolicyConfig{Engine: PolicyEngineOPA, URL: "localhost:8181"},
+			expectedError: "url must be an http or https URL, received localhost:8181",
+		},
+		{
+			name:          "invalid timeout",
+			policy:        &PolicyConfig{Engine: PolicyEngineOPA, URL: "http://localhost:8181", Timeout: "-1s"},
+			expectedError: "timeout must be a positive duration, received -1s",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestQuotasConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		quotas        *QuotasConfig
+		expectedError string
+	}{
+		{
+			name: "valid quotas",
+			quotas: &QuotasConfig{
+				PerHour: 100,
+				PerDay:  1000,
+				Clients: map[string]*QuotaLimits{"batch-agent": {PerDay: 10000}},
+				Tools:   map[string]*QuotaLimits{"send_email": {PerHour: 5}},
+			},
+		},
+		{
+			name:          "negative quota",
+			quotas:        &QuotasConfig{PerHour: -1},
+			expectedError: "perHour must not be negative",
+		},
+		{
+			name:          "negative quota of a client",
+			quotas:        &QuotasConfig{Clients: map[string]*QuotaLimits{"batch-agent": {PerDay: -1}}},
+			expectedError: "clients[batch-agent] is invalid: perDay must not be negative",
+		},
+		{
+			name:          "empty quota of a tool",
+			quotas:        &QuotasConfig{Tools: map[string]*QuotaLimits{"send_email": nil}},
+			expectedError: "tools[send_email] must not be empty",
+		},
+		{
+			name:          "empty client name",
+			quotas:        &QuotasConfig{Clients: map[string]*QuotaLimits{"": {PerDay: 1}}},
+			expectedError: "clients must not have empty client names",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.quotas.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestTenantsConfigValidate(t *testing.T) {
+	acme := &TenantConfig{
+		Variables: map[string]string{"baseUrl": "https://acme.api.example.com"},
+		Secrets:   map[string]string{"API_KEY": "ACME_API_KEY"},
+		Quotas:    &QuotaLimits{PerDay: 1000},
+	}
+
+	tt := []struct {
+		name          string
+		tenants       *TenantsConfig
+		expectedError string
+	}{
+		{
+			name:    "valid tenants",
+			tenants: &TenantsConfig{Claim: "org.id", Default: "acme", Tenants: map[string]*TenantConfig{"acme": acme, "globex": {}}},
+		},
+		{
+			name:          "claim and header",
+			tenants:       &TenantsConfig{Claim: "tenant_id", Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": acme}},
+			expectedError: "exactly one of claim and header must be set",
+		},
+		{
+			name:          "neither claim nor header",
+			tenants:       &TenantsConfig{Tenants: map[string]*TenantConfig{"acme": acme}},
+			expectedError: "exactly one of claim and header must be set",
+		},
+		{
+			name:          "no tenants",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID"},
+			expectedError: "tenants must not be empty",
+		},
+		{
+			name:          "undefined default tenant",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Default: "globex", Tenants: map[string]*TenantConfig{"acme": acme}},
+			expectedError: "default tenant 'globex' is not defined",
+		},
+		{
+			name:          "empty tenant",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": nil}},
+			expectedError: "tenants[acme] must not be empty",
+		},
+		{
+			name:          "reserved variable name",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": {Variables: map[string]string{"id": "acme"}}}},
+			expectedError: "tenants[acme] is invalid: variable name 'id' is reserved for the tenant ID",
+		},
+		{
+			name:          "invalid variable name",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": {Variables: map[string]string{"base.url": "x"}}}},
+			expectedError: "tenants[acme] is invalid: invalid variable name 'base.url'",
+		},
+		{
+			name:          "empty secret name",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": {Secrets: map[string]string{"API_KEY": ""}}}},
+			expectedError: "tenants[acme] is invalid: secrets must map non-empty secret names",
+		},
+		{
+			name:          "negative quota",
+			tenants:       &TenantsConfig{Header: "X-Tenant-ID", Tenants: map[string]*TenantConfig{"acme": {Quotas: &QuotaLimits{PerHour: -1}}}},
+			expectedError: "tenants[acme] is invalid: invalid quotas: perHour must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tenants.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateSchedules(t *testing.T) {
+	tt := []struct {
+		name          string
+		schedules     []*ScheduleConfig
+		expectedError string
+	}{
+		{
+			name: "valid schedules",
+			schedules: []*ScheduleConfig{
+				{Name: "daily-report", Tool: "generate_report", Schedule: "CRON_TZ=UTC 0 6 * * 1-5", Arguments: map[string]any{"format": "pdf"}, Timeout: "5m"},
+				{Name: "health.check", Tool: "check_backends", Schedule: "@every 15m", RunOnStart: true},
+			},
+		},
+		{
+			name:          "empty schedule",
+			schedules:     []*ScheduleConfig{nil},
+			expectedError: "schedules[0] must not be empty",
+		},
+		{
+			name:          "missing name",
+			schedules:     []*ScheduleConfig{{Tool: "generate_report", Schedule: "@daily"}},
+			expectedError: "schedules[0] is invalid: name is required",
+		},
+		{
+			name:          "invalid name",
+			schedules:     []*ScheduleConfig{{Name: "daily report", Tool: "generate_report", Schedule: "@daily"}},
+			expectedError: "name 'daily report' must only contain letters, digits, '-', '_' and '.'",
+		},
+		{
+			name: "duplicate name",
+			schedules: []*ScheduleConfig{
+				{Name: "report", Tool: "generate_report", Schedule: "@daily"},
+				{Name: "report", Tool: "generate_report", Schedule: "@hourly"},
+			},
+			expectedError: "schedules[1] is invalid: duplicate name 'report'",
+		},
+		{
+			name:          "missing tool",
+			schedules:     []*ScheduleConfig{{Name: "report", Schedule: "@daily"}},
+			expectedError: "tool is required",
+		},
+		{
+			name:          "missing schedule",
+			schedules:     []*ScheduleConfig{{Name: "report", Tool: "generate_report"}},
+			expectedError: "schedule is required",
+		},
+		{
+			name:          "invalid schedule",
+			schedules:     []*ScheduleConfig{{Name: "report", Tool: "generate_report", Schedule: "every day"}},
+			expectedError: "invalid schedule 'every day'",
+		},
+		{
+			name:          "invalid timeout",
+			schedules:     []*ScheduleConfig{{Name: "report", Tool: "generate_report", Schedule: "@daily", Timeout: "0s"}},
+			expectedError: "timeout must be a positive duration, received 0s",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&ServerRuntime{Schedules: tc.schedules}).validateSchedules()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateDestinations(t *testing.T) {
+	denyInternal := &SecurityConfig{Network: &NetworkPolicyConfig{DeniedHosts: []string{"*.internal"}}}
+
+	tt := []struct {
+		name          string
+		runtime       *ServerRuntime
+		expectedError string
+	}{
+		{
+			name: "allowed destinations",
+			runtime: &ServerRuntime{
+				Security: denyInternal,
+				Policy:   &PolicyConfig{Engine: PolicyEngineOPA, URL: "http://opa.example.com:8181/v1/data/genmcp/authz"},
+				Audit:    &AuditConfig{Sink: AuditSinkHTTP, URL: "https://audit.example.com/records"},
+			},
+		},
+		{
+			name:          "denied policy engine",
+			runtime:       &ServerRuntime{Security: denyInternal, Policy: &PolicyConfig{Engine: PolicyEngineOPA, URL: "http://opa.internal:8181/v1/data/genmcp/authz"}},
+			expectedError: "invalid policy.url 'http://opa.internal:8181/v1/data/genmcp/authz': destination denied by the network policy: opa.internal is denied",
+		},
+		{
+			name:          "denied audit sink",
+			runtime:       &ServerRuntime{Security: denyInternal, Audit: &AuditConfig{Sink: AuditSinkHTTP, URL: "https://audit.internal/records"}},
+			expectedError: "invalid audit.url 'https://audit.internal/records'",
+		},
+		{
+			name:          "denied syslog daemon",
+			runtime:       &ServerRuntime{Security: denyInternal, Audit: &AuditConfig{Sink: AuditSinkSyslog, SyslogNetwork: "udp", SyslogAddress: "syslog.internal:514"}},
+			expectedError: "invalid audit.syslogAddress 'syslog.internal:514'",
+		},
+		{
+			name:    "local syslog daemon",
+			runtime: &ServerRuntime{Security: denyInternal, Audit: &AuditConfig{Sink: AuditSinkSyslog}},
+		},
+		{
+			name: "denied vault server",
+			runtime: &ServerRuntime{Security: denyInternal, Secrets: &SecretsConfig{Providers: []*SecretProviderConfig{
+				{Type: SecretProviderVault, Vault: &VaultConfig{Address: "https://vault.internal:8200"}},
+			}}},
+			expectedError: "invalid secrets.providers[0].vault.address 'https://vault.internal:8200'",
+		},
+		{
+			name: "denied authorization server",
+			runtime: &ServerRuntime{Security: denyInternal, StreamableHTTPConfig: &StreamableHTTPConfig{Auth: &AuthConfig{
+				Issuers: []*IssuerConfig{{Issuer: "https://auth.example.com", JWKSURI: "https://keys.internal/jwks.json"}},
+			}}},
+			expectedError: "invalid streamableHttpConfig.auth.issuers[0].jwksUri 'https://keys.internal/jwks.json'",
+		},
+		{
+			name:    "url referencing an environment variable",
+			runtime: &ServerRuntime{Security: denyInternal, Audit: &AuditConfig{Sink: AuditSinkHTTP, URL: "${AUDIT_URL}/records"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.runtime.validateDestinations()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	httpRuntime := func(webhooks ...*WebhookConfig) *ServerRuntime {
+		return &ServerRuntime{
+			TransportProtocol:    TransportProtocolStreamableHttp,
+			StreamableHTTPConfig: &StreamableHTTPConfig{Port: 8080},
+			Webhooks:             webhooks,
+		}
+	}
+
+	tt := []struct {
+		name          string
+		runtime       *ServerRuntime
+		expectedError string
+	}{
+		{
+			name: "valid webhooks",
+			runtime: httpRuntime(
+				&WebhookConfig{Name: "github", Path: "/webhooks/github", Secret: "s3cret"},
+				&WebhookConfig{Name: "shopify", Path: "/webhooks/shopify", Secret: "s3cret", SignatureHeader: "X-Shopify-Hmac-Sha256", Encoding: "base64", MaxEvents: 20},
+				&WebhookConfig{Name: "ci", Path: "/webhooks/ci"},
+			),
+		},
+		{
+			name: "served by a listener",
+			runtime: &ServerRuntime{
+				TransportProtocol: TransportProtocolStdio,
+				Listeners: []*ListenerConfig{
+					{Name: "http", TransportProtocol: TransportProtocolStreamableHttp, StreamableHTTPConfig: &StreamableHTTPConfig{Port: 8080}},
+				},
+				Webhooks: []*WebhookConfig{{Name: "github", Path: "/webhooks/github"}},
+			},
+		},
+		{
+			name:          "without streamable HTTP transport",
+			runtime:       &ServerRuntime{TransportProtocol: TransportProtocolStdio, Webhooks: []*WebhookConfig{{Name: "github", Path: "/webhooks/github"}}},
+			expectedError: "webhooks require a transport using streamablehttp",
+		},
+		{
+			name:          "empty webhook",
+			runtime:       httpRuntime(nil),
+			expectedError: "webhooks[0] must not be empty",
+		},
+		{
+			name:          "missing name",
+			runtime:       httpRuntime(&WebhookConfig{Path: "/webhooks/github"}),
+			expectedError: "webhooks[0] is invalid: name is required",
+		},
+		{
+			name:          "invalid name",
+			runtime:       httpRuntime(&WebhookConfig{Name: "git hub", Path: "/webhooks/github"}),
+			expectedError: "name 'git hub' must only contain letters, digits, '-', '_' and '.'",
+		},
+		{
+			name:          "missing path",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github"}),
+			expectedError: "path is required",
+		},
+		{
+			name:          "relative path",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "webhooks/github"}),
+			expectedError: "path must be an absolute path without wildcards, received webhooks/github",
+		},
+		{
+			name:          "path with wildcard",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/webhooks/{name}"}),
+			expectedError: "path must be an absolute path without wildcards, received /webhooks/{name}",
+		},
+		{
+			name:          "well-known path",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/.well-known/github"}),
+			expectedError: "path must not be under /.well-known/, received /.well-known/github",
+		},
+		{
+			name:          "base path",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/mcp"}),
+			expectedError: "webhooks[0] is invalid: path '/mcp' is already served by a streamable HTTP transport",
+		},
+		{
+			name:          "health path",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/readyz"}),
+			expectedError: "webhooks[0] is invalid: path '/readyz' is already served by a streamable HTTP transport",
+		},
+		{
+			name: "duplicate name",
+			runtime: httpRuntime(
+				&WebhookConfig{Name: "github", Path: "/webhooks/github"},
+				&WebhookConfig{Name: "github", Path: "/webhooks/github-enterprise"},
+			),
+			expectedError: "webhooks[1] is invalid: duplicate name 'github'",
+		},
+		{
+			name: "duplicate path",
+			runtime: httpRuntime(
+				&WebhookConfig{Name: "github", Path: "/webhooks/github"},
+				&WebhookConfig{Name: "github-enterprise", Path: "/webhooks/github"},
+			),
+			expectedError: "webhooks[1] is invalid: duplicate path '/webhooks/github'",
+		},
+		{
+			name:          "invalid algorithm",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/webhooks/github", Secret: "s3cret", Algorithm: "md5"}),
+			expectedError: "algorithm must be one of (sha1, sha256, sha512), received md5",
+		},
+		{
+			name:          "invalid encoding",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/webhooks/github", Secret: "s3cret", Encoding: "base32"}),
+			expectedError: "encoding must be one of (hex, base64), received base32",
+		},
+		{
+			name:          "signature without secret",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/webhooks/github", SignatureHeader: "X-Hub-Signature"}),
+			expectedError: "signatureHeader, algorithm and encoding require a secret",
+		},
+		{
+			name:          "negative maxEvents",
+			runtime:       httpRuntime(&WebhookConfig{Name: "github", Path: "/webhooks/github", MaxEvents: -1}),
+			expectedError: "maxEvents must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.runtime.validateWebhooks()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSpoolConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *SpoolConfig
+		expectedError string
+	}{
+		{
+			name:   "valid spool",
+			config: &SpoolConfig{Dir: "/var/spool/genmcp", ThresholdBytes: 50 << 20, MaxChunkBytes: 256 << 10, TTL: "30m", MaxTotalBytes: 1 << 30},
+		},
+		{
+			name:   "defaults",
+			config: &SpoolConfig{},
+		},
+		{
+			name:          "negative threshold",
+			config:        &SpoolConfig{ThresholdBytes: -1},
+			expectedError: "thresholdBytes must not be negative",
+		},
+		{
+			name:          "negative max chunk bytes",
+			config:        &SpoolConfig{MaxChunkBytes: -1},
+			expectedError: "maxChunkBytes must not be negative",
+		},
+		{
+			name:          "negative max total bytes",
+			config:        &SpoolConfig{MaxTotalBytes: -1},
+			expectedError: "maxTotalBytes must not be negative",
+		},
+		{
+			name:          "invalid ttl",
+			config:        &SpoolConfig{TTL: "0s"},
+			expectedError: "ttl must be a positive duration, received 0s",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestToolLoadingConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ToolLoadingConfig
+		expectedError string
+	}{
+		{
+			name:   "lazy loading with pages",
+			config: &ToolLoadingConfig{Lazy: true, PageSize: 100},
+		},
+		{
+			name:   "defaults",
+			config: &ToolLoadingConfig{},
+		},
+		{
+			name:          "negative page size",
+			config:        &ToolLoadingConfig{PageSize: -1},
+			expectedError: "pageSize must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
 }
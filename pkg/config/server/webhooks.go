@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/webhook"
+)
+
+// GetWebhookReceivers returns the receivers of the Webhooks config, by webhook name. The receivers are created
+// once and cached for subsequent calls, so that additional listeners and reloaded configs serve the same
+// events. It returns nil if Webhooks is empty.
+func (sr *ServerRuntime) GetWebhookReceivers() (map[string]*webhook.Receiver, error) {
+	if sr == nil || len(sr.Webhooks) == 0 {
+		return nil, nil
+	}
+
+	sr.webhookReceiversOnce.Do(func() {
+		receivers := make(map[string]*webhook.Receiver, len(sr.Webhooks))
+		for _, wc := range sr.Webhooks {
+			receiver, err := webhook.NewReceiver(wc.receiverConfig())
+			if err != nil {
+				sr.webhookReceiversErr = fmt.Errorf("invalid webhook '%s': %w", wc.Name, err)
+				return
+			}
+			receivers[wc.Name] = receiver
+		}
+		sr.webhookReceivers = receivers
+	})
+
+	return sr.webhookReceivers, sr.webhookReceiversErr
+}
+
+func (wc *WebhookConfig) receiverConfig() webhook.Config {
+	return webhook.Config{
+		Secret:          wc.Secret,
+		SignatureHeader: wc.SignatureHeader,
+		Algorithm:       wc.Algorithm,
+		Encoding:        wc.Encoding,
+		MaxEvents:       wc.MaxEvents,
+		MaxBodyBytes:    wc.MaxBodyBytes,
+	}
+}
@@ -0,0 +1,147 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+
+	"github.com/genmcp/gen-mcp/pkg/builder"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+// Engines are the supported container engines, in the order they are detected.
+var Engines = []string{"docker", "podman"}
+
+// RunOptions defines how the image of an MCP server is run.
+type RunOptions struct {
+	Name  string // name of the container
+	Image string // image of the server, built with genmcp build
+
+	MCPFilePath      string // absolute path of the MCP file mounted over the one of the image
+	ServerConfigPath string // absolute path of the server config file mounted over the one of the image
+
+	Ports []int    // ports published on the same port of the host
+	Files []string // absolute paths of the files referenced by the server config, mounted at the same path
+	Env   []string // environment variables passed to the container, as NAME=value or NAME to pass the value of the host
+
+	Interactive bool // whether stdin is attached to the container, for the stdio transport
+	Detach      bool // whether the container runs in the background
+}
+
+// ApplyServerConfig sets the ports, files and stdin of the options from the runtime of a server config, so
+// that the container serves the same ports and reads the same files as the server run locally.
+func (o *RunOptions) ApplyServerConfig(runtime *serverconfig.ServerRuntime) {
+	o.Ports, o.Files, o.Interactive = nil, nil, false
+	if runtime == nil {
+		return
+	}
+
+	o.addTransport(runtime.TransportProtocol, runtime.StreamableHTTPConfig)
+	for _, l := range runtime.Listeners {
+		o.addTransport(l.TransportProtocol, l.StreamableHTTPConfig)
+	}
+
+	if runtime.Admin != nil {
+		o.addPort(runtime.Admin.Port)
+	}
+	if runtime.ClientTLSConfig != nil {
+		o.addFiles(runtime.ClientTLSConfig.CACertFiles...)
+		o.addFiles(runtime.ClientTLSConfig.CACertDir)
+	}
+}
+
+func (o *RunOptions) addTransport(protocol string, httpConfig *serverconfig.StreamableHTTPConfig) {
+	if protocol == serverconfig.TransportProtocolStdio {
+		o.Interactive = true
+		return
+	}
+	if httpConfig == nil {
+		return
+	}
+
+	o.addPort(httpConfig.Port)
+	if tls := httpConfig.TLS; tls != nil {
+		o.addFiles(tls.CertFile, tls.KeyFile)
+		if tls.ClientAuth != nil {
+			o.addFiles(tls.ClientAuth.CACertFiles...)
+		}
+	}
+}
+
+func (o *RunOptions) addPort(port int) {
+	if port > 0 && !slices.Contains(o.Ports, port) {
+		o.Ports = append(o.Ports, port)
+	}
+}
+
+func (o *RunOptions) addFiles(paths ...string) {
+	for _, path := range paths {
+		if path != "" && !slices.Contains(o.Files, path) {
+			o.Files = append(o.Files, path)
+		}
+	}
+}
+
+// Args returns the arguments of the run command of a container engine running the server. The container
+// is removed when it stops.
+func Args(opts RunOptions) []string {
+	args := []string{"run", "--rm"}
+	if opts.Name != "" {
+		args = append(args, "--name", opts.Name)
+	}
+	if opts.Detach {
+		args = append(args, "--detach")
+	} else if opts.Interactive {
+		args = append(args, "--interactive")
+	}
+
+	for _, port := range opts.Ports {
+		p := strconv.Itoa(port)
+		args = append(args, "--publish", p+":"+p)
+	}
+
+	if opts.MCPFilePath != "" {
+		args = append(args, "--volume", opts.MCPFilePath+":"+builder.ImageMCPFilePath+":ro")
+	}
+	if opts.ServerConfigPath != "" {
+		args = append(args, "--volume", opts.ServerConfigPath+":"+builder.ImageMCPServerConfigPath+":ro")
+	}
+	for _, file := range opts.Files {
+		args = append(args, "--volume", file+":"+file+":ro")
+	}
+
+	for _, env := range opts.Env {
+		args = append(args, "--env", env)
+	}
+
+	return append(args, opts.Image)
+}
+
+// DetectEngine returns the first container engine of Engines found in the PATH.
+func DetectEngine() (string, error) {
+	for _, engine := range Engines {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("no container engine found, install one of %v", Engines)
+}
+
+// Run runs the server with a container engine, such as docker or podman, until it stops, or until the
+// container is started when opts.Detach is set.
+func Run(ctx context.Context, engine string, opts RunOptions, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, engine, Args(opts)...)
+	if opts.Interactive && !opts.Detach {
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s run failed: %w", engine, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package container
+
+import (
+	"testing"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgs(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     RunOptions
+		expected []string
+	}{
+		{
+			name: "http server",
+			opts: RunOptions{
+				Name:             "genmcp-weather",
+				Image:            "genmcp-weather:local",
+				MCPFilePath:      "/home/user/mcpfile.yaml",
+				ServerConfigPath: "/home/user/mcpserver.yaml",
+				Ports:            []int{8080, 9090},
+				Files:            []string{"/certs/tls.crt"},
+				Env:              []string{"API_KEY", "LOG_LEVEL=debug"},
+			},
+			expected: []string{
+				"run", "--rm", "--name", "genmcp-weather",
+				"--publish", "8080:8080", "--publish", "9090:9090",
+				"--volume", "/home/user/mcpfile.yaml:/app/mcpfile.yaml:ro",
+				"--volume", "/home/user/mcpserver.yaml:/app/mcpserver.yaml:ro",
+				"--volume", "/certs/tls.crt:/certs/tls.crt:ro",
+				"--env", "API_KEY", "--env", "LOG_LEVEL=debug",
+				"genmcp-weather:local",
+			},
+		},
+		{
+			name:     "stdio server",
+			opts:     RunOptions{Image: "genmcp-weather:local", Interactive: true},
+			expected: []string{"run", "--rm", "--interactive", "genmcp-weather:local"},
+		},
+		{
+			name:     "detached",
+			opts:     RunOptions{Image: "genmcp-weather:local", Ports: []int{8080}, Detach: true},
+			expected: []string{"run", "--rm", "--detach", "--publish", "8080:8080", "genmcp-weather:local"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Args(tc.opts))
+		})
+	}
+}
+
+func TestApplyServerConfig(t *testing.T) {
+	tt := []struct {
+		name     string
+		runtime  *serverconfig.ServerRuntime
+		expected RunOptions
+	}{
+		{
+			name: "http server",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol:    serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{Port: 8080},
+			},
+			expected: RunOptions{Ports: []int{8080}},
+		},
+		{
+			name: "stdio server",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStdio,
+			},
+			expected: RunOptions{Interactive: true},
+		},
+		{
+			name: "listeners, admin API and TLS files",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port: 8443,
+					TLS: &serverconfig.TLSConfig{
+						CertFile:   "/certs/tls.crt",
+						KeyFile:    "/certs/tls.key",
+						ClientAuth: &serverconfig.ClientAuthConfig{CACertFiles: []string{"/certs/clients.crt"}},
+					},
+				},
+				Listeners: []*serverconfig.ListenerConfig{
+					{Name: "stdio", TransportProtocol: serverconfig.TransportProtocolStdio},
+					{
+						Name:                 "internal",
+						TransportProtocol:    serverconfig.TransportProtocolStreamableHttp,
+						StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{Port: 8080},
+					},
+				},
+				Admin:           &serverconfig.AdminConfig{Port: 9090},
+				ClientTLSConfig: &serverconfig.ClientTLSConfig{CACertFiles: []string{"/certs/tls.crt", "/certs/backend.crt"}},
+			},
+			expected: RunOptions{
+				Ports:       []int{8443, 8080, 9090},
+				Files:       []string{"/certs/tls.crt", "/certs/tls.key", "/certs/clients.crt", "/certs/backend.crt"},
+				Interactive: true,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts RunOptions
+			opts.ApplyServerConfig(tc.runtime)
+			assert.Equal(t, tc.expected, opts)
+		})
+	}
+}
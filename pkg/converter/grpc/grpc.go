@@ -0,0 +1,145 @@
+// Package grpc converts the services of a gRPC server, discovered with server reflection, to GenMCP config files.
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/config"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	igrpc "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type ConvertedMCPFiles struct {
+	ToolDefinitions *definitions.MCPToolDefinitionsFile
+	ServerConfig    *serverconfig.MCPServerConfigFile
+}
+
+// Options defines how the server is reached.
+type Options struct {
+	TLS bool // whether the server is reached with TLS, both for reflection and by the generated tools
+}
+
+// ServerToMcpFile converts the unary methods of the services of the gRPC server at address to tools invoking
+// them, using the server reflection service of the server to discover the services and the schemas of their
+// request messages. Streaming methods are skipped.
+//
+// Methods that can't be converted to valid tools are skipped and reported in the returned error, along with
+// the converted files.
+func ServerToMcpFile(ctx context.Context, address string, opts Options) (*ConvertedMCPFiles, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpclib.NewClient(address, grpclib.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to %s: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	services, err := igrpc.NewResolver(conn).Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the services of %s: %w", address, err)
+	}
+
+	return convertServices(services, address, opts)
+}
+
+func convertServices(services []protoreflect.ServiceDescriptor, address string, opts Options) (*ConvertedMCPFiles, error) {
+	serverConfig := &serverconfig.MCPServerConfigFile{
+		Kind:          serverconfig.KindMCPServerConfig,
+		SchemaVersion: config.SchemaVersion,
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port: serverconfig.DefaultPort,
+				},
+			},
+		},
+	}
+
+	toolDefinitions := &definitions.MCPToolDefinitionsFile{
+		Kind:          definitions.KindMCPToolDefinitions,
+		SchemaVersion: config.SchemaVersion,
+		MCPToolDefinitions: definitions.MCPToolDefinitions{
+			Name:    "mcpfile-generated",
+			Version: "0.0.1",
+			Tools:   []*definitions.Tool{},
+		},
+	}
+
+	var err error
+	for _, service := range services {
+		methods := service.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			method := methods.Get(i)
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				err = errors.Join(err, fmt.Errorf("skipping method %s: streaming methods are not supported", fullMethodName(method)))
+				continue
+			}
+
+			tool := convertMethod(method, address, opts)
+			if toolErr := tool.Validate(invocation.InvocationValidator); toolErr != nil {
+				err = errors.Join(err, fmt.Errorf("skipping tool %s: %w", tool.Name, toolErr))
+				continue
+			}
+
+			toolDefinitions.Tools = append(toolDefinitions.Tools, tool)
+		}
+	}
+
+	return &ConvertedMCPFiles{
+		ToolDefinitions: toolDefinitions,
+		ServerConfig:    serverConfig,
+	}, err
+}
+
+// convertMethod converts a unary method to a tool named Service_Method, described by the comments of the
+// method when the server sent them.
+func convertMethod(method protoreflect.MethodDescriptor, address string, opts Options) *definitions.Tool {
+	service := method.Parent().(protoreflect.ServiceDescriptor)
+
+	description := comments(method)
+	if description == "" {
+		description = fmt.Sprintf("Calls the %s method of the %s gRPC service.", method.Name(), service.FullName())
+	}
+
+	return &definitions.Tool{
+		Name:        fmt.Sprintf("%s_%s", service.Name(), method.Name()),
+		Title:       fmt.Sprintf("%s %s", service.Name(), method.Name()),
+		Description: description,
+		InputSchema: messageSchema(method.Input(), nil),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: igrpc.InvocationType,
+			Config: &igrpc.GrpcInvocationConfig{
+				Address: address,
+				Method:  fullMethodName(method),
+				TLS:     opts.TLS,
+			},
+		},
+	}
+}
+
+// fullMethodName returns the name of a method as package.Service/Method.
+func fullMethodName(method protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("%s/%s", method.Parent().FullName(), method.Name())
+}
+
+// comments returns the leading comments of a descriptor, which are only known if the server sent the source
+// info of its files.
+func comments(desc protoreflect.Descriptor) string {
+	return strings.TrimSpace(desc.ParentFile().SourceLocations().ByDescriptor(desc).LeadingComments)
+}
@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	igrpc "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testFile is a service using the field types mapped to JSON schemas
+const testFile = `
+name: "users.proto"
+package: "users.v1"
+dependency: "google/protobuf/timestamp.proto"
+syntax: "proto3"
+message_type {
+  name: "User"
+  field { name: "id" number: 1 type: TYPE_INT64 label: LABEL_OPTIONAL json_name: "id" }
+  field { name: "display_name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "displayName" }
+  field { name: "role" number: 3 type: TYPE_ENUM type_name: ".users.v1.Role" label: LABEL_OPTIONAL json_name: "role" }
+  field { name: "tags" number: 4 type: TYPE_STRING label: LABEL_REPEATED json_name: "tags" }
+  field { name: "created_at" number: 5 type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" label: LABEL_OPTIONAL json_name: "createdAt" }
+  field { name: "manager" number: 6 type: TYPE_MESSAGE type_name: ".users.v1.User" label: LABEL_OPTIONAL json_name: "manager" }
+  field { name: "labels" number: 7 type: TYPE_MESSAGE type_name: ".users.v1.User.LabelsEntry" label: LABEL_REPEATED json_name: "labels" }
+  field { name: "avatar" number: 8 type: TYPE_BYTES label: LABEL_OPTIONAL json_name: "avatar" }
+  nested_type {
+    name: "LabelsEntry"
+    field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "key" }
+    field { name: "value" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "value" }
+    options { map_entry: true }
+  }
+}
+message_type {
+  name: "UpdateUserRequest"
+  field { name: "user" number: 1 type: TYPE_MESSAGE type_name: ".users.v1.User" label: LABEL_OPTIONAL json_name: "user" }
+  field { name: "score" number: 2 type: TYPE_DOUBLE label: LABEL_OPTIONAL json_name: "score" }
+  field { name: "notify" number: 3 type: TYPE_BOOL label: LABEL_OPTIONAL json_name: "notify" }
+}
+enum_type {
+  name: "Role"
+  value { name: "ROLE_UNSPECIFIED" number: 0 }
+  value { name: "ROLE_ADMIN" number: 1 }
+}
+service {
+  name: "UserService"
+  method { name: "UpdateUser" input_type: ".users.v1.UpdateUserRequest" output_type: ".users.v1.User" }
+  method { name: "WatchUsers" input_type: ".users.v1.UpdateUserRequest" output_type: ".users.v1.User" server_streaming: true }
+}
+source_code_info {
+  location { path: [6, 0, 2, 0] span: [0, 0, 0] leading_comments: " Updates a user.\n" }
+  location { path: [4, 1, 2, 1] span: [0, 0, 0] leading_comments: " Score of the user.\n" }
+}
+`
+
+func TestConvertServices(t *testing.T) {
+	fdp := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, prototext.Unmarshal([]byte(testFile), fdp))
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+
+	files, err := convertServices([]protoreflect.ServiceDescriptor{fd.Services().Get(0)}, "localhost:50051", Options{})
+	assert.ErrorContains(t, err, "skipping method users.v1.UserService/WatchUsers: streaming methods are not supported")
+	require.NotNil(t, files)
+
+	require.Len(t, files.ToolDefinitions.Tools, 1)
+	tool := files.ToolDefinitions.Tools[0]
+	assert.Equal(t, "UserService_UpdateUser", tool.Name)
+	assert.Equal(t, "Updates a user.", tool.Description)
+	assert.Equal(t, &igrpc.GrpcInvocationConfig{
+		Address: "localhost:50051",
+		Method:  "users.v1.UserService/UpdateUser",
+	}, tool.InvocationConfigWrapper.Config)
+
+	user := tool.InputSchema.Properties["user"]
+	require.NotNil(t, user)
+
+	tt := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		expected *jsonschema.Schema
+	}{
+		{
+			name:     "double",
+			schema:   tool.InputSchema.Properties["score"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeNumber, Description: "Score of the user."},
+		},
+		{
+			name:     "bool",
+			schema:   tool.InputSchema.Properties["notify"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeBoolean},
+		},
+		{
+			name:     "int64",
+			schema:   user.Properties["id"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeInteger},
+		},
+		{
+			name:     "json name",
+			schema:   user.Properties["displayName"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString},
+		},
+		{
+			name:     "enum",
+			schema:   user.Properties["role"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, Enum: []any{"ROLE_UNSPECIFIED", "ROLE_ADMIN"}},
+		},
+		{
+			name:   "repeated",
+			schema: user.Properties["tags"],
+			expected: &jsonschema.Schema{
+				Type:  invocation.JsonSchemaTypeArray,
+				Items: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString},
+			},
+		},
+		{
+			name:     "timestamp",
+			schema:   user.Properties["createdAt"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, Format: "date-time"},
+		},
+		{
+			name:   "recursive message",
+			schema: user.Properties["manager"],
+			expected: &jsonschema.Schema{
+				Type:                 invocation.JsonSchemaTypeObject,
+				AdditionalProperties: &jsonschema.Schema{},
+			},
+		},
+		{
+			name:   "map",
+			schema: user.Properties["labels"],
+			expected: &jsonschema.Schema{
+				Type:                 invocation.JsonSchemaTypeObject,
+				AdditionalProperties: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString},
+			},
+		},
+		{
+			name:     "bytes",
+			schema:   user.Properties["avatar"],
+			expected: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, ContentEncoding: "base64"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.schema)
+		})
+	}
+}
+
+func TestServerToMcpFile(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpclib.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	files, err := ServerToMcpFile(context.Background(), lis.Addr().String(), Options{})
+	// Watch is a server streaming method
+	assert.ErrorContains(t, err, "skipping method grpc.health.v1.Health/Watch")
+	require.NotNil(t, files)
+
+	toolNames := make([]string, 0, len(files.ToolDefinitions.Tools))
+	for _, tool := range files.ToolDefinitions.Tools {
+		toolNames = append(toolNames, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"Health_Check", "Health_List"}, toolNames)
+
+	var check *definitions.Tool
+	for _, tool := range files.ToolDefinitions.Tools {
+		if tool.Name == "Health_Check" {
+			check = tool
+		}
+	}
+	require.NotNil(t, check)
+	assert.Equal(t, &jsonschema.Schema{Type: invocation.JsonSchemaTypeString}, check.InputSchema.Properties["service"])
+	assert.Equal(t, "grpc.health.v1.Health/Check", check.InvocationConfigWrapper.Config.(*igrpc.GrpcInvocationConfig).Method)
+}
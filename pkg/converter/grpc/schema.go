@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"slices"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// wrapperTypes are the JSON schema types of the well known wrapper messages, which are mapped to their value.
+var wrapperTypes = map[protoreflect.FullName]string{
+	"google.protobuf.DoubleValue": invocation.JsonSchemaTypeNumber,
+	"google.protobuf.FloatValue":  invocation.JsonSchemaTypeNumber,
+	"google.protobuf.Int64Value":  invocation.JsonSchemaTypeInteger,
+	"google.protobuf.UInt64Value": invocation.JsonSchemaTypeInteger,
+	"google.protobuf.Int32Value":  invocation.JsonSchemaTypeInteger,
+	"google.protobuf.UInt32Value": invocation.JsonSchemaTypeInteger,
+	"google.protobuf.BoolValue":   invocation.JsonSchemaTypeBoolean,
+	"google.protobuf.StringValue": invocation.JsonSchemaTypeString,
+	"google.protobuf.BytesValue":  invocation.JsonSchemaTypeString,
+}
+
+// messageSchema returns the schema of the JSON mapping of a message. parents are the messages the message is
+// nested in, so that recursive messages are mapped to objects accepting any property instead of recursing
+// forever.
+func messageSchema(message protoreflect.MessageDescriptor, parents []protoreflect.FullName) *jsonschema.Schema {
+	if schema := wellKnownSchema(message); schema != nil {
+		return schema
+	}
+
+	if slices.Contains(parents, message.FullName()) {
+		return freeFormObject()
+	}
+	parents = append(parents, message.FullName())
+
+	schema := &jsonschema.Schema{
+		Type:       invocation.JsonSchemaTypeObject,
+		Properties: make(map[string]*jsonschema.Schema),
+	}
+
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+
+		var property *jsonschema.Schema
+		switch {
+		case field.IsMap():
+			property = &jsonschema.Schema{
+				Type:                 invocation.JsonSchemaTypeObject,
+				AdditionalProperties: fieldSchema(field.MapValue(), parents),
+			}
+		case field.IsList():
+			property = &jsonschema.Schema{
+				Type:  invocation.JsonSchemaTypeArray,
+				Items: fieldSchema(field, parents),
+			}
+		default:
+			property = fieldSchema(field, parents)
+		}
+
+		if description := comments(field); description != "" {
+			property.Description = description
+		}
+		schema.Properties[field.JSONName()] = property
+	}
+
+	return schema
+}
+
+// fieldSchema returns the schema of a single value of a field.
+func fieldSchema(field protoreflect.FieldDescriptor, parents []protoreflect.FullName) *jsonschema.Schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeBoolean}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeInteger}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeNumber}
+	case protoreflect.StringKind:
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString}
+	case protoreflect.BytesKind:
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, ContentEncoding: "base64"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]any, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, Enum: names}
+	default:
+		// message and group fields
+		schema := messageSchema(field.Message(), parents)
+		schema.Description = comments(field.Message())
+		return schema
+	}
+}
+
+// wellKnownSchema returns the schema of the JSON mapping of the well known types that have a special one, or nil.
+func wellKnownSchema(message protoreflect.MessageDescriptor) *jsonschema.Schema {
+	if t, ok := wrapperTypes[message.FullName()]; ok {
+		return &jsonschema.Schema{Type: t}
+	}
+
+	switch message.FullName() {
+	case "google.protobuf.Timestamp":
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, Format: "date-time"}
+	case "google.protobuf.Duration":
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString, Pattern: `^-?[0-9]+(\.[0-9]+)?s$`}
+	case "google.protobuf.FieldMask":
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeString}
+	case "google.protobuf.ListValue":
+		return &jsonschema.Schema{Type: invocation.JsonSchemaTypeArray}
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.Any":
+		// Value can hold any JSON value, but the request parser requires a type
+		return freeFormObject()
+	}
+
+	return nil
+}
+
+func freeFormObject() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:                 invocation.JsonSchemaTypeObject,
+		AdditionalProperties: &jsonschema.Schema{},
+	}
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/pb33f/libopenapi"
+)
+
+// ImportOptions defines the operations of an OpenAPI document imported as tools.
+type ImportOptions struct {
+	Host         string   // overrides the host (v2) or server URL (v3) of the document when set
+	Tags         []string // only import the operations with one of these tags when set
+	OperationIDs []string // only import the operations with one of these operationIds when set
+}
+
+func (o ImportOptions) includes(op operationRef) bool {
+	if len(o.OperationIDs) > 0 && !slices.Contains(o.OperationIDs, op.id) {
+		return false
+	}
+	if len(o.Tags) > 0 && !slices.ContainsFunc(op.tags, func(tag string) bool { return slices.Contains(o.Tags, tag) }) {
+		return false
+	}
+	return true
+}
+
+// ImportTools returns the tools of the operations of an OpenAPI document selected by opts. Unlike the tools of
+// DocumentToMcpFile, the tools invoke the API directly instead of extending an invocation base, so that they can
+// be added to the tools of an MCP file at runtime.
+//
+// Operations that can't be converted to valid tools are skipped and reported in the returned error, along with
+// the tools that were converted.
+func ImportTools(document []byte, opts ImportOptions) ([]*definitions.Tool, error) {
+	doc, err := libopenapi.NewDocument(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create openapi document: %w", err)
+	}
+
+	convOpts := conversionOptions{
+		host:    opts.Host,
+		include: opts.includes,
+		inline:  true,
+	}
+
+	var files *ConvertedMCPFiles
+	if strings.HasPrefix(doc.GetVersion(), "3") {
+		docModel, buildErr := doc.BuildV3Model()
+		if buildErr != nil {
+			return nil, fmt.Errorf("failed to build OpenAPI V3 model: %w", buildErr)
+		}
+		files, err = convertV3Model(&docModel.Model, convOpts)
+	} else {
+		docModel, buildErr := doc.BuildV2Model()
+		if buildErr != nil {
+			return nil, fmt.Errorf("failed to build OpenAPI V2 model: %w", buildErr)
+		}
+		files, err = convertV2Model(&docModel.Model, convOpts)
+	}
+	if files == nil {
+		return nil, err
+	}
+
+	tools, toolsErr := validTools(files.ToolDefinitions.Tools)
+	return tools, errors.Join(err, toolsErr)
+}
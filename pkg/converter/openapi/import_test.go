@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"os"
+	"testing"
+
+	ihttps "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTools(t *testing.T) {
+	docBytes, err := os.ReadFile("testdata/petstorev3.json")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		opts          ImportOptions
+		expectedTools []string
+	}{
+		{
+			name:          "filter by tags",
+			opts:          ImportOptions{Host: "https://petstore.example.com/api/v3", Tags: []string{"store"}},
+			expectedTools: []string{"get_store-inventory", "post_store-order", "get_store-order-orderId", "delete_store-order-orderId"},
+		},
+		{
+			name:          "filter by operationIds",
+			opts:          ImportOptions{Host: "https://petstore.example.com/api/v3", OperationIDs: []string{"getPetById", "loginUser"}},
+			expectedTools: []string{"get_pet-petId", "get_user-login"},
+		},
+		{
+			name:          "filter by tags and operationIds",
+			opts:          ImportOptions{Host: "https://petstore.example.com/api/v3", Tags: []string{"user"}, OperationIDs: []string{"getPetById", "loginUser"}},
+			expectedTools: []string{"get_user-login"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tools, err := ImportTools(docBytes, tc.opts)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(tools))
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedTools, names)
+		})
+	}
+}
+
+func TestImportToolsInvokeTheAPIDirectly(t *testing.T) {
+	docBytes, err := os.ReadFile("testdata/petstorev3.json")
+	require.NoError(t, err)
+
+	tools, err := ImportTools(docBytes, ImportOptions{Host: "https://petstore.example.com/api/v3", OperationIDs: []string{"getPetById"}})
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	assert.Equal(t, ihttps.InvocationType, tools[0].InvocationConfigWrapper.Type)
+	config, ok := tools[0].InvocationConfigWrapper.Config.(*ihttps.HttpInvocationConfig)
+	require.True(t, ok)
+	assert.Equal(t, "https://petstore.example.com/api/v3/pet/{petId}", config.URL)
+	assert.Equal(t, "GET", config.Method)
+}
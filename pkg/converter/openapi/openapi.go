@@ -34,6 +34,20 @@ type ConvertedMCPFiles struct {
 }
 
 func DocumentToMcpFile(document []byte, host string) (*ConvertedMCPFiles, error) {
+	return DocumentToMcpFileWithRules(document, host, nil)
+}
+
+// DocumentToMcpFileWithRules converts an OpenAPI document like DocumentToMcpFile, selecting and customizing the
+// converted tools with rules if not nil.
+func DocumentToMcpFileWithRules(document []byte, host string, rules *Rules) (*ConvertedMCPFiles, error) {
+	if rules != nil {
+		if err := rules.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid conversion rules: %w", err)
+		}
+	}
+
+	opts := conversionOptions{host: host, rules: rules}
+
 	doc, err := libopenapi.NewDocument(document)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create openapi document: %w", err)
@@ -44,18 +58,118 @@ func DocumentToMcpFile(document []byte, host string) (*ConvertedMCPFiles, error)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build OpenAPI V3 model: %w", err)
 		}
-		return McpFilesFromOpenApiV3Model(&docModel.Model, host)
+		return validateConvertedFiles(convertV3Model(&docModel.Model, opts))
 	}
 
 	docModel, err := doc.BuildV2Model()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build OpenAPI V2 model: %w", err)
 	}
-	return McpFilesFromOpenApiV2Model(&docModel.Model, host)
+	return validateConvertedFiles(convertV2Model(&docModel.Model, opts))
 }
 
 func McpFilesFromOpenApiV2Model(model *v2high.Swagger, host string) (*ConvertedMCPFiles, error) {
-	if model.Host == "" && host == "" {
+	return validateConvertedFiles(convertV2Model(model, conversionOptions{host: host}))
+}
+
+func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*ConvertedMCPFiles, error) {
+	return validateConvertedFiles(convertV3Model(model, conversionOptions{host: host}))
+}
+
+// conversionOptions defines how the operations of an OpenAPI document are converted to tools.
+type conversionOptions struct {
+	host string // overrides the host of the document when set
+
+	// include selects the operations converted to tools, all operations are converted when nil
+	include func(op operationRef) bool
+
+	// rules select and customize the converted tools when set
+	rules *Rules
+
+	// inline makes the tools invoke the API directly instead of extending the base invocation, so that they
+	// don't depend on the invocation bases registered for the MCP file
+	inline bool
+}
+
+func (o conversionOptions) includes(op operationRef) bool {
+	if o.include != nil && !o.include(op) {
+		return false
+	}
+	return o.rules.includes(op)
+}
+
+// validateConvertedFiles filters out the invalid tools of converted files, reporting them in the returned error.
+func validateConvertedFiles(files *ConvertedMCPFiles, err error) (*ConvertedMCPFiles, error) {
+	if files == nil {
+		return nil, err
+	}
+
+	// the only errors we should see at this point are from the tools themselves - let's validate them and filter out invalid tools
+	extends.SetBases(files.ToolDefinitions.InvocationBases)
+	var toolsErr error
+	files.ToolDefinitions.Tools, toolsErr = validTools(files.ToolDefinitions.Tools)
+
+	return files, errors.Join(err, toolsErr)
+}
+
+func validTools(tools []*definitions.Tool) ([]*definitions.Tool, error) {
+	var err error
+	valid := make([]*definitions.Tool, 0, len(tools))
+	for _, t := range tools {
+		toolErr := t.Validate(invocation.InvocationValidator)
+		if toolErr != nil {
+			err = errors.Join(err, fmt.Errorf("skipping tool %s: %w", t.Name, toolErr))
+		} else {
+			valid = append(valid, t)
+		}
+	}
+
+	return valid, err
+}
+
+// toolInvocation returns the invocation of the operation at path, extending the base invocation of the API,
+// or invoking the API directly when inline is set.
+func toolInvocation(baseUrl, path, method string, inline bool) (*invocation.InvocationConfigWrapper, error) {
+	if inline {
+		return &invocation.InvocationConfigWrapper{
+			Type: ihttps.InvocationType,
+			Config: &ihttps.HttpInvocationConfig{
+				URL:    baseUrl + path,
+				Method: strings.ToUpper(method),
+			},
+		}, nil
+	}
+
+	extend := &ihttps.HttpInvocationConfig{
+		URL: path,
+	}
+
+	extendRaw, err := json.Marshal(extend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool extension: %w", err)
+	}
+
+	override := &ihttps.HttpInvocationConfig{
+		Method: strings.ToUpper(method),
+	}
+
+	overrideRaw, err := json.Marshal(override)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool override: %w", err)
+	}
+
+	return &invocation.InvocationConfigWrapper{
+		Type: extends.InvocationType,
+		Config: &extends.ExtendsConfig{
+			From:     baseApiInvocationName,
+			Extend:   extendRaw,
+			Override: overrideRaw,
+		},
+	}, nil
+}
+
+func convertV2Model(model *v2high.Swagger, opts conversionOptions) (*ConvertedMCPFiles, error) {
+	if model.Host == "" && opts.host == "" {
 		return nil, fmt.Errorf("no host provided in the swagger file, unable to construct valid URLs")
 	}
 	// 1. Set top level GenMCP config file info
@@ -113,8 +227,8 @@ func McpFilesFromOpenApiV2Model(model *v2high.Swagger, host string) (*ConvertedM
 	}
 
 	urlHost := model.Host
-	if host != "" {
-		urlHost = host
+	if opts.host != "" {
+		urlHost = opts.host
 	}
 
 	baseUrl := fmt.Sprintf("%s://%s%s", scheme, urlHost, model.BasePath)
@@ -135,28 +249,19 @@ func McpFilesFromOpenApiV2Model(model *v2high.Swagger, host string) (*ConvertedM
 
 	for pathName, pathItem := range model.Paths.PathItems.FromOldest() {
 		for operationMethod, operation := range pathItem.GetOperations().FromOldest() {
-			if !ihttps.IsValidHttpMethod(operationMethod) {
-				err = errors.Join(err, fmt.Errorf("%s is not a supported http method, skipping %s", operationMethod, toolName(pathName, operationMethod)))
+			op := operationRef{path: pathName, method: operationMethod, id: operation.OperationId, tags: operation.Tags}
+			if !opts.includes(op) {
 				continue
 			}
 
-			extend := &ihttps.HttpInvocationConfig{
-				URL: pathName,
-			}
-
-			extendRaw, marshalErr := json.Marshal(extend)
-			if marshalErr != nil {
-				err = errors.Join(err, fmt.Errorf("failed to marshal tool extension: %w", marshalErr))
+			if !ihttps.IsValidHttpMethod(operationMethod) {
+				err = errors.Join(err, fmt.Errorf("%s is not a supported http method, skipping %s", operationMethod, toolName(pathName, operationMethod)))
 				continue
 			}
 
-			override := &ihttps.HttpInvocationConfig{
-				Method: strings.ToUpper(operationMethod),
-			}
-
-			overrideRaw, marshalErr := json.Marshal(override)
-			if marshalErr != nil {
-				err = errors.Join(err, fmt.Errorf("failed to marshal tool override: %w", marshalErr))
+			toolInvocationConfig, invocationErr := toolInvocation(baseUrl, pathName, operationMethod, opts.inline)
+			if invocationErr != nil {
+				err = errors.Join(err, invocationErr)
 				continue
 			}
 
@@ -174,14 +279,7 @@ func McpFilesFromOpenApiV2Model(model *v2high.Swagger, host string) (*ConvertedM
 					Properties: make(map[string]*jsonschema.Schema),
 					Required:   []string{},
 				},
-				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
-					Type: extends.InvocationType,
-					Config: &extends.ExtendsConfig{
-						From:     baseApiInvocationName,
-						Extend:   extendRaw,
-						Override: overrideRaw,
-					},
-				},
+				InvocationConfigWrapper: toolInvocationConfig,
 			}
 
 			numPathParams := 0
@@ -250,30 +348,19 @@ func McpFilesFromOpenApiV2Model(model *v2high.Swagger, host string) (*ConvertedM
 				continue
 			}
 
-			toolDefinitions.Tools = append(toolDefinitions.Tools, tool)
-		}
-	}
+			opts.rules.apply(tool, op)
 
-	// the only errors we should see at this point are from the tools themselves - let's validate them and filter out invalid tools
-	extends.SetBases(toolDefinitions.InvocationBases)
-	validTools := make([]*definitions.Tool, 0, len(toolDefinitions.Tools))
-	for _, t := range toolDefinitions.Tools {
-		toolErr := t.Validate(invocation.InvocationValidator)
-		if toolErr != nil {
-			err = errors.Join(err, fmt.Errorf("skipping tool %s: %w", t.Name, toolErr))
-		} else {
-			validTools = append(validTools, t)
+			toolDefinitions.Tools = append(toolDefinitions.Tools, tool)
 		}
 	}
 
-	toolDefinitions.Tools = validTools
-
 	return &ConvertedMCPFiles{
 		ToolDefinitions: toolDefinitions,
 		ServerConfig:    serverConfig,
 	}, err
 }
-func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*ConvertedMCPFiles, error) {
+
+func convertV3Model(model *v3high.Document, opts conversionOptions) (*ConvertedMCPFiles, error) {
 	// 1. Set top level GenMCP config file info
 	// 2. Create server config file with runtime configuration
 	// 3. Create MCP file with tools
@@ -320,8 +407,8 @@ func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*Converted
 		baseUrl = model.Servers[0].URL
 	}
 
-	if host != "" {
-		baseUrl = host
+	if opts.host != "" {
+		baseUrl = opts.host
 	}
 
 	baseInvocation := &invocation.InvocationConfigWrapper{
@@ -338,28 +425,19 @@ func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*Converted
 
 	for pathName, pathItem := range model.Paths.PathItems.FromOldest() {
 		for operationMethod, operation := range pathItem.GetOperations().FromOldest() {
-			if !ihttps.IsValidHttpMethod(operationMethod) {
-				err = errors.Join(err, fmt.Errorf("%s is not a supported http method, skipping %s", operationMethod, toolName(pathName, operationMethod)))
+			op := operationRef{path: pathName, method: operationMethod, id: operation.OperationId, tags: operation.Tags}
+			if !opts.includes(op) {
 				continue
 			}
 
-			extend := &ihttps.HttpInvocationConfig{
-				URL: pathName,
-			}
-
-			extendRaw, marshalErr := json.Marshal(extend)
-			if marshalErr != nil {
-				err = errors.Join(err, fmt.Errorf("failed to marshal tool extension: %w", marshalErr))
+			if !ihttps.IsValidHttpMethod(operationMethod) {
+				err = errors.Join(err, fmt.Errorf("%s is not a supported http method, skipping %s", operationMethod, toolName(pathName, operationMethod)))
 				continue
 			}
 
-			override := &ihttps.HttpInvocationConfig{
-				Method: strings.ToUpper(operationMethod),
-			}
-
-			overrideRaw, marshalErr := json.Marshal(override)
-			if marshalErr != nil {
-				err = errors.Join(err, fmt.Errorf("failed to marshal tool override: %w", marshalErr))
+			toolInvocationConfig, invocationErr := toolInvocation(baseUrl, pathName, operationMethod, opts.inline)
+			if invocationErr != nil {
+				err = errors.Join(err, invocationErr)
 				continue
 			}
 
@@ -377,14 +455,7 @@ func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*Converted
 					Properties: make(map[string]*jsonschema.Schema),
 					Required:   []string{},
 				},
-				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
-					Type: extends.InvocationType,
-					Config: &extends.ExtendsConfig{
-						From:     baseApiInvocationName,
-						Extend:   extendRaw,
-						Override: overrideRaw,
-					},
-				},
+				InvocationConfigWrapper: toolInvocationConfig,
 			}
 
 			visited := make(map[*highbase.SchemaProxy]*jsonschema.Schema)
@@ -427,24 +498,12 @@ func McpFilesFromOpenApiV3Model(model *v3high.Document, host string) (*Converted
 
 			}
 
-			toolDefinitions.Tools = append(toolDefinitions.Tools, tool)
-		}
-	}
+			opts.rules.apply(tool, op)
 
-	// the only errors we should see at this point are from the tools themselves - let's validate them and filter out invalid tools
-	extends.SetBases(toolDefinitions.InvocationBases)
-	validTools := make([]*definitions.Tool, 0, len(toolDefinitions.Tools))
-	for _, t := range toolDefinitions.Tools {
-		toolErr := t.Validate(invocation.InvocationValidator)
-		if toolErr != nil {
-			err = errors.Join(err, fmt.Errorf("skipping tool %s: %w", t.Name, toolErr))
-		} else {
-			validTools = append(validTools, t)
+			toolDefinitions.Tools = append(toolDefinitions.Tools, tool)
 		}
 	}
 
-	toolDefinitions.Tools = validTools
-
 	return &ConvertedMCPFiles{
 		ToolDefinitions: toolDefinitions,
 		ServerConfig:    serverConfig,
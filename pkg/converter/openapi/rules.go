@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	ihttps "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/google/jsonschema-go/jsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+// Rules customizes the tools converted from an OpenAPI document, so that large documents can be converted
+// to a usable set of tools.
+type Rules struct {
+	// Only convert the operations matching one of these. All operations are converted if empty.
+	Include []OperationMatch `json:"include,omitempty"`
+
+	// Don't convert the operations matching one of these, even if they match Include.
+	Exclude []OperationMatch `json:"exclude,omitempty"`
+
+	// Overrides of the name, title and description of the tools, by operationId.
+	Tools map[string]ToolOverride `json:"tools,omitempty"`
+
+	// Rewrites applied in order to the descriptions of the tools taken from the document.
+	DescriptionRewrites []DescriptionRewrite `json:"descriptionRewrites,omitempty"`
+
+	// Schemas nested deeper than this many levels under the input schema of a tool are collapsed to values of
+	// their type without properties or items, and objects accept any property. Disabled if 0.
+	MaxSchemaDepth int `json:"maxSchemaDepth,omitempty"`
+}
+
+// OperationMatch matches the operations with all the fields that are set.
+type OperationMatch struct {
+	// Path of the operation, as a pattern where * matches a single path segment (e.g. /users/*/repos).
+	Path string `json:"path,omitempty"`
+
+	// HTTP method of the operation, case insensitive.
+	Method string `json:"method,omitempty"`
+
+	// Tag of the operation.
+	Tag string `json:"tag,omitempty"`
+}
+
+// ToolOverride replaces the fields of a converted tool that are set.
+type ToolOverride struct {
+	Name        string `json:"name,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// DescriptionRewrite replaces the matches of a regular expression in descriptions.
+type DescriptionRewrite struct {
+	// Regular expression (RE2 syntax) matched against the descriptions.
+	Pattern string `json:"pattern"`
+
+	// Replacement of the matches, which can reference the groups of the pattern ($1, ${name}).
+	Replacement string `json:"replacement"`
+
+	pattern *regexp.Regexp
+}
+
+// operationRef identifies an operation of an OpenAPI document.
+type operationRef struct {
+	path   string
+	method string
+	id     string
+	tags   []string
+}
+
+// ParseRulesFile parses and validates the conversion rules of a YAML or JSON file.
+func ParseRulesFile(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	rules := &Rules{}
+	if err := yaml.UnmarshalStrict(data, rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules file: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Validate checks the rules and compiles their patterns. It must be called before the rules are used.
+func (r *Rules) Validate() error {
+	var err error = nil
+
+	for i, m := range r.Include {
+		if matchErr := m.validate(); matchErr != nil {
+			err = errors.Join(err, fmt.Errorf("include[%d]: %w", i, matchErr))
+		}
+	}
+
+	for i, m := range r.Exclude {
+		if matchErr := m.validate(); matchErr != nil {
+			err = errors.Join(err, fmt.Errorf("exclude[%d]: %w", i, matchErr))
+		}
+	}
+
+	for i := range r.DescriptionRewrites {
+		rewrite := &r.DescriptionRewrites[i]
+		pattern, compileErr := regexp.Compile(rewrite.Pattern)
+		if compileErr != nil || rewrite.Pattern == "" {
+			err = errors.Join(err, fmt.Errorf("descriptionRewrites[%d]: invalid pattern '%s'", i, rewrite.Pattern))
+			continue
+		}
+		rewrite.pattern = pattern
+	}
+
+	if r.MaxSchemaDepth < 0 {
+		err = errors.Join(err, fmt.Errorf("maxSchemaDepth must not be negative"))
+	}
+
+	return err
+}
+
+func (m OperationMatch) validate() error {
+	var err error = nil
+
+	if m.Path == "" && m.Method == "" && m.Tag == "" {
+		err = errors.Join(err, fmt.Errorf("at least one of path, method or tag is required"))
+	}
+
+	if _, matchErr := path.Match(m.Path, ""); matchErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid path pattern '%s'", m.Path))
+	}
+
+	if m.Method != "" && !ihttps.IsValidHttpMethod(m.Method) {
+		err = errors.Join(err, fmt.Errorf("invalid method '%s'", m.Method))
+	}
+
+	return err
+}
+
+func (m OperationMatch) matches(op operationRef) bool {
+	if m.Path != "" {
+		if ok, _ := path.Match(m.Path, op.path); !ok {
+			return false
+		}
+	}
+	if m.Method != "" && !strings.EqualFold(m.Method, op.method) {
+		return false
+	}
+	if m.Tag != "" && !slices.Contains(op.tags, m.Tag) {
+		return false
+	}
+	return true
+}
+
+// includes returns whether the operation is converted to a tool.
+func (r *Rules) includes(op operationRef) bool {
+	if r == nil {
+		return true
+	}
+
+	matches := func(m OperationMatch) bool { return m.matches(op) }
+	if len(r.Include) > 0 && !slices.ContainsFunc(r.Include, matches) {
+		return false
+	}
+	return !slices.ContainsFunc(r.Exclude, matches)
+}
+
+// apply applies the rules to the tool converted from the operation.
+func (r *Rules) apply(tool *definitions.Tool, op operationRef) {
+	if r == nil {
+		return
+	}
+
+	for _, rewrite := range r.DescriptionRewrites {
+		tool.Description = strings.TrimSpace(rewrite.pattern.ReplaceAllString(tool.Description, rewrite.Replacement))
+	}
+
+	if override, ok := r.Tools[op.id]; ok && op.id != "" {
+		if override.Name != "" {
+			tool.Name = override.Name
+		}
+		if override.Title != "" {
+			tool.Title = override.Title
+		}
+		if override.Description != "" {
+			tool.Description = override.Description
+		}
+	}
+
+	if r.MaxSchemaDepth > 0 {
+		tool.InputSchema = collapseSchema(tool.InputSchema, r.MaxSchemaDepth)
+	}
+}
+
+// collapseSchema returns a copy of s where the schemas nested more than depth levels under s lose their
+// properties and items, and objects accept any property.
+func collapseSchema(s *jsonschema.Schema, depth int) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+
+	c := *s
+	if depth == 0 {
+		c.Properties, c.Required, c.Items = nil, nil, nil
+		if c.Type == invocation.JsonSchemaTypeObject {
+			c.AdditionalProperties = &jsonschema.Schema{}
+		}
+		return &c
+	}
+
+	if s.Properties != nil {
+		c.Properties = make(map[string]*jsonschema.Schema, len(s.Properties))
+		for name, property := range s.Properties {
+			c.Properties[name] = collapseSchema(property, depth-1)
+		}
+	}
+	c.Items = collapseSchema(s.Items, depth-1)
+
+	return &c
+}
@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findTool(tools []*definitions.Tool, name string) *definitions.Tool {
+	for _, t := range tools {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func TestDocumentToMcpFileWithRules(t *testing.T) {
+	docBytes, err := os.ReadFile("testdata/petstorev3.json")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		rules         *Rules
+		expectedTools []string
+		check         func(t *testing.T, tools []*definitions.Tool)
+	}{
+		{
+			name: "include by tag and exclude by method",
+			rules: &Rules{
+				Include: []OperationMatch{{Tag: "store"}},
+				Exclude: []OperationMatch{{Method: "delete"}},
+			},
+			expectedTools: []string{"get_store-inventory", "post_store-order", "get_store-order-orderId"},
+		},
+		{
+			name: "include by path and method",
+			rules: &Rules{
+				Include: []OperationMatch{{Path: "/user/*", Method: "GET"}, {Path: "/pet"}},
+			},
+			expectedTools: []string{"get_user-login", "get_user-logout", "get_user-username", "put_pet", "post_pet"},
+		},
+		{
+			name: "tool overrides by operationId",
+			rules: &Rules{
+				Include: []OperationMatch{{Path: "/pet/{petId}"}},
+				Tools: map[string]ToolOverride{
+					"getPetById": {Name: "get_pet", Title: "Get pet", Description: "Returns the pet with the given id."},
+				},
+			},
+			expectedTools: []string{"get_pet", "post_pet-petId", "delete_pet-petId"},
+			check: func(t *testing.T, tools []*definitions.Tool) {
+				tool := findTool(tools, "get_pet")
+				assert.Equal(t, "Get pet", tool.Title)
+				assert.Equal(t, "Returns the pet with the given id.", tool.Description)
+			},
+		},
+		{
+			name: "description rewrites",
+			rules: &Rules{
+				Include: []OperationMatch{{Tag: "store"}},
+				DescriptionRewrites: []DescriptionRewrite{
+					{Pattern: `For valid response try integer IDs with value [^.]*\.`, Replacement: ""},
+					{Pattern: `(?i)\bstore\b`, Replacement: "shop"},
+				},
+			},
+			expectedTools: []string{"get_store-inventory", "post_store-order", "get_store-order-orderId", "delete_store-order-orderId"},
+			check: func(t *testing.T, tools []*definitions.Tool) {
+				assert.Equal(t, "Place a new order in the shop.", findTool(tools, "post_store-order").Description)
+				assert.Equal(t, "Other values will generate exceptions.", findTool(tools, "get_store-order-orderId").Description)
+			},
+		},
+		{
+			name: "collapse deep schemas",
+			rules: &Rules{
+				Include:        []OperationMatch{{Path: "/pet", Method: "POST"}},
+				MaxSchemaDepth: 1,
+			},
+			expectedTools: []string{"post_pet"},
+			check: func(t *testing.T, tools []*definitions.Tool) {
+				schema := findTool(tools, "post_pet").InputSchema
+				require.Contains(t, schema.Properties, "category")
+				category := schema.Properties["category"]
+				assert.Equal(t, "object", category.Type)
+				assert.Nil(t, category.Properties, "nested properties should be collapsed")
+				assert.NotNil(t, category.AdditionalProperties, "collapsed objects should accept any property")
+
+				tags := schema.Properties["tags"]
+				assert.Equal(t, "array", tags.Type)
+				assert.Nil(t, tags.Items, "nested items should be collapsed")
+				assert.Equal(t, "string", schema.Properties["name"].Type)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			convertedFiles, err := DocumentToMcpFileWithRules(docBytes, "", tc.rules)
+			require.NoError(t, err)
+
+			tools := convertedFiles.ToolDefinitions.Tools
+			names := make([]string, 0, len(tools))
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedTools, names)
+
+			if tc.check != nil {
+				tc.check(t, tools)
+			}
+		})
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		rules         *Rules
+		expectedError string
+	}{
+		{
+			name: "valid rules",
+			rules: &Rules{
+				Include:             []OperationMatch{{Path: "/users/*", Method: "get"}, {Tag: "admin"}},
+				DescriptionRewrites: []DescriptionRewrite{{Pattern: `\s+`, Replacement: " "}},
+				MaxSchemaDepth:      2,
+			},
+		},
+		{
+			name:          "empty match",
+			rules:         &Rules{Exclude: []OperationMatch{{}}},
+			expectedError: "exclude[0]: at least one of path, method or tag is required",
+		},
+		{
+			name:          "invalid path pattern",
+			rules:         &Rules{Include: []OperationMatch{{Path: "/users/["}}},
+			expectedError: "include[0]: invalid path pattern '/users/['",
+		},
+		{
+			name:          "invalid method",
+			rules:         &Rules{Include: []OperationMatch{{Method: "FETCH"}}},
+			expectedError: "include[0]: invalid method 'FETCH'",
+		},
+		{
+			name:          "invalid description pattern",
+			rules:         &Rules{DescriptionRewrites: []DescriptionRewrite{{Pattern: "(unclosed"}}},
+			expectedError: "descriptionRewrites[0]: invalid pattern '(unclosed'",
+		},
+		{
+			name:          "negative max schema depth",
+			rules:         &Rules{MaxSchemaDepth: -1},
+			expectedError: "maxSchemaDepth must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rules.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestParseRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`include:
+  - tag: pet
+exclude:
+  - method: DELETE
+tools:
+  getPetById:
+    name: get_pet
+descriptionRewrites:
+  - pattern: '\s+'
+    replacement: ' '
+maxSchemaDepth: 2
+`), 0644))
+
+	rules, err := ParseRulesFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []OperationMatch{{Tag: "pet"}}, rules.Include)
+	assert.Equal(t, []OperationMatch{{Method: "DELETE"}}, rules.Exclude)
+	assert.Equal(t, "get_pet", rules.Tools["getPetById"].Name)
+	assert.Equal(t, 2, rules.MaxSchemaDepth)
+
+	require.NoError(t, os.WriteFile(path, []byte("includes:\n  - tag: pet\n"), 0644))
+	_, err = ParseRulesFile(path)
+	assert.ErrorContains(t, err, "failed to parse rules file")
+}
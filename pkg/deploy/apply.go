@@ -0,0 +1,32 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Apply applies manifests to the cluster of the current kubectl context with kubectl, which may be the
+// path of kubectl or of a compatible client such as oc. The output of kubectl is written to out.
+func Apply(ctx context.Context, kubectl string, manifests []byte, out io.Writer) error {
+	if kubectl == "" {
+		kubectl = "kubectl"
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, kubectl, "apply", "-f", "-")
+	cmd.Stdin = bytes.NewReader(manifests)
+	cmd.Stdout = out
+	cmd.Stderr = io.MultiWriter(out, &stderr)
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("%s apply failed: %w: %s", kubectl, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return fmt.Errorf("%s apply failed: %w", kubectl, err)
+	}
+
+	return nil
+}
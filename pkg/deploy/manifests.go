@@ -0,0 +1,319 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMountPath is where the config files are mounted in the container.
+	ConfigMountPath = "/etc/genmcp"
+
+	// ConfigHashAnnotation is set on the pods to the hash of the config files, so that changing them rolls out
+	// new pods.
+	ConfigHashAnnotation = "genmcp.dev/config-hash"
+
+	nameLabel      = "app.kubernetes.io/name"
+	managedByLabel = "app.kubernetes.io/managed-by"
+
+	containerName = "mcp-server"
+	portName      = "http"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Options defines the Kubernetes resources of an MCP server.
+type Options struct {
+	Name      string // name of the resources
+	Namespace string // namespace of the resources, the namespace of the kubectl context if empty
+	Image     string // image of the server, built with genmcp build
+	Replicas  int
+
+	MCPFile      []byte // contents of the MCP file, stored in the ConfigMap
+	ServerConfig []byte // contents of the server config file, stored in the ConfigMap
+
+	Port          int    // port the server listens on
+	TLS           bool   // whether the server serves HTTPS
+	LivenessPath  string // path of the liveness probe, no probe if empty
+	ReadinessPath string // path of the readiness probe, no probe if empty
+
+	IngressHost  string // an Ingress routing this host to the server is created if set
+	IngressClass string // class of the Ingress, the default class if empty
+	Route        bool   // whether an OpenShift Route to the server is created
+}
+
+// ApplyServerConfig sets the port, TLS and probes of the options from the runtime of a server config with
+// defaults applied. Only the streamable HTTP transport can be deployed.
+func (o *Options) ApplyServerConfig(runtime *serverconfig.ServerRuntime) error {
+	if runtime == nil || runtime.TransportProtocol != serverconfig.TransportProtocolStreamableHttp || runtime.StreamableHTTPConfig == nil {
+		return fmt.Errorf("only servers using the %s transport can be deployed", serverconfig.TransportProtocolStreamableHttp)
+	}
+
+	httpConfig := runtime.StreamableHTTPConfig
+	o.Port = httpConfig.Port
+	o.TLS = httpConfig.TLS != nil
+	o.LivenessPath, o.ReadinessPath = "", ""
+	if httpConfig.Health.IsEnabled() {
+		o.LivenessPath = serverconfig.DefaultLivenessPath
+		o.ReadinessPath = serverconfig.DefaultReadinessPath
+		if httpConfig.Health != nil && httpConfig.Health.LivenessPath != "" {
+			o.LivenessPath = httpConfig.Health.LivenessPath
+		}
+		if httpConfig.Health != nil && httpConfig.Health.ReadinessPath != "" {
+			o.ReadinessPath = httpConfig.Health.ReadinessPath
+		}
+	}
+
+	return nil
+}
+
+// ResourceName returns a valid Kubernetes resource name for the server name, e.g. my-server for My Server.
+func ResourceName(serverName string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(serverName), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// Render returns the manifests of the resources of the server as a multi-document YAML file: a ConfigMap
+// holding the config files, a Deployment mounting it, a Service, and the optional Ingress and Route.
+func Render(opts Options) ([]byte, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("a name is required")
+	}
+	if opts.Image == "" {
+		return nil, fmt.Errorf("an image is required")
+	}
+	if opts.Port <= 0 {
+		return nil, fmt.Errorf("the port must be greater than 0")
+	}
+
+	resources := []map[string]any{
+		configMap(opts),
+		deployment(opts),
+		service(opts),
+	}
+	if opts.IngressHost != "" {
+		resources = append(resources, ingress(opts))
+	}
+	if opts.Route {
+		resources = append(resources, route(opts))
+	}
+
+	var out []byte
+	for i, resource := range resources {
+		data, err := yaml.Marshal(resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", resource["kind"], err)
+		}
+		if i > 0 {
+			out = append(out, "---\n"...)
+		}
+		out = append(out, data...)
+	}
+
+	return out, nil
+}
+
+func (o Options) configMapName() string {
+	return o.Name + "-config"
+}
+
+func (o Options) servicePort() int {
+	if o.TLS {
+		return 443
+	}
+	return 80
+}
+
+func (o Options) metadata(name string) map[string]any {
+	metadata := map[string]any{
+		"name": name,
+		"labels": map[string]any{
+			nameLabel:      o.Name,
+			managedByLabel: "genmcp",
+		},
+	}
+	if o.Namespace != "" {
+		metadata["namespace"] = o.Namespace
+	}
+	return metadata
+}
+
+func configMap(opts Options) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   opts.metadata(opts.configMapName()),
+		"data": map[string]any{
+			"mcpfile.yaml":   string(opts.MCPFile),
+			"mcpserver.yaml": string(opts.ServerConfig),
+		},
+	}
+}
+
+func deployment(opts Options) map[string]any {
+	hash := sha256.New()
+	hash.Write(opts.MCPFile)
+	hash.Write(opts.ServerConfig)
+
+	container := map[string]any{
+		"name":  containerName,
+		"image": opts.Image,
+		"env": []any{
+			map[string]any{"name": "MCP_FILE_PATH", "value": ConfigMountPath + "/mcpfile.yaml"},
+			map[string]any{"name": "MCP_SERVER_CONFIG_PATH", "value": ConfigMountPath + "/mcpserver.yaml"},
+		},
+		"ports": []any{
+			map[string]any{"name": portName, "containerPort": opts.Port, "protocol": "TCP"},
+		},
+		"volumeMounts": []any{
+			map[string]any{"name": "config", "mountPath": ConfigMountPath, "readOnly": true},
+		},
+	}
+	if opts.LivenessPath != "" {
+		container["livenessProbe"] = opts.probe(opts.LivenessPath)
+	}
+	if opts.ReadinessPath != "" {
+		container["readinessProbe"] = opts.probe(opts.ReadinessPath)
+	}
+
+	replicas := opts.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+
+	return map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   opts.metadata(opts.Name),
+		"spec": map[string]any{
+			"replicas": replicas,
+			"selector": map[string]any{
+				"matchLabels": map[string]any{nameLabel: opts.Name},
+			},
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": map[string]any{
+						nameLabel:      opts.Name,
+						managedByLabel: "genmcp",
+					},
+					"annotations": map[string]any{
+						ConfigHashAnnotation: hex.EncodeToString(hash.Sum(nil)),
+					},
+				},
+				"spec": map[string]any{
+					"containers": []any{container},
+					"volumes": []any{
+						map[string]any{
+							"name":      "config",
+							"configMap": map[string]any{"name": opts.configMapName()},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (o Options) probe(path string) map[string]any {
+	scheme := "HTTP"
+	if o.TLS {
+		scheme = "HTTPS"
+	}
+
+	return map[string]any{
+		"httpGet": map[string]any{
+			"path":   path,
+			"port":   portName,
+			"scheme": scheme,
+		},
+	}
+}
+
+func service(opts Options) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   opts.metadata(opts.Name),
+		"spec": map[string]any{
+			"type":     "ClusterIP",
+			"selector": map[string]any{nameLabel: opts.Name},
+			"ports": []any{
+				map[string]any{
+					"name":       portName,
+					"port":       opts.servicePort(),
+					"targetPort": portName,
+					"protocol":   "TCP",
+				},
+			},
+		},
+	}
+}
+
+func ingress(opts Options) map[string]any {
+	spec := map[string]any{
+		"rules": []any{
+			map[string]any{
+				"host": opts.IngressHost,
+				"http": map[string]any{
+					"paths": []any{
+						map[string]any{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]any{
+								"service": map[string]any{
+									"name": opts.Name,
+									"port": map[string]any{"name": portName},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if opts.IngressClass != "" {
+		spec["ingressClassName"] = opts.IngressClass
+	}
+
+	return map[string]any{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   opts.metadata(opts.Name),
+		"spec":       spec,
+	}
+}
+
+func route(opts Options) map[string]any {
+	// a server serving HTTPS terminates TLS itself, otherwise the router does
+	tls := map[string]any{
+		"termination":                   "edge",
+		"insecureEdgeTerminationPolicy": "Redirect",
+	}
+	if opts.TLS {
+		tls = map[string]any{"termination": "passthrough"}
+	}
+
+	return map[string]any{
+		"apiVersion": "route.openshift.io/v1",
+		"kind":       "Route",
+		"metadata":   opts.metadata(opts.Name),
+		"spec": map[string]any{
+			"to": map[string]any{
+				"kind": "Service",
+				"name": opts.Name,
+			},
+			"port": map[string]any{"targetPort": portName},
+			"tls":  tls,
+		},
+	}
+}
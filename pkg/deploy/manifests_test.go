@@ -0,0 +1,273 @@
+package deploy
+
+import (
+	"bytes"
+	"testing"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func testOptions() Options {
+	return Options{
+		Name:          "my-server",
+		Namespace:     "mcp",
+		Image:         "registry.example.com/my-server:v1",
+		MCPFile:       []byte("name: my-server\n"),
+		ServerConfig:  []byte("runtime: {}\n"),
+		Port:          8080,
+		LivenessPath:  serverconfig.DefaultLivenessPath,
+		ReadinessPath: serverconfig.DefaultReadinessPath,
+	}
+}
+
+// renderResources renders opts and returns the rendered resources by kind.
+func renderResources(t *testing.T, opts Options) map[string]map[string]any {
+	t.Helper()
+
+	out, err := Render(opts)
+	require.NoError(t, err)
+
+	resources := make(map[string]map[string]any)
+	for _, doc := range bytes.Split(out, []byte("---\n")) {
+		var resource map[string]any
+		require.NoError(t, yaml.Unmarshal(doc, &resource))
+		resources[resource["kind"].(string)] = resource
+	}
+	return resources
+}
+
+// get returns the value at path in a rendered resource, where ints index lists.
+func get(t *testing.T, resource map[string]any, path ...any) any {
+	t.Helper()
+
+	var value any = resource
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := value.(map[string]any)
+			require.True(t, ok, "%v is not an object", path)
+			value = m[key]
+		case int:
+			l, ok := value.([]any)
+			require.True(t, ok, "%v is not a list", path)
+			require.Greater(t, len(l), key)
+			value = l[key]
+		}
+	}
+	return value
+}
+
+func TestRender(t *testing.T) {
+	container := []any{"spec", "template", "spec", "containers", 0}
+
+	tt := []struct {
+		name          string
+		modify        func(o *Options)
+		expectedKinds []string
+		check         func(t *testing.T, resources map[string]map[string]any)
+		errContains   string
+	}{
+		{
+			name:          "default resources",
+			expectedKinds: []string{"ConfigMap", "Deployment", "Service"},
+			check: func(t *testing.T, resources map[string]map[string]any) {
+				configMap := resources["ConfigMap"]
+				assert.Equal(t, "my-server-config", get(t, configMap, "metadata", "name"))
+				assert.Equal(t, "mcp", get(t, configMap, "metadata", "namespace"))
+				assert.Equal(t, "name: my-server\n", get(t, configMap, "data", "mcpfile.yaml"))
+				assert.Equal(t, "runtime: {}\n", get(t, configMap, "data", "mcpserver.yaml"))
+
+				deployment := resources["Deployment"]
+				assert.Equal(t, float64(1), get(t, deployment, "spec", "replicas"))
+				assert.NotEmpty(t, get(t, deployment, "spec", "template", "metadata", "annotations", ConfigHashAnnotation))
+				assert.Equal(t, "registry.example.com/my-server:v1", get(t, deployment, append(container, "image")...))
+				assert.Equal(t, "/etc/genmcp/mcpfile.yaml", get(t, deployment, append(container, "env", 0, "value")...))
+				assert.Equal(t, "/etc/genmcp/mcpserver.yaml", get(t, deployment, append(container, "env", 1, "value")...))
+				assert.Equal(t, float64(8080), get(t, deployment, append(container, "ports", 0, "containerPort")...))
+				assert.Equal(t, "/healthz", get(t, deployment, append(container, "livenessProbe", "httpGet", "path")...))
+				assert.Equal(t, "HTTP", get(t, deployment, append(container, "livenessProbe", "httpGet", "scheme")...))
+				assert.Equal(t, "/readyz", get(t, deployment, append(container, "readinessProbe", "httpGet", "path")...))
+				assert.Equal(t, "my-server-config", get(t, deployment, "spec", "template", "spec", "volumes", 0, "configMap", "name"))
+
+				assert.Equal(t, float64(80), get(t, resources["Service"], "spec", "ports", 0, "port"))
+				assert.Equal(t, "my-server", get(t, resources["Service"], "spec", "selector", nameLabel))
+			},
+		},
+		{
+			name: "replicas and no probes",
+			modify: func(o *Options) {
+				o.Replicas = 3
+				o.LivenessPath, o.ReadinessPath = "", ""
+			},
+			expectedKinds: []string{"ConfigMap", "Deployment", "Service"},
+			check: func(t *testing.T, resources map[string]map[string]any) {
+				assert.Equal(t, float64(3), get(t, resources["Deployment"], "spec", "replicas"))
+				assert.Nil(t, get(t, resources["Deployment"], append(container, "livenessProbe")...))
+				assert.Nil(t, get(t, resources["Deployment"], append(container, "readinessProbe")...))
+			},
+		},
+		{
+			name: "ingress",
+			modify: func(o *Options) {
+				o.IngressHost = "mcp.example.com"
+				o.IngressClass = "nginx"
+			},
+			expectedKinds: []string{"ConfigMap", "Deployment", "Service", "Ingress"},
+			check: func(t *testing.T, resources map[string]map[string]any) {
+				ingress := resources["Ingress"]
+				assert.Equal(t, "nginx", get(t, ingress, "spec", "ingressClassName"))
+				assert.Equal(t, "mcp.example.com", get(t, ingress, "spec", "rules", 0, "host"))
+				assert.Equal(t, "my-server", get(t, ingress, "spec", "rules", 0, "http", "paths", 0, "backend", "service", "name"))
+			},
+		},
+		{
+			name:          "route terminating TLS",
+			modify:        func(o *Options) { o.Route = true },
+			expectedKinds: []string{"ConfigMap", "Deployment", "Service", "Route"},
+			check: func(t *testing.T, resources map[string]map[string]any) {
+				assert.Equal(t, "edge", get(t, resources["Route"], "spec", "tls", "termination"))
+			},
+		},
+		{
+			name: "route to a server serving TLS",
+			modify: func(o *Options) {
+				o.Route = true
+				o.TLS = true
+			},
+			expectedKinds: []string{"ConfigMap", "Deployment", "Service", "Route"},
+			check: func(t *testing.T, resources map[string]map[string]any) {
+				assert.Equal(t, "passthrough", get(t, resources["Route"], "spec", "tls", "termination"))
+				assert.Equal(t, float64(443), get(t, resources["Service"], "spec", "ports", 0, "port"))
+				assert.Equal(t, "HTTPS", get(t, resources["Deployment"], append(container, "readinessProbe", "httpGet", "scheme")...))
+			},
+		},
+		{
+			name:        "missing image",
+			modify:      func(o *Options) { o.Image = "" },
+			errContains: "an image is required",
+		},
+		{
+			name:        "missing name",
+			modify:      func(o *Options) { o.Name = "" },
+			errContains: "a name is required",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := testOptions()
+			if tc.modify != nil {
+				tc.modify(&opts)
+			}
+
+			if tc.errContains != "" {
+				_, err := Render(opts)
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+
+			resources := renderResources(t, opts)
+			kinds := make([]string, 0, len(resources))
+			for kind := range resources {
+				kinds = append(kinds, kind)
+			}
+			assert.ElementsMatch(t, tc.expectedKinds, kinds)
+			tc.check(t, resources)
+		})
+	}
+}
+
+func TestRenderConfigHash(t *testing.T) {
+	hash := func(opts Options) any {
+		return get(t, renderResources(t, opts)["Deployment"], "spec", "template", "metadata", "annotations", ConfigHashAnnotation)
+	}
+
+	opts := testOptions()
+	original := hash(opts)
+	opts.Replicas = 2
+	assert.Equal(t, original, hash(opts), "the hash should only depend on the config files")
+	opts.ServerConfig = []byte("runtime: {port: 9090}\n")
+	assert.NotEqual(t, original, hash(opts), "changing the config files should change the hash")
+}
+
+func TestApplyServerConfig(t *testing.T) {
+	disabled := false
+
+	tt := []struct {
+		name        string
+		runtime     *serverconfig.ServerRuntime
+		expected    Options
+		errContains string
+	}{
+		{
+			name: "default health paths",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol:    serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{Port: 8080},
+			},
+			expected: Options{Port: 8080, LivenessPath: "/healthz", ReadinessPath: "/readyz"},
+		},
+		{
+			name: "custom health paths and TLS",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port:   8443,
+					TLS:    &serverconfig.TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+					Health: &serverconfig.HealthConfig{LivenessPath: "/live", ReadinessPath: "/ready"},
+				},
+			},
+			expected: Options{Port: 8443, TLS: true, LivenessPath: "/live", ReadinessPath: "/ready"},
+		},
+		{
+			name: "health disabled",
+			runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port:   8080,
+					Health: &serverconfig.HealthConfig{Enabled: &disabled},
+				},
+			},
+			expected: Options{Port: 8080},
+		},
+		{
+			name:        "stdio",
+			runtime:     &serverconfig.ServerRuntime{TransportProtocol: serverconfig.TransportProtocolStdio},
+			errContains: "only servers using the streamablehttp transport can be deployed",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts Options
+			err := opts.ApplyServerConfig(tc.runtime)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, opts)
+		})
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	tt := []struct {
+		serverName string
+		expected   string
+	}{
+		{serverName: "my-server", expected: "my-server"},
+		{serverName: "My Server", expected: "my-server"},
+		{serverName: "_weather.api_", expected: "weather-api"},
+		{serverName: "a-very-long-server-name-that-does-not-fit-in-a-kubernetes-resource-name", expected: "a-very-long-server-name-that-does-not-fit-in-a-kubernetes-resou"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.serverName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ResourceName(tc.serverName))
+		})
+	}
+}
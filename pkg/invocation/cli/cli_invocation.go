@@ -2,27 +2,57 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
 	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
 	"github.com/genmcp/gen-mcp/pkg/template"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/yosida95/uritemplate/v3"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+// commandWaitDelay bounds the time spent waiting for the output of a killed command, e.g. when it left
+// background processes holding its output open.
+const commandWaitDelay = 5 * time.Second
+
+// defaultKillGracePeriod is the time a cancelled command has to exit after SIGTERM before it is sent SIGKILL,
+// if not configured.
+const defaultKillGracePeriod = 5 * time.Second
+
 type CliInvoker struct {
-	ParsedTemplate *template.ParsedTemplate // Parsed template for the command
-	InputSchema    *jsonschema.Resolved     // InputSchema for the tool
-	URITemplate    string                   // MCP URI template (for resource templates only)
+	ParsedTemplate     *template.ParsedTemplate // Parsed template for the command
+	InputSchema        *jsonschema.Resolved     // InputSchema for the tool
+	URITemplate        string                   // MCP URI template (for resource templates only)
+	OutputFormat       string                   // How stdout is parsed into structured content (for tools only)
+	OutputPattern      *regexp.Regexp           // Pattern matched against every line of stdout, for the regex output format
+	OutputColumns      []string                 // Column names of the table output format, read from the header if empty
+	ErrorMode          string                   // How failed commands are reported: result or protocol (for tools only)
+	Quoting            string                   // How argument values are inserted into the command: none, shell, or argv
+	AllowedExecutables []string                 // Executables the command may run, any if empty
+	WorkingDir         string                   // Directory the command runs in, the server's if empty
+	AllowedEnv         []string                 // Environment variables passed to the command, all if nil
+	Timeout            time.Duration            // Maximum execution time of the command, no timeout if zero
+	MaxOutputBytes     int                      // Maximum output of the command, no limit if zero
+	ProgressInterval   time.Duration            // How often the progress of the command is reported, 5s if zero
+	ProgressPattern    *regexp.Regexp           // Pattern of the output lines reporting the progress of the command (for tools only)
+	KillGracePeriod    *time.Duration           // Time a cancelled command has to exit before it is killed, 5s if nil
 }
 
 var _ invocation.Invoker = &CliInvoker{}
+var _ invocation.DryRunner = &CliInvoker{}
 
 // newCommandBuilder creates a new commandBuilder from the parsed template.
 // A new builder is created for each invocation to avoid sharing state.
@@ -44,9 +74,26 @@ func (ci *CliInvoker) newCommandBuilder() (*commandBuilder, error) {
 		templateBuilder:  templateBuilder,
 		templateVarNames: templateVarNames,
 		extraArgs:        make(map[string]any),
+		quote:            ci.quote(),
 	}, nil
 }
 
+// killGracePeriod returns the time a cancelled command has to exit after SIGTERM before it is sent SIGKILL.
+func (ci *CliInvoker) killGracePeriod() time.Duration {
+	if ci.KillGracePeriod == nil {
+		return defaultKillGracePeriod
+	}
+	return *ci.KillGracePeriod
+}
+
+// quote returns the function quoting argument values, or nil if they are inserted verbatim.
+func (ci *CliInvoker) quote() func(string) string {
+	if ci.Quoting == QuotingShell || ci.Quoting == QuotingArgv {
+		return shellQuote
+	}
+	return nil
+}
+
 func (ci *CliInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Starting CLI tool invocation")
@@ -57,14 +104,41 @@ func (ci *CliInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mc
 		incomingHeaders = req.Extra.Header
 	}
 
-	command, _, err := ci.buildCommandFromArgs(ctx, req.Params.Arguments, incomingHeaders)
+	buildCtx, buildSpan := tracing.Start(ctx, "build cli command")
+	command, _, err := ci.buildCommandFromArgs(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
 	if err != nil {
 		return nil, err
 	}
 
-	output, err := ci.executeCommand(ctx, command, nil)
+	out, err := ci.executeCommand(ctx, command, nil, ci.newProgressReporter(req))
 	if err != nil {
-		return utils.McpTextError("Command execution failed:\n%s", string(output)), nil
+		return ci.failureResult(out, err)
+	}
+
+	if ci.OutputFormat == "" || ci.OutputFormat == OutputFormatText {
+		logger.Info("CLI tool invocation completed successfully")
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(out.combined),
+				},
+			},
+		}, nil
+	}
+
+	_, parseSpan := tracing.Start(ctx, "parse cli output")
+	structured, err := ci.parseOutput(out.stdout)
+	tracing.End(parseSpan, err)
+	if err != nil {
+		logger.Error("Failed to parse CLI command output", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to parse command output as %s: %v", ci.OutputFormat, err), nil
+	}
+
+	text, err := json.Marshal(structured)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode command output: %v", err), nil
 	}
 
 	logger.Info("CLI tool invocation completed successfully")
@@ -72,20 +146,102 @@ func (ci *CliInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mc
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: string(output),
+				Text: string(text),
 			},
 		},
+		StructuredContent: structured,
 	}, nil
 }
 
+// failureResult reports a failed command execution to the client, according to the error mode of the
+// invocation. out is nil if the command was rejected before it was started.
+func (ci *CliInvoker) failureResult(out *commandOutput, err error) (*mcp.CallToolResult, error) {
+	message := "Command execution failed"
+	detail := invocation.NewErrorDetail(failureCode(err), 0)
+	var (
+		combined   []byte
+		structured map[string]any
+	)
+	if out != nil {
+		if out.exitCode > 0 {
+			message += fmt.Sprintf(" with exit code %d", out.exitCode)
+			detail = invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, out.exitCode)
+		}
+		combined = out.combined
+		structured = map[string]any{
+			"exitCode": out.exitCode,
+			"stdout":   string(out.stdout),
+			"stderr":   string(out.stderr),
+		}
+	}
+
+	if ci.ErrorMode == ErrorModeProtocol {
+		var se *sandboxError
+		if errors.As(err, &se) {
+			message += ": " + se.Error()
+		}
+		rpcErr, err := invocation.NewRPCError(detail, message, structured)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode command output: %w", err)
+		}
+		return nil, rpcErr
+	}
+
+	result := utils.McpTextError("%s:\n%s", message, failureOutput(combined, err))
+	result.StructuredContent = structured
+	invocation.SetErrorDetail(result, detail)
+	return result, nil
+}
+
+// failureCode returns the code of a command that could not be executed or was stopped by the sandbox,
+// err being the error of the execution.
+func failureCode(err error) invocation.ErrorCode {
+	if errors.Is(err, exec.ErrNotFound) {
+		return invocation.ErrorCodeBackendUnavailable
+	}
+	return invocation.CodeOf(err, invocation.ErrorCodeInternal)
+}
+
+// DryRun returns the command Invoke would execute for req, without executing it.
+func (ci *CliInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	var incomingHeaders map[string][]string
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	command, _, err := ci.buildCommandFromArgs(ctx, req.Params.Arguments, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ci.checkExecutables(command); err != nil {
+		return nil, err
+	}
+
+	return &invocation.DryRunResult{
+		Type:    InvocationType,
+		Command: command,
+	}, nil
+}
+
+// commandOutput is the output of an executed command.
+type commandOutput struct {
+	combined []byte // stdout and stderr, interleaved as written by the command
+	stdout   []byte
+	stderr   []byte
+	exitCode int // -1 if the command did not exit on its own, e.g. because it was killed
+}
+
 // executeCommand handles the common command execution cycle.
 // It centralizes command creation, execution, output reading, and logging.
-// Returns the output bytes and error. Logs sensitive command details to baseLogger only.
+// Returns the output of the command, which is nil if the command was rejected before it
+// was started, and the error. Logs sensitive command details to baseLogger only.
 func (ci *CliInvoker) executeCommand(
 	ctx context.Context,
 	command string,
 	contextInfo map[string]string, // additional context for logging (e.g., "uri", "template")
-) ([]byte, error) {
+	progress *progressReporter, // reports the progress of the command, nil if the client did not ask for it
+) (*commandOutput, error) {
 	logger := logging.FromContext(ctx)
 	baseLogger := logging.BaseFromContext(ctx)
 
@@ -97,22 +253,91 @@ func (ci *CliInvoker) executeCommand(
 		logFields = append(logFields, zap.String(k, v))
 	}
 
+	if err := ci.checkExecutables(command); err != nil {
+		baseLogger.Warn("CLI command rejected", append(logFields, zap.Error(err))...)
+		logger.Warn("CLI command rejected")
+		return nil, err
+	}
+
 	baseLogger.Debug("Executing CLI command", logFields...)
 
-	cmd := exec.Command("bash", "-c", command)
+	name, args, spanName := "bash", []string{"-c", command}, "exec bash"
+	if ci.Quoting == QuotingArgv {
+		argv, err := splitArgv(command)
+		if err != nil {
+			baseLogger.Error("Failed to split CLI command into arguments", append(logFields, zap.Error(err))...)
+			logger.Error("Failed to split CLI command into arguments")
+			return nil, &sandboxError{invocation.Errorf(invocation.ErrorCodeValidation, "command rejected: %w", err)}
+		}
+		name, args, spanName = argv[0], argv[1:], "exec command"
+	}
+
+	// the command itself is not recorded on the span, as it may contain sensitive arguments
+	_, span := tracing.Start(ctx, spanName)
+	defer span.End()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	if ci.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, ci.Timeout)
+	}
+	defer cancel()
+
+	out := &outputBuffer{max: ci.MaxOutputBytes, cancel: cancel}
+
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = ci.WorkingDir
+	cmd.Env = ci.commandEnv()
+	cmd.Stdout = stdoutWriter{out}
+	cmd.Stderr = out
+	gracePeriod := ci.killGracePeriod()
+	cmd.WaitDelay = gracePeriod + commandWaitDelay
+	// cancelling the request stops the processes started by the command too
+	setProcessGroup(cmd, gracePeriod)
+
+	if progress != nil {
+		if progress.pattern != nil {
+			cmd.Stdout = io.MultiWriter(cmd.Stdout, progress.lineWriter(ctx))
+			cmd.Stderr = io.MultiWriter(cmd.Stderr, progress.lineWriter(ctx))
+		}
+		stop := progress.start(ctx, out)
+		defer stop()
+	}
 
-	output, err := cmd.CombinedOutput()
+	err := cmd.Run()
+	output := &commandOutput{
+		combined: out.buf.Bytes(),
+		stdout:   out.stdout.Bytes(),
+		stderr:   out.stderr.Bytes(),
+		exitCode: -1,
+	}
+	if cmd.ProcessState != nil {
+		output.exitCode = cmd.ProcessState.ExitCode()
+	}
+	switch {
+	case out.exceeded:
+		err = &sandboxError{invocation.Errorf(invocation.ErrorCodeInternal, "command output exceeded the limit of %d bytes", ci.MaxOutputBytes)}
+	case err != nil && ci.Timeout > 0 && errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		err = &sandboxError{invocation.Errorf(invocation.ErrorCodeTimeout, "command timed out after %s", ci.Timeout)}
+	}
+
+	if cmd.ProcessState != nil {
+		span.SetAttributes(attribute.Int("process.exit.code", output.exitCode))
+	}
 	if err != nil {
+		span.RecordError(err)
+		tracing.SetError(span, "command execution failed")
 		baseLogger.Error("CLI command execution failed", append(logFields,
-			zap.String("output", string(output)),
+			zap.Int("exit_code", output.exitCode),
+			zap.String("stdout", string(output.stdout)),
+			zap.String("stderr", string(output.stderr)),
 			zap.Error(err))...)
-		logger.Error("CLI command execution failed")
+		logger.Error("CLI command execution failed", zap.Int("exit_code", output.exitCode))
 		return output, err
 	}
 
 	// Server-side only logging with sensitive command details
 	baseLogger.Info("CLI command executed successfully", append(logFields,
-		zap.Int("output_length", len(output)))...)
+		zap.Int("output_length", len(output.combined)))...)
 
 	return output, nil
 }
@@ -132,6 +357,10 @@ func (ci *CliInvoker) buildCommandFromArgs(
 		return "", nil, fmt.Errorf("failed to create command builder: %w", err)
 	}
 
+	cb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	cb.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	cb.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
 	// Set up source resolver for incoming headers if provided
 	if incomingHeaders != nil {
 		headerResolver := template.NewHttpHeaderResolver(incomingHeaders)
@@ -145,12 +374,17 @@ func (ci *CliInvoker) buildCommandFromArgs(
 	parsed, err := dj.ParseJson(argsBytes, ci.InputSchema.Schema())
 	if err != nil {
 		logger.Error("Failed to parse request arguments", zap.Error(err))
-		return "", nil, fmt.Errorf("failed to parse request: %w", err)
+		return "", nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
 	}
 
 	if err := ci.InputSchema.Validate(parsed); err != nil {
 		logger.Error("Failed to validate request arguments", zap.Error(err))
-		return "", nil, fmt.Errorf("failed to validate request: %w", err)
+		return "", nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	if err := ci.checkArgumentPaths(parsed); err != nil {
+		logger.Error("Rejected request arguments", zap.Error(err))
+		return "", nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
 	}
 
 	command, err := cb.GetResult()
@@ -177,6 +411,10 @@ func (ci *CliInvoker) buildCommandFromPromptArgs(
 		return "", fmt.Errorf("failed to create command builder: %w", err)
 	}
 
+	cb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	cb.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	cb.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
 	// Set up source resolver for incoming headers if provided
 	if incomingHeaders != nil {
 		headerResolver := template.NewHttpHeaderResolver(incomingHeaders)
@@ -199,6 +437,11 @@ func (ci *CliInvoker) buildCommandFromPromptArgs(
 		return "", fmt.Errorf("failed to validate prompt request: %w", err)
 	}
 
+	if err := ci.checkArgumentPaths(argsForValidation); err != nil {
+		logger.Error("Rejected prompt request arguments", zap.Error(err))
+		return "", fmt.Errorf("failed to validate prompt request: %w", err)
+	}
+
 	command, err := cb.GetResult()
 	if err != nil {
 		logger.Error("Failed to build command", zap.Error(err))
@@ -223,6 +466,10 @@ func (ci *CliInvoker) extractArgsFromURI(
 		return nil, nil, fmt.Errorf("failed to create command builder: %w", err)
 	}
 
+	cb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	cb.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	cb.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
 	// Set up source resolver for incoming headers if provided
 	if incomingHeaders != nil {
 		headerResolver := template.NewHttpHeaderResolver(incomingHeaders)
@@ -264,6 +511,13 @@ func (ci *CliInvoker) extractArgsFromURI(
 		return nil, nil, fmt.Errorf("failed to validate resource template request: %w", err)
 	}
 
+	if err := ci.checkArgumentPaths(argsMap); err != nil {
+		logger.Error("Rejected resource template request",
+			zap.String("uri", uri),
+			zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to validate resource template request: %w", err)
+	}
+
 	return cb, argsMap, nil
 }
 
@@ -282,9 +536,13 @@ func (ci *CliInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptReques
 		return nil, err
 	}
 
-	output, err := ci.executeCommand(ctx, command, nil)
+	out, err := ci.executeCommand(ctx, command, nil, nil)
 	if err != nil {
-		return utils.McpPromptTextError("Command execution failed:\n%s", string(output)), nil
+		var combined []byte
+		if out != nil {
+			combined = out.combined
+		}
+		return utils.McpPromptTextError("Command execution failed:\n%s", failureOutput(combined, err)), nil
 	}
 
 	logger.Info("CLI prompt invocation completed successfully")
@@ -293,7 +551,7 @@ func (ci *CliInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptReques
 		Messages: []*mcp.PromptMessage{
 			{
 				Role:    "assistant",
-				Content: &mcp.TextContent{Text: string(output)},
+				Content: &mcp.TextContent{Text: string(out.combined)},
 			},
 		},
 	}, nil
@@ -309,7 +567,7 @@ func (ci *CliInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceR
 
 	// Note: Static resources don't use headers since they have no template variables
 
-	output, err := ci.executeCommand(ctx, command, map[string]string{"uri": req.Params.URI})
+	out, err := ci.executeCommand(ctx, command, map[string]string{"uri": req.Params.URI}, nil)
 	if err != nil {
 		logger.Error("CLI resource command execution failed", zap.String("uri", req.Params.URI))
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
@@ -322,7 +580,7 @@ func (ci *CliInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceR
 			{
 				URI:      req.Params.URI,
 				MIMEType: "text/plain",
-				Text:     string(output),
+				Text:     string(out.combined),
 			},
 		},
 	}, nil
@@ -351,10 +609,10 @@ func (ci *CliInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadR
 		return nil, fmt.Errorf("failed to build command: %w", err)
 	}
 
-	output, err := ci.executeCommand(ctx, command.(string), map[string]string{
+	out, err := ci.executeCommand(ctx, command.(string), map[string]string{
 		"uri":      req.Params.URI,
 		"template": ci.URITemplate,
-	})
+	}, nil)
 	if err != nil {
 		logger.Error("CLI resource template command execution failed", zap.String("uri", req.Params.URI))
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
@@ -367,7 +625,7 @@ func (ci *CliInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadR
 			{
 				URI:      req.Params.URI,
 				MIMEType: "text/plain",
-				Text:     string(output),
+				Text:     string(out.combined),
 			},
 		},
 	}, nil
@@ -377,13 +635,15 @@ type commandBuilder struct {
 	templateBuilder  *template.TemplateBuilder
 	templateVarNames map[string]bool // Set of variable names the template cares about
 	extraArgs        map[string]any
+	quote            func(string) string // Quotes the extra args, nil to insert them verbatim
 }
 
 var _ invocation.Builder = &commandBuilder{}
 
 func (cb *commandBuilder) SetField(path string, value any) {
-	// If this is a variable that the template cares about, propagate to the template
-	if cb.templateVarNames[path] {
+	// If this is a variable that the template cares about, or an element of one, propagate to the template
+	array, _ := template.ArrayPath(path)
+	if cb.templateVarNames[path] || cb.templateVarNames[array] {
 		cb.templateBuilder.SetField(path, value)
 	} else {
 		// Otherwise, store it in extra args
@@ -403,7 +663,11 @@ func (cb *commandBuilder) GetResult() (any, error) {
 	formattedParts = append(formattedParts, templateResult.(string))
 
 	for argName, argVal := range cb.extraArgs {
-		formattedParts = append(formattedParts, fmt.Sprintf("--%s=%v", argName, argVal))
+		arg := fmt.Sprintf("--%s=%v", argName, argVal)
+		if cb.quote != nil {
+			arg = cb.quote(arg)
+		}
+		formattedParts = append(formattedParts, arg)
 	}
 
 	return strings.Join(formattedParts, " "), nil
@@ -3,11 +3,15 @@ package cli
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
 	"github.com/genmcp/gen-mcp/pkg/template"
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -726,3 +730,154 @@ func TestCliResourceTemplateInvocation(t *testing.T) {
 		})
 	}
 }
+
+func TestCliInvokerErrorMode(t *testing.T) {
+	tt := []struct {
+		name               string
+		config             *CliInvocationConfig
+		expectedText       string
+		expectedOutput     []string // lines of the output following expectedText, in any order
+		expectedStructured map[string]any
+		expectedDetail     invocation.ErrorDetail
+		expectedRPCError   *jsonrpc.Error
+	}{
+		{
+			// stdout and stderr are read from separate pipes, so their lines may be combined in any order
+			name:           "non-zero exit code as result",
+			config:         &CliInvocationConfig{Command: "echo partial; echo 'not found' >&2; exit 3"},
+			expectedText:   "Command execution failed with exit code 3:\n",
+			expectedOutput: []string{"partial", "not found"},
+			expectedStructured: map[string]any{
+				"exitCode": 3,
+				"stdout":   "partial\n",
+				"stderr":   "not found\n",
+			},
+			expectedDetail: invocation.ErrorDetail{Code: invocation.ErrorCodeBackendStatus, Status: 3},
+		},
+		{
+			name:           "rejected command as result",
+			config:         &CliInvocationConfig{Command: "id", AllowedExecutables: []string{"echo"}},
+			expectedText:   "Command execution failed:\ncommand rejected: executable 'id' is not allowed",
+			expectedDetail: invocation.ErrorDetail{Code: invocation.ErrorCodeValidation},
+		},
+		{
+			name:           "timeout as result",
+			config:         &CliInvocationConfig{Command: "sleep 5", Timeout: "100ms"},
+			expectedText:   "Command execution failed:\ncommand timed out after 100ms",
+			expectedDetail: invocation.ErrorDetail{Code: invocation.ErrorCodeTimeout, Retryable: true},
+			expectedStructured: map[string]any{
+				"exitCode": -1,
+				"stdout":   "",
+				"stderr":   "",
+			},
+		},
+		{
+			name:   "non-zero exit code as protocol error",
+			config: &CliInvocationConfig{Command: "echo 'not found' >&2; exit 3", ErrorMode: ErrorModeProtocol},
+			expectedRPCError: &jsonrpc.Error{
+				Code:    invocation.ErrorCodeBackendStatus.RPCCode(),
+				Message: "Command execution failed with exit code 3",
+				Data:    []byte(`{"code":"backend_error_status","status":3,"retryable":false,"exitCode":3,"stderr":"not found\n","stdout":""}`),
+			},
+		},
+		{
+			name:   "timeout as protocol error",
+			config: &CliInvocationConfig{Command: "sleep 5", Timeout: "100ms", ErrorMode: ErrorModeProtocol},
+			expectedRPCError: &jsonrpc.Error{
+				Code:    invocation.ErrorCodeTimeout.RPCCode(),
+				Message: "Command execution failed: command timed out after 100ms",
+				Data:    []byte(`{"code":"timeout","retryable":true,"exitCode":-1,"stderr":"","stdout":""}`),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &definitions.Tool{Name: "lookup", InputSchema: resolvedEmpty.Schema(), ResolvedInputSchema: resolvedEmpty}
+			invoker, err := (&InvokerFactory{}).CreateInvoker(tc.config, tool)
+			require.NoError(t, err)
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+			})
+			if tc.expectedRPCError != nil {
+				var rpcErr *jsonrpc.Error
+				require.ErrorAs(t, err, &rpcErr)
+				assert.Equal(t, tc.expectedRPCError.Code, rpcErr.Code)
+				assert.Equal(t, tc.expectedRPCError.Message, rpcErr.Message)
+				assert.JSONEq(t, string(tc.expectedRPCError.Data), string(rpcErr.Data))
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tc.expectedOutput == nil {
+				assert.Equal(t, tc.expectedText, text)
+			} else {
+				output, ok := strings.CutPrefix(text, tc.expectedText)
+				require.True(t, ok, "the output should follow %q, got %q", tc.expectedText, text)
+				assert.ElementsMatch(t, tc.expectedOutput, strings.Split(strings.TrimSuffix(output, "\n"), "\n"))
+			}
+			detail, ok := invocation.GetErrorDetail(result)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedDetail, detail)
+			if tc.expectedStructured == nil {
+				assert.Nil(t, result.StructuredContent)
+			} else {
+				assert.Equal(t, tc.expectedStructured, result.StructuredContent)
+			}
+		})
+	}
+
+	t.Run("only supported for tools", func(t *testing.T) {
+		resource := &definitions.Resource{Name: "lookup", URI: "lookup://all"}
+		_, err := (&InvokerFactory{}).CreateInvoker(&CliInvocationConfig{
+			Command:   "ls",
+			ErrorMode: ErrorModeProtocol,
+		}, resource)
+		assert.ErrorContains(t, err, "error modes other than result are only supported for tools")
+	})
+}
+
+func TestCliInvokerSecrets(t *testing.T) {
+	store := secrets.NewStore(&secrets.EnvProvider{Prefix: "GENMCP_TEST_SECRET_"})
+	t.Setenv("GENMCP_TEST_SECRET_TOKEN", "s3cr3t")
+
+	tt := []struct {
+		name         string
+		command      string
+		expectedText string
+		errContains  string
+	}{
+		{
+			name:         "secret in command",
+			command:      "echo 'token: {secrets.TOKEN}'",
+			expectedText: "token: s3cr3t\n",
+		},
+		{
+			name:        "missing secret",
+			command:     "echo '{secrets.MISSING}'",
+			errContains: "secret 'MISSING' not found",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &definitions.Tool{Name: "lookup", InputSchema: resolvedEmpty.Schema(), ResolvedInputSchema: resolvedEmpty}
+			invoker, err := (&InvokerFactory{}).CreateInvoker(&CliInvocationConfig{Command: tc.command}, tool)
+			require.NoError(t, err)
+
+			result, err := invoker.Invoke(secrets.WithStore(context.Background(), store), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+			})
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, result.IsError)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -1,9 +1,63 @@
 package cli
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 )
 
+const (
+	// QuotingNone inserts argument values into the command verbatim.
+	QuotingNone = "none"
+	// QuotingShell quotes every argument value as a single shell word.
+	QuotingShell = "shell"
+	// QuotingArgv quotes every argument value as a single word, and runs the command without a shell.
+	QuotingArgv = "argv"
+)
+
+var validQuotings = map[string]bool{
+	QuotingNone:  true,
+	QuotingShell: true,
+	QuotingArgv:  true,
+}
+
+const (
+	// OutputFormatText returns the output of the command as text.
+	OutputFormatText = "text"
+	// OutputFormatJSON parses stdout as a JSON value.
+	OutputFormatJSON = "json"
+	// OutputFormatLines returns every non-empty line of stdout.
+	OutputFormatLines = "lines"
+	// OutputFormatTable parses stdout as a table of whitespace separated columns.
+	OutputFormatTable = "table"
+	// OutputFormatRegex matches every line of stdout against a regular expression with named groups.
+	OutputFormatRegex = "regex"
+)
+
+var validOutputFormats = map[string]bool{
+	OutputFormatText:  true,
+	OutputFormatJSON:  true,
+	OutputFormatLines: true,
+	OutputFormatTable: true,
+	OutputFormatRegex: true,
+}
+
+const (
+	// ErrorModeResult returns failed commands as tool results flagged with isError.
+	ErrorModeResult = "result"
+	// ErrorModeProtocol returns failed commands as MCP protocol errors.
+	ErrorModeProtocol = "protocol"
+)
+
+var validErrorModes = map[string]bool{
+	ErrorModeResult:   true,
+	ErrorModeProtocol: true,
+}
+
 // CliInvocationConfig is the configuration for executing a command-line tool.
 // This is a pure data structure with no parsing logic - all struct tags only.
 type CliInvocationConfig struct {
@@ -13,19 +67,181 @@ type CliInvocationConfig struct {
 	// Defines how input parameters are formatted into the command string.
 	// The map key corresponds to the parameter name from the input schema.
 	TemplateVariables map[string]*TemplateVariable `json:"templateVariables,omitempty" jsonschema:"optional"`
+
+	// How argument values and headers are inserted into the command (default: none).
+	// none inserts them verbatim, so a value like '; rm -rf /' is run by the shell.
+	// shell quotes every value as a single shell word, so it is passed to the command as-is; placeholders must then not be put in quotes.
+	// argv quotes values like shell, but splits the command into arguments and runs it without a shell, so shell operators and '$VAR' references cannot be used.
+	Quoting string `json:"quoting,omitempty" jsonschema:"optional,enum=none,enum=shell,enum=argv"`
+
+	// How the stdout of the command is parsed into the structured content of tool results (default: text).
+	// text returns the output as is. json parses it as a JSON value, wrapped in a 'result' property unless it is an object.
+	// lines returns the non-empty lines in a 'lines' property. table and regex return a 'rows' property with an
+	// object per line, keyed by column names or by the named groups of outputPattern. Only supported for tools.
+	OutputFormat string `json:"outputFormat,omitempty" jsonschema:"optional,enum=text,enum=json,enum=lines,enum=table,enum=regex"`
+
+	// The regular expression matched against every line of stdout for the regex output format.
+	// Its named groups (e.g. '(?P<name>\w+)') become the properties of the rows. Lines that do not match are skipped.
+	OutputPattern string `json:"outputPattern,omitempty" jsonschema:"optional"`
+
+	// The names of the columns for the table output format. If unset, the first line of stdout is used as the header.
+	// The last column holds the rest of the line, so it can contain whitespace.
+	OutputColumns []string `json:"outputColumns,omitempty" jsonschema:"optional"`
+
+	// How failed commands, e.g. commands exiting with a non-zero exit code, are reported to the client (default: result).
+	// result returns a tool result flagged with isError, whose structured content holds the exitCode, stdout and stderr
+	// of the command. protocol returns an MCP protocol error instead, with the same properties as its data.
+	// Only supported for tools.
+	ErrorMode string `json:"errorMode,omitempty" jsonschema:"optional,enum=result,enum=protocol"`
+
+	// The executables the command may run (e.g. 'git' or '/usr/bin/git'). If set, commands that run any other
+	// executable, or that use command substitution, are rejected before they are executed.
+	// The allowed executables must not run commands taken from their own arguments (e.g. 'env' or 'xargs').
+	AllowedExecutables []string `json:"allowedExecutables,omitempty" jsonschema:"optional"`
+
+	// The directory the command runs in. If set, arguments that are absolute paths or that contain '..'
+	// are rejected, so that they cannot reference files outside of it.
+	WorkingDir string `json:"workingDir,omitempty" jsonschema:"optional"`
+
+	// The names of the server's environment variables passed to the command. If unset, the command
+	// inherits the whole environment of the server.
+	AllowedEnv []string `json:"allowedEnv,omitempty" jsonschema:"optional"`
+
+	// The maximum execution time of the command, as a duration string (e.g. "30s"). The command is killed
+	// when it is exceeded. No timeout if unset.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+
+	// The maximum number of bytes of output (stdout and stderr combined) of the command. The command is killed
+	// when it is exceeded. No limit if unset.
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty" jsonschema:"optional"`
+
+	// The time a command has to exit after it is sent SIGTERM, when the tool call is cancelled or times out,
+	// before it is killed with SIGKILL, as a duration string (default: 5s). The signals are sent to all the
+	// processes started by the command. "0s" kills the command right away.
+	KillGracePeriod string `json:"killGracePeriod,omitempty" jsonschema:"optional"`
+
+	// How often the elapsed time and the size of the output of the command are sent to clients that ask for
+	// the progress of tool calls, as a duration string (default: 5s).
+	ProgressInterval string `json:"progressInterval,omitempty" jsonschema:"optional"`
+
+	// The regular expression matched against every line of the output of the command (stdout and stderr) to
+	// report its progress instead, e.g. '(?P<progress>\d+)%'. Matching lines are sent as progress notifications,
+	// with the number of the group named progress, and of the group named total if any. Only supported for tools.
+	ProgressPattern string `json:"progressPattern,omitempty" jsonschema:"optional"`
 }
 
 var _ invocation.InvocationConfig = &CliInvocationConfig{}
 
 func (c *CliInvocationConfig) Validate() error {
-	// Validation is handled during template parsing
+	// Validation of the command is handled during template parsing
+
+	if c.Quoting != "" && !validQuotings[c.Quoting] {
+		return fmt.Errorf("invalid quoting '%s': must be one of none, shell, argv", c.Quoting)
+	}
+
+	if c.OutputFormat != "" && !validOutputFormats[c.OutputFormat] {
+		return fmt.Errorf("invalid outputFormat '%s': must be one of text, json, lines, table, regex", c.OutputFormat)
+	}
+
+	if c.OutputFormat == OutputFormatRegex {
+		if c.OutputPattern == "" {
+			return fmt.Errorf("outputPattern is required for the regex output format")
+		}
+		pattern, err := regexp.Compile(c.OutputPattern)
+		if err != nil {
+			return fmt.Errorf("invalid outputPattern: %w", err)
+		}
+		if !slices.ContainsFunc(pattern.SubexpNames(), func(name string) bool { return name != "" }) {
+			return fmt.Errorf("outputPattern must contain named groups, e.g. '(?P<name>\\w+)'")
+		}
+	} else if c.OutputPattern != "" {
+		return fmt.Errorf("outputPattern can only be set for the regex output format")
+	}
+
+	if len(c.OutputColumns) > 0 && c.OutputFormat != OutputFormatTable {
+		return fmt.Errorf("outputColumns can only be set for the table output format")
+	}
+
+	if c.ErrorMode != "" && !validErrorModes[c.ErrorMode] {
+		return fmt.Errorf("invalid errorMode '%s': must be one of result, protocol", c.ErrorMode)
+	}
+
+	for _, executable := range c.AllowedExecutables {
+		if strings.TrimSpace(executable) == "" || strings.ContainsAny(executable, " \t\n") {
+			return fmt.Errorf("invalid allowed executable '%s': must be a name or path without whitespace", executable)
+		}
+	}
+
+	for _, name := range c.AllowedEnv {
+		if name == "" || strings.Contains(name, "=") {
+			return fmt.Errorf("invalid allowed environment variable '%s'", name)
+		}
+	}
+
+	if c.Timeout != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout '%s': %w", c.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+	}
+
+	if c.MaxOutputBytes < 0 {
+		return fmt.Errorf("maxOutputBytes must not be negative")
+	}
+
+	if c.KillGracePeriod != "" {
+		d, err := time.ParseDuration(c.KillGracePeriod)
+		if err != nil {
+			return fmt.Errorf("invalid killGracePeriod '%s': %w", c.KillGracePeriod, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("killGracePeriod must not be negative")
+		}
+	}
+
+	if c.ProgressInterval != "" {
+		d, err := time.ParseDuration(c.ProgressInterval)
+		if err != nil {
+			return fmt.Errorf("invalid progressInterval '%s': %w", c.ProgressInterval, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("progressInterval must be positive")
+		}
+	}
+
+	if c.ProgressPattern != "" {
+		pattern, err := regexp.Compile(c.ProgressPattern)
+		if err != nil {
+			return fmt.Errorf("invalid progressPattern: %w", err)
+		}
+		if pattern.SubexpIndex("progress") < 0 {
+			return fmt.Errorf("progressPattern must contain a group named progress, e.g. '(?P<progress>\\d+)%%'")
+		}
+	}
+
 	return nil
 }
 
 func (c *CliInvocationConfig) DeepCopy() invocation.InvocationConfig {
 	cp := &CliInvocationConfig{
-		Command:           c.Command,
-		TemplateVariables: make(map[string]*TemplateVariable, len(c.TemplateVariables)),
+		Command:            c.Command,
+		TemplateVariables:  make(map[string]*TemplateVariable, len(c.TemplateVariables)),
+		Quoting:            c.Quoting,
+		OutputFormat:       c.OutputFormat,
+		OutputPattern:      c.OutputPattern,
+		OutputColumns:      slices.Clone(c.OutputColumns),
+		ErrorMode:          c.ErrorMode,
+		AllowedExecutables: slices.Clone(c.AllowedExecutables),
+		WorkingDir:         c.WorkingDir,
+		AllowedEnv:         slices.Clone(c.AllowedEnv),
+		Timeout:            c.Timeout,
+		MaxOutputBytes:     c.MaxOutputBytes,
+		KillGracePeriod:    c.KillGracePeriod,
+		ProgressInterval:   c.ProgressInterval,
+		ProgressPattern:    c.ProgressPattern,
 	}
 	for k, v := range c.TemplateVariables {
 		cp.TemplateVariables[k] = v.DeepCopy()
@@ -2,6 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/template"
@@ -20,12 +23,44 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		return nil, fmt.Errorf("invalid InvocationConfig for cli invoker factory")
 	}
 
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for cli invocations")
+	}
+
 	// Create source factories for template parsing
-	sources := template.CreateHeadersSourceFactory()
+	sources := template.CreateSourceFactories()
+
+	quoting := cic.Quoting
+	if quoting == "" {
+		quoting = QuotingNone
+	}
+	var quote func(string) string
+	if quoting != QuotingNone {
+		quote = shellQuote
+	}
+
+	if quoting == QuotingArgv {
+		if _, err := splitArgv(withoutVariables(cic.Command)); err != nil {
+			return nil, fmt.Errorf("invalid command for argv quoting: %w", err)
+		}
+		for tvName, tv := range cic.TemplateVariables {
+			if _, err := splitArgv(withoutVariables(tv.Template)); err != nil {
+				return nil, fmt.Errorf("invalid format of template variable '%s' for argv quoting: %w", tvName, err)
+			}
+		}
+	}
 
 	formatters := make(map[string]template.VariableFormatter)
 	for tvName, tv := range cic.TemplateVariables {
-		formatter, err := template.NewTemplateFormatter(tv.Template, primitive.GetInputSchema(), tv.OmitIfFalse, sources)
+		formatter, err := template.NewTemplateFormatterWithOptions(tv.Template, template.TemplateParserOptions{
+			InputSchema: primitive.GetInputSchema(),
+			Sources:     sources,
+			Quote:       quote,
+			Tool:        invocation.ToolName(primitive),
+			Allowlist:   template.PrimitiveAllowlist(primitive),
+			// exploded arrays are passed as separate arguments
+			ExplodeSeparator: " ",
+		}, tv.OmitIfFalse)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create template formatter for '%s': %w", tvName, err)
 		}
@@ -33,9 +68,13 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 	}
 
 	parsedTemplate, err := template.ParseTemplate(cic.Command, template.TemplateParserOptions{
-		InputSchema: primitive.GetInputSchema(),
-		Formatters:  formatters,
-		Sources:     sources,
+		InputSchema:      primitive.GetInputSchema(),
+		Formatters:       formatters,
+		Sources:          sources,
+		Quote:            quote,
+		Tool:             invocation.ToolName(primitive),
+		Allowlist:        template.PrimitiveAllowlist(primitive),
+		ExplodeSeparator: " ",
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse command template: %w", err)
@@ -55,9 +94,92 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		}
 	}
 
+	if cic.WorkingDir != "" {
+		info, err := os.Stat(cic.WorkingDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid working directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("invalid working directory: '%s' is not a directory", cic.WorkingDir)
+		}
+	}
+
+	outputFormat := cic.OutputFormat
+	if outputFormat == "" {
+		outputFormat = OutputFormatText
+	}
+	if outputFormat != OutputFormatText && primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("output formats other than text are only supported for tools")
+	}
+
+	errorMode := cic.ErrorMode
+	if errorMode == "" {
+		errorMode = ErrorModeResult
+	}
+	if errorMode != ErrorModeResult && primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("error modes other than result are only supported for tools")
+	}
+
+	var outputPattern *regexp.Regexp
+	if cic.OutputPattern != "" {
+		outputPattern, err = regexp.Compile(cic.OutputPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid outputPattern: %w", err)
+		}
+	}
+
+	var timeout time.Duration
+	if cic.Timeout != "" {
+		timeout, err = time.ParseDuration(cic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", cic.Timeout, err)
+		}
+	}
+
+	var killGracePeriod *time.Duration
+	if cic.KillGracePeriod != "" {
+		d, err := time.ParseDuration(cic.KillGracePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid killGracePeriod '%s': %w", cic.KillGracePeriod, err)
+		}
+		killGracePeriod = &d
+	}
+
+	var progressInterval time.Duration
+	if cic.ProgressInterval != "" {
+		progressInterval, err = time.ParseDuration(cic.ProgressInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid progressInterval '%s': %w", cic.ProgressInterval, err)
+		}
+	}
+
+	var progressPattern *regexp.Regexp
+	if cic.ProgressPattern != "" {
+		if primitive.PrimitiveType() != "tool" {
+			return nil, fmt.Errorf("progressPattern is only supported for tools")
+		}
+		progressPattern, err = regexp.Compile(cic.ProgressPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid progressPattern: %w", err)
+		}
+	}
+
 	return &CliInvoker{
-		ParsedTemplate: parsedTemplate,
-		InputSchema:    primitive.GetResolvedInputSchema(),
-		URITemplate:    uriTemplate,
+		ParsedTemplate:     parsedTemplate,
+		InputSchema:        primitive.GetResolvedInputSchema(),
+		URITemplate:        uriTemplate,
+		Quoting:            quoting,
+		OutputFormat:       outputFormat,
+		OutputPattern:      outputPattern,
+		OutputColumns:      cic.OutputColumns,
+		ErrorMode:          errorMode,
+		AllowedExecutables: cic.AllowedExecutables,
+		WorkingDir:         cic.WorkingDir,
+		AllowedEnv:         cic.AllowedEnv,
+		Timeout:            timeout,
+		MaxOutputBytes:     cic.MaxOutputBytes,
+		KillGracePeriod:    killGracePeriod,
+		ProgressInterval:   progressInterval,
+		ProgressPattern:    progressPattern,
 	}, nil
 }
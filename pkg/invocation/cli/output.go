@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseOutput parses the stdout of a command into the structured content of a tool result,
+// according to the output format of the invoker.
+func (ci *CliInvoker) parseOutput(stdout []byte) (map[string]any, error) {
+	switch ci.OutputFormat {
+	case OutputFormatJSON:
+		return parseJSONOutput(stdout)
+	case OutputFormatLines:
+		return map[string]any{"lines": outputLines(stdout)}, nil
+	case OutputFormatTable:
+		return parseTableOutput(stdout, ci.OutputColumns)
+	case OutputFormatRegex:
+		return ci.parseRegexOutput(stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown output format '%s'", ci.OutputFormat)
+	}
+}
+
+// parseJSONOutput parses stdout as a JSON value. Values other than objects are wrapped in a 'result'
+// property, as structured content must be an object.
+func parseJSONOutput(stdout []byte) (map[string]any, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(stdout))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("output contains more than one JSON value")
+	}
+
+	if obj, ok := value.(map[string]any); ok {
+		return obj, nil
+	}
+	return map[string]any{"result": value}, nil
+}
+
+// outputLines returns the non-empty lines of stdout, without trailing whitespace.
+func outputLines(stdout []byte) []string {
+	lines := []string{}
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseTableOutput parses stdout as a table of whitespace separated columns, returning an object
+// per row. The column names are read from the first line if none are given. The last column holds
+// the rest of the line, and rows with fewer columns than the header leave the trailing ones unset.
+func parseTableOutput(stdout []byte, columns []string) (map[string]any, error) {
+	lines := outputLines(stdout)
+	if len(columns) == 0 {
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("output has no header line")
+		}
+		columns = strings.Fields(lines[0])
+		lines = lines[1:]
+	}
+
+	rows := make([]any, 0, len(lines))
+	for _, line := range lines {
+		fields := splitFields(line, len(columns))
+		row := make(map[string]any, len(fields))
+		for i, field := range fields {
+			row[columns[i]] = field
+		}
+		rows = append(rows, row)
+	}
+
+	return map[string]any{"rows": rows}, nil
+}
+
+// splitFields splits s into at most n whitespace separated fields, the last one holding the rest of s.
+func splitFields(s string, n int) []string {
+	fields := make([]string, 0, n)
+	s = strings.TrimSpace(s)
+	for s != "" && len(fields) < n-1 {
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			break
+		}
+		fields = append(fields, s[:end])
+		s = strings.TrimLeft(s[end:], " \t")
+	}
+	if s != "" {
+		fields = append(fields, s)
+	}
+	return fields
+}
+
+// parseRegexOutput matches every line of stdout against the output pattern, returning an object per
+// matching line with the values of the named groups. Groups that did not participate in the match are unset.
+func (ci *CliInvoker) parseRegexOutput(stdout []byte) map[string]any {
+	names := ci.OutputPattern.SubexpNames()
+
+	rows := []any{}
+	for _, line := range outputLines(stdout) {
+		match := ci.OutputPattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+
+		row := make(map[string]any)
+		for i, name := range names {
+			if name == "" || match[2*i] < 0 {
+				continue
+			}
+			row[name] = line[match[2*i]:match[2*i+1]]
+		}
+		rows = append(rows, row)
+	}
+
+	return map[string]any{"rows": rows}
+}
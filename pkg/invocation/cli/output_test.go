@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutput(t *testing.T) {
+	tt := []struct {
+		name        string
+		invoker     *CliInvoker
+		stdout      string
+		expected    map[string]any
+		errContains string
+	}{
+		{
+			name:     "json object",
+			invoker:  &CliInvoker{OutputFormat: OutputFormatJSON},
+			stdout:   `{"name": "gen-mcp", "stars": 42}` + "\n",
+			expected: map[string]any{"name": "gen-mcp", "stars": json.Number("42")},
+		},
+		{
+			name:     "json array is wrapped",
+			invoker:  &CliInvoker{OutputFormat: OutputFormatJSON},
+			stdout:   `["a", "b"]`,
+			expected: map[string]any{"result": []any{"a", "b"}},
+		},
+		{
+			name:        "invalid json",
+			invoker:     &CliInvoker{OutputFormat: OutputFormatJSON},
+			stdout:      "not json",
+			errContains: "invalid character",
+		},
+		{
+			name:        "several json values",
+			invoker:     &CliInvoker{OutputFormat: OutputFormatJSON},
+			stdout:      "{}\n{}\n",
+			errContains: "output contains more than one JSON value",
+		},
+		{
+			name:     "lines",
+			invoker:  &CliInvoker{OutputFormat: OutputFormatLines},
+			stdout:   "main\r\n\n  feature/x  \n",
+			expected: map[string]any{"lines": []string{"main", "  feature/x"}},
+		},
+		{
+			name:    "table with header",
+			invoker: &CliInvoker{OutputFormat: OutputFormatTable},
+			stdout:  "PID   TTY   CMD\n1     ?     /sbin/init splash\n42    pts/0\n",
+			expected: map[string]any{"rows": []any{
+				map[string]any{"PID": "1", "TTY": "?", "CMD": "/sbin/init splash"},
+				map[string]any{"PID": "42", "TTY": "pts/0"},
+			}},
+		},
+		{
+			name:    "table with columns",
+			invoker: &CliInvoker{OutputFormat: OutputFormatTable, OutputColumns: []string{"size", "path"}},
+			stdout:  "4.0K\tdocs/my notes.md\n",
+			expected: map[string]any{"rows": []any{
+				map[string]any{"size": "4.0K", "path": "docs/my notes.md"},
+			}},
+		},
+		{
+			name:        "table without header",
+			invoker:     &CliInvoker{OutputFormat: OutputFormatTable},
+			stdout:      "\n",
+			errContains: "output has no header line",
+		},
+		{
+			name: "regex",
+			invoker: &CliInvoker{
+				OutputFormat:  OutputFormatRegex,
+				OutputPattern: regexp.MustCompile(`^(?P<hash>[0-9a-f]+) (?P<subject>.*?)(?: \((?P<ref>.+)\))?$`),
+			},
+			stdout: "a1b2 Fix bug (HEAD -> main)\nnot a commit!\nc3d4 Initial commit\n",
+			expected: map[string]any{"rows": []any{
+				map[string]any{"hash": "a1b2", "subject": "Fix bug", "ref": "HEAD -> main"},
+				map[string]any{"hash": "c3d4", "subject": "Initial commit"},
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.invoker.parseOutput([]byte(tc.stdout))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestCliInvokerOutputFormat(t *testing.T) {
+	t.Run("structured content from stdout", func(t *testing.T) {
+		// braces in the command are placeholders, so the JSON output is read from the environment
+		t.Setenv("GENMCP_TEST_OUTPUT", `{"go": "1.25"}`)
+		tool := &definitions.Tool{Name: "versions", InputSchema: resolvedEmpty.Schema(), ResolvedInputSchema: resolvedEmpty}
+		invoker, err := (&InvokerFactory{}).CreateInvoker(&CliInvocationConfig{
+			Command:      `echo "warning: ignored" >&2; echo "$GENMCP_TEST_OUTPUT"`,
+			OutputFormat: OutputFormatJSON,
+		}, tool)
+		require.NoError(t, err)
+
+		result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		assert.Equal(t, map[string]any{"go": "1.25"}, result.StructuredContent)
+		assert.Equal(t, `{"go":"1.25"}`, result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("unparsable output is an error result", func(t *testing.T) {
+		tool := &definitions.Tool{Name: "versions", InputSchema: resolvedEmpty.Schema(), ResolvedInputSchema: resolvedEmpty}
+		invoker, err := (&InvokerFactory{}).CreateInvoker(&CliInvocationConfig{
+			Command:      "echo go 1.25",
+			OutputFormat: OutputFormatJSON,
+		}, tool)
+		require.NoError(t, err)
+
+		result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+		})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "failed to parse command output as json")
+	})
+
+	t.Run("only supported for tools", func(t *testing.T) {
+		resource := &definitions.Resource{Name: "versions", URI: "versions://go"}
+		_, err := (&InvokerFactory{}).CreateInvoker(&CliInvocationConfig{
+			Command:      "go version",
+			OutputFormat: OutputFormatLines,
+		}, resource)
+		assert.ErrorContains(t, err, "output formats other than text are only supported for tools")
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package cli
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setProcessGroup is a no-op on platforms without process groups: only the command itself is killed when
+// it is cancelled, without grace period.
+func setProcessGroup(cmd *exec.Cmd, gracePeriod time.Duration) {}
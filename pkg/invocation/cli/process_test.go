@@ -0,0 +1,70 @@
+//go:build unix
+
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCliInvokerCancellation(t *testing.T) {
+	tt := []struct {
+		name            string
+		command         string
+		killGracePeriod time.Duration
+		expectedOutput  string
+		minDuration     time.Duration
+		maxDuration     time.Duration
+	}{
+		{
+			name:           "processes started by the command are stopped with it",
+			command:        "sleep 30 & sleep 30; wait",
+			expectedOutput: "Command execution failed:\n",
+			maxDuration:    2 * time.Second,
+		},
+		{
+			name:           "command can clean up before it exits",
+			command:        "trap 'echo cleaning up; exit 1' TERM; sleep 30 & wait",
+			expectedOutput: "Command execution failed with exit code 1:\ncleaning up\n",
+			maxDuration:    2 * time.Second,
+		},
+		{
+			name:            "command ignoring SIGTERM is killed after the grace period",
+			command:         "trap '' TERM; sleep 30",
+			killGracePeriod: 300 * time.Millisecond,
+			expectedOutput:  "Command execution failed:\n",
+			minDuration:     500 * time.Millisecond,
+			maxDuration:     2 * time.Second,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testCliInvoker(t, tc.command, resolvedEmpty, "")
+			if tc.killGracePeriod > 0 {
+				invoker.KillGracePeriod = &tc.killGracePeriod
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(200*time.Millisecond, cancel)
+
+			started := time.Now()
+			result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+			})
+			require.NoError(t, err)
+			elapsed := time.Since(started)
+			assert.GreaterOrEqual(t, elapsed, tc.minDuration)
+			assert.Less(t, elapsed, tc.maxDuration)
+
+			assert.True(t, result.IsError)
+			require.NotEmpty(t, result.Content)
+			assert.Equal(t, tc.expectedOutput, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
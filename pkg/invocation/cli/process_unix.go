@@ -0,0 +1,29 @@
+//go:build unix
+
+package cli
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup runs cmd in a process group of its own. When cmd is cancelled, the whole group is sent
+// SIGTERM, and SIGKILL if it is still running after gracePeriod, so that the processes started by the command
+// (e.g. the git of 'bash -c "git clone ..."') are stopped with it. The group is killed right away if
+// gracePeriod is zero.
+func setProcessGroup(cmd *exec.Cmd, gracePeriod time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		group := -cmd.Process.Pid
+		if gracePeriod <= 0 {
+			return syscall.Kill(group, syscall.SIGKILL)
+		}
+
+		// processes of the group may outlive the command, so the group is killed even if the command exits
+		time.AfterFunc(gracePeriod, func() {
+			_ = syscall.Kill(group, syscall.SIGKILL)
+		})
+		return syscall.Kill(group, syscall.SIGTERM)
+	}
+}
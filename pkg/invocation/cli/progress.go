@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// defaultProgressInterval is how often the progress of a running command is reported, if not configured.
+const defaultProgressInterval = 5 * time.Second
+
+// maxProgressLineLength bounds the size of the incomplete line buffered by a progressLineWriter.
+const maxProgressLineLength = 64 * 1024
+
+// progressNotifier sends progress notifications to the client of a request, e.g. an *mcp.ServerSession.
+type progressNotifier interface {
+	NotifyProgress(ctx context.Context, params *mcp.ProgressNotificationParams) error
+}
+
+// progressReporter sends MCP progress notifications while a command runs. Without a pattern, a notification
+// with the elapsed time and the size of the output is sent every interval. With a pattern, the lines of the
+// output matching it are sent instead, with the progress and total numbers they contain.
+type progressReporter struct {
+	notifier progressNotifier
+	token    any
+	pattern  *regexp.Regexp
+	interval time.Duration
+
+	mu   sync.Mutex
+	last float64 // progress of the last notification, as progress must increase with every notification
+}
+
+// newProgressReporter returns the progressReporter of a call of the tool, or nil if the client did not ask
+// for the progress of the call.
+func (ci *CliInvoker) newProgressReporter(req *mcp.CallToolRequest) *progressReporter {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+
+	interval := ci.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	return &progressReporter{
+		notifier: req.Session,
+		token:    token,
+		pattern:  ci.ProgressPattern,
+		interval: interval,
+		last:     -1,
+	}
+}
+
+// start reports the progress of a command writing its output to out, until the returned function is called.
+func (pr *progressReporter) start(ctx context.Context, out *outputBuffer) (stop func()) {
+	if pr.pattern != nil {
+		return func() {}
+	}
+
+	started := time.Now()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(pr.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				elapsed := time.Since(started).Truncate(time.Second)
+				pr.notify(ctx, elapsed.Seconds(), 0,
+					fmt.Sprintf("running for %s, %d bytes of output", elapsed, out.len()))
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// lineWriter returns a writer matching the lines of an output of a command against the pattern of pr.
+func (pr *progressReporter) lineWriter(ctx context.Context) *progressLineWriter {
+	return &progressLineWriter{ctx: ctx, reporter: pr}
+}
+
+// matchLine sends a notification for line if it matches the pattern.
+func (pr *progressReporter) matchLine(ctx context.Context, line string) {
+	match := pr.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+
+	var progress, total float64
+	if i := pr.pattern.SubexpIndex("progress"); i >= 0 {
+		progress, _ = strconv.ParseFloat(match[i], 64)
+	}
+	if i := pr.pattern.SubexpIndex("total"); i >= 0 {
+		total, _ = strconv.ParseFloat(match[i], 64)
+	}
+
+	pr.notify(ctx, progress, total, line)
+}
+
+// notify sends a progress notification, unless progress did not increase since the last one.
+func (pr *progressReporter) notify(ctx context.Context, progress, total float64, message string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if progress <= pr.last {
+		return
+	}
+	pr.last = progress
+
+	err := pr.notifier.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: pr.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		logging.BaseFromContext(ctx).Warn("Failed to send progress notification for CLI command", zap.Error(err))
+	}
+}
+
+// progressLineWriter splits an output of a command into lines, terminated by a newline or a carriage return
+// as used by progress bars, and reports the progress they contain.
+type progressLineWriter struct {
+	ctx      context.Context
+	reporter *progressReporter
+	line     []byte
+}
+
+func (w *progressLineWriter) Write(p []byte) (int, error) {
+	data := append(w.line, p...)
+	for {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			w.reporter.matchLine(w.ctx, string(data[:i]))
+		}
+		data = data[i+1:]
+	}
+
+	// long lines without terminator can't be progress lines
+	if len(data) > maxProgressLineLength {
+		data = nil
+	}
+	w.line = append(w.line[:0], data...)
+
+	return len(p), nil
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []*mcp.ProgressNotificationParams
+}
+
+func (n *recordingNotifier) NotifyProgress(_ context.Context, params *mcp.ProgressNotificationParams) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.notifications = append(n.notifications, params)
+	return nil
+}
+
+func (n *recordingNotifier) recorded() []*mcp.ProgressNotificationParams {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]*mcp.ProgressNotificationParams(nil), n.notifications...)
+}
+
+func TestProgressLineWriter(t *testing.T) {
+	tt := []struct {
+		name     string
+		pattern  string
+		writes   []string
+		expected []*mcp.ProgressNotificationParams
+	}{
+		{
+			name:    "percentages of a progress bar",
+			pattern: `Receiving objects:\s+(?P<progress>\d+)%`,
+			writes:  []string{"Cloning into 'repo'...\n", "Receiving objects:  10% (1/10)\r", "Receiving objects:  60% (6/10)\r", "Receiving objects: 100% (10/10), done.\n"},
+			expected: []*mcp.ProgressNotificationParams{
+				{ProgressToken: "token", Progress: 10, Message: "Receiving objects:  10% (1/10)"},
+				{ProgressToken: "token", Progress: 60, Message: "Receiving objects:  60% (6/10)"},
+				{ProgressToken: "token", Progress: 100, Message: "Receiving objects: 100% (10/10), done."},
+			},
+		},
+		{
+			name:    "progress and total",
+			pattern: `(?P<progress>\d+)/(?P<total>\d+) files`,
+			writes:  []string{"1/3 files\n2/3 files\n", "3/3 files\n"},
+			expected: []*mcp.ProgressNotificationParams{
+				{ProgressToken: "token", Progress: 1, Total: 3, Message: "1/3 files"},
+				{ProgressToken: "token", Progress: 2, Total: 3, Message: "2/3 files"},
+				{ProgressToken: "token", Progress: 3, Total: 3, Message: "3/3 files"},
+			},
+		},
+		{
+			name:    "lines split across writes",
+			pattern: `(?P<progress>\d+)%`,
+			writes:  []string{"downloaded 4", "2% of the", " archive\n"},
+			expected: []*mcp.ProgressNotificationParams{
+				{ProgressToken: "token", Progress: 42, Message: "downloaded 42% of the archive"},
+			},
+		},
+		{
+			name:    "progress that does not increase is skipped",
+			pattern: `(?P<progress>\d+)%`,
+			writes:  []string{"compressing 50%\n", "compressing 100%\n", "receiving 20%\n"},
+			expected: []*mcp.ProgressNotificationParams{
+				{ProgressToken: "token", Progress: 50, Message: "compressing 50%"},
+				{ProgressToken: "token", Progress: 100, Message: "compressing 100%"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			reporter := &progressReporter{
+				notifier: notifier,
+				token:    "token",
+				pattern:  regexp.MustCompile(tc.pattern),
+				last:     -1,
+			}
+
+			w := reporter.lineWriter(context.Background())
+			for _, write := range tc.writes {
+				n, err := w.Write([]byte(write))
+				require.NoError(t, err)
+				assert.Equal(t, len(write), n)
+			}
+
+			assert.Equal(t, tc.expected, notifier.recorded())
+		})
+	}
+}
+
+func TestCliInvokerProgress(t *testing.T) {
+	tt := []struct {
+		name    string
+		command string
+		pattern string
+		check   func(t *testing.T, notifications []*mcp.ProgressNotificationParams)
+	}{
+		{
+			name:    "elapsed time and output size",
+			command: "echo started; sleep 0.3",
+			check: func(t *testing.T, notifications []*mcp.ProgressNotificationParams) {
+				require.NotEmpty(t, notifications)
+				assert.True(t, strings.HasPrefix(notifications[0].Message, "running for "), notifications[0].Message)
+				assert.Contains(t, notifications[0].Message, "8 bytes of output")
+			},
+		},
+		{
+			name:    "progress lines of stdout and stderr",
+			command: "echo 'step 1/2'; echo 'step 2/2' >&2",
+			pattern: `step (?P<progress>\d+)/(?P<total>\d+)`,
+			check: func(t *testing.T, notifications []*mcp.ProgressNotificationParams) {
+				assert.Equal(t, []*mcp.ProgressNotificationParams{
+					{ProgressToken: "token", Progress: 1, Total: 2, Message: "step 1/2"},
+					{ProgressToken: "token", Progress: 2, Total: 2, Message: "step 2/2"},
+				}, notifications)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testCliInvoker(t, tc.command, resolvedEmpty, "")
+			notifier := &recordingNotifier{}
+			reporter := &progressReporter{
+				notifier: notifier,
+				token:    "token",
+				interval: 50 * time.Millisecond,
+				last:     -1,
+			}
+			if tc.pattern != "" {
+				reporter.pattern = regexp.MustCompile(tc.pattern)
+			}
+
+			_, err := invoker.executeCommand(context.Background(), tc.command, nil, reporter)
+			require.NoError(t, err)
+
+			tc.check(t, notifier.recorded())
+		})
+	}
+}
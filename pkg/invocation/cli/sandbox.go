@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// sandboxError is returned for commands that are rejected or stopped because of the sandbox settings
+// of the invocation, as opposed to commands that fail on their own.
+type sandboxError struct {
+	err error
+}
+
+func (e *sandboxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sandboxError) Unwrap() error {
+	return e.err
+}
+
+// failureOutput returns the text describing a failed command execution to the client: the output of
+// the command, preceded by the reason when the sandbox rejected or stopped the command.
+func failureOutput(output []byte, err error) string {
+	var se *sandboxError
+	if !errors.As(err, &se) {
+		return string(output)
+	}
+	if len(output) == 0 {
+		return se.Error()
+	}
+	return se.Error() + "\n" + string(output)
+}
+
+// checkExecutables returns an error if the command runs an executable that is not allowed.
+// Every command is allowed if no executables are configured.
+func (ci *CliInvoker) checkExecutables(command string) error {
+	if len(ci.AllowedExecutables) == 0 {
+		return nil
+	}
+
+	executables, err := commandExecutables(command)
+	if err != nil {
+		return &sandboxError{invocation.Errorf(invocation.ErrorCodeValidation, "command rejected: %w", err)}
+	}
+
+	for _, executable := range executables {
+		if !slices.Contains(ci.AllowedExecutables, executable) {
+			return &sandboxError{invocation.Errorf(invocation.ErrorCodeValidation, "command rejected: executable '%s' is not allowed", executable)}
+		}
+	}
+
+	return nil
+}
+
+// checkArgumentPaths returns an error if a string argument could reference a file outside of the
+// working directory. Arguments are not checked if no working directory is configured.
+func (ci *CliInvoker) checkArgumentPaths(args map[string]any) error {
+	if ci.WorkingDir == "" {
+		return nil
+	}
+
+	for name, value := range args {
+		if escapesWorkingDir(value) {
+			return fmt.Errorf("argument '%s' must not reference paths outside of the working directory", name)
+		}
+	}
+
+	return nil
+}
+
+// escapesWorkingDir reports whether value, or any string nested in it, contains an absolute path,
+// a path relative to the home directory, or a path containing '..'. Strings are split on whitespace
+// and on the separators of flags and lists first, so that values like '--file=/etc/passwd' are detected.
+func escapesWorkingDir(value any) bool {
+	switch v := value.(type) {
+	case string:
+		tokens := strings.FieldsFunc(v, func(r rune) bool {
+			return strings.ContainsRune(" \t\n=:,;", r)
+		})
+		for _, token := range tokens {
+			if filepath.IsAbs(token) || strings.HasPrefix(token, "/") || strings.HasPrefix(token, "~") {
+				return true
+			}
+			if slices.Contains(strings.FieldsFunc(token, func(r rune) bool {
+				return r == '/' || r == filepath.Separator
+			}), "..") {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(v, escapesWorkingDir)
+	case map[string]any:
+		for _, item := range v {
+			if escapesWorkingDir(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// commandEnv returns the environment of the command, or nil to inherit the environment of the server.
+func (ci *CliInvoker) commandEnv() []string {
+	if ci.AllowedEnv == nil {
+		return nil
+	}
+
+	env := []string{}
+	for _, name := range ci.AllowedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	return env
+}
+
+// outputBuffer collects the combined output of a command, and its stdout and stderr separately. Once
+// more than max bytes are written, it stops collecting and cancels the command, while still accepting
+// writes so that the command does not block. It is the stderr of the command, and stdoutWriter its stdout.
+type outputBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	max      int // no limit if zero
+	exceeded bool
+	cancel   context.CancelFunc
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	return b.write(p, false)
+}
+
+func (b *outputBuffer) write(p []byte, isStdout bool) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.exceeded {
+		return len(p), nil
+	}
+
+	collected := p
+	if b.max > 0 && b.buf.Len()+len(p) > b.max {
+		collected = p[:b.max-b.buf.Len()]
+		b.exceeded = true
+		b.cancel()
+	}
+
+	b.buf.Write(collected)
+	if isStdout {
+		b.stdout.Write(collected)
+	} else {
+		b.stderr.Write(collected)
+	}
+
+	return len(p), nil
+}
+
+// len returns the number of bytes of output collected.
+func (b *outputBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Len()
+}
+
+// stdoutWriter writes the stdout of a command to an outputBuffer.
+type stdoutWriter struct {
+	*outputBuffer
+}
+
+func (w stdoutWriter) Write(p []byte) (int, error) {
+	return w.write(p, true)
+}
+
+// commandExecutables returns the executables run by a bash command, i.e. the first word of every
+// simple command, skipping variable assignments and redirections.
+// It returns an error for command, process, or arithmetic substitutions, whose commands cannot be
+// checked without executing them.
+func commandExecutables(command string) ([]string, error) {
+	tokens, err := lexCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var executables []string
+	commandStart := true
+	redirectTarget := false // the next word is the target of a redirection
+	for _, token := range tokens {
+		switch {
+		case token.isRedirect():
+			redirectTarget = true
+		case token.operator != "":
+			commandStart = true
+		case redirectTarget:
+			redirectTarget = false
+		case commandStart && isAssignment(token.word):
+		case commandStart:
+			executables = append(executables, token.word)
+			commandStart = false
+		}
+	}
+
+	return executables, nil
+}
+
+// isAssignment reports whether a word is a variable assignment (NAME=value) preceding a command.
+func isAssignment(word string) bool {
+	name, _, found := strings.Cut(word, "=")
+	if !found || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandExecutables(t *testing.T) {
+	tt := []struct {
+		name                string
+		command             string
+		expectedExecutables []string
+		errContains         string
+	}{
+		{
+			name:                "simple command",
+			command:             "git status --short",
+			expectedExecutables: []string{"git"},
+		},
+		{
+			name:                "lists and pipelines",
+			command:             "git log | head -n 5 && echo done; ls\nwc -l",
+			expectedExecutables: []string{"git", "head", "echo", "ls", "wc"},
+		},
+		{
+			name:                "operators in quotes",
+			command:             `echo 'a; rm -rf /' "b | cat" c\;d`,
+			expectedExecutables: []string{"echo"},
+		},
+		{
+			name:                "assignments and redirections",
+			command:             "LANG=C 2>/dev/null sort < input.txt > output.txt 2>&1",
+			expectedExecutables: []string{"sort"},
+		},
+		{
+			name:                "injected command",
+			command:             "echo hello; rm -rf /",
+			expectedExecutables: []string{"echo", "rm"},
+		},
+		{
+			name:                "subshell",
+			command:             "(cd dir && make)",
+			expectedExecutables: []string{"cd", "make"},
+		},
+		{
+			name:                "comment",
+			command:             "echo hello # ; rm -rf /\nls",
+			expectedExecutables: []string{"echo", "ls"},
+		},
+		{
+			name:        "command substitution",
+			command:     "echo $(rm -rf /)",
+			errContains: "command substitution is not allowed",
+		},
+		{
+			name:        "command substitution in double quotes",
+			command:     "echo \"`whoami`\"",
+			errContains: "command substitution is not allowed",
+		},
+		{
+			name:        "process substitution",
+			command:     "diff <(ls a) <(ls b)",
+			errContains: "process substitution is not allowed",
+		},
+		{
+			name:        "unterminated quote",
+			command:     "echo 'hello",
+			errContains: "unterminated single quote",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			executables, err := commandExecutables(tc.command)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedExecutables, executables)
+		})
+	}
+}
+
+func TestEscapesWorkingDir(t *testing.T) {
+	tt := []struct {
+		name     string
+		value    any
+		expected bool
+	}{
+		{name: "relative path", value: "docs/readme.md", expected: false},
+		{name: "dots in file name", value: "archive..tar", expected: false},
+		{name: "number", value: 42.0, expected: false},
+		{name: "absolute path", value: "/etc/passwd", expected: true},
+		{name: "home directory", value: "~/.ssh/id_rsa", expected: true},
+		{name: "parent directory", value: "docs/../../secret", expected: true},
+		{name: "absolute path in flag", value: "--config=/etc/passwd", expected: true},
+		{name: "nested in array", value: []any{"a.txt", "../b.txt"}, expected: true},
+		{name: "nested in object", value: map[string]any{"path": "/root"}, expected: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, escapesWorkingDir(tc.value))
+		})
+	}
+}
+
+func TestCliInvokerSandbox(t *testing.T) {
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "hello.txt"), []byte("hello from the working directory"), 0o600))
+	t.Setenv("GENMCP_SANDBOX_ALLOWED", "visible")
+	t.Setenv("GENMCP_SANDBOX_SECRET", "hidden")
+
+	resolvedWithFile, _ := (&jsonschema.Schema{
+		Type: invocation.JsonSchemaTypeObject,
+		Properties: map[string]*jsonschema.Schema{
+			"file": {Type: invocation.JsonSchemaTypeString},
+		},
+	}).Resolve(nil)
+
+	tt := []struct {
+		name            string
+		commandTemplate string
+		schema          *jsonschema.Resolved
+		arguments       string
+		modify          func(ci *CliInvoker)
+		expectedText    string
+		expectIsError   bool
+		errContains     string
+	}{
+		{
+			name:            "allowed executable",
+			commandTemplate: "echo {file}",
+			schema:          resolvedWithFile,
+			arguments:       `{"file": "a.txt"}`,
+			modify:          func(ci *CliInvoker) { ci.AllowedExecutables = []string{"echo"} },
+			expectedText:    "a.txt\n",
+		},
+		{
+			name:            "injected executable is rejected",
+			commandTemplate: "echo {file}",
+			schema:          resolvedWithFile,
+			arguments:       `{"file": "a.txt; id"}`,
+			modify:          func(ci *CliInvoker) { ci.AllowedExecutables = []string{"echo"} },
+			expectedText:    "Command execution failed:\ncommand rejected: executable 'id' is not allowed",
+			expectIsError:   true,
+		},
+		{
+			name:            "working directory",
+			commandTemplate: "cat {file}",
+			schema:          resolvedWithFile,
+			arguments:       `{"file": "hello.txt"}`,
+			modify:          func(ci *CliInvoker) { ci.WorkingDir = workingDir },
+			expectedText:    "hello from the working directory",
+		},
+		{
+			name:            "path outside of the working directory is rejected",
+			commandTemplate: "cat {file}",
+			schema:          resolvedWithFile,
+			arguments:       `{"file": "../hello.txt"}`,
+			modify:          func(ci *CliInvoker) { ci.WorkingDir = workingDir },
+			errContains:     "argument 'file' must not reference paths outside of the working directory",
+		},
+		{
+			name:            "scrubbed environment",
+			commandTemplate: "echo \"$GENMCP_SANDBOX_ALLOWED-$GENMCP_SANDBOX_SECRET\"",
+			schema:          resolvedEmpty,
+			arguments:       `{}`,
+			modify:          func(ci *CliInvoker) { ci.AllowedEnv = []string{"GENMCP_SANDBOX_ALLOWED"} },
+			expectedText:    "visible-\n",
+		},
+		{
+			name:            "inherited environment",
+			commandTemplate: "echo \"$GENMCP_SANDBOX_ALLOWED-$GENMCP_SANDBOX_SECRET\"",
+			schema:          resolvedEmpty,
+			arguments:       `{}`,
+			modify:          func(ci *CliInvoker) {},
+			expectedText:    "visible-hidden\n",
+		},
+		{
+			name:            "timeout",
+			commandTemplate: "sleep 5",
+			schema:          resolvedEmpty,
+			arguments:       `{}`,
+			modify:          func(ci *CliInvoker) { ci.Timeout = 100 * time.Millisecond },
+			expectedText:    "Command execution failed:\ncommand timed out after 100ms",
+			expectIsError:   true,
+		},
+		{
+			name:            "max output bytes",
+			commandTemplate: "yes",
+			schema:          resolvedEmpty,
+			arguments:       `{}`,
+			modify:          func(ci *CliInvoker) { ci.MaxOutputBytes = 6 },
+			expectedText:    "Command execution failed:\ncommand output exceeded the limit of 6 bytes\ny\ny\ny\n",
+			expectIsError:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testCliInvoker(t, tc.commandTemplate, tc.schema, "")
+			tc.modify(&invoker)
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: []byte(tc.arguments)},
+			})
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectIsError, result.IsError)
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestCliInvocationConfigValidate(t *testing.T) {
+	tt := []struct {
+		name        string
+		config      *CliInvocationConfig
+		errContains string
+	}{
+		{
+			name: "valid sandbox settings",
+			config: &CliInvocationConfig{
+				Command:            "git status",
+				AllowedExecutables: []string{"git"},
+				WorkingDir:         "/srv/repo",
+				AllowedEnv:         []string{"PATH", "HOME"},
+				Timeout:            "30s",
+				MaxOutputBytes:     1024,
+				KillGracePeriod:    "2s",
+				ProgressInterval:   "10s",
+				ProgressPattern:    `(?P<progress>\d+)/(?P<total>\d+)`,
+			},
+		},
+		{
+			name:        "invalid output format",
+			config:      &CliInvocationConfig{Command: "ls", OutputFormat: "xml"},
+			errContains: "invalid outputFormat 'xml'",
+		},
+		{
+			name:        "regex output format without pattern",
+			config:      &CliInvocationConfig{Command: "ls", OutputFormat: OutputFormatRegex},
+			errContains: "outputPattern is required for the regex output format",
+		},
+		{
+			name:        "output pattern without named groups",
+			config:      &CliInvocationConfig{Command: "ls", OutputFormat: OutputFormatRegex, OutputPattern: `(\w+)`},
+			errContains: "outputPattern must contain named groups",
+		},
+		{
+			name:        "output columns for another output format",
+			config:      &CliInvocationConfig{Command: "ls", OutputFormat: OutputFormatLines, OutputColumns: []string{"name"}},
+			errContains: "outputColumns can only be set for the table output format",
+		},
+		{
+			name:        "invalid quoting",
+			config:      &CliInvocationConfig{Command: "ls", Quoting: "double"},
+			errContains: "invalid quoting 'double'",
+		},
+		{
+			name:        "invalid error mode",
+			config:      &CliInvocationConfig{Command: "ls", ErrorMode: "exception"},
+			errContains: "invalid errorMode 'exception'",
+		},
+		{
+			name:        "empty allowed executable",
+			config:      &CliInvocationConfig{Command: "ls", AllowedExecutables: []string{" "}},
+			errContains: "invalid allowed executable",
+		},
+		{
+			name:        "invalid allowed environment variable",
+			config:      &CliInvocationConfig{Command: "ls", AllowedEnv: []string{"PATH=/bin"}},
+			errContains: "invalid allowed environment variable 'PATH=/bin'",
+		},
+		{
+			name:        "invalid timeout",
+			config:      &CliInvocationConfig{Command: "ls", Timeout: "soon"},
+			errContains: "invalid timeout 'soon'",
+		},
+		{
+			name:        "negative timeout",
+			config:      &CliInvocationConfig{Command: "ls", Timeout: "-1s"},
+			errContains: "timeout must be positive",
+		},
+		{
+			name:        "negative max output bytes",
+			config:      &CliInvocationConfig{Command: "ls", MaxOutputBytes: -1},
+			errContains: "maxOutputBytes must not be negative",
+		},
+		{
+			name:        "invalid kill grace period",
+			config:      &CliInvocationConfig{Command: "ls", KillGracePeriod: "soon"},
+			errContains: "invalid killGracePeriod 'soon'",
+		},
+		{
+			name:        "negative kill grace period",
+			config:      &CliInvocationConfig{Command: "ls", KillGracePeriod: "-1s"},
+			errContains: "killGracePeriod must not be negative",
+		},
+		{
+			name:        "invalid progress interval",
+			config:      &CliInvocationConfig{Command: "ls", ProgressInterval: "0s"},
+			errContains: "progressInterval must be positive",
+		},
+		{
+			name:        "progress pattern without progress group",
+			config:      &CliInvocationConfig{Command: "ls", ProgressPattern: `(\d+)%`},
+			errContains: "progressPattern must contain a group named progress",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shellToken is a word or an operator of a bash command, after quote removal.
+type shellToken struct {
+	word     string
+	operator string // one of ";", "&", "|", "\n", "(", ")" or a redirection such as ">" or "2>&"; empty for words
+}
+
+// isRedirect reports whether the token is a redirection operator, whose target is the next word.
+func (t shellToken) isRedirect() bool {
+	return strings.ContainsAny(t.operator, "<>")
+}
+
+// lexCommand splits a bash command into words and operators, following the quoting rules of bash.
+// Expansions other than quote removal are not performed, so words keep '$' references as-is.
+// It returns an error for command, process, or arithmetic substitutions, whose results cannot be
+// known without executing them.
+func lexCommand(command string) ([]shellToken, error) {
+	var (
+		tokens []shellToken
+		word   strings.Builder
+		inWord bool // a word is being read, possibly empty (e.g. '')
+	)
+
+	endWord := func() {
+		if inWord {
+			tokens = append(tokens, shellToken{word: word.String()})
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch c {
+		case '\\':
+			if i+1 < len(command) {
+				i++
+				if command[i] != '\n' {
+					word.WriteByte(command[i])
+				}
+			}
+			inWord = true
+		case '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case '"':
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				switch {
+				case command[i] == '`' || strings.HasPrefix(command[i:], "$("):
+					return nil, fmt.Errorf("command substitution is not allowed")
+				case command[i] == '\\' && i+1 < len(command):
+					i++
+				}
+				word.WriteByte(command[i])
+			}
+			if i == len(command) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case '`':
+			return nil, fmt.Errorf("command substitution is not allowed")
+		case '$':
+			if strings.HasPrefix(command[i:], "$(") {
+				return nil, fmt.Errorf("command substitution is not allowed")
+			}
+			word.WriteByte(c)
+			inWord = true
+		case '#':
+			if inWord {
+				word.WriteByte(c)
+				continue
+			}
+			// a comment runs until the end of the line
+			for i+1 < len(command) && command[i+1] != '\n' {
+				i++
+			}
+		case ' ', '\t':
+			endWord()
+		case ';', '&', '|', '\n', '(', ')':
+			endWord()
+			tokens = append(tokens, shellToken{operator: string(c)})
+		case '<', '>':
+			if strings.HasPrefix(command[i+1:], "(") {
+				return nil, fmt.Errorf("process substitution is not allowed")
+			}
+			// a file descriptor number before the redirection (e.g. '2>') is part of the operator
+			operator := ""
+			if inWord && isDigits(word.String()) {
+				operator = word.String()
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			start := i
+			for i+1 < len(command) && strings.IndexByte("<>&|", command[i+1]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, shellToken{operator: operator + command[start:i+1]})
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endWord()
+
+	return tokens, nil
+}
+
+// shellQuote quotes s as a single bash word, so that it is passed to the command verbatim.
+// Strings made only of characters without special meaning are returned as-is.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !isShellSafe(r)
+	}) < 0 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_@%+=:,./-", r)
+}
+
+// splitArgv splits a command into the arguments of an executable run without a shell.
+// Shell operators such as pipes, lists, and redirections are rejected, as there is no shell to run them.
+func splitArgv(command string) ([]string, error) {
+	tokens, err := lexCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	argv := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.operator != "" {
+			return nil, fmt.Errorf("shell operator %q cannot be used without a shell", token.operator)
+		}
+		argv = append(argv, token.word)
+	}
+
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("command is empty")
+	}
+
+	return argv, nil
+}
+
+// withoutVariables replaces the variables of a command template with a placeholder word, so that the
+// template can be split before its values are known, and the pipes of variables such as {name|lower}
+// are not taken for shell operators.
+func withoutVariables(command string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(command, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(command[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(command[:start])
+		b.WriteString("_")
+		command = command[start+end+1:]
+	}
+	b.WriteString(command)
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
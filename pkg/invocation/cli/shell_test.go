@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	tt := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "safe characters", value: "--file=docs/a.txt", expected: "--file=docs/a.txt"},
+		{name: "empty", value: "", expected: "''"},
+		{name: "spaces", value: "hello world", expected: "'hello world'"},
+		{name: "injection", value: "; rm -rf /", expected: "'; rm -rf /'"},
+		{name: "single quotes", value: "it's", expected: `'it'\''s'`},
+		{name: "expansions", value: "$(id) `id` $HOME", expected: "'$(id) `id` $HOME'"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			quoted := shellQuote(tc.value)
+			assert.Equal(t, tc.expected, quoted)
+
+			// quoting must round-trip through the shell lexer as a single word
+			argv, err := splitArgv("echo " + quoted)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"echo", tc.value}, argv)
+		})
+	}
+}
+
+func TestSplitArgv(t *testing.T) {
+	tt := []struct {
+		name         string
+		command      string
+		expectedArgv []string
+		errContains  string
+	}{
+		{
+			name:         "words",
+			command:      "git log  --oneline\t-n 5",
+			expectedArgv: []string{"git", "log", "--oneline", "-n", "5"},
+		},
+		{
+			name:         "quotes and escapes",
+			command:      `grep -e 'a b' "c \"d\"" e\ f`,
+			expectedArgv: []string{"grep", "-e", "a b", `c "d"`, "e f"},
+		},
+		{
+			name:        "pipe",
+			command:     "ls | wc -l",
+			errContains: `shell operator "|" cannot be used without a shell`,
+		},
+		{
+			name:        "redirection",
+			command:     "ls 2>/dev/null",
+			errContains: `shell operator "2>" cannot be used without a shell`,
+		},
+		{
+			name:        "empty",
+			command:     "  ",
+			errContains: "command is empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			argv, err := splitArgv(tc.command)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedArgv, argv)
+		})
+	}
+}
+
+func TestWithoutVariables(t *testing.T) {
+	tt := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{
+			name:     "no variables",
+			command:  "echo hello",
+			expected: "echo hello",
+		},
+		{
+			name:     "variables with pipes",
+			command:  "echo {message|default:a | b} --count={count}",
+			expected: "echo _ --count=_",
+		},
+		{
+			name:     "environment variables",
+			command:  "curl ${API_HOST|lower}/{path}",
+			expected: "curl $_/_",
+		},
+		{
+			name:     "unterminated variable",
+			command:  "echo {message",
+			expected: "echo {message",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, withoutVariables(tc.command))
+		})
+	}
+}
+
+func TestCliInvokerQuoting(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: invocation.JsonSchemaTypeObject,
+		Properties: map[string]*jsonschema.Schema{
+			"message": {Type: invocation.JsonSchemaTypeString},
+			"count":   {Type: invocation.JsonSchemaTypeInteger},
+			"tags":    {Type: invocation.JsonSchemaTypeArray, Items: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString}},
+		},
+	}
+	resolved, err := schema.Resolve(nil)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name            string
+		config          *CliInvocationConfig
+		arguments       string
+		expectedCommand string
+		expectedText    string
+		errContains     string
+	}{
+		{
+			name:            "none inserts values verbatim",
+			config:          &CliInvocationConfig{Command: "echo {message}"},
+			arguments:       `{"message": "hi; echo injected"}`,
+			expectedCommand: "echo hi; echo injected",
+			expectedText:    "hi\ninjected\n",
+		},
+		{
+			name:            "shell quotes values",
+			config:          &CliInvocationConfig{Command: "echo {message} {count}", Quoting: QuotingShell, TemplateVariables: map[string]*TemplateVariable{"count": {Template: "--count={count}"}}},
+			arguments:       `{"message": "hi; echo injected", "count": 3}`,
+			expectedCommand: "echo 'hi; echo injected' --count=3",
+			expectedText:    "hi; echo injected --count=3\n",
+		},
+		{
+			name:            "argv runs without a shell",
+			config:          &CliInvocationConfig{Command: "echo {message}", Quoting: QuotingArgv},
+			arguments:       `{"message": "$HOME $(id)"}`,
+			expectedCommand: "echo '$HOME $(id)'",
+			expectedText:    "$HOME $(id)\n",
+		},
+		{
+			name:            "argv quotes values after the functions of their pipe",
+			config:          &CliInvocationConfig{Command: "echo {message|upper} {count|default:1}", Quoting: QuotingArgv},
+			arguments:       `{"message": "hi; echo injected"}`,
+			expectedCommand: "echo 'HI; ECHO INJECTED' 1",
+			expectedText:    "HI; ECHO INJECTED 1\n",
+		},
+		{
+			name:            "argv quotes values in conditional blocks",
+			config:          &CliInvocationConfig{Command: "echo{?count} -n {count}{/count}{?message} {message}{/message}", Quoting: QuotingArgv},
+			arguments:       `{"message": "a b; echo injected"}`,
+			expectedCommand: "echo 'a b; echo injected'",
+			expectedText:    "a b; echo injected\n",
+		},
+		{
+			name:            "argv repeats template variables of exploded arrays",
+			config:          &CliInvocationConfig{Command: "echo {tags*}", Quoting: QuotingArgv, TemplateVariables: map[string]*TemplateVariable{"tags": {Template: "--tag {tags}"}}},
+			arguments:       `{"tags": ["a b", "c; echo injected"]}`,
+			expectedCommand: "echo --tag 'a b' --tag 'c; echo injected'",
+			expectedText:    "--tag a b --tag c; echo injected\n",
+		},
+		{
+			name:            "shell quotes defaults",
+			config:          &CliInvocationConfig{Command: "echo {message|default:hello world}", Quoting: QuotingShell},
+			arguments:       `{}`,
+			expectedCommand: "echo 'hello world'",
+			expectedText:    "hello world\n",
+		},
+		{
+			name:        "argv rejects shell operators",
+			config:      &CliInvocationConfig{Command: "echo {message} | wc -c", Quoting: QuotingArgv},
+			errContains: `invalid command for argv quoting: shell operator "|" cannot be used without a shell`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &definitions.Tool{Name: "echo", InputSchema: schema, ResolvedInputSchema: resolved}
+
+			invoker, err := (&InvokerFactory{}).CreateInvoker(tc.config, tool)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: []byte(tc.arguments)}}
+
+			dryRun, err := invoker.(invocation.DryRunner).DryRun(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCommand, dryRun.Command)
+
+			result, err := invoker.Invoke(context.Background(), req)
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package invocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DryRunner is implemented by invokers that can show what a tool call would execute, without executing it.
+type DryRunner interface {
+	// DryRun parses and validates the arguments of req the same way Invoke does, and returns the
+	// request Invoke would make with all of its templates rendered.
+	DryRun(ctx context.Context, req *mcp.CallToolRequest) (*DryRunResult, error)
+}
+
+// DryRunResult is the request an invoker would make. Only the fields of its invocation type are set.
+type DryRunResult struct {
+	// Type is the invocation type, e.g. http.
+	Type string `json:"type"`
+
+	// Method, URL, Headers and Body describe an HTTP request, a gRPC call of the method Method on the
+	// server at the address URL, with the metadata Headers and the request message Body, or an MCP request
+	// forwarded to an upstream server. For SMTP invocations, URL is the smtp:// URL of the server, Headers are
+	// the headers of the email and Body is its body, as a JSON string.
+	Method  string          `json:"method,omitempty"`
+	URL     string          `json:"url,omitempty"`
+	Headers http.Header     `json:"headers,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+
+	// Command is the command line of a CLI or SSH invocation, or of the upstream server of a proxy invocation.
+	// The URL of SSH invocations is the ssh:// URL of the host.
+	Command string `json:"command,omitempty"`
+
+	// Query and QueryArgs are a SQL query and the values bound to its parameters, in order.
+	Query     string `json:"query,omitempty"`
+	QueryArgs []any  `json:"queryArgs,omitempty"`
+}
+
+// Redact applies redact to the rendered templates of the request: the URL, the header values, the
+// command, and the string query arguments. It is used to hide the values of secrets.
+func (r *DryRunResult) Redact(redact func(string) string) {
+	r.URL = redact(r.URL)
+	for name, values := range r.Headers {
+		for i, value := range values {
+			r.Headers[name][i] = redact(value)
+		}
+	}
+	r.Command = redact(r.Command)
+	for i, arg := range r.QueryArgs {
+		if s, ok := arg.(string); ok {
+			r.QueryArgs[i] = redact(s)
+		}
+	}
+}
+
+// String formats the request for humans, e.g. as an HTTP request line followed by its headers and body.
+func (r *DryRunResult) String() string {
+	var sb strings.Builder
+
+	if r.URL != "" {
+		fmt.Fprintf(&sb, "%s %s\n", r.Method, r.URL)
+		for _, name := range slices.Sorted(maps.Keys(r.Headers)) {
+			for _, value := range r.Headers[name] {
+				fmt.Fprintf(&sb, "%s: %s\n", name, value)
+			}
+		}
+		if len(r.Body) > 0 {
+			fmt.Fprintf(&sb, "\n%s\n", r.Body)
+		}
+	}
+
+	if r.Command != "" {
+		fmt.Fprintf(&sb, "%s\n", r.Command)
+	}
+
+	if r.Query != "" {
+		fmt.Fprintf(&sb, "%s\n", r.Query)
+		for i, arg := range r.QueryArgs {
+			value, err := json.Marshal(arg)
+			if err != nil {
+				value = []byte(fmt.Sprint(arg))
+			}
+			fmt.Fprintf(&sb, "  arg %d: %s\n", i+1, value)
+		}
+	}
+
+	return sb.String()
+}
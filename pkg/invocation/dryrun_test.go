@@ -0,0 +1,47 @@
+package invocation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunResultString(t *testing.T) {
+	tt := []struct {
+		name     string
+		result   *DryRunResult
+		expected string
+	}{
+		{
+			name: "http",
+			result: &DryRunResult{
+				Type:   "http",
+				Method: "POST",
+				URL:    "http://localhost/users",
+				Headers: map[string][]string{
+					"X-Request-Id": {"42"},
+					"Content-Type": {"application/json"},
+				},
+				Body: json.RawMessage(`{"name":"Ada"}`),
+			},
+			expected: "POST http://localhost/users\nContent-Type: application/json\nX-Request-Id: 42\n\n{\"name\":\"Ada\"}\n",
+		},
+		{
+			name:     "cli",
+			result:   &DryRunResult{Type: "cli", Command: "echo hello"},
+			expected: "echo hello\n",
+		},
+		{
+			name:     "sql",
+			result:   &DryRunResult{Type: "sql", Query: "SELECT * FROM users WHERE name = $1 AND age > $2", QueryArgs: []any{"Ada", nil}},
+			expected: "SELECT * FROM users WHERE name = $1 AND age > $2\n  arg 1: \"Ada\"\n  arg 2: null\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.result.String())
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package invocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrorCode classifies the failure of an invocation, so that clients can branch on the type of failure
+// instead of parsing error messages.
+type ErrorCode string
+
+const (
+	// ErrorCodeValidation is returned for arguments that don't match the input schema of the primitive,
+	// or are rejected by the settings of the invocation.
+	ErrorCodeValidation ErrorCode = "validation_error"
+	// ErrorCodeAuth is returned for callers that are not authorized to invoke the primitive, and for
+	// credentials of the backend that could not be obtained.
+	ErrorCodeAuth ErrorCode = "auth_error"
+	// ErrorCodeBackendUnavailable is returned for backends that could not be reached.
+	ErrorCodeBackendUnavailable ErrorCode = "backend_unavailable"
+	// ErrorCodeBackendStatus is returned for backends that answered with an error: an HTTP error status,
+	// a non-zero exit code, a failed query or a gRPC error status.
+	ErrorCodeBackendStatus ErrorCode = "backend_error_status"
+	// ErrorCodeTimeout is returned for invocations that did not complete in time.
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeQuotaExceeded is returned for calls over a quota of the caller.
+	ErrorCodeQuotaExceeded ErrorCode = "quota_exceeded"
+	// ErrorCodeInternal is returned for every other failure, e.g. responses that could not be decoded.
+	ErrorCodeInternal ErrorCode = "internal_error"
+)
+
+// ErrorMetaKey is the key of the _meta of failed tool results holding their ErrorDetail.
+const ErrorMetaKey = "genmcp/error"
+
+// rpcCodes are the JSON-RPC error codes of failures reported as protocol errors. Codes without a standard
+// equivalent are in the range reserved for implementation-defined server errors, after the ones used by
+// the MCP SDK.
+var rpcCodes = map[ErrorCode]int64{
+	ErrorCodeValidation:         jsonrpc.CodeInvalidParams,
+	ErrorCodeAuth:               -32003,
+	ErrorCodeBackendUnavailable: -32004,
+	ErrorCodeBackendStatus:      -32005,
+	ErrorCodeTimeout:            -32006,
+	ErrorCodeQuotaExceeded:      -32007,
+	ErrorCodeInternal:           jsonrpc.CodeInternalError,
+}
+
+// RPCCode returns the JSON-RPC error code of failures of code c.
+func (c ErrorCode) RPCCode() int64 {
+	if code, ok := rpcCodes[c]; ok {
+		return code
+	}
+	return jsonrpc.CodeInternalError
+}
+
+// Error is an error of an invocation, classified by its Code.
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+// Errorf returns an *Error of code, with the error formatted like fmt.Errorf.
+func Errorf(code ErrorCode, format string, args ...any) *Error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of err: the code of the *Error it wraps if any, ErrorCodeTimeout for exceeded
+// deadlines, ErrorCodeBackendUnavailable for network errors, and fallback otherwise.
+func CodeOf(err error, fallback ErrorCode) ErrorCode {
+	var ie *Error
+	if errors.As(err, &ie) {
+		return ie.Code
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorCodeTimeout
+		}
+		return ErrorCodeBackendUnavailable
+	}
+
+	return fallback
+}
+
+// ErrorDetail is the machine-readable description of the failure of an invocation, set in the _meta of
+// failed tool results and in the data of JSON-RPC errors.
+type ErrorDetail struct {
+	Code ErrorCode `json:"code"`
+	// Status is the HTTP status of the response, the exit code of the command, or the gRPC status code
+	// returned by the backend, for ErrorCodeBackendStatus
+	Status int `json:"status,omitempty"`
+	// Retryable reports whether the invocation may succeed if retried later, unchanged
+	Retryable bool `json:"retryable"`
+}
+
+// NewErrorDetail returns the ErrorDetail of a failure of code, with the status returned by the backend
+// if any.
+func NewErrorDetail(code ErrorCode, status int) ErrorDetail {
+	retryable := false
+	switch code {
+	case ErrorCodeBackendUnavailable, ErrorCodeTimeout, ErrorCodeQuotaExceeded:
+		retryable = true
+	case ErrorCodeBackendStatus:
+		// HTTP statuses of transient failures
+		switch status {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			retryable = true
+		}
+	}
+
+	return ErrorDetail{Code: code, Status: status, Retryable: retryable}
+}
+
+// SetErrorDetail flags result as an error described by detail.
+func SetErrorDetail(result *mcp.CallToolResult, detail ErrorDetail) {
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[ErrorMetaKey] = detail
+	result.IsError = true
+}
+
+// GetErrorDetail returns the ErrorDetail of result, if it was set with SetErrorDetail or decoded from the
+// JSON encoding of such a result.
+func GetErrorDetail(result *mcp.CallToolResult) (ErrorDetail, bool) {
+	if result == nil || result.Meta == nil {
+		return ErrorDetail{}, false
+	}
+
+	switch v := result.Meta[ErrorMetaKey].(type) {
+	case ErrorDetail:
+		return v, true
+	case map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ErrorDetail{}, false
+		}
+		var detail ErrorDetail
+		if err := json.Unmarshal(data, &detail); err != nil || detail.Code == "" {
+			return ErrorDetail{}, false
+		}
+		return detail, true
+	}
+	return ErrorDetail{}, false
+}
+
+// NewRPCError returns the JSON-RPC error reporting a failure described by detail, with the fields of data
+// added to the error data, e.g. the output of a command.
+func NewRPCError(detail ErrorDetail, message string, data map[string]any) (*jsonrpc.Error, error) {
+	fields := map[string]any{
+		"code":      detail.Code,
+		"retryable": detail.Retryable,
+	}
+	if detail.Status != 0 {
+		fields["status"] = detail.Status
+	}
+	for k, v := range data {
+		fields[k] = v
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode error data: %w", err)
+	}
+
+	return &jsonrpc.Error{Code: detail.Code.RPCCode(), Message: message, Data: encoded}, nil
+}
@@ -0,0 +1,163 @@
+package invocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCodeOf(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+	}{
+		{
+			name:     "wrapped invocation error",
+			err:      fmt.Errorf("failed to build request: %w", Errorf(ErrorCodeValidation, "missing required field: repo")),
+			expected: ErrorCodeValidation,
+		},
+		{
+			name:     "exceeded deadline",
+			err:      fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expected: ErrorCodeTimeout,
+		},
+		{
+			name:     "network timeout",
+			err:      &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}},
+			expected: ErrorCodeTimeout,
+		},
+		{
+			name:     "refused connection",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			expected: ErrorCodeBackendUnavailable,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("unexpected end of JSON input"),
+			expected: ErrorCodeInternal,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CodeOf(tc.err, ErrorCodeInternal))
+		})
+	}
+}
+
+func TestNewErrorDetail(t *testing.T) {
+	tt := []struct {
+		name     string
+		code     ErrorCode
+		status   int
+		expected ErrorDetail
+	}{
+		{
+			name:     "validation error",
+			code:     ErrorCodeValidation,
+			expected: ErrorDetail{Code: ErrorCodeValidation},
+		},
+		{
+			name:     "unavailable backend",
+			code:     ErrorCodeBackendUnavailable,
+			expected: ErrorDetail{Code: ErrorCodeBackendUnavailable, Retryable: true},
+		},
+		{
+			name:     "rate limited",
+			code:     ErrorCodeBackendStatus,
+			status:   429,
+			expected: ErrorDetail{Code: ErrorCodeBackendStatus, Status: 429, Retryable: true},
+		},
+		{
+			name:     "client error status",
+			code:     ErrorCodeBackendStatus,
+			status:   404,
+			expected: ErrorDetail{Code: ErrorCodeBackendStatus, Status: 404},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NewErrorDetail(tc.code, tc.status))
+		})
+	}
+}
+
+func TestSetErrorDetail(t *testing.T) {
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not found"}}}
+	_, ok := GetErrorDetail(result)
+	assert.False(t, ok)
+
+	SetErrorDetail(result, NewErrorDetail(ErrorCodeBackendStatus, 404))
+	assert.True(t, result.IsError)
+
+	detail, ok := GetErrorDetail(result)
+	require.True(t, ok)
+	assert.Equal(t, ErrorDetail{Code: ErrorCodeBackendStatus, Status: 404}, detail)
+
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"_meta":{"genmcp/error":{"code":"backend_error_status","status":404,"retryable":false}}`)
+
+	// the detail of a decoded result, e.g. a replayed one, is a map
+	decoded := &mcp.CallToolResult{}
+	require.NoError(t, json.Unmarshal(encoded, decoded))
+	detail, ok = GetErrorDetail(decoded)
+	require.True(t, ok)
+	assert.Equal(t, ErrorDetail{Code: ErrorCodeBackendStatus, Status: 404}, detail)
+}
+
+func TestNewRPCError(t *testing.T) {
+	tt := []struct {
+		name         string
+		detail       ErrorDetail
+		data         map[string]any
+		expectedCode int64
+		expectedData string
+	}{
+		{
+			name:         "validation error",
+			detail:       NewErrorDetail(ErrorCodeValidation, 0),
+			expectedCode: jsonrpc.CodeInvalidParams,
+			expectedData: `{"code":"validation_error","retryable":false}`,
+		},
+		{
+			name:         "error status with output",
+			detail:       NewErrorDetail(ErrorCodeBackendStatus, 2),
+			data:         map[string]any{"stderr": "no such file"},
+			expectedCode: -32005,
+			expectedData: `{"code":"backend_error_status","status":2,"retryable":false,"stderr":"no such file"}`,
+		},
+		{
+			name:         "internal error",
+			detail:       NewErrorDetail(ErrorCodeInternal, 0),
+			expectedCode: jsonrpc.CodeInternalError,
+			expectedData: `{"code":"internal_error","retryable":false}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rpcErr, err := NewRPCError(tc.detail, "tool invocation failed", tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, rpcErr.Code)
+			assert.Equal(t, "tool invocation failed", rpcErr.Message)
+			assert.JSONEq(t, tc.expectedData, string(rpcErr.Data))
+		})
+	}
+}
@@ -38,7 +38,8 @@ func (ec *ExtendsConfig) DeepCopy() invocation.InvocationConfig {
 	}
 }
 
-func (ec *ExtendsConfig) resolve() (*invocation.InvocationConfigWrapper, error) {
+// Resolve returns the invocation config of the base ec extends, with the modifications of ec applied.
+func (ec *ExtendsConfig) Resolve() (*invocation.InvocationConfigWrapper, error) {
 	baseInfo, ok := getBase(ec.From)
 	if !ok {
 		return nil, fmt.Errorf("failed to get base invocation config '%s'", ec.From)
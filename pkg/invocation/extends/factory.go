@@ -22,7 +22,7 @@ func (f *ExtendsFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		return nil, fmt.Errorf("invalid ExtendsConfig for extends invoker factory")
 	}
 
-	resolved, err := cfg.resolve()
+	resolved, err := cfg.Resolve()
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve extends invocation config: %w", err)
 	}
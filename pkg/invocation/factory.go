@@ -35,6 +35,15 @@ func CreateResourceTemplateInvoker(primitive Primitive) (Invoker, error) {
 	return CreateInvoker(primitive)
 }
 
+// ToolName returns the name of primitive if it is a tool, and an empty string otherwise. Templates are parsed
+// for the tool it returns, so that they can reference the environment variables allowed for the tool.
+func ToolName(primitive Primitive) string {
+	if primitive.PrimitiveType() != "tool" {
+		return ""
+	}
+	return primitive.GetName()
+}
+
 func GetFactory(invocationType string) (InvokerFactory, bool) {
 	factory, exists := globalRegistry.factories[invocationType]
 
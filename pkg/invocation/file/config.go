@@ -0,0 +1,93 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	OperationRead  = "read"
+	OperationWrite = "write"
+	OperationList  = "list"
+
+	// DefaultContentProperty is the input schema property holding the content written by write operations.
+	DefaultContentProperty = "content"
+)
+
+var validOperations = map[string]bool{
+	OperationRead:  true,
+	OperationWrite: true,
+	OperationList:  true,
+}
+
+// FileInvocationConfig is the configuration for reading, writing or listing files under a root directory.
+// This is a pure data structure with no parsing logic - all struct tags only.
+type FileInvocationConfig struct {
+	// The directory all paths are resolved in. Files outside of it cannot be accessed, neither with '..' nor through symbolic links.
+	// It can reference environment variables using '${VAR_NAME}' syntax.
+	Root string `json:"root" jsonschema:"required"`
+
+	// The operation to perform on the file (default: read). list returns the files matching the path as a glob pattern.
+	Operation string `json:"operation,omitempty" jsonschema:"optional,enum=read,enum=write,enum=list"`
+
+	// The path of the file relative to the root, or a glob pattern (e.g. 'logs/*.log') for list operations.
+	// It can contain placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema.
+	Path string `json:"path" jsonschema:"required"`
+
+	// The input schema property whose value is written to the file, for write operations (default: content).
+	ContentProperty string `json:"contentProperty,omitempty" jsonschema:"optional"`
+
+	// If true, write operations append to the file instead of replacing it.
+	Append bool `json:"append,omitempty" jsonschema:"optional"`
+
+	// The MIME type of the files read. Detected from the file extension and content if unset.
+	MIMEType string `json:"mimeType,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &FileInvocationConfig{}
+
+func (c *FileInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Root) == "" {
+		return fmt.Errorf("root is required")
+	}
+
+	if c.Operation != "" && !validOperations[c.Operation] {
+		return fmt.Errorf("invalid operation '%s': must be one of read, write, list", c.Operation)
+	}
+
+	if strings.TrimSpace(c.Path) == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	if c.operation() != OperationWrite {
+		if c.ContentProperty != "" {
+			return fmt.Errorf("contentProperty can only be set for write operations")
+		}
+		if c.Append {
+			return fmt.Errorf("append can only be set for write operations")
+		}
+	}
+
+	return nil
+}
+
+func (c *FileInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &FileInvocationConfig{
+		Root:            c.Root,
+		Operation:       c.Operation,
+		Path:            c.Path,
+		ContentProperty: c.ContentProperty,
+		Append:          c.Append,
+		MIMEType:        c.MIMEType,
+	}
+}
+
+// operation returns the operation of the config, defaulting to read.
+func (c *FileInvocationConfig) operation() string {
+	if c.Operation == "" {
+		return OperationRead
+	}
+	return c.Operation
+}
@@ -0,0 +1,88 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name        string
+		config      *FileInvocationConfig
+		expectError bool
+	}{
+		{
+			name: "valid read",
+			config: &FileInvocationConfig{
+				Root: "${DATA_DIR}",
+				Path: "{name}.txt",
+			},
+			expectError: false,
+		},
+		{
+			name: "valid append",
+			config: &FileInvocationConfig{
+				Root:      "/var/data",
+				Operation: OperationWrite,
+				Path:      "notes/{name}.md",
+				Append:    true,
+			},
+			expectError: false,
+		},
+		{
+			name: "missing root",
+			config: &FileInvocationConfig{
+				Path: "notes.txt",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing path",
+			config: &FileInvocationConfig{
+				Root:      "/var/data",
+				Operation: OperationList,
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid operation",
+			config: &FileInvocationConfig{
+				Root:      "/var/data",
+				Operation: "delete",
+				Path:      "notes.txt",
+			},
+			expectError: true,
+		},
+		{
+			name: "content property for read",
+			config: &FileInvocationConfig{
+				Root:            "/var/data",
+				Path:            "notes.txt",
+				ContentProperty: "text",
+			},
+			expectError: true,
+		},
+		{
+			name: "append for list",
+			config: &FileInvocationConfig{
+				Root:      "/var/data",
+				Operation: OperationList,
+				Path:      "*.txt",
+				Append:    true,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
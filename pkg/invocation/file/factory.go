@@ -0,0 +1,90 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &FileInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	fic, ok := config.(*FileInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for file invoker factory")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for file invocations")
+	}
+
+	operation := fic.operation()
+	if operation == OperationWrite && primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("write operations are only supported for tools")
+	}
+
+	parsedRoot, err := template.ParseTemplate(fic.Root, template.TemplateParserOptions{Tool: invocation.ToolName(primitive), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root: %w", err)
+	}
+	for _, v := range parsedRoot.Variables {
+		if v.Type != template.VariableTypeEnv {
+			return nil, fmt.Errorf("root can only reference environment variables, got '%s'", v.Name)
+		}
+	}
+
+	parsedPath, err := template.ParseTemplate(fic.Path, template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     template.CreateSourceFactories(),
+		Tool:        invocation.ToolName(primitive),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse path template: %w", err)
+	}
+
+	if primitive.PrimitiveType() == "resource" {
+		for _, v := range parsedPath.Variables {
+			if v.Type == template.VariableTypeParam {
+				return nil, fmt.Errorf("static resource path cannot contain template variables")
+			}
+		}
+	}
+
+	contentProperty := ""
+	if operation == OperationWrite {
+		contentProperty = fic.ContentProperty
+		if contentProperty == "" {
+			contentProperty = DefaultContentProperty
+		}
+		inputSchema := primitive.GetInputSchema()
+		if inputSchema == nil || inputSchema.Properties[contentProperty] == nil {
+			return nil, fmt.Errorf("content property '%s' is not defined in the input schema", contentProperty)
+		}
+	}
+
+	uriTemplate := primitive.GetURITemplate()
+	if uriTemplate != "" {
+		_, err = uritemplate.New(uriTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI template '%s': %w", uriTemplate, err)
+		}
+	}
+
+	return &FileInvoker{
+		Root:            parsedRoot,
+		Operation:       operation,
+		Path:            parsedPath,
+		ContentProperty: contentProperty,
+		Append:          fic.Append,
+		MIMEType:        fic.MIMEType,
+		InputSchema:     primitive.GetResolvedInputSchema(),
+		URITemplate:     uriTemplate,
+	}, nil
+}
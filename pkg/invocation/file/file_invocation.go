@@ -0,0 +1,585 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.uber.org/zap"
+)
+
+type FileInvoker struct {
+	Root            *template.ParsedTemplate // Parsed root directory, may reference environment variables
+	Operation       string                   // Operation performed on the file (read, write or list)
+	Path            *template.ParsedTemplate // Parsed path of the file, or glob pattern for list operations
+	ContentProperty string                   // Input schema property written to the file (for write operations only)
+	Append          bool                     // Whether writes append to the file
+	MIMEType        string                   // MIME type of the files read, detected if empty
+	InputSchema     *jsonschema.Resolved     // InputSchema for the tool
+	URITemplate     string                   // MCP URI template (for resource templates only)
+}
+
+var _ invocation.Invoker = &FileInvoker{}
+
+// fileContent is a file read from the root directory.
+type fileContent struct {
+	path     string
+	mimeType string
+	data     []byte
+}
+
+// isText reports whether the content of the file is valid UTF-8 text.
+func (fc *fileContent) isText() bool {
+	return utf8.Valid(fc.data)
+}
+
+func (fi *FileInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting file tool invocation", zap.String("operation", fi.Operation))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build file path")
+	filePath, parsed, err := fi.buildPath(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *mcp.CallToolResult
+	switch fi.Operation {
+	case OperationList:
+		files, err := fi.listFiles(ctx, filePath)
+		if err != nil {
+			return utils.McpCodedError(fileErrorCode(err), "failed to list files: %v", err), nil
+		}
+		structured := map[string]any{"files": files}
+		text, err := json.Marshal(structured)
+		if err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode file list: %v", err), nil
+		}
+		result = &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: string(text)}},
+			StructuredContent: structured,
+		}
+	case OperationWrite:
+		content, err := fi.content(parsed)
+		if err != nil {
+			return nil, err
+		}
+		if err := fi.writeFile(ctx, filePath, content); err != nil {
+			return utils.McpCodedError(fileErrorCode(err), "failed to write file: %v", err), nil
+		}
+		result = &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("wrote %d bytes to %s", len(content), filePath)}},
+		}
+	default:
+		fc, err := fi.readFile(ctx, filePath)
+		if err != nil {
+			return utils.McpCodedError(fileErrorCode(err), "failed to read file: %v", err), nil
+		}
+		result = &mcp.CallToolResult{
+			Content: []mcp.Content{toolContent(fc)},
+		}
+	}
+
+	logger.Info("File tool invocation completed successfully", zap.String("operation", fi.Operation))
+
+	return result, nil
+}
+
+// toolContent returns text files as text content, images as image content, and any other file as an
+// embedded resource.
+func toolContent(fc *fileContent) mcp.Content {
+	if fc.isText() {
+		return &mcp.TextContent{Text: string(fc.data)}
+	}
+
+	if strings.HasPrefix(fc.mimeType, "image/") {
+		return &mcp.ImageContent{Data: fc.data, MIMEType: fc.mimeType}
+	}
+
+	return &mcp.EmbeddedResource{
+		Resource: &mcp.ResourceContents{
+			URI:      fileURI(fc.path),
+			MIMEType: fc.mimeType,
+			Blob:     fc.data,
+		},
+	}
+}
+
+func (fi *FileInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting file prompt invocation", zap.String("operation", fi.Operation))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	filePath, err := fi.buildPathFromPromptArgs(ctx, req.Params.Arguments, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	if fi.Operation == OperationList {
+		files, err := fi.listFiles(ctx, filePath)
+		if err != nil {
+			return utils.McpPromptTextError("failed to list files: %v", err), nil
+		}
+		encoded, err := json.Marshal(map[string]any{"files": files})
+		if err != nil {
+			return utils.McpPromptTextError("failed to encode file list: %v", err), nil
+		}
+		text = string(encoded)
+	} else {
+		fc, err := fi.readFile(ctx, filePath)
+		if err != nil {
+			return utils.McpPromptTextError("failed to read file: %v", err), nil
+		}
+		if !fc.isText() {
+			return utils.McpPromptTextError("file %s is not a text file", filePath), nil
+		}
+		text = string(fc.data)
+	}
+
+	logger.Info("File prompt invocation completed successfully", zap.String("operation", fi.Operation))
+
+	return &mcp.GetPromptResult{
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: text},
+			},
+		},
+	}, nil
+}
+
+func (fi *FileInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting file resource invocation", zap.String("uri", req.Params.URI))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	// Static resources have no parameters from the input schema, only env and header references
+	builder, err := fi.newPathBuilder(ctx, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath, err := fi.resolvePath(builder)
+	if err != nil {
+		logger.Error("Failed to build file resource path", zap.String("uri", req.Params.URI), zap.Error(err))
+		return nil, err
+	}
+
+	return fi.readResource(ctx, req.Params.URI, filePath)
+}
+
+func (fi *FileInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting file resource template invocation", zap.String("uri", req.Params.URI))
+
+	// URI template syntax is validated during parsing, so we can safely use it here
+	argsMap := make(map[string]any)
+	uriTmpl, _ := uritemplate.New(fi.URITemplate)
+
+	// Match the incoming URI against the template to extract argument values
+	matches := uriTmpl.Match(req.Params.URI)
+	if matches == nil {
+		logger.Error("URI does not match file resource template",
+			zap.String("uri", req.Params.URI),
+			zap.String("template", fi.URITemplate))
+		return nil, fmt.Errorf("URI does not match template")
+	}
+
+	for _, paramName := range uriTmpl.Varnames() {
+		if val := matches.Get(paramName); val.Valid() {
+			argsMap[paramName] = val.String()
+		} else {
+			logger.Error("Missing required parameter in resource template",
+				zap.String("parameter", paramName),
+				zap.String("uri", req.Params.URI),
+				zap.String("template", fi.URITemplate))
+			return nil, fmt.Errorf("missing required parameter: %s", paramName)
+		}
+	}
+
+	argsBytes, err := json.Marshal(argsMap)
+	if err != nil {
+		logger.Error("Failed to marshal file resource template arguments",
+			zap.String("uri", req.Params.URI),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to prepare arguments: %w", err)
+	}
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	filePath, _, err := fi.buildPath(ctx, argsBytes, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	return fi.readResource(ctx, req.Params.URI, filePath)
+}
+
+// readResource reads the file, or lists the files for list operations, and returns them as a resource.
+func (fi *FileInvoker) readResource(ctx context.Context, uri, filePath string) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+
+	contents := &mcp.ResourceContents{URI: uri}
+	if fi.Operation == OperationList {
+		files, err := fi.listFiles(ctx, filePath)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		text, err := json.Marshal(files)
+		if err != nil {
+			logger.Error("Failed to encode file list", zap.String("uri", uri), zap.Error(err))
+			return nil, fmt.Errorf("failed to encode file list: %w", err)
+		}
+		contents.MIMEType = "application/json"
+		contents.Text = string(text)
+	} else {
+		fc, err := fi.readFile(ctx, filePath)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		contents.MIMEType = fc.mimeType
+		if fc.isText() {
+			contents.Text = string(fc.data)
+		} else {
+			contents.Blob = fc.data
+		}
+	}
+
+	logger.Info("File resource invocation completed successfully", zap.String("uri", uri))
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{contents},
+	}, nil
+}
+
+// newPathBuilder creates a new builder for the path template, resolving header references from incomingHeaders
+// and secret references from the secret store of ctx.
+// A new builder is created for each invocation to avoid sharing state.
+func (fi *FileInvoker) newPathBuilder(ctx context.Context, incomingHeaders nethttp.Header) (*template.TemplateBuilder, error) {
+	builder, err := template.NewTemplateBuilder(fi.Path, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path builder: %w", err)
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	return builder, nil
+}
+
+// buildPath parses and validates the request arguments and returns the path relative to the root along
+// with the parsed arguments.
+func (fi *FileInvoker) buildPath(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) (string, map[string]any, error) {
+	logger := logging.FromContext(ctx)
+
+	builder, err := fi.newPathBuilder(ctx, incomingHeaders)
+	if err != nil {
+		logger.Error("Failed to create path builder", zap.Error(err))
+		return "", nil, err
+	}
+
+	dj := &invocation.DynamicJson{
+		Builders: []invocation.Builder{builder},
+	}
+
+	parsed, err := dj.ParseJson(argsBytes, fi.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return "", nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := fi.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return "", nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	filePath, err := fi.resolvePath(builder)
+	if err != nil {
+		logger.Error("Failed to build file path", zap.Error(err))
+		return "", nil, err
+	}
+
+	return filePath, parsed, nil
+}
+
+// buildPathFromPromptArgs validates the prompt arguments and returns the path relative to the root.
+func (fi *FileInvoker) buildPathFromPromptArgs(ctx context.Context, promptArgs map[string]string, incomingHeaders nethttp.Header) (string, error) {
+	logger := logging.FromContext(ctx)
+
+	builder, err := fi.newPathBuilder(ctx, incomingHeaders)
+	if err != nil {
+		logger.Error("Failed to create path builder", zap.Error(err))
+		return "", err
+	}
+
+	// Convert to map[string]any for validation and populate the path builder
+	argsForValidation := make(map[string]any, len(promptArgs))
+	for argName, argValue := range promptArgs {
+		builder.SetField(argName, argValue)
+		argsForValidation[argName] = argValue
+	}
+
+	if err := fi.InputSchema.Validate(argsForValidation); err != nil {
+		logger.Error("Failed to validate prompt request arguments", zap.Error(err))
+		return "", fmt.Errorf("failed to validate prompt request: %w", err)
+	}
+
+	filePath, err := fi.resolvePath(builder)
+	if err != nil {
+		logger.Error("Failed to build file path", zap.Error(err))
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+// resolvePath renders the path template and checks that the path stays inside the root directory.
+func (fi *FileInvoker) resolvePath(builder *template.TemplateBuilder) (string, error) {
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", fmt.Errorf("failed to build path: %w", err)
+	}
+
+	filePath := path.Clean(filepath.ToSlash(result.(string)))
+	if !fs.ValidPath(filePath) {
+		return "", fmt.Errorf("invalid path '%s': must be relative to the root and must not contain '..'", result)
+	}
+
+	return filePath, nil
+}
+
+// content returns the value of the content property in the parsed arguments. Values that are not
+// strings are written as JSON.
+func (fi *FileInvoker) content(parsed map[string]any) ([]byte, error) {
+	val, ok := parsed[fi.ContentProperty]
+	if !ok || val == nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "missing required field: %s", fi.ContentProperty)
+	}
+
+	if s, ok := val.(string); ok {
+		return []byte(s), nil
+	}
+
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode '%s': %w", fi.ContentProperty, err)
+	}
+
+	return encoded, nil
+}
+
+// fileErrorCode returns the code of a failed file access: files that don't exist, or already exist when
+// they must not, are requested by the arguments of the call.
+func fileErrorCode(err error) invocation.ErrorCode {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrExist) || errors.Is(err, fs.ErrInvalid) {
+		return invocation.ErrorCodeValidation
+	}
+	return invocation.ErrorCodeBackendStatus
+}
+
+// openRoot resolves the root directory and opens it. Every file access goes through the returned
+// root, which rejects paths escaping it, including through symbolic links.
+func (fi *FileInvoker) openRoot() (*os.Root, error) {
+	rootBuilder, err := template.NewTemplateBuilder(fi.Root, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root builder: %w", err)
+	}
+
+	dir, err := rootBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root: %w", err)
+	}
+
+	return os.OpenRoot(dir.(string))
+}
+
+// readFile reads the file at filePath under the root directory. Logs sensitive path details to baseLogger only.
+func (fi *FileInvoker) readFile(ctx context.Context, filePath string) (*fileContent, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	_, span := tracing.Start(ctx, "read file")
+	var data []byte
+	err := fi.withRoot(func(root *os.Root) (err error) {
+		data, err = root.ReadFile(filePath)
+		return err
+	})
+	tracing.End(span, err)
+	if err != nil {
+		baseLogger.Error("Failed to read file", zap.String("path", filePath), zap.Error(err))
+		logger.Error("Failed to read file")
+		return nil, err
+	}
+
+	baseLogger.Debug("Read file", zap.String("path", filePath), zap.Int("size", len(data)))
+
+	return &fileContent{
+		path:     filePath,
+		mimeType: fi.detectMIMEType(filePath, data),
+		data:     data,
+	}, nil
+}
+
+// writeFile writes content to the file at filePath under the root directory, creating its parent
+// directories. Logs sensitive path details to baseLogger only.
+func (fi *FileInvoker) writeFile(ctx context.Context, filePath string, content []byte) error {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	_, span := tracing.Start(ctx, "write file")
+	err := fi.withRoot(func(root *os.Root) error {
+		if dir := path.Dir(filePath); dir != "." {
+			if err := root.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+
+		if !fi.Append {
+			return root.WriteFile(filePath, content, 0o644)
+		}
+
+		f, err := root.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(content); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	})
+	tracing.End(span, err)
+	if err != nil {
+		baseLogger.Error("Failed to write file", zap.String("path", filePath), zap.Bool("append", fi.Append), zap.Error(err))
+		logger.Error("Failed to write file")
+		return err
+	}
+
+	baseLogger.Info("Wrote file", zap.String("path", filePath), zap.Bool("append", fi.Append), zap.Int("size", len(content)))
+
+	return nil
+}
+
+// listFiles returns the files under the root directory matching the glob pattern, in lexical order.
+// Matches that cannot be accessed, such as symbolic links pointing outside of the root, are skipped.
+func (fi *FileInvoker) listFiles(ctx context.Context, pattern string) ([]map[string]any, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	var files []map[string]any
+	_, span := tracing.Start(ctx, "list files")
+	err := fi.withRoot(func(root *os.Root) error {
+		fsys := root.FS()
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+
+		files = make([]map[string]any, 0, len(matches))
+		for _, match := range matches {
+			info, err := fs.Stat(fsys, match)
+			if err != nil {
+				continue
+			}
+			files = append(files, map[string]any{
+				"path":  match,
+				"size":  info.Size(),
+				"isDir": info.IsDir(),
+			})
+		}
+		return nil
+	})
+	tracing.End(span, err)
+	if err != nil {
+		baseLogger.Error("Failed to list files", zap.String("pattern", pattern), zap.Error(err))
+		logger.Error("Failed to list files")
+		return nil, err
+	}
+
+	baseLogger.Debug("Listed files", zap.String("pattern", pattern), zap.Int("count", len(files)))
+
+	return files, nil
+}
+
+// withRoot opens the root directory, calls fn with it and closes it.
+func (fi *FileInvoker) withRoot(fn func(root *os.Root) error) error {
+	root, err := fi.openRoot()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = root.Close()
+	}()
+
+	return fn(root)
+}
+
+// detectMIMEType returns the configured MIME type, or the MIME type of the file extension, or the MIME
+// type sniffed from the content of the file, without parameters.
+func (fi *FileInvoker) detectMIMEType(filePath string, data []byte) string {
+	if fi.MIMEType != "" {
+		return fi.MIMEType
+	}
+
+	mimeType := mime.TypeByExtension(path.Ext(filePath))
+	if mimeType == "" {
+		mimeType = nethttp.DetectContentType(data)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+
+	return mimeType
+}
+
+// fileURI returns a file URI for a path relative to the root directory.
+func fileURI(filePath string) string {
+	return (&url.URL{Scheme: "file", Path: "/" + filePath}).String()
+}
@@ -0,0 +1,322 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader is the start of a PNG file, which is not valid UTF-8.
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, 0x00, 0x0d}
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"name":    {Type: invocation.JsonSchemaTypeString},
+		"content": {Type: invocation.JsonSchemaTypeString},
+	},
+}
+
+// testRoot creates a root directory with a few files and a symbolic link pointing outside of it.
+func testRoot(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "hello.txt"), []byte("hello world"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte("some notes"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config.json"), []byte(`{"debug":true}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "logo.png"), pngHeader, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "intro.md"), []byte("# Intro"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "link.txt")))
+
+	return root
+}
+
+// testInvoker creates a FileInvoker for primitive through the invoker factory.
+func testInvoker(t *testing.T, primitive invocation.Primitive, config *FileInvocationConfig) *FileInvoker {
+	t.Helper()
+
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, primitive)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*FileInvoker)
+}
+
+func testTool(t *testing.T) *definitions.Tool {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+
+	return &definitions.Tool{
+		Name:                "files",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+}
+
+func toolRequest(args string) *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(args)},
+	}
+}
+
+func TestFileInvokerInvoke(t *testing.T) {
+	root := testRoot(t)
+	t.Setenv("TEST_FILE_ROOT", root)
+
+	tt := []struct {
+		name            string
+		config          *FileInvocationConfig
+		args            string
+		expectedContent mcp.Content
+		errResultPrefix string
+		errContains     string
+	}{
+		{
+			name:            "read text file",
+			config:          &FileInvocationConfig{Root: root, Path: "{name}.txt"},
+			args:            `{"name": "hello"}`,
+			expectedContent: &mcp.TextContent{Text: "hello world"},
+		},
+		{
+			name:            "root from environment variable",
+			config:          &FileInvocationConfig{Root: "${TEST_FILE_ROOT}", Path: "docs/intro.md"},
+			args:            `{}`,
+			expectedContent: &mcp.TextContent{Text: "# Intro"},
+		},
+		{
+			name:            "read image",
+			config:          &FileInvocationConfig{Root: root, Path: "logo.png"},
+			args:            `{}`,
+			expectedContent: &mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"},
+		},
+		{
+			name:   "read binary file",
+			config: &FileInvocationConfig{Root: root, Path: "logo.png", MIMEType: "application/octet-stream"},
+			args:   `{}`,
+			expectedContent: &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI:      "file:///logo.png",
+				MIMEType: "application/octet-stream",
+				Blob:     pngHeader,
+			}},
+		},
+		{
+			name:            "list files",
+			config:          &FileInvocationConfig{Root: root, Operation: OperationList, Path: "*.txt"},
+			args:            `{}`,
+			expectedContent: &mcp.TextContent{Text: `{"files":[{"isDir":false,"path":"hello.txt","size":11},{"isDir":false,"path":"notes.txt","size":10}]}`},
+		},
+		{
+			name:            "missing file",
+			config:          &FileInvocationConfig{Root: root, Path: "{name}.txt"},
+			args:            `{"name": "missing"}`,
+			errResultPrefix: "failed to read file: ",
+		},
+		{
+			name:        "path traversal",
+			config:      &FileInvocationConfig{Root: root, Path: "{name}.txt"},
+			args:        `{"name": "../secret"}`,
+			errContains: "invalid path '../secret.txt'",
+		},
+		{
+			name:            "symbolic link outside of the root",
+			config:          &FileInvocationConfig{Root: root, Path: "link.txt"},
+			args:            `{}`,
+			errResultPrefix: "failed to read file: ",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testInvoker(t, testTool(t), tc.config)
+
+			result, err := invoker.Invoke(context.Background(), toolRequest(tc.args))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			if tc.errResultPrefix != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.errResultPrefix)
+				return
+			}
+			assert.False(t, result.IsError)
+			assert.Equal(t, tc.expectedContent, result.Content[0])
+		})
+	}
+}
+
+func TestFileInvokerWrite(t *testing.T) {
+	root := testRoot(t)
+
+	tt := []struct {
+		name     string
+		config   *FileInvocationConfig
+		calls    []string
+		path     string
+		expected string
+	}{
+		{
+			name:     "write new file in new directory",
+			config:   &FileInvocationConfig{Root: root, Operation: OperationWrite, Path: "out/{name}.txt"},
+			calls:    []string{`{"name": "a", "content": "first"}`},
+			path:     "out/a.txt",
+			expected: "first",
+		},
+		{
+			name:     "replace file",
+			config:   &FileInvocationConfig{Root: root, Operation: OperationWrite, Path: "{name}.txt"},
+			calls:    []string{`{"name": "b", "content": "first"}`, `{"name": "b", "content": "second"}`},
+			path:     "b.txt",
+			expected: "second",
+		},
+		{
+			name:     "append to file",
+			config:   &FileInvocationConfig{Root: root, Operation: OperationWrite, Path: "{name}.log", Append: true},
+			calls:    []string{`{"name": "c", "content": "first\n"}`, `{"name": "c", "content": "second\n"}`},
+			path:     "c.log",
+			expected: "first\nsecond\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testInvoker(t, testTool(t), tc.config)
+
+			for _, args := range tc.calls {
+				result, err := invoker.Invoke(context.Background(), toolRequest(args))
+				require.NoError(t, err)
+				require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+			}
+
+			data, err := os.ReadFile(filepath.Join(root, tc.path))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+
+	t.Run("write through symbolic link outside of the root", func(t *testing.T) {
+		invoker := testInvoker(t, testTool(t), &FileInvocationConfig{Root: root, Operation: OperationWrite, Path: "link.txt"})
+
+		result, err := invoker.Invoke(context.Background(), toolRequest(`{"content": "overwritten"}`))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(root), "secret.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(data))
+	})
+}
+
+func TestFileInvokerResources(t *testing.T) {
+	root := testRoot(t)
+
+	t.Run("resource", func(t *testing.T) {
+		resource := &definitions.Resource{Name: "config", URI: "config://app"}
+		invoker := testInvoker(t, resource, &FileInvocationConfig{Root: root, Path: "config.json"})
+
+		result, err := invoker.InvokeResource(context.Background(), &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: "config://app"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*mcp.ResourceContents{
+			{URI: "config://app", MIMEType: "application/json", Text: `{"debug":true}`},
+		}, result.Contents)
+	})
+
+	t.Run("resource template", func(t *testing.T) {
+		schema := &jsonschema.Schema{
+			Type:       invocation.JsonSchemaTypeObject,
+			Properties: map[string]*jsonschema.Schema{"page": {Type: invocation.JsonSchemaTypeString}},
+		}
+		resolved, err := schema.Resolve(nil)
+		require.NoError(t, err)
+
+		resourceTemplate := &definitions.ResourceTemplate{
+			Name:                "docs",
+			URITemplate:         "docs://{page}",
+			InputSchema:         schema,
+			ResolvedInputSchema: resolved,
+		}
+		invoker := testInvoker(t, resourceTemplate, &FileInvocationConfig{Root: root, Path: "docs/{page}.md"})
+
+		result, err := invoker.InvokeResourceTemplate(context.Background(), &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: "docs://intro"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "# Intro", result.Contents[0].Text)
+	})
+
+	t.Run("prompt", func(t *testing.T) {
+		prompt := &definitions.Prompt{Name: "notes", InputSchema: testSchema}
+		resolved, err := testSchema.Resolve(nil)
+		require.NoError(t, err)
+		prompt.ResolvedInputSchema = resolved
+
+		invoker := testInvoker(t, prompt, &FileInvocationConfig{Root: root, Path: "{name}.txt"})
+
+		result, err := invoker.InvokePrompt(context.Background(), &mcp.GetPromptRequest{
+			Params: &mcp.GetPromptParams{Arguments: map[string]string{"name": "notes"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Messages, 1)
+		assert.Equal(t, &mcp.TextContent{Text: "some notes"}, result.Messages[0].Content)
+	})
+}
+
+func TestInvokerFactoryErrors(t *testing.T) {
+	tt := []struct {
+		name        string
+		primitive   invocation.Primitive
+		config      *FileInvocationConfig
+		errContains string
+	}{
+		{
+			name:        "write for resource",
+			primitive:   &definitions.Resource{Name: "r", URI: "file:///r"},
+			config:      &FileInvocationConfig{Root: "/data", Operation: OperationWrite, Path: "r.txt"},
+			errContains: "write operations are only supported for tools",
+		},
+		{
+			name:        "content property missing from input schema",
+			primitive:   &definitions.Tool{Name: "t", InputSchema: testSchema},
+			config:      &FileInvocationConfig{Root: "/data", Operation: OperationWrite, Path: "t.txt", ContentProperty: "body"},
+			errContains: "content property 'body' is not defined in the input schema",
+		},
+		{
+			name:        "static resource path with template variables",
+			primitive:   &definitions.Resource{Name: "r", URI: "file:///r", InputSchema: testSchema},
+			config:      &FileInvocationConfig{Root: "/data", Path: "{name}.txt"},
+			errContains: "static resource path cannot contain template variables",
+		},
+		{
+			name:        "root with input parameters",
+			primitive:   &definitions.Tool{Name: "t", InputSchema: testSchema},
+			config:      &FileInvocationConfig{Root: "/data/{name}", Path: "t.txt"},
+			errContains: "failed to parse root",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&InvokerFactory{}).CreateInvoker(tc.config, tc.primitive)
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
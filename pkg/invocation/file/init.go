@@ -0,0 +1,11 @@
+package file
+
+import "github.com/genmcp/gen-mcp/pkg/invocation"
+
+const (
+	InvocationType = "file"
+)
+
+func init() {
+	invocation.RegisterFactory(InvocationType, &InvokerFactory{})
+}
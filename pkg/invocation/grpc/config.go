@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// GrpcInvocationConfig is the configuration for calling a unary method of a gRPC server.
+// The request and response messages of the method are resolved with the server reflection service of the
+// server, so the server must register it.
+type GrpcInvocationConfig struct {
+	// The address of the gRPC server, as host:port.
+	// It can reference environment variables using '${VAR_NAME}' syntax.
+	Address string `json:"address" jsonschema:"required"`
+
+	// The full name of the method to call, as package.Service/Method.
+	// The arguments of the tool are sent as the request message, in the JSON mapping of protobuf.
+	Method string `json:"method" jsonschema:"required"`
+
+	// If true, the connection uses TLS, verified with the CA certificates of the system. Defaults to plaintext.
+	TLS bool `json:"tls,omitempty" jsonschema:"optional"`
+
+	// Metadata sent with the request.
+	//
+	// Values can contain placeholders in the form of {paramName} which correspond to parameters from the input schema.
+	// Values can contain placeholders in the form of {headers.paramName} which correspond to headers from the incoming
+	// http request (won't work in stdio).
+	// Values can contain placeholders in the form of ${ENV_VAR_NAME} or {env.ENV_VAR_NAME} which correspond to env vars
+	Metadata map[string]string `json:"metadata,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of the call, as a duration string (e.g. "10s"). Defaults to no limit.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &GrpcInvocationConfig{}
+var _ invocation.URLReferencer = &GrpcInvocationConfig{}
+
+func (c *GrpcInvocationConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+
+	if _, _, err := splitMethod(c.Method); err != nil {
+		return err
+	}
+
+	for key := range c.Metadata {
+		if key == "" || key != strings.ToLower(key) {
+			return fmt.Errorf("invalid metadata key '%s': must be lower case", key)
+		}
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+// ReferencedURLs returns the address of the server, as a URL.
+func (c *GrpcInvocationConfig) ReferencedURLs() []string {
+	if strings.Contains(c.Address, "://") {
+		return []string{c.Address}
+	}
+	return []string{"grpc://" + c.Address}
+}
+
+func (c *GrpcInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	var metadata map[string]string
+	if c.Metadata != nil {
+		metadata = make(map[string]string, len(c.Metadata))
+		for k, v := range c.Metadata {
+			metadata[k] = v
+		}
+	}
+
+	return &GrpcInvocationConfig{
+		Address:  c.Address,
+		Method:   c.Method,
+		TLS:      c.TLS,
+		Metadata: metadata,
+		Timeout:  c.Timeout,
+	}
+}
+
+// splitMethod splits the full name of a method, as package.Service/Method, into the full name of its service
+// and its name.
+func splitMethod(method string) (string, string, error) {
+	service, name, found := strings.Cut(method, "/")
+	if !found || service == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid method '%s': must be the full name of a method, as package.Service/Method", method)
+	}
+	return service, name, nil
+}
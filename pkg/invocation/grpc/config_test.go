@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrpcInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *GrpcInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "valid config",
+			config: &GrpcInvocationConfig{
+				Address:  "${USERS_ADDRESS}",
+				Method:   "users.v1.UserService/GetUser",
+				Metadata: map[string]string{"authorization": "Bearer {secrets.USERS_TOKEN}"},
+				Timeout:  "10s",
+			},
+		},
+		{
+			name:          "missing address",
+			config:        &GrpcInvocationConfig{Method: "users.v1.UserService/GetUser"},
+			expectedError: "address is required",
+		},
+		{
+			name:          "method without service",
+			config:        &GrpcInvocationConfig{Address: "localhost:50051", Method: "GetUser"},
+			expectedError: "invalid method 'GetUser'",
+		},
+		{
+			name:          "method with leading slash",
+			config:        &GrpcInvocationConfig{Address: "localhost:50051", Method: "/users.v1.UserService/GetUser"},
+			expectedError: "invalid method '/users.v1.UserService/GetUser'",
+		},
+		{
+			name: "upper case metadata key",
+			config: &GrpcInvocationConfig{
+				Address:  "localhost:50051",
+				Method:   "users.v1.UserService/GetUser",
+				Metadata: map[string]string{"Authorization": "token"},
+			},
+			expectedError: "invalid metadata key 'Authorization'",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &GrpcInvocationConfig{Address: "localhost:50051", Method: "users.v1.UserService/GetUser", Timeout: "soon"},
+			expectedError: "invalid timeout 'soon'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestGrpcInvocationConfig_ReferencedURLs(t *testing.T) {
+	tt := []struct {
+		name     string
+		address  string
+		expected []string
+	}{
+		{
+			name:     "host and port",
+			address:  "users.example.com:50051",
+			expected: []string{"grpc://users.example.com:50051"},
+		},
+		{
+			name:     "target with a scheme",
+			address:  "dns:///users.example.com:50051",
+			expected: []string{"dns:///users.example.com:50051"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &GrpcInvocationConfig{Address: tc.address, Method: "users.v1.UserService/GetUser"}
+			assert.Equal(t, tc.expected, config.ReferencedURLs())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &GrpcInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	gic, ok := config.(*GrpcInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for grpc invoker factory")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for grpc invocations")
+	}
+
+	parsedAddress, err := template.ParseTemplate(gic.Address, template.TemplateParserOptions{Tool: invocation.ToolName(primitive), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse address: %w", err)
+	}
+	for _, v := range parsedAddress.Variables {
+		if v.Type != template.VariableTypeEnv {
+			return nil, fmt.Errorf("address can only reference environment variables, got '%s'", v.Name)
+		}
+	}
+
+	metadata := make(map[string]*template.ParsedTemplate, len(gic.Metadata))
+	for key, value := range gic.Metadata {
+		parsed, err := template.ParseTemplate(value, template.TemplateParserOptions{
+			InputSchema: primitive.GetInputSchema(),
+			Sources:     template.CreateSourceFactories(),
+			Tool:        invocation.ToolName(primitive),
+			Allowlist:   template.PrimitiveAllowlist(primitive),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metadata '%s': %w", key, err)
+		}
+		metadata[key] = parsed
+	}
+
+	var timeout time.Duration
+	if gic.Timeout != "" {
+		timeout, err = time.ParseDuration(gic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", gic.Timeout, err)
+		}
+	}
+
+	uriTemplate := primitive.GetURITemplate()
+	if uriTemplate != "" {
+		_, err = uritemplate.New(uriTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI template '%s': %w", uriTemplate, err)
+		}
+	}
+
+	return &GrpcInvoker{
+		Address:     parsedAddress,
+		Method:      gic.Method,
+		TLS:         gic.TLS,
+		Metadata:    metadata,
+		Timeout:     timeout,
+		InputSchema: primitive.GetResolvedInputSchema(),
+		URITemplate: uriTemplate,
+	}, nil
+}
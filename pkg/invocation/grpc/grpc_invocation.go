@@ -0,0 +1,458 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type GrpcInvoker struct {
+	Address     *template.ParsedTemplate            // Parsed address of the server, may reference environment variables
+	Method      string                              // Full name of the method, as package.Service/Method
+	TLS         bool                                // Whether the connection uses TLS
+	Metadata    map[string]*template.ParsedTemplate // Parsed metadata values, by key
+	Timeout     time.Duration                       // Maximum duration of a call, no limit if 0
+	InputSchema *jsonschema.Resolved                // InputSchema for the tool
+	URITemplate string                              // MCP URI template (for resource templates only)
+
+	mu         sync.Mutex
+	descriptor protoreflect.MethodDescriptor // resolved with server reflection on the first call
+}
+
+var _ invocation.Invoker = &GrpcInvoker{}
+var _ invocation.DryRunner = &GrpcInvoker{}
+
+func (gi *GrpcInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting gRPC tool invocation")
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	parsed, err := gi.parseArgs(ctx, req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := gi.call(ctx, parsed, incomingHeaders, nil)
+	if err != nil {
+		result := utils.McpTextError("gRPC call failed: %v", err)
+		invocation.SetErrorDetail(result, grpcErrorDetail(err))
+		return result, nil
+	}
+
+	var structured map[string]any
+	if err := json.Unmarshal(response, &structured); err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to decode gRPC response: %v", err), nil
+	}
+
+	logger.Info("gRPC tool invocation completed successfully")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(response),
+			},
+		},
+		StructuredContent: structured,
+	}, nil
+}
+
+// DryRun returns the request message Invoke would send for req, in its JSON mapping, and the metadata sent
+// with it, without connecting to the server.
+func (gi *GrpcInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	parsed, err := gi.parseArgs(ctx, req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := gi.address()
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := gi.metadata(ctx, parsed, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	headers := make(nethttp.Header, len(md))
+	for key, values := range md {
+		headers[nethttp.CanonicalHeaderKey(key)] = values
+	}
+
+	return &invocation.DryRunResult{
+		Type:    InvocationType,
+		Method:  gi.Method,
+		URL:     address,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+func (gi *GrpcInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting gRPC prompt invocation")
+
+	promptArgs := req.Params.Arguments
+	if promptArgs == nil {
+		promptArgs = make(map[string]string)
+	}
+
+	argsBytes, err := json.Marshal(promptArgs)
+	if err != nil {
+		logger.Error("Failed to marshal gRPC prompt request arguments", zap.Error(err))
+		return nil, fmt.Errorf("failed to prepare prompt request: %w", err)
+	}
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	parsed, err := gi.parseArgs(ctx, argsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := gi.call(ctx, parsed, incomingHeaders, nil)
+	if err != nil {
+		return utils.McpPromptTextError("gRPC call failed: %v", err), nil
+	}
+
+	logger.Info("gRPC prompt invocation completed successfully")
+
+	return &mcp.GetPromptResult{
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: string(response)},
+			},
+		},
+	}, nil
+}
+
+func (gi *GrpcInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting gRPC resource invocation", zap.String("uri", req.Params.URI))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	return gi.readResource(ctx, req.Params.URI, map[string]any{}, incomingHeaders, map[string]string{"uri": req.Params.URI})
+}
+
+func (gi *GrpcInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting gRPC resource template invocation", zap.String("uri", req.Params.URI))
+
+	// URI template syntax is validated during parsing, so we can safely use it here
+	argsMap := make(map[string]any)
+	uriTmpl, _ := uritemplate.New(gi.URITemplate)
+
+	// Match the incoming URI against the template to extract argument values
+	matches := uriTmpl.Match(req.Params.URI)
+	if matches == nil {
+		logger.Error("URI does not match gRPC resource template",
+			zap.String("uri", req.Params.URI),
+			zap.String("template", gi.URITemplate))
+		return nil, fmt.Errorf("URI does not match template")
+	}
+
+	for _, paramName := range uriTmpl.Varnames() {
+		if val := matches.Get(paramName); val.Valid() {
+			argsMap[paramName] = val.String()
+		} else {
+			logger.Error("Missing required parameter in resource template",
+				zap.String("parameter", paramName),
+				zap.String("uri", req.Params.URI),
+				zap.String("template", gi.URITemplate))
+			return nil, fmt.Errorf("missing required parameter: %s", paramName)
+		}
+	}
+
+	argsBytes, err := json.Marshal(argsMap)
+	if err != nil {
+		logger.Error("Failed to marshal gRPC resource template arguments",
+			zap.String("uri", req.Params.URI),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to prepare arguments: %w", err)
+	}
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	parsed, err := gi.parseArgs(ctx, argsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return gi.readResource(ctx, req.Params.URI, parsed, incomingHeaders, map[string]string{
+		"uri":      req.Params.URI,
+		"template": gi.URITemplate,
+	})
+}
+
+// readResource calls the method and returns the response as a JSON resource.
+func (gi *GrpcInvoker) readResource(ctx context.Context, uri string, args map[string]any, incomingHeaders nethttp.Header, contextInfo map[string]string) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+
+	response, err := gi.call(ctx, args, incomingHeaders, contextInfo)
+	if err != nil {
+		logger.Error("gRPC resource call failed", zap.String("uri", uri))
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	logger.Info("gRPC resource invocation completed successfully", zap.String("uri", uri))
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(response),
+			},
+		},
+	}, nil
+}
+
+// parseArgs parses and validates the request arguments.
+func (gi *GrpcInvoker) parseArgs(ctx context.Context, argsBytes []byte) (map[string]any, error) {
+	logger := logging.FromContext(ctx)
+
+	dj := &invocation.DynamicJson{}
+
+	parsed, err := dj.ParseJson(argsBytes, gi.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := gi.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// call sends the arguments as the request message of the method and returns the response message in its JSON
+// mapping. Logs sensitive call details to baseLogger only.
+func (gi *GrpcInvoker) call(
+	ctx context.Context,
+	args map[string]any,
+	incomingHeaders nethttp.Header,
+	contextInfo map[string]string, // additional context for logging (e.g., "uri", "template")
+) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	address, err := gi.address()
+	if err != nil {
+		return nil, err
+	}
+
+	logFields := []zap.Field{
+		zap.String("address", address),
+		zap.String("method", gi.Method),
+	}
+	for k, v := range contextInfo {
+		logFields = append(logFields, zap.String(k, v))
+	}
+
+	baseLogger.Debug("Calling gRPC method", logFields...)
+
+	conn, err := getConn(ctx, address, gi.TLS)
+	if err != nil {
+		baseLogger.Error("Failed to connect to gRPC server", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to connect to gRPC server")
+		return nil, err
+	}
+
+	md, err := gi.metadata(ctx, args, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	if gi.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gi.Timeout)
+		defer cancel()
+	}
+
+	service, method, _ := splitMethod(gi.Method)
+	callCtx, span := tracing.Start(ctx, gi.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	descriptor, err := gi.resolve(callCtx, conn)
+	if err != nil {
+		baseLogger.Error("Failed to resolve gRPC method", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to resolve gRPC method")
+		return nil, err
+	}
+
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	request := dynamicpb.NewMessage(descriptor.Input())
+	if err = protojson.Unmarshal(argsJSON, request); err != nil {
+		return nil, fmt.Errorf("failed to build request message: %w", err)
+	}
+
+	response := dynamicpb.NewMessage(descriptor.Output())
+	if err = conn.Invoke(metadata.NewOutgoingContext(callCtx, md), "/"+gi.Method, request, response); err != nil {
+		baseLogger.Error("gRPC call failed", append(logFields, zap.Error(err))...)
+		logger.Error("gRPC call failed")
+		return nil, err
+	}
+
+	responseJSON, err := protojson.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response message: %w", err)
+	}
+
+	baseLogger.Info("gRPC call completed successfully", logFields...)
+
+	return responseJSON, nil
+}
+
+// resolve returns the descriptor of the method, resolving it with the server reflection service of the server
+// on the first call. Streaming methods are not supported.
+func (gi *GrpcInvoker) resolve(ctx context.Context, conn *grpclib.ClientConn) (protoreflect.MethodDescriptor, error) {
+	gi.mu.Lock()
+	defer gi.mu.Unlock()
+
+	if gi.descriptor != nil {
+		return gi.descriptor, nil
+	}
+
+	descriptor, err := NewResolver(conn).Method(ctx, gi.Method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve method %s with server reflection: %w", gi.Method, err)
+	}
+	if descriptor.IsStreamingClient() || descriptor.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is a streaming method, only unary methods are supported", gi.Method)
+	}
+
+	gi.descriptor = descriptor
+
+	return descriptor, nil
+}
+
+// address resolves the environment variables of the address.
+func (gi *GrpcInvoker) address() (string, error) {
+	builder, err := template.NewTemplateBuilder(gi.Address, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create address builder: %w", err)
+	}
+
+	address, err := builder.GetResult()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve address: %w", err)
+	}
+
+	return address.(string), nil
+}
+
+// metadata renders the metadata sent with the call.
+func (gi *GrpcInvoker) metadata(ctx context.Context, args map[string]any, incomingHeaders nethttp.Header) (metadata.MD, error) {
+	md := metadata.MD{}
+	for key, value := range gi.Metadata {
+		builder, err := template.NewTemplateBuilder(value, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metadata builder: %w", err)
+		}
+
+		for name, arg := range args {
+			builder.SetField(name, arg)
+		}
+		builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+		builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+		builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+		if incomingHeaders != nil {
+			builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+		}
+
+		rendered, err := builder.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render metadata '%s': %w", key, err)
+		}
+
+		md.Set(strings.ToLower(key), rendered.(string))
+	}
+
+	return md, nil
+}
+
+// grpcErrorDetail returns the ErrorDetail of a failed call, from the status returned by the server.
+func grpcErrorDetail(err error) invocation.ErrorDetail {
+	st, ok := status.FromError(err)
+	if !ok {
+		return invocation.NewErrorDetail(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), 0)
+	}
+
+	switch st.Code() {
+	case codes.Unavailable:
+		return invocation.NewErrorDetail(invocation.ErrorCodeBackendUnavailable, 0)
+	case codes.DeadlineExceeded:
+		return invocation.NewErrorDetail(invocation.ErrorCodeTimeout, 0)
+	case codes.InvalidArgument:
+		return invocation.NewErrorDetail(invocation.ErrorCodeValidation, int(st.Code()))
+	default:
+		return invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, int(st.Code()))
+	}
+}
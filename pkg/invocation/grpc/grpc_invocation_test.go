@@ -0,0 +1,246 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/netpolicy"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+)
+
+var resolvedHealthCheck, _ = (&jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"service": {Type: invocation.JsonSchemaTypeString},
+	},
+}).Resolve(nil)
+
+// testServer starts a gRPC server with the health and server reflection services, and returns its address
+// along with a function returning the metadata of the last call it received
+func testServer(t *testing.T) (string, func() metadata.MD) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "failed to listen")
+
+	var lastMD metadata.MD
+	server := grpclib.NewServer(grpclib.UnaryInterceptor(
+		func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+			lastMD, _ = metadata.FromIncomingContext(ctx)
+			return handler(ctx, req)
+		},
+	))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String(), func() metadata.MD { return lastMD }
+}
+
+// testGrpcInvoker creates a GrpcInvoker for testing
+func testGrpcInvoker(t *testing.T, address, method string, md map[string]string) *GrpcInvoker {
+	t.Helper()
+
+	parsedAddress, err := template.ParseTemplate(address, template.TemplateParserOptions{})
+	require.NoError(t, err, "failed to parse address")
+
+	parsedMetadata := make(map[string]*template.ParsedTemplate, len(md))
+	for key, value := range md {
+		parsed, err := template.ParseTemplate(value, template.TemplateParserOptions{
+			InputSchema: resolvedHealthCheck.Schema(),
+			Sources:     template.CreateHeadersSourceFactory(),
+		})
+		require.NoError(t, err, "failed to parse metadata")
+		parsedMetadata[key] = parsed
+	}
+
+	return &GrpcInvoker{
+		Address:     parsedAddress,
+		Method:      method,
+		Metadata:    parsedMetadata,
+		InputSchema: resolvedHealthCheck,
+	}
+}
+
+func TestGrpcInvoker_Invoke(t *testing.T) {
+	address, lastMD := testServer(t)
+
+	tt := []struct {
+		name               string
+		method             string
+		metadata           map[string]string
+		args               string
+		headers            http.Header
+		expectedStructured map[string]any
+		expectedMetadata   map[string]string
+		expectedError      string
+	}{
+		{
+			name:               "serving server",
+			method:             "grpc.health.v1.Health/Check",
+			args:               `{"service": ""}`,
+			expectedStructured: map[string]any{"status": "SERVING"},
+		},
+		{
+			name:               "not serving service",
+			method:             "grpc.health.v1.Health/Check",
+			args:               `{"service": "users"}`,
+			expectedStructured: map[string]any{"status": "NOT_SERVING"},
+		},
+		{
+			name:             "metadata from arguments and headers",
+			method:           "grpc.health.v1.Health/Check",
+			metadata:         map[string]string{"x-service": "{service}", "authorization": "{headers.Authorization}"},
+			args:             `{"service": ""}`,
+			headers:          http.Header{"Authorization": []string{"Bearer token"}},
+			expectedMetadata: map[string]string{"x-service": "", "authorization": "Bearer token"},
+		},
+		{
+			name:          "unknown service",
+			method:        "grpc.health.v1.Health/Check",
+			args:          `{"service": "orders"}`,
+			expectedError: "gRPC call failed",
+		},
+		{
+			name:          "unknown method",
+			method:        "grpc.health.v1.Health/Ping",
+			args:          `{}`,
+			expectedError: "has no method Ping",
+		},
+		{
+			name:          "streaming method",
+			method:        "grpc.health.v1.Health/Watch",
+			args:          `{}`,
+			expectedError: "only unary methods are supported",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testGrpcInvoker(t, address, tc.method, tc.metadata)
+
+			req := &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: []byte(tc.args)},
+				Extra:  &mcp.RequestExtra{Header: tc.headers},
+			}
+
+			result, err := invoker.Invoke(context.Background(), req)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			if tc.expectedError != "" {
+				assert.True(t, result.IsError)
+				require.Len(t, result.Content, 1)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedError)
+				return
+			}
+
+			assert.False(t, result.IsError, "unexpected error: %v", result.Content)
+			if tc.expectedStructured != nil {
+				assert.Equal(t, tc.expectedStructured, result.StructuredContent)
+			}
+			for key, value := range tc.expectedMetadata {
+				assert.Equal(t, []string{value}, lastMD().Get(key), "metadata %s", key)
+			}
+		})
+	}
+}
+
+func TestGrpcInvoker_NetworkPolicy(t *testing.T) {
+	address, _ := testServer(t)
+
+	tt := []struct {
+		name          string
+		policy        netpolicy.Config
+		expectedError string
+	}{
+		{
+			name:          "denied host",
+			policy:        netpolicy.Config{DeniedHosts: []string{"127.0.0.1"}},
+			expectedError: "127.0.0.1 is denied",
+		},
+		{
+			name:   "allowed host",
+			policy: netpolicy.Config{AllowedHosts: []string{"127.0.0.1"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := netpolicy.New(tc.policy)
+			require.NoError(t, err)
+			client := &http.Client{Transport: &http.Transport{DialContext: policy.DialContext(&net.Dialer{})}}
+			ctx := httpinvocation.WithHTTPClient(context.Background(), client)
+
+			invoker := testGrpcInvoker(t, address, "grpc.health.v1.Health/Check", nil)
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{"service": ""}`)}}
+
+			result, err := invoker.Invoke(ctx, req)
+			require.NoError(t, err)
+
+			if tc.expectedError != "" {
+				assert.True(t, result.IsError)
+				require.Len(t, result.Content, 1)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedError)
+				return
+			}
+
+			assert.False(t, result.IsError, "unexpected error: %v", result.Content)
+			assert.Equal(t, map[string]any{"status": "SERVING"}, result.StructuredContent)
+		})
+	}
+}
+
+func TestGrpcInvoker_DryRun(t *testing.T) {
+	invoker := testGrpcInvoker(t, "localhost:50051", "grpc.health.v1.Health/Check", map[string]string{
+		"authorization": "{headers.Authorization}",
+	})
+
+	req := &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{"service": "users"}`)},
+		Extra:  &mcp.RequestExtra{Header: http.Header{"Authorization": []string{"Bearer token"}}},
+	}
+
+	result, err := invoker.DryRun(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, InvocationType, result.Type)
+	assert.Equal(t, "grpc.health.v1.Health/Check", result.Method)
+	assert.Equal(t, "localhost:50051", result.URL)
+	assert.Equal(t, "Bearer token", result.Headers.Get("authorization"))
+	assert.JSONEq(t, `{"service": "users"}`, string(result.Body))
+}
+
+func TestResolver_Services(t *testing.T) {
+	address, _ := testServer(t)
+
+	conn, err := getConn(context.Background(), address, false)
+	require.NoError(t, err)
+
+	services, err := NewResolver(conn).Services(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, services, 1)
+	assert.Equal(t, "grpc.health.v1.Health", string(services[0].FullName()))
+	assert.NotNil(t, services[0].Methods().ByName("Check"))
+}
@@ -0,0 +1,11 @@
+package grpc
+
+import "github.com/genmcp/gen-mcp/pkg/invocation"
+
+const (
+	InvocationType = "grpc"
+)
+
+func init() {
+	invocation.RegisterFactory(InvocationType, &InvokerFactory{})
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"strings"
+	"sync"
+
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// connKey identifies a connection. Tools calling the same server share a single connection.
+type connKey struct {
+	address string
+	tls     bool
+	client  *nethttp.Client // the client whose transport dials the connection
+}
+
+var (
+	connsMu sync.Mutex
+	conns   = make(map[connKey]*grpclib.ClientConn)
+)
+
+// getConn returns the shared connection to the server at address, creating it if needed. The connection is
+// established on the first call made with it, with the dial function of the HTTP client of ctx, so that it is
+// subject to the network policy of the server.
+func getConn(ctx context.Context, address string, useTLS bool) (*grpclib.ClientConn, error) {
+	key := connKey{address: address, tls: useTLS, client: httpinvocation.HTTPClientFromContext(ctx)}
+
+	connsMu.Lock()
+	defer connsMu.Unlock()
+
+	if conn, ok := conns[key]; ok {
+		return conn, nil
+	}
+
+	creds := insecure.NewCredentials()
+	if key.tls {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	dial := httpinvocation.DialContextFromContext(ctx)
+	dialer := func(ctx context.Context, address string) (net.Conn, error) {
+		return dial(ctx, "tcp", address)
+	}
+
+	// the passthrough resolver leaves the resolution of host names to the dial function, which checks them
+	// against the network policy
+	target := address
+	if !strings.Contains(target, "://") {
+		target = "passthrough:///" + target
+	}
+
+	conn, err := grpclib.NewClient(target, grpclib.WithTransportCredentials(creds), grpclib.WithContextDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to %s: %w", key.address, err)
+	}
+
+	conns[key] = conn
+
+	return conn, nil
+}
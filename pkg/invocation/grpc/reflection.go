@@ -0,0 +1,219 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	grpclib "google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionServices are the services of the server reflection protocol, which are not listed by Services.
+var reflectionServices = []string{
+	"grpc.reflection.v1.ServerReflection",
+	"grpc.reflection.v1alpha.ServerReflection",
+}
+
+// Resolver resolves the descriptors of the services of a gRPC server with its server reflection service.
+type Resolver struct {
+	client reflectionpb.ServerReflectionClient
+}
+
+// NewResolver creates a resolver using the server reflection service of the server conn is connected to.
+func NewResolver(conn grpclib.ClientConnInterface) *Resolver {
+	return &Resolver{client: reflectionpb.NewServerReflectionClient(conn)}
+}
+
+// Services returns the descriptors of the services of the server, except the server reflection services.
+func (r *Resolver) Services(ctx context.Context) ([]protoreflect.ServiceDescriptor, error) {
+	stream, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	resp, err := stream.request(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var names []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		if !slices.Contains(reflectionServices, service.GetName()) {
+			names = append(names, service.GetName())
+		}
+	}
+
+	files, err := stream.files(names)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]protoreflect.ServiceDescriptor, 0, len(names))
+	for _, name := range names {
+		service, err := findService(files, name)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// Method returns the descriptor of a method, from its full name as package.Service/Method.
+func (r *Resolver) Method(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, err := splitMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	files, err := stream.files([]string{serviceName})
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := findService(files, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	md := service.Methods().ByName(protoreflect.Name(methodName))
+	if md == nil {
+		return nil, fmt.Errorf("service %s has no method %s", serviceName, methodName)
+	}
+
+	return md, nil
+}
+
+func (r *Resolver) open(ctx context.Context) (*reflectionStream, error) {
+	stream, err := r.client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server reflection stream: %w", err)
+	}
+	return &reflectionStream{stream}, nil
+}
+
+type reflectionStream struct {
+	reflectionpb.ServerReflection_ServerReflectionInfoClient
+}
+
+// request sends a request and returns its response, or the error sent by the server.
+func (s *reflectionStream) request(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := s.Send(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("server reflection error %d: %s", errResp.GetErrorCode(), errResp.GetErrorMessage())
+	}
+
+	return resp, nil
+}
+
+// files fetches the files defining the symbols, along with all their dependencies.
+func (s *reflectionStream) files(symbols []string) (*protoregistry.Files, error) {
+	fetched := make(map[string]*descriptorpb.FileDescriptorProto)
+
+	add := func(resp *reflectionpb.ServerReflectionResponse) error {
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, fd); err != nil {
+				return fmt.Errorf("failed to decode file descriptor: %w", err)
+			}
+			fetched[fd.GetName()] = fd
+		}
+		return nil
+	}
+
+	for _, symbol := range symbols {
+		resp, err := s.request(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the file defining %s: %w", symbol, err)
+		}
+		if err := add(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	// servers may only send the files that were requested, so the missing dependencies are fetched until none are left
+	for {
+		var missing []string
+		for _, fd := range fetched {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := fetched[dep]; !ok && !slices.Contains(missing, dep) {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+
+		for _, name := range missing {
+			resp, err := s.request(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch file %s: %w", name, err)
+			}
+			if err := add(resp); err != nil {
+				return nil, err
+			}
+			if _, ok := fetched[name]; !ok {
+				return nil, fmt.Errorf("server reflection did not return file %s", name)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range fetched {
+		set.File = append(set.File, fd)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptors: %w", err)
+	}
+
+	return files, nil
+}
+
+func findService(files *protoregistry.Files, name string) (protoreflect.ServiceDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find service %s: %w", name, err)
+	}
+
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", name)
+	}
+
+	return service, nil
+}
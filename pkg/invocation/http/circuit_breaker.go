@@ -0,0 +1,170 @@
+package http
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCoolDown         = 30 * time.Second
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreakerPolicy holds the settings of the circuit breakers used by an invoker.
+type CircuitBreakerPolicy struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	CoolDown         time.Duration // How long the circuit stays open before a trial request
+}
+
+// NewCircuitBreakerPolicy creates a CircuitBreakerPolicy from a validated CircuitBreakerConfig,
+// applying the defaults. It returns nil if cbc is nil.
+func NewCircuitBreakerPolicy(cbc *CircuitBreakerConfig) (*CircuitBreakerPolicy, error) {
+	if cbc == nil {
+		return nil, nil
+	}
+
+	cbp := &CircuitBreakerPolicy{
+		FailureThreshold: cbc.FailureThreshold,
+		CoolDown:         defaultCoolDown,
+	}
+
+	if cbp.FailureThreshold == 0 {
+		cbp.FailureThreshold = defaultFailureThreshold
+	}
+
+	if cbc.CoolDown != "" {
+		var err error
+		if cbp.CoolDown, err = time.ParseDuration(cbc.CoolDown); err != nil {
+			return nil, err
+		}
+	}
+
+	return cbp, nil
+}
+
+// breakerKey identifies a circuit breaker. Invokers calling the same host with the same
+// settings share a single circuit breaker.
+type breakerKey struct {
+	host   string
+	policy CircuitBreakerPolicy
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[breakerKey]*CircuitBreaker)
+)
+
+// ForHost returns the shared circuit breaker of host.
+func (cbp *CircuitBreakerPolicy) ForHost(host string) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	key := breakerKey{host: host, policy: *cbp}
+	if cb, ok := breakers[key]; ok {
+		return cb
+	}
+
+	cb := newCircuitBreaker(host, *cbp, time.Now)
+	breakers[key] = cb
+
+	return cb
+}
+
+// CircuitBreaker tracks the failures of the requests sent to a host. After FailureThreshold
+// consecutive failures the circuit opens and requests are rejected until the cool-down has passed.
+// A single trial request is then let through, which closes the circuit if it succeeds and opens
+// it again if it fails.
+type CircuitBreaker struct {
+	host   string
+	policy CircuitBreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(host string, policy CircuitBreakerPolicy, now func() time.Time) *CircuitBreaker {
+	return &CircuitBreaker{
+		host:   host,
+		policy: policy,
+		now:    now,
+	}
+}
+
+// CircuitOpenError is returned for requests rejected by an open circuit breaker.
+type CircuitOpenError struct {
+	Host    string        // Host whose circuit is open
+	RetryIn time.Duration // Time until the next trial request, zero if a trial request is in progress
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn > 0 {
+		return fmt.Sprintf("circuit breaker for %s is open after repeated failures, no requests are sent for another %s",
+			e.Host, e.RetryIn.Round(time.Second))
+	}
+	return fmt.Sprintf("circuit breaker for %s is open after repeated failures, waiting for a trial request to complete", e.Host)
+}
+
+// Allow returns a CircuitOpenError if a request to the host must not be sent. Every allowed
+// request must be followed by a call to Record or Abandon.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitClosed:
+		return nil
+	case circuitOpen:
+		if remaining := cb.policy.CoolDown - cb.now().Sub(cb.openedAt); remaining > 0 {
+			return &CircuitOpenError{Host: cb.host, RetryIn: remaining}
+		}
+		cb.state = circuitHalfOpen
+		return nil
+	default:
+		return &CircuitOpenError{Host: cb.host}
+	}
+}
+
+// Record records the outcome of an allowed request. It reports whether the failure opened the circuit.
+func (cb *CircuitBreaker) Record(failed bool) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !failed {
+		cb.state = circuitClosed
+		cb.failures = 0
+		return false
+	}
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.state == circuitClosed && cb.failures >= cb.policy.FailureThreshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+		return true
+	}
+
+	return false
+}
+
+// Abandon releases an allowed request whose outcome says nothing about the health of the host,
+// e.g. because the tool call was cancelled. A pending trial request can be sent again immediately.
+func (cb *CircuitBreaker) Abandon() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitHalfOpen {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now().Add(-cb.policy.CoolDown)
+	}
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		advance     time.Duration // time passed before the request
+		expectAllow bool
+		failed      bool // outcome recorded for allowed requests
+		abandon     bool // abandon allowed requests instead of recording an outcome
+	}
+
+	tt := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "opens after consecutive failures",
+			steps: []step{
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: false},
+				{advance: 30 * time.Second, expectAllow: false},
+			},
+		},
+		{
+			name: "success resets the failure count",
+			steps: []step{
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true},
+			},
+		},
+		{
+			name: "successful trial request closes the circuit",
+			steps: []step{
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{advance: time.Minute, expectAllow: true},
+				{expectAllow: true},
+			},
+		},
+		{
+			name: "failed trial request opens the circuit again",
+			steps: []step{
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{advance: time.Minute, expectAllow: true, failed: true},
+				{expectAllow: false},
+				{advance: time.Minute, expectAllow: true},
+			},
+		},
+		{
+			name: "abandoned trial request can be sent again",
+			steps: []step{
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{expectAllow: true, failed: true},
+				{advance: time.Minute, expectAllow: true, abandon: true},
+				{expectAllow: true},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			cb := newCircuitBreaker("example.com", CircuitBreakerPolicy{FailureThreshold: 3, CoolDown: time.Minute}, func() time.Time { return now })
+
+			for i, s := range tc.steps {
+				now = now.Add(s.advance)
+
+				err := cb.Allow()
+				if !s.expectAllow {
+					var openErr *CircuitOpenError
+					require.ErrorAs(t, err, &openErr, "request %d should be rejected", i)
+					continue
+				}
+				require.NoError(t, err, "request %d should be allowed", i)
+
+				if s.abandon {
+					cb.Abandon()
+				} else {
+					cb.Record(s.failed)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTrialInProgress(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker("example.com", CircuitBreakerPolicy{FailureThreshold: 1, CoolDown: time.Second}, func() time.Time { return now })
+
+	require.NoError(t, cb.Allow(), "first request should be allowed")
+	assert.True(t, cb.Record(true), "failure should open the circuit")
+
+	now = now.Add(time.Second)
+	require.NoError(t, cb.Allow(), "trial request should be allowed")
+
+	err := cb.Allow()
+	assert.EqualError(t, err, "circuit breaker for example.com is open after repeated failures, waiting for a trial request to complete",
+		"requests should be rejected while the trial request is in progress")
+}
+
+func TestHttpInvocationCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		requests.Add(1)
+		w.WriteHeader(nethttp.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	httpInvoker := testHttpInvoker(t, s.URL+"/breaker", nil, resolvedWithPath, "GET", "")
+	policy, err := NewCircuitBreakerPolicy(&CircuitBreakerConfig{FailureThreshold: 2, CoolDown: "1h"})
+	require.NoError(t, err, "creating the circuit breaker policy should not fail")
+	httpInvoker.CircuitBreaker = policy
+
+	invoke := func() *mcp.CallToolResult {
+		res, err := httpInvoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"search":"foo"}`)},
+		})
+		require.NoError(t, err, "invocation should not return Go error")
+		require.True(t, res.IsError, "tool call should fail")
+		require.Len(t, res.Content, 1)
+		return res
+	}
+
+	invoke()
+	invoke()
+	res := invoke()
+
+	assert.Equal(t, int32(2), requests.Load(), "requests should stop once the circuit is open")
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "HTTP request failed: circuit breaker for "+requestHost(s.URL)+" is open",
+		"tool call should fail with a descriptive error")
+}
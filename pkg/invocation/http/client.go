@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	neturl "net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientOverrides are the settings of the HTTP client of an invoker that override those of the client of the
+// server, which is passed in the context.
+type ClientOverrides struct {
+	ProxyURL            *neturl.URL   // Proxy of the requests, the proxy of the server client if nil
+	CACerts             []byte        // PEM certificates trusted in addition to those of the server client
+	InsecureSkipVerify  bool          // Skip the verification of the certificates of the backend
+	DisableKeepAlives   bool          // Close the connection after every request
+	MaxIdleConns        int           // Maximum idle connections across all hosts, kept if zero
+	MaxIdleConnsPerHost int           // Maximum idle connections to a host, kept if zero
+	IdleConnTimeout     time.Duration // How long idle connections are kept open, kept if zero
+	DisableHTTP2        bool          // Only use HTTP/1.1
+
+	signature string // identifies the settings, so that invokers with the same settings share a client
+}
+
+// NewClientOverrides creates ClientOverrides from a validated ClientConfig, reading its CA certificates.
+// It returns nil if cc is nil.
+func NewClientOverrides(cc *ClientConfig) (*ClientOverrides, error) {
+	if cc == nil {
+		return nil, nil
+	}
+
+	co := &ClientOverrides{
+		InsecureSkipVerify:  cc.InsecureSkipVerify,
+		DisableKeepAlives:   cc.DisableKeepAlives,
+		MaxIdleConns:        cc.MaxIdleConns,
+		MaxIdleConnsPerHost: cc.MaxIdleConnsPerHost,
+		DisableHTTP2:        cc.DisableHTTP2,
+	}
+
+	var err error
+	if cc.ProxyURL != "" {
+		if co.ProxyURL, err = neturl.Parse(cc.ProxyURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if cc.IdleConnTimeout != "" {
+		if co.IdleConnTimeout, err = time.ParseDuration(cc.IdleConnTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range cc.CACertFiles {
+		certPEM, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert %s: %w", file, err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(certPEM) {
+			return nil, fmt.Errorf("failed to parse CA cert %s", file)
+		}
+		co.CACerts = append(co.CACerts, certPEM...)
+		co.CACerts = append(co.CACerts, '\n')
+	}
+
+	signature, err := json.Marshal(cc)
+	if err != nil {
+		return nil, err
+	}
+	co.signature = string(signature)
+
+	return co, nil
+}
+
+// clientKey identifies a client. Invokers with the same settings, using the same server client, share a
+// single client and its connections.
+type clientKey struct {
+	base      *nethttp.Client
+	signature string
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[clientKey]*nethttp.Client)
+)
+
+// Client returns the shared client applying the overrides to the base client, creating it if needed.
+func (co *ClientOverrides) Client(base *nethttp.Client) (*nethttp.Client, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	key := clientKey{base: base, signature: co.signature}
+	if client, ok := clients[key]; ok {
+		return client, nil
+	}
+
+	transport, err := co.transport(base)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &nethttp.Client{
+		Transport:     transport,
+		CheckRedirect: base.CheckRedirect,
+		Jar:           base.Jar,
+		Timeout:       base.Timeout,
+	}
+	clients[key] = client
+
+	return client, nil
+}
+
+// transport clones the transport of the base client and applies the overrides to it.
+func (co *ClientOverrides) transport(base *nethttp.Client) (*nethttp.Transport, error) {
+	baseTransport := base.Transport
+	if baseTransport == nil {
+		baseTransport = nethttp.DefaultTransport
+	}
+
+	// the transport is cloned to keep the proxy, TLS, timeout and connection pooling settings of the server
+	t, ok := baseTransport.(*nethttp.Transport)
+	if !ok {
+		return nil, fmt.Errorf("the transport of the HTTP client is not *http.Transport; cannot apply client settings")
+	}
+	transport := t.Clone()
+
+	if co.ProxyURL != nil {
+		// the proxy function of the base client checks the requests against the network policy of the server
+		baseProxy := transport.Proxy
+		transport.Proxy = func(req *nethttp.Request) (*neturl.URL, error) {
+			if baseProxy != nil {
+				if _, err := baseProxy(req); err != nil {
+					return nil, err
+				}
+			}
+			return co.ProxyURL, nil
+		}
+	}
+
+	if len(co.CACerts) > 0 || co.InsecureSkipVerify {
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+
+		if len(co.CACerts) > 0 {
+			rootCAs := tlsConfig.RootCAs
+			if rootCAs == nil {
+				var err error
+				if rootCAs, err = x509.SystemCertPool(); err != nil {
+					rootCAs = x509.NewCertPool()
+				}
+			} else {
+				rootCAs = rootCAs.Clone()
+			}
+			rootCAs.AppendCertsFromPEM(co.CACerts)
+			tlsConfig.RootCAs = rootCAs
+		}
+
+		if co.InsecureSkipVerify {
+			tlsConfig.InsecureSkipVerify = true //nolint:gosec // User explicitly requested insecure mode
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	transport.DisableKeepAlives = co.DisableKeepAlives
+	if co.MaxIdleConns > 0 {
+		transport.MaxIdleConns = co.MaxIdleConns
+	}
+	if co.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = co.MaxIdleConnsPerHost
+	}
+	if co.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = co.IdleConnTimeout
+	}
+
+	if co.DisableHTTP2 {
+		protocols := &nethttp.Protocols{}
+		protocols.SetHTTP1(true)
+		transport.Protocols = protocols
+		transport.ForceAttemptHTTP2 = false
+	}
+
+	return transport, nil
+}
+
+// httpClient returns the client of the requests of the invoker: the client of the server from the context,
+// with the client overrides of the invoker applied if it has any.
+func (hi *HttpInvoker) httpClient(ctx context.Context) (*nethttp.Client, error) {
+	client := HTTPClientFromContext(ctx)
+	if hi.Client == nil {
+		return client, nil
+	}
+	return hi.Client.Client(client)
+}
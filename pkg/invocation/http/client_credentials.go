@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// ClientCredentials obtains access tokens for a backend with the OAuth 2.0 client credentials grant. The
+// token is cached and requested again shortly before it expires.
+type ClientCredentials struct {
+	TokenURL         string
+	ClientID         string
+	ClientSecret     *template.ParsedTemplate // may reference secrets and environment variables
+	ClientAuthMethod string
+	Scopes           []string
+	Audience         string
+	Resource         string
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time // zero if the token does not expire
+}
+
+// clientCredentialsKey identifies the token of a client. Invokers with the same client credentials
+// settings share their token.
+type clientCredentialsKey struct {
+	tokenURL         string
+	clientID         string
+	clientSecret     string
+	clientAuthMethod string
+	scopes           string
+	audience         string
+	resource         string
+}
+
+var (
+	clientCredentialsMu sync.Mutex
+	clientCredentials   = make(map[clientCredentialsKey]*ClientCredentials)
+)
+
+// NewClientCredentials returns the ClientCredentials of a validated ClientCredentialsConfig, shared with
+// the invokers having the same settings. The client secret can only reference the environment variables
+// allowed by allowlist. It returns nil if ccc is nil.
+func NewClientCredentials(ccc *ClientCredentialsConfig, allowlist *template.Allowlist) (*ClientCredentials, error) {
+	if ccc == nil {
+		return nil, nil
+	}
+
+	clientAuthMethod := strings.ToLower(ccc.ClientAuthMethod)
+	if clientAuthMethod == "" {
+		clientAuthMethod = ClientAuthBasic
+	}
+
+	key := clientCredentialsKey{
+		tokenURL:         ccc.TokenURL,
+		clientID:         ccc.ClientID,
+		clientSecret:     ccc.ClientSecret,
+		clientAuthMethod: clientAuthMethod,
+		scopes:           strings.Join(ccc.Scopes, " "),
+		audience:         ccc.Audience,
+		resource:         ccc.Resource,
+	}
+
+	// the secret is parsed before looking up the shared credentials, as the servers sharing them may allow
+	// different environment variables
+	clientSecret, err := parseClientSecret(ccc.ClientSecret, allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCredentialsMu.Lock()
+	defer clientCredentialsMu.Unlock()
+
+	if cc, ok := clientCredentials[key]; ok {
+		return cc, nil
+	}
+
+	cc := &ClientCredentials{
+		TokenURL:         ccc.TokenURL,
+		ClientID:         ccc.ClientID,
+		ClientSecret:     clientSecret,
+		ClientAuthMethod: clientAuthMethod,
+		Scopes:           ccc.Scopes,
+		Audience:         ccc.Audience,
+		Resource:         ccc.Resource,
+	}
+	clientCredentials[key] = cc
+
+	return cc, nil
+}
+
+// Apply sets the Authorization header of headers to the access token of the client.
+func (cc *ClientCredentials) Apply(ctx context.Context, headers nethttp.Header) error {
+	token, err := cc.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to obtain access token: %w", err)
+	}
+
+	headers.Set(authorizationHeader, "Bearer "+token)
+	return nil
+}
+
+// DryRun sets the Authorization header of headers to a placeholder for the token Apply would send.
+func (cc *ClientCredentials) DryRun(headers nethttp.Header) {
+	headers.Set(authorizationHeader, "Bearer [ACCESS TOKEN]")
+}
+
+// Token returns the cached access token of the client, or requests a new one if it has none or if it
+// is about to expire. Concurrent callers wait for a single token request.
+func (cc *ClientCredentials) Token(ctx context.Context) (string, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.token != "" && (cc.expiry.IsZero() || time.Now().Before(cc.expiry)) {
+		return cc.token, nil
+	}
+
+	form := neturl.Values{"grant_type": {"client_credentials"}}
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+	if cc.Audience != "" {
+		form.Set("audience", cc.Audience)
+	}
+	if cc.Resource != "" {
+		form.Set("resource", cc.Resource)
+	}
+
+	clientSecret, err := resolveClientSecret(ctx, cc.ClientSecret)
+	if err != nil {
+		return "", err
+	}
+
+	token, expiresIn, err := requestToken(ctx, cc.TokenURL, form, cc.ClientID, clientSecret, cc.ClientAuthMethod)
+	if err != nil {
+		return "", err
+	}
+
+	cc.token = token
+	cc.expiry = time.Time{}
+	if expiresIn > 0 {
+		cc.expiry = refreshAt(time.Now(), expiresIn)
+	}
+
+	return token, nil
+}
+
+// Invalidate discards the cached access token if it is still token, e.g. because the backend rejected it,
+// so that the next call requests a new one.
+func (cc *ClientCredentials) Invalidate(token string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.token == token {
+		cc.token = ""
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+func TestHttpInvocationClientCredentials(t *testing.T) {
+	tt := []struct {
+		name             string
+		config           *ClientCredentialsConfig
+		tokenStatus      int
+		revokeFirstToken bool // whether the backend rejects the first token issued
+		expectedAuth     []string
+		expectedRequests int32
+		errContains      string
+	}{
+		{
+			name: "token is cached",
+			config: &ClientCredentialsConfig{
+				ClientID:     "genmcp",
+				ClientSecret: "client-s3cr3t",
+				Scopes:       []string{"users:read"},
+			},
+			expectedAuth:     []string{"Bearer token-1", "Bearer token-1"},
+			expectedRequests: 1,
+		},
+		{
+			name: "post client auth method",
+			config: &ClientCredentialsConfig{
+				ClientID:         "genmcp",
+				ClientSecret:     "client-s3cr3t",
+				ClientAuthMethod: ClientAuthPost,
+				Scopes:           []string{"users:read"},
+			},
+			expectedAuth:     []string{"Bearer token-1", "Bearer token-1"},
+			expectedRequests: 1,
+		},
+		{
+			name: "client secret from secrets",
+			config: &ClientCredentialsConfig{
+				ClientID:     "genmcp",
+				ClientSecret: "{secrets.CLIENT_SECRET}",
+				Scopes:       []string{"users:read"},
+			},
+			expectedAuth:     []string{"Bearer token-1", "Bearer token-1"},
+			expectedRequests: 1,
+		},
+		{
+			name: "new token after rejection by the backend",
+			config: &ClientCredentialsConfig{
+				ClientID:     "genmcp",
+				ClientSecret: "client-s3cr3t",
+				Scopes:       []string{"users:read"},
+			},
+			revokeFirstToken: true,
+			expectedAuth:     []string{"Bearer token-2", "Bearer token-2"},
+			expectedRequests: 2,
+		},
+		{
+			name: "token request rejected",
+			config: &ClientCredentialsConfig{
+				ClientID:     "genmcp",
+				ClientSecret: "wrong-secret",
+				Scopes:       []string{"users:read"},
+			},
+			errContains: "failed to obtain access token: invalid_client: client authentication failed (status 401)",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var tokenRequests atomic.Int32
+			tokenServer := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				n := tokenRequests.Add(1)
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+				assert.Equal(t, "users:read", r.PostForm.Get("scope"))
+
+				id, secret, ok := r.BasicAuth()
+				if tc.config.ClientAuthMethod == ClientAuthPost {
+					assert.False(t, ok)
+					id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+				}
+				if id != "genmcp" || secret != "client-s3cr3t" {
+					w.WriteHeader(nethttp.StatusUnauthorized)
+					_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"client authentication failed"}`))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":300}`, n)
+			}))
+			defer tokenServer.Close()
+
+			backend := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if tc.revokeFirstToken && r.Header.Get("Authorization") == "Bearer token-1" {
+					w.WriteHeader(nethttp.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+			}))
+			defer backend.Close()
+
+			config := *tc.config
+			config.TokenURL = tokenServer.URL
+
+			invoker := testHttpInvoker(t, backend.URL+"/users", nil, resolvedEmpty, "GET", "")
+			var err error
+			invoker.Credentials, err = NewClientCredentials(&config, nil)
+			require.NoError(t, err)
+
+			ctx := secrets.WithStore(context.Background(), secrets.NewStore(mapSecrets{"CLIENT_SECRET": "client-s3cr3t"}))
+
+			for i := range 2 {
+				result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+					Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+				})
+				if tc.errContains != "" {
+					assert.ErrorContains(t, err, tc.errContains)
+					continue
+				}
+				require.NoError(t, err)
+				assert.False(t, result.IsError)
+				assert.Equal(t, tc.expectedAuth[i], result.Content[0].(*mcp.TextContent).Text)
+			}
+
+			if tc.errContains == "" {
+				assert.Equal(t, tc.expectedRequests, tokenRequests.Load())
+			}
+		})
+	}
+}
+
+func TestNewClientCredentialsShared(t *testing.T) {
+	config := &ClientCredentialsConfig{
+		TokenURL:     "https://sso.example.com/token",
+		ClientID:     "genmcp",
+		ClientSecret: "{secrets.CLIENT_SECRET}",
+	}
+
+	first, err := NewClientCredentials(config, nil)
+	require.NoError(t, err)
+	second, err := NewClientCredentials(config.DeepCopy(), nil)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other := config.DeepCopy()
+	other.Scopes = []string{"users:read"}
+	third, err := NewClientCredentials(other, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+
+	// the servers sharing the credentials check the environment variables of the secret against their allowlist
+	fromEnv := config.DeepCopy()
+	fromEnv.ClientSecret = "${CLIENT_SECRET}"
+	_, err = NewClientCredentials(fromEnv, nil)
+	require.NoError(t, err)
+	_, err = NewClientCredentials(fromEnv, template.NewAllowlist(nil, []string{}, nil))
+	assert.ErrorContains(t, err, "environment variable 'CLIENT_SECRET' is not in the allowedEnv")
+}
+
+func TestHttpInvocationClientCredentialsDryRun(t *testing.T) {
+	invoker := testHttpInvoker(t, "https://api.example.com/users", nil, resolvedEmpty, "GET", "")
+	var err error
+	invoker.Credentials, err = NewClientCredentials(&ClientCredentialsConfig{
+		TokenURL:     "https://sso.example.com/token",
+		ClientID:     "genmcp",
+		ClientSecret: "s3cr3t",
+	}, nil)
+	require.NoError(t, err)
+
+	result, err := invoker.DryRun(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer [ACCESS TOKEN]", result.Headers.Get("Authorization"))
+}
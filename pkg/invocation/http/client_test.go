@@ -0,0 +1,231 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	nethttp "net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeServerCert writes the certificate of a TLS test server as a PEM file, and returns its path.
+func writeServerCert(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, certPEM, 0600))
+
+	return path
+}
+
+func TestNewClientOverrides(t *testing.T) {
+	invalidCert := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidCert, []byte("not a certificate"), 0600))
+
+	tt := []struct {
+		name        string
+		config      *ClientConfig
+		check       func(t *testing.T, co *ClientOverrides)
+		expectError bool
+	}{
+		{
+			name:   "nil config",
+			config: nil,
+			check: func(t *testing.T, co *ClientOverrides) {
+				assert.Nil(t, co)
+			},
+		},
+		{
+			name: "settings",
+			config: &ClientConfig{
+				ProxyURL:            "http://proxy.internal:3128",
+				DisableKeepAlives:   true,
+				MaxIdleConns:        10,
+				MaxIdleConnsPerHost: 5,
+				IdleConnTimeout:     "30s",
+				DisableHTTP2:        true,
+			},
+			check: func(t *testing.T, co *ClientOverrides) {
+				assert.Equal(t, "proxy.internal:3128", co.ProxyURL.Host)
+				assert.True(t, co.DisableKeepAlives)
+				assert.Equal(t, 10, co.MaxIdleConns)
+				assert.Equal(t, 5, co.MaxIdleConnsPerHost)
+				assert.Equal(t, 30*time.Second, co.IdleConnTimeout)
+				assert.True(t, co.DisableHTTP2)
+				assert.NotEmpty(t, co.signature)
+			},
+		},
+		{
+			name:        "missing CA cert",
+			config:      &ClientConfig{CACertFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}},
+			expectError: true,
+		},
+		{
+			name:        "invalid CA cert",
+			config:      &ClientConfig{CACertFiles: []string{invalidCert}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			co, err := NewClientOverrides(tc.config)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, co)
+		})
+	}
+}
+
+func TestClientOverrides_Client(t *testing.T) {
+	co, err := NewClientOverrides(&ClientConfig{
+		ProxyURL:            "http://proxy.internal:3128",
+		InsecureSkipVerify:  true,
+		DisableKeepAlives:   true,
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     "30s",
+		DisableHTTP2:        true,
+	})
+	require.NoError(t, err)
+
+	base := &nethttp.Client{Timeout: time.Minute}
+	client, err := co.Client(base)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Minute, client.Timeout)
+	transport := client.Transport.(*nethttp.Transport)
+	proxy, err := transport.Proxy(&nethttp.Request{URL: &neturl.URL{Scheme: "https", Host: "api.example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxy.String())
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	assert.True(t, transport.DisableKeepAlives)
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	assert.True(t, transport.Protocols.HTTP1())
+	assert.False(t, transport.Protocols.HTTP2())
+
+	t.Run("clients are shared by settings and base client", func(t *testing.T) {
+		same, err := NewClientOverrides(&ClientConfig{
+			ProxyURL:            "http://proxy.internal:3128",
+			InsecureSkipVerify:  true,
+			DisableKeepAlives:   true,
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 5,
+			IdleConnTimeout:     "30s",
+			DisableHTTP2:        true,
+		})
+		require.NoError(t, err)
+		sameClient, err := same.Client(base)
+		require.NoError(t, err)
+		assert.Same(t, client, sameClient)
+
+		otherBase, err := co.Client(&nethttp.Client{})
+		require.NoError(t, err)
+		assert.NotSame(t, client, otherBase)
+
+		other, err := NewClientOverrides(&ClientConfig{DisableKeepAlives: true})
+		require.NoError(t, err)
+		otherClient, err := other.Client(base)
+		require.NoError(t, err)
+		assert.NotSame(t, client, otherClient)
+	})
+
+	t.Run("base transport that is not an http transport", func(t *testing.T) {
+		_, err := co.Client(&nethttp.Client{Transport: roundTripperFunc(nethttp.DefaultTransport.RoundTrip)})
+		assert.Error(t, err)
+	})
+}
+
+type roundTripperFunc func(*nethttp.Request) (*nethttp.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *nethttp.Request) (*nethttp.Response, error) {
+	return f(r)
+}
+
+func TestHttpInvoker_Client(t *testing.T) {
+	backend := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	var proxied []string
+	proxy := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"proxied": true}`))
+	}))
+	defer proxy.Close()
+
+	tt := []struct {
+		name             string
+		url              string
+		client           *ClientConfig
+		expectedText     string
+		expectedProxied  []string
+		expectFailedCall bool
+	}{
+		{
+			name:             "untrusted certificate",
+			url:              backend.URL + "/status",
+			expectFailedCall: true,
+		},
+		{
+			name:         "custom CA",
+			url:          backend.URL + "/status",
+			client:       &ClientConfig{CACertFiles: []string{writeServerCert(t, backend)}},
+			expectedText: `{"ok": true}`,
+		},
+		{
+			name:         "skip verify",
+			url:          backend.URL + "/status",
+			client:       &ClientConfig{InsecureSkipVerify: true},
+			expectedText: `{"ok": true}`,
+		},
+		{
+			name:            "proxy",
+			url:             "http://api.internal/status",
+			client:          &ClientConfig{ProxyURL: proxy.URL},
+			expectedText:    `{"proxied": true}`,
+			expectedProxied: []string{"http://api.internal/status"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			proxied = nil
+
+			invoker := testHttpInvoker(t, tc.url, nil, resolvedEmpty, nethttp.MethodGet, "")
+			invoker.Client, _ = NewClientOverrides(tc.client)
+
+			ctx := WithHTTPClient(context.Background(), &nethttp.Client{})
+			result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+			})
+			require.NoError(t, err)
+
+			if tc.expectFailedCall {
+				assert.True(t, result.IsError)
+				return
+			}
+			require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+			assert.Equal(t, tc.expectedProxied, proxied)
+		})
+	}
+}
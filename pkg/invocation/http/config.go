@@ -1,11 +1,16 @@
 package http
 
 import (
+	"cmp"
 	"fmt"
 	nethttp "net/http"
+	neturl "net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/signing"
 )
 
 var validHttpMethods = map[string]struct{}{
@@ -17,6 +22,128 @@ var validHttpMethods = map[string]struct{}{
 	nethttp.MethodDelete: {},
 }
 
+const (
+	// MessageFramingSSE splits a streamed response into server-sent events.
+	MessageFramingSSE = "sse"
+
+	// MessageFramingLines splits a streamed response on newlines (e.g. NDJSON).
+	MessageFramingLines = "lines"
+
+	// MessageFramingChunks treats every chunk of a streamed response as a message, as it is read.
+	MessageFramingChunks = "chunks"
+)
+
+var validMessageFramings = map[string]struct{}{
+	MessageFramingSSE:    {},
+	MessageFramingLines:  {},
+	MessageFramingChunks: {},
+}
+
+const (
+	// EncodingJSON encodes bodies as JSON.
+	EncodingJSON = "json"
+
+	// EncodingForm encodes bodies as URL encoded forms (application/x-www-form-urlencoded).
+	EncodingForm = "form"
+
+	// EncodingMultipart encodes request bodies as multipart forms (multipart/form-data).
+	EncodingMultipart = "multipart"
+
+	// EncodingXML encodes bodies as XML.
+	EncodingXML = "xml"
+
+	// EncodingProtobuf encodes bodies as protobuf messages, in their binary wire format.
+	EncodingProtobuf = "protobuf"
+)
+
+var validContentTypes = map[string]struct{}{
+	EncodingJSON:      {},
+	EncodingForm:      {},
+	EncodingMultipart: {},
+	EncodingXML:       {},
+	EncodingProtobuf:  {},
+}
+
+const (
+	// FileSourceBase64 reads the content of a file part from the base64 encoded value of its property.
+	FileSourceBase64 = "base64"
+
+	// FileSourceURL downloads the content of a file part from the http, https or data URL value of its property.
+	FileSourceURL = "url"
+)
+
+var validFileSources = map[string]struct{}{
+	FileSourceBase64: {},
+	FileSourceURL:    {},
+}
+
+var validProxySchemes = map[string]struct{}{
+	"http":   {},
+	"https":  {},
+	"socks5": {},
+}
+
+var validAccepts = map[string]struct{}{
+	EncodingJSON:     {},
+	EncodingForm:     {},
+	EncodingXML:      {},
+	EncodingProtobuf: {},
+}
+
+const (
+	// BackoffConstant waits the initial delay between every attempt.
+	BackoffConstant = "constant"
+
+	// BackoffLinear increases the delay by the initial delay after every attempt.
+	BackoffLinear = "linear"
+
+	// BackoffExponential doubles the delay after every attempt.
+	BackoffExponential = "exponential"
+)
+
+var validBackoffStrategies = map[string]struct{}{
+	BackoffConstant:    {},
+	BackoffLinear:      {},
+	BackoffExponential: {},
+}
+
+const (
+	// ForwardAuthPassthrough forwards the bearer token of the incoming request as is.
+	ForwardAuthPassthrough = "passthrough"
+
+	// ForwardAuthExchange exchanges the bearer token of the incoming request for a token of the backend
+	// (RFC 8693) and forwards that one.
+	ForwardAuthExchange = "exchange"
+)
+
+var validForwardAuthModes = map[string]struct{}{
+	ForwardAuthPassthrough: {},
+	ForwardAuthExchange:    {},
+}
+
+const (
+	// ClientAuthBasic sends the client credentials with HTTP basic authentication (client_secret_basic).
+	ClientAuthBasic = "basic"
+
+	// ClientAuthPost sends the client credentials in the form body of the token request (client_secret_post).
+	ClientAuthPost = "post"
+)
+
+var validClientAuthMethods = map[string]struct{}{
+	ClientAuthBasic: {},
+	ClientAuthPost:  {},
+}
+
+var validSigningAlgorithms = map[string]struct{}{
+	signing.AlgorithmSHA256: {},
+	signing.AlgorithmSHA512: {},
+}
+
+var validSigningEncodings = map[string]struct{}{
+	signing.EncodingHex:    {},
+	signing.EncodingBase64: {},
+}
+
 // HttpInvocationConfig is the configuration for making an HTTP request.
 // This is a pure data structure with no parsing logic - all struct tags only.
 type HttpInvocationConfig struct {
@@ -50,9 +177,684 @@ type HttpInvocationConfig struct {
 	// This is useful for APIs that expect array inputs but you want to expose a simpler single-item interface.
 	// Mutually exclusive with BodyRoot.
 	BodyAsArray bool `json:"bodyAsArray,omitempty" jsonschema:"optional"`
+
+	// Mapping explicitly maps the properties of the input to the query parameters and body of the request. When set,
+	// only the mapped properties are sent in the query and body, instead of every property that isn't used in the
+	// URL or header templates. Mutually exclusive with BodyRoot.
+	Mapping *MappingConfig `json:"mapping,omitempty" jsonschema:"optional"`
+
+	// ContentType is the encoding of the request body: "json" (default), "form" (application/x-www-form-urlencoded),
+	// "multipart" (multipart/form-data), "xml" or "protobuf".
+	// Form fields are the top-level properties of the body, arrays of scalars are sent as repeated fields and other
+	// objects and arrays as JSON. Multipart bodies send the properties with a base64 contentEncoding in the input
+	// schema as files.
+	ContentType string `json:"contentType,omitempty" jsonschema:"optional,enum=json,enum=form,enum=multipart,enum=xml,enum=protobuf"`
+
+	// Accept is the expected encoding of the response: "json", "form", "xml" or "protobuf". It is sent in the Accept
+	// header unless one is set in Headers, and decodes responses without a Content-Type.
+	// Responses are decoded according to their Content-Type into the structured content of tools, and protobuf
+	// responses are returned in their JSON mapping.
+	Accept string `json:"accept,omitempty" jsonschema:"optional,enum=json,enum=form,enum=xml,enum=protobuf"`
+
+	// FileParts configures the properties of the body sent as files in multipart request bodies, by property name.
+	// Properties with a base64 contentEncoding in the input schema are sent as files even if they are not set here.
+	// Only valid if ContentType is "multipart".
+	FileParts map[string]*FilePartConfig `json:"fileParts,omitempty" jsonschema:"optional"`
+
+	// XMLRoot is the name of the root element of XML request bodies. Defaults to "request".
+	XMLRoot string `json:"xmlRoot,omitempty" jsonschema:"optional"`
+
+	// Protobuf defines the messages of protobuf request and response bodies.
+	// Required if ContentType or Accept is "protobuf".
+	Protobuf *ProtobufConfig `json:"protobuf,omitempty" jsonschema:"optional"`
+
+	// Streaming, if true, reads the response incrementally and forwards each message to the MCP client
+	// as a progress notification instead of waiting for a single response, or as a log message if the
+	// client didn't ask for progress. The final tool result aggregates every message that was received.
+	// URLs with a ws:// or wss:// scheme are invoked over a WebSocket and require streaming to be enabled.
+	// Only supported for tools.
+	Streaming bool `json:"streaming,omitempty" jsonschema:"optional"`
+
+	// MessageFraming controls how messages are split out of a streamed HTTP response body.
+	// "sse" parses server-sent events, "lines" treats every non-empty line as a message (e.g. NDJSON),
+	// and "chunks" treats every chunk of the body as a message, as it is read, the final result being
+	// the whole body. If unset, the framing is chosen from the content type of the response: lines for
+	// NDJSON, chunks for text/plain and sse otherwise.
+	// Ignored for WebSocket URLs, where every WebSocket message is one message.
+	MessageFraming string `json:"messageFraming,omitempty" jsonschema:"optional,enum=sse,enum=lines,enum=chunks"`
+
+	// Pagination fetches the following pages of paginated JSON responses and merges their items into a single
+	// result. Only supported for tools, and not for streaming requests.
+	Pagination *PaginationConfig `json:"pagination,omitempty" jsonschema:"optional"`
+
+	// Timeout is the maximum duration of a single request attempt, as a duration string (e.g. "30s").
+	// Defaults to 60s. Streaming requests have no timeout unless one is set.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+
+	// Retry configures retrying failed requests. Requests are not retried if unset.
+	// Not supported for streaming requests.
+	Retry *RetryConfig `json:"retry,omitempty" jsonschema:"optional"`
+
+	// CircuitBreaker stops sending requests to a host after repeated failures, so that tool calls fail
+	// fast until the backend recovers. Disabled if unset. Not supported for streaming requests.
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" jsonschema:"optional"`
+
+	// Client overrides the settings of the HTTP client of the server for the requests of this invocation.
+	// Invocations with the same settings share a client and its connections.
+	Client *ClientConfig `json:"client,omitempty" jsonschema:"optional"`
+
+	// ForwardAuth sends the bearer token of the incoming request, validated by the OAuth configuration of
+	// the server, in the Authorization header of the request, so that the backend sees the identity of the
+	// end user. It replaces an Authorization header set in Headers. Not forwarded if unset.
+	ForwardAuth *ForwardAuthConfig `json:"forwardAuth,omitempty" jsonschema:"optional"`
+
+	// ClientCredentials obtains an access token for the backend with the OAuth 2.0 client credentials grant,
+	// and sends it in the Authorization header of the request. The token is cached and refreshed before it
+	// expires. It replaces an Authorization header set in Headers. Mutually exclusive with ForwardAuth.
+	ClientCredentials *ClientCredentialsConfig `json:"clientCredentials,omitempty" jsonschema:"optional"`
+
+	// Signing signs the request with an HMAC of its timestamp, method, path, query and body, so that the backend
+	// can verify that it was sent by the server and was not tampered with. Requests are not signed if unset.
+	// WebSocket URLs are not signed.
+	Signing *SigningConfig `json:"signing,omitempty" jsonschema:"optional"`
+}
+
+// MappingConfig maps the properties of the input to the query and body of an HTTP request.
+// Values are dot-separated paths of input properties, with array indexes in brackets (e.g. "filters.tags[0]"),
+// and may start with "$." as in JSONPath. Properties missing in the arguments are not sent.
+type MappingConfig struct {
+	// Query maps the names of query parameters to the input properties sent in them. Arrays of strings, numbers
+	// or booleans are sent as repeated parameters, and other objects and arrays as JSON.
+	Query map[string]string `json:"query,omitempty" jsonschema:"optional"`
+
+	// Body maps dot-separated paths of the request body to the input properties set at them, e.g.
+	// "user.name: name" sends {"user": {"name": ...}}. No body is sent if empty.
+	Body map[string]string `json:"body,omitempty" jsonschema:"optional"`
+}
+
+func (mc *MappingConfig) Validate() error {
+	for param, property := range mc.Query {
+		if param == "" {
+			return fmt.Errorf("query parameter names must not be empty")
+		}
+		if _, err := parsePathSegments(trimPathRoot(property)); err != nil {
+			return fmt.Errorf("invalid property path for query parameter %s: %w", param, err)
+		}
+	}
+
+	for path, property := range mc.Body {
+		if path == "" || slices.Contains(strings.Split(path, "."), "") || strings.ContainsAny(path, "[]") {
+			return fmt.Errorf("invalid body path '%s': must be dot-separated property names", path)
+		}
+		if _, err := parsePathSegments(trimPathRoot(property)); err != nil {
+			return fmt.Errorf("invalid property path for body path %s: %w", path, err)
+		}
+		for other := range mc.Body {
+			if strings.HasPrefix(other, path+".") {
+				return fmt.Errorf("body paths %s and %s conflict", path, other)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (mc *MappingConfig) DeepCopy() *MappingConfig {
+	if mc == nil {
+		return nil
+	}
+
+	cp := &MappingConfig{}
+	if mc.Query != nil {
+		cp.Query = make(map[string]string, len(mc.Query))
+		for k, v := range mc.Query {
+			cp.Query[k] = v
+		}
+	}
+	if mc.Body != nil {
+		cp.Body = make(map[string]string, len(mc.Body))
+		for k, v := range mc.Body {
+			cp.Body[k] = v
+		}
+	}
+
+	return cp
+}
+
+// FilePartConfig configures a property of the body sent as a file in multipart request bodies.
+type FilePartConfig struct {
+	// Source is how the property holds the file: "base64" (default) for its base64 encoded content, or "url"
+	// for an http, https or data URL the server downloads the file from.
+	Source string `json:"source,omitempty" jsonschema:"optional,enum=base64,enum=url"`
+
+	// PartName is the name of the form part. Defaults to the name of the property.
+	PartName string `json:"partName,omitempty" jsonschema:"optional"`
+
+	// FileName is the file name of the part. Defaults to the last path segment of the URL for url sources, and
+	// to the name of the property otherwise.
+	FileName string `json:"fileName,omitempty" jsonschema:"optional"`
+
+	// FileNameProperty is a property of the body holding the file name of the part, which takes precedence over
+	// FileName when it is set in the arguments. The property is not sent as a form field.
+	FileNameProperty string `json:"fileNameProperty,omitempty" jsonschema:"optional"`
+
+	// ContentType is the content type of the part. Defaults to the content type of the downloaded file for url
+	// sources, and to application/octet-stream otherwise.
+	ContentType string `json:"contentType,omitempty" jsonschema:"optional"`
+}
+
+func (fpc *FilePartConfig) Validate() error {
+	if fpc.Source != "" {
+		if _, ok := validFileSources[strings.ToLower(fpc.Source)]; !ok {
+			return fmt.Errorf("invalid source: '%s'", fpc.Source)
+		}
+	}
+
+	return nil
+}
+
+func (fpc *FilePartConfig) DeepCopy() *FilePartConfig {
+	if fpc == nil {
+		return nil
+	}
+
+	cp := *fpc
+	return &cp
+}
+
+// ProtobufConfig defines the messages of the protobuf bodies of an HTTP invocation.
+type ProtobufConfig struct {
+	// DescriptorSet is the path of a binary FileDescriptorSet defining the messages and their dependencies,
+	// as written by protoc --descriptor_set_out --include_imports.
+	DescriptorSet string `json:"descriptorSet" jsonschema:"required"`
+
+	// RequestMessage is the full name of the message of request bodies (e.g. users.v1.CreateUserRequest).
+	// The arguments are converted to it from its JSON mapping. Required if ContentType is "protobuf".
+	RequestMessage string `json:"requestMessage,omitempty" jsonschema:"optional"`
+
+	// ResponseMessage is the full name of the message of response bodies. Required if Accept is "protobuf".
+	ResponseMessage string `json:"responseMessage,omitempty" jsonschema:"optional"`
+}
+
+func (pc *ProtobufConfig) Validate() error {
+	if pc.DescriptorSet == "" {
+		return fmt.Errorf("descriptorSet is required")
+	}
+
+	return nil
+}
+
+func (pc *ProtobufConfig) DeepCopy() *ProtobufConfig {
+	if pc == nil {
+		return nil
+	}
+
+	cp := *pc
+	return &cp
+}
+
+// PaginationConfig is the configuration for fetching the pages of a paginated HTTP API.
+// Exactly one of CursorParam or PageParam must be set. Paths are dot-separated, with array indexes in brackets
+// (e.g. "data.items" or "links[0].next"), and may start with "$." as in JSONPath.
+type PaginationConfig struct {
+	// CursorParam is the query parameter the cursor of the next page is sent in, for APIs paginated with cursors.
+	CursorParam string `json:"cursorParam,omitempty" jsonschema:"optional"`
+
+	// NextCursorPath is the path of the cursor of the next page in the response, e.g. "meta.nextCursor".
+	// The last page is reached when it is missing, null or empty. Required if CursorParam is set.
+	NextCursorPath string `json:"nextCursorPath,omitempty" jsonschema:"optional"`
+
+	// PageParam is the query parameter the page number is sent in, for APIs paginated with page numbers.
+	// The last page is reached when a page has no items.
+	PageParam string `json:"pageParam,omitempty" jsonschema:"optional"`
+
+	// FirstPage is the number of the first page. Defaults to 1.
+	FirstPage *int `json:"firstPage,omitempty" jsonschema:"optional"`
+
+	// ItemsPath is the path of the array of items in the response, e.g. "data". The items of all pages are
+	// merged into a single array. Defaults to the response itself.
+	ItemsPath string `json:"itemsPath,omitempty" jsonschema:"optional"`
+
+	// MaxPages is the maximum number of pages fetched by a single call. Defaults to 10. If more pages remain,
+	// the cursor or number of the next page is returned with the items, so that the client can continue from it.
+	MaxPages int `json:"maxPages,omitempty" jsonschema:"optional"`
+}
+
+func (pc *PaginationConfig) Validate() error {
+	switch {
+	case pc.CursorParam != "" && pc.PageParam != "":
+		return fmt.Errorf("cursorParam and pageParam are mutually exclusive")
+	case pc.CursorParam == "" && pc.PageParam == "":
+		return fmt.Errorf("one of cursorParam or pageParam is required")
+	case pc.CursorParam != "" && pc.NextCursorPath == "":
+		return fmt.Errorf("nextCursorPath is required with cursorParam")
+	case pc.PageParam != "" && pc.NextCursorPath != "":
+		return fmt.Errorf("nextCursorPath can only be set with cursorParam")
+	case pc.CursorParam != "" && pc.FirstPage != nil:
+		return fmt.Errorf("firstPage can only be set with pageParam")
+	}
+
+	if pc.FirstPage != nil && *pc.FirstPage < 0 {
+		return fmt.Errorf("firstPage must not be negative")
+	}
+
+	if pc.MaxPages < 0 {
+		return fmt.Errorf("maxPages must not be negative")
+	}
+
+	if _, err := parsePathSegments(trimPathRoot(pc.NextCursorPath)); err != nil {
+		return fmt.Errorf("invalid nextCursorPath: %w", err)
+	}
+
+	if _, err := parsePathSegments(trimPathRoot(pc.ItemsPath)); err != nil {
+		return fmt.Errorf("invalid itemsPath: %w", err)
+	}
+
+	return nil
+}
+
+func (pc *PaginationConfig) DeepCopy() *PaginationConfig {
+	if pc == nil {
+		return nil
+	}
+
+	cp := *pc
+	if pc.FirstPage != nil {
+		firstPage := *pc.FirstPage
+		cp.FirstPage = &firstPage
+	}
+
+	return &cp
+}
+
+// RetryConfig is the configuration for retrying failed HTTP requests.
+// A request is retried if it fails with a network error or timeout, or if the response has a retryable status code.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	MaxRetries int `json:"maxRetries" jsonschema:"required"`
+
+	// Backoff is the strategy used to compute the delay between attempts: "constant", "linear" or "exponential" (default).
+	Backoff string `json:"backoff,omitempty" jsonschema:"optional,enum=constant,enum=linear,enum=exponential"`
+
+	// InitialDelay is the delay before the first retry, as a duration string. Defaults to 500ms.
+	InitialDelay string `json:"initialDelay,omitempty" jsonschema:"optional"`
+
+	// MaxDelay caps the delay between attempts, as a duration string. Defaults to 10s.
+	// A Retry-After header sent by the backend is respected up to this delay.
+	MaxDelay string `json:"maxDelay,omitempty" jsonschema:"optional"`
+
+	// RetryableStatusCodes are the response status codes that trigger a retry. Defaults to 429, 502, 503 and 504.
+	RetryableStatusCodes []int `json:"retryableStatusCodes,omitempty" jsonschema:"optional"`
+}
+
+func (rc *RetryConfig) Validate() error {
+	if rc.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries must not be negative")
+	}
+
+	if rc.Backoff != "" {
+		if _, ok := validBackoffStrategies[strings.ToLower(rc.Backoff)]; !ok {
+			return fmt.Errorf("invalid backoff strategy: '%s'", rc.Backoff)
+		}
+	}
+
+	if err := validateDuration("initialDelay", rc.InitialDelay); err != nil {
+		return err
+	}
+
+	if err := validateDuration("maxDelay", rc.MaxDelay); err != nil {
+		return err
+	}
+
+	for _, code := range rc.RetryableStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid retryable status code: %d", code)
+		}
+	}
+
+	return nil
+}
+
+func (rc *RetryConfig) DeepCopy() *RetryConfig {
+	if rc == nil {
+		return nil
+	}
+
+	cp := *rc
+	if rc.RetryableStatusCodes != nil {
+		cp.RetryableStatusCodes = make([]int, len(rc.RetryableStatusCodes))
+		copy(cp.RetryableStatusCodes, rc.RetryableStatusCodes)
+	}
+
+	return &cp
+}
+
+// CircuitBreakerConfig is the configuration of the circuit breaker of an HTTP invocation.
+// Requests that fail with a network error or timeout, or that receive a 5xx status code, count as failures.
+// Invocations with the same settings share the circuit breaker of a host.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests to a host that opens the circuit. Defaults to 5.
+	FailureThreshold int `json:"failureThreshold,omitempty" jsonschema:"optional"`
+
+	// CoolDown is how long the circuit stays open before a single trial request is sent to the host,
+	// as a duration string. Defaults to 30s.
+	CoolDown string `json:"coolDown,omitempty" jsonschema:"optional"`
+}
+
+func (cbc *CircuitBreakerConfig) Validate() error {
+	if cbc.FailureThreshold < 0 {
+		return fmt.Errorf("failureThreshold must not be negative")
+	}
+
+	return validateDuration("coolDown", cbc.CoolDown)
+}
+
+func (cbc *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
+	if cbc == nil {
+		return nil
+	}
+
+	cp := *cbc
+	return &cp
+}
+
+// ClientConfig overrides the settings of the HTTP client of the server. Unset fields keep the settings of the
+// server, including its client TLS configuration.
+type ClientConfig struct {
+	// ProxyURL is the URL of the proxy the requests are sent through, e.g. "http://proxy.internal:3128".
+	// Defaults to the proxy set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyURL string `json:"proxyUrl,omitempty" jsonschema:"optional"`
+
+	// CACertFiles are paths to PEM CA certificates trusted for HTTPS requests, in addition to those trusted by
+	// the server.
+	CACertFiles []string `json:"caCertFiles,omitempty" jsonschema:"optional"`
+
+	// InsecureSkipVerify skips the verification of the TLS certificates of the backend.
+	// WARNING: This is insecure and should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" jsonschema:"optional"`
+
+	// DisableKeepAlives closes the connection after every request instead of reusing it.
+	DisableKeepAlives bool `json:"disableKeepAlives,omitempty" jsonschema:"optional"`
+
+	// MaxIdleConns is the maximum number of idle connections kept open across all hosts. Defaults to 100.
+	MaxIdleConns int `json:"maxIdleConns,omitempty" jsonschema:"optional"`
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept open to a host. Defaults to 2.
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty" jsonschema:"optional"`
+
+	// IdleConnTimeout is how long an idle connection is kept open, as a duration string. Defaults to 90s.
+	IdleConnTimeout string `json:"idleConnTimeout,omitempty" jsonschema:"optional"`
+
+	// DisableHTTP2 only uses HTTP/1.1, for backends or proxies that don't handle HTTP/2 correctly.
+	DisableHTTP2 bool `json:"disableHttp2,omitempty" jsonschema:"optional"`
+}
+
+func (cc *ClientConfig) Validate() error {
+	if cc.ProxyURL != "" {
+		u, err := neturl.Parse(cc.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxyUrl: %w", err)
+		}
+		if _, ok := validProxySchemes[u.Scheme]; !ok || u.Host == "" {
+			return fmt.Errorf("invalid proxyUrl '%s': must be an absolute http, https or socks5 URL", cc.ProxyURL)
+		}
+	}
+
+	for _, file := range cc.CACertFiles {
+		if file == "" {
+			return fmt.Errorf("caCertFiles must not contain empty paths")
+		}
+	}
+
+	if cc.MaxIdleConns < 0 {
+		return fmt.Errorf("maxIdleConns must not be negative")
+	}
+
+	if cc.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("maxIdleConnsPerHost must not be negative")
+	}
+
+	return validateDuration("idleConnTimeout", cc.IdleConnTimeout)
+}
+
+func (cc *ClientConfig) DeepCopy() *ClientConfig {
+	if cc == nil {
+		return nil
+	}
+
+	cp := *cc
+	if cc.CACertFiles != nil {
+		cp.CACertFiles = make([]string, len(cc.CACertFiles))
+		copy(cp.CACertFiles, cc.CACertFiles)
+	}
+
+	return &cp
+}
+
+// ForwardAuthConfig is the configuration for forwarding the bearer token of the incoming request to the backend.
+type ForwardAuthConfig struct {
+	// Mode is "passthrough" to forward the token as is, or "exchange" to exchange it for a token of the
+	// backend with an OAuth 2.0 token exchange (RFC 8693) first.
+	Mode string `json:"mode" jsonschema:"required,enum=passthrough,enum=exchange"`
+
+	// TokenExchange configures the token exchange. Required if Mode is "exchange".
+	TokenExchange *TokenExchangeConfig `json:"tokenExchange,omitempty" jsonschema:"optional"`
+}
+
+func (fac *ForwardAuthConfig) Validate() error {
+	if _, ok := validForwardAuthModes[strings.ToLower(fac.Mode)]; !ok {
+		return fmt.Errorf("invalid forward auth mode: '%s'", fac.Mode)
+	}
+
+	if strings.ToLower(fac.Mode) != ForwardAuthExchange {
+		if fac.TokenExchange != nil {
+			return fmt.Errorf("tokenExchange can only be set when mode is %s", ForwardAuthExchange)
+		}
+		return nil
+	}
+
+	if fac.TokenExchange == nil {
+		return fmt.Errorf("tokenExchange is required when mode is %s", ForwardAuthExchange)
+	}
+
+	return fac.TokenExchange.Validate()
+}
+
+func (fac *ForwardAuthConfig) DeepCopy() *ForwardAuthConfig {
+	if fac == nil {
+		return nil
+	}
+
+	cp := *fac
+	cp.TokenExchange = fac.TokenExchange.DeepCopy()
+	return &cp
+}
+
+// TokenExchangeConfig is the configuration of an OAuth 2.0 token exchange (RFC 8693), which trades the
+// bearer token of the incoming request for a token accepted by the backend.
+type TokenExchangeConfig struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string `json:"tokenUrl" jsonschema:"required"`
+
+	// ClientID identifies the server to the authorization server.
+	ClientID string `json:"clientId,omitempty" jsonschema:"optional"`
+
+	// ClientSecret authenticates the server to the authorization server, with HTTP basic authentication.
+	// It can contain placeholders in the form of {secrets.NAME} or ${ENV_VAR_NAME}.
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"optional"`
+
+	// Audience is the logical name of the backend the token is requested for.
+	Audience string `json:"audience,omitempty" jsonschema:"optional"`
+
+	// Resource is the URI of the backend the token is requested for.
+	Resource string `json:"resource,omitempty" jsonschema:"optional"`
+
+	// Scopes requested for the exchanged token.
+	Scopes []string `json:"scopes,omitempty" jsonschema:"optional"`
+
+	// RequestedTokenType is the type of the token requested, as a token type URI. Left to the
+	// authorization server if unset.
+	RequestedTokenType string `json:"requestedTokenType,omitempty" jsonschema:"optional"`
+}
+
+func (tec *TokenExchangeConfig) Validate() error {
+	if err := validateTokenURL(tec.TokenURL); err != nil {
+		return err
+	}
+
+	if tec.ClientSecret != "" && tec.ClientID == "" {
+		return fmt.Errorf("clientId is required when clientSecret is set")
+	}
+
+	return nil
+}
+
+func (tec *TokenExchangeConfig) DeepCopy() *TokenExchangeConfig {
+	if tec == nil {
+		return nil
+	}
+
+	cp := *tec
+	if tec.Scopes != nil {
+		cp.Scopes = make([]string, len(tec.Scopes))
+		copy(cp.Scopes, tec.Scopes)
+	}
+
+	return &cp
+}
+
+// ClientCredentialsConfig is the configuration of the OAuth 2.0 client credentials grant, which obtains an
+// access token for the backend on behalf of the server itself.
+type ClientCredentialsConfig struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string `json:"tokenUrl" jsonschema:"required"`
+
+	// ClientID identifies the server to the authorization server.
+	ClientID string `json:"clientId" jsonschema:"required"`
+
+	// ClientSecret authenticates the server to the authorization server.
+	// It can contain placeholders in the form of {secrets.NAME} or ${ENV_VAR_NAME}.
+	ClientSecret string `json:"clientSecret" jsonschema:"required"`
+
+	// ClientAuthMethod is how the client credentials are sent: "basic" (default) for HTTP basic
+	// authentication, or "post" for form parameters.
+	ClientAuthMethod string `json:"clientAuthMethod,omitempty" jsonschema:"optional,enum=basic,enum=post"`
+
+	// Scopes requested for the access token.
+	Scopes []string `json:"scopes,omitempty" jsonschema:"optional"`
+
+	// Audience is the logical name of the backend the token is requested for, for authorization servers
+	// that require it.
+	Audience string `json:"audience,omitempty" jsonschema:"optional"`
+
+	// Resource is the URI of the backend the token is requested for (RFC 8707).
+	Resource string `json:"resource,omitempty" jsonschema:"optional"`
+}
+
+func (ccc *ClientCredentialsConfig) Validate() error {
+	if err := validateTokenURL(ccc.TokenURL); err != nil {
+		return err
+	}
+
+	if ccc.ClientID == "" {
+		return fmt.Errorf("clientId is required")
+	}
+
+	if ccc.ClientSecret == "" {
+		return fmt.Errorf("clientSecret is required")
+	}
+
+	if ccc.ClientAuthMethod != "" {
+		if _, ok := validClientAuthMethods[strings.ToLower(ccc.ClientAuthMethod)]; !ok {
+			return fmt.Errorf("invalid client auth method: '%s'", ccc.ClientAuthMethod)
+		}
+	}
+
+	return nil
+}
+
+func (ccc *ClientCredentialsConfig) DeepCopy() *ClientCredentialsConfig {
+	if ccc == nil {
+		return nil
+	}
+
+	cp := *ccc
+	if ccc.Scopes != nil {
+		cp.Scopes = make([]string, len(ccc.Scopes))
+		copy(cp.Scopes, ccc.Scopes)
+	}
+
+	return &cp
+}
+
+// SigningConfig is the configuration of the HMAC signatures of the requests sent to the backend.
+type SigningConfig struct {
+	// Secret shared with the backend to sign the requests.
+	// It can contain placeholders in the form of {secrets.NAME} or ${ENV_VAR_NAME}.
+	Secret string `json:"secret" jsonschema:"required"`
+
+	// Header of the signature, sent as <algorithm>=<signature>. Defaults to X-Genmcp-Signature.
+	Header string `json:"header,omitempty" jsonschema:"optional"`
+
+	// TimestampHeader is the header of the time the request was signed at, in Unix seconds.
+	// Defaults to X-Genmcp-Timestamp.
+	TimestampHeader string `json:"timestampHeader,omitempty" jsonschema:"optional"`
+
+	// Algorithm is the hash function of the HMAC: "sha256" (default) or "sha512".
+	Algorithm string `json:"algorithm,omitempty" jsonschema:"optional,enum=sha256,enum=sha512"`
+
+	// Encoding of the signature: "hex" (default) or "base64".
+	Encoding string `json:"encoding,omitempty" jsonschema:"optional,enum=hex,enum=base64"`
+}
+
+func (sc *SigningConfig) Validate() error {
+	if sc.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	if _, ok := validSigningAlgorithms[strings.ToLower(sc.Algorithm)]; sc.Algorithm != "" && !ok {
+		return fmt.Errorf("invalid signing algorithm: '%s'", sc.Algorithm)
+	}
+
+	if _, ok := validSigningEncodings[strings.ToLower(sc.Encoding)]; sc.Encoding != "" && !ok {
+		return fmt.Errorf("invalid signing encoding: '%s'", sc.Encoding)
+	}
+
+	header := cmp.Or(sc.Header, signing.DefaultSignatureHeader)
+	timestampHeader := cmp.Or(sc.TimestampHeader, signing.DefaultTimestampHeader)
+	if strings.EqualFold(header, timestampHeader) {
+		return fmt.Errorf("header and timestampHeader must be different")
+	}
+
+	return nil
+}
+
+func (sc *SigningConfig) DeepCopy() *SigningConfig {
+	if sc == nil {
+		return nil
+	}
+
+	cp := *sc
+	return &cp
+}
+
+// validateTokenURL checks that tokenURL is set to an http or https URL.
+func validateTokenURL(tokenURL string) error {
+	if tokenURL == "" {
+		return fmt.Errorf("tokenUrl is required")
+	}
+
+	u, err := neturl.Parse(tokenURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid tokenUrl '%s': must be an http or https URL", tokenURL)
+	}
+
+	return nil
 }
 
 var _ invocation.InvocationConfig = &HttpInvocationConfig{}
+var _ invocation.FileReferencer = &HttpInvocationConfig{}
+var _ invocation.URLReferencer = &HttpInvocationConfig{}
 
 func (hic *HttpInvocationConfig) Validate() error {
 	if hic.URL == "" {
@@ -69,6 +871,93 @@ func (hic *HttpInvocationConfig) Validate() error {
 		return fmt.Errorf("bodyRoot and bodyAsArray are mutually exclusive")
 	}
 
+	if hic.Mapping != nil {
+		if hic.BodyRoot != "" {
+			return fmt.Errorf("mapping and bodyRoot are mutually exclusive")
+		}
+		if len(hic.Mapping.Body) > 0 && !methodHasBody(hic.Method) {
+			return fmt.Errorf("mapping.body can't be set for %s requests, which have no body", hic.Method)
+		}
+		if err := hic.Mapping.Validate(); err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+
+	if IsWebSocketURL(hic.URL) && !hic.Streaming {
+		return fmt.Errorf("websocket urls require streaming to be enabled")
+	}
+
+	if hic.MessageFraming != "" {
+		if !hic.Streaming {
+			return fmt.Errorf("messageFraming can only be set when streaming is enabled")
+		}
+		if _, ok := validMessageFramings[strings.ToLower(hic.MessageFraming)]; !ok {
+			return fmt.Errorf("invalid message framing: '%s'", hic.MessageFraming)
+		}
+	}
+
+	if err := hic.validateEncodings(); err != nil {
+		return err
+	}
+
+	if hic.Pagination != nil {
+		if hic.Streaming {
+			return fmt.Errorf("pagination is not supported for streaming requests")
+		}
+		if err := hic.Pagination.Validate(); err != nil {
+			return fmt.Errorf("invalid pagination config: %w", err)
+		}
+	}
+
+	if err := validateDuration("timeout", hic.Timeout); err != nil {
+		return err
+	}
+
+	if hic.Retry != nil {
+		if hic.Streaming {
+			return fmt.Errorf("retry is not supported for streaming requests")
+		}
+		if err := hic.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry config: %w", err)
+		}
+	}
+
+	if hic.CircuitBreaker != nil {
+		if hic.Streaming {
+			return fmt.Errorf("circuitBreaker is not supported for streaming requests")
+		}
+		if err := hic.CircuitBreaker.Validate(); err != nil {
+			return fmt.Errorf("invalid circuit breaker config: %w", err)
+		}
+	}
+
+	if hic.Client != nil {
+		if err := hic.Client.Validate(); err != nil {
+			return fmt.Errorf("invalid client config: %w", err)
+		}
+	}
+
+	if hic.ForwardAuth != nil {
+		if err := hic.ForwardAuth.Validate(); err != nil {
+			return fmt.Errorf("invalid forward auth config: %w", err)
+		}
+	}
+
+	if hic.ClientCredentials != nil {
+		if hic.ForwardAuth != nil {
+			return fmt.Errorf("clientCredentials and forwardAuth are mutually exclusive")
+		}
+		if err := hic.ClientCredentials.Validate(); err != nil {
+			return fmt.Errorf("invalid client credentials config: %w", err)
+		}
+	}
+
+	if hic.Signing != nil {
+		if err := hic.Signing.Validate(); err != nil {
+			return fmt.Errorf("invalid signing config: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -78,16 +967,163 @@ func (hic *HttpInvocationConfig) DeepCopy() invocation.InvocationConfig {
 		headers[k] = v
 	}
 
+	var fileParts map[string]*FilePartConfig
+	if hic.FileParts != nil {
+		fileParts = make(map[string]*FilePartConfig, len(hic.FileParts))
+		for k, v := range hic.FileParts {
+			fileParts[k] = v.DeepCopy()
+		}
+	}
+
 	return &HttpInvocationConfig{
-		URL:         hic.URL,
-		Headers:     headers,
-		Method:      hic.Method,
-		BodyRoot:    hic.BodyRoot,
-		BodyAsArray: hic.BodyAsArray,
+		URL:               hic.URL,
+		Headers:           headers,
+		Method:            hic.Method,
+		BodyRoot:          hic.BodyRoot,
+		BodyAsArray:       hic.BodyAsArray,
+		Mapping:           hic.Mapping.DeepCopy(),
+		ContentType:       hic.ContentType,
+		Accept:            hic.Accept,
+		FileParts:         fileParts,
+		XMLRoot:           hic.XMLRoot,
+		Protobuf:          hic.Protobuf.DeepCopy(),
+		Streaming:         hic.Streaming,
+		MessageFraming:    hic.MessageFraming,
+		Pagination:        hic.Pagination.DeepCopy(),
+		Timeout:           hic.Timeout,
+		Retry:             hic.Retry.DeepCopy(),
+		CircuitBreaker:    hic.CircuitBreaker.DeepCopy(),
+		Client:            hic.Client.DeepCopy(),
+		ForwardAuth:       hic.ForwardAuth.DeepCopy(),
+		ClientCredentials: hic.ClientCredentials.DeepCopy(),
+		Signing:           hic.Signing.DeepCopy(),
+	}
+}
+
+// ReferencedFiles returns the descriptor set of protobuf bodies and the CA certificates of the client.
+func (hic *HttpInvocationConfig) ReferencedFiles() []string {
+	var files []string
+	if hic.Protobuf != nil && hic.Protobuf.DescriptorSet != "" {
+		files = append(files, hic.Protobuf.DescriptorSet)
+	}
+	if hic.Client != nil {
+		files = append(files, hic.Client.CACertFiles...)
+	}
+	return files
+}
+
+// ReferencedURLs returns the URL of the request and the token endpoints of the authorization servers.
+func (hic *HttpInvocationConfig) ReferencedURLs() []string {
+	urls := []string{hic.URL}
+	if hic.ForwardAuth != nil && hic.ForwardAuth.TokenExchange != nil {
+		urls = append(urls, hic.ForwardAuth.TokenExchange.TokenURL)
+	}
+	if hic.ClientCredentials != nil {
+		urls = append(urls, hic.ClientCredentials.TokenURL)
+	}
+	return urls
+}
+
+// validateEncodings checks the encodings of the request and response bodies, and the protobuf messages they need.
+func (hic *HttpInvocationConfig) validateEncodings() error {
+	contentType := strings.ToLower(hic.ContentType)
+	accept := strings.ToLower(hic.Accept)
+
+	if contentType != "" {
+		if _, ok := validContentTypes[contentType]; !ok {
+			return fmt.Errorf("invalid content type: '%s'", hic.ContentType)
+		}
+	}
+
+	if accept != "" {
+		if _, ok := validAccepts[accept]; !ok {
+			return fmt.Errorf("invalid accept: '%s'", hic.Accept)
+		}
+	}
+
+	if hic.Streaming && (!isJSONEncoding(contentType) || !isJSONEncoding(accept)) {
+		return fmt.Errorf("contentType and accept must be json for streaming requests")
+	}
+
+	if hic.BodyAsArray && !isJSONEncoding(contentType) && contentType != EncodingXML {
+		return fmt.Errorf("bodyAsArray is not supported for %s bodies", contentType)
+	}
+
+	if len(hic.FileParts) > 0 && contentType != EncodingMultipart {
+		return fmt.Errorf("fileParts can only be set when contentType is %s", EncodingMultipart)
+	}
+	for property, part := range hic.FileParts {
+		if part == nil {
+			return fmt.Errorf("fileParts.%s must not be empty", property)
+		}
+		if err := part.Validate(); err != nil {
+			return fmt.Errorf("invalid fileParts.%s: %w", property, err)
+		}
+	}
+
+	if hic.XMLRoot != "" && contentType != EncodingXML {
+		return fmt.Errorf("xmlRoot can only be set when contentType is %s", EncodingXML)
+	}
+
+	if hic.Protobuf == nil {
+		if contentType == EncodingProtobuf || accept == EncodingProtobuf {
+			return fmt.Errorf("protobuf is required when contentType or accept is %s", EncodingProtobuf)
+		}
+		return nil
+	}
+
+	if err := hic.Protobuf.Validate(); err != nil {
+		return fmt.Errorf("invalid protobuf config: %w", err)
 	}
+	if contentType == EncodingProtobuf && hic.Protobuf.RequestMessage == "" {
+		return fmt.Errorf("protobuf requestMessage is required when contentType is %s", EncodingProtobuf)
+	}
+	if accept == EncodingProtobuf && hic.Protobuf.ResponseMessage == "" {
+		return fmt.Errorf("protobuf responseMessage is required when accept is %s", EncodingProtobuf)
+	}
+
+	return nil
+}
+
+// isJSONEncoding reports whether a (lower case) encoding is JSON, which is the default.
+func isJSONEncoding(encoding string) bool {
+	return encoding == "" || encoding == EncodingJSON
 }
 
 func IsValidHttpMethod(method string) bool {
 	_, ok := validHttpMethods[strings.ToUpper(method)]
 	return ok
 }
+
+// methodHasBody reports whether requests with method send a body.
+func methodHasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case nethttp.MethodGet, nethttp.MethodDelete, nethttp.MethodHead:
+		return false
+	default:
+		return true
+	}
+}
+
+// IsWebSocketURL reports whether the (possibly templated) URL uses the ws:// or wss:// scheme.
+func IsWebSocketURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
+
+// validateDuration checks that value is empty or a positive duration string.
+func validateDuration(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s '%s': %w", field, value, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive", field)
+	}
+
+	return nil
+}
@@ -36,6 +36,643 @@ func TestHttpInvocationConfig_Validate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "streaming with lines framing",
+			config: &HttpInvocationConfig{
+				URL:            "/api/events",
+				Method:         "GET",
+				Streaming:      true,
+				MessageFraming: "lines",
+			},
+			expectError: false,
+		},
+		{
+			name: "websocket url with streaming",
+			config: &HttpInvocationConfig{
+				URL:       "wss://example.com/ws",
+				Method:    "GET",
+				Streaming: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "websocket url without streaming",
+			config: &HttpInvocationConfig{
+				URL:    "ws://example.com/ws",
+				Method: "GET",
+			},
+			expectError: true,
+		},
+		{
+			name: "message framing without streaming",
+			config: &HttpInvocationConfig{
+				URL:            "/api/events",
+				Method:         "GET",
+				MessageFraming: "sse",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid message framing",
+			config: &HttpInvocationConfig{
+				URL:            "/api/events",
+				Method:         "GET",
+				Streaming:      true,
+				MessageFraming: "xml",
+			},
+			expectError: true,
+		},
+		{
+			name: "timeout and retry",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "GET",
+				Timeout: "5s",
+				Retry: &RetryConfig{
+					MaxRetries:           3,
+					Backoff:              "linear",
+					InitialDelay:         "100ms",
+					MaxDelay:             "2s",
+					RetryableStatusCodes: []int{500, 503},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid timeout",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "GET",
+				Timeout: "soon",
+			},
+			expectError: true,
+		},
+		{
+			name: "non-positive timeout",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "GET",
+				Timeout: "0s",
+			},
+			expectError: true,
+		},
+		{
+			name: "negative max retries",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Retry:  &RetryConfig{MaxRetries: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid backoff strategy",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Retry:  &RetryConfig{MaxRetries: 1, Backoff: "random"},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid retryable status code",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Retry:  &RetryConfig{MaxRetries: 1, RetryableStatusCodes: []int{999}},
+			},
+			expectError: true,
+		},
+		{
+			name: "retry with streaming",
+			config: &HttpInvocationConfig{
+				URL:       "/api/events",
+				Method:    "GET",
+				Streaming: true,
+				Retry:     &RetryConfig{MaxRetries: 1},
+			},
+			expectError: true,
+		},
+		{
+			name: "circuit breaker",
+			config: &HttpInvocationConfig{
+				URL:            "/api/users",
+				Method:         "GET",
+				CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 3, CoolDown: "1m"},
+			},
+			expectError: false,
+		},
+		{
+			name: "negative circuit breaker failure threshold",
+			config: &HttpInvocationConfig{
+				URL:            "/api/users",
+				Method:         "GET",
+				CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid circuit breaker cool down",
+			config: &HttpInvocationConfig{
+				URL:            "/api/users",
+				Method:         "GET",
+				CircuitBreaker: &CircuitBreakerConfig{CoolDown: "soon"},
+			},
+			expectError: true,
+		},
+		{
+			name: "circuit breaker with streaming",
+			config: &HttpInvocationConfig{
+				URL:            "/api/events",
+				Method:         "GET",
+				Streaming:      true,
+				CircuitBreaker: &CircuitBreakerConfig{},
+			},
+			expectError: true,
+		},
+		{
+			name: "valid forward auth passthrough",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "GET",
+				ForwardAuth: &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid forward auth exchange",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ForwardAuth: &ForwardAuthConfig{
+					Mode: ForwardAuthExchange,
+					TokenExchange: &TokenExchangeConfig{
+						TokenURL:     "https://sso.example.com/token",
+						ClientID:     "genmcp",
+						ClientSecret: "{secrets.CLIENT_SECRET}",
+						Audience:     "users-api",
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid forward auth mode",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "GET",
+				ForwardAuth: &ForwardAuthConfig{Mode: "impersonate"},
+			},
+			expectError: true,
+		},
+		{
+			name: "forward auth exchange without token exchange",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "GET",
+				ForwardAuth: &ForwardAuthConfig{Mode: ForwardAuthExchange},
+			},
+			expectError: true,
+		},
+		{
+			name: "forward auth passthrough with token exchange",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ForwardAuth: &ForwardAuthConfig{
+					Mode:          ForwardAuthPassthrough,
+					TokenExchange: &TokenExchangeConfig{TokenURL: "https://sso.example.com/token"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "token exchange with invalid token url",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ForwardAuth: &ForwardAuthConfig{
+					Mode:          ForwardAuthExchange,
+					TokenExchange: &TokenExchangeConfig{TokenURL: "sso.example.com/token"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "token exchange with client secret but no client id",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ForwardAuth: &ForwardAuthConfig{
+					Mode:          ForwardAuthExchange,
+					TokenExchange: &TokenExchangeConfig{TokenURL: "https://sso.example.com/token", ClientSecret: "s3cr3t"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "valid client credentials",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ClientCredentials: &ClientCredentialsConfig{
+					TokenURL:         "https://sso.example.com/token",
+					ClientID:         "genmcp",
+					ClientSecret:     "{secrets.CLIENT_SECRET}",
+					ClientAuthMethod: ClientAuthPost,
+					Scopes:           []string{"users:read"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "client credentials without client secret",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ClientCredentials: &ClientCredentialsConfig{
+					TokenURL: "https://sso.example.com/token",
+					ClientID: "genmcp",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "client credentials with invalid client auth method",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				ClientCredentials: &ClientCredentialsConfig{
+					TokenURL:         "https://sso.example.com/token",
+					ClientID:         "genmcp",
+					ClientSecret:     "s3cr3t",
+					ClientAuthMethod: "jwt",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "client credentials with forward auth",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "GET",
+				ForwardAuth: &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+				ClientCredentials: &ClientCredentialsConfig{
+					TokenURL:     "https://sso.example.com/token",
+					ClientID:     "genmcp",
+					ClientSecret: "s3cr3t",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "signing",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Signing: &SigningConfig{Secret: "{secrets.SIGNING_SECRET}", Algorithm: "sha512", Encoding: "base64"},
+			},
+			expectError: false,
+		},
+		{
+			name: "signing without secret",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Signing: &SigningConfig{},
+			},
+			expectError: true,
+		},
+		{
+			name: "signing with invalid algorithm",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Signing: &SigningConfig{Secret: "s3cret", Algorithm: "md5"},
+			},
+			expectError: true,
+		},
+		{
+			name: "signing with the same signature and timestamp headers",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Signing: &SigningConfig{Secret: "s3cret", Header: "X-Genmcp-Timestamp"},
+			},
+			expectError: true,
+		},
+		{
+			name: "form content type with xml accept",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "POST",
+				ContentType: "form",
+				Accept:      "xml",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid content type",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "POST",
+				ContentType: "yaml",
+			},
+			expectError: true,
+		},
+		{
+			name: "multipart accept",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "POST",
+				Accept: "multipart",
+			},
+			expectError: true,
+		},
+		{
+			name: "form content type with streaming",
+			config: &HttpInvocationConfig{
+				URL:         "/api/events",
+				Method:      "POST",
+				ContentType: "form",
+				Streaming:   true,
+			},
+			expectError: true,
+		},
+		{
+			name: "form content type with body as array",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "POST",
+				ContentType: "form",
+				BodyAsArray: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "xml root without xml content type",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				XMLRoot: "user",
+			},
+			expectError: true,
+		},
+		{
+			name: "protobuf content type without protobuf config",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "POST",
+				ContentType: "protobuf",
+			},
+			expectError: true,
+		},
+		{
+			name: "protobuf accept without response message",
+			config: &HttpInvocationConfig{
+				URL:      "/api/users",
+				Method:   "GET",
+				Accept:   "protobuf",
+				Protobuf: &ProtobufConfig{DescriptorSet: "users.pb", RequestMessage: "users.v1.GetUserRequest"},
+			},
+			expectError: true,
+		},
+		{
+			name: "protobuf content type and accept",
+			config: &HttpInvocationConfig{
+				URL:         "/api/users",
+				Method:      "POST",
+				ContentType: "protobuf",
+				Accept:      "protobuf",
+				Protobuf: &ProtobufConfig{
+					DescriptorSet:   "users.pb",
+					RequestMessage:  "users.v1.CreateUserRequest",
+					ResponseMessage: "users.v1.User",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "client settings",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Client: &ClientConfig{
+					ProxyURL:            "http://proxy.internal:3128",
+					CACertFiles:         []string{"/etc/ssl/internal-ca.pem"},
+					DisableKeepAlives:   true,
+					MaxIdleConns:        50,
+					MaxIdleConnsPerHost: 10,
+					IdleConnTimeout:     "30s",
+					DisableHTTP2:        true,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "client with relative proxy url",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Client: &ClientConfig{ProxyURL: "proxy.internal:3128"},
+			},
+			expectError: true,
+		},
+		{
+			name: "client with negative max idle connections",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Client: &ClientConfig{MaxIdleConnsPerHost: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "client with invalid idle connection timeout",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Client: &ClientConfig{IdleConnTimeout: "forever"},
+			},
+			expectError: true,
+		},
+		{
+			name: "mapping",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users/{id}",
+				Method: "POST",
+				Mapping: &MappingConfig{
+					Query: map[string]string{"v": "apiVersion"},
+					Body:  map[string]string{"user.name": "name", "user.email": "$.contact.email"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "mapping with bodyRoot",
+			config: &HttpInvocationConfig{
+				URL:      "/api/users",
+				Method:   "POST",
+				BodyRoot: "user",
+				Mapping:  &MappingConfig{Query: map[string]string{"v": "apiVersion"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "mapping body for GET request",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "GET",
+				Mapping: &MappingConfig{Body: map[string]string{"name": "name"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "mapping with conflicting body paths",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Mapping: &MappingConfig{Body: map[string]string{"user": "user", "user.name": "name"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "mapping with invalid body path",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "POST",
+				Mapping: &MappingConfig{Body: map[string]string{"users[0].name": "name"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "mapping with invalid property path",
+			config: &HttpInvocationConfig{
+				URL:     "/api/users",
+				Method:  "GET",
+				Mapping: &MappingConfig{Query: map[string]string{"tag": "tags[first]"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "cursor pagination",
+			config: &HttpInvocationConfig{
+				URL:    "/api/users",
+				Method: "GET",
+				Pagination: &PaginationConfig{
+					CursorParam:    "cursor",
+					NextCursorPath: "$.meta.next",
+					ItemsPath:      "data",
+					MaxPages:       5,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "page pagination",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{PageParam: "page", FirstPage: new(int)},
+			},
+			expectError: false,
+		},
+		{
+			name: "pagination without cursor or page param",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{ItemsPath: "data"},
+			},
+			expectError: true,
+		},
+		{
+			name: "pagination with cursor and page params",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{CursorParam: "cursor", NextCursorPath: "next", PageParam: "page"},
+			},
+			expectError: true,
+		},
+		{
+			name: "cursor pagination without next cursor path",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{CursorParam: "cursor"},
+			},
+			expectError: true,
+		},
+		{
+			name: "pagination with invalid items path",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{PageParam: "page", ItemsPath: "data[x]"},
+			},
+			expectError: true,
+		},
+		{
+			name: "pagination with negative max pages",
+			config: &HttpInvocationConfig{
+				URL:        "/api/users",
+				Method:     "GET",
+				Pagination: &PaginationConfig{PageParam: "page", MaxPages: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "pagination with streaming",
+			config: &HttpInvocationConfig{
+				URL:        "/api/events",
+				Method:     "GET",
+				Streaming:  true,
+				Pagination: &PaginationConfig{PageParam: "page"},
+			},
+			expectError: true,
+		},
+		{
+			name: "file parts with multipart content type",
+			config: &HttpInvocationConfig{
+				URL:         "/api/documents",
+				Method:      "POST",
+				ContentType: "multipart",
+				FileParts: map[string]*FilePartConfig{
+					"document": {Source: "url", PartName: "file", ContentType: "application/pdf"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "file parts without multipart content type",
+			config: &HttpInvocationConfig{
+				URL:       "/api/documents",
+				Method:    "POST",
+				FileParts: map[string]*FilePartConfig{"document": {}},
+			},
+			expectError: true,
+		},
+		{
+			name: "file part with invalid source",
+			config: &HttpInvocationConfig{
+				URL:         "/api/documents",
+				Method:      "POST",
+				ContentType: "multipart",
+				FileParts:   map[string]*FilePartConfig{"document": {Source: "path"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "empty file part",
+			config: &HttpInvocationConfig{
+				URL:         "/api/documents",
+				Method:      "POST",
+				ContentType: "multipart",
+				FileParts:   map[string]*FilePartConfig{"document": nil},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -85,3 +722,33 @@ func TestIsValidHttpMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpInvocationConfig_ReferencedFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *HttpInvocationConfig
+		want   []string
+	}{
+		{
+			name:   "no files",
+			config: &HttpInvocationConfig{URL: "http://localhost/users", Method: "GET"},
+			want:   nil,
+		},
+		{
+			name: "descriptor set and CA certificates",
+			config: &HttpInvocationConfig{
+				URL:      "http://localhost/users",
+				Method:   "POST",
+				Protobuf: &ProtobufConfig{DescriptorSet: "protos/users.pb", RequestMessage: "users.User"},
+				Client:   &ClientConfig{CACertFiles: []string{"certs/ca.pem", "certs/internal-ca.pem"}},
+			},
+			want: []string{"protos/users.pb", "certs/ca.pem", "certs/internal-ca.pem"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.ReferencedFiles())
+		})
+	}
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"time"
 )
 
 type httpClientKey struct{}
@@ -28,3 +30,27 @@ func HTTPClientFromContext(ctx context.Context) *http.Client {
 
 	return httpClient
 }
+
+// DialContextFromContext returns the dial function of the transport of the HTTP client stored in the context,
+// which enforces the network policy of the server. Invokers connecting to their backends without HTTP use it, so
+// that the policy applies to them too. If the client has no such transport, it returns the one of a default dialer.
+func DialContextFromContext(ctx context.Context) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if transport, ok := HTTPClientFromContext(ctx).Transport.(*http.Transport); ok && transport.DialContext != nil {
+		return transport.DialContext
+	}
+	return (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+}
+
+type validatedBearerTokensKey struct{}
+
+// WithValidatedBearerTokens marks the bearer tokens of incoming requests as validated by the server, which
+// allows HTTP invokers to forward them to backends.
+func WithValidatedBearerTokens(ctx context.Context) context.Context {
+	return context.WithValue(ctx, validatedBearerTokensKey{}, true)
+}
+
+// validatedBearerTokens reports whether the bearer tokens of incoming requests were validated by the server.
+func validatedBearerTokens(ctx context.Context) bool {
+	validated, _ := ctx.Value(validatedBearerTokensKey{}).(bool)
+	return validated
+}
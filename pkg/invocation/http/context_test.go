@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -111,3 +112,17 @@ func TestWithHTTPClientMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestWithValidatedBearerTokensMiddleware(t *testing.T) {
+	assert.False(t, validatedBearerTokens(context.Background()))
+
+	var validated bool
+	handler := WithValidatedBearerTokensMiddleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		validated = validatedBearerTokens(ctx)
+		return nil, nil
+	})
+
+	_, err := handler(context.Background(), "tools/call", nil)
+	assert.NoError(t, err)
+	assert.True(t, validated)
+}
@@ -0,0 +1,541 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	nethttp "net/http"
+	neturl "net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	acceptHeader = "Accept"
+
+	// defaultXMLRoot is the name of the root element of XML request bodies if none is configured.
+	defaultXMLRoot = "request"
+)
+
+// encodingMediaTypes are the media types sent in the Content-Type and Accept headers for each encoding.
+var encodingMediaTypes = map[string]string{
+	EncodingJSON:      "application/json",
+	EncodingForm:      "application/x-www-form-urlencoded",
+	EncodingMultipart: "multipart/form-data",
+	EncodingXML:       "application/xml",
+	EncodingProtobuf:  "application/x-protobuf",
+}
+
+// ProtobufMessages are the resolved messages of the protobuf bodies of an HTTP invocation.
+type ProtobufMessages struct {
+	Request  protoreflect.MessageDescriptor // message of request bodies, if any
+	Response protoreflect.MessageDescriptor // message of response bodies, if any
+}
+
+// NewProtobufMessages loads the descriptor set of config and resolves its messages. Returns nil if config is nil.
+func NewProtobufMessages(config *ProtobufConfig) (*ProtobufMessages, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(config.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("failed to decode descriptor set %s: %w", config.DescriptorSet, err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %w", config.DescriptorSet, err)
+	}
+
+	findMessage := func(name string) (protoreflect.MessageDescriptor, error) {
+		if name == "" {
+			return nil, nil
+		}
+
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find message %s: %w", name, err)
+		}
+
+		md, ok := desc.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a message", name)
+		}
+
+		return md, nil
+	}
+
+	messages := &ProtobufMessages{}
+	if messages.Request, err = findMessage(config.RequestMessage); err != nil {
+		return nil, err
+	}
+	if messages.Response, err = findMessage(config.ResponseMessage); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// encodeBody encodes a request body with the content type of the invocation, returning the encoded body and the
+// value of its Content-Type header.
+// When dryRun is true, files are not downloaded from the URLs of file parts.
+func (hi *HttpInvoker) encodeBody(ctx context.Context, body any, dryRun bool) ([]byte, string, error) {
+	switch hi.ContentType {
+	case EncodingForm:
+		values, err := formValues(body)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(values.Encode()), encodingMediaTypes[EncodingForm], nil
+	case EncodingMultipart:
+		return hi.encodeMultipart(ctx, body, dryRun)
+	case EncodingXML:
+		encoded, err := encodeXML(hi.xmlRoot(), body)
+		if err != nil {
+			return nil, "", err
+		}
+		return encoded, encodingMediaTypes[EncodingXML] + "; charset=UTF-8", nil
+	case EncodingProtobuf:
+		encoded, err := encodeProtobuf(hi.Protobuf.Request, body)
+		if err != nil {
+			return nil, "", err
+		}
+		return encoded, encodingMediaTypes[EncodingProtobuf], nil
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", err
+		}
+		return encoded, encodingMediaTypes[EncodingJSON] + "; charset=UTF-8", nil
+	}
+}
+
+func (hi *HttpInvoker) xmlRoot() string {
+	if hi.XMLRoot == "" {
+		return defaultXMLRoot
+	}
+	return hi.XMLRoot
+}
+
+// formValues encodes the properties of body as form fields.
+func formValues(body any) (neturl.Values, error) {
+	fields, ok := body.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("form bodies must be objects, got %T", body)
+	}
+
+	values := neturl.Values{}
+	for name, value := range fields {
+		if value == nil {
+			continue
+		}
+
+		fieldValues, err := formFieldValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		values[name] = fieldValues
+	}
+
+	return values, nil
+}
+
+// formFieldValues returns the values of a form field: scalars as is, arrays of scalars as repeated values, and
+// other values as JSON.
+func formFieldValues(value any) ([]string, error) {
+	if s, ok := formatScalar(value); ok {
+		return []string{s}, nil
+	}
+
+	if items, ok := value.([]any); ok {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := formatScalar(item)
+			if !ok {
+				values = nil
+				break
+			}
+			values = append(values, s)
+		}
+		if values != nil {
+			return values, nil
+		}
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(encoded)}, nil
+}
+
+// formatScalar formats strings, numbers and booleans as text.
+func formatScalar(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// encodeXML encodes body as an XML document with a root element named root. Object properties are encoded as
+// child elements, and the items of arrays as repeated elements.
+func encodeXML(root string, body any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(buf)
+	if err := encodeXMLElement(enc, root, body); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeXMLElement encodes value as an element. The items of arrays that are object properties are encoded as
+// repeated elements named after the property, and those of other arrays as child elements named item.
+func encodeXMLElement(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return fmt.Errorf("failed to encode XML element %s: %w", name, err)
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			items, ok := v[key].([]any)
+			if !ok {
+				items = []any{v[key]}
+			}
+			for _, item := range items {
+				if err := encodeXMLElement(enc, key, item); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := encodeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	default:
+		text, ok := formatScalar(v)
+		if !ok {
+			return fmt.Errorf("unsupported XML value of type %T", v)
+		}
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// decodeXML decodes an XML document as an object with a property named after its root element. Elements with
+// child elements or attributes are decoded as objects, where attributes are prefixed with @ and the text of the
+// element is #text, and other elements as their text. Repeated child elements are decoded as arrays.
+func decodeXML(data []byte) (map[string]any, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML document: %w", err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			value, err := decodeXMLElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: value}, nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	children := make(map[string]any)
+	for _, attr := range start.Attr {
+		children["@"+attr.Name.Local] = attr.Value
+	}
+
+	text := &strings.Builder{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML document: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			value, err := decodeXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = value
+			case []any:
+				children[name] = append(existing, value)
+			default:
+				children[name] = []any{existing, value}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(children) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				children["#text"] = trimmed
+			}
+			return children, nil
+		}
+	}
+}
+
+// decodeForm decodes a URL encoded form as an object, with the values of repeated fields as arrays.
+func decodeForm(data []byte) (map[string]any, error) {
+	values, err := neturl.ParseQuery(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form: %w", err)
+	}
+
+	decoded := make(map[string]any, len(values))
+	for name, fieldValues := range values {
+		if len(fieldValues) == 1 {
+			decoded[name] = fieldValues[0]
+			continue
+		}
+
+		items := make([]any, 0, len(fieldValues))
+		for _, v := range fieldValues {
+			items = append(items, v)
+		}
+		decoded[name] = items
+	}
+
+	return decoded, nil
+}
+
+// encodeProtobuf encodes body as a message, from its JSON mapping.
+func encodeProtobuf(message protoreflect.MessageDescriptor, body any) ([]byte, error) {
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(message)
+	if err := protojson.Unmarshal(bodyJSON, msg); err != nil {
+		return nil, fmt.Errorf("failed to convert body to %s: %w", message.FullName(), err)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// decodeProtobuf decodes a message and returns its JSON mapping.
+func decodeProtobuf(message protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(message)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", message.FullName(), err)
+	}
+
+	return protojson.Marshal(msg)
+}
+
+// decodedResponse is a response body decoded according to its content type.
+type decodedResponse struct {
+	text     []byte // the body returned to the client: the body itself, or its JSON mapping for protobuf
+	mimeType string // the MIME type of text
+	json     []byte // the body as JSON, nil if its content type is not decoded
+	binary   bool   // whether text is binary data, such as an image or a PDF, that can't be returned as text
+}
+
+// decodeResponse decodes a response body according to its Content-Type, or to the Accept encoding of the
+// invocation if the response doesn't have a specific one. Bodies that fail to decode are returned as is,
+// except protobuf bodies, which can't be returned as text. Bodies that aren't text, including protobuf
+// bodies without a response message, are marked as binary.
+func (hi *HttpInvoker) decodeResponse(contentType string, body []byte) (*decodedResponse, error) {
+	decoded := &decodedResponse{text: body, mimeType: contentType}
+
+	encoding := responseEncoding(contentType)
+	if encoding == "" && hi.Accept != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "" || mediaType == "application/octet-stream" {
+			encoding = hi.Accept
+		}
+	}
+
+	var structured map[string]any
+	var err error
+	switch encoding {
+	case EncodingJSON:
+		decoded.json = body
+		return decoded, nil
+	case EncodingXML:
+		structured, err = decodeXML(body)
+	case EncodingForm:
+		structured, err = decodeForm(body)
+	case EncodingProtobuf:
+		if hi.Protobuf == nil || hi.Protobuf.Response == nil {
+			decoded.binary = true
+			return decoded, nil
+		}
+		decoded.json, err = decodeProtobuf(hi.Protobuf.Response, body)
+		if err != nil {
+			return nil, err
+		}
+		decoded.text = decoded.json
+		decoded.mimeType = encodingMediaTypes[EncodingJSON]
+		return decoded, nil
+	default:
+		decoded.binary = isBinary(contentType, body)
+		return decoded, nil
+	}
+	if err != nil {
+		return decoded, nil
+	}
+
+	decoded.json, err = json.Marshal(structured)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// content returns the content of a tool result or prompt message holding the response: text content for text
+// responses, image or audio content for images and audio, and an embedded resource identified by uri with the
+// body as its blob for other binary responses.
+func (d *decodedResponse) content(uri string) mcp.Content {
+	if !d.binary {
+		return &mcp.TextContent{Text: string(d.text)}
+	}
+
+	mimeType := d.mimeType
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = mediaType
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return &mcp.ImageContent{Data: d.text, MIMEType: mimeType}
+	case strings.HasPrefix(mimeType, "audio/"):
+		return &mcp.AudioContent{Data: d.text, MIMEType: mimeType}
+	default:
+		return &mcp.EmbeddedResource{Resource: d.resourceContents(uri)}
+	}
+}
+
+// resourceContents returns the contents of a resource holding the response, as text or as a blob for binary
+// responses.
+func (d *decodedResponse) resourceContents(uri string) *mcp.ResourceContents {
+	contents := &mcp.ResourceContents{URI: uri, MIMEType: d.mimeType}
+	if d.binary {
+		if contents.MIMEType == "" {
+			contents.MIMEType = defaultFileContentType
+		}
+		contents.Blob = d.text
+	} else {
+		contents.Text = string(d.text)
+	}
+	return contents
+}
+
+// isBinary returns whether a response body with a Content-Type that isn't decoded is binary data. Images, audio,
+// video and fonts are always binary, text types never are, and bodies of other types are binary if they aren't
+// valid UTF-8.
+func isBinary(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"),
+		strings.HasSuffix(mediaType, "+yaml"),
+		mediaType == "application/javascript",
+		mediaType == "application/yaml",
+		mediaType == "application/x-yaml",
+		mediaType == "application/x-ndjson":
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "font/"):
+		return true
+	default:
+		return !utf8.Valid(body)
+	}
+}
+
+// responseEncoding returns the encoding of a Content-Type, or an empty string if it isn't decoded.
+func responseEncoding(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return EncodingJSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return EncodingXML
+	case mediaType == encodingMediaTypes[EncodingForm]:
+		return EncodingForm
+	case mediaType == "application/x-protobuf" || mediaType == "application/protobuf" || mediaType == "application/vnd.google.protobuf":
+		return EncodingProtobuf
+	default:
+		return ""
+	}
+}
+
+// setAccept sets the Accept header to the media type of the Accept encoding of the invocation, unless it is
+// already set.
+func (hi *HttpInvoker) setAccept(headers nethttp.Header) {
+	if hi.Accept != "" && headers.Get(acceptHeader) == "" {
+		headers.Set(acceptHeader, encodingMediaTypes[hi.Accept])
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
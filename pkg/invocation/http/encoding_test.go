@@ -0,0 +1,441 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var resolvedWithFile, _ = (&jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"name": {Type: invocation.JsonSchemaTypeString},
+		"tags": {
+			Type:  invocation.JsonSchemaTypeArray,
+			Items: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString},
+		},
+		"age":    {Type: invocation.JsonSchemaTypeInteger},
+		"avatar": {Type: invocation.JsonSchemaTypeString, ContentEncoding: "base64"},
+	},
+}).Resolve(nil)
+
+func TestHttpInvoker_Encodings(t *testing.T) {
+	tt := []struct {
+		name                string
+		contentType         string
+		accept              string
+		args                string
+		responseContentType string
+		responseBody        string
+		checkRequest        func(t *testing.T, r *nethttp.Request, body []byte)
+		expectedText        string
+		expectedStructured  map[string]any
+	}{
+		{
+			name:                "json by default",
+			args:                `{"name": "alice"}`,
+			responseContentType: "application/json",
+			responseBody:        `{"id": 1}`,
+			checkRequest: func(t *testing.T, r *nethttp.Request, body []byte) {
+				assert.Equal(t, "application/json; charset=UTF-8", r.Header.Get("Content-Type"))
+				assert.Empty(t, r.Header.Get("Accept"))
+				assert.JSONEq(t, `{"name": "alice"}`, string(body))
+			},
+			expectedText:       `{"id": 1}`,
+			expectedStructured: map[string]any{"id": float64(1)},
+		},
+		{
+			name:                "form request with form response",
+			contentType:         EncodingForm,
+			accept:              EncodingForm,
+			args:                `{"name": "alice", "tags": ["a", "b"], "age": 30}`,
+			responseContentType: "application/x-www-form-urlencoded",
+			responseBody:        "id=1&roles=admin&roles=dev",
+			checkRequest: func(t *testing.T, r *nethttp.Request, body []byte) {
+				assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+				assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Accept"))
+				assert.Equal(t, "age=30&name=alice&tags=a&tags=b", string(body))
+			},
+			expectedText:       "id=1&roles=admin&roles=dev",
+			expectedStructured: map[string]any{"id": "1", "roles": []any{"admin", "dev"}},
+		},
+		{
+			name:        "multipart request with file",
+			contentType: EncodingMultipart,
+			args:        `{"name": "alice", "avatar": "aGVsbG8="}`,
+			checkRequest: func(t *testing.T, r *nethttp.Request, body []byte) {
+				mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				require.NoError(t, err)
+				assert.Equal(t, "multipart/form-data", mediaType)
+
+				form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(1 << 20)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"alice"}, form.Value["name"])
+				require.Len(t, form.File["avatar"], 1)
+				f, err := form.File["avatar"][0].Open()
+				require.NoError(t, err)
+				content, err := io.ReadAll(f)
+				require.NoError(t, err)
+				assert.Equal(t, "hello", string(content))
+			},
+			responseContentType: "text/plain",
+			responseBody:        "created",
+			expectedText:        "created",
+		},
+		{
+			name:                "xml request with xml response",
+			contentType:         EncodingXML,
+			accept:              EncodingXML,
+			args:                `{"name": "alice", "tags": ["a", "b"]}`,
+			responseContentType: "application/xml",
+			responseBody:        `<user id="1"><name>alice</name><role>admin</role><role>dev</role></user>`,
+			checkRequest: func(t *testing.T, r *nethttp.Request, body []byte) {
+				assert.Equal(t, "application/xml; charset=UTF-8", r.Header.Get("Content-Type"))
+				assert.Equal(t, "application/xml", r.Header.Get("Accept"))
+				assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+					`<request><name>alice</name><tags>a</tags><tags>b</tags></request>`, string(body))
+			},
+			expectedText: `<user id="1"><name>alice</name><role>admin</role><role>dev</role></user>`,
+			expectedStructured: map[string]any{"user": map[string]any{
+				"@id":  "1",
+				"name": "alice",
+				"role": []any{"admin", "dev"},
+			}},
+		},
+		{
+			name:         "accept decodes responses without content type",
+			accept:       EncodingXML,
+			args:         `{}`,
+			responseBody: `<status>ok</status>`,
+			expectedText: `<status>ok</status>`,
+			expectedStructured: map[string]any{
+				"status": "ok",
+			},
+		},
+		{
+			name:                "response content type takes precedence over accept",
+			accept:              EncodingXML,
+			args:                `{}`,
+			responseContentType: "application/json",
+			responseBody:        `{"status": "ok"}`,
+			expectedText:        `{"status": "ok"}`,
+			expectedStructured:  map[string]any{"status": "ok"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				if tc.checkRequest != nil {
+					tc.checkRequest(t, r, body)
+				}
+
+				if tc.responseContentType != "" {
+					w.Header().Set("Content-Type", tc.responseContentType)
+				} else {
+					w.Header()["Content-Type"] = nil
+				}
+				_, _ = w.Write([]byte(tc.responseBody))
+			}))
+			defer server.Close()
+
+			invoker := testHttpInvoker(t, server.URL+"/users", nil, resolvedWithFile, nethttp.MethodPost, "")
+			invoker.ContentType = tc.contentType
+			invoker.Accept = tc.accept
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.args)},
+			})
+			require.NoError(t, err)
+			require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+			if tc.expectedStructured == nil {
+				assert.Nil(t, result.StructuredContent)
+			} else {
+				assert.Equal(t, tc.expectedStructured, result.StructuredContent)
+			}
+		})
+	}
+}
+
+// testDescriptorSet writes a descriptor set defining users.v1.CreateUserRequest and users.v1.User, and
+// returns its path.
+func testDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	file := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, prototext.Unmarshal([]byte(`
+name: "users.proto"
+package: "users.v1"
+syntax: "proto3"
+message_type {
+  name: "CreateUserRequest"
+  field { name: "name" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "name" }
+  field { name: "age" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "age" }
+}
+message_type {
+  name: "User"
+  field { name: "id" number: 1 type: TYPE_INT32 label: LABEL_OPTIONAL json_name: "id" }
+  field { name: "display_name" number: 2 type: TYPE_STRING label: LABEL_OPTIONAL json_name: "displayName" }
+}
+`), file))
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "users.pb")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	return path
+}
+
+func TestHttpInvoker_Protobuf(t *testing.T) {
+	messages, err := NewProtobufMessages(&ProtobufConfig{
+		DescriptorSet:   testDescriptorSet(t),
+		RequestMessage:  "users.v1.CreateUserRequest",
+		ResponseMessage: "users.v1.User",
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Accept"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		request := dynamicpb.NewMessage(messages.Request)
+		require.NoError(t, proto.Unmarshal(body, request))
+		name := request.Get(messages.Request.Fields().ByName("name")).String()
+		assert.Equal(t, "alice", name)
+		assert.Equal(t, int64(30), request.Get(messages.Request.Fields().ByName("age")).Int())
+
+		user := dynamicpb.NewMessage(messages.Response)
+		user.Set(messages.Response.Fields().ByName("id"), protoreflect.ValueOfInt32(1))
+		user.Set(messages.Response.Fields().ByName("display_name"), protoreflect.ValueOfString(name))
+		data, err := proto.Marshal(user)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	invoker := testHttpInvoker(t, server.URL+"/users", nil, resolvedWithFile, nethttp.MethodPost, "")
+	invoker.ContentType = EncodingProtobuf
+	invoker.Accept = EncodingProtobuf
+	invoker.Protobuf = messages
+
+	result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"name": "alice", "age": 30}`)},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+
+	assert.JSONEq(t, `{"id": 1, "displayName": "alice"}`, result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, map[string]any{"id": float64(1), "displayName": "alice"}, result.StructuredContent)
+}
+
+func TestNewProtobufMessages(t *testing.T) {
+	descriptorSet := testDescriptorSet(t)
+
+	tt := []struct {
+		name          string
+		config        *ProtobufConfig
+		expectedError string
+	}{
+		{
+			name:   "request and response messages",
+			config: &ProtobufConfig{DescriptorSet: descriptorSet, RequestMessage: "users.v1.CreateUserRequest", ResponseMessage: "users.v1.User"},
+		},
+		{
+			name:          "unknown message",
+			config:        &ProtobufConfig{DescriptorSet: descriptorSet, RequestMessage: "users.v1.DeleteUserRequest"},
+			expectedError: "failed to find message users.v1.DeleteUserRequest",
+		},
+		{
+			name:          "missing descriptor set",
+			config:        &ProtobufConfig{DescriptorSet: filepath.Join(t.TempDir(), "missing.pb")},
+			expectedError: "failed to read descriptor set",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			messages, err := NewProtobufMessages(tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.config.RequestMessage, string(messages.Request.FullName()))
+			assert.Equal(t, tc.config.ResponseMessage, string(messages.Response.FullName()))
+		})
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	tt := []struct {
+		name     string
+		document string
+		expected map[string]any
+	}{
+		{
+			name:     "text element",
+			document: `<?xml version="1.0"?><status>ok</status>`,
+			expected: map[string]any{"status": "ok"},
+		},
+		{
+			name:     "nested and repeated elements",
+			document: `<users><user><name>alice</name></user><user><name>bob</name></user></users>`,
+			expected: map[string]any{"users": map[string]any{"user": []any{
+				map[string]any{"name": "alice"},
+				map[string]any{"name": "bob"},
+			}}},
+		},
+		{
+			name:     "attributes and text",
+			document: `<price currency="EUR">12.50</price>`,
+			expected: map[string]any{"price": map[string]any{"@currency": "EUR", "#text": "12.50"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := decodeXML([]byte(tc.document))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decoded)
+		})
+	}
+}
+
+func TestHttpInvoker_BinaryResponses(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf := []byte("%PDF-1.7\n\xe2\xe3\xcf\xd3")
+
+	tt := []struct {
+		name             string
+		contentType      string
+		body             []byte
+		expectedContent  func(url string) mcp.Content
+		expectedContents *mcp.ResourceContents
+	}{
+		{
+			name:        "image",
+			contentType: "image/png",
+			body:        png,
+			expectedContent: func(string) mcp.Content {
+				return &mcp.ImageContent{Data: png, MIMEType: "image/png"}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "image/png", Blob: png},
+		},
+		{
+			name:        "audio",
+			contentType: "audio/mpeg; rate=44100",
+			body:        []byte("ID3\x04\x00"),
+			expectedContent: func(string) mcp.Content {
+				return &mcp.AudioContent{Data: []byte("ID3\x04\x00"), MIMEType: "audio/mpeg"}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "audio/mpeg; rate=44100", Blob: []byte("ID3\x04\x00")},
+		},
+		{
+			name:        "pdf",
+			contentType: "application/pdf",
+			body:        pdf,
+			expectedContent: func(url string) mcp.Content {
+				return &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: url, MIMEType: "application/pdf", Blob: pdf}}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "application/pdf", Blob: pdf},
+		},
+		{
+			name: "binary without content type",
+			body: pdf,
+			expectedContent: func(url string) mcp.Content {
+				return &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: url, MIMEType: "application/octet-stream", Blob: pdf}}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "application/octet-stream", Blob: pdf},
+		},
+		{
+			name:        "protobuf without response message",
+			contentType: "application/x-protobuf",
+			body:        []byte{0x08, 0x01},
+			expectedContent: func(url string) mcp.Content {
+				return &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: url, MIMEType: "application/x-protobuf", Blob: []byte{0x08, 0x01}}}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "application/x-protobuf", Blob: []byte{0x08, 0x01}},
+		},
+		{
+			name:        "text as octet stream",
+			contentType: "application/octet-stream",
+			body:        []byte("hello"),
+			expectedContent: func(string) mcp.Content {
+				return &mcp.TextContent{Text: "hello"}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "application/octet-stream", Text: "hello"},
+		},
+		{
+			name:        "csv is text",
+			contentType: "text/csv",
+			body:        []byte("a,b\n1,2"),
+			expectedContent: func(string) mcp.Content {
+				return &mcp.TextContent{Text: "a,b\n1,2"}
+			},
+			expectedContents: &mcp.ResourceContents{URI: "docs://report", MIMEType: "text/csv", Text: "a,b\n1,2"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				} else {
+					w.Header()["Content-Type"] = nil
+				}
+				_, _ = w.Write(tc.body)
+			}))
+			defer server.Close()
+
+			url := server.URL + "/report"
+			invoker := testHttpInvoker(t, url, nil, resolvedEmpty, nethttp.MethodGet, "")
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+			})
+			require.NoError(t, err)
+			assert.False(t, result.IsError)
+			assert.Equal(t, []mcp.Content{tc.expectedContent(url)}, result.Content)
+			assert.Nil(t, result.StructuredContent)
+
+			resource, err := invoker.InvokeResource(context.Background(), &mcp.ReadResourceRequest{
+				Params: &mcp.ReadResourceParams{URI: "docs://report"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []*mcp.ResourceContents{tc.expectedContents}, resource.Contents)
+		})
+	}
+}
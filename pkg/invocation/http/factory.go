@@ -3,6 +3,7 @@ package http
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/template"
@@ -21,14 +22,89 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		return nil, fmt.Errorf("invalid InvocationConfig type for http invoker factory")
 	}
 
+	var err error
 	hic.Method = strings.ToUpper(hic.Method)
 
+	if hic.Streaming && primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("streaming invocations are only supported for tools")
+	}
+
+	// without a framing, the framing of each response is chosen from its content type
+	messageFraming := strings.ToLower(hic.MessageFraming)
+
+	responseTransformer, err := primitive.GetResponseTransform().Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid response transform: %w", err)
+	}
+	if responseTransformer != nil && hic.Streaming {
+		return nil, fmt.Errorf("response transforms are not supported for streaming invocations")
+	}
+
+	timeout := DefaultTimeout
+	if hic.Streaming {
+		// streams may legitimately stay open for a long time
+		timeout = 0
+	}
+	if hic.Timeout != "" {
+		timeout, err = time.ParseDuration(hic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", hic.Timeout, err)
+		}
+	}
+
+	retry, err := NewRetryPolicy(hic.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry config: %w", err)
+	}
+
+	circuitBreaker, err := NewCircuitBreakerPolicy(hic.CircuitBreaker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker config: %w", err)
+	}
+
+	forwardAuth, err := NewAuthForwarder(hic.ForwardAuth, template.PrimitiveAllowlist(primitive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward auth config: %w", err)
+	}
+
+	credentials, err := NewClientCredentials(hic.ClientCredentials, template.PrimitiveAllowlist(primitive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid client credentials config: %w", err)
+	}
+
+	signer, err := NewRequestSigner(hic.Signing, template.PrimitiveAllowlist(primitive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing config: %w", err)
+	}
+
+	clientOverrides, err := NewClientOverrides(hic.Client)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client config: %w", err)
+	}
+
+	protobufMessages, err := NewProtobufMessages(hic.Protobuf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf config: %w", err)
+	}
+
+	var fileParts map[string]*FilePartConfig
+	if len(hic.FileParts) > 0 {
+		fileParts = make(map[string]*FilePartConfig, len(hic.FileParts))
+		for property, part := range hic.FileParts {
+			fileParts[property] = part.DeepCopy()
+			fileParts[property].Source = strings.ToLower(part.Source)
+		}
+	}
+
 	// Create source factories for template parsing
-	sources := template.CreateHeadersSourceFactory()
+	sources := template.CreateSourceFactories()
 
 	parsedTemplate, err := template.ParseTemplate(hic.URL, template.TemplateParserOptions{
-		InputSchema: primitive.GetInputSchema(),
-		Sources:     sources,
+		InputSchema:  primitive.GetInputSchema(),
+		Sources:      sources,
+		Tool:         invocation.ToolName(primitive),
+		Allowlist:    template.PrimitiveAllowlist(primitive),
+		QueryExplode: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL template: %w", err)
@@ -54,6 +130,8 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		pt, err := template.ParseTemplate(headerTemplate, template.TemplateParserOptions{
 			InputSchema: primitive.GetInputSchema(),
 			Sources:     sources,
+			Tool:        invocation.ToolName(primitive),
+			Allowlist:   template.PrimitiveAllowlist(primitive),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse header template for '%s': %w", headerName, err)
@@ -69,6 +147,22 @@ func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primi
 		URITemplate:     uriTemplate,
 		BodyRoot:        hic.BodyRoot,
 		BodyAsArray:     hic.BodyAsArray,
+		ContentType:     strings.ToLower(hic.ContentType),
+		Accept:          strings.ToLower(hic.Accept),
+		XMLRoot:         hic.XMLRoot,
+		Protobuf:        protobufMessages,
+		FileParts:       fileParts,
+		Pagination:      NewPaginator(hic.Pagination),
+		Streaming:       hic.Streaming,
+		MessageFraming:  messageFraming,
+		Timeout:         timeout,
+		Retry:           retry,
+		CircuitBreaker:  circuitBreaker,
+		Transformer:     responseTransformer,
+		ForwardAuth:     forwardAuth,
+		Credentials:     credentials,
+		Signer:          signer,
+		Client:          clientOverrides,
 	}
 
 	return invoker, nil
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenType        = "urn:ietf:params:oauth:token-type:access_token"
+
+	authorizationHeader = "Authorization"
+)
+
+// AuthForwarder sets the Authorization header of backend requests from the bearer token of the incoming
+// request, exchanging it first if a token exchange is configured.
+type AuthForwarder struct {
+	Exchange *TokenExchange // Exchanges the incoming token for a token of the backend, passed through if nil
+}
+
+// TokenExchange exchanges bearer tokens for tokens of a backend (RFC 8693). Exchanged tokens are cached
+// until they expire.
+type TokenExchange struct {
+	TokenURL           string
+	ClientID           string
+	ClientSecret       *template.ParsedTemplate // may reference secrets and environment variables
+	Audience           string
+	Resource           string
+	Scopes             []string
+	RequestedTokenType string
+
+	mu    sync.Mutex
+	cache map[string]exchangedToken // exchanged tokens by incoming token
+}
+
+type exchangedToken struct {
+	token  string
+	expiry time.Time
+}
+
+// NewAuthForwarder creates an AuthForwarder from a validated ForwardAuthConfig, whose client secret can only
+// reference the environment variables allowed by allowlist. It returns nil if fac is nil.
+func NewAuthForwarder(fac *ForwardAuthConfig, allowlist *template.Allowlist) (*AuthForwarder, error) {
+	if fac == nil {
+		return nil, nil
+	}
+
+	if strings.ToLower(fac.Mode) != ForwardAuthExchange {
+		return &AuthForwarder{}, nil
+	}
+
+	tec := fac.TokenExchange
+	exchange := &TokenExchange{
+		TokenURL:           tec.TokenURL,
+		ClientID:           tec.ClientID,
+		Audience:           tec.Audience,
+		Resource:           tec.Resource,
+		Scopes:             tec.Scopes,
+		RequestedTokenType: tec.RequestedTokenType,
+		cache:              make(map[string]exchangedToken),
+	}
+
+	if tec.ClientSecret != "" {
+		var err error
+		exchange.ClientSecret, err = parseClientSecret(tec.ClientSecret, allowlist)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &AuthForwarder{Exchange: exchange}, nil
+}
+
+// Apply sets the Authorization header of headers to the bearer token of incomingHeaders, or to the token
+// it is exchanged for. It fails if the bearer tokens of incoming requests are not validated by the server.
+func (af *AuthForwarder) Apply(ctx context.Context, headers, incomingHeaders nethttp.Header) error {
+	if !validatedBearerTokens(ctx) {
+		return fmt.Errorf("cannot forward the bearer token: the server does not validate bearer tokens with OAuth")
+	}
+
+	token, ok := incomingBearerToken(incomingHeaders)
+	if !ok {
+		return fmt.Errorf("cannot forward the bearer token: the request has no bearer token")
+	}
+
+	if af.Exchange != nil {
+		var err error
+		token, err = af.Exchange.Token(ctx, token)
+		if err != nil {
+			return fmt.Errorf("token exchange failed: %w", err)
+		}
+	}
+
+	headers.Set(authorizationHeader, "Bearer "+token)
+	return nil
+}
+
+// DryRun sets the Authorization header of headers to a placeholder for the token Apply would forward.
+func (af *AuthForwarder) DryRun(headers nethttp.Header) {
+	if af.Exchange != nil {
+		headers.Set(authorizationHeader, "Bearer [EXCHANGED TOKEN]")
+		return
+	}
+	headers.Set(authorizationHeader, "Bearer [INCOMING TOKEN]")
+}
+
+// incomingBearerToken returns the bearer token of the Authorization header of headers, if any.
+func incomingBearerToken(headers nethttp.Header) (string, bool) {
+	scheme, token, found := strings.Cut(headers.Get(authorizationHeader), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Token returns the token subjectToken is exchanged for, from the cache if it has not expired.
+func (te *TokenExchange) Token(ctx context.Context, subjectToken string) (string, error) {
+	te.mu.Lock()
+	cached, ok := te.cache[subjectToken]
+	te.mu.Unlock()
+	if ok && time.Now().Before(cached.expiry) {
+		return cached.token, nil
+	}
+
+	token, expiresIn, err := te.exchange(ctx, subjectToken)
+	if err != nil {
+		return "", err
+	}
+
+	if expiresIn > 0 {
+		te.mu.Lock()
+		now := time.Now()
+		for k, v := range te.cache {
+			if !now.Before(v.expiry) {
+				delete(te.cache, k)
+			}
+		}
+		te.cache[subjectToken] = exchangedToken{token: token, expiry: refreshAt(now, expiresIn)}
+		te.mu.Unlock()
+	}
+
+	return token, nil
+}
+
+func (te *TokenExchange) exchange(ctx context.Context, subjectToken string) (string, time.Duration, error) {
+	form := neturl.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {accessTokenType},
+	}
+	if te.Audience != "" {
+		form.Set("audience", te.Audience)
+	}
+	if te.Resource != "" {
+		form.Set("resource", te.Resource)
+	}
+	if len(te.Scopes) > 0 {
+		form.Set("scope", strings.Join(te.Scopes, " "))
+	}
+	if te.RequestedTokenType != "" {
+		form.Set("requested_token_type", te.RequestedTokenType)
+	}
+
+	clientSecret, err := resolveClientSecret(ctx, te.ClientSecret)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return requestToken(ctx, te.TokenURL, form, te.ClientID, clientSecret, ClientAuthBasic)
+}
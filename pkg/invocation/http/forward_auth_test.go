@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+)
+
+type mapSecrets map[string]string
+
+func (m mapSecrets) Lookup(name string) (string, bool, error) {
+	value, ok := m[name]
+	return value, ok, nil
+}
+
+func TestHttpInvocationForwardAuth(t *testing.T) {
+	var exchanges atomic.Int32
+	tokenServer := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		exchanges.Add(1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", r.PostForm.Get("subject_token_type"))
+		assert.Equal(t, "users-api", r.PostForm.Get("audience"))
+		assert.Equal(t, "users:read users:write", r.PostForm.Get("scope"))
+
+		if id, secret, ok := r.BasicAuth(); ok && (id != "genmcp" || secret != "client-s3cr3t") {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		if r.PostForm.Get("subject_token") != "user-token" {
+			w.WriteHeader(nethttp.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"subject token is invalid"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"backend-token","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":300}`))
+	}))
+	defer tokenServer.Close()
+
+	backend := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer backend.Close()
+
+	exchange := &ForwardAuthConfig{
+		Mode: ForwardAuthExchange,
+		TokenExchange: &TokenExchangeConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "genmcp",
+			ClientSecret: "{secrets.CLIENT_SECRET}",
+			Audience:     "users-api",
+			Scopes:       []string{"users:read", "users:write"},
+		},
+	}
+
+	tt := []struct {
+		name              string
+		config            *ForwardAuthConfig
+		headers           map[string]string
+		incomingAuth      string
+		notValidated      bool
+		expectedAuth      string
+		expectedExchanges int32
+		errContains       string
+	}{
+		{
+			name:         "passthrough",
+			config:       &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+			incomingAuth: "Bearer user-token",
+			expectedAuth: "Bearer user-token",
+		},
+		{
+			name:         "passthrough replaces configured header",
+			config:       &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+			headers:      map[string]string{"Authorization": "Bearer static-token"},
+			incomingAuth: "bearer user-token",
+			expectedAuth: "Bearer user-token",
+		},
+		{
+			name:              "exchange",
+			config:            exchange,
+			incomingAuth:      "Bearer user-token",
+			expectedAuth:      "Bearer backend-token",
+			expectedExchanges: 1,
+		},
+		{
+			name:              "exchange rejected",
+			config:            exchange,
+			incomingAuth:      "Bearer expired-token",
+			expectedExchanges: 1,
+			errContains:       "token exchange failed: invalid_grant: subject token is invalid (status 400)",
+		},
+		{
+			name:         "not validated",
+			config:       &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+			incomingAuth: "Bearer user-token",
+			notValidated: true,
+			errContains:  "the server does not validate bearer tokens with OAuth",
+		},
+		{
+			name:         "no bearer token",
+			config:       &ForwardAuthConfig{Mode: ForwardAuthPassthrough},
+			incomingAuth: "Basic dXNlcjpwYXNz",
+			errContains:  "the request has no bearer token",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			exchanges.Store(0)
+
+			invoker := testHttpInvoker(t, backend.URL+"/users", tc.headers, resolvedEmpty, "GET", "")
+			var err error
+			invoker.ForwardAuth, err = NewAuthForwarder(tc.config, nil)
+			require.NoError(t, err)
+
+			ctx := secrets.WithStore(context.Background(), secrets.NewStore(mapSecrets{"CLIENT_SECRET": "client-s3cr3t"}))
+			if !tc.notValidated {
+				ctx = WithValidatedBearerTokens(ctx)
+			}
+
+			// the exchanged token is cached, so the second call does not exchange it again
+			for range 2 {
+				result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+					Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+					Extra:  &mcp.RequestExtra{Header: nethttp.Header{"Authorization": {tc.incomingAuth}}},
+				})
+				if tc.errContains != "" {
+					assert.ErrorContains(t, err, tc.errContains)
+					continue
+				}
+				require.NoError(t, err)
+				assert.False(t, result.IsError)
+				assert.Equal(t, tc.expectedAuth, result.Content[0].(*mcp.TextContent).Text)
+			}
+
+			if tc.errContains == "" {
+				assert.Equal(t, tc.expectedExchanges, exchanges.Load())
+			}
+		})
+	}
+}
+
+func TestHttpInvocationForwardAuthDryRun(t *testing.T) {
+	invoker := testHttpInvoker(t, "https://api.example.com/users", nil, resolvedEmpty, "GET", "")
+	var err error
+	invoker.ForwardAuth, err = NewAuthForwarder(&ForwardAuthConfig{Mode: ForwardAuthPassthrough}, nil)
+	require.NoError(t, err)
+
+	result, err := invoker.DryRun(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer [INCOMING TOKEN]", result.Headers.Get("Authorization"))
+}
@@ -4,21 +4,28 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	nethttp "net/http"
 	neturl "net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/yosida95/uritemplate/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
 	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/spool"
 	"github.com/genmcp/gen-mcp/pkg/template"
 )
 
@@ -32,15 +39,33 @@ type HttpInvoker struct {
 	URITemplate     string                              // MCP URI template (for resource templates only)
 	BodyRoot        string                              // Dot-separated path to extract as the request body
 	BodyAsArray     bool                                // Wrap the entire body in a JSON array
+	Mapping         *MappingConfig                      // Explicit mapping of the input to the query and body, if set
+	ContentType     string                              // Encoding of the request body, JSON if empty
+	Accept          string                              // Expected encoding of the response, if any
+	XMLRoot         string                              // Name of the root element of XML request bodies
+	Protobuf        *ProtobufMessages                   // Messages of protobuf bodies, if any
+	FileParts       map[string]*FilePartConfig          // Files of multipart bodies, by property
+	Pagination      *Paginator                          // Fetches the following pages of responses, if set
+	Streaming       bool                                // Forward incremental output as progress notifications
+	MessageFraming  string                              // How messages are split out of a streamed response
+	Timeout         time.Duration                       // Timeout of a single request attempt, no timeout if zero
+	Retry           *RetryPolicy                        // Policy for retrying failed requests, no retries if nil
+	CircuitBreaker  *CircuitBreakerPolicy               // Settings of the per host circuit breakers, disabled if nil
+	Transformer     *invocation.ResponseTransformer     // Transform applied to successful JSON responses, if any
+	ForwardAuth     *AuthForwarder                      // Forwards the bearer token of the incoming request, if set
+	Credentials     *ClientCredentials                  // Obtains the access token sent to the backend, if set
+	Signer          *RequestSigner                      // Signs the requests sent to the backend, if set
+	Client          *ClientOverrides                    // Settings overriding those of the client of the server, if any
 }
 
 var _ invocation.Invoker = &HttpInvoker{}
+var _ invocation.DryRunner = &HttpInvoker{}
 
 func (hi *HttpInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Starting HTTP tool invocation")
 
-	hasBody := hi.Method != nethttp.MethodGet && hi.Method != nethttp.MethodDelete && hi.Method != nethttp.MethodHead
+	hasBody := hi.hasBody()
 
 	// Extract incoming headers from request
 	var incomingHeaders nethttp.Header
@@ -48,24 +73,98 @@ func (hi *HttpInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*m
 		incomingHeaders = req.Extra.Header
 	}
 
-	url, headers, parsed, err := hi.buildRequestComponents(ctx, req.Params.Arguments, !hasBody, incomingHeaders)
+	buildCtx, buildSpan := tracing.Start(ctx, "build http request")
+	url, headers, parsed, err := hi.buildRequestComponents(buildCtx, req.Params.Arguments, !hasBody, incomingHeaders)
+	if err == nil {
+		err = hi.authorize(buildCtx, headers, incomingHeaders)
+	}
 	if err != nil {
+		tracing.End(buildSpan, err)
 		return nil, err
 	}
 
 	var reqBody io.Reader
+	var encodedBody []byte
 	if hasBody {
-		bodyJson, err := hi.prepareRequestBody(parsed)
+		var contentType string
+		encodedBody, contentType, err = hi.prepareRequestBody(buildCtx, parsed, false)
 		if err != nil {
 			logger.Error("Failed to marshal HTTP request body", zap.Error(err))
-			return nil, fmt.Errorf("failed to prepare request body: %w", err)
+			err = fmt.Errorf("failed to prepare request body: %w", err)
+			tracing.End(buildSpan, err)
+			return nil, err
 		}
-		reqBody = bytes.NewBuffer(bodyJson)
+		reqBody = bytes.NewBuffer(encodedBody)
+		headers.Set(contentTypeHeader, contentType)
+	}
+	buildSpan.End()
+
+	if hi.Streaming {
+		return hi.invokeStreaming(ctx, req, url, reqBody, hasBody, headers)
+	}
+
+	if hi.Pagination != nil {
+		return hi.invokePaginated(ctx, url, encodedBody, hasBody, headers), nil
 	}
 
-	response, body, err := hi.executeHTTPRequest(ctx, hi.Method, url, reqBody, hasBody, headers, nil)
+	response, body, spooled, err := hi.executeHTTPRequest(ctx, hi.Method, url, reqBody, hasBody, headers, spool.FromContext(ctx), nil)
 	if err != nil {
-		return utils.McpTextError("HTTP request failed: %v", err), nil
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "HTTP request failed: %v", err), nil
+	}
+
+	isError := response.StatusCode < 200 || response.StatusCode >= 300
+
+	if spooled != nil {
+		result := spooledResult(ctx, spooled, isError)
+		if isError {
+			invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, response.StatusCode))
+		}
+		return result, nil
+	}
+
+	decoded, err := hi.decodeResponse(response.Header.Get(contentTypeHeader), body)
+	if err != nil {
+		logger.Error("Failed to decode HTTP response", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to decode response: %v", err), nil
+	}
+
+	var result *mcp.CallToolResult
+	if decoded.binary {
+		logger.Info("HTTP tool invocation completed successfully")
+		result = &mcp.CallToolResult{
+			Content: []mcp.Content{decoded.content(url)},
+			IsError: isError,
+		}
+	} else {
+		result = hi.toolResult(ctx, decoded.text, decoded.json, isError)
+	}
+	if isError {
+		invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, response.StatusCode))
+	}
+	return result, nil
+}
+
+// toolResult returns the result of a tool call from a text response body and its JSON decoding, if any. The
+// response transform is applied to successful responses.
+func (hi *HttpInvoker) toolResult(ctx context.Context, body, structuredBody []byte, isError bool) *mcp.CallToolResult {
+	logger := logging.FromContext(ctx)
+
+	// error responses are returned as is, so that the model can see what went wrong
+	if hi.Transformer != nil && !isError {
+		transformInput := structuredBody
+		if transformInput == nil {
+			transformInput = body
+		}
+
+		transformCtx, transformSpan := tracing.Start(ctx, "transform response")
+		var err error
+		body, err = hi.Transformer.Apply(transformCtx, transformInput)
+		tracing.End(transformSpan, err)
+		if err != nil {
+			logger.Error("Failed to transform HTTP response", zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to transform response: %v", err)
+		}
+		structuredBody = body
 	}
 
 	logger.Info("HTTP tool invocation completed successfully")
@@ -76,26 +175,68 @@ func (hi *HttpInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*m
 				Text: string(body),
 			},
 		},
-		IsError: response.StatusCode < 200 || response.StatusCode >= 300,
+		IsError: isError,
 	}
 
-	contentType := response.Header.Get(contentTypeHeader)
-	if strings.Contains(contentType, "application/json") {
+	if structuredBody != nil {
 		var data map[string]any
-		err := json.Unmarshal(body, &data)
+		err := json.Unmarshal(structuredBody, &data)
 		if err == nil {
 			res.StructuredContent = data
 		}
 	}
 
-	return res, nil
+	return res
+}
+
+// DryRun returns the HTTP request Invoke would send for req, without sending it.
+func (hi *HttpInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	hasBody := hi.hasBody()
+
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	url, headers, parsed, err := hi.buildRequestComponents(ctx, req.Params.Arguments, !hasBody, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case hi.ForwardAuth != nil:
+		hi.ForwardAuth.DryRun(headers)
+	case hi.Credentials != nil:
+		hi.Credentials.DryRun(headers)
+	}
+	if hi.Signer != nil {
+		hi.Signer.DryRun(headers)
+	}
+
+	result := &invocation.DryRunResult{
+		Type:    InvocationType,
+		Method:  hi.Method,
+		URL:     url,
+		Headers: headers,
+	}
+
+	if hasBody {
+		var contentType string
+		result.Body, contentType, err = hi.prepareRequestBody(ctx, parsed, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare request body: %w", err)
+		}
+		headers.Set(contentTypeHeader, contentType)
+	}
+
+	return result, nil
 }
 
 func (hi *HttpInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 	logger := logging.FromContext(ctx)
 	logger.Debug("Starting HTTP prompt invocation")
 
-	hasBody := hi.Method != nethttp.MethodGet && hi.Method != nethttp.MethodDelete && hi.Method != nethttp.MethodHead
+	hasBody := hi.hasBody()
 
 	args := req.Params.Arguments
 	if args == nil {
@@ -118,18 +259,22 @@ func (hi *HttpInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptReque
 	if err != nil {
 		return nil, err
 	}
+	if err := hi.authorize(ctx, headers, incomingHeaders); err != nil {
+		return nil, err
+	}
 
 	var reqBody io.Reader
 	if hasBody {
-		bodyJson, err := hi.prepareRequestBody(parsed)
+		encodedBody, contentType, err := hi.prepareRequestBody(ctx, parsed, false)
 		if err != nil {
 			logger.Error("Failed to marshal HTTP prompt request body", zap.Error(err))
 			return nil, fmt.Errorf("failed to prepare request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(bodyJson)
+		reqBody = bytes.NewBuffer(encodedBody)
+		headers.Set(contentTypeHeader, contentType)
 	}
 
-	response, body, err := hi.executeHTTPRequest(ctx, hi.Method, url, reqBody, hasBody, headers, nil)
+	response, body, _, err := hi.executeHTTPRequest(ctx, hi.Method, url, reqBody, hasBody, headers, nil, nil)
 	if err != nil {
 		return utils.McpPromptTextError("HTTP request failed: %v", err), nil
 	}
@@ -139,13 +284,19 @@ func (hi *HttpInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptReque
 		return utils.McpPromptTextError("http request failed"), nil
 	}
 
+	decoded, err := hi.decodeResponse(response.Header.Get(contentTypeHeader), body)
+	if err != nil {
+		logger.Error("Failed to decode HTTP prompt response", zap.Error(err))
+		return utils.McpPromptTextError("failed to decode response: %v", err), nil
+	}
+
 	logger.Info("HTTP prompt invocation completed successfully")
 
 	result := &mcp.GetPromptResult{
 		Messages: []*mcp.PromptMessage{
 			{
 				Role:    "assistant",
-				Content: &mcp.TextContent{Text: string(body)},
+				Content: decoded.content(url),
 			},
 		},
 	}
@@ -161,21 +312,24 @@ func (hi *HttpInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResource
 	// We can use the template directly as the URL
 	url := hi.ParsedTemplate.Template
 
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
 	// Build headers if any are configured
 	var headers nethttp.Header
 	if len(hi.HeaderTemplates) > 0 {
-		// Extract incoming headers from request
-		var incomingHeaders nethttp.Header
-		if req.Extra != nil {
-			incomingHeaders = req.Extra.Header
-		}
-
 		hb, err := newHeaderBuilder(hi.HeaderTemplates)
 		if err != nil {
 			logger.Error("Failed to create header builder", zap.String("uri", req.Params.URI), zap.Error(err))
 			return nil, fmt.Errorf("failed to create header builder: %w", err)
 		}
 
+		hb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+		hb.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+		hb.SetSourceResolver("tenant", template.TenantFromContext(ctx))
 		if incomingHeaders != nil {
 			headerResolver := template.NewHttpHeaderResolver(incomingHeaders)
 			hb.SetSourceResolver("headers", headerResolver)
@@ -190,8 +344,13 @@ func (hi *HttpInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResource
 	} else {
 		headers = make(nethttp.Header)
 	}
+	hi.setAccept(headers)
 
-	response, body, err := hi.executeHTTPRequest(ctx, hi.Method, url, nil, false, headers, map[string]string{"uri": req.Params.URI})
+	if err := hi.authorize(ctx, headers, incomingHeaders); err != nil {
+		return nil, err
+	}
+
+	response, body, _, err := hi.executeHTTPRequest(ctx, hi.Method, url, nil, false, headers, nil, map[string]string{"uri": req.Params.URI})
 	if err != nil {
 		logger.Error("HTTP resource request execution failed", zap.String("uri", req.Params.URI))
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
@@ -204,21 +363,21 @@ func (hi *HttpInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResource
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
 
+	decoded, err := hi.decodeResponse(response.Header.Get(contentTypeHeader), body)
+	if err != nil {
+		logger.Error("Failed to decode HTTP resource response", zap.String("uri", req.Params.URI), zap.Error(err))
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	logger.Info("HTTP resource invocation completed successfully", zap.String("uri", req.Params.URI))
 
-	mimeType := response.Header.Get(contentTypeHeader)
-	if mimeType == "" {
-		mimeType = "text/plain"
+	contents := decoded.resourceContents(req.Params.URI)
+	if contents.MIMEType == "" {
+		contents.MIMEType = "text/plain"
 	}
 
 	result := &mcp.ReadResourceResult{
-		Contents: []*mcp.ResourceContents{
-			{
-				URI:      req.Params.URI,
-				MIMEType: mimeType,
-				Text:     string(body),
-			},
-		},
+		Contents: []*mcp.ResourceContents{contents},
 	}
 
 	return result, nil
@@ -272,8 +431,11 @@ func (hi *HttpInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.Read
 	if err != nil {
 		return nil, err
 	}
+	if err := hi.authorize(ctx, headers, incomingHeaders); err != nil {
+		return nil, err
+	}
 
-	response, body, err := hi.executeHTTPRequest(ctx, hi.Method, url, nil, false, headers, map[string]string{
+	response, body, _, err := hi.executeHTTPRequest(ctx, hi.Method, url, nil, false, headers, nil, map[string]string{
 		"uri":      req.Params.URI,
 		"template": hi.URITemplate,
 	})
@@ -289,23 +451,25 @@ func (hi *HttpInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.Read
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
 
+	decoded, err := hi.decodeResponse(response.Header.Get(contentTypeHeader), body)
+	if err != nil {
+		logger.Error("Failed to decode HTTP resource template response", zap.String("uri", req.Params.URI), zap.Error(err))
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	logger.Info("HTTP resource template invocation completed successfully", zap.String("uri", req.Params.URI))
 
 	return &mcp.ReadResourceResult{
-		Contents: []*mcp.ResourceContents{
-			{
-				URI:      req.Params.URI,
-				MIMEType: response.Header.Get(contentTypeHeader),
-				Text:     string(body),
-			},
-		},
+		Contents: []*mcp.ResourceContents{decoded.resourceContents(req.Params.URI)},
 	}, nil
 }
 
 // executeHTTPRequest handles the common HTTP request/response cycle.
-// It centralizes request creation, execution, response reading, and logging.
+// It centralizes request creation, execution, response reading, retries, and logging.
 // Returns the response and body bytes. The response body has already been read and closed,
 // so callers should use the returned []byte instead of accessing response.Body.
+// If sp is set, bodies larger than its threshold are spooled to it instead, and the spooled file is
+// returned in place of the body bytes.
 func (hi *HttpInvoker) executeHTTPRequest(
 	ctx context.Context,
 	method string,
@@ -313,8 +477,9 @@ func (hi *HttpInvoker) executeHTTPRequest(
 	body io.Reader,
 	hasBody bool,
 	headers nethttp.Header,
+	sp *spool.Spool,
 	contextInfo map[string]string, // additional context for logging (e.g., "uri", "template")
-) (*nethttp.Response, []byte, error) {
+) (*nethttp.Response, []byte, *spool.File, error) {
 	logger := logging.FromContext(ctx)
 	baseLogger := logging.BaseFromContext(ctx)
 
@@ -330,28 +495,137 @@ func (hi *HttpInvoker) executeHTTPRequest(
 		logFields = append(logFields, zap.String(k, v))
 	}
 
+	// Buffer the body so that it can be sent again on retries
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	var breaker *CircuitBreaker
+	if hi.CircuitBreaker != nil {
+		breaker = hi.CircuitBreaker.ForHost(requestHost(url))
+	}
+
+	reauthorized := false
+
+	for attempt := 0; ; attempt++ {
+		if breaker != nil {
+			if err := breaker.Allow(); err != nil {
+				baseLogger.Warn("HTTP request rejected by circuit breaker", append(logFields, zap.Error(err))...)
+				logger.Warn("HTTP request rejected by circuit breaker")
+				return nil, nil, nil, err
+			}
+		}
+
+		var attemptBody io.Reader
+		if body != nil {
+			attemptBody = bytes.NewReader(bodyBytes)
+		}
+		if hi.Signer != nil {
+			if err := hi.Signer.Sign(ctx, headers, method, url, bodyBytes); err != nil {
+				baseLogger.Error("Failed to sign HTTP request", append(logFields, zap.Error(err))...)
+				logger.Error("Failed to sign HTTP request")
+				return nil, nil, nil, err
+			}
+		}
+
+		attemptCtx, span := startRequestSpan(ctx, method, url, attempt)
+		response, responseBody, spooled, err := hi.doHTTPRequest(attemptCtx, method, url, attemptBody, hasBody, headers, sp, logFields)
+		endRequestSpan(span, response, err)
+		if breaker != nil {
+			recordCircuitBreakerOutcome(ctx, breaker, response, err, logFields)
+		}
+
+		// the access token may have been revoked before it expired, send the request once more with a new one
+		if hi.Credentials != nil && !reauthorized && err == nil && response.StatusCode == nethttp.StatusUnauthorized {
+			reauthorized = true
+			hi.Credentials.Invalidate(strings.TrimPrefix(headers.Get(authorizationHeader), "Bearer "))
+			authErr := hi.Credentials.Apply(ctx, headers)
+			if authErr == nil {
+				baseLogger.Info("Retrying HTTP request with a new access token", logFields...)
+				discardSpooled(sp, spooled)
+				continue
+			}
+			baseLogger.Warn("Failed to obtain a new access token", append(logFields, zap.Error(authErr))...)
+		}
+		if !hi.Retry.ShouldRetry(ctx, attempt, response, err) {
+			return response, responseBody, spooled, err
+		}
+		discardSpooled(sp, spooled)
+
+		delay := hi.Retry.Delay(attempt, response)
+		retryFields := append(logFields, zap.Int("attempt", attempt+1), zap.Duration("delay", delay))
+		if err != nil {
+			retryFields = append(retryFields, zap.Error(err))
+		} else {
+			retryFields = append(retryFields, zap.Int("status_code", response.StatusCode))
+		}
+		baseLogger.Warn("Retrying HTTP request", retryFields...)
+		logger.Info("Retrying HTTP request", zap.Int("attempt", attempt+1))
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// doHTTPRequest executes a single HTTP request attempt, bounded by the configured timeout. The body of the
+// response is spooled to sp if it is set and the body is larger than its threshold.
+func (hi *HttpInvoker) doHTTPRequest(
+	ctx context.Context,
+	method string,
+	url string,
+	body io.Reader,
+	hasBody bool,
+	headers nethttp.Header,
+	sp *spool.Spool,
+	logFields []zap.Field,
+) (*nethttp.Response, []byte, *spool.File, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
 	baseLogger.Debug("Executing HTTP request", logFields...)
 
-	httpReq, err := nethttp.NewRequestWithContext(ctx, method, url, body)
+	reqCtx := ctx
+	if hi.Timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, hi.Timeout)
+		defer cancel()
+	}
+
+	httpReq, err := nethttp.NewRequestWithContext(reqCtx, method, url, body)
 	if err != nil {
 		baseLogger.Error("Failed to create HTTP request", append(logFields, zap.Error(err))...)
 		logger.Error("Failed to create HTTP request", zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to create http request: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create http request: %w", err)
 	}
 
 	httpReq.Header = headers
 
-	if hasBody {
+	if hasBody && httpReq.Header.Get(contentTypeHeader) == "" {
 		httpReq.Header.Set(contentTypeHeader, "application/json; charset=UTF-8")
 	}
+	tracing.Inject(reqCtx, httpReq.Header)
 
-	// Use HTTP client from context (configured with custom CA certs if provided)
-	client := HTTPClientFromContext(ctx)
+	// Use HTTP client from context (configured with custom CA certs if provided), with the settings of the invoker
+	client, err := hi.httpClient(ctx)
+	if err != nil {
+		baseLogger.Error("Failed to create HTTP client", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to create HTTP client", zap.Error(err))
+		return nil, nil, nil, fmt.Errorf("failed to create http client: %w", err)
+	}
 	response, err := client.Do(httpReq)
 	if err != nil {
+		err = hi.timeoutError(ctx, err)
 		baseLogger.Error("HTTP request execution failed", append(logFields, zap.Error(err))...)
 		logger.Error("HTTP request execution failed")
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	defer func() {
 		if cerr := response.Body.Close(); cerr != nil {
@@ -359,43 +633,120 @@ func (hi *HttpInvoker) executeHTTPRequest(
 		}
 	}()
 
-	responseBody, readErr := io.ReadAll(response.Body)
+	responseBody, spooled, readErr := readResponseBody(response, sp)
 	if readErr != nil {
+		readErr = hi.timeoutError(ctx, readErr)
 		baseLogger.Error("Failed to read HTTP response body", append(logFields, zap.Error(readErr))...)
 		logger.Error("Failed to read HTTP response body")
-		return nil, nil, readErr
+		return nil, nil, nil, readErr
 	}
 
 	// Server-side only logging with sensitive HTTP details
+	responseLength := int64(len(responseBody))
+	if spooled != nil {
+		responseLength = spooled.Size
+		logFields = append(logFields, zap.String("spooled_id", spooled.ID))
+	}
 	baseLogger.Info("HTTP request completed", append(logFields,
 		zap.Int("status_code", response.StatusCode),
-		zap.Int("response_length", len(responseBody)))...)
+		zap.Int64("response_length", responseLength))...)
 
-	return response, responseBody, nil
+	return response, responseBody, spooled, nil
 }
 
-// prepareRequestBody creates a JSON body from the parsed arguments,
-// excluding any variables that are used in the URL template or header templates
-// if BodyRoot is set, it extracts that property's value as the body
-// if BodyAsArray is set, it wraps the entire body in a JSON array
-func (hi *HttpInvoker) prepareRequestBody(parsed map[string]any) ([]byte, error) {
-	varNames := make([]string, 0, len(hi.ParsedTemplate.Variables))
-	for _, v := range hi.ParsedTemplate.Variables {
-		varNames = append(varNames, v.Name)
+// recordCircuitBreakerOutcome records the outcome of a request attempt in breaker. Network errors,
+// timeouts and 5xx responses are failures, a cancelled tool call says nothing about the backend.
+func recordCircuitBreakerOutcome(ctx context.Context, breaker *CircuitBreaker, response *nethttp.Response, err error, logFields []zap.Field) {
+	if ctx.Err() != nil {
+		breaker.Abandon()
+		return
 	}
 
-	for _, headerTemplate := range hi.HeaderTemplates {
-		for _, v := range headerTemplate.Variables {
-			varNames = append(varNames, v.Name)
+	failed := err != nil || response.StatusCode >= 500
+	if breaker.Record(failed) {
+		logging.BaseFromContext(ctx).Warn("Circuit breaker opened",
+			append(logFields, zap.Duration("cool_down", breaker.policy.CoolDown))...)
+	}
+}
+
+// requestHost returns the host (and port) of url, or url itself if it cannot be parsed.
+func requestHost(url string) string {
+	u, err := neturl.Parse(url)
+	if err != nil || u.Host == "" {
+		return url
+	}
+	return u.Host
+}
+
+// startRequestSpan starts the client span of a single HTTP request attempt. The URL is not
+// recorded in full as it may contain sensitive query parameters.
+func startRequestSpan(ctx context.Context, method, url string, attempt int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", method),
+	}
+	if u, err := neturl.Parse(url); err == nil {
+		attrs = append(attrs, attribute.String("server.address", u.Hostname()))
+	}
+	if attempt > 0 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", attempt))
+	}
+
+	return tracing.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+func endRequestSpan(span trace.Span, response *nethttp.Response, err error) {
+	if response != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+		if response.StatusCode >= 400 {
+			tracing.SetError(span, response.Status)
 		}
 	}
+	tracing.End(span, err)
+}
 
-	var body any = deletePathsFromMap(parsed, varNames)
+// timeoutError replaces err with a clearer error if the request timeout (rather than ctx) expired.
+func (hi *HttpInvoker) timeoutError(ctx context.Context, err error) error {
+	if hi.Timeout > 0 && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return invocation.Errorf(invocation.ErrorCodeTimeout, "request timed out after %s", hi.Timeout)
+	}
+	return err
+}
+
+// prepareRequestBody creates a body from the parsed arguments, encoded with the content type of the invocation,
+// and returns it with the value of its Content-Type header.
+// Any variables that are used in the URL template or header templates are excluded
+// if Mapping is set, the body only holds the mapped properties instead
+// if BodyRoot is set, it extracts that property's value as the body
+// if BodyAsArray is set, it wraps the entire body in a JSON array
+// if dryRun is set, the files of multipart bodies are not downloaded from their URLs
+func (hi *HttpInvoker) prepareRequestBody(ctx context.Context, parsed map[string]any, dryRun bool) ([]byte, string, error) {
+	var body any
+	if hi.Mapping != nil {
+		var err error
+		if body, err = hi.Mapping.body(parsed); err != nil {
+			return nil, "", err
+		}
+	} else {
+		varNames := make([]string, 0, len(hi.ParsedTemplate.Variables))
+		for _, v := range hi.ParsedTemplate.Variables {
+			varNames = append(varNames, v.VariableNames()...)
+		}
+
+		for _, headerTemplate := range hi.HeaderTemplates {
+			for _, v := range headerTemplate.Variables {
+				varNames = append(varNames, v.VariableNames()...)
+			}
+		}
+
+		body = deletePathsFromMap(parsed, varNames)
+	}
 
 	if hi.BodyRoot != "" {
 		val, ok := getValueByPath(parsed, hi.BodyRoot)
 		if !ok {
-			return nil, fmt.Errorf("bodyRoot property %q not found in arguments", hi.BodyRoot)
+			return nil, "", fmt.Errorf("bodyRoot property %q not found in arguments", hi.BodyRoot)
 		}
 		body = val
 	} else if hi.BodyAsArray {
@@ -403,7 +754,7 @@ func (hi *HttpInvoker) prepareRequestBody(parsed map[string]any) ([]byte, error)
 		body = []any{body}
 	}
 
-	return json.Marshal(body)
+	return hi.encodeBody(ctx, body, dryRun)
 }
 
 // buildRequestComponents builds the URL and headers from request arguments and incoming headers.
@@ -416,8 +767,8 @@ func (hi *HttpInvoker) buildRequestComponents(
 ) (string, nethttp.Header, map[string]any, error) {
 	logger := logging.FromContext(ctx)
 
-	// Create URL builder
-	ub, err := hi.newUrlBuilder(buildQuery)
+	// Create URL builder, the query is built from the mapping instead if there is one
+	ub, err := hi.newUrlBuilder(buildQuery && hi.Mapping == nil)
 	if err != nil {
 		logger.Error("Failed to create URL builder", zap.Error(err))
 		return "", nil, nil, fmt.Errorf("failed to create URL builder: %w", err)
@@ -433,6 +784,18 @@ func (hi *HttpInvoker) buildRequestComponents(
 		}
 	}
 
+	secretResolver := secrets.FromContext(ctx)
+	claimsResolver := template.ClaimsFromContext(ctx)
+	tenantResolver := template.TenantFromContext(ctx)
+	ub.SetSourceResolver("secrets", secretResolver)
+	ub.SetSourceResolver("claims", claimsResolver)
+	ub.SetSourceResolver("tenant", tenantResolver)
+	if hb != nil {
+		hb.SetSourceResolver("secrets", secretResolver)
+		hb.SetSourceResolver("claims", claimsResolver)
+		hb.SetSourceResolver("tenant", tenantResolver)
+	}
+
 	// Set up source resolver for incoming headers if provided
 	if incomingHeaders != nil {
 		headerResolver := template.NewHttpHeaderResolver(incomingHeaders)
@@ -455,16 +818,23 @@ func (hi *HttpInvoker) buildRequestComponents(
 	parsed, err := dj.ParseJson(argsBytes, hi.InputSchema.Schema())
 	if err != nil {
 		logger.Error("Failed to parse request arguments", zap.Error(err))
-		return "", nil, nil, fmt.Errorf("failed to parse request: %w", err)
+		return "", nil, nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
 	}
 
 	if err := hi.InputSchema.Validate(parsed); err != nil {
 		logger.Error("Failed to validate request arguments", zap.Error(err))
-		return "", nil, nil, fmt.Errorf("failed to validate request: %w", err)
+		return "", nil, nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
 	}
 
 	// Get results
 	url, _ := ub.GetResult()
+	if hi.Mapping != nil {
+		url, err = hi.Mapping.appendQuery(url.(string), parsed)
+		if err != nil {
+			logger.Error("Failed to build query", zap.Error(err))
+			return "", nil, nil, fmt.Errorf("failed to build query: %w", err)
+		}
+	}
 
 	var headers nethttp.Header
 	if hb != nil {
@@ -477,10 +847,30 @@ func (hi *HttpInvoker) buildRequestComponents(
 	} else {
 		headers = make(nethttp.Header)
 	}
+	hi.setAccept(headers)
 
 	return url.(string), headers, parsed, nil
 }
 
+// authorize sets the Authorization header of headers from the bearer token of incomingHeaders if forwarding
+// is enabled, or to the access token of the client credentials if they are configured.
+func (hi *HttpInvoker) authorize(ctx context.Context, headers, incomingHeaders nethttp.Header) error {
+	switch {
+	case hi.ForwardAuth != nil:
+		if err := hi.ForwardAuth.Apply(ctx, headers, incomingHeaders); err != nil {
+			logging.FromContext(ctx).Error("Failed to forward bearer token", zap.Error(err))
+			return &invocation.Error{Code: invocation.ErrorCodeAuth, Err: err}
+		}
+	case hi.Credentials != nil:
+		if err := hi.Credentials.Apply(ctx, headers); err != nil {
+			logging.FromContext(ctx).Error("Failed to obtain access token", zap.Error(err))
+			return &invocation.Error{Code: invocation.ErrorCodeAuth, Err: err}
+		}
+	}
+
+	return nil
+}
+
 // newUrlBuilder creates a new urlBuilder from the parsed template.
 // A new builder is created for each invocation to avoid sharing state.
 func (hi *HttpInvoker) newUrlBuilder(buildQuery bool) (*urlBuilder, error) {
@@ -500,7 +890,9 @@ func (hi *HttpInvoker) newUrlBuilder(buildQuery bool) (*urlBuilder, error) {
 	headerVarNames := make(map[string]bool)
 	for _, headerTemplate := range hi.HeaderTemplates {
 		for _, v := range headerTemplate.Variables {
-			headerVarNames[v.Name] = true
+			for _, varName := range v.VariableNames() {
+				headerVarNames[varName] = true
+			}
 		}
 	}
 
@@ -529,14 +921,15 @@ type urlBuilder struct {
 var _ invocation.Builder = &urlBuilder{}
 
 func (ub *urlBuilder) SetField(path string, value any) {
-	// If this is a variable that the template cares about, propagate to the template
-	if ub.templateVarNames[path] {
+	// If this is a variable that the template cares about, or an element of one, propagate to the template
+	array, isElement := template.ArrayPath(path)
+	if ub.templateVarNames[path] || ub.templateVarNames[array] {
 		ub.templateBuilder.SetField(path, value)
 		return
 	}
 
 	// Skip fields that are used in headers - they should not appear in query params
-	if ub.headerVarNames[path] {
+	if ub.headerVarNames[path] || ub.headerVarNames[array] {
 		return
 	}
 
@@ -545,10 +938,18 @@ func (ub *urlBuilder) SetField(path string, value any) {
 		return
 	}
 
-	if s, ok := value.(string); ok {
-		ub.queryParams.Add(path, s)
-	} else {
-		ub.queryParams.Add(path, fmt.Sprintf("%v", value))
+	// elements of arrays are sent as repeated parameters
+	if isElement {
+		path = array
+	}
+
+	// arrays are sent as repeated parameters
+	values, err := formFieldValues(value)
+	if err != nil {
+		values = []string{fmt.Sprintf("%v", value)}
+	}
+	for _, v := range values {
+		ub.queryParams.Add(path, v)
 	}
 }
 
@@ -566,6 +967,9 @@ func (ub *urlBuilder) GetResult() (any, error) {
 		if q == "" {
 			return base, nil
 		}
+		if strings.Contains(base, "?") {
+			return base + "&" + q, nil
+		}
 		return base + "?" + q, nil
 	}
 
@@ -612,6 +1016,12 @@ var _ invocation.Builder = &headerBuilder{}
 
 func (hb *headerBuilder) SetField(path string, value any) {
 	headerNames, ok := hb.headerVarIndices[path]
+	if !ok {
+		// elements of arrays are set on the headers using the array
+		if array, isElement := template.ArrayPath(path); isElement {
+			headerNames, ok = hb.headerVarIndices[array]
+		}
+	}
 	if !ok {
 		return
 	}
@@ -18,8 +18,9 @@ import (
 )
 
 type testHttpInvokerOptions struct {
-	BodyRoot    string
-	BodyAsArray bool
+	BodyRoot          string
+	BodyAsArray       bool
+	ResponseTransform *invocation.ResponseTransform
 }
 
 // testHttpInvoker creates an HttpInvoker for testing from a URL template
@@ -46,11 +47,12 @@ func testHttpInvokerWithOptions(
 	opts testHttpInvokerOptions,
 ) HttpInvoker {
 
-	sources := template.CreateHeadersSourceFactory()
+	sources := template.CreateSourceFactories()
 
 	parsedTemplate, err := template.ParseTemplate(urlTemplate, template.TemplateParserOptions{
-		InputSchema: schema.Schema(),
-		Sources:     sources,
+		InputSchema:  schema.Schema(),
+		Sources:      sources,
+		QueryExplode: true,
 	})
 	require.NoError(t, err, "failed to parse URL template")
 
@@ -64,6 +66,9 @@ func testHttpInvokerWithOptions(
 		parsedHeaders[headerName] = pt
 	}
 
+	transformer, err := opts.ResponseTransform.Compile()
+	require.NoError(t, err, "failed to compile response transform")
+
 	return HttpInvoker{
 		ParsedTemplate:  parsedTemplate,
 		HeaderTemplates: parsedHeaders,
@@ -72,6 +77,7 @@ func testHttpInvokerWithOptions(
 		URITemplate:     uriTemplate,
 		BodyRoot:        opts.BodyRoot,
 		BodyAsArray:     opts.BodyAsArray,
+		Transformer:     transformer,
 	}
 }
 
@@ -126,6 +132,9 @@ func TestHttpInvocation(t *testing.T) {
 		schema            *jsonschema.Resolved
 		method            string
 		request           *mcp.CallToolRequest
+		claims            map[string]any
+		tenant            string
+		tenantVariables   map[string]string
 		opts              testHttpInvokerOptions
 		expectedResult    *mcp.CallToolResult
 		expectedReqMethod string
@@ -211,6 +220,64 @@ func TestHttpInvocation(t *testing.T) {
 			},
 			expectedPath: "/hello/1/world",
 		},
+		{
+			name:         "GET request with template functions in the path and headers",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte("hello, world!") },
+			urlTemplate:  "/hello/{path.part2|upper}/{path.part1|default:0}",
+			headerTemplates: map[string]string{
+				"X-Greeting": "{path.part2|default:nobody} {search|urlencode}",
+			},
+			schema: resolvedWithPath,
+			method: "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{\"path\": {\"part2\": \"world\"}, \"search\": \"a&b\"}"),
+				},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "hello, world!",
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/hello/WORLD/0",
+			expectedHeaders: nethttp.Header{
+				"X-Greeting": []string{"world a%26b"},
+			},
+		},
+		{
+			name:         "GET request with conditional blocks in the url and headers",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte("hello, world!") },
+			urlTemplate:  "/hello{?search}/search?q={search|urlencode}{/search}",
+			headerTemplates: map[string]string{
+				"X-Mode": "{?path.part1}partial{/path.part1}{^path.part1}full{/path.part1}",
+			},
+			schema: resolvedWithPath,
+			method: "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{\"search\": \"a&b\"}"),
+				},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "hello, world!",
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     neturl.Values{"q": []string{"a&b"}},
+			expectedPath:      "/hello/search",
+			expectedHeaders: nethttp.Header{
+				"X-Mode": []string{"full"},
+			},
+		},
 		{
 			name:         "GET request with static header",
 			responseCode: 200,
@@ -373,6 +440,64 @@ func TestHttpInvocation(t *testing.T) {
 			expectedQuery:     make(neturl.Values),
 			expectedPath:      "/users/alice",
 		},
+		{
+			name:            "GET request with claims in URL and header templates",
+			responseCode:    200,
+			responseBody:    func() []byte { return []byte("tenant users") },
+			urlTemplate:     "/tenants/{claims.tenant_id}/users",
+			headerTemplates: map[string]string{"X-User": "{claims.sub} <{claims.email}>", "X-Org": "{claims.org.id}"},
+			schema:          resolvedEmpty,
+			method:          "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{}"),
+				},
+			},
+			claims: map[string]any{"sub": "alice", "email": "alice@example.com", "tenant_id": float64(4021), "org": map[string]any{"id": "acme"}},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "tenant users",
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/tenants/4021/users",
+			expectedHeaders: nethttp.Header{
+				"X-User": []string{"alice <alice@example.com>"},
+				"X-Org":  []string{"acme"},
+			},
+		},
+		{
+			name:            "GET request with tenant variables in URL and header templates",
+			responseCode:    200,
+			responseBody:    func() []byte { return []byte("tenant users") },
+			urlTemplate:     "/tenants/{tenant.id}/{tenant.apiVersion}/users",
+			headerTemplates: map[string]string{"X-Region": "{tenant.region}"},
+			schema:          resolvedEmpty,
+			method:          "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{}"),
+				},
+			},
+			tenant:          "acme",
+			tenantVariables: map[string]string{"apiVersion": "v2", "region": "eu"},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "tenant users",
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/tenants/acme/v2/users",
+			expectedHeaders: nethttp.Header{
+				"X-Region": []string{"eu"},
+			},
+		},
 		{
 			name:         "GET request with query params and no template variables",
 			responseCode: 200,
@@ -457,6 +582,58 @@ func TestHttpInvocation(t *testing.T) {
 				"tags": []any{"alpha", "beta"},
 			},
 		},
+		{
+			name:         "GET request with arrays in the query",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte(`{"status": "ok"}`) },
+			urlTemplate:  "/items",
+			schema:       resolvedWithArrays,
+			method:       "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte(`{"name": "test-item", "tags": ["alpha", "beta"]}`),
+				},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: `{"status": "ok"}`,
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     neturl.Values{"name": {"test-item"}, "tags": {"alpha", "beta"}},
+			expectedPath:      "/items",
+		},
+		{
+			name:         "GET request with exploded arrays in the url",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte(`{"status": "ok"}`) },
+			urlTemplate:  "/items/{scores*}?tag={tags*|urlencode}",
+			headerTemplates: map[string]string{
+				"X-Tags": "{tags|join:, }",
+			},
+			schema: resolvedWithArrays,
+			method: "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte(`{"name": "test-item", "tags": ["a&b", "c"], "scores": [1, 2]}`),
+				},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: `{"status": "ok"}`,
+					},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     neturl.Values{"name": {"test-item"}, "tag": {"a&b", "c"}},
+			expectedPath:      "/items/1,2",
+			expectedHeaders: nethttp.Header{
+				"X-Tags": []string{"a&b, c"},
+			},
+		},
 		{
 			name:         "POST request with BodyAsArray wraps body in array",
 			responseCode: 200,
@@ -545,6 +722,91 @@ func TestHttpInvocation(t *testing.T) {
 				"items": []any{"foo", "bar"},
 			},
 		},
+		{
+			name:         "GET request with response transform",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte(`{"items":[{"name":"a"},{"name":"b"}],"total":2}`) },
+			urlTemplate:  "/items",
+			schema:       resolvedEmpty,
+			method:       "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{}"),
+				},
+			},
+			opts: testHttpInvokerOptions{
+				ResponseTransform: &invocation.ResponseTransform{JMESPath: "{names: items[].name}"},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: `{"names":["a","b"]}`,
+					},
+				},
+				StructuredContent: map[string]any{
+					"names": []any{"a", "b"},
+				},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/items",
+		},
+		{
+			name:         "response transform is not applied to error responses",
+			responseCode: 404,
+			responseBody: func() []byte { return []byte("not found") },
+			urlTemplate:  "/items",
+			schema:       resolvedEmpty,
+			method:       "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{}"),
+				},
+			},
+			opts: testHttpInvokerOptions{
+				ResponseTransform: &invocation.ResponseTransform{JQ: "[.items[].name]"},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "not found",
+					},
+				},
+				IsError: true,
+				Meta:    mcp.Meta{invocation.ErrorMetaKey: invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, 404)},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/items",
+		},
+		{
+			name:         "response transform on non json response",
+			responseCode: 200,
+			responseBody: func() []byte { return []byte("hello, world!") },
+			urlTemplate:  "/items",
+			schema:       resolvedEmpty,
+			method:       "GET",
+			request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Arguments: []byte("{}"),
+				},
+			},
+			opts: testHttpInvokerOptions{
+				ResponseTransform: &invocation.ResponseTransform{JQ: "[.items[].name]"},
+			},
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: "failed to transform response: response is not valid JSON: invalid character 'h' looking for beginning of value",
+					},
+				},
+				IsError: true,
+				Meta:    mcp.Meta{invocation.ErrorMetaKey: invocation.NewErrorDetail(invocation.ErrorCodeInternal, 0)},
+			},
+			expectedReqMethod: "GET",
+			expectedQuery:     make(neturl.Values),
+			expectedPath:      "/items",
+		},
 	}
 
 	for _, tc := range tt {
@@ -580,7 +842,14 @@ func TestHttpInvocation(t *testing.T) {
 
 			httpInvoker := testHttpInvokerWithOptions(t, s.URL+tc.urlTemplate, tc.headerTemplates, tc.schema, tc.method, "", tc.opts)
 
-			res, err := httpInvoker.Invoke(context.Background(), tc.request)
+			ctx := context.Background()
+			if tc.claims != nil {
+				ctx = template.WithClaims(ctx, tc.claims)
+			}
+			if tc.tenant != "" {
+				ctx = template.WithTenant(ctx, tc.tenant, tc.tenantVariables)
+			}
+			res, err := httpInvoker.Invoke(ctx, tc.request)
 			if tc.expectError {
 				// For validation/parsing errors, expect Go error
 				assert.Error(t, err, "http invocation should return Go error for validation/parsing failures")
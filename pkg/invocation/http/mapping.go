@@ -0,0 +1,89 @@
+package http
+
+import (
+	"fmt"
+	"maps"
+	neturl "net/url"
+	"slices"
+	"strings"
+)
+
+// hasBody reports whether the requests of the invocation send a body. Requests with a mapping only send one if
+// the mapping has body paths.
+func (hi *HttpInvoker) hasBody() bool {
+	if !methodHasBody(hi.Method) {
+		return false
+	}
+	return hi.Mapping == nil || len(hi.Mapping.Body) > 0
+}
+
+// appendQuery adds the query parameters mapped from the parsed arguments to url.
+func (mc *MappingConfig) appendQuery(url string, parsed map[string]any) (string, error) {
+	query := neturl.Values{}
+	for _, param := range slices.Sorted(maps.Keys(mc.Query)) {
+		value, ok := getValueByPath(parsed, trimPathRoot(mc.Query[param]))
+		if !ok || value == nil {
+			continue
+		}
+
+		values, err := formFieldValues(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode query parameter %s: %w", param, err)
+		}
+		for _, v := range values {
+			query.Add(param, v)
+		}
+	}
+
+	if len(query) == 0 {
+		return url, nil
+	}
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+
+	return url + separator + query.Encode(), nil
+}
+
+// body returns the request body mapped from the parsed arguments.
+func (mc *MappingConfig) body(parsed map[string]any) (map[string]any, error) {
+	body := make(map[string]any)
+	for _, path := range slices.Sorted(maps.Keys(mc.Body)) {
+		value, ok := getValueByPath(parsed, trimPathRoot(mc.Body[path]))
+		if !ok {
+			continue
+		}
+		if err := setValueByPath(body, path, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// setValueByPath sets value at a dot-separated path of m, creating the intermediate objects.
+func setValueByPath(m map[string]any, path string, value any) error {
+	keys := strings.Split(path, ".")
+
+	current := m
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key]
+		if !ok {
+			nested := make(map[string]any)
+			current[key] = nested
+			current = nested
+			continue
+		}
+
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("body path %s conflicts with a value that is not an object", path)
+		}
+		current = nested
+	}
+
+	current[keys[len(keys)-1]] = value
+	return nil
+}
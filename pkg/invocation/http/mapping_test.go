@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var resolvedForMapping, _ = (&jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"id":         {Type: invocation.JsonSchemaTypeString},
+		"name":       {Type: invocation.JsonSchemaTypeString},
+		"email":      {Type: invocation.JsonSchemaTypeString},
+		"apiVersion": {Type: invocation.JsonSchemaTypeString},
+		"debug":      {Type: invocation.JsonSchemaTypeBoolean},
+		"tags": {
+			Type:  invocation.JsonSchemaTypeArray,
+			Items: &jsonschema.Schema{Type: invocation.JsonSchemaTypeString},
+		},
+		"address": {
+			Type: invocation.JsonSchemaTypeObject,
+			Properties: map[string]*jsonschema.Schema{
+				"city": {Type: invocation.JsonSchemaTypeString},
+			},
+		},
+	},
+}).Resolve(nil)
+
+func TestHttpInvoker_Mapping(t *testing.T) {
+	tt := []struct {
+		name          string
+		method        string
+		urlTemplate   string
+		mapping       *MappingConfig
+		bodyAsArray   bool
+		args          string
+		expectedPath  string
+		expectedQuery string
+		expectedBody  string
+	}{
+		{
+			name:        "query and nested body with renames",
+			method:      nethttp.MethodPost,
+			urlTemplate: "/users/{id}",
+			mapping: &MappingConfig{
+				Query: map[string]string{"v": "apiVersion"},
+				Body: map[string]string{
+					"user.id":            "id",
+					"user.fullName":      "name",
+					"user.contact.email": "$.email",
+					"city":               "address.city",
+				},
+			},
+			args:          `{"id": "42", "name": "Alice", "email": "alice@example.com", "apiVersion": "2", "debug": true, "address": {"city": "Paris"}}`,
+			expectedPath:  "/users/42",
+			expectedQuery: "v=2",
+			expectedBody:  `{"user": {"id": "42", "fullName": "Alice", "contact": {"email": "alice@example.com"}}, "city": "Paris"}`,
+		},
+		{
+			name:        "missing properties are not sent",
+			method:      nethttp.MethodPut,
+			urlTemplate: "/users/{id}",
+			mapping: &MappingConfig{
+				Query: map[string]string{"v": "apiVersion"},
+				Body:  map[string]string{"user.fullName": "name", "user.contact.email": "email"},
+			},
+			args:         `{"id": "42", "name": "Alice"}`,
+			expectedPath: "/users/42",
+			expectedBody: `{"user": {"fullName": "Alice"}}`,
+		},
+		{
+			name:          "query only mapping sends no body",
+			method:        nethttp.MethodPost,
+			urlTemplate:   "/users/{id}/refresh",
+			mapping:       &MappingConfig{Query: map[string]string{"force": "debug"}},
+			args:          `{"id": "42", "name": "Alice", "debug": true}`,
+			expectedPath:  "/users/42/refresh",
+			expectedQuery: "force=true",
+		},
+		{
+			name:          "repeated query parameters appended to the url query",
+			method:        nethttp.MethodGet,
+			urlTemplate:   "/users?active=true",
+			mapping:       &MappingConfig{Query: map[string]string{"tag": "tags"}},
+			args:          `{"tags": ["a", "b"], "name": "Alice"}`,
+			expectedPath:  "/users",
+			expectedQuery: "active=true&tag=a&tag=b",
+		},
+		{
+			name:         "mapped body wrapped in an array",
+			method:       nethttp.MethodPost,
+			urlTemplate:  "/users",
+			mapping:      &MappingConfig{Body: map[string]string{"name": "name"}},
+			bodyAsArray:  true,
+			args:         `{"name": "Alice", "email": "alice@example.com"}`,
+			expectedPath: "/users",
+			expectedBody: `[{"name": "Alice"}]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var receivedPath, receivedQuery, receivedContentType string
+			var receivedBody []byte
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				receivedPath = r.URL.Path
+				receivedQuery = r.URL.RawQuery
+				receivedContentType = r.Header.Get("Content-Type")
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			invoker := testHttpInvoker(t, server.URL+tc.urlTemplate, nil, resolvedForMapping, tc.method, "")
+			invoker.Mapping = tc.mapping
+			invoker.BodyAsArray = tc.bodyAsArray
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.args)},
+			})
+			require.NoError(t, err)
+			require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+
+			assert.Equal(t, tc.expectedPath, receivedPath)
+			assert.Equal(t, tc.expectedQuery, receivedQuery)
+			if tc.expectedBody == "" {
+				assert.Empty(t, receivedBody)
+				assert.Empty(t, receivedContentType)
+			} else {
+				assert.JSONEq(t, tc.expectedBody, string(receivedBody))
+			}
+		})
+	}
+}
+
+func TestSetValueByPath(t *testing.T) {
+	tt := []struct {
+		name        string
+		initial     map[string]any
+		path        string
+		value       any
+		expected    map[string]any
+		expectError bool
+	}{
+		{
+			name:     "top level",
+			initial:  map[string]any{},
+			path:     "name",
+			value:    "alice",
+			expected: map[string]any{"name": "alice"},
+		},
+		{
+			name:     "nested into existing object",
+			initial:  map[string]any{"user": map[string]any{"id": 1}},
+			path:     "user.name",
+			value:    "alice",
+			expected: map[string]any{"user": map[string]any{"id": 1, "name": "alice"}},
+		},
+		{
+			name:        "conflict with a scalar",
+			initial:     map[string]any{"user": "alice"},
+			path:        "user.name",
+			value:       "alice",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := setValueByPath(tc.initial, tc.path, tc.value)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.initial)
+		})
+	}
+}
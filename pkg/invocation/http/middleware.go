@@ -23,3 +23,14 @@ func WithHTTPClientMiddleware(client *http.Client) mcp.Middleware {
 		}
 	}
 }
+
+// WithValidatedBearerTokensMiddleware creates an MCP middleware that marks the bearer tokens of incoming
+// requests as validated. It must only be added to servers whose HTTP requests are authenticated with OAuth
+// access tokens, as it allows HTTP invokers to forward the tokens to backends.
+func WithValidatedBearerTokensMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+			return next(WithValidatedBearerTokens(ctx), method, req)
+		}
+	}
+}
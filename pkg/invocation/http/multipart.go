@@ -0,0 +1,256 @@
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/multipart"
+	nethttp "net/http"
+	"net/textproto"
+	neturl "net/url"
+	"path"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const (
+	// maxFileSize is the maximum size of the files downloaded for file parts.
+	maxFileSize = 32 << 20
+
+	defaultFileContentType = "application/octet-stream"
+)
+
+// filePart is a file sent as a part of a multipart body.
+type filePart struct {
+	name        string // name of the form part
+	fileName    string
+	contentType string
+	content     []byte
+}
+
+// encodeMultipart encodes the properties of body as the parts of a multipart form. The properties configured in
+// FileParts, and the properties with a base64 contentEncoding in the input schema, are sent as files. The other
+// properties are sent as fields.
+func (hi *HttpInvoker) encodeMultipart(ctx context.Context, body any, dryRun bool) ([]byte, string, error) {
+	fields, ok := body.(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf("multipart bodies must be objects, got %T", body)
+	}
+
+	var properties map[string]*jsonschema.Schema
+	if hi.BodyRoot == "" && hi.InputSchema != nil {
+		properties = hi.InputSchema.Schema().Properties
+	}
+
+	// the properties holding the file names of file parts are not sent as fields
+	fileNameProperties := make(map[string]struct{})
+	for _, config := range hi.FileParts {
+		if config.FileNameProperty != "" {
+			fileNameProperties[config.FileNameProperty] = struct{}{}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+
+	for _, name := range sortedKeys(fields) {
+		value := fields[name]
+		if value == nil {
+			continue
+		}
+		if _, ok := fileNameProperties[name]; ok {
+			continue
+		}
+
+		if config := hi.filePartConfig(properties, name); config != nil {
+			part, err := loadFilePart(ctx, name, config, fields, dryRun)
+			if err != nil {
+				return nil, "", err
+			}
+			if err := writeFilePart(w, part); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		values, err := formFieldValues(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		for _, v := range values {
+			if err := w.WriteField(name, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// filePartConfig returns the config of the file part of a property, or nil if the property is sent as a field.
+func (hi *HttpInvoker) filePartConfig(properties map[string]*jsonschema.Schema, name string) *FilePartConfig {
+	if config, ok := hi.FileParts[name]; ok {
+		return config
+	}
+	if property, ok := properties[name]; ok && property.ContentEncoding == "base64" {
+		return &FilePartConfig{}
+	}
+	return nil
+}
+
+// loadFilePart loads the file of a property from its value. When dryRun is true, files are not downloaded from
+// http and https URLs, and the part holds a placeholder instead.
+func loadFilePart(ctx context.Context, property string, config *FilePartConfig, fields map[string]any, dryRun bool) (*filePart, error) {
+	value, ok := fields[property].(string)
+	if !ok {
+		return nil, fmt.Errorf("file property %s must be a string", property)
+	}
+
+	part := &filePart{
+		name:        cmp.Or(config.PartName, property),
+		fileName:    config.FileName,
+		contentType: config.ContentType,
+	}
+	if config.FileNameProperty != "" {
+		if fileName, ok := fields[config.FileNameProperty].(string); ok && fileName != "" {
+			part.fileName = fileName
+		}
+	}
+
+	switch config.Source {
+	case FileSourceURL:
+		if part.fileName == "" {
+			part.fileName = urlFileName(value)
+		}
+		if dryRun && !isDataURL(value) {
+			part.content = fmt.Appendf(nil, "<contents of %s>", value)
+			break
+		}
+
+		content, contentType, err := fetchFile(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download file property %s: %w", property, err)
+		}
+		part.content = content
+		part.contentType = cmp.Or(part.contentType, contentType)
+	default:
+		content, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("file property %s is not valid base64: %w", property, err)
+		}
+		part.content = content
+	}
+
+	part.fileName = cmp.Or(part.fileName, property)
+	part.contentType = cmp.Or(part.contentType, defaultFileContentType)
+
+	return part, nil
+}
+
+func writeFilePart(w *multipart.Writer, part *filePart) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", multipart.FileContentDisposition(part.name, part.fileName))
+	header.Set(contentTypeHeader, part.contentType)
+
+	pw, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = pw.Write(part.content)
+	return err
+}
+
+func isDataURL(rawURL string) bool {
+	return len(rawURL) >= 5 && strings.EqualFold(rawURL[:5], "data:")
+}
+
+// fetchFile returns the content of the file at an http, https or data URL, and its content type if known.
+func fetchFile(ctx context.Context, rawURL string) ([]byte, string, error) {
+	if isDataURL(rawURL) {
+		return decodeDataURL(rawURL)
+	}
+
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := HTTPClientFromContext(ctx).Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(content) > maxFileSize {
+		return nil, "", fmt.Errorf("file is larger than %d bytes", maxFileSize)
+	}
+
+	return content, resp.Header.Get(contentTypeHeader), nil
+}
+
+// decodeDataURL returns the content and media type of an RFC 2397 data URL.
+func decodeDataURL(rawURL string) ([]byte, string, error) {
+	mediaType, data, ok := strings.Cut(rawURL[len("data:"):], ",")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid data URL: missing ','")
+	}
+
+	mediaType, isBase64 := strings.CutSuffix(mediaType, ";base64")
+	if mediaType == "" || strings.HasPrefix(mediaType, ";") {
+		mediaType = "text/plain" + mediaType
+	}
+
+	if isBase64 {
+		content, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid data URL: %w", err)
+		}
+		return content, mediaType, nil
+	}
+
+	content, err := neturl.PathUnescape(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid data URL: %w", err)
+	}
+	return []byte(content), mediaType, nil
+}
+
+// urlFileName returns the last segment of the path of an http or https URL, or an empty string if it has none.
+func urlFileName(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || isDataURL(rawURL) {
+		return ""
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
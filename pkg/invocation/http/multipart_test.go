@@ -0,0 +1,250 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpInvoker_EncodeMultipartFiles(t *testing.T) {
+	downloads := 0
+	fileServer := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		downloads++
+		if r.URL.Path == "/missing.pdf" {
+			w.WriteHeader(nethttp.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	}))
+	defer fileServer.Close()
+
+	type expectedFile struct {
+		fileName    string
+		contentType string
+		content     string
+	}
+
+	tt := []struct {
+		name           string
+		fileParts      map[string]*FilePartConfig
+		fields         map[string]any
+		dryRun         bool
+		expectedFields map[string][]string
+		expectedFiles  map[string]expectedFile
+		expectedError  string
+		expectDownload bool
+	}{
+		{
+			name:           "base64 contentEncoding in the input schema",
+			fields:         map[string]any{"name": "alice", "avatar": "aGVsbG8="},
+			expectedFields: map[string][]string{"name": {"alice"}},
+			expectedFiles: map[string]expectedFile{
+				"avatar": {fileName: "avatar", contentType: "application/octet-stream", content: "hello"},
+			},
+		},
+		{
+			name: "configured base64 part",
+			fileParts: map[string]*FilePartConfig{
+				"avatar": {PartName: "image", ContentType: "image/png", FileNameProperty: "name"},
+			},
+			fields: map[string]any{"name": "alice.png", "avatar": "aGVsbG8="},
+			expectedFiles: map[string]expectedFile{
+				"image": {fileName: "alice.png", contentType: "image/png", content: "hello"},
+			},
+		},
+		{
+			name: "file name property falls back to file name",
+			fileParts: map[string]*FilePartConfig{
+				"document": {FileName: "document.txt", FileNameProperty: "name"},
+			},
+			fields: map[string]any{"document": "aGVsbG8="},
+			expectedFiles: map[string]expectedFile{
+				"document": {fileName: "document.txt", contentType: "application/octet-stream", content: "hello"},
+			},
+		},
+		{
+			name:      "http url",
+			fileParts: map[string]*FilePartConfig{"document": {Source: FileSourceURL}},
+			fields:    map[string]any{"document": fileServer.URL + "/files/report.pdf", "name": "report"},
+			expectedFields: map[string][]string{
+				"name": {"report"},
+			},
+			expectedFiles: map[string]expectedFile{
+				"document": {fileName: "report.pdf", contentType: "application/pdf", content: "%PDF-1.7"},
+			},
+			expectDownload: true,
+		},
+		{
+			name:      "configured content type takes precedence over downloaded content type",
+			fileParts: map[string]*FilePartConfig{"document": {Source: FileSourceURL, ContentType: "application/octet-stream"}},
+			fields:    map[string]any{"document": fileServer.URL + "/files/report.pdf"},
+			expectedFiles: map[string]expectedFile{
+				"document": {fileName: "report.pdf", contentType: "application/octet-stream", content: "%PDF-1.7"},
+			},
+			expectDownload: true,
+		},
+		{
+			name:      "data url",
+			fileParts: map[string]*FilePartConfig{"document": {Source: FileSourceURL}},
+			fields:    map[string]any{"document": "data:text/csv;base64,YSxiCjEsMg=="},
+			expectedFiles: map[string]expectedFile{
+				"document": {fileName: "document", contentType: "text/csv", content: "a,b\n1,2"},
+			},
+		},
+		{
+			name:      "dry run does not download",
+			fileParts: map[string]*FilePartConfig{"document": {Source: FileSourceURL}},
+			fields:    map[string]any{"document": fileServer.URL + "/files/report.pdf"},
+			dryRun:    true,
+			expectedFiles: map[string]expectedFile{
+				"document": {
+					fileName:    "report.pdf",
+					contentType: "application/octet-stream",
+					content:     "<contents of " + fileServer.URL + "/files/report.pdf>",
+				},
+			},
+		},
+		{
+			name:           "failed download",
+			fileParts:      map[string]*FilePartConfig{"document": {Source: FileSourceURL}},
+			fields:         map[string]any{"document": fileServer.URL + "/missing.pdf"},
+			expectedError:  "failed to download file property document: unexpected status 404",
+			expectDownload: true,
+		},
+		{
+			name:          "unsupported url scheme",
+			fileParts:     map[string]*FilePartConfig{"document": {Source: FileSourceURL}},
+			fields:        map[string]any{"document": "file:///etc/passwd"},
+			expectedError: `unsupported URL scheme "file"`,
+		},
+		{
+			name:          "invalid base64",
+			fileParts:     map[string]*FilePartConfig{"document": {}},
+			fields:        map[string]any{"document": "not base64!"},
+			expectedError: "file property document is not valid base64",
+		},
+		{
+			name:          "non string file",
+			fileParts:     map[string]*FilePartConfig{"document": {}},
+			fields:        map[string]any{"document": 1},
+			expectedError: "file property document must be a string",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			downloads = 0
+
+			invoker := testHttpInvoker(t, "http://example.com/upload", nil, resolvedWithFile, nethttp.MethodPost, "")
+			invoker.ContentType = EncodingMultipart
+			invoker.FileParts = tc.fileParts
+
+			body, contentType, err := invoker.encodeMultipart(context.Background(), tc.fields, tc.dryRun)
+			if tc.expectDownload {
+				assert.Equal(t, 1, downloads)
+			} else {
+				assert.Zero(t, downloads)
+			}
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			_, params, err := mime.ParseMediaType(contentType)
+			require.NoError(t, err)
+
+			reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+			fields := make(map[string][]string)
+			files := make(map[string]expectedFile)
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+
+				content, err := io.ReadAll(part)
+				require.NoError(t, err)
+
+				if part.FileName() == "" {
+					fields[part.FormName()] = append(fields[part.FormName()], string(content))
+					continue
+				}
+				files[part.FormName()] = expectedFile{
+					fileName:    part.FileName(),
+					contentType: part.Header.Get("Content-Type"),
+					content:     string(content),
+				}
+			}
+
+			if tc.expectedFields == nil {
+				tc.expectedFields = map[string][]string{}
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func TestDecodeDataURL(t *testing.T) {
+	tt := []struct {
+		name                string
+		url                 string
+		expectedContent     string
+		expectedContentType string
+		expectError         bool
+	}{
+		{
+			name:                "base64",
+			url:                 "data:image/png;base64,aGVsbG8=",
+			expectedContent:     "hello",
+			expectedContentType: "image/png",
+		},
+		{
+			name:                "percent encoded",
+			url:                 "data:text/plain;charset=utf-8,hello%20world",
+			expectedContent:     "hello world",
+			expectedContentType: "text/plain;charset=utf-8",
+		},
+		{
+			name:                "default media type",
+			url:                 "data:,hello",
+			expectedContent:     "hello",
+			expectedContentType: "text/plain",
+		},
+		{
+			name:        "missing data",
+			url:         "data:text/plain",
+			expectError: true,
+		},
+		{
+			name:        "invalid base64",
+			url:         "data:;base64,!!!",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			content, contentType, err := decodeDataURL(tc.url)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContent, string(content))
+			assert.Equal(t, tc.expectedContentType, contentType)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+const defaultMaxPages = 10
+
+// Paginator fetches the pages of a paginated HTTP API.
+type Paginator struct {
+	CursorParam    string // Query parameter of the cursor, for cursor pagination
+	NextCursorPath string // Path of the cursor of the next page in responses
+	PageParam      string // Query parameter of the page number, for page pagination
+	FirstPage      int    // Number of the first page
+	ItemsPath      string // Path of the items in responses, the whole response if empty
+	MaxPages       int    // Maximum number of pages fetched by a call
+}
+
+// NewPaginator creates a Paginator from a validated PaginationConfig, applying the defaults.
+// It returns nil if pc is nil.
+func NewPaginator(pc *PaginationConfig) *Paginator {
+	if pc == nil {
+		return nil
+	}
+
+	p := &Paginator{
+		CursorParam:    pc.CursorParam,
+		NextCursorPath: trimPathRoot(pc.NextCursorPath),
+		PageParam:      pc.PageParam,
+		FirstPage:      1,
+		ItemsPath:      trimPathRoot(pc.ItemsPath),
+		MaxPages:       pc.MaxPages,
+	}
+	if pc.FirstPage != nil {
+		p.FirstPage = *pc.FirstPage
+	}
+	if p.MaxPages == 0 {
+		p.MaxPages = defaultMaxPages
+	}
+
+	return p
+}
+
+// trimPathRoot removes the "$" root of a JSONPath style path.
+func trimPathRoot(path string) string {
+	if path == "$" {
+		return ""
+	}
+	return strings.TrimPrefix(path, "$.")
+}
+
+// invokePaginated sends the request of a tool call to every page, starting from the cursor or page number in the
+// query of url if any, until the last page or MaxPages is reached. The result holds the items of all pages, and
+// the cursor or number of the next page if pages remain. Error responses are returned as is.
+func (hi *HttpInvoker) invokePaginated(
+	ctx context.Context,
+	url string,
+	body []byte,
+	hasBody bool,
+	headers nethttp.Header,
+) *mcp.CallToolResult {
+	logger := logging.FromContext(ctx)
+	p := hi.Pagination
+
+	pageURL, err := neturl.Parse(url)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeValidation, "invalid request URL: %v", err)
+	}
+	query := pageURL.Query()
+
+	page := p.FirstPage
+	if p.PageParam != "" && query.Get(p.PageParam) != "" {
+		if page, err = strconv.Atoi(query.Get(p.PageParam)); err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeValidation, "invalid %s: %v", p.PageParam, err)
+		}
+	}
+
+	items := []any{}
+	var next any
+	for fetched := 1; ; fetched++ {
+		if p.PageParam != "" {
+			query.Set(p.PageParam, strconv.Itoa(page))
+			pageURL.RawQuery = query.Encode()
+		}
+
+		var reqBody io.Reader
+		if hasBody {
+			reqBody = bytes.NewReader(body)
+		}
+
+		response, respBody, _, err := hi.executeHTTPRequest(ctx, hi.Method, pageURL.String(), reqBody, hasBody, headers.Clone(), nil, nil)
+		if err != nil {
+			return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "HTTP request failed: %v", err)
+		}
+
+		decoded, err := hi.decodeResponse(response.Header.Get(contentTypeHeader), respBody)
+		if err != nil {
+			logger.Error("Failed to decode HTTP response", zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to decode response: %v", err)
+		}
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			result := &mcp.CallToolResult{
+				Content: []mcp.Content{decoded.content(pageURL.String())},
+			}
+			invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, response.StatusCode))
+			return result
+		}
+
+		if decoded.json == nil {
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "paginated response is not JSON")
+		}
+
+		var doc any
+		if err := json.Unmarshal(decoded.json, &doc); err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to decode response: %v", err)
+		}
+
+		pageItems, err := p.items(doc)
+		if err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to read page %d: %v", fetched, err)
+		}
+		items = append(items, pageItems...)
+
+		if p.CursorParam != "" {
+			cursor := p.nextCursor(doc)
+			if cursor == "" {
+				next = nil
+				break
+			}
+			next = cursor
+			query.Set(p.CursorParam, cursor)
+			pageURL.RawQuery = query.Encode()
+		} else {
+			if len(pageItems) == 0 {
+				next = nil
+				break
+			}
+			page++
+			next = page
+		}
+
+		if fetched >= p.MaxPages {
+			break
+		}
+	}
+
+	logger.Debug("Fetched paginated HTTP response", zap.Int("items", len(items)))
+
+	result := map[string]any{"items": items}
+	if next != nil {
+		if p.CursorParam != "" {
+			result["nextCursor"] = next
+		} else {
+			result["nextPage"] = next
+		}
+	}
+
+	merged, err := json.Marshal(result)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode response: %v", err)
+	}
+
+	return hi.toolResult(ctx, merged, merged, false)
+}
+
+// items returns the items of a page, or an error if the items aren't an array. Pages without items return nil.
+func (p *Paginator) items(doc any) ([]any, error) {
+	value := doc
+	if p.ItemsPath != "" {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("response is not an object")
+		}
+		if value, ok = getValueByPath(m, p.ItemsPath); !ok {
+			return nil, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("items are not an array")
+	}
+}
+
+// nextCursor returns the cursor of the next page, or an empty string on the last page.
+func (p *Paginator) nextCursor(doc any) string {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	value, ok := getValueByPath(m, p.NextCursorPath)
+	if !ok {
+		return ""
+	}
+
+	cursor, _ := formatScalar(value)
+	return cursor
+}
@@ -0,0 +1,293 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var resolvedWithCursor, _ = (&jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"cursor": {Type: invocation.JsonSchemaTypeString},
+		"page":   {Type: invocation.JsonSchemaTypeInteger},
+	},
+}).Resolve(nil)
+
+func TestHttpInvoker_Pagination(t *testing.T) {
+	// cursor pages: "" -> c2 -> c3 -> end
+	cursorPages := map[string]string{
+		"":   `{"data": [{"id": 1}, {"id": 2}], "meta": {"next": "c2"}}`,
+		"c2": `{"data": [{"id": 3}], "meta": {"next": "c3"}}`,
+		"c3": `{"data": [{"id": 4}], "meta": {"next": null}}`,
+	}
+	// numbered pages: 1 and 2 have items, 3 is empty
+	numberedPages := map[string]string{
+		"0": `[{"id": 0}]`,
+		"1": `[{"id": 1}]`,
+		"2": `[{"id": 2}]`,
+		"3": `[]`,
+	}
+
+	tt := []struct {
+		name              string
+		path              string
+		pagination        *PaginationConfig
+		args              string
+		expectedRequests  []string
+		expectedResult    map[string]any
+		expectError       bool
+		expectedErrorText string
+	}{
+		{
+			name:             "cursor pagination fetches every page",
+			path:             "/cursor",
+			pagination:       &PaginationConfig{CursorParam: "cursor", NextCursorPath: "$.meta.next", ItemsPath: "data"},
+			args:             `{}`,
+			expectedRequests: []string{"", "c2", "c3"},
+			expectedResult: map[string]any{"items": []any{
+				map[string]any{"id": float64(1)},
+				map[string]any{"id": float64(2)},
+				map[string]any{"id": float64(3)},
+				map[string]any{"id": float64(4)},
+			}},
+		},
+		{
+			name:             "cursor pagination returns the next cursor after max pages",
+			path:             "/cursor",
+			pagination:       &PaginationConfig{CursorParam: "cursor", NextCursorPath: "meta.next", ItemsPath: "data", MaxPages: 2},
+			args:             `{}`,
+			expectedRequests: []string{"", "c2"},
+			expectedResult: map[string]any{
+				"items": []any{
+					map[string]any{"id": float64(1)},
+					map[string]any{"id": float64(2)},
+					map[string]any{"id": float64(3)},
+				},
+				"nextCursor": "c3",
+			},
+		},
+		{
+			name:             "cursor pagination continues from the cursor argument",
+			path:             "/cursor",
+			pagination:       &PaginationConfig{CursorParam: "cursor", NextCursorPath: "meta.next", ItemsPath: "data"},
+			args:             `{"cursor": "c3"}`,
+			expectedRequests: []string{"c3"},
+			expectedResult: map[string]any{"items": []any{
+				map[string]any{"id": float64(4)},
+			}},
+		},
+		{
+			name:             "page pagination stops at the first empty page",
+			path:             "/pages",
+			pagination:       &PaginationConfig{PageParam: "page"},
+			args:             `{}`,
+			expectedRequests: []string{"1", "2", "3"},
+			expectedResult: map[string]any{"items": []any{
+				map[string]any{"id": float64(1)},
+				map[string]any{"id": float64(2)},
+			}},
+		},
+		{
+			name:             "page pagination returns the next page after max pages",
+			path:             "/pages",
+			pagination:       &PaginationConfig{PageParam: "page", FirstPage: new(int), MaxPages: 2},
+			args:             `{}`,
+			expectedRequests: []string{"0", "1"},
+			expectedResult: map[string]any{
+				"items":    []any{map[string]any{"id": float64(0)}, map[string]any{"id": float64(1)}},
+				"nextPage": float64(2),
+			},
+		},
+		{
+			name:             "page pagination continues from the page argument",
+			path:             "/pages",
+			pagination:       &PaginationConfig{PageParam: "page"},
+			args:             `{"page": 2}`,
+			expectedRequests: []string{"2", "3"},
+			expectedResult: map[string]any{"items": []any{
+				map[string]any{"id": float64(2)},
+			}},
+		},
+		{
+			name:              "items that are not an array",
+			path:              "/cursor",
+			pagination:        &PaginationConfig{CursorParam: "cursor", NextCursorPath: "meta.next", ItemsPath: "meta"},
+			args:              `{}`,
+			expectedRequests:  []string{""},
+			expectError:       true,
+			expectedErrorText: "failed to read page 1: items are not an array",
+		},
+		{
+			name:              "error responses are returned as is",
+			path:              "/missing",
+			pagination:        &PaginationConfig{PageParam: "page"},
+			args:              `{}`,
+			expectedRequests:  []string{"1"},
+			expectError:       true,
+			expectedErrorText: `{"error": "not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/cursor":
+					cursor := r.URL.Query().Get("cursor")
+					requests = append(requests, cursor)
+					_, _ = w.Write([]byte(cursorPages[cursor]))
+				case "/pages":
+					page := r.URL.Query().Get("page")
+					requests = append(requests, page)
+					_, _ = w.Write([]byte(numberedPages[page]))
+				default:
+					requests = append(requests, r.URL.Query().Get("page"))
+					w.WriteHeader(nethttp.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error": "not found"}`))
+				}
+			}))
+			defer server.Close()
+
+			invoker := testHttpInvoker(t, server.URL+tc.path, nil, resolvedWithCursor, nethttp.MethodGet, "")
+			invoker.Pagination = NewPaginator(tc.pagination)
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.args)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRequests, requests)
+
+			if tc.expectError {
+				assert.True(t, result.IsError)
+				assert.Equal(t, tc.expectedErrorText, result.Content[0].(*mcp.TextContent).Text)
+				return
+			}
+
+			require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+			assert.Equal(t, tc.expectedResult, result.StructuredContent)
+
+			var text map[string]any
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &text))
+			assert.Equal(t, tc.expectedResult, text)
+		})
+	}
+}
+
+func TestNewPaginator(t *testing.T) {
+	firstPage := 0
+
+	tt := []struct {
+		name     string
+		config   *PaginationConfig
+		expected *Paginator
+	}{
+		{
+			name:     "nil config",
+			config:   nil,
+			expected: nil,
+		},
+		{
+			name:   "defaults",
+			config: &PaginationConfig{PageParam: "page"},
+			expected: &Paginator{
+				PageParam: "page",
+				FirstPage: 1,
+				MaxPages:  defaultMaxPages,
+			},
+		},
+		{
+			name: "jsonpath roots are removed",
+			config: &PaginationConfig{
+				CursorParam:    "after",
+				NextCursorPath: "$.paging.next",
+				ItemsPath:      "$",
+				MaxPages:       3,
+			},
+			expected: &Paginator{
+				CursorParam:    "after",
+				NextCursorPath: "paging.next",
+				FirstPage:      1,
+				MaxPages:       3,
+			},
+		},
+		{
+			name:   "zero first page",
+			config: &PaginationConfig{PageParam: "page", FirstPage: &firstPage, ItemsPath: "results"},
+			expected: &Paginator{
+				PageParam: "page",
+				FirstPage: 0,
+				ItemsPath: "results",
+				MaxPages:  defaultMaxPages,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NewPaginator(tc.config))
+		})
+	}
+}
+
+func TestPaginator_NextCursor(t *testing.T) {
+	tt := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{name: "string cursor", doc: `{"next": "abc"}`, expected: "abc"},
+		{name: "numeric cursor", doc: `{"next": 42}`, expected: "42"},
+		{name: "null cursor", doc: `{"next": null}`, expected: ""},
+		{name: "missing cursor", doc: `{}`, expected: ""},
+		{name: "array response", doc: `[]`, expected: ""},
+	}
+
+	p := NewPaginator(&PaginationConfig{CursorParam: "cursor", NextCursorPath: "next"})
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var doc any
+			require.NoError(t, json.Unmarshal([]byte(tc.doc), &doc))
+			assert.Equal(t, tc.expected, p.nextCursor(doc))
+		})
+	}
+}
+
+func TestHttpInvoker_PaginationKeepsQuery(t *testing.T) {
+	// the page number replaces the one in the URL, while other query parameters are kept
+	var pages []string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		assert.Equal(t, "10", r.URL.Query().Get("size"))
+		pages = append(pages, r.URL.Query().Get("p"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		w.Header().Set("Content-Type", "application/json")
+		if page > 2 {
+			_, _ = w.Write([]byte(`{"results": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results": [` + strconv.Itoa(page) + `]}`))
+	}))
+	defer server.Close()
+
+	invoker := testHttpInvoker(t, server.URL+"/items?size=10", nil, resolvedEmpty, nethttp.MethodGet, "")
+	invoker.Pagination = NewPaginator(&PaginationConfig{PageParam: "p", ItemsPath: "results"})
+
+	result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected error result: %v", result.Content)
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+	assert.Equal(t, map[string]any{"items": []any{float64(1), float64(2)}}, result.StructuredContent)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/signing"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// RequestSigner signs the requests sent to a backend with an HMAC of their timestamp, method, path, query and
+// body, which the backend verifies with the shared secret, e.g. with signing.Signer.
+type RequestSigner struct {
+	Secret *template.ParsedTemplate // may reference secrets and environment variables
+
+	config signing.Config
+}
+
+// NewRequestSigner returns the RequestSigner of a validated SigningConfig, whose secret can only reference the
+// environment variables allowed by allowlist. It returns nil if sc is nil.
+func NewRequestSigner(sc *SigningConfig, allowlist *template.Allowlist) (*RequestSigner, error) {
+	if sc == nil {
+		return nil, nil
+	}
+
+	config := signing.Config{
+		SignatureHeader: cmp.Or(sc.Header, signing.DefaultSignatureHeader),
+		TimestampHeader: cmp.Or(sc.TimestampHeader, signing.DefaultTimestampHeader),
+		Algorithm:       strings.ToLower(sc.Algorithm),
+		Encoding:        strings.ToLower(sc.Encoding),
+	}
+	if _, err := signing.NewSigner(config); err != nil {
+		return nil, err
+	}
+
+	secret, err := template.ParseTemplate(sc.Secret, template.TemplateParserOptions{
+		Sources:   map[string]template.SourceFactory{"secrets": template.NewSourceFactory("secrets")},
+		Allowlist: allowlist,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing secret template: %w", err)
+	}
+
+	return &RequestSigner{Secret: secret, config: config}, nil
+}
+
+// Sign sets the timestamp and signature headers of headers for a request of method to url with body. It is
+// called for every attempt, so that retried requests have a fresh timestamp.
+func (rs *RequestSigner) Sign(ctx context.Context, headers nethttp.Header, method, url string, body []byte) error {
+	tb, err := template.NewTemplateBuilder(rs.Secret, false)
+	if err != nil {
+		return fmt.Errorf("failed to create signing secret builder: %w", err)
+	}
+	tb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+
+	secret, err := tb.GetResult()
+	if err != nil {
+		return fmt.Errorf("failed to resolve signing secret: %w", err)
+	}
+
+	config := rs.config
+	config.Secret = secret.(string)
+	signer, err := signing.NewSigner(config)
+	if err != nil {
+		return err
+	}
+
+	if err := signer.Sign(headers, method, url, body); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	return nil
+}
+
+// DryRun sets the timestamp and signature headers of headers to placeholders for the values Sign would send.
+func (rs *RequestSigner) DryRun(headers nethttp.Header) {
+	headers.Set(rs.config.TimestampHeader, "[TIMESTAMP]")
+	headers.Set(rs.config.SignatureHeader, "[SIGNATURE]")
+}
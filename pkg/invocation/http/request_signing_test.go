@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/signing"
+)
+
+func TestHttpInvocationSigning(t *testing.T) {
+	tt := []struct {
+		name             string
+		config           *SigningConfig
+		verifier         signing.Config
+		method           string
+		streaming        bool
+		failFirstAttempt bool
+		expectedIsError  bool
+		expectedAttempts int32
+	}{
+		{
+			name:             "signed request without body",
+			config:           &SigningConfig{Secret: "s3cret"},
+			verifier:         signing.Config{Secret: "s3cret"},
+			method:           "GET",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "signed request with body",
+			config:           &SigningConfig{Secret: "s3cret"},
+			verifier:         signing.Config{Secret: "s3cret"},
+			method:           "POST",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "secret from secrets",
+			config:           &SigningConfig{Secret: "{secrets.SIGNING_SECRET}"},
+			verifier:         signing.Config{Secret: "s3cret"},
+			method:           "POST",
+			expectedAttempts: 1,
+		},
+		{
+			name: "sha512 base64 signature in custom headers",
+			config: &SigningConfig{
+				Secret:          "s3cret",
+				Header:          "X-Signature",
+				TimestampHeader: "X-Signed-At",
+				Algorithm:       "SHA512",
+				Encoding:        "base64",
+			},
+			verifier: signing.Config{
+				Secret:          "s3cret",
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Signed-At",
+				Algorithm:       signing.AlgorithmSHA512,
+				Encoding:        signing.EncodingBase64,
+			},
+			method:           "POST",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retried request is signed again",
+			config:           &SigningConfig{Secret: "s3cret"},
+			verifier:         signing.Config{Secret: "s3cret"},
+			method:           "POST",
+			failFirstAttempt: true,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "streaming request",
+			config:           &SigningConfig{Secret: "s3cret"},
+			verifier:         signing.Config{Secret: "s3cret"},
+			method:           "POST",
+			streaming:        true,
+			expectedAttempts: 1,
+		},
+		{
+			name:            "signed with another secret",
+			config:          &SigningConfig{Secret: "other"},
+			verifier:        signing.Config{Secret: "s3cret"},
+			method:          "POST",
+			expectedIsError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := signing.NewSigner(tc.verifier)
+			require.NoError(t, err)
+
+			var attempts atomic.Int32
+			backend := httptest.NewServer(verifier.Middleware(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if attempts.Add(1) == 1 && tc.failFirstAttempt {
+					w.WriteHeader(nethttp.StatusServiceUnavailable)
+					return
+				}
+				body, _ := io.ReadAll(r.Body)
+				_, _ = w.Write(body)
+			})))
+			defer backend.Close()
+
+			invoker := testHttpInvoker(t, backend.URL+"/search?lang=en", nil, resolvedWithPath, tc.method, "")
+			invoker.Streaming = tc.streaming
+			invoker.MessageFraming = MessageFramingChunks
+			invoker.Signer, err = NewRequestSigner(tc.config, nil)
+			require.NoError(t, err)
+			if tc.failFirstAttempt {
+				invoker.Retry, err = NewRetryPolicy(&RetryConfig{MaxRetries: 1, InitialDelay: "1ms"})
+				require.NoError(t, err)
+			}
+
+			ctx := secrets.WithStore(context.Background(), secrets.NewStore(mapSecrets{"SIGNING_SECRET": "s3cret"}))
+			result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"search":"foo"}`)},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedIsError, result.IsError)
+			if tc.expectedIsError {
+				assert.Zero(t, attempts.Load(), "the backend should not handle requests with an invalid signature")
+				return
+			}
+			assert.Equal(t, tc.expectedAttempts, attempts.Load())
+			if tc.method == "POST" {
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, `"search":"foo"`)
+			}
+		})
+	}
+}
+
+func TestHttpInvocationSigningDryRun(t *testing.T) {
+	invoker := testHttpInvoker(t, "https://api.example.com/users", nil, resolvedEmpty, "GET", "")
+	var err error
+	invoker.Signer, err = NewRequestSigner(&SigningConfig{Secret: "s3cret"}, nil)
+	require.NoError(t, err)
+
+	result, err := invoker.DryRun(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "[TIMESTAMP]", result.Headers.Get(signing.DefaultTimestampHeader))
+	assert.Equal(t, "[SIGNATURE]", result.Headers.Get(signing.DefaultSignatureHeader))
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/netpolicy"
+)
+
+const (
+	// DefaultTimeout is the timeout of a single request attempt if none is configured.
+	DefaultTimeout = 60 * time.Second
+
+	defaultInitialDelay = 500 * time.Millisecond
+	defaultMaxDelay     = 10 * time.Second
+)
+
+// defaultRetryableStatusCodes are retried if no status codes are configured.
+var defaultRetryableStatusCodes = []int{
+	nethttp.StatusTooManyRequests,
+	nethttp.StatusBadGateway,
+	nethttp.StatusServiceUnavailable,
+	nethttp.StatusGatewayTimeout,
+}
+
+// RetryPolicy decides whether and when a failed HTTP request is retried.
+type RetryPolicy struct {
+	MaxRetries           int           // Maximum number of retries after the first attempt
+	Backoff              string        // Backoff strategy between attempts
+	InitialDelay         time.Duration // Delay before the first retry
+	MaxDelay             time.Duration // Upper bound of any delay between attempts
+	RetryableStatusCodes map[int]bool  // Response status codes that trigger a retry
+}
+
+// NewRetryPolicy creates a RetryPolicy from a validated RetryConfig, applying the defaults.
+// It returns nil if rc is nil.
+func NewRetryPolicy(rc *RetryConfig) (*RetryPolicy, error) {
+	if rc == nil {
+		return nil, nil
+	}
+
+	rp := &RetryPolicy{
+		MaxRetries:           rc.MaxRetries,
+		Backoff:              strings.ToLower(rc.Backoff),
+		InitialDelay:         defaultInitialDelay,
+		MaxDelay:             defaultMaxDelay,
+		RetryableStatusCodes: make(map[int]bool),
+	}
+
+	if rp.Backoff == "" {
+		rp.Backoff = BackoffExponential
+	}
+
+	var err error
+	if rc.InitialDelay != "" {
+		if rp.InitialDelay, err = time.ParseDuration(rc.InitialDelay); err != nil {
+			return nil, err
+		}
+	}
+	if rc.MaxDelay != "" {
+		if rp.MaxDelay, err = time.ParseDuration(rc.MaxDelay); err != nil {
+			return nil, err
+		}
+	}
+
+	statusCodes := rc.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryableStatusCodes
+	}
+	for _, code := range statusCodes {
+		rp.RetryableStatusCodes[code] = true
+	}
+
+	return rp, nil
+}
+
+// ShouldRetry reports whether an attempt that returned response and err should be retried.
+// Network errors and timeouts are retried, unless the parent context is done.
+func (rp *RetryPolicy) ShouldRetry(ctx context.Context, attempt int, response *nethttp.Response, err error) bool {
+	if rp == nil || attempt >= rp.MaxRetries || ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, netpolicy.ErrDenied)
+	}
+
+	return response != nil && rp.RetryableStatusCodes[response.StatusCode]
+}
+
+// Delay returns how long to wait before the retry following the given attempt (starting at 0).
+// A Retry-After header on the response takes precedence over the backoff strategy.
+func (rp *RetryPolicy) Delay(attempt int, response *nethttp.Response) time.Duration {
+	if response != nil {
+		if d, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+			return min(d, rp.MaxDelay)
+		}
+	}
+
+	var d time.Duration
+	switch rp.Backoff {
+	case BackoffConstant:
+		d = rp.InitialDelay
+	case BackoffLinear:
+		d = rp.InitialDelay * time.Duration(attempt+1)
+	default:
+		// stop doubling once the max delay is reached to avoid overflowing
+		d = rp.InitialDelay
+		for i := 0; i < attempt && d < rp.MaxDelay; i++ {
+			d *= 2
+		}
+	}
+
+	return min(d, rp.MaxDelay)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := nethttp.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpInvocationRetry(t *testing.T) {
+	tt := []struct {
+		name             string
+		method           string
+		retry            *RetryConfig
+		timeout          time.Duration
+		responses        []int
+		responseDelay    time.Duration
+		expectedAttempts int32
+		expectedIsError  bool
+		expectedText     string
+	}{
+		{
+			name:             "no retry config",
+			method:           "GET",
+			responses:        []int{503, 200},
+			expectedAttempts: 1,
+			expectedIsError:  true,
+			expectedText:     "attempt 1",
+		},
+		{
+			name:             "retries until success",
+			method:           "POST",
+			retry:            &RetryConfig{MaxRetries: 3, InitialDelay: "1ms"},
+			responses:        []int{503, 502, 200},
+			expectedAttempts: 3,
+			expectedText:     "attempt 3",
+		},
+		{
+			name:             "gives up after max retries",
+			method:           "GET",
+			retry:            &RetryConfig{MaxRetries: 2, Backoff: BackoffConstant, InitialDelay: "1ms"},
+			responses:        []int{503, 503, 503, 200},
+			expectedAttempts: 3,
+			expectedIsError:  true,
+			expectedText:     "attempt 3",
+		},
+		{
+			name:             "non retryable status code",
+			method:           "GET",
+			retry:            &RetryConfig{MaxRetries: 3, InitialDelay: "1ms"},
+			responses:        []int{500, 200},
+			expectedAttempts: 1,
+			expectedIsError:  true,
+			expectedText:     "attempt 1",
+		},
+		{
+			name:             "custom retryable status code",
+			method:           "GET",
+			retry:            &RetryConfig{MaxRetries: 3, InitialDelay: "1ms", RetryableStatusCodes: []int{500}},
+			responses:        []int{500, 200},
+			expectedAttempts: 2,
+			expectedText:     "attempt 2",
+		},
+		{
+			name:             "timeout without retry",
+			method:           "GET",
+			timeout:          20 * time.Millisecond,
+			responses:        []int{200},
+			responseDelay:    time.Second,
+			expectedAttempts: 1,
+			expectedIsError:  true,
+			expectedText:     "HTTP request failed: request timed out after 20ms",
+		},
+		{
+			name:             "timeout is retried",
+			method:           "GET",
+			retry:            &RetryConfig{MaxRetries: 1, InitialDelay: "1ms"},
+			timeout:          20 * time.Millisecond,
+			responses:        []int{200},
+			responseDelay:    time.Second,
+			expectedAttempts: 2,
+			expectedIsError:  true,
+			expectedText:     "HTTP request failed: request timed out after 20ms",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				n := attempts.Add(1)
+
+				if r.Method == nethttp.MethodPost {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err, "reading request body should not fail")
+					assert.JSONEq(t, `{"search":"foo"}`, string(body), "every attempt should send the full body")
+				}
+
+				if tc.responseDelay > 0 {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(tc.responseDelay):
+					}
+					return
+				}
+
+				w.WriteHeader(tc.responses[min(int(n), len(tc.responses))-1])
+				_, err := w.Write([]byte("attempt " + strconv.Itoa(int(n))))
+				assert.NoError(t, err, "writing response should not fail")
+			}))
+			defer s.Close()
+
+			httpInvoker := testHttpInvoker(t, s.URL+"/retry", nil, resolvedWithPath, tc.method, "")
+			httpInvoker.Timeout = tc.timeout
+			retry, err := NewRetryPolicy(tc.retry)
+			require.NoError(t, err, "creating the retry policy should not fail")
+			httpInvoker.Retry = retry
+
+			res, err := httpInvoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"search":"foo"}`)},
+			})
+			require.NoError(t, err, "invocation should not return Go error")
+
+			assert.Equal(t, tc.expectedAttempts, attempts.Load(), "number of attempts should match")
+			assert.Equal(t, tc.expectedIsError, res.IsError, "tool call error status should match")
+			require.Len(t, res.Content, 1)
+			assert.Equal(t, tc.expectedText, res.Content[0].(*mcp.TextContent).Text, "tool call result should match")
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tt := []struct {
+		name       string
+		backoff    string
+		attempt    int
+		retryAfter string
+		expected   time.Duration
+	}{
+		{name: "constant", backoff: BackoffConstant, attempt: 3, expected: 100 * time.Millisecond},
+		{name: "linear", backoff: BackoffLinear, attempt: 2, expected: 300 * time.Millisecond},
+		{name: "exponential", backoff: BackoffExponential, attempt: 3, expected: 800 * time.Millisecond},
+		{name: "capped by max delay", backoff: BackoffExponential, attempt: 20, expected: time.Second},
+		{name: "retry after seconds", backoff: BackoffConstant, retryAfter: "1", expected: time.Second},
+		{name: "retry after capped by max delay", backoff: BackoffConstant, retryAfter: "120", expected: time.Second},
+		{name: "invalid retry after is ignored", backoff: BackoffConstant, retryAfter: "later", expected: 100 * time.Millisecond},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rp, err := NewRetryPolicy(&RetryConfig{
+				MaxRetries:   5,
+				Backoff:      tc.backoff,
+				InitialDelay: "100ms",
+				MaxDelay:     "1s",
+			})
+			require.NoError(t, err, "creating the retry policy should not fail")
+
+			response := &nethttp.Response{Header: nethttp.Header{}}
+			if tc.retryAfter != "" {
+				response.Header.Set("Retry-After", tc.retryAfter)
+			}
+
+			assert.Equal(t, tc.expected, rp.Delay(tc.attempt, response), "delay should match")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/spool"
+)
+
+// readResponseBody reads the body of response. If sp is set and the body is larger than its threshold, the
+// body is spooled to sp instead and the spooled file is returned: only the first threshold bytes of the body
+// are held in memory, and the rest is read as fast as it is written to disk.
+func readResponseBody(response *nethttp.Response, sp *spool.Spool) ([]byte, *spool.File, error) {
+	if sp == nil {
+		body, err := io.ReadAll(response.Body)
+		return body, nil, err
+	}
+
+	threshold := sp.ThresholdBytes()
+	var head []byte
+	// the length is -1 if unknown, in which case the body is read until it exceeds the threshold
+	if response.ContentLength <= threshold {
+		var err error
+		head, err = io.ReadAll(io.LimitReader(response.Body, threshold+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64(len(head)) <= threshold {
+			return head, nil, nil
+		}
+	}
+
+	file, err := sp.Store(io.MultiReader(bytes.NewReader(head), response.Body), response.Header.Get(contentTypeHeader))
+	if errors.Is(err, spool.ErrFull) {
+		return nil, nil, invocation.Errorf(invocation.ErrorCodeInternal, "failed to spool response: %w", err)
+	}
+	return nil, file, err
+}
+
+// discardSpooled removes the file a response was spooled to, if any, when the response is discarded.
+func discardSpooled(sp *spool.Spool, file *spool.File) {
+	if file != nil {
+		sp.Remove(file.ID)
+	}
+}
+
+// spooledResult returns the result of a tool call whose response was spooled: a link to the resource serving
+// the spooled response, which clients read in chunks. The response transform is not applied, as it would
+// require holding the response in memory.
+func spooledResult(ctx context.Context, file *spool.File, isError bool) *mcp.CallToolResult {
+	logging.FromContext(ctx).Info("HTTP tool invocation completed with a spooled response",
+		zap.Int64("response_length", file.Size))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{file.ResourceLink()},
+		IsError: isError,
+	}
+}
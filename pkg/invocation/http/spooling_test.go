@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/spool"
+)
+
+func TestHttpInvocationSpooling(t *testing.T) {
+	large := `{"items":"` + strings.Repeat("x", 64) + `"}`
+
+	tt := []struct {
+		name              string
+		config            spool.Config
+		statuses          []int
+		body              string
+		chunked           bool
+		retry             *RetryConfig
+		expectedSpooled   bool
+		expectedFiles     int
+		expectedIsError   bool
+		expectedErrorCode invocation.ErrorCode
+		expectedText      string
+	}{
+		{
+			name:         "response under the threshold",
+			body:         `{"items":"x"}`,
+			expectedText: `{"items":"x"}`,
+		},
+		{
+			name:            "response over the threshold",
+			body:            large,
+			expectedSpooled: true,
+			expectedFiles:   1,
+		},
+		{
+			name:            "response of unknown length over the threshold",
+			body:            large,
+			chunked:         true,
+			expectedSpooled: true,
+			expectedFiles:   1,
+		},
+		{
+			name:              "error response over the threshold",
+			statuses:          []int{nethttp.StatusBadGateway},
+			body:              large,
+			expectedSpooled:   true,
+			expectedFiles:     1,
+			expectedIsError:   true,
+			expectedErrorCode: invocation.ErrorCodeBackendStatus,
+		},
+		{
+			name:            "retried response is discarded",
+			statuses:        []int{nethttp.StatusServiceUnavailable, nethttp.StatusOK},
+			body:            large,
+			retry:           &RetryConfig{MaxRetries: 1, InitialDelay: "1ms"},
+			expectedSpooled: true,
+			expectedFiles:   1,
+		},
+		{
+			name:              "spool full",
+			config:            spool.Config{MaxTotalBytes: 50},
+			body:              large,
+			expectedIsError:   true,
+			expectedErrorCode: invocation.ErrorCodeInternal,
+			expectedText:      "HTTP request failed: failed to spool response: spool is full",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				n := int(attempts.Add(1))
+				w.Header().Set("Content-Type", "application/json")
+				if tc.chunked {
+					// flushing before the end of the body sends it without a Content-Length
+					w.(nethttp.Flusher).Flush()
+				}
+				if len(tc.statuses) > 0 {
+					w.WriteHeader(tc.statuses[min(n, len(tc.statuses))-1])
+				}
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer s.Close()
+
+			tc.config.Dir = t.TempDir()
+			tc.config.ThresholdBytes = 32
+			sp, err := spool.New(tc.config)
+			require.NoError(t, err)
+
+			httpInvoker := testHttpInvoker(t, s.URL+"/items", nil, resolvedEmpty, "GET", "")
+			retry, err := NewRetryPolicy(tc.retry)
+			require.NoError(t, err)
+			httpInvoker.Retry = retry
+
+			res, err := httpInvoker.Invoke(spool.WithSpool(context.Background(), sp), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIsError, res.IsError)
+			if tc.expectedErrorCode != "" {
+				detail, ok := invocation.GetErrorDetail(res)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedErrorCode, detail.Code)
+			}
+
+			entries, err := os.ReadDir(tc.config.Dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, tc.expectedFiles)
+
+			require.Len(t, res.Content, 1)
+			if !tc.expectedSpooled {
+				assert.Equal(t, tc.expectedText, res.Content[0].(*mcp.TextContent).Text)
+				return
+			}
+
+			link, ok := res.Content[0].(*mcp.ResourceLink)
+			require.True(t, ok, "spooled response should be returned as a resource link")
+			assert.True(t, strings.HasPrefix(link.URI, "spool://"))
+			assert.Equal(t, "application/json", link.MIMEType)
+			require.NotNil(t, link.Size)
+			assert.Equal(t, int64(len(large)), *link.Size)
+
+			file, chunk, err := sp.ReadChunk(strings.TrimPrefix(link.URI, "spool://"), 0, 0)
+			require.NoError(t, err)
+			assert.Equal(t, large, string(chunk.Data))
+			assert.Equal(t, file.Size, chunk.NextOffset)
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+)
+
+// maxStreamMessageSize is the largest single message accepted from a streamed response.
+const maxStreamMessageSize = 1024 * 1024
+
+// streamCollector accumulates the messages of a streamed response and forwards each one to the MCP
+// client as a progress notification if the client asked for progress, or as a log message otherwise,
+// which the client receives if it set a log level.
+type streamCollector struct {
+	req      *mcp.CallToolRequest
+	messages []string
+
+	// separator joins the messages in the final result, a newline unless the messages are raw chunks
+	// of the response
+	separator string
+}
+
+func newStreamCollector(req *mcp.CallToolRequest) *streamCollector {
+	return &streamCollector{req: req, separator: "\n"}
+}
+
+func (sc *streamCollector) add(ctx context.Context, message string) {
+	sc.messages = append(sc.messages, message)
+
+	if sc.req == nil || sc.req.Session == nil || sc.req.Params == nil {
+		return
+	}
+
+	progressToken := sc.req.Params.GetProgressToken()
+	if progressToken == nil {
+		err := sc.req.Session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "info",
+			Logger: sc.req.Params.Name,
+			Data:   message,
+		})
+		if err != nil {
+			logging.BaseFromContext(ctx).Warn("Failed to send log message for streamed message", zap.Error(err))
+		}
+		return
+	}
+
+	err := sc.req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: progressToken,
+		Message:       message,
+		Progress:      float64(len(sc.messages)),
+	})
+	if err != nil {
+		logging.BaseFromContext(ctx).Warn("Failed to send progress notification for streamed message", zap.Error(err))
+	}
+}
+
+// result returns the aggregated result of the stream: its messages joined by the separator.
+func (sc *streamCollector) result(isError bool) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: strings.Join(sc.messages, sc.separator),
+			},
+		},
+		IsError: isError,
+	}
+}
+
+// invokeStreaming executes a streaming tool invocation, either over a WebSocket or by reading
+// a chunked HTTP response incrementally.
+func (hi *HttpInvoker) invokeStreaming(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	url string,
+	body io.Reader,
+	hasBody bool,
+	headers nethttp.Header,
+) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	collector := newStreamCollector(req)
+
+	// the timeout bounds the whole stream, as messages are read incrementally
+	if hi.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hi.Timeout)
+		defer cancel()
+	}
+
+	streamCtx, span := startRequestSpan(ctx, hi.Method, url, 0)
+	var isError bool
+	var err error
+	if IsWebSocketURL(url) {
+		err = hi.streamWebSocket(streamCtx, url, body, headers, collector)
+	} else {
+		isError, err = hi.streamHTTP(streamCtx, url, body, hasBody, headers, collector)
+	}
+	if isError {
+		tracing.SetError(span, "backend responded with an error status")
+	}
+	span.SetAttributes(attribute.Int("genmcp.stream.message_count", len(collector.messages)))
+	tracing.End(span, err)
+	if err != nil {
+		if len(collector.messages) > 0 {
+			res := collector.result(true)
+			res.Content = append(res.Content, &mcp.TextContent{Text: fmt.Sprintf("stream interrupted: %v", err)})
+			invocation.SetErrorDetail(res, invocation.NewErrorDetail(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), 0))
+			return res, nil
+		}
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "HTTP request failed: %v", err), nil
+	}
+
+	logger.Info("HTTP streaming tool invocation completed successfully")
+
+	res := collector.result(isError)
+	if isError {
+		invocation.SetErrorDetail(res, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, 0))
+	}
+	return res, nil
+}
+
+// streamHTTP executes the HTTP request and splits the response body into messages using the
+// configured message framing, or the framing matching the content type of the response if none is
+// configured. It reports whether the backend responded with an error status.
+func (hi *HttpInvoker) streamHTTP(
+	ctx context.Context,
+	url string,
+	body io.Reader,
+	hasBody bool,
+	headers nethttp.Header,
+	collector *streamCollector,
+) (bool, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	logFields := []zap.Field{
+		zap.String("method", hi.Method),
+		zap.String("url", url),
+		zap.String("message_framing", hi.MessageFraming),
+	}
+
+	baseLogger.Debug("Executing streaming HTTP request", logFields...)
+
+	if hi.Signer != nil {
+		var bodyBytes []byte
+		if body != nil {
+			var err error
+			if bodyBytes, err = io.ReadAll(body); err != nil {
+				return false, fmt.Errorf("failed to read request body: %w", err)
+			}
+			body = bytes.NewReader(bodyBytes)
+		}
+		if err := hi.Signer.Sign(ctx, headers, hi.Method, url, bodyBytes); err != nil {
+			baseLogger.Error("Failed to sign HTTP request", append(logFields, zap.Error(err))...)
+			logger.Error("Failed to sign HTTP request")
+			return false, err
+		}
+	}
+
+	httpReq, err := nethttp.NewRequestWithContext(ctx, hi.Method, url, body)
+	if err != nil {
+		baseLogger.Error("Failed to create HTTP request", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to create HTTP request", zap.Error(err))
+		return false, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	httpReq.Header = headers
+	if hasBody {
+		httpReq.Header.Set(contentTypeHeader, "application/json; charset=UTF-8")
+	}
+	if (hi.MessageFraming == "" || hi.MessageFraming == MessageFramingSSE) && httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	tracing.Inject(ctx, httpReq.Header)
+
+	client, err := hi.httpClient(ctx)
+	if err != nil {
+		logger.Error("Failed to create HTTP client", zap.Error(err))
+		return false, fmt.Errorf("failed to create http client: %w", err)
+	}
+	response, err := client.Do(httpReq)
+	if err != nil {
+		baseLogger.Error("HTTP request execution failed", append(logFields, zap.Error(err))...)
+		logger.Error("HTTP request execution failed")
+		return false, err
+	}
+	defer func() {
+		if cerr := response.Body.Close(); cerr != nil {
+			baseLogger.Warn("Failed to close HTTP response body", append(logFields, zap.Error(cerr))...)
+		}
+	}()
+
+	// error responses are not streamed, the whole body is returned as a single message
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBody, readErr := io.ReadAll(response.Body)
+		if readErr != nil {
+			return true, readErr
+		}
+		baseLogger.Info("Streaming HTTP request failed with error status", append(logFields,
+			zap.Int("status_code", response.StatusCode))...)
+		collector.messages = append(collector.messages, string(responseBody))
+		return true, nil
+	}
+
+	messageFraming := hi.MessageFraming
+	if messageFraming == "" {
+		messageFraming = detectMessageFraming(response.Header.Get(contentTypeHeader))
+		logFields = append(logFields, zap.String("detected_message_framing", messageFraming))
+	}
+
+	switch messageFraming {
+	case MessageFramingLines:
+		err = readLineMessages(ctx, response.Body, collector)
+	case MessageFramingChunks:
+		collector.separator = ""
+		err = readChunkMessages(ctx, response.Body, collector)
+	default:
+		err = readSSEMessages(ctx, response.Body, collector)
+	}
+	if err != nil {
+		baseLogger.Error("Failed to read streamed HTTP response", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to read streamed HTTP response")
+		return false, err
+	}
+
+	baseLogger.Info("Streaming HTTP request completed", append(logFields,
+		zap.Int("status_code", response.StatusCode),
+		zap.Int("message_count", len(collector.messages)))...)
+
+	return false, nil
+}
+
+// streamWebSocket connects to a WebSocket endpoint, sends the request body (if any) as the
+// first message and then collects every message until the server closes the connection.
+func (hi *HttpInvoker) streamWebSocket(
+	ctx context.Context,
+	url string,
+	body io.Reader,
+	headers nethttp.Header,
+	collector *streamCollector,
+) error {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	logFields := []zap.Field{
+		zap.String("url", url),
+	}
+
+	baseLogger.Debug("Opening WebSocket connection", logFields...)
+
+	dialer := &websocket.Dialer{
+		Proxy: nethttp.ProxyFromEnvironment,
+	}
+
+	client, err := hi.httpClient(ctx)
+	if err != nil {
+		logger.Error("Failed to create HTTP client", zap.Error(err))
+		return fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	// reuse the TLS, proxy and dial settings of the configured HTTP client (e.g. custom CA certificates)
+	if transport, ok := client.Transport.(*nethttp.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+		dialer.Proxy = transport.Proxy
+		dialer.NetDialContext = transport.DialContext
+	}
+
+	tracing.Inject(ctx, headers)
+
+	conn, response, err := dialer.DialContext(ctx, url, headers)
+	if err != nil {
+		if response != nil {
+			logFields = append(logFields, zap.Int("status_code", response.StatusCode))
+		}
+		baseLogger.Error("Failed to open WebSocket connection", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to open WebSocket connection")
+		return fmt.Errorf("failed to open websocket connection: %w", err)
+	}
+	defer func() {
+		if cerr := conn.Close(); cerr != nil {
+			baseLogger.Debug("Failed to close WebSocket connection", append(logFields, zap.Error(cerr))...)
+		}
+	}()
+
+	conn.SetReadLimit(maxStreamMessageSize)
+
+	// unblock ReadMessage when the request is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	if body != nil {
+		payload, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			baseLogger.Error("Failed to send WebSocket message", append(logFields, zap.Error(err))...)
+			return fmt.Errorf("failed to send websocket message: %w", err)
+		}
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			baseLogger.Error("Failed to read WebSocket message", append(logFields, zap.Error(err))...)
+			logger.Error("Failed to read WebSocket message")
+			return fmt.Errorf("failed to read websocket message: %w", err)
+		}
+
+		collector.add(ctx, string(message))
+	}
+
+	baseLogger.Info("WebSocket stream completed", append(logFields,
+		zap.Int("message_count", len(collector.messages)))...)
+
+	return nil
+}
+
+// detectMessageFraming returns the message framing of a streamed response with the given content type:
+// lines for newline delimited JSON, chunks for plain text, and server-sent events otherwise.
+func detectMessageFraming(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/stream+json":
+		return MessageFramingLines
+	case "text/plain":
+		return MessageFramingChunks
+	default:
+		return MessageFramingSSE
+	}
+}
+
+// readChunkMessages treats every chunk of r as a message, as it is read. A chunk ending within a UTF-8
+// character is only sent with the rest of the character, so that every message is valid text.
+func readChunkMessages(ctx context.Context, r io.Reader, collector *streamCollector) error {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := append(pending, buf[:n]...)
+			complete := len(data)
+			for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(data[i]) {
+					if !utf8.FullRune(data[i:]) {
+						complete = i
+					}
+					break
+				}
+			}
+			if complete > 0 {
+				collector.add(ctx, string(data[:complete]))
+			}
+			pending = append([]byte(nil), data[complete:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pending) > 0 {
+		collector.add(ctx, string(pending))
+	}
+
+	return nil
+}
+
+// readLineMessages treats every non-empty line of r as a message.
+func readLineMessages(ctx context.Context, r io.Reader, collector *streamCollector) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamMessageSize)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		collector.add(ctx, line)
+	}
+
+	return scanner.Err()
+}
+
+// readSSEMessages parses r as a server-sent event stream, treating the data of every event as a message.
+// Multiple data lines of one event are joined with newlines, all other fields are ignored.
+func readSSEMessages(ctx context.Context, r io.Reader, collector *streamCollector) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamMessageSize)
+
+	var data []string
+	flush := func() {
+		if len(data) > 0 {
+			collector.add(ctx, strings.Join(data, "\n"))
+			data = nil
+		}
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			flush()
+			continue
+		}
+
+		// comment lines start with a colon
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		if field != "data" {
+			continue
+		}
+		data = append(data, strings.TrimPrefix(value, " "))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// the final event may not be terminated by a blank line
+	flush()
+
+	return nil
+}
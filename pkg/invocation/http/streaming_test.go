@@ -0,0 +1,314 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpStreamingInvocation(t *testing.T) {
+	tt := []struct {
+		name           string
+		messageFraming string
+		contentType    string
+		responseCode   int
+		responseBody   string
+		expectedResult *mcp.CallToolResult
+	}{
+		{
+			name:           "sse events",
+			messageFraming: MessageFramingSSE,
+			responseCode:   200,
+			responseBody:   ": keepalive\nevent: chunk\ndata: first\n\ndata: second\ndata: line\n\ndata: third",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "first\nsecond\nline\nthird"}},
+			},
+		},
+		{
+			name:           "newline delimited messages",
+			messageFraming: MessageFramingLines,
+			responseCode:   200,
+			responseBody:   "{\"n\":1}\n\n{\"n\":2}\r\n",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "{\"n\":1}\n{\"n\":2}"}},
+			},
+		},
+		{
+			name:           "chunks of the body",
+			messageFraming: MessageFramingChunks,
+			responseCode:   200,
+			responseBody:   "tailing app.log\nline 1\n",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "tailing app.log\nline 1\n"}},
+			},
+		},
+		{
+			name:         "framing of an ndjson response",
+			contentType:  "application/x-ndjson; charset=utf-8",
+			responseCode: 200,
+			responseBody: "{\"response\":\"Hel\"}\n{\"response\":\"lo\",\"done\":true}\n",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "{\"response\":\"Hel\"}\n{\"response\":\"lo\",\"done\":true}"}},
+			},
+		},
+		{
+			name:         "framing of a plain text response",
+			contentType:  "text/plain",
+			responseCode: 200,
+			responseBody: "data: not an event\n",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "data: not an event\n"}},
+			},
+		},
+		{
+			name:         "framing of an event stream",
+			contentType:  "text/event-stream",
+			responseCode: 200,
+			responseBody: "data: first\n\ndata: second\n\n",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "first\nsecond"}},
+			},
+		},
+		{
+			name:           "error status is not split into messages",
+			messageFraming: MessageFramingLines,
+			responseCode:   500,
+			responseBody:   "line one\nline two",
+			expectedResult: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "line one\nline two"}},
+				IsError: true,
+				Meta:    mcp.Meta{invocation.ErrorMetaKey: invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, 0)},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var receivedAccept string
+			s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				receivedAccept = r.Header.Get("Accept")
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.WriteHeader(tc.responseCode)
+				_, err := w.Write([]byte(tc.responseBody))
+				assert.NoError(t, err, "writing response should not fail")
+			}))
+			defer s.Close()
+
+			httpInvoker := testHttpInvoker(t, s.URL+"/stream", nil, resolvedEmpty, "GET", "")
+			httpInvoker.Streaming = true
+			httpInvoker.MessageFraming = tc.messageFraming
+
+			res, err := httpInvoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+			})
+			require.NoError(t, err, "streaming invocation should not return Go error")
+			assert.Equal(t, tc.expectedResult, res, "mcp tool call result should match")
+
+			if tc.messageFraming == MessageFramingSSE || tc.messageFraming == "" {
+				assert.Equal(t, "text/event-stream", receivedAccept, "sse requests should accept event streams")
+			}
+		})
+	}
+}
+
+func TestHttpStreamingInvocationWebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	var receivedMessage string
+	s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err, "websocket upgrade should not fail") {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		_, msg, err := conn.ReadMessage()
+		assert.NoError(t, err, "reading request message should not fail")
+		receivedMessage = string(msg)
+
+		for i := 1; i <= 3; i++ {
+			err := conn.WriteMessage(websocket.TextMessage, fmt.Appendf(nil, "chunk %d", i))
+			assert.NoError(t, err, "writing message should not fail")
+		}
+
+		err = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		assert.NoError(t, err, "closing websocket should not fail")
+	}))
+	defer s.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+	httpInvoker := testHttpInvoker(t, wsURL+"/ws", nil, resolvedWithPath, "POST", "")
+	httpInvoker.Streaming = true
+
+	res, err := httpInvoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"search":"foo"}`)},
+	})
+	require.NoError(t, err, "websocket invocation should not return Go error")
+
+	assert.JSONEq(t, `{"search":"foo"}`, receivedMessage, "request body should be sent as the first message")
+	assert.Equal(t, &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: "chunk 1\nchunk 2\nchunk 3"}},
+	}, res, "mcp tool call result should match")
+}
+
+// chunkedReader returns its chunks one Read at a time, like the body of a chunked response.
+type chunkedReader struct {
+	chunks [][]byte
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestReadChunkMessages(t *testing.T) {
+	tt := []struct {
+		name             string
+		chunks           []string
+		expectedMessages []string
+	}{
+		{
+			name:             "every chunk is a message",
+			chunks:           []string{"Hel", "lo ", "world"},
+			expectedMessages: []string{"Hel", "lo ", "world"},
+		},
+		{
+			name:             "character split across chunks",
+			chunks:           []string{"caf\xc3", "\xa9 cr\xc3", "\xa8me"},
+			expectedMessages: []string{"caf", "é cr", "ème"},
+		},
+		{
+			name:             "chunk within a character",
+			chunks:           []string{"\xe2", "\x82", "\xac"},
+			expectedMessages: []string{"€"},
+		},
+		{
+			name:             "truncated character at the end",
+			chunks:           []string{"ok\xe2\x82"},
+			expectedMessages: []string{"ok", "\xe2\x82"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &chunkedReader{}
+			for _, chunk := range tc.chunks {
+				r.chunks = append(r.chunks, []byte(chunk))
+			}
+
+			collector := newStreamCollector(nil)
+			require.NoError(t, readChunkMessages(context.Background(), r, collector))
+			assert.Equal(t, tc.expectedMessages, collector.messages)
+		})
+	}
+}
+
+func TestStreamCollectorNotifications(t *testing.T) {
+	tt := []struct {
+		name             string
+		progressToken    any
+		logLevel         mcp.LoggingLevel
+		expectedProgress []string
+		expectedLogs     []string
+	}{
+		{
+			name:             "progress notifications",
+			progressToken:    "call-1",
+			logLevel:         "info",
+			expectedProgress: []string{"first", "second"},
+		},
+		{
+			name:         "log messages without progress token",
+			logLevel:     "info",
+			expectedLogs: []string{"first", "second"},
+		},
+		{
+			name: "no log level",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+			server.AddTool(&mcp.Tool{Name: "tail_logs", InputSchema: &jsonschema.Schema{Type: "object"}},
+				func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					collector := newStreamCollector(req)
+					collector.add(ctx, "first")
+					collector.add(ctx, "second")
+					return collector.result(false), nil
+				})
+
+			var mu sync.Mutex
+			var progress, logs []string
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+				ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+					mu.Lock()
+					defer mu.Unlock()
+					progress = append(progress, req.Params.Message)
+				},
+				LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+					mu.Lock()
+					defer mu.Unlock()
+					assert.Equal(t, "tail_logs", req.Params.Logger)
+					logs = append(logs, req.Params.Data.(string))
+				},
+			})
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			serverSession, err := server.Connect(ctx, serverTransport, nil)
+			require.NoError(t, err)
+			defer func() { _ = serverSession.Close() }()
+			cs, err := client.Connect(ctx, clientTransport, nil)
+			require.NoError(t, err)
+			defer func() { _ = cs.Close() }()
+
+			if tc.logLevel != "" {
+				require.NoError(t, cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: tc.logLevel}))
+			}
+			params := &mcp.CallToolParams{Name: "tail_logs"}
+			if tc.progressToken != nil {
+				params.SetProgressToken(tc.progressToken)
+			}
+			res, err := cs.CallTool(ctx, params)
+			require.NoError(t, err)
+			assert.Equal(t, "first\nsecond", res.Content[0].(*mcp.TextContent).Text)
+
+			// notifications are sent before the result, but may be handled after it
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(progress) == len(tc.expectedProgress) && len(logs) == len(tc.expectedLogs)
+			}, 5*time.Second, 10*time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, tc.expectedProgress, progress)
+			assert.Equal(t, tc.expectedLogs, logs)
+		})
+	}
+}
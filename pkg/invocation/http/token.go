@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// parseClientSecret parses a client secret, which may reference secrets and the environment variables allowed
+// by allowlist.
+func parseClientSecret(clientSecret string, allowlist *template.Allowlist) (*template.ParsedTemplate, error) {
+	pt, err := template.ParseTemplate(clientSecret, template.TemplateParserOptions{
+		Sources:   map[string]template.SourceFactory{"secrets": template.NewSourceFactory("secrets")},
+		Allowlist: allowlist,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client secret template: %w", err)
+	}
+
+	return pt, nil
+}
+
+// resolveClientSecret renders a client secret parsed by parseClientSecret. It returns an empty string if
+// clientSecret is nil.
+func resolveClientSecret(ctx context.Context, clientSecret *template.ParsedTemplate) (string, error) {
+	if clientSecret == nil {
+		return "", nil
+	}
+
+	tb, err := template.NewTemplateBuilder(clientSecret, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create client secret builder: %w", err)
+	}
+	tb.SetSourceResolver("secrets", secrets.FromContext(ctx))
+
+	secret, err := tb.GetResult()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve client secret: %w", err)
+	}
+
+	return secret.(string), nil
+}
+
+// requestToken sends a token request with the parameters of form to the token endpoint tokenURL, and returns
+// the access token and its lifetime, zero if unknown. The client authenticates with clientAuthMethod if it
+// has a secret, and only sends its ID otherwise.
+func requestToken(
+	ctx context.Context,
+	tokenURL string,
+	form neturl.Values,
+	clientID string,
+	clientSecret string,
+	clientAuthMethod string,
+) (string, time.Duration, error) {
+	useBasicAuth := clientSecret != "" && clientAuthMethod != ClientAuthPost
+	if !useBasicAuth && clientID != "" {
+		form.Set("client_id", clientID)
+		if clientSecret != "" {
+			form.Set("client_secret", clientSecret)
+		}
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasicAuth {
+		req.SetBasicAuth(neturl.QueryEscape(clientID), neturl.QueryEscape(clientSecret))
+	}
+
+	resp, err := HTTPClientFromContext(ctx).Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tokenResponse struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &tokenResponse)
+
+	if resp.StatusCode != nethttp.StatusOK {
+		if tokenResponse.Error != "" {
+			if tokenResponse.ErrorDescription != "" {
+				return "", 0, fmt.Errorf("%s: %s (status %d)", tokenResponse.Error, tokenResponse.ErrorDescription, resp.StatusCode)
+			}
+			return "", 0, fmt.Errorf("%s (status %d)", tokenResponse.Error, resp.StatusCode)
+		}
+		return "", 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if tokenResponse.AccessToken == "" {
+		return "", 0, fmt.Errorf("no access token returned")
+	}
+
+	return tokenResponse.AccessToken, time.Duration(tokenResponse.ExpiresIn) * time.Second, nil
+}
+
+// refreshAt returns when a token obtained now with a lifetime of expiresIn should be renewed: a little
+// before it expires.
+func refreshAt(now time.Time, expiresIn time.Duration) time.Time {
+	return now.Add(expiresIn - expiresIn/10)
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHttpInvocationTracing(t *testing.T) {
+	tt := []struct {
+		name          string
+		responses     []int
+		retry         *RetryConfig
+		expectedSpans []string
+	}{
+		{
+			name:          "single request",
+			responses:     []int{200},
+			expectedSpans: []string{"build http request", "GET"},
+		},
+		{
+			name:          "retried request",
+			responses:     []int{503, 200},
+			retry:         &RetryConfig{MaxRetries: 1, InitialDelay: "1ms"},
+			expectedSpans: []string{"build http request", "GET", "GET"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			prevTP, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+			t.Cleanup(func() {
+				otel.SetTracerProvider(prevTP)
+				otel.SetTextMapPropagator(prevPropagator)
+			})
+
+			var attempts atomic.Int32
+			var traceparents []string
+			s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				n := attempts.Add(1)
+				traceparents = append(traceparents, r.Header.Get("traceparent"))
+				w.WriteHeader(tc.responses[min(int(n), len(tc.responses))-1])
+			}))
+			defer s.Close()
+
+			httpInvoker := testHttpInvoker(t, s.URL+"/traced", nil, resolvedWithPath, "GET", "")
+			retry, err := NewRetryPolicy(tc.retry)
+			require.NoError(t, err, "creating the retry policy should not fail")
+			httpInvoker.Retry = retry
+
+			ctx, root := tp.Tracer("test").Start(context.Background(), "tools/call")
+			_, err = httpInvoker.Invoke(ctx, &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"search":"foo"}`)},
+			})
+			root.End()
+			require.NoError(t, err, "invocation should not return Go error")
+
+			var names []string
+			var requestSpans []sdktrace.ReadOnlySpan
+			for _, span := range recorder.Ended() {
+				if span.Name() == "tools/call" {
+					continue
+				}
+				names = append(names, span.Name())
+				assert.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID(), "spans should be part of the tool call trace")
+				if span.SpanKind() == trace.SpanKindClient {
+					requestSpans = append(requestSpans, span)
+				}
+			}
+			assert.Equal(t, tc.expectedSpans, names, "recorded spans should match")
+
+			require.Len(t, traceparents, len(requestSpans), "every attempt should have a request span")
+			for i, span := range requestSpans {
+				expected := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+				assert.Equal(t, expected, traceparents[i], "traceparent header should reference the request span")
+				assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tc.responses[i]), "status code should be recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package inline
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// InlineInvocationConfig is the configuration for prompts whose messages are defined in the MCP file, and
+// rendered from the arguments of the prompt without calling a backend, and for static resources whose
+// content is embedded in the MCP file or read from a local file.
+// Exactly one of messages, text, blob and file is required.
+type InlineInvocationConfig struct {
+	// The messages returned by the prompt, in order. Only supported by prompts.
+	Messages []*MessageConfig `json:"messages,omitempty" jsonschema:"optional"`
+
+	// The text content of the resource. Only supported by resources.
+	Text string `json:"text,omitempty" jsonschema:"optional"`
+
+	// The binary content of the resource, base64 encoded. Only supported by resources.
+	Blob string `json:"blob,omitempty" jsonschema:"optional"`
+
+	// The path of the file holding the content of the resource, e.g. a file packaged into the image of the
+	// server. Relative paths are resolved in the working directory of the server. The file is read when the
+	// resource is registered. Only supported by resources.
+	File string `json:"file,omitempty" jsonschema:"optional"`
+
+	// The MIME type of the content of the resource. Detected from the file extension and content if unset.
+	MIMEType string `json:"mimeType,omitempty" jsonschema:"optional"`
+}
+
+// MessageConfig is a message of a prompt with an inline invocation.
+type MessageConfig struct {
+	// The role of the sender of the message (default: user).
+	Role string `json:"role,omitempty" jsonschema:"optional,enum=user,enum=assistant"`
+
+	// The text of the message. It can contain placeholders in the form of '{paramName}' which correspond to
+	// arguments of the prompt, conditional blocks, template functions, '{headers.Name}' and '${VAR_NAME}'.
+	Text string `json:"text" jsonschema:"required"`
+}
+
+var _ invocation.InvocationConfig = &InlineInvocationConfig{}
+var _ invocation.FileReferencer = &InlineInvocationConfig{}
+
+func (c *InlineInvocationConfig) Validate() error {
+	set := 0
+	for _, isSet := range []bool{len(c.Messages) > 0, c.Text != "", c.Blob != "", c.File != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of messages, text, blob or file is required")
+	}
+
+	if c.Blob != "" {
+		if _, err := base64.StdEncoding.DecodeString(c.Blob); err != nil {
+			return fmt.Errorf("blob is not valid base64: %w", err)
+		}
+	}
+
+	if len(c.Messages) == 0 {
+		return nil
+	}
+
+	if c.MIMEType != "" {
+		return fmt.Errorf("mimeType can only be set for resources")
+	}
+
+	var err error = nil
+	for i, m := range c.Messages {
+		if m == nil {
+			err = errors.Join(err, fmt.Errorf("messages[%d] is empty", i))
+			continue
+		}
+		if m.Role != "" && m.Role != RoleUser && m.Role != RoleAssistant {
+			err = errors.Join(err, fmt.Errorf("messages[%d] role must be one of (%s, %s), received %s", i, RoleUser, RoleAssistant, m.Role))
+		}
+		if strings.TrimSpace(m.Text) == "" {
+			err = errors.Join(err, fmt.Errorf("messages[%d] text is required", i))
+		}
+	}
+
+	return err
+}
+
+func (c *InlineInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	var messages []*MessageConfig
+	if c.Messages != nil {
+		messages = make([]*MessageConfig, len(c.Messages))
+		for i, m := range c.Messages {
+			if m != nil {
+				messages[i] = &MessageConfig{Role: m.Role, Text: m.Text}
+			}
+		}
+	}
+	return &InlineInvocationConfig{
+		Messages: messages,
+		Text:     c.Text,
+		Blob:     c.Blob,
+		File:     c.File,
+		MIMEType: c.MIMEType,
+	}
+}
+
+// ReferencedFiles returns the file holding the content of the resource, if any.
+func (c *InlineInvocationConfig) ReferencedFiles() []string {
+	if c.File == "" {
+		return nil
+	}
+	return []string{c.File}
+}
+
+// role returns the role of the message, defaulting to user.
+func (m *MessageConfig) role() string {
+	if m.Role == "" {
+		return RoleUser
+	}
+	return m.Role
+}
@@ -0,0 +1,82 @@
+package inline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *InlineInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "valid messages",
+			config: &InlineInvocationConfig{Messages: []*MessageConfig{
+				{Text: "Review {code}"},
+				{Role: RoleAssistant, Text: "Sure, here is my review."},
+				{Role: RoleUser, Text: "Thanks"},
+			}},
+		},
+		{
+			name:   "valid text",
+			config: &InlineInvocationConfig{Text: "# Readme", MIMEType: "text/markdown"},
+		},
+		{
+			name:   "valid blob",
+			config: &InlineInvocationConfig{Blob: "iVBORw0KGgo=", MIMEType: "image/png"},
+		},
+		{
+			name:   "valid file",
+			config: &InlineInvocationConfig{File: "schemas/user.json"},
+		},
+		{
+			name:          "no content",
+			config:        &InlineInvocationConfig{},
+			expectedError: "exactly one of messages, text, blob or file is required",
+		},
+		{
+			name:          "text and file",
+			config:        &InlineInvocationConfig{Text: "# Readme", File: "README.md"},
+			expectedError: "exactly one of messages, text, blob or file is required",
+		},
+		{
+			name:          "invalid blob",
+			config:        &InlineInvocationConfig{Blob: "not base64!"},
+			expectedError: "blob is not valid base64",
+		},
+		{
+			name:          "messages with mimeType",
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "Hello"}}, MIMEType: "text/plain"},
+			expectedError: "mimeType can only be set for resources",
+		},
+		{
+			name:          "empty message",
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "Hello"}, nil}},
+			expectedError: "messages[1] is empty",
+		},
+		{
+			name:          "invalid role",
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Role: "system", Text: "Hello"}}},
+			expectedError: "messages[0] role must be one of (user, assistant), received system",
+		},
+		{
+			name:          "empty text",
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "  "}}},
+			expectedError: "messages[0] text is required",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package inline
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &InlineInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	iic, ok := config.(*InlineInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for inline invoker factory")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for inline invocations")
+	}
+
+	if len(iic.Messages) == 0 {
+		if primitive.PrimitiveType() != "resource" {
+			return nil, fmt.Errorf("inline text, blob and file are only supported for resources")
+		}
+
+		content, err := resourceContent(iic)
+		if err != nil {
+			return nil, err
+		}
+		return &InlineInvoker{Content: content}, nil
+	}
+
+	if primitive.PrimitiveType() != "prompt" {
+		return nil, fmt.Errorf("inline messages are only supported for prompts")
+	}
+
+	messages := make([]*message, 0, len(iic.Messages))
+	for i, m := range iic.Messages {
+		// secrets are not available, as the messages are returned to the client
+		parsed, err := template.ParseTemplate(m.Text, template.TemplateParserOptions{
+			InputSchema: primitive.GetInputSchema(),
+			Sources:     template.CreateHeadersSourceFactory(),
+			Allowlist:   template.PrimitiveAllowlist(primitive),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse text template of messages[%d]: %w", i, err)
+		}
+
+		messages = append(messages, &message{role: m.role(), text: parsed})
+	}
+
+	return &InlineInvoker{
+		Description: primitive.GetDescription(),
+		Messages:    messages,
+		InputSchema: primitive.GetResolvedInputSchema(),
+	}, nil
+}
+
+// resourceContent returns the content of a static resource, embedded in the config or read from its file.
+func resourceContent(iic *InlineInvocationConfig) (*content, error) {
+	switch {
+	case iic.Text != "":
+		return &content{data: []byte(iic.Text), text: true, mimeType: mimeTypeOr(iic.MIMEType, "text/plain")}, nil
+	case iic.Blob != "":
+		data, err := base64.StdEncoding.DecodeString(iic.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("blob is not valid base64: %w", err)
+		}
+		return &content{data: data, mimeType: mimeTypeOr(iic.MIMEType, nethttp.DetectContentType(data))}, nil
+	}
+
+	data, err := os.ReadFile(iic.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	mimeType := iic.MIMEType
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(iic.File))
+	}
+	if mimeType == "" {
+		mimeType = nethttp.DetectContentType(data)
+	}
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil && iic.MIMEType == "" {
+		mimeType = mediaType
+	}
+
+	return &content{data: data, text: utf8.Valid(data), mimeType: mimeType}, nil
+}
+
+// mimeTypeOr returns mimeType, or def if it is unset, without parameters.
+func mimeTypeOr(mimeType, def string) string {
+	if mimeType != "" {
+		return mimeType
+	}
+	if mediaType, _, err := mime.ParseMediaType(def); err == nil {
+		return mediaType
+	}
+	return def
+}
@@ -0,0 +1,11 @@
+package inline
+
+import "github.com/genmcp/gen-mcp/pkg/invocation"
+
+const (
+	InvocationType = "inline"
+)
+
+func init() {
+	invocation.RegisterFactory(InvocationType, &InvokerFactory{})
+}
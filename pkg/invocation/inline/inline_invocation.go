@@ -0,0 +1,131 @@
+package inline
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+type InlineInvoker struct {
+	Description string               // Description of the prompt, returned with its messages
+	Messages    []*message           // Messages of the prompt, with their parsed text templates
+	InputSchema *jsonschema.Resolved // InputSchema for the prompt
+	Content     *content             // Content of the static resource (for resources only)
+}
+
+// message is a message of a prompt, whose text is rendered from the arguments of the prompt.
+type message struct {
+	role string
+	text *template.ParsedTemplate
+}
+
+// content is the content of a static resource.
+type content struct {
+	data     []byte
+	text     bool
+	mimeType string
+}
+
+var _ invocation.Invoker = &InlineInvoker{}
+
+func (ii *InlineInvoker) Invoke(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return nil, fmt.Errorf("inline invocations are only supported for prompts and resources")
+}
+
+func (ii *InlineInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting inline prompt invocation")
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	argsForValidation := make(map[string]any, len(req.Params.Arguments))
+	for argName, argValue := range req.Params.Arguments {
+		argsForValidation[argName] = argValue
+	}
+	if ii.InputSchema != nil {
+		if err := ii.InputSchema.Validate(argsForValidation); err != nil {
+			logger.Error("Failed to validate prompt request arguments", zap.Error(err))
+			return nil, fmt.Errorf("failed to validate prompt request: %w", err)
+		}
+	}
+
+	messages := make([]*mcp.PromptMessage, 0, len(ii.Messages))
+	for i, m := range ii.Messages {
+		text, err := ii.render(m, req.Params.Arguments, incomingHeaders)
+		if err != nil {
+			logger.Error("Failed to render prompt message", zap.Int("message", i), zap.Error(err))
+			return nil, fmt.Errorf("failed to render messages[%d]: %w", i, err)
+		}
+
+		messages = append(messages, &mcp.PromptMessage{
+			Role:    mcp.Role(m.role),
+			Content: &mcp.TextContent{Text: text},
+		})
+	}
+
+	logger.Info("Inline prompt invocation completed successfully")
+
+	return &mcp.GetPromptResult{
+		Description: ii.Description,
+		Messages:    messages,
+	}, nil
+}
+
+// render renders the text of a message with the arguments of the prompt.
+// A new builder is created for each invocation to avoid sharing state.
+func (ii *InlineInvoker) render(m *message, arguments map[string]string, incomingHeaders nethttp.Header) (string, error) {
+	builder, err := template.NewTemplateBuilder(m.text, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create text builder: %w", err)
+	}
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	for argName, argValue := range arguments {
+		builder.SetField(argName, argValue)
+	}
+
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+
+	return result.(string), nil
+}
+
+func (ii *InlineInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	if ii.Content == nil {
+		return nil, fmt.Errorf("inline messages are only supported for prompts")
+	}
+
+	// the contents are built for each read, as the server may truncate them
+	contents := &mcp.ResourceContents{URI: req.Params.URI, MIMEType: ii.Content.mimeType}
+	if ii.Content.text {
+		contents.Text = string(ii.Content.data)
+	} else {
+		contents.Blob = ii.Content.data
+	}
+
+	logging.FromContext(ctx).Debug("Inline resource read", zap.String("uri", req.Params.URI))
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{contents},
+	}, nil
+}
+
+func (ii *InlineInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("inline invocations are only supported for prompts and resources")
+}
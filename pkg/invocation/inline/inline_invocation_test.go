@@ -0,0 +1,203 @@
+package inline
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader is the start of a PNG file, which is not valid UTF-8.
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, 0x00, 0x0d}
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"language": {Type: invocation.JsonSchemaTypeString},
+		"code":     {Type: invocation.JsonSchemaTypeString},
+		"focus":    {Type: invocation.JsonSchemaTypeString},
+	},
+	Required: []string{"code"},
+}
+
+func testPrompt(t *testing.T) *definitions.Prompt {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+
+	return &definitions.Prompt{
+		Name:                "review",
+		Description:         "Review code",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+}
+
+func TestInlineInvokerInvokePrompt(t *testing.T) {
+	config := &InlineInvocationConfig{Messages: []*MessageConfig{
+		{Text: "Review this {language|default:code}:\n{code}{?focus}\nFocus on {focus}.{/focus}"},
+		{Role: RoleAssistant, Text: "I will review it for {headers.X-User}."},
+	}}
+
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, testPrompt(t))
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		arguments     map[string]string
+		expected      []*mcp.PromptMessage
+		expectedError string
+	}{
+		{
+			name:      "all arguments",
+			arguments: map[string]string{"language": "Go", "code": "func main() {}", "focus": "naming"},
+			expected: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: "Review this Go:\nfunc main() {}\nFocus on naming."}},
+				{Role: "assistant", Content: &mcp.TextContent{Text: "I will review it for alice."}},
+			},
+		},
+		{
+			name:      "optional arguments not set",
+			arguments: map[string]string{"code": "print()"},
+			expected: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: "Review this code:\nprint()"}},
+				{Role: "assistant", Content: &mcp.TextContent{Text: "I will review it for alice."}},
+			},
+		},
+		{
+			name:          "required argument not set",
+			arguments:     map[string]string{"language": "Go"},
+			expectedError: "failed to validate prompt request",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := invoker.InvokePrompt(context.Background(), &mcp.GetPromptRequest{
+				Params: &mcp.GetPromptParams{Name: "review", Arguments: tc.arguments},
+				Extra:  &mcp.RequestExtra{Header: http.Header{"X-User": []string{"alice"}}},
+			})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Review code", result.Description)
+			assert.Equal(t, tc.expected, result.Messages)
+		})
+	}
+}
+
+func TestInlineInvokerInvokeResource(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"type": "object"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo"), pngHeader, 0o644))
+
+	tt := []struct {
+		name     string
+		config   *InlineInvocationConfig
+		expected *mcp.ResourceContents
+	}{
+		{
+			name:     "text",
+			config:   &InlineInvocationConfig{Text: "# Readme"},
+			expected: &mcp.ResourceContents{URI: "docs://readme", MIMEType: "text/plain", Text: "# Readme"},
+		},
+		{
+			name:     "text with mimeType",
+			config:   &InlineInvocationConfig{Text: "# Readme", MIMEType: "text/markdown"},
+			expected: &mcp.ResourceContents{URI: "docs://readme", MIMEType: "text/markdown", Text: "# Readme"},
+		},
+		{
+			name:     "blob",
+			config:   &InlineInvocationConfig{Blob: base64.StdEncoding.EncodeToString(pngHeader)},
+			expected: &mcp.ResourceContents{URI: "docs://readme", MIMEType: "image/png", Blob: pngHeader},
+		},
+		{
+			name:     "text file",
+			config:   &InlineInvocationConfig{File: filepath.Join(dir, "user.json")},
+			expected: &mcp.ResourceContents{URI: "docs://readme", MIMEType: "application/json", Text: `{"type": "object"}`},
+		},
+		{
+			name:     "binary file",
+			config:   &InlineInvocationConfig{File: filepath.Join(dir, "logo")},
+			expected: &mcp.ResourceContents{URI: "docs://readme", MIMEType: "image/png", Blob: pngHeader},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker, err := (&InvokerFactory{}).CreateInvoker(tc.config, &definitions.Resource{Name: "readme", URI: "docs://readme"})
+			require.NoError(t, err)
+
+			result, err := invoker.InvokeResource(context.Background(), &mcp.ReadResourceRequest{
+				Params: &mcp.ReadResourceParams{URI: "docs://readme"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []*mcp.ResourceContents{tc.expected}, result.Contents)
+		})
+	}
+}
+
+func TestInvokerFactoryErrors(t *testing.T) {
+	tt := []struct {
+		name          string
+		primitive     invocation.Primitive
+		config        *InlineInvocationConfig
+		expectedError string
+	}{
+		{
+			name:          "messages of tool",
+			primitive:     &definitions.Tool{Name: "review", InputSchema: testSchema},
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "Hello"}}},
+			expectedError: "inline messages are only supported for prompts",
+		},
+		{
+			name:          "text of prompt",
+			primitive:     testPrompt(t),
+			config:        &InlineInvocationConfig{Text: "Hello"},
+			expectedError: "inline text, blob and file are only supported for resources",
+		},
+		{
+			name:          "text of resource template",
+			primitive:     &definitions.ResourceTemplate{Name: "docs", URITemplate: "docs://{page}"},
+			config:        &InlineInvocationConfig{Text: "Hello"},
+			expectedError: "inline text, blob and file are only supported for resources",
+		},
+		{
+			name:          "missing file",
+			primitive:     &definitions.Resource{Name: "readme", URI: "docs://readme"},
+			config:        &InlineInvocationConfig{File: filepath.Join(t.TempDir(), "README.md")},
+			expectedError: "failed to read file",
+		},
+		{
+			name:          "unknown argument",
+			primitive:     testPrompt(t),
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "Hello"}, {Text: "Review {file}"}}},
+			expectedError: "failed to parse text template of messages[1]",
+		},
+		{
+			name:          "secrets",
+			primitive:     testPrompt(t),
+			config:        &InlineInvocationConfig{Messages: []*MessageConfig{{Text: "Token {secrets.TOKEN}"}}},
+			expectedError: "failed to parse text template of messages[0]",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&InvokerFactory{}).CreateInvoker(tc.config, tc.primitive)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// serviceAccountDir is the directory the credentials of the service account of a pod are mounted in.
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// client is a client of the Kubernetes API, authenticated with a kubeconfig file or the service account of the pod.
+type client struct {
+	server     string
+	httpClient *http.Client
+	token      string
+	tokenFile  string // read for every request, as service account tokens are rotated
+	username   string
+	password   string
+}
+
+// kubeconfig is the subset of a kubeconfig file needed to connect to a cluster.
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string      `json:"name"`
+		Cluster kubeCluster `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster string `json:"cluster"`
+			User    string `json:"user"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Users []struct {
+		Name string   `json:"name"`
+		User kubeUser `json:"user"`
+	} `json:"users"`
+}
+
+type kubeCluster struct {
+	Server                   string `json:"server"`
+	CertificateAuthority     string `json:"certificate-authority"`
+	CertificateAuthorityData []byte `json:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+	TLSServerName            string `json:"tls-server-name"`
+}
+
+type kubeUser struct {
+	Token                 string         `json:"token"`
+	TokenFile             string         `json:"tokenFile"`
+	ClientCertificate     string         `json:"client-certificate"`
+	ClientCertificateData []byte         `json:"client-certificate-data"`
+	ClientKey             string         `json:"client-key"`
+	ClientKeyData         []byte         `json:"client-key-data"`
+	Username              string         `json:"username"`
+	Password              string         `json:"password"`
+	Exec                  map[string]any `json:"exec"`
+	AuthProvider          map[string]any `json:"auth-provider"`
+}
+
+// newClient creates a client authenticated with the kubeconfig file at path, or the default credentials if path
+// is empty: the service account of the pod when running in a cluster, and the default kubeconfig file otherwise.
+// Its connections are dialed with the transport of base, which enforces the network policy of the server.
+func newClient(path, contextName string, base *http.Client) (*client, error) {
+	if path == "" {
+		if os.Getenv("KUBERNETES_SERVICE_HOST") != "" && contextName == "" {
+			return newInClusterClient(base)
+		}
+		path = defaultKubeconfigPath()
+	}
+
+	return newKubeconfigClient(path, contextName, base)
+}
+
+// defaultKubeconfigPath returns the first file of the KUBECONFIG environment variable, or ~/.kube/config.
+func defaultKubeconfigPath() string {
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if path != "" {
+			return path
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kube", "config")
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+func newInClusterClient(base *http.Client) (*client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if port == "" {
+		port = "443"
+	}
+
+	caData, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA certificate of the service account: %w", err)
+	}
+	tlsConfig, err := newTLSConfig(caData, false, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		server:     "https://" + net.JoinHostPort(host, port),
+		httpClient: newHTTPClient(tlsConfig, base),
+		tokenFile:  filepath.Join(serviceAccountDir, "token"),
+	}, nil
+}
+
+// errUnsupportedCredentials is returned for the users of kubeconfig files authenticating with exec plugins or
+// auth providers, which run commands or depend on the plugins of kubectl.
+var errUnsupportedCredentials = errors.New("exec and auth-provider credentials are not supported")
+
+// checkCredentials returns an error if the credentials newClient would load for path and contextName are not
+// supported. It is called when the tools are validated, so that they are rejected before their first call. The
+// other errors are only returned when the client is created, as the kubeconfig file may only be present where
+// the server runs.
+func checkCredentials(path, contextName string) error {
+	if path == "" {
+		if os.Getenv("KUBERNETES_SERVICE_HOST") != "" && contextName == "" {
+			return nil
+		}
+		path = defaultKubeconfigPath()
+	}
+
+	if _, _, err := readKubeconfig(path, contextName); errors.Is(err, errUnsupportedCredentials) {
+		return err
+	}
+	return nil
+}
+
+func newKubeconfigClient(path, contextName string, base *http.Client) (*client, error) {
+	cluster, user, err := readKubeconfig(path, contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	// relative paths of kubeconfig files are relative to the file
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	caData, err := readData(cluster.CertificateAuthorityData, resolve(cluster.CertificateAuthority))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+	tlsConfig, err := newTLSConfig(caData, cluster.InsecureSkipTLSVerify, cluster.TLSServerName)
+	if err != nil {
+		return nil, err
+	}
+
+	certData, err := readData(user.ClientCertificateData, resolve(user.ClientCertificate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyData, err := readData(user.ClientKeyData, resolve(user.ClientKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+	if certData != nil || keyData != nil {
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &client{
+		server:     strings.TrimSuffix(cluster.Server, "/"),
+		httpClient: newHTTPClient(tlsConfig, base),
+		token:      user.Token,
+		tokenFile:  resolve(user.TokenFile),
+		username:   user.Username,
+		password:   user.Password,
+	}, nil
+}
+
+// readKubeconfig returns the cluster and the user of the context of the kubeconfig file at path, or of its
+// current context if contextName is empty.
+func readKubeconfig(path, contextName string) (*kubeCluster, kubeUser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, kubeUser{}, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	var config kubeconfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, kubeUser{}, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range config.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return nil, kubeUser{}, fmt.Errorf("context '%s' not found in kubeconfig %s", contextName, path)
+	}
+
+	var cluster *kubeCluster
+	for i := range config.Clusters {
+		if config.Clusters[i].Name == clusterName {
+			cluster = &config.Clusters[i].Cluster
+			break
+		}
+	}
+	if cluster == nil || cluster.Server == "" {
+		return nil, kubeUser{}, fmt.Errorf("cluster '%s' not found in kubeconfig %s", clusterName, path)
+	}
+
+	var user kubeUser
+	for _, u := range config.Users {
+		if u.Name == userName {
+			user = u.User
+			break
+		}
+	}
+	if user.Exec != nil || user.AuthProvider != nil {
+		return nil, kubeUser{}, fmt.Errorf("user '%s' of kubeconfig %s: %w", userName, path, errUnsupportedCredentials)
+	}
+
+	return cluster, user, nil
+}
+
+// readData returns data if set, and the content of the file at path otherwise.
+func readData(data []byte, path string) ([]byte, error) {
+	if len(data) > 0 || path == "" {
+		return data, nil
+	}
+	return os.ReadFile(path)
+}
+
+func newTLSConfig(caData []byte, insecure bool, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: insecure, //nolint:gosec // User explicitly requested insecure mode in the kubeconfig
+	}
+
+	if len(caData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("invalid certificate authority: no PEM certificate found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// newHTTPClient returns a client verifying the API server with tlsConfig, and reusing the dial and proxy settings
+// of the transport of base.
+func newHTTPClient(tlsConfig *tls.Config, base *http.Client) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if baseTransport, ok := base.Transport.(*http.Transport); ok {
+		transport.DialContext = baseTransport.DialContext
+		transport.Proxy = baseTransport.Proxy
+	}
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}
+}
+
+// do sends a request to the Kubernetes API, and returns the status code and body of the response.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (int, []byte, error) {
+	u := c.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, respBody, nil
+}
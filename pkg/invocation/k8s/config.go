@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbCreate = "create"
+	VerbPatch  = "patch"
+
+	PatchTypeMerge     = "merge"
+	PatchTypeStrategic = "strategic"
+	PatchTypeJSON      = "json"
+
+	// DefaultBodyProperty is the input schema property holding the body of create and patch operations.
+	DefaultBodyProperty = "body"
+
+	// DefaultTimeout is the maximum duration of an invocation if not configured.
+	DefaultTimeout = 30 * time.Second
+)
+
+var validVerbs = map[string]bool{
+	VerbGet:    true,
+	VerbList:   true,
+	VerbCreate: true,
+	VerbPatch:  true,
+}
+
+var validPatchTypes = map[string]bool{
+	PatchTypeMerge:     true,
+	PatchTypeStrategic: true,
+	PatchTypeJSON:      true,
+}
+
+// K8sInvocationConfig is the configuration for performing an operation on a resource of the Kubernetes API.
+// This is a pure data structure with no parsing logic - all struct tags only.
+type K8sInvocationConfig struct {
+	// The path of the kubeconfig file holding the credentials. Defaults to the service account of the pod when running
+	// in a cluster, and to the file of the KUBECONFIG environment variable or '~/.kube/config' otherwise.
+	// It can reference environment variables using '${VAR_NAME}' syntax.
+	Kubeconfig string `json:"kubeconfig,omitempty" jsonschema:"optional"`
+
+	// The context of the kubeconfig file to use (default: the current context).
+	Context string `json:"context,omitempty" jsonschema:"optional"`
+
+	// The API group of the resource, e.g. 'apps'. Empty for the core group.
+	Group string `json:"group,omitempty" jsonschema:"optional"`
+
+	// The API version of the resource, e.g. 'v1'.
+	Version string `json:"version" jsonschema:"required"`
+
+	// The resource, as the lowercase plural of its kind, e.g. 'deployments'.
+	Resource string `json:"resource" jsonschema:"required"`
+
+	// The operation performed on the resource.
+	Verb string `json:"verb" jsonschema:"required,enum=get,enum=list,enum=create,enum=patch"`
+
+	// The namespace of the resource. Empty for cluster-scoped resources, or to list the resources of all namespaces.
+	// It can contain placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema.
+	Namespace string `json:"namespace,omitempty" jsonschema:"optional"`
+
+	// The namespaces the tool can access. If set, operations on other namespaces, or on all namespaces, are refused.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty" jsonschema:"optional"`
+
+	// The name of the resource, for get and patch operations.
+	// It can contain placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema.
+	Name string `json:"name,omitempty" jsonschema:"optional"`
+
+	// The label selector of list operations, e.g. 'app={app}'.
+	LabelSelector string `json:"labelSelector,omitempty" jsonschema:"optional"`
+
+	// The field selector of list operations, e.g. 'status.phase=Running'.
+	FieldSelector string `json:"fieldSelector,omitempty" jsonschema:"optional"`
+
+	// The input schema property holding the resource created, or the patch applied, for create and patch operations (default: body).
+	BodyProperty string `json:"bodyProperty,omitempty" jsonschema:"optional"`
+
+	// The type of the patch, for patch operations (default: merge).
+	PatchType string `json:"patchType,omitempty" jsonschema:"optional,enum=merge,enum=strategic,enum=json"`
+
+	// Maximum duration of the invocation, as a duration string (default: 30s).
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &K8sInvocationConfig{}
+
+func (c *K8sInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Version) == "" {
+		return fmt.Errorf("version is required")
+	}
+
+	if strings.TrimSpace(c.Resource) == "" {
+		return fmt.Errorf("resource is required")
+	}
+
+	for _, segment := range []string{c.Group, c.Version, c.Resource} {
+		if strings.ContainsAny(segment, "/ ") {
+			return fmt.Errorf("invalid API path segment '%s': group, version and resource must not contain '/' or spaces", segment)
+		}
+	}
+
+	if !validVerbs[c.Verb] {
+		return fmt.Errorf("invalid verb '%s': must be one of get, list, create, patch", c.Verb)
+	}
+
+	switch c.Verb {
+	case VerbGet, VerbPatch:
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("name is required for %s operations", c.Verb)
+		}
+	case VerbList, VerbCreate:
+		if c.Name != "" {
+			return fmt.Errorf("name can only be set for get and patch operations")
+		}
+	}
+
+	if c.Verb != VerbList && (c.LabelSelector != "" || c.FieldSelector != "") {
+		return fmt.Errorf("labelSelector and fieldSelector can only be set for list operations")
+	}
+
+	if c.Verb != VerbCreate && c.Verb != VerbPatch && c.BodyProperty != "" {
+		return fmt.Errorf("bodyProperty can only be set for create and patch operations")
+	}
+
+	if c.PatchType != "" {
+		if c.Verb != VerbPatch {
+			return fmt.Errorf("patchType can only be set for patch operations")
+		}
+		if !validPatchTypes[c.PatchType] {
+			return fmt.Errorf("invalid patchType '%s': must be one of merge, strategic, json", c.PatchType)
+		}
+	}
+
+	for _, ns := range c.AllowedNamespaces {
+		if strings.TrimSpace(ns) == "" {
+			return fmt.Errorf("allowedNamespaces must not contain empty namespaces")
+		}
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+func (c *K8sInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	var allowedNamespaces []string
+	if c.AllowedNamespaces != nil {
+		allowedNamespaces = make([]string, len(c.AllowedNamespaces))
+		copy(allowedNamespaces, c.AllowedNamespaces)
+	}
+
+	return &K8sInvocationConfig{
+		Kubeconfig:        c.Kubeconfig,
+		Context:           c.Context,
+		Group:             c.Group,
+		Version:           c.Version,
+		Resource:          c.Resource,
+		Verb:              c.Verb,
+		Namespace:         c.Namespace,
+		AllowedNamespaces: allowedNamespaces,
+		Name:              c.Name,
+		LabelSelector:     c.LabelSelector,
+		FieldSelector:     c.FieldSelector,
+		BodyProperty:      c.BodyProperty,
+		PatchType:         c.PatchType,
+		Timeout:           c.Timeout,
+	}
+}
+
+// bodyProperty returns the input schema property holding the body of the operation, defaulting to
+// DefaultBodyProperty.
+func (c *K8sInvocationConfig) bodyProperty() string {
+	if c.BodyProperty == "" {
+		return DefaultBodyProperty
+	}
+	return c.BodyProperty
+}
+
+// patchType returns the type of the patch of patch operations, defaulting to merge.
+func (c *K8sInvocationConfig) patchType() string {
+	if c.PatchType == "" {
+		return PatchTypeMerge
+	}
+	return c.PatchType
+}
+
+// timeout returns the maximum duration of an invocation, defaulting to DefaultTimeout.
+func (c *K8sInvocationConfig) timeout() time.Duration {
+	if c.Timeout == "" {
+		return DefaultTimeout
+	}
+	d, _ := time.ParseDuration(c.Timeout)
+	return d
+}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK8sInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *K8sInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "get",
+			config: &K8sInvocationConfig{
+				Group:             "apps",
+				Version:           "v1",
+				Resource:          "deployments",
+				Verb:              VerbGet,
+				Namespace:         "{namespace}",
+				AllowedNamespaces: []string{"staging", "prod"},
+				Name:              "{name}",
+				Timeout:           "10s",
+			},
+		},
+		{
+			name:   "list",
+			config: &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbList, LabelSelector: "app={app}"},
+		},
+		{
+			name:   "patch",
+			config: &K8sInvocationConfig{Version: "v1", Resource: "configmaps", Verb: VerbPatch, Name: "{name}", BodyProperty: "patch", PatchType: PatchTypeJSON},
+		},
+		{
+			name:          "missing version",
+			config:        &K8sInvocationConfig{Resource: "pods", Verb: VerbList},
+			expectedError: "version is required",
+		},
+		{
+			name:          "missing resource",
+			config:        &K8sInvocationConfig{Version: "v1", Verb: VerbList},
+			expectedError: "resource is required",
+		},
+		{
+			name:          "resource with slash",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods/exec", Verb: VerbCreate},
+			expectedError: "invalid API path segment 'pods/exec'",
+		},
+		{
+			name:          "unsupported verb",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: "delete", Name: "{name}"},
+			expectedError: "invalid verb 'delete'",
+		},
+		{
+			name:          "get without name",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbGet},
+			expectedError: "name is required for get operations",
+		},
+		{
+			name:          "list with name",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbList, Name: "{name}"},
+			expectedError: "name can only be set for get and patch operations",
+		},
+		{
+			name:          "selector of get",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbGet, Name: "{name}", LabelSelector: "app=web"},
+			expectedError: "labelSelector and fieldSelector can only be set for list operations",
+		},
+		{
+			name:          "body of list",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbList, BodyProperty: "pod"},
+			expectedError: "bodyProperty can only be set for create and patch operations",
+		},
+		{
+			name:          "patch type of create",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbCreate, PatchType: PatchTypeMerge},
+			expectedError: "patchType can only be set for patch operations",
+		},
+		{
+			name:          "invalid patch type",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbPatch, Name: "{name}", PatchType: "apply"},
+			expectedError: "invalid patchType 'apply'",
+		},
+		{
+			name:          "empty allowed namespace",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbList, AllowedNamespaces: []string{""}},
+			expectedError: "allowedNamespaces must not contain empty namespaces",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &K8sInvocationConfig{Version: "v1", Resource: "pods", Verb: VerbList, Timeout: "soon"},
+			expectedError: "invalid timeout 'soon'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &K8sInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	kic, ok := config.(*K8sInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for k8s invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("k8s invocations are only supported for tools")
+	}
+
+	responseTransformer, err := primitive.GetResponseTransform().Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid response transform: %w", err)
+	}
+
+	var parsedKubeconfig *template.ParsedTemplate
+	if kic.Kubeconfig != "" {
+		parsedKubeconfig, err = template.ParseTemplate(kic.Kubeconfig, template.TemplateParserOptions{Tool: primitive.GetName(), Allowlist: template.PrimitiveAllowlist(primitive)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+		}
+		for _, v := range parsedKubeconfig.Variables {
+			if v.Type != template.VariableTypeEnv {
+				return nil, fmt.Errorf("kubeconfig can only reference environment variables, got '%s'", v.Name)
+			}
+		}
+	}
+
+	parserOptions := template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     template.CreateSourceFactories(),
+		Tool:        primitive.GetName(),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	}
+
+	invoker := &K8sInvoker{
+		Kubeconfig:        parsedKubeconfig,
+		Context:           kic.Context,
+		Group:             kic.Group,
+		Version:           kic.Version,
+		Resource:          kic.Resource,
+		Verb:              kic.Verb,
+		AllowedNamespaces: kic.AllowedNamespaces,
+		Timeout:           kic.timeout(),
+		InputSchema:       primitive.GetResolvedInputSchema(),
+		Transformer:       responseTransformer,
+	}
+
+	if invoker.Namespace, err = parseOptionalTemplate(kic.Namespace, parserOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace template: %w", err)
+	}
+	if invoker.Name, err = parseOptionalTemplate(kic.Name, parserOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse name template: %w", err)
+	}
+	if invoker.LabelSelector, err = parseOptionalTemplate(kic.LabelSelector, parserOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse labelSelector template: %w", err)
+	}
+	if invoker.FieldSelector, err = parseOptionalTemplate(kic.FieldSelector, parserOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse fieldSelector template: %w", err)
+	}
+
+	if kic.Verb == VerbCreate || kic.Verb == VerbPatch {
+		invoker.BodyProperty = kic.bodyProperty()
+		inputSchema := primitive.GetInputSchema()
+		if inputSchema == nil || inputSchema.Properties[invoker.BodyProperty] == nil {
+			return nil, fmt.Errorf("body property '%s' is not defined in the input schema", invoker.BodyProperty)
+		}
+	}
+	if kic.Verb == VerbPatch {
+		invoker.PatchType = kic.patchType()
+	}
+
+	// the credentials are only loaded on the first call, but the unsupported ones are rejected when the tool is
+	// validated
+	if path, err := invoker.kubeconfigPath(); err == nil {
+		if err := checkCredentials(path, kic.Context); err != nil {
+			return nil, err
+		}
+	}
+
+	return invoker, nil
+}
+
+// parseOptionalTemplate parses s, returning nil if it is empty.
+func parseOptionalTemplate(s string, options template.TemplateParserOptions) (*template.ParsedTemplate, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return template.ParseTemplate(s, options)
+}
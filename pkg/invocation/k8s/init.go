@@ -0,0 +1,11 @@
+package k8s
+
+import "github.com/genmcp/gen-mcp/pkg/invocation"
+
+const (
+	InvocationType = "k8s"
+)
+
+func init() {
+	invocation.RegisterFactory(InvocationType, &InvokerFactory{})
+}
@@ -0,0 +1,363 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+var patchContentTypes = map[string]string{
+	PatchTypeMerge:     "application/merge-patch+json",
+	PatchTypeStrategic: "application/strategic-merge-patch+json",
+	PatchTypeJSON:      "application/json-patch+json",
+}
+
+type K8sInvoker struct {
+	Kubeconfig        *template.ParsedTemplate        // Parsed path of the kubeconfig file, the default credentials if nil
+	Context           string                          // Context of the kubeconfig file, the current context if empty
+	Group             string                          // API group of the resource, empty for the core group
+	Version           string                          // API version of the resource
+	Resource          string                          // Plural name of the resource
+	Verb              string                          // Operation performed on the resource: get, list, create or patch
+	Namespace         *template.ParsedTemplate        // Parsed namespace of the resource, cluster-wide if nil
+	AllowedNamespaces []string                        // Namespaces the tool can access, any if empty
+	Name              *template.ParsedTemplate        // Parsed name of the resource (for get and patch only)
+	LabelSelector     *template.ParsedTemplate        // Parsed label selector (for list only)
+	FieldSelector     *template.ParsedTemplate        // Parsed field selector (for list only)
+	BodyProperty      string                          // Input schema property holding the body (for create and patch only)
+	PatchType         string                          // Type of the patch (for patch only)
+	Timeout           time.Duration                   // Maximum duration of the invocation
+	InputSchema       *jsonschema.Resolved            // InputSchema for the tool
+	Transformer       *invocation.ResponseTransformer // Transforms the response, if set
+
+	mu     sync.Mutex
+	client *client // created on the first invocation, so that the MCP file can be validated without credentials
+}
+
+var _ invocation.Invoker = &K8sInvoker{}
+
+// request is a request to the Kubernetes API.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	contentType string
+	body        []byte
+}
+
+// status is the subset of the Status the Kubernetes API returns for failed requests.
+type status struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (ki *K8sInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting k8s tool invocation", zap.String("verb", ki.Verb), zap.String("resource", ki.Resource))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build kubernetes request")
+	kreq, err := ki.buildRequest(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := ki.getClient(ctx)
+	if err != nil {
+		logger.Error("Failed to load Kubernetes credentials", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeAuth, "failed to load Kubernetes credentials: %v", err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ki.Timeout)
+	defer cancel()
+
+	statusCode, body, err := c.do(ctx, kreq.method, kreq.path, kreq.query, kreq.contentType, kreq.body)
+	if err != nil {
+		logger.Error("Kubernetes API request failed", zap.String("path", kreq.path), zap.Error(err))
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return utils.McpCodedError(invocation.ErrorCodeTimeout, "Kubernetes API request timed out after %s", ki.Timeout), nil
+		}
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "Kubernetes API request failed: %v", err), nil
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		logger.Warn("Kubernetes API request returned an error status", zap.String("path", kreq.path), zap.Int("status", statusCode))
+		message := strings.TrimSpace(string(body))
+		var s status
+		if err := json.Unmarshal(body, &s); err == nil && s.Kind == "Status" && s.Message != "" {
+			message = s.Message
+		}
+		result := utils.McpTextError("Kubernetes API returned status %d: %s", statusCode, message)
+		invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, statusCode))
+		return result, nil
+	}
+
+	return ki.toolResult(ctx, body), nil
+}
+
+func (ki *K8sInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("k8s invocations are only supported for tools")
+}
+
+func (ki *K8sInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("k8s invocations are only supported for tools")
+}
+
+func (ki *K8sInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("k8s invocations are only supported for tools")
+}
+
+// buildRequest parses and validates the request arguments, and returns the request to the Kubernetes API.
+func (ki *K8sInvoker) buildRequest(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) (*request, error) {
+	logger := logging.FromContext(ctx)
+
+	builders := make(map[*template.ParsedTemplate]*template.TemplateBuilder)
+	var dynamicBuilders []invocation.Builder
+	for _, parsed := range []*template.ParsedTemplate{ki.Namespace, ki.Name, ki.LabelSelector, ki.FieldSelector} {
+		if parsed == nil {
+			continue
+		}
+		builder, err := ki.newBuilder(ctx, parsed, incomingHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create template builder: %w", err)
+		}
+		builders[parsed] = builder
+		dynamicBuilders = append(dynamicBuilders, builder)
+	}
+
+	dj := &invocation.DynamicJson{Builders: dynamicBuilders}
+	parsed, err := dj.ParseJson(argsBytes, ki.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := ki.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	result := func(parsed *template.ParsedTemplate) (string, error) {
+		if parsed == nil {
+			return "", nil
+		}
+		res, err := builders[parsed].GetResult()
+		if err != nil {
+			return "", err
+		}
+		return res.(string), nil
+	}
+
+	namespace, err := result(ki.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build namespace: %w", err)
+	}
+	if err := ki.checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	name, err := result(ki.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build name: %w", err)
+	}
+	if ki.Name != nil {
+		if err := checkPathSegment("name", name); err != nil {
+			return nil, err
+		}
+	}
+
+	kreq := &request{
+		method: nethttp.MethodGet,
+		path:   ki.resourcePath(namespace, name),
+		query:  url.Values{},
+	}
+
+	switch ki.Verb {
+	case VerbList:
+		for param, parsed := range map[string]*template.ParsedTemplate{"labelSelector": ki.LabelSelector, "fieldSelector": ki.FieldSelector} {
+			selector, err := result(parsed)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build %s: %w", param, err)
+			}
+			if selector != "" {
+				kreq.query.Set(param, selector)
+			}
+		}
+	case VerbCreate, VerbPatch:
+		kreq.method = nethttp.MethodPost
+		kreq.contentType = "application/json"
+		if ki.Verb == VerbPatch {
+			kreq.method = nethttp.MethodPatch
+			kreq.contentType = patchContentTypes[ki.PatchType]
+		}
+
+		body, ok := parsed[ki.BodyProperty]
+		if !ok {
+			return nil, invocation.Errorf(invocation.ErrorCodeValidation, "missing required field: %s", ki.BodyProperty)
+		}
+		kreq.body, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode '%s': %w", ki.BodyProperty, err)
+		}
+	}
+
+	return kreq, nil
+}
+
+// checkNamespace checks that the tool can access namespace, the resources of all namespaces if empty.
+func (ki *K8sInvoker) checkNamespace(namespace string) error {
+	if ki.Namespace != nil {
+		if err := checkPathSegment("namespace", namespace); err != nil {
+			return err
+		}
+	}
+
+	if len(ki.AllowedNamespaces) == 0 || slices.Contains(ki.AllowedNamespaces, namespace) {
+		return nil
+	}
+	if namespace == "" {
+		return invocation.Errorf(invocation.ErrorCodeValidation, "access to all namespaces is not allowed: allowed namespaces are %s", strings.Join(ki.AllowedNamespaces, ", "))
+	}
+	return invocation.Errorf(invocation.ErrorCodeValidation, "namespace '%s' is not allowed: allowed namespaces are %s", namespace, strings.Join(ki.AllowedNamespaces, ", "))
+}
+
+// checkPathSegment checks that the value of field can be used as a segment of the path of the request.
+func checkPathSegment(field, value string) error {
+	if value == "" || value == "." || value == ".." || strings.ContainsAny(value, "/?#%") {
+		return invocation.Errorf(invocation.ErrorCodeValidation, "invalid %s '%s'", field, value)
+	}
+	return nil
+}
+
+// resourcePath returns the path of the resource named name in namespace, or of the collection of the resources
+// if name is empty.
+func (ki *K8sInvoker) resourcePath(namespace, name string) string {
+	segments := []string{"/api", ki.Version}
+	if ki.Group != "" {
+		segments = []string{"/apis", ki.Group, ki.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, ki.Resource)
+	if name != "" {
+		segments = append(segments, name)
+	}
+	return strings.Join(segments, "/")
+}
+
+// newBuilder creates a new builder of parsed. A new builder is created for each invocation to avoid sharing
+// state.
+func (ki *K8sInvoker) newBuilder(ctx context.Context, parsed *template.ParsedTemplate, incomingHeaders nethttp.Header) (*template.TemplateBuilder, error) {
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	return builder, nil
+}
+
+// kubeconfigPath returns the path of the kubeconfig file with its environment variables expanded, or an empty
+// path for the default credentials.
+func (ki *K8sInvoker) kubeconfigPath() (string, error) {
+	if ki.Kubeconfig == nil {
+		return "", nil
+	}
+
+	builder, err := template.NewTemplateBuilder(ki.Kubeconfig, false)
+	if err != nil {
+		return "", err
+	}
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// getClient returns the client of the Kubernetes API, creating it on the first call with the HTTP client of ctx.
+func (ki *K8sInvoker) getClient(ctx context.Context) (*client, error) {
+	ki.mu.Lock()
+	defer ki.mu.Unlock()
+
+	if ki.client != nil {
+		return ki.client, nil
+	}
+
+	path, err := ki.kubeconfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newClient(path, ki.Context, httpinvocation.HTTPClientFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	ki.client = c
+
+	return c, nil
+}
+
+// toolResult returns the result of the tool for a successful response, transformed if the tool has a response
+// transform. JSON objects are returned as structured content too.
+func (ki *K8sInvoker) toolResult(ctx context.Context, body []byte) *mcp.CallToolResult {
+	logger := logging.FromContext(ctx)
+
+	if ki.Transformer != nil {
+		transformCtx, transformSpan := tracing.Start(ctx, "transform response")
+		var err error
+		body, err = ki.Transformer.Apply(transformCtx, body)
+		tracing.End(transformSpan, err)
+		if err != nil {
+			logger.Error("Failed to transform Kubernetes API response", zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to transform response: %v", err)
+		}
+	}
+
+	logger.Info("K8s tool invocation completed successfully")
+
+	res := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(body)},
+		},
+	}
+
+	var structured map[string]any
+	if err := json.Unmarshal(body, &structured); err == nil {
+		res.StructuredContent = structured
+	}
+
+	return res
+}
@@ -0,0 +1,167 @@
+package invocation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ValidateToolOutput checks that the structured content of a successful tool result conforms to the
+// output schema of the tool. If the invoker did not set any structured content, the text content of
+// the result is parsed as a JSON object instead.
+//
+// If coerce is true, values are first converted to the types declared in the schema where this is
+// lossless (e.g. "42" to 42 for an integer property), and the text content is updated to match.
+// Error results are not validated.
+func ValidateToolOutput(result *mcp.CallToolResult, schema *jsonschema.Schema, resolved *jsonschema.Resolved, coerce bool) error {
+	if result == nil || result.IsError {
+		return nil
+	}
+
+	structured, err := structuredOutput(result)
+	if err != nil {
+		return err
+	}
+
+	if coerce {
+		coerceValue(structured, schema)
+	}
+
+	if err := resolved.Validate(structured); err != nil {
+		return err
+	}
+
+	result.StructuredContent = structured
+
+	if coerce {
+		if text, ok := singleTextContent(result); ok {
+			data, err := json.Marshal(structured)
+			if err != nil {
+				return fmt.Errorf("failed to marshal coerced output: %w", err)
+			}
+			text.Text = string(data)
+		}
+	}
+
+	return nil
+}
+
+// ParseStructuredContent sets the structured content of a successful tool result that has none, by parsing
+// its text content as a JSON object. An error is returned if the text content is not a JSON object.
+func ParseStructuredContent(result *mcp.CallToolResult) error {
+	if result == nil || result.IsError || result.StructuredContent != nil {
+		return nil
+	}
+
+	structured, err := structuredOutput(result)
+	if err != nil {
+		return err
+	}
+
+	result.StructuredContent = structured
+	return nil
+}
+
+// structuredOutput returns the structured content of result as generic JSON values.
+func structuredOutput(result *mcp.CallToolResult) (map[string]any, error) {
+	var data []byte
+	if result.StructuredContent != nil {
+		var err error
+		data, err = json.Marshal(result.StructuredContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal structured content: %w", err)
+		}
+	} else if text, ok := singleTextContent(result); ok {
+		data = []byte(text.Text)
+	} else {
+		return nil, fmt.Errorf("tool returned no structured content")
+	}
+
+	var structured map[string]any
+	if err := json.Unmarshal(data, &structured); err != nil || structured == nil {
+		return nil, fmt.Errorf("tool output is not a JSON object")
+	}
+
+	return structured, nil
+}
+
+func singleTextContent(result *mcp.CallToolResult) (*mcp.TextContent, bool) {
+	if len(result.Content) != 1 {
+		return nil, false
+	}
+
+	text, ok := result.Content[0].(*mcp.TextContent)
+	return text, ok
+}
+
+// coerceValue converts v to the type declared by schema where possible. Objects and arrays are
+// coerced in place. Values that cannot be converted are returned unchanged, so that validation
+// reports them.
+func coerceValue(v any, schema *jsonschema.Schema) any {
+	if schema == nil {
+		return v
+	}
+
+	switch schema.Type {
+	case JsonSchemaTypeObject:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		for name, value := range obj {
+			if propSchema, ok := schema.Properties[name]; ok {
+				obj[name] = coerceValue(value, propSchema)
+			}
+		}
+		return obj
+	case JsonSchemaTypeArray:
+		arr, ok := v.([]any)
+		if !ok {
+			return v
+		}
+		for i, item := range arr {
+			arr[i] = coerceValue(item, schema.Items)
+		}
+		return arr
+	case JsonSchemaTypeInteger:
+		if s, ok := v.(string); ok {
+			if f, ok := parseNumber(s); ok && f == math.Trunc(f) {
+				return f
+			}
+		}
+	case JsonSchemaTypeNumber:
+		if s, ok := v.(string); ok {
+			if f, ok := parseNumber(s); ok {
+				return f
+			}
+		}
+	case JsonSchemaTypeBoolean:
+		if s, ok := v.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	case JsonSchemaTypeString:
+		switch value := v.(type) {
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(value)
+		}
+	}
+
+	return v
+}
+
+// parseNumber parses s as a finite number, as JSON has no representation for infinity or NaN.
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,130 @@
+package invocation
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateToolOutput(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: JsonSchemaTypeObject,
+		Properties: map[string]*jsonschema.Schema{
+			"id":     {Type: JsonSchemaTypeInteger},
+			"price":  {Type: JsonSchemaTypeNumber},
+			"active": {Type: JsonSchemaTypeBoolean},
+			"sku":    {Type: JsonSchemaTypeString},
+			"tags": {
+				Type:  JsonSchemaTypeArray,
+				Items: &jsonschema.Schema{Type: JsonSchemaTypeString},
+			},
+		},
+		Required: []string{"id"},
+	}
+
+	tt := []struct {
+		name               string
+		result             *mcp.CallToolResult
+		coerce             bool
+		expectError        bool
+		expectedStructured map[string]any
+		expectedText       string
+	}{
+		{
+			name: "valid structured content",
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: `{"id":1,"sku":"a"}`}},
+				StructuredContent: map[string]any{"id": 1, "sku": "a"},
+			},
+			expectedStructured: map[string]any{"id": float64(1), "sku": "a"},
+			expectedText:       `{"id":1,"sku":"a"}`,
+		},
+		{
+			name: "structured content parsed from text",
+			result: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: `{"id": 1, "tags": ["x"]}`}},
+			},
+			expectedStructured: map[string]any{"id": float64(1), "tags": []any{"x"}},
+			expectedText:       `{"id": 1, "tags": ["x"]}`,
+		},
+		{
+			name: "missing required property",
+			result: &mcp.CallToolResult{
+				StructuredContent: map[string]any{"sku": "a"},
+			},
+			expectError: true,
+		},
+		{
+			name: "wrong type without coercion",
+			result: &mcp.CallToolResult{
+				StructuredContent: map[string]any{"id": "42"},
+			},
+			expectError: true,
+		},
+		{
+			name: "wrong types with coercion",
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: `{"id":"42","price":"9.5","active":"true","sku":123,"tags":[1,true]}`}},
+				StructuredContent: map[string]any{"id": "42", "price": "9.5", "active": "true", "sku": 123, "tags": []any{1, true}},
+			},
+			coerce: true,
+			expectedStructured: map[string]any{
+				"id":     float64(42),
+				"price":  9.5,
+				"active": true,
+				"sku":    "123",
+				"tags":   []any{"1", "true"},
+			},
+			expectedText: `{"active":true,"id":42,"price":9.5,"sku":"123","tags":["1","true"]}`,
+		},
+		{
+			name: "coercion cannot fix a fractional integer",
+			result: &mcp.CallToolResult{
+				StructuredContent: map[string]any{"id": "4.2"},
+			},
+			coerce:      true,
+			expectError: true,
+		},
+		{
+			name: "text content is not a json object",
+			result: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "hello"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "error results are not validated",
+			result: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "backend unavailable"}},
+				IsError: true,
+			},
+			expectedText: "backend unavailable",
+		},
+	}
+
+	resolved, err := schema.Resolve(nil)
+	require.NoError(t, err, "resolving the schema should not fail")
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateToolOutput(tc.result, schema, resolved, tc.coerce)
+			if tc.expectError {
+				assert.Error(t, err, "output validation should fail")
+				return
+			}
+			require.NoError(t, err, "output validation should not fail")
+
+			if tc.expectedStructured != nil {
+				assert.Equal(t, tc.expectedStructured, tc.result.StructuredContent, "structured content should match")
+			}
+			if tc.expectedText != "" {
+				assert.Equal(t, tc.expectedText, tc.result.Content[0].(*mcp.TextContent).Text, "text content should match")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// PluginInvocationConfig is the configuration for invoking an external plugin, a program implementing an
+// invocation backend that is not compiled into the server. The plugin is run for every invocation, reads a
+// JSON request from its standard input and writes the JSON result of the invocation to its standard output.
+type PluginInvocationConfig struct {
+	// The plugin executable, looked up in the PATH if it is not a path. Relative paths are resolved in the
+	// working directory.
+	Command string `json:"command" jsonschema:"required"`
+
+	// The arguments of the plugin executable.
+	Args []string `json:"args,omitempty" jsonschema:"optional"`
+
+	// Environment variables of the plugin, in addition to the environment of the server.
+	Env map[string]string `json:"env,omitempty" jsonschema:"optional"`
+
+	// The directory the plugin runs in. Defaults to the directory of the server.
+	WorkingDir string `json:"workingDir,omitempty" jsonschema:"optional"`
+
+	// The configuration of the plugin, sent as is with every request.
+	Config map[string]any `json:"config,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of the invocation, as a duration string (e.g. "10s"). Defaults to no limit.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &PluginInvocationConfig{}
+var _ invocation.FileReferencer = &PluginInvocationConfig{}
+
+func (c *PluginInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Command) == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+func (c *PluginInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &PluginInvocationConfig{
+		Command:    c.Command,
+		Args:       slices.Clone(c.Args),
+		Env:        maps.Clone(c.Env),
+		WorkingDir: c.WorkingDir,
+		Config:     copyMap(c.Config),
+		Timeout:    c.Timeout,
+	}
+}
+
+// ReferencedFiles returns the plugin executable if it is a path, so that it is packaged with the MCP file.
+func (c *PluginInvocationConfig) ReferencedFiles() []string {
+	if !strings.ContainsAny(c.Command, `/\`) {
+		return nil
+	}
+	return []string{c.Command}
+}
+
+// copyMap returns a deep copy of m, a map decoded from JSON.
+func copyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]any, len(m))
+	for key, value := range m {
+		copied[key] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return copyMap(v)
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
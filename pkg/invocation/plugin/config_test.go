@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *PluginInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "valid",
+			config: &PluginInvocationConfig{
+				Command: "./plugins/weather",
+				Args:    []string{"--units", "metric"},
+				Env:     map[string]string{"WEATHER_API_URL": "https://weather.example.com"},
+				Config:  map[string]any{"endpoint": "forecast"},
+				Timeout: "10s",
+			},
+		},
+		{
+			name:          "missing command",
+			config:        &PluginInvocationConfig{Args: []string{"--units", "metric"}},
+			expectedError: "command is required",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &PluginInvocationConfig{Command: "weather", Timeout: "soon"},
+			expectedError: "invalid timeout 'soon'",
+		},
+		{
+			name:          "negative timeout",
+			config:        &PluginInvocationConfig{Command: "weather", Timeout: "-1s"},
+			expectedError: "invalid timeout '-1s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPluginInvocationConfig_DeepCopy(t *testing.T) {
+	config := &PluginInvocationConfig{
+		Command: "weather",
+		Args:    []string{"--units", "metric"},
+		Env:     map[string]string{"DEBUG": "1"},
+		Config:  map[string]any{"regions": []any{"eu"}, "api": map[string]any{"version": "2"}},
+	}
+
+	copied := config.DeepCopy().(*PluginInvocationConfig)
+	assert.Equal(t, config, copied)
+
+	copied.Args[0] = "--verbose"
+	copied.Env["DEBUG"] = "0"
+	copied.Config["regions"].([]any)[0] = "us"
+	copied.Config["api"].(map[string]any)["version"] = "3"
+	assert.Equal(t, "--units", config.Args[0])
+	assert.Equal(t, "1", config.Env["DEBUG"])
+	assert.Equal(t, "eu", config.Config["regions"].([]any)[0])
+	assert.Equal(t, "2", config.Config["api"].(map[string]any)["version"])
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &PluginInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	pic, ok := config.(*PluginInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for plugin invoker factory")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for plugin invocations")
+	}
+
+	var timeout time.Duration
+	if pic.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(pic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", pic.Timeout, err)
+		}
+	}
+
+	uriTemplate := primitive.GetURITemplate()
+	if uriTemplate != "" {
+		if _, err := uritemplate.New(uriTemplate); err != nil {
+			return nil, fmt.Errorf("invalid URI template '%s': %w", uriTemplate, err)
+		}
+	}
+
+	return &PluginInvoker{
+		Name:        primitive.GetName(),
+		Command:     pic.Command,
+		Args:        pic.Args,
+		Env:         pic.Env,
+		WorkingDir:  pic.WorkingDir,
+		Config:      pic.Config,
+		Timeout:     timeout,
+		InputSchema: primitive.GetResolvedInputSchema(),
+		URITemplate: uriTemplate,
+	}, nil
+}
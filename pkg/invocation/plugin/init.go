@@ -0,0 +1,11 @@
+package plugin
+
+import "github.com/genmcp/gen-mcp/pkg/invocation"
+
+const (
+	InvocationType = "plugin"
+)
+
+func init() {
+	invocation.RegisterFactory(InvocationType, &InvokerFactory{})
+}
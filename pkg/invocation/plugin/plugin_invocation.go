@@ -0,0 +1,271 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.uber.org/zap"
+)
+
+// ProtocolVersion is the version of the protocol between the server and its plugins, sent with every request.
+const ProtocolVersion = "1"
+
+// The methods of the requests sent to plugins, named after the MCP methods invoking them. The result a
+// plugin writes for a request is the JSON of the result of the MCP method.
+const (
+	MethodCallTool     = "tools/call"
+	MethodGetPrompt    = "prompts/get"
+	MethodReadResource = "resources/read"
+)
+
+// commandWaitDelay bounds the time spent waiting for the output of a killed plugin, e.g. when it left
+// background processes holding its output open.
+const commandWaitDelay = 5 * time.Second
+
+// Request is the request a plugin reads from its standard input.
+type Request struct {
+	// ProtocolVersion is the version of the protocol, see ProtocolVersion.
+	ProtocolVersion string `json:"protocolVersion"`
+	// Method is the MCP method invoking the plugin: tools/call, prompts/get or resources/read.
+	Method string `json:"method"`
+	// Name is the name of the tool, prompt, resource or resource template invoking the plugin.
+	Name string `json:"name"`
+	// Arguments are the arguments of the tool call or prompt, or the variables of the URI of a resource
+	// template, validated against the input schema.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// URI is the URI of the resource read, for resources/read.
+	URI string `json:"uri,omitempty"`
+	// Config is the config of the plugin in the MCP file.
+	Config map[string]any `json:"config,omitempty"`
+}
+
+type PluginInvoker struct {
+	Name        string               // Name of the primitive invoking the plugin
+	Command     string               // Plugin executable
+	Args        []string             // Arguments of the plugin executable
+	Env         map[string]string    // Environment variables of the plugin, in addition to the server's
+	WorkingDir  string               // Directory the plugin runs in, the server's if empty
+	Config      map[string]any       // Config of the plugin, sent with every request
+	Timeout     time.Duration        // Maximum duration of the invocation, no timeout if zero
+	InputSchema *jsonschema.Resolved // InputSchema for the primitive
+	URITemplate string               // MCP URI template (for resource templates only)
+}
+
+var _ invocation.Invoker = &PluginInvoker{}
+
+func (pi *PluginInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting plugin tool invocation", zap.String("command", pi.Command))
+
+	dj := &invocation.DynamicJson{}
+	arguments, err := dj.ParseJson(req.Params.Arguments, pi.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+	if err := pi.InputSchema.Validate(arguments); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	out, err := pi.run(ctx, &Request{Method: MethodCallTool, Arguments: arguments})
+	if err != nil {
+		logger.Error("Plugin invocation failed", zap.Error(err))
+		return failureResult(err), nil
+	}
+
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		logger.Error("Failed to decode plugin result", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to decode the result of the plugin: %v", err), nil
+	}
+
+	logger.Info("Plugin tool invocation completed successfully")
+
+	return &result, nil
+}
+
+func (pi *PluginInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting plugin prompt invocation", zap.String("command", pi.Command))
+
+	arguments := make(map[string]any, len(req.Params.Arguments))
+	for argName, argValue := range req.Params.Arguments {
+		arguments[argName] = argValue
+	}
+	if err := pi.validate(arguments); err != nil {
+		logger.Error("Failed to validate prompt request arguments", zap.Error(err))
+		return nil, fmt.Errorf("failed to validate prompt request: %w", err)
+	}
+
+	out, err := pi.run(ctx, &Request{Method: MethodGetPrompt, Arguments: arguments})
+	if err != nil {
+		logger.Error("Plugin invocation failed", zap.Error(err))
+		return nil, err
+	}
+
+	var result mcp.GetPromptResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		logger.Error("Failed to decode plugin result", zap.Error(err))
+		return nil, fmt.Errorf("failed to decode the result of the plugin: %w", err)
+	}
+
+	logger.Info("Plugin prompt invocation completed successfully")
+
+	return &result, nil
+}
+
+func (pi *PluginInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return pi.readResource(ctx, req.Params.URI, nil)
+}
+
+func (pi *PluginInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+
+	// URI template syntax is validated during parsing, so we can safely use it here
+	uriTmpl, _ := uritemplate.New(pi.URITemplate)
+	matches := uriTmpl.Match(req.Params.URI)
+	if matches == nil {
+		logger.Error("URI does not match plugin resource template",
+			zap.String("uri", req.Params.URI),
+			zap.String("template", pi.URITemplate))
+		return nil, fmt.Errorf("URI does not match template")
+	}
+
+	arguments := make(map[string]any)
+	for _, paramName := range uriTmpl.Varnames() {
+		val := matches.Get(paramName)
+		if !val.Valid() {
+			return nil, fmt.Errorf("missing required parameter: %s", paramName)
+		}
+		arguments[paramName] = val.String()
+	}
+	if err := pi.validate(arguments); err != nil {
+		logger.Error("Failed to validate resource template arguments", zap.Error(err))
+		return nil, fmt.Errorf("failed to validate resource template request: %w", err)
+	}
+
+	return pi.readResource(ctx, req.Params.URI, arguments)
+}
+
+func (pi *PluginInvoker) readResource(ctx context.Context, uri string, arguments map[string]any) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting plugin resource invocation", zap.String("command", pi.Command), zap.String("uri", uri))
+
+	out, err := pi.run(ctx, &Request{Method: MethodReadResource, Arguments: arguments, URI: uri})
+	if err != nil {
+		logger.Error("Plugin invocation failed", zap.Error(err))
+		return nil, err
+	}
+
+	var result mcp.ReadResourceResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		logger.Error("Failed to decode plugin result", zap.Error(err))
+		return nil, fmt.Errorf("failed to decode the result of the plugin: %w", err)
+	}
+
+	logger.Info("Plugin resource invocation completed successfully")
+
+	return &result, nil
+}
+
+// validate validates arguments against the input schema, if the primitive has one.
+func (pi *PluginInvoker) validate(arguments map[string]any) error {
+	if pi.InputSchema == nil {
+		return nil
+	}
+	return pi.InputSchema.Validate(arguments)
+}
+
+// run runs the plugin with request on its standard input, and returns its standard output.
+func (pi *PluginInvoker) run(ctx context.Context, request *Request) ([]byte, error) {
+	request.ProtocolVersion = ProtocolVersion
+	request.Name = pi.Name
+	request.Config = pi.Config
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeInternal, "failed to encode the request of the plugin: %w", err)
+	}
+
+	if pi.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pi.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pi.Command, pi.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Dir = pi.WorkingDir
+	cmd.WaitDelay = commandWaitDelay
+	if len(pi.Env) > 0 {
+		cmd.Env = os.Environ()
+		names := make([]string, 0, len(pi.Env))
+		for name := range pi.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cmd.Env = append(cmd.Env, name+"="+pi.Env[name])
+		}
+	}
+
+	err = cmd.Run()
+	switch {
+	case err == nil:
+		return stdout.Bytes(), nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, invocation.Errorf(invocation.ErrorCodeTimeout, "plugin did not complete within %s", pi.Timeout)
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.Is(err, exec.ErrNotFound):
+		return nil, invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to start plugin: %w", err)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, &exitError{exitCode: exitErr.ExitCode(), stderr: strings.TrimSpace(stderr.String())}
+	}
+	return nil, invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to run plugin: %w", err)
+}
+
+// exitError is the error of a plugin that exited with a non-zero exit code.
+type exitError struct {
+	exitCode int
+	stderr   string
+}
+
+func (e *exitError) Error() string {
+	if e.stderr == "" {
+		return fmt.Sprintf("plugin exited with code %d", e.exitCode)
+	}
+	return fmt.Sprintf("plugin exited with code %d: %s", e.exitCode, e.stderr)
+}
+
+// failureResult reports a failed plugin invocation to the client.
+func failureResult(err error) *mcp.CallToolResult {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		result := utils.McpTextError("%v", err)
+		invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, ee.exitCode))
+		return result
+	}
+	return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "%v", err)
+}
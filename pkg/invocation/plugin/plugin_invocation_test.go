@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// testPlugin returns an invoker of a plugin running script with sh, with the request it reads saved to the
+// returned path.
+func testPlugin(t *testing.T, script string, inputSchema *jsonschema.Schema) (*PluginInvoker, string) {
+	t.Helper()
+
+	requestPath := filepath.Join(t.TempDir(), "request.json")
+	invoker := &PluginInvoker{
+		Name:    "get_forecast",
+		Command: "sh",
+		Args:    []string{"-c", `cat > "$REQUEST_PATH"; ` + script},
+		Env:     map[string]string{"REQUEST_PATH": requestPath},
+		Config:  map[string]any{"units": "metric"},
+	}
+	if inputSchema != nil {
+		resolved, err := inputSchema.Resolve(nil)
+		require.NoError(t, err)
+		invoker.InputSchema = resolved
+	}
+
+	return invoker, requestPath
+}
+
+func readRequest(t *testing.T, path string) *Request {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var request Request
+	require.NoError(t, json.Unmarshal(data, &request))
+	return &request
+}
+
+func TestPluginInvoker_Invoke(t *testing.T) {
+	inputSchema := &jsonschema.Schema{
+		Type: invocation.JsonSchemaTypeObject,
+		Properties: map[string]*jsonschema.Schema{
+			"city": {Type: invocation.JsonSchemaTypeString},
+		},
+		Required: []string{"city"},
+	}
+
+	tt := []struct {
+		name            string
+		script          string
+		timeout         time.Duration
+		command         string
+		arguments       string
+		expectedText    string
+		expectedCode    invocation.ErrorCode
+		expectedStatus  int
+		expectedRequest *Request
+		expectError     bool
+	}{
+		{
+			name:         "result",
+			script:       `echo '{"content":[{"type":"text","text":"sunny"}],"structuredContent":{"sky":"clear"}}'`,
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "sunny",
+			expectedRequest: &Request{
+				ProtocolVersion: ProtocolVersion,
+				Method:          MethodCallTool,
+				Name:            "get_forecast",
+				Arguments:       map[string]any{"city": "Paris"},
+				Config:          map[string]any{"units": "metric"},
+			},
+		},
+		{
+			name:           "non-zero exit code",
+			script:         `echo "unknown city" >&2; exit 3`,
+			arguments:      `{"city":"Atlantis"}`,
+			expectedText:   "plugin exited with code 3: unknown city",
+			expectedCode:   invocation.ErrorCodeBackendStatus,
+			expectedStatus: 3,
+		},
+		{
+			name:         "invalid result",
+			script:       `echo 'sunny'`,
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "failed to decode the result of the plugin",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "timeout",
+			script:       `exec sleep 5`,
+			timeout:      100 * time.Millisecond,
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "plugin did not complete within 100ms",
+			expectedCode: invocation.ErrorCodeTimeout,
+		},
+		{
+			name:         "command not found",
+			command:      "genmcp-plugin-that-does-not-exist",
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "failed to start plugin",
+			expectedCode: invocation.ErrorCodeBackendUnavailable,
+		},
+		{
+			name:        "invalid arguments",
+			script:      `echo '{"content":[]}'`,
+			arguments:   `{}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker, requestPath := testPlugin(t, tc.script, inputSchema)
+			invoker.Timeout = tc.timeout
+			if tc.command != "" {
+				invoker.Command = tc.command
+			}
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.arguments)},
+			})
+			if tc.expectError {
+				assert.Equal(t, invocation.ErrorCodeValidation, invocation.CodeOf(err, invocation.ErrorCodeInternal))
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+
+			if tc.expectedCode == "" {
+				assert.False(t, result.IsError)
+				assert.Equal(t, tc.expectedRequest, readRequest(t, requestPath))
+				return
+			}
+			assert.True(t, result.IsError)
+			detail, ok := invocation.GetErrorDetail(result)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedCode, detail.Code)
+			assert.Equal(t, tc.expectedStatus, detail.Status)
+		})
+	}
+}
+
+func TestPluginInvoker_InvokePrompt(t *testing.T) {
+	invoker, requestPath := testPlugin(t,
+		`echo '{"messages":[{"role":"user","content":{"type":"text","text":"What is the forecast?"}}]}'`,
+		&jsonschema.Schema{
+			Type:       invocation.JsonSchemaTypeObject,
+			Properties: map[string]*jsonschema.Schema{"city": {Type: invocation.JsonSchemaTypeString}},
+		})
+
+	result, err := invoker.InvokePrompt(context.Background(), &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{Arguments: map[string]string{"city": "Paris"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "What is the forecast?", result.Messages[0].Content.(*mcp.TextContent).Text)
+
+	request := readRequest(t, requestPath)
+	assert.Equal(t, MethodGetPrompt, request.Method)
+	assert.Equal(t, map[string]any{"city": "Paris"}, request.Arguments)
+}
+
+func TestPluginInvoker_InvokeResourceTemplate(t *testing.T) {
+	invoker, requestPath := testPlugin(t,
+		`echo '{"contents":[{"uri":"weather://Paris","mimeType":"text/plain","text":"sunny"}]}'`,
+		&jsonschema.Schema{
+			Type:       invocation.JsonSchemaTypeObject,
+			Properties: map[string]*jsonschema.Schema{"city": {Type: invocation.JsonSchemaTypeString}},
+		})
+	invoker.URITemplate = "weather://{city}"
+
+	result, err := invoker.InvokeResourceTemplate(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "weather://Paris"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, "sunny", result.Contents[0].Text)
+
+	request := readRequest(t, requestPath)
+	assert.Equal(t, MethodReadResource, request.Method)
+	assert.Equal(t, "weather://Paris", request.URI)
+	assert.Equal(t, map[string]any{"city": "Paris"}, request.Arguments)
+
+	_, err = invoker.InvokeResourceTemplate(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "forecast://Paris"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// ProxyInvocationConfig is the configuration for forwarding the calls of a tool to a tool of an upstream MCP
+// server, started as a command and connected to with the stdio transport, or reached at a URL with the
+// streamable HTTP transport. Tools forwarded to the same upstream server share a single session.
+//
+// All the values can reference environment variables using '${VAR_NAME}' syntax.
+type ProxyInvocationConfig struct {
+	// The command starting the upstream MCP server, connected to over its standard input and output
+	// (e.g. npx). Exactly one of command and url is required.
+	Command string `json:"command,omitempty" jsonschema:"optional"`
+
+	// The arguments of the command.
+	Args []string `json:"args,omitempty" jsonschema:"optional"`
+
+	// Environment variables of the command, in addition to the environment of the server.
+	Env map[string]string `json:"env,omitempty" jsonschema:"optional"`
+
+	// The URL of the upstream MCP server, using the streamable HTTP transport.
+	// Exactly one of command and url is required.
+	URL string `json:"url,omitempty" jsonschema:"optional"`
+
+	// HTTP headers sent to the upstream MCP server at the url, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"optional"`
+
+	// The name of the tool of the upstream MCP server. Defaults to the name of the tool.
+	Tool string `json:"tool,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of the call, as a duration string (e.g. "10s"). Defaults to no limit.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &ProxyInvocationConfig{}
+var _ invocation.URLReferencer = &ProxyInvocationConfig{}
+
+func (c *ProxyInvocationConfig) Validate() error {
+	switch {
+	case c.Command == "" && c.URL == "":
+		return fmt.Errorf("one of command or url is required")
+	case c.Command != "" && c.URL != "":
+		return fmt.Errorf("only one of command or url can be set")
+	}
+
+	if c.URL != "" {
+		if len(c.Args) > 0 || len(c.Env) > 0 {
+			return fmt.Errorf("args and env can only be set with a command")
+		}
+		if !strings.HasPrefix(c.URL, "${") && !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("invalid url '%s': must be an http or https URL", c.URL)
+		}
+	}
+
+	if c.Command != "" && len(c.Headers) > 0 {
+		return fmt.Errorf("headers can only be set with a url")
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+// ReferencedURLs returns the URL of the upstream server, if it is reached at one.
+func (c *ProxyInvocationConfig) ReferencedURLs() []string {
+	if c.URL == "" {
+		return nil
+	}
+	return []string{c.URL}
+}
+
+func (c *ProxyInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &ProxyInvocationConfig{
+		Command: c.Command,
+		Args:    slices.Clone(c.Args),
+		Env:     maps.Clone(c.Env),
+		URL:     c.URL,
+		Headers: maps.Clone(c.Headers),
+		Tool:    c.Tool,
+		Timeout: c.Timeout,
+	}
+}
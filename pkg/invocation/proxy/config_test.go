@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ProxyInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "valid command",
+			config: &ProxyInvocationConfig{
+				Command: "npx",
+				Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "/data"},
+				Env:     map[string]string{"DEBUG": "${DEBUG}"},
+				Tool:    "read_file",
+				Timeout: "10s",
+			},
+		},
+		{
+			name: "valid url",
+			config: &ProxyInvocationConfig{
+				URL:     "https://mcp.example.com/mcp",
+				Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+			},
+		},
+		{
+			name:   "url from environment variable",
+			config: &ProxyInvocationConfig{URL: "${UPSTREAM_URL}"},
+		},
+		{
+			name:          "missing command and url",
+			config:        &ProxyInvocationConfig{Tool: "read_file"},
+			expectedError: "one of command or url is required",
+		},
+		{
+			name:          "command and url",
+			config:        &ProxyInvocationConfig{Command: "npx", URL: "https://mcp.example.com/mcp"},
+			expectedError: "only one of command or url can be set",
+		},
+		{
+			name:          "url with args",
+			config:        &ProxyInvocationConfig{URL: "https://mcp.example.com/mcp", Args: []string{"-y"}},
+			expectedError: "args and env can only be set with a command",
+		},
+		{
+			name:          "command with headers",
+			config:        &ProxyInvocationConfig{Command: "npx", Headers: map[string]string{"Authorization": "token"}},
+			expectedError: "headers can only be set with a url",
+		},
+		{
+			name:          "invalid url",
+			config:        &ProxyInvocationConfig{URL: "mcp.example.com"},
+			expectedError: "invalid url 'mcp.example.com'",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &ProxyInvocationConfig{Command: "npx", Timeout: "-1s"},
+			expectedError: "invalid timeout '-1s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestProxyInvocationConfig_DeepCopy(t *testing.T) {
+	config := &ProxyInvocationConfig{
+		Command: "npx",
+		Args:    []string{"-y"},
+		Env:     map[string]string{"DEBUG": "1"},
+	}
+
+	copied := config.DeepCopy().(*ProxyInvocationConfig)
+	assert.Equal(t, config, copied)
+
+	copied.Args[0] = "--yes"
+	copied.Env["DEBUG"] = "0"
+	assert.Equal(t, "-y", config.Args[0])
+	assert.Equal(t, "1", config.Env["DEBUG"])
+}
@@ -0,0 +1,58 @@
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &ProxyInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	pic, ok := config.(*ProxyInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for proxy invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("proxy invocations are only supported for tools")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for proxy invocations")
+	}
+
+	if err := checkAllowedEnv(pic, template.PrimitiveAllowlist(primitive), primitive.GetName()); err != nil {
+		return nil, err
+	}
+
+	u, err := newUpstream(pic)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeout time.Duration
+	if pic.Timeout != "" {
+		timeout, err = time.ParseDuration(pic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", pic.Timeout, err)
+		}
+	}
+
+	tool := pic.Tool
+	if tool == "" {
+		tool = primitive.GetName()
+	}
+
+	return &ProxyInvoker{
+		upstream: u,
+		Tool:     tool,
+		Timeout:  timeout,
+	}, nil
+}
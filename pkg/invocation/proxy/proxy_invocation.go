@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+type ProxyInvoker struct {
+	upstream upstream      // Upstream MCP server the calls are forwarded to
+	Tool     string        // Name of the tool of the upstream MCP server
+	Timeout  time.Duration // Maximum duration of a call, no limit if 0
+}
+
+var _ invocation.Invoker = &ProxyInvoker{}
+var _ invocation.DryRunner = &ProxyInvoker{}
+
+// Invoke calls the tool of the upstream MCP server with the arguments of req, and returns its result as is.
+func (pi *ProxyInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting proxy tool invocation", zap.String("upstream", pi.upstream.String()), zap.String("upstream_tool", pi.Tool))
+
+	if pi.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pi.Timeout)
+		defer cancel()
+	}
+
+	session, err := getSession(ctx, pi.upstream)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeBackendUnavailable, "%v", err), nil
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      pi.Tool,
+		Arguments: req.Params.Arguments,
+	})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return utils.McpCodedError(invocation.ErrorCodeTimeout, "upstream MCP server %s did not answer in time", pi.upstream), nil
+		}
+		return utils.McpCodedError(invocation.ErrorCodeBackendStatus, "upstream MCP server %s failed to call tool %s: %v", pi.upstream, pi.Tool, err), nil
+	}
+
+	logger.Info("Proxy tool invocation completed successfully")
+
+	return result, nil
+}
+
+// DryRun returns the tools/call request Invoke would send to the upstream MCP server for req, without
+// connecting to it.
+func (pi *ProxyInvoker) DryRun(_ context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	body, err := json.Marshal(&mcp.CallToolParams{
+		Name:      pi.Tool,
+		Arguments: req.Params.Arguments,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	result := &invocation.DryRunResult{
+		Type:   InvocationType,
+		Method: "tools/call",
+		Body:   body,
+	}
+	if pi.upstream.URL != "" {
+		result.URL = pi.upstream.URL
+		result.Headers = make(nethttp.Header, len(pi.upstream.Headers))
+		for name, value := range pi.upstream.Headers {
+			result.Headers.Set(name, value)
+		}
+	} else {
+		result.Command = strings.Join(append([]string{pi.upstream.Command}, pi.upstream.Args...), " ")
+	}
+
+	return result, nil
+}
+
+func (pi *ProxyInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("proxy invocations are only supported for tools")
+}
+
+func (pi *ProxyInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("proxy invocations are only supported for tools")
+}
+
+func (pi *ProxyInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("proxy invocations are only supported for tools")
+}
@@ -0,0 +1,285 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/netpolicy"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type echoArgs struct {
+	Message string `json:"message"`
+}
+
+// testUpstream starts an MCP server with an echo tool, returning it along with its URL. Requests without
+// the authorization header are rejected.
+func testUpstream(t *testing.T) (*mcp.Server, string) {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "upstream"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo", Description: "Echo a message"},
+		func(_ context.Context, _ *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Message}}}, nil, nil
+		})
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(CloseSessions)
+
+	return server, srv.URL
+}
+
+func testProxyInvoker(t *testing.T, config *ProxyInvocationConfig, name string) *ProxyInvoker {
+	t.Helper()
+
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, &definitions.Tool{Name: name})
+	require.NoError(t, err)
+	return invoker.(*ProxyInvoker)
+}
+
+func callToolRequest(t *testing.T, args any) *mcp.CallToolRequest {
+	t.Helper()
+
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	return &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: raw}}
+}
+
+func TestProxyInvoker_Invoke(t *testing.T) {
+	_, url := testUpstream(t)
+	t.Setenv("UPSTREAM_TOKEN", "token")
+
+	tt := []struct {
+		name         string
+		config       *ProxyInvocationConfig
+		toolName     string
+		expectedText string
+		expectedCode invocation.ErrorCode
+	}{
+		{
+			name:         "forwards the call",
+			config:       &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer ${UPSTREAM_TOKEN}"}},
+			toolName:     "echo",
+			expectedText: "hello",
+		},
+		{
+			name:         "renamed tool",
+			config:       &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer token"}, Tool: "echo"},
+			toolName:     "upstream_echo",
+			expectedText: "hello",
+		},
+		{
+			name:         "unknown upstream tool",
+			config:       &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer token"}},
+			toolName:     "missing",
+			expectedCode: invocation.ErrorCodeBackendStatus,
+		},
+		{
+			name:         "unauthorized",
+			config:       &ProxyInvocationConfig{URL: url},
+			toolName:     "echo",
+			expectedCode: invocation.ErrorCodeBackendUnavailable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testProxyInvoker(t, tc.config, tc.toolName)
+
+			result, err := invoker.Invoke(context.Background(), callToolRequest(t, map[string]any{"message": "hello"}))
+			require.NoError(t, err)
+
+			if tc.expectedCode != "" {
+				require.True(t, result.IsError)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				return
+			}
+
+			require.False(t, result.IsError)
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestProxyInvoker_NetworkPolicy(t *testing.T) {
+	_, url := testUpstream(t)
+
+	tt := []struct {
+		name          string
+		policy        netpolicy.Config
+		expectedError string
+	}{
+		{
+			name:          "denied host",
+			policy:        netpolicy.Config{DeniedHosts: []string{"127.0.0.1"}},
+			expectedError: "127.0.0.1 is denied",
+		},
+		{
+			name:   "allowed host",
+			policy: netpolicy.Config{AllowedHosts: []string{"127.0.0.1"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := netpolicy.New(tc.policy)
+			require.NoError(t, err)
+			client := &http.Client{Transport: &http.Transport{DialContext: policy.DialContext(&net.Dialer{})}}
+			ctx := httpinvocation.WithHTTPClient(context.Background(), client)
+
+			invoker := testProxyInvoker(t, &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer token"}}, "echo")
+			result, err := invoker.Invoke(ctx, callToolRequest(t, map[string]any{"message": "hello"}))
+			require.NoError(t, err)
+
+			if tc.expectedError != "" {
+				require.True(t, result.IsError)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, invocation.ErrorCodeBackendUnavailable, detail.Code)
+				require.Len(t, result.Content, 1)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedError)
+				return
+			}
+
+			require.False(t, result.IsError, "unexpected error: %v", result.Content)
+			assert.Equal(t, "hello", result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestProxyInvoker_DryRun(t *testing.T) {
+	invoker := testProxyInvoker(t, &ProxyInvocationConfig{Command: "npx", Args: []string{"-y", "server"}, Tool: "echo"}, "upstream_echo")
+
+	result, err := invoker.DryRun(context.Background(), callToolRequest(t, map[string]any{"message": "hello"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, InvocationType, result.Type)
+	assert.Equal(t, "tools/call", result.Method)
+	assert.Equal(t, "npx -y server", result.Command)
+	assert.JSONEq(t, `{"name":"echo","arguments":{"message":"hello"}}`, string(result.Body))
+}
+
+func TestCreateInvokerErrors(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ProxyInvocationConfig
+		primitive     invocation.Primitive
+		allowedEnv    []string
+		expectedError string
+	}{
+		{
+			name:          "prompt",
+			config:        &ProxyInvocationConfig{Command: "npx"},
+			primitive:     &definitions.Prompt{Name: "echo"},
+			expectedError: "only supported for tools",
+		},
+		{
+			name:          "unset environment variable",
+			config:        &ProxyInvocationConfig{URL: "${PROXY_TEST_UNSET_URL}"},
+			primitive:     &definitions.Tool{Name: "echo"},
+			expectedError: "environment variable 'PROXY_TEST_UNSET_URL' is not set",
+		},
+		{
+			name:          "environment variable not allowed",
+			config:        &ProxyInvocationConfig{Command: "npx", Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"}},
+			primitive:     &definitions.Tool{Name: "echo"},
+			allowedEnv:    []string{"API_URL"},
+			expectedError: "environment variable 'GITHUB_TOKEN' is not in the allowedEnv of the security config of the server",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tool, ok := tc.primitive.(*definitions.Tool); ok {
+				tool.TemplateAllowlist = template.NewAllowlist(nil, tc.allowedEnv, nil)
+			}
+
+			_, err := (&InvokerFactory{}).CreateInvoker(tc.config, tc.primitive)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestListTools(t *testing.T) {
+	server, url := testUpstream(t)
+	config := &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer token"}}
+
+	tools, err := ListTools(context.Background(), config)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].Name)
+	assert.Equal(t, "Echo a message", tools[0].Description)
+
+	changed := make(chan struct{}, 1)
+	require.NoError(t, OnToolListChanged(config, func() { changed <- struct{}{} }))
+
+	server.AddTool(&mcp.Tool{Name: "ping", InputSchema: map[string]any{"type": "object"}},
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tool list change was not notified")
+	}
+
+	tools, err = ListTools(context.Background(), config)
+	require.NoError(t, err)
+	assert.Len(t, tools, 2)
+}
+
+func TestCloseOwnedSessions(t *testing.T) {
+	_, url := testUpstream(t)
+	config := &ProxyInvocationConfig{URL: url, Headers: map[string]string{"Authorization": "Bearer token"}}
+
+	owners := []*int{new(int), new(int)}
+	for _, owner := range owners {
+		_, err := ListTools(WithOwner(context.Background(), owner), config)
+		require.NoError(t, err)
+	}
+
+	ownedSessions := func(owner any) []*mcp.ClientSession {
+		sessionsMu.Lock()
+		defer sessionsMu.Unlock()
+
+		var owned []*mcp.ClientSession
+		for key, session := range sessions {
+			if key.owner == owner {
+				owned = append(owned, session)
+			}
+		}
+		return owned
+	}
+	require.Len(t, ownedSessions(owners[0]), 1, "every owner should have a session of its own")
+	require.Len(t, ownedSessions(owners[1]), 1, "every owner should have a session of its own")
+	closed := ownedSessions(owners[0])[0]
+
+	CloseOwnedSessions(owners[0])
+	assert.Empty(t, ownedSessions(owners[0]))
+	assert.Len(t, ownedSessions(owners[1]), 1, "the sessions of other owners should be left open")
+	assert.Error(t, closed.Ping(context.Background(), nil), "the session of the owner should be closed")
+}
@@ -0,0 +1,321 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// connectTimeout is how long connecting to an upstream MCP server, including its initialization, may take.
+const connectTimeout = 30 * time.Second
+
+// envReference matches the ${VAR_NAME} references to environment variables of the values of the config.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// upstream is an upstream MCP server, with the environment variables of its config expanded.
+type upstream struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// newUpstream returns the upstream MCP server of c.
+func newUpstream(c *ProxyInvocationConfig) (upstream, error) {
+	var err error
+	u := upstream{Args: make([]string, len(c.Args))}
+
+	if u.Command, err = expandEnv(c.Command); err != nil {
+		return upstream{}, fmt.Errorf("invalid command: %w", err)
+	}
+	for i, arg := range c.Args {
+		if u.Args[i], err = expandEnv(arg); err != nil {
+			return upstream{}, fmt.Errorf("invalid args[%d]: %w", i, err)
+		}
+	}
+	if u.Env, err = expandEnvMap(c.Env); err != nil {
+		return upstream{}, fmt.Errorf("invalid env: %w", err)
+	}
+	if u.URL, err = expandEnv(c.URL); err != nil {
+		return upstream{}, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Headers, err = expandEnvMap(c.Headers); err != nil {
+		return upstream{}, fmt.Errorf("invalid headers: %w", err)
+	}
+
+	return u, nil
+}
+
+// key identifies the session of the upstream MCP server.
+func (u upstream) key() string {
+	// maps are encoded with sorted keys
+	key, _ := json.Marshal(u)
+	return string(key)
+}
+
+// String returns the URL or command of the upstream MCP server, without its headers and environment.
+func (u upstream) String() string {
+	if u.URL != "" {
+		return u.URL
+	}
+	return u.Command
+}
+
+// transport returns the transport of the session of the upstream MCP server. Servers reached at a URL are
+// connected to with the transport of base, which enforces the network policy of the server.
+func (u upstream) transport(base *http.Client) mcp.Transport {
+	if u.URL != "" {
+		roundTripper := base.Transport
+		if roundTripper == nil {
+			roundTripper = http.DefaultTransport
+		}
+		if len(u.Headers) > 0 {
+			roundTripper = &headerTransport{base: roundTripper, headers: u.Headers}
+		}
+		// the client has no timeout, as the responses of the server are streamed
+		return &mcp.StreamableClientTransport{Endpoint: u.URL, HTTPClient: &http.Client{Transport: roundTripper}}
+	}
+
+	cmd := exec.Command(u.Command, u.Args...)
+	cmd.Stderr = os.Stderr
+	if len(u.Env) > 0 {
+		cmd.Env = os.Environ()
+		names := make([]string, 0, len(u.Env))
+		for name := range u.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cmd.Env = append(cmd.Env, name+"="+u.Env[name])
+		}
+	}
+	return &mcp.CommandTransport{Command: cmd}
+}
+
+// headerTransport sets headers on the requests sent to an upstream MCP server.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// sessionKey identifies a session. Upstream servers reached at a URL have a session for each HTTP client, so
+// that the network policy of the client applies to its calls, and every owner has sessions of its own.
+type sessionKey struct {
+	upstream string
+	client   *http.Client
+	owner    any
+}
+
+type ownerKey struct{}
+
+// WithOwner returns a context whose calls connect to upstream MCP servers with sessions owned by owner, which
+// are not shared with other owners and are closed by CloseOwnedSessions. owner must be comparable, e.g. a
+// pointer. Calls with a context without owner share the sessions they connect.
+func WithOwner(ctx context.Context, owner any) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = make(map[sessionKey]*mcp.ClientSession)
+	listeners  = make(map[string][]func())
+)
+
+// getSession returns the session of the upstream MCP server, connecting to it if needed with the HTTP client of
+// ctx. Sessions that are closed, e.g. because the command exited, are connected to again on the next call.
+func getSession(ctx context.Context, u upstream) (*mcp.ClientSession, error) {
+	httpClient := httpinvocation.HTTPClientFromContext(ctx)
+	key := sessionKey{upstream: u.key(), owner: ctx.Value(ownerKey{})}
+	if u.URL != "" {
+		key.client = httpClient
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	if session, ok := sessions[key]; ok {
+		return session, nil
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "genmcp"}, &mcp.ClientOptions{
+		Capabilities: &mcp.ClientCapabilities{},
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			notifyToolListChanged(key.upstream)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	session, err := client.Connect(ctx, u.transport(httpClient), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upstream MCP server %s: %w", u, err)
+	}
+	sessions[key] = session
+
+	go func() {
+		_ = session.Wait()
+
+		sessionsMu.Lock()
+		defer sessionsMu.Unlock()
+		if sessions[key] == session {
+			delete(sessions, key)
+		}
+	}()
+
+	return session, nil
+}
+
+// notifyToolListChanged calls the listeners of the upstream MCP server identified by key. They are called
+// in a goroutine of their own, so that they can make requests to the server.
+func notifyToolListChanged(key string) {
+	sessionsMu.Lock()
+	fns := listeners[key]
+	sessionsMu.Unlock()
+
+	for _, fn := range fns {
+		go fn()
+	}
+}
+
+// CloseSessions closes the sessions of all the upstream MCP servers, stopping their commands.
+func CloseSessions() {
+	sessionsMu.Lock()
+	closing := sessions
+	sessions = make(map[sessionKey]*mcp.ClientSession)
+	sessionsMu.Unlock()
+
+	for _, session := range closing {
+		_ = session.Close()
+	}
+}
+
+// CloseOwnedSessions closes the sessions of the upstream MCP servers owned by owner, stopping their commands.
+// The sessions of other owners are left open.
+func CloseOwnedSessions(owner any) {
+	sessionsMu.Lock()
+	var closing []*mcp.ClientSession
+	for key, session := range sessions {
+		if key.owner == owner {
+			closing = append(closing, session)
+			delete(sessions, key)
+		}
+	}
+	sessionsMu.Unlock()
+
+	for _, session := range closing {
+		_ = session.Close()
+	}
+}
+
+// ListTools returns the tools of the upstream MCP server of c.
+func ListTools(ctx context.Context, c *ProxyInvocationConfig) ([]*mcp.Tool, error) {
+	u, err := newUpstream(c)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := getSession(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the tools of upstream MCP server %s: %w", u, err)
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools, nil
+}
+
+// OnToolListChanged registers a function called every time the upstream MCP server of c notifies that its
+// tools changed.
+func OnToolListChanged(c *ProxyInvocationConfig, fn func()) error {
+	u, err := newUpstream(c)
+	if err != nil {
+		return err
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	key := u.key()
+	listeners[key] = append(listeners[key], fn)
+
+	return nil
+}
+
+// expandEnv replaces the ${VAR_NAME} references in s with the values of the environment variables.
+func expandEnv(s string) (string, error) {
+	var err error
+	expanded := envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envReference.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable '%s' is not set", name)
+		}
+		return value
+	})
+	return expanded, err
+}
+
+// checkAllowedEnv returns an error if the values of c reference environment variables that allowlist doesn't
+// allow the templates of tool to reference.
+func checkAllowedEnv(c *ProxyInvocationConfig, allowlist *template.Allowlist, tool string) error {
+	values := append([]string{c.Command, c.URL}, c.Args...)
+	for _, value := range c.Env {
+		values = append(values, value)
+	}
+	for _, value := range c.Headers {
+		values = append(values, value)
+	}
+
+	for _, value := range values {
+		for _, match := range envReference.FindAllStringSubmatch(value, -1) {
+			if !allowlist.EnvAllowed(tool, match[1]) {
+				return fmt.Errorf("environment variable '%s' is not in the allowedEnv of the security config of the server", match[1])
+			}
+		}
+	}
+	return nil
+}
+
+func expandEnvMap(m map[string]string) (map[string]string, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	expanded := make(map[string]string, len(m))
+	for key, value := range m {
+		v, err := expandEnv(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		expanded[key] = v
+	}
+	return expanded, nil
+}
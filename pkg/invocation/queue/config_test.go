@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *QueueInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "valid",
+			config: &QueueInvocationConfig{
+				Broker:        BrokerNATS,
+				URL:           "nats://${NATS_HOST}:4222",
+				Subject:       "orders.{region}.create",
+				Payload:       "{sku}",
+				Serialization: SerializationMsgpack,
+				WaitForReply:  true,
+				Timeout:       "10s",
+			},
+		},
+		{
+			name:          "unsupported broker",
+			config:        &QueueInvocationConfig{Broker: "kafka", URL: "kafka://localhost:9092", Subject: "orders"},
+			expectedError: "invalid broker 'kafka': must be nats",
+		},
+		{
+			name:          "missing url",
+			config:        &QueueInvocationConfig{Subject: "orders.create"},
+			expectedError: "url is required",
+		},
+		{
+			name:          "missing subject",
+			config:        &QueueInvocationConfig{URL: "nats://localhost:4222"},
+			expectedError: "subject is required",
+		},
+		{
+			name:          "subject with whitespace",
+			config:        &QueueInvocationConfig{URL: "nats://localhost:4222", Subject: "orders create"},
+			expectedError: "invalid subject 'orders create'",
+		},
+		{
+			name:          "unsupported serialization",
+			config:        &QueueInvocationConfig{URL: "nats://localhost:4222", Subject: "orders.create", Serialization: "xml"},
+			expectedError: "invalid serialization 'xml': must be json or msgpack",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &QueueInvocationConfig{URL: "nats://localhost:4222", Subject: "orders.create", Timeout: "0s"},
+			expectedError: "invalid timeout '0s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &QueueInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	qic, ok := config.(*QueueInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for queue invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("queue invocations are only supported for tools")
+	}
+
+	responseTransformer, err := primitive.GetResponseTransform().Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid response transform: %w", err)
+	}
+	if responseTransformer != nil && !qic.WaitForReply {
+		return nil, fmt.Errorf("response transforms are only supported when waiting for the reply")
+	}
+
+	parsedURL, err := template.ParseTemplate(qic.URL, template.TemplateParserOptions{Tool: primitive.GetName(), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+	for _, v := range parsedURL.Variables {
+		if v.Type != template.VariableTypeEnv {
+			return nil, fmt.Errorf("url can only reference environment variables, got '%s'", v.Name)
+		}
+	}
+
+	parserOptions := template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     template.CreateSourceFactories(),
+		Tool:        primitive.GetName(),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	}
+
+	parsedSubject, err := template.ParseTemplate(qic.Subject, parserOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject template: %w", err)
+	}
+
+	var parsedPayload *template.ParsedTemplate
+	if qic.Payload != "" {
+		parsedPayload, err = template.ParseTemplate(qic.Payload, parserOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse payload template: %w", err)
+		}
+	}
+
+	broker := qic.Broker
+	if broker == "" {
+		broker = BrokerNATS
+	}
+
+	return &QueueInvoker{
+		Broker:        broker,
+		URL:           parsedURL,
+		Subject:       parsedSubject,
+		Payload:       parsedPayload,
+		Serialization: qic.serialization(),
+		WaitForReply:  qic.WaitForReply,
+		Timeout:       qic.timeout(),
+		InputSchema:   primitive.GetResolvedInputSchema(),
+		Transformer:   responseTransformer,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/nats-io/nats.go"
+)
+
+// connKey identifies a connection. Tools publishing to the same server share a single connection.
+type connKey struct {
+	url    string
+	client *http.Client // the client whose transport dials the connection
+}
+
+var (
+	connsMu sync.Mutex
+	conns   = make(map[connKey]*nats.Conn)
+)
+
+// getConn returns the shared connection to the NATS server at url, connecting to it if needed. Tools publishing
+// to the same server share a single connection, which reconnects when it is lost. The connection is dialed with
+// the dial function of the HTTP client of ctx, so that it is subject to the network policy of the server.
+// timeout is the maximum duration of the connection to the server.
+func getConn(ctx context.Context, url string, timeout time.Duration) (*nats.Conn, error) {
+	key := connKey{url: url, client: httpinvocation.HTTPClientFromContext(ctx)}
+
+	connsMu.Lock()
+	defer connsMu.Unlock()
+
+	if conn, ok := conns[key]; ok && !conn.IsClosed() {
+		return conn, nil
+	}
+
+	dialer := &policyDialer{dial: httpinvocation.DialContextFromContext(ctx), timeout: timeout}
+	// the host names are resolved by the dial function, which checks them against the network policy
+	conn, err := nats.Connect(url, nats.Name("genmcp"), nats.Timeout(timeout), nats.SetCustomDialer(dialer), nats.SkipHostLookup())
+	if err != nil {
+		return nil, err
+	}
+	conns[key] = conn
+
+	return conn, nil
+}
+
+// policyDialer dials the connections to NATS servers, including when they reconnect, with a dial function
+// enforcing the network policy.
+type policyDialer struct {
+	dial    func(ctx context.Context, network, address string) (net.Conn, error)
+	timeout time.Duration
+}
+
+func (d *policyDialer) Dial(network, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	return d.dial(ctx, network, address)
+}
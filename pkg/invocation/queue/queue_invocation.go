@@ -0,0 +1,283 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/nats-io/nats.go"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.uber.org/zap"
+)
+
+type QueueInvoker struct {
+	Broker        string                          // Message broker, e.g. nats
+	URL           *template.ParsedTemplate        // Parsed URL of the broker, may reference environment variables
+	Subject       *template.ParsedTemplate        // Parsed subject the message is published to
+	Payload       *template.ParsedTemplate        // Parsed payload of the message, the serialized arguments if nil
+	Serialization string                          // Encoding of the arguments and replies, json or msgpack
+	WaitForReply  bool                            // Whether the tool returns the reply to the message
+	Timeout       time.Duration                   // Maximum duration of the invocation, including the wait for the reply
+	InputSchema   *jsonschema.Resolved            // InputSchema for the tool
+	Transformer   *invocation.ResponseTransformer // Transforms the reply, if set
+}
+
+var _ invocation.Invoker = &QueueInvoker{}
+
+// message is a message to publish.
+type message struct {
+	subject string
+	data    []byte
+}
+
+func (qi *QueueInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting queue tool invocation", zap.String("broker", qi.Broker))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build queue message")
+	msg, err := qi.buildMessage(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerURL, err := qi.brokerURL()
+	if err != nil {
+		logger.Error("Failed to build broker URL", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to build broker url: %v", err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, qi.Timeout)
+	defer cancel()
+
+	conn, err := getConn(ctx, brokerURL, qi.Timeout)
+	if err != nil {
+		logger.Error("Failed to connect to the broker", zap.Error(err))
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "failed to connect to the broker: %v", err), nil
+	}
+
+	if !qi.WaitForReply {
+		err := conn.Publish(msg.subject, msg.data)
+		if err == nil {
+			err = conn.FlushWithContext(ctx)
+		}
+		if err != nil {
+			logger.Error("Failed to publish message", zap.String("subject", msg.subject), zap.Error(err))
+			return qi.failureResult("failed to publish message", err), nil
+		}
+
+		logger.Info("Queue tool invocation completed successfully", zap.String("subject", msg.subject))
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("published %d bytes to %s", len(msg.data), msg.subject)},
+			},
+		}, nil
+	}
+
+	reply, err := conn.RequestWithContext(ctx, msg.subject, msg.data)
+	if err != nil {
+		logger.Error("Failed to get the reply to the message", zap.String("subject", msg.subject), zap.Error(err))
+		return qi.failureResult("failed to get the reply to the message", err), nil
+	}
+
+	data, err := qi.decodeReply(reply.Data)
+	if err != nil {
+		logger.Error("Failed to decode the reply to the message", zap.String("subject", msg.subject), zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeBackendStatus, "failed to decode the reply to the message: %v", err), nil
+	}
+
+	return qi.replyResult(ctx, data), nil
+}
+
+func (qi *QueueInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("queue invocations are only supported for tools")
+}
+
+func (qi *QueueInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("queue invocations are only supported for tools")
+}
+
+func (qi *QueueInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("queue invocations are only supported for tools")
+}
+
+// buildMessage parses and validates the request arguments, and returns the message to publish.
+func (qi *QueueInvoker) buildMessage(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) (*message, error) {
+	logger := logging.FromContext(ctx)
+
+	subjectBuilder, err := qi.newBuilder(ctx, qi.Subject, incomingHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subject builder: %w", err)
+	}
+	builders := []invocation.Builder{subjectBuilder}
+
+	var payloadBuilder *template.TemplateBuilder
+	if qi.Payload != nil {
+		payloadBuilder, err = qi.newBuilder(ctx, qi.Payload, incomingHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create payload builder: %w", err)
+		}
+		builders = append(builders, payloadBuilder)
+	}
+
+	dj := &invocation.DynamicJson{Builders: builders}
+	parsed, err := dj.ParseJson(argsBytes, qi.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := qi.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	subject, err := subjectBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build subject: %w", err)
+	}
+	msg := &message{subject: subject.(string)}
+	if msg.subject == "" || strings.ContainsAny(msg.subject, " \t\r\n") {
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "invalid subject '%s': must not be empty or contain whitespace", msg.subject)
+	}
+
+	if payloadBuilder != nil {
+		payload, err := payloadBuilder.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build payload: %w", err)
+		}
+		msg.data = []byte(payload.(string))
+	} else {
+		msg.data, err = qi.encodeArguments(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+
+	return msg, nil
+}
+
+// newBuilder creates a new builder of parsed. A new builder is created for each invocation to avoid sharing
+// state.
+func (qi *QueueInvoker) newBuilder(ctx context.Context, parsed *template.ParsedTemplate, incomingHeaders nethttp.Header) (*template.TemplateBuilder, error) {
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	return builder, nil
+}
+
+// encodeArguments encodes the parsed arguments of the tool with the serialization of the invoker. The keys of
+// the objects are sorted, as with JSON, so that the same arguments are always encoded the same way.
+func (qi *QueueInvoker) encodeArguments(args any) ([]byte, error) {
+	if qi.Serialization != SerializationMsgpack {
+		return json.Marshal(args)
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(args); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeReply returns the JSON of the reply data for the serialization of the invoker. JSON replies are
+// returned as-is, as they may not be valid JSON.
+func (qi *QueueInvoker) decodeReply(data []byte) ([]byte, error) {
+	if qi.Serialization != SerializationMsgpack {
+		return data, nil
+	}
+
+	var reply any
+	if err := msgpack.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return json.Marshal(reply)
+}
+
+// brokerURL resolves the environment variables referenced by the URL of the broker.
+func (qi *QueueInvoker) brokerURL() (string, error) {
+	builder, err := template.NewTemplateBuilder(qi.URL, false)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+
+	return result.(string), nil
+}
+
+// replyResult returns the result of the tool for the reply data, transformed if the tool has a response
+// transform. JSON objects are returned as structured content too.
+func (qi *QueueInvoker) replyResult(ctx context.Context, data []byte) *mcp.CallToolResult {
+	logger := logging.FromContext(ctx)
+
+	if qi.Transformer != nil {
+		transformCtx, transformSpan := tracing.Start(ctx, "transform response")
+		var err error
+		data, err = qi.Transformer.Apply(transformCtx, data)
+		tracing.End(transformSpan, err)
+		if err != nil {
+			logger.Error("Failed to transform reply", zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to transform response: %v", err)
+		}
+	}
+
+	logger.Info("Queue tool invocation completed successfully")
+
+	res := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}
+
+	var structured map[string]any
+	if err := json.Unmarshal(data, &structured); err == nil {
+		res.StructuredContent = structured
+	}
+
+	return res
+}
+
+// failureResult reports a failure of the broker to the client.
+func (qi *QueueInvoker) failureResult(message string, err error) *mcp.CallToolResult {
+	code := invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable)
+	if errors.Is(err, nats.ErrNoResponders) || errors.Is(err, nats.ErrMaxPayload) {
+		code = invocation.ErrorCodeBackendStatus
+	}
+	return utils.McpCodedError(code, "%s: %v", message, err)
+}
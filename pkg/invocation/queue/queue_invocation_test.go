@@ -0,0 +1,352 @@
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// testNATSServer is a NATS server handling the messages of publishers. Requests published with a reply subject
+// are answered with the reply function, or with a no responders status if it is not set.
+type testNATSServer struct {
+	url   string
+	reply func(data []byte) []byte // returns nil to leave the request unanswered
+
+	mu          sync.Mutex
+	connections int
+	connect     map[string]any
+	published   []testMsg
+}
+
+// testMsg is a message published to the test server.
+type testMsg struct {
+	subject string
+	data    []byte
+}
+
+func newTestNATSServer(t *testing.T) *testNATSServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &testNATSServer{url: "nats://" + listener.Addr().String()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *testNATSServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	_, _ = io.WriteString(conn, `INFO {"server_id":"test","version":"2.10.0","proto":1,"headers":true,"max_payload":1024}`+"\r\n")
+	reader := bufio.NewReader(conn)
+	subs := make(map[string]string) // sids by subject, ending with * for wildcard subscriptions
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		switch op {
+		case "CONNECT":
+			s.mu.Lock()
+			_ = json.Unmarshal([]byte(args), &s.connect)
+			s.mu.Unlock()
+		case "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			fields := strings.Fields(args)
+			subs[fields[0]] = fields[len(fields)-1]
+		case "PUB":
+			fields := strings.Fields(args)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			data = data[:size]
+
+			s.mu.Lock()
+			s.published = append(s.published, testMsg{subject: fields[0], data: data})
+			s.mu.Unlock()
+
+			if len(fields) == 3 {
+				s.answer(conn, fields[1], subs, data)
+			}
+		}
+	}
+}
+
+// answer sends the reply to data to the reply subject, if it is subscribed to.
+func (s *testNATSServer) answer(conn net.Conn, reply string, subs map[string]string, data []byte) {
+	sid, ok := subs[reply]
+	if !ok {
+		sid, ok = subs[reply[:strings.LastIndex(reply, ".")+1]+"*"]
+	}
+	if !ok {
+		return
+	}
+
+	if s.reply == nil {
+		status := "NATS/1.0 503\r\n\r\n"
+		_, _ = fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", reply, sid, len(status), len(status), status)
+		return
+	}
+	if answer := s.reply(data); answer != nil {
+		_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, sid, len(answer), answer)
+	}
+}
+
+func (s *testNATSServer) messages() []testMsg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.published
+}
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"region":   {Type: invocation.JsonSchemaTypeString},
+		"sku":      {Type: invocation.JsonSchemaTypeString},
+		"quantity": {Type: invocation.JsonSchemaTypeInteger},
+	},
+	Required: []string{"region", "sku"},
+}
+
+// testQueueInvoker creates a QueueInvoker for a create_order tool through the invoker factory.
+func testQueueInvoker(t *testing.T, config *QueueInvocationConfig) *QueueInvoker {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{
+		Name:                "create_order",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+
+	require.NoError(t, config.Validate())
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, tool)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*QueueInvoker)
+}
+
+func TestQueueInvoker_Invoke(t *testing.T) {
+	tt := []struct {
+		name              string
+		config            func(url string) *QueueInvocationConfig
+		reply             func(data []byte) []byte
+		arguments         string
+		expectedText      string
+		expectedCode      invocation.ErrorCode
+		expectedSubject   string
+		expectedPayload   string
+		expectedStructure map[string]any
+	}{
+		{
+			name: "publish arguments as JSON",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.{region}.create"}
+			},
+			arguments:       `{"region":"eu","sku":"A-1","quantity":2}`,
+			expectedText:    "published 40 bytes to orders.eu.create",
+			expectedSubject: "orders.eu.create",
+			expectedPayload: `{"quantity":2,"region":"eu","sku":"A-1"}`,
+		},
+		{
+			name: "payload template",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.create", Payload: "{sku}:{quantity}"}
+			},
+			arguments:       `{"region":"eu","sku":"A-1","quantity":2}`,
+			expectedText:    "published 5 bytes to orders.create",
+			expectedSubject: "orders.create",
+			expectedPayload: "A-1:2",
+		},
+		{
+			name: "wait for reply",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.create", WaitForReply: true}
+			},
+			reply: func(data []byte) []byte {
+				return []byte(`{"status":"created"}`)
+			},
+			arguments:         `{"region":"eu","sku":"A-1"}`,
+			expectedText:      `{"status":"created"}`,
+			expectedSubject:   "orders.create",
+			expectedPayload:   `{"region":"eu","sku":"A-1"}`,
+			expectedStructure: map[string]any{"status": "created"},
+		},
+		{
+			name: "msgpack serialization",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.create", Serialization: SerializationMsgpack, WaitForReply: true}
+			},
+			reply: func(data []byte) []byte {
+				reply, _ := msgpack.Marshal(map[string]any{"status": "created"})
+				return reply
+			},
+			arguments:         `{"region":"eu","sku":"A-1"}`,
+			expectedText:      `{"status":"created"}`,
+			expectedSubject:   "orders.create",
+			expectedPayload:   string(mustMarshalMsgpack(t, map[string]any{"region": "eu", "sku": "A-1"})),
+			expectedStructure: map[string]any{"status": "created"},
+		},
+		{
+			name: "no reply",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.create", WaitForReply: true, Timeout: "100ms"}
+			},
+			reply:        func(data []byte) []byte { return nil },
+			arguments:    `{"region":"eu","sku":"A-1"}`,
+			expectedText: "failed to get the reply to the message",
+			expectedCode: invocation.ErrorCodeTimeout,
+		},
+		{
+			name: "no responders",
+			config: func(url string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: url, Subject: "orders.create", WaitForReply: true}
+			},
+			arguments:    `{"region":"eu","sku":"A-1"}`,
+			expectedText: "no responders available for request",
+			expectedCode: invocation.ErrorCodeBackendStatus,
+		},
+		{
+			name: "broker unavailable",
+			config: func(string) *QueueInvocationConfig {
+				return &QueueInvocationConfig{URL: "nats://127.0.0.1:1", Subject: "orders.create"}
+			},
+			arguments:    `{"region":"eu","sku":"A-1"}`,
+			expectedText: "failed to connect to the broker",
+			expectedCode: invocation.ErrorCodeBackendUnavailable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestNATSServer(t)
+			server.reply = tc.reply
+			invoker := testQueueInvoker(t, tc.config(server.url))
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.arguments)},
+			})
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+
+			if tc.expectedCode != "" {
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				return
+			}
+
+			assert.False(t, result.IsError)
+			if tc.expectedStructure != nil {
+				assert.Equal(t, tc.expectedStructure, result.StructuredContent)
+			}
+			messages := server.messages()
+			require.Len(t, messages, 1)
+			assert.Equal(t, tc.expectedSubject, messages[0].subject)
+			assert.Equal(t, tc.expectedPayload, string(messages[0].data))
+		})
+	}
+}
+
+func TestQueueInvoker_Credentials(t *testing.T) {
+	server := newTestNATSServer(t)
+	t.Setenv("NATS_PASSWORD", "secret")
+	invoker := testQueueInvoker(t, &QueueInvocationConfig{
+		URL:     strings.Replace(server.url, "nats://", "nats://orders:${NATS_PASSWORD}@", 1),
+		Subject: "orders.create",
+	})
+
+	result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"region":"eu","sku":"A-1"}`)},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "orders", server.connect["user"])
+	assert.Equal(t, "secret", server.connect["pass"])
+}
+
+func TestQueueInvoker_InvalidArguments(t *testing.T) {
+	server := newTestNATSServer(t)
+	invoker := testQueueInvoker(t, &QueueInvocationConfig{URL: server.url, Subject: "orders.create", Timeout: "1s"})
+
+	start := time.Now()
+	_, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"region":"eu"}`)},
+	})
+	assert.Equal(t, invocation.ErrorCodeValidation, invocation.CodeOf(err, invocation.ErrorCodeInternal))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Empty(t, server.messages(), "invalid arguments should not be published")
+}
+
+func TestQueueInvoker_SharedConnection(t *testing.T) {
+	server := newTestNATSServer(t)
+	first := testQueueInvoker(t, &QueueInvocationConfig{URL: server.url, Subject: "orders.create"})
+	second := testQueueInvoker(t, &QueueInvocationConfig{URL: server.url, Subject: "orders.{region}.create"})
+
+	for _, invoker := range []*QueueInvoker{first, second, first} {
+		result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"region":"eu","sku":"A-1"}`)},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	}
+
+	assert.Len(t, server.messages(), 3)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 1, server.connections, "the tools publishing to the same broker should share a connection")
+}
+
+func mustMarshalMsgpack(t *testing.T, v any) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	require.NoError(t, enc.Encode(v))
+	return buf.Bytes()
+}
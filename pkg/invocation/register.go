@@ -33,3 +33,28 @@ func InvocationValidator(primitive Primitive) error {
 	_, err := factory.CreateInvoker(config, primitive)
 	return err
 }
+
+// LazyInvocationValidator validates the invocation config of primitive like InvocationValidator, but only
+// checks that a factory can create the invoker of a tool, without creating it, so that the templates of the
+// tool are only parsed when it is first called. The invokers of the other primitives are created.
+func LazyInvocationValidator(primitive Primitive) error {
+	if primitive.PrimitiveType() != "tool" {
+		return InvocationValidator(primitive)
+	}
+
+	config := primitive.GetInvocationConfig()
+	if config == nil {
+		return fmt.Errorf("invocation config is nil")
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	invocationType := primitive.GetInvocationType()
+	if _, exists := globalRegistry.factories[invocationType]; !exists {
+		return fmt.Errorf("unknown invocation type: '%s'", invocationType)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package invocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+	"github.com/jmespath/go-jmespath"
+)
+
+// ResponseTransform extracts or reshapes a JSON response before it is returned to the client.
+// Exactly one of JMESPath or JQ must be set.
+type ResponseTransform struct {
+	// JMESPath expression applied to the response, e.g. "items[].name".
+	JMESPath string `json:"jmespath,omitempty" jsonschema:"optional"`
+
+	// jq filter applied to the response, e.g. "[.items[].name]".
+	// If the filter produces several results, they are returned as an array.
+	JQ string `json:"jq,omitempty" jsonschema:"optional"`
+}
+
+func (rt *ResponseTransform) Validate() error {
+	_, err := rt.Compile()
+	return err
+}
+
+// Compile parses the transform expression. It returns nil if rt is nil.
+func (rt *ResponseTransform) Compile() (*ResponseTransformer, error) {
+	if rt == nil {
+		return nil, nil
+	}
+
+	switch {
+	case rt.JMESPath != "" && rt.JQ != "":
+		return nil, fmt.Errorf("only one of jmespath or jq can be set on a response transform")
+	case rt.JMESPath != "":
+		jp, err := jmespath.Compile(rt.JMESPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jmespath expression '%s': %w", rt.JMESPath, err)
+		}
+		return &ResponseTransformer{jmespath: jp}, nil
+	case rt.JQ != "":
+		query, err := gojq.Parse(rt.JQ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jq filter '%s': %w", rt.JQ, err)
+		}
+		code, err := gojq.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jq filter '%s': %w", rt.JQ, err)
+		}
+		return &ResponseTransformer{jq: code}, nil
+	default:
+		return nil, fmt.Errorf("one of jmespath or jq must be set on a response transform")
+	}
+}
+
+// ResponseTransformer applies a compiled ResponseTransform to JSON responses.
+type ResponseTransformer struct {
+	jmespath *jmespath.JMESPath
+	jq       *gojq.Code
+}
+
+// Apply transforms the JSON document in data and returns the result as JSON.
+func (t *ResponseTransformer) Apply(ctx context.Context, data []byte) ([]byte, error) {
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	var output any
+	var err error
+	if t.jmespath != nil {
+		output, err = t.jmespath.Search(input)
+	} else {
+		output, err = t.runJQ(ctx, input)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(output)
+}
+
+func (t *ResponseTransformer) runJQ(ctx context.Context, input any) (any, error) {
+	var results []any
+
+	iter := t.jq.RunWithContext(ctx, input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			var haltErr *gojq.HaltError
+			if errors.As(err, &haltErr) && haltErr.Value() == nil {
+				break
+			}
+			return nil, err
+		}
+		results = append(results, v)
+	}
+
+	if len(results) == 1 {
+		return results[0], nil
+	}
+
+	return results, nil
+}
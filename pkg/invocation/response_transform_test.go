@@ -0,0 +1,114 @@
+package invocation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseTransform(t *testing.T) {
+	const response = `{"items":[{"name":"a","size":1},{"name":"b","size":2}],"total":2}`
+
+	tt := []struct {
+		name               string
+		transform          *ResponseTransform
+		input              string
+		expectCompileError bool
+		expectApplyError   bool
+		expected           string
+	}{
+		{
+			name:      "jmespath projection",
+			transform: &ResponseTransform{JMESPath: "items[].name"},
+			input:     response,
+			expected:  `["a","b"]`,
+		},
+		{
+			name:      "jmespath multiselect",
+			transform: &ResponseTransform{JMESPath: "{names: items[].name, total: total}"},
+			input:     response,
+			expected:  `{"names":["a","b"],"total":2}`,
+		},
+		{
+			name:      "jmespath missing field",
+			transform: &ResponseTransform{JMESPath: "missing"},
+			input:     response,
+			expected:  `null`,
+		},
+		{
+			name:      "jq single result",
+			transform: &ResponseTransform{JQ: "[.items[] | select(.size > 1) | .name]"},
+			input:     response,
+			expected:  `["b"]`,
+		},
+		{
+			name:      "jq multiple results are collected",
+			transform: &ResponseTransform{JQ: ".items[].size"},
+			input:     response,
+			expected:  `[1,2]`,
+		},
+		{
+			name:             "jq runtime error",
+			transform:        &ResponseTransform{JQ: ".total | keys"},
+			input:            response,
+			expectApplyError: true,
+		},
+		{
+			name:             "response is not json",
+			transform:        &ResponseTransform{JMESPath: "items"},
+			input:            "not json",
+			expectApplyError: true,
+		},
+		{
+			name:               "invalid jmespath expression",
+			transform:          &ResponseTransform{JMESPath: "items[."},
+			expectCompileError: true,
+		},
+		{
+			name:               "invalid jq filter",
+			transform:          &ResponseTransform{JQ: ".items[] |"},
+			expectCompileError: true,
+		},
+		{
+			name:               "both expressions set",
+			transform:          &ResponseTransform{JMESPath: "items", JQ: ".items"},
+			expectCompileError: true,
+		},
+		{
+			name:               "no expression set",
+			transform:          &ResponseTransform{},
+			expectCompileError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			transformer, err := tc.transform.Compile()
+			if tc.expectCompileError {
+				assert.Error(t, err, "compiling the transform should fail")
+				return
+			}
+			require.NoError(t, err, "compiling the transform should not fail")
+
+			out, err := transformer.Apply(context.Background(), []byte(tc.input))
+			if tc.expectApplyError {
+				assert.Error(t, err, "applying the transform should fail")
+				return
+			}
+			require.NoError(t, err, "applying the transform should not fail")
+			assert.JSONEq(t, tc.expected, string(out), "transformed response should match")
+		})
+	}
+}
+
+func TestResponseTransformCompileNil(t *testing.T) {
+	var rt *ResponseTransform
+
+	transformer, err := rt.Compile()
+	assert.NoError(t, err, "compiling a nil transform should not fail")
+	assert.Nil(t, transformer, "a nil transform should compile to a nil transformer")
+}
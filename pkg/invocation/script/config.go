@@ -0,0 +1,103 @@
+package script
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	// DefaultTimeout is the maximum duration of a script if not configured.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxSteps is the maximum number of execution steps of a script if not configured.
+	DefaultMaxSteps = 10_000_000
+)
+
+// ScriptInvocationConfig is the configuration for running a Starlark script, for logic that is too complex
+// for templates but too small for a service of its own.
+type ScriptInvocationConfig struct {
+	// The Starlark source of the script. It must define a 'main(args)' function, which is called with the
+	// arguments of the tool and whose return value is the result of the tool.
+	Source string `json:"source" jsonschema:"required"`
+
+	// The HTTP endpoints the script can call with the functions of the 'http' module, by name.
+	// The script can't send requests to other URLs.
+	Endpoints map[string]*EndpointConfig `json:"endpoints,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of the invocation, including the HTTP requests, as a duration string (e.g. "10s").
+	// Defaults to 30s.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+
+	// Maximum number of execution steps of the script, which bounds the CPU it uses. Defaults to 10000000.
+	MaxSteps int `json:"maxSteps,omitempty" jsonschema:"optional"`
+}
+
+// EndpointConfig is an HTTP endpoint a script can call.
+type EndpointConfig struct {
+	// The base URL of the endpoint. The paths requested by the script are relative to it.
+	// It can reference environment variables using '${VAR_NAME}' syntax, and secrets using '{secrets.NAME}'.
+	URL string `json:"url" jsonschema:"required"`
+
+	// Headers sent with every request to the endpoint. Values can reference environment variables, secrets,
+	// and incoming headers using '{headers.Name}'.
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &ScriptInvocationConfig{}
+
+func (c *ScriptInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Source) == "" {
+		return fmt.Errorf("source is required")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Endpoints)) {
+		endpoint := c.Endpoints[name]
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("endpoint names must not be empty")
+		}
+		if endpoint == nil || strings.TrimSpace(endpoint.URL) == "" {
+			return fmt.Errorf("url is required for endpoint '%s'", name)
+		}
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	if c.MaxSteps < 0 {
+		return fmt.Errorf("maxSteps must not be negative")
+	}
+
+	return nil
+}
+
+func (c *ScriptInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	copied := &ScriptInvocationConfig{
+		Source:   c.Source,
+		Timeout:  c.Timeout,
+		MaxSteps: c.MaxSteps,
+	}
+
+	if c.Endpoints != nil {
+		copied.Endpoints = make(map[string]*EndpointConfig, len(c.Endpoints))
+		for name, endpoint := range c.Endpoints {
+			if endpoint == nil {
+				copied.Endpoints[name] = nil
+				continue
+			}
+			copied.Endpoints[name] = &EndpointConfig{
+				URL:     endpoint.URL,
+				Headers: maps.Clone(endpoint.Headers),
+			}
+		}
+	}
+
+	return copied
+}
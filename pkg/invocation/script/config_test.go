@@ -0,0 +1,69 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ScriptInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "with endpoints",
+			config: &ScriptInvocationConfig{
+				Source: "def main(args):\n    return args\n",
+				Endpoints: map[string]*EndpointConfig{
+					"inventory": {URL: "${INVENTORY_URL}", Headers: map[string]string{"Authorization": "Bearer {secrets.INVENTORY_TOKEN}"}},
+				},
+				Timeout:  "10s",
+				MaxSteps: 1000,
+			},
+		},
+		{
+			name:          "missing source",
+			config:        &ScriptInvocationConfig{},
+			expectedError: "source is required",
+		},
+		{
+			name: "missing endpoint url",
+			config: &ScriptInvocationConfig{
+				Source:    "def main(args):\n    return args\n",
+				Endpoints: map[string]*EndpointConfig{"inventory": {}},
+			},
+			expectedError: "url is required for endpoint 'inventory'",
+		},
+		{
+			name: "empty endpoint",
+			config: &ScriptInvocationConfig{
+				Source:    "def main(args):\n    return args\n",
+				Endpoints: map[string]*EndpointConfig{"inventory": nil},
+			},
+			expectedError: "url is required for endpoint 'inventory'",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &ScriptInvocationConfig{Source: "def main(args):\n    return args\n", Timeout: "-1s"},
+			expectedError: "invalid timeout '-1s'",
+		},
+		{
+			name:          "negative max steps",
+			config:        &ScriptInvocationConfig{Source: "def main(args):\n    return args\n", MaxSteps: -1},
+			expectedError: "maxSteps must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package script
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &ScriptInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	sic, ok := config.(*ScriptInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for script invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("script invocations are only supported for tools")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for script invocations")
+	}
+
+	program, err := compile(primitive.GetName(), sic.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	invoker := &ScriptInvoker{
+		Name:        primitive.GetName(),
+		Program:     program,
+		Endpoints:   make(map[string]*Endpoint, len(sic.Endpoints)),
+		Timeout:     DefaultTimeout,
+		MaxSteps:    DefaultMaxSteps,
+		InputSchema: primitive.GetResolvedInputSchema(),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(sic.Endpoints)) {
+		endpoint, err := parseEndpoint(name, sic.Endpoints[name], primitive)
+		if err != nil {
+			return nil, err
+		}
+		invoker.Endpoints[name] = endpoint
+	}
+
+	if sic.Timeout != "" {
+		invoker.Timeout, err = time.ParseDuration(sic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", sic.Timeout, err)
+		}
+	}
+	if sic.MaxSteps > 0 {
+		invoker.MaxSteps = uint64(sic.MaxSteps)
+	}
+
+	return invoker, nil
+}
+
+// compile compiles the source of the script of a tool, checking that it defines a main function.
+func compile(name, source string) (*starlark.Program, error) {
+	f, err := fileOptions.Parse(name+".star", source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	hasMain := false
+	for _, stmt := range f.Stmts {
+		if def, ok := stmt.(*syntax.DefStmt); ok && def.Name.Name == "main" {
+			hasMain = true
+		}
+	}
+	if !hasMain {
+		return nil, fmt.Errorf("invalid script: it must define a main(args) function")
+	}
+
+	program, err := starlark.FileProgram(f, predeclared(&httpModule{}).Has)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	return program, nil
+}
+
+// parseEndpoint parses the templates of the URL and headers of an endpoint, which can't reference the
+// arguments of the tool, as the script chooses the requests it sends.
+func parseEndpoint(name string, config *EndpointConfig, primitive invocation.Primitive) (*Endpoint, error) {
+	parse := func(field, value string) (*template.ParsedTemplate, error) {
+		parsed, err := template.ParseTemplate(value, template.TemplateParserOptions{
+			Sources:   template.CreateSourceFactories(),
+			Tool:      primitive.GetName(),
+			Allowlist: template.PrimitiveAllowlist(primitive),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s of endpoint '%s': %w", field, name, err)
+		}
+		for _, v := range parsed.Variables {
+			if v.Type == template.VariableTypeParam {
+				return nil, fmt.Errorf("%s of endpoint '%s' can't reference the argument '%s'", field, name, v.Name)
+			}
+		}
+		return parsed, nil
+	}
+
+	endpoint := &Endpoint{Headers: make(map[string]*template.ParsedTemplate, len(config.Headers))}
+
+	var err error
+	if endpoint.URL, err = parse("url", config.URL); err != nil {
+		return nil, err
+	}
+	for header, value := range config.Headers {
+		if endpoint.Headers[header], err = parse("header "+header, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return endpoint, nil
+}
@@ -0,0 +1,236 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// maxResponseBytes bounds the size of the responses read by scripts.
+const maxResponseBytes = 10 << 20
+
+// Endpoint is an HTTP endpoint a script can call.
+type Endpoint struct {
+	URL     *template.ParsedTemplate            // Base URL of the endpoint
+	Headers map[string]*template.ParsedTemplate // Headers sent with every request
+}
+
+// httpModule implements the 'http' module of a script invocation, sending the requests of the script with
+// the context and incoming headers of the invocation.
+type httpModule struct {
+	ctx             context.Context
+	endpoints       map[string]*Endpoint
+	incomingHeaders nethttp.Header
+}
+
+// module returns the Starlark module, whose functions are named after the methods they send:
+//
+//	http.get(endpoint, path="", query=None, headers=None)
+//	http.post(endpoint, path="", query=None, headers=None, body=None, json=None)
+//
+// and likewise for put, patch and delete.
+func (m *httpModule) module() *starlarkstruct.Module {
+	members := make(starlark.StringDict)
+	for _, method := range []string{nethttp.MethodGet, nethttp.MethodPost, nethttp.MethodPut, nethttp.MethodPatch, nethttp.MethodDelete} {
+		name := strings.ToLower(method)
+		members[name] = starlark.NewBuiltin("http."+name, m.request(method))
+	}
+	return &starlarkstruct.Module{Name: "http", Members: members}
+}
+
+func (m *httpModule) request(method string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			name, path     string
+			query, headers *starlark.Dict
+			body           string
+			jsonBody       starlark.Value
+		)
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+			"endpoint", &name, "path?", &path, "query??", &query, "headers??", &headers, "body??", &body, "json??", &jsonBody); err != nil {
+			return nil, err
+		}
+		if body != "" && jsonBody != nil {
+			return nil, fmt.Errorf("%s: body and json can't both be set", b.Name())
+		}
+
+		endpoint, ok := m.endpoints[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown endpoint '%s'", b.Name(), name)
+		}
+
+		url, err := m.buildURL(endpoint, path, query)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+
+		var reqBody io.Reader
+		contentType := ""
+		if jsonBody != nil {
+			encoded, err := starlark.Call(thread, starlarkjson.Module.Members["encode"], starlark.Tuple{jsonBody}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			reqBody = strings.NewReader(string(encoded.(starlark.String)))
+			contentType = "application/json"
+		} else if body != "" {
+			reqBody = strings.NewReader(body)
+		}
+
+		req, err := nethttp.NewRequestWithContext(m.ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for header, parsed := range endpoint.Headers {
+			value, err := m.build(parsed)
+			if err != nil {
+				return nil, fmt.Errorf("%s: failed to build header %s of endpoint '%s': %w", b.Name(), header, name, err)
+			}
+			req.Header.Set(header, value)
+		}
+		if headers != nil {
+			for _, item := range headers.Items() {
+				header, ok := starlark.AsString(item[0])
+				if !ok {
+					return nil, fmt.Errorf("%s: header names must be strings, got %s", b.Name(), item[0].Type())
+				}
+				req.Header.Set(header, valueString(item[1]))
+			}
+		}
+
+		resp, err := httpinvocation.HTTPClientFromContext(m.ctx).Do(req)
+		if err != nil {
+			return nil, invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "%s: request to endpoint '%s' failed: %w", b.Name(), name, err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+		if err != nil {
+			return nil, invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "%s: failed to read the response of endpoint '%s': %w", b.Name(), name, err)
+		}
+		if len(data) > maxResponseBytes {
+			return nil, fmt.Errorf("%s: the response of endpoint '%s' exceeded %d bytes", b.Name(), name, maxResponseBytes)
+		}
+
+		return newResponse(thread, resp, data), nil
+	}
+}
+
+// buildURL returns the URL of a request to path of endpoint, which must not leave the base URL of the
+// endpoint.
+func (m *httpModule) buildURL(endpoint *Endpoint, path string, query *starlark.Dict) (string, error) {
+	base, err := m.build(endpoint.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to build url: %w", err)
+	}
+	baseURL, err := neturl.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid url '%s': %w", base, err)
+	}
+
+	ref, err := neturl.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path '%s': %w", path, err)
+	}
+	if ref.Scheme != "" || ref.Host != "" || ref.User != nil {
+		return "", fmt.Errorf("invalid path '%s': must be relative to the endpoint", path)
+	}
+
+	url := baseURL.JoinPath(ref.Path)
+	basePath := strings.TrimSuffix(baseURL.Path, "/")
+	if url.Path != basePath && !strings.HasPrefix(url.Path, basePath+"/") {
+		return "", fmt.Errorf("invalid path '%s': must be relative to the endpoint", path)
+	}
+
+	values := url.Query()
+	for key, vals := range ref.Query() {
+		values[key] = append(values[key], vals...)
+	}
+	if query != nil {
+		for _, item := range query.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return "", fmt.Errorf("query parameter names must be strings, got %s", item[0].Type())
+			}
+			if list, ok := item[1].(*starlark.List); ok {
+				for i := 0; i < list.Len(); i++ {
+					values.Add(key, valueString(list.Index(i)))
+				}
+				continue
+			}
+			values.Add(key, valueString(item[1]))
+		}
+	}
+	url.RawQuery = values.Encode()
+
+	return url.String(), nil
+}
+
+// build builds parsed, resolving the secrets, claims and incoming headers it references.
+func (m *httpModule) build(parsed *template.ParsedTemplate) (string, error) {
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return "", err
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(m.ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(m.ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(m.ctx))
+	if m.incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(m.incomingHeaders))
+	}
+
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// newResponse returns the response of a request to the script, as a struct with the status, headers, text
+// and, for JSON responses, decoded json of the response.
+func newResponse(thread *starlark.Thread, resp *nethttp.Response, data []byte) starlark.Value {
+	headers := starlark.NewDict(len(resp.Header))
+	for name, values := range resp.Header {
+		_ = headers.SetKey(starlark.String(strings.ToLower(name)), starlark.String(strings.Join(values, ", ")))
+	}
+
+	var decoded starlark.Value = starlark.None
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		// responses that are not valid JSON are only available as text
+		if value, err := starlark.Call(thread, starlarkjson.Module.Members["decode"], starlark.Tuple{starlark.String(data)}, nil); err == nil {
+			decoded = value
+		}
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("response"), starlark.StringDict{
+		"status":  starlark.MakeInt(resp.StatusCode),
+		"headers": headers,
+		"text":    starlark.String(data),
+		"json":    decoded,
+	})
+}
+
+// valueString returns the value of a query parameter or header, strings being used as-is.
+func valueString(value starlark.Value) string {
+	if s, ok := starlark.AsString(value); ok {
+		return s
+	}
+	return value.String()
+}
@@ -0,0 +1,204 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+	"go.uber.org/zap"
+)
+
+// fileOptions are the Starlark dialect of scripts, which allows the statements Python programmers expect.
+// Scripts are bounded by their maximum number of steps and timeout instead.
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+	Recursion:       true,
+}
+
+// predeclared returns the modules available to scripts, http sending its requests for the given module.
+func predeclared(http *httpModule) starlark.StringDict {
+	return starlark.StringDict{
+		"http":   http.module(),
+		"json":   starlarkjson.Module,
+		"struct": starlark.NewBuiltin("struct", starlarkstruct.Make),
+	}
+}
+
+type ScriptInvoker struct {
+	Name        string               // Name of the tool
+	Program     *starlark.Program    // Compiled script, defining main(args)
+	Endpoints   map[string]*Endpoint // Endpoints the script can call, by name
+	Timeout     time.Duration        // Maximum duration of the invocation
+	MaxSteps    uint64               // Maximum number of execution steps of the script
+	InputSchema *jsonschema.Resolved // InputSchema for the tool
+}
+
+var _ invocation.Invoker = &ScriptInvoker{}
+
+func (si *ScriptInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting script tool invocation")
+
+	dj := &invocation.DynamicJson{}
+	arguments, err := dj.ParseJson(req.Params.Arguments, si.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+	if err := si.InputSchema.Validate(arguments); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	runCtx, span := tracing.Start(ctx, "run script")
+	value, err := si.run(runCtx, arguments, incomingHeaders)
+	tracing.End(span, err)
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			logger.Error("Script failed", zap.Error(err), zap.String("backtrace", evalErr.Backtrace()))
+		} else {
+			logger.Error("Script failed", zap.Error(err))
+		}
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "Script failed: %v", err), nil
+	}
+
+	result, err := toolResult(value)
+	if err != nil {
+		logger.Error("Failed to convert script result", zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "invalid result of the script: %v", err), nil
+	}
+
+	logger.Info("Script tool invocation completed successfully")
+
+	return result, nil
+}
+
+func (si *ScriptInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("script invocations are only supported for tools")
+}
+
+func (si *ScriptInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("script invocations are only supported for tools")
+}
+
+func (si *ScriptInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("script invocations are only supported for tools")
+}
+
+// run runs the script in a new thread and returns the value returned by its main function. The thread is
+// cancelled when ctx is done or the timeout is exceeded.
+func (si *ScriptInvoker) run(ctx context.Context, arguments map[string]any, incomingHeaders nethttp.Header) (starlark.Value, error) {
+	logger := logging.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, si.Timeout)
+	defer cancel()
+
+	stepsExceeded := false
+	thread := &starlark.Thread{
+		Name: si.Name,
+		Print: func(_ *starlark.Thread, msg string) {
+			logger.Debug("Script output", zap.String("message", msg))
+		},
+		OnMaxSteps: func(thread *starlark.Thread) {
+			stepsExceeded = true
+			thread.Cancel("too many steps")
+		},
+	}
+	thread.SetMaxExecutionSteps(si.MaxSteps)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	value, err := si.call(ctx, thread, arguments, incomingHeaders)
+	switch {
+	case stepsExceeded:
+		return nil, fmt.Errorf("script exceeded the limit of %d steps", si.MaxSteps)
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, invocation.Errorf(invocation.ErrorCodeTimeout, "script timed out after %s", si.Timeout)
+	}
+	return value, err
+}
+
+// call initializes the globals of the script in thread, and calls its main function with arguments.
+func (si *ScriptInvoker) call(ctx context.Context, thread *starlark.Thread, arguments map[string]any, incomingHeaders nethttp.Header) (starlark.Value, error) {
+	http := &httpModule{ctx: ctx, endpoints: si.Endpoints, incomingHeaders: incomingHeaders}
+	globals, err := si.Program.Init(thread, predeclared(http))
+	if err != nil {
+		return nil, err
+	}
+
+	main, ok := globals["main"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("main is not a function")
+	}
+
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, err
+	}
+	args, err := starlark.Call(thread, starlarkjson.Module.Members["decode"], starlark.Tuple{starlark.String(data)}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.Call(thread, main, starlark.Tuple{args}, nil)
+}
+
+// toolResult returns the result of a tool returning value. Strings are returned as text, other values as
+// JSON text, and objects also as structured content.
+func toolResult(value starlark.Value) (*mcp.CallToolResult, error) {
+	if s, ok := starlark.AsString(value); ok {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: s}},
+		}, nil
+	}
+
+	encoded, err := starlark.Call(&starlark.Thread{}, starlarkjson.Module.Members["encode"], starlark.Tuple{value}, nil)
+	if err != nil {
+		return nil, err
+	}
+	text := string(encoded.(starlark.String))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return nil, err
+	}
+	if object, ok := decoded.(map[string]any); ok {
+		result.StructuredContent = object
+	}
+
+	return result, nil
+}
@@ -0,0 +1,275 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"site":  {Type: invocation.JsonSchemaTypeString},
+		"limit": {Type: invocation.JsonSchemaTypeInteger},
+	},
+}
+
+// testScriptInvoker creates a ScriptInvoker for a tool through the invoker factory.
+func testScriptInvoker(t *testing.T, config *ScriptInvocationConfig) *ScriptInvoker {
+	t.Helper()
+
+	invoker, err := createInvoker(t, config)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*ScriptInvoker)
+}
+
+func createInvoker(t *testing.T, config *ScriptInvocationConfig) (invocation.Invoker, error) {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{
+		Name:                "count_devices",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+
+	require.NoError(t, config.Validate())
+	return (&InvokerFactory{}).CreateInvoker(config, tool)
+}
+
+// newInventoryServer returns a server listing the devices of a site, requiring the token of the
+// INVENTORY_TOKEN environment variable.
+func newInventoryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/devices":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"site":  r.URL.Query().Get("site"),
+				"items": []string{"router1", "router2", "switch1"},
+			})
+		case "/api/tickets":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "title": body["title"], "method": r.Method})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestScriptInvoker_Invoke(t *testing.T) {
+	tt := []struct {
+		name               string
+		source             string
+		arguments          string
+		timeout            string
+		maxSteps           int
+		expectedText       string
+		expectedStructured map[string]any
+		expectedCode       invocation.ErrorCode
+	}{
+		{
+			name:         "text result",
+			source:       "def main(args):\n    return 'site ' + args['site']\n",
+			arguments:    `{"site":"paris"}`,
+			expectedText: "site paris",
+		},
+		{
+			name: "structured result",
+			source: `
+def main(args):
+    resp = http.get("inventory", "devices", query={"site": args["site"]})
+    if resp.status != 200:
+        fail("inventory returned %d" % resp.status)
+    return {"site": resp.json["site"], "count": len(resp.json["items"][:args["limit"]])}
+`,
+			arguments:          `{"site":"paris","limit":2}`,
+			expectedText:       `{"count":2,"site":"paris"}`,
+			expectedStructured: map[string]any{"site": "paris", "count": float64(2)},
+		},
+		{
+			name: "json body",
+			source: `
+def main(args):
+    resp = http.post("inventory", "/tickets", json={"title": "check " + args["site"]})
+    return resp.json
+`,
+			arguments:          `{"site":"paris"}`,
+			expectedText:       `{"id":42,"method":"POST","title":"check paris"}`,
+			expectedStructured: map[string]any{"id": float64(42), "method": "POST", "title": "check paris"},
+		},
+		{
+			name:         "list result",
+			source:       "def main(args):\n    return [n * n for n in range(args['limit'])]\n",
+			arguments:    `{"limit":4}`,
+			expectedText: "[0,1,4,9]",
+		},
+		{
+			name:         "fail",
+			source:       "def main(args):\n    fail('unknown site', args['site'])\n",
+			arguments:    `{"site":"paris"}`,
+			expectedText: "Script failed: fail: unknown site paris",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "unknown endpoint",
+			source:       "def main(args):\n    return http.get('billing').text\n",
+			arguments:    `{}`,
+			expectedText: "http.get: unknown endpoint 'billing'",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "path outside endpoint",
+			source:       "def main(args):\n    return http.get('inventory', '../admin').text\n",
+			arguments:    `{}`,
+			expectedText: "invalid path '../admin': must be relative to the endpoint",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "other host",
+			source:       "def main(args):\n    return http.get('inventory', 'https://example.com/api').text\n",
+			arguments:    `{}`,
+			expectedText: "invalid path 'https://example.com/api': must be relative to the endpoint",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "max steps",
+			source:       "def main(args):\n    while True:\n        pass\n",
+			arguments:    `{}`,
+			maxSteps:     1000,
+			expectedText: "script exceeded the limit of 1000 steps",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "timeout",
+			source:       "def main(args):\n    while True:\n        pass\n",
+			arguments:    `{}`,
+			timeout:      "100ms",
+			maxSteps:     math.MaxInt, // not reached before the timeout on fast machines
+			expectedText: "script timed out after 100ms",
+			expectedCode: invocation.ErrorCodeTimeout,
+		},
+	}
+
+	server := newInventoryServer(t)
+	t.Setenv("INVENTORY_TOKEN", "s3cret")
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testScriptInvoker(t, &ScriptInvocationConfig{
+				Source: tc.source,
+				Endpoints: map[string]*EndpointConfig{
+					"inventory": {URL: server.URL + "/api/", Headers: map[string]string{"Authorization": "Bearer ${INVENTORY_TOKEN}"}},
+				},
+				Timeout:  tc.timeout,
+				MaxSteps: tc.maxSteps,
+			})
+
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.arguments)},
+			})
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+
+			if tc.expectedCode != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				return
+			}
+
+			assert.False(t, result.IsError)
+			assert.Equal(t, tc.expectedText, result.Content[0].(*mcp.TextContent).Text)
+			if tc.expectedStructured != nil {
+				assert.Equal(t, tc.expectedStructured, result.StructuredContent)
+			}
+		})
+	}
+}
+
+func TestScriptInvoker_BackendUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	invoker := testScriptInvoker(t, &ScriptInvocationConfig{
+		Source:    "def main(args):\n    return http.get('inventory').text\n",
+		Endpoints: map[string]*EndpointConfig{"inventory": {URL: server.URL}},
+	})
+
+	result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "request to endpoint 'inventory' failed")
+	detail, ok := invocation.GetErrorDetail(result)
+	require.True(t, ok)
+	assert.Equal(t, invocation.ErrorCodeBackendUnavailable, detail.Code)
+}
+
+func TestInvokerFactory_CreateInvoker(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *ScriptInvocationConfig
+		expectedError string
+	}{
+		{
+			name:          "syntax error",
+			config:        &ScriptInvocationConfig{Source: "def main(args)\n    return args\n"},
+			expectedError: "invalid script",
+		},
+		{
+			name:          "missing main",
+			config:        &ScriptInvocationConfig{Source: "def run(args):\n    return args\n"},
+			expectedError: "it must define a main(args) function",
+		},
+		{
+			name:          "undefined name",
+			config:        &ScriptInvocationConfig{Source: "def main(args):\n    return os.environ\n"},
+			expectedError: "undefined: os",
+		},
+		{
+			name: "endpoint referencing argument",
+			config: &ScriptInvocationConfig{
+				Source:    "def main(args):\n    return args\n",
+				Endpoints: map[string]*EndpointConfig{"inventory": {URL: "https://{site}.example.com"}},
+			},
+			expectedError: "endpoint 'inventory'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := createInvoker(t, tc.config)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
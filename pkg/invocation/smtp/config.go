@@ -0,0 +1,225 @@
+package smtp
+
+import (
+	"fmt"
+	"net/mail"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	// TLSStartTLS upgrades the connection to TLS with the STARTTLS command, and fails if the server doesn't
+	// support it.
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects with TLS, as on port 465.
+	TLSImplicit = "tls"
+	// TLSNone sends the email in clear text.
+	TLSNone = "none"
+
+	// ContentTypeText is the content type of plain text bodies.
+	ContentTypeText = "text/plain"
+	// ContentTypeHTML is the content type of HTML bodies.
+	ContentTypeHTML = "text/html"
+
+	// DefaultPort is the port of the SMTP server if not configured, the submission port.
+	DefaultPort = 587
+	// DefaultImplicitTLSPort is the port of the SMTP server if not configured and the connection uses TLS.
+	DefaultImplicitTLSPort = 465
+
+	// DefaultTimeout is the maximum duration of an invocation if not configured.
+	DefaultTimeout = 30 * time.Second
+)
+
+var validTLSModes = map[string]bool{
+	TLSStartTLS: true,
+	TLSImplicit: true,
+	TLSNone:     true,
+}
+
+var validContentTypes = map[string]bool{
+	ContentTypeText: true,
+	ContentTypeHTML: true,
+}
+
+// SmtpInvocationConfig is the configuration for sending an email through an SMTP server.
+// This is a pure data structure with no parsing logic - all struct tags only.
+type SmtpInvocationConfig struct {
+	// The host of the SMTP server, e.g. 'smtp.example.com'. It can reference environment variables using
+	// '${VAR_NAME}' syntax.
+	Host string `json:"host" jsonschema:"required"`
+
+	// The port of the SMTP server (default: 465 with tls, 587 otherwise).
+	Port int `json:"port,omitempty" jsonschema:"optional"`
+
+	// How the connection is secured (default: starttls).
+	// starttls upgrades the connection with the STARTTLS command and fails if the server doesn't support it,
+	// tls connects with TLS, and none sends the email in clear text.
+	TLS string `json:"tls,omitempty" jsonschema:"optional,enum=starttls,enum=tls,enum=none"`
+
+	// CACertFiles are paths to PEM CA certificates trusted for the certificate of the server, in addition to
+	// the certificates of the system.
+	CACertFiles []string `json:"caCertFiles,omitempty" jsonschema:"optional"`
+
+	// InsecureSkipVerify skips the verification of the TLS certificate of the server.
+	// WARNING: This is insecure and should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" jsonschema:"optional"`
+
+	// The user name to authenticate with, using the PLAIN mechanism. No authentication if unset.
+	// It can reference environment variables using '${VAR_NAME}' syntax, and secrets using '{secrets.NAME}' syntax.
+	Username string `json:"username,omitempty" jsonschema:"optional"`
+
+	// The password to authenticate with. It can reference environment variables using '${VAR_NAME}' syntax, and
+	// secrets using '{secrets.NAME}' syntax.
+	Password string `json:"password,omitempty" jsonschema:"optional"`
+
+	// The sender of the email, e.g. 'Alerts <alerts@example.com>'. It can reference environment variables using
+	// '${VAR_NAME}' syntax.
+	From string `json:"from" jsonschema:"required"`
+
+	// The recipients of the email. Each entry can hold several comma-separated addresses, and can contain
+	// placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema,
+	// in which case allowedRecipients is required. Entries that are empty once rendered are skipped.
+	To []string `json:"to" jsonschema:"required"`
+
+	// The recipients the email is copied to, like 'to'.
+	Cc []string `json:"cc,omitempty" jsonschema:"optional"`
+
+	// The recipients the email is blind copied to, like 'to'. They are not listed in the headers of the email.
+	Bcc []string `json:"bcc,omitempty" jsonschema:"optional"`
+
+	// The addresses the email may be sent to, as addresses or glob patterns (e.g. '*@example.com').
+	// Emails with other recipients are rejected before connecting.
+	AllowedRecipients []string `json:"allowedRecipients,omitempty" jsonschema:"optional"`
+
+	// The address replies are sent to. It can contain placeholders in the form of '{paramName}'.
+	ReplyTo string `json:"replyTo,omitempty" jsonschema:"optional"`
+
+	// The subject of the email. It can contain placeholders in the form of '{paramName}'.
+	Subject string `json:"subject" jsonschema:"required"`
+
+	// The body of the email. It can contain placeholders in the form of '{paramName}', whose values are
+	// HTML-escaped if the content type is text/html.
+	Body string `json:"body" jsonschema:"required"`
+
+	// The content type of the body (default: text/plain).
+	ContentType string `json:"contentType,omitempty" jsonschema:"optional,enum=text/plain,enum=text/html"`
+
+	// Maximum duration of the invocation, including the connection to the server, as a duration string
+	// (default: 30s).
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &SmtpInvocationConfig{}
+var _ invocation.FileReferencer = &SmtpInvocationConfig{}
+
+func (c *SmtpInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+
+	if c.TLS != "" && !validTLSModes[c.TLS] {
+		return fmt.Errorf("invalid tls '%s': must be one of starttls, tls, none", c.TLS)
+	}
+
+	for _, file := range c.CACertFiles {
+		if file == "" {
+			return fmt.Errorf("caCertFiles must not contain empty paths")
+		}
+	}
+	if c.TLS == TLSNone && (len(c.CACertFiles) > 0 || c.InsecureSkipVerify) {
+		return fmt.Errorf("caCertFiles and insecureSkipVerify can't be set when tls is none")
+	}
+
+	if c.Username == "" && c.Password != "" {
+		return fmt.Errorf("username is required with password")
+	}
+
+	if strings.TrimSpace(c.From) == "" {
+		return fmt.Errorf("from is required")
+	}
+	// the sender is only validated if it is not a template
+	if !strings.Contains(c.From, "$") {
+		if _, err := mail.ParseAddress(c.From); err != nil {
+			return fmt.Errorf("invalid from '%s': %w", c.From, err)
+		}
+	}
+
+	if len(c.To) == 0 {
+		return fmt.Errorf("to is required")
+	}
+	for _, recipient := range slices.Concat(c.To, c.Cc, c.Bcc) {
+		if strings.TrimSpace(recipient) == "" {
+			return fmt.Errorf("recipients must not be empty")
+		}
+	}
+
+	for _, pattern := range c.AllowedRecipients {
+		if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("invalid allowed recipient '%s'", pattern)
+		}
+	}
+
+	if strings.TrimSpace(c.Subject) == "" {
+		return fmt.Errorf("subject is required")
+	}
+
+	if c.Body == "" {
+		return fmt.Errorf("body is required")
+	}
+
+	if c.ContentType != "" && !validContentTypes[c.ContentType] {
+		return fmt.Errorf("invalid contentType '%s': must be one of text/plain, text/html", c.ContentType)
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+func (c *SmtpInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &SmtpInvocationConfig{
+		Host:               c.Host,
+		Port:               c.Port,
+		TLS:                c.TLS,
+		CACertFiles:        slices.Clone(c.CACertFiles),
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		Username:           c.Username,
+		Password:           c.Password,
+		From:               c.From,
+		To:                 slices.Clone(c.To),
+		Cc:                 slices.Clone(c.Cc),
+		Bcc:                slices.Clone(c.Bcc),
+		AllowedRecipients:  slices.Clone(c.AllowedRecipients),
+		ReplyTo:            c.ReplyTo,
+		Subject:            c.Subject,
+		Body:               c.Body,
+		ContentType:        c.ContentType,
+		Timeout:            c.Timeout,
+	}
+}
+
+// ReferencedFiles returns the CA certificates of the server.
+func (c *SmtpInvocationConfig) ReferencedFiles() []string {
+	return slices.Clone(c.CACertFiles)
+}
+
+// timeout returns the maximum duration of an invocation.
+func (c *SmtpInvocationConfig) timeout() time.Duration {
+	if c.Timeout == "" {
+		return DefaultTimeout
+	}
+	d, _ := time.ParseDuration(c.Timeout)
+	return d
+}
@@ -0,0 +1,117 @@
+package smtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmtpInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *SmtpInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "full",
+			config: &SmtpInvocationConfig{
+				Host:              "${SMTP_HOST}",
+				Port:              2525,
+				TLS:               TLSStartTLS,
+				CACertFiles:       []string{"/etc/ssl/smtp-ca.pem"},
+				Username:          "${SMTP_USER}",
+				Password:          "{secrets.SMTP_PASSWORD}",
+				From:              "Alerts <alerts@example.com>",
+				To:                []string{"{email}"},
+				Cc:                []string{"ops@example.com"},
+				Bcc:               []string{"audit@example.com"},
+				AllowedRecipients: []string{"*@example.com"},
+				ReplyTo:           "{replyTo}",
+				Subject:           "Incident {id}",
+				Body:              "<p>{message}</p>",
+				ContentType:       ContentTypeHTML,
+				Timeout:           "10s",
+			},
+		},
+		{
+			name:   "sender from the environment",
+			config: &SmtpInvocationConfig{Host: "localhost", TLS: TLSNone, From: "${SMTP_FROM}", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+		},
+		{
+			name:          "missing host",
+			config:        &SmtpInvocationConfig{From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "host is required",
+		},
+		{
+			name:          "invalid port",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", Port: 70000, From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "invalid port 70000",
+		},
+		{
+			name:          "invalid tls",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", TLS: "ssl", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "invalid tls 'ssl'",
+		},
+		{
+			name:          "CA certificates without tls",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", TLS: TLSNone, CACertFiles: []string{"ca.pem"}, From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "caCertFiles and insecureSkipVerify can't be set when tls is none",
+		},
+		{
+			name:          "password without username",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", Password: "s3cret", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "username is required with password",
+		},
+		{
+			name:          "invalid from",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "invalid from 'alerts'",
+		},
+		{
+			name:          "missing to",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", Subject: "Report", Body: "ok"},
+			expectedError: "to is required",
+		},
+		{
+			name:          "empty recipient",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"ops@example.com"}, Cc: []string{" "}, Subject: "Report", Body: "ok"},
+			expectedError: "recipients must not be empty",
+		},
+		{
+			name:          "invalid allowed recipient",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"{email}"}, AllowedRecipients: []string{"[@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "invalid allowed recipient '[@example.com'",
+		},
+		{
+			name:          "missing subject",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"ops@example.com"}, Body: "ok"},
+			expectedError: "subject is required",
+		},
+		{
+			name:          "missing body",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report"},
+			expectedError: "body is required",
+		},
+		{
+			name:          "invalid content type",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok", ContentType: "text/markdown"},
+			expectedError: "invalid contentType 'text/markdown'",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok", Timeout: "-1s"},
+			expectedError: "invalid timeout '-1s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
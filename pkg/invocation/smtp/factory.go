@@ -0,0 +1,170 @@
+package smtp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"html"
+	"os"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &SmtpInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	sic, ok := config.(*SmtpInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for smtp invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("smtp invocations are only supported for tools")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for smtp invocations")
+	}
+
+	invoker := &SmtpInvoker{
+		Port:              sic.Port,
+		TLS:               sic.TLS,
+		AllowedRecipients: sic.AllowedRecipients,
+		ContentType:       sic.ContentType,
+		Timeout:           sic.timeout(),
+		InputSchema:       primitive.GetResolvedInputSchema(),
+	}
+	if invoker.TLS == "" {
+		invoker.TLS = TLSStartTLS
+	}
+	if invoker.Port == 0 {
+		invoker.Port = DefaultPort
+		if invoker.TLS == TLSImplicit {
+			invoker.Port = DefaultImplicitTLSPort
+		}
+	}
+	if invoker.ContentType == "" {
+		invoker.ContentType = ContentTypeText
+	}
+
+	var err error
+	if invoker.TLS != TLSNone {
+		if invoker.TLSConfig, err = newTLSConfig(sic); err != nil {
+			return nil, err
+		}
+	}
+
+	credentialSources := map[string]template.SourceFactory{"secrets": template.NewSourceFactory("secrets")}
+	if invoker.Host, err = parseConfigTemplate("host", sic.Host, nil, primitive); err != nil {
+		return nil, err
+	}
+	if invoker.Username, err = parseConfigTemplate("username", sic.Username, credentialSources, primitive); err != nil {
+		return nil, err
+	}
+	if invoker.Password, err = parseConfigTemplate("password", sic.Password, credentialSources, primitive); err != nil {
+		return nil, err
+	}
+	if invoker.From, err = parseConfigTemplate("from", sic.From, nil, primitive); err != nil {
+		return nil, err
+	}
+
+	parserOptions := template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     template.CreateSourceFactories(),
+		Tool:        primitive.GetName(),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	}
+
+	recipients := map[string][]string{"to": sic.To, "cc": sic.Cc, "bcc": sic.Bcc}
+	parsedRecipients := map[string][]*template.ParsedTemplate{}
+	for _, field := range []string{"to", "cc", "bcc"} {
+		for _, recipient := range recipients[field] {
+			parsed, err := template.ParseTemplate(recipient, parserOptions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s template: %w", field, err)
+			}
+			for _, v := range parsed.Variables {
+				if v.Type != template.VariableTypeEnv && len(sic.AllowedRecipients) == 0 {
+					return nil, fmt.Errorf("allowedRecipients is required when the recipients contain placeholders")
+				}
+			}
+			parsedRecipients[field] = append(parsedRecipients[field], parsed)
+		}
+	}
+	invoker.To, invoker.Cc, invoker.Bcc = parsedRecipients["to"], parsedRecipients["cc"], parsedRecipients["bcc"]
+
+	if sic.ReplyTo != "" {
+		if invoker.ReplyTo, err = template.ParseTemplate(sic.ReplyTo, parserOptions); err != nil {
+			return nil, fmt.Errorf("failed to parse replyTo template: %w", err)
+		}
+	}
+
+	if invoker.Subject, err = template.ParseTemplate(sic.Subject, parserOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse subject template: %w", err)
+	}
+
+	// values inserted into HTML bodies are escaped, so that arguments can't inject markup
+	bodyOptions := parserOptions
+	if invoker.ContentType == ContentTypeHTML {
+		bodyOptions.Quote = html.EscapeString
+	}
+	if invoker.Body, err = template.ParseTemplate(sic.Body, bodyOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse body template: %w", err)
+	}
+
+	return invoker, nil
+}
+
+// parseConfigTemplate parses the value of field, which can only reference environment variables and the
+// given sources. It returns nil if value is empty.
+func parseConfigTemplate(field, value string, sources map[string]template.SourceFactory, primitive invocation.Primitive) (*template.ParsedTemplate, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := template.ParseTemplate(value, template.TemplateParserOptions{Sources: sources, Tool: primitive.GetName(), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+	}
+	for _, v := range parsed.Variables {
+		if v.Type != template.VariableTypeEnv && v.Type != template.VariableTypeSource {
+			return nil, fmt.Errorf("%s can't reference the arguments of the tool, got '%s'", field, v.Name)
+		}
+	}
+
+	return parsed, nil
+}
+
+// newTLSConfig returns the TLS configuration of the connections to the server, trusting the CA certificates
+// of sic in addition to those of the system.
+func newTLSConfig(sic *SmtpInvocationConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(sic.CACertFiles) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		for _, file := range sic.CACertFiles {
+			certPEM, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate '%s': %w", file, err)
+			}
+			if !rootCAs.AppendCertsFromPEM(certPEM) {
+				return nil, fmt.Errorf("no valid certificate found in CA certificate '%s'", file)
+			}
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if sic.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // User explicitly requested insecure mode
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,103 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// email is an email to send.
+type email struct {
+	from    *mail.Address
+	to      []*mail.Address
+	cc      []*mail.Address
+	bcc     []*mail.Address // not listed in the headers
+	replyTo *mail.Address   // nil if unset
+	subject string
+	body    string
+}
+
+// recipients returns the addresses the email is delivered to.
+func (e *email) recipients() []string {
+	var addresses []string
+	for _, list := range [][]*mail.Address{e.to, e.cc, e.bcc} {
+		for _, addr := range list {
+			addresses = append(addresses, addr.Address)
+		}
+	}
+	return addresses
+}
+
+// headers returns the headers of the email, in order, without its date and message ID.
+func (e *email) headers(contentType string) [][2]string {
+	headers := [][2]string{
+		{"From", e.from.String()},
+		{"To", formatAddressList(e.to)},
+	}
+	if len(e.cc) > 0 {
+		headers = append(headers, [2]string{"Cc", formatAddressList(e.cc)})
+	}
+	if e.replyTo != nil {
+		headers = append(headers, [2]string{"Reply-To", e.replyTo.String()})
+	}
+	// the subject is encoded if it holds characters other than printable ASCII, including line breaks
+	return append(headers,
+		[2]string{"Subject", mime.QEncoding.Encode("utf-8", e.subject)},
+		[2]string{"MIME-Version", "1.0"},
+		[2]string{"Content-Type", contentType + "; charset=utf-8"},
+		[2]string{"Content-Transfer-Encoding", "quoted-printable"},
+	)
+}
+
+// message returns the email in the Internet Message Format, with a new message ID, which is also returned.
+func (e *email) message(contentType string, date time.Time) ([]byte, string, error) {
+	messageID, err := newMessageID(e.from.Address)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	headers := append(e.headers(contentType), [2]string{"Date", date.Format(time.RFC1123Z)}, [2]string{"Message-ID", messageID})
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(e.body)); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), messageID, nil
+}
+
+func formatAddressList(addresses []*mail.Address) string {
+	formatted := make([]string, len(addresses))
+	for i, addr := range addresses {
+		formatted[i] = addr.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// newMessageID returns a new random message ID in the domain of the sender.
+func newMessageID(from string) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">", nil
+}
@@ -0,0 +1,504 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/mail"
+	netsmtp "net/smtp"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// authFailureCodes are the SMTP reply codes of failed authentications.
+var authFailureCodes = map[int]bool{
+	530: true, // authentication required
+	534: true, // authentication mechanism is too weak
+	535: true, // authentication credentials invalid
+	538: true, // encryption required for the authentication mechanism
+}
+
+type SmtpInvoker struct {
+	Host              *template.ParsedTemplate   // Parsed host of the server, may reference environment variables
+	Port              int                        // Port of the server
+	TLS               string                     // How the connection is secured: starttls, tls or none
+	TLSConfig         *tls.Config                // TLS configuration of the connection, nil if tls is none
+	Username          *template.ParsedTemplate   // Parsed user name, no authentication if nil
+	Password          *template.ParsedTemplate   // Parsed password, may reference secrets
+	From              *template.ParsedTemplate   // Parsed sender of the email
+	To                []*template.ParsedTemplate // Parsed recipients of the email
+	Cc                []*template.ParsedTemplate // Parsed recipients the email is copied to
+	Bcc               []*template.ParsedTemplate // Parsed recipients the email is blind copied to
+	AllowedRecipients []string                   // Patterns of the addresses the email may be sent to, any if empty
+	ReplyTo           *template.ParsedTemplate   // Parsed address replies are sent to, nil if not set
+	Subject           *template.ParsedTemplate   // Parsed subject of the email
+	Body              *template.ParsedTemplate   // Parsed body of the email
+	ContentType       string                     // Content type of the body
+	Timeout           time.Duration              // Maximum duration of the invocation
+	InputSchema       *jsonschema.Resolved       // InputSchema for the tool
+}
+
+var _ invocation.Invoker = &SmtpInvoker{}
+var _ invocation.DryRunner = &SmtpInvoker{}
+
+func (si *SmtpInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SMTP tool invocation")
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build email")
+	e, err := si.buildEmail(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := si.resolve(ctx, si.Host)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to build host: %v", err), nil
+	}
+
+	msg, messageID, err := e.message(si.ContentType, time.Now())
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode email: %v", err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, si.Timeout)
+	defer cancel()
+
+	sendCtx, span := tracing.Start(ctx, "send email")
+	span.SetAttributes(attribute.String("server.address", host))
+	response, err := si.send(sendCtx, host, e, msg)
+	tracing.End(span, err)
+	if err != nil {
+		logger.Error("Failed to send email", zap.String("host", host), zap.Error(err))
+		return si.failureResult(ctx, err), nil
+	}
+
+	logger.Info("SMTP tool invocation completed successfully", zap.String("messageId", messageID))
+
+	recipients := e.recipients()
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("email sent to %s: %s", strings.Join(recipients, ", "), response)},
+		},
+		StructuredContent: map[string]any{
+			"messageId":  messageID,
+			"recipients": recipients,
+			"response":   response,
+		},
+	}, nil
+}
+
+func (si *SmtpInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("smtp invocations are only supported for tools")
+}
+
+func (si *SmtpInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("smtp invocations are only supported for tools")
+}
+
+func (si *SmtpInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("smtp invocations are only supported for tools")
+}
+
+// DryRun returns the headers and body of the email Invoke would send for req, without connecting to the
+// server. Bcc recipients are listed in a Bcc header.
+func (si *SmtpInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	e, err := si.buildEmail(ctx, req.Params.Arguments, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+	host, err := si.resolve(ctx, si.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build host: %w", err)
+	}
+
+	headers := nethttp.Header{}
+	for _, header := range e.headers(si.ContentType) {
+		headers.Set(header[0], header[1])
+	}
+	if len(e.bcc) > 0 {
+		headers.Set("Bcc", formatAddressList(e.bcc))
+	}
+	body, err := json.Marshal(e.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invocation.DryRunResult{
+		Type:    InvocationType,
+		URL:     "smtp://" + net.JoinHostPort(host, strconv.Itoa(si.Port)),
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// buildEmail parses and validates the request arguments, and returns the email to send.
+func (si *SmtpInvoker) buildEmail(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) (*email, error) {
+	logger := logging.FromContext(ctx)
+
+	newBuilders := func(parsed []*template.ParsedTemplate) ([]*template.TemplateBuilder, error) {
+		builders := make([]*template.TemplateBuilder, len(parsed))
+		for i, p := range parsed {
+			var err error
+			if builders[i], err = si.newBuilder(ctx, p, incomingHeaders); err != nil {
+				return nil, err
+			}
+		}
+		return builders, nil
+	}
+
+	var builders []invocation.Builder
+	recipientBuilders := map[string][]*template.TemplateBuilder{}
+	for field, parsed := range map[string][]*template.ParsedTemplate{"to": si.To, "cc": si.Cc, "bcc": si.Bcc} {
+		fieldBuilders, err := newBuilders(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s builder: %w", field, err)
+		}
+		recipientBuilders[field] = fieldBuilders
+		for _, builder := range fieldBuilders {
+			builders = append(builders, builder)
+		}
+	}
+
+	headerBuilders, err := newBuilders([]*template.ParsedTemplate{si.Subject, si.Body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email builder: %w", err)
+	}
+	subjectBuilder, bodyBuilder := headerBuilders[0], headerBuilders[1]
+	builders = append(builders, subjectBuilder, bodyBuilder)
+
+	var replyToBuilder *template.TemplateBuilder
+	if si.ReplyTo != nil {
+		if replyToBuilder, err = si.newBuilder(ctx, si.ReplyTo, incomingHeaders); err != nil {
+			return nil, fmt.Errorf("failed to create replyTo builder: %w", err)
+		}
+		builders = append(builders, replyToBuilder)
+	}
+
+	dj := &invocation.DynamicJson{Builders: builders}
+	parsed, err := dj.ParseJson(argsBytes, si.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := si.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	e := &email{}
+
+	from, err := si.resolve(ctx, si.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build from: %w", err)
+	}
+	if e.from, err = mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid from '%s': %w", from, err)
+	}
+
+	for field, list := range map[string]*[]*mail.Address{"to": &e.to, "cc": &e.cc, "bcc": &e.bcc} {
+		for _, builder := range recipientBuilders[field] {
+			result, err := builder.GetResult()
+			if err != nil {
+				return nil, fmt.Errorf("failed to build %s: %w", field, err)
+			}
+			addresses, err := si.parseRecipients(result.(string))
+			if err != nil {
+				logger.Warn("Email recipient rejected", zap.String("field", field), zap.Error(err))
+				return nil, err
+			}
+			*list = append(*list, addresses...)
+		}
+	}
+	if len(e.to) == 0 {
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "the email has no recipient")
+	}
+
+	if replyToBuilder != nil {
+		result, err := replyToBuilder.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build replyTo: %w", err)
+		}
+		if replyTo := strings.TrimSpace(result.(string)); replyTo != "" {
+			if e.replyTo, err = mail.ParseAddress(replyTo); err != nil {
+				return nil, invocation.Errorf(invocation.ErrorCodeValidation, "invalid replyTo '%s': %w", replyTo, err)
+			}
+		}
+	}
+
+	subject, err := subjectBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build subject: %w", err)
+	}
+	e.subject = subject.(string)
+
+	body, err := bodyBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build body: %w", err)
+	}
+	e.body = body.(string)
+
+	return e, nil
+}
+
+// parseRecipients parses the comma-separated addresses of list, and checks that the email may be sent to
+// them. Empty lists have no addresses.
+func (si *SmtpInvoker) parseRecipients(list string) ([]*mail.Address, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	addresses, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "invalid recipients '%s': %w", list, err)
+	}
+	for _, addr := range addresses {
+		if err := si.checkRecipient(addr.Address); err != nil {
+			return nil, err
+		}
+	}
+	return addresses, nil
+}
+
+// checkRecipient returns an error if the email can't be sent to address.
+func (si *SmtpInvoker) checkRecipient(address string) error {
+	if len(si.AllowedRecipients) == 0 {
+		return nil
+	}
+	for _, pattern := range si.AllowedRecipients {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(address)); matched {
+			return nil
+		}
+	}
+	return invocation.Errorf(invocation.ErrorCodeValidation, "recipient '%s' is not allowed", address)
+}
+
+// newBuilder creates a new builder of parsed. A new builder is created for each invocation to avoid sharing
+// state.
+func (si *SmtpInvoker) newBuilder(ctx context.Context, parsed *template.ParsedTemplate, incomingHeaders nethttp.Header) (*template.TemplateBuilder, error) {
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	return builder, nil
+}
+
+// resolve resolves the environment variables and secrets referenced by parsed, returning an empty string if
+// it is nil.
+func (si *SmtpInvoker) resolve(ctx context.Context, parsed *template.ParsedTemplate) (string, error) {
+	if parsed == nil {
+		return "", nil
+	}
+
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return "", err
+	}
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// send sends msg, the encoded email e, through the server at host, and returns the reply of the server
+// accepting it, e.g. '2.0.0 Ok: queued as 4B2F1'.
+func (si *SmtpInvoker) send(ctx context.Context, host string, e *email, msg []byte) (string, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(si.Port))
+
+	var tlsConfig *tls.Config
+	if si.TLSConfig != nil {
+		tlsConfig = si.TLSConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+
+	// the connection is dialed with the dial function of the network policy
+	conn, err := httpinvocation.DialContextFromContext(ctx)(ctx, "tcp", address)
+	if err == nil && si.TLS == TLSImplicit {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: host}
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+		}
+		conn = tlsConn
+	}
+	if err != nil {
+		return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to connect to %s: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// the SMTP client doesn't take a context: the pending reads and writes fail once it is done
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, err := netsmtp.NewClient(conn, host)
+	if err != nil {
+		return "", err
+	}
+
+	if si.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "the server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return "", err
+		}
+	}
+
+	if si.Username != nil {
+		if err := si.authenticate(ctx, c, host); err != nil {
+			return "", err
+		}
+	}
+
+	if err := c.Mail(e.from.Address); err != nil {
+		return "", err
+	}
+	for _, recipient := range e.recipients() {
+		if err := c.Rcpt(recipient); err != nil {
+			return "", fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+	}
+
+	response, err := data(c, msg)
+	if err != nil {
+		return "", err
+	}
+
+	// the email is sent once the server accepted it, whether the connection is closed cleanly or not
+	_ = c.Quit()
+
+	return response, nil
+}
+
+// authenticate authenticates c with the PLAIN mechanism, which the client only uses over TLS or with a
+// server on the local host.
+func (si *SmtpInvoker) authenticate(ctx context.Context, c *netsmtp.Client, host string) error {
+	if ok, _ := c.Extension("AUTH"); !ok {
+		return invocation.Errorf(invocation.ErrorCodeAuth, "the server does not support authentication")
+	}
+
+	username, err := si.resolve(ctx, si.Username)
+	if err != nil {
+		return fmt.Errorf("failed to build username: %w", err)
+	}
+	password, err := si.resolve(ctx, si.Password)
+	if err != nil {
+		return fmt.Errorf("failed to build password: %w", err)
+	}
+
+	if err := c.Auth(netsmtp.PlainAuth("", username, password, host)); err != nil {
+		var tpErr *textproto.Error
+		if errors.As(err, &tpErr) {
+			return err
+		}
+		return invocation.Errorf(invocation.ErrorCodeAuth, "failed to authenticate: %w", err)
+	}
+	return nil
+}
+
+// data sends msg with the DATA command of c, and returns the reply of the server accepting it. Unlike
+// Client.Data, the reply is not discarded, as it often holds the ID of the queued email.
+func data(c *netsmtp.Client, msg []byte) (string, error) {
+	id, err := c.Text.Cmd("DATA")
+	if err != nil {
+		return "", err
+	}
+	c.Text.StartResponse(id)
+	_, _, err = c.Text.ReadResponse(354)
+	c.Text.EndResponse(id)
+	if err != nil {
+		return "", err
+	}
+
+	w := c.Text.DotWriter()
+	if _, err := w.Write(msg); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	_, response, err := c.Text.ReadResponse(250)
+	return response, err
+}
+
+// failureResult reports an email that could not be sent to the client. Rejections by the server are
+// reported with their reply code as status, and are retryable if the failure is transient.
+func (si *SmtpInvoker) failureResult(ctx context.Context, err error) *mcp.CallToolResult {
+	// the deadline of the connection may expire just before the context
+	var netErr net.Error
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return utils.McpCodedError(invocation.ErrorCodeTimeout, "the SMTP server did not complete within %s", si.Timeout)
+	}
+
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) {
+		detail := invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, tpErr.Code)
+		if authFailureCodes[tpErr.Code] {
+			detail = invocation.NewErrorDetail(invocation.ErrorCodeAuth, tpErr.Code)
+		}
+		// 4xx replies are transient failures
+		detail.Retryable = tpErr.Code >= 400 && tpErr.Code < 500
+
+		// the reply is reported as sent by the server, after the context of the failed command if any
+		reply := fmt.Sprintf("%d %s", tpErr.Code, tpErr.Msg)
+		result := utils.McpTextError("the SMTP server rejected the email: %s%s", strings.TrimSuffix(err.Error(), tpErr.Error()), reply)
+		invocation.SetErrorDetail(result, detail)
+		return result
+	}
+
+	return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "failed to send email: %v", err)
+}
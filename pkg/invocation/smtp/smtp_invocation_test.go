@@ -0,0 +1,637 @@
+package smtp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+)
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"email":   {Type: invocation.JsonSchemaTypeString},
+		"id":      {Type: invocation.JsonSchemaTypeString},
+		"message": {Type: invocation.JsonSchemaTypeString},
+	},
+}
+
+// receivedEmail is an email received by the test server.
+type receivedEmail struct {
+	from       string
+	recipients []string
+	data       string
+	tls        bool
+}
+
+// testServer is an SMTP server accepting emails, rejecting recipients starting with 'reject' with a
+// permanent failure, and those starting with 'busy' with a transient one.
+type testServer struct {
+	host        string
+	port        int
+	tlsConfig   *tls.Config // certificate of the server, STARTTLS is not supported if nil
+	implicitTLS bool        // whether connections use TLS from the start
+	auth        bool        // whether the server supports authentication
+
+	mu     sync.Mutex
+	emails []*receivedEmail
+}
+
+func newTestServer(t *testing.T, configure func(s *testServer)) *testServer {
+	t.Helper()
+
+	s := &testServer{}
+	if configure != nil {
+		configure(s)
+	}
+
+	var listener net.Listener
+	var err error
+	if s.implicitTLS {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	addr := listener.Addr().(*net.TCPAddr)
+	s.host, s.port = addr.IP.String(), addr.Port
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *testServer) received() []*receivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.emails
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP test")
+
+	isTLS := s.implicitTLS
+	current := &receivedEmail{}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			extensions := []string{"localhost"}
+			if s.tlsConfig != nil && !isTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if s.auth {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			for i, ext := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				_ = tp.PrintfLine("250%s%s", separator, ext)
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 2.0.0 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, isTLS = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			credentials, _ := base64.StdEncoding.DecodeString(initial)
+			if string(credentials) == "\x00alerts\x00s3cret" {
+				_ = tp.PrintfLine("235 2.7.0 Authentication successful")
+			} else {
+				_ = tp.PrintfLine("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			current.from = strings.TrimSuffix(strings.TrimPrefix(arg, "FROM:<"), ">")
+			_ = tp.PrintfLine("250 2.1.0 Ok")
+		case "RCPT":
+			recipient := strings.TrimSuffix(strings.TrimPrefix(arg, "TO:<"), ">")
+			switch {
+			case strings.HasPrefix(recipient, "reject"):
+				_ = tp.PrintfLine("550 5.1.1 Mailbox unavailable")
+			case strings.HasPrefix(recipient, "busy"):
+				_ = tp.PrintfLine("451 4.3.0 Try again later")
+			default:
+				current.recipients = append(current.recipients, recipient)
+				_ = tp.PrintfLine("250 2.1.5 Ok")
+			}
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data, current.tls = string(data), isTLS
+			s.mu.Lock()
+			s.emails = append(s.emails, current)
+			s.mu.Unlock()
+			current = &receivedEmail{}
+			_ = tp.PrintfLine("250 2.0.0 Ok: queued as 42")
+		case "RSET", "NOOP":
+			_ = tp.PrintfLine("250 2.0.0 Ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+// newCertificate returns the TLS configuration of a server with a self-signed certificate for 127.0.0.1, and
+// the path of the certificate.
+func newCertificate(t *testing.T) (*tls.Config, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "smtp test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, path
+}
+
+// testSmtpInvoker creates an SmtpInvoker for a tool through the invoker factory.
+func testSmtpInvoker(t *testing.T, config *SmtpInvocationConfig) *SmtpInvoker {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{
+		Name:                "notify",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+
+	require.NoError(t, config.Validate())
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, tool)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*SmtpInvoker)
+}
+
+func callTool(invoker *SmtpInvoker, arguments string) (*mcp.CallToolResult, error) {
+	return invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(arguments)},
+	})
+}
+
+func TestSmtpInvoker_Invoke(t *testing.T) {
+	tlsConfig, caFile := newCertificate(t)
+	t.Setenv("GENMCP_TEST_SECRET_SMTP_PASSWORD", "s3cret")
+	ctx := secrets.WithStore(context.Background(), secrets.NewStore(&secrets.EnvProvider{Prefix: "GENMCP_TEST_SECRET_"}))
+
+	tt := []struct {
+		name               string
+		server             func(s *testServer)
+		config             *SmtpInvocationConfig
+		arguments          string
+		expectedRecipients []string
+		expectedData       []string
+		expectedTLS        bool
+		expectedText       string
+		expectedCode       invocation.ErrorCode
+		expectedStatus     int
+		expectedRetryable  bool
+	}{
+		{
+			name: "plain text",
+			config: &SmtpInvocationConfig{
+				TLS:               TLSNone,
+				From:              "Alerts <alerts@example.com>",
+				To:                []string{"{email}"},
+				Cc:                []string{"ops@example.com, Sré Team <sre@example.com>"},
+				Bcc:               []string{"audit@example.com"},
+				AllowedRecipients: []string{"*@example.com"},
+				ReplyTo:           "support@example.com",
+				Subject:           "Incident {id}: résolu",
+				Body:              "Incident {id} is resolved.\n{message}",
+			},
+			arguments:          `{"email":"Bob <bob@example.com>","id":"INC-7","message":"Root cause: disk full"}`,
+			expectedRecipients: []string{"bob@example.com", "ops@example.com", "sre@example.com", "audit@example.com"},
+			expectedData: []string{
+				"From: \"Alerts\" <alerts@example.com>\n",
+				"To: \"Bob\" <bob@example.com>\n",
+				"Cc: <ops@example.com>, =?utf-8?q?Sr=C3=A9_Team?= <sre@example.com>\n",
+				"Reply-To: <support@example.com>\n",
+				"Subject: =?utf-8?q?Incident_INC-7:_r=C3=A9solu?=\n",
+				"Content-Type: text/plain; charset=utf-8\n",
+				"\n\nIncident INC-7 is resolved.\nRoot cause: disk full",
+			},
+			expectedText: "email sent to bob@example.com, ops@example.com, sre@example.com, audit@example.com: 2.0.0 Ok: queued as 42",
+		},
+		{
+			name: "html",
+			config: &SmtpInvocationConfig{
+				TLS:         TLSNone,
+				From:        "alerts@example.com",
+				To:          []string{"ops@example.com"},
+				Subject:     "Report",
+				Body:        "<p>{message}</p>",
+				ContentType: ContentTypeHTML,
+			},
+			arguments:          `{"message":"<script>alert(1)</script>"}`,
+			expectedRecipients: []string{"ops@example.com"},
+			expectedData: []string{
+				"Content-Type: text/html; charset=utf-8\n",
+				"<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+			},
+			expectedText: "email sent to ops@example.com",
+		},
+		{
+			name: "starttls with authentication",
+			server: func(s *testServer) {
+				s.tlsConfig, s.auth = tlsConfig, true
+			},
+			config: &SmtpInvocationConfig{
+				CACertFiles: []string{caFile},
+				Username:    "alerts",
+				Password:    "{secrets.SMTP_PASSWORD}",
+				From:        "alerts@example.com",
+				To:          []string{"ops@example.com"},
+				Subject:     "Report",
+				Body:        "{message}",
+			},
+			arguments:          `{"message":"ok"}`,
+			expectedRecipients: []string{"ops@example.com"},
+			expectedTLS:        true,
+			expectedText:       "email sent to ops@example.com",
+		},
+		{
+			name: "implicit tls",
+			server: func(s *testServer) {
+				s.tlsConfig, s.implicitTLS = tlsConfig, true
+			},
+			config: &SmtpInvocationConfig{
+				TLS:         TLSImplicit,
+				CACertFiles: []string{caFile},
+				From:        "alerts@example.com",
+				To:          []string{"ops@example.com"},
+				Subject:     "Report",
+				Body:        "{message}",
+			},
+			arguments:          `{"message":"ok"}`,
+			expectedRecipients: []string{"ops@example.com"},
+			expectedTLS:        true,
+			expectedText:       "email sent to ops@example.com",
+		},
+		{
+			name: "invalid credentials",
+			server: func(s *testServer) {
+				s.tlsConfig, s.auth = tlsConfig, true
+			},
+			config: &SmtpInvocationConfig{
+				CACertFiles: []string{caFile},
+				Username:    "alerts",
+				Password:    "wrong",
+				From:        "alerts@example.com",
+				To:          []string{"ops@example.com"},
+				Subject:     "Report",
+				Body:        "{message}",
+			},
+			arguments:      `{"message":"ok"}`,
+			expectedText:   "535 5.7.8 Authentication credentials invalid",
+			expectedCode:   invocation.ErrorCodeAuth,
+			expectedStatus: 535,
+		},
+		{
+			name: "untrusted certificate",
+			server: func(s *testServer) {
+				s.tlsConfig = tlsConfig
+			},
+			config: &SmtpInvocationConfig{
+				From:    "alerts@example.com",
+				To:      []string{"ops@example.com"},
+				Subject: "Report",
+				Body:    "{message}",
+			},
+			arguments:         `{"message":"ok"}`,
+			expectedText:      "certificate",
+			expectedCode:      invocation.ErrorCodeBackendUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name: "starttls not supported",
+			config: &SmtpInvocationConfig{
+				From:    "alerts@example.com",
+				To:      []string{"ops@example.com"},
+				Subject: "Report",
+				Body:    "{message}",
+			},
+			arguments:         `{"message":"ok"}`,
+			expectedText:      "the server does not support STARTTLS",
+			expectedCode:      invocation.ErrorCodeBackendUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name: "rejected recipient",
+			config: &SmtpInvocationConfig{
+				TLS:     TLSNone,
+				From:    "alerts@example.com",
+				To:      []string{"ops@example.com", "rejected@example.com"},
+				Subject: "Report",
+				Body:    "{message}",
+			},
+			arguments:      `{"message":"ok"}`,
+			expectedText:   "recipient rejected@example.com: 550 5.1.1 Mailbox unavailable",
+			expectedCode:   invocation.ErrorCodeBackendStatus,
+			expectedStatus: 550,
+		},
+		{
+			name: "transient failure",
+			config: &SmtpInvocationConfig{
+				TLS:     TLSNone,
+				From:    "alerts@example.com",
+				To:      []string{"busy@example.com"},
+				Subject: "Report",
+				Body:    "{message}",
+			},
+			arguments:         `{"message":"ok"}`,
+			expectedText:      "451 4.3.0 Try again later",
+			expectedCode:      invocation.ErrorCodeBackendStatus,
+			expectedStatus:    451,
+			expectedRetryable: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, tc.server)
+			tc.config.Host, tc.config.Port = server.host, server.port
+			invoker := testSmtpInvoker(t, tc.config)
+
+			result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.arguments)},
+			})
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			text := result.Content[0].(*mcp.TextContent).Text
+			assert.Contains(t, text, tc.expectedText)
+
+			if tc.expectedCode != "" {
+				assert.True(t, result.IsError)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				assert.Equal(t, tc.expectedStatus, detail.Status)
+				assert.Equal(t, tc.expectedRetryable, detail.Retryable)
+				assert.Empty(t, server.received())
+				return
+			}
+
+			assert.False(t, result.IsError)
+			received := server.received()
+			require.Len(t, received, 1)
+			assert.Equal(t, "alerts@example.com", received[0].from)
+			assert.Equal(t, tc.expectedRecipients, received[0].recipients)
+			assert.Equal(t, tc.expectedTLS, received[0].tls)
+			for _, expected := range tc.expectedData {
+				assert.Contains(t, received[0].data, expected)
+			}
+			assert.NotContains(t, received[0].data, "Bcc")
+
+			structured := result.StructuredContent.(map[string]any)
+			assert.Equal(t, "2.0.0 Ok: queued as 42", structured["response"])
+			assert.Equal(t, tc.expectedRecipients, structured["recipients"])
+			assert.Contains(t, received[0].data, "Message-ID: "+structured["messageId"].(string)+"\n")
+		})
+	}
+}
+
+func TestSmtpInvoker_InvalidRecipients(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	tt := []struct {
+		name          string
+		arguments     string
+		expectedError string
+	}{
+		{
+			name:          "recipient not allowed",
+			arguments:     `{"email":"mallory@attacker.example"}`,
+			expectedError: "recipient 'mallory@attacker.example' is not allowed",
+		},
+		{
+			name:          "one of several recipients not allowed",
+			arguments:     `{"email":"bob@example.com, mallory@attacker.example"}`,
+			expectedError: "recipient 'mallory@attacker.example' is not allowed",
+		},
+		{
+			name:          "invalid address",
+			arguments:     `{"email":"bob"}`,
+			expectedError: "invalid recipients 'bob'",
+		},
+		{
+			name:          "no recipient",
+			arguments:     `{"email":""}`,
+			expectedError: "the email has no recipient",
+		},
+	}
+
+	invoker := testSmtpInvoker(t, &SmtpInvocationConfig{
+		Host:              server.host,
+		Port:              server.port,
+		TLS:               TLSNone,
+		From:              "alerts@example.com",
+		To:                []string{"{email}"},
+		AllowedRecipients: []string{"*@example.com"},
+		Subject:           "Report",
+		Body:              "ok",
+	})
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := callTool(invoker, tc.arguments)
+			assert.ErrorContains(t, err, tc.expectedError)
+			assert.Equal(t, invocation.ErrorCodeValidation, invocation.CodeOf(err, invocation.ErrorCodeInternal))
+			assert.Empty(t, server.received())
+		})
+	}
+}
+
+func TestSmtpInvoker_Unavailable(t *testing.T) {
+	// a server accepting connections without ever replying
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	silentPort := listener.Addr().(*net.TCPAddr).Port
+
+	// a port nothing listens on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	require.NoError(t, closed.Close())
+
+	tt := []struct {
+		name         string
+		port         int
+		expectedText string
+		expectedCode invocation.ErrorCode
+	}{
+		{
+			name:         "timeout",
+			port:         silentPort,
+			expectedText: "the SMTP server did not complete within 200ms",
+			expectedCode: invocation.ErrorCodeTimeout,
+		},
+		{
+			name:         "connection refused",
+			port:         closedPort,
+			expectedText: "failed to connect to 127.0.0.1:" + strconv.Itoa(closedPort),
+			expectedCode: invocation.ErrorCodeBackendUnavailable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testSmtpInvoker(t, &SmtpInvocationConfig{
+				Host:    "127.0.0.1",
+				Port:    tc.port,
+				TLS:     TLSNone,
+				From:    "alerts@example.com",
+				To:      []string{"ops@example.com"},
+				Subject: "Report",
+				Body:    "ok",
+				Timeout: "200ms",
+			})
+
+			result, err := callTool(invoker, `{}`)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+			detail, ok := invocation.GetErrorDetail(result)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedCode, detail.Code)
+		})
+	}
+}
+
+func TestSmtpInvoker_DryRun(t *testing.T) {
+	invoker := testSmtpInvoker(t, &SmtpInvocationConfig{
+		Host:              "smtp.example.com",
+		From:              "alerts@example.com",
+		To:                []string{"{email}"},
+		Bcc:               []string{"audit@example.com"},
+		AllowedRecipients: []string{"*@example.com"},
+		Subject:           "Incident {id}",
+		Body:              "{message}",
+	})
+
+	result, err := invoker.DryRun(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"email":"bob@example.com","id":"INC-7","message":"resolved"}`)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, InvocationType, result.Type)
+	assert.Equal(t, "smtp://smtp.example.com:587", result.URL)
+	assert.Equal(t, "<bob@example.com>", result.Headers.Get("To"))
+	assert.Equal(t, "<audit@example.com>", result.Headers.Get("Bcc"))
+	assert.Equal(t, "Incident INC-7", result.Headers.Get("Subject"))
+	assert.JSONEq(t, `"resolved"`, string(result.Body))
+}
+
+func TestInvokerFactory_CreateInvoker(t *testing.T) {
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{Name: "notify", InputSchema: testSchema, ResolvedInputSchema: resolved}
+
+	tt := []struct {
+		name          string
+		config        *SmtpInvocationConfig
+		expectedError string
+	}{
+		{
+			name:          "recipient placeholders without allowed recipients",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", From: "alerts@example.com", To: []string{"{email}"}, Subject: "Report", Body: "ok"},
+			expectedError: "allowedRecipients is required when the recipients contain placeholders",
+		},
+		{
+			name:          "password referencing an argument",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", Username: "alerts", Password: "{message}", From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "failed to parse password",
+		},
+		{
+			name:          "missing CA certificate",
+			config:        &SmtpInvocationConfig{Host: "smtp.example.com", CACertFiles: []string{"/nonexistent/ca.pem"}, From: "alerts@example.com", To: []string{"ops@example.com"}, Subject: "Report", Body: "ok"},
+			expectedError: "failed to read CA certificate '/nonexistent/ca.pem'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.config.Validate())
+			_, err := (&InvokerFactory{}).CreateInvoker(tc.config, tool)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite3"
+)
+
+var validDrivers = map[string]bool{
+	DriverPostgres: true,
+	DriverMySQL:    true,
+	DriverSQLite:   true,
+}
+
+// readOnlyStatements are the leading keywords of statements allowed for read-only queries.
+var readOnlyStatements = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"VALUES":   true,
+	"DESCRIBE": true,
+}
+
+// SqlInvocationConfig is the configuration for executing a parameterized SQL query.
+// This is a pure data structure with no parsing logic - all struct tags only.
+type SqlInvocationConfig struct {
+	// The database driver to use.
+	Driver string `json:"driver" jsonschema:"required,enum=postgres,enum=mysql,enum=sqlite3"`
+
+	// The data source name used to connect to the database, in the format expected by the driver.
+	// It can reference environment variables using '${VAR_NAME}' syntax, which are resolved when the first connection is opened.
+	DSN string `json:"dsn" jsonschema:"required"`
+
+	// The SQL query to execute. It can contain placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema.
+	// Placeholders are always sent to the database as bound query parameters, never interpolated into the query text.
+	Query string `json:"query" jsonschema:"required"`
+
+	// If true, only read statements (e.g. SELECT) are accepted and the query is executed in a read-only transaction.
+	ReadOnly bool `json:"readOnly,omitempty" jsonschema:"optional"`
+
+	// Maximum number of open connections to the database. Defaults to unlimited.
+	MaxOpenConns int `json:"maxOpenConns,omitempty" jsonschema:"optional"`
+
+	// Maximum number of idle connections kept in the pool. Defaults to 2.
+	MaxIdleConns int `json:"maxIdleConns,omitempty" jsonschema:"optional"`
+
+	// Maximum amount of time a connection may be reused, as a duration string (e.g. "5m"). Defaults to no limit.
+	ConnMaxLifetime string `json:"connMaxLifetime,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &SqlInvocationConfig{}
+
+func (c *SqlInvocationConfig) Validate() error {
+	if !validDrivers[strings.ToLower(c.Driver)] {
+		return fmt.Errorf("invalid driver '%s': must be one of postgres, mysql, sqlite3", c.Driver)
+	}
+
+	if c.DSN == "" {
+		return fmt.Errorf("dsn is required")
+	}
+
+	if strings.TrimSpace(c.Query) == "" {
+		return fmt.Errorf("query is required")
+	}
+
+	if c.ReadOnly {
+		if keyword := leadingKeyword(c.Query); !readOnlyStatements[keyword] {
+			return fmt.Errorf("read-only query must be a read statement, got '%s'", keyword)
+		}
+	}
+
+	if c.MaxOpenConns < 0 {
+		return fmt.Errorf("maxOpenConns must not be negative")
+	}
+
+	if c.MaxIdleConns < 0 {
+		return fmt.Errorf("maxIdleConns must not be negative")
+	}
+
+	if c.ConnMaxLifetime != "" {
+		if _, err := time.ParseDuration(c.ConnMaxLifetime); err != nil {
+			return fmt.Errorf("invalid connMaxLifetime '%s': %w", c.ConnMaxLifetime, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *SqlInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &SqlInvocationConfig{
+		Driver:          c.Driver,
+		DSN:             c.DSN,
+		Query:           c.Query,
+		ReadOnly:        c.ReadOnly,
+		MaxOpenConns:    c.MaxOpenConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+	}
+}
+
+// leadingKeyword returns the first keyword of a query in upper case, skipping
+// leading whitespace, comments and opening parentheses.
+func leadingKeyword(query string) string {
+	q := query
+	for {
+		q = strings.TrimLeft(q, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(q, "--"):
+			_, rest, found := strings.Cut(q, "\n")
+			if !found {
+				return ""
+			}
+			q = rest
+		case strings.HasPrefix(q, "/*"):
+			_, rest, found := strings.Cut(q, "*/")
+			if !found {
+				return ""
+			}
+			q = rest
+		default:
+			end := strings.IndexFunc(q, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			})
+			if end == -1 {
+				end = len(q)
+			}
+			return strings.ToUpper(q[:end])
+		}
+	}
+}
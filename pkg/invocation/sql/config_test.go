@@ -0,0 +1,112 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name        string
+		config      *SqlInvocationConfig
+		expectError bool
+	}{
+		{
+			name: "valid postgres query",
+			config: &SqlInvocationConfig{
+				Driver: "postgres",
+				DSN:    "${DATABASE_URL}",
+				Query:  "SELECT * FROM users WHERE id = {id}",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid driver",
+			config: &SqlInvocationConfig{
+				Driver: "oracle",
+				DSN:    "dsn",
+				Query:  "SELECT 1",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing dsn",
+			config: &SqlInvocationConfig{
+				Driver: "mysql",
+				Query:  "SELECT 1",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing query",
+			config: &SqlInvocationConfig{
+				Driver: "sqlite3",
+				DSN:    "file.db",
+				Query:  "  ",
+			},
+			expectError: true,
+		},
+		{
+			name: "read-only select with leading comment",
+			config: &SqlInvocationConfig{
+				Driver:   "sqlite3",
+				DSN:      "file.db",
+				Query:    "-- list users\n/* all of them */ (SELECT * FROM users)",
+				ReadOnly: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "read-only with CTE",
+			config: &SqlInvocationConfig{
+				Driver:   "postgres",
+				DSN:      "dsn",
+				Query:    "WITH u AS (SELECT * FROM users) SELECT * FROM u",
+				ReadOnly: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "read-only rejects write statements",
+			config: &SqlInvocationConfig{
+				Driver:   "postgres",
+				DSN:      "dsn",
+				Query:    "DELETE FROM users WHERE id = {id}",
+				ReadOnly: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "negative pool size",
+			config: &SqlInvocationConfig{
+				Driver:       "postgres",
+				DSN:          "dsn",
+				Query:        "SELECT 1",
+				MaxOpenConns: -1,
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid connection lifetime",
+			config: &SqlInvocationConfig{
+				Driver:          "postgres",
+				DSN:             "dsn",
+				Query:           "SELECT 1",
+				ConnMaxLifetime: "forever",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
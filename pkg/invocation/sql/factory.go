@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &SqlInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	sic, ok := config.(*SqlInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for sql invoker factory")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for sql invocations")
+	}
+
+	driver := strings.ToLower(sic.Driver)
+
+	// Create source factories for template parsing
+	sources := template.CreateSourceFactories()
+
+	parsedQuery, err := template.ParseTemplate(sic.Query, template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     sources,
+		Tool:        invocation.ToolName(primitive),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query template: %w", err)
+	}
+
+	for _, v := range parsedQuery.Variables {
+		if v.Type == template.VariableTypeConditional {
+			return nil, fmt.Errorf("query cannot contain conditional block '%s', as values are bound as query parameters", v.Name)
+		}
+	}
+
+	if primitive.PrimitiveType() == "resource" {
+		for _, v := range parsedQuery.Variables {
+			if v.Type == template.VariableTypeParam {
+				return nil, fmt.Errorf("static resource query cannot contain template variables")
+			}
+		}
+	}
+
+	parsedDSN, err := template.ParseTemplate(sic.DSN, template.TemplateParserOptions{Tool: invocation.ToolName(primitive), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dsn: %w", err)
+	}
+	for _, v := range parsedDSN.Variables {
+		if v.Type != template.VariableTypeEnv {
+			return nil, fmt.Errorf("dsn can only reference environment variables, got '%s'", v.Name)
+		}
+	}
+
+	uriTemplate := primitive.GetURITemplate()
+	if uriTemplate != "" {
+		_, err = uritemplate.New(uriTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI template '%s': %w", uriTemplate, err)
+		}
+	}
+
+	var connMaxLifetime time.Duration
+	if sic.ConnMaxLifetime != "" {
+		connMaxLifetime, err = time.ParseDuration(sic.ConnMaxLifetime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connMaxLifetime '%s': %w", sic.ConnMaxLifetime, err)
+		}
+	}
+
+	return &SqlInvoker{
+		Driver:          driver,
+		DSN:             parsedDSN,
+		Query:           bindQuery(parsedQuery, driver),
+		Params:          parsedQuery.Variables,
+		ReadOnly:        sic.ReadOnly,
+		MaxOpenConns:    sic.MaxOpenConns,
+		MaxIdleConns:    sic.MaxIdleConns,
+		ConnMaxLifetime: connMaxLifetime,
+		InputSchema:     primitive.GetResolvedInputSchema(),
+		URITemplate:     uriTemplate,
+	}, nil
+}
+
+// bindPlaceholder renders as a driver bind parameter regardless of the format verb
+// the template parser chose for the variable.
+type bindPlaceholder string
+
+func (p bindPlaceholder) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(p))
+}
+
+// bindQuery replaces every template variable in the parsed query with the bind
+// parameter syntax of the driver, in the order the variables appear.
+func bindQuery(pt *template.ParsedTemplate, driver string) string {
+	placeholders := make([]any, len(pt.Variables))
+	for i := range pt.Variables {
+		if driver == DriverPostgres {
+			placeholders[i] = bindPlaceholder(fmt.Sprintf("$%d", i+1))
+		} else {
+			placeholders[i] = bindPlaceholder("?")
+		}
+	}
+
+	return fmt.Sprintf(pt.Template, placeholders...)
+}
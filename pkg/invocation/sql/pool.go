@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	// database drivers available to sql invocations
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// poolKey identifies a connection pool. Tools with the same driver, dsn and pool
+// settings share a single pool.
+type poolKey struct {
+	driver          string
+	dsn             string
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+}
+
+var (
+	poolsMu sync.Mutex
+	pools   = make(map[poolKey]*sql.DB)
+)
+
+// getPool returns the shared connection pool for the given key, opening it if needed.
+func getPool(key poolKey) (*sql.DB, error) {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+
+	if db, ok := pools[key]; ok {
+		return db, nil
+	}
+
+	db, err := sql.Open(key.driver, key.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", key.driver, err)
+	}
+
+	db.SetMaxOpenConns(key.maxOpenConns)
+	if key.maxIdleConns > 0 {
+		db.SetMaxIdleConns(key.maxIdleConns)
+	}
+	db.SetConnMaxLifetime(key.connMaxLifetime)
+
+	pools[key] = db
+
+	return db, nil
+}
@@ -0,0 +1,506 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type SqlInvoker struct {
+	Driver          string                   // Database driver name
+	DSN             *template.ParsedTemplate // Parsed data source name, may reference environment variables
+	Query           string                   // Query with driver specific bind parameters
+	Params          []template.Variable      // Variables bound to the query parameters, in order
+	ReadOnly        bool                     // Whether the query runs in a read-only transaction
+	MaxOpenConns    int                      // Connection pool size limit
+	MaxIdleConns    int                      // Idle connection limit
+	ConnMaxLifetime time.Duration            // Maximum connection lifetime
+	InputSchema     *jsonschema.Resolved     // InputSchema for the tool
+	URITemplate     string                   // MCP URI template (for resource templates only)
+}
+
+var _ invocation.Invoker = &SqlInvoker{}
+var _ invocation.DryRunner = &SqlInvoker{}
+
+func (si *SqlInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SQL tool invocation")
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build sql arguments")
+	args, err := si.buildArgs(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := si.executeQuery(ctx, args, nil)
+	if err != nil {
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendStatus), "SQL query failed: %v", err), nil
+	}
+
+	result := map[string]any{"rows": rows}
+	text, err := json.Marshal(result)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode query result: %v", err), nil
+	}
+
+	logger.Info("SQL tool invocation completed successfully")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(text),
+			},
+		},
+		StructuredContent: result,
+	}, nil
+}
+
+// DryRun returns the query Invoke would run for req and the values bound to its parameters, without
+// connecting to the database.
+func (si *SqlInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	args, err := si.buildArgs(ctx, req.Params.Arguments, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invocation.DryRunResult{
+		Type:      InvocationType,
+		Query:     si.Query,
+		QueryArgs: args,
+	}, nil
+}
+
+func (si *SqlInvoker) InvokePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SQL prompt invocation")
+
+	promptArgs := req.Params.Arguments
+	if promptArgs == nil {
+		promptArgs = make(map[string]string)
+	}
+
+	argsBytes, err := json.Marshal(promptArgs)
+	if err != nil {
+		logger.Error("Failed to marshal SQL prompt request arguments", zap.Error(err))
+		return nil, fmt.Errorf("failed to prepare prompt request: %w", err)
+	}
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	args, err := si.buildArgs(ctx, argsBytes, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := si.executeQuery(ctx, args, nil)
+	if err != nil {
+		return utils.McpPromptTextError("SQL query failed: %v", err), nil
+	}
+
+	text, err := json.Marshal(map[string]any{"rows": rows})
+	if err != nil {
+		return utils.McpPromptTextError("failed to encode query result: %v", err), nil
+	}
+
+	logger.Info("SQL prompt invocation completed successfully")
+
+	return &mcp.GetPromptResult{
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "assistant",
+				Content: &mcp.TextContent{Text: string(text)},
+			},
+		},
+	}, nil
+}
+
+func (si *SqlInvoker) InvokeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SQL resource invocation", zap.String("uri", req.Params.URI))
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	// Static resources have no parameters from the input schema, only env and header references
+	args, err := si.bindArgs(ctx, map[string]any{}, incomingHeaders)
+	if err != nil {
+		logger.Error("Failed to bind SQL resource query parameters", zap.String("uri", req.Params.URI), zap.Error(err))
+		return nil, fmt.Errorf("failed to bind query parameters: %w", err)
+	}
+
+	return si.readResource(ctx, req.Params.URI, args, map[string]string{"uri": req.Params.URI})
+}
+
+func (si *SqlInvoker) InvokeResourceTemplate(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SQL resource template invocation", zap.String("uri", req.Params.URI))
+
+	// URI template syntax is validated during parsing, so we can safely use it here
+	argsMap := make(map[string]any)
+	uriTmpl, _ := uritemplate.New(si.URITemplate)
+
+	// Match the incoming URI against the template to extract argument values
+	matches := uriTmpl.Match(req.Params.URI)
+	if matches == nil {
+		logger.Error("URI does not match SQL resource template",
+			zap.String("uri", req.Params.URI),
+			zap.String("template", si.URITemplate))
+		return nil, fmt.Errorf("URI does not match template")
+	}
+
+	for _, paramName := range uriTmpl.Varnames() {
+		if val := matches.Get(paramName); val.Valid() {
+			argsMap[paramName] = val.String()
+		} else {
+			logger.Error("Missing required parameter in resource template",
+				zap.String("parameter", paramName),
+				zap.String("uri", req.Params.URI),
+				zap.String("template", si.URITemplate))
+			return nil, fmt.Errorf("missing required parameter: %s", paramName)
+		}
+	}
+
+	argsBytes, err := json.Marshal(argsMap)
+	if err != nil {
+		logger.Error("Failed to marshal SQL resource template arguments",
+			zap.String("uri", req.Params.URI),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to prepare arguments: %w", err)
+	}
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	args, err := si.buildArgs(ctx, argsBytes, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	return si.readResource(ctx, req.Params.URI, args, map[string]string{
+		"uri":      req.Params.URI,
+		"template": si.URITemplate,
+	})
+}
+
+// readResource executes the query and returns the rows as a JSON resource. The resource is not found if the
+// query returns no rows, and other failures of the query are returned as they are.
+func (si *SqlInvoker) readResource(ctx context.Context, uri string, args []any, contextInfo map[string]string) (*mcp.ReadResourceResult, error) {
+	logger := logging.FromContext(ctx)
+
+	rows, err := si.executeQuery(ctx, args, contextInfo)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && len(rows) == 0) {
+		logger.Info("SQL resource query returned no rows", zap.String("uri", uri))
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		logger.Error("SQL resource query failed", zap.String("uri", uri))
+		return nil, invocation.Errorf(invocation.CodeOf(err, invocation.ErrorCodeInternal), "SQL query failed: %w", err)
+	}
+
+	text, err := json.Marshal(rows)
+	if err != nil {
+		logger.Error("Failed to encode SQL resource query result", zap.String("uri", uri), zap.Error(err))
+		return nil, fmt.Errorf("failed to encode query result: %w", err)
+	}
+
+	logger.Info("SQL resource invocation completed successfully", zap.String("uri", uri))
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(text),
+			},
+		},
+	}, nil
+}
+
+// buildArgs parses and validates the request arguments and returns the query parameters.
+func (si *SqlInvoker) buildArgs(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) ([]any, error) {
+	logger := logging.FromContext(ctx)
+
+	dj := &invocation.DynamicJson{}
+
+	parsed, err := dj.ParseJson(argsBytes, si.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := si.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	args, err := si.bindArgs(ctx, parsed, incomingHeaders)
+	if err != nil {
+		logger.Error("Failed to bind query parameters", zap.Error(err))
+		return nil, fmt.Errorf("failed to bind query parameters: %w", err)
+	}
+
+	return args, nil
+}
+
+// bindArgs resolves the value of every query variable. Parameters missing from the
+// arguments are bound as NULL, objects and arrays are bound as JSON strings.
+func (si *SqlInvoker) bindArgs(ctx context.Context, parsed map[string]any, incomingHeaders nethttp.Header) ([]any, error) {
+	args := make([]any, len(si.Params))
+
+	for i, v := range si.Params {
+		switch v.Type {
+		case template.VariableTypeEnv:
+			val, err := v.GetResult()
+			if err != nil {
+				return nil, err
+			}
+			args[i] = val
+		case template.VariableTypeSource:
+			sourceName, fieldName, _ := strings.Cut(v.Name, ".")
+			var resolver template.SourceResolver
+			switch {
+			case sourceName == "secrets":
+				resolver = secrets.FromContext(ctx)
+			case sourceName == "claims":
+				resolver = template.ClaimsFromContext(ctx)
+			case sourceName == "tenant":
+				resolver = template.TenantFromContext(ctx)
+			case incomingHeaders != nil:
+				resolver = template.NewHttpHeaderResolver(incomingHeaders)
+			default:
+				return nil, fmt.Errorf("source '%s' not set", sourceName)
+			}
+			val, err := resolver.Resolve(fieldName)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = val
+		default:
+			val := lookupValue(parsed, v.Name)
+			switch val.(type) {
+			case map[string]any, []any:
+				encoded, err := json.Marshal(val)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode parameter '%s': %w", v.Name, err)
+				}
+				val = string(encoded)
+			}
+			args[i] = val
+		}
+	}
+
+	return args, nil
+}
+
+// executeQuery runs the query against the shared connection pool and returns the rows.
+// Logs sensitive query details to baseLogger only.
+func (si *SqlInvoker) executeQuery(
+	ctx context.Context,
+	args []any,
+	contextInfo map[string]string, // additional context for logging (e.g., "uri", "template")
+) ([]map[string]any, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+
+	logFields := []zap.Field{
+		zap.String("driver", si.Driver),
+		zap.String("query", si.Query),
+		zap.Bool("read_only", si.ReadOnly),
+	}
+	for k, v := range contextInfo {
+		logFields = append(logFields, zap.String(k, v))
+	}
+
+	baseLogger.Debug("Executing SQL query", logFields...)
+
+	db, err := si.pool()
+	if err != nil {
+		baseLogger.Error("Failed to open database connection pool", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to open database connection pool")
+		return nil, err
+	}
+
+	// the query uses bind parameters, so the argument values are not part of the recorded text
+	queryCtx, span := tracing.Start(ctx, "query "+si.Driver,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", si.Driver),
+			attribute.String("db.query.text", si.Query),
+		))
+	rows, err := si.query(queryCtx, db, args)
+	if err == nil {
+		span.SetAttributes(attribute.Int("db.response.returned_rows", len(rows)))
+	}
+	tracing.End(span, err)
+	if err != nil {
+		baseLogger.Error("SQL query execution failed", append(logFields, zap.Error(err))...)
+		logger.Error("SQL query execution failed")
+		return nil, err
+	}
+
+	baseLogger.Info("SQL query executed successfully", append(logFields,
+		zap.Int("row_count", len(rows)))...)
+
+	return rows, nil
+}
+
+// pool resolves the data source name and returns the shared connection pool for it.
+func (si *SqlInvoker) pool() (*sql.DB, error) {
+	dsnBuilder, err := template.NewTemplateBuilder(si.DSN, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dsn builder: %w", err)
+	}
+
+	dsn, err := dsnBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dsn: %w", err)
+	}
+
+	return getPool(poolKey{
+		driver:          si.Driver,
+		dsn:             dsn.(string),
+		maxOpenConns:    si.MaxOpenConns,
+		maxIdleConns:    si.MaxIdleConns,
+		connMaxLifetime: si.ConnMaxLifetime,
+	})
+}
+
+// query executes the query on a dedicated connection. Read-only queries run in a
+// read-only transaction which is always rolled back.
+func (si *SqlInvoker) query(ctx context.Context, db *sql.DB, args []any) ([]map[string]any, error) {
+	if !si.ReadOnly {
+		rows, err := db.QueryContext(ctx, si.Query, args...)
+		if err != nil {
+			return nil, err
+		}
+		return scanRows(rows)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// sqlite ignores the read-only transaction option, so the connection is switched to query only mode instead
+	if si.Driver == DriverSQLite {
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return nil, err
+		}
+		defer func() {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA query_only = OFF")
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, si.Query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRows(rows)
+}
+
+// scanRows reads all rows into maps keyed by column name and closes rows.
+func scanRows(rows *sql.Rows) ([]map[string]any, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]any, 0)
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// lookupValue returns the value at the dot separated path in the parsed arguments, or nil if it is not set.
+func lookupValue(parsed map[string]any, path string) any {
+	var current any = parsed
+	for segment := range strings.SplitSeq(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[segment]
+	}
+
+	return current
+}
@@ -0,0 +1,291 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	resolvedEmpty, _  = (&jsonschema.Schema{Type: invocation.JsonSchemaTypeObject}).Resolve(nil)
+	resolvedWithID, _ = (&jsonschema.Schema{
+		Type: invocation.JsonSchemaTypeObject,
+		Properties: map[string]*jsonschema.Schema{
+			"id":   {Type: invocation.JsonSchemaTypeInteger},
+			"name": {Type: invocation.JsonSchemaTypeString},
+		},
+	}).Resolve(nil)
+)
+
+// testDatabase creates a sqlite database with a populated users table and returns its dsn
+func testDatabase(t *testing.T) string {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := sql.Open(DriverSQLite, dsn)
+	require.NoError(t, err, "failed to open test database")
+	defer func() {
+		_ = db.Close()
+	}()
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT);
+		INSERT INTO users (id, name, email) VALUES (1, 'alice', 'alice@example.com'), (2, 'bob', NULL);
+	`)
+	require.NoError(t, err, "failed to populate test database")
+
+	return dsn
+}
+
+// testSqlInvoker creates a SqlInvoker for testing from a query template
+func testSqlInvoker(t *testing.T, dsn, query string, schema *jsonschema.Resolved, readOnly bool) *SqlInvoker {
+	t.Helper()
+
+	parsedQuery, err := template.ParseTemplate(query, template.TemplateParserOptions{
+		InputSchema: schema.Schema(),
+		Sources:     template.CreateHeadersSourceFactory(),
+	})
+	require.NoError(t, err, "failed to parse query template")
+
+	parsedDSN, err := template.ParseTemplate(dsn, template.TemplateParserOptions{})
+	require.NoError(t, err, "failed to parse dsn")
+
+	return &SqlInvoker{
+		Driver:      DriverSQLite,
+		DSN:         parsedDSN,
+		Query:       bindQuery(parsedQuery, DriverSQLite),
+		Params:      parsedQuery.Variables,
+		ReadOnly:    readOnly,
+		InputSchema: schema,
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		driver   string
+		expected string
+	}{
+		{
+			name:     "postgres numbered parameters",
+			query:    "SELECT * FROM users WHERE id = {id} AND name = {name}",
+			driver:   DriverPostgres,
+			expected: "SELECT * FROM users WHERE id = $1 AND name = $2",
+		},
+		{
+			name:     "mysql positional parameters",
+			query:    "SELECT * FROM users WHERE id = {id} OR id = {id}",
+			driver:   DriverMySQL,
+			expected: "SELECT * FROM users WHERE id = ? OR id = ?",
+		},
+		{
+			name:     "literal percent and headers",
+			query:    "SELECT * FROM users WHERE name LIKE 'a%' AND email = {headers.X-Email}",
+			driver:   DriverSQLite,
+			expected: "SELECT * FROM users WHERE name LIKE 'a%' AND email = ?",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pt, err := template.ParseTemplate(tc.query, template.TemplateParserOptions{
+				InputSchema: resolvedWithID.Schema(),
+				Sources:     template.CreateHeadersSourceFactory(),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, bindQuery(pt, tc.driver))
+		})
+	}
+}
+
+func TestSqlInvocation(t *testing.T) {
+	dsn := testDatabase(t)
+
+	tt := []struct {
+		name         string
+		query        string
+		readOnly     bool
+		args         string
+		headers      http.Header
+		expectedRows []map[string]any
+		expectError  bool
+	}{
+		{
+			name:  "select with bound parameter",
+			query: "SELECT id, name FROM users WHERE id = {id}",
+			args:  `{"id": 1}`,
+			expectedRows: []map[string]any{
+				{"id": float64(1), "name": "alice"},
+			},
+		},
+		{
+			name:         "parameters are not interpolated",
+			query:        "SELECT id FROM users WHERE name = {name}",
+			args:         `{"name": "alice' OR '1'='1"}`,
+			expectedRows: []map[string]any{},
+		},
+		{
+			name:  "missing optional parameter is null",
+			query: "SELECT id FROM users WHERE email IS {name}",
+			args:  `{}`,
+			expectedRows: []map[string]any{
+				{"id": float64(2)},
+			},
+		},
+		{
+			name:    "header source parameter",
+			query:   "SELECT id FROM users WHERE email = {headers.X-Email}",
+			args:    `{}`,
+			headers: http.Header{"X-Email": []string{"alice@example.com"}},
+			expectedRows: []map[string]any{
+				{"id": float64(1)},
+			},
+		},
+		{
+			name:     "read-only query",
+			query:    "SELECT count(*) AS total FROM users",
+			readOnly: true,
+			args:     `{}`,
+			expectedRows: []map[string]any{
+				{"total": float64(2)},
+			},
+		},
+		{
+			name:        "read-only rejects writes",
+			query:       "SELECT 1; DELETE FROM users",
+			readOnly:    true,
+			args:        `{}`,
+			expectError: true,
+		},
+		{
+			name:        "invalid query",
+			query:       "SELECT * FROM missing",
+			args:        `{}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testSqlInvoker(t, dsn, tc.query, resolvedWithID, tc.readOnly)
+
+			req := &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(tc.args)},
+			}
+			if tc.headers != nil {
+				req.Extra = &mcp.RequestExtra{Header: tc.headers}
+			}
+
+			res, err := invoker.Invoke(context.Background(), req)
+			require.NoError(t, err, "sql invocation should not return Go error")
+
+			if tc.expectError {
+				assert.True(t, res.IsError, "result should be an error")
+				return
+			}
+			require.False(t, res.IsError, "result should not be an error: %v", res.Content)
+
+			// compare through JSON so numeric types do not depend on the driver
+			var structured map[string]any
+			raw, err := json.Marshal(res.StructuredContent)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &structured))
+
+			expected, err := json.Marshal(map[string]any{"rows": tc.expectedRows})
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(raw))
+
+			text := res.Content[0].(*mcp.TextContent).Text
+			assert.JSONEq(t, string(expected), text, "text content should contain the rows as JSON")
+		})
+	}
+
+	t.Run("writes are persisted when not read-only", func(t *testing.T) {
+		invoker := testSqlInvoker(t, dsn, "UPDATE users SET name = {name} WHERE id = {id}", resolvedWithID, false)
+		res, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"id": 2, "name": "carol"}`)},
+		})
+		require.NoError(t, err)
+		require.False(t, res.IsError)
+
+		invoker = testSqlInvoker(t, dsn, "SELECT name FROM users WHERE id = 2", resolvedEmpty, true)
+		res, err = invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{}`)},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"rows":[{"name":"carol"}]}`, res.Content[0].(*mcp.TextContent).Text)
+	})
+}
+
+func TestSqlInvocationResourceTemplate(t *testing.T) {
+	dsn := testDatabase(t)
+
+	tt := []struct {
+		name             string
+		query            string
+		uri              string
+		expectedText     string
+		expectedNotFound bool
+		expectedError    string
+	}{
+		{
+			name:         "rows",
+			query:        "SELECT id, email FROM users WHERE name = {name}",
+			uri:          "users://alice",
+			expectedText: `[{"id":1,"email":"alice@example.com"}]`,
+		},
+		{
+			name:             "no rows",
+			query:            "SELECT id, email FROM users WHERE name = {name}",
+			uri:              "users://carol",
+			expectedNotFound: true,
+		},
+		{
+			name:          "query failure",
+			query:         "SELECT id, email FROM accounts WHERE name = {name}",
+			uri:           "users://alice",
+			expectedError: "SQL query failed: no such table: accounts",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testSqlInvoker(t, dsn, tc.query, resolvedWithID, true)
+			invoker.URITemplate = "users://{name}"
+
+			res, err := invoker.InvokeResourceTemplate(context.Background(), &mcp.ReadResourceRequest{
+				Params: &mcp.ReadResourceParams{URI: tc.uri},
+			})
+
+			var rpcErr *jsonrpc.Error
+			switch {
+			case tc.expectedNotFound:
+				require.ErrorAs(t, err, &rpcErr)
+				assert.Equal(t, int64(mcp.CodeResourceNotFound), rpcErr.Code)
+			case tc.expectedError != "":
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.False(t, errors.As(err, &rpcErr), "failures of the query should not be reported as missing resources")
+				assert.Equal(t, invocation.ErrorCodeInternal, invocation.CodeOf(err, invocation.ErrorCodeBackendStatus))
+			default:
+				require.NoError(t, err)
+				require.Len(t, res.Contents, 1)
+				assert.Equal(t, "application/json", res.Contents[0].MIMEType)
+				assert.JSONEq(t, tc.expectedText, res.Contents[0].Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const (
+	// QuotingNone inserts argument values into the command verbatim.
+	QuotingNone = "none"
+	// QuotingShell quotes every argument value as a single shell word.
+	QuotingShell = "shell"
+
+	// DefaultPort is the port of the SSH server if not configured.
+	DefaultPort = 22
+)
+
+var validQuotings = map[string]bool{
+	QuotingNone:  true,
+	QuotingShell: true,
+}
+
+// SshInvocationConfig is the configuration for executing a command on a remote host over SSH.
+// This is a pure data structure with no parsing logic - all struct tags only.
+type SshInvocationConfig struct {
+	// The host the command is executed on, e.g. 'router1.example.com'.
+	// It can contain placeholders in the form of '{paramName}' which correspond to parameters defined in the input schema,
+	// in which case allowedHosts is required.
+	Host string `json:"host" jsonschema:"required"`
+
+	// The port of the SSH server (default: 22).
+	Port int `json:"port,omitempty" jsonschema:"optional"`
+
+	// The hosts the command may be executed on, as names or glob patterns (e.g. '*.edge.example.com').
+	// Hosts that don't match any of them are rejected before connecting.
+	AllowedHosts []string `json:"allowedHosts,omitempty" jsonschema:"optional"`
+
+	// The user to log in as. It can reference environment variables using '${VAR_NAME}' syntax.
+	User string `json:"user" jsonschema:"required"`
+
+	// The command executed on the remote host. It can contain placeholders in the form of '{paramName}' which correspond to
+	// parameters defined in the input schema.
+	Command string `json:"command" jsonschema:"required"`
+
+	// How argument values are inserted into the command (default: shell).
+	// shell quotes every value as a single POSIX shell word, so it is passed to the command as-is; placeholders must then not be put in quotes.
+	// none inserts them verbatim, for hosts whose shell is not a POSIX shell, e.g. the CLI of network devices.
+	Quoting string `json:"quoting,omitempty" jsonschema:"optional,enum=none,enum=shell"`
+
+	// The private key file to authenticate with. It can reference environment variables using '${VAR_NAME}' syntax.
+	PrivateKeyFile string `json:"privateKeyFile,omitempty" jsonschema:"optional"`
+
+	// If true, authenticates with the keys of the SSH agent listening on the SSH_AUTH_SOCK socket.
+	Agent bool `json:"agent,omitempty" jsonschema:"optional"`
+
+	// The known_hosts file holding the keys of the hosts (default: ~/.ssh/known_hosts).
+	// It can reference environment variables using '${VAR_NAME}' syntax.
+	KnownHostsFile string `json:"knownHostsFile,omitempty" jsonschema:"optional"`
+
+	// If true, the key of the host is not verified. Only use it for testing, as it allows machine-in-the-middle attacks.
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty" jsonschema:"optional"`
+
+	// The maximum execution time of the command, including the connection to the host, as a duration string (e.g. "30s").
+	// No timeout if unset.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+
+	// The maximum number of bytes of output (stdout and stderr combined) of the command. The command is stopped
+	// when it is exceeded. No limit if unset.
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &SshInvocationConfig{}
+
+func (c *SshInvocationConfig) Validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+
+	for _, pattern := range c.AllowedHosts {
+		if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("invalid allowed host '%s'", pattern)
+		}
+	}
+
+	if strings.TrimSpace(c.User) == "" {
+		return fmt.Errorf("user is required")
+	}
+
+	if strings.TrimSpace(c.Command) == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	if c.Quoting != "" && !validQuotings[c.Quoting] {
+		return fmt.Errorf("invalid quoting '%s': must be one of none, shell", c.Quoting)
+	}
+
+	if c.PrivateKeyFile == "" && !c.Agent {
+		return fmt.Errorf("privateKeyFile or agent is required")
+	}
+
+	if c.InsecureIgnoreHostKey && c.KnownHostsFile != "" {
+		return fmt.Errorf("knownHostsFile can't be set with insecureIgnoreHostKey")
+	}
+
+	if c.Timeout != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout '%s': %w", c.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+	}
+
+	if c.MaxOutputBytes < 0 {
+		return fmt.Errorf("maxOutputBytes must not be negative")
+	}
+
+	return nil
+}
+
+func (c *SshInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &SshInvocationConfig{
+		Host:                  c.Host,
+		Port:                  c.Port,
+		AllowedHosts:          slices.Clone(c.AllowedHosts),
+		User:                  c.User,
+		Command:               c.Command,
+		Quoting:               c.Quoting,
+		PrivateKeyFile:        c.PrivateKeyFile,
+		Agent:                 c.Agent,
+		KnownHostsFile:        c.KnownHostsFile,
+		InsecureIgnoreHostKey: c.InsecureIgnoreHostKey,
+		Timeout:               c.Timeout,
+		MaxOutputBytes:        c.MaxOutputBytes,
+	}
+}
@@ -0,0 +1,101 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSshInvocationConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *SshInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "private key",
+			config: &SshInvocationConfig{
+				Host:           "{host}",
+				AllowedHosts:   []string{"*.edge.example.com"},
+				User:           "${SSH_USER}",
+				Command:        "show interface {interface}",
+				Quoting:        QuotingNone,
+				PrivateKeyFile: "${HOME}/.ssh/id_ed25519",
+				Timeout:        "10s",
+				MaxOutputBytes: 65536,
+			},
+		},
+		{
+			name:   "agent",
+			config: &SshInvocationConfig{Host: "build1", Port: 2222, User: "ci", Command: "uptime", Agent: true, InsecureIgnoreHostKey: true},
+		},
+		{
+			name:          "missing host",
+			config:        &SshInvocationConfig{User: "ci", Command: "uptime", Agent: true},
+			expectedError: "host is required",
+		},
+		{
+			name:          "invalid port",
+			config:        &SshInvocationConfig{Host: "build1", Port: 70000, User: "ci", Command: "uptime", Agent: true},
+			expectedError: "invalid port 70000",
+		},
+		{
+			name:          "invalid allowed host",
+			config:        &SshInvocationConfig{Host: "{host}", AllowedHosts: []string{"[a-"}, User: "ci", Command: "uptime", Agent: true},
+			expectedError: "invalid allowed host '[a-'",
+		},
+		{
+			name:          "missing user",
+			config:        &SshInvocationConfig{Host: "build1", Command: "uptime", Agent: true},
+			expectedError: "user is required",
+		},
+		{
+			name:          "missing command",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Agent: true},
+			expectedError: "command is required",
+		},
+		{
+			name:          "invalid quoting",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Command: "uptime", Quoting: "powershell", Agent: true},
+			expectedError: "invalid quoting 'powershell'",
+		},
+		{
+			name:          "missing credentials",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Command: "uptime"},
+			expectedError: "privateKeyFile or agent is required",
+		},
+		{
+			name: "known hosts ignored",
+			config: &SshInvocationConfig{
+				Host: "build1", User: "ci", Command: "uptime", Agent: true, KnownHostsFile: "known_hosts", InsecureIgnoreHostKey: true,
+			},
+			expectedError: "knownHostsFile can't be set with insecureIgnoreHostKey",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Command: "uptime", Agent: true, Timeout: "soon"},
+			expectedError: "invalid timeout 'soon'",
+		},
+		{
+			name:          "negative timeout",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Command: "uptime", Agent: true, Timeout: "-1s"},
+			expectedError: "timeout must be positive",
+		},
+		{
+			name:          "negative max output",
+			config:        &SshInvocationConfig{Host: "build1", User: "ci", Command: "uptime", Agent: true, MaxOutputBytes: -1},
+			expectedError: "maxOutputBytes must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
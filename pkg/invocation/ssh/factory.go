@@ -0,0 +1,135 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &SshInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	sic, ok := config.(*SshInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for ssh invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("ssh invocations are only supported for tools")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for ssh invocations")
+	}
+
+	var quote func(string) string
+	if sic.Quoting == "" || sic.Quoting == QuotingShell {
+		quote = shellQuote
+	}
+
+	sources := template.CreateSourceFactories()
+
+	parsedHost, err := template.ParseTemplate(sic.Host, template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     sources,
+		Tool:        primitive.GetName(),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host template: %w", err)
+	}
+	for _, v := range parsedHost.Variables {
+		if v.Type != template.VariableTypeEnv && len(sic.AllowedHosts) == 0 {
+			return nil, fmt.Errorf("allowedHosts is required when the host contains placeholders")
+		}
+	}
+
+	parsedCommand, err := template.ParseTemplate(sic.Command, template.TemplateParserOptions{
+		InputSchema: primitive.GetInputSchema(),
+		Sources:     sources,
+		Quote:       quote,
+		Tool:        primitive.GetName(),
+		Allowlist:   template.PrimitiveAllowlist(primitive),
+		// exploded arrays are passed as separate arguments
+		ExplodeSeparator: " ",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command template: %w", err)
+	}
+
+	invoker := &SshInvoker{
+		Host:                  parsedHost,
+		Port:                  sic.Port,
+		AllowedHosts:          sic.AllowedHosts,
+		Command:               parsedCommand,
+		Agent:                 sic.Agent,
+		InsecureIgnoreHostKey: sic.InsecureIgnoreHostKey,
+		MaxOutputBytes:        sic.MaxOutputBytes,
+		InputSchema:           primitive.GetResolvedInputSchema(),
+	}
+	if invoker.Port == 0 {
+		invoker.Port = DefaultPort
+	}
+
+	if invoker.User, err = parseEnvTemplate("user", sic.User, primitive); err != nil {
+		return nil, err
+	}
+	if invoker.PrivateKeyFile, err = parseEnvTemplate("privateKeyFile", sic.PrivateKeyFile, primitive); err != nil {
+		return nil, err
+	}
+	if invoker.KnownHostsFile, err = parseEnvTemplate("knownHostsFile", sic.KnownHostsFile, primitive); err != nil {
+		return nil, err
+	}
+
+	if sic.Timeout != "" {
+		invoker.Timeout, err = time.ParseDuration(sic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", sic.Timeout, err)
+		}
+	}
+
+	return invoker, nil
+}
+
+// parseEnvTemplate parses the value of field, which can only reference environment variables. It returns nil
+// if value is empty.
+func parseEnvTemplate(field, value string, primitive invocation.Primitive) (*template.ParsedTemplate, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := template.ParseTemplate(value, template.TemplateParserOptions{Tool: primitive.GetName(), Allowlist: template.PrimitiveAllowlist(primitive)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+	}
+	for _, v := range parsed.Variables {
+		if v.Type != template.VariableTypeEnv {
+			return nil, fmt.Errorf("%s can only reference environment variables, got '%s'", field, v.Name)
+		}
+	}
+
+	return parsed, nil
+}
+
+// shellQuote quotes s as a single POSIX shell word, so that it is passed to the command verbatim.
+// Strings made only of characters without special meaning are returned as-is.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_@%+=:,./-", r))
+	}) < 0 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,186 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	sshlib "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// clientKey identifies a client. Tools logging in to the same host with the same credentials share a single
+// connection, each command running in a session of its own.
+type clientKey struct {
+	httpClient            *http.Client // the client whose transport dials the connection
+	address               string
+	user                  string
+	privateKeyFile        string
+	agent                 bool
+	knownHostsFile        string
+	insecureIgnoreHostKey bool
+}
+
+// dialTimeout bounds the time spent connecting to a host and logging in, in addition to the timeout of the
+// invocation.
+const dialTimeout = 30 * time.Second
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[clientKey]*sshlib.Client)
+)
+
+// getClient returns the shared client for the given key, connecting to the host if needed.
+func getClient(ctx context.Context, key clientKey) (*sshlib.Client, error) {
+	clientsMu.Lock()
+	client, ok := clients[key]
+	clientsMu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	// the connection is established without holding the lock, so that a slow host does not block the others
+	client, err := dial(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if existing, ok := clients[key]; ok {
+		_ = client.Close()
+		return existing, nil
+	}
+	clients[key] = client
+
+	go func() {
+		// connections closed by the host are removed from the pool
+		_ = client.Wait()
+		dropClient(key, client)
+	}()
+
+	return client, nil
+}
+
+// dropClient closes client and removes it from the pool, if it is still the client of key.
+func dropClient(key clientKey, client *sshlib.Client) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if clients[key] == client {
+		delete(clients, key)
+	}
+	_ = client.Close()
+}
+
+func dial(ctx context.Context, key clientKey) (*sshlib.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	hostKeyCallback, err := hostKeyCallback(key)
+	if err != nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeAuth, "%w", err)
+	}
+
+	var auth []sshlib.AuthMethod
+	if key.privateKeyFile != "" {
+		signer, err := readPrivateKey(key.privateKeyFile)
+		if err != nil {
+			return nil, invocation.Errorf(invocation.ErrorCodeAuth, "%w", err)
+		}
+		auth = append(auth, sshlib.PublicKeys(signer))
+	}
+	if key.agent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, invocation.Errorf(invocation.ErrorCodeAuth, "failed to connect to the SSH agent: SSH_AUTH_SOCK is not set")
+		}
+		var dialer net.Dialer
+		agentConn, err := dialer.DialContext(ctx, "unix", socket)
+		if err != nil {
+			return nil, invocation.Errorf(invocation.ErrorCodeAuth, "failed to connect to the SSH agent: %w", err)
+		}
+		// the agent is only needed to authenticate
+		defer func() {
+			_ = agentConn.Close()
+		}()
+		auth = append(auth, sshlib.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	// the connection is dialed with the dial function of the network policy
+	conn, err := httpinvocation.DialContextFromContext(ctx)(ctx, "tcp", key.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, chans, reqs, err := sshlib.NewClientConn(conn, key.address, &sshlib.ClientConfig{
+		User:            key.user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		_ = conn.Close()
+		// host keys that could not be verified and rejected credentials are authentication failures
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) || strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, invocation.Errorf(invocation.ErrorCodeAuth, "%w", err)
+		}
+		return nil, err
+	}
+	// the deadline only applies to the handshake, as the connection is shared
+	_ = conn.SetDeadline(time.Time{})
+
+	return sshlib.NewClient(c, chans, reqs), nil
+}
+
+// hostKeyCallback returns the callback verifying the key of the host with the known_hosts file of key.
+func hostKeyCallback(key clientKey) (sshlib.HostKeyCallback, error) {
+	if key.insecureIgnoreHostKey {
+		return sshlib.InsecureIgnoreHostKey(), nil //nolint:gosec // User explicitly requested insecure mode
+	}
+
+	path := key.knownHostsFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the known_hosts file: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts file: %w", err)
+	}
+	return callback, nil
+}
+
+func readPrivateKey(path string) (sshlib.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	signer, err := sshlib.ParsePrivateKey(data)
+	if err != nil {
+		var missing *sshlib.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("private key %s is protected by a passphrase: use the SSH agent instead", path)
+		}
+		return nil, fmt.Errorf("invalid private key %s: %w", path, err)
+	}
+	return signer, nil
+}
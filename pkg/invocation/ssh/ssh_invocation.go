@@ -0,0 +1,457 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	sshlib "golang.org/x/crypto/ssh"
+)
+
+type SshInvoker struct {
+	Host                  *template.ParsedTemplate // Parsed host the command is executed on
+	Port                  int                      // Port of the SSH server
+	AllowedHosts          []string                 // Patterns of the hosts the command may be executed on, any if empty
+	User                  *template.ParsedTemplate // Parsed user to log in as, may reference environment variables
+	Command               *template.ParsedTemplate // Parsed template for the command
+	PrivateKeyFile        *template.ParsedTemplate // Parsed private key file, nil if not set
+	Agent                 bool                     // Whether to authenticate with the keys of the SSH agent
+	KnownHostsFile        *template.ParsedTemplate // Parsed known_hosts file, ~/.ssh/known_hosts if nil
+	InsecureIgnoreHostKey bool                     // Whether the key of the host is not verified
+	Timeout               time.Duration            // Maximum execution time of the command, no timeout if zero
+	MaxOutputBytes        int                      // Maximum output of the command, no limit if zero
+	InputSchema           *jsonschema.Resolved     // InputSchema for the tool
+}
+
+var _ invocation.Invoker = &SshInvoker{}
+var _ invocation.DryRunner = &SshInvoker{}
+
+// remoteCommand is a command to execute on a host.
+type remoteCommand struct {
+	host    string
+	command string
+}
+
+// commandOutput is the output of an executed command.
+type commandOutput struct {
+	combined []byte // stdout and stderr, interleaved as received
+	stdout   []byte
+	stderr   []byte
+	exitCode int // -1 if the command did not exit on its own, e.g. because it was stopped
+}
+
+func (si *SshInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting SSH tool invocation")
+
+	// Extract incoming headers from request
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	buildCtx, buildSpan := tracing.Start(ctx, "build ssh command")
+	rc, err := si.buildCommand(buildCtx, req.Params.Arguments, incomingHeaders)
+	tracing.End(buildSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := si.executeCommand(ctx, rc)
+	if err != nil {
+		return failureResult(out, err), nil
+	}
+
+	logger.Info("SSH tool invocation completed successfully")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(out.combined),
+			},
+		},
+	}, nil
+}
+
+func (si *SshInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("ssh invocations are only supported for tools")
+}
+
+func (si *SshInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("ssh invocations are only supported for tools")
+}
+
+func (si *SshInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("ssh invocations are only supported for tools")
+}
+
+// DryRun returns the command Invoke would execute for req, without connecting to the host.
+func (si *SshInvoker) DryRun(ctx context.Context, req *mcp.CallToolRequest) (*invocation.DryRunResult, error) {
+	var incomingHeaders nethttp.Header
+	if req.Extra != nil {
+		incomingHeaders = req.Extra.Header
+	}
+
+	rc, err := si.buildCommand(ctx, req.Params.Arguments, incomingHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invocation.DryRunResult{
+		Type:    InvocationType,
+		Command: rc.command,
+		URL:     "ssh://" + net.JoinHostPort(rc.host, strconv.Itoa(si.Port)),
+	}, nil
+}
+
+// failureResult reports a failed command execution to the client. out is nil if the command could not be
+// started.
+func failureResult(out *commandOutput, err error) *mcp.CallToolResult {
+	if out == nil {
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeBackendUnavailable), "Command execution failed: %v", err)
+	}
+
+	message := "Command execution failed"
+	detail := invocation.NewErrorDetail(invocation.CodeOf(err, invocation.ErrorCodeInternal), 0)
+	if out.exitCode > 0 {
+		message += fmt.Sprintf(" with exit code %d", out.exitCode)
+		detail = invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, out.exitCode)
+	}
+
+	output := string(out.combined)
+	var ee *sshlib.ExitError
+	if !errors.As(err, &ee) {
+		output = strings.TrimSuffix(err.Error()+"\n"+output, "\n")
+	}
+
+	result := utils.McpTextError("%s:\n%s", message, output)
+	result.StructuredContent = map[string]any{
+		"exitCode": out.exitCode,
+		"stdout":   string(out.stdout),
+		"stderr":   string(out.stderr),
+	}
+	invocation.SetErrorDetail(result, detail)
+	return result
+}
+
+// buildCommand parses and validates the request arguments, and returns the command to execute and the host
+// to execute it on.
+func (si *SshInvoker) buildCommand(ctx context.Context, argsBytes []byte, incomingHeaders nethttp.Header) (*remoteCommand, error) {
+	logger := logging.FromContext(ctx)
+
+	hostBuilder, err := si.newBuilder(ctx, si.Host, incomingHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create host builder: %w", err)
+	}
+	commandBuilder, err := si.newBuilder(ctx, si.Command, incomingHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command builder: %w", err)
+	}
+
+	dj := &invocation.DynamicJson{Builders: []invocation.Builder{hostBuilder, commandBuilder}}
+	parsed, err := dj.ParseJson(argsBytes, si.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+
+	if err := si.InputSchema.Validate(parsed); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	host, err := hostBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build host: %w", err)
+	}
+	if err := si.checkHost(host.(string)); err != nil {
+		logger.Warn("SSH host rejected", zap.Error(err))
+		return nil, err
+	}
+
+	command, err := commandBuilder.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build command: %w", err)
+	}
+
+	return &remoteCommand{host: host.(string), command: command.(string)}, nil
+}
+
+// checkHost returns an error if the command can't be executed on host.
+func (si *SshInvoker) checkHost(host string) error {
+	if host == "" || strings.HasPrefix(host, "-") || strings.ContainsAny(host, " \t\r\n/@") {
+		return invocation.Errorf(invocation.ErrorCodeValidation, "invalid host '%s'", host)
+	}
+
+	if len(si.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, pattern := range si.AllowedHosts {
+		if matched, _ := path.Match(pattern, host); matched {
+			return nil
+		}
+	}
+	return invocation.Errorf(invocation.ErrorCodeValidation, "host '%s' is not allowed", host)
+}
+
+// newBuilder creates a new builder of parsed. A new builder is created for each invocation to avoid sharing
+// state.
+func (si *SshInvoker) newBuilder(ctx context.Context, parsed *template.ParsedTemplate, incomingHeaders nethttp.Header) (*template.TemplateBuilder, error) {
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.SetSourceResolver("secrets", secrets.FromContext(ctx))
+	builder.SetSourceResolver("claims", template.ClaimsFromContext(ctx))
+	builder.SetSourceResolver("tenant", template.TenantFromContext(ctx))
+
+	if incomingHeaders != nil {
+		builder.SetSourceResolver("headers", template.NewHttpHeaderResolver(incomingHeaders))
+	}
+
+	return builder, nil
+}
+
+// clientKey returns the key of the client connecting to host with the HTTP client of ctx.
+func (si *SshInvoker) clientKey(ctx context.Context, host string) (clientKey, error) {
+	key := clientKey{
+		httpClient:            httpinvocation.HTTPClientFromContext(ctx),
+		address:               net.JoinHostPort(host, strconv.Itoa(si.Port)),
+		agent:                 si.Agent,
+		insecureIgnoreHostKey: si.InsecureIgnoreHostKey,
+	}
+
+	var err error
+	if key.user, err = resolveEnv(si.User); err != nil {
+		return key, fmt.Errorf("failed to build user: %w", err)
+	}
+	if key.privateKeyFile, err = resolveEnv(si.PrivateKeyFile); err != nil {
+		return key, fmt.Errorf("failed to build privateKeyFile: %w", err)
+	}
+	if key.knownHostsFile, err = resolveEnv(si.KnownHostsFile); err != nil {
+		return key, fmt.Errorf("failed to build knownHostsFile: %w", err)
+	}
+
+	return key, nil
+}
+
+// resolveEnv resolves the environment variables referenced by parsed, returning an empty string if it is nil.
+func resolveEnv(parsed *template.ParsedTemplate) (string, error) {
+	if parsed == nil {
+		return "", nil
+	}
+
+	builder, err := template.NewTemplateBuilder(parsed, false)
+	if err != nil {
+		return "", err
+	}
+	result, err := builder.GetResult()
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// executeCommand executes rc in a new session of the shared connection to its host. Returns the output of
+// the command, which is nil if the command could not be started, and the error. Logs the command to
+// baseLogger only, as it may contain sensitive arguments.
+func (si *SshInvoker) executeCommand(ctx context.Context, rc *remoteCommand) (*commandOutput, error) {
+	logger := logging.FromContext(ctx)
+	baseLogger := logging.BaseFromContext(ctx)
+	logFields := []zap.Field{zap.String("host", rc.host), zap.String("command", rc.command)}
+
+	key, err := si.clientKey(ctx, rc.host)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	if si.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, si.Timeout)
+	}
+	defer cancel()
+
+	// the command itself is not recorded on the span, as it may contain sensitive arguments
+	_, span := tracing.Start(ctx, "exec ssh command")
+	span.SetAttributes(attribute.String("server.address", rc.host))
+	defer span.End()
+
+	session, err := newSession(runCtx, key)
+	if err != nil {
+		if si.Timeout > 0 && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = invocation.Errorf(invocation.ErrorCodeTimeout, "connection timed out after %s", si.Timeout)
+		}
+		tracing.End(span, err)
+		baseLogger.Error("Failed to connect to SSH host", append(logFields, zap.Error(err))...)
+		logger.Error("Failed to connect to SSH host", zap.String("host", rc.host))
+		return nil, err
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	baseLogger.Debug("Executing SSH command", logFields...)
+
+	out := &outputBuffer{max: si.MaxOutputBytes, cancel: cancel}
+	session.Stdout = stdoutWriter{out}
+	session.Stderr = out
+
+	if err := session.Start(rc.command); err != nil {
+		tracing.End(span, err)
+		baseLogger.Error("Failed to start SSH command", append(logFields, zap.Error(err))...)
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		// stop the command, closing the session if the host ignores the signal
+		_ = session.Signal(sshlib.SIGKILL)
+		_ = session.Close()
+		err = <-done
+		if err == nil {
+			err = runCtx.Err()
+		}
+	}
+
+	output := out.output()
+	var ee *sshlib.ExitError
+	if errors.As(err, &ee) {
+		output.exitCode = ee.ExitStatus()
+	} else if err == nil {
+		output.exitCode = 0
+	}
+	switch {
+	case out.exceeded:
+		err = invocation.Errorf(invocation.ErrorCodeInternal, "command output exceeded the limit of %d bytes", si.MaxOutputBytes)
+	case err != nil && si.Timeout > 0 && errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		err = invocation.Errorf(invocation.ErrorCodeTimeout, "command timed out after %s", si.Timeout)
+	}
+
+	span.SetAttributes(attribute.Int("process.exit.code", output.exitCode))
+	tracing.End(span, err)
+	if err != nil {
+		baseLogger.Error("SSH command execution failed", append(logFields,
+			zap.Int("exit_code", output.exitCode),
+			zap.String("stdout", string(output.stdout)),
+			zap.String("stderr", string(output.stderr)),
+			zap.Error(err))...)
+		logger.Error("SSH command execution failed", zap.Int("exit_code", output.exitCode))
+		return output, err
+	}
+
+	baseLogger.Info("SSH command executed successfully", append(logFields,
+		zap.Int("output_length", len(output.combined)))...)
+
+	return output, nil
+}
+
+// newSession opens a session on the shared client of key. A broken connection of the pool is replaced
+// with a new one.
+func newSession(ctx context.Context, key clientKey) (*sshlib.Session, error) {
+	client, err := getClient(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err == nil {
+		return session, nil
+	}
+
+	dropClient(key, client)
+	client, err = getClient(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewSession()
+}
+
+// outputBuffer collects the combined output of a command, and its stdout and stderr separately. Once
+// more than max bytes are written, it stops collecting and cancels the command, while still accepting
+// writes so that the session does not block. It is the stderr of the command, and stdoutWriter its stdout.
+type outputBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	max      int // no limit if zero
+	exceeded bool
+	cancel   context.CancelFunc
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	return b.write(p, false)
+}
+
+func (b *outputBuffer) write(p []byte, isStdout bool) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.exceeded {
+		return len(p), nil
+	}
+
+	collected := p
+	if b.max > 0 && b.buf.Len()+len(p) > b.max {
+		collected = p[:b.max-b.buf.Len()]
+		b.exceeded = true
+		b.cancel()
+	}
+
+	b.buf.Write(collected)
+	if isStdout {
+		b.stdout.Write(collected)
+	} else {
+		b.stderr.Write(collected)
+	}
+
+	return len(p), nil
+}
+
+// output returns the output collected, with an exit code of -1.
+func (b *outputBuffer) output() *commandOutput {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &commandOutput{
+		combined: bytes.Clone(b.buf.Bytes()),
+		stdout:   bytes.Clone(b.stdout.Bytes()),
+		stderr:   bytes.Clone(b.stderr.Bytes()),
+		exitCode: -1,
+	}
+}
+
+// stdoutWriter writes the stdout of a command to an outputBuffer.
+type stdoutWriter struct {
+	*outputBuffer
+}
+
+func (w stdoutWriter) Write(p []byte) (int, error) {
+	return w.write(p, true)
+}
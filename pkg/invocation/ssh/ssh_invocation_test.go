@@ -0,0 +1,453 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sshlib "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// testSSHServer is an SSH server accepting a single client key. Commands starting with 'fail' write to
+// stderr and exit with code 2, the 'sleep' command runs until it is killed, and other commands print
+// 'ran: <command>'.
+type testSSHServer struct {
+	host    string
+	port    int
+	hostKey sshlib.PublicKey
+
+	mu          sync.Mutex
+	commands    []string
+	connections []net.Conn
+}
+
+func newTestSSHServer(t *testing.T, clientKey sshlib.PublicKey) *testSSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := sshlib.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &sshlib.ServerConfig{
+		PublicKeyCallback: func(_ sshlib.ConnMetadata, key sshlib.PublicKey) (*sshlib.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, errors.New("unknown key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	addr := listener.Addr().(*net.TCPAddr)
+	s := &testSSHServer{host: addr.IP.String(), port: addr.Port, hostKey: hostSigner.PublicKey()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+
+	return s
+}
+
+func (s *testSSHServer) serve(conn net.Conn, config *sshlib.ServerConfig) {
+	_, chans, reqs, err := sshlib.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	s.mu.Lock()
+	s.connections = append(s.connections, conn)
+	s.mu.Unlock()
+
+	go sshlib.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(sshlib.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *testSSHServer) handleSession(channel sshlib.Channel, requests <-chan *sshlib.Request) {
+	defer func() { _ = channel.Close() }()
+
+	for req := range requests {
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			_ = sshlib.Unmarshal(req.Payload, &payload)
+			_ = req.Reply(true, nil)
+
+			s.mu.Lock()
+			s.commands = append(s.commands, payload.Command)
+			s.mu.Unlock()
+
+			var status uint32
+			switch {
+			case payload.Command == "sleep":
+				continue
+			case strings.HasPrefix(payload.Command, "fail"):
+				_, _ = channel.Stderr().Write([]byte("error: failed\n"))
+				status = 2
+			default:
+				_, _ = channel.Write([]byte("ran: " + payload.Command + "\n"))
+			}
+			_, _ = channel.SendRequest("exit-status", false, sshlib.Marshal(struct{ Status uint32 }{status}))
+			return
+		case "signal":
+			return
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *testSSHServer) executed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+// closeConnections closes the connections of the clients, as a restarted host would.
+func (s *testSSHServer) closeConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.connections {
+		_ = conn.Close()
+	}
+}
+
+func (s *testSSHServer) connectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.connections)
+}
+
+// testKeys creates a client key, and returns it with the path of its private key file.
+func testKeys(t *testing.T) (ed25519.PrivateKey, string) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := sshlib.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+
+	return priv, path
+}
+
+func publicKey(t *testing.T, priv ed25519.PrivateKey) sshlib.PublicKey {
+	t.Helper()
+
+	key, err := sshlib.NewPublicKey(priv.Public())
+	require.NoError(t, err)
+	return key
+}
+
+// writeKnownHosts writes a known_hosts file holding key for server, and returns its path.
+func writeKnownHosts(t *testing.T, server *testSSHServer, key sshlib.PublicKey) string {
+	t.Helper()
+
+	address := knownhosts.Normalize(net.JoinHostPort(server.host, strconv.Itoa(server.port)))
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(path, []byte(knownhosts.Line([]string{address}, key)+"\n"), 0o600))
+
+	return path
+}
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"host":      {Type: invocation.JsonSchemaTypeString},
+		"interface": {Type: invocation.JsonSchemaTypeString},
+	},
+}
+
+// testSshInvoker creates an SshInvoker for a tool through the invoker factory.
+func testSshInvoker(t *testing.T, config *SshInvocationConfig) *SshInvoker {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{
+		Name:                "show_interface",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+
+	require.NoError(t, config.Validate())
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, tool)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*SshInvoker)
+}
+
+func invoke(t *testing.T, invoker *SshInvoker, arguments string) (*mcp.CallToolResult, error) {
+	t.Helper()
+
+	return invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(arguments)},
+	})
+}
+
+func TestSshInvoker_Invoke(t *testing.T) {
+	tt := []struct {
+		name             string
+		modify           func(config *SshInvocationConfig)
+		arguments        string
+		wrongHostKey     bool
+		expectedText     string
+		expectedCode     invocation.ErrorCode
+		expectedStatus   int
+		expectedCommands []string
+	}{
+		{
+			name:             "quoted arguments",
+			arguments:        `{"interface":"eth0; reboot"}`,
+			expectedText:     "ran: show interface 'eth0; reboot'\n",
+			expectedCommands: []string{"show interface 'eth0; reboot'"},
+		},
+		{
+			name: "verbatim arguments",
+			modify: func(config *SshInvocationConfig) {
+				config.Quoting = QuotingNone
+			},
+			arguments:        `{"interface":"GigabitEthernet0/1"}`,
+			expectedText:     "ran: show interface GigabitEthernet0/1\n",
+			expectedCommands: []string{"show interface GigabitEthernet0/1"},
+		},
+		{
+			name: "exit code",
+			modify: func(config *SshInvocationConfig) {
+				config.Command = "fail {interface}"
+			},
+			arguments:        `{"interface":"eth0"}`,
+			expectedText:     "Command execution failed with exit code 2:\nerror: failed\n",
+			expectedCode:     invocation.ErrorCodeBackendStatus,
+			expectedStatus:   2,
+			expectedCommands: []string{"fail eth0"},
+		},
+		{
+			name: "timeout",
+			modify: func(config *SshInvocationConfig) {
+				config.Command = "sleep"
+				config.Timeout = "200ms"
+			},
+			arguments:        `{}`,
+			expectedText:     "command timed out after 200ms",
+			expectedCode:     invocation.ErrorCodeTimeout,
+			expectedCommands: []string{"sleep"},
+		},
+		{
+			name:         "unknown host key",
+			arguments:    `{"interface":"eth0"}`,
+			wrongHostKey: true,
+			expectedText: "key mismatch",
+			expectedCode: invocation.ErrorCodeAuth,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			priv, keyFile := testKeys(t)
+			server := newTestSSHServer(t, publicKey(t, priv))
+			hostKey := server.hostKey
+			if tc.wrongHostKey {
+				otherKey, _ := testKeys(t)
+				hostKey = publicKey(t, otherKey)
+			}
+
+			config := &SshInvocationConfig{
+				Host:           server.host,
+				Port:           server.port,
+				User:           "netops",
+				Command:        "show interface {interface}",
+				PrivateKeyFile: keyFile,
+				KnownHostsFile: writeKnownHosts(t, server, hostKey),
+			}
+			if tc.modify != nil {
+				tc.modify(config)
+			}
+			invoker := testSshInvoker(t, config)
+
+			result, err := invoke(t, invoker, tc.arguments)
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+			assert.Equal(t, tc.expectedCommands, server.executed())
+
+			if tc.expectedCode != "" {
+				assert.True(t, result.IsError)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				assert.Equal(t, tc.expectedStatus, detail.Status)
+				return
+			}
+			assert.False(t, result.IsError)
+		})
+	}
+}
+
+func TestSshInvoker_SharedConnection(t *testing.T) {
+	priv, keyFile := testKeys(t)
+	server := newTestSSHServer(t, publicKey(t, priv))
+	invoker := testSshInvoker(t, &SshInvocationConfig{
+		Host:           server.host,
+		Port:           server.port,
+		User:           "netops",
+		Command:        "show interface {interface}",
+		PrivateKeyFile: keyFile,
+		KnownHostsFile: writeKnownHosts(t, server, server.hostKey),
+	})
+
+	for _, name := range []string{"eth0", "eth1"} {
+		result, err := invoke(t, invoker, `{"interface":"`+name+`"}`)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	}
+	assert.Equal(t, 1, server.connectionCount(), "commands should share a single connection")
+
+	// the next command reconnects once the host closed the connection
+	server.closeConnections()
+	require.Eventually(t, func() bool {
+		result, err := invoke(t, invoker, `{"interface":"eth2"}`)
+		return err == nil && !result.IsError
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, 2, server.connectionCount())
+}
+
+func TestSshInvoker_Agent(t *testing.T) {
+	priv, _ := testKeys(t)
+	server := newTestSSHServer(t, publicKey(t, priv))
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+
+	invoker := testSshInvoker(t, &SshInvocationConfig{
+		Host:           server.host,
+		Port:           server.port,
+		User:           "netops",
+		Command:        "show interface {interface}",
+		Agent:          true,
+		KnownHostsFile: writeKnownHosts(t, server, server.hostKey),
+	})
+
+	result, err := invoke(t, invoker, `{"interface":"eth0"}`)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "ran: show interface eth0\n", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestSshInvoker_AllowedHosts(t *testing.T) {
+	tt := []struct {
+		name          string
+		host          string
+		expectedError string
+	}{
+		{
+			name:          "host not allowed",
+			host:          "db1.example.com",
+			expectedError: "host 'db1.example.com' is not allowed",
+		},
+		{
+			name:          "option injection",
+			host:          "-oProxyCommand=reboot",
+			expectedError: "invalid host '-oProxyCommand=reboot'",
+		},
+		{
+			name:          "user in host",
+			host:          "root@router1.edge.example.com",
+			expectedError: "invalid host 'root@router1.edge.example.com'",
+		},
+	}
+
+	invoker := testSshInvoker(t, &SshInvocationConfig{
+		Host:                  "{host}",
+		AllowedHosts:          []string{"*.edge.example.com"},
+		User:                  "netops",
+		Command:               "show version",
+		Agent:                 true,
+		InsecureIgnoreHostKey: true,
+	})
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := invoke(t, invoker, `{"host":"`+tc.host+`"}`)
+			assert.ErrorContains(t, err, tc.expectedError)
+			assert.Equal(t, invocation.ErrorCodeValidation, invocation.CodeOf(err, invocation.ErrorCodeInternal))
+		})
+	}
+}
+
+func TestSshInvoker_DryRun(t *testing.T) {
+	invoker := testSshInvoker(t, &SshInvocationConfig{
+		Host:                  "{host}",
+		AllowedHosts:          []string{"*.edge.example.com"},
+		User:                  "netops",
+		Command:               "show interface {interface}",
+		Agent:                 true,
+		InsecureIgnoreHostKey: true,
+	})
+
+	result, err := invoker.DryRun(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"host":"router1.edge.example.com","interface":"eth0 1"}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "show interface 'eth0 1'", result.Command)
+	assert.Equal(t, "ssh://router1.edge.example.com:22", result.URL)
+}
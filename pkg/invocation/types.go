@@ -36,6 +36,20 @@ type InvocationConfig interface {
 	DeepCopy() InvocationConfig
 }
 
+// FileReferencer is implemented by invocation configs reading local files, e.g. descriptor sets or
+// certificates, so that the files can be packaged with the MCP file.
+type FileReferencer interface {
+	// ReferencedFiles returns the paths of the local files read by the invocation, as written in its config.
+	ReferencedFiles() []string
+}
+
+// URLReferencer is implemented by invocation configs sending requests to URLs, so that the URLs can be checked
+// against the network policy of the server when the MCP file is loaded.
+type URLReferencer interface {
+	// ReferencedURLs returns the URLs the invocation sends requests to, as written in its config.
+	ReferencedURLs() []string
+}
+
 type Primitive interface {
 	GetName() string
 	GetDescription() string
@@ -46,6 +60,7 @@ type Primitive interface {
 	GetRequiredScopes() []string
 	GetResolvedInputSchema() *jsonschema.Resolved
 	GetURITemplate() string
+	GetResponseTransform() *ResponseTransform
 	PrimitiveType() string
 }
 
@@ -56,8 +71,8 @@ type InvokerFactory interface {
 
 // InvocationConfigWrapper wraps an invocation configuration with its type.
 // In JSON, this is represented as an object with a single key indicating the type
-// (one of "http", "cli", or "extends") and the value being the configuration.
-// Example: {"http": {...}} or {"cli": {...}} or {"extends": {...}}
+// (one of "http", "cli", "sql", "file", "grpc", "proxy", "inline", "plugin", "queue", "k8s", "ssh", "script", "wasm", "smtp", or "extends") and the value being the configuration.
+// Example: {"http": {...}} or {"cli": {...}} or {"sql": {...}} or {"file": {...}} or {"grpc": {...}} or {"proxy": {...}} or {"inline": {...}} or {"plugin": {...}} or {"queue": {...}} or {"k8s": {...}} or {"ssh": {...}} or {"script": {...}} or {"wasm": {...}} or {"smtp": {...}} or {"extends": {...}}
 type InvocationConfigWrapper struct {
 	Type   string           `json:"-"`
 	Config InvocationConfig `json:"-"`
@@ -127,6 +142,66 @@ func (w InvocationConfigWrapper) JSONSchema() *invopopschema.Schema {
 		Ref: "#/$defs/CliInvocationConfig",
 	})
 
+	sqlProps := invopopschema.NewProperties()
+	sqlProps.Set("sql", &invopopschema.Schema{
+		Ref: "#/$defs/SqlInvocationConfig",
+	})
+
+	fileProps := invopopschema.NewProperties()
+	fileProps.Set("file", &invopopschema.Schema{
+		Ref: "#/$defs/FileInvocationConfig",
+	})
+
+	grpcProps := invopopschema.NewProperties()
+	grpcProps.Set("grpc", &invopopschema.Schema{
+		Ref: "#/$defs/GrpcInvocationConfig",
+	})
+
+	proxyProps := invopopschema.NewProperties()
+	proxyProps.Set("proxy", &invopopschema.Schema{
+		Ref: "#/$defs/ProxyInvocationConfig",
+	})
+
+	inlineProps := invopopschema.NewProperties()
+	inlineProps.Set("inline", &invopopschema.Schema{
+		Ref: "#/$defs/InlineInvocationConfig",
+	})
+
+	pluginProps := invopopschema.NewProperties()
+	pluginProps.Set("plugin", &invopopschema.Schema{
+		Ref: "#/$defs/PluginInvocationConfig",
+	})
+
+	queueProps := invopopschema.NewProperties()
+	queueProps.Set("queue", &invopopschema.Schema{
+		Ref: "#/$defs/QueueInvocationConfig",
+	})
+
+	k8sProps := invopopschema.NewProperties()
+	k8sProps.Set("k8s", &invopopschema.Schema{
+		Ref: "#/$defs/K8sInvocationConfig",
+	})
+
+	sshProps := invopopschema.NewProperties()
+	sshProps.Set("ssh", &invopopschema.Schema{
+		Ref: "#/$defs/SshInvocationConfig",
+	})
+
+	scriptProps := invopopschema.NewProperties()
+	scriptProps.Set("script", &invopopschema.Schema{
+		Ref: "#/$defs/ScriptInvocationConfig",
+	})
+
+	wasmProps := invopopschema.NewProperties()
+	wasmProps.Set("wasm", &invopopschema.Schema{
+		Ref: "#/$defs/WasmInvocationConfig",
+	})
+
+	smtpProps := invopopschema.NewProperties()
+	smtpProps.Set("smtp", &invopopschema.Schema{
+		Ref: "#/$defs/SmtpInvocationConfig",
+	})
+
 	extendsProps := invopopschema.NewProperties()
 	extendsProps.Set("extends", &invopopschema.Schema{
 		Ref: "#/$defs/ExtendsConfig",
@@ -148,6 +223,90 @@ func (w InvocationConfigWrapper) JSONSchema() *invopopschema.Schema {
 				AdditionalProperties: invopopschema.FalseSchema,
 				Description:          "An invocation configuration using CLI.",
 			},
+			{
+				Type:                 "object",
+				Properties:           sqlProps,
+				Required:             []string{"sql"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration using a SQL query.",
+			},
+			{
+				Type:                 "object",
+				Properties:           fileProps,
+				Required:             []string{"file"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration reading, writing or listing files under a root directory.",
+			},
+			{
+				Type:                 "object",
+				Properties:           grpcProps,
+				Required:             []string{"grpc"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration calling a unary gRPC method.",
+			},
+			{
+				Type:                 "object",
+				Properties:           proxyProps,
+				Required:             []string{"proxy"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration forwarding calls to a tool of an upstream MCP server.",
+			},
+			{
+				Type:                 "object",
+				Properties:           inlineProps,
+				Required:             []string{"inline"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration returning the messages of a prompt or the content of a resource defined in the MCP file.",
+			},
+			{
+				Type:                 "object",
+				Properties:           pluginProps,
+				Required:             []string{"plugin"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration running an external plugin, exchanging JSON over its standard input and output.",
+			},
+			{
+				Type:                 "object",
+				Properties:           queueProps,
+				Required:             []string{"queue"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration publishing a message to a message broker.",
+			},
+			{
+				Type:                 "object",
+				Properties:           k8sProps,
+				Required:             []string{"k8s"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration performing an operation on a resource of the Kubernetes API.",
+			},
+			{
+				Type:                 "object",
+				Properties:           sshProps,
+				Required:             []string{"ssh"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration executing a command on a remote host over SSH.",
+			},
+			{
+				Type:                 "object",
+				Properties:           scriptProps,
+				Required:             []string{"script"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration running a Starlark script.",
+			},
+			{
+				Type:                 "object",
+				Properties:           wasmProps,
+				Required:             []string{"wasm"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration running a WebAssembly module with WASI.",
+			},
+			{
+				Type:                 "object",
+				Properties:           smtpProps,
+				Required:             []string{"smtp"},
+				AdditionalProperties: invopopschema.FalseSchema,
+				Description:          "An invocation configuration sending an email through an SMTP server.",
+			},
 			{
 				Type:                 "object",
 				Properties:           extendsProps,
@@ -156,6 +315,6 @@ func (w InvocationConfigWrapper) JSONSchema() *invopopschema.Schema {
 				Description:          "An invocation configuration that extends a base configuration.",
 			},
 		},
-		Description: "A wrapper for invocation configurations. Must contain exactly one invocation type key (http, cli, or extends) with its corresponding configuration.",
+		Description: "A wrapper for invocation configurations. Must contain exactly one invocation type key (http, cli, sql, file, grpc, proxy, inline, plugin, queue, k8s, ssh, script, wasm, smtp, or extends) with its corresponding configuration.",
 	}
 }
@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -34,3 +36,11 @@ func McpResourceTextError(format string, args ...any) *mcp.ReadResourceResult {
 		},
 	}
 }
+
+// McpCodedError returns a failed tool result like McpTextError, described by the ErrorDetail of a
+// failure of code.
+func McpCodedError(code invocation.ErrorCode, format string, args ...any) *mcp.CallToolResult {
+	result := McpTextError(format, args...)
+	invocation.SetErrorDetail(result, invocation.NewErrorDetail(code, 0))
+	return result
+}
@@ -0,0 +1,103 @@
+package wasm
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+var digestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// WasmInvocationConfig is the configuration for executing a WebAssembly module compiled for WASI in the
+// embedded runtime, which can only access the arguments of the tool it reads from its standard input and the
+// environment variables of its config: no files, no network and no other environment variables.
+type WasmInvocationConfig struct {
+	// The path of the module. Relative paths are resolved in the working directory.
+	Module string `json:"module,omitempty" jsonschema:"optional"`
+
+	// The OCI artifact holding the module, e.g. 'ghcr.io/acme/geo-tools:1.2.0', pulled on the first call
+	// with the Docker credentials of the server.
+	Image string `json:"image,omitempty" jsonschema:"optional"`
+
+	// The sha256 digest of the module, e.g. 'sha256:2c26b4...'. Modules with another digest are not executed.
+	Digest string `json:"digest,omitempty" jsonschema:"optional"`
+
+	// The arguments of the module.
+	Args []string `json:"args,omitempty" jsonschema:"optional"`
+
+	// Environment variables of the module, which doesn't see the environment of the server.
+	Env map[string]string `json:"env,omitempty" jsonschema:"optional"`
+
+	// Maximum size of the linear memory of the module, in bytes, rounded up to 64 KiB pages. Defaults to 4 GiB,
+	// the limit of WebAssembly.
+	MaxMemoryBytes int `json:"maxMemoryBytes,omitempty" jsonschema:"optional"`
+
+	// Maximum duration of the invocation, as a duration string (e.g. "10s"). Defaults to no limit.
+	Timeout string `json:"timeout,omitempty" jsonschema:"optional"`
+}
+
+var _ invocation.InvocationConfig = &WasmInvocationConfig{}
+var _ invocation.FileReferencer = &WasmInvocationConfig{}
+
+func (c *WasmInvocationConfig) Validate() error {
+	if c.Module == "" && c.Image == "" {
+		return fmt.Errorf("module or image is required")
+	}
+	if c.Module != "" && c.Image != "" {
+		return fmt.Errorf("module and image can't both be set")
+	}
+
+	if c.Image != "" {
+		if _, err := name.ParseReference(c.Image); err != nil {
+			return fmt.Errorf("invalid image '%s': %w", c.Image, err)
+		}
+	}
+
+	if c.Digest != "" && !digestRegexp.MatchString(c.Digest) {
+		return fmt.Errorf("invalid digest '%s': must be 'sha256:' followed by 64 lowercase hexadecimal digits", c.Digest)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.Env)) {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("invalid environment variable name '%s'", key)
+		}
+	}
+
+	if c.MaxMemoryBytes < 0 {
+		return fmt.Errorf("maxMemoryBytes must not be negative")
+	}
+
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout '%s': must be a positive duration", c.Timeout)
+		}
+	}
+
+	return nil
+}
+
+func (c *WasmInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &WasmInvocationConfig{
+		Module:         c.Module,
+		Image:          c.Image,
+		Digest:         c.Digest,
+		Args:           slices.Clone(c.Args),
+		Env:            maps.Clone(c.Env),
+		MaxMemoryBytes: c.MaxMemoryBytes,
+		Timeout:        c.Timeout,
+	}
+}
+
+// ReferencedFiles returns the module if it is a local file, so that it is packaged with the MCP file.
+func (c *WasmInvocationConfig) ReferencedFiles() []string {
+	if c.Module == "" {
+		return nil
+	}
+	return []string{c.Module}
+}
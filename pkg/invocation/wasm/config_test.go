@@ -0,0 +1,80 @@
+package wasm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWasmInvocationConfig_Validate(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	tt := []struct {
+		name          string
+		config        *WasmInvocationConfig
+		expectedError string
+	}{
+		{
+			name: "local module",
+			config: &WasmInvocationConfig{
+				Module:         "./geo.wasm",
+				Digest:         digest,
+				Args:           []string{"--verbose"},
+				Env:            map[string]string{"UNITS": "metric"},
+				MaxMemoryBytes: 64 << 20,
+				Timeout:        "10s",
+			},
+		},
+		{
+			name:   "image",
+			config: &WasmInvocationConfig{Image: "ghcr.io/acme/geo-tools:1.2.0", Digest: digest},
+		},
+		{
+			name:          "missing module",
+			config:        &WasmInvocationConfig{},
+			expectedError: "module or image is required",
+		},
+		{
+			name:          "module and image",
+			config:        &WasmInvocationConfig{Module: "./geo.wasm", Image: "ghcr.io/acme/geo-tools:1.2.0"},
+			expectedError: "module and image can't both be set",
+		},
+		{
+			name:          "invalid image",
+			config:        &WasmInvocationConfig{Image: "ghcr.io/acme/Geo Tools"},
+			expectedError: "invalid image 'ghcr.io/acme/Geo Tools'",
+		},
+		{
+			name:          "invalid digest",
+			config:        &WasmInvocationConfig{Module: "./geo.wasm", Digest: "sha256:abc"},
+			expectedError: "invalid digest 'sha256:abc'",
+		},
+		{
+			name:          "invalid environment variable",
+			config:        &WasmInvocationConfig{Module: "./geo.wasm", Env: map[string]string{"A=B": "c"}},
+			expectedError: "invalid environment variable name 'A=B'",
+		},
+		{
+			name:          "negative max memory",
+			config:        &WasmInvocationConfig{Module: "./geo.wasm", MaxMemoryBytes: -1},
+			expectedError: "maxMemoryBytes must not be negative",
+		},
+		{
+			name:          "invalid timeout",
+			config:        &WasmInvocationConfig{Module: "./geo.wasm", Timeout: "0s"},
+			expectedError: "invalid timeout '0s'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package wasm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+type InvokerFactory struct{}
+
+func (f *InvokerFactory) NewConfig() invocation.InvocationConfig {
+	return &WasmInvocationConfig{}
+}
+
+func (f *InvokerFactory) CreateInvoker(config invocation.InvocationConfig, primitive invocation.Primitive) (invocation.Invoker, error) {
+	wic, ok := config.(*WasmInvocationConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid InvocationConfig type for wasm invoker factory")
+	}
+
+	if primitive.PrimitiveType() != "tool" {
+		return nil, fmt.Errorf("wasm invocations are only supported for tools")
+	}
+
+	if primitive.GetResponseTransform() != nil {
+		return nil, fmt.Errorf("response transforms are not supported for wasm invocations")
+	}
+
+	invoker := &WasmInvoker{
+		Module:         wic.Module,
+		Image:          wic.Image,
+		Digest:         wic.Digest,
+		Args:           wic.Args,
+		Env:            wic.Env,
+		MaxMemoryBytes: wic.MaxMemoryBytes,
+		InputSchema:    primitive.GetResolvedInputSchema(),
+	}
+
+	if wic.Timeout != "" {
+		var err error
+		invoker.Timeout, err = time.ParseDuration(wic.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout '%s': %w", wic.Timeout, err)
+		}
+	}
+
+	return invoker, nil
+}
@@ -0,0 +1,163 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// ModuleMediaTypes are the media types of the layers of OCI artifacts holding a module.
+var ModuleMediaTypes = []types.MediaType{
+	"application/wasm",
+	"application/vnd.wasm.content.layer.v1+wasm",
+	"application/vnd.module.wasm.content.layer.v1+wasm",
+}
+
+// maxModuleBytes bounds the size of the modules pulled from registries.
+const maxModuleBytes = 256 << 20
+
+// wasmMagic starts the binary format of WebAssembly modules.
+var wasmMagic = []byte("\x00asm")
+
+// cacheDir is the directory the pulled modules are written to, named after their digest.
+var cacheDir = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "genmcp", "wasm"), nil
+}
+
+// pullModule pulls the module of the artifact image, authenticating with the Docker credentials, and
+// returns the path it is cached at. If digest is set, the module must have this digest.
+func pullModule(ctx context.Context, image, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", invocation.Errorf(invocation.ErrorCodeInternal, "invalid image '%s': %w", image, err)
+	}
+
+	img, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to pull %s: %w", image, err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to pull %s: %w", image, err)
+	}
+
+	for _, desc := range manifest.Layers {
+		if !slices.Contains(ModuleMediaTypes, desc.MediaType) {
+			continue
+		}
+		if digest != "" && desc.Digest.String() != digest {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "the module of %s has digest %s instead of %s", image, desc.Digest, digest)
+		}
+
+		dir, err := cacheDir()
+		if err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "failed to find the cache directory: %w", err)
+		}
+		path := filepath.Join(dir, desc.Digest.Hex+".wasm")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to pull %s: %w", image, err)
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to pull %s: %w", image, err)
+		}
+		defer func() { _ = rc.Close() }()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxModuleBytes+1))
+		if err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeBackendUnavailable, "failed to pull %s: %w", image, err)
+		}
+		if len(data) > maxModuleBytes {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "the module of %s exceeds %d bytes", image, maxModuleBytes)
+		}
+		// the registry is not trusted to return the blob of the digest
+		if actual := "sha256:" + sha256Hex(data); actual != desc.Digest.String() {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "the module of %s has digest %s instead of %s", image, actual, desc.Digest)
+		}
+		if err := checkModule(data); err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "invalid module of %s: %w", image, err)
+		}
+
+		if err := writeModule(path, data); err != nil {
+			return "", invocation.Errorf(invocation.ErrorCodeInternal, "failed to cache the module of %s: %w", image, err)
+		}
+		return path, nil
+	}
+
+	return "", invocation.Errorf(invocation.ErrorCodeInternal, "no layer of %s is a WebAssembly module", image)
+}
+
+// readModule reads the module at path, which must have the given digest if set.
+func readModule(path, digest string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeInternal, "failed to read module: %w", err)
+	}
+	if err := checkModule(data); err != nil {
+		return nil, invocation.Errorf(invocation.ErrorCodeInternal, "invalid module %s: %w", path, err)
+	}
+	if digest == "" {
+		return data, nil
+	}
+	if actual := "sha256:" + sha256Hex(data); actual != digest {
+		return nil, invocation.Errorf(invocation.ErrorCodeInternal, "module %s has digest %s instead of %s", path, actual, digest)
+	}
+	return data, nil
+}
+
+// checkModule checks that data is in the binary format of WebAssembly modules.
+func checkModule(data []byte) error {
+	if !bytes.HasPrefix(data, wasmMagic) {
+		return fmt.Errorf("not a WebAssembly module")
+	}
+	return nil
+}
+
+// writeModule writes data to path through a temporary file, so that a module is never executed while it
+// is written.
+func writeModule(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".module-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// pageBytes is the size of a page of the linear memory of modules.
+const pageBytes = 64 << 10
+
+// sharedRuntime is a WebAssembly runtime with WASI, and the modules it compiled.
+type sharedRuntime struct {
+	runtime wazero.Runtime
+	modules map[string]wazero.CompiledModule // by digest
+}
+
+var (
+	runtimesMu sync.Mutex
+	runtimes   = make(map[uint32]*sharedRuntime) // by memory limit, in pages
+)
+
+// compileModule returns the runtime limiting the memory of modules to maxMemoryBytes, the limit of WebAssembly
+// if zero, and data compiled by it. The tools running the same module with the same limit share its
+// compilation.
+func compileModule(ctx context.Context, data []byte, maxMemoryBytes int) (wazero.Runtime, wazero.CompiledModule, error) {
+	// 65536 pages are the limit of WebAssembly
+	limitPages := uint32(0)
+	if maxMemoryBytes > 0 && maxMemoryBytes < 65536*pageBytes {
+		limitPages = uint32((maxMemoryBytes + pageBytes - 1) / pageBytes)
+	}
+
+	runtimesMu.Lock()
+	defer runtimesMu.Unlock()
+
+	r, ok := runtimes[limitPages]
+	if !ok {
+		config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+		if limitPages > 0 {
+			config = config.WithMemoryLimitPages(limitPages)
+		}
+		wr := wazero.NewRuntimeWithConfig(context.Background(), config)
+		if _, err := wasi_snapshot_preview1.Instantiate(context.Background(), wr); err != nil {
+			_ = wr.Close(context.Background())
+			return nil, nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+		}
+
+		r = &sharedRuntime{runtime: wr, modules: make(map[string]wazero.CompiledModule)}
+		runtimes[limitPages] = r
+	}
+
+	digest := sha256Hex(data)
+	if compiled, ok := r.modules[digest]; ok {
+		return r.runtime, compiled, nil
+	}
+
+	compiled, err := r.runtime.CompileModule(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.modules[digest] = compiled
+
+	return r.runtime, compiled, nil
+}
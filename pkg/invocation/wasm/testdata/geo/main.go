@@ -0,0 +1,53 @@
+// Command geo is the module of the tests of wasm invocations, built for WASI. It reads the arguments of the
+// tool from its standard input, and writes them to its standard output with its arguments and environment.
+// Cities select other behaviors: 'fail' exits with code 3, 'loop' does not complete, 'memory' allocates
+// 256 MiB, and 'files' reads the root directory.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+var sink []byte
+
+func main() {
+	var input struct {
+		City string `json:"city"`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid input: %v\n", err)
+		os.Exit(1)
+	}
+
+	output := map[string]any{
+		"city": input.City,
+		"args": os.Args[1:],
+		"env":  os.Environ(),
+	}
+
+	switch input.City {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "error: unknown city")
+		os.Exit(3)
+	case "loop":
+		for i := 0; ; i++ {
+			sink = []byte{byte(i)}
+		}
+	case "memory":
+		sink = make([]byte, 256<<20)
+		for i := range sink {
+			sink[i] = 1
+		}
+	case "files":
+		entries, err := os.ReadDir("/")
+		if err != nil {
+			output["error"] = err.Error()
+		} else {
+			output["files"] = len(entries)
+		}
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(output)
+}
@@ -0,0 +1,218 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/sys"
+	"go.uber.org/zap"
+)
+
+type WasmInvoker struct {
+	Module         string               // Path of the module, if it is a local file
+	Image          string               // OCI artifact holding the module, if it is pulled from a registry
+	Digest         string               // Digest the module must have, not checked if empty
+	Args           []string             // Arguments of the module
+	Env            map[string]string    // Environment variables of the module
+	MaxMemoryBytes int                  // Maximum size of the memory of the module, the limit of WebAssembly if zero
+	Timeout        time.Duration        // Maximum duration of the invocation, no timeout if zero
+	InputSchema    *jsonschema.Resolved // InputSchema for the tool
+
+	mu       sync.Mutex
+	runtime  wazero.Runtime        // runtime of the compiled module, set on the first call
+	compiled wazero.CompiledModule // verified and compiled module, set on the first call
+}
+
+var _ invocation.Invoker = &WasmInvoker{}
+
+func (wi *WasmInvoker) Invoke(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Debug("Starting wasm tool invocation")
+
+	dj := &invocation.DynamicJson{}
+	arguments, err := dj.ParseJson(req.Params.Arguments, wi.InputSchema.Schema())
+	if err != nil {
+		logger.Error("Failed to parse request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to parse request: %w", err)
+	}
+	if err := wi.InputSchema.Validate(arguments); err != nil {
+		logger.Error("Failed to validate request arguments", zap.Error(err))
+		return nil, invocation.Errorf(invocation.ErrorCodeValidation, "failed to validate request: %w", err)
+	}
+
+	input, err := json.Marshal(arguments)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to encode the arguments of the module: %v", err), nil
+	}
+
+	runtime, compiled, err := wi.module(ctx)
+	if err != nil {
+		logger.Error("Failed to load module", zap.Error(err))
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "%v", err), nil
+	}
+
+	runCtx, span := tracing.Start(ctx, "run wasm module")
+	out, err := wi.run(runCtx, runtime, compiled, input)
+	tracing.End(span, err)
+	if err != nil {
+		logger.Error("Module execution failed", zap.Error(err))
+		return failureResult(err), nil
+	}
+
+	logger.Info("Wasm tool invocation completed successfully")
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(out)},
+		},
+	}
+	// modules writing a JSON object also return it as structured content
+	var object map[string]any
+	if err := json.Unmarshal(out, &object); err == nil && object != nil {
+		result.StructuredContent = object
+	}
+
+	return result, nil
+}
+
+func (wi *WasmInvoker) InvokePrompt(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return nil, fmt.Errorf("wasm invocations are only supported for tools")
+}
+
+func (wi *WasmInvoker) InvokeResource(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("wasm invocations are only supported for tools")
+}
+
+func (wi *WasmInvoker) InvokeResourceTemplate(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, fmt.Errorf("wasm invocations are only supported for tools")
+}
+
+// module returns the compiled module and its runtime, pulling the module or checking its digest and compiling
+// it on the first call. Failures are retried on the next call.
+func (wi *WasmInvoker) module(ctx context.Context) (wazero.Runtime, wazero.CompiledModule, error) {
+	wi.mu.Lock()
+	defer wi.mu.Unlock()
+
+	if wi.compiled != nil {
+		return wi.runtime, wi.compiled, nil
+	}
+
+	path := wi.Module
+	if wi.Image != "" {
+		var err error
+		path, err = pullModule(ctx, wi.Image, wi.Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	data, err := readModule(path, wi.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	runtime, compiled, err := compileModule(ctx, data, wi.MaxMemoryBytes)
+	if err != nil {
+		return nil, nil, invocation.Errorf(invocation.ErrorCodeInternal, "failed to compile module %s: %w", path, err)
+	}
+
+	wi.runtime, wi.compiled = runtime, compiled
+	return runtime, compiled, nil
+}
+
+// moduleConfig returns the config of an instance of the module reading input on its standard input. The
+// module is only given its arguments, the environment variables of the config, the clocks and a source of
+// random numbers: no files and no sockets.
+func (wi *WasmInvoker) moduleConfig(input []byte, stdout, stderr io.Writer) wazero.ModuleConfig {
+	config := wazero.NewModuleConfig().
+		// the module is instantiated for every call, possibly concurrently, and instances must have unique names
+		WithName("").
+		WithArgs(append([]string{"module"}, wi.Args...)...).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep().
+		WithRandSource(rand.Reader)
+	for _, name := range slices.Sorted(maps.Keys(wi.Env)) {
+		config = config.WithEnv(name, wi.Env[name])
+	}
+	return config
+}
+
+// run runs a new instance of the compiled module with input on its standard input, and returns its standard
+// output.
+func (wi *WasmInvoker) run(ctx context.Context, runtime wazero.Runtime, compiled wazero.CompiledModule, input []byte) ([]byte, error) {
+	if wi.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wi.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	mod, err := runtime.InstantiateModule(ctx, compiled, wi.moduleConfig(input, &stdout, &stderr))
+	if mod != nil {
+		_ = mod.Close(ctx)
+	}
+
+	var exitErr *sys.ExitError
+	switch {
+	case err == nil:
+		return stdout.Bytes(), nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, invocation.Errorf(invocation.ErrorCodeTimeout, "module did not complete within %s", wi.Timeout)
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.As(err, &exitErr):
+		return nil, &exitError{exitCode: int(exitErr.ExitCode()), stderr: strings.TrimSpace(stderr.String())}
+	}
+
+	// the module trapped, e.g. when it exceeded its memory
+	return nil, &exitError{trap: err, stderr: strings.TrimSpace(stderr.String())}
+}
+
+// exitError is the error of a module that exited with a non-zero exit code, or trapped.
+type exitError struct {
+	exitCode int
+	trap     error // the trap of the module, if it trapped
+	stderr   string
+}
+
+func (e *exitError) Error() string {
+	msg := fmt.Sprintf("module exited with code %d", e.exitCode)
+	if e.trap != nil {
+		msg = fmt.Sprintf("module trapped: %v", e.trap)
+	}
+	if e.stderr == "" {
+		return msg
+	}
+	return msg + ": " + e.stderr
+}
+
+// failureResult reports a failed module execution to the client.
+func failureResult(err error) *mcp.CallToolResult {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		result := utils.McpTextError("%v", err)
+		invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, ee.exitCode))
+		return result
+	}
+	return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "%v", err)
+}
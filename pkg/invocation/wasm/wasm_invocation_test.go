@@ -0,0 +1,311 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// geoModule is the path of the module built from testdata/geo, and geoData its content.
+var (
+	geoModule string
+	geoData   []byte
+)
+
+// TestMain builds the module of testdata/geo for WASI, so that the tests run a real module.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "genmcp-wasm-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the directory of the test module: %v\n", err)
+		os.Exit(1)
+	}
+
+	geoModule = filepath.Join(dir, "geo.wasm")
+	cmd := exec.Command("go", "build", "-o", geoModule, "./testdata/geo")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build the test module: %v\n%s", err, out)
+		os.Exit(1)
+	}
+	if geoData, err = os.ReadFile(geoModule); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read the test module: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var testSchema = &jsonschema.Schema{
+	Type: invocation.JsonSchemaTypeObject,
+	Properties: map[string]*jsonschema.Schema{
+		"city": {Type: invocation.JsonSchemaTypeString},
+	},
+}
+
+func writeTestModule(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "geo.wasm")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+// testWasmInvoker creates a WasmInvoker for a tool through the invoker factory.
+func testWasmInvoker(t *testing.T, config *WasmInvocationConfig) *WasmInvoker {
+	t.Helper()
+
+	resolved, err := testSchema.Resolve(nil)
+	require.NoError(t, err)
+	tool := &definitions.Tool{
+		Name:                "get_coordinates",
+		InputSchema:         testSchema,
+		ResolvedInputSchema: resolved,
+	}
+
+	require.NoError(t, config.Validate())
+	invoker, err := (&InvokerFactory{}).CreateInvoker(config, tool)
+	require.NoError(t, err, "failed to create invoker")
+
+	return invoker.(*WasmInvoker)
+}
+
+func invoke(t *testing.T, invoker *WasmInvoker, arguments string) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(arguments)},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	return result
+}
+
+func TestWasmInvoker_Invoke(t *testing.T) {
+	tt := []struct {
+		name               string
+		config             *WasmInvocationConfig
+		arguments          string
+		expectedText       string
+		expectedStructure  map[string]any
+		expectedFilesError bool // the module can't read the files of the server
+		expectedCode       invocation.ErrorCode
+		expectedStatus     int
+	}{
+		{
+			name: "result",
+			config: &WasmInvocationConfig{
+				Module:         geoModule,
+				Digest:         "sha256:" + sha256Hex(geoData),
+				Args:           []string{"--verbose"},
+				Env:            map[string]string{"UNITS": "metric", "API_KEY": "k"},
+				MaxMemoryBytes: 64 << 20,
+			},
+			arguments: `{"city":"Paris"}`,
+			expectedStructure: map[string]any{
+				"city": "Paris",
+				"args": []any{"--verbose"},
+				"env":  []any{"API_KEY=k", "UNITS=metric"},
+			},
+		},
+		{
+			name:      "no files",
+			config:    &WasmInvocationConfig{Module: geoModule},
+			arguments: `{"city":"files"}`,
+			expectedStructure: map[string]any{
+				"city": "files",
+				"args": []any{},
+				"env":  []any{},
+			},
+			expectedFilesError: true,
+		},
+		{
+			name:           "exit code",
+			config:         &WasmInvocationConfig{Module: geoModule},
+			arguments:      `{"city":"fail"}`,
+			expectedText:   "module exited with code 3: error: unknown city",
+			expectedCode:   invocation.ErrorCodeBackendStatus,
+			expectedStatus: 3,
+		},
+		{
+			name:           "memory limit",
+			config:         &WasmInvocationConfig{Module: geoModule, MaxMemoryBytes: 64 << 20},
+			arguments:      `{"city":"memory"}`,
+			expectedText:   "out of memory",
+			expectedCode:   invocation.ErrorCodeBackendStatus,
+			expectedStatus: 2,
+		},
+		{
+			name:         "timeout",
+			config:       &WasmInvocationConfig{Module: geoModule, Timeout: "200ms"},
+			arguments:    `{"city":"loop"}`,
+			expectedText: "module did not complete within 200ms",
+			expectedCode: invocation.ErrorCodeTimeout,
+		},
+		{
+			name:         "digest mismatch",
+			config:       &WasmInvocationConfig{Module: geoModule, Digest: "sha256:" + strings.Repeat("0", 64)},
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "has digest sha256:" + sha256Hex(geoData),
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "not a module",
+			config:       &WasmInvocationConfig{Module: writeTestModule(t, []byte("#!/bin/sh"))},
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "not a WebAssembly module",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+		{
+			name:         "invalid module",
+			config:       &WasmInvocationConfig{Module: writeTestModule(t, []byte("\x00asm\x01\x00\x00\x00\x01"))},
+			arguments:    `{"city":"Paris"}`,
+			expectedText: "failed to compile module",
+			expectedCode: invocation.ErrorCodeInternal,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			invoker := testWasmInvoker(t, tc.config)
+
+			result := invoke(t, invoker, tc.arguments)
+			text := result.Content[0].(*mcp.TextContent).Text
+
+			if tc.expectedCode != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tc.expectedText)
+				detail, ok := invocation.GetErrorDetail(result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedCode, detail.Code)
+				assert.Equal(t, tc.expectedStatus, detail.Status)
+				return
+			}
+
+			assert.False(t, result.IsError, text)
+			structured := result.StructuredContent.(map[string]any)
+			if tc.expectedFilesError {
+				assert.NotEmpty(t, structured["error"])
+				delete(structured, "error")
+			}
+			assert.Equal(t, tc.expectedStructure, structured)
+		})
+	}
+}
+
+func TestWasmInvoker_ConcurrentCalls(t *testing.T) {
+	invoker := testWasmInvoker(t, &WasmInvocationConfig{Module: geoModule})
+
+	var wg sync.WaitGroup
+	for _, city := range []string{"Paris", "Lyon", "Nice", "Lille"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"city":"` + city + `"}`)},
+			})
+			if assert.NoError(t, err) && assert.False(t, result.IsError) {
+				assert.Equal(t, city, result.StructuredContent.(map[string]any)["city"])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// pushModule pushes an artifact holding module to a new registry, and returns its reference.
+func pushModule(t *testing.T, module []byte, mediaType types.MediaType) string {
+	t.Helper()
+
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+
+	image := strings.TrimPrefix(srv.URL, "http://") + "/tools/geo:1.0.0"
+	img, err := mutate.Append(empty.Image, mutate.Addendum{Layer: static.NewLayer(module, mediaType)})
+	require.NoError(t, err)
+	ref, err := name.ParseReference(image)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	return image
+}
+
+func TestWasmInvoker_Image(t *testing.T) {
+	tt := []struct {
+		name         string
+		mediaType    types.MediaType
+		digest       string
+		expectedText string
+	}{
+		{
+			name:         "pinned module",
+			mediaType:    "application/vnd.wasm.content.layer.v1+wasm",
+			digest:       "sha256:" + sha256Hex(geoData),
+			expectedText: `"city":"Paris"`,
+		},
+		{
+			name:         "unpinned module",
+			mediaType:    "application/wasm",
+			expectedText: `"city":"Paris"`,
+		},
+		{
+			name:         "digest mismatch",
+			mediaType:    "application/wasm",
+			digest:       "sha256:" + strings.Repeat("0", 64),
+			expectedText: "has digest sha256:" + sha256Hex(geoData) + " instead of sha256:" + strings.Repeat("0", 64),
+		},
+		{
+			name:         "no module",
+			mediaType:    "application/octet-stream",
+			expectedText: "is a WebAssembly module",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			defaultCacheDir := cacheDir
+			cacheDir = func() (string, error) { return dir, nil }
+			t.Cleanup(func() { cacheDir = defaultCacheDir })
+
+			invoker := testWasmInvoker(t, &WasmInvocationConfig{
+				Image:  pushModule(t, geoData, tc.mediaType),
+				Digest: tc.digest,
+			})
+
+			result := invoke(t, invoker, `{"city":"Paris"}`)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+			if result.IsError {
+				return
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, sha256Hex(geoData)+".wasm"))
+			require.NoError(t, err)
+			assert.Equal(t, geoData, data)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package invoke runs the primitives of an MCP file locally, without an MCP server or client, to test
+// and debug their definitions.
+package invoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+
+	// register the invocation types, so that their configs can be parsed
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/file"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/script"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/wasm"
+)
+
+// FindTool returns the tool named name, validated the same way the server validates it on startup.
+func FindTool(defs *definitions.MCPToolDefinitions, name string) (*definitions.Tool, error) {
+	return findByName("tool", defs.Tools, name)
+}
+
+// FindPrompt returns the prompt named name, validated the same way the server validates it on startup.
+func FindPrompt(defs *definitions.MCPToolDefinitions, name string) (*definitions.Prompt, error) {
+	return findByName("prompt", defs.Prompts, name)
+}
+
+type validatable interface {
+	comparable
+	GetName() string
+	Validate(invocationValidator definitions.InvocationValidator) error
+}
+
+func findByName[T validatable](kind string, prims []T, name string) (T, error) {
+	var zero T
+	names := make([]string, 0, len(prims))
+	for _, prim := range prims {
+		if prim == zero {
+			continue
+		}
+		if prim.GetName() == name {
+			if err := prim.Validate(invocation.InvocationValidator); err != nil {
+				return zero, err
+			}
+			return prim, nil
+		}
+		names = append(names, prim.GetName())
+	}
+
+	return zero, fmt.Errorf("no %s named '%s' in the MCP file, available %ss: %s", kind, name, kind, strings.Join(names, ", "))
+}
+
+// InvokeTool calls tool with args the same way the server handles a tools/call request, including the
+// validation of its output against its output schema.
+func InvokeTool(ctx context.Context, tool *definitions.Tool, args json.RawMessage) (*mcp.CallToolResult, error) {
+	invoker, err := invocation.CreateInvoker(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoker for tool %s: %w", tool.Name, err)
+	}
+
+	req, err := toolRequest(tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := invoker.Invoke(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if tool.OutputSchema != nil && !result.IsError {
+		if err := invocation.ValidateToolOutput(result, tool.OutputSchema, tool.ResolvedOutputSchema, tool.CoerceOutputTypes); err != nil {
+			return utils.McpTextError("tool output does not match outputSchema: %v", err), nil
+		}
+	}
+
+	return result, nil
+}
+
+// GetPrompt gets prompt with args the same way the server handles a prompts/get request.
+func GetPrompt(ctx context.Context, prompt *definitions.Prompt, args map[string]string) (*mcp.GetPromptResult, error) {
+	invoker, err := invocation.CreatePromptInvoker(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoker for prompt %s: %w", prompt.Name, err)
+	}
+
+	return invoker.InvokePrompt(ctx, &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{
+			Name:      prompt.Name,
+			Arguments: args,
+		},
+	})
+}
+
+// ReadResource reads the resource at uri the same way the server handles a resources/read request: from
+// the resource with this URI, or else from the first resource template matching it.
+func ReadResource(ctx context.Context, defs *definitions.MCPToolDefinitions, uri string) (*mcp.ReadResourceResult, error) {
+	req := &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: uri},
+	}
+
+	for _, r := range defs.Resources {
+		if r == nil || r.URI != uri {
+			continue
+		}
+		if err := r.Validate(invocation.InvocationValidator); err != nil {
+			return nil, err
+		}
+
+		invoker, err := invocation.CreateResourceInvoker(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create invoker for resource %s: %w", r.Name, err)
+		}
+		return invoker.InvokeResource(ctx, req)
+	}
+
+	for _, rt := range defs.ResourceTemplates {
+		if rt == nil {
+			continue
+		}
+		tmpl, err := uritemplate.New(rt.URITemplate)
+		if err != nil || tmpl.Match(uri) == nil {
+			continue
+		}
+		if err := rt.Validate(invocation.InvocationValidator); err != nil {
+			return nil, err
+		}
+
+		invoker, err := invocation.CreateResourceTemplateInvoker(rt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create invoker for resource template %s: %w", rt.Name, err)
+		}
+		return invoker.InvokeResourceTemplate(ctx, req)
+	}
+
+	return nil, fmt.Errorf("no resource or resource template in the MCP file matches URI '%s'", uri)
+}
+
+// DryRunTool parses and validates args against the input schema of tool, and returns the request
+// the tool would make with them, without executing it.
+func DryRunTool(ctx context.Context, tool *definitions.Tool, args json.RawMessage) (*invocation.DryRunResult, error) {
+	invoker, err := invocation.CreateInvoker(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoker for tool %s: %w", tool.Name, err)
+	}
+
+	dryRunner, ok := invoker.(invocation.DryRunner)
+	if !ok {
+		return nil, fmt.Errorf("invocation type '%s' does not support dry runs", tool.GetInvocationType())
+	}
+
+	req, err := toolRequest(tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dryRunner.DryRun(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rendered templates are printed, so the values of secrets are hidden
+	result.Redact(secrets.FromContext(ctx).Redact)
+	return result, nil
+}
+
+// toolRequest returns the request calling tool with args, with the transforms and defaults of tool applied.
+func toolRequest(tool *definitions.Tool, args json.RawMessage) (*mcp.CallToolRequest, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	args, err := tool.ApplyTransforms(args)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err = tool.ApplyDefaults(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{
+			Name:      tool.Name,
+			Arguments: args,
+		},
+	}, nil
+}
@@ -0,0 +1,479 @@
+package invoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+const testMCPFile = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test
+version: "1.0"
+invocationBases:
+  api:
+    http:
+      url: http://localhost:9999/users/{userId}
+      method: GET
+tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+    properties:
+      userId:
+        type: integer
+      verbose:
+        type: boolean
+    required: [userId]
+  invocation:
+    http:
+      url: http://localhost:9999/users/{userId}
+      method: GET
+      headers:
+        X-Request-Id: req-{userId}
+- name: create_user
+  description: Create a user
+  inputSchema:
+    type: object
+    properties:
+      orgId:
+        type: string
+      name:
+        type: string
+  invocation:
+    http:
+      url: http://localhost:9999/orgs/{orgId}/users
+      method: POST
+- name: get_user_posts
+  description: Get the posts of a user
+  inputSchema:
+    type: object
+    properties:
+      userId:
+        type: integer
+  invocation:
+    extends:
+      from: api
+      override:
+        url: http://localhost:9999/users/{userId}/posts
+- name: greet
+  description: Greet someone
+  inputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+  invocation:
+    cli:
+      command: echo hello {name}
+- name: find_user
+  description: Find a user
+  inputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+  invocation:
+    sql:
+      driver: sqlite3
+      dsn: test.db
+      query: SELECT * FROM users WHERE name = {name}
+- name: create_ticket
+  description: Create a ticket
+  inputSchema:
+    type: object
+    properties:
+      title:
+        type: string
+      priority:
+        type: string
+        enum: [low, normal, high]
+    required: [title, priority]
+  transforms:
+    title:
+      trim: true
+      maxLength: 20
+    priority:
+      normalizeEnum: true
+  defaults:
+    priority: normal
+    source: mcp
+    tenant: ${GENMCP_INVOKE_TEST_TENANT}
+  invocation:
+    http:
+      url: http://localhost:9999/tenants/{tenant}/tickets
+      method: POST
+`
+
+const testInvokeMCPFile = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test
+version: "1.0"
+tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+    properties:
+      userId:
+        type: integer
+    required: [userId]
+  outputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+    required: [name]
+  invocation:
+    http:
+      url: %[1]s/users/{userId}
+      method: GET
+- name: greet
+  description: Greet someone
+  inputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+  invocation:
+    cli:
+      command: echo hello {name}
+prompts:
+- name: welcome
+  description: Welcome someone
+  arguments:
+  - name: name
+    required: true
+  inputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+    required: [name]
+  invocation:
+    cli:
+      command: echo welcome {name}
+resources:
+- name: motd
+  description: The message of the day
+  uri: file:///motd
+  invocation:
+    cli:
+      command: echo have a nice day
+resourceTemplates:
+- name: forecast
+  description: The weather forecast of a city
+  uriTemplate: weather://forecast/{city}
+  inputSchema:
+    type: object
+    properties:
+      city:
+        type: string
+  invocation:
+    cli:
+      command: echo sunny in {city}
+`
+
+func TestDryRunTool(t *testing.T) {
+	defs := parseTestMCPFile(t)
+
+	tt := []struct {
+		name        string
+		tool        string
+		args        string
+		env         map[string]string
+		expected    *invocation.DryRunResult
+		errContains string
+	}{
+		{
+			name: "http GET with path, query and header",
+			tool: "get_user",
+			args: `{"userId": 42, "verbose": true}`,
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "GET",
+				URL:     "http://localhost:9999/users/42?verbose=true",
+				Headers: map[string][]string{"X-Request-Id": {"req-42"}},
+			},
+		},
+		{
+			name: "http POST with body",
+			tool: "create_user",
+			args: `{"orgId": "acme", "name": "Ada"}`,
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "POST",
+				URL:     "http://localhost:9999/orgs/acme/users",
+				Headers: map[string][]string{"Content-Type": {"application/json; charset=UTF-8"}},
+				Body:    json.RawMessage(`{"name":"Ada"}`),
+			},
+		},
+		{
+			name: "extends",
+			tool: "get_user_posts",
+			args: `{"userId": 7}`,
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "GET",
+				URL:     "http://localhost:9999/users/7/posts",
+				Headers: map[string][]string{},
+			},
+		},
+		{
+			name: "cli",
+			tool: "greet",
+			args: `{"name": "world"}`,
+			expected: &invocation.DryRunResult{
+				Type:    "cli",
+				Command: "echo hello world",
+			},
+		},
+		{
+			name: "sql",
+			tool: "find_user",
+			args: `{"name": "Ada"}`,
+			expected: &invocation.DryRunResult{
+				Type:      "sql",
+				Query:     "SELECT * FROM users WHERE name = ?",
+				QueryArgs: []any{"Ada"},
+			},
+		},
+		{
+			name: "defaults",
+			tool: "create_ticket",
+			args: `{"title": "Broken"}`,
+			env:  map[string]string{"GENMCP_INVOKE_TEST_TENANT": "acme"},
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "POST",
+				URL:     "http://localhost:9999/tenants/acme/tickets",
+				Headers: map[string][]string{"Content-Type": {"application/json; charset=UTF-8"}},
+				Body:    json.RawMessage(`{"priority":"normal","source":"mcp","title":"Broken"}`),
+			},
+		},
+		{
+			name: "arguments override defaults of declared properties only",
+			tool: "create_ticket",
+			args: `{"title": "Broken", "priority": "high", "source": "model"}`,
+			env:  map[string]string{"GENMCP_INVOKE_TEST_TENANT": "acme"},
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "POST",
+				URL:     "http://localhost:9999/tenants/acme/tickets",
+				Headers: map[string][]string{"Content-Type": {"application/json; charset=UTF-8"}},
+				Body:    json.RawMessage(`{"priority":"high","source":"mcp","title":"Broken"}`),
+			},
+		},
+		{
+			name: "transforms normalize the arguments",
+			tool: "create_ticket",
+			args: `{"title": "  Printer is on fire and nobody cares ", "priority": " HIGH"}`,
+			env:  map[string]string{"GENMCP_INVOKE_TEST_TENANT": "acme"},
+			expected: &invocation.DryRunResult{
+				Type:    "http",
+				Method:  "POST",
+				URL:     "http://localhost:9999/tenants/acme/tickets",
+				Headers: map[string][]string{"Content-Type": {"application/json; charset=UTF-8"}},
+				Body:    json.RawMessage(`{"priority":"high","source":"mcp","title":"Printer is on fire a"}`),
+			},
+		},
+		{
+			name:        "default with unset environment variable",
+			tool:        "create_ticket",
+			args:        `{"title": "Broken"}`,
+			errContains: "failed to resolve the default of property tenant: environment variable 'GENMCP_INVOKE_TEST_TENANT' not set",
+		},
+		{
+			name:        "missing required argument",
+			tool:        "get_user",
+			args:        `{"verbose": true}`,
+			errContains: "missing required field: userId",
+		},
+		{
+			name:        "invalid JSON arguments",
+			tool:        "greet",
+			args:        `{"name": `,
+			errContains: "failed to parse request",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			tool, err := FindTool(defs, tc.tool)
+			require.NoError(t, err)
+
+			result, err := DryRunTool(context.Background(), tool, json.RawMessage(tc.args))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestFindToolNotFound(t *testing.T) {
+	defs := parseTestMCPFile(t)
+
+	_, err := FindTool(defs, "missing")
+	assert.ErrorContains(t, err, "no tool named 'missing' in the MCP file, available tools: get_user, create_user")
+}
+
+func TestInvokeTool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/1":
+			_, _ = w.Write([]byte(`{"name":"Ada"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":2}`))
+		}
+	}))
+	defer srv.Close()
+
+	defs := parseMCPFile(t, fmt.Sprintf(testInvokeMCPFile, srv.URL))
+
+	tt := []struct {
+		name          string
+		tool          string
+		args          string
+		expectedText  string
+		expectIsError bool
+		errContains   string
+	}{
+		{
+			name:         "http",
+			tool:         "get_user",
+			args:         `{"userId": 1}`,
+			expectedText: `{"name":"Ada"}`,
+		},
+		{
+			name:          "http output not matching output schema",
+			tool:          "get_user",
+			args:          `{"userId": 2}`,
+			expectedText:  "tool output does not match outputSchema",
+			expectIsError: true,
+		},
+		{
+			name:         "cli",
+			tool:         "greet",
+			args:         `{"name": "world"}`,
+			expectedText: "hello world",
+		},
+		{
+			name:        "missing required argument",
+			tool:        "get_user",
+			args:        `{}`,
+			errContains: "missing required field: userId",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool, err := FindTool(defs, tc.tool)
+			require.NoError(t, err)
+
+			result, err := InvokeTool(context.Background(), tool, json.RawMessage(tc.args))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectIsError, result.IsError)
+			require.NotEmpty(t, result.Content)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+		})
+	}
+}
+
+func TestGetPrompt(t *testing.T) {
+	defs := parseMCPFile(t, fmt.Sprintf(testInvokeMCPFile, "http://localhost:9999"))
+
+	prompt, err := FindPrompt(defs, "welcome")
+	require.NoError(t, err)
+
+	result, err := GetPrompt(context.Background(), prompt, map[string]string{"name": "Ada"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "welcome Ada\n", result.Messages[0].Content.(*mcp.TextContent).Text)
+
+	_, err = FindPrompt(defs, "missing")
+	assert.ErrorContains(t, err, "no prompt named 'missing' in the MCP file, available prompts: welcome")
+}
+
+func TestReadResource(t *testing.T) {
+	defs := parseMCPFile(t, fmt.Sprintf(testInvokeMCPFile, "http://localhost:9999"))
+
+	tt := []struct {
+		name         string
+		uri          string
+		expectedText string
+		errContains  string
+	}{
+		{
+			name:         "resource",
+			uri:          "file:///motd",
+			expectedText: "have a nice day\n",
+		},
+		{
+			name:         "resource template",
+			uri:          "weather://forecast/paris",
+			expectedText: "sunny in paris\n",
+		},
+		{
+			name:        "no match",
+			uri:         "file:///missing",
+			errContains: "no resource or resource template in the MCP file matches URI 'file:///missing'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ReadResource(context.Background(), defs, tc.uri)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Contents, 1)
+			assert.Equal(t, tc.uri, result.Contents[0].URI)
+			assert.Equal(t, tc.expectedText, result.Contents[0].Text)
+		})
+	}
+}
+
+func parseTestMCPFile(t *testing.T) *definitions.MCPToolDefinitions {
+	t.Helper()
+
+	return parseMCPFile(t, testMCPFile)
+}
+
+func parseMCPFile(t *testing.T, content string) *definitions.MCPToolDefinitions {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	mcpFile, err := definitions.ParseMCPFile(path)
+	require.NoError(t, err)
+
+	return &mcpFile.MCPToolDefinitions
+}
@@ -0,0 +1,215 @@
+// Package mcpfile builds MCP files in Go, for programs generating the tool definitions of a gen-mcp server
+// instead of templating YAML. A Server collects the tools, prompts, resources and resource templates of the
+// file, and Build validates them like the server does when it starts:
+//
+//	file, err := mcpfile.NewServer("users-api", "1.0.0").
+//		AddHTTPTool("get_user", "Get a user by id", &http.HttpInvocationConfig{
+//			Method: "GET",
+//			URL:    "https://api.example.com/users/{id}",
+//		}, mcpfile.Param("id", "integer", "The id of the user"), mcpfile.ReadOnly()).
+//		Build()
+//
+// The files written by YAML and JSON parse back to the definitions returned by Build.
+package mcpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/genmcp/gen-mcp/pkg/config"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/file"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	"github.com/genmcp/gen-mcp/pkg/invocation/http"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/script"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/wasm"
+)
+
+// Server builds the MCP file of a server. Its methods return the server, so that calls can be chained.
+type Server struct {
+	definitions definitions.MCPToolDefinitions
+}
+
+// NewServer returns a builder of the MCP file of the server with the given name and version.
+func NewServer(name, version string) *Server {
+	return &Server{
+		definitions: definitions.MCPToolDefinitions{
+			Name:    name,
+			Version: version,
+		},
+	}
+}
+
+// WithInstructions sets the instructions the server gives to clients about how to use it.
+func (s *Server) WithInstructions(instructions string) *Server {
+	s.definitions.Instructions = instructions
+	return s
+}
+
+// AddInvocationBase adds an invocation base, which tools extend with an extends invocation referencing its
+// name.
+func (s *Server) AddInvocationBase(name, invocationType string, config invocation.InvocationConfig) *Server {
+	if s.definitions.InvocationBases == nil {
+		s.definitions.InvocationBases = make(map[string]*invocation.InvocationConfigWrapper)
+	}
+	s.definitions.InvocationBases[name] = &invocation.InvocationConfigWrapper{Type: invocationType, Config: config}
+	return s
+}
+
+// AddTool adds a tool, e.g. created with NewTool.
+func (s *Server) AddTool(tool *definitions.Tool) *Server {
+	s.definitions.Tools = append(s.definitions.Tools, tool)
+	return s
+}
+
+// AddHTTPTool adds a tool calling an HTTP API.
+func (s *Server) AddHTTPTool(name, description string, config *http.HttpInvocationConfig, opts ...ToolOption) *Server {
+	return s.AddTool(NewTool(name, description, http.InvocationType, config, opts...))
+}
+
+// AddCLITool adds a tool running a command.
+func (s *Server) AddCLITool(name, description string, config *cli.CliInvocationConfig, opts ...ToolOption) *Server {
+	return s.AddTool(NewTool(name, description, cli.InvocationType, config, opts...))
+}
+
+// AddPrompt adds a prompt.
+func (s *Server) AddPrompt(prompt *definitions.Prompt) *Server {
+	s.definitions.Prompts = append(s.definitions.Prompts, prompt)
+	return s
+}
+
+// AddResource adds a resource.
+func (s *Server) AddResource(resource *definitions.Resource) *Server {
+	s.definitions.Resources = append(s.definitions.Resources, resource)
+	return s
+}
+
+// AddResourceTemplate adds a resource template.
+func (s *Server) AddResourceTemplate(resourceTemplate *definitions.ResourceTemplate) *Server {
+	s.definitions.ResourceTemplates = append(s.definitions.ResourceTemplates, resourceTemplate)
+	return s
+}
+
+// Build returns the MCP file of the server, as the server parses it, or an error if it is invalid. Every
+// tool, prompt, resource and resource template is validated like the server validates them, and their names
+// must be unique.
+//
+// Like parsing an MCP file, Build registers the invocation bases of the file for extends invocations.
+func (s *Server) Build() (*definitions.MCPToolDefinitionsFile, error) {
+	if err := s.checkNames(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&definitions.MCPToolDefinitionsFile{
+		Kind:               definitions.KindMCPToolDefinitions,
+		SchemaVersion:      config.SchemaVersion,
+		MCPToolDefinitions: s.definitions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal MCP file: %w", err)
+	}
+
+	// the file is parsed back, so that it holds the values the server reads, e.g. the default input schema of
+	// tools, and so that writing it again doesn't change it
+	file, err := definitions.ParseMCPFileData(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoundTrip(file); err != nil {
+		return nil, err
+	}
+
+	if err := file.MCPToolDefinitions.Validate(invocation.InvocationValidator); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// YAML builds the MCP file of the server and returns it as YAML.
+func (s *Server) YAML() ([]byte, error) {
+	file, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(file)
+}
+
+// JSON builds the MCP file of the server and returns it as indented JSON.
+func (s *Server) JSON() ([]byte, error) {
+	file, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// checkNames checks that the names of the tools, prompts, resources and resource templates are unique.
+func (s *Server) checkNames() error {
+	var err error = nil
+
+	check := func(kind string, names []string) {
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			if seen[name] {
+				err = errors.Join(err, fmt.Errorf("%s '%s' is defined more than once", kind, name))
+			}
+			seen[name] = true
+		}
+	}
+
+	check(definitions.PrimitiveTypeTool, namesOf(s.definitions.Tools))
+	check(definitions.PrimitiveTypePrompt, namesOf(s.definitions.Prompts))
+	check(definitions.PrimitiveTypeResource, namesOf(s.definitions.Resources))
+	check(definitions.PrimitiveTypeResourceTemplate, namesOf(s.definitions.ResourceTemplates))
+
+	return err
+}
+
+func namesOf[P interface{ GetName() string }](primitives []P) []string {
+	names := make([]string, 0, len(primitives))
+	for _, p := range primitives {
+		names = append(names, p.GetName())
+	}
+	return names
+}
+
+// checkRoundTrip checks that file is marshalled the same after it is parsed back.
+func checkRoundTrip(file *definitions.MCPToolDefinitionsFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP file: %w", err)
+	}
+
+	parsed, err := definitions.ParseMCPFileData(data)
+	if err != nil {
+		return fmt.Errorf("the MCP file can't be parsed back: %w", err)
+	}
+
+	parsedData, err := json.Marshal(parsed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP file: %w", err)
+	}
+	if !bytes.Equal(data, parsedData) {
+		return fmt.Errorf("the MCP file changes when it is parsed back")
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package mcpfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	"github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/inline"
+)
+
+func TestServerBuild(t *testing.T) {
+	getUser := func() *http.HttpInvocationConfig {
+		return &http.HttpInvocationConfig{Method: "GET", URL: "https://api.example.com/users/{id}"}
+	}
+
+	tt := []struct {
+		name          string
+		server        *Server
+		expectedError string
+		check         func(t *testing.T, file *definitions.MCPToolDefinitionsFile)
+	}{
+		{
+			name: "tools, prompts and resources",
+			server: NewServer("users-api", "1.0.0").
+				WithInstructions("Look users up by id.").
+				AddHTTPTool("get_user", "Get a user by id", getUser(),
+					Title("Get user"), Param("id", invocation.JsonSchemaTypeInteger, "The id of the user"), ReadOnly()).
+				AddCLITool("echo", "Echo a message", &cli.CliInvocationConfig{Command: "echo {message}", Quoting: cli.QuotingShell},
+					OptionalParam("message", invocation.JsonSchemaTypeString, "The message")).
+				AddResource(&definitions.Resource{
+					Name:        "readme",
+					Description: "The README of the API",
+					URI:         "docs://readme",
+					InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+						Type:   inline.InvocationType,
+						Config: &inline.InlineInvocationConfig{Text: "# Users API"},
+					},
+				}),
+			check: func(t *testing.T, file *definitions.MCPToolDefinitionsFile) {
+				assert.Equal(t, definitions.KindMCPToolDefinitions, file.Kind)
+				assert.Equal(t, "Look users up by id.", file.Instructions)
+				require.Len(t, file.Tools, 2)
+
+				getUser := file.Tools[0]
+				assert.Equal(t, "Get user", getUser.Title)
+				assert.Equal(t, []string{"id"}, getUser.InputSchema.Required)
+				assert.True(t, *getUser.Annotations.ReadOnlyHint)
+				assert.NotNil(t, getUser.ResolvedInputSchema, "the tools should be validated")
+				assert.IsType(t, &http.HttpInvocationConfig{}, getUser.GetInvocationConfig())
+
+				assert.Empty(t, file.Tools[1].InputSchema.Required)
+				require.Len(t, file.Resources, 1)
+			},
+		},
+		{
+			name: "tool without parameters",
+			server: NewServer("users-api", "1.0.0").
+				AddHTTPTool("list_users", "List the users", &http.HttpInvocationConfig{Method: "GET", URL: "https://api.example.com/users"}),
+			check: func(t *testing.T, file *definitions.MCPToolDefinitionsFile) {
+				assert.Equal(t, invocation.JsonSchemaTypeObject, file.Tools[0].InputSchema.Type)
+				assert.Empty(t, file.Tools[0].InputSchema.Properties)
+			},
+		},
+		{
+			name:          "missing name",
+			server:        NewServer("", "1.0.0"),
+			expectedError: "name is required",
+		},
+		{
+			name: "invalid invocation",
+			server: NewServer("users-api", "1.0.0").
+				AddHTTPTool("get_user", "Get a user by id", &http.HttpInvocationConfig{Method: "FETCH", URL: "https://api.example.com/users"}),
+			expectedError: "tools[0] is invalid",
+		},
+		{
+			name: "undefined template variable",
+			server: NewServer("users-api", "1.0.0").
+				AddHTTPTool("get_user", "Get a user by id", getUser()),
+			expectedError: "tools[0] is invalid",
+		},
+		{
+			name: "duplicate tools",
+			server: NewServer("users-api", "1.0.0").
+				AddHTTPTool("get_user", "Get a user by id", getUser(), Param("id", invocation.JsonSchemaTypeInteger, "The id")).
+				AddHTTPTool("get_user", "Get a user by id", getUser(), Param("id", invocation.JsonSchemaTypeInteger, "The id")),
+			expectedError: "tool 'get_user' is defined more than once",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := tc.server.Build()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, file)
+		})
+	}
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	server := NewServer("users-api", "1.0.0").
+		AddInvocationBase("api", http.InvocationType, &http.HttpInvocationConfig{
+			Method:  "GET",
+			URL:     "https://api.example.com/users",
+			Headers: map[string]string{"Accept": "application/json"},
+		}).
+		AddHTTPTool("get_user", "Get a user by id", &http.HttpInvocationConfig{Method: "GET", URL: "https://api.example.com/users/{id}"},
+			Param("id", invocation.JsonSchemaTypeInteger, "The id of the user"), RequiredScopes("users:read"), Idempotent())
+
+	file, err := server.Build()
+	require.NoError(t, err)
+	expected, err := json.Marshal(file)
+	require.NoError(t, err)
+
+	yamlData, err := server.YAML()
+	require.NoError(t, err)
+	fromYAML, err := definitions.ParseMCPFileData(yamlData)
+	require.NoError(t, err)
+
+	jsonData, err := server.JSON()
+	require.NoError(t, err)
+	fromJSON, err := definitions.ParseMCPFileData(jsonData)
+	require.NoError(t, err)
+
+	for _, parsed := range []*definitions.MCPToolDefinitionsFile{fromYAML, fromJSON} {
+		actual, err := json.Marshal(parsed)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(actual))
+	}
+}
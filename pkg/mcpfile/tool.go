@@ -0,0 +1,121 @@
+package mcpfile
+
+import (
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// ToolOption sets an optional field of a tool created by NewTool.
+type ToolOption func(tool *definitions.Tool)
+
+// NewTool returns a tool invoked with config, an invocation config of the given type, e.g. a
+// *sql.SqlInvocationConfig for sql. Its input schema is an object without properties, unless set by opts.
+func NewTool(name, description, invocationType string, config invocation.InvocationConfig, opts ...ToolOption) *definitions.Tool {
+	tool := &definitions.Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: &jsonschema.Schema{
+			Type:       invocation.JsonSchemaTypeObject,
+			Properties: make(map[string]*jsonschema.Schema),
+		},
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: invocationType, Config: config},
+	}
+
+	for _, opt := range opts {
+		opt(tool)
+	}
+
+	return tool
+}
+
+// Title sets the human-readable title of the tool.
+func Title(title string) ToolOption {
+	return func(tool *definitions.Tool) {
+		tool.Title = title
+	}
+}
+
+// Param adds a required property of the given JSON schema type, e.g. string or integer, to the input schema
+// of the tool.
+func Param(name, schemaType, description string) ToolOption {
+	return func(tool *definitions.Tool) {
+		addProperty(tool, name, &jsonschema.Schema{Type: schemaType, Description: description})
+		if !slices.Contains(tool.InputSchema.Required, name) {
+			tool.InputSchema.Required = append(tool.InputSchema.Required, name)
+		}
+	}
+}
+
+// OptionalParam adds an optional property of the given JSON schema type to the input schema of the tool.
+func OptionalParam(name, schemaType, description string) ToolOption {
+	return func(tool *definitions.Tool) {
+		addProperty(tool, name, &jsonschema.Schema{Type: schemaType, Description: description})
+	}
+}
+
+// InputSchema replaces the input schema of the tool.
+func InputSchema(schema *jsonschema.Schema) ToolOption {
+	return func(tool *definitions.Tool) {
+		tool.InputSchema = schema
+	}
+}
+
+// OutputSchema sets the output schema the results of the tool are validated against.
+func OutputSchema(schema *jsonschema.Schema) ToolOption {
+	return func(tool *definitions.Tool) {
+		tool.OutputSchema = schema
+	}
+}
+
+// RequiredScopes sets the OAuth scopes required to call the tool.
+func RequiredScopes(scopes ...string) ToolOption {
+	return func(tool *definitions.Tool) {
+		tool.RequiredScopes = scopes
+	}
+}
+
+// ReadOnly annotates the tool as not modifying its environment.
+func ReadOnly() ToolOption {
+	return func(tool *definitions.Tool) {
+		annotationsOf(tool).ReadOnlyHint = boolPtr(true)
+	}
+}
+
+// Destructive annotates the tool as possibly performing destructive updates to its environment.
+func Destructive() ToolOption {
+	return func(tool *definitions.Tool) {
+		annotationsOf(tool).DestructiveHint = boolPtr(true)
+	}
+}
+
+// Idempotent annotates the tool as having no additional effect when called again with the same arguments.
+func Idempotent() ToolOption {
+	return func(tool *definitions.Tool) {
+		annotationsOf(tool).IdempotentHint = boolPtr(true)
+	}
+}
+
+func addProperty(tool *definitions.Tool, name string, schema *jsonschema.Schema) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = &jsonschema.Schema{Type: invocation.JsonSchemaTypeObject}
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]*jsonschema.Schema)
+	}
+	tool.InputSchema.Properties[name] = schema
+}
+
+func annotationsOf(tool *definitions.Tool) *definitions.ToolAnnotations {
+	if tool.Annotations == nil {
+		tool.Annotations = &definitions.ToolAnnotations{}
+	}
+	return tool.Annotations
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -3,14 +3,25 @@ package mcpserver
 import (
 	"errors"
 	"fmt"
+	"maps"
+	neturl "net/url"
+	"slices"
+	"strings"
 
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	"github.com/genmcp/gen-mcp/pkg/template"
 )
 
-// Validate validates the MCPServer configuration
+// Validate validates the MCPServer configuration. The headers and environment variables the invocations of the
+// tool definitions can reference are restricted to the ones allowed by the security config of the runtime, if any.
 func (s *MCPServer) Validate(invocationValidator definitions.InvocationValidator) error {
 	var err error = nil
 
+	env, toolEnv := s.Runtime.GetAllowedEnv()
+	s.SetTemplateAllowlist(template.NewAllowlist(s.Runtime.GetAllowedHeaders(), env, toolEnv))
+
 	if toolDefsErr := s.MCPToolDefinitions.Validate(invocationValidator); toolDefsErr != nil {
 		err = errors.Join(err, fmt.Errorf("invalid server tool definitions: %w", toolDefsErr))
 	}
@@ -19,5 +30,188 @@ func (s *MCPServer) Validate(invocationValidator definitions.InvocationValidator
 		err = errors.Join(err, fmt.Errorf("invalid server config: %w", serverConfigErr))
 	}
 
+	if listenerToolsErr := s.validateListenerTools(); listenerToolsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config: %w", listenerToolsErr))
+	}
+
+	if securityToolsErr := s.validateSecurityTools(); securityToolsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config: %w", securityToolsErr))
+	}
+
+	if quotaToolsErr := s.validateQuotaTools(); quotaToolsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config: %w", quotaToolsErr))
+	}
+
+	if scheduleToolsErr := s.validateScheduleTools(); scheduleToolsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server config: %w", scheduleToolsErr))
+	}
+
+	if urlsErr := s.validateInvocationURLs(); urlsErr != nil {
+		err = errors.Join(err, fmt.Errorf("invalid server tool definitions: %w", urlsErr))
+	}
+
+	return err
+}
+
+// validateListenerTools checks that the tools of every listener are defined in the MCP file.
+func (s *MCPServer) validateListenerTools() error {
+	if s.Runtime == nil {
+		return nil
+	}
+
+	toolNames := make(map[string]bool, len(s.Tools))
+	for _, t := range s.Tools {
+		toolNames[t.Name] = true
+	}
+
+	var err error = nil
+	for _, l := range s.Runtime.Listeners {
+		if l == nil {
+			continue
+		}
+		for _, name := range l.Tools {
+			if !toolNames[name] {
+				err = errors.Join(err, fmt.Errorf("listener '%s' serves unknown tool '%s'", l.Name, name))
+			}
+		}
+	}
+
+	return err
+}
+
+// validateSecurityTools checks that the tools of the security config are defined in the MCP file.
+func (s *MCPServer) validateSecurityTools() error {
+	if s.Runtime == nil || s.Runtime.Security == nil {
+		return nil
+	}
+
+	toolNames := make(map[string]bool, len(s.Tools))
+	for _, t := range s.Tools {
+		toolNames[t.Name] = true
+	}
+
+	var err error = nil
+	for _, name := range slices.Sorted(maps.Keys(s.Runtime.Security.Tools)) {
+		if !toolNames[name] {
+			err = errors.Join(err, fmt.Errorf("security config of unknown tool '%s'", name))
+		}
+	}
+
 	return err
 }
+
+// validateQuotaTools checks that the tools of the quotas config are defined in the MCP file.
+func (s *MCPServer) validateQuotaTools() error {
+	if s.Runtime == nil || s.Runtime.Quotas == nil {
+		return nil
+	}
+
+	toolNames := make(map[string]bool, len(s.Tools))
+	for _, t := range s.Tools {
+		toolNames[t.Name] = true
+	}
+
+	var err error = nil
+	for _, name := range slices.Sorted(maps.Keys(s.Runtime.Quotas.Tools)) {
+		if !toolNames[name] {
+			err = errors.Join(err, fmt.Errorf("quotas of unknown tool '%s'", name))
+		}
+	}
+
+	return err
+}
+
+// validateScheduleTools checks that the tools called by the schedules are defined in the MCP file. They are
+// not checked if tools are imported from an OpenAPI document or upstream MCP servers, as the imported tools
+// are only known once the server starts.
+func (s *MCPServer) validateScheduleTools() error {
+	if s.Runtime == nil || s.OpenAPIRef != nil || len(s.Upstreams) > 0 {
+		return nil
+	}
+
+	toolNames := make(map[string]bool, len(s.Tools))
+	for _, t := range s.Tools {
+		toolNames[t.Name] = true
+	}
+
+	var err error = nil
+	for _, sc := range s.Runtime.Schedules {
+		if sc != nil && sc.Tool != "" && !toolNames[sc.Tool] {
+			err = errors.Join(err, fmt.Errorf("schedule '%s' calls unknown tool '%s'", sc.Name, sc.Tool))
+		}
+	}
+
+	return err
+}
+
+// validateInvocationURLs checks that the URLs of the invocations may be targeted under the network policy of the
+// runtime. URLs whose host has placeholders are only checked when their requests are sent.
+func (s *MCPServer) validateInvocationURLs() error {
+	policy, policyErr := s.Runtime.GetNetworkPolicy()
+	if policyErr != nil {
+		// reported by the validation of the server config
+		return nil
+	}
+
+	var primitives []invocation.Primitive
+	for _, t := range s.Tools {
+		primitives = append(primitives, t)
+	}
+	for _, p := range s.Prompts {
+		primitives = append(primitives, p)
+	}
+	for _, r := range s.Resources {
+		primitives = append(primitives, r)
+	}
+	for _, rt := range s.ResourceTemplates {
+		primitives = append(primitives, rt)
+	}
+
+	var err error = nil
+	for _, p := range primitives {
+		config := p.GetInvocationConfig()
+		if ec, ok := config.(*extends.ExtendsConfig); ok {
+			resolved, resolveErr := ec.Resolve()
+			if resolveErr != nil {
+				// reported by the validation of the tool definitions
+				continue
+			}
+			config = resolved.Config
+		}
+
+		ur, ok := config.(invocation.URLReferencer)
+		if !ok {
+			continue
+		}
+		for _, url := range ur.ReferencedURLs() {
+			host, ok := staticHost(url)
+			if !ok {
+				continue
+			}
+			if checkErr := policy.CheckHost(host); checkErr != nil {
+				err = errors.Join(err, fmt.Errorf("%s %s: invalid url '%s': %w", p.PrimitiveType(), p.GetName(), url, checkErr))
+			}
+		}
+	}
+
+	return err
+}
+
+// staticHost returns the host of url, if it is absolute and its host has no placeholders.
+func staticHost(url string) (string, bool) {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok || strings.ContainsAny(scheme, "{$") {
+		return "", false
+	}
+	authority, _, _ := strings.Cut(rest, "/")
+	authority, _, _ = strings.Cut(authority, "?")
+	if authority == "" || strings.ContainsAny(authority, "{$") {
+		return "", false
+	}
+
+	u, err := neturl.Parse(scheme + "://" + authority)
+	if err != nil {
+		return "", false
+	}
+	return u.Hostname(), true
+}
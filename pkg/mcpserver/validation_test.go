@@ -6,6 +6,8 @@ import (
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
 	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,4 +75,217 @@ func TestMCPFileValidate(t *testing.T) {
 		err := mcpServer.Validate(mockValidator)
 		assert.NoError(t, err)
 	})
+	t.Run("listener with unknown tool should fail validation", func(t *testing.T) {
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+					Listeners: []*serverconfig.ListenerConfig{
+						{
+							Name:              "admin",
+							TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+							StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+								Port: 3000,
+							},
+							Tools: []string{"missing_tool"},
+						},
+					},
+				},
+			},
+		}
+		err := mcpServer.Validate(mockValidator)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "listener 'admin' serves unknown tool 'missing_tool'")
+	})
+
+	t.Run("header outside the allowed headers should fail validation", func(t *testing.T) {
+		// parses the description of the tool as a template, as invocations parse their templates
+		templateValidator := func(primitive invocation.Primitive) error {
+			_, err := template.ParseTemplate(primitive.GetDescription(), template.TemplateParserOptions{
+				Sources:   template.CreateHeadersSourceFactory(),
+				Allowlist: template.PrimitiveAllowlist(primitive),
+			})
+			return err
+		}
+
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+				Tools: []*definitions.Tool{
+					{
+						Name:                    "get_session",
+						Description:             "session={headers.Cookie}",
+						InputSchema:             &jsonschema.Schema{Type: "object"},
+						InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: &testInvocationConfig{}},
+					},
+				},
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+				},
+			},
+		}
+		assert.NoError(t, mcpServer.Validate(templateValidator))
+
+		mcpServer.Runtime.Security = &serverconfig.SecurityConfig{AllowedHeaders: []string{"X-Tenant-ID"}}
+		err := mcpServer.Validate(templateValidator)
+		assert.ErrorContains(t, err, "header 'Cookie' is not in the allowedHeaders of the security config of the server")
+
+		// the allowed headers of a server don't apply to the other servers of the process
+		other := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "other-server",
+				Version: "1.0.0",
+				Tools:   []*definitions.Tool{{Name: "get_session", Description: "session={headers.Cookie}", InputSchema: &jsonschema.Schema{Type: "object"}, InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: &testInvocationConfig{}}}},
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+				},
+			},
+		}
+		assert.NoError(t, other.Validate(templateValidator))
+		assert.False(t, mcpServer.Tools[0].TemplateAllowlist.HeaderAllowed("Cookie"))
+		assert.True(t, other.Tools[0].TemplateAllowlist.HeaderAllowed("Cookie"))
+	})
+
+	t.Run("environment variables outside the allowed environment variables should fail validation", func(t *testing.T) {
+		// parses the description of the tool as a template for the tool, as invocations parse their templates
+		templateValidator := func(primitive invocation.Primitive) error {
+			_, err := template.ParseTemplate(primitive.GetDescription(), template.TemplateParserOptions{
+				Tool:      invocation.ToolName(primitive),
+				Allowlist: template.PrimitiveAllowlist(primitive),
+			})
+			return err
+		}
+
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+				Tools: []*definitions.Tool{
+					{
+						Name:                    "get_repo",
+						Description:             "token=${GITHUB_TOKEN}",
+						InputSchema:             &jsonschema.Schema{Type: "object"},
+						InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: &testInvocationConfig{}},
+					},
+				},
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+					Security:          &serverconfig.SecurityConfig{AllowedEnv: []string{"API_URL"}},
+				},
+			},
+		}
+		err := mcpServer.Validate(templateValidator)
+		assert.ErrorContains(t, err, "environment variable 'GITHUB_TOKEN' is not in the allowedEnv of the security config of the server")
+
+		mcpServer.Runtime.Security.Tools = map[string]*serverconfig.ToolSecurityConfig{
+			"get_repo": {AllowedEnv: []string{"GITHUB_TOKEN"}},
+		}
+		assert.NoError(t, mcpServer.Validate(templateValidator))
+
+		mcpServer.Runtime.Security.Tools["delete_repo"] = &serverconfig.ToolSecurityConfig{}
+		err = mcpServer.Validate(templateValidator)
+		assert.ErrorContains(t, err, "security config of unknown tool 'delete_repo'")
+	})
+	t.Run("quotas of unknown tool should fail validation", func(t *testing.T) {
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+					Quotas: &serverconfig.QuotasConfig{
+						PerHour: 100,
+						Tools:   map[string]*serverconfig.QuotaLimits{"send_email": {PerDay: 10}},
+					},
+				},
+			},
+		}
+		err := mcpServer.Validate(mockValidator)
+		assert.ErrorContains(t, err, "quotas of unknown tool 'send_email'")
+	})
+	t.Run("schedule of unknown tool should fail validation", func(t *testing.T) {
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+					Schedules: []*serverconfig.ScheduleConfig{
+						{Name: "daily-report", Tool: "generate_report", Schedule: "@daily"},
+					},
+				},
+			},
+		}
+		err := mcpServer.Validate(mockValidator)
+		assert.ErrorContains(t, err, "schedule 'daily-report' calls unknown tool 'generate_report'")
+
+		// the tool may be imported from an upstream MCP server
+		mcpServer.Upstreams = []*serverconfig.UpstreamConfig{{Name: "reports", URL: "http://localhost:9000/mcp"}}
+		assert.NoError(t, mcpServer.Validate(mockValidator))
+	})
+	t.Run("invocation url denied by the network policy should fail validation", func(t *testing.T) {
+		tool := func(name string, urls ...string) *definitions.Tool {
+			return &definitions.Tool{
+				Name:                    name,
+				Description:             name,
+				InputSchema:             &jsonschema.Schema{Type: "object"},
+				InvocationConfigWrapper: &invocation.InvocationConfigWrapper{Type: "test", Config: &testURLInvocationConfig{urls: urls}},
+			}
+		}
+		mcpServer := &MCPServer{
+			MCPToolDefinitions: definitions.MCPToolDefinitions{
+				Name:    "test-server",
+				Version: "1.0.0",
+				Tools: []*definitions.Tool{
+					tool("get_user", "https://api.example.com/users/{id}"),
+					tool("get_credentials", "http://169.254.169.254/latest/meta-data/iam/security-credentials/"),
+					tool("get_internal", "http://admin.internal:8080/config"),
+					tool("get_templated", "http://{host}/config", "${API_URL}/users"),
+				},
+			},
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: &serverconfig.ServerRuntime{
+					TransportProtocol: serverconfig.TransportProtocolStdio,
+					Security: &serverconfig.SecurityConfig{
+						Network: &serverconfig.NetworkPolicyConfig{DeniedHosts: []string{"*.internal"}},
+					},
+				},
+			},
+		}
+		err := mcpServer.Validate(mockValidator)
+		assert.ErrorContains(t, err, "tool get_credentials: invalid url 'http://169.254.169.254/latest/meta-data/iam/security-credentials/': destination denied by the network policy: 169.254.169.254 is denied")
+		assert.ErrorContains(t, err, "tool get_internal: invalid url 'http://admin.internal:8080/config': destination denied by the network policy: admin.internal is denied")
+		assert.NotContains(t, err.Error(), "get_user")
+		assert.NotContains(t, err.Error(), "get_templated")
+	})
+}
+
+type testInvocationConfig struct{}
+
+func (*testInvocationConfig) Validate() error                       { return nil }
+func (*testInvocationConfig) DeepCopy() invocation.InvocationConfig { return &testInvocationConfig{} }
+
+type testURLInvocationConfig struct {
+	urls []string
+}
+
+func (*testURLInvocationConfig) Validate() error { return nil }
+func (*testURLInvocationConfig) DeepCopy() invocation.InvocationConfig {
+	return &testURLInvocationConfig{}
 }
+func (c *testURLInvocationConfig) ReferencedURLs() []string { return c.urls }
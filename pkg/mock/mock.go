@@ -0,0 +1,317 @@
+// Package mock serves a fake HTTP backend for the tools of an MCP file, so that MCP files can be demoed and
+// developed without the real APIs. Every tool with an HTTP invocation is served at the method and path of its
+// URL, and answers with a result recorded by genmcp run --record if one matches the request, or with data
+// generated from the output schema of the tool otherwise.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	invocationhttp "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+// SourceHeader is the header of the responses of the mock telling where their body comes from.
+const SourceHeader = "X-Genmcp-Mock-Source"
+
+// Sources of the responses of the mock.
+const (
+	SourceFixture = "fixture" // a result recorded with genmcp run --record
+	SourceSchema  = "schema"  // data generated from the output schema of the tool
+)
+
+var (
+	// schemePrefix matches the scheme and host of absolute URLs
+	schemePrefix = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*://[^/]*`)
+	// basePlaceholder matches a placeholder at the start of a URL holding its base, e.g. ${API_URL}
+	basePlaceholder = regexp.MustCompile(`^(?:\$\{[^}]+\}|\{[^}]+\})`)
+	// placeholder matches the placeholders of URL templates
+	placeholder = regexp.MustCompile(`\$\{[^}]+\}|\{[^}]+\}`)
+)
+
+// Route is the method and path of the requests answered for a tool.
+type Route struct {
+	Method string
+	// Path is the path of the URL of the tool, with its placeholders
+	Path string
+	Tool string
+
+	tool    *definitions.Tool
+	pattern *regexp.Regexp
+	// params are the names of the input properties of the placeholders of the path, in order, empty for
+	// the placeholders of headers, secrets and environment variables
+	params []string
+}
+
+// Server is an http.Handler answering the requests of the tools of an MCP file.
+type Server struct {
+	routes   []*Route
+	fixtures *recording.Store
+}
+
+// NewServer creates a Server for the tools of defs with an HTTP invocation. The recorded results of fixtures
+// are returned when they match a request, if fixtures is not nil.
+func NewServer(defs *definitions.MCPToolDefinitions, fixtures *recording.Store) (*Server, error) {
+	s := &Server{fixtures: fixtures}
+
+	for _, tool := range defs.Tools {
+		if tool == nil {
+			continue
+		}
+		hic, err := httpInvocationConfig(tool)
+		if err != nil {
+			return nil, fmt.Errorf("invalid invocation of tool %s: %w", tool.Name, err)
+		}
+		if hic == nil {
+			continue
+		}
+
+		route, ok := newRoute(tool, hic)
+		if !ok {
+			continue
+		}
+		s.routes = append(s.routes, route)
+	}
+
+	// literal paths take precedence over the templates they match, e.g. /users/me over /users/{id}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return len(s.routes[i].params) < len(s.routes[j].params)
+	})
+
+	return s, nil
+}
+
+// httpInvocationConfig returns the HTTP invocation config of tool, resolving invocations extending a base,
+// or nil if tool is not invoked over HTTP.
+func httpInvocationConfig(tool *definitions.Tool) (*invocationhttp.HttpInvocationConfig, error) {
+	config := tool.GetInvocationConfig()
+	if ec, ok := config.(*extends.ExtendsConfig); ok {
+		resolved, err := ec.Resolve()
+		if err != nil {
+			return nil, err
+		}
+		config = resolved.Config
+	}
+
+	hic, _ := config.(*invocationhttp.HttpInvocationConfig)
+	return hic, nil
+}
+
+// newRoute returns the route of tool invoked with hic. WebSocket URLs have no route.
+func newRoute(tool *definitions.Tool, hic *invocationhttp.HttpInvocationConfig) (*Route, bool) {
+	u := hic.URL
+	if strings.HasPrefix(u, "ws://") || strings.HasPrefix(u, "wss://") {
+		return nil, false
+	}
+
+	if loc := schemePrefix.FindStringIndex(u); loc != nil {
+		u = u[loc[1]:]
+	} else {
+		u = basePlaceholder.ReplaceAllString(u, "")
+	}
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u
+	}
+
+	var pattern strings.Builder
+	var params []string
+	last := 0
+	for _, loc := range placeholder.FindAllStringIndex(u, -1) {
+		pattern.WriteString(regexp.QuoteMeta(u[last:loc[0]]))
+		pattern.WriteString(`([^/]+)`)
+		params = append(params, placeholderParam(u[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(u[last:]))
+
+	method := strings.ToUpper(hic.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return &Route{
+		Method:  method,
+		Path:    u,
+		Tool:    tool.Name,
+		tool:    tool,
+		pattern: regexp.MustCompile(`^` + pattern.String() + `/?$`),
+		params:  params,
+	}, true
+}
+
+// placeholderParam returns the input property of a placeholder, or an empty string for the placeholders of
+// headers, secrets and environment variables.
+func placeholderParam(p string) string {
+	if strings.HasPrefix(p, "$") {
+		return ""
+	}
+	name := strings.Trim(p, "{}")
+	for _, prefix := range []string{"headers.", "secrets.", "env."} {
+		if strings.HasPrefix(name, prefix) {
+			return ""
+		}
+	}
+	return name
+}
+
+// Routes returns the routes of s, in the order they are matched.
+func (s *Server) Routes() []Route {
+	routes := make([]Route, len(s.routes))
+	for i, r := range s.routes {
+		routes[i] = *r
+	}
+	return routes
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, values := s.match(r)
+	if route == nil {
+		http.Error(w, fmt.Sprintf("no tool of the MCP file is invoked with %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+		return
+	}
+
+	// the arguments of the call, as far as they can be told from the request
+	for key, vals := range r.URL.Query() {
+		if _, ok := values[key]; !ok && len(vals) > 0 {
+			values[key] = vals[0]
+		}
+	}
+
+	if s.fixtures != nil {
+		fixtures, err := s.fixtures.Fixtures(route.Tool)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read the fixtures of tool %s: %s", route.Tool, err), http.StatusInternalServerError)
+			return
+		}
+		if fixture := matchFixture(fixtures, values); fixture != nil {
+			writeFixture(w, fixture)
+			return
+		}
+	}
+
+	data, err := json.Marshal(exampleValue(route.tool.OutputSchema))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to generate a response for tool %s: %s", route.Tool, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(SourceHeader, SourceSchema)
+	_, _ = w.Write(data)
+}
+
+// match returns the route of r, and the values of the input properties in the path of r.
+func (s *Server) match(r *http.Request) (*Route, map[string]string) {
+	for _, route := range s.routes {
+		if route.Method != r.Method {
+			continue
+		}
+		m := route.pattern.FindStringSubmatch(r.URL.EscapedPath())
+		if m == nil {
+			continue
+		}
+
+		values := make(map[string]string)
+		for i, param := range route.params {
+			if param == "" {
+				continue
+			}
+			value, err := url.PathUnescape(m[i+1])
+			if err != nil {
+				value = m[i+1]
+			}
+			values[param] = value
+		}
+		return route, values
+	}
+	return nil, nil
+}
+
+// matchFixture returns the most recent of fixtures whose arguments have the values of values, and that has
+// the most of them, or nil if no fixture has them.
+func matchFixture(fixtures []recording.Fixture, values map[string]string) *recording.Fixture {
+	var best *recording.Fixture
+	bestScore := -1
+	for i := range fixtures {
+		var arguments map[string]any
+		if err := json.Unmarshal(fixtures[i].Arguments, &arguments); err != nil {
+			continue
+		}
+
+		score := 0
+		for name, value := range values {
+			argument, ok := arguments[name]
+			if !ok {
+				continue
+			}
+			if fmt.Sprint(argument) != value {
+				score = -1
+				break
+			}
+			score++
+		}
+
+		// later fixtures are more recent, and replace earlier ones with the same score
+		if score >= 0 && score >= bestScore {
+			best, bestScore = &fixtures[i], score
+		}
+	}
+	return best
+}
+
+// writeFixture writes the body of the response recorded in fixture: the text of the result, which HTTP
+// invocations set to the body of the response, or its structured content. Results of backend error statuses
+// are written with their status, and other errors with 503 Service Unavailable.
+func writeFixture(w http.ResponseWriter, fixture *recording.Fixture) {
+	result := &mcp.CallToolResult{}
+	if err := json.Unmarshal(fixture.Result, result); err != nil {
+		http.Error(w, fmt.Sprintf("invalid result of fixture of tool %s: %s", fixture.Tool, err), http.StatusInternalServerError)
+		return
+	}
+
+	var body []byte
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			body = []byte(text.Text)
+			break
+		}
+	}
+	if body == nil && result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid result of fixture of tool %s: %s", fixture.Tool, err), http.StatusInternalServerError)
+			return
+		}
+		body = data
+	}
+
+	status := http.StatusOK
+	if result.IsError {
+		status = http.StatusServiceUnavailable
+		if detail, ok := invocation.GetErrorDetail(result); ok && detail.Code == invocation.ErrorCodeBackendStatus && detail.Status >= 400 {
+			status = detail.Status
+		}
+	}
+
+	if json.Valid(body) {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set(SourceHeader, SourceFixture)
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
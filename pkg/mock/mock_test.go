@@ -0,0 +1,243 @@
+package mock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+
+	// register the cli invocation type, so that MCP files with CLI tools can be parsed
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/cli"
+)
+
+const testMCPFile = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test
+version: "1.0"
+invocationBases:
+  api:
+    http:
+      url: ${API_URL}/users
+      method: POST
+tools:
+- name: get_user
+  description: Get a user
+  inputSchema:
+    type: object
+    properties:
+      id:
+        type: string
+  outputSchema:
+    type: object
+    properties:
+      id:
+        type: string
+      email:
+        type: string
+        format: email
+      age:
+        type: integer
+        minimum: 18
+  invocation:
+    http:
+      url: https://api.example.com/users/{id}?fields=all
+      method: GET
+      headers:
+        Authorization: Bearer {secrets.API_TOKEN}
+- name: get_current_user
+  description: Get the current user
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      url: https://api.example.com/users/me
+      method: GET
+- name: create_user
+  description: Create a user
+  inputSchema:
+    type: object
+    properties:
+      name:
+        type: string
+  invocation:
+    extends:
+      from: api
+- name: list_files
+  description: List files
+  inputSchema:
+    type: object
+  invocation:
+    cli:
+      command: ls
+`
+
+func parseMCPFile(t *testing.T, content string) *definitions.MCPToolDefinitions {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	mcpFile, err := definitions.ParseMCPFile(path)
+	require.NoError(t, err)
+
+	return &mcpFile.MCPToolDefinitions
+}
+
+func TestNewServerRoutes(t *testing.T) {
+	s, err := NewServer(parseMCPFile(t, testMCPFile), nil)
+	require.NoError(t, err)
+
+	var routes []string
+	for _, r := range s.Routes() {
+		routes = append(routes, r.Method+" "+r.Path+" "+r.Tool)
+	}
+	assert.Equal(t, []string{
+		"GET /users/me get_current_user",
+		"POST /users create_user",
+		"GET /users/{id} get_user",
+	}, routes)
+}
+
+func TestServerSchemaResponses(t *testing.T) {
+	s, err := NewServer(parseMCPFile(t, testMCPFile), nil)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "output schema",
+			method:         http.MethodGet,
+			path:           "/users/42?fields=all",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"age":18,"email":"user@example.com","id":"id"}`,
+		},
+		{
+			name:           "literal path before template",
+			method:         http.MethodGet,
+			path:           "/users/me",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{}`,
+		},
+		{
+			name:           "extended invocation base",
+			method:         http.MethodPost,
+			path:           "/users",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{}`,
+		},
+		{
+			name:           "unknown method",
+			method:         http.MethodDelete,
+			path:           "/users/42",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodGet,
+			path:           "/orders",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, SourceSchema, rec.Header().Get(SourceHeader))
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServerFixtureResponses(t *testing.T) {
+	dir := t.TempDir()
+	redactor, err := logging.NewRedactor(nil)
+	require.NoError(t, err)
+	recorder, err := recording.NewStore(dir, recording.ModeRecord, redactor)
+	require.NoError(t, err)
+
+	notFound := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "user 404 not found"}}}
+	invocation.SetErrorDetail(notFound, invocation.NewErrorDetail(invocation.ErrorCodeBackendStatus, http.StatusNotFound))
+
+	require.NoError(t, recorder.Record("get_user", json.RawMessage(`{"id":"1"}`),
+		&mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":"1","email":"alice@example.com"}`}}}))
+	require.NoError(t, recorder.Record("get_user", json.RawMessage(`{"id":"2"}`),
+		&mcp.CallToolResult{StructuredContent: map[string]any{"id": "2", "email": "bob@example.com"}}))
+	require.NoError(t, recorder.Record("get_user", json.RawMessage(`{"id":"404"}`), notFound))
+
+	fixtures, err := recording.NewStore(dir, recording.ModeReplay, nil)
+	require.NoError(t, err)
+	s, err := NewServer(parseMCPFile(t, testMCPFile), fixtures)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedSource string
+		expectedBody   string
+	}{
+		{
+			name:           "text of the recorded result",
+			path:           "/users/1",
+			expectedStatus: http.StatusOK,
+			expectedSource: SourceFixture,
+			expectedBody:   `{"id":"1","email":"alice@example.com"}`,
+		},
+		{
+			name:           "structured content of the recorded result",
+			path:           "/users/2",
+			expectedStatus: http.StatusOK,
+			expectedSource: SourceFixture,
+			expectedBody:   `{"email":"bob@example.com","id":"2"}`,
+		},
+		{
+			name:           "recorded error status",
+			path:           "/users/404",
+			expectedStatus: http.StatusNotFound,
+			expectedSource: SourceFixture,
+			expectedBody:   `user 404 not found`,
+		},
+		{
+			name:           "output schema without a matching fixture",
+			path:           "/users/3",
+			expectedStatus: http.StatusOK,
+			expectedSource: SourceSchema,
+			expectedBody:   `{"age":18,"email":"user@example.com","id":"id"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedSource, rec.Header().Get(SourceHeader))
+			body, err := io.ReadAll(rec.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
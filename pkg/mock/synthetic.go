@@ -0,0 +1,198 @@
+package mock
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// maxExampleDepth bounds the nesting of generated values, for recursive schemas.
+const maxExampleDepth = 8
+
+// exampleStrings are the values generated for the string formats of JSON schema.
+var exampleStrings = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "12:00:00",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "00000000-0000-4000-8000-000000000000",
+}
+
+// exampleValue returns a value valid against schema, built from its examples, defaults, constants and enums,
+// and from placeholder values of its types otherwise. Tools without an output schema get an empty object.
+func exampleValue(schema *jsonschema.Schema) any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	g := &exampleGenerator{root: schema}
+	return g.value(schema, "", 0)
+}
+
+type exampleGenerator struct {
+	root *jsonschema.Schema
+}
+
+func (g *exampleGenerator) value(s *jsonschema.Schema, name string, depth int) any {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if s.Ref != "" {
+		return g.value(g.lookup(s.Ref), name, depth+1)
+	}
+
+	switch {
+	case s.Const != nil:
+		return *s.Const
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case len(s.Default) > 0:
+		var v any
+		if err := json.Unmarshal(s.Default, &v); err == nil {
+			return v
+		}
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		merged := map[string]any{}
+		for _, sub := range s.AllOf {
+			if obj, ok := g.value(sub, name, depth+1).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		if len(s.Properties) > 0 {
+			for k, v := range g.object(s, depth) {
+				merged[k] = v
+			}
+		}
+		return merged
+	case len(s.OneOf) > 0:
+		return g.value(s.OneOf[0], name, depth+1)
+	case len(s.AnyOf) > 0:
+		return g.value(s.AnyOf[0], name, depth+1)
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return g.object(s, depth)
+	case "array":
+		return g.array(s, name, depth)
+	case "string":
+		return exampleString(s, name)
+	case "integer":
+		return math.Round(exampleNumber(s))
+	case "number":
+		return exampleNumber(s)
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// lookup returns the schema of the local reference ref, e.g. #/$defs/user.
+func (g *exampleGenerator) lookup(ref string) *jsonschema.Schema {
+	switch {
+	case strings.HasPrefix(ref, "#/$defs/"):
+		return g.root.Defs[strings.TrimPrefix(ref, "#/$defs/")]
+	case strings.HasPrefix(ref, "#/definitions/"):
+		return g.root.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	case ref == "#":
+		return g.root
+	}
+	return nil
+}
+
+func (g *exampleGenerator) object(s *jsonschema.Schema, depth int) map[string]any {
+	obj := make(map[string]any, len(s.Properties))
+	for prop, sub := range s.Properties {
+		obj[prop] = g.value(sub, prop, depth+1)
+	}
+	return obj
+}
+
+func (g *exampleGenerator) array(s *jsonschema.Schema, name string, depth int) []any {
+	items := make([]any, 0, len(s.PrefixItems)+1)
+	for _, sub := range s.PrefixItems {
+		items = append(items, g.value(sub, name, depth+1))
+	}
+
+	n := 1
+	if s.MinItems != nil && *s.MinItems > n {
+		n = *s.MinItems
+	}
+	if s.MaxItems != nil && *s.MaxItems < n {
+		n = *s.MaxItems
+	}
+	for len(items) < n && s.Items != nil {
+		items = append(items, g.value(s.Items, name, depth+1))
+	}
+
+	return items
+}
+
+// schemaType returns the type of s, the first one other than null if it has several, inferred from its
+// keywords if it has none.
+func schemaType(s *jsonschema.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case s.Properties != nil:
+		return "object"
+	case s.Items != nil || s.PrefixItems != nil:
+		return "array"
+	}
+	return ""
+}
+
+// exampleString returns a value of the format of s, or the name of the property otherwise, so that
+// generated objects are readable.
+func exampleString(s *jsonschema.Schema, name string) string {
+	if v, ok := exampleStrings[s.Format]; ok {
+		return v
+	}
+
+	v := name
+	if v == "" {
+		v = "string"
+	}
+	if s.MinLength != nil && len(v) < *s.MinLength {
+		v += strings.Repeat("x", *s.MinLength-len(v))
+	}
+	if s.MaxLength != nil && len(v) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
+
+// exampleNumber returns 1, or the closest value to it within the bounds of s.
+func exampleNumber(s *jsonschema.Schema) float64 {
+	v := 1.0
+	if s.Minimum != nil && v < *s.Minimum {
+		v = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+		v = math.Floor(*s.ExclusiveMinimum) + 1
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		v = *s.Maximum
+	}
+	if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+		v = math.Ceil(*s.ExclusiveMaximum) - 1
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleValue(t *testing.T) {
+	tt := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{
+			name:     "no schema",
+			expected: `{}`,
+		},
+		{
+			name:     "scalars",
+			schema:   `{"type":"object","properties":{"name":{"type":"string"},"count":{"type":"integer"},"ratio":{"type":"number","maximum":0.5},"active":{"type":"boolean"}}}`,
+			expected: `{"name":"name","count":1,"ratio":0.5,"active":true}`,
+		},
+		{
+			name:     "examples, defaults, constants and enums",
+			schema:   `{"type":"object","properties":{"a":{"type":"string","examples":["example"]},"b":{"type":"integer","default":7},"c":{"const":"fixed"},"d":{"enum":["open","closed"]}}}`,
+			expected: `{"a":"example","b":7,"c":"fixed","d":"open"}`,
+		},
+		{
+			name:     "formats and bounds",
+			schema:   `{"type":"object","properties":{"created":{"type":"string","format":"date-time"},"id":{"type":"string","format":"uuid"},"code":{"type":"string","minLength":6},"port":{"type":"integer","exclusiveMinimum":1024}}}`,
+			expected: `{"created":"2024-01-01T00:00:00Z","id":"00000000-0000-4000-8000-000000000000","code":"codexx","port":1025}`,
+		},
+		{
+			name:     "arrays",
+			schema:   `{"type":"array","minItems":2,"items":{"type":["string","null"]}}`,
+			expected: `["string","string"]`,
+		},
+		{
+			name:     "references and compositions",
+			schema:   `{"$defs":{"user":{"type":"object","properties":{"name":{"type":"string"}}}},"type":"object","properties":{"owner":{"$ref":"#/$defs/user"},"id":{"oneOf":[{"type":"integer"},{"type":"string"}]},"both":{"allOf":[{"properties":{"x":{"type":"integer"}}},{"properties":{"y":{"type":"integer"}}}]}}}`,
+			expected: `{"owner":{"name":"name"},"id":1,"both":{"x":1,"y":1}}`,
+		},
+		{
+			name:     "recursive schema",
+			schema:   `{"$defs":{"node":{"type":"object","properties":{"child":{"$ref":"#/$defs/node"}}}},"$ref":"#/$defs/node"}`,
+			expected: `{"child":{"child":{"child":{"child":null}}}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var schema *jsonschema.Schema
+			if tc.schema != "" {
+				schema = &jsonschema.Schema{}
+				require.NoError(t, json.Unmarshal([]byte(tc.schema), schema))
+			}
+
+			data, err := json.Marshal(exampleValue(schema))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(data))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package netpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// ErrDenied is returned when a request targets a destination denied by the network policy.
+var ErrDenied = errors.New("destination denied by the network policy")
+
+// linkLocalNets are the link-local networks, where the metadata endpoints of cloud providers are, and the
+// metadata endpoints outside of them. They are denied unless the policy allows link-local destinations.
+var linkLocalNets = []netip.Prefix{
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("fd00:ec2::254/128"),  // AWS IPv6 instance metadata
+	netip.MustParsePrefix("100.100.100.200/32"), // Alibaba Cloud instance metadata
+}
+
+// metadataHosts are the host names of the metadata endpoints of cloud providers, denied unless the policy
+// allows link-local destinations.
+var metadataHosts = []string{
+	"metadata",
+	"metadata.google.internal",
+	"metadata.goog",
+	"instance-data",
+	"instance-data.ec2.internal",
+}
+
+// Config defines the destinations outbound requests may target.
+type Config struct {
+	// Host names, IP addresses and CIDRs requests may target. Host names match case-insensitively, and a
+	// leading "*." matches any subdomain. Any destination that isn't denied may be targeted if empty.
+	AllowedHosts []string
+
+	// Host names, IP addresses and CIDRs requests may never target, even if they are allowed.
+	DeniedHosts []string
+
+	// Allows link-local addresses and the metadata endpoints of cloud providers, which are denied by default
+	// as they expose the credentials of the machine.
+	AllowLinkLocal bool
+}
+
+// Policy restricts the destinations of outbound requests. Destinations are checked by host name before
+// requests are sent, and by the addresses host names resolve to when connecting, so that a host name
+// resolving to a denied address is denied too.
+type Policy struct {
+	allowedNames []string
+	allowedNets  []netip.Prefix
+	deniedNames  []string
+	deniedNets   []netip.Prefix
+}
+
+// New creates the policy of config.
+func New(config Config) (*Policy, error) {
+	p := &Policy{}
+
+	var err error
+	if p.allowedNames, p.allowedNets, err = parseHosts(config.AllowedHosts); err != nil {
+		return nil, fmt.Errorf("invalid allowed hosts: %w", err)
+	}
+	if p.deniedNames, p.deniedNets, err = parseHosts(config.DeniedHosts); err != nil {
+		return nil, fmt.Errorf("invalid denied hosts: %w", err)
+	}
+	if !config.AllowLinkLocal {
+		p.deniedNames = append(p.deniedNames, metadataHosts...)
+		p.deniedNets = append(p.deniedNets, linkLocalNets...)
+	}
+
+	return p, nil
+}
+
+// CheckURL checks that the host of rawURL may be targeted.
+func (p *Policy) CheckURL(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	return p.CheckHost(u.Hostname())
+}
+
+// CheckHost checks that host, a host name or an IP address, may be targeted. Host names that aren't allowed
+// by name are accepted if the policy allows CIDRs, as the addresses they resolve to are checked when
+// connecting.
+func (p *Policy) CheckHost(host string) error {
+	host = normalizeHost(host)
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap().WithZone("")
+		return p.checkAddr(addr.String(), addr, false)
+	}
+
+	if matchesName(p.deniedNames, host) {
+		return fmt.Errorf("%w: %s is denied", ErrDenied, host)
+	}
+	if p.restricted() && !matchesName(p.allowedNames, host) && len(p.allowedNets) == 0 {
+		return fmt.Errorf("%w: %s is not allowed", ErrDenied, host)
+	}
+	return nil
+}
+
+// DialContext returns a dial function checking the destinations of dialer, by host name and by the
+// addresses it connects to, for the DialContext of an http.Transport.
+func (p *Policy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.CheckHost(host); err != nil {
+			return nil, err
+		}
+		allowedByName := matchesName(p.allowedNames, normalizeHost(host))
+
+		d := *dialer
+		d.Control = func(network, address string, c syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				return err
+			}
+			if err := p.checkAddr(host, addr, allowedByName); err != nil {
+				return err
+			}
+			if dialer.Control != nil {
+				return dialer.Control(network, address, c)
+			}
+			return nil
+		}
+		return d.DialContext(ctx, network, address)
+	}
+}
+
+// Proxy returns a proxy function checking the host of the requests before passing them to next, for the
+// Proxy of an http.Transport, so that the destinations of requests sent through a proxy are checked too.
+// Only their host names are checked, as they are resolved by the proxy.
+func (p *Policy) Proxy(next func(*http.Request) (*neturl.URL, error)) func(*http.Request) (*neturl.URL, error) {
+	return func(req *http.Request) (*neturl.URL, error) {
+		if err := p.CheckHost(req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, nil
+		}
+		return next(req)
+	}
+}
+
+// checkAddr checks that addr, the address of host, may be targeted. Addresses of hosts allowed by name only
+// need not be denied.
+func (p *Policy) checkAddr(host string, addr netip.Addr, allowedByName bool) error {
+	addr = addr.Unmap().WithZone("")
+	if slices.ContainsFunc(p.deniedNets, func(n netip.Prefix) bool { return n.Contains(addr) }) {
+		if host == addr.String() {
+			return fmt.Errorf("%w: %s is denied", ErrDenied, addr)
+		}
+		return fmt.Errorf("%w: %s resolves to %s, which is denied", ErrDenied, host, addr)
+	}
+	if p.restricted() && !allowedByName && !slices.ContainsFunc(p.allowedNets, func(n netip.Prefix) bool { return n.Contains(addr) }) {
+		if host == addr.String() {
+			return fmt.Errorf("%w: %s is not allowed", ErrDenied, addr)
+		}
+		return fmt.Errorf("%w: %s resolves to %s, which is not allowed", ErrDenied, host, addr)
+	}
+	return nil
+}
+
+// restricted reports whether only allowed destinations may be targeted.
+func (p *Policy) restricted() bool {
+	return len(p.allowedNames) > 0 || len(p.allowedNets) > 0
+}
+
+// parseHosts splits hosts into host name patterns and networks, IP addresses being single address networks.
+func parseHosts(hosts []string) ([]string, []netip.Prefix, error) {
+	var names []string
+	var nets []netip.Prefix
+	for _, host := range hosts {
+		if strings.Contains(host, "/") {
+			prefix, err := netip.ParsePrefix(host)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid CIDR %q", host)
+			}
+			nets = append(nets, prefix.Masked())
+			continue
+		}
+
+		host = normalizeHost(host)
+		if addr, err := netip.ParseAddr(host); err == nil {
+			nets = append(nets, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		if name := strings.TrimPrefix(host, "*."); name == "" || strings.ContainsAny(name, "*:/ ") {
+			return nil, nil, fmt.Errorf("invalid host %q", host)
+		}
+		names = append(names, host)
+	}
+	return names, nets, nil
+}
+
+// matchesName reports whether the host name host matches one of patterns.
+func matchesName(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	return strings.ToLower(host)
+}
@@ -0,0 +1,219 @@
+package netpolicy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheckURL(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        Config
+		url           string
+		expectedError string
+	}{
+		{
+			name: "public host",
+			url:  "https://api.example.com/users",
+		},
+		{
+			name: "loopback address",
+			url:  "http://127.0.0.1:8080/users",
+		},
+		{
+			name:          "link-local address",
+			url:           "http://169.254.169.254/latest/meta-data/",
+			expectedError: "destination denied by the network policy: 169.254.169.254 is denied",
+		},
+		{
+			name:          "IPv6 link-local address",
+			url:           "http://[fe80::1]/",
+			expectedError: "destination denied by the network policy: fe80::1 is denied",
+		},
+		{
+			name:          "IPv4-mapped link-local address",
+			url:           "http://[::ffff:169.254.169.254]/",
+			expectedError: "destination denied by the network policy: 169.254.169.254 is denied",
+		},
+		{
+			name:          "metadata host name",
+			url:           "http://Metadata.Google.Internal./computeMetadata/v1/",
+			expectedError: "destination denied by the network policy: metadata.google.internal is denied",
+		},
+		{
+			name:   "link-local address allowed",
+			config: Config{AllowLinkLocal: true},
+			url:    "http://169.254.169.254/latest/meta-data/",
+		},
+		{
+			name:   "allowed host",
+			config: Config{AllowedHosts: []string{"api.example.com"}},
+			url:    "https://API.example.com/users",
+		},
+		{
+			name:   "allowed subdomain",
+			config: Config{AllowedHosts: []string{"*.example.com"}},
+			url:    "https://eu.api.example.com/users",
+		},
+		{
+			name:          "apex of allowed subdomains",
+			config:        Config{AllowedHosts: []string{"*.example.com"}},
+			url:           "https://example.com/users",
+			expectedError: "destination denied by the network policy: example.com is not allowed",
+		},
+		{
+			name:   "address in allowed CIDR",
+			config: Config{AllowedHosts: []string{"10.0.0.0/8"}},
+			url:    "http://10.1.2.3/users",
+		},
+		{
+			name:          "address outside allowed CIDR",
+			config:        Config{AllowedHosts: []string{"10.0.0.0/8"}},
+			url:           "http://192.168.1.1/users",
+			expectedError: "destination denied by the network policy: 192.168.1.1 is not allowed",
+		},
+		{
+			name:   "host name checked when connecting with allowed CIDRs",
+			config: Config{AllowedHosts: []string{"10.0.0.0/8"}},
+			url:    "http://users.internal/users",
+		},
+		{
+			name:          "denied host",
+			config:        Config{AllowedHosts: []string{"*.example.com"}, DeniedHosts: []string{"admin.example.com"}},
+			url:           "https://admin.example.com/users",
+			expectedError: "destination denied by the network policy: admin.example.com is denied",
+		},
+		{
+			name:          "denied CIDR",
+			config:        Config{DeniedHosts: []string{"10.0.0.0/8"}},
+			url:           "http://10.1.2.3/users",
+			expectedError: "destination denied by the network policy: 10.1.2.3 is denied",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New(tc.config)
+			require.NoError(t, err)
+
+			err = p.CheckURL(tc.url)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrDenied)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{
+			name:   "hosts, addresses and CIDRs",
+			config: Config{AllowedHosts: []string{"api.example.com", "*.internal", "10.0.0.1", "::1", "192.168.0.0/16"}},
+		},
+		{
+			name:          "invalid CIDR",
+			config:        Config{AllowedHosts: []string{"10.0.0.0/33"}},
+			expectedError: `invalid allowed hosts: invalid CIDR "10.0.0.0/33"`,
+		},
+		{
+			name:          "wildcard inside host",
+			config:        Config{DeniedHosts: []string{"api.*.example.com"}},
+			expectedError: `invalid denied hosts: invalid host "api.*.example.com"`,
+		},
+		{
+			name:          "host with port",
+			config:        Config{AllowedHosts: []string{"api.example.com:443"}},
+			expectedError: `invalid allowed hosts: invalid host "api.example.com:443"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.config)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestPolicyTransport(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+	backendURL, err := neturl.Parse(backend.URL)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		config        Config
+		url           string
+		expectedError string
+	}{
+		{
+			name: "allowed address",
+			url:  backend.URL,
+		},
+		{
+			name:   "host name resolving to an allowed CIDR",
+			config: Config{AllowedHosts: []string{"127.0.0.0/8"}},
+			url:    "http://localhost:" + backendURL.Port(),
+		},
+		{
+			name:          "host name resolving to a denied CIDR",
+			config:        Config{DeniedHosts: []string{"127.0.0.0/8", "::1"}},
+			url:           "http://localhost:" + backendURL.Port(),
+			expectedError: "destination denied by the network policy: localhost resolves to",
+		},
+		{
+			name:          "host name resolving outside of the allowed CIDRs",
+			config:        Config{AllowedHosts: []string{"10.0.0.0/8"}},
+			url:           "http://localhost:" + backendURL.Port(),
+			expectedError: "destination denied by the network policy: localhost resolves to",
+		},
+		{
+			name:          "denied host name",
+			config:        Config{DeniedHosts: []string{"localhost"}},
+			url:           "http://localhost:" + backendURL.Port(),
+			expectedError: "destination denied by the network policy: localhost is denied",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New(tc.config)
+			require.NoError(t, err)
+
+			transport := &http.Transport{
+				DialContext: p.DialContext(&net.Dialer{}),
+				Proxy:       p.Proxy(nil),
+			}
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+				return
+			}
+			assert.ErrorIs(t, err, ErrDenied)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
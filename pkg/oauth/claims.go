@@ -2,7 +2,12 @@ package oauth
 
 import (
 	"context"
+	"maps"
 	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
 )
 
 // TokenClaims represents extracted token claims
@@ -17,6 +22,34 @@ type TokenClaims struct {
 	ClientID  string
 	Username  string
 	Email     string
+
+	// All the claims of the token, including custom claims such as a tenant ID. Nil for credentials that are
+	// not JWTs.
+	Claims map[string]any
+}
+
+// Map returns the claims keyed by their JWT names (sub, iss, aud, scope, client_id, username and email), with
+// the custom claims of the token.
+func (c *TokenClaims) Map() map[string]any {
+	m := make(map[string]any, len(c.Claims)+7)
+	maps.Copy(m, c.Claims)
+
+	set := func(name, value string) {
+		if value != "" {
+			m[name] = value
+		}
+	}
+	set("sub", c.Subject)
+	set("iss", c.Issuer)
+	set("scope", c.Scope)
+	set("client_id", c.ClientID)
+	set("username", c.Username)
+	set("email", c.Email)
+	if len(c.Audience) > 0 {
+		m["aud"] = c.Audience
+	}
+
+	return m
 }
 
 type claimKey struct{}
@@ -37,3 +70,16 @@ func GetClaimsFromContext(ctx context.Context) *TokenClaims {
 func AddClaimsToContext(ctx context.Context, claims *TokenClaims) context.Context {
 	return context.WithValue(ctx, claimKey{}, claims)
 }
+
+// WithClaimsMiddleware creates an MCP middleware that makes the claims of the credentials of the caller
+// available to invokers, which resolve {claims.name} references from them.
+func WithClaimsMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if claims := GetClaimsFromContext(ctx); claims != nil {
+				ctx = template.WithClaims(ctx, claims.Map())
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+func TestTokenClaimsMap(t *testing.T) {
+	claims := &TokenClaims{
+		Subject:  "alice",
+		Audience: []string{"genmcp"},
+		Scope:    "read write",
+		Email:    "alice@example.com",
+		Claims:   map[string]any{"sub": "alice", "tenant_id": "acme"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"sub":       "alice",
+		"aud":       []string{"genmcp"},
+		"scope":     "read write",
+		"email":     "alice@example.com",
+		"tenant_id": "acme",
+	}, claims.Map())
+}
+
+func TestWithClaimsMiddleware(t *testing.T) {
+	var tenant string
+	handler := WithClaimsMiddleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		tenant, _ = template.ClaimsFromContext(ctx).Resolve("tenant_id")
+		return nil, nil
+	})
+
+	ctx := AddClaimsToContext(context.Background(), &TokenClaims{Subject: "alice", Claims: map[string]any{"tenant_id": "acme"}})
+	_, err := handler(ctx, "tools/call", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", tenant)
+}
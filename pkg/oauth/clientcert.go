@@ -0,0 +1,56 @@
+package oauth
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+// clientCertHandler returns a handler that rejects requests without a client certificate. The certificate
+// itself is verified during the TLS handshake. If addClaims is true, the claims of the certificate are added
+// to the request context.
+func clientCertHandler(clientAuth *serverconfig.ClientAuthConfig, addClaims bool, next http.Handler) http.Handler {
+	scope := strings.Join(clientAuth.Scopes, " ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := clientCertClaims(r, scope)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, err := w.Write([]byte(`{"error":"invalid_request","error_description":"Missing client certificate"}`))
+			if err != nil {
+				log.Printf("failed to write response: %v", err)
+			}
+			return
+		}
+
+		if addClaims {
+			r = r.WithContext(AddClaimsToContext(r.Context(), claims))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientCertClaims returns the claims of the verified client certificate of the request, if any.
+// The subject of the certificate becomes the subject of the claims.
+func clientCertClaims(r *http.Request, scope string) (*TokenClaims, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	claims := &TokenClaims{
+		Subject:  cert.Subject.String(),
+		Issuer:   cert.Issuer.String(),
+		Scope:    scope,
+		Username: cert.Subject.CommonName,
+	}
+	if len(cert.EmailAddresses) > 0 {
+		claims.Email = cert.EmailAddresses[0]
+	}
+
+	return claims, true
+}
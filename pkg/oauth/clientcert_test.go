@@ -0,0 +1,224 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+func TestMiddlewareClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test CA", nil, nil)
+	otherCA := newTestCert(t, "Other CA", nil, nil)
+	server := newTestCert(t, "server", ca, func(c *x509.Certificate) {
+		c.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	})
+
+	certFile, keyFile := server.writeFiles(t, dir)
+	caFile, _ := ca.writeFiles(t, dir)
+
+	tlsConfig := &serverconfig.TLSConfig{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		ClientAuth: &serverconfig.ClientAuthConfig{
+			CACertFiles: []string{caFile},
+			AllowedSANs: []string{"client.example.com", "spiffe://example.com/agent"},
+			Scopes:      []string{"tools:read"},
+		},
+	}
+	mcpServer := &mcpserver.MCPServer{
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port: 8443,
+					TLS:  tlsConfig,
+				},
+			},
+		},
+	}
+
+	serverTLSConfig, err := tlsConfig.BuildTLSConfig()
+	require.NoError(t, err, "building the TLS config should not fail")
+
+	var claims *TokenClaims
+	s := httptest.NewUnstartedServer(Middleware(mcpServer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaimsFromContext(r.Context())
+	})))
+	s.TLS = serverTLSConfig
+	s.StartTLS()
+	defer s.Close()
+
+	clientCert := func(issuer *testCert, name string, sans func(c *x509.Certificate)) *tls.Certificate {
+		cert := newTestCert(t, name, issuer, func(c *x509.Certificate) {
+			c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+			sans(c)
+		})
+		tlsCert := cert.tlsCertificate()
+		return &tlsCert
+	}
+
+	tt := []struct {
+		name            string
+		certificate     *tls.Certificate
+		expectedStatus  int
+		expectedSubject string
+		expectError     bool
+	}{
+		{
+			name: "allowed DNS name",
+			certificate: clientCert(ca, "client-a", func(c *x509.Certificate) {
+				c.DNSNames = []string{"client.example.com"}
+			}),
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "CN=client-a",
+		},
+		{
+			name: "allowed URI",
+			certificate: clientCert(ca, "agent", func(c *x509.Certificate) {
+				c.URIs = []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/agent"}}
+			}),
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "CN=agent",
+		},
+		{
+			name: "SAN not allowed",
+			certificate: clientCert(ca, "client-b", func(c *x509.Certificate) {
+				c.DNSNames = []string{"other.example.com"}
+			}),
+			expectError: true,
+		},
+		{
+			name: "untrusted CA",
+			certificate: clientCert(otherCA, "client-c", func(c *x509.Certificate) {
+				c.DNSNames = []string{"client.example.com"}
+			}),
+			expectError: true,
+		},
+		{
+			name:           "missing client certificate",
+			certificate:    &tls.Certificate{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			claims = nil
+
+			rootCAs := x509.NewCertPool()
+			rootCAs.AddCert(ca.cert)
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:    rootCAs,
+				MinVersion: tls.VersionTLS12,
+				// always send the certificate, even if it is not signed by one of the CAs requested by the server
+				GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return tc.certificate, nil
+				},
+			}}}
+
+			resp, err := client.Get(s.URL + "/mcp")
+			if tc.expectError {
+				assert.Error(t, err, "the TLS handshake should fail")
+				return
+			}
+			require.NoError(t, err, "request should not fail")
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode, "status code should match")
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if assert.NotNil(t, claims, "claims should be added to the request context") {
+				assert.Equal(t, tc.expectedSubject, claims.Subject, "subject should be the certificate subject")
+				assert.Equal(t, "tools:read", claims.Scope, "client auth scopes should be granted")
+			}
+		})
+	}
+}
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate signed by issuer, or a self-signed CA certificate if issuer is nil.
+func newTestCert(t *testing.T, commonName string, issuer *testCert, customize func(c *x509.Certificate)) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "generating a key should not fail")
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err, "generating a serial number should not fail")
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	parent, signer := template, key
+	if issuer == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		parent, signer = issuer.cert, issuer.key
+	}
+	if customize != nil {
+		customize(template)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	require.NoError(t, err, "creating the certificate should not fail")
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "parsing the certificate should not fail")
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// writeFiles writes the PEM encoded certificate and key to dir and returns their paths.
+func (c *testCert) writeFiles(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err, "encoding the key should not fail")
+
+	certFile := filepath.Join(dir, c.cert.Subject.CommonName+".crt")
+	keyFile := filepath.Join(dir, c.cert.Subject.CommonName+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
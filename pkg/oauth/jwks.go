@@ -0,0 +1,102 @@
+package oauth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+const (
+	// DefaultJWKSRefreshInterval is how long fetched JWKS are used before they are fetched again
+	DefaultJWKSRefreshInterval = 10 * time.Minute
+
+	// minJWKSRefreshInterval is the minimum time between two fetches of a JWKS, so that tokens signed
+	// with unknown keys cannot make the server hammer the JWKS endpoint
+	minJWKSRefreshInterval = 30 * time.Second
+)
+
+// keySetCache caches the JWKS of an issuer. The JWKS is fetched again once it is older than the refresh
+// interval, and when a token is signed with a key it does not contain, so that rotated keys are picked up.
+type keySetCache struct {
+	resolveURI      func(ctx context.Context) (string, error)
+	fetch           func(ctx context.Context, uri string) (jwk.Set, error)
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	uri         string
+	keySet      jwk.Set
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func newKeySetCache(resolveURI func(ctx context.Context) (string, error), fetch func(ctx context.Context, uri string) (jwk.Set, error), refreshInterval time.Duration) *keySetCache {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+	return &keySetCache{
+		resolveURI:      resolveURI,
+		fetch:           fetch,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// get returns the cached JWKS, fetching it if it is not cached yet or older than the refresh interval.
+// The previous JWKS is returned if fetching it again fails, so that tokens can still be validated while
+// the JWKS endpoint is unavailable.
+func (c *keySetCache) get(ctx context.Context) (jwk.Set, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keySet != nil && (c.now().Sub(c.fetchedAt) < c.refreshInterval || c.now().Sub(c.attemptedAt) < minJWKSRefreshInterval) {
+		return c.keySet, nil
+	}
+	keySet, _, err := c.load(ctx)
+	return keySet, err
+}
+
+// refresh fetches the JWKS again, e.g. because a token is signed with a key it does not contain. It
+// reports whether a new JWKS was fetched, which is not the case if it was fetched too recently.
+func (c *keySetCache) refresh(ctx context.Context) (jwk.Set, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keySet != nil && c.now().Sub(c.attemptedAt) < minJWKSRefreshInterval {
+		return c.keySet, false, nil
+	}
+	return c.load(ctx)
+}
+
+func (c *keySetCache) load(ctx context.Context) (jwk.Set, bool, error) {
+	c.attemptedAt = c.now()
+
+	if c.uri == "" {
+		uri, err := c.resolveURI(ctx)
+		if err != nil {
+			return c.keySet, false, c.fallback(err)
+		}
+		c.uri = uri
+	}
+
+	keySet, err := c.fetch(ctx, c.uri)
+	if err != nil {
+		return c.keySet, false, c.fallback(err)
+	}
+
+	c.keySet = keySet
+	c.fetchedAt = c.attemptedAt
+	return keySet, true, nil
+}
+
+// fallback returns err, unless a previous JWKS can be used instead.
+func (c *keySetCache) fallback(err error) error {
+	if c.keySet == nil {
+		return err
+	}
+	log.Printf("failed to fetch JWKS from %s, using the previous JWKS: %v", c.uri, err)
+	return nil
+}
@@ -0,0 +1,109 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySetCache(t *testing.T) {
+	tt := []struct {
+		name            string
+		elapsed         time.Duration
+		refresh         bool
+		fetchErr        error
+		expectedFetches int
+		expectedFetched bool
+	}{
+		{
+			name:            "cached JWKS",
+			elapsed:         time.Minute,
+			expectedFetches: 1,
+		},
+		{
+			name:            "JWKS older than the refresh interval",
+			elapsed:         11 * time.Minute,
+			expectedFetches: 2,
+		},
+		{
+			name:            "refresh for an unknown key",
+			elapsed:         time.Minute,
+			refresh:         true,
+			expectedFetches: 2,
+			expectedFetched: true,
+		},
+		{
+			name:            "refresh right after a fetch",
+			elapsed:         time.Second,
+			refresh:         true,
+			expectedFetches: 1,
+		},
+		{
+			name:            "previous JWKS while the endpoint is unavailable",
+			elapsed:         11 * time.Minute,
+			fetchErr:        fmt.Errorf("connection refused"),
+			expectedFetches: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			keySet := jwk.NewSet()
+			fetches := 0
+			resolves := 0
+
+			cache := newKeySetCache(
+				func(ctx context.Context) (string, error) {
+					resolves++
+					return "https://auth.example.com/jwks", nil
+				},
+				func(ctx context.Context, uri string) (jwk.Set, error) {
+					fetches++
+					if fetches > 1 && tc.fetchErr != nil {
+						return nil, tc.fetchErr
+					}
+					return keySet, nil
+				},
+				10*time.Minute,
+			)
+			cache.now = func() time.Time { return now }
+
+			first, err := cache.get(context.Background())
+			require.NoError(t, err)
+			assert.Same(t, keySet, first)
+
+			now = now.Add(tc.elapsed)
+
+			var result jwk.Set
+			if tc.refresh {
+				var fetched bool
+				result, fetched, err = cache.refresh(context.Background())
+				assert.Equal(t, tc.expectedFetched, fetched)
+			} else {
+				result, err = cache.get(context.Background())
+			}
+			require.NoError(t, err)
+			assert.Same(t, keySet, result)
+			assert.Equal(t, tc.expectedFetches, fetches)
+			assert.Equal(t, 1, resolves)
+		})
+	}
+}
+
+func TestKeySetCacheFetchError(t *testing.T) {
+	cache := newKeySetCache(
+		func(ctx context.Context) (string, error) { return "https://auth.example.com/jwks", nil },
+		func(ctx context.Context, uri string) (jwk.Set, error) { return nil, fmt.Errorf("connection refused") },
+		0,
+	)
+
+	_, err := cache.get(context.Background())
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, DefaultJWKSRefreshInterval, cache.refreshInterval)
+}
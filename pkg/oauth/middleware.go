@@ -5,8 +5,8 @@ import (
 	"log"
 	"net/http"
 	"slices"
-	"strings"
 
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
 	"github.com/genmcp/gen-mcp/pkg/mcpserver"
 )
 
@@ -14,50 +14,98 @@ const (
 	ProtectedResourceMetadataEndpoint = "/.well-known/oauth-protected-resource"
 )
 
-// Middleware returns a middleware function that checks if the Authorization Header is set and otherwise returns a 401
-// with the WWW-Authenticate header containing information about the Protected Resource Endpoint
+// Middleware returns a middleware function that authenticates requests with the configured client certificates,
+// static credentials or OAuth access tokens. Without valid credentials it returns a 401, with the WWW-Authenticate
+// header containing information about the Protected Resource Endpoint if OAuth is configured.
 func Middleware(config *mcpserver.MCPServer) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		httpConfig := config.Runtime.StreamableHTTPConfig
 
-		// Only create OAuth handler if auth configured
-		if httpConfig.Auth == nil {
-			return next // No OAuth config, just pass through
+		handler := next
+		if httpConfig.Auth != nil {
+			handler = credentialsHandler(httpConfig.Auth, config.Runtime, next)
 		}
 
-		// Create token validator from auth config
-		validator := NewTokenValidator(TokenValidatorConfig{
-			JWKSURI:              httpConfig.Auth.JWKSURI,
-			AuthorizationServers: httpConfig.Auth.AuthorizationServers,
-		})
+		// The client certificate is required in addition to the credentials, its claims are only
+		// used if there are no credentials to authenticate the client with
+		if httpConfig.TLS != nil && httpConfig.TLS.ClientAuth != nil {
+			handler = clientCertHandler(httpConfig.TLS.ClientAuth, httpConfig.Auth == nil, handler)
+		}
 
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return handler
+	}
+}
 
-			// Check if auth header is set
-			authHeader, ok := r.Header["Authorization"]
-			if !ok || len(authHeader) != 1 || !strings.HasPrefix(authHeader[0], "Bearer ") {
-				write401(w, r, `{"error":"invalid_request","error_description":"Missing access token"}`)
-				return
-			}
+// credentialsHandler returns a handler that authenticates requests with static credentials or OAuth access tokens.
+// The discovery documents and JWKS of the authorization servers are fetched with the HTTP client of runtime.
+func credentialsHandler(authConfig *serverconfig.AuthConfig, runtime *serverconfig.ServerRuntime, next http.Handler) http.Handler {
+	static := newStaticAuthenticator(authConfig)
+
+	// Create token validator from auth config
+	var validator *TokenValidator
+	var validatorErr error
+	if authConfig.UsesOAuth() {
+		client, err := runtime.GetHTTPClient()
+		if err != nil {
+			// tokens are rejected rather than validated with keys fetched outside the network policy
+			validatorErr = fmt.Errorf("failed to create HTTP client: %w", err)
+		}
 
-			// Extract token from Bearer header
-			tokenString := strings.TrimPrefix(authHeader[0], "Bearer ")
+		validatorConfig := TokenValidatorConfig{
+			Client:               client,
+			JWKSURI:              authConfig.JWKSURI,
+			AuthorizationServers: authConfig.AuthorizationServers,
+			JWKSRefreshInterval:  authConfig.GetJWKSRefreshInterval(),
+		}
+		for _, issuer := range authConfig.Issuers {
+			validatorConfig.Issuers = append(validatorConfig.Issuers, IssuerConfig{
+				Issuer:       issuer.Issuer,
+				JWKSURI:      issuer.JWKSURI,
+				Audiences:    issuer.Audiences,
+				ScopeClaim:   issuer.ScopeClaim,
+				ScopeMapping: issuer.ScopeMapping,
+			})
+		}
+		validator = NewTokenValidator(validatorConfig)
+	}
 
-			// Validate the token and extract claims
-			claims, err := validator.ValidateToken(r.Context(), tokenString)
-			if err != nil {
-				write401(w, r, fmt.Sprintf(`{"error":"invalid_token","error_description":"Token validation failed: %s"}`, err.Error()))
-				return
-			}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Static credentials are checked first, as they are cheap to validate
+		if claims, ok := static.authenticate(r); ok {
+			next.ServeHTTP(w, r.WithContext(AddClaimsToContext(r.Context(), claims)))
+			return
+		}
 
-			// Add claims to request context for downstream handlers
-			ctx := AddClaimsToContext(r.Context(), claims)
-			r = r.WithContext(ctx)
+		if validator == nil {
+			static.write401(w)
+			return
+		}
 
-			// Token is valid -> continue request
-			next.ServeHTTP(w, r)
-		})
-	}
+		// Check if auth header is set
+		tokenString, ok := bearerToken(r)
+		if !ok {
+			write401(w, r, `{"error":"invalid_request","error_description":"Missing access token"}`)
+			return
+		}
+
+		// Validate the token and extract claims
+		if validatorErr != nil {
+			write401(w, r, fmt.Sprintf(`{"error":"invalid_token","error_description":"Token validation failed: %s"}`, validatorErr.Error()))
+			return
+		}
+		claims, err := validator.ValidateToken(r.Context(), tokenString)
+		if err != nil {
+			write401(w, r, fmt.Sprintf(`{"error":"invalid_token","error_description":"Token validation failed: %s"}`, err.Error()))
+			return
+		}
+
+		// Add claims to request context for downstream handlers
+		ctx := AddClaimsToContext(r.Context(), claims)
+		r = r.WithContext(ctx)
+
+		// Token is valid -> continue request
+		next.ServeHTTP(w, r)
+	})
 }
 
 func write401(w http.ResponseWriter, r *http.Request, body string) {
@@ -86,26 +134,38 @@ func ProtectedResourceMetadataHandler(config *mcpserver.MCPServer) http.HandlerF
 	httpConfig := config.Runtime.StreamableHTTPConfig
 
 	// Only create OAuth handler if configured
-	if httpConfig.Auth == nil {
+	if httpConfig.Auth == nil || !httpConfig.Auth.UsesOAuth() {
 		return func(writer http.ResponseWriter, request *http.Request) {
 			writer.WriteHeader(http.StatusNotFound)
 		}
 	}
 
-	// Add the scopes, which are defined in the tools
+	// Add the scopes, which are defined in the tools, prompts, resources and resource templates
 	var scopes []string
-	for _, tool := range config.Tools {
-		for _, requiredScope := range tool.RequiredScopes {
+	addScopes := func(requiredScopes []string) {
+		for _, requiredScope := range requiredScopes {
 			if !slices.Contains(scopes, requiredScope) {
 				scopes = append(scopes, requiredScope)
 			}
 		}
 	}
+	for _, tool := range config.Tools {
+		addScopes(tool.RequiredScopes)
+	}
+	for _, prompt := range config.Prompts {
+		addScopes(prompt.RequiredScopes)
+	}
+	for _, resource := range config.Resources {
+		addScopes(resource.RequiredScopes)
+	}
+	for _, resourceTemplate := range config.ResourceTemplates {
+		addScopes(resourceTemplate.RequiredScopes)
+	}
 
 	// Convert mcpfile.AuthConfig to oauth.MetadataConfig
 	metadataConfig := MetadataConfig{
 		ResourceName:         config.Name(),
-		AuthorizationServers: httpConfig.Auth.AuthorizationServers,
+		AuthorizationServers: httpConfig.Auth.IssuerURLs(),
 		JWKSURI:              httpConfig.Auth.JWKSURI,
 		ScopesSupported:      scopes,
 	}
@@ -0,0 +1,100 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+// staticAuthenticator authenticates clients with static bearer tokens or HTTP basic auth.
+type staticAuthenticator struct {
+	bearerTokens [][sha256.Size]byte
+	basicUsers   map[string][sha256.Size]byte
+	scope        string
+}
+
+// newStaticAuthenticator returns an authenticator for the static credentials in config,
+// or nil if there are none.
+func newStaticAuthenticator(config *serverconfig.AuthConfig) *staticAuthenticator {
+	if !config.HasStaticCredentials() {
+		return nil
+	}
+
+	// secrets are compared as hashes, so that comparisons take the same time whatever their length
+	sa := &staticAuthenticator{
+		basicUsers: make(map[string][sha256.Size]byte),
+		scope:      strings.Join(config.StaticScopes, " "),
+	}
+	for _, token := range config.BearerTokens {
+		sa.bearerTokens = append(sa.bearerTokens, sha256.Sum256([]byte(token)))
+	}
+	if config.BasicAuth != nil {
+		for user, password := range config.BasicAuth.Users {
+			sa.basicUsers[user] = sha256.Sum256([]byte(password))
+		}
+	}
+
+	return sa
+}
+
+// authenticate returns the claims of the client if the request has valid static credentials.
+func (sa *staticAuthenticator) authenticate(r *http.Request) (*TokenClaims, bool) {
+	if sa == nil {
+		return nil, false
+	}
+
+	if user, password, ok := r.BasicAuth(); ok {
+		expected, known := sa.basicUsers[user]
+		actual := sha256.Sum256([]byte(password))
+		if !known || subtle.ConstantTimeCompare(expected[:], actual[:]) != 1 {
+			return nil, false
+		}
+		return &TokenClaims{Subject: user, Username: user, Scope: sa.scope}, true
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, false
+	}
+
+	actual := sha256.Sum256([]byte(token))
+	matched := 0
+	for _, expected := range sa.bearerTokens {
+		matched |= subtle.ConstantTimeCompare(expected[:], actual[:])
+	}
+	if matched != 1 {
+		return nil, false
+	}
+
+	return &TokenClaims{Subject: "static-token", Scope: sa.scope}, true
+}
+
+// write401 rejects a request that has no valid static credentials.
+func (sa *staticAuthenticator) write401(w http.ResponseWriter) {
+	if len(sa.bearerTokens) > 0 {
+		w.Header().Add("WWW-Authenticate", "Bearer")
+	}
+	if len(sa.basicUsers) > 0 {
+		w.Header().Add("WWW-Authenticate", `Basic realm="mcp", charset="UTF-8"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, err := w.Write([]byte(`{"error":"invalid_request","error_description":"Missing or invalid credentials"}`))
+	if err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// bearerToken returns the token of the Authorization header, if it is a bearer token.
+func bearerToken(r *http.Request) (string, bool) {
+	authHeader, ok := r.Header["Authorization"]
+	if !ok || len(authHeader) != 1 || !strings.HasPrefix(authHeader[0], "Bearer ") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(authHeader[0], "Bearer "), true
+}
@@ -0,0 +1,100 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+func TestMiddlewareStaticCredentials(t *testing.T) {
+	mcpServer := &mcpserver.MCPServer{
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port: 8080,
+					Auth: &serverconfig.AuthConfig{
+						BearerTokens: []string{"first-token", "second-token"},
+						BasicAuth: &serverconfig.BasicAuthConfig{
+							Users: map[string]string{"admin": "s3cret"},
+						},
+						StaticScopes: []string{"tools:read", "tools:write"},
+					},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		name            string
+		setAuth         func(r *http.Request)
+		expectedStatus  int
+		expectedSubject string
+	}{
+		{
+			name:            "valid bearer token",
+			setAuth:         func(r *http.Request) { r.Header.Set("Authorization", "Bearer second-token") },
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "static-token",
+		},
+		{
+			name:           "invalid bearer token",
+			setAuth:        func(r *http.Request) { r.Header.Set("Authorization", "Bearer other-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:            "valid basic auth",
+			setAuth:         func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") },
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "admin",
+		},
+		{
+			name:           "wrong basic auth password",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown basic auth user",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("guest", "s3cret") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing credentials",
+			setAuth:        func(r *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var claims *TokenClaims
+			handler := Middleware(mcpServer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims = GetClaimsFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			tc.setAuth(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code, "status code should match")
+			if tc.expectedStatus != http.StatusOK {
+				assert.ElementsMatch(t, []string{"Bearer", `Basic realm="mcp", charset="UTF-8"`}, rec.Header().Values("WWW-Authenticate"),
+					"every static auth scheme should be offered")
+				return
+			}
+
+			if assert.NotNil(t, claims, "claims should be added to the request context") {
+				assert.Equal(t, tc.expectedSubject, claims.Subject, "subject should match")
+				assert.Equal(t, "tools:read tools:write", claims.Scope, "static scopes should be granted")
+			}
+		})
+	}
+}
@@ -22,80 +22,211 @@ type OIDCDiscoveryDocument struct {
 
 // TokenValidatorConfig holds configuration for token validation
 type TokenValidatorConfig struct {
-	JWKSURI              string        // Explicit JWKS URI
-	AuthorizationServers []string      // Authorization servers for discovery
-	HTTPTimeout          time.Duration // HTTP client timeout (default: 5s)
+	JWKSURI              string         // Explicit JWKS URI
+	AuthorizationServers []string       // Authorization servers for discovery
+	Issuers              []IssuerConfig // Trusted issuers, with their own JWKS, audiences and scope mapping
+	JWKSRefreshInterval  time.Duration  // How long fetched JWKS are used (default: 10m)
+	HTTPTimeout          time.Duration  // HTTP client timeout (default: 5s)
+	Client               *http.Client   // HTTP client fetching the discovery documents and JWKS (default: http.DefaultClient)
+}
+
+// IssuerConfig holds the validation configuration of the tokens of an issuer
+type IssuerConfig struct {
+	Issuer       string              // Issuer URL, matched against the iss claim
+	JWKSURI      string              // JWKS URI, discovered from the issuer URL if empty
+	Audiences    []string            // Accepted audiences, any audience if empty
+	ScopeClaim   string              // Claim holding the scopes (default: scope)
+	ScopeMapping map[string][]string // Scopes granted for each scope of the tokens
 }
 
 // TokenValidator handles OAuth 2.0 token validation
 type TokenValidator struct {
-	config TokenValidatorConfig
-	client *http.Client
+	config  TokenValidatorConfig
+	client  *http.Client
+	issuers []*issuer
+}
+
+// issuer is a trusted issuer, with the cached JWKS its tokens are verified with
+type issuer struct {
+	names  []string // values of the iss claim of the tokens of the issuer
+	config IssuerConfig
+	keys   *keySetCache
 }
 
 // NewTokenValidator creates a new token validator with the given configuration
 func NewTokenValidator(config TokenValidatorConfig) *TokenValidator {
-	return &TokenValidator{
+	tv := &TokenValidator{
 		config: config,
-		client: http.DefaultClient,
+		client: config.Client,
+	}
+	if tv.client == nil {
+		tv.client = http.DefaultClient
 	}
-}
 
-// ValidateToken validates a JWT token and returns extracted claims
-func (tv *TokenValidator) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
-	// Determine JWKS URI
-	jwksURI := tv.config.JWKSURI
+	// the authorization servers share the configured or discovered JWKS
+	if config.JWKSURI != "" || len(config.AuthorizationServers) > 0 {
+		tv.issuers = append(tv.issuers, tv.newIssuer(config.AuthorizationServers, IssuerConfig{JWKSURI: config.JWKSURI}))
+	}
+	for _, ic := range config.Issuers {
+		tv.issuers = append(tv.issuers, tv.newIssuer([]string{ic.Issuer}, ic))
+	}
 
-	// If JWKS URI is not configured, try to discover it from all authorization servers
-	if jwksURI == "" {
-		if len(tv.config.AuthorizationServers) == 0 {
-			return nil, fmt.Errorf("no JWKS URI configured and no authorization servers provided for discovery")
-		}
+	return tv
+}
 
-		var err error
-		jwksURI, err = tv.discoverJWKSURIFromAuthServers(ctx)
+func (tv *TokenValidator) newIssuer(names []string, config IssuerConfig) *issuer {
+	resolveURI := func(ctx context.Context) (string, error) {
+		if config.JWKSURI != "" {
+			return config.JWKSURI, nil
+		}
+		if len(names) == 0 {
+			return "", fmt.Errorf("no JWKS URI configured and no authorization servers provided for discovery")
+		}
+		jwksURI, err := tv.discoverJWKSURIFromAuthServers(ctx, names)
 		if err != nil {
-			return nil, fmt.Errorf("failed to discover JWKS URI: %w", err)
+			return "", fmt.Errorf("failed to discover JWKS URI: %w", err)
 		}
+		return jwksURI, nil
+	}
+	fetch := func(ctx context.Context, uri string) (jwk.Set, error) {
+		keySet, err := jwk.Fetch(ctx, uri, jwk.WithHTTPClient(tv.client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", uri, err)
+		}
+		return keySet, nil
+	}
+
+	return &issuer{
+		names:  names,
+		config: config,
+		keys:   newKeySetCache(resolveURI, fetch, tv.config.JWKSRefreshInterval),
+	}
+}
+
+// ValidateToken validates a JWT token and returns extracted claims
+func (tv *TokenValidator) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	// The issuer is read before the signature is verified, to select the JWKS to verify it with
+	unverified, err := jwt.ParseInsecure([]byte(tokenString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse/validate JWT token: %w", err)
+	}
+	iss, _ := unverified.Issuer()
+	issuer := tv.issuerOf(iss)
+	if issuer == nil {
+		return nil, fmt.Errorf("failed to validate claims: invalid token claims: %s is not a valid issuer", iss)
 	}
 
-	// Fetch JWKS
-	keySet, err := jwk.Fetch(ctx, jwksURI, jwk.WithHTTPClient(tv.client))
+	keySet, err := issuer.keys.get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", jwksURI, err)
+		return nil, err
 	}
 
 	// Parse and validate the token
 	token, err := jwt.Parse([]byte(tokenString), jwt.WithKeySet(keySet))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse/validate JWT token: %w", err)
+		// The token may be signed with a key that was rotated in since the JWKS was fetched
+		refreshed, ok, refreshErr := issuer.keys.refresh(ctx)
+		if refreshErr != nil || !ok {
+			return nil, fmt.Errorf("failed to parse/validate JWT token: %w", err)
+		}
+		if token, err = jwt.Parse([]byte(tokenString), jwt.WithKeySet(refreshed)); err != nil {
+			return nil, fmt.Errorf("failed to parse/validate JWT token: %w", err)
+		}
 	}
 
 	claims := tv.extractClaims(token)
+	issuer.applyScopes(token, claims)
 
-	if err := tv.validateClaims(claims); err != nil {
+	if err := issuer.validateClaims(claims); err != nil {
 		return nil, fmt.Errorf("failed to validate claims: %w", err)
 	}
 
 	return claims, nil
 }
 
-func (tv *TokenValidator) validateClaims(claims *TokenClaims) error {
-	if !slices.Contains(tv.config.AuthorizationServers, claims.Issuer) {
+// issuerOf returns the trusted issuer with the given name, or nil if it is not trusted
+func (tv *TokenValidator) issuerOf(name string) *issuer {
+	if name == "" {
+		return nil
+	}
+	for _, issuer := range tv.issuers {
+		if slices.Contains(issuer.names, name) {
+			return issuer
+		}
+	}
+	return nil
+}
+
+func (i *issuer) validateClaims(claims *TokenClaims) error {
+	if !slices.Contains(i.names, claims.Issuer) {
 		return fmt.Errorf("invalid token claims: %s is not a valid issuer", claims.Issuer)
 	}
 
-	// TODO: add more (e.g. audience?!?)
+	if len(i.config.Audiences) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(i.config.Audiences, aud)
+	}) {
+		return fmt.Errorf("invalid token claims: audience %v is not accepted from issuer %s", claims.Audience, claims.Issuer)
+	}
 
 	return nil
 }
 
+// applyScopes sets the scopes of claims from the scope claim of the issuer, mapped by its scope mapping
+func (i *issuer) applyScopes(token jwt.Token, claims *TokenClaims) {
+	if i.config.ScopeClaim != "" && i.config.ScopeClaim != "scope" {
+		var value any
+		claims.Scope = ""
+		if err := token.Get(i.config.ScopeClaim, &value); err == nil {
+			claims.Scope = createCanonicalScope(scopeString(value))
+		}
+	}
+
+	claims.Scope = mapScopes(claims.Scope, i.config.ScopeMapping)
+}
+
+// scopeString returns the scopes of a scope claim, which is either a space-separated string or an array
+func scopeString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		scopes := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+		return strings.Join(scopes, " ")
+	case []string:
+		return strings.Join(v, " ")
+	}
+	return ""
+}
+
+// mapScopes replaces the scopes of the space-separated scope with their mapped scopes, keeping the
+// scopes without a mapping
+func mapScopes(scope string, mapping map[string][]string) string {
+	if len(mapping) == 0 {
+		return scope
+	}
+
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if mapped, ok := mapping[s]; ok {
+			scopes = append(scopes, mapped...)
+		} else {
+			scopes = append(scopes, s)
+		}
+	}
+	return createCanonicalScope(strings.Join(scopes, " "))
+}
+
 // discoverJWKSURIFromAuthServers tries to discover JWKS URI from the authorization servers
-func (tv *TokenValidator) discoverJWKSURIFromAuthServers(ctx context.Context) (string, error) {
+func (tv *TokenValidator) discoverJWKSURIFromAuthServers(ctx context.Context, authServers []string) (string, error) {
 	var jwksURI string
 	var lastErr error
 
-	for _, authServer := range tv.config.AuthorizationServers {
+	for _, authServer := range authServers {
 		uri, err := tv.discoverJWKSURI(ctx, authServer)
 		if err != nil {
 			lastErr = err
@@ -162,6 +293,15 @@ func (tv *TokenValidator) extractClaims(token jwt.Token) *TokenClaims {
 		claims.Email = email
 	}
 
+	// All claims, so that invocation templates can reference custom claims
+	claims.Claims = make(map[string]any)
+	for _, key := range token.Keys() {
+		var value any
+		if err := token.Get(key, &value); err == nil {
+			claims.Claims[key] = value
+		}
+	}
+
 	return claims
 }
 
@@ -0,0 +1,161 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenValidatorIssuerOf(t *testing.T) {
+	tv := NewTokenValidator(TokenValidatorConfig{
+		AuthorizationServers: []string{"https://auth.example.com", "https://auth2.example.com"},
+		Issuers: []IssuerConfig{
+			{Issuer: "https://login.example.org", Audiences: []string{"genmcp"}},
+			{Issuer: "https://accounts.example.net", JWKSURI: "https://accounts.example.net/keys"},
+		},
+	})
+
+	tt := []struct {
+		name          string
+		iss           string
+		expectedNames []string
+	}{
+		{
+			name:          "authorization server",
+			iss:           "https://auth2.example.com",
+			expectedNames: []string{"https://auth.example.com", "https://auth2.example.com"},
+		},
+		{
+			name:          "issuer",
+			iss:           "https://accounts.example.net",
+			expectedNames: []string{"https://accounts.example.net"},
+		},
+		{
+			name: "unknown issuer",
+			iss:  "https://evil.example.com",
+		},
+		{
+			name: "missing issuer",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			issuer := tv.issuerOf(tc.iss)
+			if tc.expectedNames == nil {
+				assert.Nil(t, issuer)
+				return
+			}
+			if assert.NotNil(t, issuer) {
+				assert.Equal(t, tc.expectedNames, issuer.names)
+			}
+		})
+	}
+}
+
+func TestIssuerValidateClaims(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        IssuerConfig
+		claims        *TokenClaims
+		expectedError string
+	}{
+		{
+			name:   "any audience",
+			config: IssuerConfig{Issuer: "https://login.example.org"},
+			claims: &TokenClaims{Issuer: "https://login.example.org"},
+		},
+		{
+			name:   "accepted audience",
+			config: IssuerConfig{Issuer: "https://login.example.org", Audiences: []string{"genmcp", "api"}},
+			claims: &TokenClaims{Issuer: "https://login.example.org", Audience: []string{"other", "api"}},
+		},
+		{
+			name:          "audience of another service",
+			config:        IssuerConfig{Issuer: "https://login.example.org", Audiences: []string{"genmcp"}},
+			claims:        &TokenClaims{Issuer: "https://login.example.org", Audience: []string{"other"}},
+			expectedError: "audience [other] is not accepted from issuer https://login.example.org",
+		},
+		{
+			name:          "missing audience",
+			config:        IssuerConfig{Issuer: "https://login.example.org", Audiences: []string{"genmcp"}},
+			claims:        &TokenClaims{Issuer: "https://login.example.org"},
+			expectedError: "is not accepted from issuer",
+		},
+		{
+			name:          "other issuer",
+			config:        IssuerConfig{Issuer: "https://login.example.org"},
+			claims:        &TokenClaims{Issuer: "https://accounts.example.net"},
+			expectedError: "https://accounts.example.net is not a valid issuer",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tv := NewTokenValidator(TokenValidatorConfig{Issuers: []IssuerConfig{tc.config}})
+
+			err := tv.issuers[0].validateClaims(tc.claims)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestMapScopes(t *testing.T) {
+	tt := []struct {
+		name     string
+		scope    string
+		mapping  map[string][]string
+		expected string
+	}{
+		{
+			name:     "no mapping",
+			scope:    "read write",
+			expected: "read write",
+		},
+		{
+			name:     "mapped and unmapped scopes",
+			scope:    "admin profile",
+			mapping:  map[string][]string{"admin": {"tools:read", "tools:write"}},
+			expected: "profile tools:read tools:write",
+		},
+		{
+			name:     "scopes mapped to the same scope",
+			scope:    "Reader Writer",
+			mapping:  map[string][]string{"Reader": {"tools:read"}, "Writer": {"tools:read", "tools:write"}},
+			expected: "tools:read tools:write",
+		},
+		{
+			name:     "no scopes",
+			mapping:  map[string][]string{"admin": {"tools:write"}},
+			expected: "",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mapScopes(tc.scope, tc.mapping))
+		})
+	}
+}
+
+func TestScopeString(t *testing.T) {
+	tt := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "space-separated string", value: "read write", expected: "read write"},
+		{name: "array", value: []any{"read", "write", 1}, expected: "read write"},
+		{name: "other type", value: 42, expected: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, scopeString(tc.value))
+		})
+	}
+}
@@ -58,6 +58,26 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+const (
+	// EncodingJSON writes log entries as JSON objects, one per line
+	EncodingJSON = "json"
+	// EncodingConsole writes log entries as human-readable lines
+	EncodingConsole = "console"
+
+	// DefaultLevel is the minimum enabled logging level of the default configuration
+	DefaultLevel = "info"
+)
+
+// NewDefaultConfig returns the configuration used when none is provided: human-readable
+// logs of info level and above, written to stderr.
+func NewDefaultConfig() *LoggingConfig {
+	return &LoggingConfig{
+		Level:       DefaultLevel,
+		Development: true,
+		Encoding:    EncodingConsole,
+	}
+}
+
 // LoggingConfig provides a JSON-schema friendly configuration for logging
 // that can be converted to a zap.Config when needed.
 type LoggingConfig struct {
@@ -79,6 +99,57 @@ type LoggingConfig struct {
 	InitialFields map[string]interface{} `json:"initialFields,omitempty" jsonschema:"optional"`
 	// EnableMcpLogs controls whether logs are sent to MCP clients
 	EnableMcpLogs *bool `json:"enableMcpLogs,omitempty" jsonschema:"optional"`
+	// Redaction scrubs secrets from the logs of the server and from the logs sent to MCP clients.
+	// The default rules are applied if unset
+	Redaction *RedactionConfig `json:"redaction,omitempty" jsonschema:"optional"`
+	// Sampling caps the number of identical log entries written per second. If unset, json logs are
+	// sampled with an initial count and a thereafter rate of 100, and console logs are not sampled
+	Sampling *SamplingConfig `json:"sampling,omitempty" jsonschema:"optional"`
+}
+
+// SamplingConfig defines how repeated log entries are dropped to bound the cost of logging.
+// Each second, the first Initial entries with the same level and message are logged, then
+// every Thereafter-th entry.
+type SamplingConfig struct {
+	// Disabled turns off sampling, so that every entry is logged
+	Disabled bool `json:"disabled,omitempty" jsonschema:"optional"`
+	// Initial is the number of entries with the same level and message logged each second
+	Initial int `json:"initial,omitempty" jsonschema:"optional"`
+	// Thereafter is the rate of the entries logged after the initial ones, e.g. 100 logs one
+	// entry out of 100. If 0, no more entries are logged until the next second
+	Thereafter int `json:"thereafter,omitempty" jsonschema:"optional"`
+}
+
+// Validate checks the level, encoding, sampling and redaction rules of the configuration
+func (lc *LoggingConfig) Validate() error {
+	if lc.Level != "" {
+		if _, err := zapcore.ParseLevel(lc.Level); err != nil {
+			return fmt.Errorf("invalid level: %w", err)
+		}
+	}
+
+	switch lc.Encoding {
+	case "", EncodingJSON, EncodingConsole:
+	default:
+		return fmt.Errorf("invalid encoding '%s': must be one of %s, %s", lc.Encoding, EncodingJSON, EncodingConsole)
+	}
+
+	if lc.Sampling != nil && !lc.Sampling.Disabled {
+		if lc.Sampling.Initial <= 0 {
+			return fmt.Errorf("invalid sampling: initial must be greater than 0")
+		}
+		if lc.Sampling.Thereafter < 0 {
+			return fmt.Errorf("invalid sampling: thereafter must not be negative")
+		}
+	}
+
+	if lc.Redaction != nil {
+		if err := lc.Redaction.Validate(); err != nil {
+			return fmt.Errorf("invalid redaction: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // MCPLogsEnabled returns whether the mcp logs are enabled, defaulting to true if unset
@@ -96,7 +167,7 @@ func (lc *LoggingConfig) toZapConfig() (zap.Config, error) {
 
 	// Set defaults if not specified
 	switch lc.Encoding {
-	case "console":
+	case EncodingConsole:
 		config = zap.NewDevelopmentConfig()
 	default:
 		config = zap.NewProductionConfig()
@@ -131,10 +202,22 @@ func (lc *LoggingConfig) toZapConfig() (zap.Config, error) {
 		config.InitialFields = lc.InitialFields
 	}
 
+	if lc.Sampling != nil {
+		if lc.Sampling.Disabled {
+			config.Sampling = nil
+		} else {
+			config.Sampling = &zap.SamplingConfig{
+				Initial:    lc.Sampling.Initial,
+				Thereafter: lc.Sampling.Thereafter,
+			}
+		}
+	}
+
 	return config, nil
 }
 
-// BuildBase creates a base logger from the configuration.
+// BuildBase creates a base logger from the configuration, redacting secrets according to
+// the Redaction rules.
 // This should be called once at application startup and the resulting logger
 // should be reused throughout the application's lifetime.
 //
@@ -150,5 +233,11 @@ func (lc *LoggingConfig) BuildBase() (*zap.Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to build base zap logger: %w", err)
 	}
-	return logger, nil
+
+	redactor, err := NewRedactor(lc.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build log redaction: %w", err)
+	}
+
+	return WithRedaction(logger, redactor), nil
 }
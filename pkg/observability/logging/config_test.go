@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggingConfigValidate(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        *LoggingConfig
+		expectedError string
+	}{
+		{
+			name:   "default config",
+			config: NewDefaultConfig(),
+		},
+		{
+			name: "json logs with sampling",
+			config: &LoggingConfig{
+				Level:    "debug",
+				Encoding: EncodingJSON,
+				Sampling: &SamplingConfig{Initial: 10, Thereafter: 1000},
+			},
+		},
+		{
+			name:   "disabled sampling",
+			config: &LoggingConfig{Sampling: &SamplingConfig{Disabled: true}},
+		},
+		{
+			name:          "unknown level",
+			config:        &LoggingConfig{Level: "verbose"},
+			expectedError: "invalid level",
+		},
+		{
+			name:          "unknown encoding",
+			config:        &LoggingConfig{Encoding: "logfmt"},
+			expectedError: "invalid encoding 'logfmt'",
+		},
+		{
+			name:          "sampling without initial count",
+			config:        &LoggingConfig{Sampling: &SamplingConfig{Thereafter: 100}},
+			expectedError: "invalid sampling: initial must be greater than 0",
+		},
+		{
+			name:          "negative sampling rate",
+			config:        &LoggingConfig{Sampling: &SamplingConfig{Initial: 100, Thereafter: -1}},
+			expectedError: "invalid sampling: thereafter must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestLoggingConfigToZapConfig(t *testing.T) {
+	tt := []struct {
+		name   string
+		config *LoggingConfig
+		check  func(t *testing.T, config zap.Config)
+	}{
+		{
+			name:   "default config writes info console logs to stderr",
+			config: NewDefaultConfig(),
+			check: func(t *testing.T, config zap.Config) {
+				assert.Equal(t, zapcore.InfoLevel, config.Level.Level())
+				assert.Equal(t, EncodingConsole, config.Encoding)
+				assert.Equal(t, []string{"stderr"}, config.OutputPaths)
+				assert.Nil(t, config.Sampling)
+			},
+		},
+		{
+			name:   "json logs are sampled by default",
+			config: &LoggingConfig{Level: "debug"},
+			check: func(t *testing.T, config zap.Config) {
+				assert.Equal(t, zapcore.DebugLevel, config.Level.Level())
+				assert.Equal(t, EncodingJSON, config.Encoding)
+				assert.Equal(t, &zap.SamplingConfig{Initial: 100, Thereafter: 100}, config.Sampling)
+			},
+		},
+		{
+			name: "output paths and sampling",
+			config: &LoggingConfig{
+				Encoding:    EncodingConsole,
+				OutputPaths: []string{"/var/log/genmcp.log"},
+				Sampling:    &SamplingConfig{Initial: 10, Thereafter: 50},
+			},
+			check: func(t *testing.T, config zap.Config) {
+				assert.Equal(t, []string{"/var/log/genmcp.log"}, config.OutputPaths)
+				assert.Equal(t, &zap.SamplingConfig{Initial: 10, Thereafter: 50}, config.Sampling)
+			},
+		},
+		{
+			name:   "disabled sampling",
+			config: &LoggingConfig{Encoding: EncodingJSON, Sampling: &SamplingConfig{Disabled: true}},
+			check: func(t *testing.T, config zap.Config) {
+				assert.Nil(t, config.Sampling)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := tc.config.toZapConfig()
+			require.NoError(t, err)
+
+			tc.check(t, config)
+		})
+	}
+}
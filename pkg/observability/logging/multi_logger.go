@@ -9,7 +9,8 @@ import (
 )
 
 // NewRequestLogger creates a request-scoped logger that sends logs to both
-// the base logger and the MCP client via ServerSession.
+// the base logger and the MCP client via ServerSession. If the base logger
+// redacts secrets (see WithRedaction), so do the logs sent to the client.
 //
 // Performance: This function is designed to be called per-request. It reuses
 // the pre-built baseLogger (created once at startup) and only creates the
@@ -26,6 +27,10 @@ func NewRequestLogger(ctx context.Context, baseLogger *zap.Logger, ss *mcp.Serve
 
 	return baseLogger.WithOptions(
 		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			// the logs sent to the client are redacted like those of the base logger
+			if rc, ok := core.(*redactingCore); ok {
+				return rc.tee(mcpCore)
+			}
 			return zapcore.NewTee(core, mcpCore)
 		}),
 	), nil
@@ -0,0 +1,110 @@
+// Package tracing provides OpenTelemetry tracing for MCP requests and the
+// backend calls made to serve them.
+//
+// Tracing is disabled unless the server runtime has a TracingConfig. When it
+// is enabled, spans are exported over OTLP/HTTP and the W3C trace context is
+// propagated on outgoing HTTP requests as traceparent headers:
+//
+//	cfg := &tracing.TracingConfig{Endpoint: "http://localhost:4318"}
+//	shutdown, err := cfg.Setup(ctx, "my-server", "1.0.0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer shutdown(context.Background())
+//
+// Instrumented code uses Start to create spans. Without a TracingConfig the
+// global OpenTelemetry tracer provider is a no-op, so spans cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracingConfig provides a JSON-schema friendly configuration for exporting traces.
+type TracingConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP collector (e.g. "http://localhost:4318").
+	// Defaults to the standard OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or http://localhost:4318.
+	Endpoint string `json:"endpoint,omitempty" jsonschema:"optional"`
+	// Headers are sent with every export request, e.g. for authenticating to the collector
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"optional"`
+	// ServiceName identifies the server in traces. Defaults to the server name
+	ServiceName string `json:"serviceName,omitempty" jsonschema:"optional"`
+	// SampleRatio is the fraction of new traces that are sampled, between 0 and 1. Defaults to 1.
+	// Requests that are part of a sampled trace are always sampled.
+	SampleRatio *float64 `json:"sampleRatio,omitempty" jsonschema:"optional"`
+}
+
+func (tc *TracingConfig) Validate() error {
+	if tc.Endpoint != "" {
+		u, err := url.Parse(tc.Endpoint)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("endpoint must be an http or https URL, received '%s'", tc.Endpoint)
+		}
+	}
+
+	if tc.SampleRatio != nil && (*tc.SampleRatio < 0 || *tc.SampleRatio > 1) {
+		return fmt.Errorf("sampleRatio must be between 0 and 1, received %v", *tc.SampleRatio)
+	}
+
+	return nil
+}
+
+// Setup installs a global tracer provider exporting to the configured collector and the
+// W3C trace context propagator. The returned function flushes pending spans and must be
+// called when the server shuts down.
+func (tc *TracingConfig) Setup(ctx context.Context, serverName, serverVersion string) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if tc.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(tc.Endpoint))
+	}
+	if len(tc.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(tc.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	serviceName := tc.ServiceName
+	if serviceName == "" {
+		serviceName = serverName
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", serverVersion),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	sampleRatio := 1.0
+	if tc.SampleRatio != nil {
+		sampleRatio = *tc.SampleRatio
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp.Shutdown, nil
+}
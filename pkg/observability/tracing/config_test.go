@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracingConfigValidate(t *testing.T) {
+	ratio := func(r float64) *float64 { return &r }
+
+	tt := []struct {
+		name        string
+		config      *TracingConfig
+		expectError bool
+	}{
+		{
+			name:   "empty config uses defaults",
+			config: &TracingConfig{},
+		},
+		{
+			name: "full config",
+			config: &TracingConfig{
+				Endpoint:    "https://collector.example.com:4318",
+				Headers:     map[string]string{"Authorization": "Bearer token"},
+				ServiceName: "weather",
+				SampleRatio: ratio(0.25),
+			},
+		},
+		{
+			name:   "sample ratio of zero",
+			config: &TracingConfig{SampleRatio: ratio(0)},
+		},
+		{
+			name:        "endpoint without scheme",
+			config:      &TracingConfig{Endpoint: "localhost:4318"},
+			expectError: true,
+		},
+		{
+			name:        "endpoint with unsupported scheme",
+			config:      &TracingConfig{Endpoint: "grpc://localhost:4317"},
+			expectError: true,
+		},
+		{
+			name:        "negative sample ratio",
+			config:      &TracingConfig{SampleRatio: ratio(-0.5)},
+			expectError: true,
+		},
+		{
+			name:        "sample ratio above one",
+			config:      &TracingConfig{SampleRatio: ratio(1.5)},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.config.Validate()
+			if tc.expectError {
+				assert.Error(t, err, "validation should fail")
+			} else {
+				assert.NoError(t, err, "validation should not fail")
+			}
+		})
+	}
+}
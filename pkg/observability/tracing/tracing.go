@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used for all spans created by gen-mcp
+const instrumentationName = "github.com/genmcp/gen-mcp"
+
+// Start creates a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records err on span, if it is not nil, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetError marks span as failed with the given description, for failures that are not Go errors
+// (e.g. an MCP result with isError set).
+func SetError(span trace.Span, description string) {
+	span.SetStatus(codes.Error, description)
+}
+
+// Extract returns a context with the remote span context propagated in the given request headers.
+func Extract(ctx context.Context, headers http.Header) context.Context {
+	if headers == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// Inject adds the span context of ctx to the given outgoing request headers, as traceparent and
+// tracestate headers.
+func Inject(ctx context.Context, headers http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+}
@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// CELEngine evaluates a policy written as a CEL expression, with the variables subject (a map of the claims
+// of the caller, empty if the call is not authenticated), tool (the name of the tool) and arguments (a map of
+// the arguments of the call). The expression returns a boolean, or a map with the fields of an OPA result.
+type CELEngine struct {
+	program cel.Program
+}
+
+var _ Engine = &CELEngine{}
+
+// NewCELEngine compiles expression, returning an error if it is not a valid CEL expression.
+func NewCELEngine(expression string) (*CELEngine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("subject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("tool", cel.StringType),
+		cel.Variable("arguments", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", issues.Err())
+	}
+
+	switch ast.OutputType().Kind() {
+	case cel.BoolType.Kind(), cel.DynType.Kind(), cel.MapType(cel.StringType, cel.DynType).Kind():
+	default:
+		return nil, fmt.Errorf("CEL expression must return a boolean or a map, got %s", ast.OutputType())
+	}
+
+	// the interrupt check stops evaluations whose context is cancelled
+	program, err := env.Program(ast, cel.InterruptCheckFrequency(100))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", err)
+	}
+
+	return &CELEngine{program: program}, nil
+}
+
+func (e *CELEngine) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	subject := map[string]any{}
+	if input.Subject != nil {
+		data, err := json.Marshal(input.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal subject: %w", err)
+		}
+		if err := json.Unmarshal(data, &subject); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal subject: %w", err)
+		}
+	}
+
+	arguments := input.Arguments
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+
+	out, _, err := e.program.ContextEval(ctx, map[string]any{
+		"subject":   subject,
+		"tool":      input.Tool,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate CEL expression: %w", err)
+	}
+
+	// the result is converted to JSON values, so that maps and numbers match the results of OPA
+	value, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the result of the CEL expression: %w", err)
+	}
+
+	return decisionOf(value.(*structpb.Value).AsInterface())
+}
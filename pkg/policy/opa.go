@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultOPATimeout is how long the evaluation of a policy by OPA can take if OPAEngine has no timeout.
+const DefaultOPATimeout = 5 * time.Second
+
+// OPAEngine evaluates a policy with the Data API of an Open Policy Agent server. The input is sent as the
+// input document of the query, and the result of the query is either a boolean, or an object with an allow
+// boolean, and optionally the reason of a denial and the arguments of the call. A call is denied if the result
+// is undefined.
+type OPAEngine struct {
+	// URL of the decision of the policy, e.g. http://localhost:8181/v1/data/genmcp/authz
+	URL string
+
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+var _ Engine = &OPAEngine{}
+
+func (e *OPAEngine) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultOPATimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy input: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OPA request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OPA: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OPA returned status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var response struct {
+		Result any `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode OPA response: %w", err)
+	}
+
+	if response.Result == nil {
+		return &Decision{Allow: false, Reason: "no policy decision"}, nil
+	}
+	return decisionOf(response.Result)
+}
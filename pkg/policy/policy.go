@@ -0,0 +1,74 @@
+// Package policy evaluates the authorization policy of a server before its tools are invoked. A policy receives
+// the claims of the caller, the name of the tool and the arguments of the call, and either denies the call or
+// allows it, possibly with other arguments. Policies are CEL expressions evaluated by the server, or are
+// evaluated by an external Open Policy Agent (OPA) server.
+package policy
+
+import (
+	"context"
+	"fmt"
+)
+
+// Input is what a policy is evaluated with.
+type Input struct {
+	Subject   *Subject       `json:"subject,omitempty"` // claims of the caller, nil if the call is not authenticated
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// Subject holds the claims of the credentials of the caller.
+type Subject struct {
+	Subject  string   `json:"sub,omitempty"`
+	Issuer   string   `json:"iss,omitempty"`
+	Audience []string `json:"aud,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	ClientID string   `json:"clientId,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+}
+
+// Decision is the result of the evaluation of a policy.
+type Decision struct {
+	Allow bool
+
+	// Why the call is denied, returned to the client.
+	Reason string
+
+	// Arguments the tool is called with instead of the arguments of the call, nil to keep them.
+	Arguments map[string]any
+}
+
+// Engine evaluates a policy.
+type Engine interface {
+	Evaluate(ctx context.Context, input *Input) (*Decision, error)
+}
+
+// decisionOf returns the decision of the result of a policy: either a boolean allowing or denying the call, or
+// an object with an allow boolean, and optionally the reason of a denial and the arguments of the call.
+func decisionOf(result any) (*Decision, error) {
+	switch r := result.(type) {
+	case bool:
+		return &Decision{Allow: r}, nil
+	case map[string]any:
+		allow, ok := r["allow"].(bool)
+		if !ok {
+			return nil, fmt.Errorf("policy result must have an allow boolean")
+		}
+		decision := &Decision{Allow: allow}
+
+		if reason, ok := r["reason"]; ok && reason != nil {
+			if decision.Reason, ok = reason.(string); !ok {
+				return nil, fmt.Errorf("reason of the policy result must be a string, got %T", reason)
+			}
+		}
+		if arguments, ok := r["arguments"]; ok && arguments != nil {
+			if decision.Arguments, ok = arguments.(map[string]any); !ok {
+				return nil, fmt.Errorf("arguments of the policy result must be an object, got %T", arguments)
+			}
+		}
+
+		return decision, nil
+	default:
+		return nil, fmt.Errorf("policy result must be a boolean or an object, got %T", result)
+	}
+}
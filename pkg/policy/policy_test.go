@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCELEngine(t *testing.T) {
+	tt := []struct {
+		name          string
+		expression    string
+		input         *Input
+		expected      *Decision
+		expectedError string
+	}{
+		{
+			name:       "allow",
+			expression: `tool != "delete_namespace"`,
+			input:      &Input{Tool: "list_namespaces"},
+			expected:   &Decision{Allow: true},
+		},
+		{
+			name:       "deny with reason",
+			expression: `tool == "delete_namespace" && arguments.namespace.startsWith("prod-") ? {"allow": false, "reason": "cannot delete production namespaces"} : {"allow": true}`,
+			input:      &Input{Tool: "delete_namespace", Arguments: map[string]any{"namespace": "prod-eu"}},
+			expected:   &Decision{Allow: false, Reason: "cannot delete production namespaces"},
+		},
+		{
+			name:       "claims of the subject",
+			expression: `"admin" in subject.scopes || (has(arguments.owner) && arguments.owner == subject.sub)`,
+			input:      &Input{Subject: &Subject{Subject: "alice", Scopes: []string{"read"}}, Tool: "delete_repo", Arguments: map[string]any{"owner": "alice"}},
+			expected:   &Decision{Allow: true},
+		},
+		{
+			name:       "unauthenticated call",
+			expression: `has(subject.sub)`,
+			input:      &Input{Tool: "delete_repo"},
+			expected:   &Decision{Allow: false},
+		},
+		{
+			name:       "mutated arguments",
+			expression: `{"allow": true, "arguments": {"namespace": arguments.namespace, "limit": arguments.limit > 100 ? 100 : arguments.limit}}`,
+			input:      &Input{Tool: "list_pods", Arguments: map[string]any{"namespace": "dev", "limit": float64(500)}},
+			expected:   &Decision{Allow: true, Arguments: map[string]any{"namespace": "dev", "limit": float64(100)}},
+		},
+		{
+			name:          "missing allow",
+			expression:    `{"reason": "no"}`,
+			input:         &Input{Tool: "list_pods"},
+			expectedError: "policy result must have an allow boolean",
+		},
+		{
+			name:          "missing argument",
+			expression:    `arguments.namespace == "dev"`,
+			input:         &Input{Tool: "list_pods"},
+			expectedError: "failed to evaluate CEL expression",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			engine, err := NewCELEngine(tc.expression)
+			require.NoError(t, err)
+
+			decision, err := engine.Evaluate(context.Background(), tc.input)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decision)
+		})
+	}
+}
+
+func TestNewCELEngineErrors(t *testing.T) {
+	tt := []struct {
+		name          string
+		expression    string
+		expectedError string
+	}{
+		{
+			name:          "syntax error",
+			expression:    `tool ==`,
+			expectedError: "invalid CEL expression",
+		},
+		{
+			name:          "unknown variable",
+			expression:    `user == "alice"`,
+			expectedError: "undeclared reference to 'user'",
+		},
+		{
+			name:          "string result",
+			expression:    `tool + "!"`,
+			expectedError: "CEL expression must return a boolean or a map, got string",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCELEngine(tc.expression)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestOPAEngine(t *testing.T) {
+	tt := []struct {
+		name          string
+		status        int
+		response      string
+		expected      *Decision
+		expectedError string
+	}{
+		{
+			name:     "boolean result",
+			status:   http.StatusOK,
+			response: `{"result": true}`,
+			expected: &Decision{Allow: true},
+		},
+		{
+			name:     "object result",
+			status:   http.StatusOK,
+			response: `{"result": {"allow": true, "arguments": {"namespace": "dev"}}}`,
+			expected: &Decision{Allow: true, Arguments: map[string]any{"namespace": "dev"}},
+		},
+		{
+			name:     "undefined result",
+			status:   http.StatusOK,
+			response: `{}`,
+			expected: &Decision{Allow: false, Reason: "no policy decision"},
+		},
+		{
+			name:          "invalid result",
+			status:        http.StatusOK,
+			response:      `{"result": "allow"}`,
+			expectedError: "policy result must be a boolean or an object, got string",
+		},
+		{
+			name:          "error status",
+			status:        http.StatusInternalServerError,
+			response:      `{"code": "internal_error"}`,
+			expectedError: `OPA returned status 500: {"code": "internal_error"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var received map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			engine := &OPAEngine{URL: server.URL + "/v1/data/genmcp/authz", Client: server.Client()}
+			decision, err := engine.Evaluate(context.Background(), &Input{
+				Subject:   &Subject{Subject: "alice"},
+				Tool:      "list_pods",
+				Arguments: map[string]any{"namespace": "prod"},
+			})
+
+			assert.Equal(t, map[string]any{
+				"input": map[string]any{
+					"subject":   map[string]any{"sub": "alice"},
+					"tool":      "list_pods",
+					"arguments": map[string]any{"namespace": "prod"},
+				},
+			}, received)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decision)
+		})
+	}
+}
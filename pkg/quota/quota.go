@@ -0,0 +1,235 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned for calls over a quota of their client.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Limits are the maximum numbers of calls in an hour and in a day, unlimited if 0. Hours and days
+// start on the hour and at midnight UTC.
+type Limits struct {
+	PerHour int
+	PerDay  int
+}
+
+// Config defines the quotas of the clients of a server.
+type Config struct {
+	// Limits of the calls of each client to all tools.
+	Default Limits
+
+	// Limits of the calls of specific clients to all tools, replacing Default.
+	Clients map[string]Limits
+
+	// Limits of the calls of each client to a tool, in addition to the limits of the client.
+	Tools map[string]Limits
+}
+
+// Usage is the number of calls of a client, to all tools or to a tool.
+type Usage struct {
+	// Calls made since the server started, including rejected calls.
+	Calls int64 `json:"calls"`
+	// Calls rejected by a quota since the server started.
+	Rejected int64 `json:"rejected"`
+	// Calls made in the current hour and day, counted against the quotas.
+	CallsThisHour int `json:"callsThisHour"`
+	CallsToday    int `json:"callsToday"`
+	// Quotas of the calls, omitted if unlimited.
+	QuotaPerHour int `json:"quotaPerHour,omitempty"`
+	QuotaPerDay  int `json:"quotaPerDay,omitempty"`
+}
+
+// ClientUsage is the usage of the tools of a server by a client.
+type ClientUsage struct {
+	Client   string           `json:"client"`
+	LastCall time.Time        `json:"lastCall"`
+	Total    Usage            `json:"total"`
+	Tools    map[string]Usage `json:"tools"`
+}
+
+// Tracker counts the calls of each client, and rejects the calls over the quotas of the client.
+type Tracker struct {
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*clientCounters
+}
+
+type clientCounters struct {
+	lastCall time.Time
+	total    *counter
+	tools    map[string]*counter
+}
+
+// counter counts calls in the current hour and day.
+type counter struct {
+	calls    int64
+	rejected int64
+	hour     time.Time
+	hourly   int
+	day      time.Time
+	daily    int
+}
+
+// NewTracker creates a tracker enforcing the quotas of config.
+func NewTracker(config Config) *Tracker {
+	return &Tracker{
+		config:  config,
+		now:     time.Now,
+		clients: make(map[string]*clientCounters),
+	}
+}
+
+// SetConfig replaces the quotas enforced by the tracker, e.g. when the server config is reloaded. The calls
+// already counted count against the new quotas.
+func (t *Tracker) SetConfig(config Config) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config = config
+}
+
+// Record counts a call of tool by client. It returns an error wrapping ErrQuotaExceeded, and counts the
+// call as rejected, if the call exceeds the quota of the client or of the tool. A nil tracker accepts
+// every call.
+func (t *Tracker) Record(client, tool string) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	c, ok := t.clients[client]
+	if !ok {
+		c = &clientCounters{total: &counter{}, tools: make(map[string]*counter)}
+		t.clients[client] = c
+	}
+	toolCounter, ok := c.tools[tool]
+	if !ok {
+		toolCounter = &counter{}
+		c.tools[tool] = toolCounter
+	}
+
+	c.lastCall = now
+	c.total.advance(now)
+	toolCounter.advance(now)
+	c.total.calls++
+	toolCounter.calls++
+
+	err := c.total.check(t.clientLimits(client), "")
+	if err == nil {
+		err = toolCounter.check(t.config.Tools[tool], " of tool "+tool)
+	}
+	if err != nil {
+		c.total.rejected++
+		toolCounter.rejected++
+		return err
+	}
+
+	c.total.hourly++
+	c.total.daily++
+	toolCounter.hourly++
+	toolCounter.daily++
+	return nil
+}
+
+// Usage returns the usage of every client that made a call, sorted by client.
+func (t *Tracker) Usage() []ClientUsage {
+	if t == nil {
+		return []ClientUsage{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := make([]ClientUsage, 0, len(t.clients))
+	for _, client := range slices.Sorted(maps.Keys(t.clients)) {
+		usage = append(usage, t.clientUsage(client))
+	}
+	return usage
+}
+
+// ClientUsage returns the usage of client, and false if it made no call.
+func (t *Tracker) ClientUsage(client string) (ClientUsage, bool) {
+	if t == nil {
+		return ClientUsage{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.clients[client]; !ok {
+		return ClientUsage{}, false
+	}
+	return t.clientUsage(client), true
+}
+
+func (t *Tracker) clientUsage(client string) ClientUsage {
+	now := t.now().UTC()
+	c := t.clients[client]
+
+	usage := ClientUsage{
+		Client:   client,
+		LastCall: c.lastCall,
+		Total:    c.total.usage(now, t.clientLimits(client)),
+		Tools:    make(map[string]Usage, len(c.tools)),
+	}
+	for tool, toolCounter := range c.tools {
+		usage.Tools[tool] = toolCounter.usage(now, t.config.Tools[tool])
+	}
+	return usage
+}
+
+func (t *Tracker) clientLimits(client string) Limits {
+	if limits, ok := t.config.Clients[client]; ok {
+		return limits
+	}
+	return t.config.Default
+}
+
+// advance resets the counts of the hour and day if they are over at now.
+func (c *counter) advance(now time.Time) {
+	if hour := now.Truncate(time.Hour); !hour.Equal(c.hour) {
+		c.hour = hour
+		c.hourly = 0
+	}
+	if day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); !day.Equal(c.day) {
+		c.day = day
+		c.daily = 0
+	}
+}
+
+// check returns an error if one more call exceeds limits, whose scope is appended to the error.
+func (c *counter) check(limits Limits, scope string) error {
+	if limits.PerHour > 0 && c.hourly >= limits.PerHour {
+		return fmt.Errorf("%w: %d calls per hour%s", ErrQuotaExceeded, limits.PerHour, scope)
+	}
+	if limits.PerDay > 0 && c.daily >= limits.PerDay {
+		return fmt.Errorf("%w: %d calls per day%s", ErrQuotaExceeded, limits.PerDay, scope)
+	}
+	return nil
+}
+
+func (c *counter) usage(now time.Time, limits Limits) Usage {
+	c.advance(now)
+	return Usage{
+		Calls:         c.calls,
+		Rejected:      c.rejected,
+		CallsThisHour: c.hourly,
+		CallsToday:    c.daily,
+		QuotaPerHour:  limits.PerHour,
+		QuotaPerDay:   limits.PerDay,
+	}
+}
@@ -0,0 +1,153 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerRecord(t *testing.T) {
+	config := Config{
+		Default: Limits{PerHour: 3, PerDay: 5},
+		Clients: map[string]Limits{"batch-agent": {PerDay: 2}},
+		Tools:   map[string]Limits{"send_email": {PerHour: 1}},
+	}
+
+	tt := []struct {
+		name        string
+		client      string
+		callsBy     string // client of the previous calls, client if empty
+		calls       []string
+		elapsed     time.Duration
+		tool        string
+		expectedErr string
+	}{
+		{
+			name:   "within the quotas",
+			client: "alice",
+			calls:  []string{"search", "search"},
+			tool:   "search",
+		},
+		{
+			name:        "hourly quota of the client",
+			client:      "alice",
+			calls:       []string{"search", "search", "get_user"},
+			tool:        "search",
+			expectedErr: "quota exceeded: 3 calls per hour",
+		},
+		{
+			name:    "hourly quota after the hour",
+			client:  "alice",
+			calls:   []string{"search", "search", "get_user"},
+			elapsed: time.Hour,
+			tool:    "search",
+		},
+		{
+			name:        "daily quota of the client",
+			client:      "alice",
+			calls:       []string{"search", "search", "search", "search", "search"},
+			elapsed:     2 * time.Hour,
+			tool:        "search",
+			expectedErr: "quota exceeded: 5 calls per day",
+		},
+		{
+			name:        "quota of a specific client",
+			client:      "batch-agent",
+			calls:       []string{"search", "search"},
+			tool:        "search",
+			expectedErr: "quota exceeded: 2 calls per day",
+		},
+		{
+			name:        "quota of a tool",
+			client:      "alice",
+			calls:       []string{"send_email"},
+			tool:        "send_email",
+			expectedErr: "quota exceeded: 1 calls per hour of tool send_email",
+		},
+		{
+			name:    "quotas of another client",
+			client:  "bob",
+			callsBy: "alice",
+			calls:   []string{"search", "search", "search"},
+			tool:    "search",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+			tracker := NewTracker(config)
+			tracker.now = func() time.Time { return now }
+
+			for i, tool := range tc.calls {
+				// the previous calls are spread over elapsed
+				now = now.Add(tc.elapsed / time.Duration(len(tc.calls)))
+				client := tc.client
+				if tc.callsBy != "" {
+					client = tc.callsBy
+				}
+				require.NoError(t, tracker.Record(client, tool), "call %d", i)
+			}
+
+			err := tracker.Record(tc.client, tc.tool)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrQuotaExceeded)
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTrackerUsage(t *testing.T) {
+	now := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+	tracker := NewTracker(Config{
+		Default: Limits{PerHour: 2},
+		Tools:   map[string]Limits{"search": {PerDay: 10}},
+	})
+	tracker.now = func() time.Time { return now }
+
+	require.NoError(t, tracker.Record("bob", "search"))
+	require.NoError(t, tracker.Record("alice", "search"))
+	require.NoError(t, tracker.Record("alice", "get_user"))
+	require.ErrorIs(t, tracker.Record("alice", "search"), ErrQuotaExceeded)
+
+	now = now.Add(time.Hour)
+
+	usage := tracker.Usage()
+	require.Len(t, usage, 2)
+	assert.Equal(t, "alice", usage[0].Client)
+	assert.Equal(t, "bob", usage[1].Client)
+
+	alice, ok := tracker.ClientUsage("alice")
+	require.True(t, ok)
+	assert.Equal(t, ClientUsage{
+		Client:   "alice",
+		LastCall: time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC),
+		Total:    Usage{Calls: 3, Rejected: 1, CallsThisHour: 0, CallsToday: 2, QuotaPerHour: 2},
+		Tools: map[string]Usage{
+			"search":   {Calls: 2, Rejected: 1, CallsThisHour: 0, CallsToday: 1, QuotaPerDay: 10},
+			"get_user": {Calls: 1, CallsThisHour: 0, CallsToday: 1},
+		},
+	}, alice)
+
+	_, ok = tracker.ClientUsage("carol")
+	assert.False(t, ok)
+}
+
+func TestTrackerSetConfig(t *testing.T) {
+	tracker := NewTracker(Config{Default: Limits{PerHour: 2}})
+	require.NoError(t, tracker.Record("alice", "search"))
+
+	tracker.SetConfig(Config{Default: Limits{PerHour: 1}})
+	assert.ErrorIs(t, tracker.Record("alice", "search"), ErrQuotaExceeded, "calls counted before should count against the new quotas")
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	assert.NoError(t, tracker.Record("alice", "search"))
+	assert.Empty(t, tracker.Usage())
+}
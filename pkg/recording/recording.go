@@ -0,0 +1,199 @@
+// Package recording records the calls of tools as fixtures in a local directory, and replays the recorded
+// results in place of the tools, for offline development and deterministic tests. Secrets are redacted
+// from the arguments and results of the calls before they are written.
+package recording
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// Modes of a Store.
+const (
+	ModeRecord = "record" // tools are invoked and their results are recorded
+	ModeReplay = "replay" // the recorded results are returned instead of invoking the tools
+)
+
+// ErrNotRecorded is returned when replaying a call that was not recorded.
+var ErrNotRecorded = errors.New("no recorded result")
+
+// Fixture is a recorded tool call.
+type Fixture struct {
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments"`
+	Result     json.RawMessage `json:"result"`
+	RecordedAt time.Time       `json:"recordedAt"`
+}
+
+// Store records tool calls as fixtures in a directory, or replays them. Fixtures are stored in a
+// subdirectory per tool, in a file per distinct arguments. A nil Store neither records nor replays.
+type Store struct {
+	dir      string
+	mode     string
+	redactor *logging.Redactor
+}
+
+// NewStore creates a Store of the fixtures of dir in mode, redacting secrets with redactor. A nil redactor
+// applies the default redaction rules. Replayed calls must be redacted with the rules they were recorded with,
+// as fixtures are matched on the redacted arguments of calls.
+func NewStore(dir, mode string, redactor *logging.Redactor) (*Store, error) {
+	switch mode {
+	case ModeRecord, ModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown recording mode %s", mode)
+	}
+
+	if mode == ModeReplay {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open fixtures directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("failed to open fixtures directory: '%s' is not a directory", dir)
+		}
+	}
+
+	if redactor == nil {
+		var err error
+		if redactor, err = logging.NewRedactor(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Store{dir: dir, mode: mode, redactor: redactor}, nil
+}
+
+// Recording returns whether the results of tool calls are recorded.
+func (s *Store) Recording() bool {
+	return s != nil && s.mode == ModeRecord
+}
+
+// Replaying returns whether recorded results are returned instead of invoking tools.
+func (s *Store) Replaying() bool {
+	return s != nil && s.mode == ModeReplay
+}
+
+// Record writes the fixture of the call of tool with arguments that returned result, replacing the
+// fixture of a previous call with the same arguments.
+func (s *Store) Record(tool string, arguments json.RawMessage, result *mcp.CallToolResult) error {
+	redacted, path, err := s.fixturePath(tool, arguments)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	encoded, err = s.redactor.RedactJSON(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to redact result: %w", err)
+	}
+
+	data, err := json.MarshalIndent(Fixture{
+		Tool:       tool,
+		Arguments:  redacted,
+		Result:     encoded,
+		RecordedAt: time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+// Replay returns the recorded result of the call of tool with arguments, or ErrNotRecorded if the call
+// was not recorded.
+func (s *Store) Replay(tool string, arguments json.RawMessage) (*mcp.CallToolResult, error) {
+	_, path, err := s.fixturePath(tool, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotRecorded
+		}
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	result := &mcp.CallToolResult{}
+	if err := json.Unmarshal(fixture.Result, result); err != nil {
+		return nil, fmt.Errorf("invalid result of fixture %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// Fixtures returns the fixtures recorded for tool, from the oldest to the most recent.
+func (s *Store) Fixtures(tool string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, url.PathEscape(tool), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	sort.SliceStable(fixtures, func(i, j int) bool {
+		return fixtures[i].RecordedAt.Before(fixtures[j].RecordedAt)
+	})
+
+	return fixtures, nil
+}
+
+// fixturePath returns the redacted arguments of a call of tool and the path of its fixture. The name of the
+// file is a hash of the redacted arguments, encoded with sorted keys so that the same arguments always have
+// the same fixture.
+func (s *Store) fixturePath(tool string, arguments json.RawMessage) (json.RawMessage, string, error) {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	redacted, err := s.redactor.RedactJSON(arguments)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	sum := sha256.Sum256(redacted)
+	name := hex.EncodeToString(sum[:8]) + ".json"
+
+	return redacted, filepath.Join(s.dir, url.PathEscape(tool), name), nil
+}
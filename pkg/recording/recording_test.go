@@ -0,0 +1,190 @@
+package recording
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+func testStore(t *testing.T, dir, mode string) *Store {
+	t.Helper()
+
+	redactor, err := logging.NewRedactor(nil)
+	require.NoError(t, err)
+	store, err := NewStore(dir, mode, redactor)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestNewStore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "fixture.json")
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0o600))
+
+	tt := []struct {
+		name        string
+		dir         string
+		mode        string
+		errContains string
+	}{
+		{
+			name: "record to a new directory",
+			dir:  filepath.Join(dir, "new"),
+			mode: ModeRecord,
+		},
+		{
+			name: "replay from a directory",
+			dir:  dir,
+			mode: ModeReplay,
+		},
+		{
+			name:        "replay from a missing directory",
+			dir:         filepath.Join(dir, "missing"),
+			mode:        ModeReplay,
+			errContains: "failed to open fixtures directory",
+		},
+		{
+			name:        "replay from a file",
+			dir:         file,
+			mode:        ModeReplay,
+			errContains: "is not a directory",
+		},
+		{
+			name:        "unknown mode",
+			dir:         dir,
+			mode:        "mock",
+			errContains: "unknown recording mode mock",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewStore(tc.dir, tc.mode, nil)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	recorder := testStore(t, dir, ModeRecord)
+	assert.True(t, recorder.Recording())
+	assert.False(t, recorder.Replaying())
+
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "sunny"}}}
+	require.NoError(t, recorder.Record("get_weather", json.RawMessage(`{"city":"Paris","units":"metric"}`), result))
+
+	replayer := testStore(t, dir, ModeReplay)
+	assert.False(t, replayer.Recording())
+	assert.True(t, replayer.Replaying())
+
+	tt := []struct {
+		name      string
+		tool      string
+		arguments string
+		expected  *mcp.CallToolResult
+		expectErr error
+	}{
+		{
+			name:      "same arguments",
+			tool:      "get_weather",
+			arguments: `{"city":"Paris","units":"metric"}`,
+			expected:  result,
+		},
+		{
+			name:      "same arguments in another order",
+			tool:      "get_weather",
+			arguments: `{"units": "metric", "city": "Paris"}`,
+			expected:  result,
+		},
+		{
+			name:      "other arguments",
+			tool:      "get_weather",
+			arguments: `{"city":"Rome","units":"metric"}`,
+			expectErr: ErrNotRecorded,
+		},
+		{
+			name:      "other tool",
+			tool:      "get_forecast",
+			arguments: `{"city":"Paris","units":"metric"}`,
+			expectErr: ErrNotRecorded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			replayed, err := replayer.Replay(tc.tool, json.RawMessage(tc.arguments))
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, replayed)
+		})
+	}
+}
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	store := testStore(t, dir, ModeRecord)
+
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "called with Bearer abcdef123456"}}}
+	require.NoError(t, store.Record("login", json.RawMessage(`{"user":"alice","password":"hunter2"}`), result))
+
+	files, err := filepath.Glob(filepath.Join(dir, "login", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "abcdef123456")
+
+	var fixture Fixture
+	require.NoError(t, json.Unmarshal(data, &fixture))
+	assert.Equal(t, "login", fixture.Tool)
+	assert.JSONEq(t, `{"user":"alice","password":"[REDACTED]"}`, string(fixture.Arguments))
+
+	// calls are matched on their redacted arguments, so that fixtures don't hold secrets
+	replayed, err := store.Replay("login", json.RawMessage(`{"user":"alice","password":"other"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "called with Bearer [REDACTED]", replayed.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+	assert.False(t, store.Recording())
+	assert.False(t, store.Replaying())
+}
+
+func TestFixtures(t *testing.T) {
+	dir := t.TempDir()
+	store := testStore(t, dir, ModeRecord)
+
+	fixtures, err := store.Fixtures("get_weather")
+	require.NoError(t, err)
+	assert.Empty(t, fixtures)
+
+	for _, city := range []string{"Paris", "Rome"} {
+		result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: city}}}
+		require.NoError(t, store.Record("get_weather", json.RawMessage(`{"city":"`+city+`"}`), result))
+	}
+
+	fixtures, err = store.Fixtures("get_weather")
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(fixtures[0].Arguments))
+	assert.JSONEq(t, `{"city":"Rome"}`, string(fixtures[1].Arguments))
+}
@@ -0,0 +1,508 @@
+package runtime
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+// maxAdminRequestBytes is the maximum size of the tool definitions sent to the admin API.
+const maxAdminRequestBytes = 1 << 20
+
+// errToolNotFound is returned for changes to tools that are not defined in the MCP file.
+var errToolNotFound = errors.New("tool not found")
+
+// errToolsReadOnly is returned for changes to tools when the tool definitions of the server are not served
+// from the MCP file.
+var errToolsReadOnly = errors.New("the tools of the server cannot be changed")
+
+// adminAPI manages the tools of the MCP file at path, whose definitions are served by source. Changes are
+// validated with the config of the running server, written to the file, and applied to the running server,
+// which notifies connected clients.
+type adminAPI struct {
+	path         string
+	source       *toolDefinitionsSource
+	bearerTokens [][sha256.Size]byte
+	quotas       *quota.Tracker
+	status       *serverStatus
+	reloader     *configReloader
+	logger       *zap.Logger
+
+	mu sync.Mutex // serializes changes to the MCP file
+}
+
+// startAdminServer serves the admin API for the MCP file at path, served by source, on the address and port of
+// config, over TLS with tlsConfig if it is not nil, reporting the usage counted by quotas and the status
+// collected by status, and reloading the config with reloader, and returns a function that shuts it down.
+func startAdminServer(config *serverconfig.AdminConfig, tlsConfig *serverconfig.TLSConfig, path string, source *toolDefinitionsSource, quotas *quota.Tracker, status *serverStatus, reloader *configReloader, logger *zap.Logger) (func(), error) {
+	address := net.JoinHostPort(config.GetAddress(), strconv.Itoa(config.Port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	if tlsConfig != nil {
+		serverTLSConfig, err := tlsConfig.BuildTLSConfig()
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		listener = tls.NewListener(listener, serverTLSConfig)
+	}
+
+	srv := &http.Server{
+		Handler:           newAdminHandler(config, path, source, quotas, status, reloader, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Admin API server failed", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Admin API listening",
+		zap.String("address", address),
+		zap.Bool("tls", tlsConfig != nil),
+		zap.String("base_path", config.BasePath))
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Failed to shut down admin API server", zap.Error(err))
+		}
+	}, nil
+}
+
+// newAdminHandler returns the handler of the admin API for the MCP file at path, served by source, reporting
+// the usage counted by quotas and the status collected by status, and reloading the config with reloader. The
+// tools cannot be changed if source is nil, and the config cannot be reloaded if reloader is nil.
+func newAdminHandler(config *serverconfig.AdminConfig, path string, source *toolDefinitionsSource, quotas *quota.Tracker, status *serverStatus, reloader *configReloader, logger *zap.Logger) http.Handler {
+	a := &adminAPI{
+		path:     path,
+		source:   source,
+		quotas:   quotas,
+		status:   status,
+		reloader: reloader,
+		logger:   logger,
+	}
+	for _, token := range config.BearerTokens {
+		a.bearerTokens = append(a.bearerTokens, sha256.Sum256([]byte(token)))
+	}
+
+	basePath := config.BasePath
+	if basePath == "" {
+		basePath = serverconfig.DefaultAdminBasePath
+	}
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+basePath+"/tools", a.listTools)
+	mux.HandleFunc("GET "+basePath+"/tools/{name}", a.getTool)
+	mux.HandleFunc("PUT "+basePath+"/tools/{name}", a.putTool)
+	mux.HandleFunc("DELETE "+basePath+"/tools/{name}", a.deleteTool)
+	mux.HandleFunc("POST "+basePath+"/tools/{name}/disable", a.setDisabled(true))
+	mux.HandleFunc("POST "+basePath+"/tools/{name}/enable", a.setDisabled(false))
+	mux.HandleFunc("GET "+basePath+"/usage", a.listUsage)
+	mux.HandleFunc("GET "+basePath+"/usage/{client}", a.getUsage)
+	mux.HandleFunc("GET "+basePath+"/status", a.getStatus)
+	mux.HandleFunc("POST "+basePath+"/reload", a.reload)
+
+	return a.authenticate(mux)
+}
+
+// authenticate rejects requests without one of the bearer tokens of the admin API.
+func (a *adminAPI) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		// tokens are compared as hashes, so that comparisons take the same time whatever their length
+		actual := sha256.Sum256([]byte(token))
+		matched := 0
+		for _, expected := range a.bearerTokens {
+			matched |= subtle.ConstantTimeCompare(expected[:], actual[:])
+		}
+
+		if !ok || matched != 1 {
+			a.logger.Warn("Rejected admin API request without valid credentials",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("remote_addr", r.RemoteAddr))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *adminAPI) listTools(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, tools, err := a.readTools()
+	if err != nil {
+		a.fail(w, "list", "", err)
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]any{"tools": tools})
+}
+
+func (a *adminAPI) getTool(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name := r.PathValue("name")
+	_, tools, err := a.readTools()
+	if err != nil {
+		a.fail(w, "get", name, err)
+		return
+	}
+
+	i := findTool(tools, name)
+	if i < 0 {
+		a.fail(w, "get", name, errToolNotFound)
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, tools[i])
+}
+
+// putTool adds the tool in the request body, or replaces the tool with the same name.
+func (a *adminAPI) putTool(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminRequestBytes))
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	var tool map[string]json.RawMessage
+	if err := json.Unmarshal(body, &tool); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid tool definition: %v", err))
+		return
+	}
+	if bodyName, ok := tool["name"]; ok {
+		var n string
+		if err := json.Unmarshal(bodyName, &n); err != nil || n != name {
+			writeAdminError(w, http.StatusBadRequest, "the name of the tool must match the name in the path")
+			return
+		}
+	}
+	tool["name"], _ = json.Marshal(name)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	doc, tools, err := a.readTools()
+	if err != nil {
+		a.fail(w, "put", name, err)
+		return
+	}
+
+	status := http.StatusOK
+	if i := findTool(tools, name); i >= 0 {
+		tools[i] = tool
+	} else {
+		tools = append(tools, tool)
+		status = http.StatusCreated
+	}
+
+	if err := a.writeTools(doc, tools); err != nil {
+		a.fail(w, "put", name, err)
+		return
+	}
+
+	a.logger.Info("Tool saved through the admin API", zap.String("tool_name", name), zap.Bool("created", status == http.StatusCreated))
+	writeAdminJSON(w, status, tool)
+}
+
+func (a *adminAPI) deleteTool(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name := r.PathValue("name")
+	doc, tools, err := a.readTools()
+	if err != nil {
+		a.fail(w, "delete", name, err)
+		return
+	}
+
+	i := findTool(tools, name)
+	if i < 0 {
+		a.fail(w, "delete", name, errToolNotFound)
+		return
+	}
+
+	if err := a.writeTools(doc, append(tools[:i], tools[i+1:]...)); err != nil {
+		a.fail(w, "delete", name, err)
+		return
+	}
+
+	a.logger.Info("Tool removed through the admin API", zap.String("tool_name", name))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setDisabled returns a handler that disables or enables a tool.
+func (a *adminAPI) setDisabled(disabled bool) http.HandlerFunc {
+	action := "enable"
+	if disabled {
+		action = "disable"
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		name := r.PathValue("name")
+		doc, tools, err := a.readTools()
+		if err != nil {
+			a.fail(w, action, name, err)
+			return
+		}
+
+		i := findTool(tools, name)
+		if i < 0 {
+			a.fail(w, action, name, errToolNotFound)
+			return
+		}
+
+		if disabled {
+			tools[i]["disabled"] = json.RawMessage("true")
+		} else {
+			delete(tools[i], "disabled")
+		}
+
+		if err := a.writeTools(doc, tools); err != nil {
+			a.fail(w, action, name, err)
+			return
+		}
+
+		a.logger.Info("Tool state changed through the admin API", zap.String("tool_name", name), zap.Bool("disabled", disabled))
+		writeAdminJSON(w, http.StatusOK, tools[i])
+	}
+}
+
+func (a *adminAPI) listUsage(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, map[string]any{"clients": a.quotas.Usage()})
+}
+
+func (a *adminAPI) getUsage(w http.ResponseWriter, r *http.Request) {
+	client := r.PathValue("client")
+	usage, ok := a.quotas.ClientUsage(client)
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("client '%s' made no calls", client))
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, usage)
+}
+
+func (a *adminAPI) getStatus(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.status.status())
+}
+
+// reload reloads the tool definitions and the server config from their files.
+func (a *adminAPI) reload(w http.ResponseWriter, r *http.Request) {
+	if a.reloader == nil {
+		writeAdminError(w, http.StatusNotImplemented, "the config of the server cannot be reloaded")
+		return
+	}
+
+	var invalidErr *invalidConfigError
+	err := a.reloader.Reload()
+	switch {
+	case err == nil:
+		a.logger.Info("Config reloaded through the admin API")
+		writeAdminJSON(w, http.StatusOK, map[string]string{"message": "config reloaded"})
+	case errors.As(err, &invalidErr):
+		a.logger.Warn("Rejected invalid config reloaded through the admin API", zap.Error(err))
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, serverconfig.ErrRestartRequired):
+		a.logger.Warn("Rejected config reloaded through the admin API", zap.Error(err))
+		writeAdminError(w, http.StatusConflict, err.Error())
+	default:
+		a.logger.Error("Failed to reload config through the admin API", zap.Error(err))
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// readTools returns the fields of the MCP file and its tools, as JSON objects so that every other
+// field of the file and of its tools is written back as is.
+func (a *adminAPI) readTools() (map[string]json.RawMessage, []map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read MCP file: %w", err)
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse MCP file: %w", err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse MCP file: %w", err)
+	}
+
+	var tools []map[string]json.RawMessage
+	if raw, ok := doc["tools"]; ok {
+		if err := json.Unmarshal(raw, &tools); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the tools of the MCP file: %w", err)
+		}
+	}
+
+	return doc, tools, nil
+}
+
+// writeTools replaces the tools of doc and writes it to the MCP file, and serves its tools, if the result is
+// valid.
+func (a *adminAPI) writeTools(doc map[string]json.RawMessage, tools []map[string]json.RawMessage) error {
+	if a.source == nil {
+		return errToolsReadOnly
+	}
+
+	if len(tools) == 0 {
+		delete(doc, "tools")
+	} else {
+		raw, err := json.Marshal(tools)
+		if err != nil {
+			return err
+		}
+		doc["tools"] = raw
+	}
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.JSONToYAML(jsonData)
+	if err != nil {
+		return err
+	}
+
+	toolDefsFile, err := definitions.ParseMCPFileDataAt(data, a.path)
+	if err != nil {
+		return &invalidDefinitionsError{err: err}
+	}
+
+	return a.source.writeFile(toolDefsFile.MCPToolDefinitions, func() error {
+		return writeFileAtomically(a.path, data)
+	})
+}
+
+// fail logs err and writes the matching error response.
+func (a *adminAPI) fail(w http.ResponseWriter, action, name string, err error) {
+	var invalidErr *invalidDefinitionsError
+	switch {
+	case errors.Is(err, errToolNotFound):
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("tool '%s' not found", name))
+	case errors.Is(err, errToolsReadOnly):
+		writeAdminError(w, http.StatusNotImplemented, err.Error())
+	case errors.As(err, &invalidErr):
+		a.logger.Warn("Rejected invalid change through the admin API",
+			zap.String("action", action),
+			zap.String("tool_name", name),
+			zap.Error(err))
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+	default:
+		a.logger.Error("Admin API request failed",
+			zap.String("action", action),
+			zap.String("tool_name", name),
+			zap.Error(err))
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// invalidDefinitionsError is returned for changes that would make the MCP file invalid.
+type invalidDefinitionsError struct {
+	err error
+}
+
+func (e *invalidDefinitionsError) Error() string {
+	return fmt.Sprintf("invalid tool definitions: %v", e.err)
+}
+
+func (e *invalidDefinitionsError) Unwrap() error {
+	return e.err
+}
+
+// findTool returns the index of the tool named name, or -1 if there is none.
+func findTool(tools []map[string]json.RawMessage, name string) int {
+	for i, tool := range tools {
+		var n string
+		if err := json.Unmarshal(tool["name"], &n); err == nil && n == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// writeFileAtomically replaces the file at path with data, so that readers never see a partially written file.
+func writeFileAtomically(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write MCP file: %w", err)
+	}
+
+	return nil
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeAdminJSON(w, status, map[string]string{"error": message})
+}
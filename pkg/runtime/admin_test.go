@@ -0,0 +1,413 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+const adminTestToken = "admin-s3cr3t"
+
+// newAdminTestSource returns a source of the definitions of the MCP file at path, served by a server whose
+// network policy denies the hosts of the internal domain.
+func newAdminTestSource(t *testing.T, path string) *toolDefinitionsSource {
+	t.Helper()
+
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+	mcpServer := newTestMCPServer(t, *defs)
+	mcpServer.Runtime.Security = &serverconfig.SecurityConfig{
+		Network: &serverconfig.NetworkPolicyConfig{DeniedHosts: []string{"*.internal"}},
+	}
+
+	return &toolDefinitionsSource{
+		logger:   zap.NewNop(),
+		config:   mcpServer.MCPServerConfig,
+		file:     mcpServer.MCPToolDefinitions,
+		fileHash: configHash(mcpServer.MCPToolDefinitions),
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	tt := []struct {
+		name             string
+		method           string
+		path             string
+		token            string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		readOnly         bool
+		expectedTools    []string
+		expectedDisabled []string
+	}{
+		{
+			name:           "list tools",
+			method:         http.MethodGet,
+			path:           "/admin/tools",
+			token:          adminTestToken,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"second"`,
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "get tool",
+			method:         http.MethodGet,
+			path:           "/admin/tools/first",
+			token:          adminTestToken,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"url":"http://localhost:8080/first"`,
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "get missing tool",
+			method:         http.MethodGet,
+			path:           "/admin/tools/third",
+			token:          adminTestToken,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "tool 'third' not found",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "add tool",
+			method:         http.MethodPut,
+			path:           "/admin/tools/third",
+			token:          adminTestToken,
+			body:           `{"description":"A new tool","inputSchema":{"type":"object"},"invocation":{"http":{"method":"GET","url":"http://localhost:8080/third"}}}`,
+			expectedStatus: http.StatusCreated,
+			expectedTools:  []string{"first", "second", "third"},
+		},
+		{
+			name:           "update tool",
+			method:         http.MethodPut,
+			path:           "/admin/tools/first",
+			token:          adminTestToken,
+			body:           `{"name":"first","description":"Updated","inputSchema":{"type":"object"},"invocation":{"http":{"method":"POST","url":"http://localhost:8080/first"}}}`,
+			expectedStatus: http.StatusOK,
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "update tool with another name",
+			method:         http.MethodPut,
+			path:           "/admin/tools/first",
+			token:          adminTestToken,
+			body:           `{"name":"renamed","description":"Updated","inputSchema":{"type":"object"},"invocation":{"http":{"method":"GET","url":"http://localhost:8080/first"}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "the name of the tool must match the name in the path",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "invalid tool",
+			method:         http.MethodPut,
+			path:           "/admin/tools/third",
+			token:          adminTestToken,
+			body:           `{"description":"No invocation","inputSchema":{"type":"object"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid tool definitions",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "tool with url denied by the network policy",
+			method:         http.MethodPut,
+			path:           "/admin/tools/third",
+			token:          adminTestToken,
+			body:           `{"description":"Metadata","inputSchema":{"type":"object"},"invocation":{"http":{"method":"GET","url":"http://metadata.internal/latest"}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid url 'http://metadata.internal/latest'",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "tools not served from the MCP file",
+			method:         http.MethodDelete,
+			path:           "/admin/tools/first",
+			token:          adminTestToken,
+			readOnly:       true,
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   "the tools of the server cannot be changed",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:             "disable tool",
+			method:           http.MethodPost,
+			path:             "/admin/tools/second/disable",
+			token:            adminTestToken,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"disabled":true`,
+			expectedTools:    []string{"first", "second"},
+			expectedDisabled: []string{"second"},
+		},
+		{
+			name:           "enable tool",
+			method:         http.MethodPost,
+			path:           "/admin/tools/first/enable",
+			token:          adminTestToken,
+			expectedStatus: http.StatusOK,
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "remove tool",
+			method:         http.MethodDelete,
+			path:           "/admin/tools/first",
+			token:          adminTestToken,
+			expectedStatus: http.StatusNoContent,
+			expectedTools:  []string{"second"},
+		},
+		{
+			name:           "remove missing tool",
+			method:         http.MethodDelete,
+			path:           "/admin/tools/third",
+			token:          adminTestToken,
+			expectedStatus: http.StatusNotFound,
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "invalid token",
+			method:         http.MethodDelete,
+			path:           "/admin/tools/first",
+			token:          "wrong",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "missing or invalid bearer token",
+			expectedTools:  []string{"first", "second"},
+		},
+		{
+			name:           "no token",
+			method:         http.MethodGet,
+			path:           "/admin/tools",
+			expectedStatus: http.StatusUnauthorized,
+			expectedTools:  []string{"first", "second"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+			writeToolDefinitions(t, path, reloadTestTool("first"), reloadTestTool("second"))
+			var source *toolDefinitionsSource
+			if !tc.readOnly {
+				source = newAdminTestSource(t, path)
+			}
+
+			server := httptest.NewServer(newAdminHandler(&serverconfig.AdminConfig{
+				BasePath:     "/admin",
+				BearerTokens: []string{adminTestToken},
+			}, path, source, nil, nil, nil, zap.NewNop()))
+			defer server.Close()
+
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode, string(body))
+			assert.Contains(t, string(body), tc.expectedBody)
+
+			defs, err := loadToolDefinitions(path)
+			require.NoError(t, err, "the MCP file should stay valid")
+
+			var names, disabled []string
+			for _, tool := range defs.Tools {
+				names = append(names, tool.Name)
+				if tool.Disabled {
+					disabled = append(disabled, tool.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedTools, names)
+			assert.Equal(t, tc.expectedDisabled, disabled)
+		})
+	}
+}
+
+func TestAdminAPIUsage(t *testing.T) {
+	tracker := quota.NewTracker(quota.Config{Default: quota.Limits{PerHour: 100}})
+	require.NoError(t, tracker.Record("alice", "search"))
+	require.NoError(t, tracker.Record("bob", "get_user"))
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path, reloadTestTool("first"))
+
+	server := httptest.NewServer(newAdminHandler(&serverconfig.AdminConfig{
+		BearerTokens: []string{adminTestToken},
+	}, path, nil, tracker, nil, nil, zap.NewNop()))
+	defer server.Close()
+
+	tt := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "usage of all clients",
+			path:           "/admin/usage",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"client":"bob"`,
+		},
+		{
+			name:           "usage of a client",
+			path:           "/admin/usage/alice",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":{"calls":1,"rejected":0,"callsThisHour":1,"callsToday":1,"quotaPerHour":100}`,
+		},
+		{
+			name:           "unknown client",
+			path:           "/admin/usage/carol",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "client 'carol' made no calls",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tc.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+adminTestToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode, string(body))
+			assert.Contains(t, string(body), tc.expectedBody)
+		})
+	}
+}
+
+func TestAdminAPIStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path, reloadTestTool("first"))
+
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("second")))
+	sm := NewServerManager(mcpServer)
+	s, err := sm.ServerFromContext(context.Background())
+	require.NoError(t, err)
+	_, changed := connectTestClient(t, s)
+
+	require.NoError(t, sm.Reload(loadTestDefinitions(t, reloadTestTool("first"))))
+	waitForNotification(t, changed)
+
+	status := newServerStatus(mcpServer)
+	status.addListener(runtimeListenerName, sm)
+
+	server := httptest.NewServer(newAdminHandler(&serverconfig.AdminConfig{
+		BearerTokens: []string{adminTestToken},
+	}, path, nil, nil, status, nil, zap.NewNop()))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/status", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminTestToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var actual Status
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+
+	assert.Equal(t, mcpServer.Name(), actual.Server)
+	require.Len(t, actual.Listeners, 1)
+	listener := actual.Listeners[0]
+	assert.Equal(t, runtimeListenerName, listener.Name)
+	assert.Equal(t, configHash(loadTestDefinitions(t, reloadTestTool("first"))), listener.ConfigHash)
+	assert.Equal(t, 1, listener.Reloads)
+	assert.Equal(t, []ToolFilterStatus{{Scopes: "", Tools: []string{"first"}}}, listener.ToolFilters)
+	require.Len(t, listener.Sessions, 1)
+	assert.Equal(t, []string{"first"}, listener.Sessions[0].Tools)
+	assert.Empty(t, listener.RecentErrors)
+}
+
+func TestAdminAPIChangesAreServed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path, reloadTestTool("first"), reloadTestTool("second"))
+
+	sm := NewServerManager(newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("second"))))
+	s, err := sm.ServerFromContext(context.Background())
+	require.NoError(t, err)
+	cs, changed := connectTestClient(t, s)
+
+	// the changes are applied without watching the MCP file
+	source := newAdminTestSource(t, path)
+	source.onChange(sm.Reload)
+
+	server := httptest.NewServer(newAdminHandler(&serverconfig.AdminConfig{
+		BearerTokens: []string{adminTestToken},
+	}, path, source, nil, nil, nil, zap.NewNop()))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/tools/second/disable", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminTestToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	waitForNotification(t, changed)
+	assert.Equal(t, []string{"first"}, listToolNames(t, cs), "disabled tools should not be listed")
+
+	// the watcher reading the written file doesn't reload the definitions again
+	toolDefsFile, err := parseToolDefinitionsFile(path)
+	require.NoError(t, err)
+	require.NoError(t, source.setFile(toolDefsFile.MCPToolDefinitions))
+	assert.Equal(t, 1, sm.Status("default").Reloads)
+}
+
+func TestDisabledToolsAreNotServed(t *testing.T) {
+	defs := loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("second")+"  disabled: true\n")
+	require.Len(t, defs.Tools, 2)
+
+	tt := []struct {
+		name   string
+		server func() (*mcp.Server, error)
+	}{
+		{
+			name: "all tools",
+			server: func() (*mcp.Server, error) {
+				s, err := makeServerWithoutValidation(newTestMCPServer(t, defs), nil)
+				if err != nil {
+					return nil, err
+				}
+				return s.Server, nil
+			},
+		},
+		{
+			name: "filtered by scopes",
+			server: func() (*mcp.Server, error) {
+				return NewServerManager(newTestMCPServer(t, defs)).ServerFromContext(context.Background())
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := tc.server()
+			require.NoError(t, err)
+
+			cs, _ := connectTestClient(t, s)
+			assert.Equal(t, []string{"first"}, listToolNames(t, cs))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+)
+
+// auditToolCall writes the audit record of a call of tool with arguments, started at started, that returned
+// result and err. denied is true if the caller was not authorized to call the tool. It returns the error
+// result replacing the result of the call if its record was dropped, so that calls fail rather than go
+// unaudited, or nil.
+func auditToolCall(ctx context.Context, auditLog *audit.Logger, tool *definitions.Tool, arguments json.RawMessage,
+	started time.Time, denied bool, result *mcp.CallToolResult, err error) *mcp.CallToolResult {
+	if auditLog == nil {
+		return nil
+	}
+
+	var subject string
+	if claims := oauth.GetClaimsFromContext(ctx); claims != nil {
+		subject = claims.Subject
+	}
+
+	logErr := auditLog.Log(audit.Record{
+		Timestamp: started.UTC(),
+		Subject:   subject,
+		Tool:      tool.Name,
+		Arguments: arguments,
+		Outcome:   toolCallOutcome(ctx, denied, result, err),
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	})
+	if logErr != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "the call could not be audited")
+	}
+	return nil
+}
+
+func toolCallOutcome(ctx context.Context, denied bool, result *mcp.CallToolResult, err error) string {
+	switch {
+	case denied:
+		return audit.OutcomeDenied
+	case errors.Is(ctx.Err(), context.Canceled):
+		return audit.OutcomeCancelled
+	case err != nil || result == nil || result.IsError:
+		return audit.OutcomeError
+	default:
+		return audit.OutcomeSuccess
+	}
+}
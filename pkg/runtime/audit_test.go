@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+)
+
+func TestToolCallAudit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("repo") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"title":"crash"}]`))
+	}))
+	defer backend.Close()
+
+	tools := []string{`- name: list_issues
+  description: List the open issues of a repository
+  inputSchema:
+    type: object
+    properties:
+      repo:
+        type: string
+      token:
+        type: string
+  invocation:
+    http:
+      method: GET
+      url: ` + backend.URL + `/issues
+`, `- name: delete_repo
+  description: Delete a repository
+  inputSchema:
+    type: object
+    properties:
+      repo:
+        type: string
+  requiredScopes:
+    - admin
+  invocation:
+    http:
+      method: DELETE
+      url: ` + backend.URL + `/repos
+`}
+
+	tt := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+		expected  audit.Record
+	}{
+		{
+			name:      "successful call",
+			tool:      "list_issues",
+			arguments: map[string]any{"repo": "genmcp/gen-mcp", "token": "ghp_secret"},
+			expected: audit.Record{
+				Tool:      "list_issues",
+				Arguments: json.RawMessage(`{"repo":"genmcp/gen-mcp","token":"[REDACTED]"}`),
+				Outcome:   audit.OutcomeSuccess,
+			},
+		},
+		{
+			name:      "call returning an error",
+			tool:      "list_issues",
+			arguments: map[string]any{"repo": "missing"},
+			expected: audit.Record{
+				Tool:      "list_issues",
+				Arguments: json.RawMessage(`{"repo":"missing"}`),
+				Outcome:   audit.OutcomeError,
+			},
+		},
+		{
+			name:      "unauthorized call",
+			tool:      "delete_repo",
+			arguments: map[string]any{"repo": "genmcp/gen-mcp"},
+			expected: audit.Record{
+				Tool:      "delete_repo",
+				Arguments: json.RawMessage(`{"repo":"genmcp/gen-mcp"}`),
+				Outcome:   audit.OutcomeDenied,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tools...))
+			mcpServer.Runtime.Audit = &serverconfig.AuditConfig{
+				Sink:         serverconfig.AuditSinkFile,
+				Path:         path,
+				Arguments:    audit.ArgumentsRedacted,
+				RedactFields: []string{"token"},
+			}
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+			cs, _ := connectTestClient(t, s.Server)
+
+			started := time.Now().UTC()
+			_, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tc.tool, Arguments: tc.arguments})
+			require.NoError(t, err)
+
+			auditLog, err := mcpServer.Runtime.GetAuditLogger()
+			require.NoError(t, err)
+			require.NoError(t, auditLog.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			require.Len(t, lines, 1)
+
+			var record audit.Record
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+			assert.WithinDuration(t, started, record.Timestamp, 5*time.Second)
+			assert.GreaterOrEqual(t, record.LatencyMs, 0.0)
+			assert.Equal(t, tc.expected.Tool, record.Tool)
+			assert.JSONEq(t, string(tc.expected.Arguments), string(record.Arguments))
+			assert.Equal(t, tc.expected.Outcome, record.Outcome)
+			assert.Empty(t, record.Subject)
+		})
+	}
+}
+
+func TestToolCallOutcome(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tt := []struct {
+		name     string
+		ctx      context.Context
+		denied   bool
+		result   *mcp.CallToolResult
+		err      error
+		expected string
+	}{
+		{name: "success", ctx: context.Background(), result: &mcp.CallToolResult{}, expected: audit.OutcomeSuccess},
+		{name: "error result", ctx: context.Background(), result: &mcp.CallToolResult{IsError: true}, expected: audit.OutcomeError},
+		{name: "protocol error", ctx: context.Background(), err: assert.AnError, expected: audit.OutcomeError},
+		{name: "denied", ctx: context.Background(), denied: true, result: &mcp.CallToolResult{IsError: true}, expected: audit.OutcomeDenied},
+		{name: "cancelled", ctx: cancelled, result: &mcp.CallToolResult{IsError: true}, expected: audit.OutcomeCancelled},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, toolCallOutcome(tc.ctx, tc.denied, tc.result, tc.err))
+		})
+	}
+}
+
+func TestAuditToolCallSubject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := audit.NewFileSink(path)
+	require.NoError(t, err)
+	auditLog := audit.NewLogger(sink, audit.Options{}, zap.NewNop())
+
+	ctx := oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Subject: "alice"})
+	tool := loadTestDefinitions(t, `- name: list_issues
+  description: List the open issues
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      method: GET
+      url: http://localhost/issues
+`).Tools[0]
+	auditToolCall(ctx, auditLog, tool, json.RawMessage(`{}`), time.Now(), false, &mcp.CallToolResult{}, nil)
+	require.NoError(t, auditLog.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var record audit.Record
+	require.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "alice", record.Subject)
+}
@@ -0,0 +1,112 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallCancellation(t *testing.T) {
+	tt := []struct {
+		name string
+		// setup returns the definition of the tool and a channel closed when the tool observed the cancellation
+		setup func(t *testing.T) (string, <-chan struct{})
+		skip  bool
+	}{
+		{
+			name: "in-flight http request is aborted",
+			setup: func(t *testing.T) (string, <-chan struct{}) {
+				aborted := make(chan struct{})
+				backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					select {
+					case <-r.Context().Done():
+						close(aborted)
+					case <-time.After(10 * time.Second):
+					}
+				}))
+				t.Cleanup(backend.Close)
+
+				return `- name: slow_report
+  description: Build a slow report
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: GET
+      url: ` + backend.URL + `/report
+`, aborted
+			},
+		},
+		{
+			name: "cli command is terminated",
+			skip: runtime.GOOS == "windows",
+			setup: func(t *testing.T) (string, <-chan struct{}) {
+				marker := filepath.Join(t.TempDir(), "terminated")
+				terminated := make(chan struct{})
+				stop := make(chan struct{})
+				t.Cleanup(func() { close(stop) })
+				go func() {
+					ticker := time.NewTicker(20 * time.Millisecond)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-ticker.C:
+							if _, err := os.Stat(marker); err == nil {
+								close(terminated)
+								return
+							}
+						case <-stop:
+							return
+						}
+					}
+				}()
+
+				return `- name: slow_report
+  description: Build a slow report
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    cli:
+      command: "trap 'touch ` + marker + `; exit 1' TERM; sleep 30 & wait"
+`, terminated
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.skip {
+				t.Skip("not supported on " + runtime.GOOS)
+			}
+
+			tool, observed := tc.setup(t)
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tool))
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+			cs, _ := connectTestClient(t, s.Server)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(200*time.Millisecond, cancel)
+
+			_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "slow_report"})
+			assert.Error(t, err)
+
+			select {
+			case <-observed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the cancellation of the tool call was not propagated to the invocation")
+			}
+		})
+	}
+}
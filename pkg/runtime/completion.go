@@ -0,0 +1,245 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// maxCompletionValues is the maximum number of values of a completion result allowed by the MCP specification.
+const maxCompletionValues = 100
+
+// completer handles completion/complete requests for the arguments of prompts and resource templates, with
+// their completion invocations or the enums of their input schemas.
+type completer struct {
+	mu        sync.Mutex
+	prompts   map[string]*completionSource
+	templates map[string]*completionSource
+	pool      *concurrency.Pool
+}
+
+// completionSource completes the arguments of a prompt or resource template.
+type completionSource struct {
+	name           string
+	primitiveType  string
+	inputSchema    *jsonschema.Schema
+	requiredScopes []string
+	invokers       map[string]invocation.Invoker
+}
+
+// setPrimitives replaces the prompts and resource templates whose arguments are completed.
+func (c *completer) setPrimitives(prompts []*definitions.Prompt, templates []*definitions.ResourceTemplate) error {
+	var err error
+	promptSources := make(map[string]*completionSource, len(prompts))
+	for _, p := range prompts {
+		source, sourceErr := newCompletionSource(p.Name, "prompt", p.InputSchema, p.RequiredScopes, p.Completions, p.CompletionTool)
+		err = errors.Join(err, sourceErr)
+		promptSources[p.Name] = source
+	}
+
+	templateSources := make(map[string]*completionSource, len(templates))
+	for _, rt := range templates {
+		source, sourceErr := newCompletionSource(rt.Name, "resource_template", rt.InputSchema, rt.RequiredScopes, rt.Completions, rt.CompletionTool)
+		err = errors.Join(err, sourceErr)
+		templateSources[rt.URITemplate] = source
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prompts = promptSources
+	c.templates = templateSources
+	return err
+}
+
+// newCompletionSource creates the invokers of the completion invocations of a prompt or resource template.
+// Arguments whose invoker can't be created are not completed.
+func newCompletionSource(name, primitiveType string, inputSchema *jsonschema.Schema, requiredScopes []string,
+	completions map[string]*invocation.InvocationConfigWrapper, completionTool func(string) *definitions.Tool) (*completionSource, error) {
+	var err error
+	source := &completionSource{
+		name:           name,
+		primitiveType:  primitiveType,
+		inputSchema:    inputSchema,
+		requiredScopes: requiredScopes,
+		invokers:       make(map[string]invocation.Invoker, len(completions)),
+	}
+
+	for argument := range completions {
+		invoker, invokerErr := invocation.CreateInvoker(completionTool(argument))
+		if invokerErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to create completion invoker for argument %s of %s %s: %w", argument, primitiveType, name, invokerErr))
+			continue
+		}
+		source.invokers[argument] = invoker
+	}
+
+	return source, err
+}
+
+// complete is the completion handler of the server. It runs in the middlewares of the server, so the
+// invocations get the secrets, loggers and HTTP client of the request.
+func (c *completer) complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	ref := req.Params.Ref
+	if ref == nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "missing completion reference"}
+	}
+
+	c.mu.Lock()
+	var source *completionSource
+	switch ref.Type {
+	case "ref/prompt":
+		source = c.prompts[ref.Name]
+	case "ref/resource":
+		source = c.templates[ref.URI]
+	}
+	c.mu.Unlock()
+
+	if source == nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "unknown prompt or resource template"}
+	}
+
+	if err := checkPrimitiveAuthorization(ctx, source.requiredScopes, source.name, source.primitiveType); err != nil {
+		return nil, fmt.Errorf("forbidden: insufficient permissions")
+	}
+
+	argument := req.Params.Argument
+	invoker, ok := source.invokers[argument.Name]
+	if !ok {
+		return completionResult(filterCompletions(definitions.EnumCompletions(source.inputSchema, argument.Name), argument.Value)), nil
+	}
+
+	values, err := c.invoke(ctx, source, invoker, req)
+	if err != nil {
+		logging.BaseFromContext(ctx).Error("Completion invocation failed",
+			zap.String(source.primitiveType+"_name", source.name),
+			zap.String("argument", argument.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to complete argument %s", argument.Name)
+	}
+
+	return completionResult(values), nil
+}
+
+// invoke calls the completion invocation of an argument with the arguments already set by the client and
+// the partial value of the argument, and returns the values it completes the argument with.
+func (c *completer) invoke(ctx context.Context, source *completionSource, invoker invocation.Invoker, req *mcp.CompleteRequest) ([]string, error) {
+	args := make(map[string]string)
+	if req.Params.Context != nil {
+		for name, value := range req.Params.Context.Arguments {
+			// arguments that are not properties of the input schema would be rejected by the invocation
+			if source.inputSchema != nil && source.inputSchema.Properties[name] != nil {
+				args[name] = value
+			}
+		}
+	}
+	args[req.Params.Argument.Name] = req.Params.Argument.Value
+
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := acquireInvocation(ctx, c.pool, source.name, source.primitiveType, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := invoker.Invoke(ctx, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: source.name, Arguments: arguments},
+		Extra:  req.Extra,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if t, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(t.Text)
+		}
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("invocation returned an error: %s", text.String())
+	}
+
+	return parseCompletionValues(text.String())
+}
+
+// parseCompletionValues parses the output of a completion invocation: a JSON array of values, or a value per
+// line.
+func parseCompletionValues(output string) ([]string, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(output, "[") {
+		var values []string
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		for scanner.Scan() {
+			if value := strings.TrimSpace(scanner.Text()); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values, scanner.Err()
+	}
+
+	var items []any
+	if err := json.Unmarshal([]byte(output), &items); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of values: %w", err)
+	}
+
+	values := make([]string, 0, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			values = append(values, v)
+		case float64, bool:
+			values = append(values, fmt.Sprint(v))
+		default:
+			return nil, fmt.Errorf("item %d is not a string, number or boolean", i)
+		}
+	}
+
+	return values, nil
+}
+
+// filterCompletions returns the values starting with the partial value of the argument, ignoring case.
+func filterCompletions(values []string, partial string) []string {
+	filtered := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), strings.ToLower(partial)) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// completionResult returns the first values allowed in a completion result, with the total number of values.
+func completionResult(values []string) *mcp.CompleteResult {
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: values, Total: len(values)}}
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	if len(values) > maxCompletionValues {
+		result.Completion.Values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	return result
+}
@@ -0,0 +1,256 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompletionValues(t *testing.T) {
+	tt := []struct {
+		name          string
+		output        string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:   "empty output",
+			output: " \n",
+		},
+		{
+			name:     "value per line",
+			output:   "alice\n\n  bob  \n",
+			expected: []string{"alice", "bob"},
+		},
+		{
+			name:     "array of values",
+			output:   `["alice", 2, true]`,
+			expected: []string{"alice", "2", "true"},
+		},
+		{
+			name:          "invalid JSON",
+			output:        `["alice"`,
+			expectedError: "expected a JSON array of values",
+		},
+		{
+			name:          "invalid item",
+			output:        `[{"name": "alice"}]`,
+			expectedError: "item 0 is not a string, number or boolean",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseCompletionValues(tc.output)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+func TestCompletionResult(t *testing.T) {
+	values := make([]string, 150)
+	for i := range values {
+		values[i] = fmt.Sprint(i)
+	}
+
+	tt := []struct {
+		name     string
+		values   []string
+		expected mcp.CompletionResultDetails
+	}{
+		{
+			name:     "no values",
+			expected: mcp.CompletionResultDetails{Values: []string{}},
+		},
+		{
+			name:     "some values",
+			values:   []string{"a", "b"},
+			expected: mcp.CompletionResultDetails{Values: []string{"a", "b"}, Total: 2},
+		},
+		{
+			name:     "too many values",
+			values:   values,
+			expected: mcp.CompletionResultDetails{Values: values[:100], Total: 150, HasMore: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, completionResult(tc.values).Completion)
+		})
+	}
+}
+
+// completionTestFile returns an MCP file with a prompt whose language argument has an enum, and a resource
+// template of the repositories of the backend at url, whose repo variable is completed by the backend if
+// completions is set.
+func completionTestFile(url string, completions bool) string {
+	content := `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test-server
+version: "1.0.0"
+prompts:
+- name: review
+  description: Review code
+  inputSchema:
+    type: object
+    properties:
+      language:
+        type: string
+        description: The language of the code
+        enum: [go, golang, python]
+      code:
+        type: string
+    required: [code]
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/review
+resourceTemplates:
+- name: repository
+  description: A repository
+  uriTemplate: repos://{org}/{repo}
+  inputSchema:
+    type: object
+    properties:
+      org:
+        type: string
+      repo:
+        type: string
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/orgs/{org}/repos/{repo}
+`
+	if completions {
+		content += `  completions:
+    repo:
+      http:
+        method: GET
+        url: ` + url + `/orgs/{org}/repos?prefix={repo}
+`
+	}
+	return content
+}
+
+func TestCompletion(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orgs/genmcp/repos" {
+			_, _ = fmt.Fprintf(w, `["%s-mcp", "%s-docs"]`, r.URL.Query().Get("prefix"), r.URL.Query().Get("prefix"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer backend.Close()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(completionTestFile(backend.URL, true)), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	mcpServer := newTestMCPServer(t, *defs)
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	assert.NotNil(t, cs.InitializeResult().Capabilities.Completions)
+
+	prompts, err := cs.ListPrompts(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, prompts.Prompts, 1)
+	assert.Equal(t, []*mcp.PromptArgument{
+		{Name: "code", Required: true},
+		{Name: "language", Description: "The language of the code"},
+	}, prompts.Prompts[0].Arguments)
+
+	tt := []struct {
+		name          string
+		params        *mcp.CompleteParams
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "enum",
+			params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "review"},
+				Argument: mcp.CompleteParamsArgument{Name: "language", Value: "GO"},
+			},
+			expected: []string{"go", "golang"},
+		},
+		{
+			name: "no completions",
+			params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "review"},
+				Argument: mcp.CompleteParamsArgument{Name: "code", Value: "func"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "completion invocation",
+			params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: "repos://{org}/{repo}"},
+				Argument: mcp.CompleteParamsArgument{Name: "repo", Value: "gen"},
+				Context:  &mcp.CompleteContext{Arguments: map[string]string{"org": "genmcp", "other": "ignored"}},
+			},
+			expected: []string{"gen-mcp", "gen-docs"},
+		},
+		{
+			name: "failed completion invocation",
+			params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: "repos://{org}/{repo}"},
+				Argument: mcp.CompleteParamsArgument{Name: "repo", Value: "gen"},
+				Context:  &mcp.CompleteContext{Arguments: map[string]string{"org": "other"}},
+			},
+			expectedError: "failed to complete argument repo",
+		},
+		{
+			name: "unknown prompt",
+			params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "other"},
+				Argument: mcp.CompleteParamsArgument{Name: "language", Value: "go"},
+			},
+			expectedError: "unknown prompt or resource template",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := cs.Complete(context.Background(), tc.params)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res.Completion.Values)
+		})
+	}
+
+	t.Run("completions are updated on reload", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(completionTestFile(backend.URL, false)), 0644))
+		newDefs, err := loadToolDefinitions(path)
+		require.NoError(t, err)
+
+		require.NoError(t, syncServerPrimitives(s, mcpServer, newTestMCPServer(t, *newDefs), nil))
+
+		res, err := cs.Complete(context.Background(), &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: "repos://{org}/{repo}"},
+			Argument: mcp.CompleteParamsArgument{Name: "repo", Value: "gen"},
+			Context:  &mcp.CompleteContext{Arguments: map[string]string{"org": "genmcp"}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, res.Completion.Values)
+	})
+}
@@ -0,0 +1,35 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// codeConcurrencyLimitExceeded is the JSON-RPC error code of requests rejected by a concurrency limit, in the
+// range reserved for implementation-defined server errors.
+const codeConcurrencyLimitExceeded = -32000
+
+// acquireInvocation waits for a slot of pool to invoke the primitive name, limited to limit concurrent
+// invocations if positive, and returns the function releasing it. Invocations rejected by a limit return
+// a JSON-RPC error, so that clients can tell them apart from failed invocations and retry them later.
+func acquireInvocation(ctx context.Context, pool *concurrency.Pool, name, primitiveType string, limit int) (func(), error) {
+	release, err := pool.Acquire(ctx, name, limit)
+	if err == nil {
+		return release, nil
+	}
+
+	logging.BaseFromContext(ctx).Warn("Invocation rejected by a concurrency limit",
+		zap.String(primitiveType+"_name", name),
+		zap.Error(err))
+
+	if errors.Is(err, concurrency.ErrLimitExceeded) {
+		return nil, &jsonrpc.Error{Code: codeConcurrencyLimitExceeded, Message: "server busy: " + err.Error()}
+	}
+	return nil, err
+}
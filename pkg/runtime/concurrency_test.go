@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+func TestConcurrencyLimits(t *testing.T) {
+	tt := []struct {
+		name           string
+		concurrency    *serverconfig.ConcurrencyConfig
+		maxConcurrency string
+		secondTool     string
+		expectRejected bool
+		expectQueued   bool
+	}{
+		{
+			name:           "tool limit rejects calls of the same tool",
+			concurrency:    &serverconfig.ConcurrencyConfig{QueueTimeout: "100ms"},
+			maxConcurrency: "1",
+			secondTool:     "slow",
+			expectRejected: true,
+		},
+		{
+			name:           "tool limit does not apply to other tools",
+			concurrency:    &serverconfig.ConcurrencyConfig{QueueTimeout: "100ms"},
+			maxConcurrency: "1",
+			secondTool:     "fast",
+		},
+		{
+			name:           "global limit rejects calls of any tool",
+			concurrency:    &serverconfig.ConcurrencyConfig{MaxInFlight: 1, QueueTimeout: "100ms"},
+			maxConcurrency: "0",
+			secondTool:     "fast",
+			expectRejected: true,
+		},
+		{
+			name:           "queued call runs once the running call completes",
+			concurrency:    &serverconfig.ConcurrencyConfig{QueueTimeout: "1m"},
+			maxConcurrency: "1",
+			secondTool:     "slow",
+			expectQueued:   true,
+		},
+		{
+			name:           "tool limit applies without concurrency config",
+			maxConcurrency: "1",
+			secondTool:     "slow",
+			expectQueued:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{}, 10)
+			unblock := make(chan struct{})
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					started <- struct{}{}
+					<-unblock
+				}
+				_, _ = w.Write([]byte(`{"ok":true}`))
+			}))
+			defer backend.Close()
+
+			defs := loadTestDefinitions(t,
+				concurrencyTestTool("slow", backend.URL, tc.maxConcurrency),
+				concurrencyTestTool("fast", backend.URL, "0"),
+			)
+			mcpServer := newTestMCPServer(t, defs)
+			mcpServer.Runtime.Concurrency = tc.concurrency
+
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+			cs, _ := connectTestClient(t, s.Server)
+
+			firstDone := make(chan error, 1)
+			go func() {
+				_, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "slow"})
+				firstDone <- err
+			}()
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("first call did not reach the backend")
+			}
+
+			secondDone := make(chan error, 1)
+			go func() {
+				_, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tc.secondTool})
+				secondDone <- err
+			}()
+
+			switch {
+			case tc.expectRejected:
+				select {
+				case err := <-secondDone:
+					assert.ErrorContains(t, err, "server busy", "second call should be rejected by the limit")
+				case <-time.After(5 * time.Second):
+					t.Fatal("second call was not rejected")
+				}
+				close(unblock)
+			case tc.expectQueued:
+				select {
+				case <-started:
+					t.Fatal("second call should wait for the first call to complete")
+				case <-time.After(100 * time.Millisecond):
+				}
+				close(unblock)
+				select {
+				case err := <-secondDone:
+					assert.NoError(t, err, "queued call should complete")
+				case <-time.After(5 * time.Second):
+					t.Fatal("queued call did not complete")
+				}
+			default:
+				select {
+				case err := <-secondDone:
+					assert.NoError(t, err, "second call should not wait for the first call")
+				case <-time.After(5 * time.Second):
+					t.Fatal("second call did not complete")
+				}
+				close(unblock)
+			}
+
+			select {
+			case err := <-firstDone:
+				assert.NoError(t, err, "first call should complete")
+			case <-time.After(5 * time.Second):
+				t.Fatal("first call did not complete")
+			}
+		})
+	}
+}
+
+func concurrencyTestTool(name, url, maxConcurrency string) string {
+	return `- name: ` + name + `
+  description: "A test tool"
+  maxConcurrency: ` + maxConcurrency + `
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/` + name + `
+`
+}
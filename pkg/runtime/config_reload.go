@@ -0,0 +1,127 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// configLoader loads the tool definitions and the server config of a server from their files again, and
+// validates them. Warnings are logged with logger.
+type configLoader func(logger *zap.Logger) (*mcpserver.MCPServer, error)
+
+// configReloader reloads the tool definitions and the server config of a running server from their files,
+// on SIGHUP or through the admin API. The new config is validated before it is applied, and the running
+// config is kept if it is invalid or changes settings that require a restart. Otherwise, the reload
+// functions of the transports replace their servers with ones serving the new config.
+type configReloader struct {
+	load   configLoader
+	source *toolDefinitionsSource
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	current *mcpserver.MCPServer
+	reloads []func(*mcpserver.MCPServer) error
+}
+
+// invalidConfigError is returned when the reloaded config is invalid.
+type invalidConfigError struct {
+	err error
+}
+
+func (e *invalidConfigError) Error() string {
+	return fmt.Sprintf("invalid config, keeping the running config: %v", e.err)
+}
+
+func (e *invalidConfigError) Unwrap() error {
+	return e.err
+}
+
+// newConfigReloader creates a reloader of mcpServer, a running server loaded by load, whose tool definitions
+// are combined with the imported tools of source if not nil.
+func newConfigReloader(mcpServer *mcpserver.MCPServer, load configLoader, source *toolDefinitionsSource) *configReloader {
+	return &configReloader{
+		load:    load,
+		source:  source,
+		logger:  mcpServer.Runtime.GetBaseLogger(),
+		current: mcpServer,
+	}
+}
+
+// onReload registers a function replacing the servers of a transport with ones serving a reloaded config.
+// A nil reloader ignores it, as the config of the server cannot be reloaded.
+func (r *configReloader) onReload(reload func(*mcpserver.MCPServer) error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reloads = append(r.reloads, reload)
+}
+
+// Reload loads the config of the server from its files, and applies it if it is valid and only changes
+// settings that can be changed without a restart.
+func (r *configReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logger.Info("Reloading config")
+
+	next, err := r.load(r.logger)
+	if err != nil {
+		return &invalidConfigError{err: err}
+	}
+	if err := next.PrepareReload(&r.current.MCPServerConfig); err != nil {
+		return err
+	}
+
+	if r.source != nil {
+		defs, err := r.source.replaceFile(next.MCPToolDefinitions, next.MCPServerConfig)
+		if err != nil {
+			return &invalidConfigError{err: err}
+		}
+		next.MCPToolDefinitions = defs
+	}
+
+	for _, reload := range r.reloads {
+		err = errors.Join(err, reload(next))
+	}
+	r.current = next
+
+	if err != nil {
+		return fmt.Errorf("config reloaded with some errors: %w", err)
+	}
+
+	r.logger.Info("Config reloaded",
+		zap.String("server_version", next.Version()),
+		zap.Int("num_tools", len(next.Tools)))
+	return nil
+}
+
+// reloadOnSignal reloads the config whenever the process receives SIGHUP, until ctx is cancelled.
+func (r *configReloader) reloadOnSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Failed to reload config on SIGHUP", zap.Error(err))
+			}
+		}
+	}
+}
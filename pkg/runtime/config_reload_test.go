@@ -0,0 +1,146 @@
+package runtime
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/health"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+const reloadTestServerConfig = `kind: MCPServerConfig
+schemaVersion: "0.2.0"
+runtime:
+  transportProtocol: streamablehttp
+  streamableHttpConfig:
+    port: 8080
+`
+
+func TestConfigReloaderReload(t *testing.T) {
+	tt := []struct {
+		name          string
+		tools         []string
+		serverConfig  string
+		expectedTools []string
+		expectedLimit int
+		expectInvalid bool
+		expectRestart bool
+	}{
+		{
+			name:          "tools and limits",
+			tools:         []string{reloadTestTool("first"), reloadTestTool("second")},
+			serverConfig:  reloadTestServerConfig + "  limits:\n    maxArgumentBytes: 1024\n",
+			expectedTools: []string{"first", "second"},
+			expectedLimit: 1024,
+		},
+		{
+			name:          "invalid tool definitions",
+			tools:         []string{reloadTestTool("first"), "- name: [broken\n"},
+			serverConfig:  reloadTestServerConfig,
+			expectInvalid: true,
+		},
+		{
+			name:          "port",
+			tools:         []string{reloadTestTool("first")},
+			serverConfig:  "kind: MCPServerConfig\nschemaVersion: \"0.2.0\"\nruntime:\n  transportProtocol: streamablehttp\n  streamableHttpConfig:\n    port: 8081\n",
+			expectRestart: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			toolDefinitionsPath := filepath.Join(dir, "mcpfile.yaml")
+			serverConfigPath := filepath.Join(dir, "mcpserver.yaml")
+			writeToolDefinitions(t, toolDefinitionsPath, reloadTestTool("first"))
+			require.NoError(t, os.WriteFile(serverConfigPath, []byte(reloadTestServerConfig), 0644))
+
+			load := newConfigLoader(toolDefinitionsPath, serverConfigPath, RunOptions{})
+			mcpServer, err := load(zap.NewNop())
+			require.NoError(t, err)
+
+			reloader := newConfigReloader(mcpServer, load, nil)
+			var reloaded *mcpserver.MCPServer
+			reloader.onReload(func(next *mcpserver.MCPServer) error {
+				reloaded = next
+				return nil
+			})
+
+			writeToolDefinitions(t, toolDefinitionsPath, tc.tools...)
+			require.NoError(t, os.WriteFile(serverConfigPath, []byte(tc.serverConfig), 0644))
+
+			err = reloader.Reload()
+			switch {
+			case tc.expectInvalid:
+				var invalidErr *invalidConfigError
+				assert.ErrorAs(t, err, &invalidErr)
+				assert.Nil(t, reloaded)
+				assert.Same(t, mcpServer, reloader.current, "the running config should be kept")
+			case tc.expectRestart:
+				assert.ErrorIs(t, err, serverconfig.ErrRestartRequired)
+				assert.Nil(t, reloaded)
+				assert.Same(t, mcpServer, reloader.current, "the running config should be kept")
+			default:
+				require.NoError(t, err)
+				require.NotNil(t, reloaded)
+				assert.Equal(t, tc.expectedTools, toolNames(reloaded.Tools))
+				assert.Equal(t, tc.expectedLimit, reloaded.Runtime.Limits.MaxArgumentBytes)
+				assert.Same(t, mcpServer.Runtime.GetBaseLogger(), reloaded.Runtime.GetBaseLogger())
+			}
+		})
+	}
+}
+
+func TestGenerationHandlerDrainsPreviousSessions(t *testing.T) {
+	newStatefulServer := func(tools ...string) *mcpserver.MCPServer {
+		mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tools...))
+		stateless := false
+		mcpServer.Runtime.StreamableHTTPConfig.Stateless = &stateless
+		return mcpServer
+	}
+
+	healthChecker := health.NewChecker()
+	handler := &generationHandler{
+		current: newHTTPGeneration(newStatefulServer(reloadTestTool("first")), healthChecker, nil),
+		logger:  zap.NewNop(),
+	}
+	server := httptest.NewServer(handler)
+	// closed after the client sessions, which are closed by their own cleanups
+	t.Cleanup(server.Close)
+
+	connect := func() *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		cs, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: server.URL + "/mcp"}, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = cs.Close() })
+		return cs
+	}
+
+	previous := connect()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler.swap(ctx, newHTTPGeneration(newStatefulServer(reloadTestTool("first"), reloadTestTool("second")), healthChecker, nil), time.Second)
+
+	assert.Equal(t, []string{"first"}, listToolNames(t, previous), "sessions of the previous config should be served by it")
+	assert.Equal(t, []string{"first", "second"}, listToolNames(t, connect()), "new sessions should be served by the new config")
+
+	// the session of the previous config is closed after the drain timeout
+	require.Eventually(t, func() bool {
+		handler.mu.RLock()
+		defer handler.mu.RUnlock()
+		return len(handler.draining) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+	_, err := previous.ListTools(context.Background(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// elicitMissingArguments asks the user for the missing required arguments of a call of tool, if the tool has
+// elicitation enabled and the client supports it. It returns the arguments completed with the values provided
+// by the user, or a result rejecting the call if the user did not provide them. The arguments are returned
+// unchanged when the user can't be asked, so that the call fails validation as usual.
+func elicitMissingArguments(ctx context.Context, req *mcp.CallToolRequest, tool *definitions.Tool) (json.RawMessage, *mcp.CallToolResult) {
+	arguments := req.Params.Arguments
+	if tool.Elicitation == nil || !supportsElicitation(req.Session) {
+		return arguments, nil
+	}
+
+	missing := tool.MissingArguments(arguments)
+	if len(missing) == 0 {
+		return arguments, nil
+	}
+
+	schema, ok := tool.ElicitationSchema(missing)
+	if !ok {
+		return arguments, nil
+	}
+
+	args := make(map[string]any)
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil || args == nil {
+			return arguments, nil
+		}
+	}
+
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         tool.ElicitationMessage(),
+		RequestedSchema: schema,
+	})
+	if err != nil {
+		logging.BaseFromContext(ctx).Warn("Failed to elicit the missing tool arguments",
+			zap.String("tool_name", tool.Name),
+			zap.Error(err))
+		return arguments, nil
+	}
+
+	if result.Action != "accept" {
+		return nil, utils.McpCodedError(invocation.ErrorCodeValidation, "the user did not provide the missing arguments: %s", strings.Join(missing, ", "))
+	}
+
+	for name, value := range result.Content {
+		if _, requested := schema.Properties[name]; requested {
+			args[name] = value
+		}
+	}
+
+	completed, err := json.Marshal(args)
+	if err != nil {
+		return arguments, nil
+	}
+	return completed, nil
+}
+
+// supportsElicitation reports whether the client of session can be asked for input with elicitation forms.
+func supportsElicitation(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+
+	params := session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return false
+	}
+
+	// clients that don't advertise any mode support forms
+	elicitation := params.Capabilities.Elicitation
+	return elicitation.Form != nil || elicitation.URL == nil
+}
@@ -0,0 +1,165 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func elicitationTestTool(url string, elicitation bool) string {
+	tool := `- name: create_ticket
+  description: Create a ticket
+  inputSchema:
+    type: object
+    properties:
+      title:
+        type: string
+        description: Title of the ticket
+      priority:
+        type: string
+        enum: [low, high]
+    required: [title, priority]
+  invocation:
+    http:
+      method: POST
+      url: ` + url + `/tickets
+`
+	if elicitation {
+		tool += `  elicitation:
+    message: Some details of the ticket are missing
+    prompts:
+      priority: How urgent is the ticket?
+`
+	}
+	return tool
+}
+
+func TestElicitMissingArguments(t *testing.T) {
+	tt := []struct {
+		name             string
+		elicitation      bool
+		clientElicits    bool
+		arguments        map[string]any
+		response         *mcp.ElicitResult
+		expectElicited   bool
+		expectError      bool
+		expectText       string
+		expectErrMessage string
+	}{
+		{
+			name:           "missing arguments are asked to the user",
+			elicitation:    true,
+			clientElicits:  true,
+			arguments:      map[string]any{"title": "Broken"},
+			response:       &mcp.ElicitResult{Action: "accept", Content: map[string]any{"priority": "high", "title": "ignored"}},
+			expectElicited: true,
+			expectText:     `{"priority":"high","title":"Broken"}`,
+		},
+		{
+			name:             "user declines to provide the missing arguments",
+			elicitation:      true,
+			clientElicits:    true,
+			arguments:        map[string]any{"title": "Broken"},
+			response:         &mcp.ElicitResult{Action: "decline"},
+			expectElicited:   true,
+			expectError:      true,
+			expectErrMessage: "the user did not provide the missing arguments: priority",
+		},
+		{
+			name:          "complete arguments are not asked to the user",
+			elicitation:   true,
+			clientElicits: true,
+			arguments:     map[string]any{"title": "Broken", "priority": "low"},
+			expectText:    `{"priority":"low","title":"Broken"}`,
+		},
+		{
+			name:        "client without elicitation support",
+			elicitation: true,
+			arguments:   map[string]any{"title": "Broken"},
+			expectError: true,
+		},
+		{
+			name:          "tool without elicitation",
+			clientElicits: true,
+			arguments:     map[string]any{"title": "Broken"},
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				_, _ = w.Write(body)
+			}))
+			defer backend.Close()
+
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, elicitationTestTool(backend.URL, tc.elicitation)))
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+
+			var elicited *mcp.ElicitParams
+			opts := &mcp.ClientOptions{}
+			if tc.clientElicits {
+				opts.ElicitationHandler = func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					elicited = req.Params
+					return tc.response, nil
+				}
+			}
+			cs := connectTestClientWithOptions(t, s.Server, opts)
+
+			result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "create_ticket", Arguments: tc.arguments})
+			require.NoError(t, err)
+
+			if tc.expectElicited {
+				require.NotNil(t, elicited, "the user should be asked for the missing arguments")
+				assert.Equal(t, "Some details of the ticket are missing", elicited.Message)
+
+				schema, err := json.Marshal(elicited.RequestedSchema)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{
+					"type": "object",
+					"properties": {"priority": {"type": "string", "enum": ["low", "high"], "description": "How urgent is the ticket?"}},
+					"required": ["priority"]
+				}`, string(schema))
+			} else {
+				assert.Nil(t, elicited, "the user should not be asked for arguments")
+			}
+
+			assert.Equal(t, tc.expectError, result.IsError)
+			require.Len(t, result.Content, 1)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tc.expectText != "" {
+				assert.JSONEq(t, tc.expectText, text)
+			}
+			if tc.expectErrMessage != "" {
+				assert.Equal(t, tc.expectErrMessage, text)
+			}
+		})
+	}
+}
+
+// connectTestClientWithOptions connects a client created with opts to s
+func connectTestClientWithOptions(t *testing.T, s *mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
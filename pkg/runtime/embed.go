@@ -0,0 +1,243 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+)
+
+// ServerOptions are the options of a server embedded in another Go program.
+type ServerOptions struct {
+	// Logger replaces the logger built from the logging config of the server, so that the server logs with
+	// the logger of the program embedding it.
+	Logger *zap.Logger
+}
+
+// Server is an MCP server embedded in another Go program, which serves its MCP endpoint with its own HTTP
+// server, e.g. by mounting the Server on its mux:
+//
+//	server, err := runtime.NewServer(mcpServer, runtime.ServerOptions{Logger: logger})
+//	if err != nil { ... }
+//	if err := server.Start(ctx); err != nil { ... }
+//	defer server.Stop(context.Background())
+//	mux.Handle("/mcp", middleware(server))
+//
+// The Server serves the MCP endpoint whatever the path of the requests, the OAuth protected resource
+// metadata at /.well-known/oauth-protected-resource if the server uses OAuth, and the webhooks at their
+// paths, which the embedding program mounts the Server on too. The port, TLS and health
+// endpoints of the streamable HTTP config are left to the embedding program.
+type Server struct {
+	mcpServer *mcpserver.MCPServer
+	logger    *zap.Logger
+
+	mu              sync.RWMutex
+	started         bool
+	cancel          context.CancelFunc
+	generation      *httpGeneration
+	metadata        http.HandlerFunc
+	webhooks        map[string]http.Handler
+	shutdownTracing func(context.Context) error
+}
+
+// LoadServer loads and validates the server defined in the given config files, e.g. to embed it with
+// NewServer.
+func LoadServer(toolDefinitionsPath, serverConfigPath string, opts RunOptions) (*mcpserver.MCPServer, error) {
+	mcpServer, err := loadServer(toolDefinitionsPath, serverConfigPath, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateServer(mcpServer, toolDefinitionsPath, serverConfigPath, mcpServer.Runtime.GetBaseLogger()); err != nil {
+		return nil, err
+	}
+	return mcpServer, nil
+}
+
+// NewServer returns an embeddable server serving mcpServer, or an error if its config is invalid or uses the
+// listeners or the admin API, which are only supported by the standalone server. The server doesn't serve
+// requests until it is started.
+func NewServer(mcpServer *mcpserver.MCPServer, opts ServerOptions) (*Server, error) {
+	mcpServer.ApplyDefaults()
+	if opts.Logger != nil {
+		mcpServer.Runtime.SetBaseLogger(opts.Logger)
+	}
+
+	if mcpServer.Runtime.TransportProtocol != serverconfig.TransportProtocolStreamableHttp {
+		return nil, fmt.Errorf("embedded servers only support the %s transport protocol", serverconfig.TransportProtocolStreamableHttp)
+	}
+	if len(mcpServer.Runtime.Listeners) > 0 {
+		return nil, fmt.Errorf("embedded servers don't support listeners")
+	}
+	if mcpServer.Runtime.Admin != nil {
+		return nil, fmt.Errorf("embedded servers don't support the admin API")
+	}
+
+	if err := mcpServer.Validate(invocationValidator(mcpServer)); err != nil {
+		return nil, fmt.Errorf("invalid server configuration: %w", err)
+	}
+
+	return &Server{
+		mcpServer: mcpServer,
+		logger:    mcpServer.Runtime.GetBaseLogger(),
+	}, nil
+}
+
+// Start sets up the tracing, the audit log, the session store and the imported tools of the server, and
+// starts serving requests. The tools imported from OpenAPI documents and upstream servers are refreshed,
+// and the tools of the schedules are called, until the server is stopped.
+func (s *Server) Start(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("server is already started")
+	}
+
+	mcpServer := s.mcpServer
+	s.logger.Info("Starting embedded MCP server",
+		zap.String("server_name", mcpServer.Name()),
+		zap.String("server_version", mcpServer.Version()))
+
+	// everything set up so far is released if a later step fails
+	defer func() {
+		if err != nil {
+			_ = s.release(ctx)
+		}
+	}()
+
+	if tracingConfig := mcpServer.Runtime.TracingConfig; tracingConfig != nil {
+		s.shutdownTracing, err = tracingConfig.Setup(ctx, mcpServer.Name(), mcpServer.Version())
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		s.logger.Info("Tracing enabled")
+	}
+
+	if _, err = mcpServer.Runtime.GetAuditLogger(); err != nil {
+		return fmt.Errorf("failed to set up the audit log: %w", err)
+	}
+
+	// the imported tools are refreshed until the server is stopped, not until ctx is done, with the sessions of
+	// the upstream servers of the server
+	sourceCtx, cancel := context.WithCancel(proxy.WithOwner(context.WithoutCancel(ctx), s))
+	s.cancel = cancel
+	source, err := newToolDefinitionsSource(sourceCtx, mcpServer, "")
+	if err != nil {
+		return fmt.Errorf("failed to load tool definitions: %w", err)
+	}
+
+	// like the imported tools, the tools of the schedules are called until the server is stopped
+	if err := startSchedules(sourceCtx, mcpServer, source, nil); err != nil {
+		return fmt.Errorf("failed to start schedules: %w", err)
+	}
+
+	sessionStore, err := mcpServer.Runtime.NewSessionStore()
+	if err != nil {
+		return fmt.Errorf("failed to create session store: %w", err)
+	}
+
+	s.generation = newMCPGeneration(mcpServer, sessionStore)
+	if source != nil {
+		manager := s.generation.manager
+		source.onChange(func(defs definitions.MCPToolDefinitions) error {
+			return manager.Reload(defs)
+		})
+	}
+
+	if auth := mcpServer.Runtime.StreamableHTTPConfig.Auth; auth != nil && auth.UsesOAuth() {
+		s.metadata = oauth.ProtectedResourceMetadataHandler(mcpServer)
+	}
+
+	s.webhooks, err = webhookHandlers(mcpServer)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook receivers: %w", err)
+	}
+
+	s.started = true
+	return nil
+}
+
+// Stop closes the sessions of the server, stops the commands of the upstream servers its calls were forwarded
+// to, and flushes the audit log and the pending traces before ctx is done. Requests are answered with 503
+// Service Unavailable once the server is stopped, until it is started again. Stop does nothing if the server
+// is not started.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		return nil
+	}
+
+	s.logger.Info("Stopping embedded MCP server")
+	s.started = false
+	return s.release(ctx)
+}
+
+// release releases what Start set up. It must be called with mu locked.
+func (s *Server) release(ctx context.Context) error {
+	var err error = nil
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	if s.generation != nil {
+		// stored sessions stay in the store, clients resume them on other replicas
+		s.generation.close()
+		s.generation = nil
+	}
+	s.metadata = nil
+	s.webhooks = nil
+
+	// stop the commands of the upstream MCP servers that the calls of this server were forwarded to
+	proxy.CloseOwnedSessions(s)
+
+	// the audit log is created again if the server is started again
+	if closeErr := s.mcpServer.Runtime.CloseAuditLogger(); closeErr != nil {
+		err = fmt.Errorf("failed to close the audit log: %w", closeErr)
+	}
+	if s.shutdownTracing != nil {
+		if shutdownErr := s.shutdownTracing(ctx); shutdownErr != nil {
+			s.logger.Warn("Failed to flush traces", zap.Error(shutdownErr))
+		}
+		s.shutdownTracing = nil
+	}
+
+	return err
+}
+
+// ServeHTTP serves the MCP endpoint of the server, its OAuth protected resource metadata, or its webhooks.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	started, generation, metadata, webhooks := s.started, s.generation, s.metadata, s.webhooks
+	s.mu.RUnlock()
+
+	if !started {
+		http.Error(w, "MCP server is not started", http.StatusServiceUnavailable)
+		return
+	}
+
+	// the calls of the request are forwarded to upstream servers with sessions of this server
+	r = r.WithContext(proxy.WithOwner(r.Context(), s))
+
+	if metadata != nil && r.URL.Path == oauth.ProtectedResourceMetadataEndpoint {
+		metadata(w, r)
+		return
+	}
+
+	if webhook, ok := webhooks[r.URL.Path]; ok {
+		webhook.ServeHTTP(w, r)
+		return
+	}
+
+	generation.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+func TestNewServer(t *testing.T) {
+	tt := []struct {
+		name          string
+		modify        func(mcpServer *mcpserver.MCPServer)
+		expectedError string
+	}{
+		{
+			name:   "streamable http",
+			modify: func(mcpServer *mcpserver.MCPServer) {},
+		},
+		{
+			name: "stdio",
+			modify: func(mcpServer *mcpserver.MCPServer) {
+				mcpServer.Runtime.TransportProtocol = serverconfig.TransportProtocolStdio
+			},
+			expectedError: "embedded servers only support the streamablehttp transport protocol",
+		},
+		{
+			name: "admin API",
+			modify: func(mcpServer *mcpserver.MCPServer) {
+				mcpServer.Runtime.Admin = &serverconfig.AdminConfig{Port: 9090}
+			},
+			expectedError: "embedded servers don't support the admin API",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first")))
+			tc.modify(mcpServer)
+
+			logger := zap.NewNop()
+			server, err := NewServer(mcpServer, ServerOptions{Logger: logger})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, logger, mcpServer.Runtime.GetBaseLogger(), "the server should log with the given logger")
+			assert.NotNil(t, server)
+		})
+	}
+}
+
+func TestServerMountedOnMux(t *testing.T) {
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("second")))
+	server, err := NewServer(mcpServer, ServerOptions{})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/mcp", server)
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	res, err := http.Post(httpServer.URL+"/api/mcp", "application/json", nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "the server should not serve requests before it is started")
+
+	require.NoError(t, server.Start(context.Background()))
+	assert.Error(t, server.Start(context.Background()), "the server should only be started once")
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: httpServer.URL + "/api/mcp"}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+	assert.Equal(t, []string{"first", "second"}, listToolNames(t, cs))
+
+	require.NoError(t, server.Stop(context.Background()))
+	require.NoError(t, server.Stop(context.Background()), "stopping a stopped server should do nothing")
+
+	res, err = http.Post(httpServer.URL+"/api/mcp", "application/json", nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "the server should not serve requests once it is stopped")
+}
+
+func TestServerRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first")))
+	mcpServer.Runtime.Audit = &serverconfig.AuditConfig{Sink: serverconfig.AuditSinkFile, Path: path}
+	server, err := NewServer(mcpServer, ServerOptions{})
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	for range 2 {
+		require.NoError(t, server.Start(context.Background()))
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		cs, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+		require.NoError(t, err)
+		_, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "first"})
+		require.NoError(t, err)
+		_ = cs.Close()
+
+		require.NoError(t, server.Stop(context.Background()))
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 2,
+		"the calls should be audited after the server is started again")
+}
@@ -0,0 +1,18 @@
+package runtime
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// ensureErrorDetail describes failed results that don't have an ErrorDetail as internal errors, so that
+// every failed tool call has an error code.
+func ensureErrorDetail(result *mcp.CallToolResult) {
+	if result == nil || !result.IsError {
+		return
+	}
+	if _, ok := invocation.GetErrorDetail(result); !ok {
+		invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeInternal, 0))
+	}
+}
@@ -0,0 +1,120 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func errorCodesTestTool(name, url string, extra string) string {
+	return `- name: ` + name + `
+  description: Get the status of a service
+  inputSchema:
+    type: object
+    properties:
+      service:
+        type: string
+` + extra + `  invocation:
+    http:
+      method: GET
+      url: ` + url + `
+      timeout: 100ms
+`
+}
+
+func TestToolCallErrorCodes(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("service") {
+		case "down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "slow":
+			time.Sleep(time.Second)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	tools := []string{
+		errorCodesTestTool("get_status", backend.URL+"/status", ""),
+		errorCodesTestTool("restart", backend.URL+"/restart", "  requiredScopes:\n    - admin\n"),
+		errorCodesTestTool("get_unreachable", unreachable.URL+"/status", ""),
+	}
+
+	tt := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+		expected  invocation.ErrorDetail
+	}{
+		{
+			name:      "unauthorized call",
+			tool:      "restart",
+			arguments: map[string]any{"service": "api"},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeAuth},
+		},
+		{
+			name:      "invalid arguments",
+			tool:      "get_status",
+			arguments: map[string]any{"service": 42},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeValidation},
+		},
+		{
+			name:      "unreachable backend",
+			tool:      "get_unreachable",
+			arguments: map[string]any{"service": "api"},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeBackendUnavailable, Retryable: true},
+		},
+		{
+			name:      "transient error status",
+			tool:      "get_status",
+			arguments: map[string]any{"service": "down"},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeBackendStatus, Status: http.StatusServiceUnavailable, Retryable: true},
+		},
+		{
+			name:      "error status",
+			tool:      "get_status",
+			arguments: map[string]any{"service": "missing"},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeBackendStatus, Status: http.StatusNotFound},
+		},
+		{
+			name:      "timeout",
+			tool:      "get_status",
+			arguments: map[string]any{"service": "slow"},
+			expected:  invocation.ErrorDetail{Code: invocation.ErrorCodeTimeout, Retryable: true},
+		},
+	}
+
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tools...))
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tc.tool, Arguments: tc.arguments})
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+
+			// the client receives the detail as JSON in the _meta of the result
+			data, err := json.Marshal(result.Meta[invocation.ErrorMetaKey])
+			require.NoError(t, err)
+			var detail invocation.ErrorDetail
+			require.NoError(t, json.Unmarshal(data, &detail))
+			assert.Equal(t, tc.expected, detail)
+		})
+	}
+}
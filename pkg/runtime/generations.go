@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// reloadDrainTimeout is how long the sessions of a previous config are served after the config is
+	// reloaded, before they are closed.
+	reloadDrainTimeout = 5 * time.Minute
+
+	// drainPollInterval is how often a draining generation checks whether all its sessions ended.
+	drainPollInterval = time.Second
+)
+
+// httpGeneration is the handler of the streamable HTTP transport for one config of the server. A new
+// generation replaces it when the config is reloaded.
+type httpGeneration struct {
+	handler  http.Handler
+	manager  *ServerManager
+	sessions *sessionHandler // nil unless sessions are stored
+}
+
+// close closes the sessions of the generation. Stored sessions stay in the store, so that their clients
+// resume them on the next generation.
+func (g *httpGeneration) close() {
+	if g.sessions != nil {
+		g.sessions.closeAll()
+		return
+	}
+	g.manager.closeSessions()
+}
+
+// generationHandler serves requests with the current generation, except the requests of the sessions of
+// previous generations, which are still served by their generation until they end or are closed.
+type generationHandler struct {
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	current  *httpGeneration
+	draining []*httpGeneration
+}
+
+func (h *generationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	g := h.current
+	if sessionID := r.Header.Get(sessionIDHeader); sessionID != "" {
+		for _, d := range h.draining {
+			if d.manager.hasSession(sessionID) {
+				g = d
+				break
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	g.handler.ServeHTTP(w, r)
+}
+
+// manager returns the server manager of the current generation.
+func (h *generationHandler) manager() *ServerManager {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.current.manager
+}
+
+// swap makes g the current generation. New sessions are served by g, and the sessions of the previous
+// generation are drained: they are served by the previous generation until they end, and closed after
+// timeout or once ctx is cancelled.
+func (h *generationHandler) swap(ctx context.Context, g *httpGeneration, timeout time.Duration) {
+	h.mu.Lock()
+	previous := h.current
+	h.current = g
+	h.draining = append(h.draining, previous)
+	h.mu.Unlock()
+
+	go h.drain(ctx, previous, timeout)
+}
+
+func (h *generationHandler) drain(ctx context.Context, g *httpGeneration, timeout time.Duration) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+
+drain:
+	for g.manager.sessionCount() > 0 {
+		select {
+		case <-ctx.Done():
+			// the sessions are closed with the server
+			return
+		case <-deadline:
+			h.logger.Info("Closing the sessions of the previous config",
+				zap.Int("sessions", g.manager.sessionCount()))
+			g.close()
+			break drain
+		case <-ticker.C:
+		}
+	}
+
+	h.mu.Lock()
+	h.draining = slices.DeleteFunc(h.draining, func(d *httpGeneration) bool { return d == g })
+	h.mu.Unlock()
+
+	h.logger.Debug("Drained the sessions of the previous config")
+}
+
+// closeStoredSessions closes the stored sessions of every generation, leaving them in the store.
+func (h *generationHandler) closeStoredSessions() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, g := range append([]*httpGeneration{h.current}, h.draining...) {
+		if g.sessions != nil {
+			g.sessions.closeAll()
+		}
+	}
+}
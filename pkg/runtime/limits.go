@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+// checkArgumentSize returns an error if size exceeds the maximum argument size of limits.
+func checkArgumentSize(limits *serverconfig.LimitsConfig, size int) error {
+	if limits == nil || limits.MaxArgumentBytes <= 0 || size <= limits.MaxArgumentBytes {
+		return nil
+	}
+	return fmt.Errorf("arguments of %d bytes exceed the limit of %d bytes", size, limits.MaxArgumentBytes)
+}
+
+// promptArgumentsSize returns the size of the names and values of the arguments of a prompt.
+func promptArgumentsSize(args map[string]string) int {
+	size := 0
+	for k, v := range args {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// truncateToolResult truncates the content of result that exceeds the maximum response size of limits,
+// and reports whether anything was truncated. Structured content cannot be truncated without breaking
+// it, so it is dropped if it exceeds the limit.
+func truncateToolResult(limits *serverconfig.LimitsConfig, result *mcp.CallToolResult) bool {
+	if !hasResponseLimit(limits) || result == nil {
+		return false
+	}
+
+	truncated := false
+	for i, c := range result.Content {
+		var ok bool
+		if result.Content[i], ok = truncateContent(limits, c); ok {
+			truncated = true
+		}
+	}
+
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err == nil && len(data) > limits.MaxResponseBytes {
+			result.StructuredContent = nil
+			truncated = true
+		}
+	}
+
+	return truncated
+}
+
+// truncatePromptResult truncates the content of the messages of result that exceeds the maximum
+// response size of limits, and reports whether anything was truncated.
+func truncatePromptResult(limits *serverconfig.LimitsConfig, result *mcp.GetPromptResult) bool {
+	if !hasResponseLimit(limits) || result == nil {
+		return false
+	}
+
+	truncated := false
+	for _, m := range result.Messages {
+		if m == nil {
+			continue
+		}
+		var ok bool
+		if m.Content, ok = truncateContent(limits, m.Content); ok {
+			truncated = true
+		}
+	}
+
+	return truncated
+}
+
+// truncateResourceResult truncates the contents of result that exceed the maximum response size of limits,
+// and reports whether anything was truncated.
+func truncateResourceResult(limits *serverconfig.LimitsConfig, result *mcp.ReadResourceResult) bool {
+	if !hasResponseLimit(limits) || result == nil {
+		return false
+	}
+
+	truncated := false
+	for _, rc := range result.Contents {
+		if truncateResourceContents(limits, rc) {
+			truncated = true
+		}
+	}
+
+	return truncated
+}
+
+func hasResponseLimit(limits *serverconfig.LimitsConfig) bool {
+	return limits != nil && limits.MaxResponseBytes > 0
+}
+
+// truncateContent returns c, or its replacement if it exceeds the maximum response size of limits.
+// Binary content is replaced with a text marker, whatever the truncation strategy.
+func truncateContent(limits *serverconfig.LimitsConfig, c mcp.Content) (mcp.Content, bool) {
+	switch c := c.(type) {
+	case *mcp.TextContent:
+		text, ok := truncateText(limits, c.Text)
+		if !ok {
+			return c, false
+		}
+		return &mcp.TextContent{Text: text, Meta: c.Meta, Annotations: c.Annotations}, true
+	case *mcp.ImageContent:
+		if len(c.Data) <= limits.MaxResponseBytes {
+			return c, false
+		}
+		return &mcp.TextContent{Text: omittedMarker(limits, len(c.Data), c.MIMEType)}, true
+	case *mcp.AudioContent:
+		if len(c.Data) <= limits.MaxResponseBytes {
+			return c, false
+		}
+		return &mcp.TextContent{Text: omittedMarker(limits, len(c.Data), c.MIMEType)}, true
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil {
+			return c, false
+		}
+		rc := *c.Resource
+		if !truncateResourceContents(limits, &rc) {
+			return c, false
+		}
+		return &mcp.EmbeddedResource{Resource: &rc, Meta: c.Meta, Annotations: c.Annotations}, true
+	default:
+		return c, false
+	}
+}
+
+func truncateResourceContents(limits *serverconfig.LimitsConfig, rc *mcp.ResourceContents) bool {
+	if rc == nil {
+		return false
+	}
+
+	if len(rc.Blob) > limits.MaxResponseBytes {
+		rc.Text = omittedMarker(limits, len(rc.Blob), rc.MIMEType)
+		rc.Blob = nil
+		rc.MIMEType = "text/plain"
+		return true
+	}
+
+	text, ok := truncateText(limits, rc.Text)
+	if ok {
+		rc.Text = text
+	}
+	return ok
+}
+
+// truncateText truncates text to the maximum response size of limits according to its truncation
+// strategy, and reports whether it was truncated. Text is only cut at UTF-8 character boundaries.
+func truncateText(limits *serverconfig.LimitsConfig, text string) (string, bool) {
+	size := len(text)
+	if size <= limits.MaxResponseBytes {
+		return text, false
+	}
+
+	switch limits.TruncationStrategy {
+	case serverconfig.TruncationStrategySummary:
+		return omittedMarker(limits, size, ""), true
+	case serverconfig.TruncationStrategyTail:
+		start := size - limits.MaxResponseBytes
+		for start < size && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		return fmt.Sprintf("[truncated: first %d of %d bytes omitted]\n", start, size) + text[start:], true
+	default:
+		end := limits.MaxResponseBytes
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		return text[:end] + fmt.Sprintf("\n[truncated: last %d of %d bytes omitted]", size-end, size), true
+	}
+}
+
+func omittedMarker(limits *serverconfig.LimitsConfig, size int, mimeType string) string {
+	if mimeType != "" {
+		return fmt.Sprintf("[%s content of %d bytes omitted: exceeds the limit of %d bytes]", mimeType, size, limits.MaxResponseBytes)
+	}
+	return fmt.Sprintf("[content of %d bytes omitted: exceeds the limit of %d bytes]", size, limits.MaxResponseBytes)
+}
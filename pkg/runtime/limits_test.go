@@ -0,0 +1,176 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+func TestTruncateText(t *testing.T) {
+	tt := []struct {
+		name              string
+		strategy          string
+		maxResponseBytes  int
+		text              string
+		expectedText      string
+		expectedTruncated bool
+	}{
+		{
+			name:             "within limit",
+			strategy:         serverconfig.TruncationStrategyHead,
+			maxResponseBytes: 5,
+			text:             "hello",
+			expectedText:     "hello",
+		},
+		{
+			name:              "head",
+			strategy:          serverconfig.TruncationStrategyHead,
+			maxResponseBytes:  5,
+			text:              "hello world",
+			expectedText:      "hello\n[truncated: last 6 of 11 bytes omitted]",
+			expectedTruncated: true,
+		},
+		{
+			name:              "tail",
+			strategy:          serverconfig.TruncationStrategyTail,
+			maxResponseBytes:  5,
+			text:              "hello world",
+			expectedText:      "[truncated: first 6 of 11 bytes omitted]\nworld",
+			expectedTruncated: true,
+		},
+		{
+			name:              "summary",
+			strategy:          serverconfig.TruncationStrategySummary,
+			maxResponseBytes:  5,
+			text:              "hello world",
+			expectedText:      "[content of 11 bytes omitted: exceeds the limit of 5 bytes]",
+			expectedTruncated: true,
+		},
+		{
+			name:              "head does not split characters",
+			strategy:          serverconfig.TruncationStrategyHead,
+			maxResponseBytes:  2,
+			text:              "héllo",
+			expectedText:      "h\n[truncated: last 5 of 6 bytes omitted]",
+			expectedTruncated: true,
+		},
+		{
+			name:              "tail does not split characters",
+			strategy:          serverconfig.TruncationStrategyTail,
+			maxResponseBytes:  4,
+			text:              "öllo",
+			expectedText:      "[truncated: first 2 of 5 bytes omitted]\nllo",
+			expectedTruncated: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := &serverconfig.LimitsConfig{
+				MaxResponseBytes:   tc.maxResponseBytes,
+				TruncationStrategy: tc.strategy,
+			}
+
+			text, truncated := truncateText(limits, tc.text)
+			assert.Equal(t, tc.expectedText, text)
+			assert.Equal(t, tc.expectedTruncated, truncated)
+		})
+	}
+}
+
+func TestCheckArgumentSize(t *testing.T) {
+	tt := []struct {
+		name          string
+		limits        *serverconfig.LimitsConfig
+		size          int
+		expectedError string
+	}{
+		{
+			name: "no limits",
+			size: 1 << 20,
+		},
+		{
+			name:   "limit disabled",
+			limits: &serverconfig.LimitsConfig{},
+			size:   1 << 20,
+		},
+		{
+			name:   "within limit",
+			limits: &serverconfig.LimitsConfig{MaxArgumentBytes: 10},
+			size:   10,
+		},
+		{
+			name:          "exceeds limit",
+			limits:        &serverconfig.LimitsConfig{MaxArgumentBytes: 10},
+			size:          11,
+			expectedError: "arguments of 11 bytes exceed the limit of 10 bytes",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkArgumentSize(tc.limits, tc.size)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTruncateToolResult(t *testing.T) {
+	limits := &serverconfig.LimitsConfig{
+		MaxResponseBytes:   10,
+		TruncationStrategy: serverconfig.TruncationStrategySummary,
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("a", 100)}}}
+		assert.False(t, truncateToolResult(nil, result))
+		assert.Equal(t, strings.Repeat("a", 100), result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("text, image and structured content", func(t *testing.T) {
+		result := &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "short"},
+				&mcp.TextContent{Text: strings.Repeat("a", 100)},
+				&mcp.ImageContent{Data: make([]byte, 100), MIMEType: "image/png"},
+			},
+			StructuredContent: map[string]any{"data": strings.Repeat("a", 100)},
+		}
+
+		assert.True(t, truncateToolResult(limits, result))
+		assert.Equal(t, []mcp.Content{
+			&mcp.TextContent{Text: "short"},
+			&mcp.TextContent{Text: "[content of 100 bytes omitted: exceeds the limit of 10 bytes]"},
+			&mcp.TextContent{Text: "[image/png content of 100 bytes omitted: exceeds the limit of 10 bytes]"},
+		}, result.Content)
+		assert.Nil(t, result.StructuredContent)
+	})
+}
+
+func TestTruncateResourceResult(t *testing.T) {
+	limits := &serverconfig.LimitsConfig{
+		MaxResponseBytes:   4,
+		TruncationStrategy: serverconfig.TruncationStrategyHead,
+	}
+
+	result := &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: "file:///a.txt", MIMEType: "text/plain", Text: "abcdefgh"},
+			{URI: "file:///a.bin", MIMEType: "application/octet-stream", Blob: make([]byte, 8)},
+		},
+	}
+
+	assert.True(t, truncateResourceResult(limits, result))
+	assert.Equal(t, []*mcp.ResourceContents{
+		{URI: "file:///a.txt", MIMEType: "text/plain", Text: "abcd\n[truncated: last 4 of 8 bytes omitted]"},
+		{URI: "file:///a.bin", MIMEType: "text/plain", Text: "[application/octet-stream content of 8 bytes omitted: exceeds the limit of 4 bytes]"},
+	}, result.Contents)
+}
@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// runtimeListenerName is the name of the listener of the transport of the runtime.
+const runtimeListenerName = "runtime"
+
+// listener is one of the transports a server is run on.
+type listener struct {
+	name   string
+	server *mcpserver.MCPServer
+	tools  []string // names of the served tools, all tools if empty
+}
+
+// runListeners runs mcpServer on the transport of its runtime and on every additional listener
+// at the same time, reporting their status to status and replacing their servers when reloader reloads the
+// config, if they are not nil. When any listener stops, all the others are shut down, and the errors of
+// every listener are returned once they have all stopped.
+func runListeners(ctx context.Context, mcpServer *mcpserver.MCPServer, source *toolDefinitionsSource, status *serverStatus, reloader *configReloader) error {
+	logger := mcpServer.Runtime.GetBaseLogger()
+
+	listeners := []*listener{{name: runtimeListenerName, server: mcpServer}}
+	for _, l := range mcpServer.Runtime.Listeners {
+		listeners = append(listeners, newListener(mcpServer, l))
+	}
+
+	logger.Info("Running server on multiple listeners", zap.Int("num_listeners", len(listeners)))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			err := runTransport(ctx, l, source, status, reloader)
+			if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+				// stopped by the shutdown of the listeners
+				err = nil
+			}
+			if err != nil {
+				err = fmt.Errorf("listener '%s' failed: %w", l.name, err)
+			}
+
+			if ctx.Err() == nil {
+				logger.Info("Listener stopped, shutting down the other listeners", zap.String("listener", l.name))
+			}
+			cancel()
+
+			errCh <- err
+		}()
+	}
+
+	var err error
+	for range listeners {
+		err = errors.Join(err, <-errCh)
+	}
+
+	return err
+}
+
+// newListener creates the listener for an additional transport of mcpServer.
+func newListener(mcpServer *mcpserver.MCPServer, l *serverconfig.ListenerConfig) *listener {
+	return &listener{
+		name: l.Name,
+		server: &mcpserver.MCPServer{
+			MCPToolDefinitions: toolSubset(mcpServer.MCPToolDefinitions, l.Tools),
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: mcpServer.Runtime.ForListener(l),
+			},
+		},
+		tools: l.Tools,
+	}
+}
+
+// reloaded returns the server of l in next, the reloaded config of the server.
+func (l *listener) reloaded(next *mcpserver.MCPServer) *mcpserver.MCPServer {
+	if l.name == runtimeListenerName {
+		return next
+	}
+
+	for _, lc := range next.Runtime.Listeners {
+		if lc.Name == l.name {
+			return newListener(next, lc).server
+		}
+	}
+
+	// the listeners cannot change without a restart, so this is never reached
+	return l.server
+}
+
+// toolSubset returns defs with only the named tools. defs is returned unchanged if names is empty.
+func toolSubset(defs definitions.MCPToolDefinitions, names []string) definitions.MCPToolDefinitions {
+	if len(names) == 0 {
+		return defs
+	}
+
+	tools := make([]*definitions.Tool, 0, len(names))
+	for _, t := range defs.Tools {
+		if slices.Contains(names, t.Name) {
+			tools = append(tools, t)
+		}
+	}
+	defs.Tools = tools
+
+	return defs
+}
@@ -0,0 +1,196 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+func TestToolSubset(t *testing.T) {
+	defs := definitions.MCPToolDefinitions{
+		Tools: []*definitions.Tool{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}
+
+	tt := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{
+			name:     "no names keeps every tool",
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "subset keeps definition order",
+			names:    []string{"c", "a"},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "unknown names are ignored",
+			names:    []string{"b", "missing"},
+			expected: []string{"b"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			subset := toolSubset(defs, tc.names)
+			assert.Equal(t, tc.expected, toolNames(subset.Tools), "tools of the subset should match")
+			assert.Len(t, defs.Tools, 3, "the original definitions should not be modified")
+		})
+	}
+}
+
+func TestRunListeners(t *testing.T) {
+	t.Run("every listener serves its tools", func(t *testing.T) {
+		mainPort, adminPort := freePort(t), freePort(t)
+		mcpServer := testListenersServer(mainPort, &serverconfig.ListenerConfig{
+			Name:              "admin",
+			TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+			StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+				Port: adminPort,
+			},
+			Tools: []string{"admin_tool"},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- DoRunServer(ctx, mcpServer)
+		}()
+
+		assert.ElementsMatch(t, []string{"admin_tool", "public_tool"}, listTools(t, mainPort), "runtime should serve every tool")
+		assert.Equal(t, []string{"admin_tool"}, listTools(t, adminPort), "listener should serve its tools only")
+
+		cancel()
+		select {
+		case err := <-errCh:
+			assert.NoError(t, err, "shutting down the listeners should not fail")
+		case <-time.After(5 * time.Second):
+			t.Fatal("listeners did not shut down")
+		}
+	})
+
+	t.Run("a failing listener stops the others", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err, "reserving a port should not fail")
+		defer func() {
+			_ = busy.Close()
+		}()
+
+		mcpServer := testListenersServer(freePort(t), &serverconfig.ListenerConfig{
+			Name:              "busy",
+			TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+			StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+				Port: busy.Addr().(*net.TCPAddr).Port,
+			},
+		})
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- DoRunServer(context.Background(), mcpServer)
+		}()
+
+		select {
+		case err := <-errCh:
+			assert.ErrorContains(t, err, "listener 'busy' failed", "the error of the failing listener should be returned")
+		case <-time.After(5 * time.Second):
+			t.Fatal("listeners did not shut down")
+		}
+	})
+}
+
+func testListenersServer(port int, listeners ...*serverconfig.ListenerConfig) *mcpserver.MCPServer {
+	newTool := func(name string) *definitions.Tool {
+		return &definitions.Tool{
+			Name:        name,
+			Description: "test tool",
+			InputSchema: &jsonschema.Schema{Type: invocation.JsonSchemaTypeObject},
+			InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+				Type: httpinvocation.InvocationType,
+				Config: &httpinvocation.HttpInvocationConfig{
+					URL:    "http://localhost:1/test",
+					Method: http.MethodGet,
+				},
+			},
+		}
+	}
+
+	return &mcpserver.MCPServer{
+		MCPToolDefinitions: definitions.MCPToolDefinitions{
+			Name:    "test-server",
+			Version: "1.0.0",
+			Tools:   []*definitions.Tool{newTool("public_tool"), newTool("admin_tool")},
+		},
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+				StreamableHTTPConfig: &serverconfig.StreamableHTTPConfig{
+					Port: port,
+				},
+				LoggingConfig: &logging.LoggingConfig{Level: "error"},
+				Listeners:     listeners,
+			},
+		},
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "finding a free port should not fail")
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close(), "releasing the port should not fail")
+
+	return port
+}
+
+// listTools connects to the server listening on port, retrying until it is ready, and returns the
+// names of its tools.
+func listTools(t *testing.T, port int) []string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	transport := &mcp.StreamableClientTransport{Endpoint: fmt.Sprintf("http://127.0.0.1:%d/mcp", port)}
+
+	var session *mcp.ClientSession
+	require.Eventually(t, func() bool {
+		var err error
+		session, err = client.Connect(ctx, transport, nil)
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond, "connecting to the server should succeed")
+	defer func() {
+		_ = session.Close()
+	}()
+
+	res, err := session.ListTools(ctx, nil)
+	require.NoError(t, err, "listing tools should not fail")
+
+	names := make([]string, len(res.Tools))
+	for i, tool := range res.Tools {
+		names[i] = tool.Name
+	}
+
+	return names
+}
@@ -0,0 +1,462 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/converter/openapi"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// openAPIFetchTimeout is how long fetching the OpenAPI document of the server config may take.
+const openAPIFetchTimeout = 30 * time.Second
+
+// toolDefinitionsSource combines the tool definitions of the MCP file with the tools imported from the
+// OpenAPI document and the upstream MCP servers of the server config, and passes them to the reload functions
+// of the transports every time any of them changes.
+type toolDefinitionsSource struct {
+	mu       sync.Mutex
+	logger   *zap.Logger
+	config   serverconfig.MCPServerConfig // config of the running server, the MCP file is validated with
+	file     definitions.MCPToolDefinitions
+	fileHash string            // hash of the definitions of the MCP file, before their validation
+	sources  []*importedSource // sources of the imported tools, in the order they are served
+	reloads  []func(definitions.MCPToolDefinitions) error
+}
+
+// importedSource holds the tools imported from a source, and how they are served when they have the same
+// name as a tool already served.
+type importedSource struct {
+	// key identifies the source, e.g. upstream:github
+	key string
+
+	// name of the source, prefixing the names of the renamed tools
+	name string
+
+	// onConflict is the conflict policy of the source, one of the serverconfig.ConflictPolicy values
+	onConflict string
+
+	tools []*definitions.Tool
+}
+
+// newToolDefinitionsSource imports the tools of the OpenAPI document and of the upstream MCP servers of
+// mcpServer, if any, adding them to its tools. Until ctx is cancelled, the definitions are then kept in sync
+// with the MCP file at watchPath if it is not empty, with the document if it has a refresh interval, and with
+// the upstream servers when they notify that their tools changed. It returns nil if none of them is set.
+func newToolDefinitionsSource(ctx context.Context, mcpServer *mcpserver.MCPServer, watchPath string) (*toolDefinitionsSource, error) {
+	ref := mcpServer.OpenAPIRef
+	if watchPath == "" && ref == nil && len(mcpServer.Upstreams) == 0 {
+		return nil, nil
+	}
+
+	logger := mcpServer.Runtime.GetBaseLogger()
+	s := &toolDefinitionsSource{
+		logger:   logger,
+		config:   mcpServer.MCPServerConfig,
+		file:     mcpServer.MCPToolDefinitions,
+		fileHash: configHash(mcpServer.MCPToolDefinitions),
+	}
+
+	if ref != nil {
+		importer, err := newOpenAPIImporter(mcpServer.Runtime, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		tools, err := importer.importTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import tools from OpenAPI document %s: %w", ref.Source, err)
+		}
+		logger.Info(fmt.Sprintf("Imported %d tools from %s", len(tools), ref.Source))
+		s.addSource(&importedSource{key: importer.source, name: "openapi", onConflict: ref.OnConflict, tools: tools})
+
+		if ref.RefreshInterval != "" {
+			interval, err := time.ParseDuration(ref.RefreshInterval)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OpenAPI refresh interval: %w", err)
+			}
+			go s.refreshOpenAPI(ctx, importer, interval)
+		}
+	}
+
+	for _, upstream := range mcpServer.Upstreams {
+		importer, err := newUpstreamImporter(mcpServer.Runtime, upstream)
+		if err != nil {
+			return nil, err
+		}
+
+		tools, err := importer.importTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import tools from upstream MCP server %s: %w", upstream.Name, err)
+		}
+		logger.Info(fmt.Sprintf("Imported %d tools from upstream MCP server %s", len(tools), upstream.Name))
+		s.addSource(&importedSource{key: importer.source(), name: upstream.Name, onConflict: upstream.OnConflict, tools: tools})
+
+		if err := proxy.OnToolListChanged(upstream.ProxyConfig(), func() { s.refreshUpstream(ctx, importer) }); err != nil {
+			return nil, err
+		}
+	}
+
+	defs, err := s.definitions(s.file, s.sources)
+	if err != nil {
+		return nil, err
+	}
+	mcpServer.MCPToolDefinitions = defs
+
+	if watchPath != "" {
+		go watchToolDefinitions(ctx, watchPath, logger, s.setFile)
+	}
+
+	return s, nil
+}
+
+// onChange registers a function called with the new definitions every time they change.
+func (s *toolDefinitionsSource) onChange(reload func(definitions.MCPToolDefinitions) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloads = append(s.reloads, reload)
+}
+
+// setFile replaces the definitions of the MCP file, unless they didn't change. They are not replaced if they
+// are invalid with the config of the running server, or if they conflict with the imported tools of a source
+// whose conflict policy is error.
+func (s *toolDefinitionsSource) setFile(file definitions.MCPToolDefinitions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the changes made through the admin API are already served when the watcher reads the file
+	hash := configHash(file)
+	if hash == s.fileHash {
+		return nil
+	}
+
+	defs, err := s.checkFile(file)
+	if err != nil {
+		return fmt.Errorf("keeping the current definitions: %w", err)
+	}
+
+	s.file, s.fileHash = file, hash
+	return s.reload(defs)
+}
+
+// writeFile checks the definitions of the MCP file like setFile and, if they can be served, saves them with
+// write and replaces the definitions of the MCP file with them. An invalidDefinitionsError is returned if
+// they can't be served.
+func (s *toolDefinitionsSource) writeFile(file definitions.MCPToolDefinitions, write func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := configHash(file)
+	defs, err := s.checkFile(file)
+	if err != nil {
+		return &invalidDefinitionsError{err: err}
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	s.file, s.fileHash = file, hash
+	return s.reload(defs)
+}
+
+// replaceFile replaces the definitions of the MCP file and the config of the running server, already validated
+// together, without passing them to the reload functions, and returns them with the imported tools. They are
+// not replaced if they conflict with the imported tools of a source whose conflict policy is error.
+func (s *toolDefinitionsSource) replaceFile(file definitions.MCPToolDefinitions, config serverconfig.MCPServerConfig) (definitions.MCPToolDefinitions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defs, err := s.definitions(file, s.sources)
+	if err != nil {
+		return definitions.MCPToolDefinitions{}, err
+	}
+
+	s.file, s.fileHash = file, configHash(file)
+	s.config = config
+	return defs, nil
+}
+
+// checkFile validates the definitions of the MCP file with the config of the running server, like the server
+// is validated when it starts, so that a reload can't serve tools the server would refuse to start with, and
+// returns them with the imported tools. It must be called with mu locked.
+func (s *toolDefinitionsSource) checkFile(file definitions.MCPToolDefinitions) (definitions.MCPToolDefinitions, error) {
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: file,
+		MCPServerConfig:    s.config,
+	}
+	if err := mcpServer.Validate(invocationValidator(mcpServer)); err != nil {
+		return definitions.MCPToolDefinitions{}, fmt.Errorf("invalid MCP file: %w", err)
+	}
+
+	return s.definitions(file, s.sources)
+}
+
+// addSource adds a source of imported tools, served after those of the sources added before it.
+func (s *toolDefinitionsSource) addSource(source *importedSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sources = append(s.sources, source)
+}
+
+// setImported replaces the tools imported from the source with the key, returning whether they changed.
+// They are not replaced if they conflict with the served tools and the conflict policy of the source is
+// error.
+func (s *toolDefinitionsSource) setImported(key string, tools []*definitions.Tool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.sources, func(source *importedSource) bool { return source.key == key })
+	if i < 0 {
+		return false, fmt.Errorf("unknown source %s", key)
+	}
+	if equalTools(s.sources[i].tools, tools) {
+		return false, nil
+	}
+
+	updated := *s.sources[i]
+	updated.tools = tools
+	sources := slices.Clone(s.sources)
+	sources[i] = &updated
+
+	defs, err := s.definitions(s.file, sources)
+	if err != nil {
+		return false, fmt.Errorf("keeping the current tools: %w", err)
+	}
+
+	s.sources = sources
+	return true, s.reload(defs)
+}
+
+func (s *toolDefinitionsSource) reload(defs definitions.MCPToolDefinitions) error {
+	var err error
+	for _, reload := range s.reloads {
+		err = errors.Join(err, reload(defs))
+	}
+
+	return err
+}
+
+// definitions returns the definitions of file with the tools imported from sources, in order. Imported
+// tools named like a tool of the MCP file or of a previous source are handled with the conflict policy of
+// their source: they are skipped by default, renamed with the name of the source as prefix, or override
+// the served tool. An error is returned if the policy is error.
+func (s *toolDefinitionsSource) definitions(file definitions.MCPToolDefinitions, sources []*importedSource) (definitions.MCPToolDefinitions, error) {
+	defs := file
+	if len(sources) == 0 {
+		return defs, nil
+	}
+
+	tools := slices.Clone(defs.Tools)
+	origins := make(map[string]string, len(tools)) // source of the served tools, by name
+	for _, t := range tools {
+		origins[t.Name] = "the MCP file"
+	}
+
+	for _, source := range sources {
+		for _, t := range source.tools {
+			origin, conflict := origins[t.Name]
+			if !conflict {
+				origins[t.Name] = source.key
+				tools = append(tools, t)
+				continue
+			}
+
+			switch source.onConflict {
+			case serverconfig.ConflictPolicyError:
+				return definitions.MCPToolDefinitions{}, fmt.Errorf("tool %s of %s has the same name as a tool of %s", t.Name, source.key, origin)
+			case serverconfig.ConflictPolicyOverride:
+				s.logger.Warn("Imported tool has the same name as a served tool, overriding it",
+					zap.String("tool_name", t.Name),
+					zap.String("source", source.key),
+					zap.String("overridden_source", origin))
+				i := slices.IndexFunc(tools, func(served *definitions.Tool) bool { return served.Name == t.Name })
+				tools[i] = t
+				origins[t.Name] = source.key
+			case serverconfig.ConflictPolicyRename:
+				renamed := *t
+				renamed.Name = uniqueToolName(source.name+"_"+t.Name, origins)
+				s.logger.Warn("Imported tool has the same name as a served tool, renaming it",
+					zap.String("tool_name", t.Name),
+					zap.String("renamed_to", renamed.Name),
+					zap.String("source", source.key),
+					zap.String("served_source", origin))
+				origins[renamed.Name] = source.key
+				tools = append(tools, &renamed)
+			default:
+				s.logger.Warn("Imported tool has the same name as a served tool, serving the other tool",
+					zap.String("tool_name", t.Name),
+					zap.String("source", source.key),
+					zap.String("served_source", origin))
+			}
+		}
+	}
+	defs.Tools = tools
+
+	return defs, nil
+}
+
+// uniqueToolName returns name, with a numeric suffix if a tool with the name is already served.
+func uniqueToolName(name string, served map[string]string) string {
+	if _, ok := served[name]; !ok {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", name, i)
+		if _, ok := served[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// refreshOpenAPI imports the tools of the OpenAPI document again every interval, until ctx is cancelled.
+// Documents that fail to be fetched or parsed are logged and skipped, so the server keeps serving the last
+// imported tools.
+func (s *toolDefinitionsSource) refreshOpenAPI(ctx context.Context, importer *openAPIImporter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tools, err := importer.importTools(ctx)
+			if err != nil {
+				s.logger.Error("Failed to refresh OpenAPI document, keeping the current tools",
+					zap.String("source", importer.source),
+					zap.Error(err))
+				continue
+			}
+
+			changed, err := s.setImported(importer.source, tools)
+			if err != nil {
+				s.logger.Error("OpenAPI tools refreshed with some errors",
+					zap.String("source", importer.source),
+					zap.Error(err))
+				continue
+			}
+
+			if changed {
+				s.logger.Info("OpenAPI tools refreshed",
+					zap.String("source", importer.source),
+					zap.Int("num_tools", len(tools)))
+			}
+		}
+	}
+}
+
+// openAPIImporter imports the tools of the OpenAPI document of a server config.
+type openAPIImporter struct {
+	source string
+	prefix string
+	opts   openapi.ImportOptions
+	client *http.Client
+	logger *zap.Logger
+}
+
+func newOpenAPIImporter(runtime *serverconfig.ServerRuntime, ref *serverconfig.OpenAPIRefConfig) (*openAPIImporter, error) {
+	client, err := runtime.GetHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for the OpenAPI document: %w", err)
+	}
+
+	return &openAPIImporter{
+		source: ref.Source,
+		prefix: ref.Prefix,
+		opts: openapi.ImportOptions{
+			Host:         ref.Host,
+			Tags:         ref.Tags,
+			OperationIDs: ref.OperationIDs,
+		},
+		client: client,
+		logger: runtime.GetBaseLogger(),
+	}, nil
+}
+
+// importTools fetches the document and imports its tools, named with the prefix of the document.
+// Operations that can't be imported are logged and skipped.
+func (i *openAPIImporter) importTools(ctx context.Context) ([]*definitions.Tool, error) {
+	document, err := i.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, err := openapi.ImportTools(document, i.opts)
+	if tools == nil {
+		return nil, err
+	}
+	if err != nil {
+		i.logger.Warn("Some operations of the OpenAPI document were not imported",
+			zap.String("source", i.source),
+			zap.Error(err))
+	}
+
+	for _, t := range tools {
+		t.Name = i.prefix + t.Name
+	}
+
+	return tools, nil
+}
+
+// fetch reads the document from its URL, or from the file system if it isn't an http or https URL.
+func (i *openAPIImporter) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(i.source, "http://") && !strings.HasPrefix(i.source, "https://") {
+		document, err := os.ReadFile(i.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+		}
+		return document, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, openAPIFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for OpenAPI document: %w", err)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: unexpected status %s", resp.Status)
+	}
+
+	document, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+
+	return document, nil
+}
+
+// equalTools returns whether two lists of tools have the same definitions.
+func equalTools(a, b []*definitions.Tool) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
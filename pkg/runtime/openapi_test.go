@@ -0,0 +1,244 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// openAPITestDocument returns an OpenAPI document of an API served at serverURL with an operation for
+// every path, tagged with the first segment of the path.
+func openAPITestDocument(serverURL string, paths ...string) string {
+	pathItems := ""
+	for i, path := range paths {
+		if i > 0 {
+			pathItems += ","
+		}
+		pathItems += fmt.Sprintf(`%q: {"get": {"operationId": "get%d", "tags": [%q], "description": "Get %s"}}`, path, i, path[1:5], path)
+	}
+	return fmt.Sprintf(`{"openapi": "3.0.0", "info": {"title": "test", "version": "1.0.0"}, "servers": [{"url": %q}], "paths": {%s}}`, serverURL, pathItems)
+}
+
+func TestToolDefinitionsSourceDefinitions(t *testing.T) {
+	tt := []struct {
+		name          string
+		fileTools     []string
+		importedTools [][]string // by source
+		onConflict    []string   // by source
+		expectedTools []string
+		expectedError string
+	}{
+		{
+			name:          "no imported tools",
+			fileTools:     []string{"first"},
+			expectedTools: []string{"first"},
+		},
+		{
+			name:          "imported tools are added",
+			fileTools:     []string{"first"},
+			importedTools: [][]string{{"get_pets", "get_owners"}},
+			expectedTools: []string{"first", "get_owners", "get_pets"},
+		},
+		{
+			name:          "tools of the MCP file win",
+			fileTools:     []string{"first", "get_pets"},
+			importedTools: [][]string{{"get_pets", "get_owners"}},
+			expectedTools: []string{"first", "get_owners", "get_pets"},
+		},
+		{
+			name:          "tools of several sources are added",
+			fileTools:     []string{"first"},
+			importedTools: [][]string{{"get_pets"}, {"github_create_issue", "get_pets"}},
+			expectedTools: []string{"first", "get_pets", "github_create_issue"},
+		},
+		{
+			name:          "conflicts are errors",
+			fileTools:     []string{"first", "get_pets"},
+			importedTools: [][]string{{"get_owners"}, {"get_pets"}},
+			onConflict:    []string{"", serverconfig.ConflictPolicyError},
+			expectedError: "tool get_pets of source1 has the same name as a tool of the MCP file",
+		},
+		{
+			name:          "conflicting tools are renamed",
+			fileTools:     []string{"first", "get_pets", "source0_get_pets"},
+			importedTools: [][]string{{"get_pets", "first"}},
+			onConflict:    []string{serverconfig.ConflictPolicyRename},
+			expectedTools: []string{"first", "get_pets", "source0_first", "source0_get_pets", "source0_get_pets_2"},
+		},
+		{
+			name:          "conflicting tools override the served tools",
+			fileTools:     []string{"first"},
+			importedTools: [][]string{{"get_pets"}, {"get_pets", "first"}},
+			onConflict:    []string{"", serverconfig.ConflictPolicyOverride},
+			expectedTools: []string{"first", "get_pets"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			fileTools := make([]string, 0, len(tc.fileTools))
+			for _, name := range tc.fileTools {
+				fileTools = append(fileTools, reloadTestTool(name))
+			}
+
+			s := &toolDefinitionsSource{
+				logger: zap.NewNop(),
+				file:   loadTestDefinitions(t, fileTools...),
+			}
+			for i, names := range tc.importedTools {
+				source := &importedSource{key: fmt.Sprintf("source%d", i), name: fmt.Sprintf("source%d", i)}
+				if i < len(tc.onConflict) {
+					source.onConflict = tc.onConflict[i]
+				}
+				for _, name := range names {
+					source.tools = append(source.tools, &definitions.Tool{Name: name, Description: source.key})
+				}
+				s.addSource(source)
+			}
+
+			defs, err := s.definitions(s.file, s.sources)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTools, toolNames(defs.Tools))
+
+			if slices.Contains(tc.onConflict, serverconfig.ConflictPolicyOverride) {
+				for _, tool := range defs.Tools {
+					assert.Equal(t, "source1", tool.Description, tool.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestToolDefinitionsSourceRefreshesOpenAPI(t *testing.T) {
+	var document atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(document.Load().(string)))
+	}))
+	defer srv.Close()
+	document.Store(openAPITestDocument(srv.URL, "/pets", "/owners"))
+
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: loadTestDefinitions(t, reloadTestTool("first")),
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{},
+			OpenAPIRef: &serverconfig.OpenAPIRefConfig{
+				Source:          srv.URL,
+				Tags:            []string{"pets"},
+				RefreshInterval: "50ms",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source, err := newToolDefinitionsSource(ctx, mcpServer, "")
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, []string{"first", "get_pets"}, toolNames(mcpServer.Tools), "imported tools should be served at startup")
+
+	reloaded := make(chan definitions.MCPToolDefinitions, 10)
+	source.onChange(func(defs definitions.MCPToolDefinitions) error {
+		reloaded <- defs
+		return nil
+	})
+
+	document.Store(openAPITestDocument(srv.URL, "/pets", "/pets/search", "/owners"))
+
+	select {
+	case defs := <-reloaded:
+		assert.Equal(t, []string{"first", "get_pets", "get_pets-search"}, toolNames(defs.Tools))
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for refresh")
+	}
+
+	// an unreachable document keeps the current tools
+	srv.Close()
+	select {
+	case defs := <-reloaded:
+		t.Fatalf("unexpected reload with tools %v", toolNames(defs.Tools))
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestNewToolDefinitionsSourceFailsWithoutDocument(t *testing.T) {
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: loadTestDefinitions(t, reloadTestTool("first")),
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime:    &serverconfig.ServerRuntime{},
+			OpenAPIRef: &serverconfig.OpenAPIRefConfig{Source: "does-not-exist.json"},
+		},
+	}
+
+	_, err := newToolDefinitionsSource(context.Background(), mcpServer, "")
+	assert.ErrorContains(t, err, "failed to import tools from OpenAPI document does-not-exist.json")
+}
+
+func TestNewToolDefinitionsSourcePrefixesOpenAPITools(t *testing.T) {
+	tt := []struct {
+		name          string
+		ref           serverconfig.OpenAPIRefConfig
+		expectedTools []string
+		expectedError string
+	}{
+		{
+			name:          "imported tools are prefixed",
+			ref:           serverconfig.OpenAPIRefConfig{Prefix: "petstore_"},
+			expectedTools: []string{"get_pets", "petstore_get_owners", "petstore_get_pets"},
+		},
+		{
+			name:          "conflicting tools are renamed",
+			ref:           serverconfig.OpenAPIRefConfig{OnConflict: serverconfig.ConflictPolicyRename},
+			expectedTools: []string{"get_owners", "get_pets", "openapi_get_pets"},
+		},
+		{
+			name:          "conflicts fail the startup",
+			ref:           serverconfig.OpenAPIRefConfig{OnConflict: serverconfig.ConflictPolicyError},
+			expectedError: "has the same name as a tool of the MCP file",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "openapi.json")
+			require.NoError(t, os.WriteFile(path, []byte(openAPITestDocument("http://localhost:8080", "/pets", "/owners")), 0644))
+
+			ref := tc.ref
+			ref.Source = path
+			mcpServer := &mcpserver.MCPServer{
+				MCPToolDefinitions: loadTestDefinitions(t, reloadTestTool("get_pets")),
+				MCPServerConfig: serverconfig.MCPServerConfig{
+					Runtime:    &serverconfig.ServerRuntime{},
+					OpenAPIRef: &ref,
+				},
+			}
+
+			_, err := newToolDefinitionsSource(context.Background(), mcpServer, "")
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTools, toolNames(mcpServer.Tools))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/policy"
+)
+
+// authorizeCall evaluates the authorization policy of engine for a call of tool with arguments. It returns the
+// arguments the tool is called with, or a result stopping the call, with denied set if the policy denies it.
+// Calls are stopped if the policy cannot be evaluated. Every call is authorized if engine is nil.
+func authorizeCall(ctx context.Context, engine policy.Engine, tool *definitions.Tool, arguments json.RawMessage) (json.RawMessage, *mcp.CallToolResult, bool) {
+	if engine == nil {
+		return arguments, nil, false
+	}
+
+	baseLogger := logging.BaseFromContext(ctx)
+
+	args := make(map[string]any)
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, utils.McpCodedError(invocation.ErrorCodeValidation, "arguments must be a JSON object"), false
+		}
+		if args == nil {
+			args = make(map[string]any)
+		}
+	}
+
+	input := &policy.Input{Tool: tool.Name, Arguments: args}
+	if claims := oauth.GetClaimsFromContext(ctx); claims != nil {
+		input.Subject = &policy.Subject{
+			Subject:  claims.Subject,
+			Issuer:   claims.Issuer,
+			Audience: claims.Audience,
+			Scopes:   strings.Fields(claims.Scope),
+			ClientID: claims.ClientID,
+			Username: claims.Username,
+			Email:    claims.Email,
+		}
+	}
+
+	decision, err := engine.Evaluate(ctx, input)
+	if err != nil {
+		baseLogger.Error("Failed to evaluate the authorization policy",
+			zap.String("tool_name", tool.Name),
+			zap.Error(err))
+		return nil, utils.McpCodedError(invocation.ErrorCodeInternal, "failed to evaluate the authorization policy"), false
+	}
+
+	if !decision.Allow {
+		baseLogger.Warn("Tool call denied by the authorization policy",
+			zap.String("tool_name", tool.Name),
+			zap.String("reason", decision.Reason))
+		if decision.Reason != "" {
+			return nil, utils.McpCodedError(invocation.ErrorCodeAuth, "forbidden: %s", decision.Reason), true
+		}
+		return nil, utils.McpCodedError(invocation.ErrorCodeAuth, "forbidden: denied by policy"), true
+	}
+
+	if decision.Arguments == nil {
+		return arguments, nil, false
+	}
+
+	mutated, err := json.Marshal(decision.Arguments)
+	if err != nil {
+		baseLogger.Error("Failed to marshal the arguments of the authorization policy",
+			zap.String("tool_name", tool.Name),
+			zap.Error(err))
+		return nil, utils.McpCodedError(invocation.ErrorCodeInternal, "failed to evaluate the authorization policy"), false
+	}
+	baseLogger.Debug("Tool call arguments changed by the authorization policy", zap.String("tool_name", tool.Name))
+
+	return mutated, nil, false
+}
@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/policy"
+)
+
+func TestAuthorizeCall(t *testing.T) {
+	engine, err := policy.NewCELEngine(`
+		arguments.namespace.startsWith("prod-") && !("admin" in subject.scopes)
+			? {"allow": false, "reason": "production namespaces require the admin scope"}
+			: {"allow": true, "arguments": {"namespace": arguments.namespace, "dryRun": !("admin" in subject.scopes)}}`)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name         string
+		engine       policy.Engine
+		scope        string
+		arguments    string
+		expectedArgs string
+		expectedText string
+		denied       bool
+	}{
+		{
+			name:         "no policy",
+			arguments:    `{"namespace": "prod-eu"}`,
+			expectedArgs: `{"namespace": "prod-eu"}`,
+		},
+		{
+			name:         "allowed with mutated arguments",
+			engine:       engine,
+			scope:        "read",
+			arguments:    `{"namespace": "dev"}`,
+			expectedArgs: `{"namespace": "dev", "dryRun": true}`,
+		},
+		{
+			name:         "allowed for the admin scope",
+			engine:       engine,
+			scope:        "read admin",
+			arguments:    `{"namespace": "prod-eu"}`,
+			expectedArgs: `{"namespace": "prod-eu", "dryRun": false}`,
+		},
+		{
+			name:         "denied",
+			engine:       engine,
+			scope:        "read",
+			arguments:    `{"namespace": "prod-eu"}`,
+			expectedText: "forbidden: production namespaces require the admin scope",
+			denied:       true,
+		},
+		{
+			name:         "policy evaluation error",
+			engine:       engine,
+			scope:        "read",
+			arguments:    `{}`,
+			expectedText: "failed to evaluate the authorization policy",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Subject: "alice", Scope: tc.scope})
+			tool := &definitions.Tool{Name: "delete_namespace"}
+
+			arguments, result, denied := authorizeCall(ctx, tc.engine, tool, json.RawMessage(tc.arguments))
+			assert.Equal(t, tc.denied, denied)
+			if tc.expectedText != "" {
+				require.NotNil(t, result)
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+				return
+			}
+			require.Nil(t, result)
+			assert.JSONEq(t, tc.expectedArgs, string(arguments))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// postProcessResult returns the text generated by a model of the client from result and the instruction of
+// the postProcess of tool, with MCP sampling. The result is returned unchanged if it can't be post-processed,
+// unless post-processing is required by the tool.
+func postProcessResult(ctx context.Context, req *mcp.CallToolRequest, tool *definitions.Tool, result *mcp.CallToolResult) *mcp.CallToolResult {
+	postProcess := tool.PostProcess
+	if postProcess == nil || result == nil || result.IsError {
+		return result
+	}
+
+	text := resultText(result)
+	if text == "" {
+		return result
+	}
+
+	if !supportsSampling(req.Session) {
+		if postProcess.Required {
+			return utils.McpTextError("the client does not support sampling, which is required to post-process the result of the tool")
+		}
+		return result
+	}
+
+	generated, err := sampleText(ctx, req.Session, &mcp.CreateMessageParams{
+		Messages: []*mcp.SamplingMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: postProcess.Instruction + "\n\nTool result:\n" + text},
+		}},
+		SystemPrompt: postProcess.SystemPrompt,
+		MaxTokens:    int64(postProcess.GetMaxTokens()),
+	})
+	if err != nil {
+		logging.BaseFromContext(ctx).Warn("Failed to post-process the tool result",
+			zap.String("tool_name", tool.Name),
+			zap.Error(err))
+		if postProcess.Required {
+			return utils.McpTextError("failed to post-process the result of the tool")
+		}
+		return result
+	}
+
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: generated}}}
+}
+
+// sampleText requests a message from a model of the client of session, and returns its text.
+func sampleText(ctx context.Context, session *mcp.ServerSession, params *mcp.CreateMessageParams) (string, error) {
+	message, err := session.CreateMessage(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	content, ok := message.Content.(*mcp.TextContent)
+	if !ok || content.Text == "" {
+		return "", errors.New("the model did not generate text")
+	}
+	return content.Text, nil
+}
+
+// resultText returns the text of the content of result, or its structured content as JSON if it has no text.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok && text.Text != "" {
+			texts = append(texts, text.Text)
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n")
+	}
+
+	if result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			return string(data)
+		}
+	}
+	return ""
+}
+
+// supportsSampling reports whether the client of session can be asked to generate messages.
+func supportsSampling(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
+}
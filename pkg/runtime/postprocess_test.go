@@ -0,0 +1,129 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postProcessTestTool(url string, required bool) string {
+	tool := `- name: list_issues
+  description: List the open issues
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/issues
+  postProcess:
+    instruction: Summarize the open issues in one sentence.
+    systemPrompt: You are a concise assistant.
+    maxTokens: 200
+`
+	if required {
+		tool += "    required: true\n"
+	}
+	return tool
+}
+
+func TestPostProcessResult(t *testing.T) {
+	tt := []struct {
+		name          string
+		required      bool
+		clientSamples bool
+		samplingErr   error
+		expectSampled bool
+		expectError   bool
+		expectText    string
+	}{
+		{
+			name:          "result rewritten by the model of the client",
+			clientSamples: true,
+			expectSampled: true,
+			expectText:    "Two issues are open.",
+		},
+		{
+			name:       "client without sampling support",
+			expectText: `[{"title":"crash"},{"title":"typo"}]`,
+		},
+		{
+			name:        "client without sampling support when required",
+			required:    true,
+			expectError: true,
+			expectText:  "the client does not support sampling, which is required to post-process the result of the tool",
+		},
+		{
+			name:          "sampling failure",
+			clientSamples: true,
+			samplingErr:   errors.New("user rejected the request"),
+			expectSampled: true,
+			expectText:    `[{"title":"crash"},{"title":"typo"}]`,
+		},
+		{
+			name:          "sampling failure when required",
+			required:      true,
+			clientSamples: true,
+			samplingErr:   errors.New("user rejected the request"),
+			expectSampled: true,
+			expectError:   true,
+			expectText:    "failed to post-process the result of the tool",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`[{"title":"crash"},{"title":"typo"}]`))
+			}))
+			defer backend.Close()
+
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, postProcessTestTool(backend.URL, tc.required)))
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+
+			var sampled *mcp.CreateMessageParams
+			opts := &mcp.ClientOptions{}
+			if tc.clientSamples {
+				opts.CreateMessageHandler = func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+					sampled = req.Params
+					if tc.samplingErr != nil {
+						return nil, tc.samplingErr
+					}
+					return &mcp.CreateMessageResult{
+						Role:    "assistant",
+						Model:   "test-model",
+						Content: &mcp.TextContent{Text: "Two issues are open."},
+					}, nil
+				}
+			}
+			cs := connectTestClientWithOptions(t, s.Server, opts)
+
+			result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_issues"})
+			require.NoError(t, err)
+
+			if tc.expectSampled {
+				require.NotNil(t, sampled, "the model of the client should be asked to rewrite the result")
+				assert.Equal(t, "You are a concise assistant.", sampled.SystemPrompt)
+				assert.Equal(t, int64(200), sampled.MaxTokens)
+				require.Len(t, sampled.Messages, 1)
+				assert.Equal(t, mcp.Role("user"), sampled.Messages[0].Role)
+				assert.Equal(t,
+					"Summarize the open issues in one sentence.\n\nTool result:\n"+`[{"title":"crash"},{"title":"typo"}]`,
+					sampled.Messages[0].Content.(*mcp.TextContent).Text)
+			} else {
+				assert.Nil(t, sampled)
+			}
+
+			assert.Equal(t, tc.expectError, result.IsError)
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, tc.expectText, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+// checkQuota counts a call of tool for the client of ctx with tracker, and returns a result stopping the call
+// if it exceeds a quota of the client. Every call is accepted if tracker is nil.
+func checkQuota(ctx context.Context, tracker *quota.Tracker, tool string) *mcp.CallToolResult {
+	client := quotaClient(ctx)
+	if err := tracker.Record(client, tool); err != nil {
+		logging.BaseFromContext(ctx).Warn("Tool call rejected by a quota",
+			zap.String("tool_name", tool),
+			zap.String("client", client),
+			zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeQuotaExceeded, "%v", err)
+	}
+	return nil
+}
+
+// quotaClient returns the client the calls of ctx are counted for: the subject of its credentials, or its
+// OAuth client ID if it has no subject.
+func quotaClient(ctx context.Context) string {
+	if claims := oauth.GetClaimsFromContext(ctx); claims != nil {
+		if claims.Subject != "" {
+			return claims.Subject
+		}
+		if claims.ClientID != "" {
+			return claims.ClientID
+		}
+	}
+	return serverconfig.AnonymousClient
+}
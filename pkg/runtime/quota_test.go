@@ -0,0 +1,73 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+)
+
+func TestCheckQuota(t *testing.T) {
+	tt := []struct {
+		name           string
+		claims         *oauth.TokenClaims
+		previousCalls  int
+		expectedClient string
+		expectedText   string
+	}{
+		{
+			name:           "within the quota",
+			claims:         &oauth.TokenClaims{Subject: "alice", ClientID: "agent"},
+			expectedClient: "alice",
+		},
+		{
+			name:           "over the quota",
+			claims:         &oauth.TokenClaims{Subject: "alice"},
+			previousCalls:  2,
+			expectedClient: "alice",
+			expectedText:   "quota exceeded: 2 calls per hour",
+		},
+		{
+			name:           "client without subject",
+			claims:         &oauth.TokenClaims{ClientID: "agent"},
+			expectedClient: "agent",
+		},
+		{
+			name:           "unauthenticated client",
+			expectedClient: "anonymous",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.claims != nil {
+				ctx = oauth.AddClaimsToContext(ctx, tc.claims)
+			}
+			tracker := quota.NewTracker(quota.Config{Default: quota.Limits{PerHour: 2}})
+			for range tc.previousCalls {
+				require.NoError(t, tracker.Record(tc.expectedClient, "search"))
+			}
+
+			result := checkQuota(ctx, tracker, "search")
+			if tc.expectedText != "" {
+				require.NotNil(t, result)
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+				assert.Equal(t, invocation.NewErrorDetail(invocation.ErrorCodeQuotaExceeded, 0), result.Meta[invocation.ErrorMetaKey])
+			} else {
+				assert.Nil(t, result)
+			}
+
+			usage, ok := tracker.ClientUsage(tc.expectedClient)
+			require.True(t, ok)
+			assert.Equal(t, int64(tc.previousCalls+1), usage.Total.Calls)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+// invokeTool invokes tool with invoker, or returns the result recorded for arguments when store replays tool
+// calls. When store records tool calls, the results returned by invoker are recorded for arguments. arguments
+// are the arguments of the call before defaults are applied, so that fixtures don't depend on the environment
+// they were recorded in.
+func invokeTool(ctx context.Context, store *recording.Store, invoker invocation.Invoker, tool *definitions.Tool,
+	req *mcp.CallToolRequest, arguments json.RawMessage) (*mcp.CallToolResult, error) {
+	if store.Replaying() {
+		result, err := store.Replay(tool.Name, arguments)
+		if errors.Is(err, recording.ErrNotRecorded) {
+			return utils.McpCodedError(invocation.ErrorCodeBackendUnavailable, "no recorded result for these arguments of tool %s", tool.Name), nil
+		}
+		return result, err
+	}
+
+	result, err := invoker.Invoke(ctx, req)
+	if err == nil && result != nil && store.Recording() {
+		if recordErr := store.Record(tool.Name, arguments, result); recordErr != nil {
+			logging.BaseFromContext(ctx).Warn("Failed to record tool call",
+				zap.String("tool_name", tool.Name),
+				zap.Error(recordErr))
+		}
+	}
+
+	return result, err
+}
@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+func TestRecordAndReplayToolCalls(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(`{"service":"` + r.URL.Query().Get("service") + `","status":"up"}`))
+	}))
+
+	dir := t.TempDir()
+	tool := errorCodesTestTool("get_status", backend.URL+"/status", "")
+
+	callTools := func(t *testing.T, mode string) map[string]*mcp.CallToolResult {
+		t.Helper()
+
+		mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tool))
+		mcpServer.Runtime.Recording = &serverconfig.RecordingConfig{Mode: mode, Dir: dir}
+		s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+		require.NoError(t, err)
+		cs, _ := connectTestClient(t, s.Server)
+
+		results := make(map[string]*mcp.CallToolResult)
+		for _, service := range []string{"api", "missing"} {
+			result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      "get_status",
+				Arguments: map[string]any{"service": service},
+			})
+			require.NoError(t, err)
+			results[service] = result
+		}
+		return results
+	}
+
+	recorded := callTools(t, recording.ModeRecord)
+	require.False(t, recorded["api"].IsError)
+	require.True(t, recorded["missing"].IsError)
+
+	// the backend is not called when replaying
+	backend.Close()
+
+	replayed := callTools(t, recording.ModeReplay)
+	assert.Equal(t, recorded, replayed)
+
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tool))
+	mcpServer.Runtime.Recording = &serverconfig.RecordingConfig{Mode: recording.ModeReplay, Dir: dir}
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_status",
+		Arguments: map[string]any{"service": "db"},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	data, err := json.Marshal(result.Meta[invocation.ErrorMetaKey])
+	require.NoError(t, err)
+	var detail invocation.ErrorDetail
+	require.NoError(t, json.Unmarshal(data, &detail))
+	assert.Equal(t, invocation.ErrorDetail{Code: invocation.ErrorCodeBackendUnavailable, Retryable: true}, detail)
+}
@@ -0,0 +1,168 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// reloadDebounce is how long to wait for further changes to the MCP file before reloading it.
+// Editors often write a file in several steps when saving.
+const reloadDebounce = 100 * time.Millisecond
+
+// watchToolDefinitions watches the MCP file at path and calls reload with the new definitions
+// every time it changes, until ctx is cancelled. Files that fail to parse are logged and skipped, and
+// reload must keep serving the last valid definitions if the new ones are invalid.
+func watchToolDefinitions(ctx context.Context, path string, logger *zap.Logger, reload func(definitions.MCPToolDefinitions) error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Error("Failed to create MCP file watcher, hot reload is disabled", zap.Error(err))
+		return
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	// watch the directory rather than the file, as many editors replace the file when saving
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		logger.Error("Failed to watch MCP file, hot reload is disabled",
+			zap.String("path", path),
+			zap.Error(err))
+		return
+	}
+
+	logger.Info("Watching MCP file for changes", zap.String("path", path))
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			debounce = time.After(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("MCP file watcher error", zap.Error(err))
+		case <-debounce:
+			debounce = nil
+
+			// the definitions are validated by reload, with the config of the server
+			toolDefsFile, err := parseToolDefinitionsFile(path)
+			if err != nil {
+				logger.Error("Failed to reload MCP file, keeping the current definitions",
+					zap.String("path", path),
+					zap.Error(err))
+				continue
+			}
+
+			if err := reload(toolDefsFile.MCPToolDefinitions); err != nil {
+				logger.Error("MCP file reloaded with some errors",
+					zap.String("path", path),
+					zap.Error(err))
+				continue
+			}
+
+			logger.Info("MCP file reloaded", zap.String("path", path))
+		}
+	}
+}
+
+// loadToolDefinitions parses and validates the MCP file at path.
+func loadToolDefinitions(path string) (*definitions.MCPToolDefinitions, error) {
+	toolDefsFile, err := parseToolDefinitionsFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MCP file: %w", err)
+	}
+
+	if err := toolDefsFile.MCPToolDefinitions.Validate(invocation.InvocationValidator); err != nil {
+		return nil, fmt.Errorf("invalid MCP file: %w", err)
+	}
+
+	return &toolDefsFile.MCPToolDefinitions, nil
+}
+
+// serverReloader reloads the tool definitions of a single server that serves all tools.
+type serverReloader struct {
+	mu        sync.Mutex
+	server    *primitiveServer
+	mcpServer *mcpserver.MCPServer
+	invokers  *toolInvokers // invokers of the lazily loaded tools of server
+}
+
+func (r *serverReloader) Reload(defs definitions.MCPToolDefinitions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: defs,
+		MCPServerConfig:    r.mcpServer.MCPServerConfig,
+	}
+
+	err := syncServerPrimitives(r.server, r.mcpServer, newServer, r.invokers)
+	r.invokers.retain(newServer.Tools)
+	r.mcpServer = newServer
+
+	return err
+}
+
+// Replace serves the tool definitions of mcpServer, with its server config. Every tool is registered again,
+// so that the calls made after Replace use the new server config.
+func (r *serverReloader) Replace(mcpServer *mcpserver.MCPServer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the invokers created with the previous server config are dropped
+	r.invokers.retain(nil)
+	err := syncServerPrimitives(r.server, r.mcpServer, mcpServer, r.invokers)
+	r.mcpServer = mcpServer
+
+	return err
+}
+
+// syncServerPrimitives updates s in place so that it serves the enabled tools and the prompts, resources and
+// resource templates of newServer. Primitives of oldServer that no longer exist or were disabled are removed. The go-sdk notifies
+// connected clients about the changed lists. Lazily loaded tools share their invokers with the other servers
+// of invokers.
+// The server name, version and instructions are not updated, as they are sent during initialization.
+func syncServerPrimitives(s *primitiveServer, oldServer, newServer *mcpserver.MCPServer, invokers *toolInvokers) error {
+	s.RemoveTools(removedKeys(oldServer.Tools, enabledTools(newServer.Tools), func(t *definitions.Tool) string { return t.Name })...)
+	s.RemovePrompts(removedKeys(oldServer.Prompts, newServer.Prompts, func(p *definitions.Prompt) string { return p.Name })...)
+	s.RemoveResources(removedKeys(oldServer.Resources, newServer.Resources, func(r *definitions.Resource) string { return r.URI })...)
+	s.RemoveResourceTemplates(removedKeys(oldServer.ResourceTemplates, newServer.ResourceTemplates, func(rt *definitions.ResourceTemplate) string { return rt.URITemplate })...)
+
+	return registerPrimitives(s, newServer, invokers)
+}
+
+// removedKeys returns the keys of the items in oldItems that have no item with the same key in newItems.
+func removedKeys[T any](oldItems, newItems []T, key func(T) string) []string {
+	keep := make(map[string]bool, len(newItems))
+	for _, item := range newItems {
+		keep[key(item)] = true
+	}
+
+	var removed []string
+	for _, item := range oldItems {
+		if k := key(item); !keep[k] {
+			removed = append(removed, k)
+		}
+	}
+
+	return removed
+}
@@ -0,0 +1,302 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+)
+
+const reloadTestHeader = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test-server
+version: "1.0.0"
+tools:
+`
+
+func reloadTestTool(name string, scopes ...string) string {
+	tool := `- name: ` + name + `
+  description: "A test tool"
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/` + name + `
+`
+	if len(scopes) > 0 {
+		tool += "  requiredScopes:\n"
+		for _, s := range scopes {
+			tool += "  - " + s + "\n"
+		}
+	}
+	return tool
+}
+
+func writeToolDefinitions(t *testing.T, path string, tools ...string) {
+	t.Helper()
+
+	content := reloadTestHeader
+	for _, tool := range tools {
+		content += tool
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func loadTestDefinitions(t *testing.T, tools ...string) definitions.MCPToolDefinitions {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path, tools...)
+
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	return *defs
+}
+
+func newTestMCPServer(t *testing.T, defs definitions.MCPToolDefinitions) *mcpserver.MCPServer {
+	t.Helper()
+
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: defs,
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{
+				TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+			},
+		},
+	}
+	mcpServer.ApplyDefaults()
+
+	return mcpServer
+}
+
+// connectTestClient connects a client to s and returns a channel that receives tool list changed notifications
+func connectTestClient(t *testing.T, s *mcp.Server) (*mcp.ClientSession, <-chan struct{}) {
+	t.Helper()
+
+	ctx := context.Background()
+	changed := make(chan struct{}, 10)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession, changed
+}
+
+func listToolNames(t *testing.T, cs *mcp.ClientSession) []string {
+	t.Helper()
+
+	res, err := cs.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	names := make([]string, len(res.Tools))
+	for i, tool := range res.Tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func waitForNotification(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for list changed notification")
+	}
+}
+
+func TestServerManagerReload(t *testing.T) {
+	tt := []struct {
+		name          string
+		initialTools  []string
+		reloadedTools []string
+		expectedTools []string
+	}{
+		{
+			name:          "tool added",
+			initialTools:  []string{reloadTestTool("first")},
+			reloadedTools: []string{reloadTestTool("first"), reloadTestTool("second")},
+			expectedTools: []string{"first", "second"},
+		},
+		{
+			name:          "tool removed",
+			initialTools:  []string{reloadTestTool("first"), reloadTestTool("second")},
+			reloadedTools: []string{reloadTestTool("second")},
+			expectedTools: []string{"second"},
+		},
+		{
+			name:          "tool requiring scopes is filtered",
+			initialTools:  []string{reloadTestTool("first")},
+			reloadedTools: []string{reloadTestTool("first"), reloadTestTool("admin", "admin")},
+			expectedTools: []string{"first"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sm := NewServerManager(newTestMCPServer(t, loadTestDefinitions(t, tc.initialTools...)))
+
+			s, err := sm.ServerFromContext(context.Background())
+			require.NoError(t, err)
+
+			cs, changed := connectTestClient(t, s)
+
+			err = sm.Reload(loadTestDefinitions(t, tc.reloadedTools...))
+			require.NoError(t, err)
+
+			waitForNotification(t, changed)
+			assert.ElementsMatch(t, tc.expectedTools, listToolNames(t, cs), "connected client should see the reloaded tools")
+
+			newServer, err := sm.ServerFromContext(context.Background())
+			require.NoError(t, err)
+			assert.Same(t, s, newServer, "cached server should be updated in place")
+		})
+	}
+}
+
+func TestWatchToolDefinitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path, reloadTestTool("first"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan definitions.MCPToolDefinitions, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchToolDefinitions(ctx, path, zap.NewNop(), func(defs definitions.MCPToolDefinitions) error {
+			reloaded <- defs
+			return nil
+		})
+	}()
+
+	// give the watcher time to start
+	time.Sleep(200 * time.Millisecond)
+
+	// an invalid file must not be reloaded
+	require.NoError(t, os.WriteFile(path, []byte("tools: ["), 0644))
+	time.Sleep(2 * reloadDebounce)
+
+	writeToolDefinitions(t, path, reloadTestTool("first"), reloadTestTool("second"))
+
+	select {
+	case defs := <-reloaded:
+		require.Len(t, defs.Tools, 2)
+		assert.Equal(t, "second", defs.Tools[1].Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestServerManagerReloadSyncsDroppedServers(t *testing.T) {
+	sm := NewServerManager(newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("admin", "admin"))))
+
+	s, err := sm.ServerFromContext(context.Background())
+	require.NoError(t, err)
+	admin, err := sm.ServerFromContext(oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Scope: "admin"}))
+	require.NoError(t, err)
+	require.NotSame(t, s, admin)
+
+	cs, changed := connectTestClient(t, admin)
+	require.ElementsMatch(t, []string{"first", "admin"}, listToolNames(t, cs))
+
+	// both scopes now see the same tools, so only one of their servers stays cached
+	require.NoError(t, sm.Reload(loadTestDefinitions(t, reloadTestTool("first"))))
+
+	waitForNotification(t, changed)
+	assert.Equal(t, []string{"first"}, listToolNames(t, cs), "the clients of a dropped server should see the reloaded tools")
+
+	reloaded, err := sm.ServerFromContext(oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Scope: "admin"}))
+	require.NoError(t, err)
+	assert.Same(t, s, reloaded)
+}
+
+func TestToolDefinitionsSourceValidatesFile(t *testing.T) {
+	tt := []struct {
+		name          string
+		tools         []string
+		expectedError string
+	}{
+		{
+			name:  "valid definitions",
+			tools: []string{reloadTestTool("first"), reloadTestTool("second")},
+		},
+		{
+			name: "url denied by the network policy",
+			tools: []string{reloadTestTool("first"), strings.ReplaceAll(reloadTestTool("metadata"),
+				"http://localhost:8080/", "http://metadata.internal/")},
+			expectedError: "tool metadata: invalid url 'http://metadata.internal/metadata'",
+		},
+		{
+			name:          "quotas of a removed tool",
+			tools:         []string{reloadTestTool("second")},
+			expectedError: "quotas of unknown tool 'first'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first")))
+			mcpServer.Runtime.Security = &serverconfig.SecurityConfig{
+				Network: &serverconfig.NetworkPolicyConfig{DeniedHosts: []string{"*.internal"}},
+			}
+			mcpServer.Runtime.Quotas = &serverconfig.QuotasConfig{
+				Tools: map[string]*serverconfig.QuotaLimits{"first": {PerHour: 10}},
+			}
+			require.NoError(t, mcpServer.Validate(invocationValidator(mcpServer)))
+
+			source := &toolDefinitionsSource{logger: zap.NewNop(), config: mcpServer.MCPServerConfig, file: mcpServer.MCPToolDefinitions}
+			var reloaded []definitions.MCPToolDefinitions
+			source.onChange(func(defs definitions.MCPToolDefinitions) error {
+				reloaded = append(reloaded, defs)
+				return nil
+			})
+
+			path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+			writeToolDefinitions(t, path, tc.tools...)
+			toolDefsFile, err := parseToolDefinitionsFile(path)
+			require.NoError(t, err)
+
+			err = source.setFile(toolDefsFile.MCPToolDefinitions)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Empty(t, reloaded, "invalid definitions should not be reloaded")
+				assert.Equal(t, mcpServer.MCPToolDefinitions, source.file)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, reloaded, 1)
+			assert.Len(t, reloaded[0].Tools, len(tc.tools))
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+)
+
+// resourceLister adds the resources enumerated by the list invocations of resource templates to the results
+// of resources/list, next to the static resources registered on the server.
+type resourceLister struct {
+	mu        sync.Mutex
+	templates []*templateLister
+	pool      *concurrency.Pool
+}
+
+// templateLister enumerates the resources of a resource template with its list invocation.
+type templateLister struct {
+	template *definitions.ResourceTemplate
+	matcher  *uritemplate.Template
+	invoker  invocation.Invoker
+}
+
+// setTemplates replaces the resource templates whose resources are listed by the ones of templates with a
+// list invocation.
+func (l *resourceLister) setTemplates(templates []*definitions.ResourceTemplate) error {
+	var err error
+	listers := make([]*templateLister, 0, len(templates))
+	for _, rt := range templates {
+		lr := rt.ListResource()
+		if lr == nil {
+			continue
+		}
+
+		matcher, templateErr := uritemplate.New(rt.URITemplate)
+		if templateErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid uriTemplate of resource template %s: %w", rt.Name, templateErr))
+			continue
+		}
+
+		invoker, invokerErr := invocation.CreateResourceInvoker(lr)
+		if invokerErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to create list invoker for resource template %s: %w", rt.Name, invokerErr))
+			continue
+		}
+
+		listers = append(listers, &templateLister{template: rt, matcher: matcher, invoker: invoker})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.templates = listers
+	return err
+}
+
+// middleware returns an MCP middleware adding the listed resources to the last page of resources/list. It
+// must run after the secrets, logging and HTTP client middlewares, as the invocations depend on them: add
+// it to the server before them.
+func (l *resourceLister) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil || method != "resources/list" {
+				return result, err
+			}
+
+			listResult, ok := result.(*mcp.ListResourcesResult)
+			if !ok || listResult.NextCursor != "" {
+				return result, nil
+			}
+
+			l.mu.Lock()
+			templates := l.templates
+			l.mu.Unlock()
+
+			for _, tl := range templates {
+				listResult.Resources = append(listResult.Resources, l.list(ctx, tl, req.GetExtra())...)
+			}
+
+			return listResult, nil
+		}
+	}
+}
+
+// list returns the resources enumerated by the list invocation of a resource template. Failed invocations
+// are logged and list no resources, so that the static resources and the resources of the other templates
+// are still listed.
+func (l *resourceLister) list(ctx context.Context, tl *templateLister, extra *mcp.RequestExtra) []*mcp.Resource {
+	rt := tl.template
+	baseLogger := logging.BaseFromContext(ctx)
+
+	if err := checkPrimitiveAuthorization(ctx, rt.RequiredScopes, rt.Name, "resource_template"); err != nil {
+		return nil
+	}
+
+	release, err := acquireInvocation(ctx, l.pool, rt.Name, "resource_template", 0)
+	if err != nil {
+		return nil
+	}
+	defer release()
+
+	result, err := tl.invoker.InvokeResource(ctx, &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: rt.URITemplate},
+		Extra:  extra,
+	})
+	if err != nil {
+		baseLogger.Error("Failed to list the resources of resource template",
+			zap.String("resource_template_name", rt.Name),
+			zap.Error(err))
+		return nil
+	}
+
+	var text strings.Builder
+	for _, c := range result.Contents {
+		if c.Text != "" {
+			text.WriteString(c.Text)
+		} else {
+			text.Write(c.Blob)
+		}
+	}
+
+	resources, err := parseListedResources(text.String())
+	if err != nil {
+		baseLogger.Error("Invalid resources listed for resource template",
+			zap.String("resource_template_name", rt.Name),
+			zap.Error(err))
+		return nil
+	}
+
+	listed := make([]*mcp.Resource, 0, len(resources))
+	for _, r := range resources {
+		// resources that don't match the template could not be read
+		if tl.matcher.Match(r.URI) == nil {
+			baseLogger.Warn("Listed resource does not match the uriTemplate of its resource template, skipping it",
+				zap.String("resource_template_name", rt.Name),
+				zap.String("uri", r.URI))
+			continue
+		}
+		if r.Name == "" {
+			r.Name = r.URI
+		}
+		if r.MIMEType == "" {
+			r.MIMEType = rt.MIMEType
+		}
+		listed = append(listed, r)
+	}
+
+	return listed
+}
+
+// listedResource is a resource enumerated by a list invocation.
+type listedResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	MIMEType    string `json:"mimeType"`
+}
+
+// parseListedResources parses the output of a list invocation: a JSON array of URIs or of resource objects,
+// or a URI per line.
+func parseListedResources(output string) ([]*mcp.Resource, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(output, "[") {
+		var resources []*mcp.Resource
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		for scanner.Scan() {
+			if uri := strings.TrimSpace(scanner.Text()); uri != "" {
+				resources = append(resources, &mcp.Resource{URI: uri})
+			}
+		}
+		return resources, scanner.Err()
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(output), &items); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of URIs or resources: %w", err)
+	}
+
+	resources := make([]*mcp.Resource, 0, len(items))
+	for i, item := range items {
+		var r listedResource
+		if err := json.Unmarshal(item, &r.URI); err != nil {
+			if err := json.Unmarshal(item, &r); err != nil {
+				return nil, fmt.Errorf("item %d is not a URI or a resource", i)
+			}
+		}
+		if r.URI == "" {
+			return nil, fmt.Errorf("item %d has no uri", i)
+		}
+
+		resources = append(resources, &mcp.Resource{
+			URI:         r.URI,
+			Name:        r.Name,
+			Title:       r.Title,
+			Description: r.Description,
+			MIMEType:    r.MIMEType,
+		})
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,185 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListedResources(t *testing.T) {
+	tt := []struct {
+		name          string
+		output        string
+		expected      []*mcp.Resource
+		expectedError string
+	}{
+		{
+			name:     "empty output",
+			output:   " \n",
+			expected: nil,
+		},
+		{
+			name:     "URI per line",
+			output:   "users://1\n\n  users://2  \n",
+			expected: []*mcp.Resource{{URI: "users://1"}, {URI: "users://2"}},
+		},
+		{
+			name:     "array of URIs",
+			output:   `["users://1", "users://2"]`,
+			expected: []*mcp.Resource{{URI: "users://1"}, {URI: "users://2"}},
+		},
+		{
+			name:   "array of resources",
+			output: `[{"uri": "users://1", "name": "alice", "title": "Alice", "description": "The first user", "mimeType": "application/json"}, "users://2"]`,
+			expected: []*mcp.Resource{
+				{URI: "users://1", Name: "alice", Title: "Alice", Description: "The first user", MIMEType: "application/json"},
+				{URI: "users://2"},
+			},
+		},
+		{
+			name:          "invalid JSON",
+			output:        `["users://1"`,
+			expectedError: "expected a JSON array of URIs or resources",
+		},
+		{
+			name:          "resource without uri",
+			output:        `[{"name": "alice"}]`,
+			expectedError: "item 0 has no uri",
+		},
+		{
+			name:          "invalid item",
+			output:        `[1]`,
+			expectedError: "item 0 is not a URI or a resource",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, err := parseListedResources(tc.output)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resources)
+		})
+	}
+}
+
+// resourceListTestFile returns an MCP file with a static resource, and a resource template of the users of
+// the backend at url, listed by the list endpoint of the backend if list is set.
+func resourceListTestFile(url string, list bool) string {
+	content := `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test-server
+version: "1.0.0"
+resources:
+- name: readme
+  description: The readme
+  uri: docs://readme
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/readme
+resourceTemplates:
+- name: user
+  description: A user
+  uriTemplate: users://{id}
+  mimeType: application/json
+  inputSchema:
+    type: object
+    properties:
+      id:
+        type: string
+  invocation:
+    http:
+      method: GET
+      url: ` + url + `/users/{id}
+`
+	if list {
+		content += `  list:
+    http:
+      method: GET
+      url: ` + url + `/users
+`
+	}
+	return content
+}
+
+func TestResourceTemplateListInvocation(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			_, _ = w.Write([]byte(`[{"uri": "users://1", "name": "alice"}, "users://2", "groups://1"]`))
+		default:
+			_, _ = fmt.Fprintf(w, `{"path": %q}`, r.URL.Path)
+		}
+	}))
+	defer backend.Close()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(resourceListTestFile(backend.URL, true)), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	mcpServer := newTestMCPServer(t, *defs)
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	res, err := cs.ListResources(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*mcp.Resource{
+		{URI: "docs://readme", Name: "readme", Description: "The readme"},
+		{URI: "users://1", Name: "alice", MIMEType: "application/json"},
+		{URI: "users://2", Name: "users://2", MIMEType: "application/json"},
+	}, res.Resources, "resources not matching the template should be skipped")
+
+	read, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "users://2"})
+	require.NoError(t, err)
+	require.Len(t, read.Contents, 1)
+	assert.JSONEq(t, `{"path": "/users/2"}`, read.Contents[0].Text)
+
+	t.Run("listed resources are updated on reload", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(resourceListTestFile(backend.URL, false)), 0644))
+		newDefs, err := loadToolDefinitions(path)
+		require.NoError(t, err)
+
+		require.NoError(t, syncServerPrimitives(s, mcpServer, newTestMCPServer(t, *newDefs), nil))
+
+		res, err := cs.ListResources(context.Background(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Resources, 1)
+		assert.Equal(t, "docs://readme", res.Resources[0].URI)
+	})
+}
+
+func TestResourceTemplateListInvocationFails(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"users": "not a list"}`))
+	}))
+	defer backend.Close()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(resourceListTestFile(backend.URL, true)), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	mcpServer := newTestMCPServer(t, *defs)
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	res, err := cs.ListResources(context.Background(), nil)
+	require.NoError(t, err, "static resources should be listed when a list invocation fails")
+	require.Len(t, res.Resources, 1)
+	assert.Equal(t, "docs://readme", res.Resources[0].URI)
+}
@@ -0,0 +1,145 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// applyStructuredContent drops or requires the structured content of a successful result, as configured by the
+// structuredContent of tool. An error is returned if the structured content is required, and the result has
+// neither structured content nor text content that is a JSON object.
+func applyStructuredContent(tool *definitions.Tool, result *mcp.CallToolResult) error {
+	if result == nil || result.IsError {
+		return nil
+	}
+
+	switch tool.StructuredContent {
+	case definitions.StructuredContentDisabled:
+		result.StructuredContent = nil
+	case definitions.StructuredContentRequired:
+		return invocation.ParseStructuredContent(result)
+	}
+	return nil
+}
+
+// formatToolResult returns result represented as configured by the resultContent of tool. arguments are the
+// arguments of the call, which set the variables of the URI of the resource the result is returned as. Results
+// linking to a spooled response are returned as is, as their content is only available from the spool.
+func formatToolResult(tool *definitions.Tool, arguments json.RawMessage, result *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+	rc := tool.ResultContent
+	if rc == nil || result == nil || result.IsError || isSpooledResult(result) {
+		return result, nil
+	}
+
+	switch rc.Type {
+	case definitions.ResultContentText:
+		if result.StructuredContent == nil {
+			return result, nil
+		}
+		// the structured content is serialized as text, unless the invocation already returns it as text
+		content := slices.Clone(result.Content)
+		if !slices.ContainsFunc(content, func(c mcp.Content) bool { _, ok := c.(*mcp.TextContent); return ok }) {
+			content = append(content, &mcp.TextContent{Text: resultText(result)})
+		}
+		return &mcp.CallToolResult{Content: content, Meta: result.Meta}, nil
+	case definitions.ResultContentStructured:
+		if result.StructuredContent == nil {
+			return result, nil
+		}
+		content := []mcp.Content{}
+		for _, c := range result.Content {
+			if _, ok := c.(*mcp.TextContent); !ok {
+				content = append(content, c)
+			}
+		}
+		return &mcp.CallToolResult{Content: content, StructuredContent: result.StructuredContent, Meta: result.Meta}, nil
+	}
+
+	resource := rc.ResolvedResource
+	if resource == nil {
+		return nil, fmt.Errorf("resource %s is not resolved", rc.Resource)
+	}
+
+	uri, err := resultResourceURI(resource, arguments, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if rc.Type == definitions.ResultContentResourceLink {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.ResourceLink{
+				URI:         uri,
+				Name:        resource.Name,
+				Title:       resource.Title,
+				Description: resource.Description,
+				MIMEType:    resource.MIMEType,
+			}},
+			Meta: result.Meta,
+		}, nil
+	}
+
+	text := resultText(result)
+	if text == "" {
+		return result, nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{URI: uri, MIMEType: resource.MIMEType, Text: text},
+		}},
+		Meta: result.Meta,
+	}, nil
+}
+
+// resultResourceURI returns the URI of resource, with the variables of the URI template of resource templates
+// set from arguments, or else from the top-level fields of the structured content of result.
+func resultResourceURI(resource *definitions.ResultResource, arguments json.RawMessage, result *mcp.CallToolResult) (string, error) {
+	if !resource.Template {
+		return resource.URI, nil
+	}
+
+	tmpl, err := uritemplate.New(resource.URI)
+	if err != nil {
+		return "", fmt.Errorf("invalid uriTemplate of resource template %s: %w", resource.Name, err)
+	}
+
+	args := decodeFields(arguments)
+	var fields map[string]any
+	if result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			fields = decodeFields(data)
+		}
+	}
+
+	values := uritemplate.Values{}
+	for _, name := range tmpl.Varnames() {
+		value, ok := args[name]
+		if !ok || value == nil {
+			value, ok = fields[name]
+		}
+		if !ok || value == nil {
+			return "", fmt.Errorf("variable %s of resource template %s is not set by the arguments or the result", name, resource.Name)
+		}
+		values.Set(name, uritemplate.String(fmt.Sprint(value)))
+	}
+
+	return tmpl.Expand(values)
+}
+
+// decodeFields returns the top-level fields of the JSON object data, keeping numbers as they are written.
+func decodeFields(data []byte) map[string]any {
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
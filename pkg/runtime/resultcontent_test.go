@@ -0,0 +1,167 @@
+package runtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+)
+
+func TestFormatToolResult(t *testing.T) {
+	report := &definitions.ResultResource{Name: "report", Description: "A report", MIMEType: "application/json", URI: "reports://{org}/{id}", Template: true}
+	latest := &definitions.ResultResource{Name: "latest_report", Title: "Latest Report", URI: "reports://latest"}
+
+	httpResult := func() *mcp.CallToolResult {
+		return &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: `{"id":1234567}`}},
+			StructuredContent: map[string]any{"id": float64(1234567)},
+		}
+	}
+
+	tt := []struct {
+		name          string
+		resultContent *definitions.ToolResultContent
+		arguments     string
+		result        *mcp.CallToolResult
+		expected      *mcp.CallToolResult
+		expectedError string
+	}{
+		{
+			name:     "no resultContent",
+			result:   httpResult(),
+			expected: httpResult(),
+		},
+		{
+			name:          "text",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentText},
+			result:        httpResult(),
+			expected:      &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":1234567}`}}},
+		},
+		{
+			name:          "text of structured content only",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentText},
+			result:        &mcp.CallToolResult{Content: []mcp.Content{}, StructuredContent: map[string]any{"id": "a"}},
+			expected:      &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":"a"}`}}},
+		},
+		{
+			name:          "structured",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentStructured},
+			result:        httpResult(),
+			expected:      &mcp.CallToolResult{Content: []mcp.Content{}, StructuredContent: map[string]any{"id": float64(1234567)}},
+		},
+		{
+			name:          "structured without structured content",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentStructured},
+			result:        &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not JSON"}}},
+			expected:      &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not JSON"}}},
+		},
+		{
+			name:          "embedded resource",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentResource, Resource: "report", ResolvedResource: report},
+			arguments:     `{"org": "genmcp"}`,
+			result:        httpResult(),
+			expected: &mcp.CallToolResult{Content: []mcp.Content{&mcp.EmbeddedResource{
+				Resource: &mcp.ResourceContents{URI: "reports://genmcp/1234567", MIMEType: "application/json", Text: `{"id":1234567}`},
+			}}},
+		},
+		{
+			name:          "resource link",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentResourceLink, Resource: "latest_report", ResolvedResource: latest},
+			result:        httpResult(),
+			expected: &mcp.CallToolResult{Content: []mcp.Content{&mcp.ResourceLink{
+				URI: "reports://latest", Name: "latest_report", Title: "Latest Report",
+			}}},
+		},
+		{
+			name:          "arguments take precedence over the result",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentResourceLink, Resource: "report", ResolvedResource: report},
+			arguments:     `{"org": "genmcp", "id": 42}`,
+			result:        httpResult(),
+			expected: &mcp.CallToolResult{Content: []mcp.Content{&mcp.ResourceLink{
+				URI: "reports://genmcp/42", Name: "report", Description: "A report", MIMEType: "application/json",
+			}}},
+		},
+		{
+			name:          "missing variable",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentResourceLink, Resource: "report", ResolvedResource: report},
+			result:        httpResult(),
+			expectedError: "variable org of resource template report is not set by the arguments or the result",
+		},
+		{
+			name:          "error result",
+			resultContent: &definitions.ToolResultContent{Type: definitions.ResultContentResourceLink, Resource: "report", ResolvedResource: report},
+			result:        &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not found"}}, IsError: true},
+			expected:      &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not found"}}, IsError: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &definitions.Tool{Name: "create_report", ResultContent: tc.resultContent}
+
+			result, err := formatToolResult(tool, json.RawMessage(tc.arguments), tc.result)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestApplyStructuredContent(t *testing.T) {
+	tt := []struct {
+		name              string
+		structuredContent string
+		result            *mcp.CallToolResult
+		expected          any
+		expectedError     string
+	}{
+		{
+			name:     "auto keeps the structured content",
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":1}`}}, StructuredContent: map[string]any{"id": 1}},
+			expected: map[string]any{"id": 1},
+		},
+		{
+			name:              "disabled drops the structured content",
+			structuredContent: definitions.StructuredContentDisabled,
+			result:            &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":1}`}}, StructuredContent: map[string]any{"id": 1}},
+		},
+		{
+			name:              "required parses the text content",
+			structuredContent: definitions.StructuredContentRequired,
+			result:            &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `{"id":1}`}}},
+			expected:          map[string]any{"id": float64(1)},
+		},
+		{
+			name:              "required with text that is not a JSON object",
+			structuredContent: definitions.StructuredContentRequired,
+			result:            &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: `[1, 2]`}}},
+			expectedError:     "tool output is not a JSON object",
+		},
+		{
+			name:              "required with an error result",
+			structuredContent: definitions.StructuredContentRequired,
+			result:            &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "not found"}}, IsError: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := &definitions.Tool{Name: "get_user", StructuredContent: tc.structuredContent}
+
+			err := applyStructuredContent(tool, tc.result)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.result.StructuredContent)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/scheduler"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/spool"
+)
+
+// scheduleResourceURI returns the URI of the resource serving the last result of the named schedule.
+func scheduleResourceURI(name string) string {
+	return "schedule://" + name + "/last"
+}
+
+// scheduleStatus is the content of the resource of a schedule.
+type scheduleStatus struct {
+	Name     string       `json:"name"`
+	Tool     string       `json:"tool"`
+	Schedule string       `json:"schedule"`
+	Running  bool         `json:"running"`
+	NextRun  *time.Time   `json:"nextRun,omitempty"`
+	LastRun  *scheduleRun `json:"lastRun,omitempty"`
+}
+
+// scheduleRun is the last call of the tool of a schedule.
+type scheduleRun struct {
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	Error      string              `json:"error,omitempty"`
+	Result     *mcp.CallToolResult `json:"result,omitempty"`
+}
+
+// toolScheduler calls the tools of the schedules of a server, looking them up in its current tool definitions
+// when each call is made.
+type toolScheduler struct {
+	mu      sync.RWMutex
+	runtime *serverconfig.ServerRuntime
+	tools   []*definitions.Tool
+}
+
+// startSchedules starts calling the tools of the schedules of mcpServer until ctx is done. The tools are
+// called with the runtime and the tools of mcpServer, replaced when the tool definitions of source change
+// and when reloader reloads the config, if not nil.
+func startSchedules(ctx context.Context, mcpServer *mcpserver.MCPServer, source *toolDefinitionsSource, reloader *configReloader) error {
+	sched := mcpServer.Runtime.GetScheduler()
+	if sched == nil {
+		return nil
+	}
+
+	ts := &toolScheduler{runtime: mcpServer.Runtime, tools: mcpServer.Tools}
+	if source != nil {
+		source.onChange(func(defs definitions.MCPToolDefinitions) error {
+			ts.setTools(defs.Tools)
+			return nil
+		})
+	}
+	reloader.onReload(func(next *mcpserver.MCPServer) error {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+
+		ts.runtime, ts.tools = next.Runtime, next.Tools
+		return nil
+	})
+
+	logger := mcpServer.Runtime.GetBaseLogger()
+	for _, sc := range mcpServer.Runtime.Schedules {
+		schedule, err := scheduler.Parse(sc.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule '%s': %w", sc.Name, err)
+		}
+
+		sched.Start(ctx, &scheduler.Job{
+			Name:       sc.Name,
+			Schedule:   schedule,
+			RunOnStart: sc.RunOnStart,
+			Run: func(ctx context.Context) (any, error) {
+				return ts.call(ctx, sc)
+			},
+		})
+		logger.Info("Scheduled tool calls",
+			zap.String("schedule", sc.Name),
+			zap.String("tool_name", sc.Tool),
+			zap.String("cron", sc.Schedule))
+	}
+
+	return nil
+}
+
+func (ts *toolScheduler) setTools(tools []*definitions.Tool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.tools = tools
+}
+
+// current returns the runtime of the server, and its enabled tool named name, nil if there is none.
+func (ts *toolScheduler) current(name string) (*serverconfig.ServerRuntime, *definitions.Tool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	for _, t := range enabledTools(ts.tools) {
+		if t.Name == name {
+			return ts.runtime, t
+		}
+	}
+	return ts.runtime, nil
+}
+
+// call calls the tool of sc with its arguments, within the concurrency limits of the server, and returns its
+// result. Like the calls of clients, the call is audited, recorded or replayed, and its result is truncated
+// to the maximum response size of the server. Failed calls return a result with an ErrorDetail, and an
+// error if the tool could not be called.
+func (ts *toolScheduler) call(ctx context.Context, sc *serverconfig.ScheduleConfig) (result *mcp.CallToolResult, err error) {
+	runtime, tool := ts.current(sc.Tool)
+	logger := runtime.GetBaseLogger().With(zap.String("schedule", sc.Name), zap.String("tool_name", sc.Tool))
+	if tool == nil {
+		logger.Error("Scheduled tool is not served")
+		return nil, fmt.Errorf("tool '%s' is not served", sc.Tool)
+	}
+
+	if sc.Timeout != "" {
+		timeout, err := time.ParseDuration(sc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ctx, err = scheduledCallContext(ctx, runtime, logger)
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := runtime.GetAuditLogger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+	store, err := runtime.GetRecordingStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording store: %w", err)
+	}
+	invoker, err := invocation.CreateInvoker(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoker for tool %s: %w", tool.Name, err)
+	}
+
+	arguments := json.RawMessage("{}")
+	if sc.Arguments != nil {
+		if arguments, err = json.Marshal(sc.Arguments); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tool.Name, Arguments: arguments}}
+
+	ctx, span := startToolCallSpan(ctx, tool, req)
+	defer func() {
+		endToolCallSpan(span, result, err)
+	}()
+
+	started := time.Now()
+	defer func() {
+		if failed := auditToolCall(ctx, auditLog, tool, arguments, started, false, result, err); failed != nil {
+			result, err = failed, nil
+		}
+	}()
+	defer func() {
+		ensureErrorDetail(result)
+	}()
+
+	arguments, err = tool.ApplyTransforms(arguments)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeValidation, "%v", err), nil
+	}
+	callArguments := arguments
+	arguments, err = tool.ApplyDefaults(arguments)
+	if err != nil {
+		logger.Error("Failed to apply tool defaults", zap.Error(err))
+		return utils.McpTextError("failed to apply the defaults of the tool"), nil
+	}
+	req = withArguments(req, arguments)
+
+	release, err := acquireInvocation(ctx, runtime.GetConcurrencyPool(), tool.Name, "tool", tool.MaxConcurrency)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeInternal, "%v", err), nil
+	}
+	defer release()
+
+	logger.Info("Scheduled tool call started")
+	result, err = invokeTool(ctx, store, invoker, tool, req, callArguments)
+	if err != nil {
+		logger.Error("Scheduled tool call failed", zap.Error(err))
+		// the result is served to clients, so the error is not revealed, like for their own calls
+		if result != nil {
+			return result, nil
+		}
+		return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "tool invocation failed"), nil
+	}
+
+	if truncateToolResult(runtime.Limits, result) {
+		logger.Warn("Tool output exceeds the size limit and was truncated", zap.Int("max_response_bytes", runtime.Limits.MaxResponseBytes))
+	}
+
+	logger.Info("Scheduled tool call completed", zap.Bool("is_error", result.IsError))
+	return result, nil
+}
+
+// scheduledCallContext returns ctx with what the middlewares of the server add to the context of the tool
+// calls of clients: the loggers, the secret store, the HTTP client and the spool of runtime.
+func scheduledCallContext(ctx context.Context, runtime *serverconfig.ServerRuntime, logger *zap.Logger) (context.Context, error) {
+	secretStore, err := runtime.GetSecretStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret store: %w", err)
+	}
+	httpClient, err := runtime.GetHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	responseSpool, err := runtime.GetSpool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response spool: %w", err)
+	}
+
+	ctx = logging.WithBaseLogger(ctx, logger)
+	ctx = logging.WithRequestLogger(ctx, logger)
+	ctx = secrets.WithStore(ctx, secretStore)
+	if tenants := runtime.GetTenants(); tenants != nil {
+		if tenant, ok := tenants.Tenant(tenants.Default); ok {
+			ctx = withTenant(ctx, tenants.Default, tenant)
+		}
+	}
+	if responseSpool != nil {
+		ctx = spool.WithSpool(ctx, responseSpool)
+	}
+	return httpinvocation.WithHTTPClient(ctx, httpClient), nil
+}
+
+// registerScheduleResources adds to s the resources serving the status and the last result of the schedules
+// of mcpServer.
+func registerScheduleResources(s *mcp.Server, mcpServer *mcpserver.MCPServer) {
+	sched := mcpServer.Runtime.GetScheduler()
+	if sched == nil {
+		return
+	}
+
+	for _, sc := range mcpServer.Runtime.Schedules {
+		uri := scheduleResourceURI(sc.Name)
+		s.AddResource(
+			&mcp.Resource{
+				Name:        sc.Name,
+				Description: fmt.Sprintf("Last result of the tool %s, called on the schedule %s", sc.Tool, sc.Schedule),
+				URI:         uri,
+				MIMEType:    "application/json",
+			},
+			func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+				content, err := json.Marshal(newScheduleStatus(sched, sc))
+				if err != nil {
+					return nil, err
+				}
+				return &mcp.ReadResourceResult{
+					Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(content)}},
+				}, nil
+			},
+		)
+	}
+}
+
+// newScheduleStatus returns the status of the schedule sc run by sched.
+func newScheduleStatus(sched *scheduler.Scheduler, sc *serverconfig.ScheduleConfig) *scheduleStatus {
+	status := &scheduleStatus{Name: sc.Name, Tool: sc.Tool, Schedule: sc.Schedule}
+
+	jobStatus, ok := sched.Status(sc.Name)
+	if !ok {
+		return status
+	}
+
+	status.Running = jobStatus.Running
+	if !jobStatus.Next.IsZero() {
+		status.NextRun = &jobStatus.Next
+	}
+	if last := jobStatus.Last; last != nil {
+		status.LastRun = &scheduleRun{StartedAt: last.Started, FinishedAt: last.Finished}
+		if last.Err != nil {
+			status.LastRun.Error = last.Err.Error()
+		}
+		if result, ok := last.Result.(*mcp.CallToolResult); ok && result != nil {
+			status.LastRun.Result = result
+		}
+	}
+
+	return status
+}
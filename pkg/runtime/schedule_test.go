@@ -0,0 +1,143 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestSchedules(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "xls" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"unsupported format"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"report":"` + r.URL.Query().Get("format") + `"}`))
+	}))
+	defer backend.Close()
+
+	tools := []string{`- name: generate_report
+  description: Generate the daily report
+  inputSchema:
+    type: object
+    properties:
+      format:
+        type: string
+  defaults:
+    format: csv
+  requiredScopes:
+    - reports
+  invocation:
+    http:
+      method: GET
+      url: ` + backend.URL + `/report
+`}
+
+	tt := []struct {
+		name              string
+		schedule          *serverconfig.ScheduleConfig
+		expectedText      string
+		expectedErrorCode invocation.ErrorCode
+		expectedError     string
+	}{
+		{
+			name:         "successful call",
+			schedule:     &serverconfig.ScheduleConfig{Name: "report", Tool: "generate_report", Arguments: map[string]any{"format": "pdf"}},
+			expectedText: `{"report":"pdf"}`,
+		},
+		{
+			name:         "defaults of the tool",
+			schedule:     &serverconfig.ScheduleConfig{Name: "report", Tool: "generate_report"},
+			expectedText: `{"report":"csv"}`,
+		},
+		{
+			name:              "failed call",
+			schedule:          &serverconfig.ScheduleConfig{Name: "report", Tool: "generate_report", Arguments: map[string]any{"format": "xls"}},
+			expectedText:      `{"error":"unsupported format"}`,
+			expectedErrorCode: invocation.ErrorCodeBackendStatus,
+		},
+		{
+			name:          "unknown tool",
+			schedule:      &serverconfig.ScheduleConfig{Name: "report", Tool: "send_report"},
+			expectedError: "tool 'send_report' is not served",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tc.schedule.Schedule = "@yearly"
+			tc.schedule.RunOnStart = true
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tools...))
+			mcpServer.Runtime.Schedules = []*serverconfig.ScheduleConfig{tc.schedule}
+			require.NoError(t, startSchedules(ctx, mcpServer, nil, nil))
+
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+			cs, _ := connectTestClient(t, s.Server)
+
+			resources, err := cs.ListResources(ctx, &mcp.ListResourcesParams{})
+			require.NoError(t, err)
+			require.Len(t, resources.Resources, 1)
+			assert.Equal(t, "schedule://report/last", resources.Resources[0].URI)
+
+			var status struct {
+				Name     string     `json:"name"`
+				Tool     string     `json:"tool"`
+				Schedule string     `json:"schedule"`
+				Running  bool       `json:"running"`
+				NextRun  *time.Time `json:"nextRun"`
+				LastRun  *struct {
+					StartedAt  time.Time           `json:"startedAt"`
+					FinishedAt time.Time           `json:"finishedAt"`
+					Error      string              `json:"error"`
+					Result     *mcp.CallToolResult `json:"result"`
+				} `json:"lastRun"`
+			}
+			require.Eventually(t, func() bool {
+				result, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "schedule://report/last"})
+				require.NoError(t, err)
+				require.Len(t, result.Contents, 1)
+				require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &status))
+				return status.NextRun != nil
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assert.Equal(t, "report", status.Name)
+			assert.Equal(t, tc.schedule.Tool, status.Tool)
+			assert.Equal(t, "@yearly", status.Schedule)
+			assert.False(t, status.Running)
+			assert.True(t, status.NextRun.After(time.Now()))
+			require.NotNil(t, status.LastRun)
+			assert.False(t, status.LastRun.FinishedAt.Before(status.LastRun.StartedAt))
+
+			if tc.expectedError != "" {
+				assert.Equal(t, tc.expectedError, status.LastRun.Error)
+				assert.Nil(t, status.LastRun.Result)
+				return
+			}
+			assert.Empty(t, status.LastRun.Error)
+			require.NotNil(t, status.LastRun.Result)
+			require.NotEmpty(t, status.LastRun.Result.Content)
+			assert.Equal(t, tc.expectedText, status.LastRun.Result.Content[0].(*mcp.TextContent).Text)
+			assert.Equal(t, tc.expectedErrorCode != "", status.LastRun.Result.IsError)
+			if tc.expectedErrorCode != "" {
+				detail, ok := invocation.GetErrorDetail(status.LastRun.Result)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedErrorCode, detail.Code)
+			}
+		})
+	}
+}
@@ -3,36 +3,83 @@ package runtime
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
+	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/genmcp/gen-mcp/pkg/health"
 	_ "github.com/genmcp/gen-mcp/pkg/invocation/cli"
-
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/file"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/grpc"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/inline"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/k8s"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/plugin"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/queue"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/script"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/smtp"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/sql"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/ssh"
+	_ "github.com/genmcp/gen-mcp/pkg/invocation/wasm"
+
+	"github.com/genmcp/gen-mcp/pkg/audit"
+	"github.com/genmcp/gen-mcp/pkg/concurrency"
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
 	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
 	"github.com/genmcp/gen-mcp/pkg/invocation"
 	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
 	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
 	"github.com/genmcp/gen-mcp/pkg/mcpserver"
 	"github.com/genmcp/gen-mcp/pkg/oauth"
 	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/observability/tracing"
+	"github.com/genmcp/gen-mcp/pkg/policy"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/sessions"
+	"github.com/genmcp/gen-mcp/pkg/spool"
 )
 
-// makeServerWithoutValidation creates a server without performing validation
-// This is used internally when validation has already been performed
-func makeServerWithoutValidation(mcpServer *mcpserver.MCPServer) (*mcp.Server, error) {
-	return makeServerWithTools(mcpServer, mcpServer.Tools)
+// primitiveServer is an MCP server with the handlers of its resources/list and completion/complete requests,
+// which registerPrimitives updates with the resource templates and prompts of the server when the definitions
+// are reloaded.
+type primitiveServer struct {
+	*mcp.Server
+	lister      *resourceLister
+	completions *completer
+}
+
+// makeServerWithoutValidation creates a server without performing validation, whose lazily loaded tools share
+// the invokers of invokers. This is used internally when validation has already been performed
+func makeServerWithoutValidation(mcpServer *mcpserver.MCPServer, invokers *toolInvokers) (*primitiveServer, error) {
+	return makeServerWithPrimitives(mcpServer, mcpServer, invokers)
 }
 
 func DoRunServer(ctx context.Context, mcpServer *mcpserver.MCPServer) error {
+	return doRunServer(ctx, mcpServer, "", nil)
+}
+
+// doRunServer runs the server, reloading its tool definitions from watchPath whenever that file
+// changes if watchPath is not empty. The admin API, if configured, manages the tools of that file.
+// The tools of the OpenAPI document of the server config, if any, are served next to those of the file.
+// If load is not nil, the whole config is reloaded with it on SIGHUP and through the admin API. The tools of
+// the schedules of the runtime are called until ctx is done, and the webhooks of the runtime are served by its
+// streamable HTTP transports.
+func doRunServer(ctx context.Context, mcpServer *mcpserver.MCPServer, watchPath string, load configLoader) error {
 	// Apply defaults to ensure all config values are set
 	mcpServer.ApplyDefaults()
 
@@ -43,23 +90,105 @@ func DoRunServer(ctx context.Context, mcpServer *mcpserver.MCPServer) error {
 		zap.String("transport_protocol", mcpServer.Runtime.TransportProtocol))
 
 	// Validate the server configuration before running
-	if err := mcpServer.Validate(invocation.InvocationValidator); err != nil {
+	if err := mcpServer.Validate(invocationValidator(mcpServer)); err != nil {
 		logger.Error("Server configuration validation failed before running",
 			zap.String("server_name", mcpServer.Name()),
 			zap.Error(err))
 		return fmt.Errorf("invalid server configuration: %w", err)
 	}
 
+	if tracingConfig := mcpServer.Runtime.TracingConfig; tracingConfig != nil {
+		shutdownTracing, err := tracingConfig.Setup(ctx, mcpServer.Name(), mcpServer.Version())
+		if err != nil {
+			logger.Error("Failed to set up tracing", zap.Error(err))
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		defer func() {
+			// ctx is already cancelled at this point, so flush pending spans with a fresh deadline
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				logger.Warn("Failed to flush traces", zap.Error(err))
+			}
+		}()
+		logger.Info("Tracing enabled")
+	}
+
+	auditLog, err := mcpServer.Runtime.GetAuditLogger()
+	if err != nil {
+		logger.Error("Failed to set up the audit log", zap.Error(err))
+		return fmt.Errorf("failed to set up the audit log: %w", err)
+	}
+	defer func() {
+		// pending records are written before the server exits
+		if err := auditLog.Close(); err != nil {
+			logger.Warn("Failed to close the audit log", zap.Error(err))
+		}
+	}()
+
+	// stop the commands of the upstream MCP servers that calls were forwarded to
+	defer proxy.CloseSessions()
+
+	source, err := newToolDefinitionsSource(ctx, mcpServer, watchPath)
+	if err != nil {
+		logger.Error("Failed to load tool definitions", zap.Error(err))
+		return err
+	}
+
+	var reloader *configReloader
+	if load != nil {
+		reloader = newConfigReloader(mcpServer, load, source)
+		go reloader.reloadOnSignal(ctx)
+	}
+
+	if err := startSchedules(ctx, mcpServer, source, reloader); err != nil {
+		logger.Error("Failed to start schedules", zap.Error(err))
+		return fmt.Errorf("failed to start schedules: %w", err)
+	}
+
+	// the status of the listeners is only collected for the admin API
+	var status *serverStatus
+	if admin := mcpServer.Runtime.Admin; admin != nil {
+		if watchPath == "" {
+			return fmt.Errorf("the admin API requires the server to be run from an MCP file")
+		}
+		status = newServerStatus(mcpServer)
+		var tlsConfig *serverconfig.TLSConfig
+		if mcpServer.Runtime.StreamableHTTPConfig != nil {
+			tlsConfig = mcpServer.Runtime.StreamableHTTPConfig.TLS
+		}
+		stopAdmin, err := startAdminServer(admin, tlsConfig, watchPath, source, mcpServer.Runtime.GetQuotaTracker(), status, reloader, logger)
+		if err != nil {
+			logger.Error("Failed to start admin API", zap.Error(err))
+			return fmt.Errorf("failed to start admin API: %w", err)
+		}
+		defer stopAdmin()
+	}
+
+	if len(mcpServer.Runtime.Listeners) > 0 {
+		return runListeners(ctx, mcpServer, source, status, reloader)
+	}
+
+	return runTransport(ctx, &listener{name: runtimeListenerName, server: mcpServer}, source, status, reloader)
+}
+
+// runTransport runs the server of l on the transport of its runtime, reloading its tool definitions when
+// those of source change if source is not nil, reporting its status to status if not nil, and replacing its
+// server when reloader reloads the config if not nil. If the tools of l are not empty, only the named tools
+// are kept when the tool definitions are reloaded.
+func runTransport(ctx context.Context, l *listener, source *toolDefinitionsSource, status *serverStatus, reloader *configReloader) error {
+	mcpServer := l.server
+	logger := mcpServer.Runtime.GetBaseLogger()
 	logger.Debug("Server configuration validated, selecting transport protocol",
 		zap.String("transport_protocol", mcpServer.Runtime.TransportProtocol))
 
 	switch strings.ToLower(mcpServer.Runtime.TransportProtocol) {
 	case serverconfig.TransportProtocolStreamableHttp:
 		logger.Info("Running server with streamable HTTP transport")
-		return runStreamableHttpServer(ctx, mcpServer)
+		return runStreamableHttpServer(ctx, l, source, status, reloader)
 	case serverconfig.TransportProtocolStdio:
 		logger.Info("Running server with stdio transport")
-		return runStdioServer(ctx, mcpServer)
+		return runStdioServer(ctx, l, source, reloader)
 	default:
 		logger.Error("Invalid transport protocol specified",
 			zap.String("transport_protocol", mcpServer.Runtime.TransportProtocol))
@@ -67,19 +196,99 @@ func DoRunServer(ctx context.Context, mcpServer *mcpserver.MCPServer) error {
 	}
 }
 
+// RunOptions holds optional settings for RunServerWithOptions.
+type RunOptions struct {
+	// WatchToolDefinitions reloads the tools, prompts, resources and resource templates
+	// whenever the MCP file changes, without restarting the server.
+	WatchToolDefinitions bool
+
+	// RecordDir records the tool calls of the server as fixtures in this directory, overriding the
+	// recording of the server config.
+	RecordDir string
+
+	// ReplayDir returns the results of the fixtures of this directory instead of invoking tools,
+	// overriding the recording of the server config.
+	ReplayDir string
+
+	// Profile of the server config file merged onto it. The profile selected by GENMCP_PROFILE is merged
+	// if empty.
+	Profile string
+}
+
 // RunServer runs the server defined in the given config files.
 // It accepts both tool definitions and server config file paths.
 func RunServer(ctx context.Context, toolDefinitionsPath, serverConfigPath string) error {
+	return RunServerWithOptions(ctx, toolDefinitionsPath, serverConfigPath, RunOptions{})
+}
+
+// RunServerWithOptions runs the server defined in the given config files with the given options.
+func RunServerWithOptions(ctx context.Context, toolDefinitionsPath, serverConfigPath string, opts RunOptions) error {
+	mcpServer, err := loadServer(toolDefinitionsPath, serverConfigPath, opts, nil)
+	if err != nil {
+		return err
+	}
+
+	// Now we can safely get the logger (Runtime is guaranteed non-nil after ApplyDefaults)
+	logger := mcpServer.Runtime.GetBaseLogger()
+
+	// Log tool count and server config usage as promised in tutorials
+	numTools := len(mcpServer.Tools)
+	logger.Info(fmt.Sprintf("Loaded %d tools from %s", numTools, toolDefinitionsPath))
+
+	logger.Info(fmt.Sprintf("Using server config from %s", serverConfigPath))
+
+	logger.Info("Starting servers from GenMCP config files",
+		zap.String("tool_definitions_path", toolDefinitionsPath),
+		zap.String("server_config_path", serverConfigPath),
+		zap.String("server_name", mcpServer.Name()),
+		zap.String("server_version", mcpServer.Version()))
+
+	if err := validateServer(mcpServer, toolDefinitionsPath, serverConfigPath, logger); err != nil {
+		return err
+	}
+
+	logger.Debug("GenMCP config files validated successfully, creating server instance")
+
+	// changes made through the admin API are written to the MCP file, and applied by watching it
+	var watchPath string
+	if opts.WatchToolDefinitions || mcpServer.Runtime.Admin != nil {
+		watchPath, err = filepath.Abs(toolDefinitionsPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve MCP file path: %w", err)
+		}
+	}
+
+	return doRunServer(ctx, mcpServer, watchPath, newConfigLoader(toolDefinitionsPath, serverConfigPath, opts))
+}
+
+// newConfigLoader returns a loader of the config files of a server, validating them.
+func newConfigLoader(toolDefinitionsPath, serverConfigPath string, opts RunOptions) configLoader {
+	return func(logger *zap.Logger) (*mcpserver.MCPServer, error) {
+		mcpServer, err := loadServer(toolDefinitionsPath, serverConfigPath, opts, logger)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateServer(mcpServer, toolDefinitionsPath, serverConfigPath, logger); err != nil {
+			return nil, err
+		}
+		return mcpServer, nil
+	}
+}
+
+// loadServer parses the config files of a server, and applies the defaults, the overrides of the environment
+// variables and the recording of opts to them. Warnings are logged with logger, or with the logger of the
+// loaded server if nil.
+func loadServer(toolDefinitionsPath, serverConfigPath string, opts RunOptions, logger *zap.Logger) (*mcpserver.MCPServer, error) {
 	// Parse MCP file
 	toolDefsFile, err := parseToolDefinitionsFile(toolDefinitionsPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse MCP file: %w", err)
+		return nil, fmt.Errorf("failed to parse MCP file: %w", err)
 	}
 
 	// Parse server config file
-	serverConfigFile, err := parseServerConfigFile(serverConfigPath)
+	serverConfigFile, err := parseServerConfigFile(serverConfigPath, opts.Profile)
 	if err != nil {
-		return fmt.Errorf("failed to parse server config file: %w", err)
+		return nil, fmt.Errorf("failed to parse server config file: %w", err)
 	}
 
 	// Combine into MCPServer struct
@@ -95,30 +304,28 @@ func RunServer(ctx context.Context, toolDefinitionsPath, serverConfigPath string
 	// Apply runtime overrides from environment variables
 	envOverrider := serverconfig.NewEnvRuntimeOverrider()
 	if err := envOverrider.ApplyOverrides(mcpServer.Runtime); err != nil {
-		// GetBaseLogger() handles nil Runtime by returning a nop logger
-		logger := mcpServer.Runtime.GetBaseLogger()
+		if logger == nil {
+			// GetBaseLogger() handles nil Runtime by returning a nop logger
+			logger = mcpServer.Runtime.GetBaseLogger()
+		}
 		logger.Warn("Failed to apply overrides from env vars to the mcp server",
 			zap.String("server_name", mcpServer.Name()),
 			zap.Error(err))
 	}
 
-	// Now we can safely get the logger (Runtime is guaranteed non-nil after ApplyDefaults)
-	logger := mcpServer.Runtime.GetBaseLogger()
-
-	// Log tool count and server config usage as promised in tutorials
-	numTools := len(mcpServer.Tools)
-	logger.Info(fmt.Sprintf("Loaded %d tools from %s", numTools, toolDefinitionsPath))
-
-	logger.Info(fmt.Sprintf("Using server config from %s", serverConfigPath))
+	switch {
+	case opts.RecordDir != "":
+		mcpServer.Runtime.Recording = &serverconfig.RecordingConfig{Mode: recording.ModeRecord, Dir: opts.RecordDir}
+	case opts.ReplayDir != "":
+		mcpServer.Runtime.Recording = &serverconfig.RecordingConfig{Mode: recording.ModeReplay, Dir: opts.ReplayDir}
+	}
 
-	logger.Info("Starting servers from GenMCP config files",
-		zap.String("tool_definitions_path", toolDefinitionsPath),
-		zap.String("server_config_path", serverConfigPath),
-		zap.String("server_name", mcpServer.Name()),
-		zap.String("server_version", mcpServer.Version()))
+	return mcpServer, nil
+}
 
-	// Validate after defaults and overrides are applied
-	if err := mcpServer.Validate(invocation.InvocationValidator); err != nil {
+// validateServer validates a server loaded from its config files, after defaults and overrides are applied.
+func validateServer(mcpServer *mcpserver.MCPServer, toolDefinitionsPath, serverConfigPath string, logger *zap.Logger) error {
+	if err := mcpServer.Validate(invocationValidator(mcpServer)); err != nil {
 		logger.Error("GenMCP config file validation failed",
 			zap.String("tool_definitions_path", toolDefinitionsPath),
 			zap.String("server_config_path", serverConfigPath),
@@ -126,9 +333,7 @@ func RunServer(ctx context.Context, toolDefinitionsPath, serverConfigPath string
 		return fmt.Errorf("config files are invalid: %w", err)
 	}
 
-	logger.Debug("GenMCP config files validated successfully, creating server instance")
-
-	return DoRunServer(ctx, mcpServer)
+	return nil
 }
 
 // parseToolDefinitionsFile parses an MCP file
@@ -136,81 +341,49 @@ func parseToolDefinitionsFile(filePath string) (*definitions.MCPToolDefinitionsF
 	return definitions.ParseMCPFile(filePath)
 }
 
-// parseServerConfigFile parses a server config file
-func parseServerConfigFile(filePath string) (*serverconfig.MCPServerConfigFile, error) {
-	return serverconfig.ParseMCPFile(filePath)
+// parseServerConfigFile parses a server config file with the given profile merged onto it
+func parseServerConfigFile(filePath string, profile string) (*serverconfig.MCPServerConfigFile, error) {
+	return serverconfig.ParseMCPFileWithProfile(filePath, profile)
 }
 
-func runStreamableHttpServer(ctx context.Context, mcpServerConfig *mcpserver.MCPServer) error {
+func runStreamableHttpServer(ctx context.Context, l *listener, source *toolDefinitionsSource, status *serverStatus, reloader *configReloader) error {
+	mcpServerConfig := l.server
 	logger := mcpServerConfig.Runtime.GetBaseLogger()
 	httpConfig := mcpServerConfig.Runtime.StreamableHTTPConfig
 	port := httpConfig.Port
-	basePath := httpConfig.BasePath
-	stateless := httpConfig.IsStateless()
 
 	healthChecker := health.NewChecker()
-	// Get health config with defensive nil check
-	healthConfig := httpConfig.Health
-	var livenessPath, readinessPath string
-	if healthConfig != nil {
-		livenessPath = healthConfig.LivenessPath
-		readinessPath = healthConfig.ReadinessPath
-	} else {
-		// Fallback to defaults if ApplyDefaults() wasn't called
-		livenessPath = serverconfig.DefaultLivenessPath
-		readinessPath = serverconfig.DefaultReadinessPath
-	}
 
 	logger.Info("Setting up streamable HTTP server",
 		zap.Int("port", port),
-		zap.String("base_path", basePath),
-		zap.Bool("stateless", stateless))
-
-	sm := NewServerManager(mcpServerConfig)
-	// Create a root mux to handle different endpoints
-	mux := http.NewServeMux()
+		zap.String("base_path", httpConfig.BasePath),
+		zap.Bool("stateless", httpConfig.IsStateless()))
 
-	// Register health endpoints if enabled (default: true)
-	if healthConfig.IsEnabled() {
-		mux.HandleFunc(livenessPath, healthChecker.LivenessHandler)
-		mux.HandleFunc(readinessPath, healthChecker.ReadinessHandler)
-		logger.Debug("Registered health endpoints",
-			zap.String("liveness_path", livenessPath),
-			zap.String("readiness_path", readinessPath))
+	sessionStore, err := mcpServerConfig.Runtime.NewSessionStore()
+	if err != nil {
+		logger.Error("Failed to create session store", zap.Error(err))
+		return fmt.Errorf("failed to create session store: %w", err)
 	}
 
-	logger.Debug("Creating MCP handler")
-	// Set up MCP server under /mcp (or whatever is under BasePath)
-	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
-		s, err := sm.ServerFromContext(r.Context())
-		if err != nil {
-			logger.Warn("Failed to get server from context in handler",
-				zap.Error(err),
-				zap.String("request_uri", r.RequestURI))
-			return nil
-		}
+	generation := newHTTPGeneration(mcpServerConfig, healthChecker, sessionStore)
+	handler := &generationHandler{current: generation, logger: logger}
+	status.addListener(l.name, generation.manager)
 
-		return s
-	}, &mcp.StreamableHTTPOptions{
-		Stateless: stateless,
-	})
-
-	logger.Debug("Setting up OAuth middleware")
-	oauthHandler := oauth.Middleware(mcpServerConfig)(handler)
-
-	mux.Handle(basePath, oauthHandler)
-	logger.Debug("Registered MCP handler", zap.String("path", basePath))
-
-	// Set up OAuth protected resource metadata endpoint under / if needed
-	if mcpServerConfig.Runtime.StreamableHTTPConfig.Auth != nil {
-		logger.Debug("Setting up OAuth protected resource metadata endpoint")
-		mux.HandleFunc(oauth.ProtectedResourceMetadataEndpoint, oauth.ProtectedResourceMetadataHandler(mcpServerConfig))
-		logger.Debug("Registered OAuth metadata handler", zap.String("path", oauth.ProtectedResourceMetadataEndpoint))
+	if source != nil {
+		source.onChange(func(defs definitions.MCPToolDefinitions) error {
+			return handler.manager().Reload(toolSubset(defs, l.tools))
+		})
 	}
+	reloader.onReload(func(next *mcpserver.MCPServer) error {
+		generation := newHTTPGeneration(l.reloaded(next), healthChecker, sessionStore)
+		handler.swap(ctx, generation, reloadDrainTimeout)
+		status.addListener(l.name, generation.manager)
+		return nil
+	})
 
 	// Create the HTTP server
 	srv := &http.Server{
-		Handler: mux,
+		Handler: handler,
 	}
 
 	// Create listener first so we know the port is bound before setting ready
@@ -226,22 +399,20 @@ func runStreamableHttpServer(ctx context.Context, mcpServerConfig *mcpserver.MCP
 		tlsConfig := mcpServerConfig.Runtime.StreamableHTTPConfig.TLS
 		logger.Info("Configuring TLS",
 			zap.String("cert_file", tlsConfig.CertFile),
-			zap.String("key_file", tlsConfig.KeyFile))
+			zap.String("key_file", tlsConfig.KeyFile),
+			zap.Bool("client_auth", tlsConfig.ClientAuth != nil))
 
-		cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		serverTLSConfig, err := tlsConfig.BuildTLSConfig()
 		if err != nil {
-			logger.Error("Failed to load TLS certificates", zap.Error(err))
+			logger.Error("Failed to build TLS config", zap.Error(err))
 			listenerErr := listener.Close()
 			if listenerErr != nil {
 				logger.Error("Failed to shut down listener", zap.Error(listenerErr))
 			}
-			return fmt.Errorf("failed to load TLS certificates: %w", err)
+			return err
 		}
 
-		listener = tls.NewListener(listener, &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			MinVersion:   tls.VersionTLS12,
-		})
+		listener = tls.NewListener(listener, serverTLSConfig)
 	}
 
 	logger.Info(fmt.Sprintf("Starting MCP server on port %d", port))
@@ -265,6 +436,8 @@ func runStreamableHttpServer(ctx context.Context, mcpServerConfig *mcpserver.MCP
 		logger.Info("Received shutdown signal, shutting down HTTP server gracefully")
 		// Mark as not ready so k8s stops routing traffic during drain
 		healthChecker.SetReady(false)
+		// stored sessions stay in the store, clients resume them on other replicas
+		handler.closeStoredSessions()
 		if err := srv.Shutdown(context.Background()); err != nil {
 			logger.Error("Error during server shutdown", zap.Error(err))
 			return err
@@ -277,18 +450,120 @@ func runStreamableHttpServer(ctx context.Context, mcpServerConfig *mcpserver.MCP
 	}
 }
 
-func runStdioServer(ctx context.Context, mcpServerConfig *mcpserver.MCPServer) error {
+// newHTTPGeneration creates the handler of the streamable HTTP transport serving mcpServerConfig, with its
+// health endpoints reporting the readiness of healthChecker. Sessions are saved to sessionStore if not nil.
+func newHTTPGeneration(mcpServerConfig *mcpserver.MCPServer, healthChecker health.Checker, sessionStore sessions.Store) *httpGeneration {
+	logger := mcpServerConfig.Runtime.GetBaseLogger()
+	httpConfig := mcpServerConfig.Runtime.StreamableHTTPConfig
+	basePath := httpConfig.BasePath
+
+	// Get health config with defensive nil check
+	healthConfig := httpConfig.Health
+	var livenessPath, readinessPath string
+	if healthConfig != nil {
+		livenessPath = healthConfig.LivenessPath
+		readinessPath = healthConfig.ReadinessPath
+	} else {
+		// Fallback to defaults if ApplyDefaults() wasn't called
+		livenessPath = serverconfig.DefaultLivenessPath
+		readinessPath = serverconfig.DefaultReadinessPath
+	}
+
+	// Create a root mux to handle different endpoints
+	mux := http.NewServeMux()
+
+	// Register health endpoints if enabled (default: true)
+	if healthConfig.IsEnabled() {
+		mux.HandleFunc(livenessPath, healthChecker.LivenessHandler)
+		mux.HandleFunc(readinessPath, healthChecker.ReadinessHandler)
+		logger.Debug("Registered health endpoints",
+			zap.String("liveness_path", livenessPath),
+			zap.String("readiness_path", readinessPath))
+	}
+
+	// Set up MCP server under /mcp (or whatever is under BasePath)
+	generation := newMCPGeneration(mcpServerConfig, sessionStore)
+	mux.Handle(basePath, generation.handler)
+	logger.Debug("Registered MCP handler", zap.String("path", basePath))
+
+	registerWebhookHandlers(mux, mcpServerConfig)
+
+	// Set up OAuth protected resource metadata endpoint under / if needed
+	if auth := httpConfig.Auth; auth != nil && auth.UsesOAuth() {
+		logger.Debug("Setting up OAuth protected resource metadata endpoint")
+		mux.HandleFunc(oauth.ProtectedResourceMetadataEndpoint, oauth.ProtectedResourceMetadataHandler(mcpServerConfig))
+		logger.Debug("Registered OAuth metadata handler", zap.String("path", oauth.ProtectedResourceMetadataEndpoint))
+	}
+
+	generation.handler = mux
+	return generation
+}
+
+// newMCPGeneration creates the handler of the MCP endpoint serving mcpServerConfig, whatever the path of the
+// requests. Sessions are saved to sessionStore if not nil.
+func newMCPGeneration(mcpServerConfig *mcpserver.MCPServer, sessionStore sessions.Store) *httpGeneration {
+	logger := mcpServerConfig.Runtime.GetBaseLogger()
+	httpConfig := mcpServerConfig.Runtime.StreamableHTTPConfig
+
+	sm := NewServerManager(mcpServerConfig)
+
+	logger.Debug("Creating MCP handler")
+	getServer := func(r *http.Request) *mcp.Server {
+		s, err := sm.ServerFromContext(r.Context())
+		if err != nil {
+			logger.Warn("Failed to get server from context in handler",
+				zap.Error(err),
+				zap.String("request_uri", r.RequestURI))
+			return nil
+		}
+
+		return s
+	}
+
+	generation := &httpGeneration{manager: sm}
+	var handler http.Handler
+	if sessionStore != nil {
+		logger.Info("Storing sessions",
+			zap.String("store", httpConfig.Sessions.Store),
+			zap.Duration("ttl", httpConfig.Sessions.GetTTL()))
+		generation.sessions = newSessionHandler(getServer, sessionStore, httpConfig.Sessions.GetTTL(), logger)
+		handler = generation.sessions
+	} else {
+		handler = mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+			Stateless: httpConfig.IsStateless(),
+		})
+	}
+
+	logger.Debug("Setting up auth middleware")
+	generation.handler = oauth.Middleware(mcpServerConfig)(sm.toolsetsMiddleware(handler))
+	return generation
+}
+
+func runStdioServer(ctx context.Context, l *listener, source *toolDefinitionsSource, reloader *configReloader) error {
+	mcpServerConfig := l.server
 	logger := mcpServerConfig.Runtime.GetBaseLogger()
 	logger.Info("Setting up stdio server",
 		zap.String("server_name", mcpServerConfig.Name()),
 		zap.String("server_version", mcpServerConfig.Version()))
 
-	s, err := makeServerWithoutValidation(mcpServerConfig)
+	invokers := &toolInvokers{}
+	s, err := makeServerWithoutValidation(mcpServerConfig, invokers)
 	if err != nil {
 		logger.Error("Failed to create stdio server", zap.Error(err))
 		return fmt.Errorf("failed to create server: %w", err)
 	}
 
+	// the single session of the stdio transport cannot be drained, so its server is updated in place
+	serverReloader := &serverReloader{server: s, mcpServer: mcpServerConfig, invokers: invokers}
+	if source != nil {
+		source.onChange(func(defs definitions.MCPToolDefinitions) error {
+			return serverReloader.Reload(toolSubset(defs, l.tools))
+		})
+	}
+	reloader.onReload(func(next *mcpserver.MCPServer) error {
+		return serverReloader.Replace(l.reloaded(next))
+	})
+
 	logger.Info("Starting stdio server")
 	if err := s.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		logger.Error("Stdio server failed", zap.Error(err))
@@ -339,14 +614,54 @@ func checkPrimitiveAuthorization(ctx context.Context, requiredScopes []string, p
 	return nil
 }
 
-// createAuthorizedToolHandler wraps a tool handler with authorization checks
-func createAuthorizedToolHandler(tool *definitions.Tool) (mcp.ToolHandler, error) {
-	invoker, err := invocation.CreateInvoker(tool)
+// withArguments returns a copy of req with the given arguments, leaving req unchanged for the other handlers
+// of the request.
+func withArguments(req *mcp.CallToolRequest, arguments json.RawMessage) *mcp.CallToolRequest {
+	params := *req.Params
+	params.Arguments = arguments
+	withArgs := *req
+	withArgs.Params = &params
+	return &withArgs
+}
+
+// createAuthorizedToolHandler wraps a tool handler with authorization checks, the size limits of limits,
+// the concurrency limits of pool, the audit log auditLog, the recording or replay of the calls by store,
+// the authorization policy of policyEngine, the quotas of the clients of quotas and the quotas of the tenants
+// of tenantQuotas. The invoker of the tool is only created when it is first called if lazy is set, and shared
+// with the other servers of invokers.
+func createAuthorizedToolHandler(tool *definitions.Tool, invokers *toolInvokers, lazy bool, limits *serverconfig.LimitsConfig, pool *concurrency.Pool, auditLog *audit.Logger,
+	store *recording.Store, policyEngine policy.Engine, quotas *quota.Tracker, tenantQuotas *quota.Tracker) (mcp.ToolHandler, error) {
+	getInvoker, err := invokers.toolInvoker(tool, lazy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create invoker for tool %s: %w", tool.Name, err)
 	}
 
-	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	outputSchema := tool.ResolvedOutputSchema
+	if tool.OutputSchema != nil && outputSchema == nil {
+		outputSchema, err = tool.OutputSchema.Resolve(nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output schema for tool %s: %w", tool.Name, err)
+		}
+	}
+
+	return func(ctx context.Context, req *mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		ctx, span := startToolCallSpan(ctx, tool, req)
+		defer func() {
+			endToolCallSpan(span, result, err)
+		}()
+
+		// The arguments sent by the client are audited, before defaults, which can hold secrets, are applied
+		started := time.Now()
+		denied := false
+		defer func() {
+			if failed := auditToolCall(ctx, auditLog, tool, req.Params.Arguments, started, denied, result, err); failed != nil {
+				result, err = failed, nil
+			}
+		}()
+		defer func() {
+			ensureErrorDetail(result)
+		}()
+
 		clientLogger := logging.FromContext(ctx) // Sent to MCP client
 
 		// Check if user has required scopes for this tool
@@ -357,13 +672,73 @@ func createAuthorizedToolHandler(tool *definitions.Tool) (mcp.ToolHandler, error
 				zap.String("tool_name", tool.Name),
 				zap.Error(err))
 			// Return generic error to client - don't reveal tool name or specific authorization failure
-			return utils.McpTextError("forbidden: insufficient permissions"), nil
+			denied = true
+			return utils.McpCodedError(invocation.ErrorCodeAuth, "forbidden: insufficient permissions"), nil
+		}
+
+		if err := checkArgumentSize(limits, len(req.Params.Arguments)); err != nil {
+			logging.BaseFromContext(ctx).Warn("Tool arguments exceed the size limit",
+				zap.String("tool_name", tool.Name),
+				zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeValidation, "%v", err), nil
+		}
+
+		arguments, rejected := elicitMissingArguments(ctx, req, tool)
+		if rejected != nil {
+			return rejected, nil
+		}
+
+		arguments, err = tool.ApplyTransforms(arguments)
+		if err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeValidation, "%v", err), nil
+		}
+
+		// The policy is evaluated before defaults are applied, so that their values are not sent to OPA
+		arguments, rejected, denied = authorizeCall(ctx, policyEngine, tool, arguments)
+		if rejected != nil {
+			return rejected, nil
+		}
+
+		// Only authorized calls are counted against the quotas of the caller
+		if rejected := checkQuota(ctx, quotas, tool.Name); rejected != nil {
+			denied = true
+			return rejected, nil
+		}
+		if rejected := checkTenantQuota(ctx, tenantQuotas, tool.Name); rejected != nil {
+			denied = true
+			return rejected, nil
+		}
+
+		callArguments := arguments
+		arguments, err = tool.ApplyDefaults(arguments)
+		if err != nil {
+			// Defaults can hold the values of environment variables, so the error is only logged server-side
+			logging.BaseFromContext(ctx).Error("Failed to apply tool defaults",
+				zap.String("tool_name", tool.Name),
+				zap.Error(err))
+			return utils.McpTextError("failed to apply the defaults of the tool"), nil
+		}
+		req = withArguments(req, arguments)
+
+		invoker, err := getInvoker()
+		if err != nil {
+			// Invalid templates of lazily loaded tools are only found when the tools are first called
+			logging.BaseFromContext(ctx).Error("Failed to create invoker for tool",
+				zap.String("tool_name", tool.Name),
+				zap.Error(err))
+			return utils.McpCodedError(invocation.ErrorCodeInternal, "failed to create invoker for tool %s: %v", tool.Name, err), nil
+		}
+
+		release, err := acquireInvocation(ctx, pool, tool.Name, "tool", tool.MaxConcurrency)
+		if err != nil {
+			return nil, err
 		}
+		defer release()
 
 		// Client can see their own successful tool invocations
 		clientLogger.Info("Tool invocation started", zap.String("tool_name", tool.Name))
 
-		result, err := invoker.Invoke(ctx, req)
+		result, err = invokeTool(ctx, store, invoker, tool, req, callArguments)
 		if err != nil {
 			// Log detailed error server-side only
 			baseLogger := logging.BaseFromContext(ctx)
@@ -374,19 +749,79 @@ func createAuthorizedToolHandler(tool *definitions.Tool) (mcp.ToolHandler, error
 			clientLogger.Error("Tool invocation failed",
 				zap.String("tool_name", tool.Name),
 				zap.String("error", "invocation error"))
+			// Invokers return JSON-RPC errors deliberately, when configured to report failures as protocol errors
+			var rpcErr *jsonrpc.Error
+			if errors.As(err, &rpcErr) {
+				return nil, rpcErr
+			}
 			// Return result (may contain partial output) but with generic error to prevent info leakage
 			if result != nil {
 				return result, nil
 			}
-			return utils.McpTextError("tool invocation failed"), nil
+			return utils.McpCodedError(invocation.CodeOf(err, invocation.ErrorCodeInternal), "tool invocation failed"), nil
+		}
+
+		if err := applyStructuredContent(tool, result); err != nil {
+			logging.BaseFromContext(ctx).Warn("Tool output has no structured content",
+				zap.String("tool_name", tool.Name),
+				zap.Error(err))
+			return utils.McpTextError("tool output has no structured content: %v", err), nil
+		}
+
+		if outputSchema != nil {
+			if err := invocation.ValidateToolOutput(result, tool.OutputSchema, outputSchema, tool.CoerceOutputTypes); err != nil {
+				logging.BaseFromContext(ctx).Warn("Tool output does not match output schema",
+					zap.String("tool_name", tool.Name),
+					zap.Error(err))
+				return utils.McpTextError("tool output does not match outputSchema: %v", err), nil
+			}
+		}
+
+		result, err = formatToolResult(tool, callArguments, result)
+		if err != nil {
+			logging.BaseFromContext(ctx).Error("Failed to format the tool result",
+				zap.String("tool_name", tool.Name),
+				zap.Error(err))
+			return utils.McpTextError("failed to format the result of the tool"), nil
+		}
+
+		if truncateToolResult(limits, result) {
+			logging.BaseFromContext(ctx).Warn("Tool output exceeds the size limit and was truncated",
+				zap.String("tool_name", tool.Name),
+				zap.Int("max_response_bytes", limits.MaxResponseBytes))
 		}
 
+		result = postProcessResult(ctx, req, tool, result)
+
 		clientLogger.Info("Tool invocation completed successfully", zap.String("tool_name", tool.Name))
 		return result, nil
 	}, nil
 }
 
-func createAuthorizedPromptHandler(prompt *definitions.Prompt) (mcp.PromptHandler, error) {
+// startToolCallSpan starts the server span of a tools/call request. Over streamable HTTP, the span
+// continues the trace of the client if the request has a traceparent header.
+func startToolCallSpan(ctx context.Context, tool *definitions.Tool, req *mcp.CallToolRequest) (context.Context, trace.Span) {
+	if req.Extra != nil {
+		ctx = tracing.Extract(ctx, req.Extra.Header)
+	}
+
+	return tracing.Start(ctx, "tools/call "+tool.Name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("mcp.method.name", "tools/call"),
+			attribute.String("gen_ai.tool.name", tool.Name),
+			attribute.String("genmcp.invocation.type", tool.GetInvocationType()),
+		))
+}
+
+func endToolCallSpan(span trace.Span, result *mcp.CallToolResult, err error) {
+	if err == nil && result != nil && result.IsError {
+		tracing.SetError(span, "tool call returned an error result")
+	}
+	tracing.End(span, err)
+}
+
+func createAuthorizedPromptHandler(prompt *definitions.Prompt, limits *serverconfig.LimitsConfig, pool *concurrency.Pool) (mcp.PromptHandler, error) {
 	invoker, err := invocation.CreatePromptInvoker(prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create invoker for prompt %s: %w", prompt.Name, err)
@@ -406,6 +841,19 @@ func createAuthorizedPromptHandler(prompt *definitions.Prompt) (mcp.PromptHandle
 			return utils.McpPromptTextError("forbidden: insufficient permissions"), nil
 		}
 
+		if err := checkArgumentSize(limits, promptArgumentsSize(req.Params.Arguments)); err != nil {
+			logging.BaseFromContext(ctx).Warn("Prompt arguments exceed the size limit",
+				zap.String("prompt_name", prompt.Name),
+				zap.Error(err))
+			return utils.McpPromptTextError("%v", err), nil
+		}
+
+		release, err := acquireInvocation(ctx, pool, prompt.Name, "prompt", 0)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
 		// Client can see their own successful prompt invocations
 		clientLogger.Info("Prompt invocation started", zap.String("prompt_name", prompt.Name))
 
@@ -427,12 +875,18 @@ func createAuthorizedPromptHandler(prompt *definitions.Prompt) (mcp.PromptHandle
 			return utils.McpPromptTextError("prompt invocation failed"), nil
 		}
 
+		if truncatePromptResult(limits, result) {
+			logging.BaseFromContext(ctx).Warn("Prompt output exceeds the size limit and was truncated",
+				zap.String("prompt_name", prompt.Name),
+				zap.Int("max_response_bytes", limits.MaxResponseBytes))
+		}
+
 		clientLogger.Info("Prompt invocation completed successfully", zap.String("prompt_name", prompt.Name))
 		return result, nil
 	}, nil
 }
 
-func createAuthorizedResourceHandler(resource *definitions.Resource) (mcp.ResourceHandler, error) {
+func createAuthorizedResourceHandler(resource *definitions.Resource, limits *serverconfig.LimitsConfig, pool *concurrency.Pool) (mcp.ResourceHandler, error) {
 	invoker, err := invocation.CreateResourceInvoker(resource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create invoker for resource %s: %w", resource.Name, err)
@@ -452,6 +906,12 @@ func createAuthorizedResourceHandler(resource *definitions.Resource) (mcp.Resour
 			return utils.McpResourceTextError("forbidden: insufficient permissions"), nil
 		}
 
+		release, err := acquireInvocation(ctx, pool, resource.Name, "resource", 0)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
 		// Client can see their own successful resource access
 		clientLogger.Info("Resource access started", zap.String("resource_name", resource.Name))
 
@@ -473,12 +933,18 @@ func createAuthorizedResourceHandler(resource *definitions.Resource) (mcp.Resour
 			return utils.McpResourceTextError("resource access failed"), nil
 		}
 
+		if truncateResourceResult(limits, result) {
+			logging.BaseFromContext(ctx).Warn("Resource content exceeds the size limit and was truncated",
+				zap.String("resource_name", resource.Name),
+				zap.Int("max_response_bytes", limits.MaxResponseBytes))
+		}
+
 		clientLogger.Info("Resource access completed successfully", zap.String("resource_name", resource.Name))
 		return result, nil
 	}, nil
 }
 
-func createAuthorizedResourceTemplateHandler(resourceTemplate *definitions.ResourceTemplate) (mcp.ResourceHandler, error) {
+func createAuthorizedResourceTemplateHandler(resourceTemplate *definitions.ResourceTemplate, limits *serverconfig.LimitsConfig, pool *concurrency.Pool) (mcp.ResourceHandler, error) {
 	invoker, err := invocation.CreateResourceTemplateInvoker(resourceTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create invoker for resource template %s: %w", resourceTemplate.Name, err)
@@ -497,6 +963,12 @@ func createAuthorizedResourceTemplateHandler(resourceTemplate *definitions.Resou
 			return utils.McpResourceTextError("forbidden: insufficient permissions"), nil
 		}
 
+		release, err := acquireInvocation(ctx, pool, resourceTemplate.Name, "resource_template", 0)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
 		// Client can see their own successful resource template access
 		clientLogger.Info("Resource template access started", zap.String("resource_template_name", resourceTemplate.Name))
 
@@ -518,37 +990,86 @@ func createAuthorizedResourceTemplateHandler(resourceTemplate *definitions.Resou
 			return utils.McpResourceTextError("resource template access failed"), nil
 		}
 
+		if truncateResourceResult(limits, result) {
+			logging.BaseFromContext(ctx).Warn("Resource template content exceeds the size limit and was truncated",
+				zap.String("resource_template_name", resourceTemplate.Name),
+				zap.Int("max_response_bytes", limits.MaxResponseBytes))
+		}
+
 		clientLogger.Info("Resource template access completed successfully", zap.String("resource_template_name", resourceTemplate.Name))
 		return result, nil
 	}, nil
 }
 
-// makeServerWithTools makes a server using the server metadata in mcpServer but with the tools specified in tools
-// this is useful for creating servers with filtered tool lists
-func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.Tool) (*mcp.Server, error) {
+// makeServerWithPrimitives makes a server using the server metadata in mcpServer but with the tools, prompts, resources
+// and resource templates of primitives. This is useful for creating servers with filtered primitive lists. Lazily
+// loaded tools share their invokers with the other servers of invokers.
+func makeServerWithPrimitives(mcpServer *mcpserver.MCPServer, primitives *mcpserver.MCPServer, invokers *toolInvokers) (*primitiveServer, error) {
 	logger := mcpServer.Runtime.GetBaseLogger()
-	logger.Debug("Building MCP server with tools",
+	logger.Debug("Building MCP server with primitives",
 		zap.String("server_name", mcpServer.Name()),
 		zap.String("server_version", mcpServer.Version()),
-		zap.Int("num_tools", len(tools)),
-		zap.Int("num_prompts", len(mcpServer.Prompts)),
-		zap.Int("num_resources", len(mcpServer.Resources)),
-		zap.Int("num_resource_templates", len(mcpServer.ResourceTemplates)))
+		zap.Int("num_tools", len(primitives.Tools)),
+		zap.Int("num_prompts", len(primitives.Prompts)),
+		zap.Int("num_resources", len(primitives.Resources)),
+		zap.Int("num_resource_templates", len(primitives.ResourceTemplates)))
 
 	opts := &mcp.ServerOptions{
-		HasTools:     len(mcpServer.Tools) > 0,
-		HasPrompts:   len(mcpServer.Prompts) > 0,
-		HasResources: len(mcpServer.Resources)+len(mcpServer.ResourceTemplates) > 0,
+		PageSize:   mcpServer.Runtime.GetPageSize(),
+		HasTools:   len(mcpServer.Tools) > 0,
+		HasPrompts: len(mcpServer.Prompts) > 0,
+		HasResources: len(mcpServer.Resources)+len(mcpServer.ResourceTemplates) > 0 || mcpServer.Runtime.GetScheduler() != nil ||
+			len(mcpServer.Runtime.Webhooks) > 0 || mcpServer.Runtime.Spool != nil,
 	}
 	if mcpServer.Instructions() != "" {
 		logger.Debug("Adding server instructions")
 		opts.Instructions = mcpServer.Instructions()
 	}
 
+	// Completions are always advertised, as reloads may add prompts and resource templates to complete
+	completions := &completer{pool: mcpServer.Runtime.GetConcurrencyPool()}
+	opts.CompletionHandler = completions.complete
+
+	// Sessions can subscribe to the resources of webhooks, to be notified of their events
+	receivers, err := mcpServer.Runtime.GetWebhookReceivers()
+	if err != nil {
+		logger.Error("Failed to create webhook receivers", zap.Error(err))
+		return nil, fmt.Errorf("failed to create webhook receivers: %w", err)
+	}
+	subscriptions := &webhookSubscriptions{receivers: receivers}
+	if len(receivers) > 0 {
+		opts.SubscribeHandler = subscriptions.subscribe
+		opts.UnsubscribeHandler = subscriptions.unsubscribe
+	}
+
 	s := mcp.NewServer(&mcp.Implementation{
 		Name:    mcpServer.Name(),
 		Version: mcpServer.Version(),
 	}, opts)
+	subscriptions.server = s
+
+	// Added first, so that the list invocations of resource templates run after the other middlewares
+	lister := &resourceLister{pool: mcpServer.Runtime.GetConcurrencyPool()}
+	s.AddReceivingMiddleware(lister.middleware())
+
+	// Added before the secrets and claims middlewares, so that it runs after them and identifies the tenant
+	// by the claims of the caller
+	if tenants := mcpServer.Runtime.GetTenants(); tenants != nil {
+		logger.Debug("Adding tenant middleware", zap.Int("tenants", len(tenants.Tenants)))
+		s.AddReceivingMiddleware(withTenantMiddleware(tenants))
+	}
+
+	// Added before the logging middleware, so that it runs after it and redacts secrets from its loggers
+	secretStore, err := mcpServer.Runtime.GetSecretStore()
+	if err != nil {
+		logger.Error("Failed to create secret store", zap.Error(err))
+		return nil, fmt.Errorf("failed to create secret store: %w", err)
+	}
+	logger.Debug("Adding secrets middleware", zap.Bool("has_secrets_config", mcpServer.Runtime != nil && mcpServer.Runtime.Secrets != nil))
+	s.AddReceivingMiddleware(secrets.WithSecretsMiddleware(secretStore))
+
+	logger.Debug("Adding claims middleware")
+	s.AddReceivingMiddleware(oauth.WithClaimsMiddleware())
 
 	logger.Debug("Adding logging middleware")
 	s.AddReceivingMiddleware(logging.WithLoggingMiddleware(logger))
@@ -563,10 +1084,70 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 	logger.Debug("Adding HTTP client middleware", zap.Bool("has_custom_tls", hasCustomTLS))
 	s.AddReceivingMiddleware(httpinvocation.WithHTTPClientMiddleware(httpClient))
 
+	// Large responses of tool calls are spooled to disk, and served by a resource template
+	responseSpool, err := mcpServer.Runtime.GetSpool()
+	if err != nil {
+		logger.Error("Failed to create response spool", zap.Error(err))
+		return nil, fmt.Errorf("failed to create response spool: %w", err)
+	}
+	if responseSpool != nil {
+		logger.Debug("Adding spool middleware", zap.Int64("threshold_bytes", responseSpool.ThresholdBytes()))
+		s.AddReceivingMiddleware(spool.WithSpoolMiddleware(responseSpool))
+	}
+
+	// Only bearer tokens validated as OAuth access tokens may be forwarded to backends
+	if rt := mcpServer.Runtime; rt != nil && rt.TransportProtocol == serverconfig.TransportProtocolStreamableHttp &&
+		rt.StreamableHTTPConfig != nil && rt.StreamableHTTPConfig.Auth != nil && rt.StreamableHTTPConfig.Auth.UsesOAuth() {
+		logger.Debug("Adding validated bearer tokens middleware")
+		s.AddReceivingMiddleware(httpinvocation.WithValidatedBearerTokensMiddleware())
+	}
+
+	ps := &primitiveServer{Server: s, lister: lister, completions: completions}
+	serverErr := registerPrimitives(ps, primitives, invokers)
+	registerScheduleResources(s, mcpServer)
+	registerWebhookResources(s, mcpServer, receivers)
+	registerSpoolResources(s, responseSpool)
+	if serverErr != nil {
+		logger.Warn("Server created with some errors", zap.Error(serverErr))
+	} else {
+		logger.Info("Server created successfully with all components")
+	}
+
+	return ps, serverErr
+}
+
+// registerPrimitives adds the enabled tools and the prompts, resources and resource templates of mcpServer to s.
+// Primitives that are already registered with the same name (or URI) are replaced. Lazily loaded tools share
+// their invokers with the other servers of invokers.
+func registerPrimitives(s *primitiveServer, mcpServer *mcpserver.MCPServer, invokers *toolInvokers) error {
+	logger := mcpServer.Runtime.GetBaseLogger()
+
+	var limits *serverconfig.LimitsConfig
+	if mcpServer.Runtime != nil {
+		limits = mcpServer.Runtime.Limits
+	}
+	pool := mcpServer.Runtime.GetConcurrencyPool()
+	auditLog, err := mcpServer.Runtime.GetAuditLogger()
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	store, err := mcpServer.Runtime.GetRecordingStore()
+	if err != nil {
+		return fmt.Errorf("failed to create recording store: %w", err)
+	}
+	policyEngine, err := mcpServer.Runtime.GetPolicyEngine()
+	if err != nil {
+		return fmt.Errorf("failed to create policy engine: %w", err)
+	}
+	quotas := mcpServer.Runtime.GetQuotaTracker()
+	tenantQuotas := mcpServer.Runtime.GetTenantQuotaTracker()
+	lazy := mcpServer.Runtime.LazyToolLoading()
+
 	var serverErr error
+	tools := enabledTools(mcpServer.Tools)
 	logger.Debug("Registering tools", zap.Int("count", len(tools)))
 	for _, t := range tools {
-		handler, err := createAuthorizedToolHandler(t)
+		handler, err := createAuthorizedToolHandler(t, invokers, lazy, limits, pool, auditLog, store, policyEngine, quotas, tenantQuotas)
 		if err != nil {
 			logger.Error("Failed to create tool handler",
 				zap.String("tool_name", t.Name),
@@ -612,7 +1193,7 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 
 	logger.Debug("Registering prompts", zap.Int("count", len(mcpServer.Prompts)))
 	for _, p := range mcpServer.Prompts {
-		handler, err := createAuthorizedPromptHandler(p)
+		handler, err := createAuthorizedPromptHandler(p, limits, pool)
 		if err != nil {
 			logger.Error("Failed to create prompt handler",
 				zap.String("prompt_name", p.Name),
@@ -625,6 +1206,7 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 			&mcp.Prompt{
 				Name:        p.Name,
 				Description: p.Description,
+				Arguments:   promptArguments(p),
 			},
 			handler,
 		)
@@ -633,7 +1215,7 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 
 	logger.Debug("Registering resources", zap.Int("count", len(mcpServer.Resources)))
 	for _, r := range mcpServer.Resources {
-		handler, err := createAuthorizedResourceHandler(r)
+		handler, err := createAuthorizedResourceHandler(r, limits, pool)
 		if err != nil {
 			logger.Error("Failed to create resource handler",
 				zap.String("resource_name", r.Name),
@@ -657,7 +1239,7 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 
 	logger.Debug("Registering resource templates", zap.Int("count", len(mcpServer.ResourceTemplates)))
 	for _, rt := range mcpServer.ResourceTemplates {
-		handler, err := createAuthorizedResourceTemplateHandler(rt)
+		handler, err := createAuthorizedResourceTemplateHandler(rt, limits, pool)
 		if err != nil {
 			logger.Error("Failed to create resource template handler",
 				zap.String("resource_template_name", rt.Name),
@@ -678,11 +1260,57 @@ func makeServerWithTools(mcpServer *mcpserver.MCPServer, tools []*definitions.To
 		logger.Debug("Registered resource template", zap.String("resource_template_name", rt.Name))
 	}
 
-	if serverErr != nil {
-		logger.Warn("Server created with some errors", zap.Error(serverErr))
-	} else {
-		logger.Info("Server created successfully with all components")
+	if err := s.lister.setTemplates(mcpServer.ResourceTemplates); err != nil {
+		logger.Error("Failed to create resource template list invokers", zap.Error(err))
+		serverErr = errors.Join(serverErr, err)
+	}
+
+	if err := s.completions.setPrimitives(mcpServer.Prompts, mcpServer.ResourceTemplates); err != nil {
+		logger.Error("Failed to create completion invokers", zap.Error(err))
+		serverErr = errors.Join(serverErr, err)
+	}
+
+	return serverErr
+}
+
+// promptArguments returns the arguments of a prompt listed to clients, so that they can ask for their values
+// and complete them: the arguments of the prompt if set, or else the properties of its input schema.
+func promptArguments(p *definitions.Prompt) []*mcp.PromptArgument {
+	var arguments []*mcp.PromptArgument
+	if len(p.Arguments) > 0 {
+		for _, a := range p.Arguments {
+			arguments = append(arguments, &mcp.PromptArgument{
+				Name:        a.Name,
+				Title:       a.Title,
+				Description: a.Description,
+				Required:    a.Required,
+			})
+		}
+		return arguments
+	}
+
+	if p.InputSchema == nil {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.InputSchema.Properties)) {
+		arguments = append(arguments, &mcp.PromptArgument{
+			Name:        name,
+			Title:       p.InputSchema.Properties[name].Title,
+			Description: p.InputSchema.Properties[name].Description,
+			Required:    slices.Contains(p.InputSchema.Required, name),
+		})
+	}
+	return arguments
+}
+
+// enabledTools returns the tools that are not disabled.
+func enabledTools(tools []*definitions.Tool) []*definitions.Tool {
+	enabled := make([]*definitions.Tool, 0, len(tools))
+	for _, t := range tools {
+		if !t.Disabled {
+			enabled = append(enabled, t)
+		}
 	}
 
-	return s, serverErr
+	return enabled
 }
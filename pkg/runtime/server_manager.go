@@ -1,25 +1,38 @@
 package runtime
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
 
 	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
 	"github.com/genmcp/gen-mcp/pkg/mcpserver"
 	"github.com/genmcp/gen-mcp/pkg/oauth"
 )
 
 type ServerManager struct {
 	mcpServer           *mcpserver.MCPServer
+	logger              *zap.Logger
 	mu                  sync.RWMutex
-	scopedServers       map[string]*mcp.Server // set of MCP Servers by oauth scopes
-	filteredToolServers map[string]*mcp.Server // as a fallback, the set of MCP Servers that have the same set of filtered tools
+	scopedServers       map[toolFilter]*primitiveServer // set of MCP Servers by oauth scopes and selected toolsets
+	filteredToolServers map[string]*primitiveServer     // as a fallback, the set of MCP Servers that have the same set of filtered primitives
+	invokers            *toolInvokers                   // invokers of the lazily loaded tools, shared by the servers
+
+	// state reported by Status, guarded by mu
+	configHash   string
+	reloads      int
+	reloadedAt   time.Time
+	recentErrors []ErrorStatus // the last maxRecentErrors errors, oldest first
 }
 
 func NewServerManager(server *mcpserver.MCPServer) *ServerManager {
@@ -30,79 +43,66 @@ func NewServerManager(server *mcpserver.MCPServer) *ServerManager {
 
 	return &ServerManager{
 		mcpServer:           server,
-		scopedServers:       make(map[string]*mcp.Server),
-		filteredToolServers: make(map[string]*mcp.Server),
+		logger:              logger,
+		scopedServers:       make(map[toolFilter]*primitiveServer),
+		filteredToolServers: make(map[string]*primitiveServer),
+		invokers:            &toolInvokers{},
+		configHash:          configHash(server.MCPToolDefinitions),
 	}
 }
 
-// ServerFromContext returns a server based on the auth scopes in the context
-// It first checks if there is an existing server for the same set of scopes
-// It then checks if after filtering the tools for the received scopes there is an existing server with the same tool set
-// Finally, it creates a new server with the correct set of tools and caches the server for future connections
+// ServerFromContext returns a server based on the auth scopes and the toolsets selected by the client in the context
+// It first checks if there is an existing server for the same set of scopes and toolsets
+// It then checks if after filtering the primitives for the received scopes there is an existing server with the same primitives
+// Finally, it creates a new server with the correct set of tools, prompts, resources and resource templates and caches the
+// server for future connections
 func (sm *ServerManager) ServerFromContext(ctx context.Context) (*mcp.Server, error) {
-	logger := sm.mcpServer.Runtime.GetBaseLogger()
+	logger := sm.logger
 
 	claims := oauth.GetClaimsFromContext(ctx)
 	if claims == nil {
 		claims = &oauth.TokenClaims{}
 	}
 
+	filter := toolFilter{scope: claims.Scope, toolsets: strings.Join(toolsetsFromContext(ctx), ",")}
+
 	logger.Debug("Looking up server for context",
 		zap.String("user_subject", claims.Subject),
-		zap.String("scopes", claims.Scope))
+		zap.String("scopes", claims.Scope),
+		zap.String("toolsets", filter.toolsets))
 
 	sm.mu.RLock()
-	if s, ok := sm.scopedServers[claims.Scope]; ok {
-		sm.mu.RUnlock()
-		logger.Debug("Server cache hit by scopes",
-			zap.String("user_subject", claims.Subject),
-			zap.String("scopes", claims.Scope))
-		return s, nil
-	}
-
-	filteredTools := sm.filterToolsForScope(claims.Scope)
-	filteredToolNames := make([]string, len(filteredTools))
-	for i, t := range filteredTools {
-		filteredToolNames[i] = t.Name
-	}
-
-	slices.Sort(filteredToolNames)
-
-	filteredToolNamesKey := strings.Join(filteredToolNames, ",")
-
-	logger.Debug("Filtered tools for user scopes",
-		zap.String("user_subject", claims.Subject),
-		zap.Int("total_tools", len(sm.mcpServer.Tools)),
-		zap.Int("filtered_tools", len(filteredTools)),
-		zap.Strings("tool_names", filteredToolNames))
-
-	if s, ok := sm.filteredToolServers[filteredToolNamesKey]; ok {
-		sm.mu.RUnlock()
-		logger.Debug("Server cache hit by filtered tools",
-			zap.String("user_subject", claims.Subject),
-			zap.String("tool_names_key", filteredToolNamesKey))
-		return s, nil
+	s, filtered, filteredToolNamesKey := sm.cachedServer(filter, claims.Subject)
+	sm.mu.RUnlock()
+	if s != nil {
+		return s.Server, nil
 	}
 
-	// no server in either map - need to build the server here
-	sm.mu.RUnlock()
+	// no server in either map - need to build the server here. The cache is looked up again under the write
+	// lock, as a reload may have replaced the definitions the primitives were filtered from in the meantime.
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	s, filtered, filteredToolNamesKey = sm.cachedServer(filter, claims.Subject)
+	if s != nil {
+		return s.Server, nil
+	}
+
 	logger.Info("Creating new server instance for user scopes",
 		zap.String("user_subject", claims.Subject),
 		zap.String("scopes", claims.Scope),
-		zap.Int("filtered_tools", len(filteredTools)))
+		zap.Int("filtered_tools", len(filtered.Tools)))
 
-	s, err := makeServerWithTools(sm.mcpServer, filteredTools)
+	s, err := makeServerWithPrimitives(sm.mcpServer, filtered, sm.invokers)
 	if err != nil {
 		logger.Error("Failed to create server for user scopes",
 			zap.String("user_subject", claims.Subject),
 			zap.Error(err))
+		sm.recordError(fmt.Errorf("failed to create server for scopes '%s': %w", claims.Scope, err))
 		return nil, err
 	}
 
-	sm.scopedServers[claims.Scope] = s
+	sm.scopedServers[filter] = s
 	sm.filteredToolServers[filteredToolNamesKey] = s
 
 	logger.Info("Server created and cached successfully",
@@ -110,40 +110,299 @@ func (sm *ServerManager) ServerFromContext(ctx context.Context) (*mcp.Server, er
 		zap.Int("total_scoped_servers", len(sm.scopedServers)),
 		zap.Int("total_filtered_servers", len(sm.filteredToolServers)))
 
-	return s, nil
+	return s.Server, nil
+}
+
+// cachedServer returns the cached server for filter, if any, and otherwise the primitives filtered for it and
+// their key. It must be called with mu locked.
+func (sm *ServerManager) cachedServer(filter toolFilter, subject string) (*primitiveServer, *mcpserver.MCPServer, string) {
+	logger := sm.logger
+
+	if s, ok := sm.scopedServers[filter]; ok {
+		logger.Debug("Server cache hit by scopes",
+			zap.String("user_subject", subject),
+			zap.String("scopes", filter.scope),
+			zap.String("toolsets", filter.toolsets))
+		return s, nil, ""
+	}
+
+	filtered := sm.filterForScope(filter)
+	filteredToolNamesKey := primitivesKey(filtered)
+
+	logger.Debug("Filtered primitives for user scopes",
+		zap.String("user_subject", subject),
+		zap.Int("total_tools", len(sm.mcpServer.Tools)),
+		zap.Int("filtered_tools", len(filtered.Tools)),
+		zap.Int("filtered_prompts", len(filtered.Prompts)),
+		zap.Int("filtered_resources", len(filtered.Resources)),
+		zap.Int("filtered_resource_templates", len(filtered.ResourceTemplates)),
+		zap.Strings("tool_names", toolNames(filtered.Tools)))
+
+	if s, ok := sm.filteredToolServers[filteredToolNamesKey]; ok {
+		logger.Debug("Server cache hit by filtered primitives",
+			zap.String("user_subject", subject),
+			zap.String("tool_names_key", filteredToolNamesKey))
+		return s, nil, ""
+	}
+
+	return nil, filtered, filteredToolNamesKey
 }
 
-func (sm *ServerManager) filterToolsForScope(scope string) []*definitions.Tool {
-	logger := sm.mcpServer.Runtime.GetBaseLogger()
-	var allowedTools []*definitions.Tool
+// Reload replaces the tool definitions served by the manager. Cached servers are updated in place,
+// which notifies their connected clients that the lists of tools, prompts and resources changed.
+// If a cached server was shared by scopes that no longer see the same tools, it keeps serving the
+// first of those scopes and a new server is created for the others on their next connection. Servers
+// that now serve the same primitives as another one are synced too, but only the first is kept cached.
+func (sm *ServerManager) Reload(defs definitions.MCPToolDefinitions) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	oldServer := sm.mcpServer
+	newServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: defs,
+		MCPServerConfig:    oldServer.MCPServerConfig,
+	}
+	sm.mcpServer = newServer
+
+	filters := slices.SortedFunc(maps.Keys(sm.scopedServers), compareToolFilters)
+
+	scopedServers := make(map[toolFilter]*primitiveServer, len(sm.scopedServers))
+	filteredToolServers := make(map[string]*primitiveServer, len(sm.filteredToolServers))
+	synced := make(map[*primitiveServer]bool)
+
+	var err error
+	for _, filter := range filters {
+		s := sm.scopedServers[filter]
+		newFiltered := sm.filterForScope(filter)
+		newToolNamesKey := primitivesKey(newFiltered)
+
+		if synced[s] {
+			if existing, ok := filteredToolServers[newToolNamesKey]; ok {
+				scopedServers[filter] = existing
+				continue
+			}
+			sm.logger.Debug("Cached server no longer matches scopes, dropping it from the cache",
+				zap.String("scopes", filter.scope),
+				zap.String("toolsets", filter.toolsets))
+			continue
+		}
+
+		// every server is synced, even the ones dropped from the cache, as their sessions stay connected
+		oldFiltered := filterForScope(oldServer, filter, sm.logger)
+		if syncErr := syncServerPrimitives(s, oldFiltered, newFiltered, sm.invokers); syncErr != nil {
+			err = errors.Join(err, syncErr)
+		}
+		synced[s] = true
+
+		if existing, ok := filteredToolServers[newToolNamesKey]; ok {
+			sm.logger.Debug("Cached server now serves the same primitives as another one, dropping it from the cache",
+				zap.String("scopes", filter.scope),
+				zap.String("toolsets", filter.toolsets))
+			scopedServers[filter] = existing
+			continue
+		}
 
-	userScopes := strings.Split(scope, " ")
+		scopedServers[filter] = s
+		filteredToolServers[newToolNamesKey] = s
+	}
+
+	sm.scopedServers = scopedServers
+	sm.filteredToolServers = filteredToolServers
+	sm.invokers.retain(defs.Tools)
+	sm.configHash = configHash(defs)
+	sm.reloads++
+	sm.reloadedAt = time.Now()
+	if err != nil {
+		sm.recordError(fmt.Errorf("failed to reload tool definitions: %w", err))
+	}
+
+	sm.logger.Info("Reloaded tool definitions",
+		zap.Int("num_tools", len(defs.Tools)),
+		zap.Int("updated_servers", len(synced)))
+
+	return err
+}
+
+// hasSession returns whether a session with the ID is connected to a server of the manager.
+func (sm *ServerManager) hasSession(id string) bool {
+	for _, s := range sm.servers() {
+		for session := range s.Sessions() {
+			if session.ID() == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sessionCount returns the number of sessions connected to the servers of the manager.
+func (sm *ServerManager) sessionCount() int {
+	count := 0
+	for _, s := range sm.servers() {
+		for range s.Sessions() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// closeSessions closes the sessions connected to the servers of the manager.
+func (sm *ServerManager) closeSessions() {
+	for _, s := range sm.servers() {
+		for session := range s.Sessions() {
+			if err := session.Close(); err != nil {
+				sm.logger.Debug("Failed to close session", zap.String("session_id", session.ID()), zap.Error(err))
+			}
+		}
+	}
+}
+
+// servers returns the cached servers of the manager, each once.
+func (sm *ServerManager) servers() []*primitiveServer {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	servers := make([]*primitiveServer, 0, len(sm.scopedServers))
+	for _, s := range sm.scopedServers {
+		if !slices.Contains(servers, s) {
+			servers = append(servers, s)
+		}
+	}
+
+	return servers
+}
+
+func (sm *ServerManager) filterForScope(filter toolFilter) *mcpserver.MCPServer {
+	logger := sm.logger
+	logger.Debug("Filtering primitives for scope",
+		zap.String("scope", filter.scope),
+		zap.String("toolsets", filter.toolsets),
+		zap.Int("total_tools", len(sm.mcpServer.Tools)))
+
+	filtered := filterForScope(sm.mcpServer, filter, logger)
+
+	logger.Debug("Primitive filtering completed",
+		zap.Int("total_tools", len(sm.mcpServer.Tools)),
+		zap.Int("allowed_tools", len(filtered.Tools)),
+		zap.Int("allowed_prompts", len(filtered.Prompts)),
+		zap.Int("allowed_resources", len(filtered.Resources)),
+		zap.Int("allowed_resource_templates", len(filtered.ResourceTemplates)))
+
+	return filtered
+}
+
+// toolFilter identifies the primitives served to a client: those allowed by its OAuth scopes, and among the
+// tools, those of the toolsets it selected.
+type toolFilter struct {
+	scope    string
+	toolsets string // sorted names of the selected toolsets, joined with commas, empty if every tool is served
+}
+
+func compareToolFilters(a, b toolFilter) int {
+	return cmp.Or(strings.Compare(a.scope, b.scope), strings.Compare(a.toolsets, b.toolsets))
+}
+
+// filterForScope returns a copy of mcpServer with only the tools, prompts, resources and resource templates
+// whose required scopes are all contained in the scope of filter, so that the others are never listed to the
+// user, and only the tools of the toolsets of filter and the tools without toolsets if it has toolsets.
+// Disabled tools are left out.
+func filterForScope(mcpServer *mcpserver.MCPServer, filter toolFilter, logger *zap.Logger) *mcpserver.MCPServer {
+	userScopes := strings.Split(filter.scope, " ")
 	scopesLookup := make(map[string]struct{}, len(userScopes))
 	for _, s := range userScopes {
 		scopesLookup[s] = struct{}{}
 	}
 
-	logger.Debug("Filtering tools for scope",
-		zap.String("scope", scope),
-		zap.Int("total_tools", len(sm.mcpServer.Tools)))
+	filtered := &mcpserver.MCPServer{
+		MCPToolDefinitions: mcpServer.MCPToolDefinitions,
+		MCPServerConfig:    mcpServer.MCPServerConfig,
+	}
+	filtered.Tools = filterPrimitives(filterToolsets(enabledTools(mcpServer.Tools), filter.toolsets), scopesLookup, logger)
+	filtered.Prompts = filterPrimitives(mcpServer.Prompts, scopesLookup, logger)
+	filtered.Resources = filterPrimitives(mcpServer.Resources, scopesLookup, logger)
+	filtered.ResourceTemplates = filterPrimitives(mcpServer.ResourceTemplates, scopesLookup, logger)
+
+	return filtered
+}
+
+// filterToolsets returns the tools of the comma-separated toolsets, and the tools without toolsets. It returns
+// every tool if toolsets is empty.
+func filterToolsets(tools []*definitions.Tool, toolsets string) []*definitions.Tool {
+	if toolsets == "" {
+		return tools
+	}
+
+	selected := strings.Split(toolsets, ",")
+	var filtered []*definitions.Tool
+	for _, t := range tools {
+		if len(t.Toolsets) == 0 || slices.ContainsFunc(t.Toolsets, func(toolset string) bool {
+			return slices.Contains(selected, toolset)
+		}) {
+			filtered = append(filtered, t)
+		}
+	}
 
-	for _, tool := range sm.mcpServer.Tools {
-		if err := checkAuthorization(tool.RequiredScopes, scopesLookup); err != nil {
-			logger.Debug("Tool filtered out due to insufficient scopes",
-				zap.String("tool_name", tool.Name))
+	return filtered
+}
+
+// filterPrimitives returns the primitives whose required scopes are all contained in userScopes.
+func filterPrimitives[T invocation.Primitive](primitives []T, userScopes map[string]struct{}, logger *zap.Logger) []T {
+	var allowed []T
+	for _, p := range primitives {
+		if err := checkAuthorization(p.GetRequiredScopes(), userScopes); err != nil {
+			logger.Debug("Primitive filtered out due to insufficient scopes",
+				zap.String("primitive_type", p.PrimitiveType()),
+				zap.String("primitive_name", p.GetName()))
 			continue
 		}
 
-		logger.Debug("Tool included for user",
-			zap.String("tool_name", tool.Name))
-		allowedTools = append(allowedTools, tool)
+		logger.Debug("Primitive included for user",
+			zap.String("primitive_type", p.PrimitiveType()),
+			zap.String("primitive_name", p.GetName()))
+		allowed = append(allowed, p)
 	}
 
-	logger.Debug("Tool filtering completed",
-		zap.Int("total_tools", len(sm.mcpServer.Tools)),
-		zap.Int("allowed_tools", len(allowedTools)))
+	return allowed
+}
+
+// primitivesKey identifies the set of primitives of mcpServer, so that scopes allowing the same primitives share a server.
+func primitivesKey(mcpServer *mcpserver.MCPServer) string {
+	prompts := make([]string, len(mcpServer.Prompts))
+	for i, p := range mcpServer.Prompts {
+		prompts[i] = p.Name
+	}
+	resources := make([]string, len(mcpServer.Resources))
+	for i, r := range mcpServer.Resources {
+		resources[i] = r.URI
+	}
+	resourceTemplates := make([]string, len(mcpServer.ResourceTemplates))
+	for i, rt := range mcpServer.ResourceTemplates {
+		resourceTemplates[i] = rt.URITemplate
+	}
+	slices.Sort(prompts)
+	slices.Sort(resources)
+	slices.Sort(resourceTemplates)
+
+	return strings.Join([]string{
+		strings.Join(toolNames(mcpServer.Tools), ","),
+		strings.Join(prompts, ","),
+		strings.Join(resources, ","),
+		strings.Join(resourceTemplates, ","),
+	}, "|")
+}
+
+// toolNames returns the sorted names of tools.
+func toolNames(tools []*definitions.Tool) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name
+	}
+
+	slices.Sort(names)
 
-	return allowedTools
+	return names
 }
 
 func checkAuthorization(requiredScopes []string, userScopes map[string]struct{}) error {
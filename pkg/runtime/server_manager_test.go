@@ -0,0 +1,187 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+)
+
+const scopedDefinitions = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test-server
+version: "1.0.0"
+tools:
+- name: list_users
+  description: "Lists users"
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/users
+- name: delete_user
+  description: "Deletes a user"
+  inputSchema:
+    type: object
+    properties: {}
+  requiredScopes: [admin]
+  invocation:
+    http:
+      method: DELETE
+      url: http://localhost:8080/users
+prompts:
+- name: summarize
+  description: "Summarizes text"
+  inputSchema:
+    type: object
+    properties: {}
+  invocation:
+    http:
+      method: POST
+      url: http://localhost:8080/summarize
+- name: audit_report
+  description: "Writes an audit report"
+  inputSchema:
+    type: object
+    properties: {}
+  requiredScopes: [admin]
+  invocation:
+    http:
+      method: POST
+      url: http://localhost:8080/audit
+resources:
+- name: readme
+  description: "The readme"
+  uri: http://localhost:8080/readme
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/readme
+- name: access_log
+  description: "The access log"
+  uri: http://localhost:8080/access.log
+  requiredScopes: [admin, logs:read]
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/access.log
+resourceTemplates:
+- name: user_profile
+  description: "The profile of a user"
+  uriTemplate: "users://{id}"
+  inputSchema:
+    type: object
+    properties:
+      id:
+        type: string
+  requiredScopes: [admin]
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/users/{id}
+`
+
+func TestServerManagerFiltersPrimitivesByScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(scopedDefinitions), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name                      string
+		scope                     string
+		expectedTools             []string
+		expectedPrompts           []string
+		expectedResources         []string
+		expectedResourceTemplates []string
+	}{
+		{
+			name:              "no scopes",
+			expectedTools:     []string{"list_users"},
+			expectedPrompts:   []string{"summarize"},
+			expectedResources: []string{"readme"},
+		},
+		{
+			name:                      "admin",
+			scope:                     "admin",
+			expectedTools:             []string{"list_users", "delete_user"},
+			expectedPrompts:           []string{"summarize", "audit_report"},
+			expectedResources:         []string{"readme"},
+			expectedResourceTemplates: []string{"user_profile"},
+		},
+		{
+			name:                      "all required scopes",
+			scope:                     "logs:read admin",
+			expectedTools:             []string{"list_users", "delete_user"},
+			expectedPrompts:           []string{"summarize", "audit_report"},
+			expectedResources:         []string{"readme", "access_log"},
+			expectedResourceTemplates: []string{"user_profile"},
+		},
+	}
+
+	sm := NewServerManager(newTestMCPServer(t, *defs))
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Scope: tc.scope})
+			s, err := sm.ServerFromContext(ctx)
+			require.NoError(t, err)
+
+			cs, _ := connectTestClient(t, s)
+
+			assert.ElementsMatch(t, tc.expectedTools, listToolNames(t, cs))
+
+			prompts, err := cs.ListPrompts(context.Background(), nil)
+			require.NoError(t, err)
+			var promptNames []string
+			for _, p := range prompts.Prompts {
+				promptNames = append(promptNames, p.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedPrompts, promptNames)
+
+			resources, err := cs.ListResources(context.Background(), nil)
+			require.NoError(t, err)
+			var resourceNames []string
+			for _, r := range resources.Resources {
+				resourceNames = append(resourceNames, r.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedResources, resourceNames)
+
+			resourceTemplates, err := cs.ListResourceTemplates(context.Background(), nil)
+			require.NoError(t, err)
+			var resourceTemplateNames []string
+			for _, rt := range resourceTemplates.ResourceTemplates {
+				resourceTemplateNames = append(resourceTemplateNames, rt.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedResourceTemplates, resourceTemplateNames)
+		})
+	}
+}
+
+func TestServerManagerSharesServersWithSamePrimitives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(scopedDefinitions), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	sm := NewServerManager(newTestMCPServer(t, *defs))
+
+	serverFor := func(scope string) *mcp.Server {
+		s, err := sm.ServerFromContext(oauth.AddClaimsToContext(context.Background(), &oauth.TokenClaims{Scope: scope}))
+		require.NoError(t, err)
+		return s
+	}
+
+	// logs:read alone allows no more primitives than no scopes, admin allows more
+	assert.Same(t, serverFor(""), serverFor("logs:read"))
+	assert.NotSame(t, serverFor(""), serverFor("admin"))
+	assert.NotSame(t, serverFor("admin"), serverFor("admin logs:read"))
+}
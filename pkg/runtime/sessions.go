@@ -0,0 +1,326 @@
+package runtime
+
+import (
+	"crypto/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/sessions"
+)
+
+const sessionIDHeader = "Mcp-Session-Id"
+
+// sessionHandler serves the streamable HTTP transport of a stateful server, saving its sessions to a
+// store. A request for a session this replica of the server does not serve is not rejected if the session
+// is in the store: the session is restored, so that clients can be load balanced between replicas and
+// resume their session after it was closed for being idle.
+type sessionHandler struct {
+	getServer func(*http.Request) *mcp.Server
+	store     sessions.Store
+	ttl       time.Duration // idle sessions are closed after it, never if 0
+	logger    *zap.Logger
+
+	// stream events are kept in memory, so that streams can be resumed on the replica serving them
+	events *mcp.MemoryEventStore
+
+	mu       sync.Mutex
+	sessions map[string]*localSession
+}
+
+// localSession is a session served by this replica.
+type localSession struct {
+	session   *mcp.ServerSession
+	transport *mcp.StreamableServerTransport
+	subject   string
+
+	mu       sync.Mutex
+	requests int // number of POST requests being served, during which the session is not idle
+	timer    *time.Timer
+}
+
+func newSessionHandler(getServer func(*http.Request) *mcp.Server, store sessions.Store, ttl time.Duration, logger *zap.Logger) *sessionHandler {
+	return &sessionHandler{
+		getServer: getServer,
+		store:     store,
+		ttl:       ttl,
+		logger:    logger,
+		events:    mcp.NewMemoryEventStore(nil),
+		sessions:  make(map[string]*localSession),
+	}
+}
+
+func (h *sessionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(sessionIDHeader)
+
+	switch r.Method {
+	case http.MethodGet, http.MethodPost:
+	case http.MethodDelete:
+		if sessionID == "" {
+			http.Error(w, "Bad Request: DELETE requires an Mcp-Session-Id header", http.StatusBadRequest)
+			return
+		}
+		h.deleteSession(w, r, sessionID)
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ls *localSession
+	if sessionID == "" {
+		if r.Method == http.MethodGet {
+			http.Error(w, "Bad Request: GET requires an Mcp-Session-Id header", http.StatusBadRequest)
+			return
+		}
+
+		server := h.getServer(r)
+		if server == nil {
+			http.Error(w, "no server available", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		sessionID = rand.Text()
+		ls, err = h.connect(r, server, sessionID, nil)
+		if err != nil {
+			h.logger.Error("Failed to create session", zap.Error(err))
+			http.Error(w, "failed connection", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		var status int
+		ls, status = h.lookupSession(r, sessionID)
+		if ls == nil {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+
+	if ls.subject != requestSubject(r) {
+		http.Error(w, "session user mismatch", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		ls.transport.ServeHTTP(w, r)
+		return
+	}
+
+	ls.startRequest()
+	defer ls.endRequest(h.ttl)
+	ls.transport.ServeHTTP(w, r)
+
+	initializeParams := ls.session.InitializeParams()
+	if initializeParams == nil {
+		// initialization failed
+		_ = ls.session.Close()
+		return
+	}
+
+	// saving the session on every request extends its expiry in the store
+	if err := h.store.Save(r.Context(), sessionID, &sessions.Session{
+		InitializeParams: initializeParams,
+		Subject:          ls.subject,
+	}); err != nil {
+		h.logger.Warn("Failed to save session", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
+
+// lookupSession returns the session id served by this replica, restoring it from the store if this
+// replica does not serve it. If it cannot, it returns the HTTP status to respond with.
+func (h *sessionHandler) lookupSession(r *http.Request, id string) (*localSession, int) {
+	h.mu.Lock()
+	ls, ok := h.sessions[id]
+	h.mu.Unlock()
+	if ok {
+		return ls, http.StatusOK
+	}
+
+	stored, ok, err := h.store.Load(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to load session", zap.String("session_id", id), zap.Error(err))
+		return nil, http.StatusInternalServerError
+	}
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	if stored.Subject != requestSubject(r) {
+		return nil, http.StatusForbidden
+	}
+
+	// the toolsets selected by the initialize request are kept in its parameters
+	if toolsetsFromContext(r.Context()) == nil && stored.InitializeParams != nil {
+		if toolsets, _ := toolsetsFromMeta(stored.InitializeParams.Meta); toolsets != nil {
+			r = r.WithContext(withToolsets(r.Context(), toolsets))
+		}
+	}
+
+	server := h.getServer(r)
+	if server == nil {
+		return nil, http.StatusBadRequest
+	}
+
+	ls, err = h.connect(r, server, id, &mcp.ServerSessionState{
+		InitializeParams:  stored.InitializeParams,
+		InitializedParams: &mcp.InitializedParams{},
+	})
+	if err != nil {
+		h.logger.Error("Failed to restore session", zap.String("session_id", id), zap.Error(err))
+		return nil, http.StatusInternalServerError
+	}
+
+	h.logger.Debug("Restored session", zap.String("session_id", id))
+	return ls, http.StatusOK
+}
+
+// connect connects server to a new session id, in the given state, or uninitialized if state is nil.
+// If another request connected the session concurrently, that session is returned instead.
+func (h *sessionHandler) connect(r *http.Request, server *mcp.Server, id string, state *mcp.ServerSessionState) (*localSession, error) {
+	transport := &mcp.StreamableServerTransport{
+		SessionID:  id,
+		EventStore: h.events,
+	}
+
+	var opts *mcp.ServerSessionOptions
+	if state != nil {
+		opts = &mcp.ServerSessionOptions{State: state}
+	}
+
+	// the session outlives the request, the context is detached by the SDK
+	session, err := server.Connect(r.Context(), transport, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := &localSession{
+		session:   session,
+		transport: transport,
+		subject:   requestSubject(r),
+	}
+	if h.ttl > 0 {
+		ls.timer = time.AfterFunc(h.ttl, func() {
+			h.logger.Debug("Closing idle session", zap.String("session_id", id))
+			_ = session.Close()
+		})
+	}
+
+	h.mu.Lock()
+	if existing, ok := h.sessions[id]; ok {
+		h.mu.Unlock()
+		ls.stopTimer()
+		_ = session.Close()
+		return existing, nil
+	}
+	h.sessions[id] = ls
+	h.mu.Unlock()
+
+	go func() {
+		_ = session.Wait()
+
+		ls.stopTimer()
+		h.mu.Lock()
+		if h.sessions[id] == ls {
+			delete(h.sessions, id)
+		}
+		h.mu.Unlock()
+	}()
+
+	return ls, nil
+}
+
+// deleteSession closes the session id and removes it from the store, as requested by the client.
+func (h *sessionHandler) deleteSession(w http.ResponseWriter, r *http.Request, id string) {
+	subject := requestSubject(r)
+
+	h.mu.Lock()
+	ls, ok := h.sessions[id]
+	h.mu.Unlock()
+	if ok {
+		if ls.subject != subject {
+			http.Error(w, "session user mismatch", http.StatusForbidden)
+			return
+		}
+		_ = ls.session.Close()
+	} else {
+		stored, ok, err := h.store.Load(r.Context(), id)
+		if err != nil {
+			h.logger.Error("Failed to load session", zap.String("session_id", id), zap.Error(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if ok && stored.Subject != subject {
+			http.Error(w, "session user mismatch", http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := h.store.Delete(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete session", zap.String("session_id", id), zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// closeAll closes the sessions served by this replica. They stay in the store, so that clients can resume
+// them on another replica.
+func (h *sessionHandler) closeAll() {
+	h.mu.Lock()
+	open := make([]*localSession, 0, len(h.sessions))
+	for _, ls := range h.sessions {
+		open = append(open, ls)
+	}
+	h.mu.Unlock()
+
+	for _, ls := range open {
+		_ = ls.session.Close()
+	}
+}
+
+// startRequest pauses the idle timer of the session while a request is served.
+func (ls *localSession) startRequest() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.timer != nil && ls.requests == 0 {
+		ls.timer.Stop()
+	}
+	ls.requests++
+}
+
+// endRequest restarts the idle timer of the session once no request is served.
+func (ls *localSession) endRequest(ttl time.Duration) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.requests--
+	if ls.timer != nil && ls.requests == 0 {
+		ls.timer.Reset(ttl)
+	}
+}
+
+func (ls *localSession) stopTimer() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.timer != nil {
+		ls.timer.Stop()
+		ls.timer = nil
+	}
+}
+
+// requestSubject returns the subject of the OAuth token of the request, if any.
+func requestSubject(r *http.Request) string {
+	claims := oauth.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		return ""
+	}
+	return claims.Subject
+}
@@ -0,0 +1,234 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/oauth"
+	"github.com/genmcp/gen-mcp/pkg/sessions"
+)
+
+// newTestReplicas creates n session handlers of the same server sharing store, and serves them behind a
+// round robin load balancer. Requests are authenticated as the subject of their X-Subject header.
+func newTestReplicas(t *testing.T, n int, store sessions.Store, ttl time.Duration) (*httptest.Server, []*sessionHandler) {
+	t.Helper()
+
+	sm := NewServerManager(newTestMCPServer(t, loadTestDefinitions(t, reloadTestTool("first"), reloadTestTool("second"))))
+	getServer := func(r *http.Request) *mcp.Server {
+		s, err := sm.ServerFromContext(r.Context())
+		require.NoError(t, err)
+		return s
+	}
+
+	replicas := make([]*sessionHandler, n)
+	for i := range replicas {
+		replicas[i] = newSessionHandler(getServer, store, ttl, zap.NewNop())
+	}
+
+	var next atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subject := r.Header.Get("X-Subject"); subject != "" {
+			r = r.WithContext(oauth.AddClaimsToContext(r.Context(), &oauth.TokenClaims{Subject: subject}))
+		}
+		replicas[int(next.Add(1))%n].ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		for _, replica := range replicas {
+			replica.closeAll()
+		}
+		server.Close()
+	})
+
+	return server, replicas
+}
+
+func connectHTTPTestClient(t *testing.T, endpoint string) *mcp.ClientSession {
+	t.Helper()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:             endpoint,
+		MaxRetries:           -1,
+		DisableStandaloneSSE: true,
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+
+	return cs
+}
+
+func TestSessionHandlerResumesSessions(t *testing.T) {
+	tt := []struct {
+		name     string
+		replicas int
+		ttl      time.Duration
+		idle     time.Duration // pause between requests
+	}{
+		{
+			name:     "single replica",
+			replicas: 1,
+		},
+		{
+			name:     "load balanced between replicas",
+			replicas: 3,
+		},
+		{
+			name:     "restored after being closed for being idle",
+			replicas: 1,
+			ttl:      200 * time.Millisecond,
+			idle:     400 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store := sessions.NewMemoryStore(time.Minute, 0)
+			server, replicas := newTestReplicas(t, tc.replicas, store, tc.ttl)
+
+			cs := connectHTTPTestClient(t, server.URL)
+			for range 3 {
+				assert.Equal(t, []string{"first", "second"}, listToolNames(t, cs))
+				time.Sleep(tc.idle)
+			}
+
+			stored, ok, err := store.Load(context.Background(), cs.ID())
+			require.NoError(t, err)
+			require.True(t, ok, "the session should be saved to the store")
+			assert.Equal(t, "test-client", stored.InitializeParams.ClientInfo.Name)
+
+			if tc.ttl > 0 {
+				require.Eventually(t, func() bool {
+					replicas[0].mu.Lock()
+					defer replicas[0].mu.Unlock()
+					return len(replicas[0].sessions) == 0
+				}, 5*time.Second, 20*time.Millisecond, "idle sessions should be closed")
+			}
+		})
+	}
+}
+
+func TestSessionHandlerRequests(t *testing.T) {
+	const listTools = `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+
+	tt := []struct {
+		name           string
+		method         string
+		sessionID      string // the session created by the test if empty
+		subject        string
+		body           string
+		expectedStatus int
+		expectedStored bool
+	}{
+		{
+			name:           "request of the session",
+			method:         http.MethodPost,
+			subject:        "alice",
+			body:           listTools,
+			expectedStatus: http.StatusOK,
+			expectedStored: true,
+		},
+		{
+			name:           "unknown session",
+			method:         http.MethodPost,
+			sessionID:      "unknown",
+			subject:        "alice",
+			body:           listTools,
+			expectedStatus: http.StatusNotFound,
+			expectedStored: true,
+		},
+		{
+			name:           "session of another user",
+			method:         http.MethodPost,
+			subject:        "mallory",
+			body:           listTools,
+			expectedStatus: http.StatusForbidden,
+			expectedStored: true,
+		},
+		{
+			name:           "deleted by another user",
+			method:         http.MethodDelete,
+			subject:        "mallory",
+			expectedStatus: http.StatusForbidden,
+			expectedStored: true,
+		},
+		{
+			name:           "deleted",
+			method:         http.MethodDelete,
+			subject:        "alice",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodPut,
+			subject:        "alice",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedStored: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store := sessions.NewMemoryStore(time.Minute, 0)
+			// two replicas, so that requests are served by both the replica serving the session and the other one
+			server, _ := newTestReplicas(t, 2, store, 0)
+
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+			cs, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+				Endpoint:             server.URL,
+				HTTPClient:           &http.Client{Transport: subjectTransport("alice")},
+				MaxRetries:           -1,
+				DisableStandaloneSSE: true,
+			}, nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = cs.Close() })
+
+			sessionID := tc.sessionID
+			if sessionID == "" {
+				sessionID = cs.ID()
+			}
+
+			for range 2 {
+				req, err := http.NewRequest(tc.method, server.URL, strings.NewReader(tc.body))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Accept", "application/json, text/event-stream")
+				req.Header.Set(sessionIDHeader, sessionID)
+				req.Header.Set("X-Subject", tc.subject)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+
+				if tc.method == http.MethodDelete && tc.expectedStatus == http.StatusNoContent {
+					// deleting the session again is a no-op
+					assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+					continue
+				}
+				assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			}
+
+			_, ok, err := store.Load(context.Background(), cs.ID())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStored, ok)
+		})
+	}
+}
+
+// subjectTransport authenticates the requests of a client as subject.
+type subjectTransport string
+
+func (s subjectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Subject", string(s))
+	return http.DefaultTransport.RoundTrip(r)
+}
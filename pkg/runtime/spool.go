@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/genmcp/gen-mcp/pkg/spool"
+)
+
+// spoolResourceTemplate is the URI template of the resources serving the chunks of spooled responses.
+const spoolResourceTemplate = spool.URIScheme + "://{id}{?offset,length}"
+
+// registerSpoolResources adds to s the resource template serving the chunks of the responses spooled to sp.
+// The _meta of each chunk holds its offset, the offset of the next chunk and the size of the response.
+func registerSpoolResources(s *mcp.Server, sp *spool.Spool) {
+	if sp == nil {
+		return
+	}
+
+	s.AddResourceTemplate(
+		&mcp.ResourceTemplate{
+			Name:  "spooled-response",
+			Title: "Spooled response",
+			Description: "Chunk of a tool call response too large to be returned inline, starting at offset (default: 0) " +
+				"and of at most length bytes. Follow the nextOffset of the _meta of each chunk to read the next one",
+			URITemplate: spoolResourceTemplate,
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			id, offset, length, err := parseSpoolURI(req.Params.URI)
+			if err != nil {
+				return nil, err
+			}
+
+			file, chunk, err := sp.ReadChunk(id, offset, length)
+			if errors.Is(err, spool.ErrNotFound) {
+				return nil, mcp.ResourceNotFoundError(req.Params.URI)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			contents := &mcp.ResourceContents{
+				URI:      req.Params.URI,
+				MIMEType: file.MIMEType,
+				Meta: mcp.Meta{
+					"offset":     chunk.Offset,
+					"nextOffset": chunk.NextOffset,
+					"size":       file.Size,
+				},
+			}
+			if file.Text() {
+				contents.Text = string(chunk.Data)
+			} else {
+				contents.Blob = chunk.Data
+			}
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+		},
+	)
+}
+
+// parseSpoolURI returns the ID of the spooled response and the range of the chunk read by uri.
+func parseSpoolURI(uri string) (id string, offset int64, length int, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != spool.URIScheme || u.Host == "" {
+		return "", 0, 0, fmt.Errorf("invalid spooled response URI %s", uri)
+	}
+
+	query := u.Query()
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.ParseInt(v, 10, 64); err != nil || offset < 0 {
+			return "", 0, 0, fmt.Errorf("offset must be a non-negative integer, received %s", v)
+		}
+	}
+	if v := query.Get("length"); v != "" {
+		if length, err = strconv.Atoi(v); err != nil || length <= 0 {
+			return "", 0, 0, fmt.Errorf("length must be a positive integer, received %s", v)
+		}
+	}
+
+	return u.Host, offset, length, nil
+}
+
+// isSpooledResult reports whether result links to a spooled response, which is returned as is.
+func isSpooledResult(result *mcp.CallToolResult) bool {
+	if len(result.Content) != 1 {
+		return false
+	}
+	link, ok := result.Content[0].(*mcp.ResourceLink)
+	return ok && strings.HasPrefix(link.URI, spool.URIScheme+"://")
+}
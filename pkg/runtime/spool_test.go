@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+func TestSpooledResponses(t *testing.T) {
+	export := strings.Repeat("id,name\n", 16)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		if r.URL.Query().Get("limit") == "1" {
+			_, _ = w.Write([]byte("id,name\n"))
+			return
+		}
+		_, _ = w.Write([]byte(export))
+	}))
+	defer backend.Close()
+
+	tools := []string{`- name: export_users
+  description: Export the users as CSV
+  inputSchema:
+    type: object
+    properties:
+      limit:
+        type: integer
+  invocation:
+    http:
+      method: GET
+      url: ` + backend.URL + `/export
+`}
+
+	ctx := context.Background()
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t, tools...))
+	mcpServer.Runtime.Spool = &serverconfig.SpoolConfig{Dir: t.TempDir(), ThresholdBytes: 64, MaxChunkBytes: 48}
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	templates, err := cs.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{})
+	require.NoError(t, err)
+	require.Len(t, templates.ResourceTemplates, 1)
+	assert.Equal(t, "spool://{id}{?offset,length}", templates.ResourceTemplates[0].URITemplate)
+
+	// small responses are returned inline
+	result, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "export_users", Arguments: map[string]any{"limit": 1}})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "id,name\n", result.Content[0].(*mcp.TextContent).Text)
+
+	result, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "export_users", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	link, ok := result.Content[0].(*mcp.ResourceLink)
+	require.True(t, ok, "large response should be returned as a resource link")
+	assert.Equal(t, "text/csv", link.MIMEType)
+	require.NotNil(t, link.Size)
+	assert.Equal(t, int64(len(export)), *link.Size)
+
+	tt := []struct {
+		name               string
+		query              string
+		expectedText       string
+		expectedNextOffset float64
+		expectedError      string
+	}{
+		{
+			name:               "first chunk",
+			expectedText:       export[:48],
+			expectedNextOffset: 48,
+		},
+		{
+			name:               "next chunk",
+			query:              "?offset=48",
+			expectedText:       export[48:96],
+			expectedNextOffset: 96,
+		},
+		{
+			name:               "ranged read",
+			query:              "?offset=123&length=4",
+			expectedText:       "name",
+			expectedNextOffset: 127,
+		},
+		{
+			name:          "invalid length",
+			query:         "?offset=0&length=-1",
+			expectedError: "length must be a positive integer, received -1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			read, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: link.URI + tc.query})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, read.Contents, 1)
+			assert.Equal(t, tc.expectedText, read.Contents[0].Text)
+			assert.Equal(t, tc.expectedNextOffset, read.Contents[0].Meta["nextOffset"])
+			assert.Equal(t, float64(len(export)), read.Contents[0].Meta["size"])
+		})
+	}
+
+	_, err = cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "spool://0123456789abcdef"})
+	assert.ErrorContains(t, err, "Resource not found")
+}
@@ -0,0 +1,195 @@
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// maxRecentErrors is the number of errors of a server manager kept for its status.
+const maxRecentErrors = 20
+
+// Status is the status of a running server, reported by the admin API.
+type Status struct {
+	// Name of the server, from its MCP file.
+	Server string `json:"server"`
+
+	StartedAt time.Time `json:"startedAt"`
+	Uptime    string    `json:"uptime"`
+
+	// Listeners served over the streamable HTTP transport. Listeners using the stdio transport serve a
+	// single client, and are not reported.
+	Listeners []ListenerStatus `json:"listeners"`
+
+	// Number of audit records dropped because the audit sink couldn't keep up, since the server started.
+	DroppedAuditRecords uint64 `json:"droppedAuditRecords"`
+}
+
+// ListenerStatus is the status of the server manager of a listener.
+type ListenerStatus struct {
+	Name string `json:"name"`
+
+	// Version of the server in the tool definitions served by the listener. ConfigHash identifies those
+	// tool definitions, and changes whenever they are reloaded with changes.
+	Version    string    `json:"version"`
+	ConfigHash string    `json:"configHash"`
+	Reloads    int       `json:"reloads"`
+	ReloadedAt time.Time `json:"reloadedAt,omitzero"`
+
+	// Sessions connected to the listener, sorted by ID.
+	Sessions []SessionStatus `json:"sessions"`
+
+	// ToolFilters are the tools served to each set of scopes that connected to the listener, sorted by scopes.
+	ToolFilters []ToolFilterStatus `json:"toolFilters"`
+
+	// RecentErrors are the last errors of the server manager, oldest first.
+	RecentErrors []ErrorStatus `json:"recentErrors"`
+}
+
+// SessionStatus is an active session of a listener, with the tools it is served.
+type SessionStatus struct {
+	ID    string   `json:"id"`
+	Tools []string `json:"tools"`
+}
+
+// ToolFilterStatus is the set of tools served to clients with the scopes Scopes, that selected the toolsets
+// Toolsets.
+type ToolFilterStatus struct {
+	Scopes   string   `json:"scopes"`
+	Toolsets []string `json:"toolsets,omitempty"`
+	Tools    []string `json:"tools"`
+}
+
+// ErrorStatus is an error of a server manager.
+type ErrorStatus struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Status returns the status of the server manager, reported as the listener name.
+func (sm *ServerManager) Status(name string) ListenerStatus {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	status := ListenerStatus{
+		Name:         name,
+		Version:      sm.mcpServer.Version(),
+		ConfigHash:   sm.configHash,
+		Reloads:      sm.reloads,
+		ReloadedAt:   sm.reloadedAt,
+		Sessions:     []SessionStatus{},
+		ToolFilters:  make([]ToolFilterStatus, 0, len(sm.scopedServers)),
+		RecentErrors: slices.Clone(sm.recentErrors),
+	}
+	if status.RecentErrors == nil {
+		status.RecentErrors = []ErrorStatus{}
+	}
+
+	serverTools := make(map[*primitiveServer][]string, len(sm.scopedServers))
+	for _, filter := range slices.SortedFunc(maps.Keys(sm.scopedServers), compareToolFilters) {
+		s := sm.scopedServers[filter]
+		tools := toolNames(filterForScope(sm.mcpServer, filter, sm.logger).Tools)
+		status.ToolFilters = append(status.ToolFilters, ToolFilterStatus{Scopes: filter.scope, Toolsets: parseToolsets(filter.toolsets), Tools: tools})
+
+		// servers shared by several scopes serve the same tools to all of them
+		if _, ok := serverTools[s]; !ok {
+			serverTools[s] = tools
+		}
+	}
+
+	for s, tools := range serverTools {
+		for session := range s.Sessions() {
+			status.Sessions = append(status.Sessions, SessionStatus{ID: session.ID(), Tools: tools})
+		}
+	}
+	slices.SortFunc(status.Sessions, func(a, b SessionStatus) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return status
+}
+
+// recordError keeps err for the status of the server manager. sm.mu must be held for writing.
+func (sm *ServerManager) recordError(err error) {
+	sm.recentErrors = append(sm.recentErrors, ErrorStatus{Time: time.Now(), Message: err.Error()})
+	if len(sm.recentErrors) > maxRecentErrors {
+		sm.recentErrors = slices.Delete(sm.recentErrors, 0, len(sm.recentErrors)-maxRecentErrors)
+	}
+}
+
+// configHash returns the SHA-256 hash of the JSON encoding of defs, or an empty string if it cannot be encoded.
+func configHash(defs definitions.MCPToolDefinitions) string {
+	data, err := json.Marshal(defs)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// serverStatus collects the status of the listeners of a server, for the admin API.
+type serverStatus struct {
+	mcpServer *mcpserver.MCPServer
+	startedAt time.Time
+	now       func() time.Time
+
+	mu        sync.Mutex
+	listeners []*listenerManager // in the order they started
+}
+
+type listenerManager struct {
+	name    string
+	manager *ServerManager
+}
+
+func newServerStatus(mcpServer *mcpserver.MCPServer) *serverStatus {
+	return &serverStatus{
+		mcpServer: mcpServer,
+		startedAt: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// addListener reports the status of the server manager of the listener name. A nil serverStatus ignores it.
+func (s *serverStatus) addListener(name string, manager *ServerManager) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, &listenerManager{name: name, manager: manager})
+}
+
+// status returns the status of the server and of its listeners.
+func (s *serverStatus) status() Status {
+	s.mu.Lock()
+	listeners := slices.Clone(s.listeners)
+	s.mu.Unlock()
+
+	status := Status{
+		Server:    s.mcpServer.Name(),
+		StartedAt: s.startedAt,
+		Uptime:    s.now().Sub(s.startedAt).Round(time.Second).String(),
+		Listeners: make([]ListenerStatus, 0, len(listeners)),
+	}
+	for _, l := range listeners {
+		status.Listeners = append(status.Listeners, l.manager.Status(l.name))
+	}
+
+	// the audit log is shared by the listeners, and can't be changed by reloads
+	if auditLog, err := s.mcpServer.Runtime.GetAuditLogger(); err == nil {
+		status.DroppedAuditRecords = auditLog.Dropped()
+	}
+
+	return status
+}
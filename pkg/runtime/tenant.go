@@ -0,0 +1,105 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// tenantMethods are the methods invoking tools, prompts and resources, rejected if the tenant of the request
+// can't be identified. The other methods are handled without tenant.
+var tenantMethods = map[string]bool{
+	"tools/call":          true,
+	"prompts/get":         true,
+	"resources/read":      true,
+	"completion/complete": true,
+}
+
+// withTenantMiddleware creates an MCP middleware identifying the tenant of each request by tenants, and
+// storing its variables and secrets in the request context. It must run after the secrets and claims
+// middlewares: add it to the server before them.
+func withTenantMiddleware(tenants *serverconfig.TenantsConfig) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			id, err := identifyTenant(ctx, tenants, req.GetExtra())
+			if err != nil {
+				if !tenantMethods[method] {
+					return next(ctx, method, req)
+				}
+
+				logging.BaseFromContext(ctx).Warn("Request rejected: tenant not identified",
+					zap.String("method", method),
+					zap.Error(err))
+				if method == "tools/call" {
+					return utils.McpCodedError(invocation.ErrorCodeAuth, "%v", err), nil
+				}
+				return nil, err
+			}
+
+			tenant, _ := tenants.Tenant(id)
+			return next(withTenant(ctx, id, tenant), method, req)
+		}
+	}
+}
+
+// identifyTenant returns the ID of the tenant of a request, identified by its claims or its headers, or the
+// default tenant of tenants if it isn't identified.
+func identifyTenant(ctx context.Context, tenants *serverconfig.TenantsConfig, extra *mcp.RequestExtra) (string, error) {
+	var id string
+	switch {
+	case tenants.Claim != "":
+		// The claim is not found for unauthenticated requests
+		id, _ = template.ClaimsFromContext(ctx).Resolve(tenants.Claim)
+	case extra != nil && extra.Header != nil:
+		id = extra.Header.Get(tenants.Header)
+	}
+
+	if id == "" {
+		if tenants.Default == "" {
+			return "", fmt.Errorf("forbidden: tenant not identified")
+		}
+		return tenants.Default, nil
+	}
+	if _, ok := tenants.Tenant(id); !ok {
+		return "", fmt.Errorf("forbidden: unknown tenant '%s'", id)
+	}
+	return id, nil
+}
+
+// withTenant stores the variables of tenant, of ID id, in ctx, and a secret store resolving the secrets of
+// the tenant from the secret store of ctx.
+func withTenant(ctx context.Context, id string, tenant *serverconfig.TenantConfig) context.Context {
+	ctx = template.WithTenant(ctx, id, tenant.Variables)
+	if len(tenant.Secrets) > 0 {
+		ctx = secrets.WithStore(ctx, secrets.FromContext(ctx).WithAliases(tenant.Secrets))
+	}
+	return ctx
+}
+
+// checkTenantQuota counts a call of tool for the tenant of ctx with tracker, and returns a result stopping the
+// call if it exceeds the quotas of the tenant. Every call is accepted if tracker is nil or ctx has no tenant.
+func checkTenantQuota(ctx context.Context, tracker *quota.Tracker, tool string) *mcp.CallToolResult {
+	tenant := template.TenantFromContext(ctx).ID()
+	if tracker == nil || tenant == "" {
+		return nil
+	}
+
+	if err := tracker.Record(tenant, tool); err != nil {
+		logging.BaseFromContext(ctx).Warn("Tool call rejected by a quota of its tenant",
+			zap.String("tool_name", tool),
+			zap.String("tenant", tenant),
+			zap.Error(err))
+		return utils.McpCodedError(invocation.ErrorCodeQuotaExceeded, "tenant %s: %v", tenant, err)
+	}
+	return nil
+}
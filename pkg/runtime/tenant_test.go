@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/quota"
+	"github.com/genmcp/gen-mcp/pkg/secrets"
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+type tenantSecrets map[string]string
+
+func (p tenantSecrets) Lookup(name string) (string, bool, error) {
+	value, ok := p[name]
+	return value, ok, nil
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	tenants := map[string]*serverconfig.TenantConfig{
+		"acme": {
+			Variables: map[string]string{"baseUrl": "https://acme.api.example.com"},
+			Secrets:   map[string]string{"API_KEY": "ACME_API_KEY"},
+		},
+		"globex": {
+			Variables: map[string]string{"baseUrl": "https://globex.api.example.com"},
+		},
+	}
+
+	tt := []struct {
+		name            string
+		config          serverconfig.TenantsConfig
+		claims          map[string]any
+		header          http.Header
+		method          string
+		expectedTenant  string
+		expectedBaseURL string
+		expectedAPIKey  string
+		expectedError   string
+		expectedResult  string
+	}{
+		{
+			name:            "tenant from a claim",
+			config:          serverconfig.TenantsConfig{Claim: "org.id", Tenants: tenants},
+			claims:          map[string]any{"org": map[string]any{"id": "acme"}},
+			method:          "resources/read",
+			expectedTenant:  "acme",
+			expectedBaseURL: "https://acme.api.example.com",
+			expectedAPIKey:  "acme-key",
+		},
+		{
+			name:            "tenant from a header",
+			config:          serverconfig.TenantsConfig{Header: "X-Tenant-ID", Tenants: tenants},
+			header:          http.Header{"X-Tenant-Id": []string{"globex"}},
+			method:          "resources/read",
+			expectedTenant:  "globex",
+			expectedBaseURL: "https://globex.api.example.com",
+			expectedAPIKey:  "shared-key",
+		},
+		{
+			name:            "default tenant",
+			config:          serverconfig.TenantsConfig{Claim: "tenant_id", Default: "globex", Tenants: tenants},
+			method:          "resources/read",
+			expectedTenant:  "globex",
+			expectedBaseURL: "https://globex.api.example.com",
+			expectedAPIKey:  "shared-key",
+		},
+		{
+			name:          "tenant not identified",
+			config:        serverconfig.TenantsConfig{Claim: "tenant_id", Tenants: tenants},
+			method:        "resources/read",
+			expectedError: "forbidden: tenant not identified",
+		},
+		{
+			name:          "unknown tenant",
+			config:        serverconfig.TenantsConfig{Claim: "tenant_id", Default: "globex", Tenants: tenants},
+			claims:        map[string]any{"tenant_id": "initech"},
+			method:        "resources/read",
+			expectedError: "forbidden: unknown tenant 'initech'",
+		},
+		{
+			name:           "tool call of an unknown tenant",
+			config:         serverconfig.TenantsConfig{Header: "X-Tenant-ID", Tenants: tenants},
+			header:         http.Header{"X-Tenant-Id": []string{"initech"}},
+			method:         "tools/call",
+			expectedResult: "forbidden: unknown tenant 'initech'",
+		},
+		{
+			name:   "listing without tenant",
+			config: serverconfig.TenantsConfig{Claim: "tenant_id", Tenants: tenants},
+			method: "tools/list",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := secrets.WithStore(context.Background(), secrets.NewStore(tenantSecrets{"API_KEY": "shared-key", "ACME_API_KEY": "acme-key"}))
+			if tc.claims != nil {
+				ctx = template.WithClaims(ctx, tc.claims)
+			}
+
+			var tenantResolver *template.TenantResolver
+			var store *secrets.Store
+			handler := withTenantMiddleware(&tc.config)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				tenantResolver = template.TenantFromContext(ctx)
+				store = secrets.FromContext(ctx)
+				return &mcp.CallToolResult{}, nil
+			})
+
+			result, err := handler(ctx, tc.method, &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: tc.header}})
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectedResult != "" {
+				toolResult := result.(*mcp.CallToolResult)
+				assert.True(t, toolResult.IsError)
+				assert.Contains(t, toolResult.Content[0].(*mcp.TextContent).Text, tc.expectedResult)
+				assert.Equal(t, invocation.NewErrorDetail(invocation.ErrorCodeAuth, 0), toolResult.Meta[invocation.ErrorMetaKey])
+				assert.Nil(t, tenantResolver, "the call should not be handled")
+				return
+			}
+
+			require.NotNil(t, tenantResolver)
+			assert.Equal(t, tc.expectedTenant, tenantResolver.ID())
+			if tc.expectedTenant == "" {
+				return
+			}
+			baseURL, err := tenantResolver.Resolve("baseUrl")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBaseURL, baseURL)
+			apiKey, err := store.Resolve("API_KEY")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAPIKey, apiKey)
+		})
+	}
+}
+
+func TestCheckTenantQuota(t *testing.T) {
+	tt := []struct {
+		name          string
+		tenant        string
+		previousCalls int
+		expectedText  string
+	}{
+		{
+			name:   "within the quota",
+			tenant: "acme",
+		},
+		{
+			name:          "over the quota",
+			tenant:        "acme",
+			previousCalls: 2,
+			expectedText:  "tenant acme: quota exceeded: 2 calls per hour",
+		},
+		{
+			name:          "tenant without quota",
+			tenant:        "globex",
+			previousCalls: 2,
+		},
+		{
+			name:          "call without tenant",
+			previousCalls: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.tenant != "" {
+				ctx = template.WithTenant(ctx, tc.tenant, nil)
+			}
+			tracker := quota.NewTracker(quota.Config{Clients: map[string]quota.Limits{"acme": {PerHour: 2}}})
+			for range tc.previousCalls {
+				require.NoError(t, tracker.Record(tc.tenant, "search"))
+			}
+
+			result := checkTenantQuota(ctx, tracker, "search")
+			if tc.expectedText != "" {
+				require.NotNil(t, result)
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tc.expectedText)
+				assert.Equal(t, invocation.NewErrorDetail(invocation.ErrorCodeQuotaExceeded, 0), result.Meta[invocation.ErrorMetaKey])
+			} else {
+				assert.Nil(t, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package runtime
+
+import (
+	"slices"
+	"sync"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+// toolInvokers holds the invokers of the tools of a server loading its tools lazily, so that the servers of
+// every scope and toolset share the invoker of a tool, created when it is first called. A nil toolInvokers
+// shares no invoker.
+type toolInvokers struct {
+	mu       sync.Mutex
+	invokers map[*definitions.Tool]*lazyInvoker
+}
+
+// lazyInvoker creates the invoker of a tool when it is first called. Failures are not cached, so that a call
+// after a transient failure creates the invoker again.
+type lazyInvoker struct {
+	tool *definitions.Tool
+
+	mu      sync.Mutex
+	invoker invocation.Invoker
+}
+
+// get returns the invoker of the tool, creating it if it doesn't exist yet.
+func (l *lazyInvoker) get() (invocation.Invoker, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.invoker == nil {
+		invoker, err := invocation.CreateInvoker(l.tool)
+		if err != nil {
+			return nil, err
+		}
+		l.invoker = invoker
+	}
+	return l.invoker, nil
+}
+
+// toolInvoker returns a function returning the invoker of tool. The invoker is created right away, unless lazy
+// is set, in which case it is created by the first call of the function and shared with the servers of the
+// other scopes and toolsets.
+func (ti *toolInvokers) toolInvoker(tool *definitions.Tool, lazy bool) (func() (invocation.Invoker, error), error) {
+	if lazy {
+		return ti.lazyInvoker(tool).get, nil
+	}
+
+	invoker, err := invocation.CreateInvoker(tool)
+	if err != nil {
+		return nil, err
+	}
+	return func() (invocation.Invoker, error) { return invoker, nil }, nil
+}
+
+// lazyInvoker returns the lazy invoker of tool, adding it if it doesn't exist yet.
+func (ti *toolInvokers) lazyInvoker(tool *definitions.Tool) *lazyInvoker {
+	if ti == nil {
+		return &lazyInvoker{tool: tool}
+	}
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	l, ok := ti.invokers[tool]
+	if !ok {
+		if ti.invokers == nil {
+			ti.invokers = make(map[*definitions.Tool]*lazyInvoker)
+		}
+		l = &lazyInvoker{tool: tool}
+		ti.invokers[tool] = l
+	}
+	return l
+}
+
+// retain drops the invokers of the tools that are not in tools, once a reload removed or replaced them. The
+// servers of the sessions started before the reload keep using them.
+func (ti *toolInvokers) retain(tools []*definitions.Tool) {
+	if ti == nil {
+		return
+	}
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	for tool := range ti.invokers {
+		if !slices.Contains(tools, tool) {
+			delete(ti.invokers, tool)
+		}
+	}
+}
+
+// invocationValidator returns the validator of the invocations of mcpServer, which doesn't create the invokers
+// of the tools if they are loaded lazily.
+func invocationValidator(mcpServer *mcpserver.MCPServer) definitions.InvocationValidator {
+	if mcpServer.Runtime.LazyToolLoading() {
+		return invocation.LazyInvocationValidator
+	}
+	return invocation.InvocationValidator
+}
@@ -0,0 +1,98 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+func TestLazyToolLoading(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"up"}`))
+	}))
+	t.Cleanup(backend.Close)
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	writeToolDefinitions(t, path,
+		errorCodesTestTool("get_status", backend.URL+"/status", ""),
+		errorCodesTestTool("get_version", backend.URL+"/version", ""),
+		errorCodesTestTool("get_broken", backend.URL+"/broken/{service", ""))
+	defsFile, err := parseToolDefinitionsFile(path)
+	require.NoError(t, err)
+
+	mcpServer := newTestMCPServer(t, defsFile.MCPToolDefinitions)
+	require.Error(t, mcpServer.MCPToolDefinitions.Validate(invocationValidator(mcpServer)),
+		"the invokers of the tools are created when they are loaded eagerly")
+	mcpServer.Runtime.ToolLoading = &serverconfig.ToolLoadingConfig{Lazy: true, PageSize: 2}
+	require.NoError(t, mcpServer.MCPToolDefinitions.Validate(invocationValidator(mcpServer)),
+		"the templates of the tools are only parsed when they are first called")
+
+	invokers := &toolInvokers{}
+	first, err := makeServerWithPrimitives(mcpServer, mcpServer, invokers)
+	require.NoError(t, err)
+	second, err := makeServerWithPrimitives(mcpServer, mcpServer, invokers)
+	require.NoError(t, err)
+
+	lazy, ok := invokers.invokers[mcpServer.Tools[0]]
+	require.True(t, ok)
+	assert.Nil(t, lazy.invoker, "the invoker should not be created before the first call")
+
+	cs, _ := connectTestClient(t, first.Server)
+	result, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_status"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	invoker := lazy.invoker
+	require.NotNil(t, invoker)
+
+	cs, _ = connectTestClient(t, second.Server)
+	result, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_status"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Same(t, invoker, lazy.invoker, "the servers should share the invoker of the tool")
+
+	result, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_broken"})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "failed to create invoker for tool get_broken")
+	assert.Equal(t, string(invocation.ErrorCodeInternal), result.Meta[invocation.ErrorMetaKey].(map[string]any)["code"])
+
+	page, err := cs.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, page.Tools, 2)
+	require.NotEmpty(t, page.NextCursor)
+	page, err = cs.ListTools(context.Background(), &mcp.ListToolsParams{Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, page.Tools, 1)
+	assert.Empty(t, page.NextCursor)
+
+	reloader := &serverReloader{server: first, mcpServer: mcpServer, invokers: invokers}
+	reloaded := loadTestDefinitions(t, errorCodesTestTool("get_status", backend.URL+"/status", ""))
+	require.NoError(t, reloader.Reload(reloaded))
+	_, ok = invokers.invokers[mcpServer.Tools[0]]
+	assert.False(t, ok, "the invokers of the tools replaced by a reload should be dropped")
+	lazy, ok = invokers.invokers[reloaded.Tools[0]]
+	require.True(t, ok)
+
+	replacement := newTestMCPServer(t, reloaded)
+	replacement.Runtime.ToolLoading = mcpServer.Runtime.ToolLoading
+	require.NoError(t, reloader.Replace(replacement))
+	require.Contains(t, invokers.invokers, reloaded.Tools[0])
+	assert.NotSame(t, lazy, invokers.invokers[reloaded.Tools[0]], "the invokers created with the previous server config should be dropped")
+
+	sm := NewServerManager(mcpServer)
+	_, err = sm.ServerFromContext(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, sm.invokers.invokers, mcpServer.Tools[0])
+	require.NoError(t, sm.Reload(loadTestDefinitions(t, errorCodesTestTool("get_status", backend.URL+"/status", ""))))
+	_, ok = sm.invokers.invokers[mcpServer.Tools[0]]
+	assert.False(t, ok, "the invokers of the tools removed by a reload should be dropped")
+}
@@ -0,0 +1,168 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// ToolsetsHeader is the header of the requests of a client selecting the toolsets of its session, as a
+	// comma-separated list of names.
+	ToolsetsHeader = "X-MCP-Toolsets"
+
+	// ToolsetsQueryParameter is the query parameter of the URL of a client selecting the toolsets of its
+	// session, as a comma-separated list of names.
+	ToolsetsQueryParameter = "toolsets"
+
+	// ToolsetsMetaKey is the key of the _meta of the initialize request of a client selecting the toolsets of
+	// its session, as an array of names or a comma-separated list of names.
+	ToolsetsMetaKey = "genmcp/toolsets"
+
+	// maxMCPRequestBytes is the maximum size of the body of a request to the MCP endpoint.
+	maxMCPRequestBytes = 10 << 20
+)
+
+type toolsetsKey struct{}
+
+// withToolsets stores the toolsets selected by a client in the given context.
+func withToolsets(ctx context.Context, toolsets []string) context.Context {
+	return context.WithValue(ctx, toolsetsKey{}, toolsets)
+}
+
+// toolsetsFromContext returns the toolsets stored in the context by withToolsets, nil if the client selected
+// none and is served every tool.
+func toolsetsFromContext(ctx context.Context) []string {
+	toolsets, _ := ctx.Value(toolsetsKey{}).([]string)
+	return toolsets
+}
+
+// toolsetsMiddleware stores the toolsets selected by the requests creating sessions in their context, so that
+// ServerFromContext serves them only the tools of those toolsets. Requests selecting toolsets no tool belongs
+// to are rejected.
+func (sm *ServerManager) toolsetsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		toolsets, err := requestToolsets(w, r)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if toolsets == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// requests of existing sessions are served the toolsets of their session
+		if r.Header.Get(sessionIDHeader) == "" {
+			if unknown := sm.unknownToolsets(toolsets); len(unknown) > 0 {
+				http.Error(w, fmt.Sprintf("Bad Request: unknown toolsets: %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(withToolsets(r.Context(), toolsets)))
+	})
+}
+
+// unknownToolsets returns the toolsets no tool of the manager belongs to.
+func (sm *ServerManager) unknownToolsets(toolsets []string) []string {
+	sm.mu.RLock()
+	known := sm.mcpServer.ToolsetNames()
+	sm.mu.RUnlock()
+
+	var unknown []string
+	for _, toolset := range toolsets {
+		if !slices.Contains(known, toolset) {
+			unknown = append(unknown, toolset)
+		}
+	}
+	return unknown
+}
+
+// requestToolsets returns the toolsets selected by r, from the _meta of the initialize request it holds, from
+// its header or from its query parameter, in that order, or nil if it selects none. The body of r is limited to
+// maxMCPRequestBytes, for the handlers too, and the body of a request without session is read to find the
+// initialize request, and restored for the handlers.
+func requestToolsets(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxMCPRequestBytes)
+	}
+
+	if r.Method == http.MethodPost && r.Header.Get(sessionIDHeader) == "" && r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		var msg struct {
+			Method string `json:"method"`
+			Params struct {
+				Meta map[string]any `json:"_meta"`
+			} `json:"params"`
+		}
+		// invalid messages are rejected by the transport
+		if json.Unmarshal(body, &msg) == nil && msg.Method == "initialize" {
+			toolsets, err := toolsetsFromMeta(msg.Params.Meta)
+			if err != nil || toolsets != nil {
+				return toolsets, err
+			}
+		}
+	}
+
+	if value := r.Header.Get(ToolsetsHeader); value != "" {
+		return parseToolsets(value), nil
+	}
+	if value := r.URL.Query().Get(ToolsetsQueryParameter); value != "" {
+		return parseToolsets(value), nil
+	}
+	return nil, nil
+}
+
+// toolsetsFromMeta returns the toolsets selected by the _meta of an initialize request, or nil if it selects
+// none.
+func toolsetsFromMeta(meta map[string]any) ([]string, error) {
+	switch value := meta[ToolsetsMetaKey].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return parseToolsets(value), nil
+	case []any:
+		names := make([]string, 0, len(value))
+		for _, v := range value {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array of strings", ToolsetsMetaKey)
+			}
+			names = append(names, name)
+		}
+		return parseToolsets(strings.Join(names, ",")), nil
+	default:
+		return nil, fmt.Errorf("%s must be an array of strings or a string", ToolsetsMetaKey)
+	}
+}
+
+// parseToolsets returns the sorted names of a comma-separated list of toolsets, each once, or nil if it has
+// none.
+func parseToolsets(value string) []string {
+	var toolsets []string
+	for name := range strings.SplitSeq(value, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(toolsets, name) {
+			toolsets = append(toolsets, name)
+		}
+	}
+	slices.Sort(toolsets)
+	return toolsets
+}
@@ -0,0 +1,273 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+const toolsetDefinitions = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: test-server
+version: "1.0.0"
+tools:
+- name: list_pull_requests
+  description: "Lists pull requests"
+  inputSchema:
+    type: object
+  toolsets: [github]
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/pulls
+- name: list_issues
+  description: "Lists issues"
+  inputSchema:
+    type: object
+  toolsets: [github, jira]
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/issues
+- name: list_sprints
+  description: "Lists sprints"
+  inputSchema:
+    type: object
+  toolsets: [jira]
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/sprints
+- name: get_time
+  description: "Gets the time"
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      method: GET
+      url: http://localhost:8080/time
+`
+
+func newToolsetTestServer(t *testing.T) *mcpserver.MCPServer {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(toolsetDefinitions), 0644))
+	defs, err := loadToolDefinitions(path)
+	require.NoError(t, err)
+
+	return newTestMCPServer(t, *defs)
+}
+
+func TestServerManagerFiltersToolsByToolset(t *testing.T) {
+	tt := []struct {
+		name          string
+		toolsets      []string
+		expectedTools []string
+	}{
+		{
+			name:          "no toolsets",
+			expectedTools: []string{"get_time", "list_issues", "list_pull_requests", "list_sprints"},
+		},
+		{
+			name:          "one toolset",
+			toolsets:      []string{"github"},
+			expectedTools: []string{"get_time", "list_issues", "list_pull_requests"},
+		},
+		{
+			name:          "several toolsets",
+			toolsets:      []string{"github", "jira"},
+			expectedTools: []string{"get_time", "list_issues", "list_pull_requests", "list_sprints"},
+		},
+	}
+
+	sm := NewServerManager(newToolsetTestServer(t))
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := sm.ServerFromContext(withToolsets(context.Background(), tc.toolsets))
+			require.NoError(t, err)
+
+			cs, _ := connectTestClient(t, s)
+			assert.Equal(t, tc.expectedTools, listToolNames(t, cs))
+		})
+	}
+
+	github, err := sm.ServerFromContext(withToolsets(context.Background(), []string{"github"}))
+	require.NoError(t, err)
+	all, err := sm.ServerFromContext(context.Background())
+	require.NoError(t, err)
+	both, err := sm.ServerFromContext(withToolsets(context.Background(), []string{"github", "jira"}))
+	require.NoError(t, err)
+	assert.NotSame(t, all, github)
+	assert.Same(t, all, both, "toolsets selecting every tool should share the server serving every tool")
+
+	status := sm.Status("default")
+	require.Len(t, status.ToolFilters, 2)
+	assert.Equal(t, []string{"github"}, status.ToolFilters[1].Toolsets)
+	assert.Equal(t, []string{"get_time", "list_issues", "list_pull_requests"}, status.ToolFilters[1].Tools)
+}
+
+func TestRequestToolsets(t *testing.T) {
+	tt := []struct {
+		name          string
+		target        string
+		header        map[string]string
+		body          string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "initialize metadata array",
+			body:     `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"genmcp/toolsets":["jira","github"]}}}`,
+			expected: []string{"github", "jira"},
+		},
+		{
+			name:     "initialize metadata string",
+			body:     `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"genmcp/toolsets":"jira, github"}}}`,
+			expected: []string{"github", "jira"},
+		},
+		{
+			name:          "invalid initialize metadata",
+			body:          `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"genmcp/toolsets":[1]}}}`,
+			expectedError: "genmcp/toolsets must be an array of strings",
+		},
+		{
+			name:     "header",
+			header:   map[string]string{ToolsetsHeader: "github,github"},
+			body:     `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+			expected: []string{"github"},
+		},
+		{
+			name:     "query parameter",
+			target:   "/mcp?toolsets=jira,github",
+			expected: []string{"github", "jira"},
+		},
+		{
+			name:     "initialize metadata before header",
+			header:   map[string]string{ToolsetsHeader: "github"},
+			body:     `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"genmcp/toolsets":["jira"]}}}`,
+			expected: []string{"jira"},
+		},
+		{
+			name:   "request of a session",
+			header: map[string]string{sessionIDHeader: "abc"},
+			body:   `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"genmcp/toolsets":["jira"]}}}`,
+		},
+		{
+			name: "no toolsets",
+			body: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			target := tc.target
+			if target == "" {
+				target = "/mcp"
+			}
+			r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(tc.body))
+			for name, value := range tc.header {
+				r.Header.Set(name, value)
+			}
+
+			toolsets, err := requestToolsets(httptest.NewRecorder(), r)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, toolsets)
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(body), "the body should be restored")
+		})
+	}
+}
+
+func TestRequestToolsetsBodyLimit(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"_meta":{"padding":"` +
+		strings.Repeat("a", maxMCPRequestBytes) + `"}}}`
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	_, err := requestToolsets(httptest.NewRecorder(), r)
+	var maxBytesErr *http.MaxBytesError
+	require.ErrorAs(t, err, &maxBytesErr)
+	assert.Equal(t, int64(maxMCPRequestBytes), maxBytesErr.Limit)
+
+	handler := (&ServerManager{}).toolsetsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not be handled")
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+type toolsetsRoundTripper string
+
+func (rt toolsetsRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set(ToolsetsHeader, string(rt))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestToolsetsMiddleware(t *testing.T) {
+	tt := []struct {
+		name          string
+		query         string
+		header        string
+		expectedTools []string
+		expectedError string
+	}{
+		{
+			name:          "toolsets from query parameter",
+			query:         "?toolsets=jira",
+			expectedTools: []string{"get_time", "list_issues", "list_sprints"},
+		},
+		{
+			name:          "toolsets from header",
+			header:        "github",
+			expectedTools: []string{"get_time", "list_issues", "list_pull_requests"},
+		},
+		{
+			name:          "unknown toolset",
+			header:        "github,confluence",
+			expectedError: "Bad Request",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			generation := newMCPGeneration(newToolsetTestServer(t), nil)
+			server := httptest.NewServer(generation.handler)
+			t.Cleanup(server.Close)
+
+			transport := &mcp.StreamableClientTransport{Endpoint: server.URL + "/mcp" + tc.query}
+			if tc.header != "" {
+				transport.HTTPClient = &http.Client{Transport: toolsetsRoundTripper(tc.header)}
+			}
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+			cs, err := client.Connect(context.Background(), transport, nil)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = cs.Close() })
+
+			assert.Equal(t, tc.expectedTools, listToolNames(t, cs))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	httpinvocation "github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+)
+
+// refreshUpstream imports the tools of the upstream MCP server again, after it notified that they changed.
+// Tools that fail to be imported are logged and skipped, so the server keeps serving the last imported tools.
+func (s *toolDefinitionsSource) refreshUpstream(ctx context.Context, importer *upstreamImporter) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	tools, err := importer.importTools(ctx)
+	if err != nil {
+		s.logger.Error("Failed to refresh the tools of upstream MCP server, keeping the current tools",
+			zap.String("upstream", importer.upstream.Name),
+			zap.Error(err))
+		return
+	}
+
+	changed, err := s.setImported(importer.source(), tools)
+	if err != nil {
+		s.logger.Error("Upstream MCP server tools refreshed with some errors",
+			zap.String("upstream", importer.upstream.Name),
+			zap.Error(err))
+		return
+	}
+
+	if changed {
+		s.logger.Info("Upstream MCP server tools refreshed",
+			zap.String("upstream", importer.upstream.Name),
+			zap.Int("num_tools", len(tools)))
+	}
+}
+
+// upstreamImporter imports the tools of an upstream MCP server of a server config, as tools forwarding their
+// calls to it.
+type upstreamImporter struct {
+	upstream *serverconfig.UpstreamConfig
+	client   *http.Client // connects to the server if it is reached at a URL
+	logger   *zap.Logger
+}
+
+func newUpstreamImporter(runtime *serverconfig.ServerRuntime, upstream *serverconfig.UpstreamConfig) (*upstreamImporter, error) {
+	client, err := runtime.GetHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return &upstreamImporter{
+		upstream: upstream,
+		client:   client,
+		logger:   runtime.GetBaseLogger(),
+	}, nil
+}
+
+// source identifies the imported tools in the tool definitions source.
+func (i *upstreamImporter) source() string {
+	return "upstream:" + i.upstream.Name
+}
+
+// importTools lists the tools of the upstream server, and imports the selected ones. Tools that can't be
+// imported are logged and skipped.
+func (i *upstreamImporter) importTools(ctx context.Context) ([]*definitions.Tool, error) {
+	upstreamTools, err := proxy.ListTools(httpinvocation.WithHTTPClient(ctx, i.client), i.upstream.ProxyConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	tools := make([]*definitions.Tool, 0, len(upstreamTools))
+	for _, ut := range upstreamTools {
+		if len(i.upstream.Tools) > 0 && !slices.Contains(i.upstream.Tools, ut.Name) {
+			continue
+		}
+
+		tool, err := i.importTool(ut)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("skipping tool %s: %w", ut.Name, err))
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	for _, name := range i.upstream.Tools {
+		if !slices.ContainsFunc(upstreamTools, func(ut *mcp.Tool) bool { return ut.Name == name }) {
+			errs = errors.Join(errs, fmt.Errorf("tool %s not found", name))
+		}
+	}
+
+	if errs != nil {
+		i.logger.Warn("Some tools of the upstream MCP server were not imported",
+			zap.String("upstream", i.upstream.Name),
+			zap.Error(errs))
+	}
+
+	return tools, nil
+}
+
+// importTool returns the definition of a tool forwarding its calls to the tool ut of the upstream server,
+// named with the prefix of the upstream.
+func (i *upstreamImporter) importTool(ut *mcp.Tool) (*definitions.Tool, error) {
+	inputSchema, err := toSchema(ut.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputSchema: %w", err)
+	}
+
+	var outputSchema *jsonschema.Schema
+	if ut.OutputSchema != nil {
+		outputSchema, err = toSchema(ut.OutputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid outputSchema: %w", err)
+		}
+	}
+
+	// the description is required, so tools without one are described by their title or name
+	description := ut.Description
+	if description == "" {
+		description = ut.Title
+	}
+	if description == "" {
+		description = ut.Name
+	}
+
+	config := i.upstream.ProxyConfig()
+	config.Tool = ut.Name
+
+	tool := &definitions.Tool{
+		Name:         i.upstream.Prefix + ut.Name,
+		Title:        ut.Title,
+		Description:  description,
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		Annotations:  toolAnnotations(ut.Annotations),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type:   proxy.InvocationType,
+			Config: config,
+		},
+	}
+	if err := tool.Validate(invocation.InvocationValidator); err != nil {
+		return nil, err
+	}
+
+	return tool, nil
+}
+
+// toSchema converts a JSON schema decoded by the MCP client to a schema.
+func toSchema(schema any) (*jsonschema.Schema, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func toolAnnotations(annotations *mcp.ToolAnnotations) *definitions.ToolAnnotations {
+	if annotations == nil {
+		return nil
+	}
+
+	// the idempotent and read-only hints of the MCP client are unset when false
+	ta := &definitions.ToolAnnotations{
+		DestructiveHint: annotations.DestructiveHint,
+		OpenWorldHint:   annotations.OpenWorldHint,
+	}
+	if annotations.IdempotentHint {
+		ta.IdempotentHint = &annotations.IdempotentHint
+	}
+	if annotations.ReadOnlyHint {
+		ta.ReadOnlyHint = &annotations.ReadOnlyHint
+	}
+	return ta
+}
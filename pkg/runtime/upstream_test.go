@@ -0,0 +1,127 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation/proxy"
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+)
+
+type upstreamEchoArgs struct {
+	Message string `json:"message"`
+}
+
+// upstreamTestServer starts an MCP server with echo and reverse tools, returning it along with its URL.
+func upstreamTestServer(t *testing.T) (*mcp.Server, string) {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "upstream"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo", Description: "Echo a message", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}},
+		func(_ context.Context, _ *mcp.CallToolRequest, args upstreamEchoArgs) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Message}}}, nil, nil
+		})
+	mcp.AddTool(server, &mcp.Tool{Name: "reverse"},
+		func(_ context.Context, _ *mcp.CallToolRequest, args upstreamEchoArgs) (*mcp.CallToolResult, any, error) {
+			runes := []rune(args.Message)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(runes)}}}, nil, nil
+		})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+	t.Cleanup(proxy.CloseSessions)
+
+	return server, srv.URL
+}
+
+func TestToolDefinitionsSourceImportsUpstreams(t *testing.T) {
+	server, url := upstreamTestServer(t)
+
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: loadTestDefinitions(t, reloadTestTool("first")),
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime: &serverconfig.ServerRuntime{},
+			Upstreams: []*serverconfig.UpstreamConfig{
+				{Name: "all", URL: url},
+				{Name: "selected", URL: url, Tools: []string{"echo"}, Prefix: "upstream_"},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source, err := newToolDefinitionsSource(ctx, mcpServer, "")
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, []string{"echo", "first", "reverse", "upstream_echo"}, toolNames(mcpServer.Tools))
+
+	var echo *definitions.Tool
+	for _, tool := range mcpServer.Tools {
+		if tool.Name == "upstream_echo" {
+			echo = tool
+		}
+	}
+	require.NotNil(t, echo)
+	assert.Equal(t, "Echo a message", echo.Description)
+	require.NotNil(t, echo.Annotations)
+	assert.True(t, *echo.Annotations.ReadOnlyHint)
+	assert.Equal(t, &proxy.ProxyInvocationConfig{URL: url, Tool: "echo"}, echo.InvocationConfigWrapper.Config)
+
+	t.Run("tools forward their calls", func(t *testing.T) {
+		invoker, err := (&proxy.InvokerFactory{}).CreateInvoker(echo.InvocationConfigWrapper.Config, echo)
+		require.NoError(t, err)
+
+		result, err := invoker.Invoke(context.Background(), &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Arguments: []byte(`{"message":"hello"}`)},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		assert.Equal(t, "hello", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("tools are refreshed when they change", func(t *testing.T) {
+		reloaded := make(chan definitions.MCPToolDefinitions, 10)
+		source.onChange(func(defs definitions.MCPToolDefinitions) error {
+			reloaded <- defs
+			return nil
+		})
+
+		server.RemoveTools("reverse")
+
+		select {
+		case defs := <-reloaded:
+			assert.Equal(t, []string{"echo", "first", "upstream_echo"}, toolNames(defs.Tools))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for refresh")
+		}
+	})
+}
+
+func TestToolDefinitionsSourceUpstreamUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	mcpServer := &mcpserver.MCPServer{
+		MCPToolDefinitions: loadTestDefinitions(t, reloadTestTool("first")),
+		MCPServerConfig: serverconfig.MCPServerConfig{
+			Runtime:   &serverconfig.ServerRuntime{},
+			Upstreams: []*serverconfig.UpstreamConfig{{Name: "missing", URL: srv.URL}},
+		},
+	}
+
+	_, err := newToolDefinitionsSource(context.Background(), mcpServer, "")
+	assert.ErrorContains(t, err, "failed to import tools from upstream MCP server missing")
+}
@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/genmcp/gen-mcp/pkg/mcpserver"
+	"github.com/genmcp/gen-mcp/pkg/webhook"
+)
+
+// webhookResourceURI returns the URI of the resource serving the recent events of the named webhook.
+func webhookResourceURI(name string) string {
+	return "webhook://" + name + "/events"
+}
+
+// webhookEvents is the content of the resource of a webhook.
+type webhookEvents struct {
+	Name   string           `json:"name"`
+	Path   string           `json:"path"`
+	Events []*webhook.Event `json:"events"`
+}
+
+// webhookHandlers returns the handlers of the webhooks of mcpServer, by path.
+func webhookHandlers(mcpServer *mcpserver.MCPServer) (map[string]http.Handler, error) {
+	receivers, err := mcpServer.Runtime.GetWebhookReceivers()
+	if err != nil {
+		return nil, err
+	}
+
+	handlers := make(map[string]http.Handler, len(receivers))
+	for _, wc := range mcpServer.Runtime.Webhooks {
+		handlers[wc.Path] = receivers[wc.Name]
+	}
+	return handlers, nil
+}
+
+// registerWebhookHandlers adds the handlers of the webhooks of mcpServer to mux. They are not behind the auth
+// of the MCP endpoint: the requests of external services are verified with their signatures.
+func registerWebhookHandlers(mux *http.ServeMux, mcpServer *mcpserver.MCPServer) {
+	logger := mcpServer.Runtime.GetBaseLogger()
+
+	handlers, err := webhookHandlers(mcpServer)
+	if err != nil {
+		logger.Error("Failed to create webhook receivers", zap.Error(err))
+		return
+	}
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+		logger.Debug("Registered webhook handler", zap.String("path", path))
+	}
+}
+
+// registerWebhookResources adds to s the resources serving the recent events of the webhooks of mcpServer,
+// received by receivers.
+func registerWebhookResources(s *mcp.Server, mcpServer *mcpserver.MCPServer, receivers map[string]*webhook.Receiver) {
+	for _, wc := range mcpServer.Runtime.Webhooks {
+		uri := webhookResourceURI(wc.Name)
+		receiver := receivers[wc.Name]
+		s.AddResource(
+			&mcp.Resource{
+				Name:        wc.Name,
+				Description: fmt.Sprintf("Recent events received by the webhook %s, oldest first. Subscribe to be notified of new events", wc.Path),
+				URI:         uri,
+				MIMEType:    "application/json",
+			},
+			func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+				content, err := json.Marshal(&webhookEvents{Name: wc.Name, Path: wc.Path, Events: receiver.Events()})
+				if err != nil {
+					return nil, err
+				}
+				return &mcp.ReadResourceResult{
+					Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(content)}},
+				}, nil
+			},
+		)
+	}
+}
+
+// webhookSubscriptions handles the subscriptions of the sessions of server to the resources of webhooks. The
+// server notifies its subscribed sessions every time the webhook of a resource receives an event.
+type webhookSubscriptions struct {
+	server    *mcp.Server
+	receivers map[string]*webhook.Receiver
+}
+
+func (ws *webhookSubscriptions) subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	for name, receiver := range ws.receivers {
+		uri := webhookResourceURI(name)
+		if req.Params.URI != uri {
+			continue
+		}
+
+		// the server keeps track of its subscribed sessions, so it is only subscribed once
+		server := ws.server
+		receiver.Subscribe(server, func(*webhook.Event) {
+			_ = server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		})
+		return nil
+	}
+
+	return fmt.Errorf("resource %s doesn't support subscriptions", req.Params.URI)
+}
+
+func (ws *webhookSubscriptions) unsubscribe(context.Context, *mcp.UnsubscribeRequest) error {
+	return nil
+}
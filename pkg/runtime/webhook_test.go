@@ -0,0 +1,135 @@
+package runtime
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/health"
+)
+
+func TestWebhooks(t *testing.T) {
+	const body = `{"ref":"refs/heads/main"}`
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	validSignature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tt := []struct {
+		name            string
+		path            string
+		signature       string
+		expectedStatus  int
+		expectedEvents  int
+		expectedUpdated bool
+	}{
+		{
+			name:            "signed event",
+			path:            "/webhooks/github",
+			signature:       validSignature,
+			expectedStatus:  http.StatusAccepted,
+			expectedEvents:  1,
+			expectedUpdated: true,
+		},
+		{
+			name:           "invalid signature",
+			path:           "/webhooks/github",
+			signature:      "sha256=" + strings.Repeat("0", 64),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown path",
+			path:           "/webhooks/gitlab",
+			signature:      validSignature,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			mcpServer := newTestMCPServer(t, loadTestDefinitions(t))
+			mcpServer.Runtime.Webhooks = []*serverconfig.WebhookConfig{{Name: "github", Path: "/webhooks/github", Secret: "s3cret"}}
+			handler := newHTTPGeneration(mcpServer, health.NewChecker(), nil).handler
+
+			s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+			require.NoError(t, err)
+			updated := make(chan string, 10)
+			cs := connectTestClientWithOptions(t, s.Server, &mcp.ClientOptions{
+				ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+					updated <- req.Params.URI
+				},
+			})
+
+			resources, err := cs.ListResources(ctx, &mcp.ListResourcesParams{})
+			require.NoError(t, err)
+			require.Len(t, resources.Resources, 1)
+			assert.Equal(t, "webhook://github/events", resources.Resources[0].URI)
+			require.NoError(t, cs.Subscribe(ctx, &mcp.SubscribeParams{URI: "webhook://github/events"}))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(body))
+			req.Header.Set("X-Hub-Signature-256", tc.signature)
+			req.Header.Set("X-GitHub-Event", "push")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			if tc.expectedUpdated {
+				select {
+				case uri := <-updated:
+					assert.Equal(t, "webhook://github/events", uri)
+				case <-time.After(5 * time.Second):
+					t.Fatal("subscribed client should be notified of the event")
+				}
+			}
+
+			result, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "webhook://github/events"})
+			require.NoError(t, err)
+			require.Len(t, result.Contents, 1)
+			var events struct {
+				Name   string `json:"name"`
+				Path   string `json:"path"`
+				Events []struct {
+					ID      string            `json:"id"`
+					Headers map[string]string `json:"headers"`
+					Payload json.RawMessage   `json:"payload"`
+				} `json:"events"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &events))
+			assert.Equal(t, "github", events.Name)
+			assert.Equal(t, "/webhooks/github", events.Path)
+			require.Len(t, events.Events, tc.expectedEvents)
+			if tc.expectedEvents > 0 {
+				assert.Equal(t, "1", events.Events[0].ID)
+				assert.Equal(t, "push", events.Events[0].Headers["X-Github-Event"])
+				assert.JSONEq(t, body, string(events.Events[0].Payload))
+			}
+		})
+	}
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	mcpServer := newTestMCPServer(t, loadTestDefinitions(t))
+	mcpServer.Runtime.Webhooks = []*serverconfig.WebhookConfig{{Name: "github", Path: "/webhooks/github"}}
+
+	s, err := makeServerWithPrimitives(mcpServer, mcpServer, nil)
+	require.NoError(t, err)
+	cs, _ := connectTestClient(t, s.Server)
+
+	assert.True(t, cs.InitializeResult().Capabilities.Resources.Subscribe)
+	assert.NoError(t, cs.Subscribe(context.Background(), &mcp.SubscribeParams{URI: "webhook://github/events"}))
+	err = cs.Subscribe(context.Background(), &mcp.SubscribeParams{URI: "webhook://gitlab/events"})
+	assert.ErrorContains(t, err, "resource webhook://gitlab/events doesn't support subscriptions")
+}
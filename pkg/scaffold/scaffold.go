@@ -0,0 +1,284 @@
+// Package scaffold generates the config files of a new gen-mcp server, with an example tool for each
+// invocation type, so that a new project starts from files that pass validation.
+package scaffold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/genmcp/gen-mcp/pkg/config"
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/cli"
+	"github.com/genmcp/gen-mcp/pkg/invocation/extends"
+	"github.com/genmcp/gen-mcp/pkg/invocation/file"
+	"github.com/genmcp/gen-mcp/pkg/invocation/http"
+	"github.com/genmcp/gen-mcp/pkg/invocation/sql"
+)
+
+// InvocationTypes are the invocation types an example tool can be generated for.
+var InvocationTypes = []string{http.InvocationType, cli.InvocationType, sql.InvocationType, file.InvocationType, extends.InvocationType}
+
+const (
+	// exampleBaseName is the name of the invocation base extended by the example extends tool.
+	exampleBaseName = "jsonPlaceholder"
+
+	exampleAPIURL = "https://jsonplaceholder.typicode.com"
+)
+
+// Options configures the generated files.
+type Options struct {
+	// Name of the MCP server.
+	Name string
+
+	// Version of the MCP server.
+	Version string
+
+	// Transport protocol of the server, one of streamablehttp or stdio.
+	TransportProtocol string
+
+	// Port of the streamable HTTP server. Ignored for the stdio transport.
+	Port int
+
+	// Invocation types to generate an example tool for.
+	InvocationTypes []string
+}
+
+// DefaultOptions returns the options used for values not chosen by the user.
+func DefaultOptions() Options {
+	return Options{
+		Name:              "my-mcp-server",
+		Version:           "0.0.1",
+		TransportProtocol: serverconfig.TransportProtocolStreamableHttp,
+		Port:              serverconfig.DefaultPort,
+		InvocationTypes:   slices.Clone(InvocationTypes),
+	}
+}
+
+// Validate checks that the options can generate valid files.
+func (o Options) Validate() error {
+	var err error = nil
+
+	if strings.TrimSpace(o.Name) == "" {
+		err = errors.Join(err, fmt.Errorf("name is required"))
+	}
+
+	if strings.TrimSpace(o.Version) == "" {
+		err = errors.Join(err, fmt.Errorf("version is required"))
+	}
+
+	switch o.TransportProtocol {
+	case serverconfig.TransportProtocolStreamableHttp:
+		if o.Port <= 0 || o.Port > 65535 {
+			err = errors.Join(err, fmt.Errorf("invalid port %d, must be between 1 and 65535", o.Port))
+		}
+	case serverconfig.TransportProtocolStdio:
+	default:
+		err = errors.Join(err, fmt.Errorf("transport protocol must be one of (%s, %s), received %s",
+			serverconfig.TransportProtocolStreamableHttp, serverconfig.TransportProtocolStdio, o.TransportProtocol))
+	}
+
+	for _, t := range o.InvocationTypes {
+		if !slices.Contains(InvocationTypes, t) {
+			err = errors.Join(err, fmt.Errorf("invalid invocation type '%s', must be one of: %s", t, strings.Join(InvocationTypes, ", ")))
+		}
+	}
+
+	return err
+}
+
+// Files are the generated config files.
+type Files struct {
+	ToolDefinitions *definitions.MCPToolDefinitionsFile
+	ServerConfig    *serverconfig.MCPServerConfigFile
+}
+
+// Generate generates the MCP file and server config file for opts.
+func Generate(opts Options) (*Files, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	runtime := &serverconfig.ServerRuntime{
+		TransportProtocol: opts.TransportProtocol,
+	}
+	if opts.TransportProtocol == serverconfig.TransportProtocolStreamableHttp {
+		runtime.StreamableHTTPConfig = &serverconfig.StreamableHTTPConfig{
+			Port: opts.Port,
+		}
+	}
+
+	toolDefinitions := definitions.MCPToolDefinitions{
+		Name:    opts.Name,
+		Version: opts.Version,
+	}
+
+	for _, t := range InvocationTypes {
+		if !slices.Contains(opts.InvocationTypes, t) {
+			continue
+		}
+
+		switch t {
+		case http.InvocationType:
+			toolDefinitions.Tools = append(toolDefinitions.Tools, httpTool())
+		case cli.InvocationType:
+			toolDefinitions.Tools = append(toolDefinitions.Tools, cliTool())
+		case sql.InvocationType:
+			toolDefinitions.Tools = append(toolDefinitions.Tools, sqlTool())
+		case file.InvocationType:
+			toolDefinitions.Tools = append(toolDefinitions.Tools, fileTool())
+		case extends.InvocationType:
+			toolDefinitions.InvocationBases = map[string]*invocation.InvocationConfigWrapper{
+				exampleBaseName: {
+					Type: http.InvocationType,
+					Config: &http.HttpInvocationConfig{
+						URL:    exampleAPIURL + "/posts/{postId}",
+						Method: "GET",
+					},
+				},
+			}
+			toolDefinitions.Tools = append(toolDefinitions.Tools, extendsTool())
+		}
+	}
+
+	return &Files{
+		ToolDefinitions: &definitions.MCPToolDefinitionsFile{
+			Kind:               definitions.KindMCPToolDefinitions,
+			SchemaVersion:      config.SchemaVersion,
+			MCPToolDefinitions: toolDefinitions,
+		},
+		ServerConfig: &serverconfig.MCPServerConfigFile{
+			Kind:          serverconfig.KindMCPServerConfig,
+			SchemaVersion: config.SchemaVersion,
+			MCPServerConfig: serverconfig.MCPServerConfig{
+				Runtime: runtime,
+			},
+		},
+	}, nil
+}
+
+func httpTool() *definitions.Tool {
+	return &definitions.Tool{
+		Name:        "get_post",
+		Title:       "Get post",
+		Description: "Get a blog post by its id, using an HTTP API.",
+		InputSchema: objectSchema(map[string]*jsonschema.Schema{
+			"postId": {Type: invocation.JsonSchemaTypeInteger, Description: "The id of the post"},
+		}),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: http.InvocationType,
+			Config: &http.HttpInvocationConfig{
+				URL:    exampleAPIURL + "/posts/{postId}",
+				Method: "GET",
+			},
+		},
+		Annotations: readOnlyAnnotations(),
+	}
+}
+
+func cliTool() *definitions.Tool {
+	return &definitions.Tool{
+		Name:        "echo",
+		Title:       "Echo",
+		Description: "Echo a message back, using a command line tool.",
+		InputSchema: objectSchema(map[string]*jsonschema.Schema{
+			"message": {Type: invocation.JsonSchemaTypeString, Description: "The message to echo"},
+		}),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: cli.InvocationType,
+			Config: &cli.CliInvocationConfig{
+				Command: "echo {message}",
+				Quoting: cli.QuotingShell,
+			},
+		},
+		Annotations: readOnlyAnnotations(),
+	}
+}
+
+func sqlTool() *definitions.Tool {
+	return &definitions.Tool{
+		Name:        "list_tables",
+		Title:       "List tables",
+		Description: "List the tables or views of a SQLite database, using a SQL query.",
+		InputSchema: objectSchema(map[string]*jsonschema.Schema{
+			"type": {
+				Type:        invocation.JsonSchemaTypeString,
+				Description: "The type of the objects to list",
+				Enum:        []any{"table", "view"},
+			},
+		}),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: sql.InvocationType,
+			Config: &sql.SqlInvocationConfig{
+				Driver:   sql.DriverSQLite,
+				DSN:      "example.db",
+				Query:    "SELECT name FROM sqlite_master WHERE type = {type}",
+				ReadOnly: true,
+			},
+		},
+		Annotations: readOnlyAnnotations(),
+	}
+}
+
+func fileTool() *definitions.Tool {
+	return &definitions.Tool{
+		Name:        "read_file",
+		Title:       "Read file",
+		Description: "Read a file of the current directory, using the filesystem.",
+		InputSchema: objectSchema(map[string]*jsonschema.Schema{
+			"path": {Type: invocation.JsonSchemaTypeString, Description: "The path of the file, relative to the current directory"},
+		}),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: file.InvocationType,
+			Config: &file.FileInvocationConfig{
+				Root: ".",
+				Path: "{path}",
+			},
+		},
+		Annotations: readOnlyAnnotations(),
+	}
+}
+
+func extendsTool() *definitions.Tool {
+	return &definitions.Tool{
+		Name:        "get_post_comments",
+		Title:       "Get post comments",
+		Description: "Get the comments of a blog post, by extending the invocation of a base HTTP request.",
+		InputSchema: objectSchema(map[string]*jsonschema.Schema{
+			"postId": {Type: invocation.JsonSchemaTypeInteger, Description: "The id of the post"},
+		}),
+		InvocationConfigWrapper: &invocation.InvocationConfigWrapper{
+			Type: extends.InvocationType,
+			Config: &extends.ExtendsConfig{
+				From:     exampleBaseName,
+				Override: json.RawMessage(fmt.Sprintf(`{"url":%q}`, exampleAPIURL+"/posts/{postId}/comments")),
+			},
+		},
+		Annotations: readOnlyAnnotations(),
+	}
+}
+
+// objectSchema returns an input schema with the given properties, all of them required.
+func objectSchema(properties map[string]*jsonschema.Schema) *jsonschema.Schema {
+	s := &jsonschema.Schema{
+		Type:       invocation.JsonSchemaTypeObject,
+		Properties: properties,
+	}
+	for name := range properties {
+		s.Required = append(s.Required, name)
+	}
+	slices.Sort(s.Required)
+
+	return s
+}
+
+func readOnlyAnnotations() *definitions.ToolAnnotations {
+	readOnly := true
+	return &definitions.ToolAnnotations{ReadOnlyHint: &readOnly}
+}
@@ -0,0 +1,130 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/genmcp/gen-mcp/pkg/config/diagnostics"
+	serverconfig "github.com/genmcp/gen-mcp/pkg/config/server"
+)
+
+func TestGenerate(t *testing.T) {
+	tt := []struct {
+		name          string
+		modify        func(o *Options)
+		expectedTools []string
+	}{
+		{
+			name:          "defaults",
+			modify:        func(o *Options) {},
+			expectedTools: []string{"get_post", "echo", "list_tables", "read_file", "get_post_comments"},
+		},
+		{
+			name: "stdio with a single invocation type",
+			modify: func(o *Options) {
+				o.TransportProtocol = serverconfig.TransportProtocolStdio
+				o.InvocationTypes = []string{"cli"}
+			},
+			expectedTools: []string{"echo"},
+		},
+		{
+			name: "no example tools",
+			modify: func(o *Options) {
+				o.InvocationTypes = nil
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tc.modify(&opts)
+
+			files, err := Generate(opts)
+			require.NoError(t, err)
+
+			var toolNames []string
+			for _, tool := range files.ToolDefinitions.Tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, toolNames)
+
+			// the generated files must pass validation out of the box
+			dir := t.TempDir()
+			mcpFilePath := filepath.Join(dir, "mcpfile.yaml")
+			serverConfigPath := filepath.Join(dir, "mcpserver.yaml")
+			writeYAML(t, mcpFilePath, files.ToolDefinitions)
+			writeYAML(t, serverConfigPath, files.ServerConfig)
+
+			assert.Empty(t, diagnostics.ValidateMCPFile(mcpFilePath).Diagnostics)
+			assert.Empty(t, diagnostics.ValidateServerConfigFile(serverConfigPath, "").Diagnostics)
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tt := []struct {
+		name        string
+		modify      func(o *Options)
+		errContains string
+	}{
+		{
+			name:   "defaults",
+			modify: func(o *Options) {},
+		},
+		{
+			name:        "missing name",
+			modify:      func(o *Options) { o.Name = " " },
+			errContains: "name is required",
+		},
+		{
+			name:        "invalid port",
+			modify:      func(o *Options) { o.Port = 70000 },
+			errContains: "invalid port 70000",
+		},
+		{
+			name: "port is ignored for stdio",
+			modify: func(o *Options) {
+				o.TransportProtocol = serverconfig.TransportProtocolStdio
+				o.Port = 0
+			},
+		},
+		{
+			name:        "invalid transport",
+			modify:      func(o *Options) { o.TransportProtocol = "websocket" },
+			errContains: "received websocket",
+		},
+		{
+			name:        "invalid invocation type",
+			modify:      func(o *Options) { o.InvocationTypes = []string{"grpc"} },
+			errContains: "invalid invocation type 'grpc'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tc.modify(&opts)
+
+			err := opts.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+}
+
+func writeYAML(t *testing.T, path string, v any) {
+	t.Helper()
+
+	data, err := yaml.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns the times a job runs at.
+type Schedule interface {
+	// Next returns the first time after t the job runs at, or the zero time if it never runs again.
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule: a cron expression with five fields, the minute, hour, day of the month, month
+// and day of the week (e.g. "0 6 * * 1-5"), a descriptor (@yearly, @monthly, @weekly, @daily or @hourly),
+// or @every followed by a duration of at least a second (e.g. "@every 15m"). Cron expressions and
+// descriptors use the local time zone, unless they are prefixed with CRON_TZ=<zone>, e.g.
+// "CRON_TZ=Europe/Paris 0 6 * * *".
+func Parse(spec string) (Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Job is a function run on a schedule.
+type Job struct {
+	// Name of the job, unique in its scheduler.
+	Name string
+
+	// Schedule of the runs of the job.
+	Schedule Schedule
+
+	// RunOnStart also runs the job when the scheduler starts it.
+	RunOnStart bool
+
+	// Run runs the job, returning its result. The context is done when the scheduler stops.
+	Run func(ctx context.Context) (any, error)
+}
+
+// Run is a finished run of a job.
+type Run struct {
+	Started  time.Time
+	Finished time.Time
+
+	// Result returned by the job.
+	Result any
+
+	// Err is the error the job failed with, nil if it succeeded.
+	Err error
+}
+
+// Status is the status of a job.
+type Status struct {
+	// Last run of the job, nil until a run finished.
+	Last *Run
+
+	// Next time the job runs at, zero while it is running or if it doesn't run again.
+	Next time.Time
+
+	// Running is whether the job is running.
+	Running bool
+}
+
+// Scheduler runs jobs on their schedules. A job doesn't run while its previous run is not finished: the
+// times it should have run at in the meantime are skipped.
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs map[string]*jobState
+}
+
+type jobState struct {
+	job    *Job
+	cancel context.CancelFunc
+	status Status
+}
+
+// New creates a scheduler without jobs.
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*jobState)}
+}
+
+// Start runs job on its schedule, in its own goroutine, until ctx is done. A job started with the same name
+// is stopped and replaced by job, which keeps its last run, e.g. when a server is started again.
+func (s *Scheduler) Start(ctx context.Context, job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	state := &jobState{job: job, cancel: cancel}
+	if previous, ok := s.jobs[job.Name]; ok {
+		previous.cancel()
+		state.status.Last = previous.status.Last
+	}
+	s.jobs[job.Name] = state
+
+	go s.run(ctx, state)
+}
+
+// Status returns the status of the named job, and whether it was started.
+func (s *Scheduler) Status(name string) (Status, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.jobs[name]
+	if !ok {
+		return Status{}, false
+	}
+	return state.status, true
+}
+
+func (s *Scheduler) run(ctx context.Context, state *jobState) {
+	if state.job.RunOnStart {
+		s.runJob(ctx, state)
+	}
+
+	for {
+		next := state.job.Schedule.Next(time.Now())
+		s.mu.Lock()
+		state.status.Next = next
+		s.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runJob(ctx, state)
+	}
+}
+
+// runJob runs the job of state, and records its run once it finished.
+func (s *Scheduler) runJob(ctx context.Context, state *jobState) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.mu.Lock()
+	state.status.Running = true
+	state.status.Next = time.Time{}
+	s.mu.Unlock()
+
+	run := &Run{Started: time.Now()}
+	run.Result, run.Err = runRecovered(ctx, state.job)
+	run.Finished = time.Now()
+
+	s.mu.Lock()
+	state.status.Running = false
+	state.status.Last = run
+	s.mu.Unlock()
+}
+
+// runRecovered runs job, returning an error if it panics, so that a failing job doesn't stop the program
+// running the scheduler.
+func runRecovered(ctx context.Context, job *Job) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job '%s' panicked: %v", job.Name, r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// every is a schedule running a job at a fixed interval, shorter than the second of @every schedules.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// never is a schedule never running a job.
+type never struct{}
+
+func (never) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+func TestParse(t *testing.T) {
+	from := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+
+	tt := []struct {
+		name          string
+		spec          string
+		expectedNext  time.Time
+		expectedError string
+	}{
+		{
+			name:         "cron expression",
+			spec:         "CRON_TZ=UTC 0 6 * * 1-5",
+			expectedNext: time.Date(2026, 3, 3, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "descriptor",
+			spec:         "CRON_TZ=UTC @hourly",
+			expectedNext: time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "interval",
+			spec:         "@every 15m",
+			expectedNext: time.Date(2026, 3, 2, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name:          "seconds field",
+			spec:          "0 0 6 * * *",
+			expectedError: "invalid schedule '0 0 6 * * *'",
+		},
+		{
+			name:          "invalid field",
+			spec:          "0 25 * * *",
+			expectedError: "invalid schedule '0 25 * * *'",
+		},
+		{
+			name:          "empty",
+			spec:          "",
+			expectedError: "invalid schedule ''",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := Parse(tc.spec)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNext, schedule.Next(from).UTC())
+		})
+	}
+}
+
+func TestSchedulerStart(t *testing.T) {
+	tt := []struct {
+		name           string
+		job            *Job
+		expectedResult any
+		expectedError  string
+	}{
+		{
+			name: "scheduled run",
+			job: &Job{
+				Schedule: every(10 * time.Millisecond),
+				Run: func(context.Context) (any, error) {
+					return "done", nil
+				},
+			},
+			expectedResult: "done",
+		},
+		{
+			name: "run on start",
+			job: &Job{
+				Schedule:   never{},
+				RunOnStart: true,
+				Run: func(context.Context) (any, error) {
+					return "started", nil
+				},
+			},
+			expectedResult: "started",
+		},
+		{
+			name: "failed run",
+			job: &Job{
+				Schedule: every(10 * time.Millisecond),
+				Run: func(context.Context) (any, error) {
+					return nil, errors.New("backend unavailable")
+				},
+			},
+			expectedError: "backend unavailable",
+		},
+		{
+			name: "panicking run",
+			job: &Job{
+				Schedule: every(10 * time.Millisecond),
+				Run: func(context.Context) (any, error) {
+					panic("nil map")
+				},
+			},
+			expectedError: "job 'report' panicked: nil map",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tc.job.Name = "report"
+			s := New()
+			s.Start(ctx, tc.job)
+
+			var status Status
+			require.Eventually(t, func() bool {
+				status, _ = s.Status("report")
+				return status.Last != nil
+			}, 5*time.Second, 5*time.Millisecond)
+
+			assert.Equal(t, tc.expectedResult, status.Last.Result)
+			assert.False(t, status.Last.Finished.Before(status.Last.Started))
+			if tc.expectedError != "" {
+				assert.EqualError(t, status.Last.Err, tc.expectedError)
+			} else {
+				assert.NoError(t, status.Last.Err)
+			}
+		})
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, overlapping, runs atomic.Int32
+	s := New()
+	s.Start(ctx, &Job{
+		Name:     "slow",
+		Schedule: every(time.Millisecond),
+		Run: func(context.Context) (any, error) {
+			if running.Add(1) > 1 {
+				overlapping.Add(1)
+			}
+			defer running.Add(-1)
+			time.Sleep(20 * time.Millisecond)
+			return runs.Add(1), nil
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		return runs.Load() >= 3
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Zero(t, overlapping.Load())
+}
+
+func TestSchedulerStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	s := New()
+	s.Start(ctx, &Job{
+		Name:       "report",
+		Schedule:   every(time.Hour),
+		RunOnStart: true,
+		Run: func(ctx context.Context) (any, error) {
+			<-release
+			return nil, ctx.Err()
+		},
+	})
+
+	_, ok := s.Status("unknown")
+	assert.False(t, ok)
+
+	require.Eventually(t, func() bool {
+		status, _ := s.Status("report")
+		return status.Running
+	}, 5*time.Second, 5*time.Millisecond)
+	status, ok := s.Status("report")
+	assert.True(t, ok)
+	assert.Nil(t, status.Last)
+	assert.True(t, status.Next.IsZero())
+
+	close(release)
+	require.Eventually(t, func() bool {
+		status, _ = s.Status("report")
+		return !status.Next.IsZero()
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.False(t, status.Running)
+	assert.NotNil(t, status.Last)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), status.Next, time.Minute)
+
+	// the job is not run once stopped
+	cancel()
+	assert.Never(t, func() bool {
+		status, _ := s.Status("report")
+		return status.Running
+	}, 50*time.Millisecond, 5*time.Millisecond)
+
+	// a job started again keeps its last run
+	last := status.Last
+	s.Start(context.Background(), &Job{Name: "report", Schedule: never{}})
+	require.Eventually(t, func() bool {
+		status, _ = s.Status("report")
+		return status.Next.IsZero()
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Same(t, last, status.Last)
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSecretsMiddleware creates an MCP middleware that injects store into the request context, so that
+// invokers resolve {secrets.NAME} references from it. It also wraps the request and base loggers of the
+// context so that the values of secrets are redacted from their output, so it must run after the logging
+// middleware: add it to the server before it.
+func WithSecretsMiddleware(store *Store) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+			ctx = WithStore(ctx, store)
+			ctx = logging.WithRequestLogger(ctx, RedactingLogger(logging.FromContext(ctx), store))
+			ctx = logging.WithBaseLogger(ctx, RedactingLogger(logging.BaseFromContext(ctx), store))
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// RedactingLogger returns a logger writing to the same outputs as logger, with the values of the secrets
+// resolved by store redacted from messages and from string, error, and stringer fields.
+func RedactingLogger(logger *zap.Logger, store *Store) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core, store: store}
+	}))
+}
+
+type redactingCore struct {
+	zapcore.Core
+	store *Store
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), store: c.store}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.store.Redact(ent.Message)
+	return c.Core.Write(ent, c.redactFields(fields))
+}
+
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = c.store.Redact(f.String)
+		case zapcore.ByteStringType:
+			f = zap.String(f.Key, c.store.Redact(string(f.Interface.([]byte))))
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zap.String(f.Key, c.store.Redact(err.Error()))
+			}
+		case zapcore.StringerType:
+			if s, ok := f.Interface.(fmt.Stringer); ok && s != nil {
+				f = zap.String(f.Key, c.store.Redact(s.String()))
+			}
+		}
+		redacted[i] = f
+	}
+	return redacted
+}
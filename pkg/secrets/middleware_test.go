@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
+
+func TestRedactingLogger(t *testing.T) {
+	store := NewStore(mapProvider{"TOKEN": "s3cr3t"})
+	_, err := store.Resolve("TOKEN")
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.DebugLevel)
+	logger := RedactingLogger(zap.New(core), store).With(zap.String("request_url", "https://example.com/?token=s3cr3t"))
+
+	logger.Info("calling with s3cr3t",
+		zap.String("header", "Bearer s3cr3t"),
+		zap.ByteString("body", []byte(`{"token":"s3cr3t"}`)),
+		zap.Error(errors.New("rejected s3cr3t")),
+		zap.Stringer("command", stringer("curl -H s3cr3t")),
+		zap.Int("status", 401),
+	)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "calling with [REDACTED]", entries[0].Message)
+	assert.Equal(t, map[string]any{
+		"request_url": "https://example.com/?token=[REDACTED]",
+		"header":      "Bearer [REDACTED]",
+		"body":        `{"token":"[REDACTED]"}`,
+		"error":       "rejected [REDACTED]",
+		"command":     "curl -H [REDACTED]",
+		"status":      int64(401),
+	}, entries[0].ContextMap())
+}
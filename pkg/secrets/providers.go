@@ -0,0 +1,91 @@
+// Package secrets resolves the {secrets.NAME} references of invocation templates from pluggable providers,
+// and redacts the resolved values from logs.
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Provider looks up secrets by name.
+type Provider interface {
+	// Lookup returns the value of the secret, and whether the provider has it.
+	Lookup(name string) (string, bool, error)
+}
+
+// EnvProvider reads secrets from the environment variables named after them, with an optional prefix.
+type EnvProvider struct {
+	Prefix string
+}
+
+func (p *EnvProvider) Lookup(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(p.Prefix + name)
+	return value, ok, nil
+}
+
+// FileProvider reads secrets from a file of NAME=value lines, such as a dotenv file. The file is read on
+// every lookup, so that rotated secrets are picked up without restarting the server.
+type FileProvider struct {
+	Path string
+}
+
+func (p *FileProvider) Lookup(name string) (string, bool, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", false, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found || strings.TrimSpace(key) != name {
+			continue
+		}
+
+		return unquote(strings.TrimSpace(value)), true, nil
+	}
+
+	return "", false, scanner.Err()
+}
+
+// unquote removes the single or double quotes around a value, if any.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// DirectoryProvider reads every secret from the file named after it in a directory, which is how Kubernetes
+// mounts secrets into containers. Trailing newlines are removed from the values. Files are read on every
+// lookup, so that rotated secrets are picked up without restarting the server.
+type DirectoryProvider struct {
+	Path string
+}
+
+func (p *DirectoryProvider) Lookup(name string) (string, bool, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", false, fmt.Errorf("invalid secret name '%s'", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.Path, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
@@ -0,0 +1,116 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviders(t *testing.T) {
+	t.Setenv("GENMCP_TEST_API_KEY", "env-key")
+
+	file := filepath.Join(t.TempDir(), "secrets.env")
+	require.NoError(t, os.WriteFile(file, []byte(`# credentials
+API_KEY=file-key
+export TOKEN="quoted token"
+PASSWORD = 'p=ss'
+`), 0o600))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "API_KEY"), []byte("dir-key\n"), 0o600))
+
+	tt := []struct {
+		name          string
+		provider      Provider
+		secret        string
+		expected      string
+		expectedFound bool
+		errContains   string
+	}{
+		{
+			name:          "env",
+			provider:      &EnvProvider{},
+			secret:        "GENMCP_TEST_API_KEY",
+			expected:      "env-key",
+			expectedFound: true,
+		},
+		{
+			name:          "env with prefix",
+			provider:      &EnvProvider{Prefix: "GENMCP_TEST_"},
+			secret:        "API_KEY",
+			expected:      "env-key",
+			expectedFound: true,
+		},
+		{
+			name:     "env not found",
+			provider: &EnvProvider{Prefix: "GENMCP_TEST_"},
+			secret:   "MISSING",
+		},
+		{
+			name:          "file",
+			provider:      &FileProvider{Path: file},
+			secret:        "API_KEY",
+			expected:      "file-key",
+			expectedFound: true,
+		},
+		{
+			name:          "file with export and quotes",
+			provider:      &FileProvider{Path: file},
+			secret:        "TOKEN",
+			expected:      "quoted token",
+			expectedFound: true,
+		},
+		{
+			name:          "file with spaces around the separator",
+			provider:      &FileProvider{Path: file},
+			secret:        "PASSWORD",
+			expected:      "p=ss",
+			expectedFound: true,
+		},
+		{
+			name:     "file not found",
+			provider: &FileProvider{Path: file},
+			secret:   "MISSING",
+		},
+		{
+			name:        "missing file",
+			provider:    &FileProvider{Path: filepath.Join(dir, "missing.env")},
+			secret:      "API_KEY",
+			errContains: "no such file or directory",
+		},
+		{
+			name:          "directory",
+			provider:      &DirectoryProvider{Path: dir},
+			secret:        "API_KEY",
+			expected:      "dir-key",
+			expectedFound: true,
+		},
+		{
+			name:     "directory not found",
+			provider: &DirectoryProvider{Path: dir},
+			secret:   "MISSING",
+		},
+		{
+			name:        "directory traversal",
+			provider:    &DirectoryProvider{Path: dir},
+			secret:      "../secrets.env",
+			errContains: "invalid secret name '../secrets.env'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, found, err := tc.provider.Lookup(tc.secret)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// Redacted replaces the values of secrets in logs.
+const Redacted = "[REDACTED]"
+
+// Store resolves secrets from a list of providers, in order. It remembers the values it resolved, so
+// that they can be redacted from logs.
+type Store struct {
+	providers []Provider
+	aliases   map[string]string // names of the secrets resolved for other names, e.g. the secrets of a tenant
+
+	redaction *redaction
+}
+
+// redaction holds the values resolved by a store and by the stores derived from it with WithAliases.
+type redaction struct {
+	mu       sync.RWMutex
+	resolved []string // values resolved so far, longest first
+}
+
+var _ template.SourceResolver = &Store{}
+
+// defaultStore is used when no store is set in the context: secrets are read from environment variables.
+var defaultStore = NewStore(&EnvProvider{})
+
+// NewStore creates a store resolving secrets from providers, the first provider having a secret winning.
+func NewStore(providers ...Provider) *Store {
+	return &Store{providers: providers, redaction: &redaction{}}
+}
+
+// WithAliases returns a store resolving the secrets named by the keys of aliases from the secrets named by
+// their values, e.g. {secrets.API_KEY} from the API key of the tenant of the caller, and the other secrets
+// as s does. The values it resolves are redacted by s.
+func (s *Store) WithAliases(aliases map[string]string) *Store {
+	return &Store{providers: s.providers, aliases: aliases, redaction: s.redaction}
+}
+
+// Resolve returns the value of the secret name from the first provider that has it.
+func (s *Store) Resolve(name string) (string, error) {
+	if alias, ok := s.aliases[name]; ok {
+		name = alias
+	}
+
+	for _, provider := range s.providers {
+		value, ok, err := provider.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret '%s': %w", name, err)
+		}
+		if ok {
+			s.remember(value)
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("secret '%s' not found", name)
+}
+
+func (s *Store) remember(value string) {
+	if value == "" {
+		return
+	}
+
+	r := s.redaction
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Contains(r.resolved, value) {
+		return
+	}
+	r.resolved = append(r.resolved, value)
+	// replace longer values first, so that a secret containing another one is fully redacted
+	slices.SortFunc(r.resolved, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+}
+
+// Redact replaces the values of the secrets resolved by the store in text.
+func (s *Store) Redact(text string) string {
+	r := s.redaction
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.resolved {
+		text = strings.ReplaceAll(text, value, Redacted)
+	}
+
+	return text
+}
+
+type ctxKey struct{}
+
+// WithStore stores a secret store in the given context.
+func WithStore(ctx context.Context, store *Store) context.Context {
+	return context.WithValue(ctx, ctxKey{}, store)
+}
+
+// FromContext retrieves the secret store stored in the context by WithStore.
+// If no store is found, it returns a store reading secrets from environment variables.
+func FromContext(ctx context.Context) *Store {
+	if store, ok := ctx.Value(ctxKey{}).(*Store); ok && store != nil {
+		return store
+	}
+	return defaultStore
+}
@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapProvider map[string]string
+
+func (p mapProvider) Lookup(name string) (string, bool, error) {
+	value, ok := p[name]
+	return value, ok, nil
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(
+		mapProvider{"API_KEY": "key", "TOKEN": "token"},
+		mapProvider{"API_KEY": "shadowed", "FULL_TOKEN": "token-suffix"},
+	)
+
+	tt := []struct {
+		name        string
+		secret      string
+		expected    string
+		errContains string
+	}{
+		{
+			name:     "first provider",
+			secret:   "API_KEY",
+			expected: "key",
+		},
+		{
+			name:     "second provider",
+			secret:   "FULL_TOKEN",
+			expected: "token-suffix",
+		},
+		{
+			name:        "not found",
+			secret:      "MISSING",
+			errContains: "secret 'MISSING' not found",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := store.Resolve(tc.secret)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestStoreRedact(t *testing.T) {
+	store := NewStore(mapProvider{"API_KEY": "key", "TOKEN": "token", "FULL_TOKEN": "token-suffix"})
+
+	assert.Equal(t, "key=key", store.Redact("key=key"), "values are only redacted once resolved")
+
+	for _, name := range []string{"API_KEY", "TOKEN", "FULL_TOKEN"} {
+		_, err := store.Resolve(name)
+		require.NoError(t, err)
+	}
+
+	tt := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "single value",
+			text:     "Authorization: Bearer token",
+			expected: "Authorization: Bearer [REDACTED]",
+		},
+		{
+			name:     "longest value first",
+			text:     "https://example.com/?t=token-suffix&k=key",
+			expected: "https://example.com/?t=[REDACTED]&k=[REDACTED]",
+		},
+		{
+			name:     "no secret",
+			text:     "nothing to hide",
+			expected: "nothing to hide",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, store.Redact(tc.text))
+		})
+	}
+}
+
+func TestStoreWithAliases(t *testing.T) {
+	store := NewStore(mapProvider{"API_KEY": "key", "ACME_API_KEY": "acme-key", "TOKEN": "token"})
+	tenantStore := store.WithAliases(map[string]string{"API_KEY": "ACME_API_KEY", "DB_PASSWORD": "ACME_DB_PASSWORD"})
+
+	tt := []struct {
+		name        string
+		secret      string
+		expected    string
+		errContains string
+	}{
+		{
+			name:     "aliased secret",
+			secret:   "API_KEY",
+			expected: "acme-key",
+		},
+		{
+			name:     "secret without alias",
+			secret:   "TOKEN",
+			expected: "token",
+		},
+		{
+			name:        "alias of a missing secret",
+			secret:      "DB_PASSWORD",
+			errContains: "secret 'ACME_DB_PASSWORD' not found",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := tenantStore.Resolve(tc.secret)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+
+	assert.Equal(t, "key=[REDACTED]", store.Redact("key=acme-key"), "values resolved with aliases are redacted by the store")
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, defaultStore, FromContext(context.Background()))
+
+	store := NewStore()
+	assert.Same(t, store, FromContext(WithStore(context.Background(), store)))
+}
@@ -0,0 +1,308 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+	VaultAuthAppRole    = "approle"
+
+	// DefaultKubernetesJWTFile is the service account token mounted into Kubernetes pods.
+	DefaultKubernetesJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	vaultRequestTimeout = 10 * time.Second
+)
+
+// errVaultForbidden is returned for 403 responses, after which the provider logs in again.
+var errVaultForbidden = errors.New("permission denied")
+
+// VaultAuth defines how a VaultProvider authenticates to Vault.
+type VaultAuth struct {
+	Method string // token, kubernetes, or approle
+
+	// token: the token is read from TokenFile if set, or from the VAULT_TOKEN environment variable.
+	TokenFile string
+
+	// kubernetes and approle: path the auth method is mounted at, defaulting to the name of the method.
+	MountPath string
+
+	// kubernetes: role to log in with, and service account token file (DefaultKubernetesJWTFile if empty).
+	Role    string
+	JWTFile string
+
+	// approle: role ID, and file of the secret ID, read from the VAULT_SECRET_ID environment variable if empty.
+	RoleID       string
+	SecretIDFile string
+}
+
+// VaultProvider reads secrets from the keys of a secret of a HashiCorp Vault KV secrets engine, e.g.
+// {secrets.API_KEY} from the API_KEY key of the secret at Path. The secret is fetched on the first lookup
+// and cached for CacheTTL.
+type VaultProvider struct {
+	Address   string // e.g. https://vault.example.com:8200
+	Namespace string // Vault Enterprise namespace, if any
+	MountPath string // path the KV engine is mounted at
+	Path      string // path of the secret within the engine
+	KVVersion int    // version of the KV engine, 1 or 2
+	Auth      VaultAuth
+
+	// CacheTTL is how long a fetched secret is reused. It is fetched on every lookup if 0, and
+	// only once if negative.
+	CacheTTL time.Duration
+
+	Client *http.Client
+
+	mu          sync.Mutex
+	data        map[string]string
+	fetchedAt   time.Time
+	token       string
+	tokenExpiry time.Time // zero if the token does not expire
+}
+
+func (p *VaultProvider) Lookup(name string) (string, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.data == nil || p.expired() {
+		if err := p.fetch(); err != nil {
+			return "", false, err
+		}
+	}
+
+	value, ok := p.data[name]
+	return value, ok, nil
+}
+
+// Load fetches the secret now, so that unreachable servers or invalid credentials are reported on startup.
+func (p *VaultProvider) Load() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.fetch()
+}
+
+func (p *VaultProvider) expired() bool {
+	return p.CacheTTL >= 0 && time.Since(p.fetchedAt) >= p.CacheTTL
+}
+
+func (p *VaultProvider) fetch() error {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+
+	data, err := p.readSecret(ctx)
+	if errors.Is(err, errVaultForbidden) && p.Auth.Method != VaultAuthToken {
+		// the token may have been revoked before its expiry, log in again once
+		p.token = ""
+		data, err = p.readSecret(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vault secret '%s/%s': %w", p.MountPath, p.Path, err)
+	}
+
+	p.data = data
+	p.fetchedAt = time.Now()
+	return nil
+}
+
+func (p *VaultProvider) readSecret(ctx context.Context) (map[string]string, error) {
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretPath := strings.Trim(p.MountPath, "/") + "/" + strings.Trim(p.Path, "/")
+	if p.KVVersion != 1 {
+		secretPath = strings.Trim(p.MountPath, "/") + "/data/" + strings.Trim(p.Path, "/")
+	}
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, secretPath, token, nil, &response); err != nil {
+		return nil, err
+	}
+
+	fields := response.Data
+	if p.KVVersion != 1 {
+		// KV version 2 nests the fields of the secret next to its metadata
+		fields, _ = response.Data["data"].(map[string]any)
+	}
+
+	data := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			data[key] = s
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode key '%s': %w", key, err)
+		}
+		data[key] = string(encoded)
+	}
+
+	return data, nil
+}
+
+// getToken returns the token of the provider, logging in if it has none or if it expired.
+func (p *VaultProvider) getToken(ctx context.Context) (string, error) {
+	if p.token != "" && (p.tokenExpiry.IsZero() || time.Now().Before(p.tokenExpiry)) {
+		return p.token, nil
+	}
+
+	var err error
+	switch p.Auth.Method {
+	case VaultAuthToken:
+		p.token, err = readCredential(p.Auth.TokenFile, "VAULT_TOKEN")
+		p.tokenExpiry = time.Time{}
+		if err != nil {
+			return "", fmt.Errorf("failed to read vault token: %w", err)
+		}
+		return p.token, nil
+	case VaultAuthKubernetes:
+		jwtFile := p.Auth.JWTFile
+		if jwtFile == "" {
+			jwtFile = DefaultKubernetesJWTFile
+		}
+		jwt, err := readCredential(jwtFile, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to read service account token: %w", err)
+		}
+		return p.login(ctx, map[string]string{"role": p.Auth.Role, "jwt": jwt})
+	case VaultAuthAppRole:
+		secretID, err := readCredential(p.Auth.SecretIDFile, "VAULT_SECRET_ID")
+		if err != nil {
+			return "", fmt.Errorf("failed to read approle secret ID: %w", err)
+		}
+		return p.login(ctx, map[string]string{"role_id": p.Auth.RoleID, "secret_id": secretID})
+	default:
+		return "", fmt.Errorf("unsupported vault auth method '%s'", p.Auth.Method)
+	}
+}
+
+func (p *VaultProvider) login(ctx context.Context, credentials map[string]string) (string, error) {
+	mountPath := p.Auth.MountPath
+	if mountPath == "" {
+		mountPath = p.Auth.Method
+	}
+
+	body, err := json.Marshal(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := p.do(ctx, http.MethodPost, "auth/"+strings.Trim(mountPath, "/")+"/login", "", body, &response); err != nil {
+		return "", fmt.Errorf("failed to log in with %s auth: %w", p.Auth.Method, err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed to log in with %s auth: no token returned", p.Auth.Method)
+	}
+
+	p.token = response.Auth.ClientToken
+	p.tokenExpiry = time.Time{}
+	if response.Auth.LeaseDuration > 0 {
+		// log in again a little before the token expires
+		lease := time.Duration(response.Auth.LeaseDuration) * time.Second
+		p.tokenExpiry = time.Now().Add(lease - lease/10)
+	}
+
+	return p.token, nil
+}
+
+// do sends a request to the Vault API and decodes the JSON response into out.
+func (p *VaultProvider) do(ctx context.Context, method, path, token string, body []byte, out any) error {
+	endpoint, err := url.JoinPath(p.Address, "v1", path)
+	if err != nil {
+		return fmt.Errorf("invalid vault address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(respBody, &vaultErr)
+
+		switch {
+		case resp.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("%w (status 403)", errVaultForbidden)
+		case resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("not found (status 404)")
+		case len(vaultErr.Errors) > 0:
+			return fmt.Errorf("%s (status %d)", strings.Join(vaultErr.Errors, ", "), resp.StatusCode)
+		default:
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// readCredential reads a credential from file if set, or from the environment variable envVar otherwise.
+func readCredential(file, envVar string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if value := os.Getenv(envVar); envVar != "" && value != "" {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("environment variable %s is not set", envVar)
+}
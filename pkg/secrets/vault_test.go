@@ -0,0 +1,270 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the KV secret genmcp of the engines mounted at secret (version 2) and kv (version 1),
+// and issues the token valid-token to the kubernetes and approle auth methods.
+type fakeVault struct {
+	mu        sync.Mutex
+	requests  []string
+	namespace string
+	revoked   bool // whether valid-token is rejected, until the next login
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	f.namespace = r.Header.Get("X-Vault-Namespace")
+
+	switch r.URL.Path {
+	case "/v1/auth/kubernetes/login", "/v1/auth/approle/login":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["jwt"] != "sa-token" && body["secret_id"] != "secret-id" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid credentials"]}`))
+			return
+		}
+		f.revoked = false
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"valid-token","lease_duration":3600}}`))
+		return
+	}
+
+	token := r.Header.Get("X-Vault-Token")
+	if (token != "valid-token" && token != "root") || (token == "valid-token" && f.revoked) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/secret/data/genmcp":
+		_, _ = w.Write([]byte(`{"data":{"data":{"API_KEY":"v2-key","PORT":8080},"metadata":{"version":3}}}`))
+	case "/v1/kv/genmcp":
+		_, _ = w.Write([]byte(`{"data":{"API_KEY":"v1-key"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+	}
+}
+
+func (f *fakeVault) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func TestVaultProvider(t *testing.T) {
+	dir := t.TempDir()
+	jwtFile := filepath.Join(dir, "sa-token")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("sa-token\n"), 0o600))
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_SECRET_ID", "secret-id")
+
+	tt := []struct {
+		name             string
+		provider         *VaultProvider
+		secret           string
+		expected         string
+		expectedFound    bool
+		expectedRequests []string
+		errContains      string
+	}{
+		{
+			name:             "kv version 2 with token auth",
+			provider:         &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthToken}},
+			secret:           "API_KEY",
+			expected:         "v2-key",
+			expectedFound:    true,
+			expectedRequests: []string{"GET /v1/secret/data/genmcp"},
+		},
+		{
+			name:             "kv version 1",
+			provider:         &VaultProvider{MountPath: "kv", Path: "/genmcp/", KVVersion: 1, Auth: VaultAuth{Method: VaultAuthToken}},
+			secret:           "API_KEY",
+			expected:         "v1-key",
+			expectedFound:    true,
+			expectedRequests: []string{"GET /v1/kv/genmcp"},
+		},
+		{
+			name:             "non-string value",
+			provider:         &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthToken}},
+			secret:           "PORT",
+			expected:         "8080",
+			expectedFound:    true,
+			expectedRequests: []string{"GET /v1/secret/data/genmcp"},
+		},
+		{
+			name:             "missing key",
+			provider:         &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthToken}},
+			secret:           "MISSING",
+			expectedRequests: []string{"GET /v1/secret/data/genmcp"},
+		},
+		{
+			name:          "kubernetes auth",
+			provider:      &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthKubernetes, Role: "genmcp", JWTFile: jwtFile}},
+			secret:        "API_KEY",
+			expected:      "v2-key",
+			expectedFound: true,
+			expectedRequests: []string{
+				"POST /v1/auth/kubernetes/login",
+				"GET /v1/secret/data/genmcp",
+			},
+		},
+		{
+			name:          "approle auth",
+			provider:      &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthAppRole, RoleID: "role-id"}},
+			secret:        "API_KEY",
+			expected:      "v2-key",
+			expectedFound: true,
+			expectedRequests: []string{
+				"POST /v1/auth/approle/login",
+				"GET /v1/secret/data/genmcp",
+			},
+		},
+		{
+			name:        "missing secret",
+			provider:    &VaultProvider{MountPath: "secret", Path: "missing", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthToken}},
+			secret:      "API_KEY",
+			errContains: "failed to read vault secret 'secret/missing': not found (status 404)",
+		},
+		{
+			name:        "invalid token",
+			provider:    &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthToken, TokenFile: jwtFile}},
+			secret:      "API_KEY",
+			errContains: "failed to read vault secret 'secret/genmcp': permission denied (status 403)",
+		},
+		{
+			name:        "failed login",
+			provider:    &VaultProvider{MountPath: "secret", Path: "genmcp", KVVersion: 2, Auth: VaultAuth{Method: VaultAuthKubernetes, Role: "genmcp", JWTFile: filepath.Join(dir, "missing")}},
+			secret:      "API_KEY",
+			errContains: "failed to read service account token",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			vault := &fakeVault{}
+			server := httptest.NewServer(vault)
+			defer server.Close()
+			tc.provider.Address = server.URL
+
+			value, found, err := tc.provider.Lookup(tc.secret)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expected, value)
+			assert.Equal(t, tc.expectedRequests, vault.Requests())
+		})
+	}
+}
+
+func TestVaultProviderCaching(t *testing.T) {
+	t.Setenv("VAULT_SECRET_ID", "secret-id")
+
+	tt := []struct {
+		name          string
+		cacheTTL      time.Duration
+		expectedReads int
+	}{
+		{
+			name:          "cached",
+			cacheTTL:      time.Hour,
+			expectedReads: 1,
+		},
+		{
+			name:          "fetched once",
+			cacheTTL:      -1,
+			expectedReads: 1,
+		},
+		{
+			name:          "not cached",
+			cacheTTL:      0,
+			expectedReads: 3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			vault := &fakeVault{}
+			server := httptest.NewServer(vault)
+			defer server.Close()
+
+			provider := &VaultProvider{
+				Address:   server.URL,
+				Namespace: "team-a",
+				MountPath: "secret",
+				Path:      "genmcp",
+				KVVersion: 2,
+				Auth:      VaultAuth{Method: VaultAuthAppRole, RoleID: "role-id"},
+				CacheTTL:  tc.cacheTTL,
+			}
+
+			for range 3 {
+				value, found, err := provider.Lookup("API_KEY")
+				require.NoError(t, err)
+				assert.True(t, found)
+				assert.Equal(t, "v2-key", value)
+			}
+
+			reads := 0
+			for _, r := range vault.Requests() {
+				if r == "GET /v1/secret/data/genmcp" {
+					reads++
+				}
+			}
+			assert.Equal(t, tc.expectedReads, reads)
+			assert.Equal(t, "team-a", vault.namespace)
+		})
+	}
+}
+
+func TestVaultProviderRelogin(t *testing.T) {
+	t.Setenv("VAULT_SECRET_ID", "secret-id")
+
+	vault := &fakeVault{}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	provider := &VaultProvider{
+		Address:   server.URL,
+		MountPath: "secret",
+		Path:      "genmcp",
+		KVVersion: 2,
+		Auth:      VaultAuth{Method: VaultAuthAppRole, RoleID: "role-id"},
+	}
+	require.NoError(t, provider.Load())
+
+	vault.mu.Lock()
+	vault.revoked = true
+	vault.mu.Unlock()
+
+	value, found, err := provider.Lookup("API_KEY")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v2-key", value)
+	assert.Equal(t, []string{
+		"POST /v1/auth/approle/login",
+		"GET /v1/secret/data/genmcp",
+		"GET /v1/secret/data/genmcp",
+		"POST /v1/auth/approle/login",
+		"GET /v1/secret/data/genmcp",
+	}, vault.Requests())
+}
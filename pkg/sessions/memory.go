@@ -0,0 +1,109 @@
+package sessions
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore stores sessions in the memory of the server. Sessions are lost when the server stops and
+// are not shared between replicas.
+type MemoryStore struct {
+	TTL         time.Duration // how long a session is kept after it was last saved, forever if 0
+	MaxSessions int           // the least recently used session is evicted past this number, unlimited if 0
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List // of *memoryEntry, most recently used first
+}
+
+type memoryEntry struct {
+	id      string
+	session Session
+	expiry  time.Time // zero if the session does not expire
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore(ttl time.Duration, maxSessions int) *MemoryStore {
+	return &MemoryStore{TTL: ttl, MaxSessions: maxSessions}
+}
+
+func (s *MemoryStore) Load(_ context.Context, id string) (*Session, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[id]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiry.IsZero() && !time.Now().Before(entry.expiry) {
+		s.remove(elem)
+		return nil, false, nil
+	}
+
+	s.lru.MoveToFront(elem)
+	session := entry.session
+	return &session, true, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, id string, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]*list.Element)
+	}
+
+	var expiry time.Time
+	if s.TTL > 0 {
+		expiry = time.Now().Add(s.TTL)
+	}
+
+	if elem, ok := s.entries[id]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.session = *session
+		entry.expiry = expiry
+		s.lru.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[id] = s.lru.PushFront(&memoryEntry{id: id, session: *session, expiry: expiry})
+	s.evict()
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[id]; ok {
+		s.remove(elem)
+	}
+	return nil
+}
+
+// evict removes expired sessions, then the least recently used sessions past MaxSessions.
+func (s *MemoryStore) evict() {
+	now := time.Now()
+	for elem := s.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if expiry := elem.Value.(*memoryEntry).expiry; !expiry.IsZero() && !now.Before(expiry) {
+			s.remove(elem)
+		}
+		elem = prev
+	}
+
+	for s.MaxSessions > 0 && s.lru.Len() > s.MaxSessions {
+		s.remove(s.lru.Back())
+	}
+}
+
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryEntry).id)
+}
@@ -0,0 +1,101 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSession(client string) *Session {
+	return &Session{
+		InitializeParams: &mcp.InitializeParams{
+			ProtocolVersion: "2025-06-18",
+			ClientInfo:      &mcp.Implementation{Name: client, Version: "1.0.0"},
+		},
+		Subject: "alice",
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	tt := []struct {
+		name        string
+		ttl         time.Duration
+		maxSessions int
+		run         func(s *MemoryStore)
+		expected    []string // sessions expected in the store among a, b and c
+	}{
+		{
+			name: "saved sessions are loaded",
+			run: func(s *MemoryStore) {
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				_ = s.Save(context.Background(), "b", testSession("b"))
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "deleted sessions are removed",
+			run: func(s *MemoryStore) {
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				_ = s.Save(context.Background(), "b", testSession("b"))
+				_ = s.Delete(context.Background(), "a")
+			},
+			expected: []string{"b"},
+		},
+		{
+			name: "sessions expire",
+			ttl:  50 * time.Millisecond,
+			run: func(s *MemoryStore) {
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				_ = s.Save(context.Background(), "b", testSession("b"))
+				time.Sleep(100 * time.Millisecond)
+				_ = s.Save(context.Background(), "c", testSession("c"))
+			},
+			expected: []string{"c"},
+		},
+		{
+			name: "saving extends the expiry",
+			ttl:  100 * time.Millisecond,
+			run: func(s *MemoryStore) {
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				_ = s.Save(context.Background(), "b", testSession("b"))
+				time.Sleep(60 * time.Millisecond)
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				time.Sleep(60 * time.Millisecond)
+			},
+			expected: []string{"a"},
+		},
+		{
+			name:        "least recently used sessions are evicted",
+			maxSessions: 2,
+			run: func(s *MemoryStore) {
+				_ = s.Save(context.Background(), "a", testSession("a"))
+				_ = s.Save(context.Background(), "b", testSession("b"))
+				_, _, _ = s.Load(context.Background(), "a")
+				_ = s.Save(context.Background(), "c", testSession("c"))
+			},
+			expected: []string{"a", "c"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewMemoryStore(tc.ttl, tc.maxSessions)
+			tc.run(s)
+
+			var found []string
+			for _, id := range []string{"a", "b", "c"} {
+				session, ok, err := s.Load(context.Background(), id)
+				require.NoError(t, err)
+				if ok {
+					assert.Equal(t, testSession(id), session)
+					found = append(found, id)
+				}
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
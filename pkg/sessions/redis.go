@@ -0,0 +1,131 @@
+package sessions
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultRedisKeyPrefix is prepended to the IDs of sessions to build their Redis keys.
+	DefaultRedisKeyPrefix = "genmcp:session:"
+
+	redisTimeout      = 5 * time.Second
+	redisMaxIdleConns = 8
+)
+
+// RedisStore stores sessions in Redis, so that they are shared between the replicas of a server and
+// survive restarts. Sessions expire with the TTL of their key.
+type RedisStore struct {
+	Address   string      // host:port of the Redis server
+	Username  string      // ACL user name, if any
+	Password  string      // password, if any
+	DB        int         // index of the database, selected when connecting
+	KeyPrefix string      // prepended to the IDs of sessions
+	TLSConfig *tls.Config // connections use TLS if set
+	TTL       time.Duration
+
+	// Dial connects to the Redis server, e.g. with the dial function of the network policy of the server.
+	// Connections are dialed with a net.Dialer if nil.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	clientOnce sync.Once
+	client     *redis.Client
+}
+
+var _ Store = &RedisStore{}
+
+func (s *RedisStore) Load(ctx context.Context, id string) (*Session, bool, error) {
+	data, err := s.redisClient().Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, false, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	return &session, true, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, id string, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := s.redisClient().Set(ctx, s.key(id), data, s.TTL).Err(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if err := s.redisClient().Del(ctx, s.key(id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) key(id string) string {
+	if s.KeyPrefix == "" {
+		return DefaultRedisKeyPrefix + id
+	}
+	return s.KeyPrefix + id
+}
+
+// redisClient returns the client of the Redis server, created on first use. The client keeps a pool of
+// connections, which are authenticated and select the database when they are dialed.
+func (s *RedisStore) redisClient() *redis.Client {
+	s.clientOnce.Do(func() {
+		dial := s.Dial
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: redisTimeout}).DialContext
+		}
+		if s.TLSConfig != nil {
+			plain := dial
+			dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+				conn, err := plain(ctx, network, address)
+				if err != nil {
+					return nil, err
+				}
+				config := s.TLSConfig.Clone()
+				if config.ServerName == "" {
+					config.ServerName, _, _ = net.SplitHostPort(address)
+				}
+				tlsConn := tls.Client(conn, config)
+				if err := tlsConn.HandshakeContext(ctx); err != nil {
+					_ = conn.Close()
+					return nil, err
+				}
+				return tlsConn, nil
+			}
+		}
+
+		s.client = redis.NewClient(&redis.Options{
+			Addr:            s.Address,
+			Dialer:          dial,
+			Username:        s.Username,
+			Password:        s.Password,
+			DB:              s.DB,
+			Protocol:        2,
+			DisableIdentity: true,
+			DialTimeout:     redisTimeout,
+			ReadTimeout:     redisTimeout,
+			WriteTimeout:    redisTimeout,
+			MaxIdleConns:    redisMaxIdleConns,
+		})
+	})
+	return s.client
+}
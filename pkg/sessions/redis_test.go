@@ -0,0 +1,293 @@
+package sessions
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis serves the commands sent by RedisStore, requiring the password s3cr3t, or the user genmcp with
+// the password s3cr3t, when requirePass is set. Like Redis servers before 6, it doesn't know the HELLO
+// command, so that clients authenticate with AUTH.
+type fakeRedis struct {
+	requirePass bool
+
+	mu       sync.Mutex
+	data     map[string]string // by database and key
+	expiry   map[string]time.Time
+	commands []string
+}
+
+func (f *fakeRedis) start(t *testing.T) string {
+	t.Helper()
+
+	f.data = make(map[string]string)
+	f.expiry = make(map[string]time.Time)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	r := bufio.NewReader(conn)
+	authenticated := !f.requirePass
+	db := "0"
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.ToUpper(args[0]))
+		f.mu.Unlock()
+
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			if args[len(args)-1] != "s3cr3t" || (len(args) == 3 && args[1] != "genmcp") {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+				break
+			}
+			authenticated = true
+			reply = "+OK\r\n"
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			db = args[1]
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			reply = f.get(db + "/" + args[1])
+		case cmd == "SET":
+			reply = f.set(db+"/"+args[1], args[2], args[3:])
+		case cmd == "DEL":
+			f.mu.Lock()
+			_, ok := f.data[db+"/"+args[1]]
+			delete(f.data, db+"/"+args[1])
+			f.mu.Unlock()
+			reply = ":0\r\n"
+			if ok {
+				reply = ":1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) get(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.data[key]
+	if expiry, expires := f.expiry[key]; !ok || expires && !time.Now().Before(expiry) {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func (f *fakeRedis) set(key, value string, options []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.data[key] = value
+	delete(f.expiry, key)
+	if len(options) == 2 && strings.ToUpper(options[0]) == "PX" {
+		ms, err := strconv.Atoi(options[1])
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		f.expiry[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+	}
+	return "+OK\r\n"
+}
+
+func (f *fakeRedis) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.data))
+	for key := range f.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (f *fakeRedis) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...)
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	tt := []struct {
+		name             string
+		requirePass      bool
+		store            *RedisStore
+		expectedKeys     []string
+		expectedCommands []string
+		errContains      string
+	}{
+		{
+			name:             "no authentication",
+			store:            &RedisStore{TTL: time.Minute},
+			expectedKeys:     []string{"0/genmcp:session:a"},
+			expectedCommands: []string{"HELLO", "SET", "GET", "GET", "DEL", "GET"},
+		},
+		{
+			name:             "password",
+			requirePass:      true,
+			store:            &RedisStore{Password: "s3cr3t", TTL: time.Minute},
+			expectedKeys:     []string{"0/genmcp:session:a"},
+			expectedCommands: []string{"HELLO", "AUTH", "SET", "GET", "GET", "DEL", "GET"},
+		},
+		{
+			name:             "user, database and key prefix",
+			requirePass:      true,
+			store:            &RedisStore{Username: "genmcp", Password: "s3cr3t", DB: 2, KeyPrefix: "mcp:"},
+			expectedKeys:     []string{"2/mcp:a"},
+			expectedCommands: []string{"HELLO", "AUTH", "SELECT", "SET", "GET", "GET", "DEL", "GET"},
+		},
+		{
+			name:        "wrong password",
+			requirePass: true,
+			store:       &RedisStore{Password: "wrong"},
+			errContains: "failed to save session: WRONGPASS invalid username-password pair",
+		},
+		{
+			name:        "missing password",
+			requirePass: true,
+			store:       &RedisStore{},
+			errContains: "NOAUTH Authentication required.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			redis := &fakeRedis{requirePass: tc.requirePass}
+			store := tc.store
+			store.Address = redis.start(t)
+			ctx := context.Background()
+
+			err := store.Save(ctx, "a", testSession("a"))
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedKeys, redis.keys())
+
+			session, ok, err := store.Load(ctx, "a")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, testSession("a"), session)
+
+			_, ok, err = store.Load(ctx, "b")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, store.Delete(ctx, "a"))
+			_, ok, err = store.Load(ctx, "a")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.Equal(t, tc.expectedCommands, redis.Commands(), "the connection should be reused")
+		})
+	}
+}
+
+func TestRedisStoreExpiry(t *testing.T) {
+	redis := &fakeRedis{}
+	store := &RedisStore{Address: redis.start(t), TTL: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, "a", testSession("a")))
+	_, ok, err := store.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	time.Sleep(100 * time.Millisecond)
+	_, ok, err = store.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok, "the session should expire with its key")
+}
+
+func TestRedisStoreDial(t *testing.T) {
+	redis := &fakeRedis{}
+	address := redis.start(t)
+
+	var dialed []string
+	store := &RedisStore{
+		Address: address,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed = append(dialed, address)
+			return nil, errors.New("destination denied by the network policy")
+		},
+	}
+
+	err := store.Save(context.Background(), "a", testSession("a"))
+	assert.ErrorContains(t, err, "destination denied by the network policy")
+	assert.Contains(t, dialed, address, "the store should connect with its dial function")
+	assert.Empty(t, redis.keys())
+}
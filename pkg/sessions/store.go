@@ -0,0 +1,30 @@
+package sessions
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Session is the state of a session of a stateful server needed to resume it, on another replica of the
+// server or after the replica serving it closed it.
+type Session struct {
+	// Parameters of the initialize request of the client, including the negotiated protocol version.
+	InitializeParams *mcp.InitializeParams `json:"initializeParams"`
+
+	// Subject of the OAuth token the session was created with, if any. Only requests with a token of the
+	// same subject can use the session.
+	Subject string `json:"subject,omitempty"`
+}
+
+// Store stores sessions by ID. A session expires when it is not saved again within the TTL of the store.
+type Store interface {
+	// Load returns the session id, or false if it does not exist or expired.
+	Load(ctx context.Context, id string) (*Session, bool, error)
+
+	// Save stores the session id, extending its expiry.
+	Save(ctx context.Context, id string, session *Session) error
+
+	// Delete removes the session id, if it exists.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,216 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithms of the HMAC signatures of requests.
+const (
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+)
+
+// Encodings of the HMAC signatures of requests.
+const (
+	EncodingHex    = "hex"
+	EncodingBase64 = "base64"
+)
+
+const (
+	// DefaultSignatureHeader is the default header of the signature of a request.
+	DefaultSignatureHeader = "X-Genmcp-Signature"
+
+	// DefaultTimestampHeader is the default header of the time a request was signed at, in Unix seconds.
+	DefaultTimestampHeader = "X-Genmcp-Timestamp"
+
+	// DefaultMaxSkew is the default maximum difference between the time a request was signed at and the
+	// time it is verified at.
+	DefaultMaxSkew = 5 * time.Minute
+
+	// DefaultMaxBodyBytes is the default maximum size of the body of a request verified by Middleware.
+	DefaultMaxBodyBytes = 10 << 20
+)
+
+var (
+	// ErrMissingSignature is returned when verifying a request without a signature or timestamp.
+	ErrMissingSignature = errors.New("missing request signature")
+
+	// ErrInvalidSignature is returned when verifying a request whose signature doesn't match.
+	ErrInvalidSignature = errors.New("invalid request signature")
+
+	// ErrExpiredSignature is returned when verifying a request signed too long ago, or in the future.
+	ErrExpiredSignature = errors.New("request signature expired")
+)
+
+// Config defines how requests are signed and verified.
+type Config struct {
+	// Secret shared by the sender and the receiver of the requests.
+	Secret string
+
+	// Header of the signature (default: X-Genmcp-Signature).
+	SignatureHeader string
+
+	// Header of the time the request was signed at, in Unix seconds (default: X-Genmcp-Timestamp).
+	TimestampHeader string
+
+	// Hash function of the signature: sha256 or sha512 (default: sha256).
+	Algorithm string
+
+	// Encoding of the signature: hex or base64 (default: hex).
+	Encoding string
+
+	// Maximum difference between the time a request was signed at and the time it is verified at, which
+	// limits how long a captured request can be replayed (default: 5m).
+	MaxSkew time.Duration
+
+	// Maximum size in bytes of the body of a request verified by Middleware (default: 10 MiB).
+	MaxBodyBytes int64
+}
+
+// Signer signs requests, and verifies their signatures, with an HMAC of their timestamp, method, path, query
+// and body. The signed message is the timestamp, the method, the path and query of the URL and the body, each
+// followed by a newline but the body:
+//
+//	1767225600\nPOST\n/api/orders?dryRun=true\n{"item":"book"}
+//
+// The signature is sent as <algorithm>=<signature>, e.g. sha256=9f86d0...
+type Signer struct {
+	config Config
+	hash   func() hash.Hash
+	now    func() time.Time
+}
+
+// NewSigner creates a signer, applying the defaults of config.
+func NewSigner(config Config) (*Signer, error) {
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultSignatureHeader
+	}
+	if config.TimestampHeader == "" {
+		config.TimestampHeader = DefaultTimestampHeader
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmSHA256
+	}
+	if config.Encoding == "" {
+		config.Encoding = EncodingHex
+	}
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = DefaultMaxSkew
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	s := &Signer{config: config, now: time.Now}
+	switch config.Algorithm {
+	case AlgorithmSHA256:
+		s.hash = sha256.New
+	case AlgorithmSHA512:
+		s.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm '%s'", config.Algorithm)
+	}
+	if config.Encoding != EncodingHex && config.Encoding != EncodingBase64 {
+		return nil, fmt.Errorf("unsupported signature encoding '%s'", config.Encoding)
+	}
+
+	return s, nil
+}
+
+// Sign sets the timestamp and signature headers of header for a request of method to url with body.
+func (s *Signer) Sign(header http.Header, method, url string, body []byte) error {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	header.Set(s.config.TimestampHeader, timestamp)
+	header.Set(s.config.SignatureHeader, s.config.Algorithm+"="+s.encode(s.mac(timestamp, method, u.RequestURI(), body)))
+	return nil
+}
+
+// Verify checks that req with body was signed with the secret of the signer less than the maximum skew ago.
+// The body of req is not read: it must be passed as body.
+func (s *Signer) Verify(req *http.Request, body []byte) error {
+	timestamp := req.Header.Get(s.config.TimestampHeader)
+	signature := strings.TrimPrefix(strings.TrimSpace(req.Header.Get(s.config.SignatureHeader)), s.config.Algorithm+"=")
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := s.now().Sub(time.Unix(signedAt, 0)); skew > s.config.MaxSkew || skew < -s.config.MaxSkew {
+		return ErrExpiredSignature
+	}
+
+	received, err := s.decode(signature)
+	if err != nil || !hmac.Equal(received, s.mac(timestamp, req.Method, req.URL.RequestURI(), body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Middleware returns a handler verifying the signature of the requests before passing them to next, with
+// their body restored. Requests that aren't signed, or whose signature doesn't match or expired, are
+// rejected with 401 Unauthorized, and requests larger than the maximum body size with 413 Payload Too Large.
+func (s *Signer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, s.config.MaxBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.Verify(req, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (s *Signer) mac(timestamp, method, requestURI string, body []byte) []byte {
+	mac := hmac.New(s.hash, []byte(s.config.Secret))
+	mac.Write([]byte(timestamp + "\n" + strings.ToUpper(method) + "\n" + requestURI + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func (s *Signer) encode(sum []byte) string {
+	if s.config.Encoding == EncodingBase64 {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
+
+func (s *Signer) decode(signature string) ([]byte, error) {
+	if s.config.Encoding == EncodingBase64 {
+		return base64.StdEncoding.DecodeString(signature)
+	}
+	return hex.DecodeString(signature)
+}
@@ -0,0 +1,243 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerSign(t *testing.T) {
+	body := `{"item":"book"}`
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("1767225600\nPOST\n/api/orders?dryRun=true\n" + body))
+
+	s, err := NewSigner(Config{Secret: "s3cret"})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Unix(1767225600, 0) }
+
+	header := http.Header{}
+	require.NoError(t, s.Sign(header, "post", "https://api.example.com/api/orders?dryRun=true", []byte(body)))
+	assert.Equal(t, "1767225600", header.Get(DefaultTimestampHeader))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), header.Get(DefaultSignatureHeader))
+}
+
+func TestSignerVerify(t *testing.T) {
+	const body = `{"item":"book"}`
+	signedAt := time.Unix(1767225600, 0)
+
+	tt := []struct {
+		name          string
+		config        Config
+		verifyConfig  Config
+		method        string
+		target        string
+		body          string
+		verifiedAt    time.Time
+		modify        func(req *http.Request)
+		expectedError error
+	}{
+		{
+			name:   "signed request",
+			config: Config{Secret: "s3cret"},
+		},
+		{
+			name:   "sha512 base64 signature in custom headers",
+			config: Config{Secret: "s3cret", SignatureHeader: "X-Signature", TimestampHeader: "X-Signed-At", Algorithm: AlgorithmSHA512, Encoding: EncodingBase64},
+		},
+		{
+			name:       "within the maximum skew",
+			config:     Config{Secret: "s3cret"},
+			verifiedAt: signedAt.Add(4 * time.Minute),
+		},
+		{
+			name:          "signed too long ago",
+			config:        Config{Secret: "s3cret"},
+			verifiedAt:    signedAt.Add(6 * time.Minute),
+			expectedError: ErrExpiredSignature,
+		},
+		{
+			name:          "signed in the future",
+			config:        Config{Secret: "s3cret", MaxSkew: time.Minute},
+			verifiedAt:    signedAt.Add(-2 * time.Minute),
+			expectedError: ErrExpiredSignature,
+		},
+		{
+			name:          "signed with another secret",
+			config:        Config{Secret: "other"},
+			verifyConfig:  Config{Secret: "s3cret"},
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered body",
+			config:        Config{Secret: "s3cret"},
+			body:          `{"item":"car"}`,
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered method",
+			config:        Config{Secret: "s3cret"},
+			method:        http.MethodPut,
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered query",
+			config:        Config{Secret: "s3cret"},
+			target:        "/api/orders?dryRun=false",
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered timestamp",
+			config:        Config{Secret: "s3cret"},
+			modify:        func(req *http.Request) { req.Header.Set(DefaultTimestampHeader, "1767225601") },
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "missing signature",
+			config:        Config{Secret: "s3cret"},
+			modify:        func(req *http.Request) { req.Header.Del(DefaultSignatureHeader) },
+			expectedError: ErrMissingSignature,
+		},
+		{
+			name:          "malformed signature",
+			config:        Config{Secret: "s3cret"},
+			modify:        func(req *http.Request) { req.Header.Set(DefaultSignatureHeader, "sha256=not-hex") },
+			expectedError: ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := NewSigner(tc.config)
+			require.NoError(t, err)
+			signer.now = func() time.Time { return signedAt }
+
+			header := http.Header{}
+			require.NoError(t, signer.Sign(header, http.MethodPost, "https://api.example.com/api/orders?dryRun=true", []byte(body)))
+
+			method, target, received := http.MethodPost, "/api/orders?dryRun=true", body
+			if tc.method != "" {
+				method = tc.method
+			}
+			if tc.target != "" {
+				target = tc.target
+			}
+			if tc.body != "" {
+				received = tc.body
+			}
+			req := httptest.NewRequest(method, target, strings.NewReader(received))
+			req.Header = header
+			if tc.modify != nil {
+				tc.modify(req)
+			}
+
+			verifyConfig := tc.config
+			if tc.verifyConfig.Secret != "" {
+				verifyConfig = tc.verifyConfig
+			}
+			verifier, err := NewSigner(verifyConfig)
+			require.NoError(t, err)
+			verifier.now = func() time.Time { return signedAt }
+			if !tc.verifiedAt.IsZero() {
+				verifier.now = func() time.Time { return tc.verifiedAt }
+			}
+
+			assert.ErrorIs(t, verifier.Verify(req, []byte(received)), tc.expectedError)
+		})
+	}
+}
+
+func TestSignerMiddleware(t *testing.T) {
+	const body = `{"item":"book"}`
+
+	tt := []struct {
+		name           string
+		sign           bool
+		config         Config
+		expectedStatus int
+	}{
+		{
+			name:           "signed request",
+			sign:           true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unsigned request",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "body too large",
+			sign:           true,
+			config:         Config{MaxBodyBytes: 8},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Secret = "s3cret"
+			s, err := NewSigner(tc.config)
+			require.NoError(t, err)
+
+			var received string
+			handler := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, _ := io.ReadAll(req.Body)
+				received = string(b)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(body))
+			if tc.sign {
+				require.NoError(t, s.Sign(req.Header, req.Method, req.URL.String(), []byte(body)))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, body, received)
+			}
+		})
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{
+			name:   "defaults",
+			config: Config{Secret: "s3cret"},
+		},
+		{
+			name:          "unsupported algorithm",
+			config:        Config{Algorithm: "sha1"},
+			expectedError: "unsupported signature algorithm 'sha1'",
+		},
+		{
+			name:          "unsupported encoding",
+			config:        Config{Encoding: "base32"},
+			expectedError: "unsupported signature encoding 'base32'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSigner(tc.config)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package spool
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithSpoolMiddleware creates an MCP middleware that injects s into the context of tool calls, so that their
+// large responses are spooled to it. Prompts and resources are not spooled, as their content is returned inline.
+func WithSpoolMiddleware(s *Spool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+			if method == "tools/call" {
+				ctx = WithSpool(ctx, s)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
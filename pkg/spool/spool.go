@@ -0,0 +1,347 @@
+package spool
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultThresholdBytes is the default size above which responses are spooled.
+	DefaultThresholdBytes = 10 << 20
+
+	// DefaultMaxChunkBytes is the default maximum size of a ranged read.
+	DefaultMaxChunkBytes = 1 << 20
+
+	// DefaultTTL is the default time spooled responses are kept.
+	DefaultTTL = time.Hour
+
+	// URIScheme is the scheme of the URIs of the spooled responses.
+	URIScheme = "spool"
+)
+
+// ErrFull is returned when spooling a response would exceed the maximum total size of the spool.
+var ErrFull = errors.New("spool is full")
+
+// ErrNotFound is returned when reading a response that was never spooled or that expired.
+var ErrNotFound = errors.New("spooled response not found")
+
+// Config defines where responses are spooled, from which size, and for how long.
+type Config struct {
+	// Directory of the spooled responses. A new directory of the temporary directory is used if empty.
+	Dir string
+
+	// Size in bytes above which responses are spooled (default: 10 MiB).
+	ThresholdBytes int64
+
+	// Maximum size in bytes of a ranged read (default: 1 MiB).
+	MaxChunkBytes int
+
+	// Time a response is kept after it was spooled (default: 1h).
+	TTL time.Duration
+
+	// Maximum size in bytes of all the responses kept. Unlimited if 0.
+	MaxTotalBytes int64
+}
+
+// File is a response spooled to disk.
+type File struct {
+	// ID of the file, random so that it cannot be guessed by other clients.
+	ID string `json:"id"`
+
+	// MIME type of the response, if known.
+	MIMEType string `json:"mimeType,omitempty"`
+
+	// Size of the response in bytes.
+	Size int64 `json:"size"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	path string
+}
+
+// URI returns the URI of the resource serving the file.
+func (f *File) URI() string {
+	return URIScheme + "://" + f.ID
+}
+
+// ResourceLink returns a link to the resource serving the file, describing how to read it in chunks.
+func (f *File) ResourceLink() *mcp.ResourceLink {
+	size := f.Size
+	return &mcp.ResourceLink{
+		URI:   f.URI(),
+		Name:  f.ID,
+		Title: "Spooled response",
+		Description: fmt.Sprintf("Response of %d bytes, too large to be returned inline. Read it in chunks with "+
+			"%s?offset=<offset>&length=<length>, from offset 0 then from the nextOffset of the _meta of the "+
+			"previous chunk. It expires at %s.", f.Size, f.URI(), f.ExpiresAt.UTC().Format(time.RFC3339)),
+		MIMEType: f.MIMEType,
+		Size:     &size,
+	}
+}
+
+// Text reports whether the file holds text, from its MIME type.
+func (f *File) Text() bool {
+	mediaType, _, err := mime.ParseMediaType(f.MIMEType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, s := range []string{"json", "xml", "yaml", "javascript", "csv"} {
+		if strings.Contains(mediaType, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Chunk is a range of bytes read from a spooled file.
+type Chunk struct {
+	Data []byte
+
+	// Offset of the first byte of Data in the file.
+	Offset int64
+
+	// Offset to read the next chunk from, equal to the size of the file after the last chunk.
+	NextOffset int64
+}
+
+// Spool keeps large responses on disk for a limited time, so that they can be read in chunks rather than held
+// in memory.
+type Spool struct {
+	config Config
+
+	mu    sync.Mutex
+	files map[string]*File
+	total int64
+}
+
+// New creates an empty spool, applying the defaults of config and creating its directory.
+func New(config Config) (*Spool, error) {
+	if config.ThresholdBytes <= 0 {
+		config.ThresholdBytes = DefaultThresholdBytes
+	}
+	if config.MaxChunkBytes <= 0 {
+		config.MaxChunkBytes = DefaultMaxChunkBytes
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+
+	if config.Dir == "" {
+		dir, err := os.MkdirTemp("", "genmcp-spool-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create spool directory: %w", err)
+		}
+		config.Dir = dir
+	} else if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	return &Spool{config: config, files: make(map[string]*File)}, nil
+}
+
+// ThresholdBytes returns the size in bytes above which responses are spooled.
+func (s *Spool) ThresholdBytes() int64 {
+	return s.config.ThresholdBytes
+}
+
+// Store writes r to a new file of the spool, which is removed once it expires. Writing fails with ErrFull if
+// the spool would exceed its maximum total size. r is read as fast as the disk accepts its data, so only a
+// buffer of it is held in memory.
+func (s *Spool) Store(r io.Reader, mimeType string) (*File, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.config.Dir, id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spooled file: %w", err)
+	}
+
+	size, err := io.Copy(&reservingWriter{w: f, spool: s}, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.release(size)
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+
+	now := time.Now()
+	file := &File{
+		ID:        id,
+		MIMEType:  mimeType,
+		Size:      size,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.TTL),
+		path:      f.Name(),
+	}
+
+	s.mu.Lock()
+	s.files[id] = file
+	s.mu.Unlock()
+	time.AfterFunc(s.config.TTL, func() { s.Remove(id) })
+
+	return file, nil
+}
+
+// Get returns the file of the spool with the given ID.
+func (s *Spool) Get(id string) (*File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return file, nil
+}
+
+// ReadChunk reads at most length bytes of the file with the given ID from offset, and at most the maximum
+// chunk size of the spool if length is 0. The chunks of text files start and end at UTF-8 character
+// boundaries, so that each is valid text.
+func (s *Spool) ReadChunk(id string, offset int64, length int) (*File, *Chunk, error) {
+	file, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offset < 0 || offset > file.Size {
+		return nil, nil, fmt.Errorf("offset %d is out of the %d bytes of the response", offset, file.Size)
+	}
+	if length <= 0 || length > s.config.MaxChunkBytes {
+		length = s.config.MaxChunkBytes
+	}
+	// a character is at most 4 bytes, read enough to find the boundaries around the range
+	text := file.Text()
+	lookahead := 0
+	if text {
+		lookahead = utf8.UTFMax - 1
+	}
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open spooled file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	data := make([]byte, min(int64(length+lookahead), file.Size-offset))
+	n, err := f.ReadAt(data, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("failed to read spooled file: %w", err)
+	}
+	data = data[:n]
+
+	start, end := 0, min(length, len(data))
+	if text {
+		for start < end && !utf8.RuneStart(data[start]) {
+			start++
+		}
+		for end > start && end < len(data) && !utf8.RuneStart(data[end]) {
+			end--
+		}
+		// a chunk smaller than a character is extended to hold it
+		if end <= start && start < len(data) {
+			end = start + 1
+			for end < len(data) && !utf8.RuneStart(data[end]) {
+				end++
+			}
+		}
+	}
+
+	return file, &Chunk{
+		Data:       data[start:end],
+		Offset:     offset + int64(start),
+		NextOffset: offset + int64(end),
+	}, nil
+}
+
+// Remove deletes the file of the spool with the given ID, before it expires.
+func (s *Spool) Remove(id string) {
+	s.mu.Lock()
+	file, ok := s.files[id]
+	if ok {
+		delete(s.files, id)
+		s.total -= file.Size
+	}
+	s.mu.Unlock()
+
+	if ok {
+		_ = os.Remove(file.path)
+	}
+}
+
+// reserve accounts for n more bytes written to the spool, failing with ErrFull past its maximum total size.
+func (s *Spool) reserve(n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config.MaxTotalBytes > 0 && s.total+n > s.config.MaxTotalBytes {
+		return ErrFull
+	}
+	s.total += n
+	return nil
+}
+
+func (s *Spool) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total -= n
+}
+
+// reservingWriter writes to w the bytes it reserves in spool.
+type reservingWriter struct {
+	w     io.Writer
+	spool *Spool
+}
+
+func (rw *reservingWriter) Write(p []byte) (int, error) {
+	if err := rw.spool.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := rw.w.Write(p)
+	rw.spool.release(int64(len(p) - n))
+	return n, err
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate spooled file ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type ctxKey struct{}
+
+// WithSpool stores a spool in the given context, so that invokers spool the large responses of their calls.
+func WithSpool(ctx context.Context, s *Spool) context.Context {
+	return context.WithValue(ctx, ctxKey{}, s)
+}
+
+// FromContext retrieves the spool stored in the context by WithSpool, or nil if there is none.
+func FromContext(ctx context.Context) *Spool {
+	s, _ := ctx.Value(ctxKey{}).(*Spool)
+	return s
+}
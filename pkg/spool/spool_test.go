@@ -0,0 +1,188 @@
+package spool
+
+import (
+	"cmp"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpoolStore(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        Config
+		contents      []string
+		expectedError error
+	}{
+		{
+			name:     "stored responses",
+			contents: []string{"first response", "second response"},
+		},
+		{
+			name:     "within the maximum total size",
+			config:   Config{MaxTotalBytes: 28},
+			contents: []string{"first response", "second resp"},
+		},
+		{
+			name:          "exceeding the maximum total size",
+			config:        Config{MaxTotalBytes: 20},
+			contents:      []string{"first response", "second response"},
+			expectedError: ErrFull,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Dir = t.TempDir()
+			s, err := New(tc.config)
+			require.NoError(t, err)
+
+			var lastErr error
+			for _, content := range tc.contents {
+				file, err := s.Store(strings.NewReader(content), "text/plain")
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				assert.Equal(t, int64(len(content)), file.Size)
+				assert.Equal(t, "spool://"+file.ID, file.URI())
+				data, err := os.ReadFile(file.path)
+				require.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+
+			entries, err := os.ReadDir(tc.config.Dir)
+			require.NoError(t, err)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, lastErr, tc.expectedError)
+				assert.Len(t, entries, len(tc.contents)-1, "the file of the failed response should be removed")
+				return
+			}
+			assert.NoError(t, lastErr)
+			assert.Len(t, entries, len(tc.contents))
+		})
+	}
+}
+
+func TestSpoolReadChunk(t *testing.T) {
+	// "é" is 2 bytes and "€" is 3 bytes
+	const text = "café €10 café"
+
+	tt := []struct {
+		name               string
+		mimeType           string
+		maxChunkBytes      int
+		offset             int64
+		length             int
+		expectedData       string
+		expectedOffset     int64
+		expectedNextOffset int64
+		expectedError      string
+	}{
+		{
+			name:               "whole response",
+			mimeType:           "text/plain; charset=utf-8",
+			maxChunkBytes:      DefaultMaxChunkBytes,
+			expectedData:       text,
+			expectedNextOffset: int64(len(text)),
+		},
+		{
+			name:               "first chunk",
+			mimeType:           "text/plain",
+			length:             4,
+			expectedData:       "caf",
+			expectedNextOffset: 3,
+		},
+		{
+			name:               "chunk starting inside a character",
+			mimeType:           "application/json",
+			offset:             4,
+			length:             5,
+			expectedData:       " €",
+			expectedOffset:     5,
+			expectedNextOffset: 9,
+		},
+		{
+			name:               "chunk smaller than a character",
+			mimeType:           "text/plain",
+			offset:             6,
+			length:             1,
+			expectedData:       "€",
+			expectedOffset:     6,
+			expectedNextOffset: 9,
+		},
+		{
+			name:               "binary chunk",
+			mimeType:           "application/octet-stream",
+			length:             4,
+			expectedData:       "caf\xc3",
+			expectedNextOffset: 4,
+		},
+		{
+			name:               "chunk capped by the maximum chunk size",
+			mimeType:           "text/plain",
+			length:             100,
+			expectedData:       "café €10 ",
+			expectedNextOffset: 12,
+		},
+		{
+			name:               "end of the response",
+			mimeType:           "text/plain",
+			offset:             int64(len(text)),
+			expectedOffset:     int64(len(text)),
+			expectedNextOffset: int64(len(text)),
+		},
+		{
+			name:          "offset after the end",
+			mimeType:      "text/plain",
+			offset:        100,
+			expectedError: "offset 100 is out of the 17 bytes of the response",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(Config{Dir: t.TempDir(), MaxChunkBytes: cmp.Or(tc.maxChunkBytes, 12)})
+			require.NoError(t, err)
+			file, err := s.Store(strings.NewReader(text), tc.mimeType)
+			require.NoError(t, err)
+
+			_, chunk, err := s.ReadChunk(file.ID, tc.offset, tc.length)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, string(chunk.Data))
+			assert.Equal(t, tc.expectedOffset, chunk.Offset)
+			assert.Equal(t, tc.expectedNextOffset, chunk.NextOffset)
+		})
+	}
+}
+
+func TestSpoolExpiry(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(Config{Dir: dir, TTL: 50 * time.Millisecond, MaxTotalBytes: 8})
+	require.NoError(t, err)
+
+	file, err := s.Store(strings.NewReader("response"), "text/plain")
+	require.NoError(t, err)
+	_, err = s.Get(file.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := s.Get(file.ID)
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, _, err = s.ReadChunk(file.ID, 0, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoFileExists(t, file.path)
+
+	// the space of the expired response is available again
+	_, err = s.Store(strings.NewReader("response"), "text/plain")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package template
+
+import (
+	"net/textproto"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+)
+
+// Allowlist restricts the headers of the incoming request and the environment variables the templates of a
+// server can reference, as set by the security config of the server. A nil Allowlist allows any.
+type Allowlist struct {
+	// headers holds the canonical names of the headers templates can reference, nil if any can be
+	headers map[string]struct{}
+	// env holds the environment variables all templates can reference, nil if any can be
+	env map[string]struct{}
+	// toolEnv holds the environment variables the templates of each tool can also reference
+	toolEnv map[string]map[string]struct{}
+}
+
+// NewAllowlist returns an allowlist of the headers, matched case-insensitively, and of the environment
+// variables env, and of the entry of toolEnv for the tool a template is parsed for. Any header can be
+// referenced if headers is nil, and any environment variable if both env and toolEnv are nil.
+func NewAllowlist(headers, env []string, toolEnv map[string][]string) *Allowlist {
+	a := &Allowlist{}
+
+	if headers != nil {
+		a.headers = make(map[string]struct{}, len(headers))
+		for _, h := range headers {
+			a.headers[textproto.CanonicalMIMEHeaderKey(h)] = struct{}{}
+		}
+	}
+
+	if env != nil || toolEnv != nil {
+		a.env = make(map[string]struct{}, len(env))
+		for _, name := range env {
+			a.env[name] = struct{}{}
+		}
+
+		a.toolEnv = make(map[string]map[string]struct{}, len(toolEnv))
+		for tool, names := range toolEnv {
+			a.toolEnv[tool] = make(map[string]struct{}, len(names))
+			for _, name := range names {
+				a.toolEnv[tool][name] = struct{}{}
+			}
+		}
+	}
+
+	return a
+}
+
+// PrimitiveAllowlist returns the allowlist of the templates of the invocation of primitive, set when the
+// server serving it is validated, or nil if it has none.
+func PrimitiveAllowlist(primitive invocation.Primitive) *Allowlist {
+	if p, ok := primitive.(interface{ GetTemplateAllowlist() *Allowlist }); ok {
+		return p.GetTemplateAllowlist()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type claimsKey struct{}
+
+// ClaimsResolver resolves {claims.name} references from the claims of the credentials of the caller, such as
+// sub, email or a custom tenant claim. Nested claims are referenced with dots, e.g. {claims.org.id}.
+type ClaimsResolver struct {
+	claims map[string]any
+}
+
+// WithClaims stores the claims of the credentials of the caller in the given context.
+func WithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, claimsKey{}, &ClaimsResolver{claims: claims})
+}
+
+// ClaimsFromContext returns a resolver of the claims stored in the context by WithClaims. If no claims are
+// found, as for unauthenticated calls, it returns a resolver that fails to resolve any claim.
+func ClaimsFromContext(ctx context.Context) *ClaimsResolver {
+	if r, ok := ctx.Value(claimsKey{}).(*ClaimsResolver); ok && r != nil {
+		return r
+	}
+	return &ClaimsResolver{}
+}
+
+func (r *ClaimsResolver) Resolve(fieldName string) (string, error) {
+	var value any = r.claims
+	for name := range strings.SplitSeq(fieldName, ".") {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("claim '%s' not found", fieldName)
+		}
+		if value, ok = fields[name]; !ok || value == nil {
+			return "", fmt.Errorf("claim '%s' not found", fieldName)
+		}
+	}
+
+	if items, ok := value.([]any); ok {
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = claimString(item)
+		}
+		return strings.Join(values, ","), nil
+	}
+	if items, ok := value.([]string); ok {
+		return strings.Join(items, ","), nil
+	}
+	return claimString(value), nil
+}
+
+// claimString formats the value of a claim: numbers without exponent, and objects as JSON.
+func claimString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
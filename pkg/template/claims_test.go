@@ -0,0 +1,56 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsResolver(t *testing.T) {
+	claims := map[string]any{
+		"sub":       "alice",
+		"aud":       []string{"genmcp", "api"},
+		"tenant_id": float64(1000000),
+		"verified":  true,
+		"groups":    []any{"admins", "devs"},
+		"org":       map[string]any{"id": "acme", "region": "eu"},
+	}
+
+	tt := []struct {
+		name          string
+		claims        map[string]any
+		field         string
+		expected      string
+		expectedError string
+	}{
+		{name: "string claim", claims: claims, field: "sub", expected: "alice"},
+		{name: "number claim", claims: claims, field: "tenant_id", expected: "1000000"},
+		{name: "boolean claim", claims: claims, field: "verified", expected: "true"},
+		{name: "array claim", claims: claims, field: "groups", expected: "admins,devs"},
+		{name: "audience", claims: claims, field: "aud", expected: "genmcp,api"},
+		{name: "nested claim", claims: claims, field: "org.id", expected: "acme"},
+		{name: "object claim", claims: claims, field: "org", expected: `{"id":"acme","region":"eu"}`},
+		{name: "missing claim", claims: claims, field: "email", expectedError: "claim 'email' not found"},
+		{name: "missing nested claim", claims: claims, field: "sub.id", expectedError: "claim 'sub.id' not found"},
+		{name: "unauthenticated call", field: "sub", expectedError: "claim 'sub' not found"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.claims != nil {
+				ctx = WithClaims(ctx, tc.claims)
+			}
+
+			value, err := ClaimsFromContext(ctx).Resolve(tc.field)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
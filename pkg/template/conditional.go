@@ -0,0 +1,124 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+)
+
+// parseConditional parses the conditional block starting at pos, {?name}...{/name} or {^name}...{/name}
+// for an inverted block, and returns its variable and the position following its closing tag.
+func parseConditional(template string, pos, paramIdx int, opts TemplateParserOptions) (*Variable, int, error) {
+	offset := strings.Index(template[pos:], "}")
+	if offset == -1 {
+		return nil, 0, fmt.Errorf("unterminated conditional block at position %d", pos)
+	}
+	offset += pos
+
+	inverted := template[pos+1] == '^'
+	name := template[pos+2 : offset]
+	if name == "" {
+		return nil, 0, fmt.Errorf("conditional block name cannot be empty at position %d", pos)
+	}
+	if _, err := utils.FormatStringForParam(name, opts.InputSchema); err != nil {
+		return nil, 0, fmt.Errorf("invalid conditional block '%s': %w", name, err)
+	}
+
+	closingTag := "{/" + name + "}"
+	end := strings.Index(template[offset+1:], closingTag)
+	if end == -1 {
+		return nil, 0, fmt.Errorf("conditional block '%s' at position %d is not closed with %s", name, pos, closingTag)
+	}
+	end += offset + 1
+
+	content, err := ParseTemplate(template[offset+1:end], opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid content of conditional block '%s': %w", name, err)
+	}
+	builder, err := NewTemplateBuilder(content, false)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid content of conditional block '%s': %w", name, err)
+	}
+
+	return &Variable{
+		Name:  name,
+		Type:  VariableTypeConditional,
+		Index: paramIdx,
+		VariableFormatter: &conditionalFormatter{
+			paramName: name,
+			inverted:  inverted,
+			content:   builder,
+		},
+	}, end + len(closingTag), nil
+}
+
+// conditionalFormatter formats the content of a conditional block if its parameter is truthy, or if it
+// isn't for an inverted block.
+type conditionalFormatter struct {
+	paramName string
+	inverted  bool
+	content   *TemplateBuilder
+	value     any
+}
+
+func (f *conditionalFormatter) SetField(path string, value any) {
+	if path == f.paramName {
+		f.value = value
+	} else if array, index, ok := arrayElement(path); ok && array == f.paramName {
+		f.value = setElement(f.value, index, value)
+	}
+	f.content.SetField(path, value)
+}
+
+func (f *conditionalFormatter) GetResult() (any, error) {
+	if truthy(f.value) == f.inverted {
+		return "", nil
+	}
+	return f.content.GetResult()
+}
+
+func (f *conditionalFormatter) FormatString() string {
+	return "%s"
+}
+
+func (f *conditionalFormatter) VariableNames() []string {
+	names := f.content.VariableNames()
+	for _, name := range names {
+		if name == f.paramName {
+			return names
+		}
+	}
+	return append(names, f.paramName)
+}
+
+func (f *conditionalFormatter) SetSourceResolver(sourceName string, resolver SourceResolver) {
+	f.content.SetSourceResolver(sourceName, resolver)
+}
+
+func (f *conditionalFormatter) clone() VariableFormatter {
+	return &conditionalFormatter{
+		paramName: f.paramName,
+		inverted:  f.inverted,
+		content:   f.content.clone().(*TemplateBuilder),
+	}
+}
+
+// truthy reports whether the value of a parameter enables a conditional block: parameters that are not
+// set, null, false, or an empty string, array or object don't. Numbers do, including 0.
+func truthy(value any) bool {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Pointer, reflect.Interface:
+		return !rv.IsNil()
+	default:
+		return true
+	}
+}
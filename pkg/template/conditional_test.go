@@ -0,0 +1,213 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalBlocks(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"verbose": {Type: "boolean"},
+			"name":    {Type: "string"},
+			"limit":   {Type: "integer"},
+			"tags":    {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			"user": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"email": {Type: "string"},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		name     string
+		template string
+		fields   map[string]any
+		headers  map[string]string
+		quote    func(string) string
+		expected string
+		parseErr string
+	}{
+		{
+			name:     "true boolean",
+			template: "ls{?verbose} --verbose{/verbose}",
+			fields:   map[string]any{"verbose": true},
+			expected: "ls --verbose",
+		},
+		{
+			name:     "false boolean",
+			template: "ls{?verbose} --verbose{/verbose}",
+			fields:   map[string]any{"verbose": false},
+			expected: "ls",
+		},
+		{
+			name:     "missing parameter",
+			template: "ls{?verbose} --verbose{/verbose}",
+			expected: "ls",
+		},
+		{
+			name:     "variables in the block",
+			template: "/users{?name}?name={name}{/name}",
+			fields:   map[string]any{"name": "ada"},
+			expected: "/users?name=ada",
+		},
+		{
+			name:     "variables in a skipped block are not required",
+			template: "/users{?name}?name={name}&limit={limit}{/name}",
+			expected: "/users",
+		},
+		{
+			name:     "empty string",
+			template: "/users{?name}?name={name}{/name}",
+			fields:   map[string]any{"name": ""},
+			expected: "/users",
+		},
+		{
+			name:     "zero is present",
+			template: "/users{?limit}?limit={limit}{/limit}",
+			fields:   map[string]any{"limit": 0},
+			expected: "/users?limit=0",
+		},
+		{
+			name:     "empty array",
+			template: "run{?tags} --tags{/tags}",
+			fields:   map[string]any{"tags": []any{}},
+			expected: "run",
+		},
+		{
+			name:     "nil value",
+			template: "run{?tags} --tags{/tags}",
+			fields:   map[string]any{"tags": nil},
+			expected: "run",
+		},
+		{
+			name:     "inverted block",
+			template: "ls{^verbose} --quiet{/verbose}",
+			fields:   map[string]any{"verbose": false},
+			expected: "ls --quiet",
+		},
+		{
+			name:     "inverted block with true boolean",
+			template: "ls{^verbose} --quiet{/verbose}",
+			fields:   map[string]any{"verbose": true},
+			expected: "ls",
+		},
+		{
+			name:     "nested blocks",
+			template: "git log{?verbose} --stat{?name} --author={name}{/name}{/verbose}",
+			fields:   map[string]any{"verbose": true, "name": "ada"},
+			expected: "git log --stat --author=ada",
+		},
+		{
+			name:     "nested property",
+			template: "notify{?user.email} --to {user.email}{/user.email}",
+			fields:   map[string]any{"user.email": "ada@example.com"},
+			expected: "notify --to ada@example.com",
+		},
+		{
+			name:     "sources and pipes in the block",
+			template: "{?verbose}{headers.Trace|upper}/{name|default:anonymous}{/verbose}",
+			fields:   map[string]any{"verbose": true},
+			headers:  map[string]string{"Trace": "abc"},
+			expected: "ABC/anonymous",
+		},
+		{
+			name:     "percent signs in the block",
+			template: "{?verbose}100%{/verbose}",
+			fields:   map[string]any{"verbose": true},
+			expected: "100%",
+		},
+		{
+			name:     "values in the block are quoted",
+			template: "echo{?name} {name}{/name}",
+			fields:   map[string]any{"name": "it's"},
+			quote:    func(s string) string { return "<" + s + ">" },
+			expected: "echo <it's>",
+		},
+		{
+			name:     "unknown parameter",
+			template: "{?debug}--debug{/debug}",
+			parseErr: "invalid conditional block 'debug'",
+		},
+		{
+			name:     "unclosed block",
+			template: "ls{?verbose} --verbose",
+			parseErr: "conditional block 'verbose' at position 2 is not closed with {/verbose}",
+		},
+		{
+			name:     "unmatched closing tag",
+			template: "ls --verbose{/verbose}",
+			parseErr: "unmatched closing tag of conditional block at position 12",
+		},
+		{
+			name:     "invalid content",
+			template: "{?verbose}{debug}{/verbose}",
+			parseErr: "invalid content of conditional block 'verbose'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			formatter, err := NewTemplateFormatterWithOptions(tc.template, TemplateParserOptions{
+				InputSchema: schema,
+				Sources:     CreateHeadersSourceFactory(),
+				Quote:       tc.quote,
+			}, false)
+			if tc.parseErr != "" {
+				assert.ErrorContains(t, err, tc.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			builder := formatter.(*TemplateBuilder)
+			for k, v := range tc.fields {
+				builder.SetField(k, v)
+			}
+			builder.SetSourceResolver("headers", NewMapResolver(tc.headers))
+
+			result, err := builder.GetResult()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestTemplateBuildersDontShareState(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"verbose": {Type: "boolean"},
+			"name":    {Type: "string"},
+		},
+	}
+
+	pt, err := ParseTemplate("{name|default:x}{?verbose} -v{/verbose} {headers.Id}", TemplateParserOptions{
+		InputSchema: schema,
+		Sources:     CreateHeadersSourceFactory(),
+	})
+	require.NoError(t, err)
+
+	first, err := NewTemplateBuilder(pt, false)
+	require.NoError(t, err)
+	first.SetField("name", "ada")
+	first.SetField("verbose", true)
+	first.SetSourceResolver("headers", NewMapResolver(map[string]string{"Id": "1"}))
+
+	second, err := NewTemplateBuilder(pt, false)
+	require.NoError(t, err)
+	second.SetSourceResolver("headers", NewMapResolver(map[string]string{"Id": "2"}))
+
+	result, err := first.GetResult()
+	require.NoError(t, err)
+	assert.Equal(t, "ada -v 1", result)
+
+	result, err = second.GetResult()
+	require.NoError(t, err)
+	assert.Equal(t, "x 2", result)
+}
@@ -0,0 +1,14 @@
+package template
+
+// EnvAllowed reports whether the templates of tool can reference the environment variable name as ${VAR} or
+// {env.VAR}. tool is empty for templates that are not parsed for a tool.
+func (a *Allowlist) EnvAllowed(tool, name string) bool {
+	if a == nil || a.env == nil {
+		return true
+	}
+	if _, ok := a.env[name]; ok {
+		return true
+	}
+	_, ok := a.toolEnv[tool][name]
+	return ok
+}
@@ -0,0 +1,80 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowedEnv(t *testing.T) {
+	tt := []struct {
+		name          string
+		allowedEnv    []string
+		toolEnv       map[string][]string
+		tool          string
+		template      string
+		expectedError string
+	}{
+		{
+			name:     "any environment variable without allowlist",
+			template: "Bearer ${GITHUB_TOKEN}",
+		},
+		{
+			name:       "allowed environment variable",
+			allowedEnv: []string{"API_URL"},
+			template:   "{env.API_URL}/users",
+		},
+		{
+			name:          "environment variable not allowed",
+			allowedEnv:    []string{"API_URL"},
+			template:      "Bearer ${GITHUB_TOKEN}",
+			expectedError: "environment variable 'GITHUB_TOKEN' is not in the allowedEnv of the security config of the server",
+		},
+		{
+			name:          "environment variable not allowed in conditional block",
+			allowedEnv:    []string{"API_URL"},
+			template:      "{?name}token={env.GITHUB_TOKEN}{/name}",
+			expectedError: "environment variable 'GITHUB_TOKEN' is not in the allowedEnv",
+		},
+		{
+			name:       "environment variable allowed for the tool",
+			allowedEnv: []string{"API_URL"},
+			toolEnv:    map[string][]string{"get_repo": {"GITHUB_TOKEN"}},
+			tool:       "get_repo",
+			template:   "Bearer ${GITHUB_TOKEN}",
+		},
+		{
+			name:          "environment variable allowed for another tool",
+			toolEnv:       map[string][]string{"get_repo": {"GITHUB_TOKEN"}},
+			tool:          "delete_repo",
+			template:      "Bearer ${GITHUB_TOKEN}",
+			expectedError: "environment variable 'GITHUB_TOKEN' is not in the allowedEnv",
+		},
+		{
+			name:          "environment variable allowed for a tool outside of tools",
+			toolEnv:       map[string][]string{"get_repo": {"GITHUB_TOKEN"}},
+			template:      "Bearer ${GITHUB_TOKEN}",
+			expectedError: "environment variable 'GITHUB_TOKEN' is not in the allowedEnv",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseTemplate(tc.template, TemplateParserOptions{
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}},
+				},
+				Tool:      tc.tool,
+				Allowlist: NewAllowlist(nil, tc.allowedEnv, tc.toolEnv),
+			})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
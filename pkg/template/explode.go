@@ -0,0 +1,158 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultExplodeSeparator joins the elements of exploded variables when neither the variable nor the
+// parser options set a separator.
+const defaultExplodeSeparator = ","
+
+// ArrayPath returns the path of the array holding the element at path, e.g. "tags" for "tags[1]", and
+// whether path is the path of an array element. Arguments are set on builders element by element, so the
+// values of array variables are collected from the paths of their elements.
+func ArrayPath(path string) (string, bool) {
+	array, _, ok := arrayElement(path)
+	return array, ok
+}
+
+// arrayElement splits the path of an array element, e.g. "tags[1]", into the path of the array and the index
+// of the element.
+func arrayElement(path string) (string, int, bool) {
+	if !strings.HasSuffix(path, "]") {
+		return "", 0, false
+	}
+	start := strings.LastIndex(path, "[")
+	if start <= 0 {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(path[start+1 : len(path)-1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return path[:start], index, true
+}
+
+// setElement sets the element at index of array, which is extended if needed. Values that are not arrays
+// are replaced.
+func setElement(array any, index int, value any) []any {
+	items, _ := array.([]any)
+	for len(items) <= index {
+		items = append(items, nil)
+	}
+	items[index] = value
+	return items
+}
+
+// explodeSeparator returns the separator joining the elements of the exploded variable starting at pos
+// in template, given the text preceding it since the previous variable, and removes it from calls if the
+// pipe of the variable sets it with join.
+func explodeSeparator(template string, pos int, preceding string, calls []pipeCall, opts TemplateParserOptions) (string, []pipeCall) {
+	for i, call := range calls {
+		if call.name == "join" {
+			return call.arg, append(calls[:i:i], calls[i+1:]...)
+		}
+	}
+
+	// key={name*} in the query of a URL repeats the key for each element
+	if opts.QueryExplode && strings.Contains(template[:pos], "?") && strings.HasSuffix(preceding, "=") {
+		if start := strings.LastIndexAny(preceding, "?&"); start != -1 {
+			return "&" + preceding[start+1:], calls
+		}
+	}
+
+	if opts.ExplodeSeparator != "" {
+		return opts.ExplodeSeparator, calls
+	}
+	return defaultExplodeSeparator, calls
+}
+
+// explodeFormatter formats each element of an array parameter with the formatter of the variable, and
+// joins the results with a separator.
+type explodeFormatter struct {
+	element   VariableFormatter // copied to format each element
+	paramName string
+	separator string
+	value     any
+	fields    map[string]any            // other fields used by the formatter of the elements
+	resolvers map[string]SourceResolver // resolvers of the sources used by the formatter of the elements
+}
+
+// explodeVariable makes variable format each element of its value and join them with separator.
+func explodeVariable(variable *Variable, separator string) {
+	variable.VariableFormatter = &explodeFormatter{
+		element:   variable.VariableFormatter,
+		paramName: variable.Name,
+		separator: separator,
+		fields:    make(map[string]any),
+		resolvers: make(map[string]SourceResolver),
+	}
+}
+
+func (f *explodeFormatter) SetField(path string, value any) {
+	if path == f.paramName {
+		f.value = value
+		return
+	}
+	if array, index, ok := arrayElement(path); ok && array == f.paramName {
+		f.value = setElement(f.value, index, value)
+		return
+	}
+	f.fields[path] = value
+}
+
+func (f *explodeFormatter) GetResult() (any, error) {
+	// missing arrays are formatted like empty ones, as empty arrays have no elements to set
+	var items []any
+	switch v := f.value.(type) {
+	case nil:
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+
+	parts := make([]string, 0, len(items))
+	for i, item := range items {
+		element := cloneFormatter(f.element)
+		for path, value := range f.fields {
+			element.SetField(path, value)
+		}
+		for sourceName, resolver := range f.resolvers {
+			setSourceResolver(element, sourceName, resolver)
+		}
+		element.SetField(f.paramName, item)
+
+		result, err := element.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("failed to format element %d of '%s': %w", i, f.paramName, err)
+		}
+		parts = append(parts, fmt.Sprintf(element.FormatString(), result))
+	}
+
+	return strings.Join(parts, f.separator), nil
+}
+
+func (f *explodeFormatter) FormatString() string {
+	return "%s"
+}
+
+func (f *explodeFormatter) VariableNames() []string {
+	return f.element.VariableNames()
+}
+
+func (f *explodeFormatter) SetSourceResolver(sourceName string, resolver SourceResolver) {
+	f.resolvers[sourceName] = resolver
+}
+
+func (f *explodeFormatter) clone() VariableFormatter {
+	return &explodeFormatter{
+		element:   f.element,
+		paramName: f.paramName,
+		separator: f.separator,
+		fields:    make(map[string]any),
+		resolvers: make(map[string]SourceResolver),
+	}
+}
@@ -0,0 +1,189 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplode(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"tags": {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			"ids":  {Type: "array", Items: &jsonschema.Schema{Type: "integer"}},
+			"name": {Type: "string"},
+		},
+	}
+
+	tagFormatter := func(t *testing.T, quote func(string) string) map[string]VariableFormatter {
+		formatter, err := NewTemplateFormatterWithOptions("--tag {tags}", TemplateParserOptions{
+			InputSchema: schema,
+			Quote:       quote,
+		}, false)
+		require.NoError(t, err)
+		return map[string]VariableFormatter{"tags": formatter}
+	}
+
+	tt := []struct {
+		name       string
+		template   string
+		fields     map[string]any
+		opts       TemplateParserOptions
+		formatters func(t *testing.T) map[string]VariableFormatter
+		expected   string
+		parseErr   string
+		resultErr  string
+	}{
+		{
+			name:     "comma-joined by default",
+			template: "/items/{ids*}",
+			fields:   map[string]any{"ids": []any{1, 2, 3}},
+			expected: "/items/1,2,3",
+		},
+		{
+			name:     "separator of the options",
+			template: "ls {tags*}",
+			fields:   map[string]any{"tags": []any{"a", "b"}},
+			opts:     TemplateParserOptions{ExplodeSeparator: " "},
+			expected: "ls a b",
+		},
+		{
+			name:     "separator of the variable",
+			template: "/items/{ids*|join:;}",
+			fields:   map[string]any{"ids": []any{1, 2}},
+			opts:     TemplateParserOptions{ExplodeSeparator: " "},
+			expected: "/items/1;2",
+		},
+		{
+			name:     "functions apply to each element",
+			template: "/files/{tags*|pathescape|join:/}",
+			fields:   map[string]any{"tags": []any{"a b", "c/d"}},
+			expected: "/files/a%20b/c%2Fd",
+		},
+		{
+			name:     "each element is quoted",
+			template: "echo {tags*}",
+			fields:   map[string]any{"tags": []any{"a b", "c"}},
+			opts:     TemplateParserOptions{ExplodeSeparator: " ", Quote: func(s string) string { return "<" + s + ">" }},
+			expected: "echo <a b> <c>",
+		},
+		{
+			name:       "custom formatter repeats flags",
+			template:   "run {tags*}",
+			fields:     map[string]any{"tags": []any{"a", "b"}},
+			opts:       TemplateParserOptions{ExplodeSeparator: " "},
+			formatters: func(t *testing.T) map[string]VariableFormatter { return tagFormatter(t, nil) },
+			expected:   "run --tag a --tag b",
+		},
+		{
+			name:     "custom formatter quotes each element",
+			template: "run {tags*}",
+			fields:   map[string]any{"tags": []any{"it's", "b"}},
+			opts:     TemplateParserOptions{ExplodeSeparator: " "},
+			formatters: func(t *testing.T) map[string]VariableFormatter {
+				return tagFormatter(t, func(s string) string { return "<" + s + ">" })
+			},
+			expected: "run --tag <it's> --tag <b>",
+		},
+		{
+			name:     "query keys are repeated",
+			template: "/search?q={name}&tag={tags*|urlencode}&limit=10",
+			fields:   map[string]any{"name": "x", "tags": []any{"a&b", "c"}},
+			opts:     TemplateParserOptions{QueryExplode: true},
+			expected: "/search?q=x&tag=a%26b&tag=c&limit=10",
+		},
+		{
+			name:     "path is comma-joined in URLs",
+			template: "/items/{ids*}?id={ids*}",
+			fields:   map[string]any{"ids": []any{1, 2}},
+			opts:     TemplateParserOptions{QueryExplode: true},
+			expected: "/items/1,2?id=1&id=2",
+		},
+		{
+			name:     "empty array",
+			template: "ls{tags*}",
+			fields:   map[string]any{"tags": []any{}},
+			expected: "ls",
+		},
+		{
+			name:     "single value",
+			template: "ls {tags*}",
+			fields:   map[string]any{"tags": "a"},
+			expected: "ls a",
+		},
+		{
+			name:     "join without explode",
+			template: "/items/{ids|join:,}",
+			fields:   map[string]any{"ids": []any{1, 2}},
+			expected: "/items/1,2",
+		},
+		{
+			name:     "join without explode is applied before the other functions",
+			template: "{tags|upper|join:-}",
+			fields:   map[string]any{"tags": []any{"a", "b"}},
+			expected: "A-B",
+		},
+		{
+			name:     "missing parameter",
+			template: "ls{tags*}",
+			expected: "ls",
+		},
+		{
+			name:     "elements set one by one",
+			template: "ls {tags*}",
+			fields:   map[string]any{"tags[0]": "a", "tags[1]": "b"},
+			opts:     TemplateParserOptions{ExplodeSeparator: " "},
+			expected: "ls a b",
+		},
+		{
+			name:     "join of elements set one by one",
+			template: "{ids|join:+}",
+			fields:   map[string]any{"ids[1]": 2, "ids[0]": 1},
+			expected: "1+2",
+		},
+		{
+			name:     "environment variables can't be exploded",
+			template: "{env.HOME*}",
+			parseErr: "cannot explode 'env.HOME' at position 0: only input properties can be exploded",
+		},
+		{
+			name:     "sources can't be exploded",
+			template: "{headers.Tags*}",
+			parseErr: "cannot explode 'headers.Tags' at position 0: only input properties can be exploded",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.InputSchema = schema
+			opts.Sources = CreateHeadersSourceFactory()
+			if tc.formatters != nil {
+				opts.Formatters = tc.formatters(t)
+			}
+
+			formatter, err := NewTemplateFormatterWithOptions(tc.template, opts, false)
+			if tc.parseErr != "" {
+				assert.ErrorContains(t, err, tc.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			builder := formatter.(*TemplateBuilder)
+			for k, v := range tc.fields {
+				builder.SetField(k, v)
+			}
+
+			result, err := builder.GetResult()
+			if tc.resultErr != "" {
+				assert.ErrorContains(t, err, tc.resultErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
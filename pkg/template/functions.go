@@ -0,0 +1,200 @@
+package template
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateFunction transforms the formatted value of a variable in a pipe, e.g. lower in {name|lower}.
+type templateFunction struct {
+	hasArg bool // whether the function takes an argument, passed as {name|function:argument}
+	apply  func(value, arg string) (string, error)
+}
+
+// templateFunctions are the functions available in the pipes of variables. default and join are handled
+// by pipeFormatter, as they apply to variables without a value and to arrays.
+var templateFunctions = map[string]templateFunction{
+	"lower": {apply: func(value, _ string) (string, error) {
+		return strings.ToLower(value), nil
+	}},
+	"upper": {apply: func(value, _ string) (string, error) {
+		return strings.ToUpper(value), nil
+	}},
+	"trim": {apply: func(value, _ string) (string, error) {
+		return strings.TrimSpace(value), nil
+	}},
+	"urlencode": {apply: func(value, _ string) (string, error) {
+		return url.QueryEscape(value), nil
+	}},
+	"pathescape": {apply: func(value, _ string) (string, error) {
+		return url.PathEscape(value), nil
+	}},
+	"base64": {apply: func(value, _ string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	}},
+	"format":  {hasArg: true, apply: formatDate},
+	"default": {hasArg: true},
+	"join":    {hasArg: true},
+}
+
+// pipeCall is a function called in the pipe of a variable.
+type pipeCall struct {
+	name string
+	arg  string
+	templateFunction
+}
+
+// parsePipe splits a variable expression such as name|lower|default:x into the name of the variable and
+// the functions of its pipe.
+func parsePipe(expr string) (string, []pipeCall, error) {
+	parts := strings.Split(expr, "|")
+	if len(parts) == 1 {
+		return expr, nil, nil
+	}
+
+	calls := make([]pipeCall, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		name, arg, hasArg := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+
+		fn, ok := templateFunctions[name]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown template function '%s' in '%s', must be one of %s",
+				name, expr, strings.Join(slices.Sorted(maps.Keys(templateFunctions)), ", "))
+		}
+		if fn.hasArg && !hasArg {
+			return "", nil, fmt.Errorf("template function '%s' in '%s' requires an argument, as %s:argument", name, expr, name)
+		}
+		if !fn.hasArg && hasArg {
+			return "", nil, fmt.Errorf("template function '%s' in '%s' does not take an argument", name, expr)
+		}
+
+		calls = append(calls, pipeCall{name: name, arg: arg, templateFunction: fn})
+	}
+
+	return strings.TrimSpace(parts[0]), calls, nil
+}
+
+// pipeFormatter applies the functions of a pipe to the formatted value of a variable.
+type pipeFormatter struct {
+	VariableFormatter
+	calls []pipeCall
+	quote func(string) string // applied to the result of the pipe, instead of the value of the variable
+}
+
+// pipeVariable makes variable apply the functions of a pipe to its value, then quote the result if quote is set.
+func pipeVariable(variable *Variable, calls []pipeCall, quote func(string) string) {
+	if len(calls) == 0 {
+		return
+	}
+	variable.VariableFormatter = &pipeFormatter{
+		VariableFormatter: variable.VariableFormatter,
+		calls:             calls,
+		quote:             quote,
+	}
+}
+
+func (f *pipeFormatter) GetResult() (any, error) {
+	var value string
+	result, err := f.VariableFormatter.GetResult()
+	if err == nil && result != nil {
+		value = fmt.Sprintf(f.VariableFormatter.FormatString(), result)
+		if items, ok := result.([]any); ok {
+			if separator, ok := f.joinSeparator(); ok {
+				value = joinItems(items, separator)
+			}
+		}
+	}
+
+	for _, call := range f.calls {
+		if call.name == "join" {
+			continue
+		}
+		if call.name == "default" {
+			// variables without a value, whether unset or empty, take the default
+			if err != nil || value == "" {
+				value, err = call.arg, nil
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		value, err = call.apply(value, call.arg)
+		if err != nil {
+			err = fmt.Errorf("template function '%s' failed: %w", call.name, err)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if f.quote != nil {
+		return f.quote(value), nil
+	}
+	return value, nil
+}
+
+// joinSeparator returns the separator of the join function of the pipe, if it has one.
+func (f *pipeFormatter) joinSeparator() (string, bool) {
+	for _, call := range f.calls {
+		if call.name == "join" {
+			return call.arg, true
+		}
+	}
+	return "", false
+}
+
+// joinItems formats the elements of an array and joins them with separator.
+func joinItems(items []any, separator string) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprintf("%v", item)
+	}
+	return strings.Join(parts, separator)
+}
+
+func (f *pipeFormatter) FormatString() string {
+	return "%s"
+}
+
+func (f *pipeFormatter) clone() VariableFormatter {
+	return &pipeFormatter{
+		VariableFormatter: cloneFormatter(f.VariableFormatter),
+		calls:             f.calls,
+		quote:             f.quote,
+	}
+}
+
+// dateLayouts are the layouts of the dates accepted by the format function, next to Unix timestamps.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+// formatDate formats a date, given with one of dateLayouts or as a Unix timestamp in seconds, with the
+// Go time layout.
+func formatDate(value, layout string) (string, error) {
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, value); err == nil {
+			return t.Format(layout), nil
+		}
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return "", fmt.Errorf("'%s' is not a date or Unix timestamp", value)
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(layout), nil
+}
@@ -0,0 +1,188 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFunctions(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"name":    {Type: "string"},
+			"userId":  {Type: "string"},
+			"date":    {Type: "string"},
+			"created": {Type: "integer"},
+			"limit":   {Type: "integer"},
+		},
+	}
+
+	tt := []struct {
+		name      string
+		template  string
+		fields    map[string]any
+		env       map[string]string
+		headers   map[string]string
+		quote     func(string) string
+		expected  string
+		parseErr  string
+		resultErr string
+	}{
+		{
+			name:     "lower and upper",
+			template: "{name|lower}-{name|upper}",
+			fields:   map[string]any{"name": "Ada Lovelace"},
+			expected: "ada lovelace-ADA LOVELACE",
+		},
+		{
+			name:     "urlencode",
+			template: "https://api.com/users/{userId|urlencode}",
+			fields:   map[string]any{"userId": "a/b c&d"},
+			expected: "https://api.com/users/a%2Fb+c%26d",
+		},
+		{
+			name:     "pathescape",
+			template: "https://api.com/users/{userId|pathescape}",
+			fields:   map[string]any{"userId": "a/b c"},
+			expected: "https://api.com/users/a%2Fb%20c",
+		},
+		{
+			name:     "trim and base64",
+			template: "{name|trim|base64}",
+			fields:   map[string]any{"name": "  user:pass "},
+			expected: "dXNlcjpwYXNz",
+		},
+		{
+			name:     "format date",
+			template: "{date|format:2006-01-02}",
+			fields:   map[string]any{"date": "2024-03-05T10:30:00Z"},
+			expected: "2024-03-05",
+		},
+		{
+			name:     "format with colons in the layout",
+			template: "{date|format:15:04}",
+			fields:   map[string]any{"date": "2024-03-05 10:30:00"},
+			expected: "10:30",
+		},
+		{
+			name:     "format unix timestamp",
+			template: "{created|format:2006-01-02T15:04:05Z07:00}",
+			fields:   map[string]any{"created": 1700000000},
+			expected: "2023-11-14T22:13:20Z",
+		},
+		{
+			name:      "format invalid date",
+			template:  "{date|format:2006-01-02}",
+			fields:    map[string]any{"date": "yesterday"},
+			resultErr: "template function 'format' failed: 'yesterday' is not a date or Unix timestamp",
+		},
+		{
+			name:     "default for missing parameter",
+			template: "/items?limit={limit|default:10}",
+			expected: "/items?limit=10",
+		},
+		{
+			name:     "default for empty parameter",
+			template: "{name|default:anonymous}",
+			fields:   map[string]any{"name": ""},
+			expected: "anonymous",
+		},
+		{
+			name:     "default is not used for provided parameter",
+			template: "{limit|default:10}",
+			fields:   map[string]any{"limit": 25},
+			expected: "25",
+		},
+		{
+			name:     "functions after default apply to the default",
+			template: "{name|default:Guest|lower}",
+			expected: "guest",
+		},
+		{
+			name:      "functions before default don't hide missing parameter",
+			template:  "{name|lower}",
+			resultErr: "parameter 'name' was not provided",
+		},
+		{
+			name:     "default for unset environment variable",
+			template: "{env.GENMCP_TEMPLATE_FUNCTIONS_UNSET|default:fallback}-${GENMCP_TEMPLATE_FUNCTIONS_UNSET|default:other}",
+			expected: "fallback-other",
+		},
+		{
+			name:     "environment variable",
+			template: "${GENMCP_TEMPLATE_FUNCTIONS_HOST|lower}",
+			env:      map[string]string{"GENMCP_TEMPLATE_FUNCTIONS_HOST": "API.Example.COM"},
+			expected: "api.example.com",
+		},
+		{
+			name:     "source",
+			template: "Bearer {headers.Token|trim}",
+			headers:  map[string]string{"Token": " abc "},
+			expected: "Bearer abc",
+		},
+		{
+			name:     "quoted after the pipe",
+			template: "echo {name|upper} {userId}",
+			fields:   map[string]any{"name": "it's", "userId": "x"},
+			quote:    func(s string) string { return "<" + s + ">" },
+			expected: "echo <IT'S> <x>",
+		},
+		{
+			name:     "spaces around functions",
+			template: "{name | upper}",
+			fields:   map[string]any{"name": "a"},
+			expected: "A",
+		},
+		{
+			name:     "unknown function",
+			template: "{name|reverse}",
+			parseErr: "unknown template function 'reverse' in 'name|reverse', must be one of base64, default, format, join, lower, pathescape, trim, upper, urlencode",
+		},
+		{
+			name:     "missing argument",
+			template: "{limit|default}",
+			parseErr: "template function 'default' in 'limit|default' requires an argument",
+		},
+		{
+			name:     "unexpected argument",
+			template: "{name|lower:x}",
+			parseErr: "template function 'lower' in 'name|lower:x' does not take an argument",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			formatter, err := NewTemplateFormatterWithOptions(tc.template, TemplateParserOptions{
+				InputSchema: schema,
+				Sources:     CreateHeadersSourceFactory(),
+				Quote:       tc.quote,
+			}, false)
+			if tc.parseErr != "" {
+				assert.ErrorContains(t, err, tc.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			builder := formatter.(*TemplateBuilder)
+			for k, v := range tc.fields {
+				builder.SetField(k, v)
+			}
+			builder.SetSourceResolver("headers", NewMapResolver(tc.headers))
+
+			result, err := builder.GetResult()
+			if tc.resultErr != "" {
+				assert.ErrorContains(t, err, tc.resultErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
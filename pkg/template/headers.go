@@ -0,0 +1,13 @@
+package template
+
+import "net/textproto"
+
+// HeaderAllowed reports whether templates can reference the header of the incoming request name as
+// {headers.Name}.
+func (a *Allowlist) HeaderAllowed(name string) bool {
+	if a == nil || a.headers == nil {
+		return true
+	}
+	_, ok := a.headers[textproto.CanonicalMIMEHeaderKey(name)]
+	return ok
+}
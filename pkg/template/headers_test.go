@@ -0,0 +1,64 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowedHeaders(t *testing.T) {
+	tt := []struct {
+		name           string
+		allowedHeaders []string
+		template       string
+		expectedError  string
+	}{
+		{
+			name:     "any header without allowlist",
+			template: "session={headers.Cookie}",
+		},
+		{
+			name:           "allowed header",
+			allowedHeaders: []string{"X-Tenant-ID"},
+			template:       "/tenants/{headers.x-tenant-id}",
+		},
+		{
+			name:           "header not allowed",
+			allowedHeaders: []string{"X-Tenant-ID"},
+			template:       "Bearer {headers.Authorization}",
+			expectedError:  "header 'Authorization' is not in the allowedHeaders of the security config of the server",
+		},
+		{
+			name:           "header not allowed in conditional block",
+			allowedHeaders: []string{"X-Tenant-ID"},
+			template:       "{?name}session={headers.Cookie}{/name}",
+			expectedError:  "header 'Cookie' is not in the allowedHeaders",
+		},
+		{
+			name:           "no header allowed",
+			allowedHeaders: []string{},
+			template:       "{headers.X-Tenant-ID}",
+			expectedError:  "header 'X-Tenant-ID' is not in the allowedHeaders",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseTemplate(tc.template, TemplateParserOptions{
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}},
+				},
+				Sources:   CreateHeadersSourceFactory(),
+				Allowlist: NewAllowlist(tc.allowedHeaders, nil, nil),
+			})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -16,6 +16,7 @@ const (
 	VariableTypeParam VariableType = iota
 	VariableTypeEnv
 	VariableTypeSource
+	VariableTypeConditional
 )
 
 // SourceResolver resolves field values from a runtime data source.
@@ -39,6 +40,21 @@ type VariableFormatter interface {
 	VariableNames() []string // Returns the list of variable names this formatter needs
 }
 
+// clonableFormatter is implemented by formatters holding the state of an invocation, such as the values of
+// parameters, so that each builder of a parsed template gets formatters of its own.
+type clonableFormatter interface {
+	clone() VariableFormatter
+}
+
+// cloneFormatter returns a copy of formatter without the state of previous invocations, or formatter itself
+// if it is stateless.
+func cloneFormatter(formatter VariableFormatter) VariableFormatter {
+	if cf, ok := formatter.(clonableFormatter); ok {
+		return cf.clone()
+	}
+	return formatter
+}
+
 type Variable struct {
 	VariableFormatter
 	Name  string // the variable name (e.g. "userId", or "env.API_KEY")
@@ -56,6 +72,12 @@ type TemplateParserOptions struct {
 	InputSchema *jsonschema.Schema           // used to validate parameters and determine their type
 	Formatters  map[string]VariableFormatter // used to specify specific formatting options for specific variables
 	Sources     map[string]SourceFactory     // factories for creating formatters for custom sources (e.g., headers, secrets)
+	Quote       func(value string) string    // if set, applied to the formatted values of parameters and sources (e.g., to escape them for a shell)
+	Tool        string                       // name of the tool the template is parsed for, whose allowed environment variables it can reference
+	Allowlist   *Allowlist                   // headers and environment variables the template can reference, any if nil
+
+	ExplodeSeparator string // joins the elements of exploded variables such as {tags*}, "," if empty
+	QueryExplode     bool   // if set, exploded variables following key= in the query of a URL repeat key= for each element
 }
 
 // escapePercent escapes literal % characters in template chunks by replacing % with %%
@@ -72,6 +94,27 @@ func ParseTemplate(template string, opts TemplateParserOptions) (*ParsedTemplate
 	var chunk strings.Builder
 
 	for i := 0; i < len(template); {
+		// handle {?paramName}...{/paramName} and {^paramName}...{/paramName} conditional blocks
+		if i+1 < len(template) && template[i] == '{' && (template[i+1] == '?' || template[i+1] == '^') {
+			variable, next, err := parseConditional(template, i, paramIdx, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			variables = append(variables, *variable)
+			variableIndices[variable.Name] = append(variableIndices[variable.Name], paramIdx)
+			chunks = append(chunks, escapePercent(chunk.String()), variable.FormatString())
+			chunk.Reset()
+			paramIdx++
+			i = next
+			continue
+		}
+
+		// handle closing tags without a matching conditional block
+		if i+1 < len(template) && template[i] == '{' && template[i+1] == '/' {
+			return nil, fmt.Errorf("unmatched closing tag of conditional block at position %d", i)
+		}
+
 		// Handle ${VAR} syntax for environment variables
 		if i+1 < len(template) && template[i] == '$' && template[i+1] == '{' {
 			start := i + 2
@@ -81,12 +124,17 @@ func ParseTemplate(template string, opts TemplateParserOptions) (*ParsedTemplate
 				return nil, fmt.Errorf("unterminated environment variable at position %d", i)
 			}
 
-			varName := template[start:offset]
-			variable, err := createEnvVariable(varName, paramIdx)
+			varName, calls, err := parsePipe(template[start:offset])
 			if err != nil {
 				return nil, err
 			}
 
+			variable, err := createEnvVariable(varName, paramIdx, opts)
+			if err != nil {
+				return nil, err
+			}
+			pipeVariable(variable, calls, nil)
+
 			variables = append(variables, *variable)
 			variableIndices[variable.Name] = append(variableIndices[variable.Name], paramIdx)
 			chunks = append(chunks, escapePercent(chunk.String()), variable.FormatString())
@@ -105,28 +153,56 @@ func ParseTemplate(template string, opts TemplateParserOptions) (*ParsedTemplate
 				return nil, fmt.Errorf("unterminated variable at position %d", i)
 			}
 
-			varName := template[start:offset]
+			varName, calls, err := parsePipe(template[start:offset])
+			if err != nil {
+				return nil, err
+			}
+
+			// {name*} formats each element of an array and joins them
+			varName, exploded := strings.CutSuffix(varName, "*")
+			var separator string
+			if exploded {
+				separator, calls = explodeSeparator(template, i, chunk.String(), calls, opts)
+			}
+
+			// values with a pipe are quoted once the functions of the pipe are applied
+			varOpts := opts
+			if len(calls) > 0 {
+				varOpts.Quote = nil
+			}
 
 			var variable *Variable
-			var err error
 			if envVarName, found := strings.CutPrefix(varName, "env."); found {
-				variable, err = createEnvVariable(envVarName, paramIdx)
+				variable, err = createEnvVariable(envVarName, paramIdx, opts)
 			} else if dotIdx := strings.Index(varName, "."); dotIdx != -1 {
 				sourceName := varName[:dotIdx]
 				fieldName := varName[dotIdx+1:]
 				// Only treat as source if the prefix is a registered source
 				if _, isSource := opts.Sources[sourceName]; isSource {
-					variable, err = createSourceVariable(sourceName, fieldName, paramIdx, opts)
+					variable, err = createSourceVariable(sourceName, fieldName, paramIdx, varOpts)
 				} else {
 					// Otherwise treat as normal schema variable (e.g., user.name)
-					variable, err = createSchemaVariable(varName, paramIdx, opts)
+					variable, err = createSchemaVariable(varName, paramIdx, varOpts)
 				}
 			} else {
-				variable, err = createSchemaVariable(varName, paramIdx, opts)
+				variable, err = createSchemaVariable(varName, paramIdx, varOpts)
 			}
 			if err != nil {
 				return nil, err
 			}
+			if exploded && variable.Type != VariableTypeParam {
+				return nil, fmt.Errorf("cannot explode '%s' at position %d: only input properties can be exploded", varName, i)
+			}
+
+			// custom formatters quote the values they format themselves
+			quote := opts.Quote
+			if _, ok := opts.Formatters[varName]; ok {
+				quote = nil
+			}
+			pipeVariable(variable, calls, quote)
+			if exploded {
+				explodeVariable(variable, separator)
+			}
 
 			variables = append(variables, *variable)
 			variableIndices[variable.Name] = append(variableIndices[variable.Name], paramIdx)
@@ -163,19 +239,14 @@ type TemplateBuilder struct {
 	indices           map[string][]int
 	omitIfFalse       bool
 	implicitFormatter *paramFormatter // Used when omitIfFalse=true with 0 variables
-	sourceFormatters  map[string][]*SourceFormatter
 }
 
 // NewTemplateBuilder creates a new builder from a parsed template.
 func NewTemplateBuilder(pt *ParsedTemplate, omitIfFalse bool) (*TemplateBuilder, error) {
+	// the formatters of the parsed template are shared by its builders, so each builder works on copies
 	formatters := make([]VariableFormatter, len(pt.Variables))
-	sourceFormatters := make(map[string][]*SourceFormatter)
-
 	for i, v := range pt.Variables {
-		formatters[i] = v.VariableFormatter
-		if sf, ok := v.VariableFormatter.(*SourceFormatter); ok {
-			sourceFormatters[sf.sourceName] = append(sourceFormatters[sf.sourceName], sf)
-		}
+		formatters[i] = cloneFormatter(v.VariableFormatter)
 	}
 
 	var implicitFormatter *paramFormatter
@@ -225,12 +296,37 @@ func NewTemplateBuilder(pt *ParsedTemplate, omitIfFalse bool) (*TemplateBuilder,
 		indices:           indices,
 		omitIfFalse:       omitIfFalse,
 		implicitFormatter: implicitFormatter,
-		sourceFormatters:  sourceFormatters,
 	}, nil
 }
 
+func (tb *TemplateBuilder) clone() VariableFormatter {
+	formatters := make([]VariableFormatter, len(tb.formatters))
+	for i, formatter := range tb.formatters {
+		formatters[i] = cloneFormatter(formatter)
+	}
+
+	var implicitFormatter *paramFormatter
+	if tb.implicitFormatter != nil {
+		implicitFormatter = tb.implicitFormatter.clone().(*paramFormatter)
+	}
+
+	return &TemplateBuilder{
+		template:          tb.template,
+		formatters:        formatters,
+		indices:           tb.indices,
+		omitIfFalse:       tb.omitIfFalse,
+		implicitFormatter: implicitFormatter,
+	}
+}
+
 func (tb *TemplateBuilder) SetField(path string, value any) {
 	indices, ok := tb.indices[path]
+	if !ok {
+		// elements of arrays are set on the formatters of the array
+		if array, isElement := ArrayPath(path); isElement {
+			indices, ok = tb.indices[array]
+		}
+	}
 	if !ok {
 		// If there's an implicit formatter (omitIfFalse with 0 variables), accept any field
 		if tb.implicitFormatter != nil {
@@ -247,13 +343,25 @@ func (tb *TemplateBuilder) SetField(path string, value any) {
 // SetSourceResolver sets the resolver for all formatters using the specified source.
 // The resolver will be used to resolve field values when GetResult is called.
 func (tb *TemplateBuilder) SetSourceResolver(sourceName string, resolver SourceResolver) {
-	formatters, ok := tb.sourceFormatters[sourceName]
-	if !ok {
-		return
+	for _, formatter := range tb.formatters {
+		setSourceResolver(formatter, sourceName, resolver)
 	}
+}
 
-	for _, formatter := range formatters {
-		formatter.setResolver(resolver)
+// setSourceResolver sets the resolver of formatter if it uses the specified source, or of the formatters
+// nested in it.
+func setSourceResolver(formatter VariableFormatter, sourceName string, resolver SourceResolver) {
+	switch f := formatter.(type) {
+	case *SourceFormatter:
+		if f.sourceName == sourceName {
+			f.setResolver(resolver)
+		}
+	case *pipeFormatter:
+		setSourceResolver(f.VariableFormatter, sourceName, resolver)
+	case interface {
+		SetSourceResolver(string, SourceResolver)
+	}:
+		f.SetSourceResolver(sourceName, resolver)
 	}
 }
 
@@ -310,7 +418,7 @@ func (tb *TemplateBuilder) VariableNames() []string {
 	return varNames
 }
 
-func createEnvVariable(varName string, paramIdx int) (*Variable, error) {
+func createEnvVariable(varName string, paramIdx int, opts TemplateParserOptions) (*Variable, error) {
 	if varName == "" {
 		return nil, fmt.Errorf("environment variable name cannot be empty")
 	}
@@ -321,6 +429,10 @@ func createEnvVariable(varName string, paramIdx int) (*Variable, error) {
 		}
 	}
 
+	if !opts.Allowlist.EnvAllowed(opts.Tool, varName) {
+		return nil, fmt.Errorf("environment variable '%s' is not in the allowedEnv of the security config of the server", varName)
+	}
+
 	return &Variable{
 		Name:              varName,
 		Type:              VariableTypeEnv,
@@ -349,6 +461,7 @@ func createSchemaVariable(varName string, paramIdx int, opts TemplateParserOptio
 		formatter = &paramFormatter{
 			paramName:    varName,
 			formatString: formatString,
+			quote:        opts.Quote,
 		}
 	}
 
@@ -373,7 +486,14 @@ func createSourceVariable(sourceName, fieldName string, paramIdx int, opts Templ
 		return nil, fmt.Errorf("unknown source '%s'", sourceName)
 	}
 
+	if sourceName == "headers" && !opts.Allowlist.HeaderAllowed(fieldName) {
+		return nil, fmt.Errorf("header '%s' is not in the allowedHeaders of the security config of the server", fieldName)
+	}
+
 	formatter := factory(fieldName)
+	if sf, ok := formatter.(*SourceFormatter); ok {
+		sf.quote = opts.Quote
+	}
 
 	return &Variable{
 		Name:              sourceName + "." + fieldName,
@@ -410,6 +530,7 @@ func (f *envVarFormatter) VariableNames() []string {
 type paramFormatter struct {
 	paramName    string
 	formatString string
+	quote        func(string) string
 	value        any
 	hasValue     bool
 }
@@ -419,6 +540,11 @@ func (f *paramFormatter) SetField(path string, value any) {
 	if f.paramName == "" || path == f.paramName {
 		f.value = value
 		f.hasValue = true
+		return
+	}
+	if array, index, ok := arrayElement(path); ok && array == f.paramName {
+		f.value = setElement(f.value, index, value)
+		f.hasValue = true
 	}
 }
 
@@ -426,13 +552,28 @@ func (f *paramFormatter) GetResult() (any, error) {
 	if !f.hasValue {
 		return nil, fmt.Errorf("parameter '%s' was not provided", f.paramName)
 	}
+	if f.quote != nil {
+		return f.quote(fmt.Sprintf(f.formatString, f.value)), nil
+	}
 	return f.value, nil
 }
 
 func (f *paramFormatter) FormatString() string {
+	if f.quote != nil {
+		// the value is formatted by GetResult before it is quoted
+		return "%s"
+	}
 	return f.formatString
 }
 
+func (f *paramFormatter) clone() VariableFormatter {
+	return &paramFormatter{
+		paramName:    f.paramName,
+		formatString: f.formatString,
+		quote:        f.quote,
+	}
+}
+
 func (f *paramFormatter) VariableNames() []string {
 	if f.paramName == "" {
 		return []string{}
@@ -446,6 +587,7 @@ type SourceFormatter struct {
 	sourceName string
 	fieldName  string
 	resolver   SourceResolver
+	quote      func(string) string
 }
 
 func (sf *SourceFormatter) SetField(_ string, _ any) {
@@ -455,7 +597,11 @@ func (sf *SourceFormatter) GetResult() (any, error) {
 	if sf.resolver == nil {
 		return "", fmt.Errorf("source '%s' not set", sf.sourceName)
 	}
-	return sf.resolver.Resolve(sf.fieldName)
+	value, err := sf.resolver.Resolve(sf.fieldName)
+	if err != nil || sf.quote == nil {
+		return value, err
+	}
+	return sf.quote(value), nil
 }
 
 func (sf *SourceFormatter) FormatString() string {
@@ -466,6 +612,14 @@ func (sf *SourceFormatter) VariableNames() []string {
 	return []string{}
 }
 
+func (sf *SourceFormatter) clone() VariableFormatter {
+	return &SourceFormatter{
+		sourceName: sf.sourceName,
+		fieldName:  sf.fieldName,
+		quote:      sf.quote,
+	}
+}
+
 func (sf *SourceFormatter) setResolver(r SourceResolver) {
 	sf.resolver = r
 }
@@ -496,12 +650,29 @@ func CreateHeadersSourceFactory() map[string]SourceFactory {
 	}
 }
 
+// CreateSourceFactories creates a source factory map with the sources available in invocation templates:
+// "headers" for the headers of the incoming request, "secrets" for secrets resolved by the server, "claims"
+// for the claims of the credentials of the caller, and "tenant" for the variables of the tenant of the caller.
+func CreateSourceFactories() map[string]SourceFactory {
+	return map[string]SourceFactory{
+		"headers": NewSourceFactory("headers"),
+		"secrets": NewSourceFactory("secrets"),
+		"claims":  NewSourceFactory("claims"),
+		"tenant":  NewSourceFactory("tenant"),
+	}
+}
+
 // NewTemplateFormatter creates a formatter from a template string.
 func NewTemplateFormatter(templateStr string, inputSchema *jsonschema.Schema, omitIfFalse bool, sources map[string]SourceFactory) (VariableFormatter, error) {
-	pt, err := ParseTemplate(templateStr, TemplateParserOptions{
+	return NewTemplateFormatterWithOptions(templateStr, TemplateParserOptions{
 		InputSchema: inputSchema,
 		Sources:     sources,
-	})
+	}, omitIfFalse)
+}
+
+// NewTemplateFormatterWithOptions creates a formatter from a template string, parsed with the given options.
+func NewTemplateFormatterWithOptions(templateStr string, opts TemplateParserOptions, omitIfFalse bool) (VariableFormatter, error) {
+	pt, err := ParseTemplate(templateStr, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -737,3 +737,44 @@ func TestSourceResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestQuote(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"name":  {Type: "string"},
+			"count": {Type: "integer"},
+		},
+	}
+	t.Setenv("TEST_QUOTE_FLAGS", "-a -b")
+
+	quote := func(s string) string { return "<" + s + ">" }
+
+	countFormatter, err := NewTemplateFormatterWithOptions("--count={count}", TemplateParserOptions{
+		InputSchema: schema,
+		Quote:       quote,
+	}, false)
+	require.NoError(t, err)
+
+	pt, err := ParseTemplate("cmd ${TEST_QUOTE_FLAGS} {name} {count} {headers.Token}", TemplateParserOptions{
+		InputSchema: schema,
+		Formatters: map[string]VariableFormatter{
+			"count": countFormatter,
+		},
+		Sources: CreateHeadersSourceFactory(),
+		Quote:   quote,
+	})
+	require.NoError(t, err)
+
+	builder, err := NewTemplateBuilder(pt, false)
+	require.NoError(t, err)
+
+	builder.SetField("name", "a; b")
+	builder.SetField("count", 3)
+	builder.SetSourceResolver("headers", NewMapResolver(map[string]string{"Token": "t"}))
+
+	result, err := builder.GetResult()
+	require.NoError(t, err)
+	// environment variables are not quoted, and nested formatters quote their own values
+	assert.Equal(t, "cmd -a -b <a; b> --count=<3> <t>", result)
+}
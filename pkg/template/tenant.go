@@ -0,0 +1,51 @@
+package template
+
+import (
+	"context"
+	"fmt"
+)
+
+// TenantIDVariable is the name of the reference resolving to the ID of the tenant of the caller, {tenant.id}.
+const TenantIDVariable = "id"
+
+type tenantKey struct{}
+
+// TenantResolver resolves {tenant.NAME} references from the variables of the tenant of the caller, such as
+// the base URL of its backend, and {tenant.id} to the ID of the tenant.
+type TenantResolver struct {
+	id        string
+	variables map[string]string
+}
+
+// WithTenant stores the ID and the variables of the tenant of the caller in the given context.
+func WithTenant(ctx context.Context, id string, variables map[string]string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, &TenantResolver{id: id, variables: variables})
+}
+
+// TenantFromContext returns a resolver of the tenant stored in the context by WithTenant. If no tenant is
+// found, as for servers without tenants, it returns a resolver that fails to resolve any variable.
+func TenantFromContext(ctx context.Context) *TenantResolver {
+	if r, ok := ctx.Value(tenantKey{}).(*TenantResolver); ok && r != nil {
+		return r
+	}
+	return &TenantResolver{}
+}
+
+// ID returns the ID of the tenant, empty if no tenant is set.
+func (r *TenantResolver) ID() string {
+	return r.id
+}
+
+func (r *TenantResolver) Resolve(fieldName string) (string, error) {
+	if r.id == "" {
+		return "", fmt.Errorf("tenant variable '%s' not found: no tenant set", fieldName)
+	}
+	if fieldName == TenantIDVariable {
+		return r.id, nil
+	}
+	value, ok := r.variables[fieldName]
+	if !ok {
+		return "", fmt.Errorf("variable '%s' not found for tenant '%s'", fieldName, r.id)
+	}
+	return value, nil
+}
@@ -0,0 +1,43 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantResolver(t *testing.T) {
+	variables := map[string]string{"baseUrl": "https://acme.api.example.com", "region": "eu"}
+
+	tt := []struct {
+		name          string
+		tenant        string
+		field         string
+		expected      string
+		expectedError string
+	}{
+		{name: "variable", tenant: "acme", field: "baseUrl", expected: "https://acme.api.example.com"},
+		{name: "tenant ID", tenant: "acme", field: "id", expected: "acme"},
+		{name: "missing variable", tenant: "acme", field: "bucket", expectedError: "variable 'bucket' not found for tenant 'acme'"},
+		{name: "no tenant", field: "region", expectedError: "tenant variable 'region' not found: no tenant set"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.tenant != "" {
+				ctx = WithTenant(ctx, tc.tenant, variables)
+			}
+
+			value, err := TenantFromContext(ctx).Resolve(tc.field)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
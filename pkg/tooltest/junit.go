@@ -0,0 +1,87 @@
+package tooltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes r to w as JUnit XML, with a test suite per tool.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name:     r.Name,
+		Tests:    len(r.Cases),
+		Failures: r.Failed(),
+		Time:     seconds(r.Duration),
+	}
+
+	index := make(map[string]int)
+	var durations []time.Duration
+	for _, c := range r.Cases {
+		i, ok := index[c.Tool]
+		if !ok {
+			i = len(suites.Suites)
+			index[c.Tool] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: c.Tool})
+			durations = append(durations, 0)
+		}
+
+		tc := junitTestCase{Name: c.Name, ClassName: c.Tool, Time: seconds(c.Duration)}
+		if !c.Passed() {
+			tc.Failure = &junitFailure{Message: c.Failures[0], Text: strings.Join(c.Failures, "\n")}
+			suites.Suites[i].Failures++
+		}
+		suites.Suites[i].Tests++
+		suites.Suites[i].Cases = append(suites.Suites[i].Cases, tc)
+		durations[i] += c.Duration
+	}
+	for i := range suites.Suites {
+		suites.Suites[i].Time = seconds(durations[i])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
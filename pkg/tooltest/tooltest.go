@@ -0,0 +1,309 @@
+// Package tooltest runs the tests of the tools of an MCP file, the calls declared in their tests section and
+// the results they are expected to return, and reports their results as JUnit XML for CI systems.
+package tooltest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/invocation"
+	"github.com/genmcp/gen-mcp/pkg/invocation/utils"
+	"github.com/genmcp/gen-mcp/pkg/invoke"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+// Options holds optional settings for Run.
+type Options struct {
+	// Tools are the names of the tools whose tests are run. The tests of every tool are run if empty.
+	Tools []string
+
+	// Replay returns the results recorded by genmcp run --record instead of invoking the tools, so that tests
+	// don't depend on the backends. Tools are invoked if nil.
+	Replay *recording.Store
+}
+
+// CaseResult is the result of a test of a tool.
+type CaseResult struct {
+	Tool     string
+	Name     string
+	Duration time.Duration
+	// Failures are the expectations of the test that the result of the call did not meet
+	Failures []string
+}
+
+// Passed returns whether the result of the call met the expectations of the test.
+func (c CaseResult) Passed() bool {
+	return len(c.Failures) == 0
+}
+
+// Report holds the results of the tests of the tools of an MCP file.
+type Report struct {
+	// Name of the MCP server of the tools
+	Name     string
+	Cases    []CaseResult
+	Duration time.Duration
+}
+
+// Failed returns the number of tests that failed.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, c := range r.Cases {
+		if !c.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Run runs the tests of the tools of defs, in the order of the MCP file. The tests of a tool that is not valid
+// fail with its validation error.
+func Run(ctx context.Context, defs *definitions.MCPToolDefinitions, opts Options) (*Report, error) {
+	selected := make(map[string]bool, len(opts.Tools))
+	for _, name := range opts.Tools {
+		if _, err := findTool(defs, name); err != nil {
+			return nil, err
+		}
+		selected[name] = true
+	}
+
+	report := &Report{Name: defs.Name}
+	started := time.Now()
+	for _, tool := range defs.Tools {
+		if tool == nil || len(tool.Tests) == 0 || (len(selected) > 0 && !selected[tool.Name]) {
+			continue
+		}
+
+		validationErr := tool.Validate(invocation.InvocationValidator)
+		for _, test := range tool.Tests {
+			if test == nil {
+				continue
+			}
+
+			c := CaseResult{Tool: tool.Name, Name: test.Name}
+			if validationErr != nil {
+				c.Failures = []string{validationErr.Error()}
+			} else {
+				testStarted := time.Now()
+				result := callTool(ctx, tool, test, opts.Replay)
+				c.Duration = time.Since(testStarted)
+				c.Failures = checkResult(test.Expect, result)
+			}
+			report.Cases = append(report.Cases, c)
+		}
+	}
+	report.Duration = time.Since(started)
+
+	return report, nil
+}
+
+func findTool(defs *definitions.MCPToolDefinitions, name string) (*definitions.Tool, error) {
+	for _, tool := range defs.Tools {
+		if tool != nil && tool.Name == name {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("no tool named '%s' in the MCP file", name)
+}
+
+// callTool calls tool with the arguments of test, or replays the result recorded for them. Failures are
+// returned as results with an ErrorDetail, like the server returns them to clients.
+func callTool(ctx context.Context, tool *definitions.Tool, test *definitions.ToolTest, replay *recording.Store) *mcp.CallToolResult {
+	args := json.RawMessage("{}")
+	if test.Arguments != nil {
+		data, err := json.Marshal(test.Arguments)
+		if err != nil {
+			return utils.McpCodedError(invocation.ErrorCodeValidation, "invalid arguments: %v", err)
+		}
+		args = data
+	}
+
+	var result *mcp.CallToolResult
+	var err error
+	if replay.Replaying() {
+		result, err = replayTool(tool, args, replay)
+	} else {
+		result, err = invoke.InvokeTool(ctx, tool, args)
+	}
+	if err != nil {
+		return errorResult(err)
+	}
+
+	if result.IsError {
+		if _, ok := invocation.GetErrorDetail(result); !ok {
+			invocation.SetErrorDetail(result, invocation.NewErrorDetail(invocation.ErrorCodeInternal, 0))
+		}
+	}
+	return result
+}
+
+// replayTool returns the result recorded for the call of tool with args. Recordings are keyed by the
+// arguments of calls after their transforms are applied, as the server records them.
+func replayTool(tool *definitions.Tool, args json.RawMessage, replay *recording.Store) (*mcp.CallToolResult, error) {
+	args, err := tool.ApplyTransforms(args)
+	if err != nil {
+		return utils.McpCodedError(invocation.ErrorCodeValidation, "%v", err), nil
+	}
+
+	result, err := replay.Replay(tool.Name, args)
+	if errors.Is(err, recording.ErrNotRecorded) {
+		return utils.McpCodedError(invocation.ErrorCodeBackendUnavailable, "no recorded result for these arguments of tool %s", tool.Name), nil
+	}
+	return result, err
+}
+
+// errorResult returns the result of a call that failed with err: the error code and status of JSON-RPC
+// errors returned by invocations reporting failures as protocol errors, or the code of err otherwise.
+func errorResult(err error) *mcp.CallToolResult {
+	detail := invocation.NewErrorDetail(invocation.CodeOf(err, invocation.ErrorCodeInternal), 0)
+
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		var data invocation.ErrorDetail
+		if json.Unmarshal(rpcErr.Data, &data) == nil && data.Code != "" {
+			detail = data
+		}
+	}
+
+	result := utils.McpTextError("%v", err)
+	invocation.SetErrorDetail(result, detail)
+	return result
+}
+
+// checkResult returns the expectations of expect that result doesn't meet.
+func checkResult(expect *definitions.ToolTestExpectation, result *mcp.CallToolResult) []string {
+	var failures []string
+	text := resultText(result)
+
+	wantError := expect.ExpectsError()
+	switch {
+	case wantError && !result.IsError:
+		failures = append(failures, "expected an error, got a successful result")
+	case !wantError && result.IsError:
+		failures = append(failures, fmt.Sprintf("expected a successful result, got an error: %s", text))
+	}
+
+	if expect == nil {
+		return failures
+	}
+
+	detail, _ := invocation.GetErrorDetail(result)
+	if expect.ErrorCode != "" && detail.Code != expect.ErrorCode {
+		failures = append(failures, fmt.Sprintf("expected error code %s, got %s", expect.ErrorCode, orNone(string(detail.Code))))
+	}
+	if expect.Status != 0 && detail.Status != expect.Status {
+		failures = append(failures, fmt.Sprintf("expected status %d, got %d", expect.Status, detail.Status))
+	}
+
+	for _, s := range expect.Contains {
+		if !strings.Contains(text, s) {
+			failures = append(failures, fmt.Sprintf("expected the text of the result to contain %q", s))
+		}
+	}
+	if expect.Matches != "" && !regexp.MustCompile(expect.Matches).MatchString(text) {
+		failures = append(failures, fmt.Sprintf("expected the text of the result to match %q", expect.Matches))
+	}
+
+	if len(expect.JSON) > 0 {
+		doc, err := resultJSON(result, text)
+		if err != nil {
+			return append(failures, fmt.Sprintf("expected JSON content: %v", err))
+		}
+		for _, m := range expect.JSON {
+			if failure := checkMatcher(m, doc); failure != "" {
+				failures = append(failures, failure)
+			}
+		}
+	}
+
+	return failures
+}
+
+func checkMatcher(m definitions.ToolTestMatcher, doc any) string {
+	value, err := jmespath.Search(m.Path, doc)
+	if err != nil {
+		return fmt.Sprintf("failed to evaluate %s: %v", m.Path, err)
+	}
+
+	if m.Equals != nil {
+		want, got := normalizeJSON(m.Equals), normalizeJSON(value)
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Sprintf("expected %s to equal %s, got %s", m.Path, encodeJSON(want), encodeJSON(got))
+		}
+	}
+
+	if m.Matches != "" {
+		s, ok := value.(string)
+		if !ok {
+			s = encodeJSON(value)
+		}
+		if !regexp.MustCompile(m.Matches).MatchString(s) {
+			return fmt.Sprintf("expected %s to match %q, got %s", m.Path, m.Matches, encodeJSON(value))
+		}
+	}
+
+	return ""
+}
+
+// resultText returns the text content of result, one line per text content.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// resultJSON returns the structured content of result, or its text content parsed as JSON.
+func resultJSON(result *mcp.CallToolResult, text string) (any, error) {
+	if result.StructuredContent != nil {
+		return normalizeJSON(result.StructuredContent), nil
+	}
+
+	var doc any
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return nil, fmt.Errorf("the result has no structured content, and its text is not JSON")
+	}
+	return doc, nil
+}
+
+// normalizeJSON returns v with the types of the values of decoded JSON, so that values of different Go types
+// with the same JSON encoding are equal.
+func normalizeJSON(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func encodeJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,193 @@
+package tooltest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	definitions "github.com/genmcp/gen-mcp/pkg/config/definitions"
+	"github.com/genmcp/gen-mcp/pkg/observability/logging"
+	"github.com/genmcp/gen-mcp/pkg/recording"
+)
+
+const testMCPFile = `kind: MCPToolDefinitions
+schemaVersion: "0.2.0"
+name: issues
+version: "1.0"
+tools:
+- name: list_issues
+  description: List the issues of a repository
+  inputSchema:
+    type: object
+    properties:
+      repo:
+        type: string
+    required: [repo]
+  invocation:
+    http:
+      url: {{URL}}/repos/{repo}/issues
+      method: GET
+  tests:
+  - name: lists the issues
+    arguments:
+      repo: genmcp
+    expect:
+      contains: [Fix the parser]
+      json:
+      - path: length(issues)
+        equals: 2
+      - path: issues[0].title
+        matches: ^Fix
+  - name: unknown repository
+    arguments:
+      repo: missing
+    expect:
+      errorCode: backend_error_status
+      status: 404
+  - name: missing repository
+    expect:
+      errorCode: validation_error
+  - name: wrong expectations
+    arguments:
+      repo: genmcp
+    expect:
+      json:
+      - path: issues[1].state
+        equals: open
+      - path: total
+        equals: 3
+- name: get_issue
+  description: Get an issue
+  inputSchema:
+    type: object
+  invocation:
+    http:
+      url: {{URL}}/issue
+      method: GET
+`
+
+func parseMCPFile(t *testing.T, url string) *definitions.MCPToolDefinitions {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mcpfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(testMCPFile, "{{URL}}", url)), 0o600))
+
+	mcpFile, err := definitions.ParseMCPFile(path)
+	require.NoError(t, err)
+
+	return &mcpFile.MCPToolDefinitions
+}
+
+func testBackend(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/genmcp/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issues":[{"title":"Fix the parser","state":"open"},{"title":"Add tests","state":"closed"}],"total":2}`))
+	}))
+	t.Cleanup(backend.Close)
+
+	return backend
+}
+
+func TestRun(t *testing.T) {
+	defs := parseMCPFile(t, testBackend(t).URL)
+
+	report, err := Run(context.Background(), defs, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "issues", report.Name)
+	require.Len(t, report.Cases, 4)
+	assert.Equal(t, 1, report.Failed())
+
+	failures := make(map[string][]string)
+	for _, c := range report.Cases {
+		assert.Equal(t, "list_issues", c.Tool)
+		failures[c.Name] = c.Failures
+	}
+	assert.Empty(t, failures["lists the issues"])
+	assert.Empty(t, failures["unknown repository"])
+	assert.Empty(t, failures["missing repository"])
+	assert.Equal(t, []string{
+		`expected issues[1].state to equal "open", got "closed"`,
+		`expected total to equal 3, got 2`,
+	}, failures["wrong expectations"])
+}
+
+func TestRunUnknownTool(t *testing.T) {
+	defs := parseMCPFile(t, "http://localhost")
+
+	_, err := Run(context.Background(), defs, Options{Tools: []string{"create_issue"}})
+	assert.ErrorContains(t, err, "no tool named 'create_issue' in the MCP file")
+}
+
+func TestRunReplay(t *testing.T) {
+	// the backend is unreachable, results are replayed
+	defs := parseMCPFile(t, "http://127.0.0.1:1")
+
+	dir := t.TempDir()
+	redactor, err := logging.NewRedactor(nil)
+	require.NoError(t, err)
+	recorder, err := recording.NewStore(dir, recording.ModeRecord, redactor)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Record("list_issues", json.RawMessage(`{"repo":"genmcp"}`), &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: "Fix the parser"}},
+		StructuredContent: map[string]any{"issues": []any{map[string]any{"title": "Fix the parser"}, map[string]any{"title": "Add tests", "state": "closed"}}, "total": 2},
+	}))
+
+	replay, err := recording.NewStore(dir, recording.ModeReplay, nil)
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), defs, Options{Tools: []string{"list_issues"}, Replay: replay})
+	require.NoError(t, err)
+
+	failures := make(map[string][]string)
+	for _, c := range report.Cases {
+		failures[c.Name] = c.Failures
+	}
+	assert.Empty(t, failures["lists the issues"])
+	// calls that were not recorded fail as unavailable backends
+	assert.Equal(t, []string{"expected error code backend_error_status, got backend_unavailable", "expected status 404, got 0"}, failures["unknown repository"])
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := &Report{
+		Name: "issues",
+		Cases: []CaseResult{
+			{Tool: "list_issues", Name: "lists the issues"},
+			{Tool: "list_issues", Name: "unknown repository", Failures: []string{"expected an error, got a successful result", "expected status 404, got 0"}},
+			{Tool: "get_issue", Name: "gets the issue"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buf))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="issues" tests="3" failures="1" time="0.000">
+  <testsuite name="list_issues" tests="2" failures="1" time="0.000">
+    <testcase name="lists the issues" classname="list_issues" time="0.000"></testcase>
+    <testcase name="unknown repository" classname="list_issues" time="0.000">
+      <failure message="expected an error, got a successful result">expected an error, got a successful result&#xA;expected status 404, got 0</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="get_issue" tests="1" failures="0" time="0.000">
+    <testcase name="gets the issue" classname="get_issue" time="0.000"></testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Algorithms of the HMAC signatures of the requests of a webhook.
+const (
+	AlgorithmSHA1   = "sha1"
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+)
+
+// Encodings of the HMAC signatures of the requests of a webhook.
+const (
+	EncodingHex    = "hex"
+	EncodingBase64 = "base64"
+)
+
+const (
+	// DefaultSignatureHeader is the header of the signatures of the requests, as sent by GitHub.
+	DefaultSignatureHeader = "X-Hub-Signature-256"
+
+	// DefaultMaxEvents is the default number of events kept by a receiver.
+	DefaultMaxEvents = 100
+
+	// DefaultMaxBodyBytes is the default maximum size of the body of a request.
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Config defines how a receiver verifies the requests of a webhook and how many events it keeps.
+type Config struct {
+	// Secret of the HMAC signatures of the requests. Requests are not verified if empty.
+	Secret string
+
+	// Header of the signatures of the requests (default: X-Hub-Signature-256).
+	SignatureHeader string
+
+	// Hash function of the signatures: sha1, sha256 or sha512 (default: sha256).
+	Algorithm string
+
+	// Encoding of the signatures: hex or base64 (default: hex).
+	Encoding string
+
+	// Number of events kept, the oldest events being dropped past it (default: 100).
+	MaxEvents int
+
+	// Maximum size in bytes of the body of a request. Larger requests are rejected (default: 1 MiB).
+	MaxBodyBytes int64
+}
+
+// Event is a request received by a webhook.
+type Event struct {
+	// ID of the event, increasing with each event of the receiver.
+	ID string `json:"id"`
+
+	ReceivedAt time.Time `json:"receivedAt"`
+
+	// Headers of the request, except its credentials and signature.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Payload is the body of the request if it is JSON.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Body is the body of the request if it is not JSON.
+	Body string `json:"body,omitempty"`
+}
+
+// Receiver is the HTTP handler of a webhook, keeping its most recent events in memory.
+type Receiver struct {
+	config Config
+	hash   func() hash.Hash
+
+	mu          sync.Mutex
+	events      []*Event
+	lastID      int64
+	subscribers map[any]func(*Event)
+}
+
+// NewReceiver creates a receiver without events, applying the defaults of config.
+func NewReceiver(config Config) (*Receiver, error) {
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultSignatureHeader
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmSHA256
+	}
+	if config.Encoding == "" {
+		config.Encoding = EncodingHex
+	}
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = DefaultMaxEvents
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	r := &Receiver{config: config, subscribers: make(map[any]func(*Event))}
+	switch config.Algorithm {
+	case AlgorithmSHA1:
+		r.hash = sha1.New
+	case AlgorithmSHA256:
+		r.hash = sha256.New
+	case AlgorithmSHA512:
+		r.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm '%s'", config.Algorithm)
+	}
+	if config.Encoding != EncodingHex && config.Encoding != EncodingBase64 {
+		return nil, fmt.Errorf("unsupported signature encoding '%s'", config.Encoding)
+	}
+
+	return r, nil
+}
+
+// ServeHTTP receives an event posted to the webhook. Requests whose signature doesn't match their body are
+// rejected with 401 Unauthorized, and accepted events are answered with 202 Accepted and their ID.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, r.config.MaxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if !r.verify(req.Header.Get(r.config.SignatureHeader), body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.add(r.eventHeaders(req.Header), body)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"id": event.ID})
+}
+
+// verify returns whether signature is the signature of body, always true if the receiver has no secret. The
+// signature may be prefixed with the name of the algorithm, e.g. sha256=<hex>.
+func (r *Receiver) verify(signature string, body []byte) bool {
+	if r.config.Secret == "" {
+		return true
+	}
+
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), r.config.Algorithm+"=")
+	if signature == "" {
+		return false
+	}
+
+	var received []byte
+	var err error
+	if r.config.Encoding == EncodingBase64 {
+		received, err = base64.StdEncoding.DecodeString(signature)
+	} else {
+		received, err = hex.DecodeString(signature)
+	}
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(r.hash, []byte(r.config.Secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// eventHeaders returns the headers of a request kept in its event: every header but its credentials and its
+// signature, with the values of repeated headers joined by commas.
+func (r *Receiver) eventHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Cookie", "Proxy-Authorization", http.CanonicalHeaderKey(r.config.SignatureHeader):
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// add adds an event with the given headers and body, dropping the oldest event if the receiver keeps too
+// many, and notifies the subscribers.
+func (r *Receiver) add(headers map[string]string, body []byte) *Event {
+	r.mu.Lock()
+	r.lastID++
+	event := &Event{ID: strconv.FormatInt(r.lastID, 10), ReceivedAt: time.Now(), Headers: headers}
+	if json.Valid(body) {
+		event.Payload = json.RawMessage(body)
+	} else {
+		event.Body = string(body)
+	}
+
+	r.events = append(r.events, event)
+	if len(r.events) > r.config.MaxEvents {
+		r.events = r.events[len(r.events)-r.config.MaxEvents:]
+	}
+
+	subscribers := make([]func(*Event), 0, len(r.subscribers))
+	for _, notify := range r.subscribers {
+		subscribers = append(subscribers, notify)
+	}
+	r.mu.Unlock()
+
+	for _, notify := range subscribers {
+		notify(event)
+	}
+	return event
+}
+
+// Events returns the events kept by the receiver, oldest first.
+func (r *Receiver) Events() []*Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]*Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Subscribe calls notify with every event received from now on. A subscriber subscribing again with the
+// same key replaces its previous function.
+func (r *Receiver) Subscribe(key any, notify func(*Event)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers[key] = notify
+}
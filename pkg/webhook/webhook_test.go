@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret, body string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+func TestReceiverServeHTTP(t *testing.T) {
+	body := `{"action":"opened","number":42}`
+	sha1Mac := hmac.New(sha1.New, []byte("s3cret"))
+	sha1Mac.Write([]byte(body))
+
+	tt := []struct {
+		name            string
+		config          Config
+		method          string
+		body            string
+		headers         map[string]string
+		expectedStatus  int
+		expectedPayload string
+		expectedBody    string
+	}{
+		{
+			name:            "signed request",
+			config:          Config{Secret: "s3cret"},
+			body:            body,
+			headers:         map[string]string{"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(sign("s3cret", body))},
+			expectedStatus:  http.StatusAccepted,
+			expectedPayload: body,
+		},
+		{
+			name:            "signature without prefix",
+			config:          Config{Secret: "s3cret"},
+			body:            body,
+			headers:         map[string]string{"X-Hub-Signature-256": hex.EncodeToString(sign("s3cret", body))},
+			expectedStatus:  http.StatusAccepted,
+			expectedPayload: body,
+		},
+		{
+			name:            "base64 signature in custom header",
+			config:          Config{Secret: "s3cret", SignatureHeader: "X-Shopify-Hmac-Sha256", Encoding: EncodingBase64},
+			body:            body,
+			headers:         map[string]string{"X-Shopify-Hmac-Sha256": base64.StdEncoding.EncodeToString(sign("s3cret", body))},
+			expectedStatus:  http.StatusAccepted,
+			expectedPayload: body,
+		},
+		{
+			name:            "sha1 signature",
+			config:          Config{Secret: "s3cret", SignatureHeader: "X-Hub-Signature", Algorithm: AlgorithmSHA1},
+			body:            body,
+			headers:         map[string]string{"X-Hub-Signature": "sha1=" + hex.EncodeToString(sha1Mac.Sum(nil))},
+			expectedStatus:  http.StatusAccepted,
+			expectedPayload: body,
+		},
+		{
+			name:           "signature of another secret",
+			config:         Config{Secret: "s3cret"},
+			body:           body,
+			headers:        map[string]string{"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(sign("other", body))},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing signature",
+			config:         Config{Secret: "s3cret"},
+			body:           body,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "malformed signature",
+			config:         Config{Secret: "s3cret"},
+			body:           body,
+			headers:        map[string]string{"X-Hub-Signature-256": "sha256=not-hex"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsigned webhook",
+			body:           "build passed",
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   "build passed",
+		},
+		{
+			name:           "body too large",
+			config:         Config{MaxBodyBytes: 8},
+			body:           body,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "not a POST",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReceiver(tc.config)
+			require.NoError(t, err)
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/webhooks/github", strings.NewReader(tc.body))
+			req.Header.Set("X-GitHub-Event", "pull_request")
+			req.Header.Set("Authorization", "Bearer token")
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			events := r.Events()
+			if tc.expectedStatus != http.StatusAccepted {
+				assert.Empty(t, events)
+				return
+			}
+
+			require.Len(t, events, 1)
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, events[0].ID, response["id"])
+			assert.Equal(t, tc.expectedPayload, string(events[0].Payload))
+			assert.Equal(t, tc.expectedBody, events[0].Body)
+			assert.Equal(t, "pull_request", events[0].Headers["X-Github-Event"])
+			assert.NotContains(t, events[0].Headers, "Authorization")
+			for name := range tc.headers {
+				assert.NotContains(t, events[0].Headers, name)
+			}
+		})
+	}
+}
+
+func TestNewReceiver(t *testing.T) {
+	tt := []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{
+			name:   "defaults",
+			config: Config{},
+		},
+		{
+			name:          "unsupported algorithm",
+			config:        Config{Algorithm: "md5"},
+			expectedError: "unsupported signature algorithm 'md5'",
+		},
+		{
+			name:          "unsupported encoding",
+			config:        Config{Encoding: "base32"},
+			expectedError: "unsupported signature encoding 'base32'",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewReceiver(tc.config)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiverEvents(t *testing.T) {
+	r, err := NewReceiver(Config{MaxEvents: 2})
+	require.NoError(t, err)
+
+	var notified []string
+	r.Subscribe("client", func(e *Event) {
+		notified = append(notified, e.ID)
+	})
+	// subscribing again with the same key doesn't notify twice
+	r.Subscribe("client", func(e *Event) {
+		notified = append(notified, e.ID)
+	})
+
+	for _, body := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body)))
+		require.Equal(t, http.StatusAccepted, rec.Code)
+	}
+
+	events := r.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "2", events[0].ID)
+	assert.JSONEq(t, `{"n":2}`, string(events[0].Payload))
+	assert.Equal(t, "3", events[1].ID)
+	assert.JSONEq(t, `{"n":3}`, string(events[1].Payload))
+	assert.Equal(t, []string{"1", "2", "3"}, notified)
+}
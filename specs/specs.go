@@ -0,0 +1,14 @@
+// Package specs embeds the JSON schemas of the gen-mcp configuration files.
+package specs
+
+import _ "embed"
+
+// MCPFileSchema is the JSON schema of the latest MCP file version.
+//
+//go:embed mcpfile-schema.json
+var MCPFileSchema []byte
+
+// MCPServerConfigSchema is the JSON schema of the latest server config file version.
+//
+//go:embed mcpserver-schema.json
+var MCPServerConfigSchema []byte